@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"todo-api/internal/seed"
+)
+
+// validateFixtures checks a file of fixture users and tasks without starting the server.
+// Storage is seeded from the file as the server starts with STORAGE_SEED_FILE set to it.
+func validateFixtures(args []string) error {
+	fs := flag.NewFlagSet("validate-fixtures", flag.ExitOnError)
+	configFlags(fs)
+	file := fs.String("file", "", "YAML or JSON file of fixture users and tasks, defaulting to STORAGE_SEED_FILE or "+seed.DefaultFile)
+	fs.Parse(args)
+
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		return err
+	}
+
+	path := *file
+	if path == "" {
+		path = cfg.Storage.SeedFile
+	}
+	if path == "" {
+		path = seed.DefaultFile
+	}
+
+	fixtures, err := seed.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load fixtures: %w", err)
+	}
+	fmt.Fprintf(stdout, "%s: %d users and %d tasks are valid; start the server with STORAGE_SEED_FILE=%s to seed storage with them\n",
+		path, len(fixtures.Users), len(fixtures.Tasks), path)
+	return nil
+}
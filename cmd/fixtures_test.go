@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFixtures(t *testing.T) {
+	var out bytes.Buffer
+	stdout = &out
+	t.Cleanup(func() { stdout = os.Stdout })
+
+	path := "../internal/seed/testdata/fixtures.yaml"
+	require.NoError(t, validateFixtures([]string{"-file", path}))
+	assert.Equal(t, path+": 5 users and 6 tasks are valid; start the server with STORAGE_SEED_FILE="+path+" to seed storage with them\n", out.String())
+
+	invalid := filepath.Join(t.TempDir(), "fixtures.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("users:\n  - email: sam\n    password: password123\n"), 0o600))
+	err := validateFixtures([]string{"-file", invalid})
+	assert.EqualError(t, err, "failed to load fixtures: "+invalid+`: users[0].email: "sam" is not an email address`)
+}
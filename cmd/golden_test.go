@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"todo-api/internal/container"
+	"todo-api/internal/golden"
+	"todo-api/internal/lifecycle"
+	"todo-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goldenRequest is a request whose response is compared with a golden file. {task} in the
+// path is replaced by the ID of the task created by an earlier request. Authenticated
+// requests are sent as John.
+type goldenRequest struct {
+	name   string
+	method string
+	path   string
+	body   string
+	auth   bool
+}
+
+// goldenRequests cover each kind of endpoint and error, in order, as later requests depend on
+// the changes of earlier ones
+var goldenRequests = []goldenRequest{
+	{"health", fiber.MethodGet, "/health", "", false},
+	{"not_found", fiber.MethodGet, "/api/v2/unknown", "", false},
+	{"login", fiber.MethodPost, "/api/v2/auth/login", `{"email":"john.doe@example.com","password":"password123"}`, false},
+	{"login_invalid", fiber.MethodPost, "/api/v2/auth/login", `{"email":"john.doe@example.com","password":"wrong-password"}`, false},
+	{"unauthorized", fiber.MethodGet, "/api/v2/tasks", "", false},
+	{"list_tasks_v1", fiber.MethodGet, "/api/v1/tasks?sort=title:asc", "", true},
+	{"list_tasks_v2", fiber.MethodGet, "/api/v2/tasks?sort=title:asc", "", true},
+	{"list_tasks_filtered", fiber.MethodGet, "/api/v2/tasks?status=pending&sort=title:asc&fields=id,title,status", "", true},
+	{"list_tasks_invalid_query", fiber.MethodGet, "/api/v2/tasks?sort=owner", "", true},
+	{"create_task", fiber.MethodPost, "/api/v2/tasks", `{"title":"Record golden files","priority":"high"}`, true},
+	{"create_task_invalid", fiber.MethodPost, "/api/v2/tasks", `{"title":" "}`, true},
+	{"get_task", fiber.MethodGet, "/api/v2/tasks/{task}", "", true},
+	{"get_task_not_found", fiber.MethodGet, "/api/v2/tasks/00000000-0000-0000-0000-000000000000", "", true},
+	{"update_task", fiber.MethodPut, "/api/v2/tasks/{task}", `{"description":"Snapshot every endpoint"}`, true},
+	{"add_checklist_item", fiber.MethodPost, "/api/v2/tasks/{task}/checklist", `{"text":"Cover errors"}`, true},
+	{"complete_task", fiber.MethodPost, "/api/v2/tasks/{task}/complete", "", true},
+	{"task_history", fiber.MethodGet, "/api/v2/tasks/{task}/history", "", true},
+	{"task_stats", fiber.MethodGet, "/api/v2/tasks/stats?from=2026-01-01&to=2026-01-07", "", true},
+	{"search", fiber.MethodGet, "/api/v2/search?q=golden", "", true},
+	{"workspaces", fiber.MethodGet, "/api/v2/workspaces", "", true},
+	{"usage", fiber.MethodGet, "/api/v2/me/usage", "", true},
+	{"notifications", fiber.MethodGet, "/api/v2/me/notifications", "", true},
+	{"delete_task", fiber.MethodDelete, "/api/v2/tasks/{task}", "", true},
+	{"graphql", fiber.MethodPost, "/graphql", `{"query":"{ tasks(sortField: \"title\", sortOrder: \"asc\", limit: 2) { data { title status } pagination { total } } }"}`, true},
+}
+
+func TestAPIResponses_Golden(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Storage.SeedFile = "../internal/seed/testdata/fixtures.yaml"
+
+	lc := lifecycle.NewManager()
+	t.Cleanup(func() { lc.Shutdown(context.Background()) })
+	deps, err := container.New(cfg, lc)
+	require.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: customErrorHandler})
+	setupRoutes(app, cfg, deps)
+
+	var token, taskID string
+	for _, r := range goldenRequests {
+		replacer := strings.NewReplacer("{task}", taskID)
+		var body io.Reader
+		if r.body != "" {
+			body = strings.NewReader(r.body)
+		}
+		req := httptest.NewRequest(r.method, replacer.Replace(r.path), body)
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if r.auth {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err, r.name)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, r.name)
+
+		// The status is recorded along with the body, so changed codes are caught too
+		snapshot, err := json.Marshal(map[string]interface{}{
+			"status": resp.StatusCode,
+			"body":   json.RawMessage(data),
+		})
+		require.NoError(t, err, r.name)
+		t.Run(r.name, func(t *testing.T) {
+			golden.AssertJSON(t, r.name, snapshot)
+		})
+
+		var decoded struct {
+			Data struct {
+				AccessToken string `json:"access_token"`
+				ID          string `json:"id"`
+			} `json:"data"`
+		}
+		json.Unmarshal(data, &decoded)
+		switch r.name {
+		case "login":
+			token = decoded.Data.AccessToken
+			assert.NotEmpty(t, token)
+		case "create_task":
+			taskID = decoded.Data.ID
+			assert.NotEmpty(t, taskID)
+		}
+	}
+}
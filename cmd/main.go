@@ -1,124 +1,104 @@
 package main
 
 import (
-	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
-	authHandler "todo-api/internal/handler/auth"
-	taskHandler "todo-api/internal/handler/task"
-	"todo-api/internal/middleware"
-	authService "todo-api/internal/service/auth"
 	"todo-api/pkg/config"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
+	"todo-api/pkg/redact"
 )
 
-func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	app := fiber.New(fiber.Config{
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
-		ErrorHandler: customErrorHandler,
-	})
-
-	app.Use(recover.New())
-	app.Use(logger.New())
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
-	}))
+// command is a subcommand of the binary, run with the arguments following its name
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	setupRoutes(app, cfg)
+// commands lists the subcommands of the binary; serve runs when none is given
+var commands = []command{
+	{"serve", "Start the HTTP and gRPC servers", serve},
+	{"routes", "Print the route table", printRoutes},
+	{"openapi", "Print an OpenAPI document describing the routes", printOpenAPI},
+	{"validate-fixtures", "Check a file of fixture users and tasks to seed storage with", validateFixtures},
+}
 
-	go func() {
-		addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-		log.Printf("Server starting on %s", addr)
-		if err := app.Listen(addr); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
+// stdout is where commands write their output
+var stdout io.Writer = os.Stdout
 
-	// Wait for interrupt signal to gracefully shutdown the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// flagSettings maps command-line flags to the settings they override
+var flagSettings = map[string]string{
+	"port":           "SERVER_PORT",
+	"config":         "CONFIG_FILE",
+	"log-level":      "LOG_LEVEL",
+	"storage-driver": "STORAGE_DRIVER",
+}
 
-	log.Println("Shutting down server...")
+func main() {
+	// Credentials and email addresses are kept out of the logs of every command
+	log.SetOutput(redact.NewWriter(os.Stderr))
 
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// Without a command the servers are started, so flags alone keep working as before
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	if err := app.ShutdownWithContext(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if name == "help" {
+		usage(stdout)
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(args); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
 	}
 
-	log.Println("Server exited")
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
 }
 
-// setupRoutes sets up all the application routes
-func setupRoutes(app *fiber.App, cfg *config.Config) {
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"status":  "ok",
-			"message": "Todo API is running",
-			"time":    time.Now().UTC(),
-		})
-	})
-
-	// Initialize handlers
-	authHandler := authHandler.NewHandler(cfg)
-	authSvc := authService.NewService(cfg)
-	taskHandler := taskHandler.NewHandler(authSvc)
-
-	api := app.Group("/api/v1")
-
-	// Authentication routes
-	auth := api.Group("/auth")
-	auth.Post("/login", authHandler.Login)
-
-	// Protected routes
-	protected := api.Group("/tasks")
-	protected.Use(middleware.AuthMiddleware(cfg))
+// usage writes the list of commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: todo-api [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'todo-api <command> -h' for the flags of a command.")
+}
 
-	protected.Get("/", taskHandler.ListTasks)
-	protected.Post("/", taskHandler.CreateTask)
-	protected.Get("/:id", taskHandler.GetTask)
-	protected.Put("/:id", taskHandler.UpdateTask)
-	protected.Delete("/:id", taskHandler.DeleteTask)
+// configFlags registers the flags overriding settings that every command loading the
+// configuration accepts
+func configFlags(fs *flag.FlagSet) {
+	fs.String("config", "", "Path of a YAML or TOML configuration file, overriding CONFIG_FILE")
+	fs.String("log-level", "", "Log level (debug, info, warn, or error), overriding LOG_LEVEL")
+	fs.String("storage-driver", "", "Storage driver (memory), overriding STORAGE_DRIVER")
+}
 
-	// 404 fallback
-	app.Use(func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Route not found",
-		})
+// loadConfig loads the configuration, overridden by the flags set on the command line
+func loadConfig(fs *flag.FlagSet) (*config.Config, error) {
+	// Flags set on the command line take precedence over every other configuration source
+	overrides := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if setting, ok := flagSettings[f.Name]; ok {
+			overrides[setting] = f.Value.String()
+		}
 	})
-}
 
-// customErrorHandler handles application errors
-func customErrorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+	cfg, err := config.LoadWithOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
-	return c.Status(code).JSON(fiber.Map{
-		"error":   true,
-		"message": err.Error(),
-	})
+	return cfg, nil
 }
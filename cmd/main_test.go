@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"todo-api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes a configuration file and points CONFIG_FILE at it
+func writeConfigFile(t *testing.T, name, data string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	writeConfigFile(t, "config.yaml", "server:\n  port: \"7000\"\n  host: file.internal\napp:\n  log_level: debug\n")
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		wantPort string
+		wantHost string
+		wantLog  string
+	}{
+		{name: "file", wantPort: "7000", wantHost: "file.internal", wantLog: "debug"},
+		{name: "env over file", env: map[string]string{"SERVER_PORT": "7100", "LOG_LEVEL": "warn"},
+			wantPort: "7100", wantHost: "file.internal", wantLog: "warn"},
+		{name: "flag over file", args: []string{"-port", "7200"},
+			wantPort: "7200", wantHost: "file.internal", wantLog: "debug"},
+		{name: "flag over env", env: map[string]string{"SERVER_PORT": "7100", "LOG_LEVEL": "warn"},
+			args: []string{"-port", "7200", "-log-level", "error"}, wantPort: "7200", wantHost: "file.internal", wantLog: "error"},
+		{name: "flag set to the default", env: map[string]string{"LOG_LEVEL": "warn"}, args: []string{"-log-level", "info"},
+			wantPort: "7000", wantHost: "file.internal", wantLog: "info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.String("port", "", "")
+			configFlags(fs)
+			require.NoError(t, fs.Parse(tt.args))
+
+			cfg, err := loadConfig(fs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPort, cfg.Server.Port)
+			assert.Equal(t, tt.wantHost, cfg.Server.Host)
+			assert.Equal(t, tt.wantLog, cfg.App.LogLevel)
+		})
+	}
+}
+
+func TestLoadConfig_ConfigFlag(t *testing.T) {
+	writeConfigFile(t, "config.yaml", "server:\n  port: \"7000\"\n")
+	path := filepath.Join(t.TempDir(), "override.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[server]\nport = \"7300\"\n"), 0o600))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	configFlags(fs)
+	require.NoError(t, fs.Parse([]string{"-config", path}))
+
+	cfg, err := loadConfig(fs)
+	require.NoError(t, err)
+	assert.Equal(t, "7300", cfg.Server.Port)
+}
+
+func TestServe_ValidateConfig(t *testing.T) {
+	writeConfigFile(t, "config.yaml", "jwt:\n  secret_key: file-jwt-secret\nmail:\n  smtp_password: file-smtp-password\n")
+	t.Setenv("SCIM_TOKEN", "env-scim-token-0123456789abcdef0123")
+
+	var out bytes.Buffer
+	stdout = &out
+	t.Cleanup(func() { stdout = os.Stdout })
+
+	require.NoError(t, serve([]string{"-validate-config", "-port", "7400"}))
+
+	output := out.String()
+	assert.Contains(t, output, "SERVER_PORT=7400\n")
+	assert.Contains(t, output, "JWT_SECRET_KEY="+config.Redacted+"\n")
+	assert.Contains(t, output, "SMTP_PASSWORD="+config.Redacted+"\n")
+	assert.Contains(t, output, "SCIM_TOKEN="+config.Redacted+"\n")
+	for _, secret := range []string{"file-jwt-secret", "file-smtp-password", "env-scim-token-0123456789abcdef0123"} {
+		assert.NotContains(t, output, secret)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the documents follow
+const openAPIVersion = "3.0.3"
+
+// openAPIDocument describes the paths of the API and their operations. Request and response
+// bodies are documented in the README rather than as schemas.
+type openAPIDocument struct {
+	OpenAPI string                                 `json:"openapi"`
+	Info    openAPIInfo                            `json:"info"`
+	Paths   map[string]map[string]openAPIOperation `json:"paths"`
+}
+
+// openAPIInfo is the metadata of an OpenAPI document
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// openAPIOperation is an operation on a path
+type openAPIOperation struct {
+	Tags       []string                   `json:"tags,omitempty"`
+	Deprecated bool                       `json:"deprecated,omitempty"`
+	Parameters []openAPIParameter         `json:"parameters,omitempty"`
+	Responses  map[string]openAPIResponse `json:"responses"`
+}
+
+// openAPIParameter is a parameter of an operation
+type openAPIParameter struct {
+	Name     string            `json:"name"`
+	In       string            `json:"in"`
+	Required bool              `json:"required"`
+	Schema   map[string]string `json:"schema"`
+}
+
+// openAPIResponse is a response of an operation
+type openAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// printOpenAPI prints an OpenAPI document of the routes served with the configuration
+func printOpenAPI(args []string) error {
+	routes, err := loadRoutes("openapi", args)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newOpenAPIDocument(routes))
+}
+
+// newOpenAPIDocument describes the routes as an OpenAPI document. Operations are tagged with
+// the first segment of their path after the API version, and operations of the deprecated
+// v1 API are marked as such.
+func newOpenAPIDocument(routes []fiber.Route) *openAPIDocument {
+	doc := &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: "Todo API", Version: "v2"},
+		Paths:   make(map[string]map[string]openAPIOperation),
+	}
+
+	for _, route := range routes {
+		segments := strings.Split(strings.Trim(route.Path, "/"), "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+			}
+		}
+		path := "/" + strings.Join(segments, "/")
+
+		op := openAPIOperation{
+			Responses: map[string]openAPIResponse{
+				"default": {Description: "JSON response; failures set error and message"},
+			},
+		}
+		resource := segments
+		if len(segments) > 2 && segments[0] == "api" {
+			op.Deprecated = segments[1] == "v1"
+			resource = segments[2:]
+		}
+		if resource[0] != "" {
+			op.Tags = []string{resource[0]}
+		}
+		for _, param := range route.Params {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name:     param,
+				In:       "path",
+				Required: true,
+				Schema:   map[string]string{"type": "string"},
+			})
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]openAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	return doc
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpenAPIDocument(t *testing.T) {
+	doc := newOpenAPIDocument([]fiber.Route{
+		{Method: fiber.MethodGet, Path: "/api/v1/tasks/"},
+		{Method: fiber.MethodGet, Path: "/api/v2/tasks/:id/checklist/:itemId", Params: []string{"id", "itemId"}},
+		{Method: fiber.MethodPatch, Path: "/api/v2/tasks/:id/checklist/:itemId", Params: []string{"id", "itemId"}},
+		{Method: fiber.MethodPost, Path: "/graphql"},
+	})
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	require.Len(t, doc.Paths, 3)
+
+	list := doc.Paths["/api/v1/tasks"]["get"]
+	assert.True(t, list.Deprecated)
+	assert.Equal(t, []string{"tasks"}, list.Tags)
+	assert.Empty(t, list.Parameters)
+
+	item := doc.Paths["/api/v2/tasks/{id}/checklist/{itemId}"]
+	require.Len(t, item, 2)
+	assert.False(t, item["patch"].Deprecated)
+	require.Len(t, item["patch"].Parameters, 2)
+	assert.Equal(t, openAPIParameter{Name: "itemId", In: "path", Required: true, Schema: map[string]string{"type": "string"}}, item["patch"].Parameters[1])
+
+	assert.Equal(t, []string{"graphql"}, doc.Paths["/graphql"]["post"].Tags)
+	assert.Contains(t, doc.Paths["/graphql"]["post"].Responses, "default")
+}
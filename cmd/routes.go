@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"todo-api/internal/container"
+	authDomain "todo-api/internal/domain/auth"
+	workspaceDomain "todo-api/internal/domain/workspace"
+	"todo-api/internal/health"
+	"todo-api/internal/lifecycle"
+	"todo-api/internal/metrics"
+	"todo-api/internal/middleware"
+	"todo-api/internal/response"
+	"todo-api/pkg/config"
+	"todo-api/pkg/redact"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// routeMethods orders the routes of a path in listings
+var routeMethods = []string{
+	fiber.MethodGet,
+	fiber.MethodPost,
+	fiber.MethodPut,
+	fiber.MethodPatch,
+	fiber.MethodDelete,
+}
+
+// printRoutes prints the method and path of every route served with the configuration
+func printRoutes(args []string) error {
+	routes, err := loadRoutes("routes", args)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, route := range routes {
+		fmt.Fprintf(w, "%s\t%s\n", route.Method, route.Path)
+	}
+	return w.Flush()
+}
+
+// loadRoutes registers the routes the server would serve with the configuration, without
+// starting it, and returns them ordered by path. Middleware, and the HEAD routes fiber adds
+// for GET routes, are left out.
+func loadRoutes(name string, args []string) ([]fiber.Route, error) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	configFlags(fs)
+	fs.Parse(args)
+
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	lc := lifecycle.NewManager()
+	defer lc.Shutdown(context.Background())
+	deps, err := container.New(cfg, lc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	app := fiber.New()
+	setupRoutes(app, cfg, deps)
+
+	var routes []fiber.Route
+	for _, route := range app.GetRoutes(true) {
+		if route.Method != fiber.MethodHead {
+			routes = append(routes, route)
+		}
+	}
+	slices.SortStableFunc(routes, func(a, b fiber.Route) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return slices.Index(routeMethods, a.Method) - slices.Index(routeMethods, b.Method)
+	})
+	return routes, nil
+}
+
+// setupRoutes sets up all the application routes
+func setupRoutes(app *fiber.App, cfg *config.Config, deps *container.Container) {
+	// Health of the server and its dependencies for uptime monitors, unavailable while a
+	// critical dependency is down
+	app.Get("/health", func(c *fiber.Ctx) error {
+		report := deps.Health.Check(c.UserContext())
+		status, message := fiber.StatusOK, "Todo API is running"
+		if report.Status == health.StatusDown {
+			status, message = fiber.StatusServiceUnavailable, "A critical dependency of the Todo API is down"
+		}
+		return response.Send(c, status, fiber.Map{
+			"status":       report.Status,
+			"message":      message,
+			"time":         time.Now().UTC(),
+			"checked_at":   report.CheckedAt,
+			"dependencies": report.Dependencies,
+		})
+	})
+
+	// Prometheus metrics, restricted like the admin API
+	app.Get("/metrics", middleware.IPFilter(cfg.IP.AdminAllow, cfg.IP.AdminDeny), func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, metrics.ContentType)
+		return deps.Registry.Write(c)
+	})
+
+	h := deps.Handlers
+
+	// v1 is deprecated in favour of v2, which differs only in its response envelope.
+	// Routes are registered on both groups; version-specific formats are applied by
+	// response adapters, so handlers stay version-agnostic.
+	v1 := app.Group("/api/v1", middleware.APIVersion(response.V1), middleware.Deprecated("/api/v2"))
+	registerAPIRoutes(v1, cfg, deps)
+
+	v2 := app.Group("/api/v2", middleware.APIVersion(response.V2))
+	registerAPIRoutes(v2, cfg, deps)
+
+	// GraphQL API
+	app.Post("/graphql", deps.Authenticate, deps.ResolveTenant, h.GraphQL.Handle)
+
+	// Slack slash commands, authenticated by their signature
+	app.Post("/integrations/slack/commands", h.Integration.SlackCommand)
+
+	// Telegram bot updates, authenticated by the webhook secret token
+	app.Post("/integrations/telegram/webhook", h.Integration.TelegramWebhook)
+
+	// GitHub OAuth redirects, authenticated by their state, and repository webhook events,
+	// authenticated by their signature
+	app.Get("/integrations/github/callback", h.Integration.GitHubCallback)
+	app.Post("/integrations/github/webhook", h.Integration.GitHubWebhook)
+
+	// Google OAuth redirects, authenticated by their state
+	app.Get("/integrations/google-calendar/callback", h.Integration.GoogleCalendarCallback)
+
+	// Twilio message status callbacks, authenticated by their signature
+	app.Post("/integrations/twilio/status", h.Integration.TwilioStatus)
+
+	// Emails posted by Mailgun or SNS, authenticated by their signature
+	app.Post("/integrations/email/webhook", h.Integration.EmailWebhook)
+
+	// Stripe checkout and subscription events, authenticated by their signature
+	app.Post("/integrations/stripe/webhook", h.Billing.StripeWebhook)
+
+	// Real-time task updates
+	app.Get("/ws", deps.AuthenticateWebSocket, deps.ResolveTenant, websocket.New(h.Tasks.StreamTasks))
+
+	// SCIM user provisioning by identity providers, authenticated by their bearer token
+	if h.SCIM != nil {
+		scim := app.Group("/scim/v2", h.SCIM.Authenticate)
+		scim.Get("/ServiceProviderConfig", h.SCIM.ServiceProviderConfig)
+		scim.Get("/Users", h.SCIM.ListUsers)
+		scim.Post("/Users", h.SCIM.CreateUser)
+		scim.Get("/Users/:id", h.SCIM.GetUser)
+		scim.Put("/Users/:id", h.SCIM.ReplaceUser)
+		scim.Patch("/Users/:id", h.SCIM.PatchUser)
+		scim.Delete("/Users/:id", h.SCIM.DeleteUser)
+	}
+
+	// 404 fallback
+	app.Use(func(c *fiber.Ctx) error {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Route not found",
+		})
+	})
+
+	// Time spent in route handlers is told apart from middleware in slow request logs
+	middleware.TimeHandlers(app)
+}
+
+// registerAPIRoutes registers the REST routes shared by every API version
+func registerAPIRoutes(api fiber.Router, cfg *config.Config, deps *container.Container) {
+	h := deps.Handlers
+
+	// API requests are bounded by the request timeout, and imports and exports, which read or
+	// write every task of the user, and completing uploads, which scans the file, by the
+	// longer bulk request timeout
+	api.Use(middleware.Timeout(cfg.Server.RequestTimeout))
+	bulk := middleware.Timeout(cfg.Server.BulkRequestTimeout)
+
+	// Authentication routes
+	auth := api.Group("/auth")
+	auth.Post("/login", h.Auth.Login)
+	auth.Post("/refresh", h.Auth.Refresh)
+	auth.Post("/password/forgot", h.Auth.ForgotPassword)
+	auth.Post("/password/reset", h.Auth.ResetPassword)
+	auth.Post("/email/verify", h.Auth.VerifyEmail)
+	auth.Post("/email/verification", deps.Authenticate, h.Auth.SendVerificationEmail)
+
+	// Every protected route is isolated to the caller's tenant
+	resolveTenant := deps.ResolveTenant
+
+	// Protected routes
+	protected := api.Group("/tasks")
+	protected.Use(deps.Authenticate, resolveTenant)
+
+	canRead := middleware.RequireScope(authDomain.ScopeTasksRead)
+	canWrite := middleware.RequireScope(authDomain.ScopeTasksWrite)
+
+	protected.Get("/", canRead, h.Tasks.ListTasks)
+	protected.Post("/", canWrite, h.Tasks.CreateTask)
+	protected.Get("/stats", canRead, h.Tasks.GetStats)
+	protected.Get("/export", bulk, canRead, h.Tasks.ExportTasks)
+	protected.Post("/import", bulk, canWrite, h.Tasks.ImportTasks)
+	protected.Get("/changes", canRead, h.Tasks.TaskChanges)
+	protected.Get("/:id", canRead, h.Tasks.GetTask)
+	protected.Put("/:id", canWrite, h.Tasks.UpdateTask)
+	protected.Post("/:id/resolve", canWrite, h.Tasks.ResolveTask)
+	protected.Delete("/:id", canWrite, h.Tasks.DeleteTask)
+	protected.Post("/:id/move", canWrite, h.Tasks.MoveTask)
+	protected.Post("/:id/complete", canWrite, h.Tasks.CompleteTask)
+	protected.Post("/:id/reopen", canWrite, h.Tasks.ReopenTask)
+	protected.Post("/:id/archive", canWrite, h.Tasks.ArchiveTask)
+	protected.Post("/:id/unarchive", canWrite, h.Tasks.UnarchiveTask)
+	protected.Post("/:id/checklist", canWrite, h.Tasks.AddChecklistItem)
+	protected.Patch("/:id/checklist/:itemId", canWrite, h.Tasks.UpdateChecklistItem)
+	protected.Post("/:id/checklist/:itemId/move", canWrite, h.Tasks.MoveChecklistItem)
+	protected.Delete("/:id/checklist/:itemId", canWrite, h.Tasks.RemoveChecklistItem)
+	protected.Put("/:id/assignee", canWrite, h.Tasks.AssignTask)
+	protected.Post("/:id/shares", canWrite, h.Tasks.ShareTask)
+	protected.Delete("/:id/shares/:userId", canWrite, h.Tasks.UnshareTask)
+	protected.Post("/:id/share-link", canWrite, h.Tasks.CreateShareLink)
+	protected.Get("/:id/share-links", canRead, h.Tasks.ListShareLinks)
+	protected.Delete("/:id/share-links/:linkId", canWrite, h.Tasks.RevokeShareLink)
+	protected.Get("/:id/history", canRead, h.Tasks.GetTaskHistory)
+	protected.Get("/:id/presence", canRead, h.Tasks.GetTaskPresence)
+	protected.Get("/:id/attachments", canRead, h.Attachments.ListAttachments)
+	protected.Post("/:id/attachments", canWrite, h.Attachments.CreateAttachment)
+	protected.Post("/:id/attachments/:attachmentId/complete", bulk, canWrite, h.Attachments.CompleteAttachment)
+	protected.Get("/:id/attachments/:attachmentId/download", canRead, h.Attachments.DownloadAttachment)
+	protected.Delete("/:id/attachments/:attachmentId", canWrite, h.Attachments.DeleteAttachment)
+
+	// Share links and the boards of shared projects are viewed without an account; the
+	// token is the credential. Requests are rate limited per client address.
+	api.Get("/shared/:token", deps.LimitPublic, h.Tasks.ViewShareLink)
+	api.Get("/public/projects/:token", deps.LimitPublic, h.Tasks.GetProjectBoard)
+
+	// Quick-add parses a task from natural text. Browser extensions keep an API key, as
+	// tokens expire.
+	api.Post("/quick-add", deps.AuthenticateAPIKey, resolveTenant, canWrite, h.Tasks.QuickAdd)
+
+	// Workspace routes
+	workspaces := api.Group("/workspaces", deps.Authenticate, resolveTenant)
+	workspaces.Get("/", canRead, h.Workspaces.ListWorkspaces)
+	workspaces.Post("/", canWrite, h.Workspaces.CreateWorkspace)
+
+	// Routes of a single workspace require membership
+	workspace := workspaces.Group("/:wid", middleware.WorkspaceMember(deps.Services.Workspaces))
+	isAdmin := middleware.RequireWorkspaceRole(workspaceDomain.RoleAdmin)
+
+	workspace.Get("/", canRead, h.Workspaces.GetWorkspace)
+	workspace.Get("/members", canRead, h.Workspaces.ListMembers)
+	workspace.Delete("/members/:userId", canWrite, h.Workspaces.RemoveMember)
+	workspace.Get("/invitations", canRead, isAdmin, h.Workspaces.ListInvitations)
+	workspace.Post("/invitations", canWrite, isAdmin, h.Workspaces.InviteMember)
+	workspace.Delete("/invitations/:invitationId", canWrite, isAdmin, h.Workspaces.RevokeInvitation)
+	workspace.Get("/projects", canRead, h.Workspaces.ListProjects)
+	workspace.Post("/projects", canWrite, isAdmin, h.Workspaces.CreateProject)
+	workspace.Get("/tasks", canRead, h.Tasks.ListWorkspaceTasks)
+	workspace.Post("/tasks", canWrite, h.Tasks.CreateWorkspaceTask)
+	workspace.Get("/reports/aging", canRead, isAdmin, h.Tasks.GetWorkspaceAgingReport)
+	workspace.Get("/projects/:projectId/burndown", canRead, h.Reports.GetBurndown)
+	workspace.Get("/projects/:projectId/github", canRead, h.Integration.GetProjectGitHubLink)
+	workspace.Put("/projects/:projectId/github", canWrite, isAdmin, h.Integration.LinkProjectToGitHub)
+	workspace.Delete("/projects/:projectId/github", canWrite, isAdmin, h.Integration.UnlinkProjectFromGitHub)
+	workspace.Post("/projects/:projectId/share-link", canWrite, isAdmin, h.Tasks.CreateShareLink)
+	workspace.Get("/projects/:projectId/share-links", canRead, isAdmin, h.Tasks.ListShareLinks)
+	workspace.Delete("/projects/:projectId/share-links/:linkId", canWrite, isAdmin, h.Tasks.RevokeShareLink)
+	workspace.Get("/escalation-rules", canRead, h.Escalations.ListRules)
+	workspace.Post("/escalation-rules", canWrite, isAdmin, h.Escalations.CreateRule)
+	workspace.Get("/escalation-rules/:ruleId", canRead, h.Escalations.GetRule)
+	workspace.Put("/escalation-rules/:ruleId", canWrite, isAdmin, h.Escalations.UpdateRule)
+	workspace.Delete("/escalation-rules/:ruleId", canWrite, isAdmin, h.Escalations.DeleteRule)
+
+	// Invitations addressed to the current user
+	invitations := api.Group("/invitations", deps.Authenticate, resolveTenant)
+	invitations.Get("/", canRead, h.Workspaces.ListMyInvitations)
+	invitations.Post("/:id/accept", canWrite, h.Workspaces.AcceptInvitation)
+
+	// Full-text search over the user's tasks
+	api.Get("/search", deps.Authenticate, resolveTenant, canRead, h.Tasks.SearchTasks)
+
+	// Routes about the current user
+	me := api.Group("/me", deps.Authenticate, resolveTenant)
+	me.Get("/usage", canRead, h.Me.GetUsage)
+	me.Get("/export", bulk, canRead, h.Me.ExportData)
+	me.Post("/export", canRead, h.Me.StartExport)
+	me.Get("/export/:id", canRead, h.Me.GetExport)
+	me.Get("/notifications", canRead, h.Me.GetNotificationPreferences)
+	me.Put("/notifications", canWrite, h.Me.UpdateNotificationPreferences)
+	me.Get("/digest", canRead, h.Me.GetDigest)
+	me.Get("/notification-settings", canRead, h.Me.GetNotificationSettings)
+	me.Put("/notification-settings", canWrite, h.Me.UpdateNotificationSettings)
+	me.Get("/integrations/slack", canRead, h.Integration.GetSlack)
+	me.Put("/integrations/slack", canWrite, h.Integration.ConnectSlack)
+	me.Delete("/integrations/slack", canWrite, h.Integration.DisconnectSlack)
+	me.Get("/integrations/telegram", canRead, h.Integration.GetTelegram)
+	me.Post("/integrations/telegram/link", canWrite, h.Integration.CreateTelegramLinkCode)
+	me.Delete("/integrations/telegram", canWrite, h.Integration.UnlinkTelegram)
+	me.Get("/integrations/github", canRead, h.Integration.GetGitHub)
+	me.Post("/integrations/github/authorize", canWrite, h.Integration.AuthorizeGitHub)
+	me.Delete("/integrations/github", canWrite, h.Integration.DisconnectGitHub)
+	me.Get("/integrations/google-calendar", canRead, h.Integration.GetGoogleCalendar)
+	me.Post("/integrations/google-calendar/authorize", canWrite, h.Integration.AuthorizeGoogleCalendar)
+	me.Delete("/integrations/google-calendar", canWrite, h.Integration.DisconnectGoogleCalendar)
+	me.Get("/integrations/sms", canRead, h.Integration.GetSMS)
+	me.Put("/integrations/sms", canWrite, h.Integration.UpdateSMS)
+	me.Delete("/integrations/sms", canWrite, h.Integration.RemoveSMS)
+	me.Post("/integrations/sms/phone", canWrite, h.Integration.VerifySMSPhone)
+	me.Post("/integrations/sms/verify", canWrite, h.Integration.ConfirmSMSPhone)
+	me.Get("/integrations/sms/messages", canRead, h.Integration.ListSMSMessages)
+	me.Get("/integrations/email", canRead, h.Integration.GetEmail)
+	me.Post("/integrations/email", canWrite, h.Integration.CreateEmail)
+	me.Delete("/integrations/email", canWrite, h.Integration.DeleteEmail)
+	me.Get("/devices", canRead, h.Me.ListDevices)
+	me.Post("/devices", canWrite, h.Me.RegisterDevice)
+	me.Delete("/devices/:id", canWrite, h.Me.RemoveDevice)
+	me.Get("/sessions", canRead, h.Auth.ListSessions)
+	me.Delete("/sessions/:id", canWrite, h.Auth.RevokeSession)
+	me.Get("/api-keys", canRead, h.Automations.ListAPIKeys)
+	me.Post("/api-keys", canWrite, h.Automations.CreateAPIKey)
+	me.Delete("/api-keys/:id", canWrite, h.Automations.RevokeAPIKey)
+	me.Get("/automation-rules", canRead, h.Automations.ListRules)
+	me.Post("/automation-rules", canWrite, h.Automations.CreateRule)
+	me.Get("/automation-rules/:id", canRead, h.Automations.GetRule)
+	me.Put("/automation-rules/:id", canWrite, h.Automations.UpdateRule)
+	me.Delete("/automation-rules/:id", canWrite, h.Automations.DeleteRule)
+	me.Get("/automation-rules/:id/executions", canRead, h.Automations.ListExecutions)
+	me.Get("/stats-webhooks", canRead, h.Reports.ListWebhooks)
+	me.Post("/stats-webhooks", canWrite, h.Reports.CreateWebhook)
+	me.Get("/stats-webhooks/:id", canRead, h.Reports.GetWebhook)
+	me.Put("/stats-webhooks/:id", canWrite, h.Reports.UpdateWebhook)
+	me.Post("/stats-webhooks/:id/rotate-secret", canWrite, h.Reports.RotateWebhookSecret)
+	me.Delete("/stats-webhooks/:id", canWrite, h.Reports.DeleteWebhook)
+	me.Get("/billing", canRead, h.Billing.GetSubscription)
+	me.Post("/billing/checkout", canWrite, h.Billing.CreateCheckoutSession)
+	me.Get("/jobs/:id", canRead, h.Jobs.GetJob)
+	me.Delete("/", canWrite, h.Me.DeleteAccount)
+
+	// Triggers and actions of automation platforms such as Zapier and IFTTT, authenticated by
+	// an API key or a token
+	automations := api.Group("/automations", deps.AuthenticateAPIKey, resolveTenant)
+	automations.Get("/me", canRead, h.Automations.Me)
+	automations.Get("/triggers/new-task", canRead, h.Automations.NewTasks)
+	automations.Get("/triggers/completed-task", canRead, h.Automations.CompletedTasks)
+	automations.Post("/actions/create-task", canWrite, h.Automations.CreateTask)
+	automations.Post("/actions/complete-task", canWrite, h.Automations.CompleteTask)
+
+	// Admin APIs, restricted to platform admins and optionally to internal networks
+	admin := api.Group("/admin", middleware.IPFilter(cfg.IP.AdminAllow, cfg.IP.AdminDeny))
+
+	// Tenant admin API
+	tenants := admin.Group("/tenants", deps.Authenticate, middleware.RequireExplicitScope(authDomain.ScopeTenantsAdmin))
+	tenants.Get("/", h.Tenants.ListTenants)
+	tenants.Post("/", h.Tenants.CreateTenant)
+	tenants.Get("/:id", h.Tenants.GetTenant)
+	tenants.Put("/:id", h.Tenants.UpdateTenant)
+
+	// Security audit log
+	auditLog := admin.Group("/audit", deps.Authenticate, middleware.RequireExplicitScope(authDomain.ScopeAuditRead))
+	auditLog.Get("/", h.Audit.ListEntries)
+
+	// Background job queue and its dead-letter list
+	jobAdmin := admin.Group("/jobs", deps.Authenticate, middleware.RequireExplicitScope(authDomain.ScopeJobsAdmin))
+	jobAdmin.Get("/stats", h.Jobs.GetStats)
+	jobAdmin.Get("/dead", h.Jobs.ListDeadLetters)
+	jobAdmin.Get("/schedules", h.Jobs.ListSchedules)
+	jobAdmin.Post("/dead/:id/retry", h.Jobs.RetryJob)
+	jobAdmin.Delete("/dead/:id", h.Jobs.DiscardJob)
+}
+
+// customErrorHandler handles application errors. Messages are redacted, as errors such as those
+// of parsing a request body or recovered panics may quote the credentials of the request.
+func customErrorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+	if e, ok := err.(*fiber.Error); ok {
+		code = e.Code
+	}
+	return response.Send(c, code, fiber.Map{
+		"error":   true,
+		"message": redact.String(err.Error()),
+	})
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"todo-api/internal/container"
+	"todo-api/internal/grpcserver"
+	"todo-api/internal/httpserver"
+	"todo-api/internal/lifecycle"
+	"todo-api/internal/metrics"
+	"todo-api/internal/middleware"
+	"todo-api/pkg/config"
+	"todo-api/pkg/redact"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// serve starts the HTTP and gRPC servers and blocks until the process is interrupted, then
+// shuts them down gracefully
+func serve(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.String("port", "", "HTTP server port, overriding SERVER_PORT")
+	configFlags(fs)
+	validateConfig := fs.Bool("validate-config", false, "Load the configuration, print the effective settings with secrets redacted, and exit")
+	fs.Parse(args)
+
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		return err
+	}
+
+	if *validateConfig {
+		if err := cfg.WriteSettings(stdout); err != nil {
+			return fmt.Errorf("failed to print configuration: %w", err)
+		}
+		return nil
+	}
+
+	// Background work is stopped on shutdown in reverse order of registration, so the
+	// servers stop accepting work before the event bus delivers what is pending
+	lc := lifecycle.NewManager()
+
+	// Services, handlers, and middleware, each created once. Components with background
+	// work are stopped on shutdown after the servers.
+	deps, err := container.New(cfg, lc)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	// Refresh secrets from the secrets provider, if configured, until shutdown
+	lc.Go("secrets refresh", cfg.WatchSecrets)
+
+	app := newApp(cfg, deps)
+	setupRoutes(app, cfg, deps)
+
+	grpcSrv := grpcserver.NewServer(grpcserver.Deps{
+		Auth:    deps.Services.Auth,
+		Tasks:   deps.Services.Tasks,
+		Tenants: deps.Services.Tenants,
+		Login:   deps.Services.Login,
+	})
+	go func() {
+		addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.GRPCPort)
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		log.Printf("gRPC server starting on %s", addr)
+		if err := grpcSrv.Serve(lis); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+	lc.Register("gRPC server", func(ctx context.Context) (int, error) {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return 0, nil
+		case <-ctx.Done():
+			// Close the connections of calls still running
+			grpcSrv.Stop()
+			return 0, ctx.Err()
+		}
+	})
+
+	startHTTPServer(app, cfg, lc)
+
+	// Wait for interrupt signal to gracefully shutdown the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+
+	// Graceful shutdown, finishing in-flight work within the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	for _, result := range lc.Shutdown(ctx) {
+		log.Println(result)
+	}
+
+	log.Println("Server exited")
+	return nil
+}
+
+// newApp creates the HTTP app tuned by the server settings, with the middleware applied to
+// every request. Routes are registered separately.
+func newApp(cfg *config.Config, deps *container.Container) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ReadTimeout:     cfg.Server.ReadTimeout,
+		WriteTimeout:    cfg.Server.WriteTimeout,
+		IdleTimeout:     cfg.Server.IdleTimeout,
+		Concurrency:     cfg.Server.MaxConnections,
+		ReadBufferSize:  cfg.Server.ReadBufferSize,
+		WriteBufferSize: cfg.Server.WriteBufferSize,
+		BodyLimit:       cfg.Limits.MaxBodySize,
+		ErrorHandler:    customErrorHandler,
+	})
+
+	// Every request is timed, including the middleware below, and slow ones are logged
+	durations := deps.Registry.NewHistogramVec("http_request_duration_seconds",
+		"Duration of HTTP requests in seconds, by method and route.", metrics.DefaultBuckets, "method", "route")
+	app.Use(middleware.RequestTiming(cfg.Server.SlowRequestThreshold, durations))
+	app.Use(requestid.New())
+	// Requests are logged as JSON lines at the info level
+	if slices.Index(config.LogLevels, cfg.App.LogLevel) <= slices.Index(config.LogLevels, "info") {
+		app.Use(middleware.AccessLog(redact.NewWriter(os.Stdout), cfg.App.AccessLogSampleRate))
+	}
+	app.Use(recover.New())
+	app.Use(middleware.ResolveClientIP(cfg.IP.TrustedProxies))
+	if len(cfg.IP.Allow) > 0 || len(cfg.IP.Deny) > 0 {
+		app.Use(middleware.IPFilter(cfg.IP.Allow, cfg.IP.Deny))
+	}
+	// Services authenticate with client certificates on the configured paths, or every path
+	if cfg.TLS.ClientAuth() {
+		paths := cfg.TLS.ClientAuthPaths
+		if len(paths) == 0 {
+			paths = []string{"/"}
+		}
+		for _, path := range paths {
+			app.Use(path, middleware.RequireClientCert(cfg.TLS.ClientIdentities))
+		}
+	}
+	// Cross-origin requests are only answered when origins are configured
+	if len(cfg.CORS.AllowOrigins) > 0 {
+		app.Use(cors.New(cors.Config{
+			AllowOrigins:     strings.Join(cfg.CORS.AllowOrigins, ","),
+			AllowHeaders:     strings.Join(cfg.CORS.AllowHeaders, ","),
+			AllowMethods:     strings.Join(cfg.CORS.AllowMethods, ","),
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           int(cfg.CORS.MaxAge.Seconds()),
+		}))
+	}
+	return app
+}
+
+// startHTTPServer starts serving the app, over TLS when configured, along with the server
+// redirecting plain HTTP to HTTPS when enabled. The servers are stopped on shutdown.
+func startHTTPServer(app *fiber.App, cfg *config.Config, lc *lifecycle.Manager) {
+	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
+	lc.Register("HTTP server", func(ctx context.Context) (int, error) {
+		return 0, app.ShutdownWithContext(ctx)
+	})
+
+	if !cfg.TLS.Enabled() {
+		go func() {
+			log.Printf("Server starting on %s", addr)
+			if err := app.Listen(addr); err != nil {
+				log.Fatalf("Failed to start server: %v", err)
+			}
+		}()
+		return
+	}
+
+	tlsConfig, manager, err := httpserver.NewTLSConfig(cfg.TLS)
+	if err != nil {
+		log.Fatalf("Failed to configure TLS: %v", err)
+	}
+	go func() {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("Failed to listen: %v", err)
+		}
+		log.Printf("Server starting on %s with TLS", addr)
+		if err := app.Listener(tls.NewListener(ln, tlsConfig)); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	if cfg.TLS.RedirectPort == "" {
+		return
+	}
+	redirectSrv := &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.TLS.RedirectPort),
+		Handler:           httpserver.RedirectHandler(cfg.Server.Port, manager),
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	go func() {
+		log.Printf("HTTP redirect server starting on %s", redirectSrv.Addr)
+		if err := redirectSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start HTTP redirect server: %v", err)
+		}
+	}()
+	lc.Register("HTTP redirect server", func(ctx context.Context) (int, error) {
+		return 0, redirectSrv.Shutdown(ctx)
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"todo-api/internal/container"
+	"todo-api/internal/lifecycle"
+	"todo-api/pkg/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+// BenchmarkServer measures requests served over real connections with the default server
+// settings and with larger connection buffers. Request logs are disabled in both, as they
+// would flood the output and are disabled in high-RPS deployments.
+func BenchmarkServer(b *testing.B) {
+	settings := []struct {
+		name      string
+		overrides map[string]string
+	}{
+		{"default", map[string]string{"LOG_LEVEL": "warn"}},
+		{"large_buffers", map[string]string{
+			"LOG_LEVEL":                "warn",
+			"SERVER_READ_BUFFER_SIZE":  "16384",
+			"SERVER_WRITE_BUFFER_SIZE": "16384",
+		}},
+	}
+	requests := []struct {
+		name string
+		path string
+		auth bool
+	}{
+		{"health", "/health", false},
+		{"list_tasks", "/api/v2/tasks", true},
+	}
+
+	for _, s := range settings {
+		baseURL, token := startBenchmarkServer(b, s.overrides)
+		client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 256}}
+		for _, r := range requests {
+			b.Run(s.name+"/"+r.name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						req, _ := http.NewRequest(http.MethodGet, baseURL+r.path, nil)
+						if r.auth {
+							req.Header.Set("Authorization", "Bearer "+token)
+						}
+						resp, err := client.Do(req)
+						if err != nil {
+							b.Error(err)
+							return
+						}
+						io.Copy(io.Discard, resp.Body)
+						resp.Body.Close()
+						if resp.StatusCode != http.StatusOK {
+							b.Errorf("GET %s: status %d", r.path, resp.StatusCode)
+							return
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
+// startBenchmarkServer serves the API with the settings on a free local port until the
+// benchmark ends, returning its base URL and an access token of John
+func startBenchmarkServer(b *testing.B, overrides map[string]string) (string, string) {
+	cfg, err := config.LoadWithOverrides(overrides)
+	require.NoError(b, err)
+
+	lc := lifecycle.NewManager()
+	b.Cleanup(func() { lc.Shutdown(context.Background()) })
+	deps, err := container.New(cfg, lc)
+	require.NoError(b, err)
+
+	app := newApp(cfg, deps)
+	setupRoutes(app, cfg, deps)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(b, err)
+	go app.Listener(ln)
+	b.Cleanup(func() { app.Shutdown() })
+
+	baseURL := "http://" + ln.Addr().String()
+	resp, err := http.Post(baseURL+"/api/v2/auth/login", "application/json",
+		strings.NewReader(`{"email":"john.doe@example.com","password":"password123"}`))
+	require.NoError(b, err)
+	defer resp.Body.Close()
+	require.Equal(b, http.StatusOK, resp.StatusCode)
+
+	var login struct {
+		Data struct {
+			AccessToken string `json:"access_token"`
+		} `json:"data"`
+	}
+	require.NoError(b, json.NewDecoder(resp.Body).Decode(&login))
+	return baseURL, login.Data.AccessToken
+}
@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache defines a cache of serialized values. Values are stored under a key within a group,
+// such as the listings of a user, and groups are invalidated as a whole when what they
+// were computed from changes. A group expires the TTL after its first value was stored,
+// which bounds how stale its values may get when an invalidation is missed.
+//
+// Caches are an optimization: callers fall back to the source of the values when they fail.
+type Cache interface {
+	// Get returns the value of the key in the group, and whether it was found
+	Get(ctx context.Context, group, key string) ([]byte, bool, error)
+	Set(ctx context.Context, group, key string, value []byte, ttl time.Duration) error
+	// Invalidate removes the values of the groups
+	Invalidate(ctx context.Context, groups ...string) error
+}
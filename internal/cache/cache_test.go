@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/pkg/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startRedis starts a server answering the hash commands the cache uses from memory, without
+// expiring keys. It returns the expiries set, in milliseconds by key.
+func startRedis(t *testing.T) (*redis.Client, map[string]string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	var mu sync.Mutex
+	hashes := make(map[string]map[string]string)
+	expiries := make(map[string]string)
+	handle := func(args []string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch args[0] {
+		case "HGET":
+			value, ok := hashes[args[1]][args[2]]
+			if !ok {
+				return "$-1\r\n"
+			}
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		case "HSET":
+			if hashes[args[1]] == nil {
+				hashes[args[1]] = make(map[string]string)
+			}
+			hashes[args[1]][args[2]] = args[3]
+			return ":1\r\n"
+		case "PEXPIRE":
+			if _, ok := expiries[args[1]]; ok && args[3] == "NX" {
+				return ":0\r\n"
+			}
+			expiries[args[1]] = args[2]
+			return ":1\r\n"
+		case "DEL":
+			for _, key := range args[1:] {
+				delete(hashes, key)
+				delete(expiries, key)
+			}
+			return fmt.Sprintf(":%d\r\n", len(args)-1)
+		default:
+			return "-ERR unknown command\r\n"
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+					args := make([]string, n)
+					for i := range args {
+						r.ReadString('\n')
+						arg, _ := r.ReadString('\n')
+						args[i] = strings.TrimSuffix(arg, "\r\n")
+					}
+					if _, err := io.WriteString(conn, handle(args)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	client := redis.NewClient(redis.Config{Addr: lis.Addr().String(), Timeout: time.Second})
+	t.Cleanup(func() { client.Close() })
+	return client, expiries
+}
+
+func TestCaches(t *testing.T) {
+	client, _ := startRedis(t)
+	caches := map[string]Cache{
+		"memory": NewMemoryCache(),
+		"redis":  NewRedisCache(client),
+	}
+
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, found, err := c.Get(ctx, "tasks:john", "page1")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			require.NoError(t, c.Set(ctx, "tasks:john", "page1", []byte(`[{"id":1}]`), time.Minute))
+			require.NoError(t, c.Set(ctx, "tasks:jane", "page1", []byte(`[]`), time.Minute))
+			value, found, err := c.Get(ctx, "tasks:john", "page1")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, `[{"id":1}]`, string(value))
+
+			// Invalidating a group leaves the others
+			require.NoError(t, c.Invalidate(ctx, "tasks:john", "tasks:unknown"))
+			_, found, _ = c.Get(ctx, "tasks:john", "page1")
+			assert.False(t, found)
+			_, found, _ = c.Get(ctx, "tasks:jane", "page1")
+			assert.True(t, found)
+			require.NoError(t, c.Invalidate(ctx))
+		})
+	}
+}
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "task:1", "john", []byte("a"), 20*time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+	// Later values expire with the group
+	require.NoError(t, c.Set(ctx, "task:1", "jane", []byte("b"), time.Hour))
+	time.Sleep(15 * time.Millisecond)
+
+	_, found, _ := c.Get(ctx, "task:1", "jane")
+	assert.False(t, found)
+}
+
+func TestRedisCache_Expiry(t *testing.T) {
+	client, expiries := startRedis(t)
+	c := NewRedisCache(client)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "task:1", "john", []byte("a"), time.Minute))
+	require.NoError(t, c.Set(ctx, "task:1", "jane", []byte("b"), time.Hour))
+	assert.Equal(t, "60000", expiries["cache:task:1"])
+}
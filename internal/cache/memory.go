@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryGroup is a group of cached values with its expiry
+type memoryGroup struct {
+	values    map[string][]byte
+	expiresAt time.Time
+}
+
+// memoryCache implements a cache kept in the memory of the process
+type memoryCache struct {
+	mu     sync.Mutex
+	groups map[string]*memoryGroup
+}
+
+// NewMemoryCache creates a cache kept in memory, for a single instance of the server
+func NewMemoryCache() Cache {
+	return &memoryCache{groups: make(map[string]*memoryGroup)}
+}
+
+// Get returns the value of the key in the group, unless the group expired
+func (c *memoryCache) Get(ctx context.Context, group, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[group]
+	if !exists {
+		return nil, false, nil
+	}
+	if !time.Now().Before(g.expiresAt) {
+		delete(c.groups, group)
+		return nil, false, nil
+	}
+	value, found := g.values[key]
+	return value, found, nil
+}
+
+// Set stores the value, starting the expiry of the group if it is new
+func (c *memoryCache) Set(ctx context.Context, group, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	g, exists := c.groups[group]
+	if !exists || !now.Before(g.expiresAt) {
+		g = &memoryGroup{values: make(map[string][]byte), expiresAt: now.Add(ttl)}
+		c.groups[group] = g
+		c.prune(now)
+	}
+	g.values[key] = value
+	return nil
+}
+
+// Invalidate removes the groups
+func (c *memoryCache) Invalidate(ctx context.Context, groups ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, group := range groups {
+		delete(c.groups, group)
+	}
+	return nil
+}
+
+// prune removes the expired groups. The caller must hold the lock.
+func (c *memoryCache) prune(now time.Time) {
+	for name, g := range c.groups {
+		if !now.Before(g.expiresAt) {
+			delete(c.groups, name)
+		}
+	}
+}
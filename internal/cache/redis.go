@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"todo-api/pkg/redis"
+)
+
+// redisKeyPrefix namespaces the keys of the cache in a Redis database shared with other data
+const redisKeyPrefix = "cache:"
+
+// redisCache implements a cache shared by the instances of the server in Redis. Each group
+// is a hash, so invalidating a group deletes a single key.
+type redisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a cache stored in Redis. Redis 7 or later is required.
+func NewRedisCache(client *redis.Client) Cache {
+	return &redisCache{client: client}
+}
+
+// Get returns the value of the key in the group
+func (c *redisCache) Get(ctx context.Context, group, key string) ([]byte, bool, error) {
+	reply, err := c.client.Do(ctx, "HGET", redisKeyPrefix+group, key)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	return value, ok, nil
+}
+
+// Set stores the value, starting the expiry of the group if it has none
+func (c *redisCache) Set(ctx context.Context, group, key string, value []byte, ttl time.Duration) error {
+	if _, err := c.client.Do(ctx, "HSET", redisKeyPrefix+group, key, string(value)); err != nil {
+		return err
+	}
+	// NX keeps the expiry set by the first value of the group
+	_, err := c.client.Do(ctx, "PEXPIRE", redisKeyPrefix+group, strconv.FormatInt(ttl.Milliseconds(), 10), "NX")
+	return err
+}
+
+// Invalidate deletes the groups
+func (c *redisCache) Invalidate(ctx context.Context, groups ...string) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	args := make([]string, 0, len(groups)+1)
+	args = append(args, "DEL")
+	for _, group := range groups {
+		args = append(args, redisKeyPrefix+group)
+	}
+	_, err := c.client.Do(ctx, args...)
+	return err
+}
@@ -0,0 +1,635 @@
+// Package container is the composition root of the server: it creates every service,
+// handler, and shared middleware once, in dependency order, and registers the components
+// with background work to be stopped on shutdown.
+package container
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"todo-api/internal/cache"
+	deviceDomain "todo-api/internal/domain/device"
+	integrationDomain "todo-api/internal/domain/integration"
+	taskDomain "todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	attachmentHandler "todo-api/internal/handler/attachment"
+	auditHandler "todo-api/internal/handler/audit"
+	authHandler "todo-api/internal/handler/auth"
+	automationHandler "todo-api/internal/handler/automation"
+	billingHandler "todo-api/internal/handler/billing"
+	escalationHandler "todo-api/internal/handler/escalation"
+	graphqlHandler "todo-api/internal/handler/graphql"
+	integrationHandler "todo-api/internal/handler/integration"
+	jobHandler "todo-api/internal/handler/job"
+	meHandler "todo-api/internal/handler/me"
+	reportHandler "todo-api/internal/handler/report"
+	scimHandler "todo-api/internal/handler/scim"
+	taskHandler "todo-api/internal/handler/task"
+	tenantHandler "todo-api/internal/handler/tenant"
+	workspaceHandler "todo-api/internal/handler/workspace"
+	"todo-api/internal/health"
+	"todo-api/internal/jobs"
+	"todo-api/internal/lifecycle"
+	"todo-api/internal/metrics"
+	"todo-api/internal/middleware"
+	"todo-api/internal/scheduler"
+	"todo-api/internal/search"
+	"todo-api/internal/seed"
+	attachmentService "todo-api/internal/service/attachment"
+	auditService "todo-api/internal/service/audit"
+	authService "todo-api/internal/service/auth"
+	automationService "todo-api/internal/service/automation"
+	billingService "todo-api/internal/service/billing"
+	deviceService "todo-api/internal/service/device"
+	escalationService "todo-api/internal/service/escalation"
+	integrationService "todo-api/internal/service/integration"
+	loginService "todo-api/internal/service/login"
+	loginGuardService "todo-api/internal/service/loginguard"
+	notificationService "todo-api/internal/service/notification"
+	privacyService "todo-api/internal/service/privacy"
+	reportService "todo-api/internal/service/report"
+	scimService "todo-api/internal/service/scim"
+	taskService "todo-api/internal/service/task"
+	tenantService "todo-api/internal/service/tenant"
+	warehouseService "todo-api/internal/service/warehouse"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+	"todo-api/pkg/push"
+	"todo-api/pkg/resilience"
+	"todo-api/pkg/slack"
+	"todo-api/pkg/warehouse"
+	"todo-api/pkg/webhook"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Container holds the components of the server. Each is created once and shared for the
+// lifetime of the process, so state kept in memory, such as users and tasks, is the same
+// for every handler, middleware, and transport.
+type Container struct {
+	Config    *config.Config
+	Bus       events.Bus
+	JobQueue  jobs.Queue
+	Scheduler *scheduler.Scheduler
+	Registry  *metrics.Registry
+	// Health checks the dependencies reported by the health endpoint
+	Health *health.Checker
+	// Resilience holds the breakers of external integrations, retrying their failed calls
+	Resilience *resilience.Group
+
+	Services Services
+	Handlers Handlers
+
+	// Authenticate authenticates requests with a token
+	Authenticate fiber.Handler
+	// AuthenticateAPIKey authenticates requests with an API key or a token
+	AuthenticateAPIKey fiber.Handler
+	// AuthenticateWebSocket authenticates WebSocket upgrade requests
+	AuthenticateWebSocket fiber.Handler
+	// ResolveTenant isolates authenticated requests to the caller's tenant
+	ResolveTenant fiber.Handler
+	// LimitPublic rate limits unauthenticated public endpoints, shared by every API version
+	LimitPublic fiber.Handler
+}
+
+// Services holds the services shared by the HTTP and gRPC transports. Optional services
+// are nil when they are not configured.
+type Services struct {
+	Auth          authService.Service
+	Tenants       tenantService.Service
+	Workspaces    workspaceService.Service
+	Billing       billingService.Service
+	Tasks         taskService.Service
+	Privacy       privacyService.Service
+	Audit         auditService.Service
+	SCIM          scimService.Service
+	LoginGuard    loginGuardService.Service
+	Login         loginService.Service // throttles and audits logins of both transports
+	Slack         integrationService.SlackService
+	Telegram      integrationService.TelegramService
+	GitHub        integrationService.GitHubService
+	Calendar      integrationService.GoogleCalendarService
+	SMS           integrationService.SMSService   // nil without a Twilio account
+	Email         integrationService.EmailService // nil without an email-in domain
+	Devices       deviceService.Service
+	Attachments   attachmentService.Service // nil without attachment storage
+	Notifications notificationService.Service
+	Escalations   escalationService.Service
+	Automations   automationService.Service
+	Reports       reportService.Service
+	Warehouse     warehouseService.Service // nil without a warehouse sink
+}
+
+// Handlers holds the HTTP handlers
+type Handlers struct {
+	Auth        *authHandler.Handler
+	Tasks       *taskHandler.Handler
+	Workspaces  *workspaceHandler.Handler
+	Tenants     *tenantHandler.Handler
+	Me          *meHandler.Handler
+	Audit       *auditHandler.Handler
+	Integration *integrationHandler.Handler
+	Attachments *attachmentHandler.Handler
+	Escalations *escalationHandler.Handler
+	Automations *automationHandler.Handler
+	Reports     *reportHandler.Handler
+	Billing     *billingHandler.Handler
+	Jobs        *jobHandler.Handler
+	GraphQL     *graphqlHandler.Handler
+	SCIM        *scimHandler.Handler // nil when SCIM is disabled
+}
+
+// New creates the components of the server from the configuration. Components with
+// background work, such as the event bus, the job queue, and the scheduler, are started
+// and registered with the lifecycle manager, which stops them on shutdown after the
+// components registered later, such as the servers.
+func New(cfg *config.Config, lc *lifecycle.Manager) (*Container, error) {
+	c := &Container{Config: cfg}
+	c.Health = health.NewChecker(cfg.Health.Timeout, cfg.Health.Interval)
+
+	// In-process event bus shared by domain event producers and consumers
+	c.Bus = events.NewChannelBus(events.DefaultBufferSize)
+	bus := c.Bus
+	lc.Register("event bus", func(ctx context.Context) (int, error) {
+		return bus.Shutdown(ctx), nil
+	})
+
+	// Task and auth events streamed to the configured broker through an outbox, relayed
+	// until shutdown and then flushed
+	if publisher := cfg.Stream.NewPublisher(); publisher != nil {
+		outbox := events.NewOutbox(cfg.Stream.OutboxLimit)
+		c.Bus = events.WithOutbox(c.Bus, outbox)
+		lc.Register("event stream", func(ctx context.Context) (int, error) {
+			defer publisher.Close()
+			return outbox.Flush(ctx, publisher, cfg.Stream.BatchSize), nil
+		})
+		lc.Go("event stream relay", func(ctx context.Context) {
+			outbox.Relay(ctx, publisher, cfg.Stream.BatchSize, cfg.Stream.MaxBackoff)
+		})
+	}
+
+	// Domain events exported to the configured data warehouse through an outbox of their
+	// own, relayed until shutdown and then flushed
+	sink, err := cfg.Warehouse.NewSink()
+	if err != nil {
+		return nil, err
+	}
+	if sink != nil && cfg.Warehouse.Events {
+		publisher := warehouse.NewPublisher(sink)
+		outbox := events.NewOutbox(cfg.Warehouse.OutboxLimit)
+		c.Bus = events.WithOutbox(c.Bus, outbox)
+		lc.Register("warehouse export", func(ctx context.Context) (int, error) {
+			defer publisher.Close()
+			return outbox.Flush(ctx, publisher, cfg.Warehouse.BatchSize), nil
+		})
+		lc.Go("warehouse export relay", func(ctx context.Context) {
+			outbox.Relay(ctx, publisher, cfg.Warehouse.BatchSize, cfg.Warehouse.MaxBackoff)
+		})
+	}
+
+	// Background jobs such as webhook events, imports, reminders, and digests, retried on
+	// failure and run until shutdown
+	jobQueue := jobs.NewMemoryQueue(jobs.Config{
+		Workers:         cfg.Jobs.Workers,
+		MaxAttempts:     cfg.Jobs.MaxAttempts,
+		RetryBackoff:    cfg.Jobs.RetryBackoff,
+		MaxBackoff:      cfg.Jobs.MaxBackoff,
+		DeadLetterLimit: cfg.Jobs.DeadLetterLimit,
+		Retention:       cfg.Jobs.Retention,
+	})
+	c.JobQueue = jobQueue
+	lc.Register("job queue", func(ctx context.Context) (int, error) {
+		return jobQueue.Shutdown(ctx), nil
+	})
+	c.Health.Add("queue", true, func(context.Context) error {
+		return jobQueue.Ping()
+	})
+
+	// Hot task reads cached in memory or in Redis, invalidated by task changes
+	var taskCache cache.Cache
+	switch cfg.Cache.Driver {
+	case "memory":
+		taskCache = cache.NewMemoryCache()
+		c.Health.Add("cache", false, func(context.Context) error {
+			return nil
+		})
+	case "redis":
+		redisClient := cfg.Redis.NewClient()
+		taskCache = cache.NewRedisCache(redisClient)
+		lc.Register("redis", func(ctx context.Context) (int, error) {
+			return 0, redisClient.Close()
+		})
+		// Task reads fall back to storage while Redis is down, so it is not critical
+		c.Health.Add("cache", false, func(ctx context.Context) error {
+			_, err := redisClient.Do(ctx, "PING")
+			return err
+		})
+	}
+
+	if err := c.newServices(taskCache, sink); err != nil {
+		return nil, err
+	}
+	if cfg.Storage.SeedFile != "" {
+		if err := c.seedStorage(cfg.Storage.SeedFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.newScheduler(lc); err != nil {
+		return nil, err
+	}
+	if err := c.newHandlers(); err != nil {
+		return nil, err
+	}
+
+	// Middleware shared by the routes, authenticating with the same auth service as the
+	// handlers
+	c.Authenticate = middleware.AuthMiddleware(c.Services.Auth)
+	c.AuthenticateAPIKey = middleware.APIKeyAuth(c.Services.Auth, c.Services.Automations)
+	c.AuthenticateWebSocket = middleware.WebSocketAuthMiddleware(c.Services.Auth)
+	c.ResolveTenant = middleware.Tenant(c.Services.Tenants, cfg.Server.TenantBaseDomain)
+	c.LimitPublic = middleware.RateLimit(cfg.Server.PublicRateLimit, time.Minute)
+
+	return c, nil
+}
+
+// newServices creates the services, snapshotting tasks into the warehouse sink when set
+func (c *Container) newServices(taskCache cache.Cache, sink warehouse.Sink) error {
+	cfg := c.Config
+	s := &c.Services
+
+	// Prometheus metrics
+	c.Registry = metrics.NewRegistry()
+
+	// Calls to external integrations retried and stopped by breakers as configured, with the
+	// state of the breakers and the result of each attempt exposed as metrics
+	circuitStates := c.Registry.NewGaugeVec("external_circuit_state",
+		"State of the breaker of each external integration: 0 closed, 1 half-open, 2 open.", "integration")
+	externalRequests := c.Registry.NewCounterVec("external_requests_total",
+		"Attempted calls to external integrations by result: success, failure, or rejected by an open breaker.",
+		"integration", "result")
+	c.Resilience = resilience.NewGroupWithHooks(cfg.Resilience.Policy(), resilience.Hooks{
+		StateChanged: func(integration string, state resilience.State) {
+			circuitStates.Set(float64(state), integration)
+		},
+		Called: func(integration, result string) {
+			externalRequests.Inc(integration, result)
+		},
+	})
+
+	// Storage seeded from a fixture file starts without the default users, and so without the
+	// default tasks
+	if cfg.Storage.SeedFile != "" {
+		s.Auth = authService.NewServiceWithUsers(cfg, nil)
+	} else {
+		s.Auth = authService.NewService(cfg)
+	}
+	s.Tenants = tenantService.NewService(s.Auth)
+	s.Workspaces = workspaceService.NewServiceWithTenants(s.Auth, s.Tenants)
+
+	// Free and pro plans paid through Stripe. Plan limits are only enforced when billing is enabled.
+	s.Billing = billingService.NewServiceWithJobs(cfg, s.Auth, cfg.Billing.NewClient(c.Resilience.Transport("stripe", nil)), c.JobQueue)
+	tasks, err := c.newTaskService(taskCache)
+	if err != nil {
+		return err
+	}
+	s.Tasks = tasks
+	c.Health.Add("storage", true, func(context.Context) error {
+		return tasks.CheckStorage()
+	})
+
+	s.Audit = auditService.NewServiceWithEventBus(c.Bus)
+
+	s.LoginGuard = loginGuardService.NewService(cfg.Login, c.Registry)
+	s.Login = loginService.NewService(loginService.Deps{Auth: s.Auth, Audit: s.Audit, Guard: s.LoginGuard})
+
+	// Slack messages about task events, and slash commands sent from Slack
+	slackClient := slack.NewClientWithTransport(cfg.Slack.APIURL, cfg.Slack.Timeout, c.Resilience.Transport("slack", nil))
+	s.Slack = integrationService.NewSlackServiceWithDeps(integrationService.SlackDeps{
+		Config: cfg,
+		Auth:   s.Auth,
+		Tasks:  s.Tasks,
+		Client: slackClient,
+		Bus:    c.Bus,
+		Plans:  s.Billing,
+		Allows: s.allowsNotification,
+	})
+
+	// Telegram bot managing tasks of linked chats
+	s.Telegram = integrationService.NewTelegramServiceWithPlans(cfg, s.Auth, s.Tasks, s.Billing)
+
+	// Projects synced with GitHub issues at the configured interval
+	s.GitHub = integrationService.NewGitHubServiceWithDeps(integrationService.GitHubDeps{
+		Config:     cfg,
+		Tasks:      s.Tasks,
+		Workspaces: s.Workspaces,
+		Client:     cfg.GitHub.NewClient(c.Resilience.Transport("github", nil)),
+		Bus:        c.Bus,
+		Plans:      s.Billing,
+		Jobs:       c.JobQueue,
+	})
+
+	// Tasks with due dates synced with the Google Calendar of their users at the configured interval
+	s.Calendar = integrationService.NewGoogleCalendarServiceWithPlans(cfg, s.Tasks, cfg.Calendar.NewClient(c.Resilience.Transport("google_calendar", nil)), s.Billing)
+
+	// Integrations counted against the plan limits
+	s.Billing.RegisterIntegration(integrationDomain.NameSlack, func(userID uuid.UUID) bool {
+		_, err := s.Slack.GetSlackConnection(userID)
+		return err == nil
+	})
+	s.Billing.RegisterIntegration(integrationDomain.NameTelegram, func(userID uuid.UUID) bool {
+		_, err := s.Telegram.GetTelegramLink(userID)
+		return err == nil
+	})
+	s.Billing.RegisterIntegration(integrationDomain.NameGitHub, func(userID uuid.UUID) bool {
+		_, err := s.GitHub.GetGitHubAccount(userID)
+		return err == nil
+	})
+	s.Billing.RegisterIntegration(integrationDomain.NameGoogleCalendar, func(userID uuid.UUID) bool {
+		_, err := s.Calendar.GetGoogleCalendar(userID)
+		return err == nil
+	})
+
+	// Task attachments, uploaded to and downloaded from object storage with presigned URLs,
+	// with thumbnails of images generated by jobs. Attachments are disabled without storage.
+	attachmentStore, err := cfg.Files.NewStore(c.Resilience.Transport("attachments", nil))
+	if err != nil {
+		return fmt.Errorf("failed to configure attachment storage: %w", err)
+	}
+	if attachmentStore != nil {
+		s.Attachments = attachmentService.NewServiceWithDeps(attachmentService.Deps{
+			Config:  cfg.Files,
+			Tasks:   s.Tasks,
+			Store:   attachmentStore,
+			Bus:     c.Bus,
+			Plans:   s.Billing,
+			Scanner: cfg.Files.NewScanner(),
+			Jobs:    c.JobQueue,
+		})
+	}
+
+	// Data exports, archived in the attachment storage in the background. Archives are
+	// unavailable without storage.
+	s.Privacy = privacyService.NewServiceWithDeps(privacyService.Deps{
+		Auth:        s.Auth,
+		Tasks:       s.Tasks,
+		Workspaces:  s.Workspaces,
+		Attachments: s.Attachments,
+		Store:       attachmentStore,
+		Jobs:        c.JobQueue,
+		ArchiveTTL:  cfg.Account.ExportTTL,
+	})
+
+	// Users provisioned and deprovisioned by identity providers over SCIM
+	s.SCIM = scimService.NewService(cfg, s.Auth, s.Privacy)
+
+	// Tasks created from emails received by Mailgun or SES, with their attachments attached
+	// when attachments are enabled. Email-in is disabled without a domain.
+	if cfg.Inbound.Enabled() {
+		s.Email = integrationService.NewEmailServiceWithPlans(cfg, s.Auth, s.Tasks, s.Attachments,
+			c.Resilience.Transport("sns", nil), s.Billing)
+		s.Billing.RegisterIntegration(integrationDomain.NameEmail, func(userID uuid.UUID) bool {
+			_, err := s.Email.GetEmailAddress(userID)
+			return err == nil
+		})
+	}
+
+	// Push notifications to registered mobile devices
+	androidSender, iosSender, err := cfg.Push.NewSenders()
+	if err != nil {
+		return fmt.Errorf("failed to configure push notifications: %w", err)
+	}
+	s.Devices = deviceService.NewServiceWithFilter(map[string]push.Sender{
+		deviceDomain.PlatformAndroid: androidSender,
+		deviceDomain.PlatformIOS:     iosSender,
+	}, c.Bus, s.allowsNotification)
+
+	// SMS reminders of high-priority tasks sent through Twilio to verified phone numbers.
+	// SMS is disabled unless the Twilio account is configured.
+	if cfg.Twilio.Enabled() {
+		s.SMS = integrationService.NewSMSService(cfg, cfg.Twilio.NewClient(c.Resilience.Transport("twilio", nil)))
+	}
+
+	// Account, reminder, and digest emails. Reminders are also posted to Slack, pushed to
+	// devices, and texted.
+	channels := []notificationService.Channel{s.Slack, s.Devices}
+	if s.SMS != nil {
+		channels = append(channels, s.SMS)
+	}
+	mail := cfg.Mail.NewMailer()
+	s.Notifications = notificationService.NewServiceWithDeps(notificationService.Deps{
+		Config:   cfg,
+		Auth:     s.Auth,
+		Tasks:    s.Tasks,
+		Mailer:   mailer.NewGuardedMailer(mail, c.Resilience.Guard("mail")),
+		Channels: channels,
+		Jobs:     c.JobQueue,
+	})
+	// Only emails depend on the mail server, so it is not critical
+	c.Health.Add("mailer", false, func(ctx context.Context) error {
+		if pinger, ok := mail.(mailer.Pinger); ok {
+			return pinger.Ping(ctx)
+		}
+		return nil
+	})
+
+	// Escalation rules emailing about overdue workspace tasks
+	s.Escalations = escalationService.NewService(s.Auth, s.Tasks, s.Workspaces, s.Notifications)
+
+	// API keys and polling triggers of automation platforms such as Zapier and IFTTT, and
+	// automation rules run on task events
+	s.Automations = automationService.NewServiceWithDeps(automationService.Deps{Config: cfg, Auth: s.Auth, Tasks: s.Tasks, Bus: c.Bus, Jobs: c.JobQueue})
+
+	// Project burndowns aggregated nightly, and task statistics pushed to stats webhooks
+	s.Reports = reportService.NewServiceWithDeps(reportService.Deps{
+		Tasks:      s.Tasks,
+		Workspaces: s.Workspaces,
+		Client:     webhook.NewClient(cfg.Reports.WebhookTimeout),
+		Jobs:       c.JobQueue,
+	})
+
+	// Data kept about users outside of their accounts and tasks, erased with their accounts
+	erasers := []privacyService.Eraser{s.Slack, s.Telegram, s.GitHub, s.Calendar, s.Devices, s.Automations, s.Reports}
+	if s.SMS != nil {
+		erasers = append(erasers, s.SMS)
+	}
+	if s.Email != nil {
+		erasers = append(erasers, s.Email)
+	}
+	for _, eraser := range erasers {
+		s.Privacy.RegisterEraser(eraser)
+	}
+
+	// Snapshots of every task exported to the data warehouse, when one is configured
+	if sink != nil {
+		s.Warehouse = warehouseService.NewService(s.Tasks, sink, cfg.Warehouse.BatchSize)
+	}
+	return nil
+}
+
+// allowsNotification reports whether the user wants notifications of the event through the
+// channel. Slack and devices notify users of task events by it, though they are created
+// before the notification service holding the settings.
+func (s *Services) allowsNotification(userID uuid.UUID, event, channel string) bool {
+	return s.Notifications == nil || s.Notifications.Allows(userID, event, channel)
+}
+
+// seedStorage creates the fixture users and tasks of the file through the services, so their
+// events reach every subscriber like changes made through the API
+func (c *Container) seedStorage(path string) error {
+	fixtures, err := seed.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load fixtures: %w", err)
+	}
+	if err := fixtures.Apply(c.Services.Auth, c.Services.Tenants, c.Services.Tasks); err != nil {
+		return fmt.Errorf("failed to seed storage from %s: %w", path, err)
+	}
+	log.Printf("Seeded storage with %d users and %d tasks from %s", len(fixtures.Users), len(fixtures.Tasks), path)
+	return nil
+}
+
+// newTaskService creates the task service with the configured search engine, caching reads
+// when a cache is given
+func (c *Container) newTaskService(taskCache cache.Cache) (taskService.Service, error) {
+	cfg := c.Config
+	s := &c.Services
+
+	// Per-user limits are lowered by the plan of each user when billing is enabled
+	var limits taskService.LimitsDirectory = taskDomain.Limits{MaxTasks: cfg.Limits.MaxTasksPerUser}
+	if cfg.Billing.Enabled() {
+		limits = s.Billing
+	}
+
+	var index search.Index // nil searches in memory
+	switch cfg.Search.Engine {
+	case "memory":
+	case "elasticsearch", "opensearch":
+		index = search.NewElasticsearchIndex(search.ElasticsearchConfig{
+			URL:      cfg.Search.ElasticsearchURL,
+			Index:    cfg.Search.ElasticsearchIndex,
+			Username: cfg.Search.ElasticsearchUsername,
+			Password: cfg.Search.ElasticsearchPassword,
+			Timeout:  cfg.Search.ElasticsearchTimeout,
+		}, taskService.SearchFieldWeights)
+	default:
+		return nil, fmt.Errorf("unknown search engine: %s", cfg.Search.Engine)
+	}
+
+	// Tasks stored across every user are capped, so a misbehaving client cannot exhaust memory
+	budget := taskDomain.StorageBudget{MaxTasks: cfg.Storage.MaxTasks, MaxBytes: cfg.Storage.MaxBytes}
+	return taskService.NewServiceWithDeps(taskService.Deps{
+		Auth:          s.Auth,
+		Bus:           c.Bus,
+		Workspaces:    s.Workspaces,
+		Tenants:       s.Tenants,
+		Limits:        limits,
+		Index:         index,
+		Cache:         taskCache,
+		CacheTTL:      cfg.Cache.TTL,
+		StorageBudget: budget,
+		Registry:      c.Registry,
+	}), nil
+}
+
+// newScheduler creates the scheduler of recurring jobs such as reminders, digests, and
+// syncs, run on their schedules until shutdown. Platform admins see how they ran.
+func (c *Container) newScheduler(lc *lifecycle.Manager) error {
+	cfg := c.Config
+	s := &c.Services
+
+	c.Scheduler = scheduler.New()
+	if interval := cfg.Notify.ReminderInterval; interval > 0 {
+		c.Scheduler.Add("reminders", scheduler.Every(interval), s.Notifications.RunReminders)
+	}
+	switch {
+	case cfg.Notify.DigestSchedule != "":
+		digests, err := scheduler.Cron(cfg.Notify.DigestSchedule)
+		if err != nil {
+			return fmt.Errorf("failed to schedule digests: %w", err)
+		}
+		c.Scheduler.Add("digests", digests, s.Notifications.RunDigests)
+	case cfg.Notify.DigestInterval > 0:
+		c.Scheduler.Add("digests", scheduler.Every(cfg.Notify.DigestInterval), s.Notifications.RunDigests)
+	}
+	if interval := cfg.Notify.EscalationInterval; interval > 0 {
+		c.Scheduler.Add("escalations", scheduler.Every(interval), s.Escalations.Run)
+	}
+	if interval := cfg.Search.ReconcileInterval; cfg.Search.Engine != "memory" && interval > 0 {
+		c.Scheduler.Add("search reconcile", scheduler.Every(interval), s.Tasks.ReconcileSearchIndex)
+	}
+	if interval := cfg.GitHub.SyncInterval; interval > 0 {
+		c.Scheduler.Add("github sync", scheduler.Every(interval), s.GitHub.Sync)
+	}
+	if interval := cfg.Calendar.SyncInterval; interval > 0 {
+		c.Scheduler.Add("google calendar sync", scheduler.Every(interval), s.Calendar.Sync)
+	}
+	if cfg.Reports.BurndownSchedule != "" {
+		burndowns, err := scheduler.Cron(cfg.Reports.BurndownSchedule)
+		if err != nil {
+			return fmt.Errorf("failed to schedule burndowns: %w", err)
+		}
+		c.Scheduler.Add("burndowns", burndowns, s.Reports.Run)
+	}
+	if cfg.Reports.WebhookSchedule != "" {
+		statsWebhooks, err := scheduler.Cron(cfg.Reports.WebhookSchedule)
+		if err != nil {
+			return fmt.Errorf("failed to schedule stats webhooks: %w", err)
+		}
+		c.Scheduler.Add("stats webhooks", statsWebhooks, s.Reports.RunWebhooks)
+	}
+	if s.Warehouse != nil && cfg.Warehouse.SnapshotSchedule != "" {
+		snapshots, err := scheduler.Cron(cfg.Warehouse.SnapshotSchedule)
+		if err != nil {
+			return fmt.Errorf("failed to schedule warehouse snapshots: %w", err)
+		}
+		c.Scheduler.Add("warehouse snapshots", snapshots, s.Warehouse.Run)
+	}
+	lc.Go("scheduler", c.Scheduler.Run)
+	return nil
+}
+
+// newHandlers creates the HTTP handlers
+func (c *Container) newHandlers() error {
+	cfg := c.Config
+	s := &c.Services
+	h := &c.Handlers
+
+	h.Auth = authHandler.NewHandlerWithDeps(authHandler.Deps{Auth: s.Auth, Audit: s.Audit, Login: s.Login, Notifications: s.Notifications})
+	h.Tasks = taskHandler.NewHandlerWithDeps(taskHandler.Deps{Tasks: s.Tasks, Jobs: c.JobQueue, Notifications: s.Notifications})
+	h.Workspaces = workspaceHandler.NewHandler(s.Workspaces)
+	h.Tenants = tenantHandler.NewHandlerWithAudit(s.Tenants, s.Audit)
+	h.Me = meHandler.NewHandlerWithDeps(meHandler.Deps{Tasks: s.Tasks, Privacy: s.Privacy, Audit: s.Audit,
+		Notifications: s.Notifications, Devices: s.Devices, Limits: cfg.Limits})
+	h.Audit = auditHandler.NewHandler(s.Audit)
+	h.Integration = integrationHandler.NewHandlerWithDeps(integrationHandler.Deps{
+		Slack:                 s.Slack,
+		SlackSigningSecret:    cfg.Slack.SigningSecret,
+		Telegram:              s.Telegram,
+		TelegramWebhookSecret: cfg.Telegram.WebhookSecret,
+		GitHub:                s.GitHub,
+		GitHubWebhookSecret:   cfg.GitHub.WebhookSecret,
+		Calendar:              s.Calendar,
+		SMS:                   s.SMS,
+		Email:                 s.Email,
+	})
+	h.Attachments = attachmentHandler.NewHandler(s.Attachments)
+	h.Escalations = escalationHandler.NewHandler(s.Escalations)
+	h.Automations = automationHandler.NewHandler(s.Automations, s.Tasks)
+	h.Reports = reportHandler.NewHandler(s.Reports)
+	h.Billing = billingHandler.NewHandler(s.Billing)
+	h.Jobs = jobHandler.NewHandlerWithDeps(jobHandler.Deps{Jobs: c.JobQueue, Audit: s.Audit, Scheduler: c.Scheduler})
+
+	graphql, err := graphqlHandler.NewHandler(s.Tasks)
+	if err != nil {
+		return fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+	h.GraphQL = graphql
+
+	if cfg.SCIM.Enabled() {
+		h.SCIM = scimHandler.NewHandler(s.SCIM, s.Audit, cfg.SCIM.Token)
+	}
+	return nil
+}
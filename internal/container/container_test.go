@@ -0,0 +1,189 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/automation"
+	"todo-api/internal/domain/device"
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/report"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/health"
+	"todo-api/internal/lifecycle"
+	"todo-api/pkg/config"
+	"todo-api/pkg/telegram"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContainer(t *testing.T, cfg *config.Config) (*Container, error) {
+	lc := lifecycle.NewManager()
+	t.Cleanup(func() { lc.Shutdown(context.Background()) })
+	return New(cfg, lc)
+}
+
+func TestNew(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	c, err := newContainer(t, cfg)
+	require.NoError(t, err)
+
+	// Optional components are left out unless configured
+	assert.Nil(t, c.Services.SMS)
+	assert.Nil(t, c.Services.Attachments)
+	assert.Nil(t, c.Services.Warehouse)
+	assert.Nil(t, c.Handlers.SCIM)
+	assert.NotNil(t, c.Handlers.GraphQL)
+	assert.Len(t, c.Scheduler.Entries(), 7)
+	report := c.Health.Check(context.Background())
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Len(t, report.Dependencies, 3)
+
+	// Middleware and handlers share the services, so a task created through the task
+	// service is served to a user logged in through the auth service
+	tokens, err := c.Services.Auth.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	require.NoError(t, err)
+	claims, err := c.Services.Auth.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	created, err := c.Services.Tasks.CreateTask(&task.CreateTaskRequest{Title: "Wire the server"}, claims.UserID)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/tasks/:id", c.Authenticate, c.ResolveTenant, c.Handlers.Tasks.GetTask)
+	req := httptest.NewRequest(fiber.MethodGet, "/tasks/"+created.ID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data task.Task `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Wire the server", body.Data.Title)
+}
+
+func TestNew_Schedules(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Notify.ReminderInterval = 0
+	cfg.Notify.EscalationInterval = 0
+	cfg.GitHub.SyncInterval = 0
+	cfg.Calendar.SyncInterval = 0
+	cfg.Reports.BurndownSchedule = ""
+	cfg.Reports.WebhookSchedule = ""
+	cfg.Notify.DigestSchedule = "0 8 * * 1-5"
+
+	c, err := newContainer(t, cfg)
+	require.NoError(t, err)
+	entries := c.Scheduler.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "digests", entries[0].Name)
+}
+
+func TestNew_Warehouse(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	// Tasks are snapshotted without exporting events
+	cfg.Warehouse = config.WarehouseConfig{Sink: "redshift", SnapshotSchedule: "0 2 * * *", Timeout: time.Second,
+		BatchSize: 100, MaxBackoff: time.Minute, AWSRegion: "us-east-1", RedshiftWorkgroup: "analytics",
+		RedshiftDatabase: "todo", AWSAccessKeyID: "AKID", AWSSecretAccessKey: "secret"}
+
+	c, err := newContainer(t, cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, c.Services.Warehouse)
+	names := make([]string, 0, len(c.Scheduler.Entries()))
+	for _, entry := range c.Scheduler.Entries() {
+		names = append(names, entry.Name)
+	}
+	assert.Contains(t, names, "warehouse snapshots")
+}
+
+func TestNew_SeedFile(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Storage.SeedFile = "../seed/testdata/fixtures.yaml"
+
+	c, err := newContainer(t, cfg)
+	require.NoError(t, err)
+	assert.Len(t, c.Services.Auth.ListUsers(), 5)
+
+	// Users own the tasks of the file, seeded once
+	john, err := c.Services.Auth.GetUserByEmail("john.doe@example.com")
+	require.NoError(t, err)
+	tasks, _, err := c.Services.Tasks.ListTasks(&task.TaskFilter{}, nil, 1, 10, john.ID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 3)
+}
+
+func TestNew_EraseUser(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	c, err := newContainer(t, cfg)
+	require.NoError(t, err)
+	s := c.Services
+
+	john, err := s.Auth.GetUserByEmail("john.doe@example.com")
+	require.NoError(t, err)
+	_, err = s.Slack.ConnectSlack(john.ID, &integration.ConnectSlackRequest{BotToken: "xoxb-token", Channel: "C123", TeamID: "T1", SlackUserID: "U1"})
+	require.NoError(t, err)
+	code, err := s.Telegram.CreateTelegramLinkCode(john.ID, nil)
+	require.NoError(t, err)
+	s.Telegram.HandleMessage(&telegram.Message{
+		From: &telegram.User{ID: 7}, Chat: telegram.Chat{ID: 42, Type: telegram.ChatTypePrivate}, Text: "/start " + code.Code,
+	}, time.Now())
+	_, err = s.Telegram.GetTelegramLink(john.ID)
+	require.NoError(t, err)
+	_, _, err = s.Devices.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: device.PlatformIOS, Token: "a1b2c3d4e5f6"})
+	require.NoError(t, err)
+	_, err = s.Automations.CreateAPIKey(john.ID, &automation.CreateAPIKeyRequest{Name: "Zapier"})
+	require.NoError(t, err)
+	_, err = s.Automations.CreateRule(john.ID, &automation.RuleRequest{
+		Name: "Archive new", Trigger: automation.Trigger{Event: automation.TriggerTaskCreated},
+		Actions: []automation.Action{{Type: automation.ActionArchive}},
+	})
+	require.NoError(t, err)
+	_, err = s.Reports.CreateWebhook(john.ID, &report.WebhookRequest{URL: "https://bi.example.com/ingest"})
+	require.NoError(t, err)
+
+	// Erasing the account deletes what the integrations keep about the user
+	_, err = s.Privacy.EraseUser(john.ID)
+	require.NoError(t, err)
+	_, err = s.Slack.GetSlackConnection(john.ID)
+	assert.Error(t, err)
+	_, err = s.Telegram.GetTelegramLink(john.ID)
+	assert.Error(t, err)
+	assert.Empty(t, s.Devices.ListDevices(john.ID))
+	assert.Empty(t, s.Automations.ListAPIKeys(john.ID))
+	assert.Empty(t, s.Automations.ListRules(john.ID))
+	assert.Empty(t, s.Reports.ListWebhooks(john.ID))
+}
+
+func TestNew_Errors(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Search.Engine = "solr"
+
+	_, err = newContainer(t, cfg)
+	assert.EqualError(t, err, "unknown search engine: solr")
+
+	cfg.Search.Engine = "memory"
+	cfg.Storage.SeedFile = "fixtures.yaml"
+	_, err = newContainer(t, cfg)
+	assert.EqualError(t, err, "failed to load fixtures: open fixtures.yaml: no such file or directory")
+
+	cfg.Storage.SeedFile = ""
+	cfg.Warehouse.Sink = "bigquery"
+	cfg.Warehouse.GCPCredentialsFile = "missing.json"
+	_, err = newContainer(t, cfg)
+	assert.EqualError(t, err, "WAREHOUSE_GCP_CREDENTIALS_FILE: open missing.json: no such file or directory")
+}
@@ -0,0 +1,48 @@
+package activity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action represents the kind of change recorded for a task
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionDeleted Action = "deleted"
+)
+
+// Entry represents a single change to a task
+type Entry struct {
+	ID        uuid.UUID `json:"id"`
+	TaskID    uuid.UUID `json:"task_id"`
+	ActorID   uuid.UUID `json:"actor_id"`
+	Action    Action    `json:"action"`
+	Field     string    `json:"field,omitempty"`
+	OldValue  string    `json:"old_value,omitempty"`
+	NewValue  string    `json:"new_value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewEntry creates a new activity entry instance
+func NewEntry(taskID, actorID uuid.UUID, action Action) *Entry {
+	return &Entry{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		ActorID:   actorID,
+		Action:    action,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewFieldChange creates an update entry for a single field change
+func NewFieldChange(taskID, actorID uuid.UUID, field, oldValue, newValue string) *Entry {
+	entry := NewEntry(taskID, actorID, ActionUpdated)
+	entry.Field = field
+	entry.OldValue = oldValue
+	entry.NewValue = newValue
+	return entry
+}
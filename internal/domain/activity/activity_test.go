@@ -0,0 +1,35 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry(t *testing.T) {
+	taskID := uuid.New()
+	actorID := uuid.New()
+
+	entry := NewEntry(taskID, actorID, ActionCreated)
+
+	assert.NotNil(t, entry)
+	assert.NotEqual(t, uuid.Nil, entry.ID)
+	assert.Equal(t, taskID, entry.TaskID)
+	assert.Equal(t, actorID, entry.ActorID)
+	assert.Equal(t, ActionCreated, entry.Action)
+	assert.Empty(t, entry.Field)
+	assert.False(t, entry.CreatedAt.IsZero())
+}
+
+func TestNewFieldChange(t *testing.T) {
+	taskID := uuid.New()
+	actorID := uuid.New()
+
+	entry := NewFieldChange(taskID, actorID, "status", "pending", "completed")
+
+	assert.Equal(t, ActionUpdated, entry.Action)
+	assert.Equal(t, "status", entry.Field)
+	assert.Equal(t, "pending", entry.OldValue)
+	assert.Equal(t, "completed", entry.NewValue)
+}
@@ -0,0 +1,152 @@
+package attachment
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of attachments
+const (
+	StatusPending     = "pending"     // the upload URL was issued but the upload was not completed
+	StatusReady       = "ready"       // the file was uploaded and can be downloaded
+	StatusQuarantined = "quarantined" // the file was flagged by the malware scanner and deleted
+)
+
+// Results of scanning uploaded files for malware
+const (
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+)
+
+// Statuses of the thumbnails of images, generated in the background once they are uploaded
+const (
+	ThumbnailsPending = "pending"
+	ThumbnailsReady   = "ready"
+	ThumbnailsFailed  = "failed" // the image could not be decoded or the generation gave up
+)
+
+// DefaultContentType is the content type of files uploaded without one
+const DefaultContentType = "application/octet-stream"
+
+// maxFilenameLength bounds the length of file names, as most file systems do
+const maxFilenameLength = 255
+
+var (
+	// ErrNotFound is returned for attachments missing from the task
+	ErrNotFound = errors.New("attachment not found")
+	// ErrTooLarge is returned for files larger than the maximum attachment size
+	ErrTooLarge = errors.New("file is too large")
+	// ErrNotUploaded is returned when completing an upload before the file was uploaded
+	ErrNotUploaded = errors.New("file was not uploaded")
+	// ErrStorageUnavailable is returned when the object storage cannot be reached
+	ErrStorageUnavailable = errors.New("attachment storage is unavailable")
+	// ErrScanUnavailable is returned when uploaded files cannot be scanned for malware
+	ErrScanUnavailable = errors.New("attachment scanning is unavailable")
+)
+
+// Attachment represents a file attached to a task. The file itself is kept in object
+// storage under the key, which is never returned by the API.
+type Attachment struct {
+	ID          uuid.UUID  `json:"id"`
+	TaskID      uuid.UUID  `json:"task_id"`
+	UserID      uuid.UUID  `json:"user_id"` // who uploaded the file
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Status      string     `json:"status"`
+	Key         string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UploadedAt  *time.Time `json:"uploaded_at,omitempty"`
+	ScanStatus  string     `json:"scan_status,omitempty"` // left out when scanning is off
+	Threat      string     `json:"threat,omitempty"`      // what the scanner found in quarantined files
+	ScannedAt   *time.Time `json:"scanned_at,omitempty"`
+	// Thumbnails of images, left out for other files
+	ThumbnailStatus string      `json:"thumbnail_status,omitempty"`
+	Thumbnails      []Thumbnail `json:"thumbnails,omitempty"`
+	ThumbnailJobID  uuid.UUID   `json:"-"`
+}
+
+// Thumbnail is a scaled-down copy of an image attachment, kept next to its file. Listed
+// thumbnails come with a URL they are downloaded from.
+type Thumbnail struct {
+	Size        int        `json:"size"` // longest side the image was scaled down to fit
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	ContentType string     `json:"content_type"`
+	Key         string     `json:"-"`
+	URL         string     `json:"url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateAttachmentRequest represents a request to attach a file to a task. The file is
+// uploaded separately, to the URL returned for the new attachment.
+type CreateAttachmentRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+// Validate validates create attachment request, with files limited to maxSize bytes
+func (req *CreateAttachmentRequest) Validate(maxSize int64) error {
+	// Only the base name of paths sent by some clients is kept
+	req.Filename = strings.TrimSpace(req.Filename[strings.LastIndexAny(req.Filename, `/\`)+1:])
+	req.ContentType = strings.TrimSpace(req.ContentType)
+	if req.ContentType == "" {
+		req.ContentType = DefaultContentType
+	}
+
+	if req.Filename == "" || req.Filename == "." || req.Filename == ".." {
+		return errors.New("filename is required")
+	}
+	if len(req.Filename) > maxFilenameLength {
+		return fmt.Errorf("filename must be at most %d bytes", maxFilenameLength)
+	}
+	if strings.IndexFunc(req.Filename, unicode.IsControl) != -1 {
+		return errors.New("filename must not contain control characters")
+	}
+	if _, _, err := mime.ParseMediaType(req.ContentType); err != nil {
+		return errors.New("content_type is not a valid media type")
+	}
+	if req.Size <= 0 {
+		return errors.New("size must be positive")
+	}
+	if req.Size > maxSize {
+		return fmt.Errorf("%w: attachments must be at most %d bytes", ErrTooLarge, maxSize)
+	}
+	return nil
+}
+
+// NewAttachment creates a pending attachment of the task uploaded by the user
+func NewAttachment(taskID, userID uuid.UUID, req *CreateAttachmentRequest) *Attachment {
+	id := uuid.New()
+	return &Attachment{
+		ID:          id,
+		TaskID:      taskID,
+		UserID:      userID,
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Status:      StatusPending,
+		Key:         ObjectKey(taskID, id),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// ThumbnailKey returns the key the thumbnail of the size is stored under, next to the file
+// stored under the key
+func ThumbnailKey(key string, size int) string {
+	return key + "/thumbnails/" + strconv.Itoa(size)
+}
+
+// ObjectKey returns the key the file of the attachment is stored under. File names are
+// left out, so keys never need escaping.
+func ObjectKey(taskID, id uuid.UUID) string {
+	return "attachments/" + taskID.String() + "/" + id.String()
+}
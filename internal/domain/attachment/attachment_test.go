@@ -0,0 +1,44 @@
+package attachment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAttachmentRequest_Validate(t *testing.T) {
+	req := &CreateAttachmentRequest{Filename: ` C:\Users\john\report.pdf `, Size: 1024}
+	require.NoError(t, req.Validate(4096))
+	assert.Equal(t, "report.pdf", req.Filename)
+	assert.Equal(t, DefaultContentType, req.ContentType)
+
+	tests := []struct {
+		name string
+		req  CreateAttachmentRequest
+		err  string
+	}{
+		{"missing filename", CreateAttachmentRequest{Filename: "photos/", Size: 1}, "filename is required"},
+		{"long filename", CreateAttachmentRequest{Filename: strings.Repeat("a", 256), Size: 1}, "filename must be at most 255 bytes"},
+		{"control characters", CreateAttachmentRequest{Filename: "a\nb.txt", Size: 1}, "filename must not contain control characters"},
+		{"invalid content type", CreateAttachmentRequest{Filename: "a.txt", ContentType: "text/", Size: 1}, "content_type is not a valid media type"},
+		{"empty", CreateAttachmentRequest{Filename: "a.txt"}, "size must be positive"},
+		{"too large", CreateAttachmentRequest{Filename: "a.txt", Size: 4097}, "file is too large: attachments must be at most 4096 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.req.Validate(4096), tt.err)
+		})
+	}
+}
+
+func TestNewAttachment(t *testing.T) {
+	taskID, userID := uuid.New(), uuid.New()
+	a := NewAttachment(taskID, userID, &CreateAttachmentRequest{Filename: "a.txt", ContentType: "text/plain", Size: 3})
+	assert.Equal(t, StatusPending, a.Status)
+	assert.Equal(t, "attachments/"+taskID.String()+"/"+a.ID.String(), a.Key)
+	assert.Nil(t, a.UploadedAt)
+	assert.Equal(t, a.Key+"/thumbnails/128", ThumbnailKey(a.Key, 128))
+}
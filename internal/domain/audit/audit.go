@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action represents the kind of security event recorded. Actions are namespaced by
+// category, e.g. "auth.login_failed".
+type Action string
+
+const (
+	ActionLoginSucceeded     Action = "auth.login_succeeded"
+	ActionLoginFailed        Action = "auth.login_failed"
+	ActionTokenRefreshed     Action = "auth.token_refreshed"
+	ActionTokenRefreshFailed Action = "auth.token_refresh_failed"
+	ActionAnomalyDetected    Action = "auth.anomaly_detected"
+	ActionPasswordReset      Action = "auth.password_reset"
+	ActionEmailVerified      Action = "auth.email_verified"
+	ActionSessionRevoked     Action = "auth.session_revoked"
+	ActionTenantCreated      Action = "tenant.created"
+	ActionTenantUpdated      Action = "tenant.updated"
+	ActionAccountErased      Action = "account.erased"
+	ActionAccountProvisioned Action = "account.provisioned"
+	ActionAccountUpdated     Action = "account.updated"
+	ActionAccountDeactivated Action = "account.deactivated"
+	ActionAccountReactivated Action = "account.reactivated"
+	ActionJobRetried         Action = "job.retried"
+	ActionJobDiscarded       Action = "job.discarded"
+)
+
+// Entry represents a single security event. Entries are never changed once recorded.
+type Entry struct {
+	ID         uuid.UUID         `json:"id"`
+	Action     Action            `json:"action"`
+	ActorID    *uuid.UUID        `json:"actor_id,omitempty"`  // user performing the action, if known
+	Subject    string            `json:"subject,omitempty"`   // identifier the actor used, e.g. the email of a login attempt
+	TargetID   *uuid.UUID        `json:"target_id,omitempty"` // tenant, account, or job acted upon
+	IP         string            `json:"ip,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// NewEntry creates a new audit entry for an action by the actor, if known
+func NewEntry(action Action, actorID *uuid.UUID) *Entry {
+	return &Entry{
+		ID:         uuid.New(),
+		Action:     action,
+		ActorID:    actorID,
+		OccurredAt: time.Now(),
+	}
+}
+
+// EventName returns the action, so entries can be published on the event bus
+func (e *Entry) EventName() string {
+	return string(e.Action)
+}
+
+// EventKey returns the ID of the account or tenant acted upon, or else of the actor, so the
+// events of an account are streamed in order. Anonymous attempts are keyed by their subject.
+func (e *Entry) EventKey() string {
+	switch {
+	case e.TargetID != nil:
+		return e.TargetID.String()
+	case e.ActorID != nil:
+		return e.ActorID.String()
+	default:
+		return e.Subject
+	}
+}
+
+// Filter represents filters for audit log queries. All set conditions must match.
+type Filter struct {
+	UserID *uuid.UUID // matches entries where the user is the actor or the target
+	Action string     // an action, or a category such as "auth" matching all its actions
+	From   *time.Time // inclusive
+	To     *time.Time // exclusive
+}
+
+// Validate validates the audit filter
+func (f *Filter) Validate() error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// Matches reports whether the entry satisfies every condition of the filter. A nil
+// filter matches every entry.
+func (f *Filter) Matches(e *Entry) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.UserID != nil && !sameID(e.ActorID, *f.UserID) && !sameID(e.TargetID, *f.UserID) {
+		return false
+	}
+
+	if f.Action != "" && string(e.Action) != f.Action && !strings.HasPrefix(string(e.Action), f.Action+".") {
+		return false
+	}
+
+	if f.From != nil && e.OccurredAt.Before(*f.From) {
+		return false
+	}
+
+	if f.To != nil && !e.OccurredAt.Before(*f.To) {
+		return false
+	}
+
+	return true
+}
+
+// sameID reports whether the optional ID is set to id
+func sameID(optional *uuid.UUID, id uuid.UUID) bool {
+	return optional != nil && *optional == id
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry(t *testing.T) {
+	actorID := uuid.New()
+
+	entry := NewEntry(ActionLoginSucceeded, &actorID)
+
+	assert.NotEqual(t, uuid.Nil, entry.ID)
+	assert.Equal(t, ActionLoginSucceeded, entry.Action)
+	assert.Equal(t, &actorID, entry.ActorID)
+	assert.False(t, entry.OccurredAt.IsZero())
+}
+
+func TestFilter_Matches(t *testing.T) {
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	erased := NewEntry(ActionAccountErased, &adminID)
+	erased.TargetID = &userID
+	failed := NewEntry(ActionLoginFailed, nil)
+
+	hourAgo := time.Now().Add(-time.Hour)
+	inHour := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		entry  *Entry
+		want   bool
+	}{
+		{"nil filter", nil, failed, true},
+		{"actor", &Filter{UserID: &adminID}, erased, true},
+		{"target", &Filter{UserID: &userID}, erased, true},
+		{"other user", &Filter{UserID: &userID}, failed, false},
+		{"exact action", &Filter{Action: "auth.login_failed"}, failed, true},
+		{"action category", &Filter{Action: "auth"}, failed, true},
+		{"partial category", &Filter{Action: "au"}, failed, false},
+		{"other action", &Filter{Action: "account.erased"}, failed, false},
+		{"within range", &Filter{From: &hourAgo, To: &inHour}, failed, true},
+		{"before range", &Filter{From: &inHour}, failed, false},
+		{"after range", &Filter{To: &hourAgo}, failed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.entry))
+		})
+	}
+}
+
+func TestFilter_Validate(t *testing.T) {
+	from := time.Now()
+	to := from.Add(time.Hour)
+
+	assert.NoError(t, (&Filter{From: &from, To: &to}).Validate())
+	assert.NoError(t, (&Filter{From: &from}).Validate())
+	assert.EqualError(t, (&Filter{From: &to, To: &from}).Validate(), "from must be before to")
+}
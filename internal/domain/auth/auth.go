@@ -2,33 +2,135 @@ package auth
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
+// MinPasswordLength is the minimum length of a password in characters
+const MinPasswordLength = 8
+
+// MaxClientIDLength is the maximum length of the client identifier sent at login
+const MaxClientIDLength = 128
+
+// Scopes that can be granted to a token
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+
+	// ScopeTenantsAdmin grants access to the tenant admin API. It is only
+	// granted to platform admins and never implied by a token without scopes.
+	ScopeTenantsAdmin = "tenants:admin"
+
+	// ScopeAuditRead grants access to the security audit log. Like tenants:admin it is
+	// only granted to platform admins.
+	ScopeAuditRead = "audit:read"
+
+	// ScopeJobsAdmin grants access to the background job queue, to retry or discard failed
+	// jobs. Like tenants:admin it is only granted to platform admins.
+	ScopeJobsAdmin = "jobs:admin"
+)
+
+// ErrDirectoryUnavailable is returned when passwords cannot be checked because the directory
+// users authenticate against cannot be reached or rejects the lookup
+var ErrDirectoryUnavailable = errors.New("directory is unavailable, try again later")
+
+// ErrUserDeactivated is returned when a deactivated user logs in
+var ErrUserDeactivated = errors.New("account is deactivated")
+
+// ErrEmailTaken is returned when creating or renaming a user to the email of another user
+var ErrEmailTaken = errors.New("email is already in use")
+
+// ErrClientMismatch is returned when a refresh token is used by another client than the one
+// it was issued to
+var ErrClientMismatch = errors.New("refresh token was issued to another client")
+
+// ErrSessionNotFound is returned when revoking a session the user does not have
+var ErrSessionNotFound = errors.New("session not found")
+
+// AllScopes lists every scope a user can be granted
+var AllScopes = []string{ScopeTasksRead, ScopeTasksWrite}
+
+// AdminScopes lists the scopes that are additionally granted to platform admins
+var AdminScopes = []string{ScopeTenantsAdmin, ScopeAuditRead, ScopeJobsAdmin}
+
 // User represents a user in the system
 type User struct {
 	ID        uuid.UUID `json:"id"`
 	Email     string    `json:"email"`
 	Password  string    `json:"-"` // Don't include password in JSON
+	TenantID  uuid.UUID `json:"tenant_id"`
+	Admin     bool      `json:"-"` // Platform admin managing tenants
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// EmailVerifiedAt is when the user proved they own their email address
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// DeactivatedAt is when the user was deactivated, such as by their identity provider.
+	// Deactivated users cannot log in or refresh tokens.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	// Plan is the subscription plan of the user, free when empty
+	Plan string `json:"plan,omitempty"`
+	// StripeCustomerID identifies the user at Stripe once they paid for a subscription
+	StripeCustomerID string `json:"-"`
 }
 
 // LoginRequest represents a login request
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
+	Email    string   `json:"email" validate:"required,email"`
+	Password string   `json:"password" validate:"required,min=8"`
+	Scopes   []string `json:"scopes,omitempty"`
+	// ClientID identifies the client logging in, such as an app installation, which the
+	// refresh tokens of the login are bound to
+	ClientID  string `json:"client_id,omitempty"`
+	UserAgent string `json:"-"` // User-Agent header of the login, shown in the sessions of the user
+}
+
+// RefreshRequest represents a token refresh request
+type RefreshRequest struct {
+	RefreshToken string   `json:"refresh_token" validate:"required"`
+	Scopes       []string `json:"scopes,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"` // must be that of the login
+}
+
+// Session is a login of a user from a client. Refresh tokens are bound to the session they
+// were issued for, and stop working once it is revoked.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"-"`
+	ClientID   string    `json:"client_id,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"` // last login or refresh
+}
+
+// ForgotPasswordRequest represents a request for a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents a request to reset a password with the token of a
+// password reset email
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
 	Password string `json:"password" validate:"required,min=8"`
 }
 
+// VerifyEmailRequest represents a request to verify an email address with the token of a
+// verification email
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
 // TokenResponse represents a token response
 type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int64  `json:"expires_in"`
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token"`
+	TokenType    string   `json:"token_type"`
+	ExpiresIn    int64    `json:"expires_in"`
+	Scopes       []string `json:"scopes,omitempty"`
 }
 
 // NewUser creates a new user instance
@@ -56,14 +158,91 @@ func (req *LoginRequest) Validate() error {
 		return errors.New("password is required")
 	}
 
-	if len(req.Password) < 8 {
+	if utf8.RuneCountInString(req.Password) < MinPasswordLength {
 		return errors.New("password must be at least 8 characters long")
 	}
 
+	if len(req.ClientID) > MaxClientIDLength {
+		return errors.New("client_id must be at most 128 characters")
+	}
+
+	return validateScopes(req.Scopes)
+}
+
+// Validate validates refresh request
+func (req *RefreshRequest) Validate() error {
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		return errors.New("refresh token is required")
+	}
+
+	return validateScopes(req.Scopes)
+}
+
+// Validate validates forgot password request
+func (req *ForgotPasswordRequest) Validate() error {
+	if strings.TrimSpace(req.Email) == "" {
+		return errors.New("email is required")
+	}
+
+	if !isValidEmail(req.Email) {
+		return errors.New("invalid email format")
+	}
+
+	return nil
+}
+
+// Validate validates reset password request
+func (req *ResetPasswordRequest) Validate() error {
+	if strings.TrimSpace(req.Token) == "" {
+		return errors.New("token is required")
+	}
+
+	if utf8.RuneCountInString(req.Password) < MinPasswordLength {
+		return errors.New("password must be at least 8 characters long")
+	}
+
+	return nil
+}
+
+// Validate validates verify email request
+func (req *VerifyEmailRequest) Validate() error {
+	if strings.TrimSpace(req.Token) == "" {
+		return errors.New("token is required")
+	}
+
 	return nil
 }
 
+// IsEmailVerified reports whether the user verified their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsActive reports whether the user may log in
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
 // Helper functions
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !IsValidScope(scope) {
+			return errors.New("invalid scope: " + scope)
+		}
+	}
+	return nil
+}
+
+// IsValidScope checks if the scope is known
+func IsValidScope(scope string) bool {
+	return slices.Contains(AllScopes, scope) || IsAdminScope(scope)
+}
+
+// IsAdminScope checks if the scope is reserved for platform admins
+func IsAdminScope(scope string) bool {
+	return slices.Contains(AdminScopes, scope)
+}
+
 func isValidEmail(email string) bool {
 	// Basic email validation - in production, use a proper email validation library
 	return strings.Contains(email, "@") && strings.Contains(email, ".")
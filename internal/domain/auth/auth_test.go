@@ -2,8 +2,10 @@ package auth
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -119,6 +121,64 @@ func TestLoginRequest_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid scopes",
+			request: LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+				Scopes:   []string{ScopeTasksRead},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown scope",
+			request: LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+				Scopes:   []string{"tasks:admin"},
+			},
+			wantErr: true,
+			errMsg:  "invalid scope: tasks:admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRefreshRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request RefreshRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid request",
+			request: RefreshRequest{RefreshToken: "token"},
+			wantErr: false,
+		},
+		{
+			name:    "empty refresh token",
+			request: RefreshRequest{RefreshToken: "  "},
+			wantErr: true,
+			errMsg:  "refresh token is required",
+		},
+		{
+			name:    "unknown scope",
+			request: RefreshRequest{RefreshToken: "token", Scopes: []string{"unknown"}},
+			wantErr: true,
+			errMsg:  "invalid scope: unknown",
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,3 +249,36 @@ func TestUser_JSONSerialization(t *testing.T) {
 	assert.Contains(t, jsonStr, user.ID.String())
 	assert.NotContains(t, jsonStr, user.Password)
 }
+
+func TestResetPasswordRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&ResetPasswordRequest{Token: "token", Password: "new-password"}).Validate())
+	assert.EqualError(t, (&ResetPasswordRequest{Password: "new-password"}).Validate(), "token is required")
+	assert.EqualError(t, (&ResetPasswordRequest{Token: "token", Password: "short"}).Validate(),
+		"password must be at least 8 characters long")
+
+	assert.EqualError(t, (&ForgotPasswordRequest{Email: "not-an-email"}).Validate(), "invalid email format")
+	assert.EqualError(t, (&VerifyEmailRequest{Token: " "}).Validate(), "token is required")
+}
+
+func FuzzLoginRequest_Validate(f *testing.F) {
+	f.Add("john.doe@example.com", "password123", "tasks:read")
+	f.Add("  ", "short", "")
+	f.Add("пользователь@пример.рф", "пароль12", "tasks:read,tasks:write")
+	f.Add("a@b.c", "密码密码密码", "tenants:admin")
+	f.Add("user@example.com", "\x00\x00\x00\x00\x00\x00\x00\x00", "tasks:delete,")
+
+	f.Fuzz(func(t *testing.T, email, password, scopes string) {
+		req := &LoginRequest{Email: email, Password: password}
+		if scopes != "" {
+			req.Scopes = strings.Split(scopes, ",")
+		}
+		err := req.Validate()
+
+		valid := strings.TrimSpace(email) != "" && isValidEmail(email) &&
+			strings.TrimSpace(password) != "" && utf8.RuneCountInString(password) >= MinPasswordLength
+		for _, scope := range req.Scopes {
+			valid = valid && IsValidScope(scope)
+		}
+		assert.Equal(t, valid, err == nil, "email %q, password %q, scopes %q: %v", email, password, scopes, err)
+	})
+}
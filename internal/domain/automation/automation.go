@@ -0,0 +1,129 @@
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// KeyPrefix starts every API key, so leaked keys are easy to recognize
+const KeyPrefix = "todo_"
+
+// Bounds of the number of items returned by polling triggers
+const (
+	DefaultTriggerLimit = 50
+	MaxTriggerLimit     = 100
+)
+
+// APIKey represents a key a user created for an automation platform such as Zapier or IFTTT.
+// Only a hash of the key is kept; the key itself is returned once, when it is created.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"-"`
+	Name       string     `json:"name"`   // e.g. "Zapier"
+	Prefix     string     `json:"prefix"` // first characters of the key, to tell keys apart
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// CreatedAPIKey is a newly created API key along with the key itself
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyRequest represents a request to create an API key. Keys are granted every
+// task scope unless scopes are listed.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// CompleteTaskRequest represents an action completing a task
+type CompleteTaskRequest struct {
+	TaskID uuid.UUID `json:"task_id"`
+}
+
+// TaskItem is a task as returned by polling triggers. Its ID identifies the event that
+// triggered rather than the task, so automation platforms deduplicate items by it.
+type TaskItem struct {
+	ID          string            `json:"id"`
+	TaskID      uuid.UUID         `json:"task_id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Status      task.TaskStatus   `json:"status"`
+	Priority    task.TaskPriority `json:"priority"`
+	DueDate     *time.Time        `json:"due_date,omitempty"`
+	URL         string            `json:"url"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// Validate validates create API key request
+func (req *CreateAPIKeyRequest) Validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(req.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(auth.AllScopes, scope) {
+			return errors.New("invalid scope: " + scope)
+		}
+	}
+	return nil
+}
+
+// Validate validates complete task request
+func (req *CompleteTaskRequest) Validate() error {
+	if req.TaskID == uuid.Nil {
+		return errors.New("task_id is required")
+	}
+	return nil
+}
+
+// NewTaskItem creates the item of a new task trigger, identified by the task
+func NewTaskItem(t *task.Task, url string) *TaskItem {
+	return &TaskItem{
+		ID:          t.ID.String(),
+		TaskID:      t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		URL:         url,
+		CreatedAt:   t.CreatedAt,
+		CompletedAt: t.CompletedAt,
+	}
+}
+
+// CompletedTaskItem creates the item of a completed task trigger, identified by the task and
+// the time it was completed, so a task reopened and completed again triggers again
+func CompletedTaskItem(t *task.Task, url string) *TaskItem {
+	item := NewTaskItem(t, url)
+	if t.CompletedAt != nil {
+		item.ID = fmt.Sprintf("%s-%d", t.ID, t.CompletedAt.UnixNano())
+	}
+	return item
+}
+
+// ClampTriggerLimit returns the number of items a trigger returns for the requested limit
+func ClampTriggerLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultTriggerLimit
+	}
+	return min(limit, MaxTriggerLimit)
+}
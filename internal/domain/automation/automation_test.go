@@ -0,0 +1,59 @@
+package automation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIKeyRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CreateAPIKeyRequest
+		wantErr string
+	}{
+		{"valid", CreateAPIKeyRequest{Name: " Zapier "}, ""},
+		{"scoped", CreateAPIKeyRequest{Name: "IFTTT", Scopes: []string{"tasks:read"}}, ""},
+		{"missing name", CreateAPIKeyRequest{Name: "  "}, "name is required"},
+		{"long name", CreateAPIKeyRequest{Name: strings.Repeat("a", 101)}, "name must be at most 100 characters"},
+		{"admin scope", CreateAPIKeyRequest{Name: "Zapier", Scopes: []string{"tenants:admin"}}, "invalid scope: tenants:admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompletedTaskItem(t *testing.T) {
+	completedAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tk := &task.Task{ID: uuid.New(), Title: "File taxes", Status: task.StatusCompleted, CompletedAt: &completedAt}
+
+	assert.Equal(t, tk.ID.String(), NewTaskItem(tk, "").ID)
+
+	// Each completion of a task is a distinct item
+	item := CompletedTaskItem(tk, "https://todo.example.com/tasks/"+tk.ID.String())
+	assert.Equal(t, tk.ID.String()+"-1773144000000000000", item.ID)
+	assert.Equal(t, tk.ID, item.TaskID)
+	assert.Equal(t, "https://todo.example.com/tasks/"+tk.ID.String(), item.URL)
+
+	completedAt = completedAt.Add(time.Hour)
+	assert.NotEqual(t, item.ID, CompletedTaskItem(tk, "").ID)
+}
+
+func TestClampTriggerLimit(t *testing.T) {
+	assert.Equal(t, DefaultTriggerLimit, ClampTriggerLimit(0))
+	assert.Equal(t, 10, ClampTriggerLimit(10))
+	assert.Equal(t, MaxTriggerLimit, ClampTriggerLimit(1000))
+}
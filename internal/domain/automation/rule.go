@@ -0,0 +1,345 @@
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// Events that trigger rules
+const (
+	TriggerTaskCreated = "task.created"
+	TriggerTaskUpdated = "task.updated"
+)
+
+// Task fields rules are triggered by changes to and check conditions on
+const (
+	FieldStatus   = "status"
+	FieldPriority = "priority"
+	FieldTags     = "tags"
+	FieldTitle    = "title"
+)
+
+// Operators of conditions
+const (
+	OperatorEquals      = "equals"
+	OperatorNotEquals   = "not_equals"
+	OperatorContains    = "contains"
+	OperatorNotContains = "not_contains"
+)
+
+// Actions rules take on the task
+const (
+	ActionSetStatus   = "set_status"
+	ActionSetPriority = "set_priority"
+	ActionAddTag      = "add_tag"
+	ActionRemoveTag   = "remove_tag"
+	ActionArchive     = "archive"
+)
+
+// Statuses of rule executions
+const (
+	ExecutionSucceeded = "succeeded" // every action was applied or already held
+	ExecutionScheduled = "scheduled" // the delayed actions were scheduled
+	ExecutionSkipped   = "skipped"   // the conditions no longer held, or the task ran too many rules
+	ExecutionFailed    = "failed"
+)
+
+// Bounds of rules
+const (
+	MaxConditions  = 10
+	MaxActions     = 10
+	MaxActionDelay = 90 * 24 * time.Hour
+)
+
+// operators lists the operators allowed on each field
+var operators = map[string][]string{
+	FieldStatus:   {OperatorEquals, OperatorNotEquals},
+	FieldPriority: {OperatorEquals, OperatorNotEquals},
+	FieldTags:     {OperatorContains, OperatorNotContains},
+	FieldTitle:    {OperatorEquals, OperatorNotEquals, OperatorContains, OperatorNotContains},
+}
+
+// ErrRuleNotFound is returned for rules that do not exist or belong to another user
+var ErrRuleNotFound = errors.New("automation rule not found")
+
+// Trigger is the task event a rule is evaluated on. With a field, only updates changing the
+// field trigger the rule.
+type Trigger struct {
+	Event string `json:"event"`
+	Field string `json:"field,omitempty"`
+}
+
+// Condition checks a field of the task once the rule is triggered. Comparisons ignore case.
+type Condition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// Action changes the task, right away or once After, a duration such as 168h, has passed.
+// Delayed actions only run if the conditions of the rule still hold then.
+type Action struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+	After string `json:"after,omitempty"`
+}
+
+// Rule is a user-defined automation of the tasks the user owns: when the trigger fires and
+// every condition holds, the actions are taken
+type Rule struct {
+	ID         uuid.UUID   `json:"id"`
+	UserID     uuid.UUID   `json:"-"`
+	Name       string      `json:"name"`
+	Trigger    Trigger     `json:"trigger"`
+	Conditions []Condition `json:"conditions"`
+	Actions    []Action    `json:"actions"`
+	Enabled    bool        `json:"enabled"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// RuleRequest represents a request to create or replace an automation rule
+type RuleRequest struct {
+	Name       string      `json:"name"`
+	Trigger    Trigger     `json:"trigger"`
+	Conditions []Condition `json:"conditions,omitempty"`
+	Actions    []Action    `json:"actions"`
+	Enabled    *bool       `json:"enabled,omitempty"` // defaults to true
+}
+
+// Execution records a run of a rule on a task
+type Execution struct {
+	ID      uuid.UUID `json:"id"`
+	RuleID  uuid.UUID `json:"rule_id"`
+	TaskID  uuid.UUID `json:"task_id"`
+	Status  string    `json:"status"`
+	Actions []string  `json:"actions,omitempty"` // the actions applied or scheduled, such as set_priority high
+	Error   string    `json:"error,omitempty"`
+	RanAt   time.Time `json:"ran_at"`
+}
+
+// Validate validates the rule request, normalizing tags and the case of values
+func (req *RuleRequest) Validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(req.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+
+	switch req.Trigger.Event {
+	case TriggerTaskCreated:
+		if req.Trigger.Field != "" {
+			return errors.New("trigger field is only allowed for task.updated")
+		}
+	case TriggerTaskUpdated:
+		if _, ok := operators[req.Trigger.Field]; req.Trigger.Field != "" && !ok {
+			return errors.New("invalid trigger field: " + req.Trigger.Field)
+		}
+	default:
+		return errors.New("invalid trigger event: " + req.Trigger.Event)
+	}
+
+	if len(req.Conditions) > MaxConditions {
+		return fmt.Errorf("rules have at most %d conditions", MaxConditions)
+	}
+	for i := range req.Conditions {
+		if err := req.Conditions[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(req.Actions) == 0 {
+		return errors.New("at least one action is required")
+	}
+	if len(req.Actions) > MaxActions {
+		return fmt.Errorf("rules have at most %d actions", MaxActions)
+	}
+	for i := range req.Actions {
+		if err := req.Actions[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate validates the condition
+func (c *Condition) validate() error {
+	allowed, ok := operators[c.Field]
+	if !ok {
+		return errors.New("invalid condition field: " + c.Field)
+	}
+	if !slices.Contains(allowed, c.Operator) {
+		return fmt.Errorf("invalid operator %q for %s", c.Operator, c.Field)
+	}
+	switch c.Field {
+	case FieldStatus:
+		return validateStatus(c.Value)
+	case FieldPriority:
+		return validatePriority(c.Value)
+	case FieldTags:
+		return normalizeTag(&c.Value)
+	}
+	if c.Value == "" {
+		return errors.New("condition value is required")
+	}
+	return nil
+}
+
+// validate validates the action
+func (a *Action) validate() error {
+	if a.After != "" {
+		after, err := time.ParseDuration(a.After)
+		if err != nil {
+			return errors.New("invalid after: " + a.After)
+		}
+		if after < time.Minute || after > MaxActionDelay {
+			return errors.New("after must be between 1m and 2160h")
+		}
+	}
+
+	switch a.Type {
+	case ActionSetStatus:
+		return validateStatus(a.Value)
+	case ActionSetPriority:
+		return validatePriority(a.Value)
+	case ActionAddTag, ActionRemoveTag:
+		return normalizeTag(&a.Value)
+	case ActionArchive:
+		if a.Value != "" {
+			return errors.New("archive takes no value")
+		}
+		return nil
+	default:
+		return errors.New("invalid action: " + a.Type)
+	}
+}
+
+// validateStatus checks the value is a task status
+func validateStatus(value string) error {
+	switch task.TaskStatus(value) {
+	case task.StatusPending, task.StatusInProgress, task.StatusCompleted, task.StatusCancelled:
+		return nil
+	}
+	return errors.New("invalid status: " + value)
+}
+
+// validatePriority checks the value is a task priority
+func validatePriority(value string) error {
+	switch task.TaskPriority(value) {
+	case task.PriorityLow, task.PriorityMedium, task.PriorityHigh:
+		return nil
+	}
+	return errors.New("invalid priority: " + value)
+}
+
+// normalizeTag validates the tag and normalizes it as tasks store it
+func normalizeTag(value *string) error {
+	tags, err := task.NormalizeTags([]string{*value})
+	if err != nil {
+		return err
+	}
+	*value = tags[0]
+	return nil
+}
+
+// NewRule creates a rule of the user from a validated request
+func NewRule(userID uuid.UUID, req *RuleRequest) *Rule {
+	rule := &Rule{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	req.Apply(rule)
+	return rule
+}
+
+// Apply replaces the settings of the rule with those of a validated request
+func (req *RuleRequest) Apply(r *Rule) {
+	r.Name = req.Name
+	r.Trigger = req.Trigger
+	r.Conditions = append([]Condition{}, req.Conditions...)
+	r.Actions = append([]Action{}, req.Actions...)
+	r.Enabled = req.Enabled == nil || *req.Enabled
+	r.UpdatedAt = time.Now()
+}
+
+// Triggers reports whether the event triggers the rule: an event of the trigger on a task
+// the user owns, changing the trigger field if set
+func (r *Rule) Triggers(e *task.Event) bool {
+	if !r.Enabled || e.Task == nil || e.Task.UserID != r.UserID || string(e.Type) != r.Trigger.Event {
+		return false
+	}
+	if r.Trigger.Field != "" {
+		_, changed := e.Change(r.Trigger.Field)
+		return changed
+	}
+	return true
+}
+
+// Matches reports whether every condition of the rule holds for the task
+func (r *Rule) Matches(t *task.Task) bool {
+	for _, c := range r.Conditions {
+		if !c.Matches(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether the condition holds for the task
+func (c Condition) Matches(t *task.Task) bool {
+	if c.Field == FieldTags {
+		has := slices.Contains(t.Tags, c.Value)
+		return has == (c.Operator == OperatorContains)
+	}
+
+	var value string
+	switch c.Field {
+	case FieldStatus:
+		value = string(t.Status)
+	case FieldPriority:
+		value = string(t.Priority)
+	case FieldTitle:
+		value = t.Title
+	}
+	value, expected := strings.ToLower(value), strings.ToLower(c.Value)
+	switch c.Operator {
+	case OperatorEquals:
+		return value == expected
+	case OperatorNotEquals:
+		return value != expected
+	case OperatorContains:
+		return strings.Contains(value, expected)
+	case OperatorNotContains:
+		return !strings.Contains(value, expected)
+	}
+	return false
+}
+
+// Delay returns how long after the rule is triggered the action is taken
+func (a Action) Delay() time.Duration {
+	d, _ := time.ParseDuration(a.After)
+	return d
+}
+
+// String describes the action as recorded in executions, such as set_priority high
+func (a Action) String() string {
+	s := a.Type
+	if a.Value != "" {
+		s += " " + a.Value
+	}
+	if a.After != "" {
+		s += " after " + a.After
+	}
+	return s
+}
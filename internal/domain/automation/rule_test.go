@@ -0,0 +1,112 @@
+package automation
+
+import (
+	"testing"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleRequest_Validate(t *testing.T) {
+	archive := []Action{{Type: ActionArchive}}
+	tests := []struct {
+		name    string
+		req     RuleRequest
+		wantErr string
+	}{
+		{"valid", RuleRequest{Name: "Archive done", Trigger: Trigger{Event: TriggerTaskUpdated, Field: FieldStatus},
+			Conditions: []Condition{{Field: FieldStatus, Operator: OperatorEquals, Value: "completed"}},
+			Actions:    []Action{{Type: ActionArchive, After: "168h"}}}, ""},
+		{"missing name", RuleRequest{Name: " ", Trigger: Trigger{Event: TriggerTaskCreated}, Actions: archive}, "name is required"},
+		{"deleted trigger", RuleRequest{Name: "a", Trigger: Trigger{Event: "task.deleted"}, Actions: archive}, "invalid trigger event: task.deleted"},
+		{"created with field", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated, Field: FieldStatus}, Actions: archive},
+			"trigger field is only allowed for task.updated"},
+		{"unknown field", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskUpdated, Field: "checklist"}, Actions: archive},
+			"invalid trigger field: checklist"},
+		{"no actions", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated}}, "at least one action is required"},
+		{"operator of field", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated}, Actions: archive,
+			Conditions: []Condition{{Field: FieldTags, Operator: OperatorEquals, Value: "bug"}}}, `invalid operator "equals" for tags`},
+		{"unknown status", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated}, Actions: archive,
+			Conditions: []Condition{{Field: FieldStatus, Operator: OperatorEquals, Value: "done"}}}, "invalid status: done"},
+		{"empty title", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated}, Actions: archive,
+			Conditions: []Condition{{Field: FieldTitle, Operator: OperatorContains}}}, "condition value is required"},
+		{"unknown action", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated}, Actions: []Action{{Type: "delete"}}},
+			"invalid action: delete"},
+		{"unknown priority", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated},
+			Actions: []Action{{Type: ActionSetPriority, Value: "urgent"}}}, "invalid priority: urgent"},
+		{"short delay", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated}, Actions: []Action{{Type: ActionArchive, After: "30s"}}},
+			"after must be between 1m and 2160h"},
+		{"invalid delay", RuleRequest{Name: "a", Trigger: Trigger{Event: TriggerTaskCreated}, Actions: []Action{{Type: ActionArchive, After: "7d"}}},
+			"invalid after: 7d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleRequest_ValidateNormalizesTags(t *testing.T) {
+	req := RuleRequest{
+		Name:       "Bugs",
+		Trigger:    Trigger{Event: TriggerTaskCreated},
+		Conditions: []Condition{{Field: FieldTags, Operator: OperatorContains, Value: "#Bug"}},
+		Actions:    []Action{{Type: ActionAddTag, Value: "Triage"}},
+	}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "bug", req.Conditions[0].Value)
+	assert.Equal(t, "triage", req.Actions[0].Value)
+}
+
+func TestRule_Triggers(t *testing.T) {
+	userID := uuid.New()
+	disabled := false
+	rule := NewRule(userID, &RuleRequest{Name: "Done", Trigger: Trigger{Event: TriggerTaskUpdated, Field: FieldStatus},
+		Actions: []Action{{Type: ActionArchive}}})
+	owned := &task.Task{ID: uuid.New(), UserID: userID}
+
+	changed := &task.Event{Type: task.EventTaskUpdated, Task: owned,
+		Changes: []task.FieldChange{{Field: FieldStatus, OldValue: "pending", NewValue: "completed"}}}
+	assert.True(t, rule.Triggers(changed))
+
+	// Updates of other fields, events of other types, and tasks of other users do not
+	// trigger the rule
+	assert.False(t, rule.Triggers(&task.Event{Type: task.EventTaskUpdated, Task: owned,
+		Changes: []task.FieldChange{{Field: FieldTitle}}}))
+	assert.False(t, rule.Triggers(&task.Event{Type: task.EventTaskCreated, Task: owned}))
+	assert.False(t, rule.Triggers(&task.Event{Type: task.EventTaskUpdated, Task: &task.Task{UserID: uuid.New()},
+		Changes: changed.Changes}))
+
+	(&RuleRequest{Name: "Done", Trigger: rule.Trigger, Actions: rule.Actions, Enabled: &disabled}).Apply(rule)
+	assert.False(t, rule.Triggers(changed))
+}
+
+func TestRule_Matches(t *testing.T) {
+	rule := &Rule{Conditions: []Condition{
+		{Field: FieldTags, Operator: OperatorContains, Value: "bug"},
+		{Field: FieldPriority, Operator: OperatorNotEquals, Value: "high"},
+		{Field: FieldTitle, Operator: OperatorContains, Value: "CRASH"},
+	}}
+
+	assert.True(t, rule.Matches(&task.Task{Title: "Crash on login", Priority: task.PriorityLow, Tags: []string{"bug"}}))
+	assert.False(t, rule.Matches(&task.Task{Title: "Crash on login", Priority: task.PriorityHigh, Tags: []string{"bug"}}))
+	assert.False(t, rule.Matches(&task.Task{Title: "Crash on login", Priority: task.PriorityLow}))
+	assert.False(t, rule.Matches(&task.Task{Title: "Slow login", Priority: task.PriorityLow, Tags: []string{"bug"}}))
+
+	// Rules without conditions match every task
+	assert.True(t, (&Rule{}).Matches(&task.Task{}))
+}
+
+func TestAction_String(t *testing.T) {
+	assert.Equal(t, "archive after 168h", Action{Type: ActionArchive, After: "168h"}.String())
+	assert.Equal(t, "set_priority high", Action{Type: ActionSetPriority, Value: "high"}.String())
+}
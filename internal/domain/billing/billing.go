@@ -0,0 +1,79 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Plan is a subscription plan
+type Plan string
+
+const (
+	PlanFree Plan = "free"
+	PlanPro  Plan = "pro" // paid through Stripe, without limits
+)
+
+var (
+	// ErrUpgradeRequired is returned when an operation would exceed a limit of the user's
+	// plan, which upgrading to the pro plan lifts
+	ErrUpgradeRequired = errors.New("upgrade required")
+	// ErrAlreadySubscribed is returned when users on the pro plan check out again
+	ErrAlreadySubscribed = errors.New("already subscribed to the pro plan")
+	// ErrNotEnabled is returned when checking out while billing is not configured
+	ErrNotEnabled = errors.New("billing is not enabled")
+	// ErrPaymentProviderUnavailable is returned when Stripe cannot be reached
+	ErrPaymentProviderUnavailable = errors.New("payment provider is unavailable, try again later")
+)
+
+// Limits caps the resources users of a plan may use. Zero means unlimited.
+type Limits struct {
+	MaxTasks        int `json:"max_tasks"`
+	MaxAttachments  int `json:"max_attachments"`
+	MaxIntegrations int `json:"max_integrations"`
+}
+
+// Subscription reports the plan of a user and its limits
+type Subscription struct {
+	Plan   Plan   `json:"plan"`
+	Limits Limits `json:"limits"`
+	// Status is the status of the Stripe subscription, such as active or past_due
+	Status           string     `json:"status,omitempty"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end,omitempty"`
+}
+
+// CheckoutSession is a Stripe Checkout Session users pay for the pro plan on
+type CheckoutSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"` // where the user is redirected to pay
+}
+
+// PlanOf returns the plan stored on a user, free when none is stored
+func PlanOf(stored string) Plan {
+	if Plan(stored) == PlanPro {
+		return PlanPro
+	}
+	return PlanFree
+}
+
+// AllowsAttachments reports whether a user holding count attachments may upload another
+func (l Limits) AllowsAttachments(count int) bool {
+	return l.MaxAttachments == 0 || count < l.MaxAttachments
+}
+
+// AllowsIntegrations reports whether a user with count connected integrations may connect
+// another
+func (l Limits) AllowsIntegrations(count int) bool {
+	return l.MaxIntegrations == 0 || count < l.MaxIntegrations
+}
+
+// UpgradeRequired returns the error of exceeding a limit of the plan, such as
+// "upgrade required: the free plan allows at most 100 tasks". The plural resources name is
+// made singular for a limit of 1.
+func UpgradeRequired(plan Plan, limit int, resources string) error {
+	if limit == 1 {
+		resources = strings.TrimSuffix(resources, "s")
+	}
+	return fmt.Errorf("%w: the %s plan allows at most %d %s", ErrUpgradeRequired, plan, limit, resources)
+}
@@ -0,0 +1,32 @@
+package billing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanOf(t *testing.T) {
+	assert.Equal(t, PlanFree, PlanOf(""))
+	assert.Equal(t, PlanFree, PlanOf("free"))
+	assert.Equal(t, PlanPro, PlanOf("pro"))
+	assert.Equal(t, PlanFree, PlanOf("enterprise"))
+}
+
+func TestLimits(t *testing.T) {
+	limits := Limits{MaxAttachments: 2, MaxIntegrations: 1}
+	assert.True(t, limits.AllowsAttachments(1))
+	assert.False(t, limits.AllowsAttachments(2))
+	assert.True(t, limits.AllowsIntegrations(0))
+	assert.False(t, limits.AllowsIntegrations(1))
+
+	assert.True(t, Limits{}.AllowsAttachments(1000))
+	assert.True(t, Limits{}.AllowsIntegrations(1000))
+}
+
+func TestUpgradeRequired(t *testing.T) {
+	err := UpgradeRequired(PlanFree, 100, "tasks")
+	assert.ErrorIs(t, err, ErrUpgradeRequired)
+	assert.Equal(t, "upgrade required: the free plan allows at most 100 tasks", err.Error())
+	assert.EqualError(t, UpgradeRequired(PlanFree, 1, "integrations"), "upgrade required: the free plan allows at most 1 integration")
+}
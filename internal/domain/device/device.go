@@ -0,0 +1,82 @@
+package device
+
+import (
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Platforms devices are registered for, each delivered through its own push service
+const (
+	PlatformAndroid = "android" // Firebase Cloud Messaging
+	PlatformIOS     = "ios"     // Apple Push Notification service
+)
+
+// Platforms lists every platform devices can be registered for
+var Platforms = []string{PlatformAndroid, PlatformIOS}
+
+// maxTokenLength bounds the length of push tokens. FCM tokens are around 160 characters and
+// APNs tokens 64 hex digits, but neither length is guaranteed.
+const maxTokenLength = 4096
+
+// Device represents a mobile device receiving push notifications for a user. The push token
+// addresses the app installation and is never returned by the API.
+type Device struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	Platform  string    `json:"platform"`
+	Token     string    `json:"-"`
+	Name      string    `json:"name,omitempty"` // e.g. "Jane's iPhone"
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RegisterDeviceRequest represents a request to register a device for push notifications
+type RegisterDeviceRequest struct {
+	Platform string `json:"platform"`
+	Token    string `json:"token"`
+	Name     string `json:"name,omitempty"`
+}
+
+// Validate validates register device request
+func (req *RegisterDeviceRequest) Validate() error {
+	req.Token = strings.TrimSpace(req.Token)
+	req.Name = strings.TrimSpace(req.Name)
+
+	if !slices.Contains(Platforms, req.Platform) {
+		return errors.New("platform must be one of: " + strings.Join(Platforms, ", "))
+	}
+	if req.Token == "" {
+		return errors.New("token is required")
+	}
+	if len(req.Token) > maxTokenLength {
+		return errors.New("token is too long")
+	}
+	if req.Platform == PlatformIOS {
+		if _, err := hex.DecodeString(req.Token); err != nil {
+			return errors.New("token must be a hex encoded APNs device token")
+		}
+	}
+	if len(req.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	return nil
+}
+
+// NewDevice creates a new device of the user from the request
+func NewDevice(userID uuid.UUID, req *RegisterDeviceRequest) *Device {
+	now := time.Now()
+	return &Device{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Platform:  req.Platform,
+		Token:     req.Token,
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
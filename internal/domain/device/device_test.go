@@ -0,0 +1,49 @@
+package device
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterDeviceRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     RegisterDeviceRequest
+		wantErr bool
+	}{
+		{"android", RegisterDeviceRequest{Platform: PlatformAndroid, Token: "fcm:token-1"}, false},
+		{"ios", RegisterDeviceRequest{Platform: PlatformIOS, Token: "a1b2c3d4", Name: "Jane's iPhone"}, false},
+		{"unknown platform", RegisterDeviceRequest{Platform: "web", Token: "token"}, true},
+		{"missing token", RegisterDeviceRequest{Platform: PlatformAndroid, Token: "  "}, true},
+		{"token too long", RegisterDeviceRequest{Platform: PlatformAndroid, Token: strings.Repeat("a", maxTokenLength+1)}, true},
+		{"ios token not hex", RegisterDeviceRequest{Platform: PlatformIOS, Token: "not-hex"}, true},
+		{"name too long", RegisterDeviceRequest{Platform: PlatformAndroid, Token: "token", Name: strings.Repeat("a", 101)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewDevice(t *testing.T) {
+	userID := uuid.New()
+	req := &RegisterDeviceRequest{Platform: PlatformIOS, Token: "a1b2c3d4", Name: "iPad"}
+	require.NoError(t, req.Validate())
+
+	d := NewDevice(userID, req)
+	assert.NotEqual(t, uuid.Nil, d.ID)
+	assert.Equal(t, userID, d.UserID)
+	assert.Equal(t, "a1b2c3d4", d.Token)
+	assert.Equal(t, d.CreatedAt, d.UpdatedAt)
+}
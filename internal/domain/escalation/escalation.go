@@ -0,0 +1,145 @@
+package escalation
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// Recipients of escalations
+const (
+	RecipientAssignee     = "assignee"      // the task's assignee, or its creator when unassigned
+	RecipientCreator      = "creator"       // the user who created the task
+	RecipientProjectOwner = "project_owner" // the user who created the task's project
+)
+
+// Recipients lists every recipient an escalation can be sent to
+var Recipients = []string{RecipientAssignee, RecipientCreator, RecipientProjectOwner}
+
+// MaxOverdueBy bounds how long tasks can be overdue before rules escalate them
+const MaxOverdueBy = 90 * 24 * time.Hour
+
+// ErrRuleNotFound is returned for rules that do not exist in the workspace
+var ErrRuleNotFound = errors.New("escalation rule not found")
+
+// Rule escalates the open tasks of a workspace overdue by at least OverdueBy, emailing the
+// Notify recipients with the CC recipients in copy. Each task is escalated once per rule
+// and due date.
+type Rule struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Name        string    `json:"name"`
+	// Priority and ProjectID restrict the rule to tasks of the priority and project
+	Priority  task.TaskPriority `json:"priority,omitempty"`
+	ProjectID *uuid.UUID        `json:"project_id,omitempty"`
+	OverdueBy string            `json:"overdue_by"` // duration, such as 24h
+	Notify    []string          `json:"notify"`
+	CC        []string          `json:"cc"`
+	Enabled   bool              `json:"enabled"`
+	CreatedBy uuid.UUID         `json:"created_by"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// RuleRequest represents a request to create or replace an escalation rule
+type RuleRequest struct {
+	Name      string            `json:"name"`
+	Priority  task.TaskPriority `json:"priority,omitempty"`
+	ProjectID *uuid.UUID        `json:"project_id,omitempty"`
+	OverdueBy string            `json:"overdue_by"`
+	Notify    []string          `json:"notify"`
+	CC        []string          `json:"cc,omitempty"`
+	Enabled   *bool             `json:"enabled,omitempty"` // defaults to true
+}
+
+// Validate validates the rule request
+func (req *RuleRequest) Validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(req.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	switch req.Priority {
+	case "", task.PriorityLow, task.PriorityMedium, task.PriorityHigh:
+	default:
+		return errors.New("invalid priority: " + string(req.Priority))
+	}
+	overdueBy, err := time.ParseDuration(req.OverdueBy)
+	if err != nil {
+		return errors.New("invalid overdue_by: " + req.OverdueBy)
+	}
+	if overdueBy < 0 || overdueBy > MaxOverdueBy {
+		return errors.New("overdue_by must be between 0s and 2160h")
+	}
+	if len(req.Notify) == 0 {
+		return errors.New("notify requires at least one recipient")
+	}
+	for _, recipient := range append(slices.Clip(req.Notify), req.CC...) {
+		if !slices.Contains(Recipients, recipient) {
+			return errors.New("invalid recipient: " + recipient)
+		}
+	}
+	return nil
+}
+
+// NewRule creates a rule of the workspace from a validated request
+func NewRule(workspaceID uuid.UUID, req *RuleRequest, userID uuid.UUID) *Rule {
+	now := time.Now()
+	rule := &Rule{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+	}
+	req.Apply(rule)
+	return rule
+}
+
+// Apply replaces the settings of the rule with those of a validated request
+func (req *RuleRequest) Apply(r *Rule) {
+	r.Name = req.Name
+	r.Priority = req.Priority
+	r.ProjectID = req.ProjectID
+	r.OverdueBy = req.OverdueBy
+	r.Notify = slices.Compact(slices.Sorted(slices.Values(req.Notify)))
+	r.CC = []string{}
+	for _, recipient := range slices.Sorted(slices.Values(req.CC)) {
+		if !slices.Contains(r.Notify, recipient) && !slices.Contains(r.CC, recipient) {
+			r.CC = append(r.CC, recipient)
+		}
+	}
+	r.Enabled = req.Enabled == nil || *req.Enabled
+	r.UpdatedAt = time.Now()
+}
+
+// Threshold returns how long tasks must be overdue before the rule escalates them
+func (r *Rule) Threshold() time.Duration {
+	d, _ := time.ParseDuration(r.OverdueBy)
+	return d
+}
+
+// Matches reports whether the rule escalates the task at the time: an open task of the
+// workspace, of the rule's priority and project if set, overdue by at least the threshold.
+func (r *Rule) Matches(t *task.Task, now time.Time) bool {
+	if !r.Enabled || t.WorkspaceID == nil || *t.WorkspaceID != r.WorkspaceID {
+		return false
+	}
+	if t.DueDate == nil || t.Status.IsClosed() || t.IsArchived() {
+		return false
+	}
+	if r.Priority != "" && t.Priority != r.Priority {
+		return false
+	}
+	if r.ProjectID != nil && (t.ProjectID == nil || *t.ProjectID != *r.ProjectID) {
+		return false
+	}
+	return !now.Before(t.DueDate.Add(r.Threshold()))
+}
@@ -0,0 +1,93 @@
+package escalation
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleRequest_Validate(t *testing.T) {
+	valid := RuleRequest{Name: " Urgent ", Priority: task.PriorityHigh, OverdueBy: "24h", Notify: []string{RecipientAssignee}}
+	require.NoError(t, valid.Validate())
+	assert.Equal(t, "Urgent", valid.Name)
+
+	for name, mutate := range map[string]func(req *RuleRequest){
+		"no name":           func(req *RuleRequest) { req.Name = "" },
+		"invalid priority":  func(req *RuleRequest) { req.Priority = "urgent" },
+		"invalid duration":  func(req *RuleRequest) { req.OverdueBy = "1 day" },
+		"negative duration": func(req *RuleRequest) { req.OverdueBy = "-1h" },
+		"too long":          func(req *RuleRequest) { req.OverdueBy = "2161h" },
+		"no recipient":      func(req *RuleRequest) { req.Notify = nil },
+		"unknown recipient": func(req *RuleRequest) { req.CC = []string{"manager"} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := valid
+			mutate(&req)
+			assert.Error(t, req.Validate())
+		})
+	}
+}
+
+func TestNewRule(t *testing.T) {
+	off := false
+	req := &RuleRequest{
+		Name:      "Overdue",
+		OverdueBy: "1h",
+		Notify:    []string{RecipientAssignee, RecipientAssignee},
+		CC:        []string{RecipientProjectOwner, RecipientAssignee},
+		Enabled:   &off,
+	}
+	rule := NewRule(uuid.New(), req, uuid.New())
+
+	// Recipients are notified once, directly rather than in copy
+	assert.Equal(t, []string{RecipientAssignee}, rule.Notify)
+	assert.Equal(t, []string{RecipientProjectOwner}, rule.CC)
+	assert.False(t, rule.Enabled)
+	assert.Equal(t, time.Hour, rule.Threshold())
+}
+
+func TestRule_Matches(t *testing.T) {
+	workspaceID, projectID := uuid.New(), uuid.New()
+	now := time.Now()
+	due := now.Add(-25 * time.Hour)
+	rule := NewRule(workspaceID, &RuleRequest{
+		Name:      "Urgent",
+		Priority:  task.PriorityHigh,
+		ProjectID: &projectID,
+		OverdueBy: "24h",
+		Notify:    []string{RecipientAssignee},
+	}, uuid.New())
+
+	overdue := &task.Task{
+		Status:      task.StatusPending,
+		Priority:    task.PriorityHigh,
+		DueDate:     &due,
+		WorkspaceID: &workspaceID,
+		ProjectID:   &projectID,
+	}
+	assert.True(t, rule.Matches(overdue, now))
+	assert.False(t, rule.Matches(overdue, now.Add(-2*time.Hour)), "not overdue long enough")
+
+	for name, mutate := range map[string]func(t *task.Task){
+		"other workspace": func(t *task.Task) { other := uuid.New(); t.WorkspaceID = &other },
+		"personal":        func(t *task.Task) { t.WorkspaceID = nil },
+		"other project":   func(t *task.Task) { t.ProjectID = nil },
+		"other priority":  func(t *task.Task) { t.Priority = task.PriorityMedium },
+		"completed":       func(t *task.Task) { t.Status = task.StatusCompleted },
+		"no due date":     func(t *task.Task) { t.DueDate = nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			other := *overdue
+			mutate(&other)
+			assert.False(t, rule.Matches(&other, now))
+		})
+	}
+
+	rule.Enabled = false
+	assert.False(t, rule.Matches(overdue, now))
+}
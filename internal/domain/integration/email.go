@@ -0,0 +1,24 @@
+package integration
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailAddressPrefix starts the local part of email-in addresses, as in add+<token>@<domain>
+const EmailAddressPrefix = "add+"
+
+// ErrEmailRejected is returned for inbound emails no task is created from, such as emails to
+// unknown addresses or from senders other than the user
+var ErrEmailRejected = errors.New("email rejected")
+
+// EmailAddress is the address a user creates tasks at by sending emails to it. The token in
+// the address is its only secret, so it is replaced when it leaks.
+type EmailAddress struct {
+	UserID    uuid.UUID `json:"-"`
+	Token     string    `json:"-"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GitHubAccount represents the GitHub account a user authorized the OAuth app with. The
+// access token is a credential and never returned by the API.
+type GitHubAccount struct {
+	UserID       uuid.UUID `json:"-"`
+	Login        string    `json:"login"`
+	AccessToken  string    `json:"-"`
+	AuthorizedAt time.Time `json:"authorized_at"`
+}
+
+// GitHubAuthorization is where a user authorizes the OAuth app. The URL carries a one-time
+// state tying the authorization back to the user.
+type GitHubAuthorization struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GitHubLink represents a project synced with the issues of a GitHub repository. Issues and
+// tasks are synced with the GitHub account of the member who linked them.
+type GitHubLink struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Repository  string    `json:"repository"` // owner/name
+	LinkedBy    uuid.UUID `json:"linked_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	// LastSyncedAt is when issues were last listed; LastSyncError is why the last sync
+	// failed, if it did
+	LastSyncedAt  *time.Time `json:"last_synced_at,omitempty"`
+	LastSyncError string     `json:"last_sync_error,omitempty"`
+	// Conflicts counts the changes discarded because the issue and task both changed
+	Conflicts int `json:"conflicts"`
+}
+
+// LinkGitHubRequest represents a request to sync a project with a GitHub repository
+type LinkGitHubRequest struct {
+	Repository string `json:"repository"` // owner/name
+}
+
+// repositoryPattern matches owner/name repository references
+var repositoryPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})/[A-Za-z0-9._-]{1,100}$`)
+
+// Validate validates link GitHub request
+func (req *LinkGitHubRequest) Validate() error {
+	req.Repository = strings.TrimSuffix(strings.TrimSpace(req.Repository), ".git")
+	if !repositoryPattern.MatchString(req.Repository) {
+		return errors.New("repository must be in the owner/name format")
+	}
+	return nil
+}
+
+// Owner returns the owner of the linked repository
+func (l *GitHubLink) Owner() string {
+	owner, _, _ := strings.Cut(l.Repository, "/")
+	return owner
+}
+
+// Name returns the name of the linked repository
+func (l *GitHubLink) Name() string {
+	_, name, _ := strings.Cut(l.Repository, "/")
+	return name
+}
+
+// MergeGitHubField merges a field of an issue and its task, such as the title. base is the
+// value both had when last synced, so the side whose value differs from it changed it. When
+// both sides changed it to different values, the side changed last wins and the merge
+// reports a conflict.
+func MergeGitHubField(base, issueValue, taskValue string, issueChangedAt, taskChangedAt time.Time) (merged string, conflict bool) {
+	switch {
+	case issueValue == taskValue, taskValue == base:
+		return issueValue, false
+	case issueValue == base:
+		return taskValue, false
+	case issueChangedAt.After(taskChangedAt):
+		return issueValue, true
+	default:
+		return taskValue, true
+	}
+}
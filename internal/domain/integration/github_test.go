@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkGitHubRequest_Validate(t *testing.T) {
+	req := &LinkGitHubRequest{Repository: " acme/todo-app.git "}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "acme/todo-app", req.Repository)
+
+	link := &GitHubLink{Repository: req.Repository}
+	assert.Equal(t, "acme", link.Owner())
+	assert.Equal(t, "todo-app", link.Name())
+
+	for _, repository := range []string{"", "acme", "acme/", "/app", "acme/app/issues", "-acme/app", "https://github.com/acme/app"} {
+		assert.Error(t, (&LinkGitHubRequest{Repository: repository}).Validate(), repository)
+	}
+}
+
+func TestMergeGitHubField(t *testing.T) {
+	earlier := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	tests := []struct {
+		name              string
+		base, issue, task string
+		issueAt, taskAt   time.Time
+		wantMerged        string
+		wantConflict      bool
+	}{
+		{"unchanged", "open", "open", "open", later, earlier, "open", false},
+		{"issue changed", "open", "closed", "open", earlier, later, "closed", false},
+		{"task changed", "open", "open", "closed", later, earlier, "closed", false},
+		{"same change", "Old", "New", "New", earlier, later, "New", false},
+		{"issue changed last", "Old", "Issue title", "Task title", later, earlier, "Issue title", true},
+		{"task changed last", "Old", "Issue title", "Task title", earlier, later, "Task title", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflict := MergeGitHubField(tt.base, tt.issue, tt.task, tt.issueAt, tt.taskAt)
+			assert.Equal(t, tt.wantMerged, merged)
+			assert.Equal(t, tt.wantConflict, conflict)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GoogleCalendarEventDuration is the length of the events of tasks due at a time of day.
+// Tasks due at midnight UTC, i.e. on a date, get all-day events.
+const GoogleCalendarEventDuration = 30 * time.Minute
+
+// GoogleCalendarConnection represents the Google Calendar a user's tasks with due dates are
+// synced with. The tokens are credentials and never returned by the API.
+type GoogleCalendarConnection struct {
+	UserID       uuid.UUID `json:"-"`
+	Email        string    `json:"email"`
+	CalendarID   string    `json:"calendar_id"`
+	AccessToken  string    `json:"-"`
+	RefreshToken string    `json:"-"`
+	TokenExpiry  time.Time `json:"-"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	// LastSyncedAt is when the calendar was last synced; LastSyncError is why the last sync
+	// failed, if it did
+	LastSyncedAt  *time.Time `json:"last_synced_at,omitempty"`
+	LastSyncError string     `json:"last_sync_error,omitempty"`
+	// SyncedTasks counts the tasks with an event; Conflicts counts the reschedules
+	// discarded because the task and its event were both rescheduled
+	SyncedTasks int `json:"synced_tasks"`
+	Conflicts   int `json:"conflicts"`
+}
+
+// GoogleCalendarAuthorization is where a user grants access to their Google Calendar. The
+// URL carries a one-time state tying the authorization back to the user.
+type GoogleCalendarAuthorization struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IsAllDay reports whether a task due at the time gets an all-day event
+func IsAllDay(dueDate time.Time) bool {
+	dueDate = dueDate.UTC()
+	return dueDate.Equal(dueDate.Truncate(24 * time.Hour))
+}
+
+// MergeDueDate merges the due date of a task with the start of its event, like
+// MergeGitHubField: base is the due date both had when last synced, and when both were
+// rescheduled to different times, the side changed last wins and the merge reports a
+// conflict.
+func MergeDueDate(base, eventDue, taskDue, eventChangedAt, taskChangedAt time.Time) (merged time.Time, conflict bool) {
+	switch {
+	case eventDue.Equal(taskDue), taskDue.Equal(base):
+		return eventDue, false
+	case eventDue.Equal(base):
+		return taskDue, false
+	case eventChangedAt.After(taskChangedAt):
+		return eventDue, true
+	default:
+		return taskDue, true
+	}
+}
@@ -0,0 +1,39 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllDay(t *testing.T) {
+	assert.True(t, IsAllDay(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, IsAllDay(time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC)))
+	// Midnight elsewhere is a time of day in UTC
+	assert.False(t, IsAllDay(time.Date(2026, 3, 10, 0, 0, 0, 0, time.FixedZone("CET", 3600))))
+}
+
+func TestMergeDueDate(t *testing.T) {
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	moved := base.Add(24 * time.Hour)
+	movedElsewhere := base.Add(48 * time.Hour)
+	earlier := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	merged, conflict := MergeDueDate(base, moved, base, later, earlier)
+	assert.Equal(t, moved, merged)
+	assert.False(t, conflict)
+
+	merged, conflict = MergeDueDate(base, base, moved, later, earlier)
+	assert.Equal(t, moved, merged)
+	assert.False(t, conflict)
+
+	merged, conflict = MergeDueDate(base, moved, movedElsewhere, earlier, later)
+	assert.Equal(t, movedElsewhere, merged)
+	assert.True(t, conflict)
+
+	merged, conflict = MergeDueDate(base, moved, movedElsewhere, later, earlier)
+	assert.Equal(t, moved, merged)
+	assert.True(t, conflict)
+}
@@ -0,0 +1,11 @@
+package integration
+
+// Names of the integrations users connect, which count against the integrations their plan
+// allows
+const (
+	NameSlack          = "slack"
+	NameTelegram       = "telegram"
+	NameGitHub         = "github"
+	NameGoogleCalendar = "google_calendar"
+	NameEmail          = "email"
+)
@@ -0,0 +1,136 @@
+package integration
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Task events a Slack connection can be notified of
+const (
+	SlackEventAssigned  = "assigned"  // a task was assigned to the user
+	SlackEventDueSoon   = "due_soon"  // a task of the user is coming due
+	SlackEventCompleted = "completed" // a task the user owns or is assigned to was completed
+)
+
+// SlackEvents lists every event a Slack connection can be notified of
+var SlackEvents = []string{SlackEventAssigned, SlackEventDueSoon, SlackEventCompleted}
+
+// Ways messages are delivered to Slack
+const (
+	SlackModeWebhook = "webhook" // through an incoming webhook bound to a channel
+	SlackModeBot     = "bot"     // with a bot token to a channel of the workspace
+)
+
+// SlackWebhookHost is the host of Slack incoming webhooks
+const SlackWebhookHost = "hooks.slack.com"
+
+// SlackConnection represents a user's connection to Slack. The webhook URL and bot token
+// are credentials and never returned by the API.
+type SlackConnection struct {
+	UserID     uuid.UUID `json:"-"`
+	Mode       string    `json:"mode"`
+	WebhookURL string    `json:"-"`
+	BotToken   string    `json:"-"`
+	Channel    string    `json:"channel,omitempty"` // channel bot messages are posted to
+	// TeamID and SlackUserID identify the user's Slack account, whose slash commands act
+	// on the user's tasks
+	TeamID      string    `json:"team_id,omitempty"`
+	SlackUserID string    `json:"slack_user_id,omitempty"`
+	Events      []string  `json:"events"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ConnectSlackRequest represents a request to connect Slack, through either an incoming
+// webhook or a bot token and channel
+type ConnectSlackRequest struct {
+	WebhookURL  string   `json:"webhook_url,omitempty"`
+	BotToken    string   `json:"bot_token,omitempty"`
+	Channel     string   `json:"channel,omitempty"`
+	TeamID      string   `json:"team_id,omitempty"`
+	SlackUserID string   `json:"slack_user_id,omitempty"`
+	Events      []string `json:"events,omitempty"` // defaults to every event
+}
+
+// SlashCommand represents a slash command invoked in Slack, such as /todo add Buy milk
+type SlashCommand struct {
+	TeamID  string
+	UserID  string // Slack user ID
+	Command string // e.g. /todo
+	Text    string // text following the command
+}
+
+// Validate validates connect Slack request
+func (req *ConnectSlackRequest) Validate() error {
+	webhook := strings.TrimSpace(req.WebhookURL) != ""
+	bot := strings.TrimSpace(req.BotToken) != ""
+
+	switch {
+	case webhook && bot:
+		return errors.New("webhook_url and bot_token are mutually exclusive")
+	case webhook:
+		u, err := url.Parse(req.WebhookURL)
+		if err != nil || u.Scheme != "https" || u.Host != SlackWebhookHost || !strings.HasPrefix(u.Path, "/services/") {
+			return errors.New("webhook_url must be a Slack incoming webhook URL")
+		}
+	case bot:
+		if !strings.HasPrefix(req.BotToken, "xoxb-") {
+			return errors.New("bot_token must be a Slack bot token")
+		}
+		if strings.TrimSpace(req.Channel) == "" {
+			return errors.New("channel is required with a bot token")
+		}
+	default:
+		return errors.New("webhook_url or bot_token is required")
+	}
+
+	if (req.TeamID == "") != (req.SlackUserID == "") {
+		return errors.New("team_id and slack_user_id must be set together")
+	}
+
+	for _, event := range req.Events {
+		if !slices.Contains(SlackEvents, event) {
+			return errors.New("invalid event: " + event)
+		}
+	}
+
+	return nil
+}
+
+// NewSlackConnection creates the user's connection from a validated request
+func NewSlackConnection(userID uuid.UUID, req *ConnectSlackRequest) *SlackConnection {
+	conn := &SlackConnection{
+		UserID:      userID,
+		Mode:        SlackModeWebhook,
+		WebhookURL:  req.WebhookURL,
+		TeamID:      req.TeamID,
+		SlackUserID: req.SlackUserID,
+		Events:      slices.Clone(req.Events),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if req.BotToken != "" {
+		conn.Mode = SlackModeBot
+		conn.BotToken = req.BotToken
+		conn.Channel = req.Channel
+	}
+	if len(conn.Events) == 0 {
+		conn.Events = slices.Clone(SlackEvents)
+	}
+	return conn
+}
+
+// Notifies reports whether the connection is notified of the event
+func (c *SlackConnection) Notifies(event string) bool {
+	return slices.Contains(c.Events, event)
+}
+
+// AcceptsCommandsFrom reports whether slash commands of the Slack user act on the user's tasks
+func (c *SlackConnection) AcceptsCommandsFrom(teamID, slackUserID string) bool {
+	return c.SlackUserID != "" && c.TeamID == teamID && c.SlackUserID == slackUserID
+}
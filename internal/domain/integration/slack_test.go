@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectSlackRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ConnectSlackRequest
+		wantErr string
+	}{
+		{"webhook", ConnectSlackRequest{WebhookURL: "https://hooks.slack.com/services/T0/B0/X"}, ""},
+		{"bot", ConnectSlackRequest{BotToken: "xoxb-1", Channel: "C1", TeamID: "T1", SlackUserID: "U1"}, ""},
+		{"neither", ConnectSlackRequest{}, "webhook_url or bot_token is required"},
+		{"both", ConnectSlackRequest{WebhookURL: "https://hooks.slack.com/services/T0/B0/X", BotToken: "xoxb-1"}, "webhook_url and bot_token are mutually exclusive"},
+		{"foreign webhook", ConnectSlackRequest{WebhookURL: "https://evil.example.com/services/X"}, "webhook_url must be a Slack incoming webhook URL"},
+		{"plain http webhook", ConnectSlackRequest{WebhookURL: "http://hooks.slack.com/services/T0/B0/X"}, "webhook_url must be a Slack incoming webhook URL"},
+		{"user token", ConnectSlackRequest{BotToken: "xoxp-1", Channel: "C1"}, "bot_token must be a Slack bot token"},
+		{"bot without channel", ConnectSlackRequest{BotToken: "xoxb-1"}, "channel is required with a bot token"},
+		{"user without team", ConnectSlackRequest{BotToken: "xoxb-1", Channel: "C1", SlackUserID: "U1"}, "team_id and slack_user_id must be set together"},
+		{"unknown event", ConnectSlackRequest{BotToken: "xoxb-1", Channel: "C1", Events: []string{"deleted"}}, "invalid event: deleted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSlackConnection_AcceptsCommandsFrom(t *testing.T) {
+	conn := &SlackConnection{TeamID: "T1", SlackUserID: "U1"}
+	assert.True(t, conn.AcceptsCommandsFrom("T1", "U1"))
+	assert.False(t, conn.AcceptsCommandsFrom("T2", "U1"))
+	assert.False(t, (&SlackConnection{}).AcceptsCommandsFrom("", ""))
+}
@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// phoneNumberPattern matches phone numbers in E.164 format, e.g. +14155550100
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// clockFormat is the format of the times of day quiet hours start and end at
+const clockFormat = "15:04"
+
+// SMSSettings represents the phone number a user verified and how they are sent SMS
+type SMSSettings struct {
+	UserID      uuid.UUID `json:"-"`
+	PhoneNumber string    `json:"phone_number"`
+	VerifiedAt  time.Time `json:"verified_at"`
+	// Reminders is whether the user opted into reminders about high-priority tasks
+	Reminders  bool        `json:"reminders"`
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// QuietHours is the time of day no SMS is sent, such as 22:00 to 07:00, in the user's time
+// zone
+type QuietHours struct {
+	Start    string `json:"start"`     // HH:MM
+	End      string `json:"end"`       // HH:MM, before Start for quiet hours spanning midnight
+	TimeZone string `json:"time_zone"` // IANA time zone, e.g. Europe/Berlin; defaults to UTC
+}
+
+// SMSVerification is a code sent to a phone number to verify the user owns it
+type SMSVerification struct {
+	PhoneNumber string    `json:"phone_number"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// SMSMessage is an SMS sent to a user, with its delivery status as reported by Twilio
+type SMSMessage struct {
+	SID       string    `json:"sid"`
+	UserID    uuid.UUID `json:"-"`
+	TaskID    uuid.UUID `json:"task_id"`
+	To        string    `json:"to"`
+	Status    string    `json:"status"`               // queued, sent, delivered, undelivered, or failed
+	ErrorCode int       `json:"error_code,omitempty"` // Twilio error code of failed deliveries
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VerifySMSPhoneRequest represents a request to send a verification code to a phone number
+type VerifySMSPhoneRequest struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+// ConfirmSMSPhoneRequest represents a request to verify a phone number with the code sent to it
+type ConfirmSMSPhoneRequest struct {
+	Code string `json:"code"`
+}
+
+// UpdateSMSSettingsRequest represents a request to update SMS settings. Omitted fields are
+// left unchanged; quiet hours with no start and end are removed.
+type UpdateSMSSettingsRequest struct {
+	Reminders  *bool       `json:"reminders,omitempty"`
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// Validate validates verify SMS phone request
+func (req *VerifySMSPhoneRequest) Validate() error {
+	if !phoneNumberPattern.MatchString(req.PhoneNumber) {
+		return errors.New("phone_number must be in E.164 format, e.g. +14155550100")
+	}
+	return nil
+}
+
+// Validate validates update SMS settings request
+func (req *UpdateSMSSettingsRequest) Validate() error {
+	if req.QuietHours != nil && !req.QuietHours.IsEmpty() {
+		return req.QuietHours.Validate()
+	}
+	return nil
+}
+
+// Apply updates the settings with the fields set in the request
+func (req *UpdateSMSSettingsRequest) Apply(s *SMSSettings) {
+	if req.Reminders != nil {
+		s.Reminders = *req.Reminders
+	}
+	if req.QuietHours != nil {
+		if req.QuietHours.IsEmpty() {
+			s.QuietHours = nil
+		} else {
+			quiet := *req.QuietHours
+			if quiet.TimeZone == "" {
+				quiet.TimeZone = "UTC"
+			}
+			s.QuietHours = &quiet
+		}
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// IsEmpty reports whether the quiet hours have no start and end
+func (q *QuietHours) IsEmpty() bool {
+	return q.Start == "" && q.End == ""
+}
+
+// Validate validates the quiet hours
+func (q *QuietHours) Validate() error {
+	if _, err := time.Parse(clockFormat, q.Start); err != nil {
+		return errors.New("quiet_hours.start must be a time of day, e.g. 22:00")
+	}
+	if _, err := time.Parse(clockFormat, q.End); err != nil {
+		return errors.New("quiet_hours.end must be a time of day, e.g. 07:00")
+	}
+	if q.Start == q.End {
+		return errors.New("quiet_hours.start and quiet_hours.end must differ")
+	}
+	if _, err := time.LoadLocation(q.TimeZone); err != nil {
+		return errors.New("quiet_hours.time_zone must be an IANA time zone, e.g. Europe/Berlin")
+	}
+	return nil
+}
+
+// Contains reports whether the time falls in the quiet hours, which include their start
+// but not their end. Invalid quiet hours contain no time.
+func (q *QuietHours) Contains(t time.Time) bool {
+	start, err := time.Parse(clockFormat, q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(clockFormat, q.End)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	// Quiet hours spanning midnight
+	return minute >= from || minute < to
+}
@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySMSPhoneRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&VerifySMSPhoneRequest{PhoneNumber: "+14155550100"}).Validate())
+	for _, number := range []string{"", "14155550100", "+1 415 555 0100", "+0155550100", "+1234"} {
+		assert.Error(t, (&VerifySMSPhoneRequest{PhoneNumber: number}).Validate(), number)
+	}
+}
+
+func TestUpdateSMSSettingsRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quiet   *QuietHours
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"removed", &QuietHours{}, ""},
+		{"overnight", &QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"}, ""},
+		{"utc by default", &QuietHours{Start: "12:00", End: "13:30"}, ""},
+		{"invalid start", &QuietHours{Start: "25:00", End: "07:00"}, "quiet_hours.start must be a time of day, e.g. 22:00"},
+		{"missing end", &QuietHours{Start: "22:00"}, "quiet_hours.end must be a time of day, e.g. 07:00"},
+		{"empty", &QuietHours{Start: "22:00", End: "22:00"}, "quiet_hours.start and quiet_hours.end must differ"},
+		{"unknown time zone", &QuietHours{Start: "22:00", End: "07:00", TimeZone: "Mars/Olympus"}, "quiet_hours.time_zone must be an IANA time zone, e.g. Europe/Berlin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&UpdateSMSSettingsRequest{QuietHours: tt.quiet}).Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	overnight := &QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"}
+	// Berlin is UTC+1 in winter
+	assert.True(t, overnight.Contains(time.Date(2026, 1, 10, 21, 0, 0, 0, time.UTC)))
+	assert.True(t, overnight.Contains(time.Date(2026, 1, 10, 5, 59, 0, 0, time.UTC)))
+	assert.False(t, overnight.Contains(time.Date(2026, 1, 10, 6, 0, 0, 0, time.UTC)))
+	assert.False(t, overnight.Contains(time.Date(2026, 1, 10, 20, 59, 0, 0, time.UTC)))
+
+	lunch := &QuietHours{Start: "12:00", End: "13:00", TimeZone: "UTC"}
+	assert.True(t, lunch.Contains(time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, lunch.Contains(time.Date(2026, 1, 10, 13, 0, 0, 0, time.UTC)))
+	assert.False(t, lunch.Contains(time.Date(2026, 1, 10, 11, 59, 0, 0, time.UTC)))
+}
+
+func TestUpdateSMSSettingsRequest_Apply(t *testing.T) {
+	reminders := true
+	settings := &SMSSettings{}
+	(&UpdateSMSSettingsRequest{Reminders: &reminders, QuietHours: &QuietHours{Start: "22:00", End: "07:00"}}).Apply(settings)
+	assert.True(t, settings.Reminders)
+	assert.Equal(t, &QuietHours{Start: "22:00", End: "07:00", TimeZone: "UTC"}, settings.QuietHours)
+
+	// Omitted fields are left unchanged, and empty quiet hours are removed
+	(&UpdateSMSSettingsRequest{}).Apply(settings)
+	assert.True(t, settings.Reminders)
+	assert.NotNil(t, settings.QuietHours)
+	(&UpdateSMSSettingsRequest{QuietHours: &QuietHours{}}).Apply(settings)
+	assert.Nil(t, settings.QuietHours)
+}
@@ -0,0 +1,39 @@
+package integration
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TelegramLink represents a Telegram chat linked to a user, through which the user manages
+// their tasks
+type TelegramLink struct {
+	UserID   uuid.UUID `json:"-"`
+	ChatID   int64     `json:"chat_id"`
+	Username string    `json:"username,omitempty"` // Telegram username, if the user has one
+	// Scopes are the task scopes of the token that linked the chat, limiting what the chat
+	// may do
+	Scopes   []string  `json:"scopes"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// TelegramLinkCode is a one-time code linking the Telegram chat it is sent from to a user
+type TelegramLinkCode struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url,omitempty"` // deep link starting the bot with the code
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BotSession is the user a linked chat acts as, with the scopes granted when linking. It
+// bridges chat messages, which carry no token, to the task service.
+type BotSession struct {
+	UserID uuid.UUID
+	Scopes []string
+}
+
+// HasScope reports whether the session may act with the scope
+func (s *BotSession) HasScope(scope string) bool {
+	return slices.Contains(s.Scopes, scope)
+}
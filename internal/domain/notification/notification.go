@@ -0,0 +1,230 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// Events users are notified of
+const (
+	EventDueSoon   = "due_soon"  // a task of the user is coming due
+	EventAssigned  = "assigned"  // a task was assigned to the user by someone else
+	EventCompleted = "completed" // a task the user owns or is assigned to was completed
+	EventDigest    = "digest"    // periodic summary of open tasks
+	EventEscalated = "escalated" // an overdue task was escalated by a rule of its workspace
+)
+
+// Channels notifications are sent through
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelSlack = "slack"
+	ChannelSMS   = "sms"
+)
+
+// Channels lists the channels notifications of each event are sent through
+var Channels = map[string][]string{
+	EventDueSoon:   {ChannelEmail, ChannelPush, ChannelSlack, ChannelSMS},
+	EventAssigned:  {ChannelPush, ChannelSlack},
+	EventCompleted: {ChannelSlack},
+	EventDigest:    {ChannelEmail},
+	EventEscalated: {ChannelEmail},
+}
+
+// Filter reports whether the user wants notifications of the event through the channel
+type Filter func(userID uuid.UUID, event, channel string) bool
+
+// AllowAll is the filter sending every notification
+func AllowAll(uuid.UUID, string, string) bool { return true }
+
+// Preferences holds the emails a user opted into, the time zone they live in, and whether
+// new tasks are checked for duplicates. Account emails, such as password resets, are always
+// sent.
+type Preferences struct {
+	Reminders bool   `json:"reminders"` // reminders about tasks coming due
+	Digest    bool   `json:"digest"`    // periodic summary of open tasks
+	TimeZone  string `json:"time_zone"` // IANA time zone, e.g. Asia/Jakarta, days such as "today" are computed in
+	// DuplicateCheck rejects new tasks with the same title as a recent open task, unless forced
+	DuplicateCheck bool      `json:"duplicate_check"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+	// Muted holds the notifications other than emails the user turned off, keyed by
+	// event:channel. It is replaced rather than modified, as copies of the preferences share it.
+	Muted map[string]bool `json:"-"`
+}
+
+// Settings is the notification settings matrix, whether the user wants notifications of each
+// event through each channel sending it
+type Settings map[string]map[string]bool
+
+// UpdateSettingsRequest represents a request to update notification settings, keyed by event
+// and channel. Omitted events and channels are left unchanged.
+type UpdateSettingsRequest map[string]map[string]bool
+
+// UpdatePreferencesRequest represents a request to update notification preferences.
+// Omitted fields are left unchanged.
+type UpdatePreferencesRequest struct {
+	Reminders      *bool   `json:"reminders,omitempty"`
+	Digest         *bool   `json:"digest,omitempty"`
+	TimeZone       *string `json:"time_zone,omitempty"`
+	DuplicateCheck *bool   `json:"duplicate_check,omitempty"`
+}
+
+// DefaultPreferences returns the preferences of users who never changed them, opted into
+// every email and living in UTC
+func DefaultPreferences() *Preferences {
+	return &Preferences{Reminders: true, Digest: true, TimeZone: "UTC"}
+}
+
+// Location returns the time zone of the preferences, UTC when it is not set
+func (p *Preferences) Location() *time.Location {
+	if p.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Allows reports whether the user wants notifications of the event through the channel.
+// Reminder and digest emails follow the reminders and digest preferences.
+func (p *Preferences) Allows(event, channel string) bool {
+	if channel == ChannelEmail {
+		switch event {
+		case EventDueSoon:
+			return p.Reminders
+		case EventDigest:
+			return p.Digest
+		}
+	}
+	return !p.Muted[event+":"+channel]
+}
+
+// Settings returns the notification settings matrix of the preferences
+func (p *Preferences) Settings() Settings {
+	settings := make(Settings, len(Channels))
+	for event, channels := range Channels {
+		settings[event] = make(map[string]bool, len(channels))
+		for _, channel := range channels {
+			settings[event][channel] = p.Allows(event, channel)
+		}
+	}
+	return settings
+}
+
+// Validate validates the update preferences request
+func (req *UpdatePreferencesRequest) Validate() error {
+	if req.TimeZone != nil {
+		if *req.TimeZone == "" {
+			return errors.New("time_zone cannot be empty")
+		}
+		if _, err := time.LoadLocation(*req.TimeZone); err != nil {
+			return errors.New("invalid time_zone: " + *req.TimeZone)
+		}
+	}
+	return nil
+}
+
+// Apply updates the preferences with the fields set in the request
+func (req *UpdatePreferencesRequest) Apply(p *Preferences) {
+	if req.Reminders != nil {
+		p.Reminders = *req.Reminders
+	}
+	if req.Digest != nil {
+		p.Digest = *req.Digest
+	}
+	if req.TimeZone != nil {
+		p.TimeZone = *req.TimeZone
+	}
+	if req.DuplicateCheck != nil {
+		p.DuplicateCheck = *req.DuplicateCheck
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// Validate validates the update settings request
+func (req UpdateSettingsRequest) Validate() error {
+	if len(req) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for event, channels := range req {
+		supported, ok := Channels[event]
+		if !ok {
+			return fmt.Errorf("unknown event: %s", event)
+		}
+		for channel := range channels {
+			if !slices.Contains(supported, channel) {
+				return fmt.Errorf("%s notifications are not sent through %s", event, channel)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply updates the preferences with the settings in the request
+func (req UpdateSettingsRequest) Apply(p *Preferences) {
+	muted := maps.Clone(p.Muted)
+	if muted == nil {
+		muted = make(map[string]bool)
+	}
+	for event, channels := range req {
+		for channel, enabled := range channels {
+			switch {
+			case channel == ChannelEmail && event == EventDueSoon:
+				p.Reminders = enabled
+			case channel == ChannelEmail && event == EventDigest:
+				p.Digest = enabled
+			case enabled:
+				delete(muted, event+":"+channel)
+			default:
+				muted[event+":"+channel] = true
+			}
+		}
+	}
+	p.Muted = muted
+	p.UpdatedAt = time.Now()
+}
+
+// Digest summarizes the open tasks of a user on a day of their time zone, as sent in digest
+// emails
+type Digest struct {
+	Date     string       `json:"date"`      // day of the digest in the user's time zone
+	Open     int          `json:"open"`      // open tasks, those below included
+	Overdue  []*task.Task `json:"overdue"`   // due before the time, earliest first
+	DueToday []*task.Task `json:"due_today"` // due later on the day, earliest first
+}
+
+// NewDigest compiles the digest of the open tasks at the time, with days in the time zone
+func NewDigest(open []*task.Task, now time.Time, loc *time.Location) *Digest {
+	local := now.In(loc)
+	tomorrow := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+	digest := &Digest{
+		Date:     local.Format(time.DateOnly),
+		Open:     len(open),
+		Overdue:  []*task.Task{},
+		DueToday: []*task.Task{},
+	}
+
+	for _, t := range open {
+		switch {
+		case t.DueDate == nil:
+		case t.DueDate.Before(now):
+			digest.Overdue = append(digest.Overdue, t)
+		case t.DueDate.Before(tomorrow):
+			digest.DueToday = append(digest.DueToday, t)
+		}
+	}
+
+	byDueDate := func(a, b *task.Task) int { return a.DueDate.Compare(*b.DueDate) }
+	slices.SortStableFunc(digest.Overdue, byDueDate)
+	slices.SortStableFunc(digest.DueToday, byDueDate)
+	return digest
+}
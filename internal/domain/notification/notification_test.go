@@ -0,0 +1,125 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdatePreferencesRequest_Apply(t *testing.T) {
+	prefs := DefaultPreferences()
+	assert.True(t, prefs.Reminders)
+	assert.True(t, prefs.Digest)
+
+	off := false
+	(&UpdatePreferencesRequest{Digest: &off}).Apply(prefs)
+	assert.True(t, prefs.Reminders)
+	assert.False(t, prefs.Digest)
+	assert.False(t, prefs.UpdatedAt.IsZero())
+
+	// Duplicate checks are off until turned on
+	assert.False(t, prefs.DuplicateCheck)
+	on := true
+	(&UpdatePreferencesRequest{DuplicateCheck: &on}).Apply(prefs)
+	assert.True(t, prefs.DuplicateCheck)
+	assert.False(t, prefs.Digest)
+}
+
+func TestUpdatePreferencesRequest_TimeZone(t *testing.T) {
+	prefs := DefaultPreferences()
+	assert.Equal(t, time.UTC, prefs.Location())
+
+	jakarta := "Asia/Jakarta"
+	req := &UpdatePreferencesRequest{TimeZone: &jakarta}
+	assert.NoError(t, req.Validate())
+	req.Apply(prefs)
+	assert.Equal(t, "Asia/Jakarta", prefs.Location().String())
+
+	for _, invalid := range []string{"", "Mars/Olympus_Mons"} {
+		assert.Error(t, (&UpdatePreferencesRequest{TimeZone: &invalid}).Validate())
+	}
+}
+
+func TestUpdateSettingsRequest_Apply(t *testing.T) {
+	prefs := DefaultPreferences()
+	for event, channels := range prefs.Settings() {
+		for channel, enabled := range channels {
+			assert.True(t, enabled, "%s through %s", event, channel)
+		}
+	}
+
+	req := UpdateSettingsRequest{
+		EventDueSoon:  {ChannelEmail: false, ChannelSlack: false},
+		EventAssigned: {ChannelPush: false},
+	}
+	assert.NoError(t, req.Validate())
+	shared := *prefs
+	req.Apply(prefs)
+
+	// Reminder and digest emails follow the email preferences
+	assert.False(t, prefs.Reminders)
+	assert.True(t, prefs.Digest)
+	assert.False(t, prefs.Allows(EventDueSoon, ChannelSlack))
+	assert.True(t, prefs.Allows(EventDueSoon, ChannelPush))
+	assert.False(t, prefs.Settings()[EventAssigned][ChannelPush])
+	assert.True(t, prefs.Settings()[EventAssigned][ChannelSlack])
+	// Copies taken before are left unchanged
+	assert.True(t, shared.Allows(EventAssigned, ChannelPush))
+
+	UpdateSettingsRequest{EventAssigned: {ChannelPush: true}}.Apply(prefs)
+	assert.True(t, prefs.Allows(EventAssigned, ChannelPush))
+	assert.False(t, prefs.Allows(EventDueSoon, ChannelSlack))
+}
+
+func TestUpdateSettingsRequest_Validate(t *testing.T) {
+	for _, invalid := range []UpdateSettingsRequest{
+		{},
+		{"mentioned": {ChannelEmail: true}},
+		{EventAssigned: {ChannelEmail: true}},
+		{EventDueSoon: {"in_app": true}},
+	} {
+		assert.Error(t, invalid.Validate())
+	}
+}
+
+func TestNewDigest(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	now := time.Date(2024, 1, 15, 5, 0, 0, 0, jakarta)
+	at := func(hour int) time.Time { return time.Date(2024, 1, 15, hour, 0, 0, 0, jakarta) }
+	due := func(title string, at time.Time) *task.Task {
+		return &task.Task{Title: title, DueDate: &at}
+	}
+	open := []*task.Task{
+		{Title: "Someday"},
+		due("Tonight", at(22)),
+		due("Yesterday", now.Add(-24*time.Hour)),
+		due("Before dawn", at(4)),
+		due("Tomorrow", at(24)),
+		due("Afternoon", at(15)),
+		due("Breakfast", at(6)),
+	}
+	titles := func(tasks []*task.Task) []string {
+		var titles []string
+		for _, t := range tasks {
+			titles = append(titles, t.Title)
+		}
+		return titles
+	}
+
+	digest := NewDigest(open, now, jakarta)
+	assert.Equal(t, "2024-01-15", digest.Date)
+	assert.Equal(t, 7, digest.Open)
+	assert.Equal(t, []string{"Yesterday", "Before dawn"}, titles(digest.Overdue))
+	assert.Equal(t, []string{"Breakfast", "Afternoon", "Tonight"}, titles(digest.DueToday))
+
+	// Days follow the time zone: it is still the 14th in UTC, ending at 7:00 in Jakarta
+	digest = NewDigest(open, now, time.UTC)
+	assert.Equal(t, "2024-01-14", digest.Date)
+	assert.Equal(t, []string{"Breakfast"}, titles(digest.DueToday))
+
+	// Lists are empty rather than null without tasks
+	assert.NotNil(t, NewDigest(nil, now, time.UTC).Overdue)
+}
@@ -0,0 +1,149 @@
+package privacy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/attachment"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+
+	"github.com/google/uuid"
+)
+
+// Profile represents the account data of an exported user
+type Profile struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Membership represents an exported user's membership in a workspace
+type Membership struct {
+	WorkspaceID   uuid.UUID      `json:"workspace_id"`
+	WorkspaceName string         `json:"workspace_name"`
+	Role          workspace.Role `json:"role"`
+	JoinedAt      time.Time      `json:"joined_at"`
+}
+
+// Export holds all the data kept about a user
+type Export struct {
+	ExportedAt time.Time         `json:"exported_at"`
+	Profile    Profile           `json:"profile"`
+	Tasks      []*task.Task      `json:"tasks"`      // tasks the user owns, including archived tasks
+	Activity   []*activity.Entry `json:"activity"`   // changes the user made to any task
+	Workspaces []Membership      `json:"workspaces"` // workspaces the user belongs to
+	// Projects lists the projects the user created in workspaces they belong to
+	Projects []*workspace.Project `json:"projects"`
+	// Attachments lists the files the user attached to tasks, without their content
+	Attachments []*attachment.Attachment `json:"attachments"`
+}
+
+// WriteZip writes the export as a ZIP archive with one JSON file per kind of data
+func (e *Export) WriteZip(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", e.Profile},
+		{"tasks.json", e.Tasks},
+		{"activity.json", e.Activity},
+		{"workspaces.json", e.Workspaces},
+		{"projects.json", e.Projects},
+		{"attachments.json", e.Attachments},
+	}
+
+	for _, file := range files {
+		fw, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: e.ExportedAt})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(fw)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Statuses of data export archives
+const (
+	ArchivePending = "pending" // waiting for a worker, or for another attempt
+	ArchiveRunning = "running"
+	ArchiveReady   = "ready" // the archive can be downloaded until it expires
+	ArchiveFailed  = "failed"
+	ArchiveExpired = "expired" // the archive was deleted
+)
+
+var (
+	// ErrArchiveNotFound is returned for archives of other users, or no longer kept
+	ErrArchiveNotFound = errors.New("data export not found")
+	// ErrArchivesUnavailable is returned when archives cannot be stored, without object
+	// storage or a job queue
+	ErrArchivesUnavailable = errors.New("data export archives are unavailable")
+)
+
+// Archive is a ZIP archive of an export, built in the background and kept in object
+// storage until it expires
+type Archive struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"-"`
+	Status      string     `json:"status"`
+	Size        int64      `json:"size,omitempty"` // in bytes, once ready
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"` // presigned, valid until the archive expires
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Key         string     `json:"-"` // object key of the archive
+	JobID       uuid.UUID  `json:"-"` // job building the archive
+}
+
+// NewArchive creates a new pending archive of the user's data
+func NewArchive(userID uuid.UUID) *Archive {
+	id := uuid.New()
+	return &Archive{
+		ID:        id,
+		UserID:    userID,
+		Status:    ArchivePending,
+		Key:       "exports/" + userID.String() + "/" + id.String() + ".zip",
+		CreatedAt: time.Now(),
+	}
+}
+
+// Filename returns the name archives are downloaded under
+func (a *Archive) Filename() string {
+	return "export-" + a.CreatedAt.UTC().Format("20060102") + ".zip"
+}
+
+// ErasureRecord records that an account was erased. It identifies the user by ID only, so
+// it holds no personal data.
+type ErasureRecord struct {
+	ID                uuid.UUID `json:"id"`
+	UserID            uuid.UUID `json:"user_id"`
+	TenantID          uuid.UUID `json:"tenant_id"`
+	TasksDeleted      int       `json:"tasks_deleted"`
+	TasksAnonymized   int       `json:"tasks_anonymized"`
+	WorkspacesDeleted int       `json:"workspaces_deleted"`
+	ErasedAt          time.Time `json:"erased_at"`
+}
+
+// NewErasureRecord creates a new erasure record instance
+func NewErasureRecord(userID, tenantID uuid.UUID) *ErasureRecord {
+	return &ErasureRecord{
+		ID:       uuid.New(),
+		UserID:   userID,
+		TenantID: tenantID,
+		ErasedAt: time.Now(),
+	}
+}
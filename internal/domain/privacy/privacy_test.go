@@ -0,0 +1,88 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/attachment"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport_WriteZip(t *testing.T) {
+	userID := uuid.New()
+	exported := task.NewTask("Exported task", userID)
+
+	export := &Export{
+		ExportedAt: time.Now(),
+		Profile:    Profile{ID: userID, Email: "jane.smith@example.com"},
+		Tasks:      []*task.Task{exported},
+		Activity:   []*activity.Entry{activity.NewEntry(exported.ID, userID, activity.ActionCreated)},
+		Workspaces: []Membership{{WorkspaceID: uuid.New(), WorkspaceName: "Platform", Role: workspace.RoleMember}},
+		Projects:   []*workspace.Project{workspace.NewProject(uuid.New(), "Launch", userID)},
+		Attachments: []*attachment.Attachment{attachment.NewAttachment(exported.ID, userID,
+			&attachment.CreateAttachmentRequest{Filename: "notes.txt", ContentType: "text/plain", Size: 5})},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteZip(&buf))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[file.Name] = content
+	}
+	require.Len(t, files, 6)
+
+	var profile Profile
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "jane.smith@example.com", profile.Email)
+
+	var tasks []*task.Task
+	require.NoError(t, json.Unmarshal(files["tasks.json"], &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Exported task", tasks[0].Title)
+
+	assert.Contains(t, string(files["activity.json"]), `"action": "created"`)
+	assert.Contains(t, string(files["workspaces.json"]), `"workspace_name": "Platform"`)
+	assert.Contains(t, string(files["projects.json"]), `"name": "Launch"`)
+	assert.Contains(t, string(files["attachments.json"]), `"filename": "notes.txt"`)
+}
+
+func TestNewErasureRecord(t *testing.T) {
+	userID := uuid.New()
+	tenantID := uuid.New()
+
+	record := NewErasureRecord(userID, tenantID)
+
+	assert.NotEqual(t, uuid.Nil, record.ID)
+	assert.Equal(t, userID, record.UserID)
+	assert.Equal(t, tenantID, record.TenantID)
+	assert.False(t, record.ErasedAt.IsZero())
+}
+
+func TestNewArchive(t *testing.T) {
+	userID := uuid.New()
+
+	archive := NewArchive(userID)
+
+	assert.Equal(t, ArchivePending, archive.Status)
+	assert.Equal(t, "exports/"+userID.String()+"/"+archive.ID.String()+".zip", archive.Key)
+	assert.Equal(t, "export-"+archive.CreatedAt.UTC().Format("20060102")+".zip", archive.Filename())
+}
@@ -0,0 +1,207 @@
+package report
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// Frequencies of stats webhooks
+const (
+	FrequencyDaily  = "daily"  // the previous UTC day, delivered once it ends
+	FrequencyWeekly = "weekly" // the previous week, Monday to Sunday, delivered once it ends
+)
+
+// Statuses of deliveries
+const (
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// SecretPrefix starts every webhook signing secret, so leaked secrets are easy to recognize
+const SecretPrefix = "whsec_"
+
+// SecretRotationGrace is how long deliveries stay signed with the previous secret of a
+// webhook as well, once it is rotated, so endpoints can switch to the new one
+const SecretRotationGrace = 24 * time.Hour
+
+// ErrWebhookNotFound is returned for webhooks that do not exist or belong to another user
+var ErrWebhookNotFound = errors.New("stats webhook not found")
+
+// Webhook pushes the task statistics of its user, or of a workspace, to an endpoint every
+// day or week. Deliveries are signed with a secret returned once, when the webhook is
+// created or its secret rotated.
+type Webhook struct {
+	ID                      uuid.UUID  `json:"id"`
+	UserID                  uuid.UUID  `json:"-"`
+	WorkspaceID             *uuid.UUID `json:"workspace_id"`
+	URL                     string     `json:"url"`
+	Frequency               string     `json:"frequency"`
+	Secret                  string     `json:"-"`
+	PreviousSecret          string     `json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+	Enabled                 bool       `json:"enabled"`
+	LastDelivery            *Delivery  `json:"last_delivery"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
+
+// CreatedWebhook is a newly created webhook along with its signing secret
+type CreatedWebhook struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookRequest represents a request to create or replace a stats webhook
+type WebhookRequest struct {
+	URL         string     `json:"url"`
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty"` // the user's own tasks when unset
+	Frequency   string     `json:"frequency"`
+	Enabled     *bool      `json:"enabled,omitempty"` // defaults to true
+}
+
+// Delivery records the delivery of the statistics of a period to a webhook
+type Delivery struct {
+	ID          uuid.UUID `json:"id"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// StatsPayload is the body of a delivery: the statistics of the tasks of a user or
+// workspace, with daily counts covering the period
+type StatsPayload struct {
+	Type        string      `json:"type"` // stats.daily or stats.weekly
+	WebhookID   uuid.UUID   `json:"webhook_id"`
+	UserID      *uuid.UUID  `json:"user_id,omitempty"`
+	WorkspaceID *uuid.UUID  `json:"workspace_id,omitempty"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Stats       *task.Stats `json:"stats"`
+}
+
+// Validate validates the webhook request, defaulting the frequency to daily
+func (req *WebhookRequest) Validate() error {
+	req.URL = strings.TrimSpace(req.URL)
+
+	if req.URL == "" {
+		return errors.New("url is required")
+	}
+	if len(req.URL) > 2048 {
+		return errors.New("url must be at most 2048 characters")
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return errors.New("url must be an https URL")
+	}
+	if isInternalHost(u.Hostname()) {
+		return errors.New("url must not point to a local or private address")
+	}
+
+	switch req.Frequency {
+	case "":
+		req.Frequency = FrequencyDaily
+	case FrequencyDaily, FrequencyWeekly:
+	default:
+		return errors.New("frequency must be daily or weekly")
+	}
+
+	return nil
+}
+
+// NewWebhook creates a webhook of the user from a validated request
+func NewWebhook(userID uuid.UUID, req *WebhookRequest, secret string) *Webhook {
+	now := time.Now()
+	w := &Webhook{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: now,
+	}
+	w.Apply(req)
+	w.UpdatedAt = now
+	return w
+}
+
+// Apply replaces the settings of the webhook with those of a validated request
+func (w *Webhook) Apply(req *WebhookRequest) {
+	w.URL = req.URL
+	w.WorkspaceID = req.WorkspaceID
+	w.Frequency = req.Frequency
+	w.Enabled = req.Enabled == nil || *req.Enabled
+	w.UpdatedAt = time.Now()
+}
+
+// RotateSecret replaces the signing secret of the webhook, keeping the current one as the
+// previous secret for SecretRotationGrace
+func (w *Webhook) RotateSecret(secret string, now time.Time) {
+	expiresAt := now.Add(SecretRotationGrace)
+	w.PreviousSecret = w.Secret
+	w.PreviousSecretExpiresAt = &expiresAt
+	w.Secret = secret
+	w.UpdatedAt = now
+}
+
+// SigningSecrets returns the secrets deliveries are signed with at the time: the current
+// secret, followed by the previous one until it expires
+func (w *Webhook) SigningSecrets(now time.Time) []string {
+	secrets := []string{w.Secret}
+	if w.PreviousSecret != "" && w.PreviousSecretExpiresAt != nil && now.Before(*w.PreviousSecretExpiresAt) {
+		secrets = append(secrets, w.PreviousSecret)
+	}
+	return secrets
+}
+
+// Period returns the last period of the webhook to end before the day of the time: the day
+// before for daily webhooks, and the last week from Monday to Sunday for weekly ones. It
+// returns nil when that period ended before the webhook was created.
+func (w *Webhook) Period(now time.Time) *task.StatsRange {
+	today := startOfDay(now)
+	r := &task.StatsRange{From: today.AddDate(0, 0, -1), To: today.AddDate(0, 0, -1)}
+	if w.Frequency == FrequencyWeekly {
+		// Days since the last Monday, which starts the week in progress
+		sinceMonday := (int(today.Weekday()) + 6) % 7
+		r.To = today.AddDate(0, 0, -sinceMonday-1)
+		r.From = r.To.AddDate(0, 0, -6)
+	}
+
+	if r.To.Before(startOfDay(w.CreatedAt)) {
+		return nil
+	}
+	return r
+}
+
+// EventType returns the type of the payloads of the webhook
+func (w *Webhook) EventType() string {
+	return "stats." + w.Frequency
+}
+
+// isInternalHost reports whether the host is a name or address of the local machine or a
+// private network, which webhooks must not reach. Names resolving to such addresses are
+// refused by the webhook client when it connects.
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified()
+}
+
+// startOfDay returns the start of the UTC day of the time
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,342 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema URNs of the resources and messages of SCIM 2.0 (RFC 7643 and RFC 7644)
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// ContentType is the media type of SCIM requests and responses
+const ContentType = "application/scim+json"
+
+// Pagination of user listings
+const (
+	DefaultCount = 100
+	MaxCount     = 200
+)
+
+// Errors of SCIM requests, wrapped with details of what was wrong
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUniqueness    = errors.New("userName is already in use")
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrInvalidPath   = errors.New("invalid path")
+)
+
+// Name is the name of a user
+type Name struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// Email is an email address of a user. Users have a single address, their userName.
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Meta holds the metadata of a resource
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// User is the SCIM representation of a user. The userName is the email the user logs in
+// with; emails in requests are ignored.
+type User struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"` // ID of the user at the identity provider
+	UserName    string   `json:"userName"`
+	Name        *Name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []Email  `json:"emails,omitempty"`
+	Active      *bool    `json:"active,omitempty"` // defaults to true in requests
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// ListResponse is a page of users
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []*User  `json:"Resources"`
+}
+
+// PatchOperation is an operation of a PATCH request. Value is an object of attributes when
+// Path is empty.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PatchRequest is a PATCH request changing some attributes of a user
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// Error is the body of error responses
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// Filter is a filter of user listings. Only equality on one attribute is supported, as
+// identity providers use to look up users before creating them.
+type Filter struct {
+	Attribute string // userName, externalId, or emails.value
+	Value     string
+}
+
+// filterPattern matches filters such as userName eq "jane@example.com"
+var filterPattern = regexp.MustCompile(`(?i)^\s*([a-z0-9:.]+)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// NewListResponse creates a page of users starting at the 1-based index
+func NewListResponse(users []*User, total, startIndex int) *ListResponse {
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(users),
+		Resources:    append(make([]*User, 0, len(users)), users...),
+	}
+}
+
+// NewError creates the body of an error response
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+// ParseFilter parses a filter. An empty filter returns nil, matching every user.
+func ParseFilter(filter string) (*Filter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	match := filterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return nil, fmt.Errorf("%w: only eq comparisons such as userName eq \"jane@example.com\" are supported", ErrInvalidFilter)
+	}
+	value, err := strconv.Unquote(match[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid string %s", ErrInvalidFilter, match[2])
+	}
+
+	attribute := strings.TrimPrefix(strings.ToLower(match[1]), strings.ToLower(SchemaUser)+":")
+	switch attribute {
+	case "username":
+		return &Filter{Attribute: "userName", Value: value}, nil
+	case "externalid":
+		return &Filter{Attribute: "externalId", Value: value}, nil
+	case "emails.value", "emails":
+		return &Filter{Attribute: "emails.value", Value: value}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported attribute %s", ErrInvalidFilter, match[1])
+	}
+}
+
+// Matches reports whether the user matches the filter. userName and emails are compared
+// case-insensitively.
+func (f *Filter) Matches(u *User) bool {
+	if f == nil {
+		return true
+	}
+	switch f.Attribute {
+	case "externalId":
+		return u.ExternalID == f.Value
+	default:
+		return strings.EqualFold(u.UserName, f.Value)
+	}
+}
+
+// IsActive reports whether the user is active, which they are unless set otherwise
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Normalize lowercases the userName, which is an email address
+func (u *User) Normalize() {
+	u.UserName = strings.ToLower(strings.TrimSpace(u.UserName))
+}
+
+// Validate validates the user
+func (u *User) Validate() error {
+	if u.UserName == "" {
+		return fmt.Errorf("%w: userName is required", ErrInvalidValue)
+	}
+	if !strings.Contains(u.UserName, "@") || strings.ContainsAny(u.UserName, " \t") {
+		return fmt.Errorf("%w: userName must be an email address", ErrInvalidValue)
+	}
+	return nil
+}
+
+// ApplyPatch applies the operations of a PATCH request to the user
+func (u *User) ApplyPatch(req *PatchRequest) error {
+	if len(req.Operations) == 0 {
+		return fmt.Errorf("%w: Operations are required", ErrInvalidValue)
+	}
+	for _, op := range req.Operations {
+		if err := u.applyOperation(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOperation applies an add, replace, or remove operation
+func (u *User) applyOperation(op PatchOperation) error {
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		if op.Path != "" {
+			return u.set(op.Path, op.Value)
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &values); err != nil {
+			return fmt.Errorf("%w: value must be an object of attributes without a path", ErrInvalidValue)
+		}
+		for path, value := range values {
+			if err := u.set(path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "remove":
+		return u.remove(op.Path)
+	default:
+		return fmt.Errorf("%w: unsupported op %q", ErrInvalidValue, op.Op)
+	}
+}
+
+// set sets the attribute at the path
+func (u *User) set(path string, value json.RawMessage) error {
+	attribute, ignored := attributeOf(path)
+	if ignored {
+		return nil
+	}
+
+	switch attribute {
+	case "active":
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		u.Active = &active
+		return nil
+	case "username":
+		return setString(&u.UserName, value)
+	case "externalid":
+		return setString(&u.ExternalID, value)
+	case "displayname":
+		return setString(&u.DisplayName, value)
+	case "name":
+		var name Name
+		if err := json.Unmarshal(value, &name); err != nil {
+			return fmt.Errorf("%w: name must be an object", ErrInvalidValue)
+		}
+		u.Name = &name
+		return nil
+	case "name.givenname":
+		return setString(&u.name().GivenName, value)
+	case "name.familyname":
+		return setString(&u.name().FamilyName, value)
+	default:
+		return fmt.Errorf("%w: unsupported attribute %s", ErrInvalidPath, path)
+	}
+}
+
+// remove clears the attribute at the path
+func (u *User) remove(path string) error {
+	attribute, ignored := attributeOf(path)
+	if ignored {
+		return nil
+	}
+
+	switch attribute {
+	case "externalid":
+		u.ExternalID = ""
+	case "displayname":
+		u.DisplayName = ""
+	case "name":
+		u.Name = nil
+	case "name.givenname":
+		u.name().GivenName = ""
+	case "name.familyname":
+		u.name().FamilyName = ""
+	case "username", "active":
+		return fmt.Errorf("%w: %s cannot be removed", ErrInvalidValue, path)
+	default:
+		return fmt.Errorf("%w: unsupported attribute %s", ErrInvalidPath, path)
+	}
+	return nil
+}
+
+// name returns the name of the user, creating it when unset
+func (u *User) name() *Name {
+	if u.Name == nil {
+		u.Name = &Name{}
+	}
+	return u.Name
+}
+
+// attributeOf returns the lowercase attribute of a path, without the User schema prefix.
+// Emails, which follow the userName, and attributes of schema extensions such as the
+// enterprise user are ignored.
+func attributeOf(path string) (string, bool) {
+	attribute := strings.TrimPrefix(strings.ToLower(path), strings.ToLower(SchemaUser)+":")
+	if strings.HasPrefix(attribute, "emails") || strings.HasPrefix(attribute, "urn:") {
+		return "", true
+	}
+	return attribute, false
+}
+
+// setString sets a string attribute from a JSON string
+func setString(target *string, value json.RawMessage) error {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return fmt.Errorf("%w: expected a string", ErrInvalidValue)
+	}
+	*target = s
+	return nil
+}
+
+// parseBool parses a JSON boolean, or a string such as "False" as Azure AD sends
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(strings.ToLower(s)); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%w: active must be a boolean", ErrInvalidValue)
+}
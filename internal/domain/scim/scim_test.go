@@ -0,0 +1,90 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter("")
+	require.NoError(t, err)
+	assert.Nil(t, filter)
+	assert.True(t, filter.Matches(&User{UserName: "jane@example.com"}))
+
+	filter, err = ParseFilter(`userName eq "Jane@Example.com"`)
+	require.NoError(t, err)
+	assert.Equal(t, &Filter{Attribute: "userName", Value: "Jane@Example.com"}, filter)
+	assert.True(t, filter.Matches(&User{UserName: "jane@example.com"}))
+	assert.False(t, filter.Matches(&User{UserName: "john@example.com"}))
+
+	filter, err = ParseFilter(`externalId EQ "00u1\"a"`)
+	require.NoError(t, err)
+	assert.True(t, filter.Matches(&User{ExternalID: `00u1"a`}))
+	assert.False(t, filter.Matches(&User{ExternalID: `00U1"A`}))
+
+	filter, err = ParseFilter(`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "jane@example.com"`)
+	require.NoError(t, err)
+	assert.Equal(t, "userName", filter.Attribute)
+
+	for _, f := range []string{`userName co "jane"`, `displayName eq "Jane"`, `userName eq jane`, `userName eq "a" and active eq true`} {
+		_, err := ParseFilter(f)
+		assert.ErrorIs(t, err, ErrInvalidFilter, f)
+	}
+}
+
+func TestUser_Validate(t *testing.T) {
+	user := &User{UserName: " Jane@Example.com "}
+	user.Normalize()
+	assert.Equal(t, "jane@example.com", user.UserName)
+	assert.NoError(t, user.Validate())
+	assert.True(t, user.IsActive())
+
+	assert.EqualError(t, (&User{}).Validate(), "invalid value: userName is required")
+	assert.EqualError(t, (&User{UserName: "jane"}).Validate(), "invalid value: userName must be an email address")
+}
+
+func TestUser_ApplyPatch(t *testing.T) {
+	patch := func(t *testing.T, user *User, body string) error {
+		var req PatchRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &req))
+		return user.ApplyPatch(&req)
+	}
+
+	t.Run("okta", func(t *testing.T) {
+		user := &User{UserName: "jane@example.com"}
+		err := patch(t, user, `{"Operations": [{"op": "replace", "value": {"active": false, "name": {"givenName": "Jane"}}}]}`)
+		require.NoError(t, err)
+		assert.False(t, user.IsActive())
+		assert.Equal(t, &Name{GivenName: "Jane"}, user.Name)
+	})
+
+	t.Run("azure", func(t *testing.T) {
+		user := &User{UserName: "jane@example.com", ExternalID: "old"}
+		err := patch(t, user, `{"Operations": [
+			{"op": "Replace", "path": "active", "value": "False"},
+			{"op": "Replace", "path": "userName", "value": "jane.doe@example.com"},
+			{"op": "Add", "path": "name.familyName", "value": "Doe"},
+			{"op": "Replace", "path": "emails[type eq \"work\"].value", "value": "jane.doe@example.com"},
+			{"op": "Add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "IT"},
+			{"op": "Remove", "path": "externalId"}
+		]}`)
+		require.NoError(t, err)
+		assert.False(t, user.IsActive())
+		assert.Equal(t, "jane.doe@example.com", user.UserName)
+		assert.Equal(t, &Name{FamilyName: "Doe"}, user.Name)
+		assert.Empty(t, user.ExternalID)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		user := &User{UserName: "jane@example.com"}
+		assert.ErrorIs(t, patch(t, user, `{"Operations": []}`), ErrInvalidValue)
+		assert.ErrorIs(t, patch(t, user, `{"Operations": [{"op": "move", "path": "active"}]}`), ErrInvalidValue)
+		assert.ErrorIs(t, patch(t, user, `{"Operations": [{"op": "replace", "path": "active", "value": "maybe"}]}`), ErrInvalidValue)
+		assert.ErrorIs(t, patch(t, user, `{"Operations": [{"op": "remove", "path": "userName"}]}`), ErrInvalidValue)
+		assert.ErrorIs(t, patch(t, user, `{"Operations": [{"op": "replace", "path": "nickName", "value": "JJ"}]}`), ErrInvalidPath)
+		assert.ErrorIs(t, patch(t, user, `{"Operations": [{"op": "replace", "value": "jane"}]}`), ErrInvalidValue)
+	})
+}
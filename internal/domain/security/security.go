@@ -0,0 +1,60 @@
+package security
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnomalyType represents a kind of unusual login pattern
+type AnomalyType string
+
+const (
+	AnomalyIPFailures       AnomalyType = "ip_failures"       // many failed logins from one address
+	AnomalyAccountFailures  AnomalyType = "account_failures"  // many failed logins for one account
+	AnomalyImpossibleTravel AnomalyType = "impossible_travel" // logins from places too far apart for the time between them
+)
+
+// Anomaly represents an unusual login pattern. Logins of the affected accounts are
+// throttled until FlaggedUntil.
+type Anomaly struct {
+	Type         AnomalyType `json:"type"`
+	IP           string      `json:"ip"`
+	UserID       *uuid.UUID  `json:"user_id,omitempty"`
+	Emails       []string    `json:"emails"` // accounts affected
+	DetectedAt   time.Time   `json:"detected_at"`
+	FlaggedUntil time.Time   `json:"flagged_until"`
+}
+
+// Location represents where an address is located
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two locations in kilometres
+func (l Location) DistanceKm(other Location) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// SpeedKmh returns the speed needed to travel between two locations in the elapsed time.
+// Any distance covered instantly is infinitely fast.
+func SpeedKmh(from, to Location, elapsed time.Duration) float64 {
+	distance := from.DistanceKm(to)
+	if distance == 0 {
+		return 0
+	}
+	if elapsed <= 0 {
+		return math.Inf(1)
+	}
+	return distance / elapsed.Hours()
+}
@@ -0,0 +1,28 @@
+package security
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation_DistanceKm(t *testing.T) {
+	paris := Location{Latitude: 48.8566, Longitude: 2.3522}
+	newYork := Location{Latitude: 40.7128, Longitude: -74.0060}
+
+	assert.InDelta(t, 5837, paris.DistanceKm(newYork), 5)
+	assert.InDelta(t, 5837, newYork.DistanceKm(paris), 5)
+	assert.Zero(t, paris.DistanceKm(paris))
+}
+
+func TestSpeedKmh(t *testing.T) {
+	paris := Location{Latitude: 48.8566, Longitude: 2.3522}
+	newYork := Location{Latitude: 40.7128, Longitude: -74.0060}
+
+	assert.InDelta(t, 5837, SpeedKmh(paris, newYork, time.Hour), 5)
+	assert.InDelta(t, 729, SpeedKmh(paris, newYork, 8*time.Hour), 1)
+	assert.True(t, math.IsInf(SpeedKmh(paris, newYork, 0), 1))
+	assert.Zero(t, SpeedKmh(paris, paris, 0))
+}
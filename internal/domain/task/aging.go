@@ -0,0 +1,191 @@
+package task
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusChange is a change of the status of a task, as recorded in its activity history
+type StatusChange struct {
+	At   time.Time
+	From TaskStatus
+	To   TaskStatus
+}
+
+// AgingBuckets counts open tasks by how long ago they were created
+type AgingBuckets struct {
+	UnderDay  int `json:"under_1d"`
+	DayToWeek int `json:"1d_to_7d"`
+	OverWeek  int `json:"over_7d"`
+}
+
+// AgingStats describes how long tasks stay open: the open tasks by age, and the average
+// time tasks spent in each open status, null when no task was ever in it
+type AgingStats struct {
+	Open                   int                     `json:"open"`
+	Aging                  AgingBuckets            `json:"aging"`
+	AverageSecondsInStatus map[TaskStatus]*float64 `json:"average_seconds_in_status"`
+}
+
+// UserAging is the aging of the tasks a user is responsible for: those assigned to them, and
+// those they own that are not assigned
+type UserAging struct {
+	UserID uuid.UUID `json:"user_id"`
+	AgingStats
+}
+
+// ProjectAging is the aging of the tasks of a project, or of the tasks without a project
+// when ProjectID is nil
+type ProjectAging struct {
+	ProjectID *uuid.UUID `json:"project_id"`
+	AgingStats
+}
+
+// AgingReport describes how long the tasks of a workspace stay open, overall and by user
+// and project. Groups are ordered by their open tasks, most first.
+type AgingReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	AgingStats
+	ByUser    []UserAging    `json:"by_user"`
+	ByProject []ProjectAging `json:"by_project"`
+}
+
+// agingAccumulator sums the aging of a group of tasks
+type agingAccumulator struct {
+	stats   AgingStats
+	inState map[TaskStatus]time.Duration
+	tasks   map[TaskStatus]int
+}
+
+func newAgingAccumulator() *agingAccumulator {
+	return &agingAccumulator{
+		inState: make(map[TaskStatus]time.Duration),
+		tasks:   make(map[TaskStatus]int),
+	}
+}
+
+// add counts the task with the time it spent in each open status
+func (a *agingAccumulator) add(t *Task, inStatus map[TaskStatus]time.Duration, now time.Time) {
+	if !t.Status.IsClosed() && !t.IsArchived() {
+		a.stats.Open++
+		switch age := now.Sub(t.CreatedAt); {
+		case age < 24*time.Hour:
+			a.stats.Aging.UnderDay++
+		case age <= 7*24*time.Hour:
+			a.stats.Aging.DayToWeek++
+		default:
+			a.stats.Aging.OverWeek++
+		}
+	}
+	for status, d := range inStatus {
+		a.inState[status] += d
+		a.tasks[status]++
+	}
+}
+
+// result returns the aging of the group
+func (a *agingAccumulator) result() AgingStats {
+	stats := a.stats
+	stats.AverageSecondsInStatus = map[TaskStatus]*float64{StatusPending: nil, StatusInProgress: nil}
+	for status := range stats.AverageSecondsInStatus {
+		if a.tasks[status] > 0 {
+			average := a.inState[status].Seconds() / float64(a.tasks[status])
+			stats.AverageSecondsInStatus[status] = &average
+		}
+	}
+	return stats
+}
+
+// NewAgingReport computes the aging of the tasks at the time from the status changes of each
+// task, by task ID
+func NewAgingReport(tasks []*Task, history map[uuid.UUID][]StatusChange, now time.Time) *AgingReport {
+	total := newAgingAccumulator()
+	byUser := make(map[uuid.UUID]*agingAccumulator)
+	byProject := make(map[uuid.UUID]*agingAccumulator)
+	var noProject *agingAccumulator
+
+	for _, t := range tasks {
+		inStatus := TimeInStatus(t, history[t.ID], now)
+		total.add(t, inStatus, now)
+
+		userID := t.UserID
+		if t.AssigneeID != nil {
+			userID = *t.AssigneeID
+		}
+		if byUser[userID] == nil {
+			byUser[userID] = newAgingAccumulator()
+		}
+		byUser[userID].add(t, inStatus, now)
+
+		if t.ProjectID == nil {
+			if noProject == nil {
+				noProject = newAgingAccumulator()
+			}
+			noProject.add(t, inStatus, now)
+			continue
+		}
+		if byProject[*t.ProjectID] == nil {
+			byProject[*t.ProjectID] = newAgingAccumulator()
+		}
+		byProject[*t.ProjectID].add(t, inStatus, now)
+	}
+
+	report := &AgingReport{
+		GeneratedAt: now,
+		AgingStats:  total.result(),
+		ByUser:      make([]UserAging, 0, len(byUser)),
+		ByProject:   make([]ProjectAging, 0, len(byProject)+1),
+	}
+	for id, a := range byUser {
+		report.ByUser = append(report.ByUser, UserAging{UserID: id, AgingStats: a.result()})
+	}
+	for id, a := range byProject {
+		report.ByProject = append(report.ByProject, ProjectAging{ProjectID: &id, AgingStats: a.result()})
+	}
+
+	slices.SortFunc(report.ByUser, func(a, b UserAging) int {
+		return cmp.Or(cmp.Compare(b.Open, a.Open), bytes.Compare(a.UserID[:], b.UserID[:]))
+	})
+	slices.SortFunc(report.ByProject, func(a, b ProjectAging) int {
+		return cmp.Or(cmp.Compare(b.Open, a.Open), bytes.Compare(a.ProjectID[:], b.ProjectID[:]))
+	})
+	// Tasks without a project come last
+	if noProject != nil {
+		report.ByProject = append(report.ByProject, ProjectAging{AgingStats: noProject.result()})
+	}
+	return report
+}
+
+// TimeInStatus returns how long the task spent in each open status from its creation to the
+// time, from its status changes. Time in the current status counts until the time unless
+// the task is archived.
+func TimeInStatus(t *Task, changes []StatusChange, now time.Time) map[TaskStatus]time.Duration {
+	changes = slices.SortedFunc(slices.Values(changes), func(a, b StatusChange) int {
+		return a.At.Compare(b.At)
+	})
+
+	status := t.Status
+	if len(changes) > 0 {
+		status = changes[0].From
+	}
+	since := t.CreatedAt
+
+	inStatus := make(map[TaskStatus]time.Duration)
+	spend := func(until time.Time) {
+		if !status.IsClosed() && until.After(since) {
+			inStatus[status] += until.Sub(since)
+		}
+	}
+	for _, change := range changes {
+		spend(change.At)
+		status, since = change.To, change.At
+	}
+	if !t.IsArchived() {
+		spend(now)
+	}
+	return inStatus
+}
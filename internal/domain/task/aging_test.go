@@ -0,0 +1,81 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeInStatus(t *testing.T) {
+	now := time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * time.Hour)
+	task := &Task{Status: StatusCompleted, CreatedAt: created}
+
+	// Pending for 2 hours, in progress for 3, completed, then reopened for an hour
+	changes := []StatusChange{
+		{At: created.Add(5 * time.Hour), From: StatusInProgress, To: StatusCompleted},
+		{At: created.Add(2 * time.Hour), From: StatusPending, To: StatusInProgress},
+		{At: created.Add(8 * time.Hour), From: StatusCompleted, To: StatusPending},
+		{At: created.Add(9 * time.Hour), From: StatusPending, To: StatusCompleted},
+	}
+	assert.Equal(t, map[TaskStatus]time.Duration{
+		StatusPending:    3 * time.Hour,
+		StatusInProgress: 3 * time.Hour,
+	}, TimeInStatus(task, changes, now))
+
+	// Open tasks spend the time since their last change in their status
+	open := &Task{Status: StatusInProgress, CreatedAt: created}
+	assert.Equal(t, map[TaskStatus]time.Duration{
+		StatusPending:    time.Hour,
+		StatusInProgress: 9 * time.Hour,
+	}, TimeInStatus(open, []StatusChange{{At: created.Add(time.Hour), From: StatusPending, To: StatusInProgress}}, now))
+
+	// Tasks without changes were in their status since they were created
+	assert.Equal(t, map[TaskStatus]time.Duration{StatusPending: 10 * time.Hour},
+		TimeInStatus(&Task{Status: StatusPending, CreatedAt: created}, nil, now))
+}
+
+func TestNewAgingReport(t *testing.T) {
+	now := time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+	owner, assignee := uuid.New(), uuid.New()
+	projectID := uuid.New()
+	archivedAt := now.Add(-time.Hour)
+
+	fresh := &Task{ID: uuid.New(), UserID: owner, Status: StatusPending, CreatedAt: now.Add(-2 * time.Hour), ProjectID: &projectID}
+	week := &Task{ID: uuid.New(), UserID: owner, AssigneeID: &assignee, Status: StatusInProgress, CreatedAt: now.Add(-72 * time.Hour), ProjectID: &projectID}
+	stale := &Task{ID: uuid.New(), UserID: owner, AssigneeID: &assignee, Status: StatusPending, CreatedAt: now.Add(-10 * 24 * time.Hour)}
+	done := &Task{ID: uuid.New(), UserID: owner, Status: StatusCompleted, CreatedAt: now.Add(-48 * time.Hour)}
+	archived := &Task{ID: uuid.New(), UserID: owner, Status: StatusPending, CreatedAt: now.Add(-48 * time.Hour), ArchivedAt: &archivedAt}
+
+	history := map[uuid.UUID][]StatusChange{
+		week.ID: {{At: week.CreatedAt.Add(24 * time.Hour), From: StatusPending, To: StatusInProgress}},
+		done.ID: {{At: done.CreatedAt.Add(4 * time.Hour), From: StatusPending, To: StatusCompleted}},
+	}
+
+	report := NewAgingReport([]*Task{fresh, week, stale, done, archived}, history, now)
+	assert.Equal(t, now, report.GeneratedAt)
+	assert.Equal(t, 3, report.Open)
+	assert.Equal(t, AgingBuckets{UnderDay: 1, DayToWeek: 1, OverWeek: 1}, report.Aging)
+	require.NotNil(t, report.AverageSecondsInStatus[StatusInProgress])
+	assert.Equal(t, (48 * time.Hour).Seconds(), *report.AverageSecondsInStatus[StatusInProgress])
+
+	// Tasks belong to their assignee, or their owner when unassigned
+	require.Len(t, report.ByUser, 2)
+	assert.Equal(t, assignee, report.ByUser[0].UserID)
+	assert.Equal(t, 2, report.ByUser[0].Open)
+	assert.Equal(t, AgingBuckets{DayToWeek: 1, OverWeek: 1}, report.ByUser[0].Aging)
+	assert.Equal(t, owner, report.ByUser[1].UserID)
+	assert.Equal(t, 1, report.ByUser[1].Open)
+	assert.Equal(t, (3 * time.Hour).Seconds(), *report.ByUser[1].AverageSecondsInStatus[StatusPending])
+	assert.Nil(t, report.ByUser[1].AverageSecondsInStatus[StatusInProgress])
+
+	// Tasks without a project come last
+	require.Len(t, report.ByProject, 2)
+	assert.Equal(t, &projectID, report.ByProject[0].ProjectID)
+	assert.Equal(t, 2, report.ByProject[0].Open)
+	assert.Nil(t, report.ByProject[1].ProjectID)
+	assert.Equal(t, 1, report.ByProject[1].Open)
+}
@@ -0,0 +1,44 @@
+package task
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// IsArchived reports whether the task has been archived
+func (t *Task) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+// Archive hides the task from default listings. Unlike deletion, the task and its
+// history are kept and it can be unarchived at any time.
+func (t *Task) Archive() ([]FieldChange, error) {
+	if t.IsArchived() {
+		return nil, errors.New("task is already archived")
+	}
+
+	now := time.Now()
+	t.ArchivedAt = &now
+	t.Touch()
+	return []FieldChange{archivedChange(false, true)}, nil
+}
+
+// Unarchive restores an archived task to default listings
+func (t *Task) Unarchive() ([]FieldChange, error) {
+	if !t.IsArchived() {
+		return nil, errors.New("task is not archived")
+	}
+
+	t.ArchivedAt = nil
+	t.Touch()
+	return []FieldChange{archivedChange(true, false)}, nil
+}
+
+func archivedChange(oldValue, newValue bool) FieldChange {
+	return FieldChange{
+		Field:    "archived",
+		OldValue: strconv.FormatBool(oldValue),
+		NewValue: strconv.FormatBool(newValue),
+	}
+}
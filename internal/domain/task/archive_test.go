@@ -0,0 +1,30 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_ArchiveAndUnarchive(t *testing.T) {
+	task := NewTask("Task", uuid.New())
+	assert.False(t, task.IsArchived())
+
+	changes, err := task.Archive()
+	require.NoError(t, err)
+	assert.True(t, task.IsArchived())
+	assert.Equal(t, []FieldChange{{Field: "archived", OldValue: "false", NewValue: "true"}}, changes)
+
+	_, err = task.Archive()
+	assert.EqualError(t, err, "task is already archived")
+
+	changes, err = task.Unarchive()
+	require.NoError(t, err)
+	assert.False(t, task.IsArchived())
+	assert.Equal(t, []FieldChange{{Field: "archived", OldValue: "true", NewValue: "false"}}, changes)
+
+	_, err = task.Unarchive()
+	assert.EqualError(t, err, "task is not archived")
+}
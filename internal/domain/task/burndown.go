@@ -0,0 +1,101 @@
+package task
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BurndownDay is the state of the tasks of a project at the end of a day (UTC)
+type BurndownDay struct {
+	Date      string `json:"date"`
+	Open      int    `json:"open"`      // pending or in progress at the end of the day
+	Closed    int    `json:"closed"`    // completed or cancelled at the end of the day
+	Completed int    `json:"completed"` // completed during the day
+}
+
+// Velocity is the rate tasks were completed at over the days of a burndown
+type Velocity struct {
+	Completed int     `json:"completed"`
+	PerDay    float64 `json:"per_day"`
+	PerWeek   float64 `json:"per_week"`
+	// DaysToFinish is how many days the tasks open on the last day take to complete at this
+	// velocity, null when no task was completed
+	DaysToFinish *float64 `json:"days_to_finish"`
+}
+
+// Burndown represents the daily open and closed tasks of a project over a range of days
+// with its completion velocity
+type Burndown struct {
+	ProjectID uuid.UUID     `json:"project_id"`
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Days      []BurndownDay `json:"days"`
+	Velocity  Velocity      `json:"velocity"`
+}
+
+// NewBurndown creates the burndown of a project from its days, oldest first
+func NewBurndown(projectID uuid.UUID, r *StatsRange, days []BurndownDay) *Burndown {
+	b := &Burndown{
+		ProjectID: projectID,
+		From:      r.From.Format(time.DateOnly),
+		To:        r.To.Format(time.DateOnly),
+		Days:      days,
+	}
+	if len(days) == 0 {
+		b.Days = []BurndownDay{}
+		return b
+	}
+
+	for _, day := range days {
+		b.Velocity.Completed += day.Completed
+	}
+	b.Velocity.PerDay = float64(b.Velocity.Completed) / float64(len(days))
+	b.Velocity.PerWeek = b.Velocity.PerDay * 7
+	if b.Velocity.PerDay > 0 {
+		remaining := float64(days[len(days)-1].Open) / b.Velocity.PerDay
+		b.Velocity.DaysToFinish = &remaining
+	}
+	return b
+}
+
+// BurndownDays computes the state of the tasks at the end of each day of the range from the
+// status changes of each task, by task ID. Tasks count from the day they were created.
+func BurndownDays(tasks []*Task, history map[uuid.UUID][]StatusChange, r *StatsRange) []BurndownDay {
+	days := make([]BurndownDay, r.Days())
+	for i := range days {
+		days[i].Date = r.From.AddDate(0, 0, i).Format(time.DateOnly)
+	}
+
+	for _, t := range tasks {
+		changes := slices.SortedFunc(slices.Values(history[t.ID]), func(a, b StatusChange) int {
+			return a.At.Compare(b.At)
+		})
+		status := t.Status
+		if len(changes) > 0 {
+			status = changes[0].From
+		}
+
+		next := 0
+		for i := range days {
+			start := r.From.AddDate(0, 0, i)
+			end := start.AddDate(0, 0, 1)
+			for ; next < len(changes) && changes[next].At.Before(end); next++ {
+				status = changes[next].To
+				if status == StatusCompleted && !changes[next].At.Before(start) {
+					days[i].Completed++
+				}
+			}
+			if !t.CreatedAt.Before(end) {
+				continue
+			}
+			if status.IsClosed() {
+				days[i].Closed++
+			} else {
+				days[i].Open++
+			}
+		}
+	}
+	return days
+}
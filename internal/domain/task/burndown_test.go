@@ -0,0 +1,63 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBurndownDays(t *testing.T) {
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	r, err := NewStatsRange(day, day.AddDate(0, 0, 2))
+	require.NoError(t, err)
+
+	early := &Task{ID: uuid.New(), Status: StatusCompleted, CreatedAt: day.AddDate(0, 0, -5)}
+	late := &Task{ID: uuid.New(), Status: StatusPending, CreatedAt: day.AddDate(0, 0, 1).Add(9 * time.Hour)}
+	cancelled := &Task{ID: uuid.New(), Status: StatusCancelled, CreatedAt: day.AddDate(0, 0, -1)}
+	reopened := &Task{ID: uuid.New(), Status: StatusPending, CreatedAt: day.AddDate(0, 0, -1)}
+
+	history := map[uuid.UUID][]StatusChange{
+		early.ID: {
+			{At: day.AddDate(0, 0, 1).Add(15 * time.Hour), From: StatusInProgress, To: StatusCompleted},
+			{At: day.Add(10 * time.Hour), From: StatusPending, To: StatusInProgress},
+		},
+		reopened.ID: {
+			{At: day.Add(8 * time.Hour), From: StatusPending, To: StatusCompleted},
+			{At: day.AddDate(0, 0, 2).Add(8 * time.Hour), From: StatusCompleted, To: StatusPending},
+		},
+	}
+
+	assert.Equal(t, []BurndownDay{
+		{Date: "2024-01-10", Open: 1, Closed: 2, Completed: 1},
+		{Date: "2024-01-11", Open: 1, Closed: 3, Completed: 1},
+		{Date: "2024-01-12", Open: 2, Closed: 2},
+	}, BurndownDays([]*Task{early, late, cancelled, reopened}, history, r))
+}
+
+func TestNewBurndown(t *testing.T) {
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	r, err := NewStatsRange(day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	projectID := uuid.New()
+
+	b := NewBurndown(projectID, r, []BurndownDay{
+		{Date: "2024-01-10", Open: 5, Closed: 1, Completed: 1},
+		{Date: "2024-01-11", Open: 3, Closed: 3, Completed: 2},
+	})
+	assert.Equal(t, projectID, b.ProjectID)
+	assert.Equal(t, "2024-01-10", b.From)
+	assert.Equal(t, "2024-01-11", b.To)
+	assert.Equal(t, 3, b.Velocity.Completed)
+	assert.Equal(t, 1.5, b.Velocity.PerDay)
+	assert.Equal(t, 10.5, b.Velocity.PerWeek)
+	require.NotNil(t, b.Velocity.DaysToFinish)
+	assert.Equal(t, 2.0, *b.Velocity.DaysToFinish)
+
+	// Without completions there is no estimate
+	b = NewBurndown(projectID, r, []BurndownDay{{Date: "2024-01-10", Open: 5}, {Date: "2024-01-11", Open: 5}})
+	assert.Zero(t, b.Velocity.PerDay)
+	assert.Nil(t, b.Velocity.DaysToFinish)
+}
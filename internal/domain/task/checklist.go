@@ -0,0 +1,189 @@
+package task
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxChecklistItems is the maximum number of checklist items in a task
+const MaxChecklistItems = 100
+
+// ChecklistItem represents a single step of a task
+type ChecklistItem struct {
+	ID        uuid.UUID `json:"id"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddChecklistItemRequest represents a request to add a checklist item
+type AddChecklistItemRequest struct {
+	Text string `json:"text" validate:"required,min=1,max=200"`
+}
+
+// UpdateChecklistItemRequest represents a request to edit or toggle a checklist item
+type UpdateChecklistItemRequest struct {
+	Text *string `json:"text,omitempty" validate:"omitempty,min=1,max=200"`
+	Done *bool   `json:"done,omitempty"`
+}
+
+// MoveChecklistItemRequest represents a request to reorder a checklist item
+type MoveChecklistItemRequest struct {
+	Position *int `json:"position"` // zero-based index in the checklist
+}
+
+// Validate validates add checklist item request
+func (req *AddChecklistItemRequest) Validate() error {
+	return validateChecklistText(req.Text)
+}
+
+// Validate validates update checklist item request
+func (req *UpdateChecklistItemRequest) Validate() error {
+	if req.Text == nil && req.Done == nil {
+		return errors.New("text or done is required")
+	}
+
+	if req.Text != nil {
+		return validateChecklistText(*req.Text)
+	}
+
+	return nil
+}
+
+// Validate validates move checklist item request
+func (req *MoveChecklistItemRequest) Validate() error {
+	if req.Position == nil {
+		return errors.New("position is required")
+	}
+
+	if *req.Position < 0 {
+		return errors.New("position must not be negative")
+	}
+
+	return nil
+}
+
+// AddChecklistItem appends an item to the task's checklist
+func (t *Task) AddChecklistItem(text string) (*ChecklistItem, error) {
+	if len(t.Checklist) >= MaxChecklistItems {
+		return nil, errors.New("checklist must have at most 100 items")
+	}
+
+	t.Checklist = append(t.Checklist, ChecklistItem{
+		ID:        uuid.New(),
+		Text:      text,
+		CreatedAt: time.Now(),
+	})
+	t.touchChecklist()
+
+	return &t.Checklist[len(t.Checklist)-1], nil
+}
+
+// UpdateChecklistItem edits the text or done flag of a checklist item
+func (t *Task) UpdateChecklistItem(itemID uuid.UUID, req *UpdateChecklistItemRequest) (*ChecklistItem, error) {
+	index := t.checklistIndex(itemID)
+	if index == -1 {
+		return nil, errors.New("checklist item not found")
+	}
+
+	item := &t.Checklist[index]
+	if req.Text != nil {
+		item.Text = *req.Text
+	}
+	if req.Done != nil {
+		item.Done = *req.Done
+	}
+	t.touchChecklist()
+
+	return item, nil
+}
+
+// MoveChecklistItem moves a checklist item to the index, clamped to the end of the list
+func (t *Task) MoveChecklistItem(itemID uuid.UUID, index int) (*ChecklistItem, error) {
+	from := t.checklistIndex(itemID)
+	if from == -1 {
+		return nil, errors.New("checklist item not found")
+	}
+
+	if index >= len(t.Checklist) {
+		index = len(t.Checklist) - 1
+	}
+
+	item := t.Checklist[from]
+	checklist := append(t.Checklist[:from:from], t.Checklist[from+1:]...)
+	checklist = append(checklist[:index], append([]ChecklistItem{item}, checklist[index:]...)...)
+	t.Checklist = checklist
+	t.touchChecklist()
+
+	return &t.Checklist[index], nil
+}
+
+// RemoveChecklistItem deletes a checklist item and returns it
+func (t *Task) RemoveChecklistItem(itemID uuid.UUID) (*ChecklistItem, error) {
+	index := t.checklistIndex(itemID)
+	if index == -1 {
+		return nil, errors.New("checklist item not found")
+	}
+
+	item := t.Checklist[index]
+	t.Checklist = append(t.Checklist[:index:index], t.Checklist[index+1:]...)
+	t.touchChecklist()
+
+	return &item, nil
+}
+
+// String formats the item for activity history, e.g. "[x] Write tests"
+func (item ChecklistItem) String() string {
+	if item.Done {
+		return "[x] " + item.Text
+	}
+	return "[ ] " + item.Text
+}
+
+// ChecklistProgress returns the percentage of checklist items that are done, rounded down,
+// or nil when the task has no checklist
+func (t *Task) ChecklistProgress() *int {
+	if len(t.Checklist) == 0 {
+		return nil
+	}
+
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+
+	progress := done * 100 / len(t.Checklist)
+	return &progress
+}
+
+// touchChecklist refreshes the computed progress after the checklist changed
+func (t *Task) touchChecklist() {
+	t.Progress = t.ChecklistProgress()
+	t.Touch()
+}
+
+func (t *Task) checklistIndex(itemID uuid.UUID) int {
+	for i, item := range t.Checklist {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateChecklistText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("text is required")
+	}
+
+	if len(text) > 200 {
+		return errors.New("text must be at most 200 characters")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checklistTexts(t *Task) []string {
+	var texts []string
+	for _, item := range t.Checklist {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestChecklistRequests_Validate(t *testing.T) {
+	done := true
+	empty := " "
+	negative := -1
+
+	assert.NoError(t, (&AddChecklistItemRequest{Text: "Write tests"}).Validate())
+	assert.EqualError(t, (&AddChecklistItemRequest{Text: ""}).Validate(), "text is required")
+	assert.EqualError(t, (&AddChecklistItemRequest{Text: strings.Repeat("a", 201)}).Validate(), "text must be at most 200 characters")
+
+	assert.NoError(t, (&UpdateChecklistItemRequest{Done: &done}).Validate())
+	assert.EqualError(t, (&UpdateChecklistItemRequest{}).Validate(), "text or done is required")
+	assert.EqualError(t, (&UpdateChecklistItemRequest{Text: &empty}).Validate(), "text is required")
+
+	assert.EqualError(t, (&MoveChecklistItemRequest{}).Validate(), "position is required")
+	assert.EqualError(t, (&MoveChecklistItemRequest{Position: &negative}).Validate(), "position must not be negative")
+}
+
+func TestTask_Checklist(t *testing.T) {
+	task := NewTask("Release", uuid.New())
+	assert.Nil(t, task.ChecklistProgress())
+
+	first, err := task.AddChecklistItem("Tag version")
+	require.NoError(t, err)
+	firstID := first.ID
+	second, err := task.AddChecklistItem("Build binaries")
+	require.NoError(t, err)
+	secondID := second.ID
+	_, err = task.AddChecklistItem("Publish notes")
+	require.NoError(t, err)
+	assert.Equal(t, 0, *task.Progress)
+
+	// Toggle
+	done := true
+	item, err := task.UpdateChecklistItem(firstID, &UpdateChecklistItemRequest{Done: &done})
+	require.NoError(t, err)
+	assert.Equal(t, "[x] Tag version", item.String())
+	assert.Equal(t, 33, *task.Progress)
+
+	// Reorder, clamping past the end
+	_, err = task.MoveChecklistItem(firstID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Build binaries", "Publish notes", "Tag version"}, checklistTexts(task))
+
+	_, err = task.MoveChecklistItem(firstID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Tag version", "Build binaries", "Publish notes"}, checklistTexts(task))
+
+	// Remove
+	removed, err := task.RemoveChecklistItem(secondID)
+	require.NoError(t, err)
+	assert.Equal(t, "Build binaries", removed.Text)
+	assert.Equal(t, []string{"Tag version", "Publish notes"}, checklistTexts(task))
+	assert.Equal(t, 50, *task.Progress)
+
+	_, err = task.RemoveChecklistItem(secondID)
+	assert.EqualError(t, err, "checklist item not found")
+}
+
+func TestTask_AddChecklistItem_Limit(t *testing.T) {
+	task := NewTask("Big task", uuid.New())
+	for i := 0; i < MaxChecklistItems; i++ {
+		_, err := task.AddChecklistItem("Step")
+		require.NoError(t, err)
+	}
+
+	_, err := task.AddChecklistItem("One too many")
+	assert.EqualError(t, err, "checklist must have at most 100 items")
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrConflict is returned when an edit based on a stale version of a task clashes with
+// changes made to the task since
+var ErrConflict = errors.New("task was changed since the version the edit is based on")
+
+// ErrBaseVersionRequired is returned when resolving a conflict without the version of the
+// server copy the merge is based on
+var ErrBaseVersionRequired = errors.New("base_version is required")
+
+// ConflictField is a field an edit would change, whose server value differs from the
+// value of the edit
+type ConflictField struct {
+	Field  string `json:"field"`
+	Server any    `json:"server"`
+	Client any    `json:"client"`
+}
+
+// ConflictError carries the server copy of a task and the fields where a stale edit
+// differs from it, for the client to merge and resolve
+type ConflictError struct {
+	Server *Task           `json:"server"`
+	Client []ConflictField `json:"client"`
+}
+
+func (e *ConflictError) Error() string { return ErrConflict.Error() }
+
+func (e *ConflictError) Unwrap() error { return ErrConflict }
+
+// CheckVersion checks an edit can be applied to the task. Edits of the current version,
+// or without a base version, always can; edits of a stale version only when they change
+// nothing the server copy differs on.
+func (t *Task) CheckVersion(req *UpdateTaskRequest) error {
+	if req.BaseVersion == nil || *req.BaseVersion == t.Version {
+		return nil
+	}
+
+	fields := t.Conflicts(req)
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ConflictError{Server: t.Snapshot(), Client: fields}
+}
+
+// Conflicts returns the fields the request would change on the task
+func (t *Task) Conflicts(req *UpdateTaskRequest) []ConflictField {
+	var fields []ConflictField
+
+	if req.Title != nil && *req.Title != t.Title {
+		fields = append(fields, ConflictField{Field: "title", Server: t.Title, Client: *req.Title})
+	}
+	if req.Description != nil && *req.Description != t.Description {
+		fields = append(fields, ConflictField{Field: "description", Server: t.Description, Client: *req.Description})
+	}
+	if req.Status != nil && *req.Status != t.Status {
+		fields = append(fields, ConflictField{Field: "status", Server: t.Status, Client: *req.Status})
+	}
+	if req.Priority != nil && *req.Priority != t.Priority {
+		fields = append(fields, ConflictField{Field: "priority", Server: t.Priority, Client: *req.Priority})
+	}
+	if req.DueDate != nil && (t.DueDate == nil || !req.DueDate.Equal(*t.DueDate)) {
+		var server *time.Time
+		if t.DueDate != nil {
+			dueDate := *t.DueDate
+			server = &dueDate
+		}
+		fields = append(fields, ConflictField{Field: "due_date", Server: server, Client: *req.DueDate})
+	}
+	if req.Tags != nil && !slices.Equal(*req.Tags, t.Tags) {
+		fields = append(fields, ConflictField{Field: "tags", Server: slices.Clone(t.Tags), Client: *req.Tags})
+	}
+
+	return fields
+}
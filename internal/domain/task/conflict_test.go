@@ -0,0 +1,63 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_Version(t *testing.T) {
+	task := NewTask("Write report", uuid.New())
+	assert.Equal(t, 1, task.Version)
+
+	title := "Write the report"
+	task.Update(&UpdateTaskRequest{Title: &title})
+	assert.Equal(t, 2, task.Version)
+
+	_, err := task.Archive()
+	require.NoError(t, err)
+	assert.Equal(t, 3, task.Version)
+}
+
+func TestTask_CheckVersion(t *testing.T) {
+	task := NewTask("Write report", uuid.New())
+	task.Tags = []string{"work"}
+	title := "Write the report"
+	task.Update(&UpdateTaskRequest{Title: &title})
+	current, stale := task.Version, task.Version-1
+
+	edited := "Write the final report"
+	status := StatusCompleted
+	dueDate := time.Date(2026, time.October, 20, 17, 0, 0, 0, time.UTC)
+	tags := []string{"work", "q4"}
+	edit := &UpdateTaskRequest{Title: &edited, Status: &status, DueDate: &dueDate, Tags: &tags}
+
+	// Edits without a base version, or of the current version, apply
+	assert.NoError(t, task.CheckVersion(edit))
+	edit.BaseVersion = &current
+	assert.NoError(t, task.CheckVersion(edit))
+
+	// Stale edits conflict on the fields they change
+	edit.BaseVersion = &stale
+	err := task.CheckVersion(edit)
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, current, conflict.Server.Version)
+	assert.Equal(t, []ConflictField{
+		{Field: "title", Server: title, Client: edited},
+		{Field: "status", Server: StatusPending, Client: StatusCompleted},
+		{Field: "due_date", Server: (*time.Time)(nil), Client: dueDate},
+		{Field: "tags", Server: []string{"work"}, Client: tags},
+	}, conflict.Client)
+
+	// The server copy is not affected by later changes
+	task.Tags[0] = "home"
+	assert.Equal(t, []string{"work"}, conflict.Server.Tags)
+
+	// Stale edits that agree with the server copy apply
+	assert.NoError(t, task.CheckVersion(&UpdateTaskRequest{Title: &title, BaseVersion: &stale}))
+}
@@ -0,0 +1,20 @@
+package task
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DuplicateWindow is how recently an open task must have been created for a new task with
+// the same normalized title to be considered a duplicate of it
+const DuplicateWindow = 24 * time.Hour
+
+// NormalizeTitle returns the title as compared to find duplicates: in lowercase, with
+// punctuation removed and runs of spaces collapsed, so "Pay rent!" matches "pay  rent"
+func NormalizeTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,14 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	assert.Equal(t, "pay rent", NormalizeTitle("  Pay   rent! "))
+	assert.Equal(t, NormalizeTitle("Call mom, re: trip"), NormalizeTitle("call Mom re trip"))
+	assert.Equal(t, "q3 plan", NormalizeTitle("Q3-plan"), "punctuation separates words")
+	assert.NotEqual(t, NormalizeTitle("Pay rent"), NormalizeTitle("Pay the rent"))
+}
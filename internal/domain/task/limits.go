@@ -0,0 +1,55 @@
+package task
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrLimitExceeded is returned when an operation would exceed a per-user limit
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// ErrStorageFull is returned when a new task would exceed the storage budget shared by every
+// user
+var ErrStorageFull = errors.New("task storage is full")
+
+// Limits caps the resources a single user may create. Zero means unlimited.
+type Limits struct {
+	MaxTasks int
+	// Plan is the subscription plan imposing MaxTasks, if any, which upgrading lifts
+	Plan string
+}
+
+// LimitsOf returns the limits themselves, so that fixed limits apply to every user
+func (l Limits) LimitsOf(uuid.UUID) Limits {
+	return l
+}
+
+// StorageBudget caps the tasks stored across every user, by number and by estimated size in
+// bytes, so a misbehaving client cannot exhaust memory. Zero means unlimited.
+type StorageBudget struct {
+	MaxTasks int
+	MaxBytes int
+}
+
+// Usage reports a user's consumption of a limited resource
+type Usage struct {
+	Used      int  `json:"used"`
+	Limit     int  `json:"limit"`               // 0 means unlimited
+	Remaining *int `json:"remaining,omitempty"` // omitted when unlimited
+}
+
+// NewUsage creates a usage report for used resources against a limit
+func NewUsage(used, limit int) Usage {
+	usage := Usage{Used: used, Limit: limit}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		usage.Remaining = &remaining
+	}
+	return usage
+}
+
+// Allows reports whether n more resources fit within the limit
+func (u Usage) Allows(n int) bool {
+	return u.Remaining == nil || n <= *u.Remaining
+}
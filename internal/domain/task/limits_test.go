@@ -0,0 +1,25 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUsage(t *testing.T) {
+	unlimited := NewUsage(5, 0)
+	assert.Nil(t, unlimited.Remaining)
+	assert.True(t, unlimited.Allows(1000))
+
+	limited := NewUsage(3, 5)
+	require.NotNil(t, limited.Remaining)
+	assert.Equal(t, 2, *limited.Remaining)
+	assert.True(t, limited.Allows(2))
+	assert.False(t, limited.Allows(3))
+
+	// Usage above a lowered limit leaves nothing remaining
+	exceeded := NewUsage(7, 5)
+	assert.Equal(t, 0, *exceeded.Remaining)
+	assert.False(t, exceeded.Allows(1))
+}
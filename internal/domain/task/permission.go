@@ -0,0 +1,138 @@
+package task
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ResourceType identifies tasks in the authorization policy
+const ResourceType = "task"
+
+// Roles a user can hold on a task. What each role may do is declared in the authorization
+// policy.
+const (
+	RoleOwner    = "owner"
+	RoleAssignee = "assignee"
+	RoleViewer   = "viewer" // users the task is shared with
+)
+
+// Actions on a task checked against the authorization policy
+const (
+	ActionRead   = "read"
+	ActionUpdate = "update" // update, move, complete, or archive
+	ActionDelete = "delete"
+	ActionAssign = "assign"
+	ActionShare  = "share"
+)
+
+// AssignTaskRequest represents a request to assign a task. A nil user ID unassigns it.
+type AssignTaskRequest struct {
+	UserID *uuid.UUID `json:"user_id"`
+}
+
+// ShareTaskRequest represents a request to share a task read-only with a user
+type ShareTaskRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// Validate validates share task request
+func (req *ShareTaskRequest) Validate() error {
+	if req.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+// RolesFor returns the user's roles on the task
+func (t *Task) RolesFor(userID uuid.UUID) []string {
+	var roles []string
+	if t.UserID == userID {
+		roles = append(roles, RoleOwner)
+	}
+	if t.AssigneeID != nil && *t.AssigneeID == userID {
+		roles = append(roles, RoleAssignee)
+	}
+	if t.IsSharedWith(userID) {
+		roles = append(roles, RoleViewer)
+	}
+	return roles
+}
+
+// IsSharedWith reports whether the task is shared read-only with the user
+func (t *Task) IsSharedWith(userID uuid.UUID) bool {
+	for _, id := range t.SharedWith {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Viewers returns the users other than the owner with access to the task
+func (t *Task) Viewers() []uuid.UUID {
+	var viewers []uuid.UUID
+	if t.AssigneeID != nil {
+		viewers = append(viewers, *t.AssigneeID)
+	}
+	return append(viewers, t.SharedWith...)
+}
+
+// Assign sets or clears the task's assignee and returns the change, if any
+func (t *Task) Assign(userID *uuid.UUID) []FieldChange {
+	if uuidEqual(t.AssigneeID, userID) {
+		return nil
+	}
+
+	change := FieldChange{Field: "assignee_id", OldValue: formatUUID(t.AssigneeID), NewValue: formatUUID(userID)}
+	if userID != nil {
+		assignee := *userID
+		t.AssigneeID = &assignee
+	} else {
+		t.AssigneeID = nil
+	}
+	t.Touch()
+
+	return []FieldChange{change}
+}
+
+// Share gives the user read access to the task and returns the change, if any
+func (t *Task) Share(userID uuid.UUID) ([]FieldChange, error) {
+	if userID == t.UserID {
+		return nil, errors.New("cannot share a task with its owner")
+	}
+	if t.IsSharedWith(userID) {
+		return nil, nil
+	}
+
+	t.SharedWith = append(t.SharedWith, userID)
+	t.Touch()
+
+	return []FieldChange{{Field: "shared_with", NewValue: userID.String()}}, nil
+}
+
+// Unshare revokes the user's read access to the task
+func (t *Task) Unshare(userID uuid.UUID) ([]FieldChange, error) {
+	for i, id := range t.SharedWith {
+		if id == userID {
+			t.SharedWith = append(t.SharedWith[:i:i], t.SharedWith[i+1:]...)
+			t.Touch()
+			return []FieldChange{{Field: "shared_with", OldValue: userID.String()}}, nil
+		}
+	}
+	return nil, errors.New("task is not shared with user")
+}
+
+func uuidEqual(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatUUID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
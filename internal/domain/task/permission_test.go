@@ -0,0 +1,77 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_RolesFor(t *testing.T) {
+	owner, assignee, viewer := uuid.New(), uuid.New(), uuid.New()
+	task := NewTask("Shared task", owner)
+	task.Assign(&assignee)
+	_, err := task.Share(viewer)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{RoleOwner}, task.RolesFor(owner))
+	assert.Equal(t, []string{RoleAssignee}, task.RolesFor(assignee))
+	assert.Equal(t, []string{RoleViewer}, task.RolesFor(viewer))
+	assert.Empty(t, task.RolesFor(uuid.New()))
+	assert.Equal(t, []uuid.UUID{assignee, viewer}, task.Viewers())
+}
+
+func TestTask_Assign(t *testing.T) {
+	assignee := uuid.New()
+	task := NewTask("Task", uuid.New())
+
+	changes := task.Assign(&assignee)
+	assert.Equal(t, []FieldChange{{Field: "assignee_id", NewValue: assignee.String()}}, changes)
+	assert.Equal(t, assignee, *task.AssigneeID)
+
+	assert.Empty(t, task.Assign(&assignee))
+
+	changes = task.Assign(nil)
+	assert.Equal(t, []FieldChange{{Field: "assignee_id", OldValue: assignee.String()}}, changes)
+	assert.Nil(t, task.AssigneeID)
+}
+
+func TestTask_ShareAndUnshare(t *testing.T) {
+	owner, viewer := uuid.New(), uuid.New()
+	task := NewTask("Task", owner)
+
+	_, err := task.Share(owner)
+	assert.EqualError(t, err, "cannot share a task with its owner")
+
+	changes, err := task.Share(viewer)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	// Sharing twice is a no-op
+	changes, err = task.Share(viewer)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, []uuid.UUID{viewer}, task.SharedWith)
+
+	changes, err = task.Unshare(viewer)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{{Field: "shared_with", OldValue: viewer.String()}}, changes)
+	assert.False(t, task.IsSharedWith(viewer))
+
+	_, err = task.Unshare(viewer)
+	assert.EqualError(t, err, "task is not shared with user")
+}
+
+func TestEvent_VisibleTo(t *testing.T) {
+	owner, viewer := uuid.New(), uuid.New()
+	task := NewTask("Task", owner)
+	_, err := task.Share(viewer)
+	require.NoError(t, err)
+
+	event := NewEvent(EventTaskUpdated, task)
+
+	assert.True(t, event.VisibleTo(owner))
+	assert.True(t, event.VisibleTo(viewer))
+	assert.False(t, event.VisibleTo(uuid.New()))
+}
@@ -0,0 +1,107 @@
+package task
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// PositionGap is the spacing between positions assigned when appending or re-indexing
+const PositionGap = 1024.0
+
+// minPositionGap is the smallest gap between neighbours before a column is re-indexed
+const minPositionGap = 1e-6
+
+// MoveTaskRequest represents a request to move a task within or into a status column.
+// Exactly one of Position, BeforeID, or AfterID must be set.
+type MoveTaskRequest struct {
+	Position *int        `json:"position,omitempty"`  // zero-based index in the target column
+	BeforeID *uuid.UUID  `json:"before_id,omitempty"` // place directly before this task
+	AfterID  *uuid.UUID  `json:"after_id,omitempty"`  // place directly after this task
+	Status   *TaskStatus `json:"status,omitempty"`    // target column, defaults to the current status
+}
+
+// Validate validates move task request
+func (req *MoveTaskRequest) Validate() error {
+	targets := 0
+	for _, set := range []bool{req.Position != nil, req.BeforeID != nil, req.AfterID != nil} {
+		if set {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return errors.New("exactly one of position, before_id, or after_id is required")
+	}
+
+	if req.Position != nil && *req.Position < 0 {
+		return errors.New("position must not be negative")
+	}
+
+	if req.Status != nil && !isValidStatus(*req.Status) {
+		return errors.New("invalid status")
+	}
+
+	return nil
+}
+
+// SortByPosition orders a column of tasks by position, oldest first on ties
+func SortByPosition(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Position != tasks[j].Position {
+			return tasks[i].Position < tasks[j].Position
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+}
+
+// PlaceAt positions the task at the index of a column sorted by position, which must not contain it.
+// The position is the midpoint between its new neighbours; when they are too close together the
+// whole column is re-indexed. It returns the tasks whose positions changed, including t.
+func PlaceAt(t *Task, column []*Task, index int) []*Task {
+	if index > len(column) {
+		index = len(column)
+	}
+
+	var prev, next *Task
+	if index > 0 {
+		prev = column[index-1]
+	}
+	if index < len(column) {
+		next = column[index]
+	}
+
+	switch {
+	case prev == nil && next == nil:
+		t.Position = PositionGap
+	case prev == nil:
+		t.Position = next.Position - PositionGap
+	case next == nil:
+		t.Position = prev.Position + PositionGap
+	case next.Position-prev.Position > minPositionGap:
+		t.Position = prev.Position + (next.Position-prev.Position)/2
+	default:
+		return reindex(t, column, index)
+	}
+
+	return []*Task{t}
+}
+
+// reindex spreads the column, with t inserted at index, evenly by PositionGap
+func reindex(t *Task, column []*Task, index int) []*Task {
+	ordered := make([]*Task, 0, len(column)+1)
+	ordered = append(ordered, column[:index]...)
+	ordered = append(ordered, t)
+	ordered = append(ordered, column[index:]...)
+
+	var changed []*Task
+	for i, item := range ordered {
+		position := float64(i+1) * PositionGap
+		if item == t || item.Position != position {
+			item.Position = position
+			changed = append(changed, item)
+		}
+	}
+
+	return changed
+}
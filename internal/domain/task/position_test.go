@@ -0,0 +1,98 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newColumn(positions ...float64) []*Task {
+	column := make([]*Task, len(positions))
+	for i, position := range positions {
+		column[i] = NewTask("Task", uuid.New())
+		column[i].Position = position
+	}
+	return column
+}
+
+func TestMoveTaskRequest_Validate(t *testing.T) {
+	position := 1
+	negative := -1
+	id := uuid.New()
+	invalidStatus := TaskStatus("done")
+
+	tests := []struct {
+		name    string
+		request MoveTaskRequest
+		errMsg  string
+	}{
+		{"position", MoveTaskRequest{Position: &position}, ""},
+		{"before", MoveTaskRequest{BeforeID: &id}, ""},
+		{"after with status", MoveTaskRequest{AfterID: &id, Status: statusPtr(StatusCompleted)}, ""},
+		{"no target", MoveTaskRequest{}, "exactly one of position, before_id, or after_id is required"},
+		{"several targets", MoveTaskRequest{Position: &position, AfterID: &id}, "exactly one of position, before_id, or after_id is required"},
+		{"negative position", MoveTaskRequest{Position: &negative}, "position must not be negative"},
+		{"invalid status", MoveTaskRequest{Position: &position, Status: &invalidStatus}, "invalid status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.errMsg, err.Error())
+		})
+	}
+}
+
+func TestPlaceAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions []float64
+		index     int
+		expected  float64
+	}{
+		{"empty column", nil, 0, PositionGap},
+		{"first", []float64{1024, 2048}, 0, 0},
+		{"between", []float64{1024, 2048}, 1, 1536},
+		{"last", []float64{1024, 2048}, 2, 3072},
+		{"index past end", []float64{1024}, 5, 2048},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moved := NewTask("Moved", uuid.New())
+
+			changed := PlaceAt(moved, newColumn(tt.positions...), tt.index)
+
+			assert.Equal(t, tt.expected, moved.Position)
+			assert.Equal(t, []*Task{moved}, changed)
+		})
+	}
+}
+
+func TestPlaceAt_Reindexes(t *testing.T) {
+	column := newColumn(1, 1, 5000)
+	moved := NewTask("Moved", uuid.New())
+
+	changed := PlaceAt(moved, column, 1)
+
+	assert.Len(t, changed, 4)
+	assert.Equal(t, 1024.0, column[0].Position)
+	assert.Equal(t, 2048.0, moved.Position)
+	assert.Equal(t, 3072.0, column[1].Position)
+	assert.Equal(t, 4096.0, column[2].Position)
+}
+
+func TestSortByPosition(t *testing.T) {
+	column := newColumn(3, 1, 2)
+
+	SortByPosition(column)
+
+	assert.Equal(t, []float64{1, 2, 3}, []float64{column[0].Position, column[1].Position, column[2].Position})
+}
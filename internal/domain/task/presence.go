@@ -0,0 +1,81 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventTaskPresence is the type of presence events, sent to the collaborators on a task
+// when a user opens, edits, or leaves it
+const EventTaskPresence EventType = "task.presence"
+
+// PresenceState is what a user is doing with a task they have open
+type PresenceState string
+
+const (
+	PresenceViewing PresenceState = "viewing"
+	PresenceEditing PresenceState = "editing"
+	PresenceLeft    PresenceState = "left" // the user no longer has the task open
+)
+
+// Presence is a user who has a task open
+type Presence struct {
+	UserID uuid.UUID     `json:"user_id"`
+	State  PresenceState `json:"state"`
+	Since  time.Time     `json:"since"` // when the user opened the task
+}
+
+// PresenceEvent is a change of a user's presence on a task. Unlike task events, presence
+// events are not streamed or kept; they only reach clients connected at the time.
+type PresenceEvent struct {
+	Type        EventType     `json:"type"`
+	TaskID      uuid.UUID     `json:"task_id"`
+	UserID      uuid.UUID     `json:"user_id"` // the user whose presence changed
+	State       PresenceState `json:"state"`
+	OccurredAt  time.Time     `json:"occurred_at"`
+	Audience    []uuid.UUID   `json:"-"` // owner, assignee, and shared users of the task
+	WorkspaceID *uuid.UUID    `json:"-"` // workspace whose members may receive the event
+}
+
+// VisibleTo reports whether the user may receive the event. Users do not receive their own
+// presence.
+func (e *PresenceEvent) VisibleTo(userID uuid.UUID) bool {
+	if userID == e.UserID {
+		return false
+	}
+	for _, id := range e.Audience {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// PresenceMessage is sent by WebSocket clients when they open, edit, or leave a task, e.g.
+// {"type": "presence", "task_id": "...", "state": "editing"}
+type PresenceMessage struct {
+	Type   string        `json:"type"`
+	TaskID uuid.UUID     `json:"task_id"`
+	State  PresenceState `json:"state"`
+}
+
+// PresenceMessageType is the type of presence messages sent by clients
+const PresenceMessageType = "presence"
+
+// Validate validates the presence message
+func (m *PresenceMessage) Validate() error {
+	if m.Type != PresenceMessageType {
+		return errors.New("unknown message type")
+	}
+	if m.TaskID == uuid.Nil {
+		return errors.New("task_id is required")
+	}
+	switch m.State {
+	case PresenceViewing, PresenceEditing, PresenceLeft:
+		return nil
+	default:
+		return errors.New("state must be viewing, editing, or left")
+	}
+}
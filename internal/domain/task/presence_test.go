@@ -0,0 +1,26 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenceMessage_Validate(t *testing.T) {
+	taskID := uuid.New()
+
+	assert.NoError(t, (&PresenceMessage{Type: "presence", TaskID: taskID, State: PresenceEditing}).Validate())
+	assert.EqualError(t, (&PresenceMessage{Type: "typing", TaskID: taskID, State: PresenceEditing}).Validate(), "unknown message type")
+	assert.EqualError(t, (&PresenceMessage{Type: "presence", State: PresenceViewing}).Validate(), "task_id is required")
+	assert.EqualError(t, (&PresenceMessage{Type: "presence", TaskID: taskID, State: "away"}).Validate(), "state must be viewing, editing, or left")
+}
+
+func TestPresenceEvent_VisibleTo(t *testing.T) {
+	owner, shared, other := uuid.New(), uuid.New(), uuid.New()
+	event := &PresenceEvent{UserID: shared, Audience: []uuid.UUID{owner, shared}}
+
+	assert.True(t, event.VisibleTo(owner))
+	assert.False(t, event.VisibleTo(shared))
+	assert.False(t, event.VisibleTo(other))
+}
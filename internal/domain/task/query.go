@@ -0,0 +1,329 @@
+package task
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predicate reports whether a task satisfies a single filter condition
+type Predicate func(t *Task) bool
+
+// statusRank orders statuses by workflow progress
+var statusRank = map[TaskStatus]int{
+	StatusPending:    1,
+	StatusInProgress: 2,
+	StatusCompleted:  3,
+	StatusCancelled:  4,
+}
+
+// priorityRank orders priorities from lowest to highest
+var priorityRank = map[TaskPriority]int{
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+}
+
+// ParseStatuses parses a comma-separated list of statuses, e.g. "pending,in_progress"
+func ParseStatuses(value string) ([]TaskStatus, error) {
+	var statuses []TaskStatus
+	for _, part := range strings.Split(value, ",") {
+		status := TaskStatus(strings.TrimSpace(part))
+		if !isValidStatus(status) {
+			return nil, errors.New("invalid status: " + string(status))
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Due periods, relative to the current time in the time zone of the user
+const (
+	DueToday    = "today"
+	DueThisWeek = "this_week"
+)
+
+// DuePeriod returns the start and end of the due period containing now, in the location of
+// now: the day for today, and the week from Monday for this_week. Days are calendar days of
+// the location, so they last 23 or 25 hours when daylight saving time starts or ends.
+func DuePeriod(period string, now time.Time) (time.Time, time.Time, error) {
+	year, month, day := now.Date()
+	switch period {
+	case DueToday:
+		return time.Date(year, month, day, 0, 0, 0, 0, now.Location()),
+			time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()), nil
+	case DueThisWeek:
+		// Weeks start on Monday, so Sunday is the last day of its week
+		monday := day - (int(now.Weekday())+6)%7
+		return time.Date(year, month, monday, 0, 0, 0, 0, now.Location()),
+			time.Date(year, month, monday+7, 0, 0, 0, 0, now.Location()), nil
+	default:
+		return time.Time{}, time.Time{}, errors.New("invalid due value: " + period + ", expected today or this_week")
+	}
+}
+
+// ParseTaskSort parses a sort expression such as "priority:desc,due_date:asc".
+// The order defaults to asc when omitted.
+func ParseTaskSort(expr string) (*TaskSort, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(expr, ",") {
+		field, order, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if order == "" {
+			order = "asc"
+		}
+
+		if !isValidSortField(field) {
+			return nil, errors.New("invalid sort field: " + field)
+		}
+		if order != "asc" && order != "desc" {
+			return nil, errors.New("invalid sort order: " + order)
+		}
+
+		keys = append(keys, SortKey{Field: field, Order: order})
+	}
+
+	return &TaskSort{Field: keys[0].Field, Order: keys[0].Order, ThenBy: keys[1:]}, nil
+}
+
+// Keys returns the primary sort key followed by its tie-breakers
+func (s *TaskSort) Keys() []SortKey {
+	return append([]SortKey{{Field: s.Field, Order: s.Order}}, s.ThenBy...)
+}
+
+// String formats the sort options as a sort expression
+func (s *TaskSort) String() string {
+	var parts []string
+	for _, key := range s.Keys() {
+		parts = append(parts, key.Field+":"+key.Order)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Less reports whether task a sorts before task b
+func (s *TaskSort) Less(a, b *Task) bool {
+	for _, key := range s.Keys() {
+		cmp := compareField(a, b, key.Field)
+		if cmp == 0 {
+			continue
+		}
+
+		// Tasks without a due date come last in either direction
+		if key.Field == "due_date" && (a.DueDate == nil || b.DueDate == nil) {
+			return a.DueDate != nil
+		}
+
+		if key.Order == "desc" {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+
+	return false
+}
+
+// Predicates returns the conditions of the filter
+func (f *TaskFilter) Predicates() []Predicate {
+	var predicates []Predicate
+
+	if f.Status != nil {
+		status := *f.Status
+		predicates = append(predicates, func(t *Task) bool {
+			return t.Status == status
+		})
+	}
+
+	if len(f.Statuses) > 0 {
+		statuses := f.Statuses
+		predicates = append(predicates, func(t *Task) bool {
+			for _, status := range statuses {
+				if t.Status == status {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	if f.Search != "" {
+		searchLower := strings.ToLower(f.Search)
+		predicates = append(predicates, func(t *Task) bool {
+			return strings.Contains(strings.ToLower(t.Title), searchLower)
+		})
+	}
+
+	if f.CreatedAfter != nil {
+		after := *f.CreatedAfter
+		predicates = append(predicates, func(t *Task) bool {
+			return t.CreatedAt.After(after)
+		})
+	}
+
+	if f.CreatedBefore != nil {
+		before := *f.CreatedBefore
+		predicates = append(predicates, func(t *Task) bool {
+			return t.CreatedAt.Before(before)
+		})
+	}
+
+	if f.DueFrom != nil {
+		from := *f.DueFrom
+		predicates = append(predicates, func(t *Task) bool {
+			return t.DueDate != nil && !t.DueDate.Before(from)
+		})
+	}
+
+	if f.DueBefore != nil {
+		before := *f.DueBefore
+		predicates = append(predicates, func(t *Task) bool {
+			return t.DueDate != nil && t.DueDate.Before(before)
+		})
+	}
+
+	if f.AssigneeID != nil {
+		assigneeID := *f.AssigneeID
+		predicates = append(predicates, func(t *Task) bool {
+			return t.AssigneeID != nil && *t.AssigneeID == assigneeID
+		})
+	}
+
+	if f.ProjectID != nil {
+		projectID := *f.ProjectID
+		predicates = append(predicates, func(t *Task) bool {
+			return t.ProjectID != nil && *t.ProjectID == projectID
+		})
+	}
+
+	if f.Archived != nil {
+		archived := *f.Archived
+		predicates = append(predicates, func(t *Task) bool {
+			return t.IsArchived() == archived
+		})
+	}
+
+	return predicates
+}
+
+// Matches reports whether the task satisfies every condition of the filter.
+// Archived tasks only match a filter that explicitly asks for them; a nil filter
+// matches every task that is not archived.
+func (f *TaskFilter) Matches(t *Task) bool {
+	if f == nil || f.Archived == nil {
+		if t.IsArchived() {
+			return false
+		}
+		if f == nil {
+			return true
+		}
+	}
+
+	for _, predicate := range f.Predicates() {
+		if !predicate(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the filter for response metadata, e.g. "status:pending|in_progress,search:docs"
+func (f *TaskFilter) String() string {
+	var parts []string
+
+	if f.Status != nil {
+		parts = append(parts, "status:"+string(*f.Status))
+	}
+	if len(f.Statuses) > 0 {
+		names := make([]string, len(f.Statuses))
+		for i, status := range f.Statuses {
+			names[i] = string(status)
+		}
+		parts = append(parts, "status:"+strings.Join(names, "|"))
+	}
+	if f.Search != "" {
+		parts = append(parts, "search:"+f.Search)
+	}
+	if f.CreatedAfter != nil {
+		parts = append(parts, "created_after:"+f.CreatedAfter.UTC().Format(time.RFC3339))
+	}
+	if f.CreatedBefore != nil {
+		parts = append(parts, "created_before:"+f.CreatedBefore.UTC().Format(time.RFC3339))
+	}
+	if f.DueFrom != nil {
+		parts = append(parts, "due_from:"+f.DueFrom.Format(time.RFC3339))
+	}
+	if f.DueBefore != nil {
+		parts = append(parts, "due_before:"+f.DueBefore.Format(time.RFC3339))
+	}
+	if f.AssigneeID != nil {
+		parts = append(parts, "assignee:"+f.AssigneeID.String())
+	}
+	if f.ProjectID != nil {
+		parts = append(parts, "project:"+f.ProjectID.String())
+	}
+	if f.Archived != nil {
+		parts = append(parts, "archived:"+strconv.FormatBool(*f.Archived))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// compareField compares a single field of two tasks, returning -1, 0, or 1
+func compareField(a, b *Task, field string) int {
+	switch field {
+	case "title":
+		return strings.Compare(a.Title, b.Title)
+	case "status":
+		return compareInts(statusRank[a.Status], statusRank[b.Status])
+	case "priority":
+		return compareInts(priorityRank[a.Priority], priorityRank[b.Priority])
+	case "due_date":
+		switch {
+		case a.DueDate == nil && b.DueDate == nil:
+			return 0
+		case a.DueDate == nil:
+			return 1
+		case b.DueDate == nil:
+			return -1
+		}
+		return a.DueDate.Compare(*b.DueDate)
+	case "position":
+		switch {
+		case a.Position < b.Position:
+			return -1
+		case a.Position > b.Position:
+			return 1
+		}
+		return 0
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isValidSortField(field string) bool {
+	switch field {
+	case "created_at", "updated_at", "title", "status", "priority", "due_date", "position":
+		return true
+	default:
+		return false
+	}
+}
+
+// SearchHit represents a task matching a full-text search query
+type SearchHit struct {
+	Task  *Task   `json:"task"`
+	Score float64 `json:"score"` // relevance, higher is better
+}
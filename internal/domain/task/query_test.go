@@ -0,0 +1,258 @@
+package task
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatuses(t *testing.T) {
+	statuses, err := ParseStatuses("pending, in_progress")
+	require.NoError(t, err)
+	assert.Equal(t, []TaskStatus{StatusPending, StatusInProgress}, statuses)
+
+	_, err = ParseStatuses("pending,done")
+	require.Error(t, err)
+	assert.Equal(t, "invalid status: done", err.Error())
+}
+
+func TestParseTaskSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+		errMsg   string
+	}{
+		{"single field", "title:desc", "title:desc", ""},
+		{"multiple fields", "priority:desc,due_date:asc", "priority:desc,due_date:asc", ""},
+		{"default order", "status", "status:asc", ""},
+		{"invalid field", "owner:asc", "", "invalid sort field: owner"},
+		{"invalid order", "title:up", "", "invalid sort order: up"},
+		{"empty field", "title:asc,", "", "invalid sort field: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParseTaskSort(tt.expr)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, sort.String())
+		})
+	}
+}
+
+func TestTaskSort_Less(t *testing.T) {
+	now := time.Now()
+	tomorrow := now.Add(24 * time.Hour)
+	userID := uuid.New()
+
+	newTask := func(title string, priority TaskPriority, dueDate *time.Time) *Task {
+		task := NewTask(title, userID)
+		task.Priority = priority
+		task.DueDate = dueDate
+		return task
+	}
+
+	tasks := []*Task{
+		newTask("low", PriorityLow, &now),
+		newTask("high-undated", PriorityHigh, nil),
+		newTask("high-later", PriorityHigh, &tomorrow),
+		newTask("high-sooner", PriorityHigh, &now),
+	}
+
+	sortOptions, err := ParseTaskSort("priority:desc,due_date:asc")
+	require.NoError(t, err)
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return sortOptions.Less(tasks[i], tasks[j])
+	})
+
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	assert.Equal(t, []string{"high-sooner", "high-later", "high-undated", "low"}, titles)
+
+	// Undated tasks stay last when sorting descending
+	desc := &TaskSort{Field: "due_date", Order: "desc"}
+	assert.True(t, desc.Less(tasks[1], tasks[2]))
+	assert.False(t, desc.Less(tasks[2], tasks[1]))
+}
+
+func TestTaskFilter_Matches(t *testing.T) {
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+
+	assignee := uuid.New()
+	other := uuid.New()
+
+	task := NewTask("Write documentation", uuid.New())
+	task.Status = StatusInProgress
+	task.AssigneeID = &assignee
+
+	tests := []struct {
+		name    string
+		filter  TaskFilter
+		matches bool
+	}{
+		{"empty filter", TaskFilter{}, true},
+		{"status in list", TaskFilter{Statuses: []TaskStatus{StatusPending, StatusInProgress}}, true},
+		{"status not in list", TaskFilter{Statuses: []TaskStatus{StatusPending, StatusCompleted}}, false},
+		{"single status", TaskFilter{Status: statusPtr(StatusPending)}, false},
+		{"search", TaskFilter{Search: "DOCUMENT"}, true},
+		{"created in range", TaskFilter{CreatedAfter: &yesterday, CreatedBefore: &tomorrow}, true},
+		{"created before range", TaskFilter{CreatedAfter: &tomorrow}, false},
+		{"assignee", TaskFilter{AssigneeID: &assignee}, true},
+		{"other assignee", TaskFilter{AssigneeID: &other}, false},
+		{"all conditions must match", TaskFilter{Search: "documentation", CreatedBefore: &yesterday}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, tt.filter.Matches(task))
+		})
+	}
+}
+
+func TestTaskFilter_Matches_Due(t *testing.T) {
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	before := from.AddDate(0, 0, 1)
+	dueAt := func(due time.Time) *Task {
+		task := NewTask("Water the plants", uuid.New())
+		task.DueDate = &due
+		return task
+	}
+
+	filter := &TaskFilter{DueFrom: &from, DueBefore: &before}
+	assert.True(t, filter.Matches(dueAt(from)))
+	assert.True(t, filter.Matches(dueAt(before.Add(-time.Second))))
+	assert.False(t, filter.Matches(dueAt(before)))
+	assert.False(t, filter.Matches(dueAt(from.Add(-time.Second))))
+	// Tasks without a due date are never due in a period
+	assert.False(t, filter.Matches(NewTask("Someday", uuid.New())))
+}
+
+func TestDuePeriod(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		period     string
+		now        time.Time
+		wantFrom   time.Time
+		wantBefore time.Time
+	}{
+		{"today", DueToday, time.Date(2026, 3, 11, 23, 30, 0, 0, jakarta),
+			time.Date(2026, 3, 11, 0, 0, 0, 0, jakarta), time.Date(2026, 3, 12, 0, 0, 0, 0, jakarta)},
+		{"this week from wednesday", DueThisWeek, time.Date(2026, 3, 11, 9, 0, 0, 0, jakarta),
+			time.Date(2026, 3, 9, 0, 0, 0, 0, jakarta), time.Date(2026, 3, 16, 0, 0, 0, 0, jakarta)},
+		{"this week from sunday", DueThisWeek, time.Date(2026, 3, 15, 9, 0, 0, 0, jakarta),
+			time.Date(2026, 3, 9, 0, 0, 0, 0, jakarta), time.Date(2026, 3, 16, 0, 0, 0, 0, jakarta)},
+		{"this week from monday", DueThisWeek, time.Date(2026, 3, 16, 0, 0, 0, 0, jakarta),
+			time.Date(2026, 3, 16, 0, 0, 0, 0, jakarta), time.Date(2026, 3, 23, 0, 0, 0, 0, jakarta)},
+		{"daylight saving time starts", DueToday, time.Date(2026, 3, 29, 12, 0, 0, 0, berlin),
+			time.Date(2026, 3, 29, 0, 0, 0, 0, berlin), time.Date(2026, 3, 30, 0, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, before, err := DuePeriod(tt.period, tt.now)
+			require.NoError(t, err)
+			assert.True(t, tt.wantFrom.Equal(from), "from %s", from)
+			assert.True(t, tt.wantBefore.Equal(before), "before %s", before)
+		})
+	}
+
+	// The day of daylight saving time starting lasts 23 hours
+	from, before, err := DuePeriod(DueToday, time.Date(2026, 3, 29, 12, 0, 0, 0, berlin))
+	require.NoError(t, err)
+	assert.Equal(t, 23*time.Hour, before.Sub(from))
+
+	_, _, err = DuePeriod("tomorrow", time.Now())
+	assert.EqualError(t, err, "invalid due value: tomorrow, expected today or this_week")
+}
+
+func TestTaskFilter_Matches_Archived(t *testing.T) {
+	archived, active := true, false
+
+	task := NewTask("Write documentation", uuid.New())
+	_, err := task.Archive()
+	require.NoError(t, err)
+
+	// Archived tasks are excluded unless explicitly requested
+	var nilFilter *TaskFilter
+	assert.False(t, nilFilter.Matches(task))
+	assert.False(t, (&TaskFilter{Search: "docu"}).Matches(task))
+	assert.True(t, (&TaskFilter{Archived: &archived}).Matches(task))
+	assert.False(t, (&TaskFilter{Archived: &archived, Search: "other"}).Matches(task))
+	assert.False(t, (&TaskFilter{Archived: &active}).Matches(task))
+
+	_, err = task.Unarchive()
+	require.NoError(t, err)
+	assert.True(t, nilFilter.Matches(task))
+	assert.False(t, (&TaskFilter{Archived: &archived}).Matches(task))
+}
+
+func TestTaskFilter_String(t *testing.T) {
+	createdAfter := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	filter := &TaskFilter{
+		Statuses:     []TaskStatus{StatusPending, StatusInProgress},
+		Search:       "docs",
+		CreatedAfter: &createdAfter,
+	}
+
+	assert.Equal(t, "status:pending|in_progress,search:docs,created_after:2024-01-15T00:00:00Z", filter.String())
+}
+
+func FuzzParseStatuses(f *testing.F) {
+	f.Add("pending")
+	f.Add("pending, in_progress,completed")
+	f.Add(",,")
+	f.Add("pendiente,完成")
+
+	f.Fuzz(func(t *testing.T, value string) {
+		statuses, err := ParseStatuses(value)
+		if err != nil {
+			return
+		}
+		require.NotEmpty(t, statuses)
+		for _, status := range statuses {
+			assert.True(t, isValidStatus(status), "status %q", status)
+		}
+	})
+}
+
+func FuzzParseTaskSort(f *testing.F) {
+	f.Add("title")
+	f.Add("priority:desc,due_date:asc")
+	f.Add(" status : desc ")
+	f.Add("title:asc:desc,,")
+	f.Add("títle:désc")
+
+	f.Fuzz(func(t *testing.T, expr string) {
+		sort, err := ParseTaskSort(expr)
+		if err != nil {
+			return
+		}
+		for _, key := range sort.Keys() {
+			assert.True(t, isValidSortField(key.Field), "field %q", key.Field)
+			assert.Contains(t, []string{"asc", "desc"}, key.Order)
+		}
+
+		// Parsed expressions format to an equivalent canonical expression
+		reparsed, err := ParseTaskSort(sort.String())
+		require.NoError(t, err)
+		assert.Equal(t, sort, reparsed)
+	})
+}
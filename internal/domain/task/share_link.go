@@ -0,0 +1,180 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLinkPrefix starts every share link token, so leaked tokens are easy to recognize
+const ShareLinkPrefix = "shr_"
+
+// Bounds of how many days share links are valid for
+const (
+	DefaultShareLinkDays = 7
+	MaxShareLinkDays     = 90
+)
+
+// MaxShareLinks bounds the active share links of a task or project
+const MaxShareLinks = 10
+
+// ErrShareLinkNotFound is returned for unknown, expired, or revoked share link tokens
+var ErrShareLinkNotFound = errors.New("share link not found or expired")
+
+// ShareTarget is what a share link gives read-only access to: a task, or a project of a
+// workspace
+type ShareTarget struct {
+	TaskID      *uuid.UUID
+	WorkspaceID *uuid.UUID
+	ProjectID   *uuid.UUID
+}
+
+// ShareLink gives anyone with its token read-only access to a task or project until it
+// expires or is revoked. Only a hash of the token is kept; the token itself is returned
+// once, when the link is created.
+type ShareLink struct {
+	ID           uuid.UUID  `json:"id"`
+	TaskID       *uuid.UUID `json:"task_id,omitempty"`
+	WorkspaceID  *uuid.UUID `json:"workspace_id,omitempty"`
+	ProjectID    *uuid.UUID `json:"project_id,omitempty"`
+	Hash         string     `json:"-"`
+	CreatedBy    uuid.UUID  `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	Views        int        `json:"views"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+}
+
+// Snapshot returns a copy of the share link that later views and revocation do not affect
+func (l *ShareLink) Snapshot() *ShareLink {
+	snapshot := *l
+	return &snapshot
+}
+
+// CreatedShareLink is a newly created share link along with its token
+type CreatedShareLink struct {
+	*ShareLink
+	Token string `json:"token"`
+}
+
+// CreateShareLinkRequest represents a request to create a share link
+type CreateShareLinkRequest struct {
+	ExpiresInDays int `json:"expires_in_days,omitempty"` // defaults to DefaultShareLinkDays
+}
+
+// Validate validates create share link request
+func (req *CreateShareLinkRequest) Validate() error {
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = DefaultShareLinkDays
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > MaxShareLinkDays {
+		return errors.New("expires_in_days must be between 1 and 90")
+	}
+	return nil
+}
+
+// Shares reports whether the link gives access to the target
+func (l *ShareLink) Shares(target ShareTarget) bool {
+	if target.TaskID != nil {
+		return l.TaskID != nil && *l.TaskID == *target.TaskID
+	}
+	return l.ProjectID != nil && target.ProjectID != nil && *l.ProjectID == *target.ProjectID
+}
+
+// IsActive reports whether the link can be viewed at the time
+func (l *ShareLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// PublicTask is a task as shown to viewers of a share link, without the users it belongs
+// to or is shared with
+type PublicTask struct {
+	ID          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Status      TaskStatus      `json:"status"`
+	Priority    TaskPriority    `json:"priority"`
+	DueDate     *time.Time      `json:"due_date,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Checklist   []ChecklistItem `json:"checklist,omitempty"`
+	Progress    *int            `json:"progress,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// SharedView is what a share link shows: a task, or a project and its tasks
+type SharedView struct {
+	Task    *PublicTask    `json:"task,omitempty"`
+	Project *SharedProject `json:"project,omitempty"`
+}
+
+// SharedProject is a project as shown to viewers of a share link
+type SharedProject struct {
+	ID    uuid.UUID     `json:"id"`
+	Name  string        `json:"name"`
+	Tasks []*PublicTask `json:"tasks"`
+}
+
+// Bounds of the number of tasks on a page of a project board
+const (
+	DefaultBoardLimit = 50
+	MaxBoardLimit     = 100
+)
+
+// ProjectBoard is a page of the board of a shared project, for embedding in status pages
+type ProjectBoard struct {
+	ID    uuid.UUID    `json:"id"`
+	Name  string       `json:"name"`
+	Tasks []*BoardTask `json:"tasks"`
+	// UpdatedAt is when a task of the page last changed
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// BoardTask is a task as shown on a project board: its progress only, without its
+// description or checklist
+type BoardTask struct {
+	ID          uuid.UUID    `json:"id"`
+	Title       string       `json:"title"`
+	Status      TaskStatus   `json:"status"`
+	Priority    TaskPriority `json:"priority"`
+	DueDate     *time.Time   `json:"due_date,omitempty"`
+	Progress    *int         `json:"progress,omitempty"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+}
+
+// NewBoardTask returns the board view of the task
+func NewBoardTask(t *Task) *BoardTask {
+	return &BoardTask{
+		ID:          t.ID,
+		Title:       t.Title,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		Progress:    t.Progress,
+		UpdatedAt:   t.UpdatedAt,
+		CompletedAt: t.CompletedAt,
+	}
+}
+
+// NewPublicTask returns the public view of the task
+func NewPublicTask(t *Task) *PublicTask {
+	snapshot := t.Snapshot()
+	return &PublicTask{
+		ID:          snapshot.ID,
+		Title:       snapshot.Title,
+		Description: snapshot.Description,
+		Status:      snapshot.Status,
+		Priority:    snapshot.Priority,
+		DueDate:     snapshot.DueDate,
+		Tags:        snapshot.Tags,
+		Checklist:   snapshot.Checklist,
+		Progress:    snapshot.Progress,
+		CreatedAt:   snapshot.CreatedAt,
+		UpdatedAt:   snapshot.UpdatedAt,
+		CompletedAt: snapshot.CompletedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateShareLinkRequest_Validate(t *testing.T) {
+	req := &CreateShareLinkRequest{}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, DefaultShareLinkDays, req.ExpiresInDays)
+
+	assert.NoError(t, (&CreateShareLinkRequest{ExpiresInDays: MaxShareLinkDays}).Validate())
+	assert.EqualError(t, (&CreateShareLinkRequest{ExpiresInDays: 91}).Validate(), "expires_in_days must be between 1 and 90")
+	assert.EqualError(t, (&CreateShareLinkRequest{ExpiresInDays: -1}).Validate(), "expires_in_days must be between 1 and 90")
+}
+
+func TestShareLink_SharesAndIsActive(t *testing.T) {
+	taskID, workspaceID, projectID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	taskLink := &ShareLink{TaskID: &taskID, ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, taskLink.Shares(ShareTarget{TaskID: &taskID}))
+	assert.False(t, taskLink.Shares(ShareTarget{WorkspaceID: &workspaceID, ProjectID: &projectID}))
+
+	projectLink := &ShareLink{WorkspaceID: &workspaceID, ProjectID: &projectID, ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, projectLink.Shares(ShareTarget{WorkspaceID: &workspaceID, ProjectID: &projectID}))
+	assert.False(t, projectLink.Shares(ShareTarget{TaskID: &taskID}))
+
+	assert.True(t, taskLink.IsActive(now))
+	assert.False(t, taskLink.IsActive(now.Add(2*time.Hour)))
+	taskLink.RevokedAt = &now
+	assert.False(t, taskLink.IsActive(now))
+}
+
+func TestNewPublicTask(t *testing.T) {
+	owner := uuid.New()
+	source := NewTask("Plan the offsite", owner)
+	source.SharedWith = []uuid.UUID{uuid.New()}
+	source.Tags = []string{"team"}
+
+	public := NewPublicTask(source)
+	assert.Equal(t, source.ID, public.ID)
+	assert.Equal(t, source.Title, public.Title)
+
+	// Later changes to the task do not affect the view
+	source.Tags[0] = "home"
+	assert.Equal(t, []string{"team"}, public.Tags)
+}
@@ -0,0 +1,105 @@
+package task
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxStatsDays is the longest date range, in days, covered by a statistics request
+const MaxStatsDays = 366
+
+// Stats represents aggregated task analytics for a user
+type Stats struct {
+	Total                    int                  `json:"total"`
+	ByStatus                 map[TaskStatus]int   `json:"by_status"`
+	ByPriority               map[TaskPriority]int `json:"by_priority"`
+	Daily                    []DailyStats         `json:"daily"`
+	AverageCompletionSeconds *float64             `json:"average_completion_seconds"`
+}
+
+// DailyStats represents the number of tasks created and completed on a single day (UTC)
+type DailyStats struct {
+	Date      string `json:"date"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// StatsRange is an inclusive range of UTC days
+type StatsRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewStatsRange creates a range covering the days of from and to, inclusive
+func NewStatsRange(from, to time.Time) (*StatsRange, error) {
+	r := &StatsRange{From: startOfDay(from), To: startOfDay(to)}
+
+	if r.To.Before(r.From) {
+		return nil, errors.New("from must not be after to")
+	}
+	if r.Days() > MaxStatsDays {
+		return nil, errors.New("date range must not exceed 366 days")
+	}
+
+	return r, nil
+}
+
+// Days returns the number of days in the range
+func (r *StatsRange) Days() int {
+	return int(r.To.Sub(r.From).Hours()/24) + 1
+}
+
+// Contains reports whether the time falls on a day within the range
+func (r *StatsRange) Contains(t time.Time) bool {
+	day := startOfDay(t)
+	return !day.Before(r.From) && !day.After(r.To)
+}
+
+// NewStats computes statistics for the tasks. Status and priority counts cover all tasks;
+// daily counts and the average completion time cover the range.
+func NewStats(tasks []*Task, r *StatsRange) *Stats {
+	stats := &Stats{
+		Total:      len(tasks),
+		ByStatus:   map[TaskStatus]int{StatusPending: 0, StatusInProgress: 0, StatusCompleted: 0, StatusCancelled: 0},
+		ByPriority: map[TaskPriority]int{PriorityLow: 0, PriorityMedium: 0, PriorityHigh: 0},
+		Daily:      make([]DailyStats, r.Days()),
+	}
+
+	for i := range stats.Daily {
+		stats.Daily[i].Date = r.From.AddDate(0, 0, i).Format(time.DateOnly)
+	}
+
+	var completedCount int
+	var completionTotal time.Duration
+
+	for _, t := range tasks {
+		stats.ByStatus[t.Status]++
+		stats.ByPriority[t.Priority]++
+
+		if r.Contains(t.CreatedAt) {
+			stats.Daily[r.dayIndex(t.CreatedAt)].Created++
+		}
+
+		if t.CompletedAt != nil && r.Contains(*t.CompletedAt) {
+			stats.Daily[r.dayIndex(*t.CompletedAt)].Completed++
+			completedCount++
+			completionTotal += t.CompletedAt.Sub(t.CreatedAt)
+		}
+	}
+
+	if completedCount > 0 {
+		average := completionTotal.Seconds() / float64(completedCount)
+		stats.AverageCompletionSeconds = &average
+	}
+
+	return stats
+}
+
+// dayIndex returns the position of the time's day within the range
+func (r *StatsRange) dayIndex(t time.Time) int {
+	return int(startOfDay(t).Sub(r.From).Hours() / 24)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,94 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatsRange(t *testing.T) {
+	day := time.Date(2024, 1, 15, 18, 30, 0, 0, time.UTC)
+
+	r, err := NewStatsRange(day, day.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.Days())
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), r.From)
+	assert.True(t, r.Contains(time.Date(2024, 1, 17, 23, 59, 0, 0, time.UTC)))
+	assert.False(t, r.Contains(time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC)))
+
+	_, err = NewStatsRange(day, day.AddDate(0, 0, -1))
+	require.Error(t, err)
+	assert.Equal(t, "from must not be after to", err.Error())
+
+	_, err = NewStatsRange(day, day.AddDate(0, 0, MaxStatsDays))
+	require.Error(t, err)
+	assert.Equal(t, "date range must not exceed 366 days", err.Error())
+}
+
+func TestNewStats(t *testing.T) {
+	day := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+
+	newTask := func(createdAt time.Time, status TaskStatus, priority TaskPriority, completedAfter time.Duration) *Task {
+		task := NewTask("Task", userID)
+		task.CreatedAt = createdAt
+		task.Status = status
+		task.Priority = priority
+		if completedAfter > 0 {
+			completedAt := createdAt.Add(completedAfter)
+			task.CompletedAt = &completedAt
+		}
+		return task
+	}
+
+	tasks := []*Task{
+		newTask(day, StatusCompleted, PriorityHigh, 2*time.Hour),
+		newTask(day, StatusCompleted, PriorityMedium, 28*time.Hour),
+		newTask(day.AddDate(0, 0, 1), StatusPending, PriorityMedium, 0),
+		newTask(day.AddDate(0, 0, -10), StatusInProgress, PriorityLow, 0),
+	}
+
+	r, err := NewStatsRange(day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+
+	stats := NewStats(tasks, r)
+
+	assert.Equal(t, 4, stats.Total)
+	assert.Equal(t, map[TaskStatus]int{StatusPending: 1, StatusInProgress: 1, StatusCompleted: 2, StatusCancelled: 0}, stats.ByStatus)
+	assert.Equal(t, map[TaskPriority]int{PriorityLow: 1, PriorityMedium: 2, PriorityHigh: 1}, stats.ByPriority)
+	assert.Equal(t, []DailyStats{
+		{Date: "2024-01-15", Created: 2, Completed: 1},
+		{Date: "2024-01-16", Created: 1, Completed: 1},
+	}, stats.Daily)
+	require.NotNil(t, stats.AverageCompletionSeconds)
+	assert.Equal(t, float64(15*60*60), *stats.AverageCompletionSeconds)
+}
+
+func TestNewStats_NoCompletedTasks(t *testing.T) {
+	r, err := NewStatsRange(time.Now(), time.Now())
+	require.NoError(t, err)
+
+	stats := NewStats(nil, r)
+
+	assert.Equal(t, 0, stats.Total)
+	assert.Len(t, stats.Daily, 1)
+	assert.Nil(t, stats.AverageCompletionSeconds)
+}
+
+func TestTask_SetStatus(t *testing.T) {
+	task := NewTask("Task", uuid.New())
+
+	task.SetStatus(StatusCompleted)
+	require.NotNil(t, task.CompletedAt)
+	completedAt := *task.CompletedAt
+
+	// Completing again keeps the original completion time
+	task.SetStatus(StatusCompleted)
+	assert.Equal(t, completedAt, *task.CompletedAt)
+
+	task.SetStatus(StatusPending)
+	assert.Nil(t, task.CompletedAt)
+}
@@ -0,0 +1,36 @@
+package task
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// MaxSyncChanges is the number of most recent task changes kept for syncing; clients with
+// an older cursor must sync all their tasks again
+const MaxSyncChanges = 10000
+
+// DefaultSyncLimit and MaxSyncLimit bound the changed tasks returned by one sync request
+const (
+	DefaultSyncLimit = 100
+	MaxSyncLimit     = 1000
+)
+
+// ErrCursorExpired is returned for sync cursors older than the changes kept, or issued
+// before the server restarted
+var ErrCursorExpired = errors.New("sync cursor expired, sync all tasks again")
+
+// ErrInvalidCursor is returned for sync cursors that were not issued by the server
+var ErrInvalidCursor = errors.New("invalid sync cursor")
+
+// ChangeSet lists the tasks that changed for a user since a sync cursor, by ID. A task is
+// listed once, by its latest change.
+type ChangeSet struct {
+	Created []uuid.UUID `json:"created"`
+	Updated []uuid.UUID `json:"updated"`
+	// Deleted lists deleted tasks and tasks the user can no longer see, such as tasks that
+	// are no longer shared with them
+	Deleted []uuid.UUID `json:"deleted"`
+	Cursor  string      `json:"cursor"`   // passed as since to get the following changes
+	HasMore bool        `json:"has_more"` // more changes follow the cursor
+}
@@ -0,0 +1,46 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTags is the maximum number of tags of a task
+const MaxTags = 10
+
+// MaxTagLength is the maximum length of a tag in characters
+const MaxTagLength = 32
+
+// tagPattern matches tags: letters, digits, hyphens, and underscores
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+// NormalizeTags validates tags and returns them in lowercase, without a leading # and
+// without duplicates, in the order given
+func NormalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" {
+			return nil, errors.New("tags cannot be empty")
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tags must be at most %d characters", MaxTagLength)
+		}
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("tag %q may only contain letters, digits, hyphens, and underscores", tag)
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("tasks can have at most %d tags", MaxTags)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,61 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{"Finance", "#home", " finance ", "q3-plan", "été"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finance", "home", "q3-plan", "été"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	tests := []struct {
+		name   string
+		tags   []string
+		errMsg string
+	}{
+		{"empty", []string{"#"}, "tags cannot be empty"},
+		{"too long", []string{strings.Repeat("a", MaxTagLength+1)}, "tags must be at most 32 characters"},
+		{"spaces", []string{"two words"}, `tag "two words" may only contain letters, digits, hyphens, and underscores`},
+		{"too many", strings.Split("a b c d e f g h i j k", " "), "tasks can have at most 10 tags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizeTags(tt.tags)
+			require.Error(t, err)
+			assert.Equal(t, tt.errMsg, err.Error())
+		})
+	}
+}
+
+func TestTags_CreateAndUpdate(t *testing.T) {
+	req := &CreateTaskRequest{Title: "Pay rent", Tags: []string{"Finance", "finance", "#home"}}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, []string{"finance", "home"}, req.Tags)
+
+	task := NewTask("Pay rent", uuid.New())
+	task.Tags = req.Tags
+
+	tags := []string{"#Home"}
+	update := &UpdateTaskRequest{Tags: &tags}
+	require.NoError(t, update.Validate())
+	changes := task.Update(update)
+	assert.Equal(t, []string{"home"}, task.Tags)
+	assert.Equal(t, []FieldChange{{Field: "tags", OldValue: "finance,home", NewValue: "home"}}, changes)
+
+	// Unchanged tags are not recorded, and an empty list removes them
+	assert.Empty(t, task.Update(update))
+	empty := []string{}
+	require.NoError(t, (&UpdateTaskRequest{Tags: &empty}).Validate())
+	task.Update(&UpdateTaskRequest{Tags: &empty})
+	assert.Empty(t, task.Tags)
+}
@@ -2,8 +2,10 @@ package task
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -18,37 +20,183 @@ const (
 	StatusCancelled  TaskStatus = "cancelled"
 )
 
+// TaskPriority represents the priority of a task
+type TaskPriority string
+
+const (
+	PriorityLow    TaskPriority = "low"
+	PriorityMedium TaskPriority = "medium"
+	PriorityHigh   TaskPriority = "high"
+)
+
+// EventType represents the kind of change published for a task
+type EventType string
+
+const (
+	EventTaskCreated EventType = "task.created"
+	EventTaskUpdated EventType = "task.updated"
+	EventTaskDeleted EventType = "task.deleted"
+)
+
 // Task represents a task in the system
 type Task struct {
-	ID        uuid.UUID  `json:"id"`
-	Title     string     `json:"title"`
-	Status    TaskStatus `json:"status"`
-	UserID    uuid.UUID  `json:"user_id"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Status      TaskStatus      `json:"status"`
+	Priority    TaskPriority    `json:"priority"`
+	Position    float64         `json:"position"`
+	DueDate     *time.Time      `json:"due_date,omitempty"`
+	UserID      uuid.UUID       `json:"user_id"`
+	AssigneeID  *uuid.UUID      `json:"assignee_id,omitempty"`
+	SharedWith  []uuid.UUID     `json:"shared_with,omitempty"` // users with read-only access
+	WorkspaceID *uuid.UUID      `json:"workspace_id,omitempty"`
+	ProjectID   *uuid.UUID      `json:"project_id,omitempty"`
+	TenantID    uuid.UUID       `json:"-"` // tenant the task is isolated to
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Version     int             `json:"version"` // increases with every change, for offline edits
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty"`
+	Checklist   []ChecklistItem `json:"checklist,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Progress    *int            `json:"progress,omitempty"` // percentage of checklist items done
+	// RenderedDescription is the description rendered from Markdown as sanitized HTML, set
+	// only on responses asking for it
+	RenderedDescription string `json:"rendered_description,omitempty"`
 }
 
 // CreateTaskRequest represents a request to create a task
 type CreateTaskRequest struct {
-	Title string `json:"title" validate:"required,min=1,max=200"`
+	Title       string       `json:"title" validate:"required,min=1,max=200"`
+	Description string       `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Priority    TaskPriority `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	DueDate     *time.Time   `json:"due_date,omitempty"`
+	// Due is a due date in natural language, such as "next friday", which the API resolves
+	// into DueDate in the user's time zone
+	Due string `json:"due,omitempty"`
+	// ProjectID groups the task under a project; only allowed for workspace tasks
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
 }
 
 // UpdateTaskRequest represents a request to update a task
 type UpdateTaskRequest struct {
-	Title  *string     `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
-	Status *TaskStatus `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress completed cancelled"`
+	Title       *string       `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
+	Description *string       `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Status      *TaskStatus   `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress completed cancelled"`
+	Priority    *TaskPriority `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	DueDate     *time.Time    `json:"due_date,omitempty"`
+	Tags        *[]string     `json:"tags,omitempty"` // replaces the tags; an empty list removes them
+	Due         string        `json:"due,omitempty"`  // natural language, resolved into DueDate
+	// BaseVersion is the version of the task the edit was made on, such as by an offline
+	// client; edits of a stale version that clash with the server copy are rejected
+	BaseVersion *int `json:"base_version,omitempty"`
+}
+
+// ImportTaskRequest represents a single row of a bulk import
+type ImportTaskRequest struct {
+	Title  string     `json:"title"`
+	Status TaskStatus `json:"status,omitempty"`
+}
+
+// QuickAddRequest represents a request to create a task from a line of natural text, such
+// as "Pay rent tomorrow 5pm #finance !high"
+type QuickAddRequest struct {
+	Text string `json:"text" validate:"required,min=1,max=500"`
+}
+
+// ImportRowError describes why a row of a bulk import was rejected
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
 }
 
-// TaskFilter represents filters for task queries
+// ImportResult summarizes the outcome of a bulk import
+type ImportResult struct {
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Tasks    []*Task          `json:"tasks"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ErrUnresolvedDue is returned for requests with a natural-language due date reaching the
+// service, for endpoints that do not resolve it
+var ErrUnresolvedDue = errors.New("due is not supported here, set due_date instead")
+
+// MaxTitleLength is the maximum length of a task title in characters
+const MaxTitleLength = 200
+
+// MaxDescriptionLength is the maximum length of a task description in bytes
+const MaxDescriptionLength = 5000
+
+// MaxQuickAddLength is the maximum length of the text of a quick-add request in characters
+const MaxQuickAddLength = 500
+
+// MaxImportRows is the maximum number of rows accepted in a single import
+const MaxImportRows = 1000
+
+// Event represents a change to a task pushed to subscribers
+type Event struct {
+	Type        EventType   `json:"type"`
+	TaskID      uuid.UUID   `json:"task_id"`
+	UserID      uuid.UUID   `json:"-"`
+	Viewers     []uuid.UUID `json:"-"` // assignee and shared users at the time of the event
+	WorkspaceID *uuid.UUID  `json:"-"` // workspace whose members may receive the event
+	Task        *Task       `json:"task,omitempty"`
+	OccurredAt  time.Time   `json:"occurred_at"`
+	// ActorID and Changes are set when the user who made the change and the changed fields
+	// are known, for subscribers notifying users of specific changes
+	ActorID uuid.UUID     `json:"-"`
+	Changes []FieldChange `json:"-"`
+}
+
+// FieldChange represents a change to a single task field
+type FieldChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// TaskFilter represents filters for task queries. All set conditions must match.
 type TaskFilter struct {
-	Status *TaskStatus `json:"status,omitempty"`
-	Search string      `json:"search,omitempty"`
+	Status        *TaskStatus  `json:"status,omitempty"`
+	Statuses      []TaskStatus `json:"statuses,omitempty"` // matches any of the listed statuses
+	Search        string       `json:"search,omitempty"`
+	CreatedAfter  *time.Time   `json:"created_after,omitempty"`
+	CreatedBefore *time.Time   `json:"created_before,omitempty"`
+	DueFrom       *time.Time   `json:"due_from,omitempty"`   // due at or after, e.g. the start of a day
+	DueBefore     *time.Time   `json:"due_before,omitempty"` // due before, e.g. the start of the next day
+	AssigneeID    *uuid.UUID   `json:"assignee_id,omitempty"`
+	ProjectID     *uuid.UUID   `json:"project_id,omitempty"`
+	Archived      *bool        `json:"archived,omitempty"` // archived tasks are excluded unless set
 }
 
 // TaskSort represents sorting options for task queries
 type TaskSort struct {
-	Field string `json:"field"` // created_at, updated_at, title, status
-	Order string `json:"order"` // asc, desc
+	Field  string    `json:"field"`             // created_at, updated_at, title, status, priority, due_date, position
+	Order  string    `json:"order"`             // asc, desc
+	ThenBy []SortKey `json:"then_by,omitempty"` // tie-breakers applied in order
+}
+
+// SortKey is an additional field and direction to sort by
+type SortKey struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+}
+
+// NewTaskSort creates sorting options, falling back to created_at desc for invalid values
+func NewTaskSort(field, order string) *TaskSort {
+	if !isValidSortField(field) {
+		field = "created_at"
+	}
+
+	if order != "asc" && order != "desc" {
+		order = "desc"
+	}
+
+	return &TaskSort{Field: field, Order: order}
 }
 
 // NewTask creates a new task instance
@@ -57,10 +205,74 @@ func NewTask(title string, userID uuid.UUID) *Task {
 		ID:        uuid.New(),
 		Title:     title,
 		Status:    StatusPending,
+		Priority:  PriorityMedium,
 		UserID:    userID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
+		Version:   1,
+	}
+}
+
+// NewEvent creates a new task event carrying a snapshot of the task
+func NewEvent(eventType EventType, t *Task) *Event {
+	event := &Event{
+		Type:       eventType,
+		TaskID:     t.ID,
+		UserID:     t.UserID,
+		OccurredAt: time.Now(),
+	}
+
+	event.Viewers = t.Viewers()
+	event.WorkspaceID = t.WorkspaceID
+
+	// Deleted tasks are identified by ID only
+	if eventType != EventTaskDeleted {
+		event.Task = t.Snapshot()
+	}
+
+	return event
+}
+
+// Snapshot returns a copy of the task that later changes to the task do not affect
+func (t *Task) Snapshot() *Task {
+	snapshot := *t
+	snapshot.Checklist = append([]ChecklistItem(nil), t.Checklist...)
+	snapshot.SharedWith = append([]uuid.UUID(nil), t.SharedWith...)
+	snapshot.Tags = append([]string(nil), t.Tags...)
+	return &snapshot
+}
+
+// VisibleTo reports whether the user may receive the event
+func (e *Event) VisibleTo(userID uuid.UUID) bool {
+	if e.UserID == userID {
+		return true
+	}
+	for _, id := range e.Viewers {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Change returns the change to the field carried by the event, if any
+func (e *Event) Change(field string) (FieldChange, bool) {
+	for _, change := range e.Changes {
+		if change.Field == field {
+			return change, true
+		}
 	}
+	return FieldChange{}, false
+}
+
+// EventName returns the event type, satisfying the event bus interface
+func (e *Event) EventName() string {
+	return string(e.Type)
+}
+
+// EventKey returns the task ID, so the changes of a task are streamed in order
+func (e *Event) EventKey() string {
+	return e.TaskID.String()
 }
 
 // ValidateCreateRequest validates create task request
@@ -69,10 +281,56 @@ func (req *CreateTaskRequest) Validate() error {
 		return errors.New("title is required")
 	}
 
-	if len(req.Title) > 200 {
+	if utf8.RuneCountInString(req.Title) > MaxTitleLength {
 		return errors.New("title must be at most 200 characters")
 	}
 
+	if len(req.Description) > MaxDescriptionLength {
+		return errors.New("description must be at most 5000 characters")
+	}
+
+	if req.Priority != "" && !isValidPriority(req.Priority) {
+		return errors.New("invalid priority")
+	}
+
+	tags, err := NormalizeTags(req.Tags)
+	if err != nil {
+		return err
+	}
+	req.Tags = tags
+
+	if req.Due != "" {
+		return ErrUnresolvedDue
+	}
+
+	return nil
+}
+
+// Validate validates an import row, allowing an empty status which defaults to pending
+func (req *ImportTaskRequest) Validate() error {
+	createReq := CreateTaskRequest{Title: req.Title}
+	if err := createReq.Validate(); err != nil {
+		return err
+	}
+
+	if req.Status != "" && !isValidStatus(req.Status) {
+		return errors.New("invalid status")
+	}
+
+	return nil
+}
+
+// Validate validates a quick-add request; the task parsed from the text is validated as a
+// create request
+func (req *QuickAddRequest) Validate() error {
+	if strings.TrimSpace(req.Text) == "" {
+		return errors.New("text is required")
+	}
+
+	if utf8.RuneCountInString(req.Text) > MaxQuickAddLength {
+		return errors.New("text must be at most 500 characters")
+	}
+
 	return nil
 }
 
@@ -82,27 +340,91 @@ func (req *UpdateTaskRequest) Validate() error {
 		if strings.TrimSpace(*req.Title) == "" {
 			return errors.New("title cannot be empty")
 		}
-		if len(*req.Title) > 200 {
+		if utf8.RuneCountInString(*req.Title) > MaxTitleLength {
 			return errors.New("title must be at most 200 characters")
 		}
 	}
 
+	if req.Description != nil && len(*req.Description) > MaxDescriptionLength {
+		return errors.New("description must be at most 5000 characters")
+	}
+
 	if req.Status != nil && !isValidStatus(*req.Status) {
 		return errors.New("invalid status")
 	}
 
+	if req.Priority != nil && !isValidPriority(*req.Priority) {
+		return errors.New("invalid priority")
+	}
+
+	if req.Tags != nil {
+		tags, err := NormalizeTags(*req.Tags)
+		if err != nil {
+			return err
+		}
+		req.Tags = &tags
+	}
+
+	if req.Due != "" {
+		return ErrUnresolvedDue
+	}
+
+	if req.BaseVersion != nil && *req.BaseVersion < 1 {
+		return errors.New("base_version must be positive")
+	}
+
 	return nil
 }
 
-// Update updates the task with the provided request
-func (t *Task) Update(req *UpdateTaskRequest) {
-	if req.Title != nil {
+// Update updates the task with the provided request and returns the fields that changed
+func (t *Task) Update(req *UpdateTaskRequest) []FieldChange {
+	var changes []FieldChange
+
+	if req.Title != nil && *req.Title != t.Title {
+		changes = append(changes, FieldChange{Field: "title", OldValue: t.Title, NewValue: *req.Title})
 		t.Title = *req.Title
 	}
-	if req.Status != nil {
-		t.Status = *req.Status
+	if req.Description != nil && *req.Description != t.Description {
+		changes = append(changes, FieldChange{Field: "description", OldValue: t.Description, NewValue: *req.Description})
+		t.Description = *req.Description
+	}
+	if req.Status != nil && *req.Status != t.Status {
+		changes = append(changes, FieldChange{Field: "status", OldValue: string(t.Status), NewValue: string(*req.Status)})
+		t.SetStatus(*req.Status)
+	}
+	if req.Priority != nil && *req.Priority != t.Priority {
+		changes = append(changes, FieldChange{Field: "priority", OldValue: string(t.Priority), NewValue: string(*req.Priority)})
+		t.Priority = *req.Priority
+	}
+	if req.DueDate != nil && (t.DueDate == nil || !req.DueDate.Equal(*t.DueDate)) {
+		changes = append(changes, FieldChange{Field: "due_date", OldValue: formatDueDate(t.DueDate), NewValue: formatDueDate(req.DueDate)})
+		dueDate := *req.DueDate
+		t.DueDate = &dueDate
 	}
+	if req.Tags != nil && !slices.Equal(*req.Tags, t.Tags) {
+		changes = append(changes, FieldChange{Field: "tags", OldValue: strings.Join(t.Tags, ","), NewValue: strings.Join(*req.Tags, ",")})
+		t.Tags = append([]string(nil), *req.Tags...)
+	}
+	t.Touch()
+
+	return changes
+}
+
+// Touch records that the task changed, moving its update time and version forward
+func (t *Task) Touch() {
 	t.UpdatedAt = time.Now()
+	t.Version++
+}
+
+// SetStatus changes the task status, tracking when the task was completed
+func (t *Task) SetStatus(status TaskStatus) {
+	if status == StatusCompleted && t.Status != StatusCompleted {
+		now := time.Now()
+		t.CompletedAt = &now
+	} else if status != StatusCompleted {
+		t.CompletedAt = nil
+	}
+	t.Status = status
 }
 
 // Helper functions
@@ -114,3 +436,19 @@ func isValidStatus(status TaskStatus) bool {
 		return false
 	}
 }
+
+func isValidPriority(priority TaskPriority) bool {
+	switch priority {
+	case PriorityLow, PriorityMedium, PriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+func formatDueDate(dueDate *time.Time) string {
+	if dueDate == nil {
+		return ""
+	}
+	return dueDate.UTC().Format(time.RFC3339)
+}
@@ -1,8 +1,12 @@
 package task
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -69,6 +73,15 @@ func TestCreateTaskRequest_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "description too long",
+			request: CreateTaskRequest{
+				Title:       "Valid Task Title",
+				Description: string(make([]byte, 5001)),
+			},
+			wantErr: true,
+			errMsg:  "description must be at most 5000 characters",
+		},
 	}
 
 	for _, tt := range tests {
@@ -165,6 +178,32 @@ func TestUpdateTaskRequest_Validate(t *testing.T) {
 	}
 }
 
+func TestImportTaskRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ImportTaskRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{"title only", ImportTaskRequest{Title: "Imported"}, false, ""},
+		{"title and status", ImportTaskRequest{Title: "Imported", Status: StatusCompleted}, false, ""},
+		{"empty title", ImportTaskRequest{Title: " ", Status: StatusPending}, true, "title is required"},
+		{"invalid status", ImportTaskRequest{Title: "Imported", Status: "done"}, true, "invalid status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestTask_Update(t *testing.T) {
 	originalTime := time.Now().Add(-1 * time.Hour)
 	task := &Task{
@@ -213,6 +252,95 @@ func TestTask_Update(t *testing.T) {
 	assert.True(t, task.UpdatedAt.After(originalUpdatedAt))
 }
 
+func TestQuickAddRequest_Validate(t *testing.T) {
+	require.NoError(t, (&QuickAddRequest{Text: "Pay rent tomorrow #finance"}).Validate())
+
+	err := (&QuickAddRequest{Text: "  "}).Validate()
+	require.Error(t, err)
+	assert.Equal(t, "text is required", err.Error())
+
+	err = (&QuickAddRequest{Text: strings.Repeat("a", MaxQuickAddLength+1)}).Validate()
+	require.Error(t, err)
+	assert.Equal(t, "text must be at most 500 characters", err.Error())
+}
+
+func TestValidate_UnresolvedDue(t *testing.T) {
+	// Handlers resolve natural-language due dates before the service validates requests
+	assert.ErrorIs(t, (&CreateTaskRequest{Title: "Task", Due: "next friday"}).Validate(), ErrUnresolvedDue)
+	assert.ErrorIs(t, (&UpdateTaskRequest{Due: "next friday"}).Validate(), ErrUnresolvedDue)
+}
+
+func TestTask_Update_PriorityAndDueDate(t *testing.T) {
+	task := NewTask("Task", uuid.New())
+	assert.Equal(t, PriorityMedium, task.Priority)
+	assert.Nil(t, task.DueDate)
+
+	priority := PriorityHigh
+	dueDate := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	changes := task.Update(&UpdateTaskRequest{Priority: &priority, DueDate: &dueDate})
+
+	assert.Equal(t, PriorityHigh, task.Priority)
+	assert.True(t, dueDate.Equal(*task.DueDate))
+	assert.Equal(t, []FieldChange{
+		{Field: "priority", OldValue: "medium", NewValue: "high"},
+		{Field: "due_date", OldValue: "", NewValue: "2024-02-01T09:00:00Z"},
+	}, changes)
+
+	// Unchanged values are not recorded
+	assert.Empty(t, task.Update(&UpdateTaskRequest{Priority: &priority, DueDate: &dueDate}))
+
+	invalid := TaskPriority("urgent")
+	err := (&UpdateTaskRequest{Priority: &invalid}).Validate()
+	require.Error(t, err)
+	assert.Equal(t, "invalid priority", err.Error())
+
+	err = (&CreateTaskRequest{Title: "Task", Priority: invalid}).Validate()
+	require.Error(t, err)
+	assert.Equal(t, "invalid priority", err.Error())
+}
+
+func TestTask_Update_ReturnsChanges(t *testing.T) {
+	task := NewTask("Original Title", uuid.New())
+
+	changes := task.Update(&UpdateTaskRequest{
+		Title:  stringPtr("Original Title"), // Unchanged
+		Status: statusPtr(StatusInProgress),
+	})
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, FieldChange{Field: "status", OldValue: "pending", NewValue: "in_progress"}, changes[0])
+
+	changes = task.Update(&UpdateTaskRequest{Title: stringPtr("New Title")})
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, FieldChange{Field: "title", OldValue: "Original Title", NewValue: "New Title"}, changes[0])
+
+	changes = task.Update(&UpdateTaskRequest{Description: stringPtr("Some details")})
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, FieldChange{Field: "description", OldValue: "", NewValue: "Some details"}, changes[0])
+	assert.Equal(t, "Some details", task.Description)
+}
+
+func TestNewEvent(t *testing.T) {
+	task := NewTask("Event Task", uuid.New())
+
+	event := NewEvent(EventTaskUpdated, task)
+
+	assert.Equal(t, EventTaskUpdated, event.Type)
+	assert.Equal(t, task.ID, event.TaskID)
+	assert.Equal(t, task.UserID, event.UserID)
+	require.NotNil(t, event.Task)
+	assert.False(t, event.OccurredAt.IsZero())
+
+	// The event carries a snapshot, not a live reference
+	task.Title = "Changed"
+	assert.Equal(t, "Event Task", event.Task.Title)
+
+	deleted := NewEvent(EventTaskDeleted, task)
+	assert.Nil(t, deleted.Task)
+}
+
 func TestIsValidStatus(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -268,6 +396,30 @@ func TestTaskSort(t *testing.T) {
 	assert.Equal(t, "desc", sort.Order)
 }
 
+func TestNewTaskSort(t *testing.T) {
+	tests := []struct {
+		name          string
+		field         string
+		order         string
+		expectedField string
+		expectedOrder string
+	}{
+		{"valid options", "title", "asc", "title", "asc"},
+		{"invalid field", "unknown", "asc", "created_at", "asc"},
+		{"priority field", "priority", "desc", "priority", "desc"},
+		{"invalid order", "status", "up", "status", "desc"},
+		{"empty options", "", "", "created_at", "desc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort := NewTaskSort(tt.field, tt.order)
+			assert.Equal(t, tt.expectedField, sort.Field)
+			assert.Equal(t, tt.expectedOrder, sort.Order)
+		})
+	}
+}
+
 // Helper functions for tests
 func stringPtr(s string) *string {
 	return &s
@@ -276,3 +428,80 @@ func stringPtr(s string) *string {
 func statusPtr(s TaskStatus) *TaskStatus {
 	return &s
 }
+
+func FuzzCreateTaskRequest_Validate(f *testing.F) {
+	f.Add("Buy groceries", "Milk and eggs", "high")
+	f.Add("   ", "", "")
+	f.Add("日本語のタスク", "説明", "low")
+	f.Add(strings.Repeat("é", 200), strings.Repeat("🙂", 1250), "medium")
+	f.Add("​\t\n", "\xff\xfe", "HIGH")
+
+	f.Fuzz(func(t *testing.T, title, description, priority string) {
+		req := &CreateTaskRequest{Title: title, Description: description, Priority: TaskPriority(priority)}
+		err := req.Validate()
+
+		valid := strings.TrimSpace(title) != "" &&
+			utf8.RuneCountInString(title) <= MaxTitleLength &&
+			len(description) <= MaxDescriptionLength &&
+			(priority == "" || isValidPriority(TaskPriority(priority)))
+		assert.Equal(t, valid, err == nil, "title %q, priority %q: %v", title, priority, err)
+	})
+}
+
+func FuzzUpdateTaskRequest_Validate(f *testing.F) {
+	f.Add(true, "Updated title", false, "", true, "in_progress", false, "")
+	f.Add(true, "", true, "desc", false, "", true, "urgent")
+	f.Add(true, strings.Repeat("ß", 201), false, "", true, "completed", true, "low")
+	f.Add(false, "", false, "", true, "in progress", false, "")
+
+	f.Fuzz(func(t *testing.T, hasTitle bool, title string, hasDescription bool, description string,
+		hasStatus bool, status string, hasPriority bool, priority string) {
+		req := &UpdateTaskRequest{}
+		valid := true
+		if hasTitle {
+			req.Title = &title
+			valid = valid && strings.TrimSpace(title) != "" && utf8.RuneCountInString(title) <= MaxTitleLength
+		}
+		if hasDescription {
+			req.Description = &description
+			valid = valid && len(description) <= MaxDescriptionLength
+		}
+		if hasStatus {
+			req.Status = statusPtr(TaskStatus(status))
+			valid = valid && isValidStatus(TaskStatus(status))
+		}
+		if hasPriority {
+			p := TaskPriority(priority)
+			req.Priority = &p
+			valid = valid && isValidPriority(p)
+		}
+
+		err := req.Validate()
+		assert.Equal(t, valid, err == nil, "%+v: %v", req, err)
+	})
+}
+
+func BenchmarkTask_MarshalJSON(b *testing.B) {
+	// Pages of the default and the largest listing limit, with the optional fields set
+	for _, n := range []int{20, 100} {
+		tasks := make([]*Task, n)
+		for i := range tasks {
+			t := NewTask(fmt.Sprintf("Task %d", i), uuid.New())
+			t.Description = "Check the quarterly numbers before the review meeting"
+			due := t.CreatedAt.Add(72 * time.Hour)
+			t.DueDate = &due
+			t.AddChecklistItem("Gather numbers")
+			t.AddChecklistItem("Book a room")
+			tasks[i] = t
+		}
+
+		b.Run(fmt.Sprintf("tasks=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := json.Marshal(tasks); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
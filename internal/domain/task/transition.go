@@ -0,0 +1,65 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition is returned when a task cannot move from its current status to the requested one
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// transitions lists the statuses each status may change to. Closed tasks must be
+// reopened before they can be worked on or closed differently.
+var transitions = map[TaskStatus][]TaskStatus{
+	StatusPending:    {StatusInProgress, StatusCompleted, StatusCancelled},
+	StatusInProgress: {StatusPending, StatusCompleted, StatusCancelled},
+	StatusCompleted:  {StatusPending},
+	StatusCancelled:  {StatusPending},
+}
+
+// CanTransition reports whether a task may change from one status to another
+func CanTransition(from, to TaskStatus) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClosed reports whether the status ends the task's workflow
+func (s TaskStatus) IsClosed() bool {
+	return s == StatusCompleted || s == StatusCancelled
+}
+
+// CheckTransition returns an error if the task cannot change to the status.
+// Keeping the current status is always allowed.
+func (t *Task) CheckTransition(to TaskStatus) error {
+	if to == t.Status || CanTransition(t.Status, to) {
+		return nil
+	}
+	return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, t.Status, to)
+}
+
+// Complete marks the task as completed
+func (t *Task) Complete() ([]FieldChange, error) {
+	if t.Status == StatusCompleted {
+		return nil, fmt.Errorf("%w: task is already completed", ErrInvalidTransition)
+	}
+	return t.transition(StatusCompleted)
+}
+
+// Reopen moves a completed or cancelled task back to pending
+func (t *Task) Reopen() ([]FieldChange, error) {
+	if !t.Status.IsClosed() {
+		return nil, fmt.Errorf("%w: only completed or cancelled tasks can be reopened", ErrInvalidTransition)
+	}
+	return t.transition(StatusPending)
+}
+
+func (t *Task) transition(to TaskStatus) ([]FieldChange, error) {
+	if err := t.CheckTransition(to); err != nil {
+		return nil, err
+	}
+	return t.Update(&UpdateTaskRequest{Status: &to}), nil
+}
@@ -0,0 +1,82 @@
+package task
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from     TaskStatus
+		to       TaskStatus
+		expected bool
+	}{
+		{StatusPending, StatusInProgress, true},
+		{StatusPending, StatusCompleted, true},
+		{StatusInProgress, StatusCancelled, true},
+		{StatusCompleted, StatusPending, true},
+		{StatusCancelled, StatusPending, true},
+		{StatusCancelled, StatusCompleted, false},
+		{StatusCancelled, StatusInProgress, false},
+		{StatusCompleted, StatusCancelled, false},
+		{StatusCompleted, StatusInProgress, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestTask_CheckTransition(t *testing.T) {
+	task := NewTask("Task", uuid.New())
+	task.SetStatus(StatusCancelled)
+
+	assert.NoError(t, task.CheckTransition(StatusCancelled))
+	assert.NoError(t, task.CheckTransition(StatusPending))
+
+	err := task.CheckTransition(StatusCompleted)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Equal(t, "invalid status transition from cancelled to completed", err.Error())
+}
+
+func TestTask_Complete(t *testing.T) {
+	task := NewTask("Task", uuid.New())
+
+	changes, err := task.Complete()
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, task.Status)
+	assert.NotNil(t, task.CompletedAt)
+	assert.Equal(t, []FieldChange{{Field: "status", OldValue: "pending", NewValue: "completed"}}, changes)
+
+	_, err = task.Complete()
+	require.Error(t, err)
+	assert.Equal(t, "invalid status transition: task is already completed", err.Error())
+
+	cancelled := NewTask("Task", uuid.New())
+	cancelled.SetStatus(StatusCancelled)
+	_, err = cancelled.Complete()
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Equal(t, StatusCancelled, cancelled.Status)
+}
+
+func TestTask_Reopen(t *testing.T) {
+	task := NewTask("Task", uuid.New())
+
+	_, err := task.Reopen()
+	require.Error(t, err)
+	assert.Equal(t, "invalid status transition: only completed or cancelled tasks can be reopened", err.Error())
+
+	task.SetStatus(StatusCompleted)
+	changes, err := task.Reopen()
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, task.Status)
+	assert.Nil(t, task.CompletedAt)
+	assert.Len(t, changes, 1)
+}
@@ -0,0 +1,165 @@
+package tenant
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status represents whether a tenant may use the API
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+)
+
+// DefaultTenantID identifies the tenant that owns users and data created before
+// tenants existed, and tokens that do not carry a tenant claim
+var DefaultTenantID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// DefaultSlug is the slug of the default tenant
+const DefaultSlug = "default"
+
+// ErrQuotaExceeded is returned when an operation would exceed a tenant quota
+var ErrQuotaExceeded = errors.New("tenant quota exceeded")
+
+// slugPattern matches a single DNS label so that slugs can be used as subdomains
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Quota limits the resources a tenant may create. Zero means unlimited.
+type Quota struct {
+	MaxTasks      int `json:"max_tasks"`
+	MaxWorkspaces int `json:"max_workspaces"`
+}
+
+// Tenant represents an isolated customer of a hosted deployment
+type Tenant struct {
+	ID        uuid.UUID `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Quota     Quota     `json:"quota"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateTenantRequest represents a request to create a tenant
+type CreateTenantRequest struct {
+	Slug  string `json:"slug" validate:"required"`
+	Name  string `json:"name" validate:"required,min=1,max=100"`
+	Quota Quota  `json:"quota"`
+}
+
+// UpdateTenantRequest represents a request to update a tenant
+type UpdateTenantRequest struct {
+	Name   *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Status *Status `json:"status,omitempty" validate:"omitempty,oneof=active suspended"`
+	Quota  *Quota  `json:"quota,omitempty"`
+}
+
+// NewTenant creates a new active tenant instance
+func NewTenant(slug, name string, quota Quota) *Tenant {
+	return &Tenant{
+		ID:        uuid.New(),
+		Slug:      slug,
+		Name:      name,
+		Status:    StatusActive,
+		Quota:     quota,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// IsActive reports whether the tenant may use the API
+func (t *Tenant) IsActive() bool {
+	return t.Status == StatusActive
+}
+
+// AllowsTasks reports whether a tenant holding count tasks may create another
+func (q Quota) AllowsTasks(count int) bool {
+	return q.MaxTasks == 0 || count < q.MaxTasks
+}
+
+// AllowsWorkspaces reports whether a tenant holding count workspaces may create another
+func (q Quota) AllowsWorkspaces(count int) bool {
+	return q.MaxWorkspaces == 0 || count < q.MaxWorkspaces
+}
+
+// Validate validates the quota
+func (q Quota) Validate() error {
+	if q.MaxTasks < 0 || q.MaxWorkspaces < 0 {
+		return errors.New("quota limits cannot be negative")
+	}
+	return nil
+}
+
+// Validate validates create tenant request
+func (req *CreateTenantRequest) Validate() error {
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if req.Slug == "" {
+		return errors.New("slug is required")
+	}
+
+	if !slugPattern.MatchString(req.Slug) {
+		return errors.New("slug must contain only lowercase letters, digits and hyphens")
+	}
+
+	if err := validateName(req.Name); err != nil {
+		return err
+	}
+
+	return req.Quota.Validate()
+}
+
+// Validate validates update tenant request
+func (req *UpdateTenantRequest) Validate() error {
+	if req.Name != nil {
+		if err := validateName(*req.Name); err != nil {
+			return err
+		}
+	}
+
+	if req.Status != nil && *req.Status != StatusActive && *req.Status != StatusSuspended {
+		return errors.New("invalid status")
+	}
+
+	if req.Quota != nil {
+		return req.Quota.Validate()
+	}
+
+	return nil
+}
+
+// SlugFromHost extracts the tenant slug from a subdomain of baseDomain, e.g. "acme"
+// from "acme.todo.example.com". It returns an empty string when host is not a
+// direct subdomain of baseDomain.
+func SlugFromHost(host, baseDomain string) string {
+	host = strings.ToLower(host)
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	if baseDomain == "" || !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+
+	slug := strings.TrimSuffix(host, suffix)
+	if !slugPattern.MatchString(slug) {
+		return ""
+	}
+	return slug
+}
+
+// Helper functions
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+
+	if len(name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+
+	return nil
+}
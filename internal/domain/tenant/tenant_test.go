@@ -0,0 +1,83 @@
+package tenant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota_Allows(t *testing.T) {
+	unlimited := Quota{}
+	assert.True(t, unlimited.AllowsTasks(1000))
+	assert.True(t, unlimited.AllowsWorkspaces(1000))
+
+	limited := Quota{MaxTasks: 2, MaxWorkspaces: 1}
+	assert.True(t, limited.AllowsTasks(1))
+	assert.False(t, limited.AllowsTasks(2))
+	assert.True(t, limited.AllowsWorkspaces(0))
+	assert.False(t, limited.AllowsWorkspaces(1))
+}
+
+func TestCreateTenantRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request CreateTenantRequest
+		errMsg  string
+	}{
+		{"valid", CreateTenantRequest{Slug: "acme", Name: "Acme"}, ""},
+		{"normalizes slug", CreateTenantRequest{Slug: " Acme-Corp ", Name: "Acme"}, ""},
+		{"missing slug", CreateTenantRequest{Name: "Acme"}, "slug is required"},
+		{"invalid slug", CreateTenantRequest{Slug: "acme.corp", Name: "Acme"}, "slug must contain only lowercase letters, digits and hyphens"},
+		{"leading hyphen", CreateTenantRequest{Slug: "-acme", Name: "Acme"}, "slug must contain only lowercase letters, digits and hyphens"},
+		{"missing name", CreateTenantRequest{Slug: "acme"}, "name is required"},
+		{"long name", CreateTenantRequest{Slug: "acme", Name: strings.Repeat("a", 101)}, "name must be at most 100 characters"},
+		{"negative quota", CreateTenantRequest{Slug: "acme", Name: "Acme", Quota: Quota{MaxTasks: -1}}, "quota limits cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+
+	req := CreateTenantRequest{Slug: " Acme-Corp ", Name: "Acme"}
+	assert.NoError(t, req.Validate())
+	assert.Equal(t, "acme-corp", req.Slug)
+}
+
+func TestUpdateTenantRequest_Validate(t *testing.T) {
+	suspended, unknown := StatusSuspended, Status("deleted")
+	empty := " "
+
+	assert.NoError(t, (&UpdateTenantRequest{Status: &suspended}).Validate())
+	assert.EqualError(t, (&UpdateTenantRequest{Status: &unknown}).Validate(), "invalid status")
+	assert.EqualError(t, (&UpdateTenantRequest{Name: &empty}).Validate(), "name is required")
+	assert.EqualError(t, (&UpdateTenantRequest{Quota: &Quota{MaxWorkspaces: -1}}).Validate(), "quota limits cannot be negative")
+}
+
+func TestSlugFromHost(t *testing.T) {
+	tests := []struct {
+		host       string
+		baseDomain string
+		expected   string
+	}{
+		{"acme.todo.example.com", "todo.example.com", "acme"},
+		{"ACME.todo.example.com", "todo.example.com", "acme"},
+		{"todo.example.com", "todo.example.com", ""},
+		{"a.b.todo.example.com", "todo.example.com", ""},
+		{"acme.other.com", "todo.example.com", ""},
+		{"acme.todo.example.com", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SlugFromHost(tt.host, tt.baseDomain))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package workspace
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role represents a member's role within a workspace
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+)
+
+// roleRank orders roles from least to most privileged
+var roleRank = map[Role]int{
+	RoleMember: 1,
+	RoleAdmin:  2,
+	RoleOwner:  3,
+}
+
+// InvitationStatus represents the state of a workspace invitation
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// InvitationTTL is how long an invitation can be accepted after it was sent
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Workspace represents a team sharing tasks and projects
+type Workspace struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   uuid.UUID `json:"owner_id"`
+	TenantID  uuid.UUID `json:"-"` // tenant the workspace is isolated to
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Member represents a user's membership in a workspace
+type Member struct {
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Role        Role      `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// Project groups tasks within a workspace
+type Project struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Name        string    `json:"name"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Invitation represents an invitation for a user to join a workspace
+type Invitation struct {
+	ID          uuid.UUID        `json:"id"`
+	WorkspaceID uuid.UUID        `json:"workspace_id"`
+	Email       string           `json:"email"`
+	Role        Role             `json:"role"`
+	Status      InvitationStatus `json:"status"`
+	InvitedBy   uuid.UUID        `json:"invited_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+}
+
+// CreateWorkspaceRequest represents a request to create a workspace
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// CreateProjectRequest represents a request to create a project
+type CreateProjectRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// InviteMemberRequest represents a request to invite a user to a workspace
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  Role   `json:"role,omitempty" validate:"omitempty,oneof=admin member"`
+}
+
+// NewWorkspace creates a new workspace instance
+func NewWorkspace(name string, ownerID uuid.UUID) *Workspace {
+	return &Workspace{
+		ID:        uuid.New(),
+		Name:      name,
+		OwnerID:   ownerID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// NewMember creates a new workspace membership
+func NewMember(workspaceID, userID uuid.UUID, role Role) *Member {
+	return &Member{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}
+}
+
+// NewProject creates a new project instance
+func NewProject(workspaceID uuid.UUID, name string, createdBy uuid.UUID) *Project {
+	return &Project{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Name:        name,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// NewInvitation creates a pending invitation, defaulting the role to member
+func NewInvitation(workspaceID uuid.UUID, email string, role Role, invitedBy uuid.UUID) *Invitation {
+	if role == "" {
+		role = RoleMember
+	}
+
+	now := time.Now()
+	return &Invitation{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Email:       strings.ToLower(email),
+		Role:        role,
+		Status:      InvitationPending,
+		InvitedBy:   invitedBy,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(InvitationTTL),
+	}
+}
+
+// AtLeast reports whether the role has at least the privileges of the given role
+func (r Role) AtLeast(min Role) bool {
+	return roleRank[r] >= roleRank[min]
+}
+
+// IsExpired reports whether the invitation can no longer be accepted
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+// Validate validates create workspace request
+func (req *CreateWorkspaceRequest) Validate() error {
+	return validateName(req.Name)
+}
+
+// Validate validates create project request
+func (req *CreateProjectRequest) Validate() error {
+	return validateName(req.Name)
+}
+
+// Validate validates invite member request
+func (req *InviteMemberRequest) Validate() error {
+	if !strings.Contains(req.Email, "@") {
+		return errors.New("invalid email")
+	}
+
+	// Ownership cannot be granted through an invitation
+	if req.Role != "" && req.Role != RoleAdmin && req.Role != RoleMember {
+		return errors.New("invalid role")
+	}
+
+	return nil
+}
+
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+
+	if len(name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+
+	return nil
+}
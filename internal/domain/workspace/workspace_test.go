@@ -0,0 +1,64 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole_AtLeast(t *testing.T) {
+	assert.True(t, RoleOwner.AtLeast(RoleAdmin))
+	assert.True(t, RoleAdmin.AtLeast(RoleAdmin))
+	assert.True(t, RoleAdmin.AtLeast(RoleMember))
+	assert.False(t, RoleMember.AtLeast(RoleAdmin))
+	assert.False(t, Role("guest").AtLeast(RoleMember))
+}
+
+func TestNewInvitation(t *testing.T) {
+	workspaceID, inviterID := uuid.New(), uuid.New()
+
+	invitation := NewInvitation(workspaceID, "Jane.Smith@Example.com", "", inviterID)
+
+	assert.Equal(t, "jane.smith@example.com", invitation.Email)
+	assert.Equal(t, RoleMember, invitation.Role)
+	assert.Equal(t, InvitationPending, invitation.Status)
+	assert.WithinDuration(t, time.Now().Add(InvitationTTL), invitation.ExpiresAt, time.Second)
+	assert.False(t, invitation.IsExpired())
+
+	invitation.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.True(t, invitation.IsExpired())
+}
+
+func TestCreateWorkspaceRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&CreateWorkspaceRequest{Name: "Platform team"}).Validate())
+	assert.EqualError(t, (&CreateWorkspaceRequest{Name: "  "}).Validate(), "name is required")
+	assert.EqualError(t, (&CreateWorkspaceRequest{Name: strings.Repeat("a", 101)}).Validate(), "name must be at most 100 characters")
+	assert.EqualError(t, (&CreateProjectRequest{}).Validate(), "name is required")
+}
+
+func TestInviteMemberRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request InviteMemberRequest
+		errMsg  string
+	}{
+		{"default role", InviteMemberRequest{Email: "jane@example.com"}, ""},
+		{"admin", InviteMemberRequest{Email: "jane@example.com", Role: RoleAdmin}, ""},
+		{"invalid email", InviteMemberRequest{Email: "jane"}, "invalid email"},
+		{"owner role", InviteMemberRequest{Email: "jane@example.com", Role: RoleOwner}, "invalid role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
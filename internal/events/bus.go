@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Event represents a domain event published on the bus
+type Event interface {
+	EventName() string
+}
+
+// Handler consumes events delivered by the bus
+type Handler func(event Event)
+
+// Bus defines the event bus interface.
+// Backends must deliver events to each subscriber in publish order.
+type Bus interface {
+	Publish(event Event)
+	Subscribe(handler Handler) (unsubscribe func())
+	Close()
+	// Shutdown stops the bus like Close, delivering pending events until the context is
+	// done, and returns the number of events left undelivered
+	Shutdown(ctx context.Context) (dropped int)
+}
+
+// DefaultBufferSize is the number of events buffered per subscriber
+const DefaultBufferSize = 256
+
+// channelBus implements an in-process bus backed by one channel per subscriber
+type channelBus struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	bufferSize  int
+	closed      bool
+}
+
+// subscriber delivers events to a handler from its own goroutine
+type subscriber struct {
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewChannelBus creates a new in-process event bus.
+// A slow subscriber never blocks publishers; events are dropped once its buffer is full.
+func NewChannelBus(bufferSize int) Bus {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &channelBus{
+		subscribers: make(map[*subscriber]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Publish delivers an event to every subscriber without blocking
+func (b *channelBus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("Event bus subscriber buffer full, dropping %s event", event.EventName())
+		}
+	}
+}
+
+// Subscribe registers a handler for all published events.
+// The returned function stops delivery and waits for the handler to finish.
+func (b *channelBus) Subscribe(handler Handler) func() {
+	sub := &subscriber{
+		events: make(chan Event, b.bufferSize),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(sub.done)
+		for event := range sub.events {
+			handler(event)
+		}
+	}()
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(sub.events)
+		return func() { <-sub.done }
+	}
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return func() {
+		b.remove(sub)
+		<-sub.done
+	}
+}
+
+// Close stops the bus and drains pending events to subscribers
+func (b *channelBus) Close() {
+	b.Shutdown(context.Background())
+}
+
+// Shutdown stops the bus and drains pending events to subscribers until the context is
+// done. Events still pending then are counted as dropped; subscribers keep delivering them
+// in the background, but they are lost if the process exits.
+func (b *channelBus) Shutdown(ctx context.Context) int {
+	b.mu.Lock()
+	b.closed = true
+	subs := make([]*subscriber, 0, len(b.subscribers))
+	for sub := range b.subscribers {
+		subs = append(subs, sub)
+		delete(b.subscribers, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.once.Do(func() { close(sub.events) })
+	}
+
+	dropped := 0
+	for _, sub := range subs {
+		select {
+		case <-sub.done:
+		case <-ctx.Done():
+			dropped += len(sub.events)
+		}
+	}
+	return dropped
+}
+
+// remove unregisters a subscriber and closes its channel
+func (b *channelBus) remove(sub *subscriber) {
+	b.mu.Lock()
+	delete(b.subscribers, sub)
+	b.mu.Unlock()
+
+	sub.once.Do(func() { close(sub.events) })
+}
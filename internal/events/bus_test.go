@@ -0,0 +1,163 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	name string
+	seq  int
+}
+
+func (e testEvent) EventName() string {
+	return e.name
+}
+
+func TestChannelBus_PublishSubscribe(t *testing.T) {
+	bus := NewChannelBus(DefaultBufferSize)
+	defer bus.Close()
+
+	received := make(chan Event, 10)
+	unsubscribe := bus.Subscribe(func(event Event) {
+		received <- event
+	})
+	defer unsubscribe()
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(testEvent{name: "test.event", seq: i})
+	}
+
+	// Events are delivered in publish order
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-received:
+			assert.Equal(t, "test.event", event.EventName())
+			assert.Equal(t, i, event.(testEvent).seq)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+}
+
+func TestChannelBus_MultipleSubscribers(t *testing.T) {
+	bus := NewChannelBus(DefaultBufferSize)
+	defer bus.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	for i := 0; i < 2; i++ {
+		bus.Subscribe(func(event Event) {
+			wg.Done()
+		})
+	}
+
+	bus.Publish(testEvent{name: "test.event"})
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("not every subscriber received the event")
+	}
+}
+
+func TestChannelBus_Unsubscribe(t *testing.T) {
+	bus := NewChannelBus(DefaultBufferSize)
+	defer bus.Close()
+
+	count := 0
+	unsubscribe := bus.Subscribe(func(event Event) {
+		count++
+	})
+
+	bus.Publish(testEvent{name: "test.event"})
+	unsubscribe()
+	bus.Publish(testEvent{name: "test.event"})
+
+	// Unsubscribe waits for in-flight deliveries, so count is stable here
+	assert.Equal(t, 1, count)
+}
+
+func TestChannelBus_CloseDrainsPendingEvents(t *testing.T) {
+	bus := NewChannelBus(DefaultBufferSize)
+
+	var mu sync.Mutex
+	var received []int
+	bus.Subscribe(func(event Event) {
+		mu.Lock()
+		received = append(received, event.(testEvent).seq)
+		mu.Unlock()
+	})
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(testEvent{name: "test.event", seq: i})
+	}
+	bus.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 5)
+
+	// Publishing after close is a no-op
+	bus.Publish(testEvent{name: "test.event"})
+	assert.Len(t, received, 5)
+}
+
+func TestChannelBus_DropsWhenBufferFull(t *testing.T) {
+	bus := NewChannelBus(1)
+	defer bus.Close()
+
+	block := make(chan struct{})
+	var mu sync.Mutex
+	count := 0
+	unsubscribe := bus.Subscribe(func(event Event) {
+		<-block
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+
+	// Publishing never blocks even though the handler is stuck
+	for i := 0; i < 10; i++ {
+		bus.Publish(testEvent{name: "test.event", seq: i})
+	}
+
+	close(block)
+	unsubscribe()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Less(t, count, 10)
+}
+
+func TestChannelBus_ShutdownReportsDroppedEvents(t *testing.T) {
+	bus := NewChannelBus(DefaultBufferSize)
+
+	release := make(chan struct{})
+	bus.Subscribe(func(event Event) {
+		<-release
+	})
+	defer close(release)
+
+	// The first event blocks the subscriber, so the others stay pending
+	for i := 0; i < 3; i++ {
+		bus.Publish(testEvent{name: "test.event", seq: i})
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 2, bus.Shutdown(ctx))
+}
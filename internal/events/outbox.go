@@ -0,0 +1,204 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"todo-api/pkg/stream"
+
+	"github.com/google/uuid"
+)
+
+// Keyed is implemented by events streamed in order with the other events of their key,
+// e.g. the changes of a task
+type Keyed interface {
+	EventKey() string
+}
+
+// relayRetryDelay is the delay before the first retry of a failed delivery, doubling after
+// each failure up to the relay's maximum
+const relayRetryDelay = time.Second
+
+// envelope is the document streamed for an event
+type envelope struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Key        string    `json:"key,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Data       Event     `json:"data"`
+}
+
+// Outbox keeps published events until they are delivered to the event stream. Events are
+// recorded by publishers, in the order they are published, so slow subscribers of the bus
+// cannot lose them; a relay then delivers them in that order.
+type Outbox struct {
+	mu      sync.Mutex
+	pending []outboxEntry // oldest first
+	seq     uint64        // sequence of the last recorded event
+	limit   int
+	ready   chan struct{} // signaled when events are recorded
+}
+
+// outboxEntry is a recorded event
+type outboxEntry struct {
+	seq uint64
+	msg stream.Message
+}
+
+// NewOutbox creates an outbox keeping up to limit undelivered events. Once full, the
+// oldest events are dropped to record new ones.
+func NewOutbox(limit int) *Outbox {
+	return &Outbox{limit: limit, ready: make(chan struct{}, 1)}
+}
+
+// WithOutbox returns a bus recording each published event in the outbox before delivering
+// it to the subscribers of the bus
+func WithOutbox(bus Bus, outbox *Outbox) Bus {
+	return &outboxBus{Bus: bus, outbox: outbox}
+}
+
+// outboxBus records published events in an outbox
+type outboxBus struct {
+	Bus
+	outbox *Outbox
+}
+
+// Publish records the event, then delivers it to every subscriber
+func (b *outboxBus) Publish(event Event) {
+	b.outbox.Record(event)
+	b.Bus.Publish(event)
+}
+
+// Record serializes the event for the stream. Events are keyed by their EventKey, if any.
+func (o *Outbox) Record(event Event) {
+	env := envelope{
+		ID:         uuid.NewString(),
+		Type:       event.EventName(),
+		RecordedAt: time.Now().UTC(),
+		Data:       event,
+	}
+	if keyed, ok := event.(Keyed); ok {
+		env.Key = keyed.EventKey()
+	}
+	value, err := json.Marshal(env)
+	if err != nil {
+		log.Printf("Failed to record %s event in the outbox: %v", env.Type, err)
+		return
+	}
+
+	o.mu.Lock()
+	o.seq++
+	o.pending = append(o.pending, outboxEntry{
+		seq: o.seq,
+		msg: stream.Message{ID: env.ID, Type: env.Type, Key: env.Key, Value: value},
+	})
+	if len(o.pending) > o.limit {
+		log.Printf("Event outbox full, dropping %s event", o.pending[0].msg.Type)
+		o.pending[0] = outboxEntry{}
+		o.pending = o.pending[1:]
+	}
+	o.mu.Unlock()
+
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns the number of undelivered events
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Relay delivers recorded events to the publisher until the context is done, in batches of
+// up to batchSize. Events are removed once the broker stored them; a failed batch is
+// retried whole before any later event, after a delay doubling up to maxBackoff, so every
+// event is delivered at least once and in order.
+func (o *Outbox) Relay(ctx context.Context, publisher stream.Publisher, batchSize int, maxBackoff time.Duration) {
+	delay := relayRetryDelay
+	for {
+		delivered, err := o.deliver(ctx, publisher, batchSize)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			// Events recorded meanwhile do not cut the delay short
+			log.Printf("Failed to stream events, retrying in %s: %v", delay, err)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			delay = min(delay*2, maxBackoff)
+			continue
+		}
+		delay = relayRetryDelay
+		if delivered > 0 {
+			continue
+		}
+
+		select {
+		case <-o.ready:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Flush delivers recorded events until none is left or the context is done, retrying
+// failures, and returns the number of events left undelivered
+func (o *Outbox) Flush(ctx context.Context, publisher stream.Publisher, batchSize int) int {
+	for ctx.Err() == nil {
+		delivered, err := o.deliver(ctx, publisher, batchSize)
+		if err == nil && delivered == 0 {
+			break
+		}
+		if err != nil {
+			select {
+			case <-time.After(relayRetryDelay):
+			case <-ctx.Done():
+			}
+		}
+	}
+	return o.Len()
+}
+
+// deliver publishes the oldest batch of recorded events, removing them once published, and
+// returns how many were
+func (o *Outbox) deliver(ctx context.Context, publisher stream.Publisher, batchSize int) (int, error) {
+	o.mu.Lock()
+	n := min(batchSize, len(o.pending))
+	batch := make([]stream.Message, n)
+	for i, entry := range o.pending[:n] {
+		batch[i] = entry.msg
+	}
+	var last uint64
+	if n > 0 {
+		last = o.pending[n-1].seq
+	}
+	o.mu.Unlock()
+
+	if n == 0 {
+		return 0, nil
+	}
+	if err := publisher.Publish(ctx, batch); err != nil {
+		return 0, err
+	}
+
+	// Events dropped from the full outbox meanwhile are no longer pending
+	o.mu.Lock()
+	i := 0
+	for i < len(o.pending) && o.pending[i].seq <= last {
+		i++
+	}
+	clear(o.pending[:i])
+	o.pending = o.pending[i:]
+	o.mu.Unlock()
+	return n, nil
+}
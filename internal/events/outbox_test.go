@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/pkg/stream"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyedEvent struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Seq  int    `json:"seq"`
+}
+
+func (e keyedEvent) EventName() string {
+	return e.Name
+}
+
+func (e keyedEvent) EventKey() string {
+	return e.Key
+}
+
+// fakePublisher records published messages, failing while fail is set
+type fakePublisher struct {
+	mu       sync.Mutex
+	messages []stream.Message
+	fail     bool
+	attempts int
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msgs []stream.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attempts++
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.messages = append(p.messages, msgs...)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func (p *fakePublisher) published() []stream.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]stream.Message(nil), p.messages...)
+}
+
+func TestOutbox_RecordsPublishedEvents(t *testing.T) {
+	outbox := NewOutbox(100)
+	bus := WithOutbox(NewChannelBus(DefaultBufferSize), outbox)
+	defer bus.Close()
+
+	received := make(chan Event, 1)
+	unsubscribe := bus.Subscribe(func(event Event) { received <- event })
+	defer unsubscribe()
+
+	bus.Publish(keyedEvent{Name: "task.created", Key: "task-1", Seq: 1})
+	select {
+	case event := <-received:
+		assert.Equal(t, "task.created", event.EventName())
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered to subscriber")
+	}
+
+	publisher := &fakePublisher{}
+	assert.Equal(t, 0, outbox.Flush(context.Background(), publisher, 10))
+	messages := publisher.published()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "task.created", messages[0].Type)
+	assert.Equal(t, "task-1", messages[0].Key)
+	assert.NotEmpty(t, messages[0].ID)
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(messages[0].Value, &env))
+	assert.Equal(t, messages[0].ID, env["id"])
+	assert.Equal(t, "task.created", env["type"])
+	assert.Equal(t, "task-1", env["key"])
+	assert.Equal(t, map[string]interface{}{"name": "task.created", "key": "task-1", "seq": float64(1)}, env["data"])
+}
+
+func TestOutbox_RelayRetriesInOrder(t *testing.T) {
+	outbox := NewOutbox(100)
+	publisher := &fakePublisher{fail: true}
+	for i := 0; i < 5; i++ {
+		outbox.Record(keyedEvent{Name: "task.updated", Key: "task-1", Seq: i})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		outbox.Relay(ctx, publisher, 2, time.Second)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Failed batches stay in the outbox
+	assert.Eventually(t, func() bool {
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+		return publisher.attempts > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 5, outbox.Len())
+
+	publisher.mu.Lock()
+	publisher.fail = false
+	publisher.mu.Unlock()
+
+	// Events are delivered in order once the broker recovers, including later ones
+	outbox.Record(keyedEvent{Name: "task.updated", Key: "task-1", Seq: 5})
+	require.Eventually(t, func() bool { return outbox.Len() == 0 && len(publisher.published()) == 6 }, 3*time.Second, 10*time.Millisecond)
+	for i, msg := range publisher.published() {
+		var env struct {
+			Data keyedEvent `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(msg.Value, &env))
+		assert.Equal(t, i, env.Data.Seq)
+	}
+}
+
+func TestOutbox_Limit(t *testing.T) {
+	outbox := NewOutbox(2)
+	for i := 0; i < 3; i++ {
+		outbox.Record(keyedEvent{Name: "task.updated", Key: "task-1", Seq: i})
+	}
+	assert.Equal(t, 2, outbox.Len())
+
+	// Events left undelivered are reported
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 2, outbox.Flush(ctx, &fakePublisher{fail: true}, 10))
+}
@@ -0,0 +1,129 @@
+// Package golden compares JSON documents, such as API responses, with golden files kept
+// under testdata/golden, catching accidental changes to their shape. Values that change
+// between runs, such as IDs, timestamps, and tokens, are replaced by placeholders first.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateEnv is the environment variable that, when set, rewrites golden files with the
+// documents compared with them instead of failing
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Dir is the directory of golden files, relative to the package under test
+const Dir = "testdata/golden"
+
+// tokenPattern matches JSON Web Tokens
+var tokenPattern = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)
+
+// AssertJSON compares the canonical form of the JSON document with the golden file of the
+// name, showing a diff when they differ. With UPDATE_GOLDEN set, the golden file is written
+// instead.
+func AssertJSON(t testing.TB, name string, data []byte) {
+	t.Helper()
+
+	actual, err := Canonical(data)
+	require.NoError(t, err, "golden file %s", name)
+
+	path := filepath.Join(Dir, name+".json")
+	if os.Getenv(UpdateEnv) != "" {
+		require.NoError(t, os.MkdirAll(Dir, 0o755))
+		require.NoError(t, os.WriteFile(path, actual, 0o644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file, run the test with %s=1 to record it", UpdateEnv)
+	assert.Equal(t, string(expected), string(actual), "response differs from %s; run the test with %s=1 if the change is intended", path, UpdateEnv)
+}
+
+// Canonical formats the JSON document with indentation and sorted keys, replacing volatile
+// values: each distinct UUID by a numbered placeholder in order of appearance, so references
+// between values are kept, timestamps and dates by fixed placeholders, and tokens by a
+// placeholder.
+func Canonical(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	s := &scrubber{ids: make(map[string]string)}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.scrub(doc)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scrubber replaces the volatile values of a document
+type scrubber struct {
+	ids map[string]string // placeholders by UUID
+}
+
+// scrub returns the value with its volatile values replaced, visiting object keys in
+// sorted order so placeholders are numbered the same way on every run
+func (s *scrubber) scrub(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			v[key] = s.scrub(v[key])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = s.scrub(v[i])
+		}
+		return v
+	case string:
+		return s.scrubString(v)
+	default:
+		return v
+	}
+}
+
+// scrubString returns the placeholder of a volatile string, or the string itself
+func (s *scrubber) scrubString(v string) string {
+	if len(v) == 36 {
+		if _, err := uuid.Parse(v); err == nil {
+			placeholder, ok := s.ids[v]
+			if !ok {
+				placeholder = fmt.Sprintf("<uuid-%d>", len(s.ids)+1)
+				s.ids[v] = placeholder
+			}
+			return placeholder
+		}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return "<time>"
+	}
+	if _, err := time.Parse(time.DateOnly, v); err == nil {
+		return "<date>"
+	}
+	if tokenPattern.MatchString(v) {
+		return "<token>"
+	}
+	return v
+}
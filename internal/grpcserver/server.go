@@ -0,0 +1,344 @@
+// Package grpcserver exposes the auth and task services over gRPC.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/grpcserver/todopb"
+	authService "todo-api/internal/service/auth"
+	loginService "todo-api/internal/service/login"
+	taskService "todo-api/internal/service/task"
+	tenantService "todo-api/internal/service/tenant"
+	"todo-api/pkg/utils"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// contextKey is the type for values stored in the request context
+type contextKey string
+
+const userIDKey contextKey = "user_id"
+
+// Server implements the todo.v1 gRPC services on top of the shared service layer
+type Server struct {
+	authService   authService.Service
+	loginService  loginService.Service
+	taskService   taskService.Service
+	tenantService tenantService.Service
+}
+
+// Deps are the services the gRPC server is built on, shared with the REST API
+type Deps struct {
+	Auth    authService.Service
+	Tasks   taskService.Service
+	Tenants tenantService.Service
+	// Login throttles and audits logins like the REST API; without it, logins go straight
+	// to the auth service
+	Login loginService.Service
+}
+
+// NewServer creates a gRPC server with the auth and task services registered
+func NewServer(deps Deps) *grpc.Server {
+	s := &Server{
+		authService:   deps.Auth,
+		loginService:  deps.Login,
+		taskService:   deps.Tasks,
+		tenantService: deps.Tenants,
+	}
+	if s.loginService == nil {
+		s.loginService = loginService.NewService(loginService.Deps{Auth: deps.Auth})
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.ForceServerCodec(todopb.Codec{}),
+		grpc.UnaryInterceptor(s.authInterceptor),
+	)
+	grpcServer.RegisterService(&authServiceDesc, s)
+	grpcServer.RegisterService(&taskServiceDesc, s)
+
+	return grpcServer
+}
+
+// methodScopes lists the scope required by each authenticated method
+var methodScopes = map[string]string{
+	"/todo.v1.TaskService/CreateTask": auth.ScopeTasksWrite,
+	"/todo.v1.TaskService/GetTask":    auth.ScopeTasksRead,
+	"/todo.v1.TaskService/UpdateTask": auth.ScopeTasksWrite,
+	"/todo.v1.TaskService/DeleteTask": auth.ScopeTasksWrite,
+	"/todo.v1.TaskService/ListTasks":  auth.ScopeTasksRead,
+}
+
+// authInterceptor validates the bearer token for task methods and, like the REST API,
+// rejects callers of suspended tenants
+func (s *Server) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	scope, protected := methodScopes[info.FullMethod]
+	if !protected {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	token, err := utils.ExtractTokenFromHeader(values[0])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	claims, err := s.authService.ValidateToken(token)
+	if err != nil || !claims.IsAccessToken() {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	if !claims.HasScope(scope) {
+		return nil, status.Error(codes.PermissionDenied, "insufficient scope: "+scope+" required")
+	}
+
+	if err := s.checkTenant(claims); err != nil {
+		return nil, err
+	}
+
+	return handler(context.WithValue(ctx, userIDKey, claims.UserID), req)
+}
+
+// checkTenant checks the tenant of the token's tenant claim exists and is active. Tokens
+// without a tenant claim belong to the default tenant.
+func (s *Server) checkTenant(claims *utils.JWTClaims) error {
+	tenantID := tenant.DefaultTenantID
+	if claims.TenantID != uuid.Nil {
+		tenantID = claims.TenantID
+	}
+
+	t, err := s.tenantService.GetTenant(tenantID)
+	if err != nil {
+		return status.Error(codes.NotFound, "tenant not found")
+	}
+	if !t.IsActive() {
+		return status.Error(codes.PermissionDenied, "tenant is suspended")
+	}
+	return nil
+}
+
+// Login authenticates a user and returns tokens. Like the REST API, logins flagged by the
+// login guard are rejected and every attempt is recorded in the audit log.
+func (s *Server) Login(ctx context.Context, req *todopb.LoginRequest) (*todopb.TokenResponse, error) {
+	tokenResponse, err := s.loginService.Login(&auth.LoginRequest{
+		Email:    req.Email,
+		Password: req.Password,
+		Scopes:   req.Scopes,
+	}, loginClient(ctx))
+	var throttled *loginService.ThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		// Clients wait for the retry-after trailer, in seconds, like the REST header
+		grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(throttled.Wait.Seconds())))))
+		return nil, status.Error(codes.ResourceExhausted, "too many login attempts, try again later")
+	case errors.Is(err, auth.ErrDirectoryUnavailable):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return &todopb.TokenResponse{
+		AccessToken:  tokenResponse.AccessToken,
+		RefreshToken: tokenResponse.RefreshToken,
+		TokenType:    tokenResponse.TokenType,
+		ExpiresIn:    tokenResponse.ExpiresIn,
+		Scopes:       tokenResponse.Scopes,
+	}, nil
+}
+
+// loginClient returns the address and user agent of the caller
+func loginClient(ctx context.Context) loginService.Client {
+	var client loginService.Client
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			client.UserAgent = values[0]
+		}
+	}
+	return client
+}
+
+// ValidateToken validates a token and returns its claims
+func (s *Server) ValidateToken(ctx context.Context, req *todopb.ValidateTokenRequest) (*todopb.ValidateTokenResponse, error) {
+	claims, err := s.authService.ValidateToken(req.Token)
+	if err != nil || !claims.IsAccessToken() {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	resp := &todopb.ValidateTokenResponse{
+		UserID: claims.UserID.String(),
+		Email:  claims.Email,
+		Scopes: claims.Scopes,
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+
+	return resp, nil
+}
+
+// CreateTask creates a task for the authenticated user
+func (s *Server) CreateTask(ctx context.Context, req *todopb.CreateTaskRequest) (*todopb.Task, error) {
+	newTask, err := s.taskService.CreateTask(&task.CreateTaskRequest{Title: req.Title}, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoTask(newTask), nil
+}
+
+// GetTask retrieves a task by ID
+func (s *Server) GetTask(ctx context.Context, req *todopb.GetTaskRequest) (*todopb.Task, error) {
+	taskID, err := parseTaskID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.taskService.GetTaskByID(taskID, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoTask(existing), nil
+}
+
+// UpdateTask updates a task
+func (s *Server) UpdateTask(ctx context.Context, req *todopb.UpdateTaskRequest) (*todopb.Task, error) {
+	taskID, err := parseTaskID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updateReq := &task.UpdateTaskRequest{Title: req.Title}
+	if req.Status != nil {
+		taskStatus := task.TaskStatus(*req.Status)
+		updateReq.Status = &taskStatus
+	}
+
+	updated, err := s.taskService.UpdateTask(taskID, updateReq, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoTask(updated), nil
+}
+
+// DeleteTask deletes a task
+func (s *Server) DeleteTask(ctx context.Context, req *todopb.DeleteTaskRequest) (*todopb.DeleteTaskResponse, error) {
+	taskID, err := parseTaskID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskService.DeleteTask(taskID, userIDFrom(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todopb.DeleteTaskResponse{}, nil
+}
+
+// ListTasks lists tasks with filtering, sorting, and pagination
+func (s *Server) ListTasks(ctx context.Context, req *todopb.ListTasksRequest) (*todopb.ListTasksResponse, error) {
+	var filter *task.TaskFilter
+	if req.Status != "" || req.Search != "" {
+		filter = &task.TaskFilter{Search: req.Search}
+		if req.Status != "" {
+			taskStatus := task.TaskStatus(req.Status)
+			filter.Status = &taskStatus
+		}
+	}
+
+	// Apply the same pagination bounds as the REST API
+	page, limit := int(req.Page), int(req.Limit)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	tasks, pagination, err := s.taskService.ListTasks(filter, task.NewTaskSort(req.SortField, req.SortOrder), page, limit, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &todopb.ListTasksResponse{
+		Page:       int32(pagination.Page),
+		Limit:      int32(pagination.Limit),
+		Total:      pagination.Total,
+		TotalPages: int32(pagination.TotalPages),
+	}
+	for _, t := range tasks {
+		resp.Tasks = append(resp.Tasks, toProtoTask(t))
+	}
+
+	return resp, nil
+}
+
+// Helper functions
+func userIDFrom(ctx context.Context) uuid.UUID {
+	userID, _ := ctx.Value(userIDKey).(uuid.UUID)
+	return userID
+}
+
+func parseTaskID(id string) (uuid.UUID, error) {
+	taskID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid task ID")
+	}
+	return taskID, nil
+}
+
+func toProtoTask(t *task.Task) *todopb.Task {
+	return &todopb.Task{
+		ID:        t.ID.String(),
+		Title:     t.Title,
+		Status:    string(t.Status),
+		UserID:    t.UserID.String(),
+		CreatedAt: t.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt: t.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// toStatus maps service errors to gRPC status codes
+func toStatus(err error) error {
+	switch {
+	case err.Error() == "task not found":
+		return status.Error(codes.NotFound, err.Error())
+	case err.Error() == "access denied":
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, task.ErrInvalidTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, tenant.ErrQuotaExceeded), errors.Is(err, task.ErrLimitExceeded), errors.Is(err, billing.ErrUpgradeRequired),
+		errors.Is(err, task.ErrStorageFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case strings.HasPrefix(err.Error(), "failed to"):
+		return status.Error(codes.Internal, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
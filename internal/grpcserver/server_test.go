@@ -0,0 +1,263 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/grpcserver/todopb"
+	"todo-api/internal/metrics"
+	auditService "todo-api/internal/service/audit"
+	authService "todo-api/internal/service/auth"
+	loginService "todo-api/internal/service/login"
+	loginGuard "todo-api/internal/service/loginguard"
+	taskService "todo-api/internal/service/task"
+	tenantService "todo-api/internal/service/tenant"
+	"todo-api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupTestClient(t *testing.T) *grpc.ClientConn {
+	return newTestClient(t, newTestDeps())
+}
+
+// newTestDeps returns the services of a test server
+func newTestDeps() Deps {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := authService.NewService(cfg)
+	return Deps{Auth: authSvc, Tasks: taskService.NewService(authSvc), Tenants: tenantService.NewService(authSvc)}
+}
+
+// newTestClient starts a server on the services and connects a client to it
+func newTestClient(t *testing.T, deps Deps) *grpc.ClientConn {
+	server := NewServer(deps)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(todopb.Codec{})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func login(t *testing.T, conn *grpc.ClientConn, scopes ...string) context.Context {
+	return loginAs(t, conn, "john.doe@example.com", scopes...)
+}
+
+// loginAs logs in as the fixture user, returning a context carrying the access token
+func loginAs(t *testing.T, conn *grpc.ClientConn, email string, scopes ...string) context.Context {
+	var tokenResp todopb.TokenResponse
+	err := conn.Invoke(context.Background(), "/todo.v1.AuthService/Login", &todopb.LoginRequest{
+		Email:    email,
+		Password: "password123",
+		Scopes:   scopes,
+	}, &tokenResp)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+tokenResp.AccessToken)
+}
+
+func TestServer_LoginAndValidateToken(t *testing.T) {
+	conn := setupTestClient(t)
+
+	var tokenResp todopb.TokenResponse
+	err := conn.Invoke(context.Background(), "/todo.v1.AuthService/Login", &todopb.LoginRequest{
+		Email:    "john.doe@example.com",
+		Password: "password123",
+	}, &tokenResp)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokenResp.AccessToken)
+	assert.Equal(t, "Bearer", tokenResp.TokenType)
+	assert.Equal(t, int64(900), tokenResp.ExpiresIn)
+
+	var validateResp todopb.ValidateTokenResponse
+	err = conn.Invoke(context.Background(), "/todo.v1.AuthService/ValidateToken", &todopb.ValidateTokenRequest{
+		Token: tokenResp.AccessToken,
+	}, &validateResp)
+
+	require.NoError(t, err)
+	assert.Equal(t, "3484ec33-20f9-4993-a25f-f49f6f5dbe54", validateResp.UserID)
+	assert.Equal(t, "john.doe@example.com", validateResp.Email)
+	assert.NotZero(t, validateResp.ExpiresAt)
+}
+
+func TestServer_Login_InvalidCredentials(t *testing.T) {
+	conn := setupTestClient(t)
+
+	var tokenResp todopb.TokenResponse
+	err := conn.Invoke(context.Background(), "/todo.v1.AuthService/Login", &todopb.LoginRequest{
+		Email:    "john.doe@example.com",
+		Password: "wrongpassword",
+	}, &tokenResp)
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_LoginThrottling(t *testing.T) {
+	deps := newTestDeps()
+	auditSvc := auditService.NewService()
+	deps.Login = loginService.NewService(loginService.Deps{
+		Auth:  deps.Auth,
+		Audit: auditSvc,
+		Guard: loginGuard.NewService(config.LoginGuardConfig{
+			Window:             time.Minute,
+			MaxAccountFailures: 2,
+			FlagDuration:       time.Minute,
+			ThrottleInterval:   time.Minute,
+		}, metrics.NewRegistry()),
+	})
+	conn := newTestClient(t, deps)
+
+	invoke := func(password string, opts ...grpc.CallOption) error {
+		var tokenResp todopb.TokenResponse
+		return conn.Invoke(context.Background(), "/todo.v1.AuthService/Login", &todopb.LoginRequest{
+			Email:    "john.doe@example.com",
+			Password: password,
+		}, &tokenResp, opts...)
+	}
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(invoke("wrongpassword")))
+	assert.Equal(t, codes.Unauthenticated, status.Code(invoke("wrongpassword")))
+
+	// Like the REST API, the flagged account gets one more attempt, then must wait
+	assert.NoError(t, invoke("password123"))
+	var trailer metadata.MD
+	err := invoke("password123", grpc.Trailer(&trailer))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"60"}, trailer.Get("retry-after"))
+
+	entries, _ := auditSvc.List(&audit.Filter{Action: string(audit.ActionAnomalyDetected)}, 1, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "account_failures", entries[0].Details["type"])
+
+	entries, _ = auditSvc.List(&audit.Filter{Action: string(audit.ActionLoginFailed)}, 1, 10)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "too many login attempts", entries[0].Details["reason"])
+	assert.NotEmpty(t, entries[0].IP)
+}
+
+func TestServer_TaskCRUD(t *testing.T) {
+	conn := setupTestClient(t)
+	ctx := login(t, conn)
+
+	var created todopb.Task
+	err := conn.Invoke(ctx, "/todo.v1.TaskService/CreateTask", &todopb.CreateTaskRequest{Title: "gRPC Task"}, &created)
+	require.NoError(t, err)
+	assert.Equal(t, "gRPC Task", created.Title)
+	assert.Equal(t, "pending", created.Status)
+
+	var fetched todopb.Task
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/GetTask", &todopb.GetTaskRequest{ID: created.ID}, &fetched)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, fetched.ID)
+
+	completed := "completed"
+	var updated todopb.Task
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/UpdateTask", &todopb.UpdateTaskRequest{ID: created.ID, Status: &completed}, &updated)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", updated.Status)
+	assert.Equal(t, "gRPC Task", updated.Title)
+
+	var list todopb.ListTasksResponse
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/ListTasks", &todopb.ListTasksRequest{Status: "completed"}, &list)
+	require.NoError(t, err)
+	require.Len(t, list.Tasks, 1)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, int32(10), list.Limit)
+
+	var deleted todopb.DeleteTaskResponse
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/DeleteTask", &todopb.DeleteTaskRequest{ID: created.ID}, &deleted)
+	require.NoError(t, err)
+
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/GetTask", &todopb.GetTaskRequest{ID: created.ID}, &fetched)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_TaskMethodsRequireAuth(t *testing.T) {
+	conn := setupTestClient(t)
+
+	var list todopb.ListTasksResponse
+	err := conn.Invoke(context.Background(), "/todo.v1.TaskService/ListTasks", &todopb.ListTasksRequest{}, &list)
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_TaskMethodsRequireScope(t *testing.T) {
+	conn := setupTestClient(t)
+	ctx := login(t, conn, auth.ScopeTasksRead)
+
+	var created todopb.Task
+	err := conn.Invoke(ctx, "/todo.v1.TaskService/CreateTask", &todopb.CreateTaskRequest{Title: "Denied"}, &created)
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_SuspendedTenant(t *testing.T) {
+	deps := newTestDeps()
+	conn := newTestClient(t, deps)
+	ctx := loginAs(t, conn, "alice@acme.example.com")
+
+	var created todopb.Task
+	err := conn.Invoke(ctx, "/todo.v1.TaskService/CreateTask", &todopb.CreateTaskRequest{Title: "Before suspension"}, &created)
+	require.NoError(t, err)
+
+	// Users of a suspended tenant can no longer read or change tasks
+	suspended := tenant.StatusSuspended
+	_, err = deps.Tenants.UpdateTenant(authService.AcmeTenantID, &tenant.UpdateTenantRequest{Status: &suspended})
+	require.NoError(t, err)
+
+	var result todopb.Task
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/CreateTask", &todopb.CreateTaskRequest{Title: "After suspension"}, &result)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/GetTask", &todopb.GetTaskRequest{ID: created.ID}, &result)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	var deleted todopb.DeleteTaskResponse
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/DeleteTask", &todopb.DeleteTaskRequest{ID: created.ID}, &deleted)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Other tenants are not affected
+	err = conn.Invoke(login(t, conn), "/todo.v1.TaskService/CreateTask", &todopb.CreateTaskRequest{Title: "Default tenant"}, &result)
+	assert.NoError(t, err)
+}
+
+func TestServer_InvalidArguments(t *testing.T) {
+	conn := setupTestClient(t)
+	ctx := login(t, conn)
+
+	var fetched todopb.Task
+	err := conn.Invoke(ctx, "/todo.v1.TaskService/GetTask", &todopb.GetTaskRequest{ID: "not-a-uuid"}, &fetched)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	var created todopb.Task
+	err = conn.Invoke(ctx, "/todo.v1.TaskService/CreateTask", &todopb.CreateTaskRequest{Title: ""}, &created)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,59 @@
+package grpcserver
+
+import (
+	"context"
+
+	"todo-api/internal/grpcserver/todopb"
+
+	"google.golang.org/grpc"
+)
+
+// unaryMethod builds a method descriptor decoding Req and dispatching to call
+func unaryMethod[Req any, PReq interface {
+	*Req
+	todopb.Message
+}, Resp todopb.Message](service, name string, call func(s *Server, ctx context.Context, req PReq) (Resp, error)) grpc.MethodDesc {
+	fullMethod := "/" + service + "/" + name
+
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := PReq(new(Req))
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(*Server), ctx, req.(PReq))
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+		},
+	}
+}
+
+var authServiceDesc = grpc.ServiceDesc{
+	ServiceName: "todo.v1.AuthService",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("todo.v1.AuthService", "Login", (*Server).Login),
+		unaryMethod("todo.v1.AuthService", "ValidateToken", (*Server).ValidateToken),
+	},
+	Metadata: "proto/todo.proto",
+}
+
+var taskServiceDesc = grpc.ServiceDesc{
+	ServiceName: "todo.v1.TaskService",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("todo.v1.TaskService", "CreateTask", (*Server).CreateTask),
+		unaryMethod("todo.v1.TaskService", "GetTask", (*Server).GetTask),
+		unaryMethod("todo.v1.TaskService", "UpdateTask", (*Server).UpdateTask),
+		unaryMethod("todo.v1.TaskService", "DeleteTask", (*Server).DeleteTask),
+		unaryMethod("todo.v1.TaskService", "ListTasks", (*Server).ListTasks),
+	},
+	Metadata: "proto/todo.proto",
+}
@@ -0,0 +1,389 @@
+package todopb
+
+import "google.golang.org/protobuf/encoding/protowire"
+
+// LoginRequest mirrors todo.v1.LoginRequest
+type LoginRequest struct {
+	Email    string
+	Password string
+	Scopes   []string
+}
+
+// Marshal encodes the message
+func (m *LoginRequest) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.Email)
+	b = appendString(b, 2, m.Password)
+	b = appendStrings(b, 3, m.Scopes)
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *LoginRequest) Unmarshal(b []byte) error {
+	*m = LoginRequest{}
+	return decodeFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.Email, err = f.string()
+		case 2:
+			m.Password, err = f.string()
+		case 3:
+			var scope string
+			scope, err = f.string()
+			m.Scopes = append(m.Scopes, scope)
+		}
+		return err
+	})
+}
+
+// TokenResponse mirrors todo.v1.TokenResponse
+type TokenResponse struct {
+	AccessToken  string
+	RefreshToken string
+	TokenType    string
+	ExpiresIn    int64
+	Scopes       []string
+}
+
+// Marshal encodes the message
+func (m *TokenResponse) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.AccessToken)
+	b = appendString(b, 2, m.RefreshToken)
+	b = appendString(b, 3, m.TokenType)
+	b = appendInt(b, 4, m.ExpiresIn)
+	b = appendStrings(b, 5, m.Scopes)
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *TokenResponse) Unmarshal(b []byte) error {
+	*m = TokenResponse{}
+	return decodeFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.AccessToken, err = f.string()
+		case 2:
+			m.RefreshToken, err = f.string()
+		case 3:
+			m.TokenType, err = f.string()
+		case 4:
+			m.ExpiresIn, err = f.int64()
+		case 5:
+			var scope string
+			scope, err = f.string()
+			m.Scopes = append(m.Scopes, scope)
+		}
+		return err
+	})
+}
+
+// ValidateTokenRequest mirrors todo.v1.ValidateTokenRequest
+type ValidateTokenRequest struct {
+	Token string
+}
+
+// Marshal encodes the message
+func (m *ValidateTokenRequest) Marshal() []byte {
+	return appendString(nil, 1, m.Token)
+}
+
+// Unmarshal decodes the message
+func (m *ValidateTokenRequest) Unmarshal(b []byte) error {
+	*m = ValidateTokenRequest{}
+	return decodeFields(b, func(f field) (err error) {
+		if f.num == 1 {
+			m.Token, err = f.string()
+		}
+		return err
+	})
+}
+
+// ValidateTokenResponse mirrors todo.v1.ValidateTokenResponse
+type ValidateTokenResponse struct {
+	UserID    string
+	Email     string
+	Scopes    []string
+	ExpiresAt int64
+}
+
+// Marshal encodes the message
+func (m *ValidateTokenResponse) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.UserID)
+	b = appendString(b, 2, m.Email)
+	b = appendStrings(b, 3, m.Scopes)
+	b = appendInt(b, 4, m.ExpiresAt)
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *ValidateTokenResponse) Unmarshal(b []byte) error {
+	*m = ValidateTokenResponse{}
+	return decodeFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.UserID, err = f.string()
+		case 2:
+			m.Email, err = f.string()
+		case 3:
+			var scope string
+			scope, err = f.string()
+			m.Scopes = append(m.Scopes, scope)
+		case 4:
+			m.ExpiresAt, err = f.int64()
+		}
+		return err
+	})
+}
+
+// Task mirrors todo.v1.Task
+type Task struct {
+	ID        string
+	Title     string
+	Status    string
+	UserID    string
+	CreatedAt string
+	UpdatedAt string
+}
+
+// Marshal encodes the message
+func (m *Task) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.ID)
+	b = appendString(b, 2, m.Title)
+	b = appendString(b, 3, m.Status)
+	b = appendString(b, 4, m.UserID)
+	b = appendString(b, 5, m.CreatedAt)
+	b = appendString(b, 6, m.UpdatedAt)
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *Task) Unmarshal(b []byte) error {
+	*m = Task{}
+	return decodeFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.ID, err = f.string()
+		case 2:
+			m.Title, err = f.string()
+		case 3:
+			m.Status, err = f.string()
+		case 4:
+			m.UserID, err = f.string()
+		case 5:
+			m.CreatedAt, err = f.string()
+		case 6:
+			m.UpdatedAt, err = f.string()
+		}
+		return err
+	})
+}
+
+// CreateTaskRequest mirrors todo.v1.CreateTaskRequest
+type CreateTaskRequest struct {
+	Title string
+}
+
+// Marshal encodes the message
+func (m *CreateTaskRequest) Marshal() []byte {
+	return appendString(nil, 1, m.Title)
+}
+
+// Unmarshal decodes the message
+func (m *CreateTaskRequest) Unmarshal(b []byte) error {
+	*m = CreateTaskRequest{}
+	return decodeFields(b, func(f field) (err error) {
+		if f.num == 1 {
+			m.Title, err = f.string()
+		}
+		return err
+	})
+}
+
+// GetTaskRequest mirrors todo.v1.GetTaskRequest
+type GetTaskRequest struct {
+	ID string
+}
+
+// Marshal encodes the message
+func (m *GetTaskRequest) Marshal() []byte {
+	return appendString(nil, 1, m.ID)
+}
+
+// Unmarshal decodes the message
+func (m *GetTaskRequest) Unmarshal(b []byte) error {
+	*m = GetTaskRequest{}
+	return decodeFields(b, func(f field) (err error) {
+		if f.num == 1 {
+			m.ID, err = f.string()
+		}
+		return err
+	})
+}
+
+// UpdateTaskRequest mirrors todo.v1.UpdateTaskRequest
+type UpdateTaskRequest struct {
+	ID     string
+	Title  *string
+	Status *string
+}
+
+// Marshal encodes the message
+func (m *UpdateTaskRequest) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.ID)
+	b = appendOptionalString(b, 2, m.Title)
+	b = appendOptionalString(b, 3, m.Status)
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *UpdateTaskRequest) Unmarshal(b []byte) error {
+	*m = UpdateTaskRequest{}
+	return decodeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			v, err := f.string()
+			m.ID = v
+			return err
+		case 2:
+			v, err := f.string()
+			m.Title = &v
+			return err
+		case 3:
+			v, err := f.string()
+			m.Status = &v
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteTaskRequest mirrors todo.v1.DeleteTaskRequest
+type DeleteTaskRequest struct {
+	ID string
+}
+
+// Marshal encodes the message
+func (m *DeleteTaskRequest) Marshal() []byte {
+	return appendString(nil, 1, m.ID)
+}
+
+// Unmarshal decodes the message
+func (m *DeleteTaskRequest) Unmarshal(b []byte) error {
+	*m = DeleteTaskRequest{}
+	return decodeFields(b, func(f field) (err error) {
+		if f.num == 1 {
+			m.ID, err = f.string()
+		}
+		return err
+	})
+}
+
+// DeleteTaskResponse mirrors todo.v1.DeleteTaskResponse
+type DeleteTaskResponse struct{}
+
+// Marshal encodes the message
+func (m *DeleteTaskResponse) Marshal() []byte {
+	return nil
+}
+
+// Unmarshal decodes the message
+func (m *DeleteTaskResponse) Unmarshal(b []byte) error {
+	return decodeFields(b, func(f field) error { return nil })
+}
+
+// ListTasksRequest mirrors todo.v1.ListTasksRequest
+type ListTasksRequest struct {
+	Status    string
+	Search    string
+	SortField string
+	SortOrder string
+	Page      int32
+	Limit     int32
+}
+
+// Marshal encodes the message
+func (m *ListTasksRequest) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.Status)
+	b = appendString(b, 2, m.Search)
+	b = appendString(b, 3, m.SortField)
+	b = appendString(b, 4, m.SortOrder)
+	b = appendInt(b, 5, int64(m.Page))
+	b = appendInt(b, 6, int64(m.Limit))
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *ListTasksRequest) Unmarshal(b []byte) error {
+	*m = ListTasksRequest{}
+	return decodeFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.Status, err = f.string()
+		case 2:
+			m.Search, err = f.string()
+		case 3:
+			m.SortField, err = f.string()
+		case 4:
+			m.SortOrder, err = f.string()
+		case 5:
+			m.Page, err = f.int32()
+		case 6:
+			m.Limit, err = f.int32()
+		}
+		return err
+	})
+}
+
+// ListTasksResponse mirrors todo.v1.ListTasksResponse
+type ListTasksResponse struct {
+	Tasks      []*Task
+	Page       int32
+	Limit      int32
+	Total      int64
+	TotalPages int32
+}
+
+// Marshal encodes the message
+func (m *ListTasksResponse) Marshal() []byte {
+	var b []byte
+	for _, t := range m.Tasks {
+		b = appendMessage(b, 1, t)
+	}
+	b = appendInt(b, 2, int64(m.Page))
+	b = appendInt(b, 3, int64(m.Limit))
+	b = appendInt(b, 4, m.Total)
+	b = appendInt(b, 5, int64(m.TotalPages))
+	return b
+}
+
+// Unmarshal decodes the message
+func (m *ListTasksResponse) Unmarshal(b []byte) error {
+	*m = ListTasksResponse{}
+	return decodeFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			if err = f.expect(protowire.BytesType); err != nil {
+				return err
+			}
+			t := &Task{}
+			if err = t.Unmarshal(f.bytes); err != nil {
+				return err
+			}
+			m.Tasks = append(m.Tasks, t)
+		case 2:
+			m.Page, err = f.int32()
+		case 3:
+			m.Limit, err = f.int32()
+		case 4:
+			m.Total, err = f.int64()
+		case 5:
+			m.TotalPages, err = f.int32()
+		}
+		return err
+	})
+}
@@ -0,0 +1,87 @@
+package todopb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestLoginRequest_RoundTrip(t *testing.T) {
+	original := &LoginRequest{
+		Email:    "john.doe@example.com",
+		Password: "password123",
+		Scopes:   []string{"tasks:read", "tasks:write"},
+	}
+
+	var decoded LoginRequest
+	require.NoError(t, decoded.Unmarshal(original.Marshal()))
+
+	assert.Equal(t, *original, decoded)
+}
+
+func TestUpdateTaskRequest_OptionalFields(t *testing.T) {
+	title := ""
+	original := &UpdateTaskRequest{ID: "task-id", Title: &title}
+
+	var decoded UpdateTaskRequest
+	require.NoError(t, decoded.Unmarshal(original.Marshal()))
+
+	// Explicitly set empty strings survive the round trip, unset fields stay nil
+	require.NotNil(t, decoded.Title)
+	assert.Equal(t, "", *decoded.Title)
+	assert.Nil(t, decoded.Status)
+}
+
+func TestListTasksResponse_RoundTrip(t *testing.T) {
+	original := &ListTasksResponse{
+		Tasks: []*Task{
+			{ID: "1", Title: "First", Status: "pending"},
+			{ID: "2", Title: "Second", Status: "completed"},
+		},
+		Page:       1,
+		Limit:      10,
+		Total:      2,
+		TotalPages: 1,
+	}
+
+	var decoded ListTasksResponse
+	require.NoError(t, decoded.Unmarshal(original.Marshal()))
+
+	assert.Equal(t, original, &decoded)
+}
+
+func TestUnmarshal_SkipsUnknownFields(t *testing.T) {
+	b := (&GetTaskRequest{ID: "task-id"}).Marshal()
+	b = protowire.AppendTag(b, 99, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, 42)
+
+	var decoded GetTaskRequest
+	require.NoError(t, decoded.Unmarshal(b))
+
+	assert.Equal(t, "task-id", decoded.ID)
+}
+
+func TestUnmarshal_InvalidInput(t *testing.T) {
+	var decoded Task
+
+	err := decoded.Unmarshal([]byte{0x0a, 0xff})
+
+	assert.Error(t, err)
+}
+
+func TestCodec(t *testing.T) {
+	codec := Codec{}
+
+	data, err := codec.Marshal(&CreateTaskRequest{Title: "New task"})
+	require.NoError(t, err)
+
+	var decoded CreateTaskRequest
+	require.NoError(t, codec.Unmarshal(data, &decoded))
+	assert.Equal(t, "New task", decoded.Title)
+	assert.Equal(t, "proto", codec.Name())
+
+	_, err = codec.Marshal("not a message")
+	assert.Error(t, err)
+}
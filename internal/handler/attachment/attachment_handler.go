@@ -0,0 +1,243 @@
+package attachment
+
+import (
+	"errors"
+	"log"
+
+	"todo-api/internal/domain/attachment"
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/response"
+	attachmentService "todo-api/internal/service/attachment"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles task attachment HTTP requests. File contents never pass through the
+// handler: clients upload and download them with presigned URLs of the object storage.
+type Handler struct {
+	attachmentService attachmentService.Service
+}
+
+// NewHandler creates a new attachment handler instance. A nil service responds to every
+// request with 501 Not Implemented, for deployments without attachment storage.
+func NewHandler(attachmentSvc attachmentService.Service) *Handler {
+	return &Handler{
+		attachmentService: attachmentSvc,
+	}
+}
+
+// CreateAttachment handles adding a pending attachment to a task. The response carries the
+// URL the file is uploaded to, after which the upload is completed.
+func (h *Handler) CreateAttachment(c *fiber.Ctx) error {
+	if h.attachmentService == nil {
+		return errNotConfigured(c)
+	}
+
+	taskID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errInvalidTaskID(c)
+	}
+
+	var req attachment.CreateAttachmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	created, upload, err := h.attachmentService.CreateAttachment(taskID, &req, userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Attachment created, upload the file to complete it",
+		"data": fiber.Map{
+			"attachment": created,
+			"upload":     upload,
+		},
+	})
+}
+
+// CompleteAttachment handles completing the upload of an attachment's file. Files flagged by
+// the malware scanner are rejected, with the quarantined attachment.
+func (h *Handler) CompleteAttachment(c *fiber.Ctx) error {
+	if h.attachmentService == nil {
+		return errNotConfigured(c)
+	}
+
+	taskID, id, err := parseIDs(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	completed, err := h.attachmentService.CompleteAttachment(c.UserContext(), taskID, id, userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+	if completed.Status == attachment.StatusQuarantined {
+		return response.Send(c, fiber.StatusUnprocessableEntity, fiber.Map{
+			"error":   true,
+			"message": "File was flagged by the malware scanner and quarantined",
+			"data":    completed,
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Attachment uploaded successfully",
+		"data":    completed,
+	})
+}
+
+// ListAttachments handles listing the attachments of a task
+func (h *Handler) ListAttachments(c *fiber.Ctx) error {
+	if h.attachmentService == nil {
+		return errNotConfigured(c)
+	}
+
+	taskID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errInvalidTaskID(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	attachments, err := h.attachmentService.ListAttachments(taskID, userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Attachments retrieved successfully",
+		"data":    attachments,
+	})
+}
+
+// DownloadAttachment handles returning the URL an attachment's file is downloaded from
+func (h *Handler) DownloadAttachment(c *fiber.Ctx) error {
+	if h.attachmentService == nil {
+		return errNotConfigured(c)
+	}
+
+	taskID, id, err := parseIDs(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	download, err := h.attachmentService.DownloadAttachment(taskID, id, userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Download URL created successfully",
+		"data":    download,
+	})
+}
+
+// DeleteAttachment handles deleting an attachment and its file
+func (h *Handler) DeleteAttachment(c *fiber.Ctx) error {
+	if h.attachmentService == nil {
+		return errNotConfigured(c)
+	}
+
+	taskID, id, err := parseIDs(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.attachmentService.DeleteAttachment(c.UserContext(), taskID, id, userID); err != nil {
+		return sendError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// parseIDs parses the task and attachment IDs from the URL parameters
+func parseIDs(c *fiber.Ctx) (taskID, id uuid.UUID, err error) {
+	if taskID, err = uuid.Parse(c.Params("id")); err != nil {
+		return taskID, id, errors.New("Invalid task ID")
+	}
+	if id, err = uuid.Parse(c.Params("attachmentId")); err != nil {
+		return taskID, id, errors.New("Invalid attachment ID")
+	}
+	return taskID, id, nil
+}
+
+// sendError responds with the status of an attachment service error
+func sendError(c *fiber.Ctx, err error) error {
+	if response.TimedOut(c, err) {
+		return c.UserContext().Err()
+	}
+
+	status, message := fiber.StatusBadRequest, err.Error()
+	switch {
+	case err.Error() == "task not found", errors.Is(err, attachment.ErrNotFound):
+		status = fiber.StatusNotFound
+	case err.Error() == "access denied":
+		status = fiber.StatusForbidden
+	case errors.Is(err, attachment.ErrTooLarge):
+		status = fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, attachment.ErrNotUploaded):
+		status = fiber.StatusConflict
+	case errors.Is(err, billing.ErrUpgradeRequired):
+		status = fiber.StatusPaymentRequired
+	case errors.Is(err, attachment.ErrStorageUnavailable):
+		// Storage responses are logged rather than returned
+		log.Printf("Attachment storage request failed: %v", err)
+		status, message = fiber.StatusBadGateway, attachment.ErrStorageUnavailable.Error()
+	case errors.Is(err, attachment.ErrScanUnavailable):
+		log.Printf("Attachment scan failed: %v", err)
+		status, message = fiber.StatusServiceUnavailable, attachment.ErrScanUnavailable.Error()
+	}
+
+	return response.Send(c, status, fiber.Map{
+		"error":   true,
+		"message": message,
+	})
+}
+
+func errInvalidTaskID(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+		"error":   true,
+		"message": "Invalid task ID",
+	})
+}
+
+func errNotConfigured(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotImplemented, fiber.Map{
+		"error":   true,
+		"message": "Attachments are not configured",
+	})
+}
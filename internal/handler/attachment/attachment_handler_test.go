@@ -0,0 +1,177 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/service/attachment"
+	"todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/blob"
+	"todo-api/pkg/config"
+	"todo-api/pkg/scan"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+func setupTestApp(t *testing.T, attachmentSvc attachment.Service) *fiber.App {
+	handler := NewHandler(attachmentSvc)
+
+	app := fiber.New()
+	tasks := app.Group("/tasks", func(c *fiber.Ctx) error {
+		c.Locals("user_id", johnID)
+		return c.Next()
+	})
+	tasks.Get("/:id/attachments", handler.ListAttachments)
+	tasks.Post("/:id/attachments", handler.CreateAttachment)
+	tasks.Post("/:id/attachments/:attachmentId/complete", handler.CompleteAttachment)
+	tasks.Get("/:id/attachments/:attachmentId/download", handler.DownloadAttachment)
+	tasks.Delete("/:id/attachments/:attachmentId", handler.DeleteAttachment)
+	return app
+}
+
+func request(t *testing.T, app *fiber.App, method, target string, body interface{}) (*http.Response, map[string]interface{}) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return resp, result
+}
+
+func TestHandler_Attachments(t *testing.T) {
+	cfg := &config.Config{Files: config.AttachmentsConfig{Storage: "s3", MaxSize: 1024, URLTTL: time.Minute, Timeout: time.Second}}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithEventBus(auth.NewService(cfg), bus)
+	store := blob.NewMemoryStore()
+	app := setupTestApp(t, attachment.NewService(cfg.Files, taskSvc, store, bus))
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+	base := "/tasks/" + created.ID.String() + "/attachments"
+
+	resp, _ := request(t, app, http.MethodPost, base, map[string]interface{}{"filename": "big.bin", "size": 4096})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp, result := request(t, app, http.MethodPost, base, map[string]interface{}{"filename": "notes.txt", "content_type": "text/plain", "size": 5})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	data := result["data"].(map[string]interface{})
+	upload := data["upload"].(map[string]interface{})
+	assert.Equal(t, "PUT", upload["method"])
+	assert.NotContains(t, data["attachment"], "key")
+	id := data["attachment"].(map[string]interface{})["id"].(string)
+
+	// Completing before uploading conflicts
+	resp, _ = request(t, app, http.MethodPost, base+"/"+id+"/complete", nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	store.Put("attachments/"+created.ID.String()+"/"+id, "text/plain", []byte("notes"))
+	resp, result = request(t, app, http.MethodPost, base+"/"+id+"/complete", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ready", result["data"].(map[string]interface{})["status"])
+
+	resp, result = request(t, app, http.MethodGet, base+"/"+id+"/download", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "GET", result["data"].(map[string]interface{})["method"])
+
+	resp, result = request(t, app, http.MethodGet, base, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, result["data"], 1)
+
+	resp, _ = request(t, app, http.MethodDelete, base+"/"+id, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = request(t, app, http.MethodGet, base+"/"+id+"/download", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = request(t, app, http.MethodGet, "/tasks/"+uuid.NewString()+"/attachments", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = request(t, app, http.MethodDelete, base+"/not-an-id", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// flaggingScanner flags every file, or fails while down
+type flaggingScanner struct {
+	down bool
+}
+
+func (f *flaggingScanner) Scan(ctx context.Context, r io.Reader) (*scan.Result, error) {
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	return &scan.Result{Infected: true, Threat: "Eicar-Signature"}, nil
+}
+
+// proPlans is a plan directory putting every user on a plan without limits
+type proPlans struct{}
+
+func (proPlans) PlanOf(uuid.UUID) (billing.Plan, billing.Limits) {
+	return billing.PlanPro, billing.Limits{}
+}
+
+func TestHandler_Quarantine(t *testing.T) {
+	cfg := &config.Config{Files: config.AttachmentsConfig{
+		Storage: "s3", MaxSize: 1024, URLTTL: time.Minute, Timeout: time.Second, ScannerTimeout: time.Second,
+	}}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithEventBus(auth.NewService(cfg), bus)
+	store, scanner := blob.NewMemoryStore(), &flaggingScanner{down: true}
+	svc := attachment.NewServiceWithDeps(attachment.Deps{Config: cfg.Files, Tasks: taskSvc, Store: store, Bus: bus, Plans: proPlans{},
+		Scanner: scanner})
+	app := setupTestApp(t, svc)
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+	base := "/tasks/" + created.ID.String() + "/attachments"
+
+	resp, result := request(t, app, http.MethodPost, base, map[string]interface{}{"filename": "setup.exe", "size": 5})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	id := result["data"].(map[string]interface{})["attachment"].(map[string]interface{})["id"].(string)
+	store.Put("attachments/"+created.ID.String()+"/"+id, "application/octet-stream", []byte("EICAR"))
+
+	resp, result = request(t, app, http.MethodPost, base+"/"+id+"/complete", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "attachment scanning is unavailable", result["message"])
+
+	scanner.down = false
+	resp, result = request(t, app, http.MethodPost, base+"/"+id+"/complete", nil)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "quarantined", data["status"])
+	assert.Equal(t, "infected", data["scan_status"])
+	assert.Equal(t, "Eicar-Signature", data["threat"])
+
+	resp, _ = request(t, app, http.MethodGet, base+"/"+id+"/download", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_NotConfigured(t *testing.T) {
+	app := setupTestApp(t, nil)
+	resp, result := request(t, app, http.MethodGet, "/tasks/"+uuid.NewString()+"/attachments", nil)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	assert.Equal(t, "Attachments are not configured", result["message"])
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/middleware"
+	"todo-api/internal/response"
+	auditService "todo-api/internal/service/audit"
+	"todo-api/pkg/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles the audit log admin API. Routes are expected to be restricted to
+// platform admins holding the audit:read scope.
+type Handler struct {
+	auditService auditService.Service
+}
+
+// NewHandler creates a new audit handler instance
+func NewHandler(auditSvc auditService.Service) *Handler {
+	return &Handler{
+		auditService: auditSvc,
+	}
+}
+
+// Record stamps the entry with the client address and user agent of the request and
+// appends it to the audit log. A nil service records nothing.
+func Record(auditSvc auditService.Service, c *fiber.Ctx, entry *audit.Entry) {
+	if auditSvc == nil {
+		return
+	}
+
+	entry.IP = middleware.ClientIP(c).String()
+	entry.UserAgent = c.Get(fiber.HeaderUserAgent)
+	auditSvc.Record(entry)
+}
+
+// ListEntries handles listing audit log entries, newest first
+func (h *Handler) ListEntries(c *fiber.Ctx) error {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	if err := filter.Validate(); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	page, limit := parsePagination(c)
+	entries, pagination := h.auditService.List(filter, page, limit)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Audit entries retrieved successfully",
+		"data":    entries,
+		"meta":    &types.MetaInfo{Pagination: *pagination},
+	})
+}
+
+// parseFilter parses the audit filter from the query parameters
+func parseFilter(c *fiber.Ctx) (*audit.Filter, error) {
+	filter := &audit.Filter{
+		Action: c.Query("action"),
+	}
+
+	if userStr := c.Query("user_id"); userStr != "" {
+		userID, err := uuid.Parse(userStr)
+		if err != nil {
+			return nil, errors.New("invalid user_id: " + userStr)
+		}
+		filter.UserID = &userID
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	filter.From = from
+
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		return nil, err
+	}
+	filter.To = to
+
+	return filter, nil
+}
+
+// parseTimeQuery parses an optional RFC 3339 timestamp or YYYY-MM-DD date query parameter
+func parseTimeQuery(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+
+	return nil, errors.New("invalid " + key + ": expected RFC 3339 timestamp or YYYY-MM-DD date")
+}
+
+// parsePagination parses the page and limit query parameters
+func parsePagination(c *fiber.Ctx) (int, int) {
+	page := 1
+	limit := 20
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	return page, limit
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	auditService "todo-api/internal/service/audit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, app *fiber.App, path string) (int, map[string]interface{}) {
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return resp.StatusCode, response
+}
+
+func TestHandler_ListEntries(t *testing.T) {
+	auditSvc := auditService.NewService()
+	handler := NewHandler(auditSvc)
+
+	userID := uuid.New()
+	app := fiber.New()
+	app.Post("/login", func(c *fiber.Ctx) error {
+		entry := audit.NewEntry(audit.ActionLoginFailed, nil)
+		entry.TargetID = &userID
+		Record(auditSvc, c, entry)
+		return c.SendStatus(http.StatusUnauthorized)
+	})
+	app.Get("/audit", handler.ListEntries)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	other := audit.NewEntry(audit.ActionTenantCreated, nil)
+	auditSvc.Record(other)
+
+	status, response := get(t, app, "/audit")
+	assert.Equal(t, http.StatusOK, status)
+	entries := response["data"].([]interface{})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "tenant.created", entries[0].(map[string]interface{})["action"])
+	assert.Equal(t, float64(2), response["meta"].(map[string]interface{})["pagination"].(map[string]interface{})["total"])
+
+	// The request context is recorded
+	login := entries[1].(map[string]interface{})
+	assert.Equal(t, "auth.login_failed", login["action"])
+	assert.Equal(t, "curl/8.0", login["user_agent"])
+	assert.NotEmpty(t, login["ip"])
+
+	status, response = get(t, app, "/audit?user_id="+userID.String()+"&action=auth")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["data"], 1)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+	status, response = get(t, app, "/audit?from="+tomorrow)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, response["data"])
+
+	status, response = get(t, app, "/audit?user_id=nope")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid user_id: nope", response["message"])
+
+	status, _ = get(t, app, "/audit?to=yesterday")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, response = get(t, app, "/audit?from=2026-02-01&to=2026-01-01")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "from must be before to", response["message"])
+}
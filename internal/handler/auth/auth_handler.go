@@ -1,25 +1,63 @@
 package auth
 
 import (
+	"errors"
+	"log"
+	"math"
+	"strconv"
+
+	"todo-api/internal/domain/audit"
 	"todo-api/internal/domain/auth"
+	auditHandler "todo-api/internal/handler/audit"
+	"todo-api/internal/middleware"
+	"todo-api/internal/response"
+	auditService "todo-api/internal/service/audit"
 	authService "todo-api/internal/service/auth"
-	"todo-api/pkg/config"
+	loginService "todo-api/internal/service/login"
+	notificationService "todo-api/internal/service/notification"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // Handler handles authentication HTTP requests
 type Handler struct {
-	authService authService.Service
+	authService  authService.Service
+	auditService auditService.Service
+	loginService loginService.Service
+	// notifications sends password reset and verification emails; without it, those
+	// endpoints are not implemented
+	notifications notificationService.Service
 }
 
-// NewHandler creates a new auth handler instance
-func NewHandler(config *config.Config) *Handler {
-	// Initialize service
-	authSvc := authService.NewService(config)
+// Deps are the dependencies of an auth handler. Auth is required; the others are optional,
+// and a zero value keeps the default noted next to each.
+type Deps struct {
+	Auth  authService.Service
+	Audit auditService.Service // logins and token refreshes are not recorded
+	// Login throttles logins flagged by the login guard and records them in the audit log,
+	// as the gRPC API does. Logins go through Auth and Audit without a login guard when nil.
+	Login         loginService.Service
+	Notifications notificationService.Service // password reset and verification emails are not implemented
+}
+
+// NewHandler creates a new auth handler instance using an existing auth service
+func NewHandler(authSvc authService.Service) *Handler {
+	return NewHandlerWithDeps(Deps{Auth: authSvc})
+}
+
+// NewHandlerWithDeps creates a new auth handler instance, using the defaults of Deps for the
+// dependencies left unset
+func NewHandlerWithDeps(deps Deps) *Handler {
+	if deps.Login == nil {
+		deps.Login = loginService.NewService(loginService.Deps{Auth: deps.Auth, Audit: deps.Audit})
+	}
 
 	return &Handler{
-		authService: authSvc,
+		authService:   deps.Auth,
+		auditService:  deps.Audit,
+		loginService:  deps.Login,
+		notifications: deps.Notifications,
 	}
 }
 
@@ -29,24 +67,274 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 
 	// Parse request body
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
 			"error":   true,
 			"message": "Invalid request body",
 		})
 	}
 
 	// Login user
-	tokenResponse, err := h.authService.Login(&req)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+	tokenResponse, err := h.loginService.Login(&req, loginService.Client{
+		IP:        middleware.ClientIP(c).String(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	})
+	var throttled *loginService.ThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.Wait.Seconds()))))
+		return response.Send(c, fiber.StatusTooManyRequests, fiber.Map{
+			"error":   true,
+			"message": "Too many login attempts, try again later",
+		})
+	case errors.Is(err, auth.ErrDirectoryUnavailable):
+		return response.Send(c, fiber.StatusServiceUnavailable, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	case err != nil:
+		return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
 			"error":   true,
 			"message": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	return response.Send(c, fiber.StatusOK, fiber.Map{
 		"error":   false,
 		"message": "Login successful",
 		"data":    tokenResponse,
 	})
 }
+
+// Refresh handles exchanging a refresh token for a new token pair
+func (h *Handler) Refresh(c *fiber.Ctx) error {
+	var req auth.RefreshRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Refresh tokens
+	tokenResponse, err := h.authService.Refresh(&req)
+	h.recordRefresh(c, req.RefreshToken, err)
+	if err != nil {
+		return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Token refreshed successfully",
+		"data":    tokenResponse,
+	})
+}
+
+// ListSessions handles listing the clients the current user is logged in from
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Sessions retrieved successfully",
+		"data":    h.authService.ListSessions(userID),
+	})
+}
+
+// RevokeSession handles logging a client of the current user out, so its refresh tokens stop
+// working
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid session ID",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.authService.RevokeSession(userID, id); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Session not found",
+		})
+	}
+
+	entry := audit.NewEntry(audit.ActionSessionRevoked, &userID)
+	entry.TargetID = &id
+	auditHandler.Record(h.auditService, c, entry)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Session revoked successfully",
+	})
+}
+
+// ForgotPassword handles requesting a password reset email. The response is the same
+// whether or not the account exists, so it cannot be used to discover accounts.
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	if h.notifications == nil {
+		return errEmailNotConfigured(c)
+	}
+
+	var req auth.ForgotPasswordRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	if user, token, err := h.authService.CreatePasswordResetToken(req.Email); err == nil {
+		if err := h.notifications.SendPasswordReset(c.Context(), user, token); err != nil {
+			log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+		}
+	}
+
+	return response.Send(c, fiber.StatusAccepted, fiber.Map{
+		"error":   false,
+		"message": "If the account exists, a password reset email has been sent",
+	})
+}
+
+// ResetPassword handles choosing a new password with the token of a password reset email
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	var req auth.ResetPasswordRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	user, err := h.authService.ResetPassword(&req)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	entry := audit.NewEntry(audit.ActionPasswordReset, &user.ID)
+	entry.Subject = user.Email
+	auditHandler.Record(h.auditService, c, entry)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Password reset successfully",
+	})
+}
+
+// SendVerificationEmail handles sending the authenticated user an email to verify their address
+func (h *Handler) SendVerificationEmail(c *fiber.Ctx) error {
+	if h.notifications == nil {
+		return errEmailNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	user, token, err := h.authService.CreateEmailVerificationToken(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusConflict, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	if err := h.notifications.SendEmailVerification(c.Context(), user, token); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+		return response.Send(c, fiber.StatusBadGateway, fiber.Map{
+			"error":   true,
+			"message": "Failed to send verification email",
+		})
+	}
+
+	return response.Send(c, fiber.StatusAccepted, fiber.Map{
+		"error":   false,
+		"message": "Verification email sent",
+	})
+}
+
+// VerifyEmail handles verifying an email address with the token of a verification email
+func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
+	var req auth.VerifyEmailRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	user, err := h.authService.VerifyEmail(&req)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	entry := audit.NewEntry(audit.ActionEmailVerified, &user.ID)
+	entry.Subject = user.Email
+	auditHandler.Record(h.auditService, c, entry)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Email verified successfully",
+		"data":    user,
+	})
+}
+
+// errEmailNotConfigured responds that emails cannot be sent
+func errEmailNotConfigured(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotImplemented, fiber.Map{
+		"error":   true,
+		"message": "Email is not configured",
+	})
+}
+
+// recordRefresh records the outcome of a token refresh in the audit log. The user is only
+// known when the refresh token carries a valid signature.
+func (h *Handler) recordRefresh(c *fiber.Ctx, refreshToken string, refreshErr error) {
+	if h.auditService == nil {
+		return
+	}
+
+	var userID *uuid.UUID
+	var email string
+	if claims, err := h.authService.ValidateToken(refreshToken); err == nil {
+		userID = &claims.UserID
+		email = claims.Email
+	}
+
+	var entry *audit.Entry
+	if refreshErr == nil {
+		entry = audit.NewEntry(audit.ActionTokenRefreshed, userID)
+	} else {
+		entry = audit.NewEntry(audit.ActionTokenRefreshFailed, userID)
+		entry.Details = map[string]string{"reason": refreshErr.Error()}
+	}
+	entry.Subject = email
+
+	auditHandler.Record(h.auditService, c, entry)
+}
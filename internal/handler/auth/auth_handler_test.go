@@ -3,122 +3,109 @@ package auth
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"todo-api/internal/domain/audit"
 	"todo-api/internal/domain/auth"
+	"todo-api/internal/metrics"
+	"todo-api/internal/mocks"
+	auditService "todo-api/internal/service/audit"
+	authService "todo-api/internal/service/auth"
+	loginService "todo-api/internal/service/login"
+	loginGuard "todo-api/internal/service/loginguard"
+	notificationService "todo-api/internal/service/notification"
 	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+	"todo-api/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestNewHandler(t *testing.T) {
-	cfg := &config.Config{
-		JWT: config.JWTConfig{
-			SecretKey:       "test-secret",
-			AccessTokenTTL:  15 * time.Minute,
-			RefreshTokenTTL: 7 * 24 * time.Hour,
-		},
-	}
-
-	handler := NewHandler(cfg)
+	handler := NewHandler(mocks.NewAuthService(t))
 
 	assert.NotNil(t, handler)
 	assert.IsType(t, &Handler{}, handler)
 }
 
-func TestHandler_Login_ValidCredentials(t *testing.T) {
-	cfg := &config.Config{
-		JWT: config.JWTConfig{
-			SecretKey:       "test-secret",
-			AccessTokenTTL:  15 * time.Minute,
-			RefreshTokenTTL: 7 * 24 * time.Hour,
-		},
+// john is the user the mocked auth service knows
+var john = &auth.User{ID: uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"), Email: "john.doe@example.com"}
+
+// postJSON sends the body as JSON to the path of the app
+func postJSON(t *testing.T, app *fiber.App, path string, body interface{}) (*http.Response, map[string]interface{}) {
+	reqBody, _ := json.Marshal(body)
+	httpReq := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
 	}
+	return resp, response
+}
 
-	handler := NewHandler(cfg)
+func TestHandler_Login_ValidCredentials(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	handler := NewHandler(authSvc)
 	app := fiber.New()
 
 	app.Post("/login", handler.Login)
 
-	req := auth.LoginRequest{
+	req := &auth.LoginRequest{
 		Email:    "john.doe@example.com",
 		Password: "password123",
 	}
+	authSvc.On("Login", req).Return(&auth.TokenResponse{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}, nil)
 
-	reqBody, _ := json.Marshal(req)
-	httpReq := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := app.Test(httpReq)
-
-	require.NoError(t, err)
+	resp, response := postJSON(t, app, "/login", req)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-
 	assert.Equal(t, false, response["error"])
 	assert.Equal(t, "Login successful", response["message"])
 	assert.NotNil(t, response["data"])
 
 	data := response["data"].(map[string]interface{})
-	assert.NotEmpty(t, data["access_token"])
-	assert.NotEmpty(t, data["refresh_token"])
+	assert.Equal(t, "access", data["access_token"])
+	assert.Equal(t, "refresh", data["refresh_token"])
 	assert.Equal(t, "Bearer", data["token_type"])
 }
 
 func TestHandler_Login_InvalidCredentials(t *testing.T) {
-	cfg := &config.Config{
-		JWT: config.JWTConfig{
-			SecretKey:       "test-secret",
-			AccessTokenTTL:  15 * time.Minute,
-			RefreshTokenTTL: 7 * 24 * time.Hour,
-		},
-	}
-
-	handler := NewHandler(cfg)
+	authSvc := mocks.NewAuthService(t)
+	handler := NewHandler(authSvc)
 	app := fiber.New()
 
 	app.Post("/login", handler.Login)
 
-	req := auth.LoginRequest{
+	req := &auth.LoginRequest{
 		Email:    "nonexistent@example.com",
 		Password: "wrongpassword",
 	}
+	authSvc.On("Login", req).Return(nil, errors.New("invalid email or password"))
 
-	reqBody, _ := json.Marshal(req)
-	httpReq := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := app.Test(httpReq)
-
-	require.NoError(t, err)
+	resp, response := postJSON(t, app, "/login", req)
 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-
 	assert.Equal(t, true, response["error"])
 	assert.Equal(t, "invalid email or password", response["message"])
 }
 
 func TestHandler_Login_InvalidRequest(t *testing.T) {
-	cfg := &config.Config{
-		JWT: config.JWTConfig{
-			SecretKey:       "test-secret",
-			AccessTokenTTL:  15 * time.Minute,
-			RefreshTokenTTL: 7 * 24 * time.Hour,
-		},
-	}
-
-	handler := NewHandler(cfg)
+	// The service is not called
+	handler := NewHandler(mocks.NewAuthService(t))
 	app := fiber.New()
 
 	app.Post("/login", handler.Login)
@@ -141,15 +128,8 @@ func TestHandler_Login_InvalidRequest(t *testing.T) {
 }
 
 func TestHandler_Login_EmptyBody(t *testing.T) {
-	cfg := &config.Config{
-		JWT: config.JWTConfig{
-			SecretKey:       "test-secret",
-			AccessTokenTTL:  15 * time.Minute,
-			RefreshTokenTTL: 7 * 24 * time.Hour,
-		},
-	}
-
-	handler := NewHandler(cfg)
+	// The service is not called
+	handler := NewHandler(mocks.NewAuthService(t))
 	app := fiber.New()
 
 	app.Post("/login", handler.Login)
@@ -170,16 +150,20 @@ func TestHandler_Login_EmptyBody(t *testing.T) {
 	assert.Equal(t, "Invalid request body", response["message"])
 }
 
-func TestHandler_Login_ValidationErrors(t *testing.T) {
-	cfg := &config.Config{
+// newAuthService creates an auth service with the default fixture users, for the tests
+// checking the handler against the real login rules
+func newAuthService() authService.Service {
+	return authService.NewService(&config.Config{
 		JWT: config.JWTConfig{
 			SecretKey:       "test-secret",
 			AccessTokenTTL:  15 * time.Minute,
 			RefreshTokenTTL: 7 * 24 * time.Hour,
 		},
-	}
+	})
+}
 
-	handler := NewHandler(cfg)
+func TestHandler_Login_ValidationErrors(t *testing.T) {
+	handler := NewHandler(newAuthService())
 	app := fiber.New()
 
 	app.Post("/login", handler.Login)
@@ -225,19 +209,9 @@ func TestHandler_Login_ValidationErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			reqBody, _ := json.Marshal(tt.request)
-			httpReq := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
-			httpReq.Header.Set("Content-Type", "application/json")
-
-			resp, err := app.Test(httpReq)
-
-			require.NoError(t, err)
+			resp, response := postJSON(t, app, "/login", tt.request)
 			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-			var response map[string]interface{}
-			err = json.NewDecoder(resp.Body).Decode(&response)
-			require.NoError(t, err)
-
 			assert.Equal(t, true, response["error"])
 			assert.Equal(t, tt.expected, response["message"])
 		})
@@ -245,15 +219,7 @@ func TestHandler_Login_ValidationErrors(t *testing.T) {
 }
 
 func TestHandler_Login_AllMockUsers(t *testing.T) {
-	cfg := &config.Config{
-		JWT: config.JWTConfig{
-			SecretKey:       "test-secret",
-			AccessTokenTTL:  15 * time.Minute,
-			RefreshTokenTTL: 7 * 24 * time.Hour,
-		},
-	}
-
-	handler := NewHandler(cfg)
+	handler := NewHandler(newAuthService())
 	app := fiber.New()
 
 	app.Post("/login", handler.Login)
@@ -266,26 +232,270 @@ func TestHandler_Login_AllMockUsers(t *testing.T) {
 
 	for _, email := range users {
 		t.Run(email, func(t *testing.T) {
-			req := auth.LoginRequest{
+			resp, response := postJSON(t, app, "/login", auth.LoginRequest{
 				Email:    email,
 				Password: "password123",
-			}
-
-			reqBody, _ := json.Marshal(req)
-			httpReq := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
-			httpReq.Header.Set("Content-Type", "application/json")
-
-			resp, err := app.Test(httpReq)
-
-			require.NoError(t, err)
+			})
 			assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-			var response map[string]interface{}
-			err = json.NewDecoder(resp.Body).Decode(&response)
-			require.NoError(t, err)
-
 			assert.Equal(t, false, response["error"])
 			assert.Equal(t, "Login successful", response["message"])
+
+			data := response["data"].(map[string]interface{})
+			assert.NotEmpty(t, data["access_token"])
+			assert.NotEmpty(t, data["refresh_token"])
 		})
 	}
 }
+
+func TestHandler_Refresh(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	handler := NewHandler(authSvc)
+	app := fiber.New()
+
+	app.Post("/refresh", handler.Refresh)
+
+	req := &auth.RefreshRequest{
+		RefreshToken: "refresh",
+		Scopes:       []string{auth.ScopeTasksRead},
+	}
+	authSvc.On("Refresh", req).Return(&auth.TokenResponse{AccessToken: "access", Scopes: req.Scopes}, nil)
+
+	resp, response := postJSON(t, app, "/refresh", req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, false, response["error"])
+	assert.Equal(t, "Token refreshed successfully", response["message"])
+
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, []interface{}{auth.ScopeTasksRead}, data["scopes"])
+}
+
+func TestHandler_Sessions(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	auditSvc := auditService.NewService()
+	handler := NewHandlerWithDeps(Deps{Auth: authSvc, Audit: auditSvc})
+	app := fiber.New()
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", john.ID)
+		return c.Next()
+	})
+	app.Get("/sessions", handler.ListSessions)
+	app.Delete("/sessions/:id", handler.RevokeSession)
+
+	session := &auth.Session{ID: uuid.New(), UserID: john.ID, ClientID: "phone-1", UserAgent: "TodoApp/2.1 (iOS)"}
+	unknown := uuid.New()
+	authSvc.On("ListSessions", john.ID).Return([]*auth.Session{session})
+	authSvc.On("RevokeSession", john.ID, session.ID).Return(nil)
+	authSvc.On("RevokeSession", john.ID, unknown).Return(auth.ErrSessionNotFound)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/sessions", nil))
+	require.NoError(t, err)
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, session.ID.String(), response.Data[0]["id"])
+	assert.Equal(t, "phone-1", response.Data[0]["client_id"])
+	assert.Equal(t, "TodoApp/2.1 (iOS)", response.Data[0]["user_agent"])
+
+	tests := []struct {
+		id     string
+		status int
+	}{
+		{session.ID.String(), http.StatusOK},
+		{unknown.String(), http.StatusNotFound},
+		{"not-a-uuid", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/sessions/"+tt.id, nil))
+		require.NoError(t, err)
+		assert.Equal(t, tt.status, resp.StatusCode, tt.id)
+	}
+
+	// Only the revocation is audited
+	entries, _ := auditSvc.List(nil, 1, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.ActionSessionRevoked, entries[0].Action)
+	assert.Equal(t, session.ID, *entries[0].TargetID)
+}
+
+func TestHandler_AuditLog(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	auditSvc := auditService.NewService()
+	handler := NewHandlerWithDeps(Deps{Auth: authSvc, Audit: auditSvc})
+	app := fiber.New()
+
+	app.Post("/login", handler.Login)
+	app.Post("/refresh", handler.Refresh)
+
+	wrongPassword := &auth.LoginRequest{Email: john.Email, Password: "wrongpassword"}
+	rightPassword := &auth.LoginRequest{Email: john.Email, Password: "password123"}
+	authSvc.On("GetUserByEmail", john.Email).Return(john, nil)
+	authSvc.On("Login", wrongPassword).Return(nil, errors.New("invalid email or password"))
+	authSvc.On("Login", rightPassword).Return(&auth.TokenResponse{AccessToken: "access", RefreshToken: "refresh"}, nil)
+	authSvc.On("ValidateToken", "refresh").Return(&utils.JWTClaims{UserID: john.ID, Email: john.Email}, nil)
+	authSvc.On("Refresh", &auth.RefreshRequest{RefreshToken: "refresh"}).Return(&auth.TokenResponse{AccessToken: "access"}, nil)
+	authSvc.On("ValidateToken", "not-a-token").Return(nil, errors.New("invalid token"))
+	authSvc.On("Refresh", &auth.RefreshRequest{RefreshToken: "not-a-token"}).Return(nil, errors.New("invalid or expired refresh token"))
+
+	postJSON(t, app, "/login", wrongPassword)
+	postJSON(t, app, "/login", rightPassword)
+	postJSON(t, app, "/refresh", auth.RefreshRequest{RefreshToken: "refresh"})
+	postJSON(t, app, "/refresh", auth.RefreshRequest{RefreshToken: "not-a-token"})
+
+	entries, _ := auditSvc.List(nil, 1, 10)
+	require.Len(t, entries, 4)
+
+	// Newest first
+	assert.Equal(t, audit.ActionTokenRefreshFailed, entries[0].Action)
+	assert.Nil(t, entries[0].ActorID)
+	assert.Equal(t, "invalid or expired refresh token", entries[0].Details["reason"])
+
+	assert.Equal(t, audit.ActionTokenRefreshed, entries[1].Action)
+	assert.Equal(t, john.ID, *entries[1].ActorID)
+
+	assert.Equal(t, audit.ActionLoginSucceeded, entries[2].Action)
+	assert.Equal(t, john.ID, *entries[2].ActorID)
+	assert.Equal(t, "john.doe@example.com", entries[2].Subject)
+
+	// A failed login targets the account without attributing the attempt to its owner
+	assert.Equal(t, audit.ActionLoginFailed, entries[3].Action)
+	assert.Nil(t, entries[3].ActorID)
+	assert.Equal(t, john.ID, *entries[3].TargetID)
+	assert.Equal(t, "invalid email or password", entries[3].Details["reason"])
+}
+
+func TestHandler_LoginThrottling(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	auditSvc := auditService.NewService()
+	guard := loginGuard.NewService(config.LoginGuardConfig{
+		Window:             time.Minute,
+		MaxAccountFailures: 2,
+		FlagDuration:       time.Minute,
+		ThrottleInterval:   time.Minute,
+	}, metrics.NewRegistry())
+	handler := NewHandlerWithDeps(Deps{Auth: authSvc, Audit: auditSvc,
+		Login: loginService.NewService(loginService.Deps{Auth: authSvc, Audit: auditSvc, Guard: guard})})
+	app := fiber.New()
+	app.Post("/login", handler.Login)
+
+	wrongPassword := &auth.LoginRequest{Email: john.Email, Password: "wrongpassword"}
+	rightPassword := &auth.LoginRequest{Email: john.Email, Password: "password123"}
+	authSvc.On("GetUserByEmail", john.Email).Return(john, nil)
+	authSvc.On("Login", wrongPassword).Return(nil, errors.New("invalid email or password")).Twice()
+	authSvc.On("Login", rightPassword).Return(&auth.TokenResponse{AccessToken: "access"}, nil).Once()
+
+	resp, _ := postJSON(t, app, "/login", wrongPassword)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, _ = postJSON(t, app, "/login", wrongPassword)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// The account is flagged: one more attempt is allowed, then attempts must wait without
+	// reaching the auth service
+	resp, _ = postJSON(t, app, "/login", rightPassword)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = postJSON(t, app, "/login", rightPassword)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	entries, _ := auditSvc.List(&audit.Filter{Action: string(audit.ActionAnomalyDetected)}, 1, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "account_failures", entries[0].Details["type"])
+
+	entries, _ = auditSvc.List(nil, 1, 1)
+	assert.Equal(t, "too many login attempts", entries[0].Details["reason"])
+}
+
+func TestHandler_Login_DirectoryUnavailable(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	guard := loginGuard.NewService(config.LoginGuardConfig{
+		Window:             time.Minute,
+		MaxAccountFailures: 1,
+		FlagDuration:       time.Minute,
+		ThrottleInterval:   time.Minute,
+	}, metrics.NewRegistry())
+	auditSvc := auditService.NewService()
+	handler := NewHandlerWithDeps(Deps{Auth: authSvc, Audit: auditSvc,
+		Login: loginService.NewService(loginService.Deps{Auth: authSvc, Audit: auditSvc, Guard: guard})})
+	app := fiber.New()
+	app.Post("/login", handler.Login)
+
+	req := &auth.LoginRequest{Email: john.Email, Password: "password123"}
+	authSvc.On("GetUserByEmail", john.Email).Return(john, nil)
+	authSvc.On("Login", req).Return(nil, fmt.Errorf("%w: connection refused", auth.ErrDirectoryUnavailable))
+
+	// Outages are not held against the account by the login guard
+	for i := 0; i < 3; i++ {
+		resp, _ := postJSON(t, app, "/login", req)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
+
+func TestHandler_PasswordResetAndEmailVerification(t *testing.T) {
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "https://todo.example.com"},
+	}
+
+	authSvc := mocks.NewAuthService(t)
+	auditSvc := auditService.NewService()
+	m := mailer.NewMemoryMailer()
+	notificationSvc := notificationService.NewService(cfg, authSvc, mocks.NewTaskService(t), m)
+	handler := NewHandlerWithDeps(Deps{Auth: authSvc, Audit: auditSvc, Notifications: notificationSvc})
+
+	app := fiber.New()
+	app.Post("/password/forgot", handler.ForgotPassword)
+	app.Post("/password/reset", handler.ResetPassword)
+	app.Post("/email/verification", func(c *fiber.Ctx) error {
+		c.Locals("user_id", john.ID)
+		return c.Next()
+	}, handler.SendVerificationEmail)
+	app.Post("/email/verify", handler.VerifyEmail)
+
+	post := func(path string, body interface{}) int {
+		resp, _ := postJSON(t, app, path, body)
+		return resp.StatusCode
+	}
+	// tokenOf extracts the token of the link in the last email sent
+	tokenOf := func() string {
+		messages := m.Messages()
+		require.NotEmpty(t, messages)
+		for _, field := range strings.Fields(messages[len(messages)-1].Text) {
+			if link, err := url.Parse(field); err == nil && link.Query().Get("token") != "" {
+				return link.Query().Get("token")
+			}
+		}
+		t.Fatal("no link with a token in email")
+		return ""
+	}
+
+	authSvc.On("CreatePasswordResetToken", "nobody@example.com").Return(nil, "", errors.New("user not found"))
+	authSvc.On("CreatePasswordResetToken", john.Email).Return(john, "reset-token", nil)
+	authSvc.On("ResetPassword", &auth.ResetPasswordRequest{Token: "wrong", Password: "new-password"}).
+		Return(nil, errors.New("invalid or expired token"))
+	authSvc.On("ResetPassword", &auth.ResetPasswordRequest{Token: "reset-token", Password: "new-password"}).Return(john, nil)
+	authSvc.On("CreateEmailVerificationToken", john.ID).Return(john, "verify-token", nil).Once()
+	authSvc.On("CreateEmailVerificationToken", john.ID).Return(nil, "", errors.New("email already verified")).Once()
+	authSvc.On("VerifyEmail", &auth.VerifyEmailRequest{Token: "verify-token"}).Return(john, nil)
+
+	// Unknown accounts get the same response without an email
+	assert.Equal(t, http.StatusAccepted, post("/password/forgot", auth.ForgotPasswordRequest{Email: "nobody@example.com"}))
+	assert.Empty(t, m.Messages())
+
+	assert.Equal(t, http.StatusAccepted, post("/password/forgot", auth.ForgotPasswordRequest{Email: john.Email}))
+	assert.Equal(t, "reset-token", tokenOf())
+	assert.Equal(t, http.StatusBadRequest, post("/password/reset", auth.ResetPasswordRequest{Token: "wrong", Password: "new-password"}))
+	assert.Equal(t, http.StatusOK, post("/password/reset", auth.ResetPasswordRequest{Token: "reset-token", Password: "new-password"}))
+
+	assert.Equal(t, http.StatusAccepted, post("/email/verification", nil))
+	assert.Equal(t, http.StatusOK, post("/email/verify", auth.VerifyEmailRequest{Token: tokenOf()}))
+	assert.Equal(t, http.StatusConflict, post("/email/verification", nil))
+
+	entries, _ := auditSvc.List(nil, 1, 10)
+	require.Len(t, entries, 2)
+	assert.Equal(t, audit.ActionEmailVerified, entries[0].Action)
+	assert.Equal(t, audit.ActionPasswordReset, entries[1].Action)
+	assert.Equal(t, john.ID, *entries[1].ActorID)
+}
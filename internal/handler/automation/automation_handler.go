@@ -0,0 +1,233 @@
+package automation
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/automation"
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/response"
+	automationService "todo-api/internal/service/automation"
+	taskService "todo-api/internal/service/task"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles API key management and the trigger and action endpoints of automation
+// platforms such as Zapier and IFTTT. Triggers and actions respond with bare JSON arrays
+// and objects rather than the API envelope, as those platforms expect; errors keep the
+// envelope, which the platforms show by their message.
+type Handler struct {
+	automationService automationService.Service
+	taskService       taskService.Service
+}
+
+// NewHandler creates a new automation handler instance
+func NewHandler(automationSvc automationService.Service, taskSvc taskService.Service) *Handler {
+	return &Handler{
+		automationService: automationSvc,
+		taskService:       taskSvc,
+	}
+}
+
+// CreateAPIKey handles creating an API key. The key is only included in this response.
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	var req automation.CreateAPIKeyRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	created, err := h.automationService.CreateAPIKey(userID, &req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if err.Error() == "api key limit reached" {
+			status = fiber.StatusConflict
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "API key created successfully, store it now as it is not shown again",
+		"data":    created,
+	})
+}
+
+// ListAPIKeys handles retrieving the user's API keys
+func (h *Handler) ListAPIKeys(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "API keys retrieved successfully",
+		"data":    h.automationService.ListAPIKeys(userID),
+	})
+}
+
+// RevokeAPIKey handles revoking an API key
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid API key ID",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.automationService.RevokeAPIKey(userID, id); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "API key not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "API key revoked successfully",
+	})
+}
+
+// Me handles the request automation platforms test credentials with, identifying the user
+// the credentials belong to
+func (h *Handler) Me(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"id":    c.Locals("user_id").(uuid.UUID),
+		"email": c.Locals("user_email").(string),
+	})
+}
+
+// NewTasks handles the polling trigger of newly created tasks, newest first
+func (h *Handler) NewTasks(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	items, err := h.automationService.NewTasks(userID, c.QueryInt("limit"))
+	if err != nil {
+		return errListTasks(c)
+	}
+	return c.JSON(items)
+}
+
+// CompletedTasks handles the polling trigger of completed tasks, most recently completed
+// first
+func (h *Handler) CompletedTasks(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	items, err := h.automationService.CompletedTasks(userID, c.QueryInt("limit"))
+	if err != nil {
+		return errListTasks(c)
+	}
+	return c.JSON(items)
+}
+
+// CreateTask handles the action creating a task
+func (h *Handler) CreateTask(c *fiber.Ctx) error {
+	var req task.CreateTaskRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	created, err := h.taskService.CreateTask(&req, userID)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, billing.ErrUpgradeRequired) {
+			status = fiber.StatusPaymentRequired
+		} else if errors.Is(err, task.ErrLimitExceeded) {
+			status = fiber.StatusTooManyRequests
+		} else if errors.Is(err, tenant.ErrQuotaExceeded) {
+			status = fiber.StatusForbidden
+		} else if errors.Is(err, task.ErrStorageFull) {
+			status = fiber.StatusInsufficientStorage
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// CompleteTask handles the action completing a task. Completing a task that is already
+// completed succeeds, so actions retried by the platform do not fail.
+func (h *Handler) CompleteTask(c *fiber.Ctx) error {
+	var req automation.CompleteTaskRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	completed, err := h.taskService.CompleteTask(req.TaskID, userID)
+	if errors.Is(err, task.ErrInvalidTransition) {
+		if existing, getErr := h.taskService.GetTaskByID(req.TaskID, userID); getErr == nil && existing.Status == task.StatusCompleted {
+			return c.JSON(existing)
+		}
+	}
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Task not found",
+			})
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusConflict, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(completed)
+}
+
+// errListTasks responds that the tasks of a trigger could not be listed
+func errListTasks(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+		"error":   true,
+		"message": "Failed to list tasks",
+	})
+}
@@ -0,0 +1,165 @@
+package automation
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authDomain "todo-api/internal/domain/auth"
+	"todo-api/internal/events"
+	"todo-api/internal/middleware"
+	"todo-api/internal/service/auth"
+	automationService "todo-api/internal/service/automation"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+func setupTestApp(t *testing.T) (*fiber.App, *config.Config) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "https://todo.example.com"},
+		JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute},
+	}
+	authSvc := auth.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	automationSvc := automationService.NewService(cfg, authSvc, taskSvc)
+	handler := NewHandler(automationSvc, taskSvc)
+
+	canRead := middleware.RequireScope(authDomain.ScopeTasksRead)
+	canWrite := middleware.RequireScope(authDomain.ScopeTasksWrite)
+
+	app := fiber.New()
+	me := app.Group("/me", middleware.AuthMiddleware(authSvc))
+	me.Get("/api-keys", canRead, handler.ListAPIKeys)
+	me.Post("/api-keys", canWrite, handler.CreateAPIKey)
+	me.Delete("/api-keys/:id", canWrite, handler.RevokeAPIKey)
+	me.Get("/automation-rules", canRead, handler.ListRules)
+	me.Post("/automation-rules", canWrite, handler.CreateRule)
+	me.Get("/automation-rules/:id", canRead, handler.GetRule)
+	me.Put("/automation-rules/:id", canWrite, handler.UpdateRule)
+	me.Delete("/automation-rules/:id", canWrite, handler.DeleteRule)
+	me.Get("/automation-rules/:id/executions", canRead, handler.ListExecutions)
+
+	automations := app.Group("/automations", middleware.APIKeyAuth(authSvc, automationSvc))
+	automations.Get("/me", canRead, handler.Me)
+	automations.Get("/triggers/new-task", canRead, handler.NewTasks)
+	automations.Get("/triggers/completed-task", canRead, handler.CompletedTasks)
+	automations.Post("/actions/create-task", canWrite, handler.CreateTask)
+	automations.Post("/actions/complete-task", canWrite, handler.CompleteTask)
+	return app, cfg
+}
+
+// request sends a request authenticated with the header and returns the response with its
+// decoded body
+func request(t *testing.T, app *fiber.App, method, target, header, credential string, body interface{}) (*http.Response, interface{}) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(header, credential)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	var result interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return resp, result
+}
+
+// createKey creates an API key for John with the scopes and returns it
+func createKey(t *testing.T, app *fiber.App, token string, scopes ...string) (string, string) {
+	resp, result := request(t, app, http.MethodPost, "/me/api-keys", "Authorization", "Bearer "+token, fiber.Map{"name": "Zapier", "scopes": scopes})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	data := result.(map[string]interface{})["data"].(map[string]interface{})
+	return data["key"].(string), data["id"].(string)
+}
+
+func TestHandler_APIKeys(t *testing.T) {
+	app, cfg := setupTestApp(t)
+	token, err := utils.GenerateToken(cfg.JWT.SecretKey, johnID, "john.doe@example.com", time.Minute)
+	require.NoError(t, err)
+
+	resp, _ := request(t, app, http.MethodPost, "/me/api-keys", "Authorization", "Bearer "+token, fiber.Map{"name": ""})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	key, id := createKey(t, app, token)
+
+	// Keys authenticate automation endpoints only
+	resp, result := request(t, app, http.MethodGet, "/automations/me", middleware.APIKeyHeader, key, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, map[string]interface{}{"id": johnID.String(), "email": "john.doe@example.com"}, result)
+
+	resp, _ = request(t, app, http.MethodGet, "/me/api-keys", middleware.APIKeyHeader, key, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Tokens are accepted too
+	resp, _ = request(t, app, http.MethodGet, "/automations/me", "Authorization", "Bearer "+token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, result = request(t, app, http.MethodGet, "/me/api-keys", "Authorization", "Bearer "+token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	keys := result.(map[string]interface{})["data"].([]interface{})
+	require.Len(t, keys, 1)
+	assert.NotContains(t, keys[0], "key")
+
+	resp, _ = request(t, app, http.MethodDelete, "/me/api-keys/"+id, "Authorization", "Bearer "+token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = request(t, app, http.MethodGet, "/automations/me", middleware.APIKeyHeader, key, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHandler_TriggersAndActions(t *testing.T) {
+	app, cfg := setupTestApp(t)
+	token, err := utils.GenerateToken(cfg.JWT.SecretKey, johnID, "john.doe@example.com", time.Minute)
+	require.NoError(t, err)
+	key, _ := createKey(t, app, token)
+
+	resp, result := request(t, app, http.MethodPost, "/automations/actions/create-task", middleware.APIKeyHeader, key, fiber.Map{"title": "File taxes"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	taskID := result.(map[string]interface{})["id"].(string)
+
+	// Triggers respond with bare arrays of items deduplicated by their ID
+	resp, result = request(t, app, http.MethodGet, "/automations/triggers/new-task?limit=1", middleware.APIKeyHeader, key, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	items := result.([]interface{})
+	require.Len(t, items, 1)
+	assert.Equal(t, taskID, items[0].(map[string]interface{})["id"])
+
+	resp, result = request(t, app, http.MethodPost, "/automations/actions/complete-task", middleware.APIKeyHeader, key, fiber.Map{"task_id": taskID})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "completed", result.(map[string]interface{})["status"])
+
+	// Completing it again succeeds, so retried actions do not fail
+	resp, _ = request(t, app, http.MethodPost, "/automations/actions/complete-task", middleware.APIKeyHeader, key, fiber.Map{"task_id": taskID})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = request(t, app, http.MethodPost, "/automations/actions/complete-task", middleware.APIKeyHeader, key, fiber.Map{"task_id": uuid.New()})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, result = request(t, app, http.MethodGet, "/automations/triggers/completed-task", middleware.APIKeyHeader, key, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	item := result.([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, taskID, item["task_id"])
+	assert.NotEqual(t, taskID, item["id"])
+
+	// Keys are limited to their scopes
+	readOnly, _ := createKey(t, app, token, authDomain.ScopeTasksRead)
+	resp, _ = request(t, app, http.MethodPost, "/automations/actions/create-task", middleware.APIKeyHeader, readOnly, fiber.Map{"title": "File taxes"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,170 @@
+package automation
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/automation"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ListRules handles listing the user's automation rules
+func (h *Handler) ListRules(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Automation rules retrieved successfully",
+		"data":    h.automationService.ListRules(userID),
+	})
+}
+
+// CreateRule handles creating an automation rule
+func (h *Handler) CreateRule(c *fiber.Ctx) error {
+	var req automation.RuleRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	rule, err := h.automationService.CreateRule(userID, &req)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Automation rule created successfully",
+		"data":    rule,
+	})
+}
+
+// GetRule handles retrieving an automation rule
+func (h *Handler) GetRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidRuleID(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	rule, err := h.automationService.GetRule(userID, id)
+	if err != nil {
+		return sendRuleError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Automation rule retrieved successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule handles replacing the settings of an automation rule
+func (h *Handler) UpdateRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidRuleID(c)
+	}
+
+	var req automation.RuleRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	rule, err := h.automationService.UpdateRule(userID, id, &req)
+	if err != nil {
+		return sendRuleError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Automation rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule handles deleting an automation rule
+func (h *Handler) DeleteRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidRuleID(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.automationService.DeleteRule(userID, id); err != nil {
+		return sendRuleError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Automation rule deleted successfully",
+	})
+}
+
+// ListExecutions handles listing the recent executions of an automation rule, newest first
+func (h *Handler) ListExecutions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidRuleID(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	executions, err := h.automationService.ListExecutions(userID, id)
+	if err != nil {
+		return sendRuleError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Automation rule executions retrieved successfully",
+		"data":    executions,
+	})
+}
+
+// sendRuleError responds with the status of the error: 404 for unknown rules and 400
+// otherwise
+func sendRuleError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	if errors.Is(err, automation.ErrRuleNotFound) {
+		status = fiber.StatusNotFound
+	}
+	return response.Send(c, status, fiber.Map{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
+
+// invalidRuleID responds to a malformed rule ID
+func invalidRuleID(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+		"error":   true,
+		"message": "Invalid rule ID",
+	})
+}
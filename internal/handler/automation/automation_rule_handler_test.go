@@ -0,0 +1,65 @@
+package automation
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Rules(t *testing.T) {
+	app, cfg := setupTestApp(t)
+	token, err := utils.GenerateToken(cfg.JWT.SecretKey, johnID, "john.doe@example.com", time.Minute)
+	require.NoError(t, err)
+	send := func(method, target string, body interface{}) (*http.Response, map[string]interface{}) {
+		resp, result := request(t, app, method, target, "Authorization", "Bearer "+token, body)
+		return resp, result.(map[string]interface{})
+	}
+
+	rule := fiber.Map{
+		"name":       "Archive done",
+		"trigger":    fiber.Map{"event": "task.updated", "field": "status"},
+		"conditions": []fiber.Map{{"field": "status", "operator": "equals", "value": "completed"}},
+		"actions":    []fiber.Map{{"type": "archive", "after": "168h"}},
+	}
+
+	resp, result := send(http.MethodPost, "/me/automation-rules", fiber.Map{"name": "Broken", "trigger": fiber.Map{"event": "task.deleted"}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid trigger event: task.deleted", result["message"])
+
+	resp, result = send(http.MethodPost, "/me/automation-rules", rule)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, true, data["enabled"])
+	assert.NotContains(t, data, "user_id")
+	id := data["id"].(string)
+
+	resp, result = send(http.MethodGet, "/me/automation-rules", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, result["data"], 1)
+
+	rule["enabled"] = false
+	resp, result = send(http.MethodPut, "/me/automation-rules/"+id, rule)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, false, result["data"].(map[string]interface{})["enabled"])
+
+	resp, result = send(http.MethodGet, "/me/automation-rules/"+id+"/executions", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []interface{}{}, result["data"])
+
+	resp, _ = send(http.MethodGet, "/me/automation-rules/"+uuid.NewString(), nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = send(http.MethodDelete, "/me/automation-rules/not-a-uuid", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = send(http.MethodDelete, "/me/automation-rules/"+id, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = send(http.MethodGet, "/me/automation-rules/"+id, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
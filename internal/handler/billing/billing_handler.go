@@ -0,0 +1,95 @@
+package billing
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/response"
+	billingService "todo-api/internal/service/billing"
+	"todo-api/pkg/stripe"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests about the subscription plans of users
+type Handler struct {
+	billingService billingService.Service
+}
+
+// NewHandler creates a new billing handler instance
+func NewHandler(billingSvc billingService.Service) *Handler {
+	return &Handler{
+		billingService: billingSvc,
+	}
+}
+
+// GetSubscription handles retrieving the user's plan and its limits
+func (h *Handler) GetSubscription(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	subscription, err := h.billingService.GetSubscription(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "User not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Subscription retrieved successfully",
+		"data":    subscription,
+	})
+}
+
+// CreateCheckoutSession handles starting the upgrade to the pro plan. The user is sent to
+// the returned URL to pay, and upgraded once Stripe reports the payment to the webhook.
+func (h *Handler) CreateCheckoutSession(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	session, err := h.billingService.CreateCheckoutSession(c.UserContext(), userID)
+	if response.TimedOut(c, err) {
+		return c.UserContext().Err()
+	}
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, billing.ErrAlreadySubscribed):
+			status = fiber.StatusConflict
+		case errors.Is(err, billing.ErrNotEnabled):
+			status = fiber.StatusNotImplemented
+		case errors.Is(err, billing.ErrPaymentProviderUnavailable):
+			status = fiber.StatusBadGateway
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Checkout session created successfully",
+		"data":    session,
+	})
+}
+
+// StripeWebhook handles events Stripe sends about checkouts and subscriptions. The request
+// is authenticated by its signature rather than a token; Stripe retries events until they
+// are acknowledged.
+func (h *Handler) StripeWebhook(c *fiber.Ctx) error {
+	if err := h.billingService.HandleWebhook(c.Body(), c.Get(stripe.SignatureHeader)); err != nil {
+		status, message := fiber.StatusBadRequest, err.Error()
+		if errors.Is(err, stripe.ErrInvalidSignature) {
+			status, message = fiber.StatusUnauthorized, "Invalid request signature"
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error":   true,
+			"message": message,
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
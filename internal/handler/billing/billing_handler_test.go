@@ -0,0 +1,121 @@
+package billing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/service/auth"
+	billingService "todo-api/internal/service/billing"
+	"todo-api/pkg/config"
+	"todo-api/pkg/stripe"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookSecret = "whsec_test"
+
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+// setupTestApp sets up the app paying through the Stripe API served at the URL
+func setupTestApp(t *testing.T, stripeURL string) *fiber.App {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute},
+		Billing: config.BillingConfig{
+			SecretKey:           "sk_test",
+			WebhookSecret:       webhookSecret,
+			ProPriceID:          "price_pro",
+			APIURL:              stripeURL,
+			Timeout:             time.Second,
+			SuccessURL:          "https://todo.example.com/billing/success",
+			CancelURL:           "https://todo.example.com/billing",
+			FreeMaxTasks:        100,
+			FreeMaxIntegrations: 1,
+		},
+	}
+	handler := NewHandler(billingService.NewService(cfg, auth.NewService(cfg), cfg.Billing.NewClient(nil)))
+
+	app := fiber.New()
+	app.Post("/integrations/stripe/webhook", handler.StripeWebhook)
+	me := app.Group("/me", func(c *fiber.Ctx) error {
+		c.Locals("user_id", johnID)
+		return c.Next()
+	})
+	me.Get("/billing", handler.GetSubscription)
+	me.Post("/billing/checkout", handler.CreateCheckoutSession)
+	return app
+}
+
+// send sends a request and returns the response with its decoded body
+func send(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, map[string]interface{}) {
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	if resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	}
+	return resp, body
+}
+
+func TestHandler_Upgrade(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, johnID.String(), r.PostForm.Get("client_reference_id"))
+		w.Write([]byte(`{"id":"cs_1","url":"https://checkout.stripe.com/c/pay/cs_1"}`))
+	}))
+	defer server.Close()
+	app := setupTestApp(t, server.URL)
+
+	resp, body := send(t, app, httptest.NewRequest(http.MethodGet, "/me/billing", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, "free", data["plan"])
+	assert.Equal(t, float64(100), data["limits"].(map[string]interface{})["max_tasks"])
+
+	resp, body = send(t, app, httptest.NewRequest(http.MethodPost, "/me/billing/checkout", nil))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "https://checkout.stripe.com/c/pay/cs_1", body["data"].(map[string]interface{})["url"])
+
+	payload := []byte(`{"id":"evt_1","type":"checkout.session.completed","data":{"object":{"id":"cs_1",
+		"client_reference_id":"` + johnID.String() + `","customer":"cus_1","subscription":"sub_1"}}}`)
+	req := httptest.NewRequest(http.MethodPost, "/integrations/stripe/webhook", bytes.NewReader(payload))
+	req.Header.Set(stripe.SignatureHeader, stripe.SignatureHeaderValue(payload, webhookSecret, time.Now()))
+	resp, _ = send(t, app, req)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, body = send(t, app, httptest.NewRequest(http.MethodGet, "/me/billing", nil))
+	assert.Equal(t, "pro", body["data"].(map[string]interface{})["plan"])
+
+	resp, _ = send(t, app, httptest.NewRequest(http.MethodPost, "/me/billing/checkout", nil))
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestHandler_CheckoutUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	app := setupTestApp(t, server.URL)
+
+	resp, body := send(t, app, httptest.NewRequest(http.MethodPost, "/me/billing/checkout", nil))
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, "payment provider is unavailable, try again later", body["message"])
+}
+
+func TestHandler_StripeWebhook_InvalidSignature(t *testing.T) {
+	app := setupTestApp(t, stripe.DefaultAPIURL)
+
+	payload := []byte(`{"id":"evt_1","type":"checkout.session.completed"}`)
+	req := httptest.NewRequest(http.MethodPost, "/integrations/stripe/webhook", bytes.NewReader(payload))
+	req.Header.Set(stripe.SignatureHeader, stripe.SignatureHeaderValue(payload, "whsec_other", time.Now()))
+	resp, body := send(t, app, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "Invalid request signature", body["message"])
+}
@@ -0,0 +1,162 @@
+package escalation
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/escalation"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/response"
+	escalationService "todo-api/internal/service/escalation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles the escalation rules of workspaces. Routes expect the workspace_id and
+// workspace_member locals set by the WorkspaceMember middleware.
+type Handler struct {
+	escalationService escalationService.Service
+}
+
+// NewHandler creates a new escalation handler instance
+func NewHandler(escalationSvc escalationService.Service) *Handler {
+	return &Handler{
+		escalationService: escalationSvc,
+	}
+}
+
+// ListRules handles listing the escalation rules of a workspace
+func (h *Handler) ListRules(c *fiber.Ctx) error {
+	rules := h.escalationService.ListRules(workspaceID(c))
+	if rules == nil {
+		rules = []*escalation.Rule{}
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Escalation rules retrieved successfully",
+		"data":    rules,
+	})
+}
+
+// CreateRule handles creating an escalation rule in a workspace
+func (h *Handler) CreateRule(c *fiber.Ctx) error {
+	var req escalation.RuleRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	rule, err := h.escalationService.CreateRule(workspaceID(c), &req, member(c).UserID)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Escalation rule created successfully",
+		"data":    rule,
+	})
+}
+
+// GetRule handles retrieving an escalation rule of a workspace
+func (h *Handler) GetRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("ruleId"))
+	if err != nil {
+		return invalidRuleID(c)
+	}
+
+	rule, err := h.escalationService.GetRule(workspaceID(c), id)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Escalation rule retrieved successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule handles replacing the settings of an escalation rule of a workspace
+func (h *Handler) UpdateRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("ruleId"))
+	if err != nil {
+		return invalidRuleID(c)
+	}
+
+	var req escalation.RuleRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	rule, err := h.escalationService.UpdateRule(workspaceID(c), id, &req)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Escalation rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule handles deleting an escalation rule of a workspace
+func (h *Handler) DeleteRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("ruleId"))
+	if err != nil {
+		return invalidRuleID(c)
+	}
+
+	if err := h.escalationService.DeleteRule(workspaceID(c), id); err != nil {
+		return sendError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Escalation rule deleted successfully",
+	})
+}
+
+// sendError responds with the status of the error: 404 for unknown rules and 400 otherwise
+func sendError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	if errors.Is(err, escalation.ErrRuleNotFound) {
+		status = fiber.StatusNotFound
+	}
+	return response.Send(c, status, fiber.Map{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
+
+// invalidRuleID responds to a malformed rule ID
+func invalidRuleID(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+		"error":   true,
+		"message": "Invalid rule ID",
+	})
+}
+
+// workspaceID returns the workspace resolved by the WorkspaceMember middleware
+func workspaceID(c *fiber.Ctx) uuid.UUID {
+	return c.Locals("workspace_id").(uuid.UUID)
+}
+
+// member returns the caller's membership resolved by the WorkspaceMember middleware
+func member(c *fiber.Ctx) *workspace.Member {
+	return c.Locals("workspace_member").(*workspace.Member)
+}
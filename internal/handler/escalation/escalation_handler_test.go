@@ -0,0 +1,113 @@
+package escalation
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/middleware"
+	"todo-api/internal/service/auth"
+	escalationService "todo-api/internal/service/escalation"
+	notificationService "todo-api/internal/service/notification"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	johnID = "3484ec33-20f9-4993-a25f-f49f6f5dbe54"
+	janeID = "550e8400-e29b-41d4-a716-446655440002"
+)
+
+func TestHandler_Rules(t *testing.T) {
+	cfg := &config.Config{}
+	authSvc := auth.NewService(cfg)
+	workspaceSvc := workspaceService.NewService(authSvc)
+	taskSvc := taskService.NewService(authSvc)
+	notificationSvc := notificationService.NewService(cfg, authSvc, taskSvc, mailer.NewMemoryMailer())
+	handler := NewHandler(escalationService.NewService(authSvc, taskSvc, workspaceSvc, notificationSvc))
+
+	// John owns the workspace, where Jane is a member
+	created, err := workspaceSvc.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, uuid.MustParse(johnID))
+	require.NoError(t, err)
+	owner, err := workspaceSvc.GetMember(created.ID, uuid.MustParse(johnID))
+	require.NoError(t, err)
+	invitation, err := workspaceSvc.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "jane.smith@example.com"}, owner)
+	require.NoError(t, err)
+	_, err = workspaceSvc.AcceptInvitation(invitation.ID, uuid.MustParse(janeID), "jane.smith@example.com")
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse(c.Get("X-User-ID", johnID)))
+		return c.Next()
+	})
+	scoped := app.Group("/workspaces/:wid", middleware.WorkspaceMember(workspaceSvc))
+	isAdmin := middleware.RequireWorkspaceRole(workspace.RoleAdmin)
+	scoped.Get("/escalation-rules", handler.ListRules)
+	scoped.Post("/escalation-rules", isAdmin, handler.CreateRule)
+	scoped.Get("/escalation-rules/:ruleId", handler.GetRule)
+	scoped.Put("/escalation-rules/:ruleId", isAdmin, handler.UpdateRule)
+	scoped.Delete("/escalation-rules/:ruleId", isAdmin, handler.DeleteRule)
+
+	send := func(method, path, body, userID string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, "/workspaces/"+created.ID.String()+path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	rule := `{"name":"Urgent overdue","priority":"high","overdue_by":"24h","notify":["assignee"],"cc":["project_owner"]}`
+
+	// Only admins manage rules
+	status, _ := send(http.MethodPost, "/escalation-rules", rule, janeID)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, response := send(http.MethodPost, "/escalation-rules", `{"name":"Late","overdue_by":"soon","notify":["assignee"]}`, johnID)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid overdue_by: soon", response["message"])
+
+	status, response = send(http.MethodPost, "/escalation-rules", rule, johnID)
+	require.Equal(t, http.StatusCreated, status)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "high", data["priority"])
+	assert.Equal(t, []interface{}{"project_owner"}, data["cc"])
+	assert.Equal(t, true, data["enabled"])
+	id := data["id"].(string)
+
+	// Members see the rules of the workspace
+	status, response = send(http.MethodGet, "/escalation-rules", "", janeID)
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["data"], 1)
+
+	status, response = send(http.MethodPut, "/escalation-rules/"+id, `{"name":"Paused","overdue_by":"48h","notify":["creator"],"enabled":false}`, johnID)
+	require.Equal(t, http.StatusOK, status)
+	data = response["data"].(map[string]interface{})
+	assert.Equal(t, false, data["enabled"])
+	assert.Equal(t, []interface{}{}, data["cc"])
+
+	status, _ = send(http.MethodGet, "/escalation-rules/"+uuid.NewString(), "", janeID)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = send(http.MethodDelete, "/escalation-rules/not-a-uuid", "", johnID)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = send(http.MethodDelete, "/escalation-rules/"+id, "", johnID)
+	assert.Equal(t, http.StatusOK, status)
+	status, response = send(http.MethodGet, "/escalation-rules", "", johnID)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{}, response["data"])
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"todo-api/internal/domain/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+)
+
+// contextKey is the type for values stored in the resolver context
+type contextKey string
+
+const (
+	userIDKey     contextKey = "user_id"
+	userClaimsKey contextKey = "user_claims"
+)
+
+// Handler handles GraphQL HTTP requests
+type Handler struct {
+	taskService taskService.Service
+	schema      graphql.Schema
+}
+
+// Request represents a GraphQL request body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// NewHandler creates a new GraphQL handler instance
+func NewHandler(taskSvc taskService.Service) (*Handler, error) {
+	h := &Handler{
+		taskService: taskSvc,
+	}
+
+	schema, err := h.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	h.schema = schema
+
+	return h, nil
+}
+
+// Handle executes a GraphQL query or mutation
+func (h *Handler) Handle(c *fiber.Ctx) error {
+	var req Request
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil || req.Query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"errors": []fiber.Map{{"message": "Invalid request body"}},
+		})
+	}
+
+	// Pass the authenticated user to resolvers
+	ctx := context.WithValue(c.UserContext(), userIDKey, c.Locals("user_id"))
+	ctx = context.WithValue(ctx, userClaimsKey, c.Locals("user_claims"))
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// authorize returns the authenticated user ID if the token grants the scope
+func authorize(ctx context.Context, scope string) (uuid.UUID, error) {
+	userID, ok := ctx.Value(userIDKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, errors.New("authentication required")
+	}
+
+	if claims, ok := ctx.Value(userClaimsKey).(*utils.JWTClaims); ok && !claims.HasScope(scope) {
+		return uuid.Nil, errors.New("insufficient scope: " + scope + " required")
+	}
+
+	return userID, nil
+}
+
+// readScope and writeScope are the scopes required by queries and mutations
+const (
+	readScope  = auth.ScopeTasksRead
+	writeScope = auth.ScopeTasksWrite
+)
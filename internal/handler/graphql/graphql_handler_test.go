@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestApp(t *testing.T, scopes ...string) *fiber.App {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := authService.NewService(cfg)
+	handler, err := NewHandler(taskService.NewService(authSvc))
+	require.NoError(t, err)
+
+	app := fiber.New()
+
+	// Mock auth middleware for testing
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		c.Locals("user_email", "john.doe@example.com")
+		c.Locals("user_claims", &utils.JWTClaims{Scopes: scopes})
+		return c.Next()
+	})
+
+	app.Post("/graphql", handler.Handle)
+
+	return app
+}
+
+func doGraphQL(t *testing.T, app *fiber.App, query string, variables map[string]interface{}) map[string]interface{} {
+	reqBody, _ := json.Marshal(Request{Query: query, Variables: variables})
+	httpReq := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+
+	return response
+}
+
+func TestHandler_CreateAndQueryTask(t *testing.T) {
+	app := setupTestApp(t)
+
+	created := doGraphQL(t, app, `mutation($title: String!) {
+		createTask(title: $title) { id title status }
+	}`, map[string]interface{}{"title": "GraphQL Task"})
+
+	require.Nil(t, created["errors"])
+	createdTask := created["data"].(map[string]interface{})["createTask"].(map[string]interface{})
+	assert.Equal(t, "GraphQL Task", createdTask["title"])
+	assert.Equal(t, "PENDING", createdTask["status"])
+
+	// Fetch the task with its history in one round trip
+	fetched := doGraphQL(t, app, `query($id: ID!) {
+		task(id: $id) { id title history { action } }
+	}`, map[string]interface{}{"id": createdTask["id"]})
+
+	require.Nil(t, fetched["errors"])
+	fetchedTask := fetched["data"].(map[string]interface{})["task"].(map[string]interface{})
+	assert.Equal(t, createdTask["id"], fetchedTask["id"])
+
+	history := fetchedTask["history"].([]interface{})
+	require.Len(t, history, 1)
+	assert.Equal(t, "created", history[0].(map[string]interface{})["action"])
+}
+
+func TestHandler_ListTasks(t *testing.T) {
+	app := setupTestApp(t)
+
+	response := doGraphQL(t, app, `{
+		tasks(status: IN_PROGRESS, sortField: "title", sortOrder: "asc", limit: 5) {
+			data { title status }
+			pagination { page limit total totalPages }
+		}
+	}`, nil)
+
+	require.Nil(t, response["errors"])
+	tasks := response["data"].(map[string]interface{})["tasks"].(map[string]interface{})
+
+	data := tasks["data"].([]interface{})
+	require.Len(t, data, 1)
+	assert.Equal(t, "Complete project documentation", data[0].(map[string]interface{})["title"])
+
+	pagination := tasks["pagination"].(map[string]interface{})
+	assert.Equal(t, float64(5), pagination["limit"])
+	assert.Equal(t, float64(1), pagination["total"])
+}
+
+func TestHandler_UpdateAndDeleteTask(t *testing.T) {
+	app := setupTestApp(t)
+
+	created := doGraphQL(t, app, `mutation { createTask(title: "To Update") { id } }`, nil)
+	taskID := created["data"].(map[string]interface{})["createTask"].(map[string]interface{})["id"]
+
+	updated := doGraphQL(t, app, `mutation($id: ID!) {
+		updateTask(id: $id, status: COMPLETED) { status }
+	}`, map[string]interface{}{"id": taskID})
+
+	require.Nil(t, updated["errors"])
+	assert.Equal(t, "COMPLETED", updated["data"].(map[string]interface{})["updateTask"].(map[string]interface{})["status"])
+
+	deleted := doGraphQL(t, app, `mutation($id: ID!) { deleteTask(id: $id) }`, map[string]interface{}{"id": taskID})
+
+	require.Nil(t, deleted["errors"])
+	assert.Equal(t, true, deleted["data"].(map[string]interface{})["deleteTask"])
+
+	// The task is gone
+	missing := doGraphQL(t, app, `query($id: ID!) { task(id: $id) { id } }`, map[string]interface{}{"id": taskID})
+	errs := missing["errors"].([]interface{})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "task not found", errs[0].(map[string]interface{})["message"])
+}
+
+func TestHandler_MutationRequiresWriteScope(t *testing.T) {
+	app := setupTestApp(t, auth.ScopeTasksRead)
+
+	response := doGraphQL(t, app, `mutation { createTask(title: "Denied") { id } }`, nil)
+
+	errs := response["errors"].([]interface{})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "insufficient scope: tasks:write required", errs[0].(map[string]interface{})["message"])
+}
+
+func TestHandler_InvalidRequest(t *testing.T) {
+	app := setupTestApp(t)
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString("{}"))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(httpReq)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
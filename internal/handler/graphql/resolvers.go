@@ -0,0 +1,136 @@
+package graphql
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+)
+
+// resolveTasks resolves the tasks query with filtering, sorting, and pagination
+func (h *Handler) resolveTasks(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := authorize(p.Context, readScope)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter *task.TaskFilter
+	status, hasStatus := p.Args["status"].(task.TaskStatus)
+	search, _ := p.Args["search"].(string)
+	if hasStatus || search != "" {
+		filter = &task.TaskFilter{Search: search}
+		if hasStatus {
+			filter.Status = &status
+		}
+	}
+
+	sortField, _ := p.Args["sortField"].(string)
+	sortOrder, _ := p.Args["sortOrder"].(string)
+	sort := task.NewTaskSort(sortField, sortOrder)
+
+	// Apply the same pagination bounds as the REST API
+	page, _ := p.Args["page"].(int)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := p.Args["limit"].(int)
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	tasks, pagination, err := h.taskService.ListTasks(filter, sort, page, limit, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"data": tasks,
+		"pagination": map[string]interface{}{
+			"page":       pagination.Page,
+			"limit":      pagination.Limit,
+			"total":      pagination.Total,
+			"totalPages": pagination.TotalPages,
+		},
+	}, nil
+}
+
+// resolveTask resolves a single task by ID
+func (h *Handler) resolveTask(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := authorize(p.Context, readScope)
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	return h.taskService.GetTaskByID(taskID, userID)
+}
+
+// resolveCreateTask resolves the createTask mutation
+func (h *Handler) resolveCreateTask(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := authorize(p.Context, writeScope)
+	if err != nil {
+		return nil, err
+	}
+
+	title, _ := p.Args["title"].(string)
+
+	return h.taskService.CreateTask(&task.CreateTaskRequest{Title: title}, userID)
+}
+
+// resolveUpdateTask resolves the updateTask mutation
+func (h *Handler) resolveUpdateTask(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := authorize(p.Context, writeScope)
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	var req task.UpdateTaskRequest
+	if title, ok := p.Args["title"].(string); ok {
+		req.Title = &title
+	}
+	if status, ok := p.Args["status"].(task.TaskStatus); ok {
+		req.Status = &status
+	}
+
+	return h.taskService.UpdateTask(taskID, &req, userID)
+}
+
+// resolveDeleteTask resolves the deleteTask mutation
+func (h *Handler) resolveDeleteTask(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := authorize(p.Context, writeScope)
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.taskService.DeleteTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	return true, nil
+}
+
+// parseID parses a task ID argument
+func parseID(arg interface{}) (uuid.UUID, error) {
+	idStr, _ := arg.(string)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid task ID")
+	}
+	return id, nil
+}
@@ -0,0 +1,169 @@
+package graphql
+
+import (
+	"time"
+
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+)
+
+// buildSchema builds the GraphQL schema backed by the task service
+func (h *Handler) buildSchema() (graphql.Schema, error) {
+	statusEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "TaskStatus",
+		Values: graphql.EnumValueConfigMap{
+			"PENDING":     &graphql.EnumValueConfig{Value: task.StatusPending},
+			"IN_PROGRESS": &graphql.EnumValueConfig{Value: task.StatusInProgress},
+			"COMPLETED":   &graphql.EnumValueConfig{Value: task.StatusCompleted},
+			"CANCELLED":   &graphql.EnumValueConfig{Value: task.StatusCancelled},
+		},
+	})
+
+	activityType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ActivityEntry",
+		Fields: graphql.Fields{
+			"id":        idField(func(src interface{}) uuid.UUID { return src.(*activity.Entry).ID }),
+			"actorId":   idField(func(src interface{}) uuid.UUID { return src.(*activity.Entry).ActorID }),
+			"action":    stringField(func(src interface{}) string { return string(src.(*activity.Entry).Action) }),
+			"field":     stringField(func(src interface{}) string { return src.(*activity.Entry).Field }),
+			"oldValue":  stringField(func(src interface{}) string { return src.(*activity.Entry).OldValue }),
+			"newValue":  stringField(func(src interface{}) string { return src.(*activity.Entry).NewValue }),
+			"createdAt": timeField(func(src interface{}) time.Time { return src.(*activity.Entry).CreatedAt }),
+		},
+	})
+
+	taskType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Task",
+		Fields: graphql.Fields{
+			"id":    idField(func(src interface{}) uuid.UUID { return src.(*task.Task).ID }),
+			"title": stringField(func(src interface{}) string { return src.(*task.Task).Title }),
+			"status": &graphql.Field{
+				Type: graphql.NewNonNull(statusEnum),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*task.Task).Status, nil
+				},
+			},
+			"userId":    idField(func(src interface{}) uuid.UUID { return src.(*task.Task).UserID }),
+			"createdAt": timeField(func(src interface{}) time.Time { return src.(*task.Task).CreatedAt }),
+			"updatedAt": timeField(func(src interface{}) time.Time { return src.(*task.Task).UpdatedAt }),
+			"history": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(activityType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID, err := authorize(p.Context, readScope)
+					if err != nil {
+						return nil, err
+					}
+					return h.taskService.GetTaskHistory(p.Source.(*task.Task).ID, userID)
+				},
+			},
+		},
+	})
+
+	paginationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PaginationInfo",
+		Fields: graphql.Fields{
+			"page":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"limit":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"total":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalPages": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	taskListType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TaskList",
+		Fields: graphql.Fields{
+			"data":       &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskType)))},
+			"pagination": &graphql.Field{Type: graphql.NewNonNull(paginationType)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"tasks": &graphql.Field{
+				Type: graphql.NewNonNull(taskListType),
+				Args: graphql.FieldConfigArgument{
+					"status":    &graphql.ArgumentConfig{Type: statusEnum},
+					"search":    &graphql.ArgumentConfig{Type: graphql.String},
+					"sortField": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "created_at"},
+					"sortOrder": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "desc"},
+					"page":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 10},
+				},
+				Resolve: h.resolveTasks,
+			},
+			"task": &graphql.Field{
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: h.resolveTask,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTask": &graphql.Field{
+				Type: graphql.NewNonNull(taskType),
+				Args: graphql.FieldConfigArgument{
+					"title": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: h.resolveCreateTask,
+			},
+			"updateTask": &graphql.Field{
+				Type: graphql.NewNonNull(taskType),
+				Args: graphql.FieldConfigArgument{
+					"id":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"title":  &graphql.ArgumentConfig{Type: graphql.String},
+					"status": &graphql.ArgumentConfig{Type: statusEnum},
+				},
+				Resolve: h.resolveUpdateTask,
+			},
+			"deleteTask": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: h.resolveDeleteTask,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+// Field helpers
+func idField(get func(src interface{}) uuid.UUID) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(graphql.ID),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source).String(), nil
+		},
+	}
+}
+
+func stringField(get func(src interface{}) string) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source), nil
+		},
+	}
+}
+
+func timeField(get func(src interface{}) time.Time) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(graphql.DateTime),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source), nil
+		},
+	}
+}
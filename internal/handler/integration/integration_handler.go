@@ -0,0 +1,1063 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/response"
+	integrationService "todo-api/internal/service/integration"
+	"todo-api/pkg/github"
+	"todo-api/pkg/inbound"
+	"todo-api/pkg/slack"
+	"todo-api/pkg/telegram"
+	"todo-api/pkg/twilio"
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests about third-party integrations
+type Handler struct {
+	slackService          integrationService.SlackService
+	slackSigningSecret    string                                   // verifies slash commands; commands are rejected when empty
+	telegramService       integrationService.TelegramService       // optional, serves the Telegram bot
+	telegramWebhookSecret string                                   // verifies webhook updates; updates are rejected when empty
+	githubService         integrationService.GitHubService         // optional, syncs projects with GitHub issues
+	githubWebhookSecret   string                                   // verifies webhook deliveries; deliveries are rejected when empty
+	calendarService       integrationService.GoogleCalendarService // optional, syncs tasks with Google Calendar
+	smsService            integrationService.SMSService            // optional, texts reminders through Twilio
+	emailService          integrationService.EmailService          // optional, creates tasks from inbound emails
+}
+
+// Deps are the dependencies of an integration handler. Slack is required; the other
+// integrations are optional, and each is only served when its service is set.
+type Deps struct {
+	Slack              integrationService.SlackService
+	SlackSigningSecret string // signing secret of the Slack app, which slash commands are verified with
+	// Telegram serves the Telegram bot, verifying webhook updates with the secret token set
+	// with setWebhook
+	Telegram              integrationService.TelegramService
+	TelegramWebhookSecret string
+	// GitHub syncs projects with GitHub issues, verifying webhook deliveries with the secret
+	// of the repository webhooks
+	GitHub              integrationService.GitHubService
+	GitHubWebhookSecret string
+	Calendar            integrationService.GoogleCalendarService // syncs the tasks of users with their Google Calendar
+	// SMS manages the phone numbers users are texted reminders at, recording the delivery
+	// status Twilio reports
+	SMS integrationService.SMSService
+	// Email creates tasks from the emails users send to their email-in address, posted by
+	// Mailgun or SNS
+	Email integrationService.EmailService
+}
+
+// NewHandler creates a new integration handler verifying Slack slash commands with the
+// signing secret of the Slack app
+func NewHandler(slackSvc integrationService.SlackService, slackSigningSecret string) *Handler {
+	return NewHandlerWithDeps(Deps{Slack: slackSvc, SlackSigningSecret: slackSigningSecret})
+}
+
+// NewHandlerWithDeps creates a new integration handler serving the integrations of Deps
+func NewHandlerWithDeps(deps Deps) *Handler {
+	return &Handler{
+		slackService:          deps.Slack,
+		slackSigningSecret:    deps.SlackSigningSecret,
+		telegramService:       deps.Telegram,
+		telegramWebhookSecret: deps.TelegramWebhookSecret,
+		githubService:         deps.GitHub,
+		githubWebhookSecret:   deps.GitHubWebhookSecret,
+		calendarService:       deps.Calendar,
+		smsService:            deps.SMS,
+		emailService:          deps.Email,
+	}
+}
+
+// GetSlack handles retrieving the user's Slack connection
+func (h *Handler) GetSlack(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	conn, err := h.slackService.GetSlackConnection(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Slack is not connected",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Slack connection retrieved successfully",
+		"data":    conn,
+	})
+}
+
+// ConnectSlack handles connecting the user's Slack through an incoming webhook or a bot token
+func (h *Handler) ConnectSlack(c *fiber.Ctx) error {
+	var req integration.ConnectSlackRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	conn, err := h.slackService.ConnectSlack(userID, &req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, billing.ErrUpgradeRequired):
+			status = fiber.StatusPaymentRequired
+		case err.Error() == "slack account is connected to another user":
+			status = fiber.StatusConflict
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Slack connected successfully",
+		"data":    conn,
+	})
+}
+
+// DisconnectSlack handles removing the user's Slack connection
+func (h *Handler) DisconnectSlack(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.slackService.DisconnectSlack(userID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Slack is not connected",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Slack disconnected successfully",
+	})
+}
+
+// SlackCommand handles a slash command sent by Slack. The request is authenticated by its
+// signature rather than a token, and answered in the format Slack shows to the user who
+// sent the command.
+func (h *Handler) SlackCommand(c *fiber.Ctx) error {
+	if h.slackSigningSecret == "" {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   true,
+			"message": "Slack commands are not configured",
+		})
+	}
+
+	err := slack.VerifySignature(h.slackSigningSecret, c.Get(slack.TimestampHeader), c.Get(slack.SignatureHeader), c.Body(), time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request signature",
+		})
+	}
+
+	text := h.slackService.HandleSlashCommand(&integration.SlashCommand{
+		TeamID:  c.FormValue("team_id"),
+		UserID:  c.FormValue("user_id"),
+		Command: c.FormValue("command"),
+		Text:    c.FormValue("text"),
+	})
+
+	return c.JSON(fiber.Map{
+		"response_type": "ephemeral",
+		"text":          text,
+	})
+}
+
+// CreateTelegramLinkCode handles creating a code linking a Telegram chat to the user. The
+// chat may act with the task scopes of the token used to create the code.
+func (h *Handler) CreateTelegramLinkCode(c *fiber.Ctx) error {
+	if h.telegramService == nil {
+		return errTelegramNotConfigured(c)
+	}
+
+	// Get user ID and token scopes from context
+	userID := c.Locals("user_id").(uuid.UUID)
+	var scopes []string
+	if claims, ok := c.Locals("user_claims").(*utils.JWTClaims); ok {
+		scopes = claims.Scopes
+	}
+
+	code, err := h.telegramService.CreateTelegramLinkCode(userID, scopes)
+	if err != nil {
+		if errors.Is(err, billing.ErrUpgradeRequired) {
+			return errUpgradeRequired(c, err)
+		}
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+			"error":   true,
+			"message": "Failed to create link code",
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Telegram link code created successfully",
+		"data":    code,
+	})
+}
+
+// GetTelegram handles retrieving the Telegram chat linked to the user
+func (h *Handler) GetTelegram(c *fiber.Ctx) error {
+	if h.telegramService == nil {
+		return errTelegramNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	link, err := h.telegramService.GetTelegramLink(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Telegram is not linked",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Telegram link retrieved successfully",
+		"data":    link,
+	})
+}
+
+// UnlinkTelegram handles unlinking the Telegram chat linked to the user
+func (h *Handler) UnlinkTelegram(c *fiber.Ctx) error {
+	if h.telegramService == nil {
+		return errTelegramNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.telegramService.UnlinkTelegram(userID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Telegram is not linked",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Telegram unlinked successfully",
+	})
+}
+
+// TelegramWebhook handles an update delivered by Telegram. The request is authenticated by
+// the secret token header, and bot commands are answered in the response.
+func (h *Handler) TelegramWebhook(c *fiber.Ctx) error {
+	if h.telegramService == nil || h.telegramWebhookSecret == "" {
+		return errTelegramNotConfigured(c)
+	}
+
+	if !telegram.VerifySecretToken(h.telegramWebhookSecret, c.Get(telegram.SecretTokenHeader)) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid secret token",
+		})
+	}
+
+	var update telegram.Update
+	if err := json.Unmarshal(c.Body(), &update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid update",
+		})
+	}
+
+	// Updates other than text messages, such as edits, are acknowledged and ignored
+	if update.Message == nil || update.Message.Text == "" {
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	reply := h.telegramService.HandleMessage(update.Message, time.Now())
+	if reply == "" {
+		return c.SendStatus(fiber.StatusOK)
+	}
+	return c.JSON(telegram.NewReply(update.Message.Chat.ID, reply))
+}
+
+// errTelegramNotConfigured responds that the Telegram bot is not configured
+func errTelegramNotConfigured(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotImplemented, fiber.Map{
+		"error":   true,
+		"message": "Telegram is not configured",
+	})
+}
+
+// AuthorizeGitHub handles starting the authorization of the GitHub OAuth app. The user is
+// sent to the returned URL and redirected back to the callback.
+func (h *Handler) AuthorizeGitHub(c *fiber.Ctx) error {
+	if h.githubService == nil {
+		return errGitHubNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	authorization, err := h.githubService.AuthorizeGitHub(userID)
+	if err != nil {
+		if err.Error() == "github is not configured" {
+			return errGitHubNotConfigured(c)
+		}
+		if errors.Is(err, billing.ErrUpgradeRequired) {
+			return errUpgradeRequired(c, err)
+		}
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+			"error":   true,
+			"message": "Failed to start GitHub authorization",
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "GitHub authorization started successfully",
+		"data":    authorization,
+	})
+}
+
+// GitHubCallback handles the redirect back from GitHub after the user authorized the OAuth
+// app. The request is authenticated by the one-time state rather than a token.
+func (h *Handler) GitHubCallback(c *fiber.Ctx) error {
+	if h.githubService == nil {
+		return errGitHubNotConfigured(c)
+	}
+
+	// The user denied the authorization
+	if c.Query("error") != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "GitHub authorization was denied",
+		})
+	}
+
+	account, err := h.githubService.CompleteGitHubAuthorization(c.UserContext(), c.Query("state"), c.Query("code"))
+	if response.TimedOut(c, err) {
+		return c.UserContext().Err()
+	}
+	if err != nil {
+		status := fiber.StatusBadGateway
+		switch {
+		case err.Error() == "invalid or expired state":
+			status = fiber.StatusBadRequest
+		case errors.Is(err, billing.ErrUpgradeRequired):
+			status = fiber.StatusPaymentRequired
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "GitHub connected successfully",
+		"data":    account,
+	})
+}
+
+// GetGitHub handles retrieving the GitHub account the user authorized
+func (h *Handler) GetGitHub(c *fiber.Ctx) error {
+	if h.githubService == nil {
+		return errGitHubNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	account, err := h.githubService.GetGitHubAccount(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "GitHub is not connected",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "GitHub account retrieved successfully",
+		"data":    account,
+	})
+}
+
+// DisconnectGitHub handles forgetting the GitHub account the user authorized
+func (h *Handler) DisconnectGitHub(c *fiber.Ctx) error {
+	if h.githubService == nil {
+		return errGitHubNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.githubService.DisconnectGitHub(userID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "GitHub is not connected",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "GitHub disconnected successfully",
+	})
+}
+
+// LinkProjectToGitHub handles linking a project of the workspace to a GitHub repository
+// with the caller's GitHub account
+func (h *Handler) LinkProjectToGitHub(c *fiber.Ctx) error {
+	if h.githubService == nil {
+		return errGitHubNotConfigured(c)
+	}
+
+	projectID, err := uuid.Parse(c.Params("projectId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid project ID",
+		})
+	}
+
+	var req integration.LinkGitHubRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID and workspace ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+	workspaceID := c.Locals("workspace_id").(uuid.UUID)
+
+	link, err := h.githubService.LinkProject(c.UserContext(), workspaceID, projectID, userID, &req)
+	if response.TimedOut(c, err) {
+		return c.UserContext().Err()
+	}
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case err.Error() == "project not found", err.Error() == "repository not found":
+			status = fiber.StatusNotFound
+		case err.Error() == "github account cannot push to the repository":
+			status = fiber.StatusForbidden
+		case err.Error() == "github is not connected":
+			status = fiber.StatusConflict
+		case strings.HasPrefix(err.Error(), "failed to get repository"):
+			status = fiber.StatusBadGateway
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Project linked to GitHub successfully",
+		"data":    link,
+	})
+}
+
+// GetProjectGitHubLink handles retrieving the GitHub repository of a project of the
+// workspace, with its sync status
+func (h *Handler) GetProjectGitHubLink(c *fiber.Ctx) error {
+	if h.githubService == nil {
+		return errGitHubNotConfigured(c)
+	}
+
+	projectID, err := uuid.Parse(c.Params("projectId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid project ID",
+		})
+	}
+
+	link, err := h.githubService.GetProjectLink(c.Locals("workspace_id").(uuid.UUID), projectID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Project is not linked to GitHub",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "GitHub link retrieved successfully",
+		"data":    link,
+	})
+}
+
+// UnlinkProjectFromGitHub handles stopping the sync of a project of the workspace with
+// GitHub. Its tasks are kept.
+func (h *Handler) UnlinkProjectFromGitHub(c *fiber.Ctx) error {
+	if h.githubService == nil {
+		return errGitHubNotConfigured(c)
+	}
+
+	projectID, err := uuid.Parse(c.Params("projectId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid project ID",
+		})
+	}
+
+	if err := h.githubService.UnlinkProject(c.Locals("workspace_id").(uuid.UUID), projectID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Project is not linked to GitHub",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Project unlinked from GitHub successfully",
+	})
+}
+
+// GitHubWebhook handles an event delivered by a repository webhook. The request is
+// authenticated by its signature. Issue events are applied to the linked projects; other
+// events are acknowledged and ignored.
+func (h *Handler) GitHubWebhook(c *fiber.Ctx) error {
+	if h.githubService == nil || h.githubWebhookSecret == "" {
+		return errGitHubNotConfigured(c)
+	}
+
+	if err := github.VerifySignature(h.githubWebhookSecret, c.Get(github.SignatureHeader), c.Body()); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request signature",
+		})
+	}
+
+	if c.Get(github.EventHeader) != github.EventIssues {
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	var event github.IssuesEvent
+	if err := json.Unmarshal(c.Body(), &event); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid event",
+		})
+	}
+
+	h.githubService.HandleIssuesEvent(c.UserContext(), &event)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// errGitHubNotConfigured responds that the GitHub integration is not configured
+func errGitHubNotConfigured(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotImplemented, fiber.Map{
+		"error":   true,
+		"message": "GitHub is not configured",
+	})
+}
+
+// AuthorizeGoogleCalendar handles starting to grant access to the user's Google Calendar.
+// The user is sent to the returned URL and redirected back to the callback.
+func (h *Handler) AuthorizeGoogleCalendar(c *fiber.Ctx) error {
+	if h.calendarService == nil {
+		return errGoogleCalendarNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	authorization, err := h.calendarService.AuthorizeGoogleCalendar(userID)
+	if err != nil {
+		if err.Error() == "google calendar is not configured" {
+			return errGoogleCalendarNotConfigured(c)
+		}
+		if errors.Is(err, billing.ErrUpgradeRequired) {
+			return errUpgradeRequired(c, err)
+		}
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+			"error":   true,
+			"message": "Failed to start Google Calendar authorization",
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Google Calendar authorization started successfully",
+		"data":    authorization,
+	})
+}
+
+// GoogleCalendarCallback handles the redirect back from Google after the user granted
+// access. The request is authenticated by the one-time state rather than a token.
+func (h *Handler) GoogleCalendarCallback(c *fiber.Ctx) error {
+	if h.calendarService == nil {
+		return errGoogleCalendarNotConfigured(c)
+	}
+
+	// The user denied access
+	if c.Query("error") != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Google Calendar authorization was denied",
+		})
+	}
+
+	connection, err := h.calendarService.CompleteGoogleCalendarAuthorization(c.UserContext(), c.Query("state"), c.Query("code"))
+	if response.TimedOut(c, err) {
+		return c.UserContext().Err()
+	}
+	if err != nil {
+		status := fiber.StatusBadGateway
+		switch {
+		case err.Error() == "invalid or expired state":
+			status = fiber.StatusBadRequest
+		case errors.Is(err, billing.ErrUpgradeRequired):
+			status = fiber.StatusPaymentRequired
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Google Calendar connected successfully",
+		"data":    connection,
+	})
+}
+
+// GetGoogleCalendar handles retrieving the Google Calendar the user connected, with its
+// sync status
+func (h *Handler) GetGoogleCalendar(c *fiber.Ctx) error {
+	if h.calendarService == nil {
+		return errGoogleCalendarNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	connection, err := h.calendarService.GetGoogleCalendar(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Google Calendar is not connected",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Google Calendar connection retrieved successfully",
+		"data":    connection,
+	})
+}
+
+// DisconnectGoogleCalendar handles stopping the sync of the user's Google Calendar
+func (h *Handler) DisconnectGoogleCalendar(c *fiber.Ctx) error {
+	if h.calendarService == nil {
+		return errGoogleCalendarNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.calendarService.DisconnectGoogleCalendar(userID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Google Calendar is not connected",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Google Calendar disconnected successfully",
+	})
+}
+
+// errGoogleCalendarNotConfigured responds that the Google Calendar integration is not configured
+func errGoogleCalendarNotConfigured(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotImplemented, fiber.Map{
+		"error":   true,
+		"message": "Google Calendar is not configured",
+	})
+}
+
+// GetSMS handles retrieving the user's verified phone number and SMS settings
+func (h *Handler) GetSMS(c *fiber.Ctx) error {
+	if h.smsService == nil {
+		return errSMSNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	settings, err := h.smsService.GetSMSSettings(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "SMS is not set up",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "SMS settings retrieved successfully",
+		"data":    settings,
+	})
+}
+
+// VerifySMSPhone handles sending a code verifying the user's phone number
+func (h *Handler) VerifySMSPhone(c *fiber.Ctx) error {
+	if h.smsService == nil {
+		return errSMSNotConfigured(c)
+	}
+
+	var req integration.VerifySMSPhoneRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	verification, err := h.smsService.VerifySMSPhone(c.UserContext(), userID, &req)
+	if response.TimedOut(c, err) {
+		return c.UserContext().Err()
+	}
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case err.Error() == "phone number is verified by another user":
+			status = fiber.StatusConflict
+		case err.Error() == "verification code was sent recently":
+			status = fiber.StatusTooManyRequests
+		case strings.HasPrefix(err.Error(), "twilio:"):
+			status = fiber.StatusBadGateway
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Verification code sent successfully",
+		"data":    verification,
+	})
+}
+
+// ConfirmSMSPhone handles verifying the user's phone number with the code sent to it
+func (h *Handler) ConfirmSMSPhone(c *fiber.Ctx) error {
+	if h.smsService == nil {
+		return errSMSNotConfigured(c)
+	}
+
+	var req integration.ConfirmSMSPhoneRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	settings, err := h.smsService.ConfirmSMSPhone(userID, &req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if err.Error() == "phone number is verified by another user" {
+			status = fiber.StatusConflict
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Phone number verified successfully",
+		"data":    settings,
+	})
+}
+
+// UpdateSMS handles updating the user's SMS reminders and quiet hours
+func (h *Handler) UpdateSMS(c *fiber.Ctx) error {
+	if h.smsService == nil {
+		return errSMSNotConfigured(c)
+	}
+
+	var req integration.UpdateSMSSettingsRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	settings, err := h.smsService.UpdateSMSSettings(userID, &req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if err.Error() == "sms is not set up" {
+			status = fiber.StatusNotFound
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "SMS settings updated successfully",
+		"data":    settings,
+	})
+}
+
+// RemoveSMS handles removing the user's phone number
+func (h *Handler) RemoveSMS(c *fiber.Ctx) error {
+	if h.smsService == nil {
+		return errSMSNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.smsService.RemoveSMS(userID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "SMS is not set up",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "SMS removed successfully",
+	})
+}
+
+// ListSMSMessages handles listing the latest messages sent to the user, with their delivery status
+func (h *Handler) ListSMSMessages(c *fiber.Ctx) error {
+	if h.smsService == nil {
+		return errSMSNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "SMS messages retrieved successfully",
+		"data":    h.smsService.ListSMSMessages(userID),
+	})
+}
+
+// TwilioStatus handles the delivery status of a message posted by Twilio. The request is
+// authenticated by its signature rather than a token.
+func (h *Handler) TwilioStatus(c *fiber.Ctx) error {
+	if h.smsService == nil {
+		return errSMSNotConfigured(c)
+	}
+
+	params, err := url.ParseQuery(string(c.Body()))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := h.smsService.HandleStatusCallback(params, c.Get(twilio.SignatureHeader)); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request signature",
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetEmail handles retrieving the user's email-in address
+func (h *Handler) GetEmail(c *fiber.Ctx) error {
+	if h.emailService == nil {
+		return errEmailNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	address, err := h.emailService.GetEmailAddress(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Email-in address not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Email-in address retrieved successfully",
+		"data":    address,
+	})
+}
+
+// CreateEmail handles creating the user's email-in address, replacing any previous one
+func (h *Handler) CreateEmail(c *fiber.Ctx) error {
+	if h.emailService == nil {
+		return errEmailNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	address, err := h.emailService.CreateEmailAddress(userID)
+	if err != nil {
+		if errors.Is(err, billing.ErrUpgradeRequired) {
+			return errUpgradeRequired(c, err)
+		}
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+			"error":   true,
+			"message": "Failed to create email-in address",
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Email-in address created successfully",
+		"data":    address,
+	})
+}
+
+// DeleteEmail handles deleting the user's email-in address
+func (h *Handler) DeleteEmail(c *fiber.Ctx) error {
+	if h.emailService == nil {
+		return errEmailNotConfigured(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.emailService.DeleteEmailAddress(userID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Email-in address not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Email-in address deleted successfully",
+	})
+}
+
+// EmailWebhook handles an email forwarded by a Mailgun route, or a message SNS posted for an
+// email received by SES. Requests are authenticated by their signature rather than a token.
+func (h *Handler) EmailWebhook(c *fiber.Ctx) error {
+	if h.emailService == nil {
+		return errEmailNotConfigured(c)
+	}
+
+	fromSNS := c.Get(inbound.SNSMessageTypeHeader) != ""
+	var err error
+	if fromSNS {
+		_, err = h.emailService.ReceiveSNS(c.UserContext(), c.Body())
+	} else {
+		// Mailgun posts emails with attachments as a multipart form
+		values, files := url.Values{}, map[string][]*multipart.FileHeader{}
+		if form, formErr := c.MultipartForm(); formErr == nil {
+			values, files = form.Value, form.File
+		} else if values, err = url.ParseQuery(string(c.Body())); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid request body",
+			})
+		}
+		_, err = h.emailService.ReceiveMailgun(c.UserContext(), values, files, time.Now())
+	}
+
+	switch {
+	case err == nil:
+		return c.SendStatus(fiber.StatusOK)
+	case errors.Is(err, inbound.ErrInvalidSignature):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request signature",
+		})
+	case errors.Is(err, integration.ErrEmailRejected):
+		log.Printf("Rejected inbound email: %v", err)
+		// SNS retries every message not acknowledged, while Mailgun does not retry emails
+		// rejected as not acceptable
+		if fromSNS {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		return c.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+}
+
+// errUpgradeRequired responds that the user's plan allows no more integrations
+func errUpgradeRequired(c *fiber.Ctx, err error) error {
+	return response.Send(c, fiber.StatusPaymentRequired, fiber.Map{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
+
+// errSMSNotConfigured responds that SMS is not configured
+func errSMSNotConfigured(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotImplemented, fiber.Map{
+		"error":   true,
+		"message": "SMS is not configured",
+	})
+}
+
+// errEmailNotConfigured responds that email-in is not configured
+func errEmailNotConfigured(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotImplemented, fiber.Map{
+		"error":   true,
+		"message": "Email-in is not configured",
+	})
+}
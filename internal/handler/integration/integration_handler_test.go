@@ -0,0 +1,463 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-api/internal/events"
+	"todo-api/internal/service/auth"
+	integrationService "todo-api/internal/service/integration"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+	"todo-api/pkg/github"
+	"todo-api/pkg/inbound"
+	"todo-api/pkg/slack"
+	"todo-api/pkg/telegram"
+	"todo-api/pkg/twilio"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	signingSecret   = "test-signing-secret"
+	webhookSecret   = "test-webhook-secret"
+	twilioAuthToken = "test-auth-token"
+)
+
+func setupTestApp(t *testing.T) *fiber.App {
+	return setupTestAppWithTwilio(t, twilio.DefaultAPIURL)
+}
+
+// setupTestAppWithTwilio sets up the app sending SMS through the Twilio API at the URL
+func setupTestAppWithTwilio(t *testing.T, twilioURL string) *fiber.App {
+	cfg := &config.Config{
+		App:      config.AppConfig{BaseURL: "https://todo.example.com"},
+		Telegram: config.TelegramConfig{LinkTTL: 10 * time.Minute},
+		Twilio: config.TwilioConfig{AccountSID: "AC123", AuthToken: twilioAuthToken, From: "+14155550199", APIURL: twilioURL,
+			Timeout: time.Second, CodeTTL: 10 * time.Minute},
+		GitHub:   config.GitHubConfig{ClientID: "client-id", ClientSecret: "client-secret", AuthorizationTTL: 10 * time.Minute},
+		Calendar: config.GoogleCalendarConfig{ClientID: "client-id", ClientSecret: "client-secret", AuthorizationTTL: 10 * time.Minute},
+		Inbound: config.InboundEmailConfig{Domain: "todo.example", Provider: "mailgun", SigningKey: signingSecret,
+			SESTopicARN: "arn:aws:sns:us-east-1:123456789012:inbound", Timeout: time.Second},
+	}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := auth.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	slackSvc := integrationService.NewSlackService(cfg, authSvc, taskSvc, slack.NewClient("", time.Second), bus)
+	telegramSvc := integrationService.NewTelegramService(cfg, authSvc, taskSvc)
+	githubSvc := integrationService.NewGitHubService(cfg, taskSvc, workspaceService.NewService(authSvc),
+		cfg.GitHub.NewClient(nil), bus)
+	calendarSvc := integrationService.NewGoogleCalendarService(cfg, taskSvc, cfg.Calendar.NewClient(nil))
+	smsSvc := integrationService.NewSMSService(cfg, cfg.Twilio.NewClient(nil))
+	emailSvc := integrationService.NewEmailService(cfg, authSvc, taskSvc, nil, nil)
+	handler := NewHandlerWithDeps(Deps{
+		Slack:                 slackSvc,
+		SlackSigningSecret:    signingSecret,
+		Telegram:              telegramSvc,
+		TelegramWebhookSecret: webhookSecret,
+		GitHub:                githubSvc,
+		GitHubWebhookSecret:   webhookSecret,
+		Calendar:              calendarSvc,
+		SMS:                   smsSvc,
+		Email:                 emailSvc,
+	})
+
+	app := fiber.New()
+	app.Post("/integrations/slack/commands", handler.SlackCommand)
+	app.Post("/integrations/telegram/webhook", handler.TelegramWebhook)
+	app.Get("/integrations/github/callback", handler.GitHubCallback)
+	app.Post("/integrations/github/webhook", handler.GitHubWebhook)
+	app.Get("/integrations/google-calendar/callback", handler.GoogleCalendarCallback)
+	app.Post("/integrations/twilio/status", handler.TwilioStatus)
+	app.Post("/integrations/email/webhook", handler.EmailWebhook)
+	me := app.Group("/me", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	me.Get("/integrations/slack", handler.GetSlack)
+	me.Put("/integrations/slack", handler.ConnectSlack)
+	me.Delete("/integrations/slack", handler.DisconnectSlack)
+	me.Get("/integrations/telegram", handler.GetTelegram)
+	me.Post("/integrations/telegram/link", handler.CreateTelegramLinkCode)
+	me.Delete("/integrations/telegram", handler.UnlinkTelegram)
+	me.Get("/integrations/github", handler.GetGitHub)
+	me.Post("/integrations/github/authorize", handler.AuthorizeGitHub)
+	me.Delete("/integrations/github", handler.DisconnectGitHub)
+	me.Get("/integrations/google-calendar", handler.GetGoogleCalendar)
+	me.Post("/integrations/google-calendar/authorize", handler.AuthorizeGoogleCalendar)
+	me.Delete("/integrations/google-calendar", handler.DisconnectGoogleCalendar)
+	me.Get("/integrations/sms", handler.GetSMS)
+	me.Put("/integrations/sms", handler.UpdateSMS)
+	me.Delete("/integrations/sms", handler.RemoveSMS)
+	me.Post("/integrations/sms/phone", handler.VerifySMSPhone)
+	me.Post("/integrations/sms/verify", handler.ConfirmSMSPhone)
+	me.Get("/integrations/sms/messages", handler.ListSMSMessages)
+	me.Get("/integrations/email", handler.GetEmail)
+	me.Post("/integrations/email", handler.CreateEmail)
+	me.Delete("/integrations/email", handler.DeleteEmail)
+	return app
+}
+
+func TestHandler_Slack(t *testing.T) {
+	app := setupTestApp(t)
+
+	send := func(method, body string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(method, "/me/integrations/slack", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	resp, _ := send(http.MethodGet, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = send(http.MethodPut, `{"webhook_url":"https://example.com/hook"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, body := send(http.MethodPut, `{"bot_token":"xoxb-secret","channel":"C123","events":["assigned"]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, "bot", data["mode"])
+	assert.Equal(t, "C123", data["channel"])
+	assert.Equal(t, []interface{}{"assigned"}, data["events"])
+	assert.NotContains(t, data, "bot_token")
+
+	resp, _ = send(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = send(http.MethodDelete, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = send(http.MethodDelete, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_SlackCommand(t *testing.T) {
+	app := setupTestApp(t)
+
+	// Link the Slack account to the user
+	req := httptest.NewRequest(http.MethodPut, "/me/integrations/slack",
+		strings.NewReader(`{"webhook_url":"https://hooks.slack.com/services/T0/B0/X","team_id":"T1","slack_user_id":"U1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	command := func(secret string, timestamp time.Time) *http.Response {
+		body := url.Values{"team_id": {"T1"}, "user_id": {"U1"}, "command": {"/todo"}, "text": {"add Buy milk"}}.Encode()
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+		req := httptest.NewRequest(http.MethodPost, "/integrations/slack/commands", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(slack.TimestampHeader, ts)
+		req.Header.Set(slack.SignatureHeader, slack.Sign(secret, ts, []byte(body)))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Unsigned and replayed requests are rejected
+	assert.Equal(t, http.StatusUnauthorized, command("wrong-secret", time.Now()).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, command(signingSecret, time.Now().Add(-time.Hour)).StatusCode)
+
+	resp = command(signingSecret, time.Now())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "ephemeral", response["response_type"])
+	assert.Contains(t, response["text"], "|Buy milk>")
+}
+
+func TestHandler_Telegram(t *testing.T) {
+	app := setupTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/me/integrations/telegram/link", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	code := created["data"].(map[string]interface{})["code"].(string)
+
+	update := func(secret, text string) *http.Response {
+		body := `{"update_id":1,"message":{"message_id":1,"from":{"id":7},"chat":{"id":42,"type":"private"},"text":"` + text + `"}}`
+		req := httptest.NewRequest(http.MethodPost, "/integrations/telegram/webhook", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(telegram.SecretTokenHeader, secret)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, update("wrong-secret", "/start "+code).StatusCode)
+
+	// Commands are answered in the webhook response
+	resp = update(webhookSecret, "/start "+code)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var reply telegram.Reply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	assert.Equal(t, "sendMessage", reply.Method)
+	assert.Equal(t, int64(42), reply.ChatID)
+	assert.Contains(t, reply.Text, "now linked")
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/me/integrations/telegram", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/me/integrations/telegram", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/me/integrations/telegram", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_GitHub(t *testing.T) {
+	app := setupTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/me/integrations/github/authorize", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	authorizeURL, err := url.Parse(created["data"].(map[string]interface{})["url"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "github.com", authorizeURL.Host)
+	assert.Equal(t, "https://todo.example.com/integrations/github/callback", authorizeURL.Query().Get("redirect_uri"))
+
+	// Callbacks must carry the state of an authorization
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/integrations/github/callback?state=unknown&code=abc", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/me/integrations/github", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_GitHubWebhook(t *testing.T) {
+	app := setupTestApp(t)
+
+	deliver := func(event, signature, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/integrations/github/webhook", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(github.EventHeader, event)
+		req.Header.Set(github.SignatureHeader, signature)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	body := `{"action":"opened","issue":{"number":1,"title":"Crash","state":"open"},"repository":{"full_name":"acme/app"}}`
+	assert.Equal(t, http.StatusUnauthorized, deliver(github.EventIssues, github.Sign("wrong-secret", []byte(body)), body))
+	assert.Equal(t, http.StatusUnauthorized, deliver(github.EventIssues, "", body))
+
+	// Events of repositories no project is linked to are acknowledged
+	assert.Equal(t, http.StatusOK, deliver(github.EventIssues, github.Sign(webhookSecret, []byte(body)), body))
+	ping := `{"zen":"Keep it logically awesome."}`
+	assert.Equal(t, http.StatusOK, deliver(github.EventPing, github.Sign(webhookSecret, []byte(ping)), ping))
+
+	invalid := `{"issue":`
+	assert.Equal(t, http.StatusBadRequest, deliver(github.EventIssues, github.Sign(webhookSecret, []byte(invalid)), invalid))
+}
+
+func TestHandler_GoogleCalendar(t *testing.T) {
+	app := setupTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/me/integrations/google-calendar/authorize", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	authorizeURL, err := url.Parse(created["data"].(map[string]interface{})["url"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "accounts.google.com", authorizeURL.Host)
+	assert.Equal(t, "https://todo.example.com/integrations/google-calendar/callback", authorizeURL.Query().Get("redirect_uri"))
+
+	// Callbacks must carry the state of an authorization
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/integrations/google-calendar/callback?state=unknown&code=abc", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/integrations/google-calendar/callback?error=access_denied", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/me/integrations/google-calendar", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/me/integrations/google-calendar", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_SMS(t *testing.T) {
+	texts := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		texts <- r.FormValue("Body")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+	app := setupTestAppWithTwilio(t, server.URL)
+
+	send := func(method, path, body string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	resp, _ := send(http.MethodGet, "/me/integrations/sms", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = send(http.MethodPut, "/me/integrations/sms", `{"reminders":true}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = send(http.MethodPost, "/me/integrations/sms/phone", `{"phone_number":"4155550100"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = send(http.MethodPost, "/me/integrations/sms/phone", `{"phone_number":"+14155550100"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	code := strings.TrimPrefix(<-texts, "Your Todo API verification code is ")
+
+	resp, _ = send(http.MethodPost, "/me/integrations/sms/phone", `{"phone_number":"+14155550100"}`)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	resp, _ = send(http.MethodPost, "/me/integrations/sms/verify", `{"code":"wrong"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, body := send(http.MethodPost, "/me/integrations/sms/verify", `{"code":"`+code+`"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "+14155550100", body["data"].(map[string]interface{})["phone_number"])
+
+	resp, _ = send(http.MethodPut, "/me/integrations/sms", `{"quiet_hours":{"start":"22:00","end":"7am"}}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, body = send(http.MethodPut, "/me/integrations/sms", `{"reminders":true,"quiet_hours":{"start":"22:00","end":"07:00","time_zone":"Europe/Berlin"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, true, data["reminders"])
+	assert.Equal(t, "Europe/Berlin", data["quiet_hours"].(map[string]interface{})["time_zone"])
+
+	resp, body = send(http.MethodGet, "/me/integrations/sms/messages", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, body["data"])
+
+	// Status callbacks must be signed by Twilio
+	status := func(signature string) *http.Response {
+		params := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}}
+		req := httptest.NewRequest(http.MethodPost, "/integrations/twilio/status", strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(twilio.SignatureHeader, signature)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	params := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}}
+	assert.Equal(t, http.StatusUnauthorized, status("invalid").StatusCode)
+	assert.Equal(t, http.StatusNoContent,
+		status(twilio.Sign(twilioAuthToken, "https://todo.example.com/integrations/twilio/status", params)).StatusCode)
+
+	resp, _ = send(http.MethodDelete, "/me/integrations/sms", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = send(http.MethodDelete, "/me/integrations/sms", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_SMSNotConfigured(t *testing.T) {
+	handler := NewHandlerWithDeps(Deps{})
+	app := fiber.New()
+	app.Get("/me/integrations/sms", handler.GetSMS)
+	app.Post("/integrations/twilio/status", handler.TwilioStatus)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/me/integrations/sms", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/integrations/twilio/status", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
+
+func TestHandler_Email(t *testing.T) {
+	app := setupTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/me/integrations/email", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/me/integrations/email", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	address := created["data"].(map[string]interface{})["address"].(string)
+
+	// Mailgun posts emails without attachments as a URL encoded form
+	email := func(from, signature string) *http.Response {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		if signature == "" {
+			signature = inbound.SignMailgun(signingSecret, timestamp, "token")
+		}
+		params := url.Values{
+			"timestamp":       {timestamp},
+			"token":           {"token"},
+			"signature":       {signature},
+			"recipient":       {address},
+			"from":            {from},
+			"subject":         {"Buy milk"},
+			"message-headers": {`[["X-Mailgun-Spf","Pass"]]`},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/integrations/email/webhook", strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	assert.Equal(t, http.StatusOK, email("john.doe@example.com", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, email("john.doe@example.com", "forged").StatusCode)
+	// Mailgun does not retry emails rejected as not acceptable
+	assert.Equal(t, http.StatusNotAcceptable, email("jane.smith@example.com", "").StatusCode)
+
+	// SNS messages of other topics are rejected
+	req := httptest.NewRequest(http.MethodPost, "/integrations/email/webhook",
+		strings.NewReader(`{"Type":"Notification","TopicArn":"arn:aws:sns:us-east-1:999999999999:other","Message":"{}"}`))
+	req.Header.Set(inbound.SNSMessageTypeHeader, "Notification")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/me/integrations/email", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusNotAcceptable, email("john.doe@example.com", "").StatusCode)
+}
+
+func TestHandler_EmailNotConfigured(t *testing.T) {
+	handler := NewHandlerWithDeps(Deps{})
+	app := fiber.New()
+	app.Get("/me/integrations/email", handler.GetEmail)
+	app.Post("/integrations/email/webhook", handler.EmailWebhook)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/me/integrations/email", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/integrations/email/webhook", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
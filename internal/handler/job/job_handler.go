@@ -0,0 +1,197 @@
+package job
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/audit"
+	auditHandler "todo-api/internal/handler/audit"
+	"todo-api/internal/jobs"
+	"todo-api/internal/response"
+	"todo-api/internal/scheduler"
+	auditService "todo-api/internal/service/audit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests about background jobs: users follow the jobs they started,
+// such as imports, and platform admins holding the jobs:admin scope manage the dead-letter
+// list and see how scheduled jobs ran
+type Handler struct {
+	jobs         jobs.Queue
+	auditService auditService.Service // optional, records retried and discarded jobs
+	scheduler    *scheduler.Scheduler // optional, lists scheduled jobs
+}
+
+// Deps are the dependencies of a job handler. Jobs is required; the others are optional,
+// and a zero value keeps the default noted next to each.
+type Deps struct {
+	Jobs      jobs.Queue
+	Audit     auditService.Service // admin actions on jobs are not recorded
+	Scheduler *scheduler.Scheduler // scheduled jobs are not listed
+}
+
+// NewHandler creates a new job handler instance
+func NewHandler(queue jobs.Queue) *Handler {
+	return NewHandlerWithDeps(Deps{Jobs: queue})
+}
+
+// NewHandlerWithDeps creates a new job handler instance, using the defaults of Deps for the
+// dependencies left unset
+func NewHandlerWithDeps(deps Deps) *Handler {
+	return &Handler{
+		jobs:         deps.Jobs,
+		auditService: deps.Audit,
+		scheduler:    deps.Scheduler,
+	}
+}
+
+// GetJob handles retrieving the status of a job the user started, with its result once it
+// succeeded
+func (h *Handler) GetJob(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid job ID",
+		})
+	}
+
+	// Jobs of other users are reported as not found
+	job, err := h.jobs.Get(id)
+	if err != nil || job.OwnerID == nil || *job.OwnerID != userID {
+		return errJobNotFound(c)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Job retrieved successfully",
+		"data":    job,
+	})
+}
+
+// GetStats handles counting the jobs of the queue by status
+func (h *Handler) GetStats(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Job stats retrieved successfully",
+		"data":    h.jobs.Stats(),
+	})
+}
+
+// ListDeadLetters handles listing the jobs that failed every attempt, oldest first
+func (h *Handler) ListDeadLetters(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Dead jobs retrieved successfully",
+		"data":    h.jobs.DeadLetters(),
+	})
+}
+
+// ListSchedules handles listing the scheduled jobs with their last and next runs and failures
+func (h *Handler) ListSchedules(c *fiber.Ctx) error {
+	entries := []scheduler.Entry{}
+	if h.scheduler != nil {
+		entries = h.scheduler.Entries()
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Scheduled jobs retrieved successfully",
+		"data":    entries,
+	})
+}
+
+// RetryJob handles moving a dead job back to the queue
+func (h *Handler) RetryJob(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid job ID",
+		})
+	}
+
+	job, err := h.jobs.Retry(id)
+	if err != nil {
+		return sendError(c, err)
+	}
+	h.record(c, audit.ActionJobRetried, job)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Job retried successfully",
+		"data":    job,
+	})
+}
+
+// DiscardJob handles removing a dead job for good
+func (h *Handler) DiscardJob(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid job ID",
+		})
+	}
+
+	job, err := h.jobs.Get(id)
+	if err == nil {
+		err = h.jobs.Discard(id)
+	}
+	if err != nil {
+		return sendError(c, err)
+	}
+	h.record(c, audit.ActionJobDiscarded, job)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Job discarded successfully",
+	})
+}
+
+// sendError responds with the status of a queue error
+func sendError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return errJobNotFound(c)
+	case errors.Is(err, jobs.ErrNotDead):
+		return response.Send(c, fiber.StatusConflict, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	default:
+		return response.Send(c, fiber.StatusServiceUnavailable, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+}
+
+// errJobNotFound responds that the job does not exist
+func errJobNotFound(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusNotFound, fiber.Map{
+		"error":   true,
+		"message": "Job not found",
+	})
+}
+
+// record records an admin action on a job in the audit log
+func (h *Handler) record(c *fiber.Ctx, action audit.Action, job *jobs.Job) {
+	if h.auditService == nil {
+		return
+	}
+
+	var actorID *uuid.UUID
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		actorID = &userID
+	}
+
+	entry := audit.NewEntry(action, actorID)
+	entry.TargetID = &job.ID
+	entry.Details = map[string]string{"type": job.Type}
+	auditHandler.Record(h.auditService, c, entry)
+}
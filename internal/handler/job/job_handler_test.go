@@ -0,0 +1,169 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/jobs"
+	"todo-api/internal/scheduler"
+	auditService "todo-api/internal/service/audit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	johnID  = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	janeID  = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	adminID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440010")
+)
+
+// export is a job failing until the exporter comes back
+type export struct{}
+
+func (export) JobType() string { return "test.export" }
+
+func setupTestApp(t *testing.T) (*fiber.App, jobs.Queue, auditService.Service) {
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, DeadLetterLimit: 10, Retention: time.Hour})
+	t.Cleanup(func() { queue.Shutdown(context.Background()) })
+	auditSvc := auditService.NewService()
+	sched := scheduler.New()
+	sched.Add("github sync", scheduler.Every(time.Hour), func(ctx context.Context) error { return nil })
+	handler := NewHandlerWithDeps(Deps{Jobs: queue, Audit: auditSvc, Scheduler: sched})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id := c.Get("X-User-ID"); id != "" {
+			c.Locals("user_id", uuid.MustParse(id))
+		}
+		return c.Next()
+	})
+	app.Get("/me/jobs/:id", handler.GetJob)
+	app.Get("/admin/jobs/stats", handler.GetStats)
+	app.Get("/admin/jobs/dead", handler.ListDeadLetters)
+	app.Get("/admin/jobs/schedules", handler.ListSchedules)
+	app.Post("/admin/jobs/dead/:id/retry", handler.RetryJob)
+	app.Delete("/admin/jobs/dead/:id", handler.DiscardJob)
+	return app, queue, auditSvc
+}
+
+// send sends a request as the user and returns the response with its decoded body
+func send(t *testing.T, app *fiber.App, method, path string, userID uuid.UUID) (*http.Response, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-User-ID", userID.String())
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp, body
+}
+
+// waitDone waits for the job to stop running
+func waitDone(t *testing.T, queue jobs.Queue, id uuid.UUID) {
+	require.Eventually(t, func() bool {
+		job, err := queue.Get(id)
+		return err == nil && job.Done()
+	}, time.Second, time.Millisecond)
+}
+
+func TestHandler_GetJob(t *testing.T) {
+	app, queue, _ := setupTestApp(t)
+	jobs.Handle(queue, func(ctx context.Context, e export) error { return nil })
+	job, err := queue.Enqueue(export{}, jobs.WithOwner(johnID))
+	require.NoError(t, err)
+	waitDone(t, queue, job.ID)
+
+	resp, body := send(t, app, http.MethodGet, "/me/jobs/"+job.ID.String(), johnID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "succeeded", body["data"].(map[string]interface{})["status"])
+
+	// Jobs of other users, and jobs without an owner, are not found
+	resp, _ = send(t, app, http.MethodGet, "/me/jobs/"+job.ID.String(), janeID)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	unowned, err := queue.Enqueue(export{})
+	require.NoError(t, err)
+	resp, _ = send(t, app, http.MethodGet, "/me/jobs/"+unowned.ID.String(), johnID)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = send(t, app, http.MethodGet, "/me/jobs/not-a-uuid", johnID)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandler_DeadLetters(t *testing.T) {
+	app, queue, auditSvc := setupTestApp(t)
+	fail := true
+	jobs.Handle(queue, func(ctx context.Context, e export) error {
+		if fail {
+			return errors.New("exporter unavailable")
+		}
+		return nil
+	})
+	job, err := queue.Enqueue(export{})
+	require.NoError(t, err)
+	waitDone(t, queue, job.ID)
+
+	resp, body := send(t, app, http.MethodGet, "/admin/jobs/dead", adminID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	dead := body["data"].([]interface{})
+	require.Len(t, dead, 1)
+	assert.Equal(t, "exporter unavailable", dead[0].(map[string]interface{})["last_error"])
+
+	_, body = send(t, app, http.MethodGet, "/admin/jobs/stats", adminID)
+	assert.Equal(t, float64(1), body["data"].(map[string]interface{})["dead"])
+
+	// Retried jobs run again
+	fail = false
+	resp, _ = send(t, app, http.MethodPost, "/admin/jobs/dead/"+job.ID.String()+"/retry", adminID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	waitDone(t, queue, job.ID)
+	retried, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusSucceeded, retried.Status)
+
+	// Only dead jobs are retried or discarded
+	resp, body = send(t, app, http.MethodPost, "/admin/jobs/dead/"+job.ID.String()+"/retry", adminID)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, "job is not in the dead-letter list", body["message"])
+	resp, _ = send(t, app, http.MethodDelete, "/admin/jobs/dead/"+uuid.NewString(), adminID)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	entries, _ := auditSvc.List(&audit.Filter{Action: "job"}, 1, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.ActionJobRetried, entries[0].Action)
+	assert.Equal(t, job.ID, *entries[0].TargetID)
+	assert.Equal(t, adminID, *entries[0].ActorID)
+}
+
+func TestHandler_DiscardJob(t *testing.T) {
+	app, queue, _ := setupTestApp(t)
+	jobs.Handle(queue, func(ctx context.Context, e export) error { return errors.New("exporter unavailable") })
+	job, err := queue.Enqueue(export{})
+	require.NoError(t, err)
+	waitDone(t, queue, job.ID)
+
+	resp, _ := send(t, app, http.MethodDelete, "/admin/jobs/dead/"+job.ID.String(), adminID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, queue.DeadLetters())
+}
+
+func TestHandler_ListSchedules(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+
+	resp, body := send(t, app, http.MethodGet, "/admin/jobs/schedules", adminID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	entries := body["data"].([]interface{})
+	require.Len(t, entries, 1)
+	entry := entries[0].(map[string]interface{})
+	assert.Equal(t, "github sync", entry["name"])
+	assert.Equal(t, "@every 1h0m0s", entry["schedule"])
+	assert.Equal(t, float64(0), entry["failures"])
+}
@@ -0,0 +1,403 @@
+package me
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/domain/device"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/privacy"
+	auditHandler "todo-api/internal/handler/audit"
+	"todo-api/internal/response"
+	auditService "todo-api/internal/service/audit"
+	deviceService "todo-api/internal/service/device"
+	notificationService "todo-api/internal/service/notification"
+	privacyService "todo-api/internal/service/privacy"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests about the authenticated user
+type Handler struct {
+	taskService    taskService.Service
+	privacyService privacyService.Service
+	auditService   auditService.Service        // optional, records account erasures
+	notifications  notificationService.Service // optional, manages notification preferences
+	devices        deviceService.Service       // optional, registers devices for push notifications
+	limits         config.LimitsConfig
+}
+
+// Deps are the dependencies of a me handler. Audit is optional; the other services back the
+// endpoints noted next to each, which must not be routed without them.
+type Deps struct {
+	Tasks         taskService.Service
+	Privacy       privacyService.Service      // data exports and account erasure
+	Audit         auditService.Service        // records account erasures
+	Notifications notificationService.Service // notification preferences
+	Devices       deviceService.Service       // device registration for push notifications
+	Limits        config.LimitsConfig         // usage is reported against these limits
+}
+
+// NewHandler creates a new handler reporting usage against the given limits
+func NewHandler(taskSvc taskService.Service, privacySvc privacyService.Service, limits config.LimitsConfig) *Handler {
+	return NewHandlerWithDeps(Deps{Tasks: taskSvc, Privacy: privacySvc, Limits: limits})
+}
+
+// NewHandlerWithDeps creates a new handler, using the defaults of Deps for the dependencies
+// left unset
+func NewHandlerWithDeps(deps Deps) *Handler {
+	return &Handler{
+		taskService:    deps.Tasks,
+		privacyService: deps.Privacy,
+		auditService:   deps.Audit,
+		notifications:  deps.Notifications,
+		devices:        deps.Devices,
+		limits:         deps.Limits,
+	}
+}
+
+// GetUsage handles reporting the user's current consumption against their limits
+func (h *Handler) GetUsage(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Usage retrieved successfully",
+		"data": fiber.Map{
+			"tasks": h.taskService.GetTaskUsage(userID),
+			"request_body": fiber.Map{
+				"max_bytes": h.limits.MaxBodySize,
+			},
+		},
+	})
+}
+
+// ExportData handles exporting all the data kept about the user, as JSON or as a ZIP archive
+func (h *Handler) ExportData(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "zip" {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Unsupported export format: " + format,
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	export, err := h.privacyService.ExportUserData(userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "User not found",
+		})
+	}
+
+	if format == "json" {
+		return response.Send(c, fiber.StatusOK, fiber.Map{
+			"error":   false,
+			"message": "Data exported successfully",
+			"data":    export,
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Attachment("export-" + export.ExportedAt.UTC().Format("20060102") + ".zip")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.WriteZip(w); err != nil {
+			log.Printf("Data export failed: %v", err)
+		}
+	})
+	return nil
+}
+
+// StartExport handles starting an archive of all the data kept about the user, built in the
+// background. Its status and download URL are read from /me/export/:id.
+func (h *Handler) StartExport(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	archive, err := h.privacyService.StartArchive(userID)
+	if err != nil {
+		return sendArchiveError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusAccepted, fiber.Map{
+		"error":   false,
+		"message": "Export started",
+		"data":    archive,
+	})
+}
+
+// GetExport handles retrieving the status of an archive of the user's data, with its
+// download URL once ready
+func (h *Handler) GetExport(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid export ID",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	archive, err := h.privacyService.GetArchive(id, userID)
+	if err != nil {
+		return sendArchiveError(c, err)
+	}
+
+	// Download URLs are credentials
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Export retrieved successfully",
+		"data":    archive,
+	})
+}
+
+// sendArchiveError responds with the status of an error starting or retrieving an archive
+func sendArchiveError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, privacy.ErrArchiveNotFound):
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Export not found",
+		})
+	case errors.Is(err, privacy.ErrArchivesUnavailable):
+		return response.Send(c, fiber.StatusServiceUnavailable, fiber.Map{
+			"error":   true,
+			"message": "Data export archives are unavailable, use GET /me/export instead",
+		})
+	default:
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "User not found",
+		})
+	}
+}
+
+// DeleteAccount handles erasing the user's account and data
+func (h *Handler) DeleteAccount(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	record, err := h.privacyService.EraseUser(userID)
+	if err != nil {
+		switch err.Error() {
+		case "account owns workspaces with other members":
+			return response.Send(c, fiber.StatusConflict, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		case "user not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "User not found",
+			})
+		default:
+			return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+				"error":   true,
+				"message": "Failed to delete account",
+			})
+		}
+	}
+
+	entry := audit.NewEntry(audit.ActionAccountErased, &userID)
+	entry.TargetID = &userID
+	entry.Details = map[string]string{
+		"tasks_deleted":      strconv.Itoa(record.TasksDeleted),
+		"tasks_anonymized":   strconv.Itoa(record.TasksAnonymized),
+		"workspaces_deleted": strconv.Itoa(record.WorkspacesDeleted),
+	}
+	auditHandler.Record(h.auditService, c, entry)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Account deleted successfully",
+		"data":    record,
+	})
+}
+
+// GetNotificationPreferences handles retrieving the emails the user opted into
+func (h *Handler) GetNotificationPreferences(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Notification preferences retrieved successfully",
+		"data":    h.notifications.GetPreferences(userID),
+	})
+}
+
+// UpdateNotificationPreferences handles opting into or out of reminder and digest emails, and
+// setting the time zone of the user
+func (h *Handler) UpdateNotificationPreferences(c *fiber.Ctx) error {
+	var req notification.UpdatePreferencesRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Notification preferences updated successfully",
+		"data":    h.notifications.UpdatePreferences(userID, &req),
+	})
+}
+
+// GetDigest handles retrieving the summary of the user's open tasks due today or overdue,
+// as sent in their digest emails
+func (h *Handler) GetDigest(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Digest retrieved successfully",
+		"data":    h.notifications.Digest(userID, time.Now()),
+	})
+}
+
+// GetNotificationSettings handles retrieving whether the user wants notifications of each
+// event through each channel
+func (h *Handler) GetNotificationSettings(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Notification settings retrieved successfully",
+		"data":    h.notifications.GetSettings(userID),
+	})
+}
+
+// UpdateNotificationSettings handles turning notifications of events through channels on or
+// off
+func (h *Handler) UpdateNotificationSettings(c *fiber.Ctx) error {
+	var req notification.UpdateSettingsRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Notification settings updated successfully",
+		"data":    h.notifications.UpdateSettings(userID, req),
+	})
+}
+
+// RegisterDevice handles registering a device for push notifications. Registering a known
+// token refreshes its device.
+func (h *Handler) RegisterDevice(c *fiber.Ctx) error {
+	var req device.RegisterDeviceRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	registered, created, err := h.devices.RegisterDevice(userID, &req)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	if !created {
+		return response.Send(c, fiber.StatusOK, fiber.Map{
+			"error":   false,
+			"message": "Device updated successfully",
+			"data":    registered,
+		})
+	}
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Device registered successfully",
+		"data":    registered,
+	})
+}
+
+// ListDevices handles retrieving the devices registered for push notifications
+func (h *Handler) ListDevices(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Devices retrieved successfully",
+		"data":    h.devices.ListDevices(userID),
+	})
+}
+
+// RemoveDevice handles unregistering a device, e.g. when the user signs out of the app
+func (h *Handler) RemoveDevice(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid device ID",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.devices.RemoveDevice(userID, id); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Device not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Device removed successfully",
+	})
+}
@@ -0,0 +1,374 @@
+package me
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/device"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	"todo-api/internal/service/auth"
+	deviceService "todo-api/internal/service/device"
+	notificationService "todo-api/internal/service/notification"
+	privacyService "todo-api/internal/service/privacy"
+	taskService "todo-api/internal/service/task"
+	tenantService "todo-api/internal/service/tenant"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/blob"
+	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+	"todo-api/pkg/push"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetUsage(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		Limits: config.LimitsConfig{MaxTasksPerUser: 10, MaxBodySize: 1024},
+	}
+
+	authSvc := auth.NewService(cfg)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceService.NewService(authSvc),
+		Tenants: tenantService.NewService(authSvc), Limits: task.Limits{MaxTasks: cfg.Limits.MaxTasksPerUser}})
+	handler := NewHandler(taskSvc, nil, cfg.Limits)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Get("/me/usage", handler.GetUsage)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/me/usage", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+	// John owns three fixture tasks
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"used": float64(3), "limit": float64(10), "remaining": float64(7)}, data["tasks"])
+	assert.Equal(t, map[string]interface{}{"max_bytes": float64(1024)}, data["request_body"])
+}
+
+func TestHandler_ExportAndDeleteAccount(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	workspaceSvc := workspaceService.NewService(authSvc)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceSvc})
+	handler := NewHandler(taskSvc, privacyService.NewService(authSvc, taskSvc, workspaceSvc), cfg.Limits)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Get("/me/export", handler.ExportData)
+	app.Delete("/me", handler.DeleteAccount)
+
+	send := func(method, path string) (*http.Response, map[string]interface{}) {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil))
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		if resp.Header.Get("Content-Type") == "application/json" {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		}
+		return resp, response
+	}
+
+	resp, response := send(http.MethodGet, "/me/export")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "john.doe@example.com", data["profile"].(map[string]interface{})["email"])
+	assert.Len(t, data["tasks"], 3)
+	assert.Equal(t, []interface{}{}, data["workspaces"])
+
+	resp, _ = send(http.MethodGet, "/me/export?format=zip")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	assert.Len(t, archive.File, 6)
+
+	resp, _ = send(http.MethodGet, "/me/export?format=xml")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, response = send(http.MethodDelete, "/me")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Account deleted successfully", response["message"])
+	assert.Equal(t, float64(3), response["data"].(map[string]interface{})["tasks_deleted"])
+
+	resp, _ = send(http.MethodDelete, "/me")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = send(http.MethodGet, "/me/export")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_Export_Async(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute}}
+	authSvc := auth.NewService(cfg)
+	workspaceSvc := workspaceService.NewService(authSvc)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceSvc})
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	privacySvc := privacyService.NewServiceWithDeps(privacyService.Deps{Auth: authSvc, Tasks: taskSvc, Workspaces: workspaceSvc,
+		Store: blob.NewMemoryStore(), Jobs: queue, ArchiveTTL: time.Hour})
+	handler := NewHandler(taskSvc, privacySvc, cfg.Limits)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Post("/me/export", handler.StartExport)
+	app.Get("/me/export/:id", handler.GetExport)
+
+	send := func(method, path string) (*http.Response, map[string]interface{}) {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil))
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	resp, response := send(http.MethodPost, "/me/export")
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	id := response["data"].(map[string]interface{})["id"].(string)
+
+	var data map[string]interface{}
+	require.Eventually(t, func() bool {
+		resp, response = send(http.MethodGet, "/me/export/"+id)
+		data, _ = response["data"].(map[string]interface{})
+		return resp.StatusCode == http.StatusOK && data["status"] == "ready"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	assert.Contains(t, data["download_url"], "memory:///exports/")
+
+	resp, _ = send(http.MethodGet, "/me/export/"+uuid.NewString())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = send(http.MethodGet, "/me/export/invalid")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Archives need object storage
+	handler = NewHandler(taskSvc, privacyService.NewService(authSvc, taskSvc, workspaceSvc), cfg.Limits)
+	app.Post("/me/export/sync-only", handler.StartExport)
+	resp, _ = send(http.MethodPost, "/me/export/sync-only")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestHandler_NotificationPreferences(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	notificationSvc := notificationService.NewService(cfg, authSvc, taskSvc, mailer.NewMemoryMailer())
+	handler := NewHandlerWithDeps(Deps{Tasks: taskSvc, Notifications: notificationSvc, Limits: cfg.Limits})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Get("/me/notifications", handler.GetNotificationPreferences)
+	app.Put("/me/notifications", handler.UpdateNotificationPreferences)
+
+	preferences := func(req *http.Request) map[string]interface{} {
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return response["data"].(map[string]interface{})
+	}
+
+	// Users are opted into every email by default
+	data := preferences(httptest.NewRequest(http.MethodGet, "/me/notifications", nil))
+	assert.Equal(t, true, data["reminders"])
+	assert.Equal(t, true, data["digest"])
+
+	// Omitted preferences are left unchanged
+	preferences(httptest.NewRequest(http.MethodPut, "/me/notifications", bytes.NewBufferString(`{"digest":false}`)))
+	data = preferences(httptest.NewRequest(http.MethodGet, "/me/notifications", nil))
+	assert.Equal(t, true, data["reminders"])
+	assert.Equal(t, false, data["digest"])
+}
+
+func TestHandler_Digest(t *testing.T) {
+	cfg := &config.Config{}
+	authSvc := auth.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	notificationSvc := notificationService.NewService(cfg, authSvc, taskSvc, mailer.NewMemoryMailer())
+	handler := NewHandlerWithDeps(Deps{Tasks: taskSvc, Notifications: notificationSvc, Limits: cfg.Limits})
+
+	john := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	due := time.Now().Add(-time.Minute)
+	_, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Call the bank", DueDate: &due}, john)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", john)
+		return c.Next()
+	})
+	app.Get("/me/digest", handler.GetDigest)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/me/digest", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data notification.Digest `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, time.Now().UTC().Format(time.DateOnly), body.Data.Date)
+	assert.Equal(t, len(taskSvc.OpenTasks(john)), body.Data.Open)
+	require.Len(t, body.Data.Overdue, 1)
+	assert.Equal(t, "Call the bank", body.Data.Overdue[0].Title)
+	assert.NotNil(t, body.Data.DueToday)
+}
+
+func TestHandler_NotificationSettings(t *testing.T) {
+	cfg := &config.Config{}
+	authSvc := auth.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	notificationSvc := notificationService.NewService(cfg, authSvc, taskSvc, mailer.NewMemoryMailer())
+	handler := NewHandlerWithDeps(Deps{Tasks: taskSvc, Notifications: notificationSvc, Limits: cfg.Limits})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Get("/me/notification-settings", handler.GetNotificationSettings)
+	app.Put("/me/notification-settings", handler.UpdateNotificationSettings)
+	app.Get("/me/notifications", handler.GetNotificationPreferences)
+
+	send := func(method, target, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	// Every notification is on by default
+	status, response := send(http.MethodGet, "/me/notification-settings", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{"push": true, "slack": true}, response["data"].(map[string]interface{})["assigned"])
+
+	status, response = send(http.MethodPut, "/me/notification-settings", `{"assigned":{"slack":false},"digest":{"email":false}}`)
+	require.Equal(t, http.StatusOK, status)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"push": true, "slack": false}, data["assigned"])
+	assert.Equal(t, map[string]interface{}{"email": false}, data["digest"])
+
+	// Digest emails are the same preference in both APIs
+	_, response = send(http.MethodGet, "/me/notifications", "")
+	assert.Equal(t, false, response["data"].(map[string]interface{})["digest"])
+
+	status, response = send(http.MethodPut, "/me/notification-settings", `{"completed":{"email":true}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "completed notifications are not sent through email", response["message"])
+}
+
+func TestHandler_Devices(t *testing.T) {
+	cfg := &config.Config{}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := auth.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	deviceSvc := deviceService.NewService(map[string]push.Sender{device.PlatformAndroid: push.NewMemorySender()}, bus)
+	handler := NewHandlerWithDeps(Deps{Tasks: taskSvc, Devices: deviceSvc, Limits: cfg.Limits})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Post("/me/devices", handler.RegisterDevice)
+	app.Get("/me/devices", handler.ListDevices)
+	app.Delete("/me/devices/:id", handler.RemoveDevice)
+
+	send := func(method, target, body string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	resp, _ := send(http.MethodPost, "/me/devices", `{"platform":"web","token":"token"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, body := send(http.MethodPost, "/me/devices", `{"platform":"android","token":"fcm-token","name":"Pixel"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, "android", data["platform"])
+	assert.Equal(t, "Pixel", data["name"])
+	assert.NotContains(t, data, "token")
+	id := data["id"].(string)
+
+	// Registering the token again refreshes the device
+	resp, body = send(http.MethodPost, "/me/devices", `{"platform":"android","token":"fcm-token"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, id, body["data"].(map[string]interface{})["id"])
+
+	_, body = send(http.MethodGet, "/me/devices", "")
+	assert.Len(t, body["data"], 1)
+
+	resp, _ = send(http.MethodDelete, "/me/devices/"+id, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = send(http.MethodDelete, "/me/devices/"+id, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"errors"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+	reportService "todo-api/internal/service/report"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles the reports of workspaces and the stats webhooks of users. Report routes
+// expect the workspace_id local set by the WorkspaceMember middleware.
+type Handler struct {
+	reportService reportService.Service
+}
+
+// NewHandler creates a new report handler instance
+func NewHandler(reportSvc reportService.Service) *Handler {
+	return &Handler{
+		reportService: reportSvc,
+	}
+}
+
+// GetBurndown handles retrieving the daily open and closed tasks of a project and its
+// completion velocity, over the last 30 days by default
+func (h *Handler) GetBurndown(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("projectId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid project ID",
+		})
+	}
+
+	r, err := parseRange(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	workspaceID := c.Locals("workspace_id").(uuid.UUID)
+	burndown, err := h.reportService.Burndown(workspaceID, projectID, r, time.Now())
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, reportService.ErrProjectNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Burndown retrieved successfully",
+		"data":    burndown,
+	})
+}
+
+// parseRange parses the from and to parameters of a report, ending today and covering 30
+// days by default
+func parseRange(c *fiber.Ctx) (*task.StatsRange, error) {
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		defaultFrom := to.AddDate(0, 0, -29)
+		from = &defaultFrom
+	}
+
+	return task.NewStatsRange(*from, *to)
+}
+
+// parseTimeQuery parses a time query parameter as an RFC 3339 timestamp or a date,
+// returning nil when it is empty
+func parseTimeQuery(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+
+	return nil, errors.New("invalid " + key + ": expected RFC 3339 timestamp or YYYY-MM-DD date")
+}
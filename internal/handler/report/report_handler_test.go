@@ -0,0 +1,103 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	"todo-api/internal/middleware"
+	"todo-api/internal/service/auth"
+	reportService "todo-api/internal/service/report"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	johnID = "3484ec33-20f9-4993-a25f-f49f6f5dbe54"
+	janeID = "550e8400-e29b-41d4-a716-446655440002"
+)
+
+func TestHandler_GetBurndown(t *testing.T) {
+	authSvc := auth.NewService(&config.Config{})
+	workspaceSvc := workspaceService.NewService(authSvc)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceSvc})
+	handler := NewHandler(reportService.NewService(taskSvc, workspaceSvc))
+
+	// John owns the workspace and its project, with a completed and an open task
+	created, err := workspaceSvc.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, uuid.MustParse(johnID))
+	require.NoError(t, err)
+	project, err := workspaceSvc.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "Launch"}, uuid.MustParse(johnID))
+	require.NoError(t, err)
+	for _, title := range []string{"Book venue", "Send invites"} {
+		_, err := taskSvc.CreateWorkspaceTask(created.ID, &task.CreateTaskRequest{Title: title, ProjectID: &project.ID}, uuid.MustParse(johnID))
+		require.NoError(t, err)
+	}
+	done, err := taskSvc.CreateWorkspaceTask(created.ID, &task.CreateTaskRequest{Title: "Pick date", ProjectID: &project.ID}, uuid.MustParse(johnID))
+	require.NoError(t, err)
+	_, err = taskSvc.CompleteTask(done.ID, uuid.MustParse(johnID))
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse(c.Get("X-User-ID", johnID)))
+		return c.Next()
+	})
+	scoped := app.Group("/workspaces/:wid", middleware.WorkspaceMember(workspaceSvc))
+	scoped.Get("/projects/:projectId/burndown", handler.GetBurndown)
+
+	send := func(path, userID string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/workspaces/"+created.ID.String()+path, nil)
+		req.Header.Set("X-User-ID", userID)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	// The last 30 days by default, ending today
+	status, response := send("/projects/"+project.ID.String()+"/burndown", johnID)
+	require.Equal(t, http.StatusOK, status)
+	data := response["data"].(map[string]interface{})
+	days := data["days"].([]interface{})
+	require.Len(t, days, 30)
+	today := days[29].(map[string]interface{})
+	assert.Equal(t, time.Now().UTC().Format(time.DateOnly), today["date"])
+	assert.Equal(t, float64(2), today["open"])
+	assert.Equal(t, float64(1), today["closed"])
+	velocity := data["velocity"].(map[string]interface{})
+	assert.Equal(t, float64(1), velocity["completed"])
+	assert.Equal(t, float64(60), velocity["days_to_finish"])
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	status, response = send("/projects/"+project.ID.String()+"/burndown?from="+yesterday, johnID)
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["data"].(map[string]interface{})["days"], 2)
+
+	status, response = send("/projects/"+project.ID.String()+"/burndown?from=yesterday", johnID)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid from: expected RFC 3339 timestamp or YYYY-MM-DD date", response["message"])
+
+	status, _ = send("/projects/"+uuid.NewString()+"/burndown", johnID)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = send("/projects/not-a-uuid/burndown", johnID)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// The workspace is not found for users who are not members
+	status, _ = send("/projects/"+project.ID.String()+"/burndown", janeID)
+	assert.Equal(t, http.StatusNotFound, status)
+}
@@ -0,0 +1,171 @@
+package report
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/report"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ListWebhooks handles listing the user's stats webhooks
+func (h *Handler) ListWebhooks(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Stats webhooks retrieved successfully",
+		"data":    h.reportService.ListWebhooks(userID),
+	})
+}
+
+// CreateWebhook handles creating a stats webhook, responding with its signing secret
+func (h *Handler) CreateWebhook(c *fiber.Ctx) error {
+	var req report.WebhookRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	created, err := h.reportService.CreateWebhook(userID, &req)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Stats webhook created successfully",
+		"data":    created,
+	})
+}
+
+// GetWebhook handles retrieving a stats webhook
+func (h *Handler) GetWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	w, err := h.reportService.GetWebhook(userID, id)
+	if err != nil {
+		return sendWebhookError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Stats webhook retrieved successfully",
+		"data":    w,
+	})
+}
+
+// UpdateWebhook handles replacing the settings of a stats webhook
+func (h *Handler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	var req report.WebhookRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	w, err := h.reportService.UpdateWebhook(userID, id, &req)
+	if err != nil {
+		return sendWebhookError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Stats webhook updated successfully",
+		"data":    w,
+	})
+}
+
+// RotateWebhookSecret handles replacing the signing secret of a stats webhook, responding
+// with the new secret
+func (h *Handler) RotateWebhookSecret(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	rotated, err := h.reportService.RotateWebhookSecret(userID, id)
+	if err != nil {
+		return sendWebhookError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Stats webhook secret rotated successfully",
+		"data":    rotated,
+	})
+}
+
+// DeleteWebhook handles deleting a stats webhook
+func (h *Handler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.reportService.DeleteWebhook(userID, id); err != nil {
+		return sendWebhookError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Stats webhook deleted successfully",
+	})
+}
+
+// sendWebhookError responds with the status of the error: 404 for unknown webhooks and 400
+// otherwise
+func sendWebhookError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	if errors.Is(err, report.ErrWebhookNotFound) {
+		status = fiber.StatusNotFound
+	}
+	return response.Send(c, status, fiber.Map{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
+
+// invalidWebhookID responds to a malformed webhook ID
+func invalidWebhookID(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+		"error":   true,
+		"message": "Invalid webhook ID",
+	})
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"todo-api/internal/service/auth"
+	reportService "todo-api/internal/service/report"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Webhooks(t *testing.T) {
+	authSvc := auth.NewService(&config.Config{})
+	workspaceSvc := workspaceService.NewService(authSvc)
+	handler := NewHandler(reportService.NewService(taskService.NewService(authSvc), workspaceSvc))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse(c.Get("X-User-ID", johnID)))
+		return c.Next()
+	})
+	app.Get("/me/stats-webhooks", handler.ListWebhooks)
+	app.Post("/me/stats-webhooks", handler.CreateWebhook)
+	app.Get("/me/stats-webhooks/:id", handler.GetWebhook)
+	app.Put("/me/stats-webhooks/:id", handler.UpdateWebhook)
+	app.Post("/me/stats-webhooks/:id/rotate-secret", handler.RotateWebhookSecret)
+	app.Delete("/me/stats-webhooks/:id", handler.DeleteWebhook)
+
+	send := func(method, path, body, userID string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	status, response := send(http.MethodPost, "/me/stats-webhooks", `{"url":"https://bi.example.com","frequency":"hourly"}`, johnID)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "frequency must be daily or weekly", response["message"])
+
+	// The secret is only returned when the webhook is created
+	status, response = send(http.MethodPost, "/me/stats-webhooks", `{"url":"https://bi.example.com/ingest","frequency":"weekly"}`, johnID)
+	require.Equal(t, http.StatusCreated, status)
+	data := response["data"].(map[string]interface{})
+	assert.True(t, strings.HasPrefix(data["secret"].(string), "whsec_"))
+	assert.Equal(t, "weekly", data["frequency"])
+	assert.Nil(t, data["last_delivery"])
+	id := data["id"].(string)
+	secret := data["secret"].(string)
+
+	// Rotating returns a new secret, the previous one expiring a day later
+	status, response = send(http.MethodPost, "/me/stats-webhooks/"+id+"/rotate-secret", "", johnID)
+	require.Equal(t, http.StatusOK, status)
+	data = response["data"].(map[string]interface{})
+	assert.True(t, strings.HasPrefix(data["secret"].(string), "whsec_"))
+	assert.NotEqual(t, secret, data["secret"])
+	assert.NotEmpty(t, data["previous_secret_expires_at"])
+	status, _ = send(http.MethodPost, "/me/stats-webhooks/"+id+"/rotate-secret", "", janeID)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, response = send(http.MethodGet, "/me/stats-webhooks/"+id, "", johnID)
+	require.Equal(t, http.StatusOK, status)
+	assert.NotContains(t, response["data"], "secret")
+
+	status, response = send(http.MethodPut, "/me/stats-webhooks/"+id, `{"url":"https://bi.example.com/v2","enabled":false}`, johnID)
+	require.Equal(t, http.StatusOK, status)
+	data = response["data"].(map[string]interface{})
+	assert.Equal(t, "daily", data["frequency"])
+	assert.Equal(t, false, data["enabled"])
+
+	// Webhooks of other users are not found
+	status, _ = send(http.MethodGet, "/me/stats-webhooks/"+id, "", janeID)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, response = send(http.MethodGet, "/me/stats-webhooks", "", janeID)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{}, response["data"])
+	status, _ = send(http.MethodDelete, "/me/stats-webhooks/not-a-uuid", "", johnID)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = send(http.MethodDelete, "/me/stats-webhooks/"+id, "", johnID)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = send(http.MethodGet, "/me/stats-webhooks/"+id, "", johnID)
+	assert.Equal(t, http.StatusNotFound, status)
+}
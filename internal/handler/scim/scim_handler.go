@@ -0,0 +1,236 @@
+package scim
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"strings"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/domain/scim"
+	auditHandler "todo-api/internal/handler/audit"
+	auditService "todo-api/internal/service/audit"
+	scimService "todo-api/internal/service/scim"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// auditSubject identifies changes made by the identity provider in the audit log
+const auditSubject = "scim"
+
+// Handler handles the SCIM 2.0 endpoints identity providers provision users with. Responses
+// use the SCIM media type and error format rather than the API envelope, and requests are
+// authenticated by the bearer token of the identity provider rather than user tokens.
+type Handler struct {
+	scimService  scimService.Service
+	auditService auditService.Service // optional, records provisioning changes
+	token        string
+}
+
+// NewHandler creates a new SCIM handler instance accepting the bearer token
+func NewHandler(scimSvc scimService.Service, auditSvc auditService.Service, token string) *Handler {
+	return &Handler{
+		scimService:  scimSvc,
+		auditService: auditSvc,
+		token:        token,
+	}
+}
+
+// Authenticate rejects requests without the bearer token of the identity provider
+func (h *Handler) Authenticate(c *fiber.Ctx) error {
+	token, found := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !found || h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return sendError(c, fiber.StatusUnauthorized, "", "Invalid or missing bearer token")
+	}
+	return c.Next()
+}
+
+// ServiceProviderConfig handles describing the SCIM features supported
+func (h *Handler) ServiceProviderConfig(c *fiber.Ctx) error {
+	unsupported := fiber.Map{"supported": false}
+	return send(c, fiber.StatusOK, fiber.Map{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          fiber.Map{"supported": true},
+		"bulk":           fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         fiber.Map{"supported": true, "maxResults": scim.MaxCount},
+		"changePassword": unsupported,
+		"sort":           unsupported,
+		"etag":           unsupported,
+		"authenticationSchemes": []fiber.Map{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Authentication with the token configured as SCIM_TOKEN",
+			"primary":     true,
+		}},
+	})
+}
+
+// ListUsers handles listing users, optionally filtered with an eq filter such as
+// userName eq "jane@example.com"
+func (h *Handler) ListUsers(c *fiber.Ctx) error {
+	filter, err := scim.ParseFilter(c.Query("filter"))
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	startIndex := max(c.QueryInt("startIndex", 1), 1)
+	count := min(max(c.QueryInt("count", scim.DefaultCount), 0), scim.MaxCount)
+	return send(c, fiber.StatusOK, h.scimService.ListUsers(filter, startIndex, count))
+}
+
+// CreateUser handles provisioning a user
+func (h *Handler) CreateUser(c *fiber.Ctx) error {
+	var req scim.User
+	if err := c.BodyParser(&req); err != nil {
+		return sendError(c, fiber.StatusBadRequest, "invalidSyntax", "Invalid request body")
+	}
+
+	created, err := h.scimService.CreateUser(&req)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	h.record(c, audit.ActionAccountProvisioned, created)
+	c.Set(fiber.HeaderLocation, created.Meta.Location)
+	return send(c, fiber.StatusCreated, created)
+}
+
+// GetUser handles retrieving a user
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return sendServiceError(c, scim.ErrUserNotFound)
+	}
+
+	user, err := h.scimService.GetUser(userID)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	return send(c, fiber.StatusOK, user)
+}
+
+// ReplaceUser handles replacing every attribute of a user
+func (h *Handler) ReplaceUser(c *fiber.Ctx) error {
+	var req scim.User
+	if err := c.BodyParser(&req); err != nil {
+		return sendError(c, fiber.StatusBadRequest, "invalidSyntax", "Invalid request body")
+	}
+
+	return h.update(c, func(userID uuid.UUID) (*scim.User, error) {
+		return h.scimService.ReplaceUser(userID, &req)
+	})
+}
+
+// PatchUser handles changing some attributes of a user, such as deactivating them
+func (h *Handler) PatchUser(c *fiber.Ctx) error {
+	var req scim.PatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return sendError(c, fiber.StatusBadRequest, "invalidSyntax", "Invalid request body")
+	}
+
+	return h.update(c, func(userID uuid.UUID) (*scim.User, error) {
+		return h.scimService.PatchUser(userID, &req)
+	})
+}
+
+// DeleteUser handles deprovisioning a user, erasing their account and data
+func (h *Handler) DeleteUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return sendServiceError(c, scim.ErrUserNotFound)
+	}
+
+	record, err := h.scimService.DeleteUser(userID)
+	if err != nil {
+		if errors.Is(err, scim.ErrUserNotFound) {
+			return sendServiceError(c, err)
+		}
+		// Such as owning workspaces with other members
+		return sendError(c, fiber.StatusConflict, "", err.Error())
+	}
+
+	entry := audit.NewEntry(audit.ActionAccountErased, nil)
+	entry.Subject = auditSubject
+	entry.TargetID = &userID
+	entry.Details = map[string]string{
+		"tasks_deleted":      strconv.Itoa(record.TasksDeleted),
+		"tasks_anonymized":   strconv.Itoa(record.TasksAnonymized),
+		"workspaces_deleted": strconv.Itoa(record.WorkspacesDeleted),
+	}
+	auditHandler.Record(h.auditService, c, entry)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// update applies a change to the user of the request, recording whether it deactivated or
+// reactivated them
+func (h *Handler) update(c *fiber.Ctx, change func(userID uuid.UUID) (*scim.User, error)) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return sendServiceError(c, scim.ErrUserNotFound)
+	}
+
+	before, err := h.scimService.GetUser(userID)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	after, err := change(userID)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	action := audit.ActionAccountUpdated
+	switch {
+	case before.IsActive() && !after.IsActive():
+		action = audit.ActionAccountDeactivated
+	case !before.IsActive() && after.IsActive():
+		action = audit.ActionAccountReactivated
+	}
+	h.record(c, action, after)
+
+	return send(c, fiber.StatusOK, after)
+}
+
+// record records a change of the identity provider to a user in the audit log
+func (h *Handler) record(c *fiber.Ctx, action audit.Action, user *scim.User) {
+	entry := audit.NewEntry(action, nil)
+	entry.Subject = auditSubject
+	if userID, err := uuid.Parse(user.ID); err == nil {
+		entry.TargetID = &userID
+	}
+	entry.Details = map[string]string{"user_name": user.UserName}
+	if user.ExternalID != "" {
+		entry.Details["external_id"] = user.ExternalID
+	}
+	auditHandler.Record(h.auditService, c, entry)
+}
+
+// sendServiceError sends the SCIM error of a service error
+func sendServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, scim.ErrUserNotFound):
+		return sendError(c, fiber.StatusNotFound, "", "User not found")
+	case errors.Is(err, scim.ErrUniqueness):
+		return sendError(c, fiber.StatusConflict, "uniqueness", err.Error())
+	case errors.Is(err, scim.ErrInvalidFilter):
+		return sendError(c, fiber.StatusBadRequest, "invalidFilter", err.Error())
+	case errors.Is(err, scim.ErrInvalidPath):
+		return sendError(c, fiber.StatusBadRequest, "invalidPath", err.Error())
+	case errors.Is(err, scim.ErrInvalidValue):
+		return sendError(c, fiber.StatusBadRequest, "invalidValue", err.Error())
+	default:
+		return sendError(c, fiber.StatusInternalServerError, "", "Failed to provision user")
+	}
+}
+
+// sendError sends a SCIM error
+func sendError(c *fiber.Ctx, status int, scimType, detail string) error {
+	return send(c, status, scim.NewError(status, scimType, detail))
+}
+
+// send sends a SCIM response
+func send(c *fiber.Ctx, status int, body interface{}) error {
+	return c.Status(status).JSON(body, scim.ContentType)
+}
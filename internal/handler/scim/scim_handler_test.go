@@ -0,0 +1,171 @@
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/domain/scim"
+	"todo-api/internal/events"
+	auditService "todo-api/internal/service/audit"
+	authService "todo-api/internal/service/auth"
+	privacyService "todo-api/internal/service/privacy"
+	scimService "todo-api/internal/service/scim"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "scim-test-token-0123456789abcdef"
+
+func setupTestApp(t *testing.T) (*fiber.App, auditService.Service) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "https://todo.example.com"},
+		JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute},
+	}
+	authSvc := authService.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+	tasks := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces})
+	auditSvc := auditService.NewService()
+	handler := NewHandler(scimService.NewService(cfg, authSvc, privacyService.NewService(authSvc, tasks, workspaces)), auditSvc, testToken)
+
+	app := fiber.New()
+	v2 := app.Group("/scim/v2", handler.Authenticate)
+	v2.Get("/ServiceProviderConfig", handler.ServiceProviderConfig)
+	v2.Get("/Users", handler.ListUsers)
+	v2.Post("/Users", handler.CreateUser)
+	v2.Get("/Users/:id", handler.GetUser)
+	v2.Put("/Users/:id", handler.ReplaceUser)
+	v2.Patch("/Users/:id", handler.PatchUser)
+	v2.Delete("/Users/:id", handler.DeleteUser)
+	return app, auditSvc
+}
+
+// request sends a SCIM request with the token and returns the response with its decoded body
+func request(t *testing.T, app *fiber.App, method, path, token, body string) (*http.Response, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", scim.ContentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	if resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	}
+	return resp, decoded
+}
+
+func TestHandler_Authenticate(t *testing.T) {
+	app, _ := setupTestApp(t)
+
+	for _, token := range []string{"", "wrong-token"} {
+		resp, body := request(t, app, http.MethodGet, "/scim/v2/Users", token, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+		assert.Equal(t, []interface{}{scim.SchemaError}, body["schemas"])
+		assert.Equal(t, "401", body["status"])
+	}
+
+	resp, body := request(t, app, http.MethodGet, "/scim/v2/ServiceProviderConfig", testToken, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, scim.ContentType, resp.Header.Get("Content-Type"))
+	assert.Equal(t, true, body["patch"].(map[string]interface{})["supported"])
+}
+
+func TestHandler_ProvisioningLifecycle(t *testing.T) {
+	app, auditSvc := setupTestApp(t)
+
+	// Identity providers look users up before creating them
+	filter := url.QueryEscape(`userName eq "new.hire@example.com"`)
+	resp, body := request(t, app, http.MethodGet, "/scim/v2/Users?filter="+filter, testToken, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(0), body["totalResults"])
+
+	resp, body = request(t, app, http.MethodPost, "/scim/v2/Users", testToken, `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "new.hire@example.com",
+		"externalId": "00u1",
+		"name": {"givenName": "New", "familyName": "Hire"},
+		"emails": [{"value": "new.hire@example.com", "primary": true}],
+		"active": true
+	}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	id := body["id"].(string)
+	assert.Equal(t, "https://todo.example.com/scim/v2/Users/"+id, resp.Header.Get("Location"))
+	assert.Equal(t, "00u1", body["externalId"])
+
+	resp, body = request(t, app, http.MethodPost, "/scim/v2/Users", testToken, `{"userName": "new.hire@example.com"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, "uniqueness", body["scimType"])
+
+	resp, body = request(t, app, http.MethodGet, "/scim/v2/Users?filter="+filter, testToken, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(1), body["totalResults"])
+
+	resp, body = request(t, app, http.MethodPatch, "/scim/v2/Users/"+id, testToken, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [{"op": "replace", "value": {"active": false}}]
+	}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, false, body["active"])
+
+	resp, body = request(t, app, http.MethodPut, "/scim/v2/Users/"+id, testToken, `{"userName": "new.hire@example.com", "active": true}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, true, body["active"])
+	assert.Nil(t, body["externalId"])
+
+	resp, _ = request(t, app, http.MethodDelete, "/scim/v2/Users/"+id, testToken, "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, body = request(t, app, http.MethodGet, "/scim/v2/Users/"+id, testToken, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "404", body["status"])
+
+	entries, _ := auditSvc.List(&audit.Filter{Action: "account"}, 1, 10)
+	var actions []audit.Action
+	for _, entry := range entries {
+		assert.Equal(t, "scim", entry.Subject)
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, []audit.Action{
+		audit.ActionAccountErased,
+		audit.ActionAccountReactivated,
+		audit.ActionAccountDeactivated,
+		audit.ActionAccountProvisioned,
+	}, actions)
+}
+
+func TestHandler_InvalidRequests(t *testing.T) {
+	app, _ := setupTestApp(t)
+
+	resp, body := request(t, app, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`userName sw "j"`), testToken, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalidFilter", body["scimType"])
+
+	resp, body = request(t, app, http.MethodPost, "/scim/v2/Users", testToken, `{"userName": "jane"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalidValue", body["scimType"])
+
+	resp, body = request(t, app, http.MethodPatch, "/scim/v2/Users/3484ec33-20f9-4993-a25f-f49f6f5dbe54", testToken,
+		`{"Operations": [{"op": "replace", "path": "nickName", "value": "JD"}]}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalidPath", body["scimType"])
+
+	resp, _ = request(t, app, http.MethodGet, "/scim/v2/Users/not-a-uuid", testToken, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,114 @@
+package task
+
+import (
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AddChecklistItem handles appending an item to a task's checklist
+func (h *Handler) AddChecklistItem(c *fiber.Ctx) error {
+	var req task.AddChecklistItemRequest
+
+	return h.changeChecklist(c, &req, false, fiber.StatusCreated, "Checklist item added successfully",
+		func(taskID, _ uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+			return h.taskService.AddChecklistItem(taskID, &req, userID)
+		})
+}
+
+// UpdateChecklistItem handles editing or toggling a checklist item
+func (h *Handler) UpdateChecklistItem(c *fiber.Ctx) error {
+	var req task.UpdateChecklistItemRequest
+
+	return h.changeChecklist(c, &req, true, fiber.StatusOK, "Checklist item updated successfully",
+		func(taskID, itemID uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+			return h.taskService.UpdateChecklistItem(taskID, itemID, &req, userID)
+		})
+}
+
+// MoveChecklistItem handles reordering a checklist item
+func (h *Handler) MoveChecklistItem(c *fiber.Ctx) error {
+	var req task.MoveChecklistItemRequest
+
+	return h.changeChecklist(c, &req, true, fiber.StatusOK, "Checklist item moved successfully",
+		func(taskID, itemID uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+			return h.taskService.MoveChecklistItem(taskID, itemID, &req, userID)
+		})
+}
+
+// RemoveChecklistItem handles deleting a checklist item
+func (h *Handler) RemoveChecklistItem(c *fiber.Ctx) error {
+	return h.changeChecklist(c, nil, true, fiber.StatusOK, "Checklist item removed successfully",
+		func(taskID, itemID uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+			return h.taskService.RemoveChecklistItem(taskID, itemID, userID)
+		})
+}
+
+// changeChecklist parses the task ID, the optional item ID and request body, and applies a checklist change
+func (h *Handler) changeChecklist(c *fiber.Ctx, req interface{}, hasItem bool, status int, message string,
+	change func(taskID, itemID uuid.UUID, userID uuid.UUID) (*task.Task, error)) error {
+	// Parse task ID from URL parameter
+	taskID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid task ID",
+		})
+	}
+
+	var itemID uuid.UUID
+	if hasItem {
+		if itemID, err = uuid.Parse(c.Params("itemId")); err != nil {
+			return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+				"error":   true,
+				"message": "Invalid checklist item ID",
+			})
+		}
+	}
+
+	// Parse request body
+	if req != nil {
+		if err := c.BodyParser(req); err != nil {
+			return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+				"error":   true,
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	updatedTask, err := change(taskID, itemID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Task not found",
+			})
+		case "checklist item not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Checklist item not found",
+			})
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, status, fiber.Map{
+		"error":   false,
+		"message": message,
+		"data":    updatedTask,
+	})
+}
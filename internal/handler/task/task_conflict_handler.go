@@ -0,0 +1,52 @@
+package task
+
+import (
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ResolveTask handles resolving a conflict of an offline edit. The body is the result of
+// merging the edit with the server copy returned by the conflict, with base_version set to
+// the version of that copy.
+func (h *Handler) ResolveTask(c *fiber.Ctx) error {
+	taskID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid task ID",
+		})
+	}
+
+	var req task.UpdateTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	dueDate, err := h.resolveDue(c, req.Due, req.DueDate)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	req.Due, req.DueDate = "", dueDate
+
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	resolved, err := h.taskService.ResolveTask(taskID, &req, userID)
+	if err != nil {
+		return sendUpdateError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Conflict resolved successfully",
+		"data":    resolved,
+	})
+}
@@ -0,0 +1,99 @@
+package task
+
+import (
+	"bufio"
+	"encoding/csv"
+	"log"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// csvFlushInterval is the number of rows written before the response is flushed to the client
+const csvFlushInterval = 100
+
+// csvHeader lists the columns of exported tasks
+var csvHeader = []string{"id", "title", "status", "priority", "due_date", "created_at", "updated_at"}
+
+// ExportTasks streams the user's tasks matching the current filters as a file download
+func (h *Handler) ExportTasks(c *fiber.Ctx) error {
+	if format := c.Query("format", "csv"); format != "csv" {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Unsupported export format: " + format,
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	// Parse query parameters
+	filter, sort, err := h.parseQuery(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="tasks.csv"`)
+
+	// Rows are written as the response is sent rather than buffered in memory
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer := csv.NewWriter(w)
+		rows := 0
+
+		err := writer.Write(csvHeader)
+		if err == nil {
+			err = h.taskService.ExportTasks(filter, sort, userID, func(t *task.Task) error {
+				if err := writer.Write(csvRecord(t)); err != nil {
+					return err
+				}
+
+				rows++
+				if rows%csvFlushInterval == 0 {
+					writer.Flush()
+					if err := writer.Error(); err != nil {
+						return err
+					}
+					return w.Flush()
+				}
+				return nil
+			})
+		}
+
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+		if err != nil {
+			// Headers are already sent, so the client sees a truncated file
+			log.Printf("task export for user %s failed: %v", userID, err)
+		}
+	})
+
+	return nil
+}
+
+// csvRecord converts a task into a CSV row matching csvHeader
+func csvRecord(t *task.Task) []string {
+	var dueDate string
+	if t.DueDate != nil {
+		dueDate = t.DueDate.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		t.ID.String(),
+		t.Title,
+		string(t.Status),
+		string(t.Priority),
+		dueDate,
+		t.CreatedAt.UTC().Format(time.RFC3339),
+		t.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -1,13 +1,22 @@
 package task
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
+	"todo-api/internal/domain/billing"
 	"todo-api/internal/domain/task"
-	authService "todo-api/internal/service/auth"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/jobs"
+	"todo-api/internal/response"
+	notificationService "todo-api/internal/service/notification"
 	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/markdown"
 	"todo-api/pkg/types"
+	"todo-api/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,16 +24,49 @@ import (
 
 // Handler handles task HTTP requests
 type Handler struct {
-	taskService taskService.Service
+	taskService   taskService.Service
+	jobs          jobs.Queue                  // runs imports asked to respond asynchronously, if any
+	notifications notificationService.Service // optional, holds the time zone of users
 }
 
-// NewHandler creates a new task handler instance
-func NewHandler(authSvc authService.Service) *Handler {
-	// Initialize service
-	taskSvc := taskService.NewService(authSvc)
+// TimeZoneHeader names the time zone to compute due periods such as "today" in for a request,
+// overriding that of the user's preferences
+const TimeZoneHeader = "X-Timezone"
+
+// Deps are the dependencies of a task handler. Tasks is required; the others are optional,
+// and a zero value keeps the default noted next to each.
+type Deps struct {
+	Tasks taskService.Service
+	// Jobs runs imports as jobs when clients prefer an asynchronous response; imports always
+	// run within the request when it is nil
+	Jobs jobs.Queue
+	// Notifications holds the preferences of users: the time zone due periods such as "today"
+	// are computed in, and whether new tasks are checked for duplicates. Due periods are in
+	// UTC and duplicates are not checked when it is nil.
+	Notifications notificationService.Service
+}
+
+// NewHandler creates a new task handler instance using an existing task service
+func NewHandler(taskSvc taskService.Service) *Handler {
+	return NewHandlerWithDeps(Deps{Tasks: taskSvc})
+}
+
+// NewHandlerWithDeps creates a new task handler instance, using the defaults of Deps for the
+// dependencies left unset
+func NewHandlerWithDeps(deps Deps) *Handler {
+	if deps.Jobs != nil {
+		taskSvc := deps.Tasks
+		jobs.HandleWithResult(deps.Jobs, func(ctx context.Context, job importJob) (*task.ImportResult, error) {
+			// Imports fail the same way however often they are retried
+			result, err := taskSvc.ImportTasks(job.Tasks, job.UserID)
+			return result, jobs.Permanent(err)
+		})
+	}
 
 	return &Handler{
-		taskService: taskSvc,
+		taskService:   deps.Tasks,
+		jobs:          deps.Jobs,
+		notifications: deps.Notifications,
 	}
 }
 
@@ -34,38 +76,99 @@ func (h *Handler) CreateTask(c *fiber.Ctx) error {
 
 	// Parse request body
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
 			"error":   true,
 			"message": "Invalid request body",
 		})
 	}
 
+	dueDate, err := h.resolveDue(c, req.Due, req.DueDate)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	req.Due, req.DueDate = "", dueDate
+
 	// Get user ID from context (set by auth middleware)
 	userID := c.Locals("user_id").(uuid.UUID)
 
+	if existing := h.duplicateOf(c, req.Title, userID); existing != nil {
+		return sendDuplicate(c, existing)
+	}
+
 	// Create task
 	newTask, err := h.taskService.CreateTask(&req, userID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": err.Error(),
-		})
+		return sendCreateError(c, err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
 		"error":   false,
 		"message": "Task created successfully",
 		"data":    newTask,
 	})
 }
 
+// sendCreateError responds with the status of an error creating a task: plan and tenant
+// limits, a full store, or else an invalid request
+func sendCreateError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, billing.ErrUpgradeRequired) {
+		return response.Send(c, fiber.StatusPaymentRequired, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if errors.Is(err, task.ErrLimitExceeded) {
+		return response.Send(c, fiber.StatusTooManyRequests, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if errors.Is(err, tenant.ErrQuotaExceeded) {
+		return response.Send(c, fiber.StatusForbidden, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if errors.Is(err, task.ErrStorageFull) {
+		return response.Send(c, fiber.StatusInsufficientStorage, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
+
+// duplicateOf returns the recent open task a new task with the title duplicates, when the
+// user turned duplicate checks on and the request is not forced with force=true
+func (h *Handler) duplicateOf(c *fiber.Ctx, title string, userID uuid.UUID) *task.Task {
+	if h.notifications == nil || c.QueryBool("force") || !h.notifications.GetPreferences(userID).DuplicateCheck {
+		return nil
+	}
+	return h.taskService.FindDuplicate(title, time.Now().Add(-task.DuplicateWindow), userID)
+}
+
+// sendDuplicate responds that a new task duplicates the existing one, which is returned
+func sendDuplicate(c *fiber.Ctx, existing *task.Task) error {
+	return response.Send(c, fiber.StatusConflict, fiber.Map{
+		"error":   true,
+		"message": "A similar open task already exists, send force=true to create it anyway",
+		"data":    existing,
+	})
+}
+
 // GetTask handles getting a single task
 func (h *Handler) GetTask(c *fiber.Ctx) error {
 	// Parse task ID from URL parameter
 	taskIDStr := c.Params("id")
 	taskID, err := uuid.Parse(taskIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
 			"error":   true,
 			"message": "Invalid task ID",
 		})
@@ -78,22 +181,39 @@ func (h *Handler) GetTask(c *fiber.Ctx) error {
 	task, err := h.taskService.GetTaskByID(taskID, userID)
 	if err != nil {
 		if err.Error() == "task not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
 				"error":   true,
 				"message": "Task not found",
 			})
 		}
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusForbidden, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	rendered, err := renderDescriptions(c, task)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
 			"error":   true,
 			"message": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	// Apply sparse fieldset
+	data, err := types.SelectFields(rendered[0], types.ParseFields(c.Query("fields")))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return h.sendCacheable(c, fiber.Map{
 		"error":   false,
 		"message": "Task retrieved successfully",
-		"data":    task,
-	})
+		"data":    data,
+	}, task.UpdatedAt)
 }
 
 // UpdateTask handles task updates
@@ -102,7 +222,7 @@ func (h *Handler) UpdateTask(c *fiber.Ctx) error {
 	taskIDStr := c.Params("id")
 	taskID, err := uuid.Parse(taskIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
 			"error":   true,
 			"message": "Invalid task ID",
 		})
@@ -112,44 +232,229 @@ func (h *Handler) UpdateTask(c *fiber.Ctx) error {
 
 	// Parse request body
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
 			"error":   true,
 			"message": "Invalid request body",
 		})
 	}
 
+	dueDate, err := h.resolveDue(c, req.Due, req.DueDate)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	req.Due, req.DueDate = "", dueDate
+
 	// Get user ID from context
 	userID := c.Locals("user_id").(uuid.UUID)
 
 	// Update task
 	updatedTask, err := h.taskService.UpdateTask(taskID, &req, userID)
+	if err != nil {
+		return sendUpdateError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Task updated successfully",
+		"data":    updatedTask,
+	})
+}
+
+// sendUpdateError responds with the status matching an error updating a task. Edits of a
+// stale version that conflict get the server copy and the conflicting fields to merge.
+func sendUpdateError(c *fiber.Ctx, err error) error {
+	var conflict *task.ConflictError
+	switch {
+	case err.Error() == "task not found":
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Task not found",
+		})
+	case errors.As(err, &conflict):
+		return response.Send(c, fiber.StatusConflict, fiber.Map{
+			"error":   true,
+			"message": "Task was changed since base_version, merge the changes and send them to resolve",
+			"data":    conflict,
+		})
+	case errors.Is(err, task.ErrInvalidTransition):
+		return response.Send(c, fiber.StatusConflict, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
+
+// DeleteTask handles task deletion
+func (h *Handler) DeleteTask(c *fiber.Ctx) error {
+	// Parse task ID from URL parameter
+	taskIDStr := c.Params("id")
+	taskID, err := uuid.Parse(taskIDStr)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid task ID",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	// Delete task
+	err = h.taskService.DeleteTask(taskID, userID)
 	if err != nil {
 		if err.Error() == "task not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
 				"error":   true,
 				"message": "Task not found",
 			})
 		}
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusForbidden, fiber.Map{
 			"error":   true,
 			"message": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	return response.Send(c, fiber.StatusOK, fiber.Map{
 		"error":   false,
-		"message": "Task updated successfully",
+		"message": "Task deleted successfully",
+	})
+}
+
+// MoveTask handles reordering a task within or across status columns
+func (h *Handler) MoveTask(c *fiber.Ctx) error {
+	// Parse task ID from URL parameter
+	taskIDStr := c.Params("id")
+	taskID, err := uuid.Parse(taskIDStr)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid task ID",
+		})
+	}
+
+	var req task.MoveTaskRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	// Move task
+	movedTask, err := h.taskService.MoveTask(taskID, &req, userID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Task not found",
+			})
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, task.ErrInvalidTransition) {
+			return response.Send(c, fiber.StatusConflict, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Task moved successfully",
+		"data":    movedTask,
+	})
+}
+
+// CompleteTask handles marking a task as completed
+func (h *Handler) CompleteTask(c *fiber.Ctx) error {
+	return h.transitionTask(c, h.taskService.CompleteTask, "Task completed successfully")
+}
+
+// ReopenTask handles moving a completed or cancelled task back to pending
+func (h *Handler) ReopenTask(c *fiber.Ctx) error {
+	return h.transitionTask(c, h.taskService.ReopenTask, "Task reopened successfully")
+}
+
+// ArchiveTask handles hiding a task from default listings
+func (h *Handler) ArchiveTask(c *fiber.Ctx) error {
+	return h.transitionTask(c, h.taskService.ArchiveTask, "Task archived successfully")
+}
+
+// UnarchiveTask handles restoring an archived task to default listings
+func (h *Handler) UnarchiveTask(c *fiber.Ctx) error {
+	return h.transitionTask(c, h.taskService.UnarchiveTask, "Task unarchived successfully")
+}
+
+// transitionTask applies a lifecycle change such as a status transition or archiving to the task in the URL
+func (h *Handler) transitionTask(c *fiber.Ctx, transition func(id uuid.UUID, userID uuid.UUID) (*task.Task, error), message string) error {
+	// Parse task ID from URL parameter
+	taskIDStr := c.Params("id")
+	taskID, err := uuid.Parse(taskIDStr)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid task ID",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	updatedTask, err := transition(taskID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Task not found",
+			})
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusConflict, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": message,
 		"data":    updatedTask,
 	})
 }
 
-// DeleteTask handles task deletion
-func (h *Handler) DeleteTask(c *fiber.Ctx) error {
+// GetTaskHistory handles retrieving the activity history of a task
+func (h *Handler) GetTaskHistory(c *fiber.Ctx) error {
 	// Parse task ID from URL parameter
 	taskIDStr := c.Params("id")
 	taskID, err := uuid.Parse(taskIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
 			"error":   true,
 			"message": "Invalid task ID",
 		})
@@ -158,41 +463,81 @@ func (h *Handler) DeleteTask(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id").(uuid.UUID)
 
-	// Delete task
-	err = h.taskService.DeleteTask(taskID, userID)
+	// Get history
+	history, err := h.taskService.GetTaskHistory(taskID, userID)
 	if err != nil {
 		if err.Error() == "task not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
 				"error":   true,
 				"message": "Task not found",
 			})
 		}
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusForbidden, fiber.Map{
 			"error":   true,
 			"message": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	return response.Send(c, fiber.StatusOK, fiber.Map{
 		"error":   false,
-		"message": "Task deleted successfully",
+		"message": "Task history retrieved successfully",
+		"data":    history,
+	})
+}
+
+// GetStats handles task analytics over a date range, defaulting to the last 30 days
+func (h *Handler) GetStats(c *fiber.Ctx) error {
+	statsRange, err := h.parseStatsRange(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	// Get statistics
+	stats, err := h.taskService.GetStats(statsRange, userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+			"error":   true,
+			"message": "Failed to compute task statistics",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Task statistics retrieved successfully",
+		"data":    stats,
 	})
 }
 
 // ListTasks handles task listing with filtering, sorting, and pagination
 func (h *Handler) ListTasks(c *fiber.Ctx) error {
+	return h.listTasks(c, h.taskService.ListTasks)
+}
+
+// listTasks responds with a page of the tasks returned by list for the current query
+func (h *Handler) listTasks(c *fiber.Ctx, list func(filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, error)) error {
 	// Get user ID from context
 	userID := c.Locals("user_id").(uuid.UUID)
 
 	// Parse query parameters
-	filter := h.parseFilter(c)
-	sort := h.parseSort(c)
+	filter, sort, err := h.parseQuery(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
 	page, limit := h.parsePagination(c)
 
 	// Get tasks
-	tasks, paginationInfo, err := h.taskService.ListTasks(filter, sort, page, limit, userID)
+	tasks, paginationInfo, err := list(filter, sort, page, limit, userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
 			"error":   true,
 			"message": "Failed to retrieve tasks",
 		})
@@ -204,36 +549,95 @@ func (h *Handler) ListTasks(c *fiber.Ctx) error {
 	}
 
 	if sort != nil {
-		meta.Sort = sort.Field + ":" + sort.Order
+		meta.Sort = sort.String()
 	}
 
 	if filter != nil {
-		var filterParts []string
-		if filter.Status != nil {
-			filterParts = append(filterParts, "status:"+string(*filter.Status))
-		}
-		if filter.Search != "" {
-			filterParts = append(filterParts, "search:"+filter.Search)
-		}
-		meta.Filter = strings.Join(filterParts, ",")
+		meta.Filter = filter.String()
+	}
+
+	if tasks, err = renderDescriptions(c, tasks...); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	// Apply sparse fieldset
+	data, err := types.SelectFields(tasks, types.ParseFields(c.Query("fields")))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return h.sendCacheable(c, fiber.Map{
 		"error":   false,
 		"message": "Tasks retrieved successfully",
-		"data":    tasks,
+		"data":    data,
 		"meta":    meta,
-	})
+	}, time.Time{}) // deleted tasks would not move a Last-Modified forward, so lists rely on the ETag alone
+}
+
+// sendCacheable writes a response with an ETag validator, and a Last-Modified one unless
+// lastModified is zero, replying 304 Not Modified when the client's cached copy is still current
+func (h *Handler) sendCacheable(c *fiber.Ctx, body fiber.Map, lastModified time.Time) error {
+	return sendCacheableAs(c, body, lastModified, "private, no-cache")
+}
+
+// sendCacheableAs writes a cacheable response like sendCacheable, with the Cache-Control
+// directives given
+func sendCacheableAs(c *fiber.Ctx, body fiber.Map, lastModified time.Time, cacheControl string) error {
+	data, contentType, err := response.Encode(c, body)
+	if err != nil {
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+			"error":   true,
+			"message": "Failed to encode response",
+		})
+	}
+
+	etag := utils.ETag(data)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if utils.IsNotModified(c.Get(fiber.HeaderIfNoneMatch), c.Get(fiber.HeaderIfModifiedSince), etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// parseQuery parses the filter and sort parameters shared by listing and export
+func (h *Handler) parseQuery(c *fiber.Ctx) (*task.TaskFilter, *task.TaskSort, error) {
+	filter, err := h.parseFilter(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort, err := h.parseSort(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return filter, sort, nil
 }
 
 // parseFilter parses filter parameters from query string
-func (h *Handler) parseFilter(c *fiber.Ctx) *task.TaskFilter {
+func (h *Handler) parseFilter(c *fiber.Ctx) (*task.TaskFilter, error) {
 	filter := &task.TaskFilter{}
 
-	// Status filter
+	// Status filter, a comma-separated list matches any of the statuses
 	if statusStr := c.Query("status"); statusStr != "" {
-		status := task.TaskStatus(statusStr)
-		filter.Status = &status
+		statuses, err := task.ParseStatuses(statusStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Statuses = statuses
 	}
 
 	// Search filter
@@ -241,40 +645,154 @@ func (h *Handler) parseFilter(c *fiber.Ctx) *task.TaskFilter {
 		filter.Search = search
 	}
 
+	// Creation time range
+	createdAfter, err := parseTimeQuery(c, "created_after")
+	if err != nil {
+		return nil, err
+	}
+	filter.CreatedAfter = createdAfter
+
+	createdBefore, err := parseTimeQuery(c, "created_before")
+	if err != nil {
+		return nil, err
+	}
+	filter.CreatedBefore = createdBefore
+
+	// Due period, such as today, in the time zone of the user
+	if due := c.Query("due"); due != "" {
+		loc, err := h.location(c)
+		if err != nil {
+			return nil, err
+		}
+		from, before, err := task.DuePeriod(due, time.Now().In(loc))
+		if err != nil {
+			return nil, err
+		}
+		filter.DueFrom = &from
+		filter.DueBefore = &before
+	}
+
+	// Only tasks assigned to the current user
+	if assignedStr := c.Query("assigned_to_me"); assignedStr != "" {
+		assignedToMe, err := strconv.ParseBool(assignedStr)
+		if err != nil {
+			return nil, errors.New("invalid assigned_to_me value: " + assignedStr)
+		}
+		if assignedToMe {
+			userID := c.Locals("user_id").(uuid.UUID)
+			filter.AssigneeID = &userID
+		}
+	}
+
+	// Project filter
+	if projectStr := c.Query("project_id"); projectStr != "" {
+		projectID, err := uuid.Parse(projectStr)
+		if err != nil {
+			return nil, errors.New("invalid project_id: " + projectStr)
+		}
+		filter.ProjectID = &projectID
+	}
+
+	// Archived tasks are only listed when requested
+	if archivedStr := c.Query("archived"); archivedStr != "" {
+		archived, err := strconv.ParseBool(archivedStr)
+		if err != nil {
+			return nil, errors.New("invalid archived value: " + archivedStr)
+		}
+		filter.Archived = &archived
+	}
+
 	// Return nil if no filters are applied
-	if filter.Status == nil && filter.Search == "" {
-		return nil
+	if len(filter.Predicates()) == 0 {
+		return nil, nil
 	}
 
-	return filter
+	return filter, nil
 }
 
-// parseSort parses sort parameters from query string
-func (h *Handler) parseSort(c *fiber.Ctx) *task.TaskSort {
-	sortField := c.Query("sort_field", "created_at")
-	sortOrder := c.Query("sort_order", "desc")
+// parseSort parses sort parameters from query string.
+// The sort expression takes precedence over the legacy sort_field and sort_order parameters.
+func (h *Handler) parseSort(c *fiber.Ctx) (*task.TaskSort, error) {
+	if expr := c.Query("sort"); expr != "" {
+		return task.ParseTaskSort(expr)
+	}
+
+	return task.NewTaskSort(c.Query("sort_field", "created_at"), c.Query("sort_order", "desc")), nil
+}
 
-	// Validate sort field
-	validFields := map[string]bool{
-		"created_at": true,
-		"updated_at": true,
-		"title":      true,
-		"status":     true,
+// parseStatsRange parses the from and to parameters of the statistics endpoint
+func (h *Handler) parseStatsRange(c *fiber.Ctx) (*task.StatsRange, error) {
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
 	}
 
-	if !validFields[sortField] {
-		sortField = "created_at"
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		defaultFrom := to.AddDate(0, 0, -29)
+		from = &defaultFrom
 	}
 
-	// Validate sort order
-	if sortOrder != "asc" && sortOrder != "desc" {
-		sortOrder = "desc"
+	return task.NewStatsRange(*from, *to)
+}
+
+// renderDescriptions returns copies of the tasks with their Markdown descriptions rendered as
+// sanitized HTML when the render query parameter is html, or the tasks themselves without it
+func renderDescriptions(c *fiber.Ctx, tasks ...*task.Task) ([]*task.Task, error) {
+	switch format := c.Query("render"); format {
+	case "":
+		return tasks, nil
+	case "html":
+	default:
+		return nil, errors.New("Unsupported render format: " + format)
 	}
 
-	return &task.TaskSort{
-		Field: sortField,
-		Order: sortOrder,
+	rendered := make([]*task.Task, len(tasks))
+	for i, t := range tasks {
+		copied := *t
+		copied.RenderedDescription = markdown.Render(t.Description)
+		rendered[i] = &copied
+	}
+	return rendered, nil
+}
+
+// parseTimeQuery parses an RFC 3339 timestamp or YYYY-MM-DD date query parameter
+func parseTimeQuery(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+
+	return nil, errors.New("invalid " + key + ": expected RFC 3339 timestamp or YYYY-MM-DD date")
+}
+
+// location returns the time zone of the request's X-Timezone header, or else of the user's
+// preferences, UTC when neither is set
+func (h *Handler) location(c *fiber.Ctx) (*time.Location, error) {
+	if name := c.Get(TimeZoneHeader); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, errors.New("invalid " + TimeZoneHeader + " header: " + name)
+		}
+		return loc, nil
+	}
+	if h.notifications == nil {
+		return time.UTC, nil
 	}
+	return h.notifications.GetPreferences(c.Locals("user_id").(uuid.UUID)).Location(), nil
 }
 
 // parsePagination parses pagination parameters from query string
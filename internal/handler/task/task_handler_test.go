@@ -2,24 +2,49 @@ package task
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sync"
 	"testing"
 	"time"
 
+	"todo-api/internal/cache"
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/notification"
 	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	"todo-api/internal/mocks"
 	"todo-api/internal/service/auth"
+	notificationService "todo-api/internal/service/notification"
+	taskService "todo-api/internal/service/task"
+	tenantService "todo-api/internal/service/tenant"
+	workspaceService "todo-api/internal/service/workspace"
 	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+	"todo-api/pkg/types"
 	"todo-api/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-func setupTestHandler(t *testing.T) (*Handler, string) {
+func setupTestHandler(t testing.TB) (*Handler, string) {
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
 			SecretKey:       "test-secret",
@@ -29,7 +54,7 @@ func setupTestHandler(t *testing.T) (*Handler, string) {
 	}
 
 	authSvc := auth.NewService(cfg)
-	handler := NewHandler(authSvc)
+	handler := NewHandler(taskService.NewService(authSvc))
 
 	// Generate a valid token for testing
 	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
@@ -49,43 +74,51 @@ func TestNewHandler(t *testing.T) {
 	}
 
 	authSvc := auth.NewService(cfg)
-	handler := NewHandler(authSvc)
+	handler := NewHandler(taskService.NewService(authSvc))
 
 	assert.NotNil(t, handler)
 	assert.IsType(t, &Handler{}, handler)
 }
 
-func TestHandler_CreateTask_ValidRequest(t *testing.T) {
-	handler, token := setupTestHandler(t)
-	app := fiber.New()
+// johnID is the user requests are authenticated as
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
 
-	// Add auth middleware
+// setupMockedHandler creates a handler of a mocked task service, and an app whose requests
+// are authenticated as John
+func setupMockedHandler(t *testing.T) (*Handler, *mocks.TaskService, *fiber.App) {
+	taskSvc := mocks.NewTaskService(t)
+	handler := NewHandler(taskSvc)
+
+	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
-		// Mock auth middleware for testing
-		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		c.Locals("user_id", johnID)
 		c.Locals("user_email", "john.doe@example.com")
 		return c.Next()
 	})
+	return handler, taskSvc, app
+}
 
-	app.Post("/tasks", handler.CreateTask)
-
-	req := task.CreateTaskRequest{
-		Title: "Test Task",
-	}
-
-	reqBody, _ := json.Marshal(req)
-	httpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(reqBody))
+// send sends the request to the app and decodes the JSON response
+func send(t *testing.T, app *fiber.App, method, path, body string) (*http.Response, map[string]interface{}) {
+	httpReq := httptest.NewRequest(method, path, bytes.NewBufferString(body))
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+token)
 
 	resp, err := app.Test(httpReq)
-
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
 	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return resp, response
+}
+
+func TestHandler_CreateTask_ValidRequest(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Post("/tasks", handler.CreateTask)
+
+	taskSvc.On("CreateTask", &task.CreateTaskRequest{Title: "Test Task"}, johnID).Return(task.NewTask("Test Task", johnID), nil)
+
+	resp, response := send(t, app, http.MethodPost, "/tasks", `{"title":"Test Task"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
 	assert.Equal(t, false, response["error"])
 	assert.Equal(t, "Task created successfully", response["message"])
@@ -97,122 +130,152 @@ func TestHandler_CreateTask_ValidRequest(t *testing.T) {
 }
 
 func TestHandler_CreateTask_InvalidRequest(t *testing.T) {
-	handler, token := setupTestHandler(t)
-	app := fiber.New()
-
-	// Add auth middleware
-	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
-		c.Locals("user_email", "john.doe@example.com")
-		return c.Next()
-	})
-
+	handler, taskSvc, app := setupMockedHandler(t)
 	app.Post("/tasks", handler.CreateTask)
 
-	req := task.CreateTaskRequest{
-		Title: "", // Invalid title
-	}
-
-	reqBody, _ := json.Marshal(req)
-	httpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(reqBody))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+token)
-
-	resp, err := app.Test(httpReq)
+	// Requests are validated by the service
+	taskSvc.On("CreateTask", &task.CreateTaskRequest{}, johnID).Return(nil, errors.New("title is required"))
 
-	require.NoError(t, err)
+	resp, response := send(t, app, http.MethodPost, "/tasks", `{"title":""}`)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-
 	assert.Equal(t, true, response["error"])
 	assert.Equal(t, "title is required", response["message"])
 }
 
-func TestHandler_GetTaskByID_ExistingTask(t *testing.T) {
-	handler, token := setupTestHandler(t)
-	app := fiber.New()
-
-	// Add auth middleware
-	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
-		c.Locals("user_email", "john.doe@example.com")
-		return c.Next()
-	})
-
-	// First create a task
+func TestHandler_CreateTask_LimitErrors(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
 	app.Post("/tasks", handler.CreateTask)
-	createReq := task.CreateTaskRequest{Title: "Test Task"}
-	createReqBody, _ := json.Marshal(createReq)
-	createHttpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(createReqBody))
-	createHttpReq.Header.Set("Content-Type", "application/json")
-	createHttpReq.Header.Set("Authorization", "Bearer "+token)
 
-	createResp, err := app.Test(createHttpReq)
-	require.NoError(t, err)
-	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+	tests := []struct {
+		err            error
+		expectedStatus int
+	}{
+		{billing.ErrUpgradeRequired, http.StatusPaymentRequired},
+		{task.ErrLimitExceeded, http.StatusTooManyRequests},
+		{tenant.ErrQuotaExceeded, http.StatusForbidden},
+		{task.ErrStorageFull, http.StatusInsufficientStorage},
+	}
 
-	var createResponse map[string]interface{}
-	err = json.NewDecoder(createResp.Body).Decode(&createResponse)
-	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			taskSvc.On("CreateTask", &task.CreateTaskRequest{Title: "One too many"}, johnID).Return(nil, tt.err).Once()
 
-	taskID := createResponse["data"].(map[string]interface{})["id"].(string)
+			resp, response := send(t, app, http.MethodPost, "/tasks", `{"title":"One too many"}`)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.err.Error(), response["message"])
+		})
+	}
+}
 
-	// Now get the task
+func TestHandler_GetTaskByID_ExistingTask(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
 	app.Get("/tasks/:id", handler.GetTask)
-	httpReq := httptest.NewRequest(http.MethodGet, "/tasks/"+taskID, nil)
-	httpReq.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := app.Test(httpReq)
+	existing := task.NewTask("Test Task", johnID)
+	taskSvc.On("GetTaskByID", existing.ID, johnID).Return(existing, nil)
 
-	require.NoError(t, err)
+	resp, response := send(t, app, http.MethodGet, "/tasks/"+existing.ID.String(), "")
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-
 	assert.Equal(t, false, response["error"])
 	assert.Equal(t, "Task retrieved successfully", response["message"])
 	assert.NotNil(t, response["data"])
 
 	data := response["data"].(map[string]interface{})
 	assert.Equal(t, "Test Task", data["title"])
-	assert.Equal(t, taskID, data["id"])
+	assert.Equal(t, existing.ID.String(), data["id"])
 }
 
-func TestHandler_GetTaskByID_NonExistingTask(t *testing.T) {
-	handler, token := setupTestHandler(t)
-	app := fiber.New()
+func TestHandler_GetTask_RenderHTML(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Get("/tasks/:id", handler.GetTask)
+	app.Get("/tasks", handler.ListTasks)
 
-	// Add auth middleware
-	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
-		c.Locals("user_email", "john.doe@example.com")
-		return c.Next()
-	})
+	existing := task.NewTask("Test Task", johnID)
+	existing.Description = "**Steps**\n\n- [docs](https://example.com)\n- <script>alert(1)</script>"
+	taskSvc.On("GetTaskByID", existing.ID, johnID).Return(existing, nil)
+	taskSvc.On("ListTasks", mock.Anything, mock.Anything, 1, 10, johnID).
+		Return([]*task.Task{existing}, &types.PaginationInfo{Page: 1, Limit: 10, Total: 1, TotalPages: 1}, nil)
+
+	resp, response := send(t, app, http.MethodGet, "/tasks/"+existing.ID.String()+"?render=html", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, existing.Description, data["description"])
+	assert.Equal(t, "<p><strong>Steps</strong></p>\n<ul>\n"+
+		`<li><a href="https://example.com" rel="nofollow noopener noreferrer">docs</a></li>`+"\n"+
+		"<li>&lt;script&gt;alert(1)&lt;/script&gt;</li>\n</ul>\n", data["rendered_description"])
+	assert.Empty(t, existing.RenderedDescription)
+
+	resp, response = send(t, app, http.MethodGet, "/tasks/"+existing.ID.String()+"?render=html&fields=id,rendered_description", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, response["data"], 2)
 
+	resp, response = send(t, app, http.MethodGet, "/tasks?render=html", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, response["data"].([]interface{})[0], "rendered_description")
+
+	// Without render, descriptions are left as they are
+	_, response = send(t, app, http.MethodGet, "/tasks/"+existing.ID.String(), "")
+	assert.NotContains(t, response["data"], "rendered_description")
+
+	resp, response = send(t, app, http.MethodGet, "/tasks/"+existing.ID.String()+"?render=pdf", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Unsupported render format: pdf", response["message"])
+}
+
+func TestHandler_GetTaskByID_NonExistingTask(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
 	app.Get("/tasks/:id", handler.GetTask)
-	nonExistingID := uuid.New().String()
-	httpReq := httptest.NewRequest(http.MethodGet, "/tasks/"+nonExistingID, nil)
-	httpReq.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := app.Test(httpReq)
+	nonExistingID := uuid.New()
+	taskSvc.On("GetTaskByID", nonExistingID, johnID).Return(nil, errors.New("task not found"))
+	otherID := uuid.New()
+	taskSvc.On("GetTaskByID", otherID, johnID).Return(nil, errors.New("access denied"))
 
-	require.NoError(t, err)
+	resp, response := send(t, app, http.MethodGet, "/tasks/"+nonExistingID.String(), "")
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-
 	assert.Equal(t, true, response["error"])
 	assert.Equal(t, "Task not found", response["message"])
+
+	resp, response = send(t, app, http.MethodGet, "/tasks/"+otherID.String(), "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "access denied", response["message"])
+
+	// Invalid IDs do not reach the service
+	resp, response = send(t, app, http.MethodGet, "/tasks/invalid-uuid", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Invalid task ID", response["message"])
 }
 
 func TestHandler_UpdateTask_ValidRequest(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Put("/tasks/:id", handler.UpdateTask)
+
+	existing := task.NewTask("Original Title", johnID)
+	updateReq := &task.UpdateTaskRequest{
+		Title:  stringPtr("Updated Title"),
+		Status: statusPtr(task.StatusInProgress),
+	}
+	updated := *existing
+	updated.Title = "Updated Title"
+	updated.Status = task.StatusInProgress
+	taskSvc.On("UpdateTask", existing.ID, updateReq, johnID).Return(&updated, nil)
+
+	resp, response := send(t, app, http.MethodPut, "/tasks/"+existing.ID.String(), `{"title":"Updated Title","status":"in_progress"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, false, response["error"])
+	assert.Equal(t, "Task updated successfully", response["message"])
+	assert.NotNil(t, response["data"])
+
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "Updated Title", data["title"])
+	assert.Equal(t, "in_progress", data["status"])
+}
+
+func TestHandler_MoveTask(t *testing.T) {
 	handler, token := setupTestHandler(t)
 	app := fiber.New()
 
@@ -223,102 +286,207 @@ func TestHandler_UpdateTask_ValidRequest(t *testing.T) {
 		return c.Next()
 	})
 
-	// First create a task
 	app.Post("/tasks", handler.CreateTask)
-	createReq := task.CreateTaskRequest{Title: "Original Title"}
-	createReqBody, _ := json.Marshal(createReq)
-	createHttpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(createReqBody))
-	createHttpReq.Header.Set("Content-Type", "application/json")
-	createHttpReq.Header.Set("Authorization", "Bearer "+token)
+	app.Post("/tasks/:id/move", handler.MoveTask)
+
+	var ids []string
+	for _, title := range []string{"First", "Second"} {
+		createReqBody, _ := json.Marshal(task.CreateTaskRequest{Title: title})
+		createHttpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(createReqBody))
+		createHttpReq.Header.Set("Content-Type", "application/json")
+		createHttpReq.Header.Set("Authorization", "Bearer "+token)
+
+		createResp, err := app.Test(createHttpReq)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+		var createResponse map[string]interface{}
+		require.NoError(t, json.NewDecoder(createResp.Body).Decode(&createResponse))
+		ids = append(ids, createResponse["data"].(map[string]interface{})["id"].(string))
+	}
 
-	createResp, err := app.Test(createHttpReq)
-	require.NoError(t, err)
-	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+	tests := []struct {
+		name           string
+		taskID         string
+		body           string
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{"before another task", ids[1], `{"before_id":"` + ids[0] + `"}`, http.StatusOK, "Task moved successfully"},
+		{"missing target", ids[1], `{}`, http.StatusBadRequest, "exactly one of position, before_id, or after_id is required"},
+		{"invalid ID", "invalid-uuid", `{"position":0}`, http.StatusBadRequest, "Invalid task ID"},
+		{"unknown task", uuid.New().String(), `{"position":0}`, http.StatusNotFound, "Task not found"},
+	}
 
-	var createResponse map[string]interface{}
-	err = json.NewDecoder(createResp.Body).Decode(&createResponse)
-	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpReq := httptest.NewRequest(http.MethodPost, "/tasks/"+tt.taskID+"/move", bytes.NewBufferString(tt.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := app.Test(httpReq)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, tt.expectedMsg, response["message"])
+
+			if tt.expectedStatus == http.StatusOK {
+				data := response["data"].(map[string]interface{})
+				assert.Less(t, data["position"].(float64), 1024.0)
+			}
+		})
+	}
+}
 
-	taskID := createResponse["data"].(map[string]interface{})["id"].(string)
+func TestHandler_CompleteAndReopenTask(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Post("/tasks/:id/complete", handler.CompleteTask)
+	app.Post("/tasks/:id/reopen", handler.ReopenTask)
+
+	existing := task.NewTask("Ship release", johnID)
+	taskID := existing.ID.String()
+	completed := *existing
+	completed.Status = task.StatusCompleted
+	unknownID := uuid.New()
+	taskSvc.On("CompleteTask", existing.ID, johnID).Return(&completed, nil).Once()
+	taskSvc.On("CompleteTask", existing.ID, johnID).
+		Return(nil, fmt.Errorf("%w: task is already completed", task.ErrInvalidTransition)).Once()
+	taskSvc.On("ReopenTask", existing.ID, johnID).Return(existing, nil).Once()
+	taskSvc.On("ReopenTask", existing.ID, johnID).
+		Return(nil, fmt.Errorf("%w: only completed or cancelled tasks can be reopened", task.ErrInvalidTransition)).Once()
+	taskSvc.On("ReopenTask", unknownID, johnID).Return(nil, errors.New("task not found"))
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedMsg    string
+		expectedState  string
+	}{
+		{"complete", "/tasks/" + taskID + "/complete", http.StatusOK, "Task completed successfully", "completed"},
+		{"complete again", "/tasks/" + taskID + "/complete", http.StatusConflict, "invalid status transition: task is already completed", ""},
+		{"reopen", "/tasks/" + taskID + "/reopen", http.StatusOK, "Task reopened successfully", "pending"},
+		{"reopen open task", "/tasks/" + taskID + "/reopen", http.StatusConflict, "invalid status transition: only completed or cancelled tasks can be reopened", ""},
+		{"invalid ID", "/tasks/invalid-uuid/complete", http.StatusBadRequest, "Invalid task ID", ""},
+		{"unknown task", "/tasks/" + unknownID.String() + "/reopen", http.StatusNotFound, "Task not found", ""},
+	}
 
-	// Now update the task
-	app.Put("/tasks/:id", handler.UpdateTask)
-	updateReq := task.UpdateTaskRequest{
-		Title:  stringPtr("Updated Title"),
-		Status: statusPtr(task.StatusInProgress),
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := send(t, app, http.MethodPost, tt.path, "")
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedMsg, response["message"])
+
+			if tt.expectedState != "" {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, tt.expectedState, data["status"])
+			}
+		})
 	}
+}
+func TestHandler_SearchTasks(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
 
-	updateReqBody, _ := json.Marshal(updateReq)
-	httpReq := httptest.NewRequest(http.MethodPut, "/tasks/"+taskID, bytes.NewBuffer(updateReqBody))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+token)
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
 
-	resp, err := app.Test(httpReq)
+	app.Post("/tasks", handler.CreateTask)
+	app.Get("/search", handler.SearchTasks)
 
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	send := func(method, path, body string) (int, map[string]interface{}) {
+		httpReq := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
 
-	assert.Equal(t, false, response["error"])
-	assert.Equal(t, "Task updated successfully", response["message"])
-	assert.NotNil(t, response["data"])
+	status, _ := send(http.MethodPost, "/tasks", `{"title":"Renew passport","description":"Book an appointment at the embassy"}`)
+	require.Equal(t, http.StatusCreated, status)
 
-	data := response["data"].(map[string]interface{})
-	assert.Equal(t, "Updated Title", data["title"])
-	assert.Equal(t, "in_progress", data["status"])
+	status, response := send(http.MethodGet, "/search?q=embassy+appoint", "")
+	assert.Equal(t, http.StatusOK, status)
+	hits := response["data"].([]interface{})
+	require.Len(t, hits, 1)
+	hit := hits[0].(map[string]interface{})
+	assert.Equal(t, "Renew passport", hit["task"].(map[string]interface{})["title"])
+	assert.Greater(t, hit["score"], float64(0))
+
+	status, response = send(http.MethodGet, "/search?q=nothing+matches", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, response["data"])
+
+	status, response = send(http.MethodGet, "/search", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "query is required", response["message"])
 }
 
-func TestHandler_DeleteTask_ExistingTask(t *testing.T) {
-	handler, token := setupTestHandler(t)
+func TestHandler_ArchiveTask(t *testing.T) {
+	handler, _ := setupTestHandler(t)
 	app := fiber.New()
 
 	// Add auth middleware
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
-		c.Locals("user_email", "john.doe@example.com")
 		return c.Next()
 	})
 
-	// First create a task
-	app.Post("/tasks", handler.CreateTask)
-	createReq := task.CreateTaskRequest{Title: "Task to Delete"}
-	createReqBody, _ := json.Marshal(createReq)
-	createHttpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(createReqBody))
-	createHttpReq.Header.Set("Content-Type", "application/json")
-	createHttpReq.Header.Set("Authorization", "Bearer "+token)
+	app.Get("/tasks", handler.ListTasks)
+	app.Post("/tasks/:id/archive", handler.ArchiveTask)
+	app.Post("/tasks/:id/unarchive", handler.UnarchiveTask)
 
-	createResp, err := app.Test(createHttpReq)
-	require.NoError(t, err)
-	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+	send := func(method, path string) (int, map[string]interface{}) {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil))
+		require.NoError(t, err)
 
-	var createResponse map[string]interface{}
-	err = json.NewDecoder(createResp.Body).Decode(&createResponse)
-	require.NoError(t, err)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
 
-	taskID := createResponse["data"].(map[string]interface{})["id"].(string)
+	_, response := send(http.MethodGet, "/tasks")
+	tasks := response["data"].([]interface{})
+	require.Len(t, tasks, 3)
+	taskID := tasks[0].(map[string]interface{})["id"].(string)
 
-	// Now delete the task
-	app.Delete("/tasks/:id", handler.DeleteTask)
-	httpReq := httptest.NewRequest(http.MethodDelete, "/tasks/"+taskID, nil)
-	httpReq.Header.Set("Authorization", "Bearer "+token)
+	status, response := send(http.MethodPost, "/tasks/"+taskID+"/archive")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Task archived successfully", response["message"])
+	assert.NotNil(t, response["data"].(map[string]interface{})["archived_at"])
 
-	resp, err := app.Test(httpReq)
+	status, response = send(http.MethodPost, "/tasks/"+taskID+"/archive")
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "task is already archived", response["message"])
 
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_, response = send(http.MethodGet, "/tasks")
+	assert.Len(t, response["data"], 2)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
+	_, response = send(http.MethodGet, "/tasks?archived=true")
+	require.Len(t, response["data"], 1)
+	assert.Equal(t, taskID, response["data"].([]interface{})[0].(map[string]interface{})["id"])
 
-	assert.Equal(t, false, response["error"])
-	assert.Equal(t, "Task deleted successfully", response["message"])
+	status, response = send(http.MethodGet, "/tasks?archived=maybe")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid archived value: maybe", response["message"])
+
+	status, response = send(http.MethodPost, "/tasks/"+taskID+"/unarchive")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Task unarchived successfully", response["message"])
+
+	_, response = send(http.MethodGet, "/tasks")
+	assert.Len(t, response["data"], 3)
 }
 
-func TestHandler_ListTasks_NoFilters(t *testing.T) {
+func TestHandler_Checklist(t *testing.T) {
 	handler, token := setupTestHandler(t)
 	app := fiber.New()
 
@@ -329,60 +497,1388 @@ func TestHandler_ListTasks_NoFilters(t *testing.T) {
 		return c.Next()
 	})
 
-	app.Get("/tasks", handler.ListTasks)
-	httpReq := httptest.NewRequest(http.MethodGet, "/tasks", nil)
-	httpReq.Header.Set("Authorization", "Bearer "+token)
+	app.Post("/tasks", handler.CreateTask)
+	app.Post("/tasks/:id/checklist", handler.AddChecklistItem)
+	app.Patch("/tasks/:id/checklist/:itemId", handler.UpdateChecklistItem)
+	app.Delete("/tasks/:id/checklist/:itemId", handler.RemoveChecklistItem)
 
-	resp, err := app.Test(httpReq)
+	send := func(method, path, body string) (int, map[string]interface{}) {
+		httpReq := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+token)
 
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
 
-	assert.Equal(t, false, response["error"])
-	assert.Equal(t, "Tasks retrieved successfully", response["message"])
-	assert.NotNil(t, response["data"])
-	assert.NotNil(t, response["meta"])
+	status, response := send(http.MethodPost, "/tasks", `{"title":"Move house"}`)
+	require.Equal(t, http.StatusCreated, status)
+	taskID := response["data"].(map[string]interface{})["id"].(string)
+
+	status, response = send(http.MethodPost, "/tasks/"+taskID+"/checklist", `{"text":"Pack boxes"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "Checklist item added successfully", response["message"])
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(0), data["progress"])
+	itemID := data["checklist"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	status, response = send(http.MethodPatch, "/tasks/"+taskID+"/checklist/"+itemID, `{"done":true}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(100), response["data"].(map[string]interface{})["progress"])
+
+	status, response = send(http.MethodPatch, "/tasks/"+taskID+"/checklist/invalid-uuid", `{"done":true}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Invalid checklist item ID", response["message"])
+
+	status, response = send(http.MethodDelete, "/tasks/"+taskID+"/checklist/"+itemID, "")
+	assert.Equal(t, http.StatusOK, status)
+	data = response["data"].(map[string]interface{})
+	assert.NotContains(t, data, "checklist")
+	assert.NotContains(t, data, "progress")
+
+	status, response = send(http.MethodDelete, "/tasks/"+taskID+"/checklist/"+itemID, "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "Checklist item not found", response["message"])
 }
 
-func TestHandler_ListTasks_WithFilters(t *testing.T) {
+func TestHandler_AssignAndShareTask(t *testing.T) {
 	handler, token := setupTestHandler(t)
 	app := fiber.New()
 
-	// Add auth middleware
+	// Authenticate as the user in the X-User-ID header
 	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
-		c.Locals("user_email", "john.doe@example.com")
+		c.Locals("user_id", uuid.MustParse(c.Get("X-User-ID", "3484ec33-20f9-4993-a25f-f49f6f5dbe54")))
 		return c.Next()
 	})
 
 	app.Get("/tasks", handler.ListTasks)
-	httpReq := httptest.NewRequest(http.MethodGet, "/tasks?status=pending&search=test&page=1&limit=10", nil)
-	httpReq.Header.Set("Authorization", "Bearer "+token)
+	app.Post("/tasks", handler.CreateTask)
+	app.Put("/tasks/:id/assignee", handler.AssignTask)
+	app.Post("/tasks/:id/shares", handler.ShareTask)
+	app.Delete("/tasks/:id/shares/:userId", handler.UnshareTask)
+
+	send := func(method, path, body, userID string) (int, map[string]interface{}) {
+		httpReq := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+		if userID != "" {
+			httpReq.Header.Set("X-User-ID", userID)
+		}
+
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
 
-	resp, err := app.Test(httpReq)
+	jane := "550e8400-e29b-41d4-a716-446655440002"
+	mike := "550e8400-e29b-41d4-a716-446655440003"
+
+	status, response := send(http.MethodPost, "/tasks", `{"title":"Team offsite"}`, "")
+	require.Equal(t, http.StatusCreated, status)
+	taskID := response["data"].(map[string]interface{})["id"].(string)
+
+	status, response = send(http.MethodPut, "/tasks/"+taskID+"/assignee", `{"user_id":"`+jane+`"}`, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, jane, response["data"].(map[string]interface{})["assignee_id"])
+
+	status, response = send(http.MethodPost, "/tasks/"+taskID+"/shares", `{"user_id":"`+mike+`"}`, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{mike}, response["data"].(map[string]interface{})["shared_with"])
 
+	status, response = send(http.MethodPost, "/tasks/"+taskID+"/shares", `{"user_id":"`+uuid.New().String()+`"}`, "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "User not found", response["message"])
+
+	// Only the owner may share
+	status, _ = send(http.MethodDelete, "/tasks/"+taskID+"/shares/"+mike, "", jane)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// The assignee sees the task when filtering by assignment
+	status, response = send(http.MethodGet, "/tasks?assigned_to_me=true", "", jane)
+	assert.Equal(t, http.StatusOK, status)
+	tasks := response["data"].([]interface{})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, taskID, tasks[0].(map[string]interface{})["id"])
+
+	status, _ = send(http.MethodGet, "/tasks?assigned_to_me=maybe", "", jane)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, response = send(http.MethodPut, "/tasks/"+taskID+"/assignee", `{"user_id":null}`, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotContains(t, response["data"], "assignee_id")
+}
+
+func TestHandler_WorkspaceTasks(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	workspaceSvc := workspaceService.NewService(authSvc)
+	handler := NewHandler(taskService.NewServiceWithDeps(taskService.Deps{
+		Auth: authSvc, Bus: events.NewChannelBus(events.DefaultBufferSize), Workspaces: workspaceSvc}))
+
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	created, err := workspaceSvc.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, userID)
+	require.NoError(t, err)
+	project, err := workspaceSvc.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "Launch"}, userID)
 	require.NoError(t, err)
+
+	// Stand in for the auth and workspace membership middleware
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("workspace_id", created.ID)
+		return c.Next()
+	})
+
+	base := "/workspaces/" + created.ID.String() + "/tasks"
+	app.Get(base, handler.ListWorkspaceTasks)
+	app.Post(base, handler.CreateWorkspaceTask)
+	app.Get("/workspaces/"+created.ID.String()+"/reports/aging", handler.GetWorkspaceAgingReport)
+
+	send := func(method, path, body string) (int, map[string]interface{}) {
+		httpReq := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	status, response := send(http.MethodPost, base, `{"title":"Plan launch","project_id":"`+project.ID.String()+`"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, created.ID.String(), data["workspace_id"])
+	assert.Equal(t, project.ID.String(), data["project_id"])
+
+	status, response = send(http.MethodPost, base, `{"title":"Plan launch","project_id":"`+uuid.New().String()+`"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "Project not found", response["message"])
+
+	status, response = send(http.MethodGet, base+"?project_id="+project.ID.String(), "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["data"], 1)
+
+	status, _ = send(http.MethodGet, base+"?project_id=invalid", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, response = send(http.MethodGet, "/workspaces/"+created.ID.String()+"/reports/aging", "")
+	assert.Equal(t, http.StatusOK, status)
+	data = response["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["open"])
+	assert.Equal(t, map[string]interface{}{"under_1d": float64(1), "1d_to_7d": float64(0), "over_7d": float64(0)}, data["aging"])
+	assert.Len(t, data["by_project"], 1)
+}
+
+func TestHandler_DeleteTask_ExistingTask(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Delete("/tasks/:id", handler.DeleteTask)
+
+	taskID := uuid.New()
+	taskSvc.On("DeleteTask", taskID, johnID).Return(nil).Once()
+	taskSvc.On("DeleteTask", taskID, johnID).Return(errors.New("task not found")).Once()
+
+	resp, response := send(t, app, http.MethodDelete, "/tasks/"+taskID.String(), "")
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
+	assert.Equal(t, false, response["error"])
+	assert.Equal(t, "Task deleted successfully", response["message"])
+
+	resp, response = send(t, app, http.MethodDelete, "/tasks/"+taskID.String(), "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "Task not found", response["message"])
+}
+
+func TestHandler_GetTaskHistory(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Get("/tasks/:id/history", handler.GetTaskHistory)
+
+	taskID := uuid.New()
+	taskSvc.On("GetTaskHistory", taskID, johnID).Return([]*activity.Entry{activity.NewEntry(taskID, johnID, activity.ActionCreated)}, nil)
+
+	resp, response := send(t, app, http.MethodGet, "/tasks/"+taskID.String()+"/history", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
 	assert.Equal(t, false, response["error"])
-	assert.Equal(t, "Tasks retrieved successfully", response["message"])
-	assert.NotNil(t, response["data"])
-	assert.NotNil(t, response["meta"])
+	assert.Equal(t, "Task history retrieved successfully", response["message"])
+
+	history := response["data"].([]interface{})
+	require.Len(t, history, 1)
+	assert.Equal(t, "created", history[0].(map[string]interface{})["action"])
 }
 
-// Helper functions for tests
-func stringPtr(s string) *string {
-	return &s
+func TestHandler_ListTasks_NoFilters(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Get("/tasks", handler.ListTasks)
+
+	taskSvc.On("ListTasks", mock.Anything, mock.Anything, 1, 10, johnID).
+		Return([]*task.Task{task.NewTask("Test Task", johnID)}, &types.PaginationInfo{Page: 1, Limit: 10, Total: 1, TotalPages: 1}, nil)
+
+	resp, response := send(t, app, http.MethodGet, "/tasks", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, false, response["error"])
+	assert.Equal(t, "Tasks retrieved successfully", response["message"])
+	assert.Len(t, response["data"], 1)
+	assert.NotNil(t, response["meta"])
+}
+
+func TestHandler_ListTasks_WithFilters(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Get("/tasks", handler.ListTasks)
+
+	isQuery := mock.MatchedBy(func(filter *task.TaskFilter) bool {
+		return filter.String() == "status:pending,search:test"
+	})
+	taskSvc.On("ListTasks", isQuery, mock.Anything, 2, 5, johnID).
+		Return([]*task.Task{}, &types.PaginationInfo{Page: 2, Limit: 5, Total: 5, TotalPages: 1}, nil)
+
+	resp, response := send(t, app, http.MethodGet, "/tasks?status=pending&search=test&page=2&limit=5", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, false, response["error"])
+	assert.Equal(t, "Tasks retrieved successfully", response["message"])
+	assert.NotNil(t, response["data"])
+	assert.NotNil(t, response["meta"])
+}
+
+func stringPtr(s string) *string {
+	return &s
 }
 
 func statusPtr(s task.TaskStatus) *task.TaskStatus {
 	return &s
 }
+
+func TestHandler_ExportTasks_CSV(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+
+	app.Get("/tasks/export", handler.ExportTasks)
+
+	// Titles needing escaping survive the export
+	_, err := handler.taskService.CreateTask(&task.CreateTaskRequest{Title: `Buy "milk", eggs`}, userID)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/tasks/export?format=csv&search=milk", nil)
+
+	resp, err := app.Test(httpReq)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="tasks.csv"`, resp.Header.Get("Content-Disposition"))
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, `Buy "milk", eggs`, records[1][1])
+	assert.Equal(t, "pending", records[1][2])
+}
+
+func TestHandler_ExportTasks_UnsupportedFormat(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+
+	app.Get("/tasks/export", handler.ExportTasks)
+	httpReq := httptest.NewRequest(http.MethodGet, "/tasks/export?format=pdf", nil)
+
+	resp, err := app.Test(httpReq)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func newImportRequest(t *testing.T, filename, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/tasks/import", body)
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+	return httpReq
+}
+
+func TestHandler_ImportTasks(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+
+	app.Post("/tasks/import", handler.ImportTasks)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		imported float64
+		failed   float64
+		message  string
+	}{
+		{
+			name:     "csv",
+			filename: "tasks.csv",
+			content:  "title,status\nWrite report,completed\n\"Call Bob, then Alice\",\n,pending\nFix bug,unknown\n",
+			imported: 2,
+			failed:   2,
+			message:  "Tasks imported with errors",
+		},
+		{
+			name:     "json",
+			filename: "tasks.json",
+			content:  `[{"title":"Write report","status":"in_progress"},{"title":"Call Bob"}]`,
+			imported: 2,
+			failed:   0,
+			message:  "Tasks imported successfully",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(newImportRequest(t, tt.filename, tt.content))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var response map[string]interface{}
+			err = json.NewDecoder(resp.Body).Decode(&response)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.message, response["message"])
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, tt.imported, data["imported"])
+			assert.Equal(t, tt.failed, data["failed"])
+		})
+	}
+}
+
+func TestHandler_TaskLimits(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	// John owns three fixture tasks, leaving room for one more
+	authSvc := auth.NewService(cfg)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	handler := NewHandler(taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus,
+		Workspaces: workspaceService.NewService(authSvc), Tenants: tenantService.NewService(authSvc), Limits: task.Limits{MaxTasks: 4}}))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Post("/tasks", handler.CreateTask)
+	app.Post("/tasks/import", handler.ImportTasks)
+
+	// Imports that do not fit the remaining limit are rejected as a whole
+	resp, err := app.Test(newImportRequest(t, "tasks.json", `[{"title":"One"},{"title":"Two"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	create := func() *http.Response {
+		httpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(`{"title":"Limited"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusCreated, create().StatusCode)
+
+	resp = create()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "limit exceeded: at most 4 tasks per user", response["message"])
+}
+
+func TestHandler_PlanLimits(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	// John owns three fixture tasks, the most his plan allows
+	authSvc := auth.NewService(cfg)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	handler := NewHandler(taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus,
+		Workspaces: workspaceService.NewService(authSvc), Tenants: tenantService.NewService(authSvc), Limits: task.Limits{MaxTasks: 3, Plan: "free"}}))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+	app.Post("/tasks", handler.CreateTask)
+	app.Post("/tasks/import", handler.ImportTasks)
+
+	resp, err := app.Test(newImportRequest(t, "tasks.json", `[{"title":"One"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(`{"title":"Limited"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "upgrade required: the free plan allows at most 3 tasks", response["message"])
+}
+
+func TestHandler_ImportTasks_RowErrors(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+
+	app.Post("/tasks/import", handler.ImportTasks)
+
+	resp, err := app.Test(newImportRequest(t, "tasks.csv", "title,status\nValid,pending\n,pending\nOther,done\n"))
+	require.NoError(t, err)
+
+	var response struct {
+		Data task.ImportResult `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+
+	assert.Equal(t, []task.ImportRowError{
+		{Row: 2, Message: "title is required"},
+		{Row: 3, Message: "invalid status"},
+	}, response.Data.Errors)
+}
+
+func TestHandler_ImportTasks_Async(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute}}
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 3, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	handler := NewHandlerWithDeps(Deps{Tasks: taskService.NewService(auth.NewService(cfg)), Jobs: queue})
+	app := fiber.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", johnID)
+		return c.Next()
+	})
+
+	app.Post("/tasks/import", handler.ImportTasks)
+
+	req := newImportRequest(t, "tasks.csv", "title\nWrite report\n\n")
+	req.Header.Set("Prefer", "respond-async")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "respond-async", resp.Header.Get("Preference-Applied"))
+
+	var response struct {
+		Data jobs.Job `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, jobs.StatusPending, response.Data.Status)
+
+	// The import runs as a job owned by the user
+	var job *jobs.Job
+	require.Eventually(t, func() bool {
+		job, err = queue.Get(response.Data.ID)
+		return err == nil && job.Done()
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, jobs.StatusSucceeded, job.Status)
+	assert.Equal(t, johnID, *job.OwnerID)
+	var result task.ImportResult
+	require.NoError(t, json.Unmarshal(job.Result, &result))
+	assert.Equal(t, 1, result.Imported)
+
+	// Imports that cannot succeed fail without retries
+	req = newImportRequest(t, "tasks.csv", "title\n")
+	req.Header.Set("Prefer", "respond-async")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Eventually(t, func() bool {
+		job, err = queue.Get(response.Data.ID)
+		return err == nil && job.Done()
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, jobs.StatusFailed, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "import contains no rows", job.LastError)
+}
+
+func TestHandler_ImportTasks_InvalidFile(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+
+	app.Post("/tasks/import", handler.ImportTasks)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		expected string
+	}{
+		{"unsupported format", "tasks.txt", "title\nTask", "Unsupported import format, expected csv or json"},
+		{"missing title column", "tasks.csv", "name\nTask", "Invalid import file: missing title column"},
+		{"malformed json", "tasks.json", `{"title":"Task"}`, "Invalid import file: expected a JSON array of tasks"},
+		{"no rows", "tasks.csv", "title\n", "import contains no rows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(newImportRequest(t, tt.filename, tt.content))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			var response map[string]interface{}
+			err = json.NewDecoder(resp.Body).Decode(&response)
+			require.NoError(t, err)
+
+			assert.Equal(t, true, response["error"])
+			assert.Equal(t, tt.expected, response["message"])
+		})
+	}
+}
+
+func TestHandler_GetTask_ConditionalRequests(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+
+	app.Get("/tasks/:id", handler.GetTask)
+
+	createdTask, err := handler.taskService.CreateTask(&task.CreateTaskRequest{Title: "Cached task"}, userID)
+	require.NoError(t, err)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tasks/"+createdTask.ID.String(), nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, createdTask.UpdatedAt.UTC().Format(http.TimeFormat), lastModified)
+
+	// Matching ETag
+	httpReq := httptest.NewRequest(http.MethodGet, "/tasks/"+createdTask.ID.String(), nil)
+	httpReq.Header.Set("If-None-Match", etag)
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	// Not modified since the last response
+	httpReq = httptest.NewRequest(http.MethodGet, "/tasks/"+createdTask.ID.String(), nil)
+	httpReq.Header.Set("If-Modified-Since", lastModified)
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	// Updating the task invalidates the ETag
+	_, err = handler.taskService.UpdateTask(createdTask.ID, &task.UpdateTaskRequest{Title: stringPtr("Changed")}, userID)
+	require.NoError(t, err)
+
+	httpReq = httptest.NewRequest(http.MethodGet, "/tasks/"+createdTask.ID.String(), nil)
+	httpReq.Header.Set("If-None-Match", etag)
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+}
+
+func TestHandler_ListTasks_ConditionalRequests(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+
+	app.Get("/tasks", handler.ListTasks)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	require.NoError(t, err)
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Empty(t, resp.Header.Get("Last-Modified"))
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	httpReq.Header.Set("If-None-Match", `"stale", `+etag)
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	// Deleting a task changes the list even though no remaining task was modified
+	tasks, _, err := handler.taskService.ListTasks(nil, nil, 1, 10, userID)
+	require.NoError(t, err)
+	require.NoError(t, handler.taskService.DeleteTask(tasks[0].ID, userID))
+
+	httpReq = httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	httpReq.Header.Set("If-None-Match", etag)
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Without an ETag, If-Modified-Since alone cannot tell that the list changed
+	httpReq = httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	httpReq.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestHandler_ConcurrentReadsAndUpdates reads and lists tasks while they are updated, for the
+// race detector to check responses and cache entries are encoded from copies of the tasks
+func TestHandler_ConcurrentReadsAndUpdates(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}}
+	taskSvc := taskService.NewServiceWithDeps(taskService.Deps{Auth: auth.NewService(cfg), Cache: cache.NewMemoryCache(), CacheTTL: time.Minute})
+	handler := NewHandler(taskSvc)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+	app.Get("/tasks", handler.ListTasks)
+	app.Get("/tasks/:id", handler.GetTask)
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Contended task"}, userID)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	updated := make(chan struct{})
+	go func() {
+		defer close(updated)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			title := fmt.Sprintf("Contended task %d", i)
+			_, err := taskSvc.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: &title, Tags: &[]string{"tag"}}, userID)
+			assert.NoError(t, err)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"/tasks/" + created.ID.String(), "/tasks"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+				if assert.NoError(t, err) {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	<-updated
+}
+
+func TestHandler_ListTasks_SortAndFilterExpressions(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+
+	app.Get("/tasks", handler.ListTasks)
+
+	_, err := handler.taskService.CreateTask(&task.CreateTaskRequest{Title: "Urgent", Priority: task.PriorityHigh}, userID)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/tasks?status=pending,in_progress&sort=priority:desc,due_date:asc&created_after=2000-01-01", nil)
+	resp, err := app.Test(httpReq)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []task.Task `json:"data"`
+		Meta struct {
+			Sort   string `json:"sort"`
+			Filter string `json:"filter"`
+		} `json:"meta"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+
+	require.Len(t, response.Data, 4)
+	assert.Equal(t, "Urgent", response.Data[0].Title)
+	assert.Equal(t, "priority:desc,due_date:asc", response.Meta.Sort)
+	assert.Equal(t, "status:pending|in_progress,created_after:2000-01-01T00:00:00Z", response.Meta.Filter)
+}
+
+func TestHandler_ListTasks_Due(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+	userID := uuid.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+
+	app.Get("/tasks", handler.ListTasks)
+
+	// Days in Kiritimati, 14 hours ahead of UTC, differ from those in UTC most of the time
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	require.NoError(t, err)
+	year, month, day := time.Now().In(kiritimati).Date()
+	for title, due := range map[string]time.Time{
+		"Today":    time.Date(year, month, day, 0, 30, 0, 0, kiritimati),
+		"Tomorrow": time.Date(year, month, day+1, 0, 30, 0, 0, kiritimati),
+	} {
+		_, err := handler.taskService.CreateTask(&task.CreateTaskRequest{Title: title, DueDate: &due}, userID)
+		require.NoError(t, err)
+	}
+	_, err = handler.taskService.CreateTask(&task.CreateTaskRequest{Title: "Someday"}, userID)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks?due=today", nil)
+	req.Header.Set(TimeZoneHeader, "Pacific/Kiritimati")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []task.Task `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "Today", response.Data[0].Title)
+
+	for _, tt := range []struct{ query, timeZone string }{
+		{"due=tomorrow", ""},
+		{"due=today", "Mars/Olympus_Mons"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/tasks?"+tt.query, nil)
+		req.Header.Set(TimeZoneHeader, tt.timeZone)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.query+" "+tt.timeZone)
+	}
+}
+
+func TestHandler_ListTasks_InvalidQuery(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+
+	app.Get("/tasks", handler.ListTasks)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"invalid status", "status=pending,done", "invalid status: done"},
+		{"invalid sort field", "sort=owner:asc", "invalid sort field: owner"},
+		{"invalid date", "created_before=yesterday", "invalid created_before: expected RFC 3339 timestamp or YYYY-MM-DD date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tasks?"+tt.query, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			var response map[string]interface{}
+			err = json.NewDecoder(resp.Body).Decode(&response)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, response["message"])
+		})
+	}
+}
+
+func FuzzHandler_ParseQuery(f *testing.F) {
+	f.Add("pending,in_progress", "report", "priority:desc,due_date:asc", "", "", "2000-01-01", "", "true", "false", "2", "20")
+	f.Add("pending,done", "", "owner:asc", "title", "up", "yesterday", "2024-13-01", "maybe", "1", "-1", "1000")
+	f.Add(" completed ,", "日本語 ü", "", "títle", "desc", "2024-02-30T25:00:00Z", "2024-01-01T00:00:00+05:30", "", "TRUE", "0", "abc")
+	f.Add("", "\x00", ":,:", "", "", "", "", "t", "", "99999999999999999999", "100")
+
+	handler, _ := setupTestHandler(f)
+	app := fiber.New()
+	app.Get("/tasks", func(c *fiber.Ctx) error {
+		c.Locals("user_id", johnID)
+		filter, sort, err := handler.parseQuery(c)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		page, limit := handler.parsePagination(c)
+
+		// Parsed queries only hold values the service accepts
+		if filter != nil {
+			for _, status := range filter.Statuses {
+				if _, err := task.ParseStatuses(string(status)); err != nil {
+					return fmt.Errorf("parsed invalid status %q", status)
+				}
+			}
+		}
+		if _, err := task.ParseTaskSort(sort.String()); err != nil {
+			return fmt.Errorf("parsed invalid sort %q: %w", sort, err)
+		}
+		if page < 1 || limit < 1 || limit > 100 {
+			return fmt.Errorf("parsed invalid pagination: page %d, limit %d", page, limit)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	f.Fuzz(func(t *testing.T, status, search, sortExpr, sortField, sortOrder, createdAfter, createdBefore,
+		assignedToMe, archived, page, limit string) {
+		query := url.Values{}
+		for key, value := range map[string]string{
+			"status": status, "search": search, "sort": sortExpr, "sort_field": sortField, "sort_order": sortOrder,
+			"created_after": createdAfter, "created_before": createdBefore, "assigned_to_me": assignedToMe,
+			"archived": archived, "page": page, "limit": limit,
+		} {
+			if value != "" {
+				query.Set(key, value)
+			}
+		}
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tasks?"+query.Encode(), nil), -1)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, []int{fiber.StatusOK, fiber.StatusBadRequest}, resp.StatusCode, "%s: %s", query.Encode(), body)
+	})
+}
+
+func TestHandler_SparseFieldsets(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+
+	app.Get("/tasks", handler.ListTasks)
+	app.Get("/tasks/:id", handler.GetTask)
+
+	createdTask, err := handler.taskService.CreateTask(&task.CreateTaskRequest{Title: "Sparse task"}, userID)
+	require.NoError(t, err)
+
+	// Detail
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tasks/"+createdTask.ID.String()+"?fields=id,title", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var detail struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&detail)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":    createdTask.ID.String(),
+		"title": "Sparse task",
+	}, detail.Data)
+
+	// List
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/tasks?fields=id,status", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var list struct {
+		Data []map[string]interface{} `json:"data"`
+		Meta map[string]interface{}   `json:"meta"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&list)
+	require.NoError(t, err)
+	require.NotEmpty(t, list.Data)
+	for _, item := range list.Data {
+		assert.Len(t, item, 2)
+		assert.Contains(t, item, "id")
+		assert.Contains(t, item, "status")
+	}
+	assert.NotNil(t, list.Meta["pagination"])
+
+	// Unknown fields are rejected
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/tasks?fields=id,secret", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var response map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid field: secret", response["message"])
+}
+
+func TestHandler_GetTask_ContentNegotiation(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return c.Next()
+	})
+
+	app.Get("/tasks/:id", handler.GetTask)
+
+	createdTask, err := handler.taskService.CreateTask(&task.CreateTaskRequest{Title: "Negotiated"}, userID)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/tasks/"+createdTask.ID.String()+"?fields=id,title", nil)
+	httpReq.Header.Set("Accept", "application/xml")
+
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/xml; charset=utf-8", resp.Header.Get("Content-Type"))
+	etag := resp.Header.Get("ETag")
+
+	var response struct {
+		Message string `xml:"message"`
+		Data    struct {
+			ID    string `xml:"id"`
+			Title string `xml:"title"`
+		} `xml:"data"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, "Task retrieved successfully", response.Message)
+	assert.Equal(t, createdTask.ID.String(), response.Data.ID)
+	assert.Equal(t, "Negotiated", response.Data.Title)
+
+	// Each representation has its own ETag
+	httpReq = httptest.NewRequest(http.MethodGet, "/tasks/"+createdTask.ID.String()+"?fields=id,title", nil)
+	httpReq.Header.Set("Accept", "application/msgpack")
+	httpReq.Header.Set("If-None-Match", etag)
+
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/msgpack", resp.Header.Get("Content-Type"))
+}
+
+func TestHandler_GetStats(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	app := fiber.New()
+
+	// Add auth middleware
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"))
+		return c.Next()
+	})
+
+	app.Get("/tasks/stats", handler.GetStats)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tasks/stats", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Message string     `json:"message"`
+		Data    task.Stats `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, "Task statistics retrieved successfully", response.Message)
+	assert.Equal(t, 3, response.Data.Total)
+	assert.Len(t, response.Data.Daily, 30)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/tasks/stats?from=2024-01-01&to=2024-01-07", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response.Data.Daily, 7)
+	assert.Equal(t, "2024-01-01", response.Data.Daily[0].Date)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/tasks/stats?from=2024-02-01&to=2024-01-01", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandler_QuickAdd(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Post("/quick-add", handler.QuickAdd)
+
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	require.NoError(t, err)
+	year, month, day := time.Now().In(kiritimati).Date()
+	wantDue := time.Date(year, month, day+1, 17, 0, 0, 0, kiritimati)
+
+	var created *task.CreateTaskRequest
+	taskSvc.On("CreateTask", mock.MatchedBy(func(req *task.CreateTaskRequest) bool {
+		created = req
+		return true
+	}), johnID).Return(task.NewTask("Pay rent", johnID), nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/quick-add",
+		bytes.NewBufferString(`{"text":"Pay rent tomorrow 5pm #finance !high"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimeZoneHeader, "Pacific/Kiritimati")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	require.NotNil(t, created)
+	assert.Equal(t, "Pay rent", created.Title)
+	assert.Equal(t, task.PriorityHigh, created.Priority)
+	assert.Equal(t, []string{"finance"}, created.Tags)
+	require.NotNil(t, created.DueDate)
+	assert.True(t, wantDue.Equal(*created.DueDate), "due %s, want %s", created.DueDate, wantDue)
+
+	// Limits are reported as for other tasks
+	taskSvc.On("CreateTask", mock.Anything, johnID).Return(nil, task.ErrLimitExceeded).Once()
+	resp, response := send(t, app, http.MethodPost, "/quick-add", `{"text":"One too many"}`)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, task.ErrLimitExceeded.Error(), response["message"])
+
+	resp, response = send(t, app, http.MethodPost, "/quick-add", `{"text":"  "}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "text is required", response["message"])
+}
+
+func TestHandler_NaturalLanguageDue(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Post("/tasks", handler.CreateTask)
+	app.Put("/tasks/:id", handler.UpdateTask)
+
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	require.NoError(t, err)
+	year, month, day := time.Now().In(kiritimati).Date()
+	wantDue := time.Date(year, month, day+1, 17, 0, 0, 0, kiritimati)
+
+	created := task.NewTask("Pay rent", johnID)
+	created.DueDate = &wantDue
+	taskSvc.On("CreateTask", mock.MatchedBy(func(req *task.CreateTaskRequest) bool {
+		return req.Due == "" && req.DueDate != nil && req.DueDate.Equal(wantDue)
+	}), johnID).Return(created, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks",
+		bytes.NewBufferString(`{"title":"Pay rent","due":"tomorrow at 5pm"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimeZoneHeader, "Pacific/Kiritimati")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// The resolved timestamp is returned with the offset of the time zone
+	var response struct {
+		Data task.Task `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.NotNil(t, response.Data.DueDate)
+	assert.True(t, wantDue.Equal(*response.Data.DueDate))
+	_, offset := response.Data.DueDate.Zone()
+	assert.Equal(t, 14*60*60, offset)
+
+	taskID := uuid.New()
+	taskSvc.On("UpdateTask", taskID, mock.MatchedBy(func(req *task.UpdateTaskRequest) bool {
+		return req.Due == "" && req.DueDate != nil && req.DueDate.Equal(wantDue)
+	}), johnID).Return(created, nil).Once()
+
+	req = httptest.NewRequest(http.MethodPut, "/tasks/"+taskID.String(), bytes.NewBufferString(`{"due":"tomorrow 17:00"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimeZoneHeader, "Pacific/Kiritimati")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, body := range []string{
+		`{"title":"Pay rent","due":"whenever"}`,
+		`{"title":"Pay rent","due":"friday","due_date":"2026-01-02T15:04:05Z"}`,
+	} {
+		resp, _ := send(t, app, http.MethodPost, "/tasks", body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+}
+
+func TestHandler_CreateTask_Duplicates(t *testing.T) {
+	cfg := &config.Config{}
+	authSvc := auth.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	notifications := notificationService.NewService(cfg, authSvc, taskSvc, mailer.NewMemoryMailer())
+	handler := NewHandlerWithDeps(Deps{Tasks: taskSvc, Notifications: notifications})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", johnID)
+		return c.Next()
+	})
+	app.Post("/tasks", handler.CreateTask)
+	app.Post("/quick-add", handler.QuickAdd)
+
+	const body = `{"title":"Water the plants"}`
+	resp, _ := send(t, app, http.MethodPost, "/tasks", body)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Duplicates are allowed until the user turns checks on
+	resp, _ = send(t, app, http.MethodPost, "/tasks", body)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	on := true
+	notifications.UpdatePreferences(johnID, &notification.UpdatePreferencesRequest{DuplicateCheck: &on})
+
+	resp, response := send(t, app, http.MethodPost, "/tasks", `{"title":"water the plants!"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, "A similar open task already exists, send force=true to create it anyway", response["message"])
+	existing := response["data"].(map[string]interface{})
+	assert.Equal(t, "Water the plants", existing["title"])
+
+	resp, _ = send(t, app, http.MethodPost, "/quick-add", `{"text":"Water the plants tomorrow #home"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, _ = send(t, app, http.MethodPost, "/tasks?force=true", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp, _ = send(t, app, http.MethodPost, "/tasks", `{"title":"Water the garden"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestHandler_TaskChanges(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Get("/tasks/changes", handler.TaskChanges)
+
+	deleted := uuid.New()
+	taskSvc.On("TaskChanges", "abc.1", 50, johnID).Return(&task.ChangeSet{
+		Created: []uuid.UUID{}, Updated: []uuid.UUID{}, Deleted: []uuid.UUID{deleted}, Cursor: "abc.2",
+	}, nil).Once()
+
+	resp, response := send(t, app, http.MethodGet, "/tasks/changes?since=abc.1&limit=50", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, []interface{}{deleted.String()}, data["deleted"])
+	assert.Equal(t, "abc.2", data["cursor"])
+	assert.Equal(t, false, data["has_more"])
+
+	taskSvc.On("TaskChanges", "old.1", task.DefaultSyncLimit, johnID).Return(nil, task.ErrCursorExpired).Once()
+	resp, _ = send(t, app, http.MethodGet, "/tasks/changes?since=old.1", "")
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+
+	taskSvc.On("TaskChanges", "bad", task.DefaultSyncLimit, johnID).Return(nil, task.ErrInvalidCursor).Once()
+	resp, _ = send(t, app, http.MethodGet, "/tasks/changes?since=bad", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandler_ResolveTask(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Put("/tasks/:id", handler.UpdateTask)
+	app.Post("/tasks/:id/resolve", handler.ResolveTask)
+
+	taskID := uuid.New()
+	server := &task.Task{ID: taskID, Title: "Call the plumber today", Status: task.StatusInProgress, UserID: johnID, Version: 3}
+	conflict := &task.ConflictError{Server: server, Client: []task.ConflictField{
+		{Field: "title", Server: server.Title, Client: "Call the plumber"},
+	}}
+	taskSvc.On("UpdateTask", taskID, mock.Anything, johnID).Return(nil, conflict).Once()
+
+	resp, response := send(t, app, http.MethodPut, "/tasks/"+taskID.String(), `{"title": "Call the plumber", "base_version": 2}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(3), data["server"].(map[string]interface{})["version"])
+	client := data["client"].([]interface{})
+	require.Len(t, client, 1)
+	assert.Equal(t, "Call the plumber", client[0].(map[string]interface{})["client"])
+
+	resolved := &task.Task{ID: taskID, Title: "Call the plumber", UserID: johnID, Version: 4}
+	taskSvc.On("ResolveTask", taskID, mock.MatchedBy(func(req *task.UpdateTaskRequest) bool {
+		return req.BaseVersion != nil && *req.BaseVersion == 3 && *req.Title == "Call the plumber"
+	}), johnID).Return(resolved, nil).Once()
+
+	resp, response = send(t, app, http.MethodPost, "/tasks/"+taskID.String()+"/resolve", `{"title": "Call the plumber", "base_version": 3}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Conflict resolved successfully", response["message"])
+
+	taskSvc.On("ResolveTask", taskID, mock.Anything, johnID).Return(nil, task.ErrBaseVersionRequired).Once()
+	resp, _ = send(t, app, http.MethodPost, "/tasks/"+taskID.String()+"/resolve", `{"title": "Call the plumber"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandler_GetTaskPresence(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Get("/tasks/:id/presence", handler.GetTaskPresence)
+
+	taskID := uuid.New()
+	taskSvc.On("TaskPresence", taskID, johnID).Return([]task.Presence{
+		{UserID: uuid.New(), State: task.PresenceEditing, Since: time.Now()},
+	}, nil).Once()
+
+	resp, response := send(t, app, http.MethodGet, "/tasks/"+taskID.String()+"/presence", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	data := response["data"].([]interface{})
+	require.Len(t, data, 1)
+	assert.Equal(t, "editing", data[0].(map[string]interface{})["state"])
+
+	taskSvc.On("TaskPresence", taskID, johnID).Return(nil, errors.New("task not found")).Once()
+	resp, _ = send(t, app, http.MethodGet, "/tasks/"+taskID.String()+"/presence", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_HandlePresenceMessage(t *testing.T) {
+	handler, taskSvc, _ := setupMockedHandler(t)
+
+	taskID := uuid.New()
+	taskSvc.On("UpdatePresence", "session", taskID, task.PresenceEditing, johnID).Return(nil).Once()
+	assert.Nil(t, handler.handlePresenceMessage("session", []byte(`{"type": "presence", "task_id": "`+taskID.String()+`", "state": "editing"}`), johnID))
+
+	taskSvc.On("UpdatePresence", "session", taskID, task.PresenceViewing, johnID).Return(errors.New("task not found")).Once()
+	reply := handler.handlePresenceMessage("session", []byte(`{"type": "presence", "task_id": "`+taskID.String()+`", "state": "viewing"}`), johnID)
+	assert.Equal(t, "Task not found", reply["message"])
+
+	assert.Equal(t, "Invalid message", handler.handlePresenceMessage("session", []byte(`not json`), johnID)["message"])
+	assert.Equal(t, "unknown message type", handler.handlePresenceMessage("session", []byte(`{"type": "ping"}`), johnID)["message"])
+}
+
+func TestHandler_ShareLinks(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Post("/tasks/:id/share-link", handler.CreateShareLink)
+	app.Delete("/tasks/:id/share-links/:linkId", handler.RevokeShareLink)
+	app.Post("/workspaces/:wid/projects/:projectId/share-link", handler.CreateShareLink)
+	app.Get("/shared/:token", handler.ViewShareLink)
+
+	taskID := uuid.New()
+	link := &task.ShareLink{ID: uuid.New(), TaskID: &taskID}
+	taskSvc.On("CreateShareLink", task.ShareTarget{TaskID: &taskID}, &task.CreateShareLinkRequest{ExpiresInDays: 3}, johnID).
+		Return(&task.CreatedShareLink{ShareLink: link, Token: "shr_token"}, nil).Once()
+
+	resp, response := send(t, app, http.MethodPost, "/tasks/"+taskID.String()+"/share-link", `{"expires_in_days": 3}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "shr_token", response["data"].(map[string]interface{})["token"])
+
+	// Without a body, the defaults apply
+	taskSvc.On("CreateShareLink", task.ShareTarget{TaskID: &taskID}, &task.CreateShareLinkRequest{}, johnID).
+		Return(nil, errors.New("access denied")).Once()
+	resp, _ = send(t, app, http.MethodPost, "/tasks/"+taskID.String()+"/share-link", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	workspaceID, projectID := uuid.New(), uuid.New()
+	taskSvc.On("CreateShareLink", task.ShareTarget{WorkspaceID: &workspaceID, ProjectID: &projectID}, mock.Anything, johnID).
+		Return(nil, errors.New("project not found")).Once()
+	resp, _ = send(t, app, http.MethodPost, "/workspaces/"+workspaceID.String()+"/projects/"+projectID.String()+"/share-link", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	taskSvc.On("RevokeShareLink", task.ShareTarget{TaskID: &taskID}, link.ID, johnID).Return(link, nil).Once()
+	resp, _ = send(t, app, http.MethodDelete, "/tasks/"+taskID.String()+"/share-links/"+link.ID.String(), "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	taskSvc.On("ViewShareLink", "shr_token").Return(&task.SharedView{Task: &task.PublicTask{ID: taskID, Title: "Plan"}}, nil).Once()
+	resp, response = send(t, app, http.MethodGet, "/shared/shr_token", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "Plan", response["data"].(map[string]interface{})["task"].(map[string]interface{})["title"])
+
+	taskSvc.On("ViewShareLink", "shr_revoked").Return(nil, task.ErrShareLinkNotFound).Once()
+	resp, _ = send(t, app, http.MethodGet, "/shared/shr_revoked", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_GetProjectBoard(t *testing.T) {
+	handler, taskSvc, app := setupMockedHandler(t)
+	app.Get("/public/projects/:token", handler.GetProjectBoard)
+
+	board := &task.ProjectBoard{ID: uuid.New(), Name: "Launch", Tasks: []*task.BoardTask{{ID: uuid.New(), Title: "Book venue", Status: task.StatusInProgress}}}
+	pagination := &types.PaginationInfo{Page: 2, Limit: 10, Total: 11, TotalPages: 2}
+	taskSvc.On("ProjectBoard", "shr_board", 2, 10).Return(board, pagination, nil)
+
+	resp, response := send(t, app, http.MethodGet, "/public/projects/shr_board?page=2&limit=10", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "public, max-age=60", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Launch", response["data"].(map[string]interface{})["name"])
+	assert.Equal(t, float64(11), response["meta"].(map[string]interface{})["pagination"].(map[string]interface{})["total"])
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Revalidated by the ETag
+	httpReq := httptest.NewRequest(http.MethodGet, "/public/projects/shr_board?page=2&limit=10", nil)
+	httpReq.Header.Set("If-None-Match", etag)
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	taskSvc.On("ProjectBoard", "shr_revoked", 1, task.DefaultBoardLimit).Return(nil, nil, task.ErrShareLinkNotFound)
+	resp, _ = send(t, app, http.MethodGet, "/public/projects/shr_revoked", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+}
@@ -0,0 +1,226 @@
+package task
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/jobs"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// preferAsync is the preference of clients asking for an asynchronous response (RFC 7240)
+const preferAsync = "respond-async"
+
+// importJob imports the parsed rows of an uploaded file
+type importJob struct {
+	UserID uuid.UUID                 `json:"user_id"`
+	Tasks  []*task.ImportTaskRequest `json:"tasks"`
+}
+
+func (importJob) JobType() string { return "task.import" }
+
+// ImportTasks handles bulk task creation from an uploaded CSV or JSON file. Clients sending
+// "Prefer: respond-async" get the job importing the file instead, whose status and result
+// are read from /me/jobs/:id.
+func (h *Handler) ImportTasks(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "File is required",
+		})
+	}
+
+	format := importFormat(c.Query("format"), fileHeader.Filename, fileHeader.Header.Get(fiber.HeaderContentType))
+	if format == "" {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Unsupported import format, expected csv or json",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid import file",
+		})
+	}
+	defer file.Close()
+
+	var reqs []*task.ImportTaskRequest
+	if format == "csv" {
+		reqs, err = parseImportCSV(file)
+	} else {
+		reqs, err = parseImportJSON(file)
+	}
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid import file: " + err.Error(),
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if h.jobs != nil && prefersAsync(c) {
+		job, err := h.jobs.Enqueue(importJob{UserID: userID, Tasks: reqs}, jobs.WithOwner(userID))
+		if err != nil {
+			return response.Send(c, fiber.StatusServiceUnavailable, fiber.Map{
+				"error":   true,
+				"message": "Imports are unavailable, try again later",
+			})
+		}
+		c.Set("Preference-Applied", preferAsync)
+		return response.Send(c, fiber.StatusAccepted, fiber.Map{
+			"error":   false,
+			"message": "Import started",
+			"data":    job,
+		})
+	}
+
+	// Import tasks
+	result, err := h.taskService.ImportTasks(reqs, userID)
+	if err != nil {
+		if errors.Is(err, billing.ErrUpgradeRequired) {
+			return response.Send(c, fiber.StatusPaymentRequired, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, task.ErrLimitExceeded) {
+			return response.Send(c, fiber.StatusUnprocessableEntity, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	message := "Tasks imported successfully"
+	if result.Failed > 0 {
+		message = "Tasks imported with errors"
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// prefersAsync reports whether the client prefers an asynchronous response
+func prefersAsync(c *fiber.Ctx) bool {
+	for _, preference := range strings.Split(c.Get("Prefer"), ",") {
+		if strings.EqualFold(strings.TrimSpace(preference), preferAsync) {
+			return true
+		}
+	}
+	return false
+}
+
+// importFormat determines the upload format from the query, file extension, or content type
+func importFormat(format, filename, contentType string) string {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			format = "csv"
+		case ".json":
+			format = "json"
+		default:
+			switch {
+			case strings.HasPrefix(contentType, "text/csv"):
+				format = "csv"
+			case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON):
+				format = "json"
+			}
+		}
+	}
+
+	if format != "csv" && format != "json" {
+		return ""
+	}
+	return format
+}
+
+// parseImportCSV reads rows from a CSV file with a header containing a title and optional status column.
+// Files produced by the CSV export can be imported as-is; unknown columns are ignored.
+func parseImportCSV(r io.Reader) ([]*task.ImportTaskRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	titleCol, statusCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "title":
+			titleCol = i
+		case "status":
+			statusCol = i
+		}
+	}
+	if titleCol == -1 {
+		return nil, errors.New("missing title column")
+	}
+
+	var reqs []*task.ImportTaskRequest
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Short rows leave missing columns empty so they are reported by validation
+		req := &task.ImportTaskRequest{}
+		if titleCol < len(record) {
+			req.Title = record[titleCol]
+		}
+		if statusCol != -1 && statusCol < len(record) {
+			req.Status = task.TaskStatus(strings.TrimSpace(record[statusCol]))
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs, nil
+}
+
+// parseImportJSON reads rows from a JSON array of {"title": "...", "status": "..."} objects
+func parseImportJSON(r io.Reader) ([]*task.ImportTaskRequest, error) {
+	var reqs []*task.ImportTaskRequest
+	if err := json.NewDecoder(r).Decode(&reqs); err != nil {
+		return nil, errors.New("expected a JSON array of tasks")
+	}
+
+	// Null array entries are reported as invalid rows
+	for i, req := range reqs {
+		if req == nil {
+			reqs[i] = &task.ImportTaskRequest{}
+		}
+	}
+
+	return reqs, nil
+}
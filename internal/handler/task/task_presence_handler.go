@@ -0,0 +1,42 @@
+package task
+
+import (
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetTaskPresence handles listing the users who have a task open, for clients showing
+// collaborators when they open a task; later changes arrive over the WebSocket
+func (h *Handler) GetTaskPresence(c *fiber.Ctx) error {
+	taskID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid task ID",
+		})
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	presence, err := h.taskService.TaskPresence(taskID, userID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Task not found",
+			})
+		}
+		return response.Send(c, fiber.StatusForbidden, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Task presence retrieved successfully",
+		"data":    presence,
+	})
+}
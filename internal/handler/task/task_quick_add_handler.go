@@ -0,0 +1,84 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+	"todo-api/pkg/quickadd"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// QuickAdd handles creating a task from a line of natural text, such as
+// "Pay rent tomorrow 5pm #finance !high", as typed in a browser extension. Dates and times
+// are read in the time zone of the request or the user's preferences.
+func (h *Handler) QuickAdd(c *fiber.Ctx) error {
+	var req task.QuickAddRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	loc, err := h.location(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	parsed := quickadd.Parse(req.Text, time.Now().In(loc))
+	if existing := h.duplicateOf(c, parsed.Title, userID); existing != nil {
+		return sendDuplicate(c, existing)
+	}
+
+	newTask, err := h.taskService.CreateTask(&task.CreateTaskRequest{
+		Title:    parsed.Title,
+		Priority: task.TaskPriority(parsed.Priority),
+		DueDate:  parsed.Due,
+		Tags:     parsed.Tags,
+	}, userID)
+	if err != nil {
+		return sendCreateError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Task created successfully",
+		"data":    newTask,
+	})
+}
+
+// resolveDue returns the due date of a request, resolving a due date in natural language,
+// such as "next friday", in the time zone of the request or the user's preferences
+func (h *Handler) resolveDue(c *fiber.Ctx, due string, dueDate *time.Time) (*time.Time, error) {
+	if due == "" {
+		return dueDate, nil
+	}
+	if dueDate != nil {
+		return nil, errors.New("due and due_date cannot both be set")
+	}
+
+	loc, err := h.location(c)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := quickadd.ParseDue(due, time.Now().In(loc))
+	if err != nil {
+		return nil, err
+	}
+	return &resolved, nil
+}
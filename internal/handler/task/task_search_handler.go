@@ -0,0 +1,46 @@
+package task
+
+import (
+	"strconv"
+
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// defaultSearchLimit is the number of search results returned when no limit is given
+const defaultSearchLimit = 20
+
+// SearchTasks handles full-text search over the tasks visible to the user
+func (h *Handler) SearchTasks(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	limit := defaultSearchLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	hits, err := h.taskService.SearchTasks(c.Query("q"), limit, userID)
+	if err != nil {
+		if err.Error() == "query is required" {
+			return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusInternalServerError, fiber.Map{
+			"error":   true,
+			"message": "Failed to search tasks",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Search completed successfully",
+		"data":    hits,
+	})
+}
@@ -0,0 +1,199 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+	"todo-api/pkg/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CreateShareLink handles creating a read-only link to a task, or to a project on
+// workspace routes, for people without an account. The token is only returned here.
+func (h *Handler) CreateShareLink(c *fiber.Ctx) error {
+	target, err := shareTarget(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	var req task.CreateShareLinkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+				"error":   true,
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	link, err := h.taskService.CreateShareLink(target, &req, userID)
+	if err != nil {
+		return sendShareLinkError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Share link created successfully",
+		"data":    link,
+	})
+}
+
+// ListShareLinks handles listing the share links of a task or project with their views
+func (h *Handler) ListShareLinks(c *fiber.Ctx) error {
+	target, err := shareTarget(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	links, err := h.taskService.ListShareLinks(target, userID)
+	if err != nil {
+		return sendShareLinkError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Share links retrieved successfully",
+		"data":    links,
+	})
+}
+
+// RevokeShareLink handles revoking a share link of a task or project
+func (h *Handler) RevokeShareLink(c *fiber.Ctx) error {
+	target, err := shareTarget(c)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	linkID, err := uuid.Parse(c.Params("linkId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid share link ID",
+		})
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	link, err := h.taskService.RevokeShareLink(target, linkID, userID)
+	if err != nil {
+		return sendShareLinkError(c, err)
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Share link revoked successfully",
+		"data":    link,
+	})
+}
+
+// ViewShareLink handles viewing the task or project of a share link without an account.
+// Responses are not cached, so every view is counted, and not indexed by search engines.
+func (h *Handler) ViewShareLink(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("X-Robots-Tag", "noindex")
+
+	view, err := h.taskService.ViewShareLink(c.Params("token"))
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Share link not found or expired",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Shared content retrieved successfully",
+		"data":    view,
+	})
+}
+
+// boardCacheControl lets browsers and shared caches reuse a project board for a minute,
+// then revalidate it with its ETag
+const boardCacheControl = "public, max-age=60"
+
+// GetProjectBoard handles the public board of a project shared with a link, for embedding
+// in status pages. Boards can be fetched from any origin and cached by shared caches.
+func (h *Handler) GetProjectBoard(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+	c.Set("X-Robots-Tag", "noindex")
+
+	board, pagination, err := h.taskService.ProjectBoard(c.Params("token"), c.QueryInt("page", 1), c.QueryInt("limit", task.DefaultBoardLimit))
+	if err != nil {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Share link not found or expired",
+		})
+	}
+
+	// Boards are revalidated by their ETag only, as removing a task from the project does not
+	// make the board more recent
+	return sendCacheableAs(c, fiber.Map{
+		"error":   false,
+		"message": "Project board retrieved successfully",
+		"data":    board,
+		"meta":    &types.MetaInfo{Pagination: *pagination},
+	}, time.Time{}, boardCacheControl)
+}
+
+// shareTarget returns the project of workspace routes, or the task of task routes
+func shareTarget(c *fiber.Ctx) (task.ShareTarget, error) {
+	if c.Params("projectId") != "" {
+		workspaceID, err := uuid.Parse(c.Params("wid"))
+		if err != nil {
+			return task.ShareTarget{}, errors.New("Invalid workspace ID")
+		}
+		projectID, err := uuid.Parse(c.Params("projectId"))
+		if err != nil {
+			return task.ShareTarget{}, errors.New("Invalid project ID")
+		}
+		return task.ShareTarget{WorkspaceID: &workspaceID, ProjectID: &projectID}, nil
+	}
+
+	taskID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return task.ShareTarget{}, errors.New("Invalid task ID")
+	}
+	return task.ShareTarget{TaskID: &taskID}, nil
+}
+
+// sendShareLinkError responds with the status matching an error managing share links
+func sendShareLinkError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "task not found":
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Task not found",
+		})
+	case "workspace not found", "project not found", "share link not found":
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	case "access denied":
+		return response.Send(c, fiber.StatusForbidden, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,104 @@
+package task
+
+import (
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AssignTask handles assigning a task to a user, or unassigning it when user_id is null
+func (h *Handler) AssignTask(c *fiber.Ctx) error {
+	var req task.AssignTaskRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	return h.changeSharing(c, "Task assignee updated successfully", func(taskID, userID uuid.UUID) (*task.Task, error) {
+		return h.taskService.AssignTask(taskID, &req, userID)
+	})
+}
+
+// ShareTask handles sharing a task read-only with a user
+func (h *Handler) ShareTask(c *fiber.Ctx) error {
+	var req task.ShareTaskRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	return h.changeSharing(c, "Task shared successfully", func(taskID, userID uuid.UUID) (*task.Task, error) {
+		return h.taskService.ShareTask(taskID, &req, userID)
+	})
+}
+
+// UnshareTask handles revoking a user's read-only access to a task
+func (h *Handler) UnshareTask(c *fiber.Ctx) error {
+	sharedUserID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid user ID",
+		})
+	}
+
+	return h.changeSharing(c, "Task unshared successfully", func(taskID, userID uuid.UUID) (*task.Task, error) {
+		return h.taskService.UnshareTask(taskID, sharedUserID, userID)
+	})
+}
+
+// changeSharing parses the task ID and applies an assignment or sharing change
+func (h *Handler) changeSharing(c *fiber.Ctx, message string, change func(taskID, userID uuid.UUID) (*task.Task, error)) error {
+	// Parse task ID from URL parameter
+	taskID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid task ID",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	updatedTask, err := change(taskID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Task not found",
+			})
+		case "user not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "User not found",
+			})
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": message,
+		"data":    updatedTask,
+	})
+}
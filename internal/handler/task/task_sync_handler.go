@@ -0,0 +1,36 @@
+package task
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// TaskChanges handles listing the tasks created, updated, and deleted since the sync cursor
+// in the since parameter, for offline clients. Without since, only the current cursor is
+// returned. Expired cursors return 410 Gone, after which clients fetch all tasks again.
+func (h *Handler) TaskChanges(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	changes, err := h.taskService.TaskChanges(c.Query("since"), c.QueryInt("limit", task.DefaultSyncLimit), userID)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, task.ErrCursorExpired) {
+			status = fiber.StatusGone
+		}
+		return response.Send(c, status, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Changes retrieved successfully",
+		"data":    changes,
+	})
+}
@@ -0,0 +1,123 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/response"
+	"todo-api/pkg/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ListWorkspaceTasks handles listing the tasks of the workspace resolved by the WorkspaceMember middleware
+func (h *Handler) ListWorkspaceTasks(c *fiber.Ctx) error {
+	workspaceID := c.Locals("workspace_id").(uuid.UUID)
+
+	return h.listTasks(c, func(filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, error) {
+		return h.taskService.ListWorkspaceTasks(workspaceID, filter, sort, page, limit, userID)
+	})
+}
+
+// CreateWorkspaceTask handles task creation in the workspace resolved by the WorkspaceMember middleware
+func (h *Handler) CreateWorkspaceTask(c *fiber.Ctx) error {
+	var req task.CreateTaskRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	dueDate, err := h.resolveDue(c, req.Due, req.DueDate)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	req.Due, req.DueDate = "", dueDate
+
+	// Get user and workspace from context
+	userID := c.Locals("user_id").(uuid.UUID)
+	workspaceID := c.Locals("workspace_id").(uuid.UUID)
+
+	// Create task
+	newTask, err := h.taskService.CreateWorkspaceTask(workspaceID, &req, userID)
+	if err != nil {
+		switch err.Error() {
+		case "project not found":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Project not found",
+			})
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, billing.ErrUpgradeRequired) {
+			return response.Send(c, fiber.StatusPaymentRequired, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, task.ErrLimitExceeded) {
+			return response.Send(c, fiber.StatusTooManyRequests, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, tenant.ErrQuotaExceeded) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, task.ErrStorageFull) {
+			return response.Send(c, fiber.StatusInsufficientStorage, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Task created successfully",
+		"data":    newTask,
+	})
+}
+
+// GetWorkspaceAgingReport handles reporting how long the tasks of the workspace resolved by the
+// WorkspaceMember middleware stay open
+func (h *Handler) GetWorkspaceAgingReport(c *fiber.Ctx) error {
+	// Get user and workspace from context
+	userID := c.Locals("user_id").(uuid.UUID)
+	workspaceID := c.Locals("workspace_id").(uuid.UUID)
+
+	report, err := h.taskService.WorkspaceAgingReport(workspaceID, time.Now(), userID)
+	if err != nil {
+		return response.Send(c, fiber.StatusForbidden, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Aging report retrieved successfully",
+		"data":    report,
+	})
+}
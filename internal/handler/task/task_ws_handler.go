@@ -0,0 +1,110 @@
+package task
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// wsPingInterval is how often keep-alive pings are sent to WebSocket clients
+const wsPingInterval = 30 * time.Second
+
+// StreamTasks pushes changes to the authenticated user's tasks over a WebSocket.
+// Messages are task events: {"type": "task.updated", "task_id": "...", "task": {...}}, and
+// presence events of collaborators: {"type": "task.presence", "task_id": "...", "user_id":
+// "...", "state": "editing"}. Clients report their own presence with presence messages:
+// {"type": "presence", "task_id": "...", "state": "viewing"}.
+func (h *Handler) StreamTasks(conn *websocket.Conn) {
+	// Get user ID from context (set by WebSocket auth middleware)
+	userID := conn.Locals("user_id").(uuid.UUID)
+	session := uuid.NewString()
+
+	events, unsubscribe := h.taskService.Subscribe(userID)
+	defer unsubscribe()
+	presence, unsubscribePresence := h.taskService.SubscribePresence(userID)
+	defer unsubscribePresence()
+	defer h.taskService.EndPresence(session)
+
+	// Handle presence messages and detect client disconnects. Replies are written by the
+	// loop below, the only writer of the connection.
+	closed := make(chan struct{})
+	replies := make(chan fiber.Map)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(closed)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if reply := h.handlePresenceMessage(session, data, userID); reply != nil {
+				select {
+				case replies <- reply:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		var msg interface{}
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			msg = event
+		case event, ok := <-presence:
+			if !ok {
+				return
+			}
+			msg = event
+		case reply := <-replies:
+			msg = reply
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+			continue
+		case <-closed:
+			return
+		}
+
+		if err := conn.WriteJSON(msg); err != nil {
+			log.Printf("WebSocket write failed for user %s: %v", userID, err)
+			return
+		}
+	}
+}
+
+// handlePresenceMessage records the presence reported by a WebSocket client, returning an
+// error message for the client if it cannot
+func (h *Handler) handlePresenceMessage(session string, data []byte, userID uuid.UUID) fiber.Map {
+	var msg task.PresenceMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fiber.Map{"type": "error", "message": "Invalid message"}
+	}
+	if err := msg.Validate(); err != nil {
+		return fiber.Map{"type": "error", "message": err.Error()}
+	}
+
+	if err := h.taskService.UpdatePresence(session, msg.TaskID, msg.State, userID); err != nil {
+		message := err.Error()
+		if message == "task not found" {
+			message = "Task not found"
+		}
+		return fiber.Map{"type": "error", "task_id": msg.TaskID, "message": message}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package tenant
+
+import (
+	"strconv"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/domain/tenant"
+	auditHandler "todo-api/internal/handler/audit"
+	"todo-api/internal/response"
+	auditService "todo-api/internal/service/audit"
+	tenantService "todo-api/internal/service/tenant"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles the tenant admin API. Routes are expected to be restricted to
+// platform admins holding the tenants:admin scope.
+type Handler struct {
+	tenantService tenantService.Service
+	auditService  auditService.Service // optional, records tenant changes
+}
+
+// NewHandler creates a new tenant handler instance
+func NewHandler(tenantSvc tenantService.Service) *Handler {
+	return NewHandlerWithAudit(tenantSvc, nil)
+}
+
+// NewHandlerWithAudit creates a new tenant handler instance that records tenant changes in
+// the audit log
+func NewHandlerWithAudit(tenantSvc tenantService.Service, auditSvc auditService.Service) *Handler {
+	return &Handler{
+		tenantService: tenantSvc,
+		auditService:  auditSvc,
+	}
+}
+
+// CreateTenant handles tenant creation
+func (h *Handler) CreateTenant(c *fiber.Ctx) error {
+	var req tenant.CreateTenantRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	newTenant, err := h.tenantService.CreateTenant(&req)
+	if err != nil {
+		if err.Error() == "slug is already taken" {
+			return response.Send(c, fiber.StatusConflict, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	h.record(c, audit.ActionTenantCreated, newTenant.ID, map[string]string{
+		"slug": newTenant.Slug,
+		"name": newTenant.Name,
+	})
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Tenant created successfully",
+		"data":    newTenant,
+	})
+}
+
+// ListTenants handles listing every tenant
+func (h *Handler) ListTenants(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Tenants retrieved successfully",
+		"data":    h.tenantService.ListTenants(),
+	})
+}
+
+// GetTenant handles tenant retrieval
+func (h *Handler) GetTenant(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid tenant ID",
+		})
+	}
+
+	t, err := h.tenantService.GetTenant(tenantID)
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Tenant not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Tenant retrieved successfully",
+		"data":    t,
+	})
+}
+
+// UpdateTenant handles renaming, suspending or reactivating a tenant, or changing its quota
+func (h *Handler) UpdateTenant(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid tenant ID",
+		})
+	}
+
+	var req tenant.UpdateTenantRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	updated, err := h.tenantService.UpdateTenant(tenantID, &req)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Tenant not found",
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	h.record(c, audit.ActionTenantUpdated, updated.ID, updateDetails(&req))
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Tenant updated successfully",
+		"data":    updated,
+	})
+}
+
+// record records an admin action on a tenant in the audit log
+func (h *Handler) record(c *fiber.Ctx, action audit.Action, tenantID uuid.UUID, details map[string]string) {
+	if h.auditService == nil {
+		return
+	}
+
+	var actorID *uuid.UUID
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		actorID = &userID
+	}
+
+	entry := audit.NewEntry(action, actorID)
+	entry.TargetID = &tenantID
+	entry.Details = details
+	auditHandler.Record(h.auditService, c, entry)
+}
+
+// updateDetails describes the fields changed by a tenant update
+func updateDetails(req *tenant.UpdateTenantRequest) map[string]string {
+	details := make(map[string]string)
+	if req.Name != nil {
+		details["name"] = *req.Name
+	}
+	if req.Status != nil {
+		details["status"] = string(*req.Status)
+	}
+	if req.Quota != nil {
+		details["max_tasks"] = strconv.Itoa(req.Quota.MaxTasks)
+		details["max_workspaces"] = strconv.Itoa(req.Quota.MaxWorkspaces)
+	}
+	return details
+}
@@ -0,0 +1,204 @@
+package tenant
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/middleware"
+	auditService "todo-api/internal/service/audit"
+	"todo-api/internal/service/auth"
+	tenantService "todo-api/internal/service/tenant"
+	"todo-api/pkg/config"
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	adminID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440010")
+	aliceID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440004")
+)
+
+// setupTestApp registers the tenant admin routes and a tenant-scoped route. Requests
+// authenticate with the claims of the X-Scopes and X-Tenant-ID headers.
+func setupTestApp(t *testing.T) *fiber.App {
+	return setupTestAppWithAudit(t, nil)
+}
+
+// setupTestAppWithAudit registers the same routes as setupTestApp, recording tenant
+// changes in the audit log
+func setupTestAppWithAudit(t *testing.T, auditSvc auditService.Service) *fiber.App {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	tenantSvc := tenantService.NewService(auth.NewService(cfg))
+	handler := NewHandlerWithAudit(tenantSvc, auditSvc)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		claims := &utils.JWTClaims{UserID: adminID}
+		if scopes := c.Get("X-Scopes"); scopes != "" {
+			claims.Scopes = []string{scopes}
+		}
+		if tenantID := c.Get("X-Tenant-ID"); tenantID != "" {
+			claims.TenantID = uuid.MustParse(tenantID)
+			claims.UserID = aliceID
+		}
+		c.Locals("user_id", claims.UserID)
+		c.Locals("user_claims", claims)
+		return c.Next()
+	})
+
+	admin := app.Group("/admin/tenants", middleware.RequireExplicitScope("tenants:admin"))
+	admin.Get("/", handler.ListTenants)
+	admin.Post("/", handler.CreateTenant)
+	admin.Get("/:id", handler.GetTenant)
+	admin.Put("/:id", handler.UpdateTenant)
+
+	app.Get("/tasks", middleware.Tenant(tenantSvc, "todo.example.com"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	return app
+}
+
+func send(t *testing.T, app *fiber.App, method, path, body string, headers map[string]string) (int, map[string]interface{}) {
+	httpReq := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	if resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	}
+	return resp.StatusCode, response
+}
+
+func TestHandler_AdminScopeRequired(t *testing.T) {
+	app := setupTestApp(t)
+
+	// Tokens without scopes do not imply the admin scope
+	status, response := send(t, app, http.MethodGet, "/admin/tenants", "", nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Insufficient scope: tenants:admin required", response["message"])
+
+	status, _ = send(t, app, http.MethodGet, "/admin/tenants", "", map[string]string{"X-Scopes": "tasks:write"})
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestHandler_ManageTenants(t *testing.T) {
+	app := setupTestApp(t)
+	admin := map[string]string{"X-Scopes": "tenants:admin"}
+
+	status, response := send(t, app, http.MethodPost, "/admin/tenants", `{"slug":"globex","name":"Globex","quota":{"max_tasks":50}}`, admin)
+	require.Equal(t, http.StatusCreated, status)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "globex", data["slug"])
+	assert.Equal(t, "active", data["status"])
+	assert.Equal(t, float64(50), data["quota"].(map[string]interface{})["max_tasks"])
+	tenantID := data["id"].(string)
+
+	status, response = send(t, app, http.MethodPost, "/admin/tenants", `{"slug":"globex","name":"Other"}`, admin)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "slug is already taken", response["message"])
+
+	status, _ = send(t, app, http.MethodPost, "/admin/tenants", `{"slug":"Not a slug!","name":"Other"}`, admin)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, response = send(t, app, http.MethodGet, "/admin/tenants", "", admin)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["data"], 3)
+
+	status, response = send(t, app, http.MethodPut, "/admin/tenants/"+tenantID, `{"status":"suspended"}`, admin)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "suspended", response["data"].(map[string]interface{})["status"])
+
+	status, response = send(t, app, http.MethodGet, "/admin/tenants/"+tenantID, "", admin)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Globex", response["data"].(map[string]interface{})["name"])
+
+	status, _ = send(t, app, http.MethodGet, "/admin/tenants/"+uuid.New().String(), "", admin)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = send(t, app, http.MethodPut, "/admin/tenants/invalid-uuid", `{}`, admin)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestHandler_AuditLog(t *testing.T) {
+	auditSvc := auditService.NewService()
+	app := setupTestAppWithAudit(t, auditSvc)
+	admin := map[string]string{"X-Scopes": "tenants:admin"}
+
+	status, response := send(t, app, http.MethodPost, "/admin/tenants", `{"slug":"globex","name":"Globex"}`, admin)
+	require.Equal(t, http.StatusCreated, status)
+	tenantID := uuid.MustParse(response["data"].(map[string]interface{})["id"].(string))
+
+	status, _ = send(t, app, http.MethodPut, "/admin/tenants/"+tenantID.String(), `{"status":"suspended"}`, admin)
+	require.Equal(t, http.StatusOK, status)
+
+	// Rejected changes are not recorded
+	status, _ = send(t, app, http.MethodPost, "/admin/tenants", `{"slug":"globex","name":"Other"}`, admin)
+	require.Equal(t, http.StatusConflict, status)
+
+	entries, _ := auditSvc.List(nil, 1, 10)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, audit.ActionTenantUpdated, entries[0].Action)
+	assert.Equal(t, adminID, *entries[0].ActorID)
+	assert.Equal(t, tenantID, *entries[0].TargetID)
+	assert.Equal(t, map[string]string{"status": "suspended"}, entries[0].Details)
+
+	assert.Equal(t, audit.ActionTenantCreated, entries[1].Action)
+	assert.Equal(t, "globex", entries[1].Details["slug"])
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	app := setupTestApp(t)
+	acme := map[string]string{"X-Tenant-ID": auth.AcmeTenantID.String()}
+
+	status, _ := send(t, app, http.MethodGet, "/tasks", "", acme)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	// Tokens are only valid on the subdomain of their tenant
+	httpReq := httptest.NewRequest(http.MethodGet, "http://default.todo.example.com/tasks", nil)
+	httpReq.Header.Set("X-Tenant-ID", auth.AcmeTenantID.String())
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	httpReq = httptest.NewRequest(http.MethodGet, "http://acme.todo.example.com/tasks", nil)
+	httpReq.Header.Set("X-Tenant-ID", auth.AcmeTenantID.String())
+	resp, err = app.Test(httpReq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// Suspended tenants are locked out
+	status, _ = send(t, app, http.MethodPut, "/admin/tenants/"+auth.AcmeTenantID.String(), `{"status":"suspended"}`,
+		map[string]string{"X-Scopes": "tenants:admin"})
+	require.Equal(t, http.StatusOK, status)
+
+	status, response := send(t, app, http.MethodGet, "/tasks", "", acme)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Tenant is suspended", response["message"])
+
+	status, _ = send(t, app, http.MethodGet, "/tasks", "", map[string]string{"X-Tenant-ID": uuid.New().String()})
+	assert.Equal(t, http.StatusNotFound, status)
+}
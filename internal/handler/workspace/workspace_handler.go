@@ -0,0 +1,309 @@
+package workspace
+
+import (
+	"errors"
+
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/response"
+	workspaceService "todo-api/internal/service/workspace"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler handles workspace HTTP requests. Routes under /workspaces/:wid expect the
+// workspace_id and workspace_member locals set by the WorkspaceMember middleware.
+type Handler struct {
+	workspaceService workspaceService.Service
+}
+
+// NewHandler creates a new workspace handler instance
+func NewHandler(workspaceSvc workspaceService.Service) *Handler {
+	return &Handler{
+		workspaceService: workspaceSvc,
+	}
+}
+
+// CreateWorkspace handles workspace creation
+func (h *Handler) CreateWorkspace(c *fiber.Ctx) error {
+	var req workspace.CreateWorkspaceRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	// Create workspace
+	newWorkspace, err := h.workspaceService.CreateWorkspace(&req, userID)
+	if err != nil {
+		if errors.Is(err, tenant.ErrQuotaExceeded) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Workspace created successfully",
+		"data":    newWorkspace,
+	})
+}
+
+// ListWorkspaces handles listing the workspaces the user belongs to
+func (h *Handler) ListWorkspaces(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Workspaces retrieved successfully",
+		"data":    nonNil(h.workspaceService.ListWorkspaces(userID)),
+	})
+}
+
+// GetWorkspace handles workspace retrieval
+func (h *Handler) GetWorkspace(c *fiber.Ctx) error {
+	w, err := h.workspaceService.GetWorkspace(workspaceID(c))
+	if err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Workspace not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Workspace retrieved successfully",
+		"data":    w,
+	})
+}
+
+// ListMembers handles listing the members of a workspace
+func (h *Handler) ListMembers(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Members retrieved successfully",
+		"data":    h.workspaceService.ListMembers(workspaceID(c)),
+	})
+}
+
+// RemoveMember handles removing a member from a workspace, or leaving it
+func (h *Handler) RemoveMember(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid user ID",
+		})
+	}
+
+	err = h.workspaceService.RemoveMember(workspaceID(c), userID, member(c))
+	if err != nil {
+		switch err.Error() {
+		case "not a member of this workspace":
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Member not found",
+			})
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Member removed successfully",
+	})
+}
+
+// InviteMember handles inviting a user to a workspace by email
+func (h *Handler) InviteMember(c *fiber.Ctx) error {
+	var req workspace.InviteMemberRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	invitation, err := h.workspaceService.InviteMember(workspaceID(c), &req, member(c))
+	if err != nil {
+		switch err.Error() {
+		case "access denied":
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		case "user is already a member":
+			return response.Send(c, fiber.StatusConflict, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Invitation sent successfully",
+		"data":    invitation,
+	})
+}
+
+// ListInvitations handles listing the pending invitations of a workspace
+func (h *Handler) ListInvitations(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Invitations retrieved successfully",
+		"data":    nonNil(h.workspaceService.ListInvitations(workspaceID(c))),
+	})
+}
+
+// RevokeInvitation handles cancelling a pending invitation
+func (h *Handler) RevokeInvitation(c *fiber.Ctx) error {
+	invitationID, err := uuid.Parse(c.Params("invitationId"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid invitation ID",
+		})
+	}
+
+	if err := h.workspaceService.RevokeInvitation(workspaceID(c), invitationID); err != nil {
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Invitation not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Invitation revoked successfully",
+	})
+}
+
+// ListMyInvitations handles listing the pending invitations sent to the user's email address
+func (h *Handler) ListMyInvitations(c *fiber.Ctx) error {
+	// Get user email from context
+	email := c.Locals("user_email").(string)
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Invitations retrieved successfully",
+		"data":    nonNil(h.workspaceService.ListInvitationsForEmail(email)),
+	})
+}
+
+// AcceptInvitation handles joining a workspace through an invitation
+func (h *Handler) AcceptInvitation(c *fiber.Ctx) error {
+	invitationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid invitation ID",
+		})
+	}
+
+	// Get user information from context
+	userID := c.Locals("user_id").(uuid.UUID)
+	email := c.Locals("user_email").(string)
+
+	newMember, err := h.workspaceService.AcceptInvitation(invitationID, userID, email)
+	if err != nil {
+		if err.Error() == "invitation has expired" {
+			return response.Send(c, fiber.StatusGone, fiber.Map{
+				"error":   true,
+				"message": "Invitation has expired",
+			})
+		}
+		return response.Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Invitation not found",
+		})
+	}
+
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Invitation accepted successfully",
+		"data":    newMember,
+	})
+}
+
+// CreateProject handles project creation in a workspace
+func (h *Handler) CreateProject(c *fiber.Ctx) error {
+	var req workspace.CreateProjectRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	project, err := h.workspaceService.CreateProject(workspaceID(c), &req, member(c).UserID)
+	if err != nil {
+		return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	return response.Send(c, fiber.StatusCreated, fiber.Map{
+		"error":   false,
+		"message": "Project created successfully",
+		"data":    project,
+	})
+}
+
+// ListProjects handles listing the projects of a workspace
+func (h *Handler) ListProjects(c *fiber.Ctx) error {
+	return response.Send(c, fiber.StatusOK, fiber.Map{
+		"error":   false,
+		"message": "Projects retrieved successfully",
+		"data":    nonNil(h.workspaceService.ListProjects(workspaceID(c))),
+	})
+}
+
+// workspaceID returns the workspace resolved by the WorkspaceMember middleware
+func workspaceID(c *fiber.Ctx) uuid.UUID {
+	return c.Locals("workspace_id").(uuid.UUID)
+}
+
+// member returns the caller's membership resolved by the WorkspaceMember middleware
+func member(c *fiber.Ctx) *workspace.Member {
+	return c.Locals("workspace_member").(*workspace.Member)
+}
+
+// nonNil returns an empty slice instead of nil so lists are encoded as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
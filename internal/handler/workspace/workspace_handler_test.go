@@ -0,0 +1,169 @@
+package workspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/middleware"
+	"todo-api/internal/service/auth"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	johnID = "3484ec33-20f9-4993-a25f-f49f6f5dbe54"
+	janeID = "550e8400-e29b-41d4-a716-446655440002"
+	mikeID = "550e8400-e29b-41d4-a716-446655440003"
+)
+
+var emails = map[string]string{
+	johnID: "john.doe@example.com",
+	janeID: "jane.smith@example.com",
+	mikeID: "mike.wilson@example.com",
+}
+
+// setupTestApp registers the workspace routes, authenticating as the user in the X-User-ID header
+func setupTestApp(t *testing.T) *fiber.App {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	workspaceSvc := workspaceService.NewService(auth.NewService(cfg))
+	handler := NewHandler(workspaceSvc)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		userID := c.Get("X-User-ID", johnID)
+		c.Locals("user_id", uuid.MustParse(userID))
+		c.Locals("user_email", emails[userID])
+		return c.Next()
+	})
+
+	app.Get("/workspaces", handler.ListWorkspaces)
+	app.Post("/workspaces", handler.CreateWorkspace)
+
+	scoped := app.Group("/workspaces/:wid", middleware.WorkspaceMember(workspaceSvc))
+	isAdmin := middleware.RequireWorkspaceRole(workspace.RoleAdmin)
+	scoped.Get("/", handler.GetWorkspace)
+	scoped.Get("/members", handler.ListMembers)
+	scoped.Delete("/members/:userId", handler.RemoveMember)
+	scoped.Post("/invitations", isAdmin, handler.InviteMember)
+	scoped.Get("/projects", handler.ListProjects)
+	scoped.Post("/projects", isAdmin, handler.CreateProject)
+
+	app.Get("/invitations", handler.ListMyInvitations)
+	app.Post("/invitations/:id/accept", handler.AcceptInvitation)
+
+	return app
+}
+
+func send(t *testing.T, app *fiber.App, method, path, body, userID string) (int, map[string]interface{}) {
+	httpReq := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		httpReq.Header.Set("X-User-ID", userID)
+	}
+
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return resp.StatusCode, response
+}
+
+func TestHandler_CreateWorkspace(t *testing.T) {
+	app := setupTestApp(t)
+
+	status, response := send(t, app, http.MethodPost, "/workspaces", `{"name":"Platform"}`, "")
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "Workspace created successfully", response["message"])
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "Platform", data["name"])
+	assert.Equal(t, johnID, data["owner_id"])
+
+	status, response = send(t, app, http.MethodPost, "/workspaces", `{"name":""}`, "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "name is required", response["message"])
+
+	status, response = send(t, app, http.MethodGet, "/workspaces", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["data"], 1)
+
+	status, response = send(t, app, http.MethodGet, "/workspaces", "", janeID)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{}, response["data"])
+}
+
+func TestHandler_WorkspaceMembership(t *testing.T) {
+	app := setupTestApp(t)
+
+	_, response := send(t, app, http.MethodPost, "/workspaces", `{"name":"Platform"}`, "")
+	workspaceID := response["data"].(map[string]interface{})["id"].(string)
+	base := "/workspaces/" + workspaceID
+
+	// Non-members cannot see the workspace
+	status, response := send(t, app, http.MethodGet, base, "", janeID)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "Workspace not found", response["message"])
+
+	status, _ = send(t, app, http.MethodGet, "/workspaces/invalid-uuid", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// Invite and accept
+	status, response = send(t, app, http.MethodPost, base+"/invitations", `{"email":"jane.smith@example.com"}`, "")
+	require.Equal(t, http.StatusCreated, status)
+	invitationID := response["data"].(map[string]interface{})["id"].(string)
+
+	_, response = send(t, app, http.MethodGet, "/invitations", "", janeID)
+	assert.Len(t, response["data"], 1)
+
+	status, _ = send(t, app, http.MethodPost, "/invitations/"+invitationID+"/accept", "", mikeID)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, response = send(t, app, http.MethodPost, "/invitations/"+invitationID+"/accept", "", janeID)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "member", response["data"].(map[string]interface{})["role"])
+
+	status, response = send(t, app, http.MethodGet, base+"/members", "", janeID)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["data"], 2)
+
+	// Members cannot invite or create projects
+	status, response = send(t, app, http.MethodPost, base+"/invitations", `{"email":"mike.wilson@example.com"}`, janeID)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Workspace admin role required", response["message"])
+
+	status, _ = send(t, app, http.MethodPost, base+"/projects", `{"name":"Launch"}`, janeID)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _ = send(t, app, http.MethodPost, base+"/projects", `{"name":"Launch"}`, "")
+	assert.Equal(t, http.StatusCreated, status)
+
+	_, response = send(t, app, http.MethodGet, base+"/projects", "", janeID)
+	assert.Len(t, response["data"], 1)
+
+	// Members may leave but not remove the owner
+	status, _ = send(t, app, http.MethodDelete, base+"/members/"+johnID, "", janeID)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = send(t, app, http.MethodDelete, base+"/members/"+janeID, "", janeID)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _ = send(t, app, http.MethodGet, base, "", janeID)
+	assert.Equal(t, http.StatusNotFound, status)
+}
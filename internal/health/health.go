@@ -0,0 +1,158 @@
+// Package health checks the dependencies of the server, such as task storage and the job
+// queue, for the health endpoint polled by uptime monitors and load balancers.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is the state of a dependency, or of the server as a whole
+type Status string
+
+const (
+	StatusUp   Status = "up"   // the dependency answered its check
+	StatusDown Status = "down" // the dependency failed its check, or a critical one did
+
+	StatusOK       Status = "ok"       // every dependency is up
+	StatusDegraded Status = "degraded" // a dependency that is not critical is down
+)
+
+// Check returns why a dependency is unavailable, or nil when it is available
+type Check func(ctx context.Context) error
+
+// Dependency reports the state of a dependency at its last check
+type Dependency struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Critical bool   `json:"critical"`
+	// Latency is how long the last check took in milliseconds
+	Latency int64 `json:"latency_ms"`
+	// LastError is why a check last failed, kept once the dependency recovers; LastFailure is
+	// when it failed
+	LastError   string     `json:"last_error,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+}
+
+// Report reports the state of the dependencies at their last check
+type Report struct {
+	Status       Status       `json:"status"`
+	CheckedAt    time.Time    `json:"checked_at"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// dependency is a checked dependency with its state
+type dependency struct {
+	check Check
+	entry Dependency
+}
+
+// Checker checks the dependencies of the server. Dependencies are checked concurrently, each
+// within a timeout, and the results are reused for an interval, so frequent polling does not
+// load them.
+type Checker struct {
+	timeout  time.Duration
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex // serializes checks
+	deps   []*dependency
+	report *Report // last report, nil before the first check
+}
+
+// NewChecker creates a checker running each check within the timeout and reusing the
+// results for the interval; a zero interval checks on every call
+func NewChecker(timeout, interval time.Duration) *Checker {
+	return &Checker{
+		timeout:  timeout,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Add adds a dependency to check. A critical dependency being down makes the server down;
+// others only degrade it. Dependencies are added at startup, and reported in the order they
+// were added.
+func (c *Checker) Add(name string, critical bool, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.deps = append(c.deps, &dependency{
+		check: check,
+		entry: Dependency{Name: name, Status: StatusUp, Critical: critical},
+	})
+	c.report = nil
+}
+
+// Check returns the state of the dependencies, checking them again when the last report is
+// older than the interval. Checks are not cancelled with the context, so a client going
+// away does not fail them.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.report != nil && c.now().Sub(c.report.CheckedAt) < c.interval {
+		return *c.report
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for _, dep := range c.deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.run(ctx, dep)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status:       StatusOK,
+		CheckedAt:    c.now().UTC(),
+		Dependencies: make([]Dependency, len(c.deps)),
+	}
+	for i, dep := range c.deps {
+		report.Dependencies[i] = dep.entry
+		if dep.entry.Status == StatusDown {
+			if dep.entry.Critical {
+				report.Status = StatusDown
+			} else if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+	c.report = report
+	return *report
+}
+
+// run checks the dependency, recording its status and latency. A check still running once
+// the timeout passes fails; it is left to finish in the background.
+func (c *Checker) run(ctx context.Context, dep *dependency) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := c.now()
+	done := make(chan error, 1)
+	go func() {
+		done <- dep.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", c.timeout)
+	}
+
+	end := c.now()
+	dep.entry.Latency = end.Sub(start).Milliseconds()
+	dep.entry.Status = StatusUp
+	if err != nil {
+		failedAt := end.UTC()
+		dep.entry.Status = StatusDown
+		dep.entry.LastError = err.Error()
+		dep.entry.LastFailure = &failedAt
+	}
+}
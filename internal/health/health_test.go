@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker(time.Second, 0)
+	var mailErr atomic.Pointer[error]
+	checker.Add("storage", true, func(ctx context.Context) error { return nil })
+	checker.Add("mailer", false, func(ctx context.Context) error {
+		if err := mailErr.Load(); err != nil {
+			return *err
+		}
+		return nil
+	})
+
+	report := checker.Check(context.Background())
+	assert.Equal(t, StatusOK, report.Status)
+	require.Len(t, report.Dependencies, 2)
+	assert.Equal(t, Dependency{Name: "storage", Status: StatusUp, Critical: true}, report.Dependencies[0])
+	assert.Equal(t, "mailer", report.Dependencies[1].Name)
+
+	// A dependency that is not critical being down degrades the server
+	err := errors.New("connection refused")
+	mailErr.Store(&err)
+	report = checker.Check(context.Background())
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, StatusDown, report.Dependencies[1].Status)
+	assert.Equal(t, "connection refused", report.Dependencies[1].LastError)
+	require.NotNil(t, report.Dependencies[1].LastFailure)
+
+	// The last error is kept once the dependency recovers
+	mailErr.Store(nil)
+	report = checker.Check(context.Background())
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, StatusUp, report.Dependencies[1].Status)
+	assert.Equal(t, "connection refused", report.Dependencies[1].LastError)
+}
+
+func TestChecker_CriticalDown(t *testing.T) {
+	checker := NewChecker(10*time.Millisecond, 0)
+	checker.Add("cache", false, func(ctx context.Context) error { return errors.New("redis: connection refused") })
+	checker.Add("queue", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// Checks still running after the timeout fail
+	report := checker.Check(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "check timed out after 10ms", report.Dependencies[1].LastError)
+	assert.GreaterOrEqual(t, report.Dependencies[1].Latency, int64(10))
+
+	// Checks are not cancelled with the context of the caller
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	checker = NewChecker(time.Second, 0)
+	checker.Add("storage", true, func(ctx context.Context) error { return ctx.Err() })
+	assert.Equal(t, StatusOK, checker.Check(cancelled).Status)
+}
+
+func TestChecker_Interval(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+	checker := NewChecker(time.Second, 5*time.Second)
+	checker.now = func() time.Time { return now }
+	var checks atomic.Int32
+	checker.Add("storage", true, func(ctx context.Context) error {
+		checks.Add(1)
+		return nil
+	})
+
+	first := checker.Check(context.Background())
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, first, checker.Check(context.Background()))
+	assert.Equal(t, int32(1), checks.Load())
+
+	// Results are checked again once they are older than the interval
+	now = now.Add(time.Second)
+	assert.Equal(t, now, checker.Check(context.Background()).CheckedAt)
+	assert.Equal(t, int32(2), checks.Load())
+}
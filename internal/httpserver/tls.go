@@ -0,0 +1,90 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"todo-api/pkg/config"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// NextProtos are the application protocols negotiated with clients. The HTTP server only
+// speaks HTTP/1.1, so h2 is not offered.
+var NextProtos = []string{"http/1.1"}
+
+// NewTLSConfig creates the TLS configuration of the HTTP server. With automatic
+// certificates, the manager is returned so its ACME HTTP-01 challenge handler can be
+// served; otherwise it is nil.
+func NewTLSConfig(cfg config.TLSConfig) (*tls.Config, *autocert.Manager, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: NextProtos,
+	}
+
+	if cfg.ClientAuth() {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load client CA certificates: %w", err)
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("failed to load client CA certificates: no certificate found in %s", cfg.ClientCAFile)
+		}
+
+		// Certificates are verified whenever clients send one. They are only required during
+		// the handshake when every path requires them; otherwise the middleware requires
+		// them on the configured paths.
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if len(cfg.ClientAuthPaths) == 0 {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	if !cfg.Autocert() {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		return tlsConfig, nil, nil
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+		Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+		Email:      cfg.AutocertEmail,
+	}
+	tlsConfig.GetCertificate = manager.GetCertificate
+	// Let's Encrypt may also validate domains with TLS-ALPN-01 challenges on the TLS port
+	tlsConfig.NextProtos = append([]string{acme.ALPNProto}, NextProtos...)
+
+	return tlsConfig, manager, nil
+}
+
+// RedirectHandler redirects plain HTTP requests to HTTPS on the port, keeping the host,
+// path and query. With automatic certificates, ACME HTTP-01 challenges are answered
+// instead of redirected.
+func RedirectHandler(httpsPort string, manager *autocert.Manager) http.Handler {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+
+	if manager != nil {
+		return manager.HTTPHandler(redirect)
+	}
+	return redirect
+}
@@ -0,0 +1,120 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"todo-api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+)
+
+// writeCertificate writes a self-signed certificate and its key to the directory
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestNewTLSConfigWithCertificate(t *testing.T) {
+	certFile, keyFile := writeCertificate(t, t.TempDir())
+
+	tlsConfig, manager, err := NewTLSConfig(config.TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	assert.Nil(t, manager)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.Equal(t, []string{"http/1.1"}, tlsConfig.NextProtos)
+
+	_, _, err = NewTLSConfig(config.TLSConfig{CertFile: certFile, KeyFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "failed to load TLS certificate")
+}
+
+func TestNewTLSConfigWithAutocert(t *testing.T) {
+	tlsConfig, manager, err := NewTLSConfig(config.TLSConfig{
+		AutocertDomains:  []string{"todo.example.com"},
+		AutocertCacheDir: t.TempDir(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, manager)
+	assert.NotNil(t, tlsConfig.GetCertificate)
+	assert.Contains(t, tlsConfig.NextProtos, acme.ALPNProto)
+
+	// Certificates are only requested for the configured domains
+	assert.Error(t, manager.HostPolicy(context.Background(), "other.example.com"))
+	assert.NoError(t, manager.HostPolicy(context.Background(), "todo.example.com"))
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsPort string
+		target    string
+		location  string
+	}{
+		{"default port", "443", "http://todo.example.com/api/v1/tasks?page=2", "https://todo.example.com/api/v1/tasks?page=2"},
+		{"custom port", "8443", "http://todo.example.com:8080/health", "https://todo.example.com:8443/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RedirectHandler(tt.httpsPort, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, nil))
+
+			assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+			assert.Equal(t, tt.location, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestNewTLSConfigWithClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	cfg := config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile}
+
+	// Without paths, every connection must present a certificate
+	tlsConfig, _, err := NewTLSConfig(cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.ClientCAs)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+
+	cfg.ClientAuthPaths = []string{"/admin"}
+	tlsConfig, _, err = NewTLSConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, tlsConfig.ClientAuth)
+
+	cfg.ClientCAFile = keyFile
+	_, _, err = NewTLSConfig(cfg)
+	assert.ErrorContains(t, err, "no certificate found")
+}
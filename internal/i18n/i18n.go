@@ -0,0 +1,173 @@
+// Package i18n translates API messages into the language negotiated from the Accept-Language
+// header. The catalog of each language holds its messages by message ID. Messages are
+// written in English, so a message is matched to its ID by its English text, the values it
+// was formatted with are taken from it, and they are formatted into the translation.
+// Messages without a translation are served in English.
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Languages lists the supported languages, the default first
+var Languages = []language.Tag{language.English, language.Indonesian}
+
+// matcher picks the supported language closest to those accepted by the client
+var matcher = language.NewMatcher(Languages)
+
+// catalogs holds the messages of each language other than English, by message ID
+var catalogs = map[language.Tag]map[string]string{
+	language.Indonesian: indonesian,
+}
+
+// verb matches the verbs of a message
+var verb = regexp.MustCompile(`%[a-z%]`)
+
+// pattern matches the English messages formatted from one message with verbs
+type pattern struct {
+	id     string
+	verbs  []string
+	re     *regexp.Regexp
+	length int // length of the text around the verbs, longer patterns being more specific
+}
+
+var (
+	// ids holds the IDs of the English messages without verbs, by message
+	ids = literalIDs()
+
+	// patterns holds the English messages with verbs, the most specific first
+	patterns = compilePatterns()
+)
+
+// literalIDs maps the English messages without verbs to their IDs
+func literalIDs() map[string]string {
+	ids := make(map[string]string)
+	for id, message := range english {
+		if !verb.MatchString(message) {
+			ids[message] = id
+		}
+	}
+	return ids
+}
+
+// compilePatterns compiles a pattern for each English message with verbs. Values of %d are
+// numbers, of %q quoted strings, and of other verbs any text.
+func compilePatterns() []pattern {
+	var patterns []pattern
+	for id, message := range english {
+		verbs := verb.FindAllString(message, -1)
+		if len(verbs) == 0 {
+			continue
+		}
+
+		p := pattern{id: id}
+		var expr strings.Builder
+		expr.WriteString(`(?s)^`)
+		literals := verb.Split(message, -1)
+		for i, literal := range literals {
+			expr.WriteString(regexp.QuoteMeta(literal))
+			p.length += len(literal)
+			if i == len(verbs) {
+				break
+			}
+			switch verbs[i] {
+			case "%%":
+				expr.WriteString("%")
+				p.length++
+				continue
+			case "%d":
+				expr.WriteString(`(-?\d+)`)
+			case "%q":
+				expr.WriteString(`("(?:[^"\\]|\\.)*")`)
+			default:
+				expr.WriteString(`(.+?)`)
+			}
+			p.verbs = append(p.verbs, verbs[i])
+		}
+		expr.WriteString(`$`)
+		p.re = regexp.MustCompile(expr.String())
+		patterns = append(patterns, p)
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].length != patterns[j].length {
+			return patterns[i].length > patterns[j].length
+		}
+		return patterns[i].id < patterns[j].id
+	})
+	return patterns
+}
+
+// Negotiate returns the supported language best matching an Accept-Language header value,
+// such as "id-ID,id;q=0.9,en;q=0.8". English is returned when the header is empty, invalid,
+// or names no supported language.
+func Negotiate(acceptLanguage string) language.Tag {
+	accepted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(accepted) == 0 {
+		return Languages[0]
+	}
+	_, index, confidence := matcher.Match(accepted...)
+	if confidence == language.No {
+		return Languages[0]
+	}
+	return Languages[index]
+}
+
+// Translate returns the message in the language, or the message itself when the language
+// has no translation of it
+func Translate(lang language.Tag, message string) string {
+	catalog, ok := catalogs[lang]
+	if !ok {
+		return message
+	}
+	return translate(catalog, message)
+}
+
+// translate looks the message up in the catalog, translating the messages wrapped by it
+func translate(catalog map[string]string, message string) string {
+	if id, ok := ids[message]; ok {
+		if translated, ok := catalog[id]; ok {
+			return translated
+		}
+		return message
+	}
+
+	for _, p := range patterns {
+		match := p.re.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		translated, ok := catalog[p.id]
+		if !ok {
+			return message
+		}
+
+		values := match[1:]
+		for i, v := range p.verbs {
+			if v == "%w" || v == "%v" {
+				values[i] = translate(catalog, values[i])
+			}
+		}
+		return format(translated, values)
+	}
+	return message
+}
+
+// format replaces the verbs of a translation with the values, in order
+func format(translated string, values []string) string {
+	next := 0
+	return verb.ReplaceAllStringFunc(translated, func(v string) string {
+		if v == "%%" {
+			return "%"
+		}
+		if next == len(values) {
+			return v
+		}
+		next++
+		return values[next-1]
+	})
+}
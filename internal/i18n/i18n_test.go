@@ -0,0 +1,262 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           language.Tag
+	}{
+		{"", language.English},
+		{"id", language.Indonesian},
+		{"id-ID,id;q=0.9,en;q=0.8", language.Indonesian},
+		{"en-US,en;q=0.9,id;q=0.5", language.English},
+		{"fr-FR,id;q=0.5", language.Indonesian},
+		{"fr", language.English},
+		{"not a language;;", language.English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tt.want, Negotiate(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "judul wajib diisi", Translate(language.Indonesian, "title is required"))
+	assert.Equal(t, "title is required", Translate(language.English, "title is required"))
+	// Messages without a translation are kept in English
+	assert.Equal(t, "Unknown message", Translate(language.Indonesian, "Unknown message"))
+}
+
+func TestTranslate_Formatted(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"tasks can have at most 10 tags", "tugas paling banyak memiliki 10 tag"},
+		{"Insufficient scope: tasks:write required", "Cakupan tidak mencukupi: tasks:write diperlukan"},
+		{`tag "a b" may only contain letters, digits, hyphens, and underscores`, `tag "a b" hanya boleh berisi huruf, angka, tanda hubung, dan garis bawah`},
+		{"invalid status transition from todo to todo", "perubahan status tidak valid dari todo ke todo"},
+		{"upgrade required: the free plan allows at most 1 task", "peningkatan paket diperlukan: paket free paling banyak mengizinkan 1 tugas"},
+		{"upgrade required: the free plan allows at most 50 tasks", "peningkatan paket diperlukan: paket free paling banyak mengizinkan 50 tugas"},
+		{"Invalid import file: import exceeds maximum of 1000 rows", "Berkas impor tidak valid: impor melebihi batas 1000 baris"},
+		{"attachment storage is unavailable: connection refused", "penyimpanan lampiran tidak tersedia: connection refused"},
+		// Formatted messages without a translation are kept in English
+		{"Unknown message: 42", "Unknown message: 42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			assert.Equal(t, tt.want, Translate(language.Indonesian, tt.message))
+			assert.Equal(t, tt.message, Translate(language.English, tt.message))
+		})
+	}
+}
+
+func TestCatalogs(t *testing.T) {
+	messages := make(map[string]string)
+	for id, message := range english {
+		if other, ok := messages[message]; ok {
+			t.Errorf("messages %s and %s are both %q", id, other, message)
+		}
+		messages[message] = id
+	}
+
+	for lang, catalog := range catalogs {
+		for id, message := range english {
+			translated, ok := catalog[id]
+			if !assert.True(t, ok, "%s has no %s translation", id, lang) {
+				continue
+			}
+			assert.Equal(t, verb.FindAllString(message, -1), verb.FindAllString(translated, -1),
+				"%s translation of %s has other verbs", lang, id)
+		}
+		for id := range catalog {
+			assert.Contains(t, english, id, "%s translation of unknown message %s", lang, id)
+		}
+	}
+}
+
+// untranslated lists the error messages of background jobs and integrations that are
+// logged rather than sent in responses
+var untranslated = map[string]bool{
+	"%d burndown days aggregated: %w":                   true,
+	"%d digests sent: %w":                               true,
+	"%d escalations sent: %w":                           true,
+	"%d of %d tasks indexed: %w":                        true,
+	"%d reminders sent: %w":                             true,
+	"%d stats webhook deliveries enqueued: %w":          true,
+	"%d tasks snapshotted: %w":                          true,
+	"%s device %s: %w":                                  true,
+	"checkout session %s has no user":                   true,
+	"event %s: %w":                                      true,
+	"event has no start":                                true,
+	"failed to authorize github: %w":                    true,
+	"failed to authorize google calendar: %w":           true,
+	"failed to delete attachment file %s: %w":           true,
+	"failed to enqueue reminders: %w":                   true,
+	"failed to enqueue the digest of user %s: %w":       true,
+	"failed to get repository: %w":                      true,
+	"failed to schedule the actions after %s: %w":       true,
+	"invalid event start: %w":                           true,
+	"issue #%d: %w":                                     true,
+	"rule %s, task %s: %w":                              true,
+	"search index: %w":                                  true,
+	"slack: %w":                                         true,
+	"task %s: %w":                                       true,
+	"tenant %s is not a tenant of the default fixtures": true,
+	"twilio: %w":                                        true,
+	"unexpected signing method":                         true,
+	"user %s: %w":                                       true,
+	"webhook %s: %w":                                    true,
+}
+
+// errorPackages lists the directories whose errors are sent in responses
+var errorPackages = []string{
+	"internal/domain", "internal/handler", "internal/middleware", "internal/service",
+	"pkg/quickadd", "pkg/types", "pkg/utils",
+}
+
+// TestCatalogs_Coverage checks that the response messages and the errors of the packages
+// sending them are in the catalog
+func TestCatalogs_Coverage(t *testing.T) {
+	catalogued := make(map[string]bool)
+	for _, message := range english {
+		catalogued[normalize(message)] = true
+	}
+
+	root := filepath.Join("..", "..")
+	seen := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		check := func(message string) {
+			seen++
+			if !catalogued[normalize(message)] && !untranslated[message] {
+				t.Errorf("%s: %q is not in the catalog", rel, message)
+			}
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.KeyValueExpr:
+				if key, ok := n.Key.(*ast.BasicLit); ok && key.Value == `"message"` {
+					if message, ok := text(n.Value, true); ok {
+						check(message)
+					}
+				}
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "message" && len(n.Rhs) == len(n.Lhs) {
+						if message, ok := text(n.Rhs[i], true); ok {
+							check(message)
+						}
+					}
+				}
+			case *ast.CallExpr:
+				if len(n.Args) == 0 || !inPackages(rel, errorPackages) {
+					return true
+				}
+				fun, ok := n.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				pkg, ok := fun.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				switch pkg.Name + "." + fun.Sel.Name {
+				case "errors.New":
+					if message, ok := text(n.Args[0], true); ok {
+						check(message)
+					}
+				case "fmt.Errorf":
+					if message, ok := text(n.Args[0], false); ok {
+						check(message)
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Greater(t, seen, len(english)/2, "too few messages found")
+}
+
+// text returns the message an expression is formatted as: string literals as they are and
+// the operands of concatenations other than literals as %s. Literal percent signs are
+// escaped when the text is not a format already.
+func text(e ast.Expr, escape bool) (string, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		if err != nil {
+			return "", false
+		}
+		if escape {
+			s = strings.ReplaceAll(s, "%", "%%")
+		}
+		return s, true
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		left, leftOK := text(e.X, escape)
+		right, rightOK := text(e.Y, escape)
+		if !leftOK && !rightOK {
+			return "", false
+		}
+		if !leftOK {
+			left = "%s"
+		}
+		if !rightOK {
+			right = "%s"
+		}
+		return left + right, true
+	case *ast.ParenExpr:
+		return text(e.X, escape)
+	}
+	return "", false
+}
+
+// normalize formats the wrapped messages of a message as %s, as concatenations are
+func normalize(message string) string {
+	return strings.NewReplacer("%w", "%s", "%v", "%s").Replace(message)
+}
+
+// inPackages reports whether a path is in one of the directories
+func inPackages(path string, dirs []string) bool {
+	path = filepath.ToSlash(path)
+	for _, dir := range dirs {
+		if strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
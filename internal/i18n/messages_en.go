@@ -0,0 +1,537 @@
+package i18n
+
+// english holds the messages in English by message ID. Verbs stand for the values a message
+// is formatted with: %s, %d, and %q for values kept as they are, %w and %v for wrapped
+// messages, which are translated themselves.
+var english = map[string]string{
+	// Requests
+	"request.invalid_body":         "Invalid request body",
+	"request.timed_out":            "Request timed out",
+	"request.too_many":             "Too many requests, try again later",
+	"request.websocket_required":   "WebSocket upgrade required",
+	"request.invalid_message":      "Invalid message",
+	"request.unknown_message_type": "unknown message type",
+	"request.invalid_header":       "invalid %s header: %s",
+	"request.invalid_timestamp":    "invalid %s: expected RFC 3339 timestamp or YYYY-MM-DD date",
+	"request.invalid_field":        "invalid field: %s",
+	"request.fields_of_objects":    "fields can only be selected from objects",
+	"request.encode_failed":        "Failed to encode response",
+	"route.not_found":              "Route not found",
+
+	// Health
+	"health.running":         "Todo API is running",
+	"health.dependency_down": "A critical dependency of the Todo API is down",
+
+	// Authentication
+	"auth.header_required":          "Authorization header is required",
+	"auth.header_required_lower":    "authorization header is required",
+	"auth.header_bearer":            "authorization header must start with 'Bearer '",
+	"auth.required":                 "authentication required",
+	"auth.invalid_token":            "Invalid or expired token",
+	"auth.invalid_token_lower":      "invalid or expired token",
+	"auth.token_invalid":            "invalid token",
+	"auth.token_required":           "token is required",
+	"auth.invalid_api_key":          "Invalid API key",
+	"auth.invalid_api_key_lower":    "invalid api key",
+	"auth.insufficient_scope":       "Insufficient scope: %s required",
+	"auth.insufficient_scope_lower": "insufficient scope: %s required",
+	"auth.invalid_scope":            "invalid scope: %s",
+	"auth.scope_not_permitted":      "scope not permitted: %s",
+	"auth.scope_exceeds_granted":    "requested scope exceeds granted scopes: %s",
+	"auth.ip_denied":                "Access denied for this IP address",
+	"auth.client_cert_required":     "Client certificate is required",
+	"auth.client_cert_not_allowed":  "Client certificate is not allowed",
+	"auth.access_denied":            "access denied",
+
+	// Login and accounts
+	"auth.login_successful":                "Login successful",
+	"auth.invalid_credentials":             "invalid email or password",
+	"auth.too_many_logins":                 "Too many login attempts, try again later",
+	"auth.account_deactivated":             "account is deactivated",
+	"auth.directory_unavailable":           "directory is unavailable, try again later",
+	"auth.password_managed":                "password is managed by the directory",
+	"auth.email_required":                  "email is required",
+	"auth.email_invalid":                   "invalid email format",
+	"auth.email_taken":                     "email is already in use",
+	"auth.password_required":               "password is required",
+	"auth.password_too_short":              "password must be at least 8 characters long",
+	"auth.client_id_too_long":              "client_id must be at most 128 characters",
+	"auth.user_not_found":                  "User not found",
+	"auth.user_not_found_lower":            "user not found",
+	"auth.user_id_required":                "user_id is required",
+	"auth.invalid_user_id":                 "Invalid user ID",
+	"auth.invalid_user_id_param":           "invalid user_id: %s",
+	"auth.token_generation_failed":         "failed to generate token",
+	"auth.access_token_generation_failed":  "failed to generate access token",
+	"auth.refresh_token_generation_failed": "failed to generate refresh token",
+
+	// Tokens and sessions
+	"auth.token_refreshed":            "Token refreshed successfully",
+	"auth.refresh_token_required":     "refresh token is required",
+	"auth.invalid_refresh_token":      "invalid or expired refresh token",
+	"auth.refresh_token_other_client": "refresh token was issued to another client",
+	"auth.sessions_retrieved":         "Sessions retrieved successfully",
+	"auth.session_revoked":            "Session revoked successfully",
+	"auth.session_not_found":          "Session not found",
+	"auth.session_not_found_lower":    "session not found",
+	"auth.session_ended":              "session has ended",
+	"auth.invalid_session_id":         "Invalid session ID",
+
+	// Email verification and password resets
+	"auth.email_not_configured":      "Email is not configured",
+	"auth.verification_email_sent":   "Verification email sent",
+	"auth.verification_email_failed": "Failed to send verification email",
+	"auth.email_verified":            "Email verified successfully",
+	"auth.email_already_verified":    "email already verified",
+	"auth.password_reset_sent":       "If the account exists, a password reset email has been sent",
+	"auth.password_reset":            "Password reset successfully",
+
+	// API keys
+	"apikey.created":           "API key created successfully, store it now as it is not shown again",
+	"apikey.revoked":           "API key revoked successfully",
+	"apikey.list_retrieved":    "API keys retrieved successfully",
+	"apikey.not_found":         "API key not found",
+	"apikey.not_found_lower":   "api key not found",
+	"apikey.invalid_id":        "Invalid API key ID",
+	"apikey.limit_reached":     "api key limit reached",
+	"apikey.generation_failed": "failed to generate api key",
+
+	// Tenants
+	"tenant.created":                     "Tenant created successfully",
+	"tenant.retrieved":                   "Tenant retrieved successfully",
+	"tenant.list_retrieved":              "Tenants retrieved successfully",
+	"tenant.updated":                     "Tenant updated successfully",
+	"tenant.not_found":                   "Tenant not found",
+	"tenant.not_found_lower":             "tenant not found",
+	"tenant.invalid_id":                  "Invalid tenant ID",
+	"tenant.suspended":                   "Tenant is suspended",
+	"tenant.token_mismatch":              "Token is not valid for this tenant",
+	"tenant.default_cannot_be_suspended": "the default tenant cannot be suspended",
+	"tenant.slug_required":               "slug is required",
+	"tenant.slug_invalid":                "slug must contain only lowercase letters, digits and hyphens",
+	"tenant.slug_taken":                  "slug is already taken",
+	"tenant.quota_negative":              "quota limits cannot be negative",
+	"tenant.quota_exceeded":              "tenant quota exceeded",
+	"tenant.max_tasks":                   "%w: at most %d tasks allowed",
+
+	// Workspaces, members, and projects
+	"workspace.created":                    "Workspace created successfully",
+	"workspace.retrieved":                  "Workspace retrieved successfully",
+	"workspace.list_retrieved":             "Workspaces retrieved successfully",
+	"workspace.not_found":                  "Workspace not found",
+	"workspace.not_found_lower":            "workspace not found",
+	"workspace.invalid_id":                 "Invalid workspace ID",
+	"workspace.role_required":              "Workspace %s role required",
+	"workspace.invalid_role":               "invalid role",
+	"workspace.not_member":                 "not a member of this workspace",
+	"workspace.max_workspaces":             "%w: at most %d workspaces allowed",
+	"workspace.owns_shared_workspaces":     "account owns workspaces with other members",
+	"workspace.members_retrieved":          "Members retrieved successfully",
+	"workspace.member_removed":             "Member removed successfully",
+	"workspace.member_not_found":           "Member not found",
+	"workspace.already_member":             "user is already a member",
+	"workspace.owner_cannot_be_removed":    "the workspace owner cannot be removed",
+	"workspace.invalid_email":              "invalid email",
+	"workspace.invitation_sent":            "Invitation sent successfully",
+	"workspace.invitation_accepted":        "Invitation accepted successfully",
+	"workspace.invitation_revoked":         "Invitation revoked successfully",
+	"workspace.invitations_retrieved":      "Invitations retrieved successfully",
+	"workspace.invitation_not_found":       "Invitation not found",
+	"workspace.invitation_not_found_lower": "invitation not found",
+	"workspace.invitation_expired":         "Invitation has expired",
+	"workspace.invitation_expired_lower":   "invitation has expired",
+	"workspace.invalid_invitation_id":      "Invalid invitation ID",
+	"workspace.name_required":              "name is required",
+	"workspace.name_too_long":              "name must be at most 100 characters",
+	"project.created":                      "Project created successfully",
+	"project.list_retrieved":               "Projects retrieved successfully",
+	"project.board_retrieved":              "Project board retrieved successfully",
+	"project.not_found":                    "Project not found",
+	"project.not_found_lower":              "project not found",
+	"project.not_found_in_workspace":       "project not found in workspace",
+	"project.invalid_id":                   "Invalid project ID",
+	"project.invalid_id_param":             "invalid project_id: %s",
+	"project.only_workspace_tasks":         "project_id is only allowed for workspace tasks",
+
+	// Tasks
+	"task.created":                   "Task created successfully",
+	"task.retrieved":                 "Task retrieved successfully",
+	"task.list_retrieved":            "Tasks retrieved successfully",
+	"task.updated":                   "Task updated successfully",
+	"task.deleted":                   "Task deleted successfully",
+	"task.moved":                     "Task moved successfully",
+	"task.history_retrieved":         "Task history retrieved successfully",
+	"task.stats_retrieved":           "Task statistics retrieved successfully",
+	"task.stats_failed":              "Failed to compute task statistics",
+	"task.aging_retrieved":           "Aging report retrieved successfully",
+	"task.list_failed":               "Failed to retrieve tasks",
+	"task.list_failed_automation":    "Failed to list tasks",
+	"task.not_found":                 "Task not found",
+	"task.not_found_lower":           "task not found",
+	"task.invalid_id":                "Invalid task ID",
+	"task.invalid_id_lower":          "invalid task ID",
+	"task.task_id_required":          "task_id is required",
+	"task.or_project_required":       "task or project is required",
+	"task.duplicate":                 "A similar open task already exists, send force=true to create it anyway",
+	"task.unsupported_render_format": "Unsupported render format: %s",
+
+	// Task validation
+	"task.title_required":            "title is required",
+	"task.title_empty":               "title cannot be empty",
+	"task.title_too_long":            "title must be at most 200 characters",
+	"task.description_too_long":      "description must be at most 5000 characters",
+	"task.invalid_status":            "invalid status",
+	"task.invalid_status_value":      "invalid status: %s",
+	"task.invalid_status_transition": "invalid status transition",
+	"task.status_transition":         "%w from %s to %s",
+	"task.already_completed":         "%w: task is already completed",
+	"task.only_closed_reopened":      "%w: only completed or cancelled tasks can be reopened",
+	"task.invalid_priority":          "invalid priority",
+	"task.invalid_priority_value":    "invalid priority: %s",
+	"task.tags_empty":                "tags cannot be empty",
+	"task.tags_too_many":             "tasks can have at most %d tags",
+	"task.tag_too_long":              "tags must be at most %d characters",
+	"task.tag_invalid":               "tag %q may only contain letters, digits, hyphens, and underscores",
+	"task.due_both_set":              "due and due_date cannot both be set",
+	"task.due_not_supported":         "due is not supported here, set due_date instead",
+	"task.due_unrecognized":          "unrecognized due date",
+	"task.due_unrecognized_value":    "%w: %q",
+	"task.invalid_due":               "invalid due value: %s, expected today or this_week",
+	"task.invalid_archived":          "invalid archived value: %s",
+	"task.invalid_assigned_to_me":    "invalid assigned_to_me value: %s",
+	"task.invalid_sort_field":        "invalid sort field: %s",
+	"task.invalid_sort_order":        "invalid sort order: %s",
+	"task.date_range_required":       "date range is required",
+	"task.date_range_too_long":       "date range must not exceed 366 days",
+	"task.from_after_to":             "from must not be after to",
+	"task.query_required":            "query is required",
+	"task.search_completed":          "Search completed successfully",
+	"task.search_failed":             "Failed to search tasks",
+
+	// Task limits and storage
+	"task.limit_exceeded":       "limit exceeded",
+	"task.max_per_user":         "%w: at most %d tasks per user",
+	"task.import_exceeds_limit": "%w: importing %d tasks exceeds the %d remaining of %d tasks per user",
+	"task.storage_full":         "task storage is full",
+	"task.storage_max_tasks":    "%w: at most %d tasks can be stored",
+	"task.storage_max_bytes":    "%w: at most %d bytes of tasks can be stored",
+	"task.storage_tasks_stored": "%w: %d of %d tasks stored",
+	"task.storage_bytes_stored": "%w: %d of %d bytes of tasks stored",
+
+	// Archiving, ordering, and boards
+	"task.already_archived":     "task is already archived",
+	"task.not_archived":         "task is not archived",
+	"task.position_required":    "position is required",
+	"task.position_negative":    "position must not be negative",
+	"task.position_exactly_one": "exactly one of position, before_id, or after_id is required",
+	"task.reference_not_found":  "reference task not found in target column",
+
+	// Checklists
+	"checklist.item_not_found":        "Checklist item not found",
+	"checklist.item_not_found_lower":  "checklist item not found",
+	"checklist.invalid_item_id":       "Invalid checklist item ID",
+	"checklist.too_many_items":        "checklist must have at most 100 items",
+	"checklist.text_required":         "text is required",
+	"checklist.text_too_long":         "text must be at most 200 characters",
+	"task.quick_add_text_too_long":    "text must be at most 500 characters",
+	"checklist.text_or_done_required": "text or done is required",
+
+	// Sharing and presence
+	"task.cannot_share_with_owner":         "cannot share a task with its owner",
+	"task.not_shared_with_user":            "task is not shared with user",
+	"sharelink.created":                    "Share link created successfully",
+	"sharelink.revoked":                    "Share link revoked successfully",
+	"sharelink.list_retrieved":             "Share links retrieved successfully",
+	"sharelink.content_retrieved":          "Shared content retrieved successfully",
+	"sharelink.not_found":                  "share link not found",
+	"sharelink.not_found_or_expired":       "Share link not found or expired",
+	"sharelink.not_found_or_expired_lower": "share link not found or expired",
+	"sharelink.invalid_id":                 "Invalid share link ID",
+	"sharelink.expiry_range":               "expires_in_days must be between 1 and 90",
+	"sharelink.too_many":                   "too many active share links, revoke one first",
+	"presence.retrieved":                   "Task presence retrieved successfully",
+	"presence.invalid_state":               "state must be viewing, editing, or left",
+
+	// Sync and conflicts
+	"sync.changes_retrieved":         "Changes retrieved successfully",
+	"sync.invalid_cursor":            "invalid sync cursor",
+	"sync.cursor_expired":            "sync cursor expired, sync all tasks again",
+	"conflict.detected":              "Task was changed since base_version, merge the changes and send them to resolve",
+	"conflict.version_changed":       "task was changed since the version the edit is based on",
+	"conflict.resolved":              "Conflict resolved successfully",
+	"conflict.base_version_required": "base_version is required",
+	"conflict.base_version_positive": "base_version must be positive",
+
+	// Imports and exports
+	"import.started":              "Import started",
+	"import.succeeded":            "Tasks imported successfully",
+	"import.partially_failed":     "Tasks imported with errors",
+	"import.unavailable":          "Imports are unavailable, try again later",
+	"import.file_required":        "File is required",
+	"import.file_empty":           "file is empty",
+	"import.invalid_file":         "Invalid import file",
+	"import.invalid_file_reason":  "Invalid import file: %w",
+	"import.unsupported_format":   "Unsupported import format, expected csv or json",
+	"import.expected_array":       "expected a JSON array of tasks",
+	"import.missing_title_column": "missing title column",
+	"import.no_rows":              "import contains no rows",
+	"import.too_many_rows":        "import exceeds maximum of %d rows",
+	"export.unsupported_format":   "Unsupported export format: %s",
+
+	// Attachments
+	"attachment.created":                     "Attachment created, upload the file to complete it",
+	"attachment.uploaded":                    "Attachment uploaded successfully",
+	"attachment.deleted":                     "Attachment deleted successfully",
+	"attachment.list_retrieved":              "Attachments retrieved successfully",
+	"attachment.download_url_created":        "Download URL created successfully",
+	"attachment.not_configured":              "Attachments are not configured",
+	"attachment.not_found":                   "attachment not found",
+	"attachment.invalid_id":                  "Invalid attachment ID",
+	"attachment.quarantined":                 "File was flagged by the malware scanner and quarantined",
+	"attachment.scanning_unavailable":        "attachment scanning is unavailable",
+	"attachment.storage_unavailable":         "attachment storage is unavailable",
+	"attachment.file_too_large":              "file is too large",
+	"attachment.max_size":                    "%w: attachments must be at most %d bytes",
+	"attachment.not_uploaded":                "file was not uploaded",
+	"attachment.filename_required":           "filename is required",
+	"attachment.filename_too_long":           "filename must be at most %d bytes",
+	"attachment.filename_control_characters": "filename must not contain control characters",
+	"attachment.invalid_content_type":        "content_type is not a valid media type",
+	"attachment.size_positive":               "size must be positive",
+	"attachment.too_many":                    "tasks can have at most %d attachments",
+
+	// Automation rules
+	"automation.rule_created":               "Automation rule created successfully",
+	"automation.rule_retrieved":             "Automation rule retrieved successfully",
+	"automation.rules_retrieved":            "Automation rules retrieved successfully",
+	"automation.rule_updated":               "Automation rule updated successfully",
+	"automation.rule_deleted":               "Automation rule deleted successfully",
+	"automation.executions_retrieved":       "Automation rule executions retrieved successfully",
+	"automation.rule_not_found":             "automation rule not found",
+	"automation.invalid_rule_id":            "Invalid rule ID",
+	"automation.max_rules":                  "a user has at most %d automation rules",
+	"automation.action_required":            "at least one action is required",
+	"automation.max_actions":                "rules have at most %d actions",
+	"automation.max_conditions":             "rules have at most %d conditions",
+	"automation.invalid_action":             "invalid action: %s",
+	"automation.invalid_after":              "invalid after: %s",
+	"automation.after_range":                "after must be between 1m and 2160h",
+	"automation.archive_no_value":           "archive takes no value",
+	"automation.condition_value_required":   "condition value is required",
+	"automation.invalid_condition_field":    "invalid condition field: %s",
+	"automation.invalid_operator":           "invalid operator %q for %s",
+	"automation.invalid_trigger_event":      "invalid trigger event: %s",
+	"automation.invalid_trigger_field":      "invalid trigger field: %s",
+	"automation.trigger_field_updated_only": "trigger field is only allowed for task.updated",
+	"automation.delayed_unavailable":        "delayed actions are unavailable",
+	"automation.conditions_changed":         "the conditions no longer hold",
+	"automation.loop":                       "too many automation runs on the task, rules may be undoing each other",
+
+	// Escalation rules
+	"escalation.rule_created":       "Escalation rule created successfully",
+	"escalation.rule_retrieved":     "Escalation rule retrieved successfully",
+	"escalation.rules_retrieved":    "Escalation rules retrieved successfully",
+	"escalation.rule_updated":       "Escalation rule updated successfully",
+	"escalation.rule_deleted":       "Escalation rule deleted successfully",
+	"escalation.rule_not_found":     "escalation rule not found",
+	"escalation.max_rules":          "a workspace has at most %d escalation rules",
+	"escalation.overdue_by_range":   "overdue_by must be between 0s and 2160h",
+	"escalation.invalid_overdue_by": "invalid overdue_by: %s",
+	"escalation.recipient_required": "notify requires at least one recipient",
+	"escalation.invalid_recipient":  "invalid recipient: %s",
+
+	// Reports and stats webhooks
+	"report.burndown_retrieved":     "Burndown retrieved successfully",
+	"report.webhook_created":        "Stats webhook created successfully",
+	"report.webhook_retrieved":      "Stats webhook retrieved successfully",
+	"report.webhooks_retrieved":     "Stats webhooks retrieved successfully",
+	"report.webhook_updated":        "Stats webhook updated successfully",
+	"report.webhook_deleted":        "Stats webhook deleted successfully",
+	"report.webhook_secret_rotated": "Stats webhook secret rotated successfully",
+	"report.webhook_not_found":      "stats webhook not found",
+	"report.invalid_webhook_id":     "Invalid webhook ID",
+	"report.max_webhooks":           "a user has at most %d stats webhooks",
+	"report.webhook_admins_only":    "only workspace admins can add stats webhooks of the workspace",
+	"report.webhook_admin_removed":  "the user is no longer an admin of the workspace",
+	"report.webhooks_need_jobs":     "stats webhooks are not delivered without a job queue",
+	"report.webhook_secret_failed":  "failed to generate webhook secret",
+	"report.frequency_invalid":      "frequency must be daily or weekly",
+	"report.url_required":           "url is required",
+	"report.url_https":              "url must be an https URL",
+	"report.url_too_long":           "url must be at most 2048 characters",
+	"report.url_private":            "url must not point to a local or private address",
+
+	// Notifications and devices
+	"notification.preferences_retrieved": "Notification preferences retrieved successfully",
+	"notification.preferences_updated":   "Notification preferences updated successfully",
+	"notification.settings_retrieved":    "Notification settings retrieved successfully",
+	"notification.settings_updated":      "Notification settings updated successfully",
+	"notification.digest_retrieved":      "Digest retrieved successfully",
+	"notification.not_sent_through":      "%s notifications are not sent through %s",
+	"notification.event_required":        "at least one event is required",
+	"notification.unknown_event":         "unknown event: %s",
+	"notification.time_zone_empty":       "time_zone cannot be empty",
+	"notification.invalid_time_zone":     "invalid time_zone: %s",
+	"device.registered":                  "Device registered successfully",
+	"device.updated":                     "Device updated successfully",
+	"device.removed":                     "Device removed successfully",
+	"device.list_retrieved":              "Devices retrieved successfully",
+	"device.not_found":                   "Device not found",
+	"device.not_found_lower":             "device not found",
+	"device.invalid_id":                  "Invalid device ID",
+	"device.invalid_platform":            "platform must be one of: %s",
+	"device.token_too_long":              "token is too long",
+	"device.invalid_apns_token":          "token must be a hex encoded APNs device token",
+
+	// Account data
+	"account.usage_retrieved":                   "Usage retrieved successfully",
+	"account.data_exported":                     "Data exported successfully",
+	"account.export_started":                    "Export started",
+	"account.export_retrieved":                  "Export retrieved successfully",
+	"account.export_not_found":                  "Export not found",
+	"account.export_not_found_lower":            "data export not found",
+	"account.invalid_export_id":                 "Invalid export ID",
+	"account.export_archives_unavailable":       "Data export archives are unavailable, use GET /me/export instead",
+	"account.export_archives_unavailable_lower": "data export archives are unavailable",
+	"account.deleted":                           "Account deleted successfully",
+	"account.delete_failed":                     "Failed to delete account",
+
+	// Billing
+	"billing.checkout_created":        "Checkout session created successfully",
+	"billing.subscription_retrieved":  "Subscription retrieved successfully",
+	"billing.not_enabled":             "billing is not enabled",
+	"billing.already_pro":             "already subscribed to the pro plan",
+	"billing.provider_unavailable":    "payment provider is unavailable, try again later",
+	"billing.upgrade_required":        "upgrade required",
+	"billing.plan_limit":              "%w: the %s plan allows at most %d %s",
+	"billing.plan_limit_tasks":        "%w: the %s plan allows at most %d tasks",
+	"billing.plan_limit_task":         "%w: the %s plan allows at most %d task",
+	"billing.plan_limit_attachments":  "%w: the %s plan allows at most %d attachments",
+	"billing.plan_limit_attachment":   "%w: the %s plan allows at most %d attachment",
+	"billing.plan_limit_integrations": "%w: the %s plan allows at most %d integrations",
+	"billing.plan_limit_integration":  "%w: the %s plan allows at most %d integration",
+
+	// Jobs
+	"job.retrieved":           "Job retrieved successfully",
+	"job.retried":             "Job retried successfully",
+	"job.discarded":           "Job discarded successfully",
+	"job.stats_retrieved":     "Job stats retrieved successfully",
+	"job.dead_retrieved":      "Dead jobs retrieved successfully",
+	"job.scheduled_retrieved": "Scheduled jobs retrieved successfully",
+	"job.not_found":           "Job not found",
+	"job.invalid_id":          "Invalid job ID",
+
+	// Audit log
+	"audit.entries_retrieved": "Audit entries retrieved successfully",
+	"audit.from_before_to":    "from must be before to",
+
+	// Integrations
+	"integration.invalid_event":            "Invalid event",
+	"integration.invalid_event_value":      "invalid event: %s",
+	"integration.invalid_update":           "Invalid update",
+	"integration.invalid_signature":        "Invalid request signature",
+	"integration.invalid_secret_token":     "Invalid secret token",
+	"integration.invalid_state":            "invalid or expired state",
+	"integration.state_generation_failed":  "failed to generate state",
+	"slack.connected":                      "Slack connected successfully",
+	"slack.disconnected":                   "Slack disconnected successfully",
+	"slack.connection_retrieved":           "Slack connection retrieved successfully",
+	"slack.not_connected":                  "Slack is not connected",
+	"slack.not_connected_lower":            "slack is not connected",
+	"slack.commands_not_configured":        "Slack commands are not configured",
+	"slack.connected_to_other_user":        "slack account is connected to another user",
+	"slack.bot_token_invalid":              "bot_token must be a Slack bot token",
+	"slack.channel_required":               "channel is required with a bot token",
+	"slack.team_and_user_together":         "team_id and slack_user_id must be set together",
+	"slack.webhook_or_bot_token":           "webhook_url or bot_token is required",
+	"slack.webhook_and_bot_token":          "webhook_url and bot_token are mutually exclusive",
+	"slack.webhook_url_invalid":            "webhook_url must be a Slack incoming webhook URL",
+	"github.authorization_started":         "GitHub authorization started successfully",
+	"github.authorization_failed":          "Failed to start GitHub authorization",
+	"github.authorization_denied":          "GitHub authorization was denied",
+	"github.connected":                     "GitHub connected successfully",
+	"github.disconnected":                  "GitHub disconnected successfully",
+	"github.account_retrieved":             "GitHub account retrieved successfully",
+	"github.link_retrieved":                "GitHub link retrieved successfully",
+	"github.project_linked":                "Project linked to GitHub successfully",
+	"github.project_unlinked":              "Project unlinked from GitHub successfully",
+	"github.project_not_linked":            "Project is not linked to GitHub",
+	"github.project_not_linked_lower":      "project is not linked to github",
+	"github.not_configured":                "GitHub is not configured",
+	"github.not_configured_lower":          "github is not configured",
+	"github.not_connected":                 "GitHub is not connected",
+	"github.not_connected_lower":           "github is not connected",
+	"github.cannot_push":                   "github account cannot push to the repository",
+	"github.repository_not_found":          "repository not found",
+	"github.repository_format":             "repository must be in the owner/name format",
+	"gcal.authorization_started":           "Google Calendar authorization started successfully",
+	"gcal.authorization_failed":            "Failed to start Google Calendar authorization",
+	"gcal.authorization_denied":            "Google Calendar authorization was denied",
+	"gcal.connected":                       "Google Calendar connected successfully",
+	"gcal.disconnected":                    "Google Calendar disconnected successfully",
+	"gcal.connection_retrieved":            "Google Calendar connection retrieved successfully",
+	"gcal.not_configured":                  "Google Calendar is not configured",
+	"gcal.not_configured_lower":            "google calendar is not configured",
+	"gcal.not_connected":                   "Google Calendar is not connected",
+	"gcal.not_connected_lower":             "google calendar is not connected",
+	"telegram.link_code_created":           "Telegram link code created successfully",
+	"telegram.link_code_failed":            "Failed to create link code",
+	"telegram.link_code_generation_failed": "failed to generate link code",
+	"telegram.link_retrieved":              "Telegram link retrieved successfully",
+	"telegram.unlinked":                    "Telegram unlinked successfully",
+	"telegram.not_configured":              "Telegram is not configured",
+	"telegram.not_linked":                  "Telegram is not linked",
+	"telegram.not_linked_lower":            "telegram is not linked",
+	"sms.settings_retrieved":               "SMS settings retrieved successfully",
+	"sms.settings_updated":                 "SMS settings updated successfully",
+	"sms.removed":                          "SMS removed successfully",
+	"sms.messages_retrieved":               "SMS messages retrieved successfully",
+	"sms.code_sent":                        "Verification code sent successfully",
+	"sms.phone_verified":                   "Phone number verified successfully",
+	"sms.not_configured":                   "SMS is not configured",
+	"sms.not_set_up":                       "SMS is not set up",
+	"sms.not_set_up_lower":                 "sms is not set up",
+	"sms.code_sent_recently":               "verification code was sent recently",
+	"sms.invalid_code":                     "invalid or expired verification code",
+	"sms.code_generation_failed":           "failed to generate verification code",
+	"sms.phone_unreachable":                "phone number cannot receive sms",
+	"sms.phone_taken":                      "phone number is verified by another user",
+	"sms.phone_format":                     "phone_number must be in E.164 format, e.g. +14155550100",
+	"sms.quiet_hours_start":                "quiet_hours.start must be a time of day, e.g. 22:00",
+	"sms.quiet_hours_end":                  "quiet_hours.end must be a time of day, e.g. 07:00",
+	"sms.quiet_hours_differ":               "quiet_hours.start and quiet_hours.end must differ",
+	"sms.quiet_hours_time_zone":            "quiet_hours.time_zone must be an IANA time zone, e.g. Europe/Berlin",
+	"emailin.created":                      "Email-in address created successfully",
+	"emailin.retrieved":                    "Email-in address retrieved successfully",
+	"emailin.deleted":                      "Email-in address deleted successfully",
+	"emailin.create_failed":                "Failed to create email-in address",
+	"emailin.generation_failed":            "failed to generate email-in address",
+	"emailin.not_found":                    "Email-in address not found",
+	"emailin.not_found_lower":              "email-in address not found",
+	"emailin.not_configured":               "Email-in is not configured",
+	"emailin.rejected":                     "email rejected",
+	"emailin.no_recipient":                 "%w: no recipient is an email-in address",
+	"emailin.spam":                         "%w: the sender failed SPF and DKIM or the email is spam",
+	"emailin.unknown_sender":               "%w: the sender is not the email address of the user",
+
+	// SCIM provisioning
+	"scim.invalid_filter":        "invalid filter",
+	"scim.invalid_path":          "invalid path",
+	"scim.invalid_value":         "invalid value",
+	"scim.username_taken":        "userName is already in use",
+	"scim.username_required":     "%w: userName is required",
+	"scim.username_email":        "%w: userName must be an email address",
+	"scim.operations_required":   "%w: Operations are required",
+	"scim.active_boolean":        "%w: active must be a boolean",
+	"scim.expected_string":       "%w: expected a string",
+	"scim.invalid_string":        "%w: invalid string %s",
+	"scim.name_object":           "%w: name must be an object",
+	"scim.only_eq":               "%w: only eq comparisons such as userName eq \"jane@example.com\" are supported",
+	"scim.unsupported_attribute": "%w: unsupported attribute %s",
+	"scim.unsupported_op":        "%w: unsupported op %q",
+	"scim.cannot_remove":         "%w: %s cannot be removed",
+	"scim.value_object":          "%w: value must be an object of attributes without a path",
+
+	// Wrapped errors
+	"error.wrapped": "%w: %v",
+}
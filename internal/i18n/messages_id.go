@@ -0,0 +1,536 @@
+package i18n
+
+// indonesian translates the messages into Indonesian by message ID, with the verbs of the
+// English message in the same order
+var indonesian = map[string]string{
+	// Requests
+	"request.invalid_body":         "Isi permintaan tidak valid",
+	"request.timed_out":            "Waktu permintaan habis",
+	"request.too_many":             "Terlalu banyak permintaan, coba lagi nanti",
+	"request.websocket_required":   "Upgrade WebSocket diperlukan",
+	"request.invalid_message":      "Pesan tidak valid",
+	"request.unknown_message_type": "jenis pesan tidak dikenal",
+	"request.invalid_header":       "header %s tidak valid: %s",
+	"request.invalid_timestamp":    "%s tidak valid: harus berupa stempel waktu RFC 3339 atau tanggal YYYY-MM-DD",
+	"request.invalid_field":        "field tidak valid: %s",
+	"request.fields_of_objects":    "field hanya dapat dipilih dari objek",
+	"request.encode_failed":        "Gagal mengodekan respons",
+	"route.not_found":              "Rute tidak ditemukan",
+
+	// Health
+	"health.running":         "Todo API sedang berjalan",
+	"health.dependency_down": "Dependensi penting Todo API sedang tidak berfungsi",
+
+	// Authentication
+	"auth.header_required":          "Header Authorization wajib diisi",
+	"auth.header_required_lower":    "header Authorization wajib diisi",
+	"auth.header_bearer":            "header Authorization harus diawali dengan 'Bearer '",
+	"auth.required":                 "autentikasi diperlukan",
+	"auth.invalid_token":            "Token tidak valid atau sudah kedaluwarsa",
+	"auth.invalid_token_lower":      "token tidak valid atau sudah kedaluwarsa",
+	"auth.token_invalid":            "token tidak valid",
+	"auth.token_required":           "token wajib diisi",
+	"auth.invalid_api_key":          "Kunci API tidak valid",
+	"auth.invalid_api_key_lower":    "kunci API tidak valid",
+	"auth.insufficient_scope":       "Cakupan tidak mencukupi: %s diperlukan",
+	"auth.insufficient_scope_lower": "cakupan tidak mencukupi: %s diperlukan",
+	"auth.invalid_scope":            "cakupan tidak valid: %s",
+	"auth.scope_not_permitted":      "cakupan tidak diizinkan: %s",
+	"auth.scope_exceeds_granted":    "cakupan yang diminta melebihi cakupan yang diberikan: %s",
+	"auth.ip_denied":                "Akses ditolak untuk alamat IP ini",
+	"auth.client_cert_required":     "Sertifikat klien wajib disertakan",
+	"auth.client_cert_not_allowed":  "Sertifikat klien tidak diizinkan",
+	"auth.access_denied":            "akses ditolak",
+
+	// Login and accounts
+	"auth.login_successful":                "Berhasil masuk",
+	"auth.invalid_credentials":             "email atau kata sandi salah",
+	"auth.too_many_logins":                 "Terlalu banyak percobaan masuk, coba lagi nanti",
+	"auth.account_deactivated":             "akun dinonaktifkan",
+	"auth.directory_unavailable":           "direktori tidak tersedia, coba lagi nanti",
+	"auth.password_managed":                "kata sandi dikelola oleh direktori",
+	"auth.email_required":                  "email wajib diisi",
+	"auth.email_invalid":                   "format email tidak valid",
+	"auth.email_taken":                     "email sudah digunakan",
+	"auth.password_required":               "kata sandi wajib diisi",
+	"auth.password_too_short":              "kata sandi minimal 8 karakter",
+	"auth.client_id_too_long":              "client_id paling banyak 128 karakter",
+	"auth.user_not_found":                  "Pengguna tidak ditemukan",
+	"auth.user_not_found_lower":            "pengguna tidak ditemukan",
+	"auth.user_id_required":                "user_id wajib diisi",
+	"auth.invalid_user_id":                 "ID pengguna tidak valid",
+	"auth.invalid_user_id_param":           "user_id tidak valid: %s",
+	"auth.token_generation_failed":         "gagal membuat token",
+	"auth.access_token_generation_failed":  "gagal membuat token akses",
+	"auth.refresh_token_generation_failed": "gagal membuat refresh token",
+
+	// Tokens and sessions
+	"auth.token_refreshed":            "Token berhasil diperbarui",
+	"auth.refresh_token_required":     "refresh token wajib diisi",
+	"auth.invalid_refresh_token":      "refresh token tidak valid atau sudah kedaluwarsa",
+	"auth.refresh_token_other_client": "refresh token diterbitkan untuk klien lain",
+	"auth.sessions_retrieved":         "Daftar sesi berhasil diambil",
+	"auth.session_revoked":            "Sesi berhasil dicabut",
+	"auth.session_not_found":          "Sesi tidak ditemukan",
+	"auth.session_not_found_lower":    "sesi tidak ditemukan",
+	"auth.session_ended":              "sesi telah berakhir",
+	"auth.invalid_session_id":         "ID sesi tidak valid",
+
+	// Email verification and password resets
+	"auth.email_not_configured":      "Email belum dikonfigurasi",
+	"auth.verification_email_sent":   "Email verifikasi telah dikirim",
+	"auth.verification_email_failed": "Gagal mengirim email verifikasi",
+	"auth.email_verified":            "Email berhasil diverifikasi",
+	"auth.email_already_verified":    "email sudah diverifikasi",
+	"auth.password_reset_sent":       "Jika akun tersebut ada, email pengaturan ulang kata sandi telah dikirim",
+	"auth.password_reset":            "Kata sandi berhasil diatur ulang",
+
+	// API keys
+	"apikey.created":           "Kunci API berhasil dibuat, simpan sekarang karena tidak akan ditampilkan lagi",
+	"apikey.revoked":           "Kunci API berhasil dicabut",
+	"apikey.list_retrieved":    "Daftar kunci API berhasil diambil",
+	"apikey.not_found":         "Kunci API tidak ditemukan",
+	"apikey.not_found_lower":   "kunci API tidak ditemukan",
+	"apikey.invalid_id":        "ID kunci API tidak valid",
+	"apikey.limit_reached":     "batas kunci API telah tercapai",
+	"apikey.generation_failed": "gagal membuat kunci API",
+
+	// Tenants
+	"tenant.created":                     "Tenant berhasil dibuat",
+	"tenant.retrieved":                   "Tenant berhasil diambil",
+	"tenant.list_retrieved":              "Daftar tenant berhasil diambil",
+	"tenant.updated":                     "Tenant berhasil diperbarui",
+	"tenant.not_found":                   "Tenant tidak ditemukan",
+	"tenant.not_found_lower":             "tenant tidak ditemukan",
+	"tenant.invalid_id":                  "ID tenant tidak valid",
+	"tenant.suspended":                   "Tenant sedang ditangguhkan",
+	"tenant.token_mismatch":              "Token tidak berlaku untuk tenant ini",
+	"tenant.default_cannot_be_suspended": "tenant bawaan tidak dapat ditangguhkan",
+	"tenant.slug_required":               "slug wajib diisi",
+	"tenant.slug_invalid":                "slug hanya boleh berisi huruf kecil, angka, dan tanda hubung",
+	"tenant.slug_taken":                  "slug sudah digunakan",
+	"tenant.quota_negative":              "batas kuota tidak boleh negatif",
+	"tenant.quota_exceeded":              "kuota tenant terlampaui",
+	"tenant.max_tasks":                   "%w: paling banyak %d tugas yang diizinkan",
+
+	// Workspaces, members, and projects
+	"workspace.created":                    "Ruang kerja berhasil dibuat",
+	"workspace.retrieved":                  "Ruang kerja berhasil diambil",
+	"workspace.list_retrieved":             "Daftar ruang kerja berhasil diambil",
+	"workspace.not_found":                  "Ruang kerja tidak ditemukan",
+	"workspace.not_found_lower":            "ruang kerja tidak ditemukan",
+	"workspace.invalid_id":                 "ID ruang kerja tidak valid",
+	"workspace.role_required":              "Peran %s di ruang kerja diperlukan",
+	"workspace.invalid_role":               "peran tidak valid",
+	"workspace.not_member":                 "bukan anggota ruang kerja ini",
+	"workspace.max_workspaces":             "%w: paling banyak %d ruang kerja yang diizinkan",
+	"workspace.owns_shared_workspaces":     "akun memiliki ruang kerja dengan anggota lain",
+	"workspace.members_retrieved":          "Daftar anggota berhasil diambil",
+	"workspace.member_removed":             "Anggota berhasil dikeluarkan",
+	"workspace.member_not_found":           "Anggota tidak ditemukan",
+	"workspace.already_member":             "pengguna sudah menjadi anggota",
+	"workspace.owner_cannot_be_removed":    "pemilik ruang kerja tidak dapat dikeluarkan",
+	"workspace.invalid_email":              "email tidak valid",
+	"workspace.invitation_sent":            "Undangan berhasil dikirim",
+	"workspace.invitation_accepted":        "Undangan berhasil diterima",
+	"workspace.invitation_revoked":         "Undangan berhasil dicabut",
+	"workspace.invitations_retrieved":      "Daftar undangan berhasil diambil",
+	"workspace.invitation_not_found":       "Undangan tidak ditemukan",
+	"workspace.invitation_not_found_lower": "undangan tidak ditemukan",
+	"workspace.invitation_expired":         "Undangan sudah kedaluwarsa",
+	"workspace.invitation_expired_lower":   "undangan sudah kedaluwarsa",
+	"workspace.invalid_invitation_id":      "ID undangan tidak valid",
+	"workspace.name_required":              "nama wajib diisi",
+	"workspace.name_too_long":              "nama paling banyak 100 karakter",
+	"project.created":                      "Proyek berhasil dibuat",
+	"project.list_retrieved":               "Daftar proyek berhasil diambil",
+	"project.board_retrieved":              "Papan proyek berhasil diambil",
+	"project.not_found":                    "Proyek tidak ditemukan",
+	"project.not_found_lower":              "proyek tidak ditemukan",
+	"project.not_found_in_workspace":       "proyek tidak ditemukan di ruang kerja",
+	"project.invalid_id":                   "ID proyek tidak valid",
+	"project.invalid_id_param":             "project_id tidak valid: %s",
+	"project.only_workspace_tasks":         "project_id hanya diizinkan untuk tugas ruang kerja",
+
+	// Tasks
+	"task.created":                   "Tugas berhasil dibuat",
+	"task.retrieved":                 "Tugas berhasil diambil",
+	"task.list_retrieved":            "Daftar tugas berhasil diambil",
+	"task.updated":                   "Tugas berhasil diperbarui",
+	"task.deleted":                   "Tugas berhasil dihapus",
+	"task.moved":                     "Tugas berhasil dipindahkan",
+	"task.history_retrieved":         "Riwayat tugas berhasil diambil",
+	"task.stats_retrieved":           "Statistik tugas berhasil diambil",
+	"task.stats_failed":              "Gagal menghitung statistik tugas",
+	"task.aging_retrieved":           "Laporan umur tugas berhasil diambil",
+	"task.list_failed":               "Gagal mengambil daftar tugas",
+	"task.list_failed_automation":    "Gagal menampilkan daftar tugas",
+	"task.not_found":                 "Tugas tidak ditemukan",
+	"task.not_found_lower":           "tugas tidak ditemukan",
+	"task.invalid_id":                "ID tugas tidak valid",
+	"task.invalid_id_lower":          "ID tugas tidak valid",
+	"task.task_id_required":          "task_id wajib diisi",
+	"task.or_project_required":       "tugas atau proyek wajib diisi",
+	"task.duplicate":                 "Tugas terbuka yang mirip sudah ada, kirim force=true untuk tetap membuatnya",
+	"task.unsupported_render_format": "Format tampilan tidak didukung: %s",
+
+	// Task validation
+	"task.title_required":            "judul wajib diisi",
+	"task.title_empty":               "judul tidak boleh kosong",
+	"task.title_too_long":            "judul paling banyak 200 karakter",
+	"task.description_too_long":      "deskripsi paling banyak 5000 karakter",
+	"task.invalid_status":            "status tidak valid",
+	"task.invalid_status_value":      "status tidak valid: %s",
+	"task.invalid_status_transition": "perubahan status tidak valid",
+	"task.status_transition":         "%w dari %s ke %s",
+	"task.already_completed":         "%w: tugas sudah selesai",
+	"task.only_closed_reopened":      "%w: hanya tugas yang selesai atau dibatalkan yang dapat dibuka kembali",
+	"task.invalid_priority":          "prioritas tidak valid",
+	"task.invalid_priority_value":    "prioritas tidak valid: %s",
+	"task.tags_empty":                "tag tidak boleh kosong",
+	"task.tags_too_many":             "tugas paling banyak memiliki %d tag",
+	"task.tag_too_long":              "tag paling banyak %d karakter",
+	"task.tag_invalid":               "tag %q hanya boleh berisi huruf, angka, tanda hubung, dan garis bawah",
+	"task.due_both_set":              "due dan due_date tidak boleh diisi bersamaan",
+	"task.due_not_supported":         "due tidak didukung di sini, isi due_date sebagai gantinya",
+	"task.due_unrecognized":          "tanggal jatuh tempo tidak dikenali",
+	"task.due_unrecognized_value":    "%w: %q",
+	"task.invalid_due":               "nilai due tidak valid: %s, harus today atau this_week",
+	"task.invalid_archived":          "nilai archived tidak valid: %s",
+	"task.invalid_assigned_to_me":    "nilai assigned_to_me tidak valid: %s",
+	"task.invalid_sort_field":        "field pengurutan tidak valid: %s",
+	"task.invalid_sort_order":        "urutan pengurutan tidak valid: %s",
+	"task.date_range_required":       "rentang tanggal wajib diisi",
+	"task.date_range_too_long":       "rentang tanggal tidak boleh lebih dari 366 hari",
+	"task.from_after_to":             "from tidak boleh setelah to",
+	"task.query_required":            "kueri wajib diisi",
+	"task.search_completed":          "Pencarian berhasil diselesaikan",
+	"task.search_failed":             "Gagal mencari tugas",
+
+	// Task limits and storage
+	"task.limit_exceeded":       "batas terlampaui",
+	"task.max_per_user":         "%w: paling banyak %d tugas per pengguna",
+	"task.import_exceeds_limit": "%w: mengimpor %d tugas melebihi sisa %d dari %d tugas per pengguna",
+	"task.storage_full":         "penyimpanan tugas penuh",
+	"task.storage_max_tasks":    "%w: paling banyak %d tugas dapat disimpan",
+	"task.storage_max_bytes":    "%w: paling banyak %d byte tugas dapat disimpan",
+	"task.storage_tasks_stored": "%w: %d dari %d tugas tersimpan",
+	"task.storage_bytes_stored": "%w: %d dari %d byte tugas tersimpan",
+
+	// Archiving, ordering, and boards
+	"task.already_archived":     "tugas sudah diarsipkan",
+	"task.not_archived":         "tugas tidak diarsipkan",
+	"task.position_required":    "posisi wajib diisi",
+	"task.position_negative":    "posisi tidak boleh negatif",
+	"task.position_exactly_one": "tepat satu dari position, before_id, atau after_id wajib diisi",
+	"task.reference_not_found":  "tugas acuan tidak ditemukan di kolom tujuan",
+
+	// Checklists
+	"checklist.item_not_found":        "Item daftar periksa tidak ditemukan",
+	"checklist.item_not_found_lower":  "item daftar periksa tidak ditemukan",
+	"checklist.invalid_item_id":       "ID item daftar periksa tidak valid",
+	"checklist.too_many_items":        "daftar periksa paling banyak berisi 100 item",
+	"checklist.text_required":         "teks wajib diisi",
+	"checklist.text_too_long":         "teks paling banyak 200 karakter",
+	"task.quick_add_text_too_long":    "teks paling banyak 500 karakter",
+	"checklist.text_or_done_required": "text atau done wajib diisi",
+
+	// Sharing and presence
+	"task.cannot_share_with_owner":         "tugas tidak dapat dibagikan dengan pemiliknya",
+	"task.not_shared_with_user":            "tugas tidak dibagikan dengan pengguna",
+	"sharelink.created":                    "Tautan berbagi berhasil dibuat",
+	"sharelink.revoked":                    "Tautan berbagi berhasil dicabut",
+	"sharelink.list_retrieved":             "Daftar tautan berbagi berhasil diambil",
+	"sharelink.content_retrieved":          "Konten yang dibagikan berhasil diambil",
+	"sharelink.not_found":                  "tautan berbagi tidak ditemukan",
+	"sharelink.not_found_or_expired":       "Tautan berbagi tidak ditemukan atau sudah kedaluwarsa",
+	"sharelink.not_found_or_expired_lower": "tautan berbagi tidak ditemukan atau sudah kedaluwarsa",
+	"sharelink.invalid_id":                 "ID tautan berbagi tidak valid",
+	"sharelink.expiry_range":               "expires_in_days harus antara 1 dan 90",
+	"sharelink.too_many":                   "terlalu banyak tautan berbagi aktif, cabut salah satunya terlebih dahulu",
+	"presence.retrieved":                   "Kehadiran di tugas berhasil diambil",
+	"presence.invalid_state":               "state harus viewing, editing, atau left",
+
+	// Sync and conflicts
+	"sync.changes_retrieved":         "Perubahan berhasil diambil",
+	"sync.invalid_cursor":            "kursor sinkronisasi tidak valid",
+	"sync.cursor_expired":            "kursor sinkronisasi kedaluwarsa, sinkronkan ulang semua tugas",
+	"conflict.detected":              "Tugas telah diubah sejak base_version, gabungkan perubahannya lalu kirim ke resolve",
+	"conflict.version_changed":       "tugas telah diubah sejak versi yang menjadi dasar suntingan",
+	"conflict.resolved":              "Konflik berhasil diselesaikan",
+	"conflict.base_version_required": "base_version wajib diisi",
+	"conflict.base_version_positive": "base_version harus bernilai positif",
+
+	// Imports and exports
+	"import.started":              "Impor dimulai",
+	"import.succeeded":            "Tugas berhasil diimpor",
+	"import.partially_failed":     "Tugas diimpor dengan beberapa kesalahan",
+	"import.unavailable":          "Impor tidak tersedia, coba lagi nanti",
+	"import.file_required":        "Berkas wajib diunggah",
+	"import.file_empty":           "berkas kosong",
+	"import.invalid_file":         "Berkas impor tidak valid",
+	"import.invalid_file_reason":  "Berkas impor tidak valid: %w",
+	"import.unsupported_format":   "Format impor tidak didukung, harus csv atau json",
+	"import.expected_array":       "harus berupa array JSON berisi tugas",
+	"import.missing_title_column": "kolom title tidak ada",
+	"import.no_rows":              "impor tidak berisi baris",
+	"import.too_many_rows":        "impor melebihi batas %d baris",
+	"export.unsupported_format":   "Format ekspor tidak didukung: %s",
+
+	// Attachments
+	"attachment.created":                     "Lampiran dibuat, unggah berkasnya untuk menyelesaikan",
+	"attachment.uploaded":                    "Lampiran berhasil diunggah",
+	"attachment.deleted":                     "Lampiran berhasil dihapus",
+	"attachment.list_retrieved":              "Daftar lampiran berhasil diambil",
+	"attachment.download_url_created":        "URL unduhan berhasil dibuat",
+	"attachment.not_configured":              "Lampiran belum dikonfigurasi",
+	"attachment.not_found":                   "lampiran tidak ditemukan",
+	"attachment.invalid_id":                  "ID lampiran tidak valid",
+	"attachment.quarantined":                 "Berkas ditandai oleh pemindai malware dan dikarantina",
+	"attachment.scanning_unavailable":        "pemindaian lampiran tidak tersedia",
+	"attachment.storage_unavailable":         "penyimpanan lampiran tidak tersedia",
+	"attachment.file_too_large":              "berkas terlalu besar",
+	"attachment.max_size":                    "%w: lampiran paling besar %d byte",
+	"attachment.not_uploaded":                "berkas belum diunggah",
+	"attachment.filename_required":           "nama berkas wajib diisi",
+	"attachment.filename_too_long":           "nama berkas paling panjang %d byte",
+	"attachment.filename_control_characters": "nama berkas tidak boleh berisi karakter kontrol",
+	"attachment.invalid_content_type":        "content_type bukan jenis media yang valid",
+	"attachment.size_positive":               "size harus bernilai positif",
+	"attachment.too_many":                    "tugas paling banyak memiliki %d lampiran",
+
+	// Automation rules
+	"automation.rule_created":               "Aturan otomatisasi berhasil dibuat",
+	"automation.rule_retrieved":             "Aturan otomatisasi berhasil diambil",
+	"automation.rules_retrieved":            "Daftar aturan otomatisasi berhasil diambil",
+	"automation.rule_updated":               "Aturan otomatisasi berhasil diperbarui",
+	"automation.rule_deleted":               "Aturan otomatisasi berhasil dihapus",
+	"automation.executions_retrieved":       "Riwayat eksekusi aturan otomatisasi berhasil diambil",
+	"automation.rule_not_found":             "aturan otomatisasi tidak ditemukan",
+	"automation.invalid_rule_id":            "ID aturan tidak valid",
+	"automation.max_rules":                  "pengguna paling banyak memiliki %d aturan otomatisasi",
+	"automation.action_required":            "minimal satu aksi wajib diisi",
+	"automation.max_actions":                "aturan paling banyak memiliki %d aksi",
+	"automation.max_conditions":             "aturan paling banyak memiliki %d kondisi",
+	"automation.invalid_action":             "aksi tidak valid: %s",
+	"automation.invalid_after":              "after tidak valid: %s",
+	"automation.after_range":                "after harus antara 1m dan 2160h",
+	"automation.archive_no_value":           "archive tidak menerima nilai",
+	"automation.condition_value_required":   "nilai kondisi wajib diisi",
+	"automation.invalid_condition_field":    "field kondisi tidak valid: %s",
+	"automation.invalid_operator":           "operator %q tidak valid untuk %s",
+	"automation.invalid_trigger_event":      "event pemicu tidak valid: %s",
+	"automation.invalid_trigger_field":      "field pemicu tidak valid: %s",
+	"automation.trigger_field_updated_only": "field pemicu hanya diizinkan untuk task.updated",
+	"automation.delayed_unavailable":        "aksi tertunda tidak tersedia",
+	"automation.conditions_changed":         "kondisi tidak lagi terpenuhi",
+	"automation.loop":                       "terlalu banyak otomatisasi dijalankan pada tugas, aturan mungkin saling membatalkan",
+
+	// Escalation rules
+	"escalation.rule_created":       "Aturan eskalasi berhasil dibuat",
+	"escalation.rule_retrieved":     "Aturan eskalasi berhasil diambil",
+	"escalation.rules_retrieved":    "Daftar aturan eskalasi berhasil diambil",
+	"escalation.rule_updated":       "Aturan eskalasi berhasil diperbarui",
+	"escalation.rule_deleted":       "Aturan eskalasi berhasil dihapus",
+	"escalation.rule_not_found":     "aturan eskalasi tidak ditemukan",
+	"escalation.max_rules":          "ruang kerja paling banyak memiliki %d aturan eskalasi",
+	"escalation.overdue_by_range":   "overdue_by harus antara 0s dan 2160h",
+	"escalation.invalid_overdue_by": "overdue_by tidak valid: %s",
+	"escalation.recipient_required": "notify membutuhkan minimal satu penerima",
+	"escalation.invalid_recipient":  "penerima tidak valid: %s",
+
+	// Reports and stats webhooks
+	"report.burndown_retrieved":     "Grafik burndown berhasil diambil",
+	"report.webhook_created":        "Webhook statistik berhasil dibuat",
+	"report.webhook_retrieved":      "Webhook statistik berhasil diambil",
+	"report.webhooks_retrieved":     "Daftar webhook statistik berhasil diambil",
+	"report.webhook_updated":        "Webhook statistik berhasil diperbarui",
+	"report.webhook_deleted":        "Webhook statistik berhasil dihapus",
+	"report.webhook_secret_rotated": "Rahasia webhook statistik berhasil diganti",
+	"report.webhook_not_found":      "webhook statistik tidak ditemukan",
+	"report.invalid_webhook_id":     "ID webhook tidak valid",
+	"report.max_webhooks":           "pengguna paling banyak memiliki %d webhook statistik",
+	"report.webhook_admins_only":    "hanya admin ruang kerja yang dapat menambahkan webhook statistik ruang kerja",
+	"report.webhook_admin_removed":  "pengguna bukan lagi admin ruang kerja",
+	"report.webhooks_need_jobs":     "webhook statistik tidak dikirim tanpa antrean pekerjaan",
+	"report.webhook_secret_failed":  "gagal membuat rahasia webhook",
+	"report.frequency_invalid":      "frequency harus daily atau weekly",
+	"report.url_required":           "url wajib diisi",
+	"report.url_https":              "url harus berupa URL https",
+	"report.url_too_long":           "url paling banyak 2048 karakter",
+	"report.url_private":            "url tidak boleh mengarah ke alamat lokal atau privat",
+
+	// Notifications and devices
+	"notification.preferences_retrieved": "Preferensi notifikasi berhasil diambil",
+	"notification.preferences_updated":   "Preferensi notifikasi berhasil diperbarui",
+	"notification.settings_retrieved":    "Pengaturan notifikasi berhasil diambil",
+	"notification.settings_updated":      "Pengaturan notifikasi berhasil diperbarui",
+	"notification.digest_retrieved":      "Ringkasan berhasil diambil",
+	"notification.not_sent_through":      "notifikasi %s tidak dikirim melalui %s",
+	"notification.event_required":        "minimal satu event wajib diisi",
+	"notification.unknown_event":         "event tidak dikenal: %s",
+	"notification.time_zone_empty":       "time_zone tidak boleh kosong",
+	"notification.invalid_time_zone":     "time_zone tidak valid: %s",
+	"device.registered":                  "Perangkat berhasil didaftarkan",
+	"device.updated":                     "Perangkat berhasil diperbarui",
+	"device.removed":                     "Perangkat berhasil dihapus",
+	"device.list_retrieved":              "Daftar perangkat berhasil diambil",
+	"device.not_found":                   "Perangkat tidak ditemukan",
+	"device.not_found_lower":             "perangkat tidak ditemukan",
+	"device.invalid_id":                  "ID perangkat tidak valid",
+	"device.invalid_platform":            "platform harus salah satu dari: %s",
+	"device.token_too_long":              "token terlalu panjang",
+	"device.invalid_apns_token":          "token harus berupa token perangkat APNs dalam format heksadesimal",
+
+	// Account data
+	"account.usage_retrieved":                   "Penggunaan berhasil diambil",
+	"account.data_exported":                     "Data berhasil diekspor",
+	"account.export_started":                    "Ekspor dimulai",
+	"account.export_retrieved":                  "Ekspor berhasil diambil",
+	"account.export_not_found":                  "Ekspor tidak ditemukan",
+	"account.export_not_found_lower":            "ekspor data tidak ditemukan",
+	"account.invalid_export_id":                 "ID ekspor tidak valid",
+	"account.export_archives_unavailable":       "Arsip ekspor data tidak tersedia, gunakan GET /me/export sebagai gantinya",
+	"account.export_archives_unavailable_lower": "arsip ekspor data tidak tersedia",
+	"account.deleted":                           "Akun berhasil dihapus",
+	"account.delete_failed":                     "Gagal menghapus akun",
+
+	// Billing
+	"billing.checkout_created":        "Sesi pembayaran berhasil dibuat",
+	"billing.subscription_retrieved":  "Langganan berhasil diambil",
+	"billing.not_enabled":             "penagihan tidak diaktifkan",
+	"billing.already_pro":             "sudah berlangganan paket pro",
+	"billing.provider_unavailable":    "penyedia pembayaran tidak tersedia, coba lagi nanti",
+	"billing.upgrade_required":        "peningkatan paket diperlukan",
+	"billing.plan_limit":              "%w: paket %s paling banyak mengizinkan %d %s",
+	"billing.plan_limit_tasks":        "%w: paket %s paling banyak mengizinkan %d tugas",
+	"billing.plan_limit_task":         "%w: paket %s paling banyak mengizinkan %d tugas",
+	"billing.plan_limit_attachments":  "%w: paket %s paling banyak mengizinkan %d lampiran",
+	"billing.plan_limit_attachment":   "%w: paket %s paling banyak mengizinkan %d lampiran",
+	"billing.plan_limit_integrations": "%w: paket %s paling banyak mengizinkan %d integrasi",
+	"billing.plan_limit_integration":  "%w: paket %s paling banyak mengizinkan %d integrasi",
+
+	// Jobs
+	"job.retrieved":           "Pekerjaan berhasil diambil",
+	"job.retried":             "Pekerjaan berhasil dicoba ulang",
+	"job.discarded":           "Pekerjaan berhasil dibuang",
+	"job.stats_retrieved":     "Statistik pekerjaan berhasil diambil",
+	"job.dead_retrieved":      "Daftar pekerjaan gagal berhasil diambil",
+	"job.scheduled_retrieved": "Daftar pekerjaan terjadwal berhasil diambil",
+	"job.not_found":           "Pekerjaan tidak ditemukan",
+	"job.invalid_id":          "ID pekerjaan tidak valid",
+
+	// Audit log
+	"audit.entries_retrieved": "Entri audit berhasil diambil",
+	"audit.from_before_to":    "from harus sebelum to",
+
+	// Integrations
+	"integration.invalid_event":            "Event tidak valid",
+	"integration.invalid_event_value":      "event tidak valid: %s",
+	"integration.invalid_update":           "Pembaruan tidak valid",
+	"integration.invalid_signature":        "Tanda tangan permintaan tidak valid",
+	"integration.invalid_secret_token":     "Token rahasia tidak valid",
+	"integration.invalid_state":            "state tidak valid atau sudah kedaluwarsa",
+	"integration.state_generation_failed":  "gagal membuat state",
+	"slack.connected":                      "Slack berhasil dihubungkan",
+	"slack.disconnected":                   "Slack berhasil diputuskan",
+	"slack.connection_retrieved":           "Koneksi Slack berhasil diambil",
+	"slack.not_connected":                  "Slack belum terhubung",
+	"slack.not_connected_lower":            "Slack belum terhubung",
+	"slack.commands_not_configured":        "Perintah Slack belum dikonfigurasi",
+	"slack.connected_to_other_user":        "akun Slack terhubung ke pengguna lain",
+	"slack.bot_token_invalid":              "bot_token harus berupa token bot Slack",
+	"slack.channel_required":               "channel wajib diisi jika menggunakan token bot",
+	"slack.team_and_user_together":         "team_id dan slack_user_id harus diisi bersamaan",
+	"slack.webhook_or_bot_token":           "webhook_url atau bot_token wajib diisi",
+	"slack.webhook_and_bot_token":          "webhook_url dan bot_token tidak boleh diisi bersamaan",
+	"slack.webhook_url_invalid":            "webhook_url harus berupa URL incoming webhook Slack",
+	"github.authorization_started":         "Otorisasi GitHub berhasil dimulai",
+	"github.authorization_failed":          "Gagal memulai otorisasi GitHub",
+	"github.authorization_denied":          "Otorisasi GitHub ditolak",
+	"github.connected":                     "GitHub berhasil dihubungkan",
+	"github.disconnected":                  "GitHub berhasil diputuskan",
+	"github.account_retrieved":             "Akun GitHub berhasil diambil",
+	"github.link_retrieved":                "Tautan GitHub berhasil diambil",
+	"github.project_linked":                "Proyek berhasil ditautkan ke GitHub",
+	"github.project_unlinked":              "Tautan proyek ke GitHub berhasil dilepas",
+	"github.project_not_linked":            "Proyek tidak ditautkan ke GitHub",
+	"github.project_not_linked_lower":      "proyek tidak ditautkan ke GitHub",
+	"github.not_configured":                "GitHub belum dikonfigurasi",
+	"github.not_configured_lower":          "GitHub belum dikonfigurasi",
+	"github.not_connected":                 "GitHub belum terhubung",
+	"github.not_connected_lower":           "GitHub belum terhubung",
+	"github.cannot_push":                   "akun GitHub tidak dapat melakukan push ke repositori",
+	"github.repository_not_found":          "repositori tidak ditemukan",
+	"github.repository_format":             "repository harus dalam format owner/name",
+	"gcal.authorization_started":           "Otorisasi Google Calendar berhasil dimulai",
+	"gcal.authorization_failed":            "Gagal memulai otorisasi Google Calendar",
+	"gcal.authorization_denied":            "Otorisasi Google Calendar ditolak",
+	"gcal.connected":                       "Google Calendar berhasil dihubungkan",
+	"gcal.disconnected":                    "Google Calendar berhasil diputuskan",
+	"gcal.connection_retrieved":            "Koneksi Google Calendar berhasil diambil",
+	"gcal.not_configured":                  "Google Calendar belum dikonfigurasi",
+	"gcal.not_configured_lower":            "Google Calendar belum dikonfigurasi",
+	"gcal.not_connected":                   "Google Calendar belum terhubung",
+	"gcal.not_connected_lower":             "Google Calendar belum terhubung",
+	"telegram.link_code_created":           "Kode penautan Telegram berhasil dibuat",
+	"telegram.link_code_failed":            "Gagal membuat kode penautan",
+	"telegram.link_code_generation_failed": "gagal membuat kode penautan",
+	"telegram.link_retrieved":              "Tautan Telegram berhasil diambil",
+	"telegram.unlinked":                    "Tautan Telegram berhasil dilepas",
+	"telegram.not_configured":              "Telegram belum dikonfigurasi",
+	"telegram.not_linked":                  "Telegram belum ditautkan",
+	"telegram.not_linked_lower":            "Telegram belum ditautkan",
+	"sms.settings_retrieved":               "Pengaturan SMS berhasil diambil",
+	"sms.settings_updated":                 "Pengaturan SMS berhasil diperbarui",
+	"sms.removed":                          "SMS berhasil dihapus",
+	"sms.messages_retrieved":               "Daftar pesan SMS berhasil diambil",
+	"sms.code_sent":                        "Kode verifikasi berhasil dikirim",
+	"sms.phone_verified":                   "Nomor telepon berhasil diverifikasi",
+	"sms.not_configured":                   "SMS belum dikonfigurasi",
+	"sms.not_set_up":                       "SMS belum diatur",
+	"sms.not_set_up_lower":                 "SMS belum diatur",
+	"sms.code_sent_recently":               "kode verifikasi baru saja dikirim",
+	"sms.invalid_code":                     "kode verifikasi tidak valid atau sudah kedaluwarsa",
+	"sms.code_generation_failed":           "gagal membuat kode verifikasi",
+	"sms.phone_unreachable":                "nomor telepon tidak dapat menerima SMS",
+	"sms.phone_taken":                      "nomor telepon sudah diverifikasi oleh pengguna lain",
+	"sms.phone_format":                     "phone_number harus dalam format E.164, misalnya +14155550100",
+	"sms.quiet_hours_start":                "quiet_hours.start harus berupa jam, misalnya 22:00",
+	"sms.quiet_hours_end":                  "quiet_hours.end harus berupa jam, misalnya 07:00",
+	"sms.quiet_hours_differ":               "quiet_hours.start dan quiet_hours.end harus berbeda",
+	"sms.quiet_hours_time_zone":            "quiet_hours.time_zone harus berupa zona waktu IANA, misalnya Europe/Berlin",
+	"emailin.created":                      "Alamat email masuk berhasil dibuat",
+	"emailin.retrieved":                    "Alamat email masuk berhasil diambil",
+	"emailin.deleted":                      "Alamat email masuk berhasil dihapus",
+	"emailin.create_failed":                "Gagal membuat alamat email masuk",
+	"emailin.generation_failed":            "gagal membuat alamat email masuk",
+	"emailin.not_found":                    "Alamat email masuk tidak ditemukan",
+	"emailin.not_found_lower":              "alamat email masuk tidak ditemukan",
+	"emailin.not_configured":               "Email masuk belum dikonfigurasi",
+	"emailin.rejected":                     "email ditolak",
+	"emailin.no_recipient":                 "%w: tidak ada penerima yang merupakan alamat email masuk",
+	"emailin.spam":                         "%w: pengirim gagal lolos SPF dan DKIM atau email tersebut spam",
+	"emailin.unknown_sender":               "%w: pengirim bukan alamat email pengguna",
+
+	// SCIM provisioning
+	"scim.invalid_filter":        "filter tidak valid",
+	"scim.invalid_path":          "path tidak valid",
+	"scim.invalid_value":         "nilai tidak valid",
+	"scim.username_taken":        "userName sudah digunakan",
+	"scim.username_required":     "%w: userName wajib diisi",
+	"scim.username_email":        "%w: userName harus berupa alamat email",
+	"scim.operations_required":   "%w: Operations wajib diisi",
+	"scim.active_boolean":        "%w: active harus berupa boolean",
+	"scim.expected_string":       "%w: harus berupa string",
+	"scim.invalid_string":        "%w: string tidak valid %s",
+	"scim.name_object":           "%w: name harus berupa objek",
+	"scim.only_eq":               "%w: hanya perbandingan eq seperti userName eq \"jane@example.com\" yang didukung",
+	"scim.unsupported_attribute": "%w: atribut tidak didukung %s",
+	"scim.unsupported_op":        "%w: op tidak didukung %q",
+	"scim.cannot_remove":         "%w: %s tidak dapat dihapus",
+	"scim.value_object":          "%w: value harus berupa objek atribut tanpa path",
+
+	// Wrapped errors
+	"error.wrapped": "%w: %v",
+}
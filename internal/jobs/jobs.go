@@ -0,0 +1,167 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payload is the typed payload of a job. Payloads are stored as JSON, so a job can be
+// retried after its enqueuer is gone; they must be value types whose JobType does not
+// depend on their fields.
+type Payload interface {
+	JobType() string
+}
+
+// Status is the state of a job
+type Status string
+
+const (
+	StatusPending   Status = "pending" // waiting for a worker, or for its next attempt
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed" // failed permanently, without retries
+	StatusDead      Status = "dead"   // failed every attempt, kept in the dead-letter list
+)
+
+var (
+	// ErrJobNotFound is returned when no job has the ID, or it is no longer kept
+	ErrJobNotFound = errors.New("job not found")
+	// ErrNotDead is returned when retrying or discarding a job outside the dead-letter list
+	ErrNotDead = errors.New("job is not in the dead-letter list")
+	// ErrUnknownType is returned when enqueuing a job no handler is registered for
+	ErrUnknownType = errors.New("unknown job type")
+	// ErrClosed is returned when enqueuing once the queue is shut down
+	ErrClosed = errors.New("job queue is closed")
+)
+
+// Job is a unit of background work, run by a worker until it succeeds or runs out of
+// attempts
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      Status          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	OwnerID     *uuid.UUID      `json:"-"` // user who may follow the job, if any
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Done reports whether the job will not run again unless retried
+func (j *Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusDead
+}
+
+// Stats counts the jobs kept by a queue by status
+type Stats struct {
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Dead      int `json:"dead"`
+}
+
+// HandlerFunc runs a job, returning its result, if any. Errors are retried unless they are
+// permanent.
+type HandlerFunc func(ctx context.Context, job *Job) (result interface{}, err error)
+
+// Queue defines the job queue interface. Jobs are run by a pool of workers, at least once:
+// a failed job is retried with a backoff until it runs out of attempts, then moved to the
+// dead-letter list, where admins may retry or discard it.
+type Queue interface {
+	// Register registers the handler of a job type. Handlers are registered at startup,
+	// before jobs of their type are enqueued.
+	Register(jobType string, handler HandlerFunc)
+	Enqueue(payload Payload, opts ...Option) (*Job, error)
+	Get(id uuid.UUID) (*Job, error)
+	// DeadLetters lists the jobs that failed every attempt, oldest first
+	DeadLetters() []*Job
+	// Retry moves a job of the dead-letter list back to the queue with fresh attempts
+	Retry(id uuid.UUID) (*Job, error)
+	// Discard removes a job from the dead-letter list
+	Discard(id uuid.UUID) error
+	Stats() Stats
+	// Ping returns ErrClosed once the queue is shut down, for health checks
+	Ping() error
+	// Shutdown stops accepting jobs and runs the jobs already due until the context is done.
+	// It returns the number of jobs left pending or interrupted.
+	Shutdown(ctx context.Context) (dropped int)
+}
+
+// Option customizes an enqueued job
+type Option func(*Job)
+
+// WithOwner lets the user follow the job, such as an import they started
+func WithOwner(userID uuid.UUID) Option {
+	return func(j *Job) {
+		j.OwnerID = &userID
+	}
+}
+
+// WithMaxAttempts overrides the attempts of the queue for the job
+func WithMaxAttempts(attempts int) Option {
+	return func(j *Job) {
+		if attempts > 0 {
+			j.MaxAttempts = attempts
+		}
+	}
+}
+
+// WithDelay runs the job once the delay has passed
+func WithDelay(delay time.Duration) Option {
+	return func(j *Job) {
+		j.RunAt = j.RunAt.Add(delay)
+	}
+}
+
+// permanentError is an error retries would not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks the error of a job as one retries would not fix, such as invalid input.
+// The job fails at once rather than being retried and dead-lettered.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether the error was marked permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Handle registers the handler of the jobs with payloads of type P
+func Handle[P Payload](q Queue, handle func(ctx context.Context, payload P) error) {
+	HandleWithResult(q, func(ctx context.Context, payload P) (interface{}, error) {
+		return nil, handle(ctx, payload)
+	})
+}
+
+// HandleWithResult registers the handler of the jobs with payloads of type P, keeping the
+// result of each job for whoever follows it
+func HandleWithResult[P Payload, R any](q Queue, handle func(ctx context.Context, payload P) (R, error)) {
+	var zero P
+	q.Register(zero.JobType(), func(ctx context.Context, job *Job) (interface{}, error) {
+		var payload P
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, Permanent(fmt.Errorf("invalid %s payload: %w", job.Type, err))
+		}
+		return handle(ctx, payload)
+	})
+}
@@ -0,0 +1,423 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config holds the configuration of an in-process queue
+type Config struct {
+	Workers         int           // jobs run concurrently
+	MaxAttempts     int           // attempts per job, including retries
+	RetryBackoff    time.Duration // delay before the first retry, doubling after each
+	MaxBackoff      time.Duration // longest delay between retries
+	DeadLetterLimit int           // dead jobs kept, dropping the oldest beyond
+	Retention       time.Duration // how long succeeded and failed jobs are kept
+}
+
+// memoryQueue implements an in-process queue run by a pool of worker goroutines
+type memoryQueue struct {
+	mu       sync.Mutex
+	jobs     map[uuid.UUID]*Job // Mock job storage
+	pending  []*Job             // jobs waiting to run, in enqueue order
+	dead     []*Job             // dead-letter list, oldest first
+	handlers map[string]HandlerFunc
+	closed   bool
+	ready    chan struct{} // signaled when jobs are enqueued
+	stop     chan struct{} // closed on shutdown
+	ctx      context.Context
+	cancel   context.CancelFunc // interrupts running jobs once the shutdown deadline passes
+	wg       sync.WaitGroup
+	config   Config
+}
+
+// NewMemoryQueue creates an in-process queue and starts its workers. Jobs are lost when
+// the process exits.
+func NewMemoryQueue(cfg Config) Queue {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &memoryQueue{
+		jobs:     make(map[uuid.UUID]*Job),
+		handlers: make(map[string]HandlerFunc),
+		ready:    make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		config:   cfg,
+	}
+
+	q.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Register registers the handler of a job type, replacing any previous one
+func (q *memoryQueue) Register(jobType string, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue adds a job with the payload, run as soon as a worker is free unless delayed
+func (q *memoryQueue) Enqueue(payload Payload, opts ...Option) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", payload.JobType(), err)
+	}
+
+	now := time.Now().UTC()
+	job := &Job{
+		ID:          uuid.New(),
+		Type:        payload.JobType(),
+		Payload:     data,
+		Status:      StatusPending,
+		MaxAttempts: q.config.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, ErrClosed
+	}
+	if _, exists := q.handlers[job.Type]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, job.Type)
+	}
+
+	q.prune(now)
+	q.jobs[job.ID] = job
+	q.pending = append(q.pending, job)
+	q.signal()
+
+	created := *job
+	return &created, nil
+}
+
+// Get returns a copy of the job
+func (q *memoryQueue) Get(id uuid.UUID) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, exists := q.jobs[id]
+	if !exists {
+		return nil, ErrJobNotFound
+	}
+	found := *job
+	return &found, nil
+}
+
+// DeadLetters returns copies of the dead jobs, oldest first
+func (q *memoryQueue) DeadLetters() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dead := make([]*Job, len(q.dead))
+	for i, job := range q.dead {
+		copied := *job
+		dead[i] = &copied
+	}
+	return dead
+}
+
+// Retry moves a dead job back to the queue, run again with all its attempts
+func (q *memoryQueue) Retry(id uuid.UUID) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrClosed
+	}
+	job, err := q.removeDead(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job.Status = StatusPending
+	job.Attempts = 0
+	job.RunAt = now
+	job.UpdatedAt = now
+	q.pending = append(q.pending, job)
+	q.signal()
+
+	retried := *job
+	return &retried, nil
+}
+
+// Discard forgets a dead job
+func (q *memoryQueue) Discard(id uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, err := q.removeDead(id); err != nil {
+		return err
+	}
+	delete(q.jobs, id)
+	return nil
+}
+
+// Stats counts the jobs by status
+func (q *memoryQueue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var stats Stats
+	for _, job := range q.jobs {
+		switch job.Status {
+		case StatusPending:
+			stats.Pending++
+		case StatusRunning:
+			stats.Running++
+		case StatusSucceeded:
+			stats.Succeeded++
+		case StatusFailed:
+			stats.Failed++
+		case StatusDead:
+			stats.Dead++
+		}
+	}
+	return stats
+}
+
+// Ping reports whether the queue still accepts jobs
+func (q *memoryQueue) Ping() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Shutdown stops accepting jobs and lets the workers run the jobs already due. Once the
+// context is done, running jobs are interrupted through their context, and they and the
+// jobs still pending are counted as dropped.
+func (q *memoryQueue) Shutdown(ctx context.Context) int {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.stop)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		q.cancel()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dropped := 0
+	for _, job := range q.jobs {
+		if job.Status == StatusPending || job.Status == StatusRunning {
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// work runs jobs as they come due until the queue is shut down and no job is due
+func (q *memoryQueue) work() {
+	defer q.wg.Done()
+
+	for {
+		job, handler, wait := q.next(time.Now())
+		if job != nil {
+			q.finish(job, q.run(job, handler))
+			continue
+		}
+
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-q.ready:
+		case <-due:
+		case <-q.stop:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// next takes the first job due at the time, marking it running. Without one, it returns
+// how long until the next job comes due, zero when none is pending.
+func (q *memoryQueue) next(now time.Time) (*Job, HandlerFunc, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var wait time.Duration
+	for i, job := range q.pending {
+		if job.RunAt.After(now) {
+			if until := job.RunAt.Sub(now); wait == 0 || until < wait {
+				wait = until
+			}
+			continue
+		}
+
+		q.pending = append(q.pending[:i], q.pending[i+1:]...)
+		job.Status = StatusRunning
+		job.Attempts++
+		job.UpdatedAt = now.UTC()
+		if len(q.pending) > 0 {
+			// Wake another worker for the jobs left
+			q.signal()
+		}
+		return job, q.handlers[job.Type], 0
+	}
+	return nil, nil, wait
+}
+
+// outcome is what a run of a job returned
+type outcome struct {
+	result interface{}
+	err    error
+}
+
+// run runs the job with its handler, turning panics into errors
+func (q *memoryQueue) run(job *Job, handler HandlerFunc) (o outcome) {
+	defer func() {
+		if r := recover(); r != nil {
+			o = outcome{err: fmt.Errorf("panic: %v", r)}
+		}
+	}()
+
+	if handler == nil {
+		return outcome{err: Permanent(fmt.Errorf("%w: %s", ErrUnknownType, job.Type))}
+	}
+	copied := *job
+	result, err := handler(q.ctx, &copied)
+	return outcome{result: result, err: err}
+}
+
+// finish records the outcome of a run, scheduling a retry or dead-lettering failed jobs
+func (q *memoryQueue) finish(job *Job, o outcome) {
+	var result json.RawMessage
+	if o.err == nil && o.result != nil {
+		data, err := json.Marshal(o.result)
+		if err != nil {
+			o.err = Permanent(fmt.Errorf("invalid result: %w", err))
+		}
+		result = data
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now().UTC()
+	job.UpdatedAt = now
+	switch {
+	case o.err == nil:
+		job.Status = StatusSucceeded
+		job.Result = result
+		job.LastError = ""
+	case IsPermanent(o.err):
+		job.Status = StatusFailed
+		job.LastError = o.err.Error()
+		log.Printf("Job %s %s failed: %v", job.Type, job.ID, o.err)
+	case job.Attempts >= job.MaxAttempts:
+		job.Status = StatusDead
+		job.LastError = o.err.Error()
+		q.dead = append(q.dead, job)
+		if len(q.dead) > q.config.DeadLetterLimit && q.config.DeadLetterLimit > 0 {
+			delete(q.jobs, q.dead[0].ID)
+			q.dead = q.dead[1:]
+		}
+		log.Printf("Job %s %s failed %d times, moved to the dead-letter list: %v", job.Type, job.ID, job.Attempts, o.err)
+	default:
+		job.Status = StatusPending
+		job.LastError = o.err.Error()
+		job.RunAt = now.Add(q.backoff(job.Attempts))
+		q.pending = append(q.pending, job)
+		log.Printf("Job %s %s failed, retrying at %s: %v", job.Type, job.ID, job.RunAt.Format(time.RFC3339), o.err)
+		q.signal()
+	}
+}
+
+// backoff returns the delay before retrying a job after its failed attempts
+func (q *memoryQueue) backoff(attempts int) time.Duration {
+	delay := q.config.RetryBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if q.config.MaxBackoff > 0 && delay >= q.config.MaxBackoff {
+			return q.config.MaxBackoff
+		}
+	}
+	if q.config.MaxBackoff > 0 && delay > q.config.MaxBackoff {
+		return q.config.MaxBackoff
+	}
+	return delay
+}
+
+// removeDead removes a job from the dead-letter list. The caller must hold the lock.
+func (q *memoryQueue) removeDead(id uuid.UUID) (*Job, error) {
+	job, exists := q.jobs[id]
+	if !exists {
+		return nil, ErrJobNotFound
+	}
+	if job.Status != StatusDead {
+		return nil, ErrNotDead
+	}
+	for i, dead := range q.dead {
+		if dead.ID == id {
+			q.dead = append(q.dead[:i], q.dead[i+1:]...)
+			break
+		}
+	}
+	return job, nil
+}
+
+// prune forgets succeeded and failed jobs kept longer than the retention. The caller must
+// hold the lock.
+func (q *memoryQueue) prune(now time.Time) {
+	for id, job := range q.jobs {
+		if (job.Status == StatusSucceeded || job.Status == StatusFailed) && now.Sub(job.UpdatedAt) > q.config.Retention {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// signal wakes a waiting worker without blocking. The caller must hold the lock.
+func (q *memoryQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
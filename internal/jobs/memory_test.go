@@ -0,0 +1,239 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func (greeting) JobType() string { return "test.greeting" }
+
+func newTestQueue(t *testing.T) Queue {
+	q := NewMemoryQueue(Config{
+		Workers:         2,
+		MaxAttempts:     3,
+		RetryBackoff:    time.Millisecond,
+		MaxBackoff:      5 * time.Millisecond,
+		DeadLetterLimit: 10,
+		Retention:       time.Hour,
+	})
+	t.Cleanup(func() { q.Shutdown(context.Background()) })
+	return q
+}
+
+// waitDone waits for the job to stop running and returns it
+func waitDone(t *testing.T, q Queue, id uuid.UUID) *Job {
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = q.Get(id)
+		require.NoError(t, err)
+		return job.Done()
+	}, time.Second, time.Millisecond)
+	return job
+}
+
+func TestMemoryQueue_RunsTypedJobs(t *testing.T) {
+	q := newTestQueue(t)
+	HandleWithResult(q, func(ctx context.Context, g greeting) (string, error) {
+		return "hello " + g.Name, nil
+	})
+
+	job, err := q.Enqueue(greeting{Name: "jane"}, WithOwner(uuid.New()))
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, job.Status)
+	assert.JSONEq(t, `{"name":"jane"}`, string(job.Payload))
+
+	job = waitDone(t, q, job.ID)
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.JSONEq(t, `"hello jane"`, string(job.Result))
+	assert.NotNil(t, job.OwnerID)
+}
+
+func TestMemoryQueue_RetriesThenDeadLetters(t *testing.T) {
+	q := newTestQueue(t)
+	var calls atomic.Int32
+	Handle(q, func(ctx context.Context, g greeting) error {
+		calls.Add(1)
+		return errors.New("mail server down")
+	})
+
+	job, err := q.Enqueue(greeting{Name: "jane"})
+	require.NoError(t, err)
+
+	job = waitDone(t, q, job.ID)
+	assert.Equal(t, StatusDead, job.Status)
+	assert.Equal(t, 3, job.Attempts)
+	assert.Equal(t, "mail server down", job.LastError)
+	assert.Equal(t, int32(3), calls.Load())
+
+	dead := q.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, job.ID, dead[0].ID)
+	assert.Equal(t, 1, q.Stats().Dead)
+
+	// Retried jobs run again with all their attempts
+	retried, err := q.Retry(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, retried.Status)
+	assert.Equal(t, 0, retried.Attempts)
+	waitDone(t, q, job.ID)
+	assert.Equal(t, int32(6), calls.Load())
+
+	require.NoError(t, q.Discard(job.ID))
+	assert.Empty(t, q.DeadLetters())
+	_, err = q.Get(job.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestMemoryQueue_SucceedsOnRetry(t *testing.T) {
+	q := newTestQueue(t)
+	var calls atomic.Int32
+	Handle(q, func(ctx context.Context, g greeting) error {
+		if calls.Add(1) == 1 {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+
+	job, err := q.Enqueue(greeting{})
+	require.NoError(t, err)
+
+	job = waitDone(t, q, job.ID)
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Empty(t, job.LastError)
+
+	_, err = q.Retry(job.ID)
+	assert.ErrorIs(t, err, ErrNotDead)
+}
+
+func TestMemoryQueue_PermanentErrors(t *testing.T) {
+	q := newTestQueue(t)
+	Handle(q, func(ctx context.Context, g greeting) error {
+		return Permanent(errors.New("no such user"))
+	})
+
+	job, err := q.Enqueue(greeting{})
+	require.NoError(t, err)
+
+	job = waitDone(t, q, job.ID)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "no such user", job.LastError)
+	assert.Empty(t, q.DeadLetters())
+}
+
+func TestMemoryQueue_Panics(t *testing.T) {
+	q := newTestQueue(t)
+	Handle(q, func(ctx context.Context, g greeting) error {
+		panic("nil map")
+	})
+
+	job, err := q.Enqueue(greeting{}, WithMaxAttempts(1))
+	require.NoError(t, err)
+
+	job = waitDone(t, q, job.ID)
+	assert.Equal(t, StatusDead, job.Status)
+	assert.Equal(t, "panic: nil map", job.LastError)
+}
+
+func TestMemoryQueue_Enqueue(t *testing.T) {
+	q := newTestQueue(t)
+
+	_, err := q.Enqueue(greeting{})
+	assert.ErrorIs(t, err, ErrUnknownType)
+
+	Handle(q, func(ctx context.Context, g greeting) error { return nil })
+	job, err := q.Enqueue(greeting{}, WithDelay(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, q.Stats().Pending)
+	assert.NoError(t, q.Ping())
+
+	// Delayed jobs are left pending on shutdown
+	assert.Equal(t, 1, q.Shutdown(context.Background()))
+	_, err = q.Enqueue(greeting{})
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, q.Ping(), ErrClosed)
+
+	job, err = q.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, job.Status)
+}
+
+func TestMemoryQueue_Shutdown_RunsDueJobs(t *testing.T) {
+	q := newTestQueue(t)
+	var calls atomic.Int32
+	Handle(q, func(ctx context.Context, g greeting) error {
+		calls.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 20; i++ {
+		_, err := q.Enqueue(greeting{})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 0, q.Shutdown(context.Background()))
+	assert.Equal(t, int32(20), calls.Load())
+}
+
+func TestMemoryQueue_Shutdown_InterruptsJobs(t *testing.T) {
+	q := newTestQueue(t)
+	started := make(chan struct{})
+	var once sync.Once
+	Handle(q, func(ctx context.Context, g greeting) error {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	_, err := q.Enqueue(greeting{})
+	require.NoError(t, err)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 1, q.Shutdown(ctx))
+}
+
+func TestMemoryQueue_DeadLetterLimit(t *testing.T) {
+	q := NewMemoryQueue(Config{Workers: 1, MaxAttempts: 1, DeadLetterLimit: 2, Retention: time.Hour})
+	defer q.Shutdown(context.Background())
+	Handle(q, func(ctx context.Context, g greeting) error { return errors.New("failed") })
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		job, err := q.Enqueue(greeting{})
+		require.NoError(t, err)
+		waitDone(t, q, job.ID)
+		ids = append(ids, job.ID)
+	}
+
+	dead := q.DeadLetters()
+	require.Len(t, dead, 2)
+	assert.Equal(t, ids[1], dead[0].ID)
+	_, err := q.Get(ids[0])
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestBackoff(t *testing.T) {
+	q := &memoryQueue{config: Config{RetryBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+	assert.Equal(t, time.Second, q.backoff(1))
+	assert.Equal(t, 2*time.Second, q.backoff(2))
+	assert.Equal(t, 4*time.Second, q.backoff(3))
+	assert.Equal(t, 5*time.Second, q.backoff(4))
+	assert.Equal(t, 5*time.Second, q.backoff(40))
+}
@@ -0,0 +1,138 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StopFunc stops a component, finishing in-flight work until the context is done. It
+// returns the number of pending work items, such as queued events, that were dropped.
+type StopFunc func(ctx context.Context) (dropped int, err error)
+
+// Result reports how a component stopped
+type Result struct {
+	Name     string
+	Duration time.Duration
+	Dropped  int   // pending work items dropped
+	Err      error // context.DeadlineExceeded when the component did not stop in time
+}
+
+// Manager stops the components of the application on shutdown, such as servers, event
+// delivery, and scheduled jobs
+type Manager struct {
+	mu         sync.Mutex
+	components []component
+	stopped    bool
+}
+
+// component is a registered component with its stop function
+type component struct {
+	name string
+	stop StopFunc
+}
+
+// NewManager creates a manager without components
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Register adds a component stopped on shutdown. Components are stopped in reverse order
+// of registration, so those started first, which later ones may depend on, stop last.
+func (m *Manager) Register(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, stop: stop})
+}
+
+// Go runs a background loop, such as a scheduler, until shutdown. The loop must return
+// once its context is done.
+func (m *Manager) Go(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+
+	m.Register(name, func(stopCtx context.Context) (int, error) {
+		cancel()
+		select {
+		case <-done:
+			return 0, nil
+		case <-stopCtx.Done():
+			return 0, stopCtx.Err()
+		}
+	})
+}
+
+// Shutdown stops every component in reverse order of registration, sharing the deadline of
+// the context. Components are stopped even once the deadline has passed, so they can drop
+// their pending work quickly and report it. Later calls do nothing.
+func (m *Manager) Shutdown(ctx context.Context) []Result {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	components := m.components
+	m.mu.Unlock()
+
+	results := make([]Result, 0, len(components))
+	for i := len(components) - 1; i >= 0; i-- {
+		start := time.Now()
+		dropped, err := stopComponent(ctx, components[i].stop)
+		results = append(results, Result{
+			Name:     components[i].name,
+			Duration: time.Since(start),
+			Dropped:  dropped,
+			Err:      err,
+		})
+	}
+	return results
+}
+
+// dropGracePeriod is how long components may take to drop their pending work after the
+// shutdown deadline has passed
+const dropGracePeriod = 100 * time.Millisecond
+
+// stopComponent calls the stop function, returning when the context is done even if the
+// function does not
+func stopComponent(ctx context.Context, stop StopFunc) (int, error) {
+	type outcome struct {
+		dropped int
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		dropped, err := stop(ctx)
+		done <- outcome{dropped, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.dropped, o.err
+	case <-ctx.Done():
+		// Give the function a chance to report what it dropped once the deadline passed
+		select {
+		case o := <-done:
+			return o.dropped, o.err
+		case <-time.After(dropGracePeriod):
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// String describes the result for logging
+func (r Result) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s failed to stop after %s: %v", r.Name, r.Duration.Round(time.Millisecond), r.Err)
+	case r.Dropped > 0:
+		return fmt.Sprintf("%s stopped in %s, dropping %d pending items", r.Name, r.Duration.Round(time.Millisecond), r.Dropped)
+	default:
+		return fmt.Sprintf("%s stopped in %s", r.Name, r.Duration.Round(time.Millisecond))
+	}
+}
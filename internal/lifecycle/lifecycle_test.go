@@ -0,0 +1,89 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerStopsComponentsInReverseOrder(t *testing.T) {
+	m := NewManager()
+
+	var stopped []string
+	for _, name := range []string{"event bus", "http server"} {
+		m.Register(name, func(ctx context.Context) (int, error) {
+			stopped = append(stopped, name)
+			return 0, nil
+		})
+	}
+
+	results := m.Shutdown(context.Background())
+	assert.Equal(t, []string{"http server", "event bus"}, stopped)
+	require.Len(t, results, 2)
+	assert.Equal(t, "http server", results[0].Name)
+	assert.NoError(t, results[0].Err)
+
+	// Shutting down again does nothing
+	assert.Empty(t, m.Shutdown(context.Background()))
+	assert.Len(t, stopped, 2)
+}
+
+func TestManagerReportsDroppedWorkAndTimeouts(t *testing.T) {
+	m := NewManager()
+	m.Register("stuck", func(ctx context.Context) (int, error) {
+		select {}
+	})
+	m.Register("queue", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 4, nil
+	})
+	m.Register("broken", func(ctx context.Context) (int, error) {
+		return 0, errors.New("connection reset")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results := m.Shutdown(ctx)
+	require.Len(t, results, 3)
+
+	assert.EqualError(t, results[0].Err, "connection reset")
+	assert.Contains(t, results[0].String(), "broken failed to stop")
+
+	// Components still run after the deadline, so they can report what they dropped
+	assert.Equal(t, 4, results[1].Dropped)
+	assert.Contains(t, results[1].String(), "queue stopped in")
+	assert.Contains(t, results[1].String(), "dropping 4 pending items")
+
+	assert.ErrorIs(t, results[2].Err, context.DeadlineExceeded)
+}
+
+func TestManagerGo(t *testing.T) {
+	m := NewManager()
+
+	ticks := make(chan struct{}, 1)
+	m.Go("scheduler", func(ctx context.Context) {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case ticks <- struct{}{}:
+				default:
+				}
+			}
+		}
+	})
+	<-ticks
+
+	results := m.Shutdown(context.Background())
+	require.Len(t, results, 1)
+	assert.Equal(t, "scheduler", results[0].Name)
+	assert.NoError(t, results[0].Err)
+}
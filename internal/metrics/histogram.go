@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds of histogram buckets suited to request durations in
+// seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramVec is a family of histograms partitioned by label values, such as
+// http_request_duration_seconds{method="GET",route="/api/v2/tasks"}
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64 // upper bounds, ascending
+
+	mu     sync.Mutex
+	series map[string]*histogram // formatted label set to histogram
+}
+
+// histogram counts the observations of one label set
+type histogram struct {
+	counts []uint64 // observations in each bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram family with the bucket upper bounds, sorted in
+// ascending order, and the label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*histogram),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.histograms = append(r.histograms, h)
+
+	return h
+}
+
+// Observe records the value in the histogram with the label values, given in the order of
+// the label names
+func (v *HistogramVec) Observe(value float64, labelValues ...string) {
+	labelSet := formatLabelSet(v.name, v.labels, labelValues)
+	bucket := sort.SearchFloat64s(v.buckets, value)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	h, ok := v.series[labelSet]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(v.buckets))}
+		v.series[labelSet] = h
+	}
+	if bucket < len(v.buckets) {
+		h.counts[bucket]++
+	}
+	h.count++
+	h.sum += value
+}
+
+// Count returns the number of observations of the histogram with the label values
+func (v *HistogramVec) Count(labelValues ...string) uint64 {
+	labelSet := formatLabelSet(v.name, v.labels, labelValues)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if h, ok := v.series[labelSet]; ok {
+		return h.count
+	}
+	return 0
+}
+
+// write writes the histogram family, its series sorted by label set, with cumulative buckets
+func (v *HistogramVec) write(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", v.name, v.help, v.name); err != nil {
+		return err
+	}
+
+	labelSets := make([]string, 0, len(v.series))
+	for labelSet := range v.series {
+		labelSets = append(labelSets, labelSet)
+	}
+	sort.Strings(labelSets)
+
+	for _, labelSet := range labelSets {
+		h := v.series[labelSet]
+		prefix := labelSet
+		if prefix != "" {
+			prefix += ","
+		}
+
+		var cumulative uint64
+		for i, bound := range v.buckets {
+			cumulative += h.counts[i]
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			if _, err := fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", v.name, prefix, le, cumulative); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", v.name, prefix, h.count); err != nil {
+			return err
+		}
+
+		sample := ""
+		if labelSet != "" {
+			sample = "{" + labelSet + "}"
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %g\n%s_count%s %d\n", v.name, sample, h.sum, v.name, sample, h.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
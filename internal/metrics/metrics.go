@@ -0,0 +1,210 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry holds the metrics exposed to Prometheus
+type Registry struct {
+	mu         sync.Mutex
+	counters   []*CounterVec
+	gauges     []*GaugeFunc
+	gaugeVecs  []*GaugeVec
+	histograms []*HistogramVec
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// CounterVec is a family of counters partitioned by label values, such as
+// auth_login_attempts_total{result="failure"}
+type CounterVec struct {
+	vec
+}
+
+// NewCounterVec registers a counter family with the label names
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{vec: newVec(name, help, labels)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters = append(r.counters, counter)
+
+	return counter
+}
+
+// Inc increments the counter with the label values, given in the order of the label names
+func (v *CounterVec) Inc(labelValues ...string) {
+	labelSet := v.labelSet(labelValues)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[labelSet]++
+}
+
+// GaugeVec is a family of gauges partitioned by label values and set as they change, such as
+// external_circuit_state{integration="slack"}
+type GaugeVec struct {
+	vec
+}
+
+// NewGaugeVec registers a gauge family with the label names
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	gauge := &GaugeVec{vec: newVec(name, help, labels)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gaugeVecs = append(r.gaugeVecs, gauge)
+
+	return gauge
+}
+
+// Set sets the gauge with the label values, given in the order of the label names
+func (v *GaugeVec) Set(value float64, labelValues ...string) {
+	labelSet := v.labelSet(labelValues)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[labelSet] = value
+}
+
+// vec holds the samples of a metric family by label set
+type vec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64 // formatted label set to value
+}
+
+// newVec creates an empty metric family with the label names
+func newVec(name, help string, labels []string) vec {
+	return vec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// labelSet formats the label values, given in the order of the label names
+func (v *vec) labelSet(labelValues []string) string {
+	return formatLabelSet(v.name, v.labels, labelValues)
+}
+
+// formatLabelSet formats the label values of the metric, given in the order of the label names
+func formatLabelSet(name string, labels, labelValues []string) string {
+	if len(labelValues) != len(labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", name, len(labels), len(labelValues)))
+	}
+
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		pairs[i] = label + `="` + escapeLabelValue(labelValues[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Value returns the current value of the metric with the label values
+func (v *vec) Value(labelValues ...string) float64 {
+	pairs := make([]string, 0, len(labelValues))
+	for i, value := range labelValues {
+		if i < len(v.labels) {
+			pairs = append(pairs, v.labels[i]+`="`+escapeLabelValue(value)+`"`)
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.values[strings.Join(pairs, ",")]
+}
+
+// GaugeFunc is a gauge whose value is read when metrics are written, such as the number of
+// stored tasks
+type GaugeFunc struct {
+	name  string
+	help  string
+	value func() float64
+}
+
+// NewGaugeFunc registers a gauge reporting the value returned by the function, which must be
+// safe to call concurrently
+func (r *Registry) NewGaugeFunc(name, help string, value func() float64) *GaugeFunc {
+	gauge := &GaugeFunc{name: name, help: help, value: value}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges = append(r.gauges, gauge)
+
+	return gauge
+}
+
+// Write writes every metric in the Prometheus text exposition format: counters, then gauges,
+// then histograms
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	counters := append([]*CounterVec{}, r.counters...)
+	gauges := append([]*GaugeFunc{}, r.gauges...)
+	gaugeVecs := append([]*GaugeVec{}, r.gaugeVecs...)
+	histograms := append([]*HistogramVec{}, r.histograms...)
+	r.mu.Unlock()
+
+	for _, counter := range counters {
+		if err := counter.write(w, "counter"); err != nil {
+			return err
+		}
+	}
+	for _, gauge := range gauges {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", gauge.name, gauge.help, gauge.name, gauge.name, gauge.value()); err != nil {
+			return err
+		}
+	}
+	for _, gauge := range gaugeVecs {
+		if err := gauge.write(w, "gauge"); err != nil {
+			return err
+		}
+	}
+	for _, histogram := range histograms {
+		if err := histogram.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes the metric family of the type, its samples sorted by label set
+func (v *vec) write(w io.Writer, metricType string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, metricType); err != nil {
+		return err
+	}
+
+	labelSets := make([]string, 0, len(v.values))
+	for labelSet := range v.values {
+		labelSets = append(labelSets, labelSet)
+	}
+	sort.Strings(labelSets)
+
+	for _, labelSet := range labelSets {
+		sample := v.name
+		if labelSet != "" {
+			sample += "{" + labelSet + "}"
+		}
+		if _, err := fmt.Fprintf(w, "%s %g\n", sample, v.values[labelSet]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeLabelValue escapes a label value as required by the text exposition format
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	registry := NewRegistry()
+	attempts := registry.NewCounterVec("auth_login_attempts_total", "Login attempts by result.", "result")
+	registry.NewCounterVec("auth_anomalies_total", "Detected anomalies by type.", "type")
+	stored := 3.0
+	registry.NewGaugeFunc("task_storage_tasks", "Tasks stored.", func() float64 { return stored })
+	states := registry.NewGaugeVec("external_circuit_state", "State of the breakers.", "integration")
+
+	attempts.Inc("success")
+	attempts.Inc("failure")
+	attempts.Inc("failure")
+	attempts.Inc(`odd"value`)
+
+	assert.Equal(t, float64(2), attempts.Value("failure"))
+	assert.Equal(t, float64(0), attempts.Value("throttled"))
+	assert.Panics(t, func() { attempts.Inc() })
+
+	states.Set(2, "slack")
+	states.Set(0, "github")
+	states.Set(0, "slack")
+	assert.Equal(t, float64(0), states.Value("slack"))
+	assert.Panics(t, func() { states.Set(1) })
+
+	var out bytes.Buffer
+	require.NoError(t, registry.Write(&out))
+	assert.Equal(t, `# HELP auth_login_attempts_total Login attempts by result.
+# TYPE auth_login_attempts_total counter
+auth_login_attempts_total{result="failure"} 2
+auth_login_attempts_total{result="odd\"value"} 1
+auth_login_attempts_total{result="success"} 1
+# HELP auth_anomalies_total Detected anomalies by type.
+# TYPE auth_anomalies_total counter
+# HELP task_storage_tasks Tasks stored.
+# TYPE task_storage_tasks gauge
+task_storage_tasks 3
+# HELP external_circuit_state State of the breakers.
+# TYPE external_circuit_state gauge
+external_circuit_state{integration="github"} 0
+external_circuit_state{integration="slack"} 0
+`, out.String())
+}
+
+func TestHistogramVec(t *testing.T) {
+	registry := NewRegistry()
+	durations := registry.NewHistogramVec("http_request_duration_seconds", "Request durations.", []float64{0.1, 1}, "route")
+
+	durations.Observe(0.05, "/tasks")
+	durations.Observe(0.1, "/tasks")
+	durations.Observe(0.5, "/tasks")
+	durations.Observe(3, "/tasks")
+	durations.Observe(0.01, "/health")
+
+	assert.Equal(t, uint64(4), durations.Count("/tasks"))
+	assert.Equal(t, uint64(0), durations.Count("/search"))
+	assert.Panics(t, func() { durations.Observe(1) })
+
+	var out bytes.Buffer
+	require.NoError(t, registry.Write(&out))
+	assert.Equal(t, `# HELP http_request_duration_seconds Request durations.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/health",le="0.1"} 1
+http_request_duration_seconds_bucket{route="/health",le="1"} 1
+http_request_duration_seconds_bucket{route="/health",le="+Inf"} 1
+http_request_duration_seconds_sum{route="/health"} 0.01
+http_request_duration_seconds_count{route="/health"} 1
+http_request_duration_seconds_bucket{route="/tasks",le="0.1"} 2
+http_request_duration_seconds_bucket{route="/tasks",le="1"} 3
+http_request_duration_seconds_bucket{route="/tasks",le="+Inf"} 4
+http_request_duration_seconds_sum{route="/tasks"} 3.65
+http_request_duration_seconds_count{route="/tasks"} 4
+`, out.String())
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// accessLogEntry is a line of the access log
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	LatencyMs float64   `json:"latency_ms"`
+	Bytes     *int      `json:"bytes,omitempty"` // unknown for streamed responses
+	UserID    string    `json:"user_id,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// AccessLog creates middleware writing a JSON line to w for each request, with its method,
+// path, status, latency, response size, authenticated user, and request ID. Requests that
+// succeed are sampled at the rate, between 0 and 1, while failed requests, with a status of
+// 400 or more, are always logged. Errors are handled by the error handler of the app before
+// logging, so the status and size are those sent.
+func AccessLog(w io.Writer, sampleRate float64) fiber.Handler {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status < fiber.StatusBadRequest && (sampleRate <= 0 || rand.Float64() >= sampleRate) {
+			return nil
+		}
+
+		entry := accessLogEntry{
+			Time:      start.UTC(),
+			Method:    c.Method(),
+			Path:      c.Path(),
+			Status:    status,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
+		}
+		// Streamed bodies are only written once the handler returns, so their size is unknown
+		if !c.Response().IsBodyStream() {
+			size := len(c.Response().Body())
+			entry.Bytes = &size
+		}
+		if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+			entry.UserID = userID.String()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err := encoder.Encode(entry); err != nil {
+			log.Printf("Failed to write access log: %v", err)
+		}
+		return nil
+	}
+}
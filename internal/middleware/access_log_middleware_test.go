@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	userID := uuid.New()
+	newApp := func(logs *bytes.Buffer, sampleRate float64) *fiber.App {
+		app := fiber.New()
+		app.Use(requestid.New(), AccessLog(logs, sampleRate))
+		app.Get("/tasks", func(c *fiber.Ctx) error {
+			c.Locals("user_id", userID)
+			return c.SendString("tasks")
+		})
+		app.Get("/missing", func(c *fiber.Ctx) error {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		})
+		app.Get("/export", func(c *fiber.Ctx) error {
+			c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+				_, _ = w.WriteString("exported")
+			})
+			return nil
+		})
+		return app
+	}
+	request := func(app *fiber.App, path string) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	entries := func(logs *bytes.Buffer) []map[string]any {
+		var entries []map[string]any
+		decoder := json.NewDecoder(logs)
+		for decoder.More() {
+			var entry map[string]any
+			require.NoError(t, decoder.Decode(&entry))
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	t.Run("every request", func(t *testing.T) {
+		var logs bytes.Buffer
+		app := newApp(&logs, 1)
+		request(app, "/tasks")
+		request(app, "/missing")
+		request(app, "/export")
+
+		logged := entries(&logs)
+		require.Len(t, logged, 3)
+		assert.Equal(t, "GET", logged[0]["method"])
+		assert.Equal(t, "/tasks", logged[0]["path"])
+		assert.Equal(t, float64(fiber.StatusOK), logged[0]["status"])
+		assert.Equal(t, float64(len("tasks")), logged[0]["bytes"])
+		assert.Equal(t, userID.String(), logged[0]["user_id"])
+		assert.NotEmpty(t, logged[0]["request_id"])
+		assert.Contains(t, logged[0], "latency_ms")
+
+		// The status is that sent by the error handler
+		assert.Equal(t, float64(fiber.StatusNotFound), logged[1]["status"])
+		assert.NotContains(t, logged[1], "user_id")
+
+		// The size of streamed responses is unknown
+		assert.Equal(t, "/export", logged[2]["path"])
+		assert.NotContains(t, logged[2], "bytes")
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		var logs bytes.Buffer
+		app := newApp(&logs, 0)
+		request(app, "/tasks")
+		request(app, "/missing")
+
+		// Only the failed request is logged
+		logged := entries(&logs)
+		require.Len(t, logged, 1)
+		assert.Equal(t, "/missing", logged[0]["path"])
+	})
+}
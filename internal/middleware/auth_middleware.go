@@ -1,33 +1,34 @@
 package middleware
 
 import (
+	"slices"
+
+	"todo-api/internal/response"
 	authService "todo-api/internal/service/auth"
-	"todo-api/pkg/config"
+	automationService "todo-api/internal/service/automation"
 	"todo-api/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// AuthMiddleware creates authentication middleware
-func AuthMiddleware(config *config.Config) fiber.Handler {
-	// Initialize service
-	authSvc := authService.NewService(config)
-
+// AuthMiddleware creates authentication middleware validating tokens with the shared auth
+// service, which knows the sessions they belong to
+func AuthMiddleware(authSvc authService.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Extract token from Authorization header
 		authHeader := c.Get("Authorization")
 		token, err := utils.ExtractTokenFromHeader(authHeader)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
 				"error":   true,
 				"message": "Authorization header is required",
 			})
 		}
 
-		// Validate token
+		// Validate token, refresh tokens are only good for refreshing
 		claims, err := authSvc.ValidateToken(token)
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		if err != nil || !claims.IsAccessToken() {
+			return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
 				"error":   true,
 				"message": "Invalid or expired token",
 			})
@@ -36,6 +37,79 @@ func AuthMiddleware(config *config.Config) fiber.Handler {
 		// Store user information in context
 		c.Locals("user_id", claims.UserID)
 		c.Locals("user_email", claims.Email)
+		c.Locals("user_claims", claims)
+
+		return c.Next()
+	}
+}
+
+// APIKeyHeader is the header automation platforms send API keys in
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth creates authentication middleware accepting an API key in the X-API-Key header,
+// falling back to a token like AuthMiddleware. Requests with an API key are given claims
+// carrying the key's scopes and the tenant of its user, so scope and tenant middleware apply
+// to them as to tokens.
+func APIKeyAuth(authSvc authService.Service, keys automationService.Service) fiber.Handler {
+	authenticateToken := AuthMiddleware(authSvc)
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(APIKeyHeader)
+		if key == "" {
+			return authenticateToken(c)
+		}
+
+		apiKey, user, err := keys.Authenticate(key)
+		if err != nil {
+			return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
+				"error":   true,
+				"message": "Invalid API key",
+			})
+		}
+
+		// Store user information in context
+		c.Locals("user_id", user.ID)
+		c.Locals("user_email", user.Email)
+		c.Locals("user_claims", &utils.JWTClaims{
+			UserID:   user.ID,
+			TenantID: user.TenantID,
+			Email:    user.Email,
+			Scopes:   apiKey.Scopes,
+		})
+
+		return c.Next()
+	}
+}
+
+// RequireScope creates middleware that rejects tokens missing the given scope.
+// It must run after AuthMiddleware.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user_claims").(*utils.JWTClaims)
+		if !ok || !claims.HasScope(scope) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Insufficient scope: " + scope + " required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireExplicitScope creates middleware that rejects tokens not listing the given scope.
+// Unlike RequireScope, tokens issued without any scopes are rejected too, so it is used
+// for scopes that are never implied, such as the tenant admin scope.
+// It must run after AuthMiddleware.
+func RequireExplicitScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user_claims").(*utils.JWTClaims)
+		if !ok || !slices.Contains(claims.Scopes, scope) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Insufficient scope: " + scope + " required",
+			})
+		}
 
 		return c.Next()
 	}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/netip"
+	"strings"
+
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// clientIPKey is the context key holding the resolved client address
+const clientIPKey = "client_ip"
+
+// ResolveClientIP creates middleware that resolves the address of the client. When the
+// request comes from a trusted proxy, the client is the last address of X-Forwarded-For
+// that is not itself a trusted proxy; addresses before it are set by the client and
+// cannot be trusted. Otherwise the client is the peer of the connection.
+func ResolveClientIP(trustedProxies []netip.Prefix) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		addr := peerAddr(c)
+
+		if addr.IsValid() && containsAddr(trustedProxies, addr) {
+			forwarded := strings.Split(c.Get(fiber.HeaderXForwardedFor), ",")
+			for i := len(forwarded) - 1; i >= 0; i-- {
+				hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+				if err != nil {
+					break
+				}
+				addr = hop.Unmap()
+				if !containsAddr(trustedProxies, addr) {
+					break
+				}
+			}
+		}
+
+		c.Locals(clientIPKey, addr)
+
+		return c.Next()
+	}
+}
+
+// ClientIP returns the client address resolved by ResolveClientIP, falling back to the
+// peer of the connection when it did not run
+func ClientIP(c *fiber.Ctx) netip.Addr {
+	if addr, ok := c.Locals(clientIPKey).(netip.Addr); ok {
+		return addr
+	}
+	return peerAddr(c)
+}
+
+// IPFilter creates middleware that rejects clients in a denied range, or outside every
+// allowed range when any is set. Denied ranges take precedence over allowed ones.
+func IPFilter(allow, deny []netip.Prefix) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		addr := ClientIP(c)
+
+		if !addr.IsValid() || containsAddr(deny, addr) || (len(allow) > 0 && !containsAddr(allow, addr)) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Access denied for this IP address",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// peerAddr returns the address of the peer of the connection
+func peerAddr(c *fiber.Ctx) netip.Addr {
+	addr, _ := netip.AddrFromSlice(c.Context().RemoteIP())
+	return addr.Unmap()
+}
+
+// containsAddr reports whether any of the prefixes contains the address
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
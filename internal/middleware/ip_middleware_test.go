@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPeer is the peer address of requests sent with app.Test
+var testPeer = netip.MustParsePrefix("0.0.0.0/32")
+
+func prefixes(cidrs ...string) []netip.Prefix {
+	parsed := make([]netip.Prefix, len(cidrs))
+	for i, cidr := range cidrs {
+		parsed[i] = netip.MustParsePrefix(cidr)
+	}
+	return parsed
+}
+
+func TestResolveClientIP(t *testing.T) {
+	proxies := prefixes("10.0.0.0/8")
+
+	tests := []struct {
+		name         string
+		trusted      []netip.Prefix
+		forwardedFor string
+		wantAddr     string
+	}{
+		{name: "no proxies", forwardedFor: "203.0.113.7", wantAddr: "0.0.0.0"},
+		{name: "spoofed header from an untrusted peer", trusted: proxies, forwardedFor: "203.0.113.7", wantAddr: "0.0.0.0"},
+		{name: "trusted peer without header", trusted: append(proxies, testPeer), wantAddr: "0.0.0.0"},
+		{name: "trusted peer", trusted: append(proxies, testPeer), forwardedFor: "203.0.113.7", wantAddr: "203.0.113.7"},
+		{name: "chain of trusted proxies", trusted: append(proxies, testPeer), forwardedFor: "203.0.113.7, 10.0.0.2, 10.0.0.3",
+			wantAddr: "203.0.113.7"},
+		{name: "addresses set by the client are ignored", trusted: append(proxies, testPeer),
+			forwardedFor: "198.51.100.1, 10.0.0.9, 203.0.113.7, 10.0.0.2", wantAddr: "203.0.113.7"},
+		{name: "every hop trusted", trusted: append(proxies, testPeer), forwardedFor: "10.0.0.5, 10.0.0.2", wantAddr: "10.0.0.5"},
+		{name: "mapped IPv6 address", trusted: append(proxies, testPeer), forwardedFor: "::ffff:203.0.113.7", wantAddr: "203.0.113.7"},
+		{name: "IPv6 address", trusted: append(proxies, testPeer), forwardedFor: "2001:db8::7", wantAddr: "2001:db8::7"},
+		{name: "malformed entry set by the client", trusted: append(proxies, testPeer), forwardedFor: "garbage, 203.0.113.7",
+			wantAddr: "203.0.113.7"},
+		{name: "malformed last entry", trusted: append(proxies, testPeer), forwardedFor: "203.0.113.7, garbage", wantAddr: "0.0.0.0"},
+		{name: "malformed entry between proxies", trusted: append(proxies, testPeer), forwardedFor: "203.0.113.7, not-an-ip, 10.0.0.2",
+			wantAddr: "10.0.0.2"},
+		{name: "entry with a port", trusted: append(proxies, testPeer), forwardedFor: "203.0.113.7:4711", wantAddr: "0.0.0.0"},
+		{name: "empty entries", trusted: append(proxies, testPeer), forwardedFor: " , ", wantAddr: "0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resolved netip.Addr
+			app := fiber.New()
+			app.Use(ResolveClientIP(tt.trusted))
+			app.Get("/", func(c *fiber.Ctx) error {
+				resolved = ClientIP(c)
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.forwardedFor != "" {
+				req.Header.Set(fiber.HeaderXForwardedFor, tt.forwardedFor)
+			}
+			_, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, netip.MustParseAddr(tt.wantAddr), resolved)
+		})
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		allow, deny  []netip.Prefix
+		forwardedFor string
+		wantStatus   int
+	}{
+		{name: "no ranges", forwardedFor: "203.0.113.7", wantStatus: fiber.StatusNoContent},
+		{name: "allowed", allow: prefixes("203.0.113.0/24"), forwardedFor: "203.0.113.7", wantStatus: fiber.StatusNoContent},
+		{name: "not allowed", allow: prefixes("203.0.113.0/24"), forwardedFor: "198.51.100.1", wantStatus: fiber.StatusForbidden},
+		{name: "denied", deny: prefixes("198.51.100.0/24"), forwardedFor: "198.51.100.1", wantStatus: fiber.StatusForbidden},
+		{name: "not denied", deny: prefixes("198.51.100.0/24"), forwardedFor: "203.0.113.7", wantStatus: fiber.StatusNoContent},
+		{name: "deny takes precedence over allow", allow: prefixes("203.0.113.0/24"), deny: prefixes("203.0.113.7/32"),
+			forwardedFor: "203.0.113.7", wantStatus: fiber.StatusForbidden},
+		{name: "allowed outside the denied range", allow: prefixes("203.0.113.0/24"), deny: prefixes("203.0.113.7/32"),
+			forwardedFor: "203.0.113.8", wantStatus: fiber.StatusNoContent},
+		{name: "IPv6 denied", deny: prefixes("2001:db8::/32"), forwardedFor: "2001:db8::7", wantStatus: fiber.StatusForbidden},
+		{name: "mapped address matches IPv4 ranges", allow: prefixes("203.0.113.0/24"), forwardedFor: "::ffff:203.0.113.7",
+			wantStatus: fiber.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(ResolveClientIP([]netip.Prefix{testPeer}), IPFilter(tt.allow, tt.deny))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			req.Header.Set(fiber.HeaderXForwardedFor, tt.forwardedFor)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"slices"
+
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// serviceIdentityKey is the context key holding the identity of the calling service
+const serviceIdentityKey = "service_identity"
+
+// RequireClientCert creates middleware that rejects requests without a verified client
+// certificate, or whose service identity is not allowed when any identities are set. The
+// identity of the service is stored in the context, see ServiceIdentity.
+func RequireClientCert(identities []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		state := c.Context().TLSConnectionState()
+		if state == nil || len(state.VerifiedChains) == 0 {
+			return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
+				"error":   true,
+				"message": "Client certificate is required",
+			})
+		}
+
+		identity := certificateIdentity(state.VerifiedChains[0][0])
+		if identity == "" || (len(identities) > 0 && !slices.Contains(identities, identity)) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Client certificate is not allowed",
+			})
+		}
+
+		c.Locals(serviceIdentityKey, identity)
+
+		return c.Next()
+	}
+}
+
+// ServiceIdentity returns the identity of the service authenticated by RequireClientCert,
+// or an empty string when it did not run
+func ServiceIdentity(c *fiber.Ctx) string {
+	identity, _ := c.Locals(serviceIdentityKey).(string)
+	return identity
+}
+
+// certificateIdentity returns the service identity of a client certificate: its subject
+// common name, or its first DNS name when the subject has none
+func certificateIdentity(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return ""
+}
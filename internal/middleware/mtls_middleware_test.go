@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueCertificate issues a certificate signed by the parent, or a self-signed CA
+// certificate when parent is nil
+func issueCertificate(t *testing.T, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	ca := issueCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Internal CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	server := issueCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	client := func(name string) tls.Certificate {
+		return issueCertificate(t, &x509.Certificate{
+			Subject:     pkix.Name{CommonName: name},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, &ca)
+	}
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use("/internal", RequireClientCert([]string{"billing"}))
+	app.Get("/internal/whoami", func(c *fiber.Ctx) error {
+		return c.SendString(ServiceIdentity(c))
+	})
+	app.Get("/public", func(c *fiber.Ctx) error {
+		return c.SendString("public:" + ServiceIdentity(c))
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = app.Listener(tls.NewListener(ln, &tls.Config{
+			Certificates: []tls.Certificate{server},
+			ClientCAs:    pool,
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+		}))
+	}()
+	defer func() { _ = app.Shutdown() }()
+
+	get := func(path string, certs ...tls.Certificate) (int, string) {
+		httpClient := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certs},
+		}}
+		resp, err := httpClient.Get("https://" + ln.Addr().String() + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		return resp.StatusCode, string(body[:n])
+	}
+
+	// The identity of the service is its certificate's common name
+	status, body := get("/internal/whoami", client("billing"))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "billing", body)
+
+	status, _ = get("/internal/whoami")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = get("/internal/whoami", client("reporting"))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// Paths without the middleware do not require a certificate
+	status, body = get("/public")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "public:", body)
+}
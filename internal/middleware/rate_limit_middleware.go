@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"time"
+
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimit creates middleware that allows each client address at most limit requests per
+// window, and responds with 429 Too Many Requests, with Retry-After, to the requests over
+// it. Counts are kept in memory, per server. Zero disables the limit.
+func RateLimit(limit int, window time.Duration) fiber.Handler {
+	if limit <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return limiter.New(limiter.Config{
+		Max:        limit,
+		Expiration: window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return ClientIP(c).String()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return response.Send(c, fiber.StatusTooManyRequests, fiber.Map{
+				"error":   true,
+				"message": "Too many requests, try again later",
+			})
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	app := fiber.New()
+	app.Use(ResolveClientIP(nil))
+	ok := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	app.Get("/limited", RateLimit(2, time.Minute), ok)
+	app.Get("/unlimited", RateLimit(0, time.Minute), ok)
+
+	get := func(path string) int {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		require.NoError(t, err)
+		if resp.StatusCode == fiber.StatusTooManyRequests {
+			assert.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+		}
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, get("/limited"))
+	assert.Equal(t, fiber.StatusOK, get("/limited"))
+	assert.Equal(t, fiber.StatusTooManyRequests, get("/limited"))
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, fiber.StatusOK, get("/unlimited"))
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/response"
+	tenantService "todo-api/internal/service/tenant"
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Tenant creates middleware that resolves the caller's tenant from the token's tenant claim
+// and rejects requests for suspended tenants, storing the tenant in the context. When
+// baseDomain is set, requests to a tenant subdomain such as acme.<baseDomain> must carry
+// a token of that tenant. Tokens without a tenant claim belong to the default tenant.
+// It must run after AuthMiddleware.
+func Tenant(tenants tenantService.Service, baseDomain string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID := tenant.DefaultTenantID
+		if claims, ok := c.Locals("user_claims").(*utils.JWTClaims); ok && claims.TenantID != uuid.Nil {
+			tenantID = claims.TenantID
+		}
+
+		t, err := tenants.GetTenant(tenantID)
+		if err != nil {
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Tenant not found",
+			})
+		}
+
+		if slug := tenant.SlugFromHost(c.Hostname(), baseDomain); slug != "" && slug != t.Slug {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Token is not valid for this tenant",
+			})
+		}
+
+		if !t.IsActive() {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Tenant is suspended",
+			})
+		}
+
+		c.Locals("tenant", t)
+
+		return c.Next()
+	}
+}
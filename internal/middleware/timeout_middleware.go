@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// timeoutParentKey is the context key holding the request context before any timeout
+const timeoutParentKey = "timeout_parent"
+
+// Timeout creates middleware that cancels the request context after the timeout. Handlers are
+// not interrupted, but work that honors the request context, such as calls to integrations,
+// stops at the deadline: handlers giving up return the context's error, which is answered
+// with 504 Gateway Timeout. Handlers that complete keep their response, however late, so
+// clients are not told to retry changes that were applied. A timeout of a route replaces that
+// of its group, so slow routes can be given longer. Zero disables the timeout.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parent, ok := c.Locals(timeoutParentKey).(context.Context)
+		if !ok {
+			parent = c.UserContext()
+			c.Locals(timeoutParentKey, parent)
+		}
+		if timeout <= 0 {
+			c.SetUserContext(parent)
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		// A timeout set by a route replaces this one, and reports its own expiry
+		if c.UserContext() != ctx || !timedOut(ctx, err) {
+			return err
+		}
+		// A response the handler already wrote is kept rather than replaced
+		if committed(c) {
+			return nil
+		}
+
+		return response.Send(c, fiber.StatusGatewayTimeout, fiber.Map{
+			"error":   true,
+			"message": "Request timed out",
+		})
+	}
+}
+
+// timedOut reports whether a handler gave up because the request context reached its deadline
+func timedOut(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// committed reports whether the handler already wrote a response
+func committed(c *fiber.Ctx) bool {
+	return c.Response().IsBodyStream() || len(c.Response().Body()) > 0
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout(t *testing.T) {
+	app := fiber.New()
+	api := app.Group("/api", Timeout(20*time.Millisecond))
+
+	// waitFor waits for the request context to be done, at most the duration, giving up with
+	// the context's error
+	waitFor := func(d time.Duration) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			select {
+			case <-c.UserContext().Done():
+				return c.UserContext().Err()
+			case <-time.After(d):
+			}
+			return c.SendString("done")
+		}
+	}
+	api.Get("/fast", waitFor(0))
+	api.Get("/slow", waitFor(time.Second))
+	api.Get("/export", Timeout(200*time.Millisecond), waitFor(50*time.Millisecond))
+	api.Get("/unbounded", Timeout(0), waitFor(50*time.Millisecond))
+	// Handlers ignoring the deadline keep their response, as their changes were applied
+	api.Get("/late", func(c *fiber.Ctx) error {
+		time.Sleep(50 * time.Millisecond)
+		return c.Status(fiber.StatusCreated).SendString("created")
+	})
+	// Responses written before giving up are not replaced
+	api.Get("/written", func(c *fiber.Ctx) error {
+		c.Set("X-Partial", "true")
+		if err := c.SendString("partial"); err != nil {
+			return err
+		}
+		<-c.UserContext().Done()
+		return c.UserContext().Err()
+	})
+	// Other errors are left to the error handler
+	api.Get("/failed", func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return fiber.ErrBadGateway
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/api/fast", fiber.StatusOK, "done"},
+		{"/api/slow", fiber.StatusGatewayTimeout, `{"error":true,"message":"Request timed out"}`},
+		{"/api/export", fiber.StatusOK, "done"},
+		{"/api/unbounded", fiber.StatusOK, "done"},
+		{"/api/late", fiber.StatusCreated, "created"},
+		{"/api/written", fiber.StatusOK, "partial"},
+		{"/api/failed", fiber.StatusBadGateway, "Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil), -1)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"todo-api/internal/metrics"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestTiming breaks down the time taken by a request
+type requestTiming struct {
+	start        time.Time
+	handlerStart time.Time // when the route handler started, zero if none ran
+	handlerEnd   time.Time
+	encoding     time.Duration // spent encoding responses
+}
+
+// AddEncoding adds time spent encoding a response
+func (t *requestTiming) AddEncoding(d time.Duration) {
+	t.encoding += d
+}
+
+// RequestTiming creates middleware observing the duration of every request in the histogram,
+// by method and route, and logging requests taking the threshold or longer with the time spent
+// in middleware, in the route handler, and encoding the response. The handler time includes
+// the services and the storage they read and write. Route handlers are only told apart from
+// middleware once wrapped by TimeHandlers. A zero threshold disables logging.
+func RequestTiming(threshold time.Duration, durations *metrics.HistogramVec) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		timing := &requestTiming{start: time.Now()}
+		c.Locals(response.TimingKey, timing)
+
+		err := c.Next()
+
+		elapsed := time.Since(timing.start)
+		route := c.Route().Path
+		durations.Observe(elapsed.Seconds(), c.Method(), route)
+
+		if threshold > 0 && elapsed >= threshold {
+			status := c.Response().StatusCode()
+			if err != nil {
+				status = fiber.StatusInternalServerError
+				var e *fiber.Error
+				if errors.As(err, &e) {
+					status = e.Code
+				}
+			}
+
+			var handler time.Duration
+			if !timing.handlerStart.IsZero() {
+				handler = timing.handlerEnd.Sub(timing.handlerStart) - timing.encoding
+			}
+			log.Printf("Slow request: method=%s path=%s route=%s status=%d duration=%s middleware=%s handler=%s encoding=%s",
+				c.Method(), c.Path(), route, status, elapsed, elapsed-handler-timing.encoding, handler, timing.encoding)
+		}
+		return err
+	}
+}
+
+// TimeHandlers wraps the route handler of every route registered on the app, the last of its
+// handlers, so RequestTiming tells the time spent in it from that spent in middleware. It is
+// called once every route is registered.
+func TimeHandlers(app *fiber.App) {
+	// Routes share their handlers with the routes registered along with them, such as the
+	// HEAD route of a GET route, so each handler is wrapped once
+	wrapped := make(map[*fiber.Handler]bool)
+	for _, route := range app.GetRoutes(true) {
+		handler := &route.Handlers[len(route.Handlers)-1]
+		if wrapped[handler] {
+			continue
+		}
+		wrapped[handler] = true
+		*handler = timeHandler(*handler)
+	}
+}
+
+// timeHandler records when the route handler starts and ends in the timing of the request
+func timeHandler(handler fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		timing, ok := c.Locals(response.TimingKey).(*requestTiming)
+		if !ok {
+			return handler(c)
+		}
+
+		timing.handlerStart = time.Now()
+		err := handler(c)
+		timing.handlerEnd = time.Now()
+		return err
+	}
+}
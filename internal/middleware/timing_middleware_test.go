@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"todo-api/internal/metrics"
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestTiming(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	durations := metrics.NewRegistry().NewHistogramVec("http_request_duration_seconds", "Request durations.",
+		metrics.DefaultBuckets, "method", "route")
+	app := fiber.New()
+	app.Use(RequestTiming(20*time.Millisecond, durations))
+
+	slowMiddleware := func(c *fiber.Ctx) error {
+		time.Sleep(30 * time.Millisecond)
+		return c.Next()
+	}
+	app.Get("/tasks/:id", func(c *fiber.Ctx) error {
+		return response.Send(c, fiber.StatusOK, fiber.Map{"id": c.Params("id")})
+	})
+	app.Get("/slow", slowMiddleware, func(c *fiber.Ctx) error {
+		time.Sleep(30 * time.Millisecond)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	TimeHandlers(app)
+
+	for _, path := range []string{"/tasks/1", "/tasks/2", "/slow"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// Requests are observed by route rather than path
+	assert.Equal(t, uint64(2), durations.Count(fiber.MethodGet, "/tasks/:id"))
+	assert.Equal(t, uint64(1), durations.Count(fiber.MethodGet, "/slow"))
+
+	// Only the slow request is logged, its time split between the middleware and the handler
+	output := logs.String()
+	assert.NotContains(t, output, "/tasks/")
+	require.Contains(t, output, "Slow request: method=GET path=/slow route=/slow status=204 duration=")
+	var middleware, handler time.Duration
+	for _, field := range bytes.Fields(logs.Bytes()) {
+		if value, ok := bytes.CutPrefix(field, []byte("middleware=")); ok {
+			middleware, _ = time.ParseDuration(string(value))
+		}
+		if value, ok := bytes.CutPrefix(field, []byte("handler=")); ok {
+			handler, _ = time.ParseDuration(string(value))
+		}
+	}
+	assert.GreaterOrEqual(t, middleware, 30*time.Millisecond)
+	assert.GreaterOrEqual(t, handler, 30*time.Millisecond)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"todo-api/internal/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIVersion creates middleware that tags requests with the API version of their route group,
+// selecting the response format of that version
+func APIVersion(version string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(response.VersionKey, version)
+		return c.Next()
+	}
+}
+
+// Deprecated creates middleware that marks responses as coming from a deprecated API version
+// and links to its successor, e.g. "/api/v2"
+func Deprecated(successor string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", "true")
+		c.Append(fiber.HeaderLink, "<"+successor+`>; rel="successor-version"`)
+		return c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/response"
+	authService "todo-api/internal/service/auth"
+	"todo-api/pkg/utils"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebSocketAuthMiddleware creates middleware that authenticates WebSocket upgrade requests.
+// Browsers cannot set headers on WebSocket handshakes, so the token may also be passed
+// in the `token` query parameter.
+func WebSocketAuthMiddleware(authSvc authService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return response.Send(c, fiber.StatusUpgradeRequired, fiber.Map{
+				"error":   true,
+				"message": "WebSocket upgrade required",
+			})
+		}
+
+		// Extract token from Authorization header or query string
+		token, err := utils.ExtractTokenFromHeader(c.Get("Authorization"))
+		if err != nil {
+			token = c.Query("token")
+		}
+		if token == "" {
+			return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
+				"error":   true,
+				"message": "Authorization header is required",
+			})
+		}
+
+		// Validate token, refresh tokens are only good for refreshing
+		claims, err := authSvc.ValidateToken(token)
+		if err != nil || !claims.IsAccessToken() {
+			return response.Send(c, fiber.StatusUnauthorized, fiber.Map{
+				"error":   true,
+				"message": "Invalid or expired token",
+			})
+		}
+
+		if !claims.HasScope(auth.ScopeTasksRead) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Insufficient scope: " + auth.ScopeTasksRead + " required",
+			})
+		}
+
+		// Store user information in context
+		c.Locals("user_id", claims.UserID)
+		c.Locals("user_email", claims.Email)
+		c.Locals("user_claims", claims)
+
+		return c.Next()
+	}
+}
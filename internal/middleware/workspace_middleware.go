@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/response"
+	workspaceService "todo-api/internal/service/workspace"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// WorkspaceMember creates middleware that resolves the caller's membership in the workspace
+// of the :wid route parameter, storing the workspace ID and member in the context.
+// It must run after AuthMiddleware.
+func WorkspaceMember(workspaces workspaceService.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		workspaceID, err := uuid.Parse(c.Params("wid"))
+		if err != nil {
+			return response.Send(c, fiber.StatusBadRequest, fiber.Map{
+				"error":   true,
+				"message": "Invalid workspace ID",
+			})
+		}
+
+		userID := c.Locals("user_id").(uuid.UUID)
+
+		member, err := workspaces.GetMember(workspaceID, userID)
+		if err != nil {
+			// Non-members cannot tell whether a workspace exists
+			return response.Send(c, fiber.StatusNotFound, fiber.Map{
+				"error":   true,
+				"message": "Workspace not found",
+			})
+		}
+
+		c.Locals("workspace_id", workspaceID)
+		c.Locals("workspace_member", member)
+
+		return c.Next()
+	}
+}
+
+// RequireWorkspaceRole creates middleware that rejects workspace members below the given role.
+// It must run after WorkspaceMember.
+func RequireWorkspaceRole(role workspace.Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		member, ok := c.Locals("workspace_member").(*workspace.Member)
+		if !ok || !member.Role.AtLeast(role) {
+			return response.Send(c, fiber.StatusForbidden, fiber.Map{
+				"error":   true,
+				"message": "Workspace " + string(role) + " role required",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,7 @@
+// Package mocks holds testify mocks of the service interfaces, for handler tests. The mocks are
+// generated by mockery from .mockery.yaml and must be regenerated with
+// `go generate ./internal/mocks` when the interfaces they implement change. The mockery version
+// is pinned by the separate module in tools, so its dependencies stay out of the API module.
+package mocks
+
+//go:generate go run -C ../.. -modfile=internal/mocks/tools/go.mod github.com/vektra/mockery/v2
@@ -0,0 +1,12 @@
+package mocks
+
+import (
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+)
+
+// The mocks must be regenerated when the interfaces they implement change
+var (
+	_ authService.Service = (*AuthService)(nil)
+	_ taskService.Service = (*TaskService)(nil)
+)
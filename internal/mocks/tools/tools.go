@@ -0,0 +1,7 @@
+//go:build tools
+
+// Package tools pins the version of mockery the mocks are generated with, apart from the
+// dependencies of the API.
+package tools
+
+import _ "github.com/vektra/mockery/v2"
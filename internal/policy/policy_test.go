@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforcer_Enforce(t *testing.T) {
+	e, err := Parse(`
+# Editors inherit the rights of readers
+p, reader, document, read
+p, editor, document, update
+g, editor, reader
+g, reader, editor
+p, admin, document, *
+`)
+	require.NoError(t, err)
+
+	assert.True(t, e.Enforce([]string{"reader"}, "document", "read"))
+	assert.False(t, e.Enforce([]string{"reader"}, "folder", "read"))
+	assert.False(t, e.Enforce(nil, "document", "read"))
+
+	// Inheritance cycles are followed once
+	assert.True(t, e.Enforce([]string{"editor"}, "document", "read"))
+	assert.True(t, e.Enforce([]string{"reader"}, "document", "update"))
+	assert.False(t, e.Enforce([]string{"editor"}, "document", "delete"))
+
+	assert.True(t, e.Enforce([]string{"guest", "admin"}, "document", "delete"))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse("p, reader, document")
+	assert.EqualError(t, err, `line 1: expected "p, role, resource, action" or "g, role, role"`)
+
+	_, err = Parse("\ng, editor, ")
+	assert.EqualError(t, err, "line 2: empty field")
+}
+
+func TestDefault(t *testing.T) {
+	e := Default()
+
+	assert.True(t, e.Enforce([]string{"viewer"}, "task", "read"))
+	assert.False(t, e.Enforce([]string{"viewer"}, "task", "update"))
+	assert.True(t, e.Enforce([]string{"assignee"}, "task", "update"))
+	assert.False(t, e.Enforce([]string{"assignee"}, "task", "share"))
+	assert.True(t, e.Enforce([]string{"owner"}, "task", "delete"))
+	assert.True(t, e.Enforce([]string{"workspace_member"}, "task", "update"))
+	assert.False(t, e.Enforce([]string{"workspace_member"}, "task", "delete"))
+	assert.True(t, e.Enforce([]string{"workspace_owner"}, "task", "share"))
+}
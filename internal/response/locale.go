@@ -0,0 +1,37 @@
+package response
+
+import (
+	"todo-api/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// localize translates the message of a response envelope into the language negotiated from
+// the request's Accept-Language header, announced in the Content-Language header
+func localize(c *fiber.Ctx, body interface{}) interface{} {
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	envelope, ok := body.(fiber.Map)
+	if !ok {
+		return body
+	}
+	message, ok := envelope["message"].(string)
+	if !ok {
+		return body
+	}
+
+	lang := i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+	c.Set(fiber.HeaderContentLanguage, lang.String())
+
+	translated := i18n.Translate(lang, message)
+	if translated == message {
+		return body
+	}
+	// The envelope may be shared with other responses, so it is copied rather than changed
+	localized := make(fiber.Map, len(envelope))
+	for key, value := range envelope {
+		localized[key] = value
+	}
+	localized["message"] = translated
+	return localized
+}
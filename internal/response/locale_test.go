@@ -0,0 +1,46 @@
+package response
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend_Localized(t *testing.T) {
+	body := fiber.Map{"error": true, "message": "title is required"}
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return Send(c, fiber.StatusBadRequest, body)
+	})
+
+	tests := []struct {
+		acceptLanguage string
+		contentLang    string
+		body           string
+	}{
+		{"", "en", `{"error":true,"message":"title is required"}`},
+		{"id-ID,id;q=0.9", "id", `{"error":true,"message":"judul wajib diisi"}`},
+		{"de", "en", `{"error":true,"message":"title is required"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			data, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.contentLang, resp.Header.Get(fiber.HeaderContentLanguage))
+			assert.Equal(t, tt.body, string(data))
+		})
+	}
+
+	// The envelope passed in is not changed
+	assert.Equal(t, "title is required", body["message"])
+}
@@ -0,0 +1,103 @@
+package response
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// encodeMessagePack renders the body as MessagePack (https://msgpack.org).
+// Map keys are sorted for stable output.
+func encodeMessagePack(body interface{}) ([]byte, error) {
+	value, err := toGeneric(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendMessagePack(nil, value)
+}
+
+func appendMessagePack(b []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendMessagePackInt(b, i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, 0xcb)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(f)), nil
+	case string:
+		b = appendMessagePackHeader(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		return append(b, v...), nil
+	case []interface{}:
+		b = appendMessagePackHeader(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			var err error
+			if b, err = appendMessagePack(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		b = appendMessagePackHeader(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			var err error
+			if b, err = appendMessagePack(b, key); err != nil {
+				return nil, err
+			}
+			if b, err = appendMessagePack(b, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type %T", value)
+	}
+}
+
+// appendMessagePackInt uses a fixint when the value fits and int64 otherwise
+func appendMessagePackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= 127:
+		return append(b, byte(i))
+	case i >= -32 && i < 0:
+		return append(b, byte(int8(i)))
+	default:
+		b = append(b, 0xd3)
+		return binary.BigEndian.AppendUint64(b, uint64(i))
+	}
+}
+
+// appendMessagePackHeader writes a length prefix using the fix, 8-bit (if available), 16-bit, or 32-bit form
+func appendMessagePackHeader(b []byte, n int, fix byte, fixLimit int, code8, code16, code32 byte) []byte {
+	switch {
+	case n < fixLimit:
+		return append(b, fix|byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		return append(b, code8, byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, code16)
+		return binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, code32)
+		return binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+}
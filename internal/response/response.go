@@ -0,0 +1,94 @@
+// Package response encodes handler responses in the format negotiated from the Accept header.
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Supported response media types
+const (
+	MIMEJSON        = fiber.MIMEApplicationJSON
+	MIMEXML         = fiber.MIMEApplicationXML
+	MIMEMessagePack = "application/msgpack"
+)
+
+// mimeMessagePackLegacy is the unregistered media type still sent by many MessagePack clients
+const mimeMessagePackLegacy = "application/x-msgpack"
+
+// TimingKey is the context key of the timing of the request, if it is timed. The time taken
+// to encode responses is added to it.
+const TimingKey = "request_timing"
+
+// encodingTimer is implemented by request timings
+type encodingTimer interface {
+	AddEncoding(d time.Duration)
+}
+
+// Send writes the body with the given status code in the negotiated format
+func Send(c *fiber.Ctx, status int, body interface{}) error {
+	c.Status(status)
+
+	start := time.Now()
+	data, contentType, err := Encode(c, body)
+	if timer, ok := c.Locals(TimingKey).(encodingTimer); ok {
+		timer.AddEncoding(time.Since(start))
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(data)
+}
+
+// TimedOut reports whether a handler failed because the request context reached its deadline.
+// Handlers return the context's error rather than responding, for the timeout middleware to
+// answer 504 Gateway Timeout.
+func TimedOut(c *fiber.Ctx, err error) bool {
+	return err != nil && errors.Is(c.UserContext().Err(), context.DeadlineExceeded)
+}
+
+// Encode encodes the body in the format negotiated from the request's Accept header,
+// adapted to the request's API version. JSON is used when the header is missing or
+// names no supported format. The response status must be set before encoding.
+func Encode(c *fiber.Ctx, body interface{}) ([]byte, string, error) {
+	c.Vary(fiber.HeaderAccept)
+	body = localize(c, body)
+	body = adapt(c, body)
+
+	switch c.Accepts(MIMEJSON, MIMEXML, MIMEMessagePack, mimeMessagePackLegacy) {
+	case MIMEXML:
+		data, err := encodeXML(body)
+		return data, MIMEXML + "; charset=utf-8", err
+	case MIMEMessagePack, mimeMessagePackLegacy:
+		data, err := encodeMessagePack(body)
+		return data, MIMEMessagePack, err
+	default:
+		data, err := json.Marshal(body)
+		return data, MIMEJSON, err
+	}
+}
+
+// toGeneric converts a value into maps, slices, and scalars through its JSON form,
+// so every format shares the field names and omission rules of the JSON tags
+func toGeneric(body interface{}) (interface{}, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
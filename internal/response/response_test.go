@@ -0,0 +1,123 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend_Negotiation(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return Send(c, fiber.StatusCreated, fiber.Map{
+			"error":   false,
+			"message": "ok",
+		})
+	})
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"no accept header", "", MIMEJSON, `{"error":false,"message":"ok"}`},
+		{"json", "application/json", MIMEJSON, `{"error":false,"message":"ok"}`},
+		{"unsupported falls back to json", "text/html", MIMEJSON, `{"error":false,"message":"ok"}`},
+		{"xml", "application/xml", "application/xml; charset=utf-8", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<response><error>false</error><message>ok</message></response>"},
+		{"msgpack", "application/msgpack", MIMEMessagePack, "\x82\xa5error\xc2\xa7message\xa2ok"},
+		{"legacy msgpack", "application/x-msgpack", MIMEMessagePack, "\x82\xa5error\xc2\xa7message\xa2ok"},
+		{"quality values", "application/json;q=0.5, application/xml", "application/xml; charset=utf-8", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<response><error>false</error><message>ok</message></response>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+			assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			assert.Equal(t, "Accept, Accept-Language", resp.Header.Get("Vary"))
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	body := fiber.Map{
+		"data": []fiber.Map{
+			{"title": "Fish & <chips>", "due_date": nil},
+		},
+		"meta":       fiber.Map{"total": 1, "in progress": true},
+		"ratio":      0.5,
+		"unexported": struct{ Name string }{"task"},
+	}
+
+	data, err := encodeXML(body)
+
+	require.NoError(t, err)
+	assert.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<response>"+
+		"<data><item><due_date></due_date><title>Fish &amp; &lt;chips&gt;</title></item></data>"+
+		"<meta><item key=\"in progress\">true</item><total>1</total></meta>"+
+		"<ratio>0.5</ratio>"+
+		"<unexported><Name>task</Name></unexported>"+
+		"</response>", string(data))
+}
+
+func TestEncodeMessagePack(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"true", true, []byte{0xc3}},
+		{"positive fixint", 7, []byte{0x07}},
+		{"negative fixint", -3, []byte{0xfd}},
+		{"int64", 300, []byte{0xd3, 0, 0, 0, 0, 0, 0, 0x01, 0x2c}},
+		{"float64", 1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "hi", []byte{0xa2, 'h', 'i'}},
+		{"fixarray", []int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{"sorted fixmap", map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := encodeMessagePack(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func TestEncodeMessagePack_LengthPrefixes(t *testing.T) {
+	long := make([]byte, 300)
+	for i := range long {
+		long[i] = 'x'
+	}
+
+	data, err := encodeMessagePack(string(long[:40]))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xd9, 40}, data[:2])
+
+	data, err = encodeMessagePack(string(long))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xda, 0x01, 0x2c}, data[:3])
+
+	data, err = encodeMessagePack(make([]int, 20))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xdc, 0x00, 0x14}, data[:3])
+}
@@ -0,0 +1,74 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VersionKey is the context key holding the API version of the current request
+const VersionKey = "api_version"
+
+// API versions
+const (
+	V1 = "v1"
+	V2 = "v2"
+)
+
+// Adapter converts a response body in the v1 envelope into another version's format
+type Adapter func(status int, body interface{}) interface{}
+
+// adapters holds the body adapters of versions whose format differs from v1
+var adapters = map[string]Adapter{
+	V2: adaptV2,
+}
+
+// adapt applies the adapter of the request's API version, if any
+func adapt(c *fiber.Ctx, body interface{}) interface{} {
+	version, _ := c.Locals(VersionKey).(string)
+	adapter, ok := adapters[version]
+	if !ok {
+		return body
+	}
+	return adapter(c.Response().StatusCode(), body)
+}
+
+// adaptV2 replaces the boolean error flag with a structured error object:
+//
+//	{"error": {"status": 404, "code": "not_found", "message": "Task not found"}}
+//
+// Successful responses drop the error flag and keep their message, data, and meta.
+func adaptV2(status int, body interface{}) interface{} {
+	envelope, ok := body.(fiber.Map)
+	if !ok {
+		return body
+	}
+
+	if isError, _ := envelope["error"].(bool); isError {
+		return fiber.Map{
+			"error": fiber.Map{
+				"status":  status,
+				"code":    errorCode(status),
+				"message": envelope["message"],
+			},
+		}
+	}
+
+	adapted := make(fiber.Map, len(envelope))
+	for key, value := range envelope {
+		if key != "error" {
+			adapted[key] = value
+		}
+	}
+	return adapted
+}
+
+// errorCode derives a machine-readable code from an HTTP status, e.g. 404 -> "not_found"
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
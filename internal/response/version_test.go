@@ -0,0 +1,69 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupVersionedApp(version string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(VersionKey, version)
+		return c.Next()
+	})
+
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return Send(c, fiber.StatusOK, fiber.Map{
+			"error":   false,
+			"message": "Task retrieved successfully",
+			"data":    fiber.Map{"id": "1"},
+		})
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return Send(c, fiber.StatusNotFound, fiber.Map{
+			"error":   true,
+			"message": "Task not found",
+		})
+	})
+
+	return app
+}
+
+func TestSend_Versions(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		path     string
+		expected string
+	}{
+		{"v1 success", V1, "/ok", `{"data":{"id":"1"},"error":false,"message":"Task retrieved successfully"}`},
+		{"v1 error", V1, "/missing", `{"error":true,"message":"Task not found"}`},
+		{"v2 success", V2, "/ok", `{"data":{"id":"1"},"message":"Task retrieved successfully"}`},
+		{"v2 error", V2, "/missing", `{"error":{"code":"not_found","message":"Task not found","status":404}}`},
+		{"unversioned", "", "/missing", `{"error":true,"message":"Task not found"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := setupVersionedApp(tt.version).Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, "bad_request", errorCode(http.StatusBadRequest))
+	assert.Equal(t, "internal_server_error", errorCode(http.StatusInternalServerError))
+	assert.Equal(t, "error", errorCode(599))
+}
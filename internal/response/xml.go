@@ -0,0 +1,105 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"unicode"
+)
+
+// xmlRoot is the name of the document element wrapping every XML response
+const xmlRoot = "response"
+
+// xmlItem is the element name used for array entries and keys that are not valid XML names
+const xmlItem = "item"
+
+// encodeXML renders the body as XML. Objects become nested elements, array entries
+// become <item> elements, and keys are sorted for stable output.
+func encodeXML(body interface{}) ([]byte, error) {
+	value, err := toGeneric(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	if err := writeXMLElement(encoder, xml.StartElement{Name: xml.Name{Local: xmlRoot}}, value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeXMLElement(encoder *xml.Encoder, start xml.StartElement, value interface{}) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := xml.StartElement{Name: xml.Name{Local: key}}
+			if !isXMLName(key) {
+				child = xml.StartElement{
+					Name: xml.Name{Local: xmlItem},
+					Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+				}
+			}
+			if err := writeXMLElement(encoder, child, v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := writeXMLElement(encoder, xml.StartElement{Name: xml.Name{Local: xmlItem}}, item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := encoder.EncodeToken(xml.CharData(v)); err != nil {
+			return err
+		}
+	case json.Number:
+		if err := encoder.EncodeToken(xml.CharData(v.String())); err != nil {
+			return err
+		}
+	case bool:
+		if err := encoder.EncodeToken(xml.CharData(strconv.FormatBool(v))); err != nil {
+			return err
+		}
+	case nil:
+		// Null values are rendered as empty elements
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// isXMLName reports whether the key can be used as an element name as-is
+func isXMLName(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for i, r := range key {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
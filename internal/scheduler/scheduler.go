@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"todo-api/pkg/cron"
+)
+
+// Schedule decides when a job runs next
+type Schedule interface {
+	// Next returns the first run after the time, or the zero time if the job never runs again
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// interval runs a job at a fixed interval
+type interval time.Duration
+
+// Every returns a schedule running a job at the interval, the first time one interval after
+// the scheduler starts
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+func (i interval) Next(after time.Time) time.Time { return after.Add(time.Duration(i)) }
+func (i interval) String() string                 { return "@every " + time.Duration(i).String() }
+
+// Cron returns a schedule running a job at the times of the cron expression, in UTC
+func Cron(spec string) (Schedule, error) {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	return utc{schedule}, nil
+}
+
+// utc evaluates a cron schedule in UTC, whatever the local time zone of the server
+type utc struct {
+	*cron.Schedule
+}
+
+func (u utc) Next(after time.Time) time.Time { return u.Schedule.Next(after.UTC()) }
+
+// Func runs a scheduled job. The error, if any, is logged and recorded as the last failure
+// of the job; the job still runs at its next time.
+type Func func(ctx context.Context) error
+
+// Entry reports the state of a scheduled job
+type Entry struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	NextRun  *time.Time `json:"next_run,omitempty"` // nil while running, or when the job never runs again
+	Running  bool       `json:"running"`
+	Runs     int        `json:"runs"`
+	Failures int        `json:"failures"`
+	// LastRun is when the last run started, and LastDuration how long it took in milliseconds
+	LastRun      *time.Time `json:"last_run,omitempty"`
+	LastDuration int64      `json:"last_duration_ms"`
+	// LastError is why the last run failed, empty if it succeeded; LastFailure is when a run
+	// last failed
+	LastError   string     `json:"last_error,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+}
+
+// job is a scheduled job with its state
+type job struct {
+	schedule Schedule
+	run      Func
+	entry    Entry
+}
+
+// Scheduler runs recurring jobs, such as reminders and syncs, on their schedules and records
+// how their runs went. A job never overlaps itself: a run taking longer than the schedule
+// delays the next one.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []*job
+	started bool
+}
+
+// New creates a scheduler without jobs
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add schedules a job. Jobs are added before the scheduler runs; adding a job with the name
+// of another replaces it.
+func (s *Scheduler) Add(name string, schedule Schedule, run Func) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		panic(fmt.Sprintf("scheduler: job %s added after the scheduler started", name))
+	}
+
+	j := &job{schedule: schedule, run: run, entry: Entry{Name: name, Schedule: schedule.String()}}
+	for i, existing := range s.jobs {
+		if existing.entry.Name == name {
+			s.jobs[i] = j
+			return
+		}
+	}
+	s.jobs = append(s.jobs, j)
+}
+
+// Run runs the jobs on their schedules until the context is done, then waits for the runs
+// in progress, which are interrupted through their context, to return
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(len(jobs))
+	for _, j := range jobs {
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+// Entries returns the state of the scheduled jobs, in the order they were added
+func (s *Scheduler) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, len(s.jobs))
+	for i, j := range s.jobs {
+		entries[i] = j.entry
+	}
+	return entries
+}
+
+// loop runs the job at each of its times until the context is done
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		next := s.schedule(j, time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runJob(ctx, j)
+	}
+}
+
+// schedule records and returns the next run of the job after the time
+func (s *Scheduler) schedule(j *job, now time.Time) time.Time {
+	next := j.schedule.Next(now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.entry.NextRun = nil
+	if !next.IsZero() {
+		at := next.UTC()
+		j.entry.NextRun = &at
+	}
+	return next
+}
+
+// runJob runs the job once, recording its outcome. Panics are recovered and recorded as
+// failures so that the job runs again at its next time.
+func (s *Scheduler) runJob(ctx context.Context, j *job) {
+	startedAt := time.Now().UTC()
+	s.mu.Lock()
+	j.entry.Running = true
+	j.entry.NextRun = nil
+	j.entry.LastRun = &startedAt
+	s.mu.Unlock()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return j.run(ctx)
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.entry.Running = false
+	j.entry.Runs++
+	j.entry.LastDuration = time.Since(startedAt).Milliseconds()
+	j.entry.LastError = ""
+	if err != nil {
+		failedAt := time.Now().UTC()
+		j.entry.Failures++
+		j.entry.LastError = err.Error()
+		j.entry.LastFailure = &failedAt
+		if ctx.Err() == nil {
+			log.Printf("Scheduled job %s failed: %v", j.entry.Name, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// start runs the scheduler until the test ends
+func start(t *testing.T, s *Scheduler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestScheduler_RunsJobs(t *testing.T) {
+	s := New()
+	var syncs, reminders atomic.Int32
+	s.Add("github sync", Every(5*time.Millisecond), func(ctx context.Context) error {
+		syncs.Add(1)
+		return nil
+	})
+	s.Add("reminders", Every(5*time.Millisecond), func(ctx context.Context) error {
+		if reminders.Add(1) == 1 {
+			return errors.New("mail server down")
+		}
+		return nil
+	})
+	start(t, s)
+
+	require.Eventually(t, func() bool { return syncs.Load() >= 2 && reminders.Load() >= 2 }, time.Second, time.Millisecond)
+
+	entries := s.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "github sync", entries[0].Name)
+	assert.Equal(t, "@every 5ms", entries[0].Schedule)
+	assert.NotNil(t, entries[0].LastRun)
+	assert.Zero(t, entries[0].Failures)
+
+	// Failures are kept after later runs succeed
+	assert.Equal(t, "reminders", entries[1].Name)
+	assert.Equal(t, 1, entries[1].Failures)
+	assert.NotNil(t, entries[1].LastFailure)
+}
+
+func TestScheduler_RecordsFailures(t *testing.T) {
+	s := New()
+	s.Add("digests", Every(time.Millisecond), func(ctx context.Context) error {
+		panic("nil map")
+	})
+	start(t, s)
+
+	require.Eventually(t, func() bool { return s.Entries()[0].Failures >= 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, "panic: nil map", s.Entries()[0].LastError)
+}
+
+func TestScheduler_NextRun(t *testing.T) {
+	s := New()
+	s.Add("digests", Every(time.Hour), func(ctx context.Context) error { return nil })
+	s.Add("sync", Every(time.Hour), func(ctx context.Context) error { return nil })
+	s.Add("sync", Every(2*time.Hour), func(ctx context.Context) error { return nil })
+
+	entries := s.Entries()
+	require.Len(t, entries, 2)
+	assert.Nil(t, entries[0].NextRun)
+	assert.Equal(t, "@every 2h0m0s", entries[1].Schedule)
+
+	start(t, s)
+	require.Eventually(t, func() bool { return s.Entries()[1].NextRun != nil }, time.Second, time.Millisecond)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), *s.Entries()[1].NextRun, time.Minute)
+	assert.Nil(t, s.Entries()[1].LastRun)
+	assert.Panics(t, func() { s.Add("late", Every(time.Hour), nil) })
+}
+
+func TestScheduler_StopsRunningJobs(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	s.Add("sync", Every(time.Millisecond), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	assert.Equal(t, context.Canceled.Error(), s.Entries()[0].LastError)
+}
+
+func TestCron(t *testing.T) {
+	schedule, err := Cron("0 8 * * *")
+	require.NoError(t, err)
+	assert.Equal(t, "0 8 * * *", schedule.String())
+
+	// Times are in UTC, whatever the zone of the time given
+	tokyo := time.FixedZone("JST", 9*60*60)
+	next := schedule.Next(time.Date(2024, 1, 12, 10, 0, 0, 0, tokyo))
+	assert.Equal(t, time.Date(2024, 1, 12, 8, 0, 0, 0, time.UTC), next)
+
+	_, err = Cron("0 25 * * *")
+	assert.Error(t, err)
+}
@@ -0,0 +1,167 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ElasticsearchMaxHits is the maximum number of hits fetched from Elasticsearch for a query
+const ElasticsearchMaxHits = 1000
+
+// ElasticsearchConfig configures an Elasticsearch or OpenSearch index
+type ElasticsearchConfig struct {
+	URL      string // base URL of the cluster, e.g. http://localhost:9200
+	Index    string // name of the index holding the documents
+	Username string // optional basic auth credentials
+	Password string
+	Timeout  time.Duration
+}
+
+// elasticsearchIndex implements an index stored in Elasticsearch or OpenSearch through their
+// shared REST API
+type elasticsearchIndex struct {
+	cfg     ElasticsearchConfig
+	fields  []string // searched fields with their boosts, e.g. "title^3"
+	client  *http.Client
+	baseURL string
+}
+
+// NewElasticsearchIndex creates an index backed by an Elasticsearch or OpenSearch cluster.
+// Fields are boosted by their weight, as with the in-memory index.
+func NewElasticsearchIndex(cfg ElasticsearchConfig, weights map[string]float64) Index {
+	fields := make([]string, 0, len(weights))
+	for field, weight := range weights {
+		fields = append(fields, field+"^"+strconv.FormatFloat(weight, 'f', -1, 64))
+	}
+	sort.Strings(fields)
+
+	return &elasticsearchIndex{
+		cfg:     cfg,
+		fields:  fields,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		baseURL: strings.TrimSuffix(cfg.URL, "/") + "/" + url.PathEscape(cfg.Index),
+	}
+}
+
+// Index adds the document, replacing any document with the same ID
+func (idx *elasticsearchIndex) Index(doc Document) error {
+	return idx.do(http.MethodPut, "/_doc/"+doc.ID.String(), doc.Fields, nil)
+}
+
+// Remove deletes the document with the ID, if indexed
+func (idx *elasticsearchIndex) Remove(id uuid.UUID) error {
+	err := idx.do(http.MethodDelete, "/_doc/"+id.String(), nil, nil)
+	var statusErr *elasticsearchError
+	if errors.As(err, &statusErr) && statusErr.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Search returns the documents matching every term of the query, best match first.
+// The last term of the query also matches as a prefix of indexed terms.
+func (idx *elasticsearchIndex) Search(query string) ([]Hit, error) {
+	// Stop words are dropped here so both backends agree on what matches
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	request := map[string]interface{}{
+		"size":    ElasticsearchMaxHits,
+		"_source": false,
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":    strings.Join(terms, " "),
+				"type":     "bool_prefix",
+				"fields":   idx.fields,
+				"operator": "and",
+			},
+		},
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				ID    string  `json:"_id"`
+				Score float64 `json:"_score"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := idx.do(http.MethodPost, "/_search", request, &result); err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		id, err := uuid.Parse(hit.ID)
+		if err != nil {
+			// Not one of our documents
+			continue
+		}
+		hits = append(hits, Hit{ID: id, Score: hit.Score})
+	}
+
+	return hits, nil
+}
+
+// elasticsearchError is returned when the cluster responds with an error status
+type elasticsearchError struct {
+	Status int
+	Body   string
+}
+
+func (e *elasticsearchError) Error() string {
+	return fmt.Sprintf("elasticsearch responded with status %d: %s", e.Status, e.Body)
+}
+
+// do sends a request with an optional JSON body to a path of the index, decoding the
+// JSON response into out when set
+func (idx *elasticsearchIndex) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, idx.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idx.cfg.Username != "" {
+		req.SetBasicAuth(idx.cfg.Username, idx.cfg.Password)
+	}
+
+	resp, err := idx.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &elasticsearchError{Status: resp.StatusCode, Body: strings.TrimSpace(string(message))}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
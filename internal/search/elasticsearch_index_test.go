@@ -0,0 +1,71 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticsearchIndex(t *testing.T) {
+	doc := newDoc("Write documentation", "Cover the deployment guide")
+
+	var searchRequest map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "elastic", user)
+		assert.Equal(t, "secret", password)
+
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/tasks/_doc/"+doc.ID.String():
+			var fields map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&fields))
+			assert.Equal(t, "Write documentation", fields["title"])
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete && r.URL.Path == "/tasks/_doc/"+doc.ID.String():
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost && r.URL.Path == "/tasks/_search":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&searchRequest))
+			_, _ = w.Write([]byte(`{"hits":{"hits":[{"_id":"` + doc.ID.String() + `","_score":2.5},{"_id":"not-a-uuid","_score":1}]}}`))
+		default:
+			http.Error(w, `{"error":"unexpected request"}`, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	idx := NewElasticsearchIndex(ElasticsearchConfig{URL: server.URL + "/", Index: "tasks", Username: "elastic", Password: "secret"},
+		map[string]float64{"title": 3, "description": 1})
+
+	require.NoError(t, idx.Index(doc))
+
+	hits, err := idx.Search("the deploy")
+	require.NoError(t, err)
+	assert.Equal(t, []Hit{{ID: doc.ID, Score: 2.5}}, hits)
+
+	// Stop words are dropped and every term must match, the last one as a prefix
+	match := searchRequest["query"].(map[string]interface{})["multi_match"].(map[string]interface{})
+	assert.Equal(t, "deploy", match["query"])
+	assert.Equal(t, "bool_prefix", match["type"])
+	assert.Equal(t, "and", match["operator"])
+	assert.Equal(t, []interface{}{"description^1", "title^3"}, match["fields"])
+
+	// Removing a missing document is not an error
+	require.NoError(t, idx.Remove(doc.ID))
+	require.NoError(t, idx.Remove(uuid.New()))
+
+	// Blank queries do not reach the cluster
+	hits, err = idx.Search("the")
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// Cluster errors are reported
+	err = idx.Index(newDoc("Other", ""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "elasticsearch responded with status 400")
+}
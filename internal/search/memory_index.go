@@ -0,0 +1,155 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// prefixWeight scales the score of terms matched by prefix rather than exactly
+const prefixWeight = 0.5
+
+// memoryIndex implements an in-memory inverted index ranking matches by TF-IDF with per-field weights
+type memoryIndex struct {
+	mu       sync.RWMutex
+	weights  map[string]float64
+	postings map[string]map[uuid.UUID]float64 // term to weighted term frequency per document
+	docs     map[uuid.UUID][]string           // indexed terms per document, to remove them
+}
+
+// NewMemoryIndex creates an in-memory index. Matches in a field count as much as its weight;
+// fields without a weight count once.
+func NewMemoryIndex(weights map[string]float64) Index {
+	return &memoryIndex{
+		weights:  weights,
+		postings: make(map[string]map[uuid.UUID]float64),
+		docs:     make(map[uuid.UUID][]string),
+	}
+}
+
+// Index adds the document, replacing any document with the same ID
+func (idx *memoryIndex) Index(doc Document) error {
+	frequencies := make(map[string]float64)
+	for field, text := range doc.Fields {
+		weight, ok := idx.weights[field]
+		if !ok {
+			weight = 1
+		}
+		for _, term := range Tokenize(text) {
+			frequencies[term] += weight
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(doc.ID)
+	if len(frequencies) == 0 {
+		return nil
+	}
+
+	terms := make([]string, 0, len(frequencies))
+	for term, frequency := range frequencies {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[uuid.UUID]float64)
+		}
+		idx.postings[term][doc.ID] = frequency
+		terms = append(terms, term)
+	}
+	idx.docs[doc.ID] = terms
+	return nil
+}
+
+// Remove deletes the document with the ID, if indexed
+func (idx *memoryIndex) Remove(id uuid.UUID) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+	return nil
+}
+
+// remove deletes a document's postings. The caller must hold the write lock.
+func (idx *memoryIndex) remove(id uuid.UUID) {
+	for _, term := range idx.docs[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Search returns the documents matching every term of the query, best match first.
+// The last term of the query also matches as a prefix of indexed terms.
+func (idx *memoryIndex) Search(query string) ([]Hit, error) {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := idx.scoreTerm(terms[len(terms)-1], true)
+	for _, term := range terms[:len(terms)-1] {
+		termScores := idx.scoreTerm(term, false)
+
+		// Documents must match every term
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: math.Round(score*1000) / 1000})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID.String() < hits[j].ID.String()
+	})
+
+	return hits, nil
+}
+
+// scoreTerm scores the documents containing the term, or with prefix set any term starting
+// with it. The caller must hold the read lock.
+func (idx *memoryIndex) scoreTerm(term string, prefix bool) map[uuid.UUID]float64 {
+	scores := make(map[uuid.UUID]float64)
+	idx.addScores(scores, term, 1)
+
+	if prefix {
+		for indexed := range idx.postings {
+			if indexed != term && strings.HasPrefix(indexed, term) {
+				idx.addScores(scores, indexed, prefixWeight)
+			}
+		}
+	}
+
+	return scores
+}
+
+// addScores adds the TF-IDF score of a term, scaled by weight, to the documents containing it.
+// Only the best matching term of a query term counts for a document.
+func (idx *memoryIndex) addScores(scores map[uuid.UUID]float64, term string, weight float64) {
+	postings := idx.postings[term]
+	if len(postings) == 0 {
+		return
+	}
+
+	idf := math.Log(1 + float64(len(idx.docs))/float64(len(postings)))
+	for id, frequency := range postings {
+		scores[id] = max(scores[id], weight*frequency*idf)
+	}
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDoc(title, description string) Document {
+	return Document{ID: uuid.New(), Fields: map[string]string{"title": title, "description": description}}
+}
+
+func mustSearch(t *testing.T, idx Index, query string) []Hit {
+	hits, err := idx.Search(query)
+	require.NoError(t, err)
+	return hits
+}
+
+func ids(hits []Hit) []uuid.UUID {
+	result := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		result[i] = hit.ID
+	}
+	return result
+}
+
+func TestMemoryIndex_Search(t *testing.T) {
+	idx := NewMemoryIndex(map[string]float64{"title": 3})
+
+	docs := newDoc("Write documentation", "Cover the deployment guide")
+	deploy := newDoc("Deploy release", "Roll out to production")
+	meeting := newDoc("Plan meeting", "Agenda mentions the docs deployment")
+	for _, doc := range []Document{docs, deploy, meeting} {
+		require.NoError(t, idx.Index(doc))
+	}
+
+	// Every term must match, in any field
+	assert.Equal(t, []uuid.UUID{docs.ID}, ids(mustSearch(t, idx, "guide deployment")))
+	assert.Empty(t, mustSearch(t, idx, "guide production"))
+
+	// Title matches rank above description matches
+	hits := mustSearch(t, idx, "deploy")
+	require.Len(t, hits, 3)
+	assert.Equal(t, deploy.ID, hits[0].ID)
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	// The last term matches as a prefix, earlier terms only exactly
+	assert.ElementsMatch(t, []uuid.UUID{docs.ID, meeting.ID}, ids(mustSearch(t, idx, "doc")))
+	assert.Empty(t, mustSearch(t, idx, "doc guide"))
+	assert.Equal(t, []uuid.UUID{meeting.ID}, ids(mustSearch(t, idx, "the agenda of")))
+
+	assert.Empty(t, mustSearch(t, idx, ""))
+	assert.Empty(t, mustSearch(t, idx, "!!"))
+}
+
+func TestMemoryIndex_ReindexAndRemove(t *testing.T) {
+	idx := NewMemoryIndex(nil)
+
+	doc := newDoc("Buy groceries", "")
+	require.NoError(t, idx.Index(doc))
+	require.Len(t, mustSearch(t, idx, "groceries"), 1)
+
+	// Reindexing replaces the previous terms
+	doc.Fields["title"] = "Buy flowers"
+	require.NoError(t, idx.Index(doc))
+	assert.Empty(t, mustSearch(t, idx, "groceries"))
+	assert.Equal(t, []uuid.UUID{doc.ID}, ids(mustSearch(t, idx, "flowers")))
+
+	require.NoError(t, idx.Remove(doc.ID))
+	assert.Empty(t, mustSearch(t, idx, "flowers"))
+	assert.Empty(t, mustSearch(t, idx, "buy"))
+
+	// Removing an unknown document is a no-op
+	assert.NoError(t, idx.Remove(uuid.New()))
+}
@@ -0,0 +1,30 @@
+package search
+
+import (
+	"github.com/google/uuid"
+)
+
+// Document is a unit of text indexed for search, keyed by the ID of the entity it describes
+type Document struct {
+	ID     uuid.UUID
+	Fields map[string]string // field name to text, e.g. "title" or "description"
+}
+
+// Hit is a document matching a query, with its relevance score
+type Hit struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+// Index defines a full-text search index.
+// Backends such as Bleve can be plugged in by implementing this interface.
+type Index interface {
+	// Index adds the document, replacing any document with the same ID
+	Index(doc Document) error
+	// Remove deletes the document with the ID, if indexed
+	Remove(id uuid.UUID) error
+	// Search returns the documents matching every term of the query, best match first.
+	// The last term of the query also matches as a prefix, to support search as you type.
+	// Stop words in the query are ignored.
+	Search(query string) ([]Hit, error)
+}
@@ -0,0 +1,28 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are common English words too frequent to be useful search terms
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {}, "for": {},
+	"from": {}, "in": {}, "is": {}, "it": {}, "of": {}, "on": {}, "or": {}, "the": {}, "to": {}, "with": {},
+}
+
+// Tokenize splits text into lowercase terms on anything that is not a letter or digit,
+// dropping stop words
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := fields[:0]
+	for _, field := range fields {
+		if _, stop := stopWords[field]; !stop {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
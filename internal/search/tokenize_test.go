@@ -0,0 +1,13 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"review", "q3", "roadmap", "café"}, Tokenize("Review the Q3-roadmap, at the Café!"))
+	assert.Empty(t, Tokenize("  the and, of  "))
+	assert.Empty(t, Tokenize(""))
+}
@@ -0,0 +1,259 @@
+// Package seed loads fixture users and tasks from YAML or JSON files into storage, so
+// development and demo environments start with known data.
+package seed
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/tenant"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFixtures are the users and tasks storage starts with unless another fixture file is
+// configured
+//
+//go:embed testdata/fixtures.yaml
+var defaultFixtures []byte
+
+// DefaultFile is the path of the default fixtures from the repository root
+const DefaultFile = "internal/seed/testdata/fixtures.yaml"
+
+// Fixtures are the users and tasks storage is seeded with
+type Fixtures struct {
+	Users []User `json:"users" yaml:"users"`
+	Tasks []Task `json:"tasks" yaml:"tasks"`
+}
+
+// User is a fixture user. Users without an ID are given a random one, and users without a
+// tenant belong to the default tenant.
+type User struct {
+	ID       uuid.UUID `json:"id" yaml:"id"`
+	Email    string    `json:"email" yaml:"email"`
+	Password string    `json:"password" yaml:"password"`
+	Tenant   string    `json:"tenant" yaml:"tenant"` // slug of an existing tenant
+	Admin    bool      `json:"admin" yaml:"admin"`
+}
+
+// Task is a fixture task owned by the fixture user with the owner email. Tasks are pending
+// unless they have a status.
+type Task struct {
+	Owner       string            `json:"owner" yaml:"owner"`
+	Title       string            `json:"title" yaml:"title"`
+	Description string            `json:"description" yaml:"description"`
+	Status      task.TaskStatus   `json:"status" yaml:"status"`
+	Priority    task.TaskPriority `json:"priority" yaml:"priority"`
+	DueDate     *time.Time        `json:"due_date" yaml:"due_date"`
+}
+
+// UserDirectory finds users by email
+type UserDirectory interface {
+	GetUserByEmail(email string) (*auth.User, error)
+}
+
+// UserStore creates users
+type UserStore interface {
+	CreateUser(user *auth.User) error
+}
+
+// TenantDirectory finds the tenants users belong to
+type TenantDirectory interface {
+	GetTenantBySlug(slug string) (*tenant.Tenant, error)
+}
+
+// TaskStore creates tasks and changes their status
+type TaskStore interface {
+	CreateTask(req *task.CreateTaskRequest, userID uuid.UUID) (*task.Task, error)
+	UpdateTask(id uuid.UUID, req *task.UpdateTaskRequest, userID uuid.UUID) (*task.Task, error)
+}
+
+// Load reads and validates the fixtures of a YAML or JSON file, chosen by its extension.
+// Unknown keys are rejected so typos do not go unnoticed.
+func Load(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := parse(data, strings.ToLower(filepath.Ext(path)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Default returns the default fixtures, embedded from DefaultFile. Their tests keep them
+// valid, so it panics when they are not.
+func Default() *Fixtures {
+	f, err := parse(defaultFixtures, ".yaml")
+	if err != nil {
+		panic(fmt.Sprintf("invalid default fixtures: %v", err))
+	}
+	return f
+}
+
+// parse decodes and validates fixtures in the format of the file extension
+func parse(data []byte, ext string) (*Fixtures, error) {
+	var f Fixtures
+	var err error
+	switch ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&f)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&f)
+	default:
+		return nil, fmt.Errorf("unsupported fixtures format %q, expected .yaml, .yml, or .json", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate checks the fixtures, reporting every problem found
+func (f *Fixtures) Validate() error {
+	var errs []error
+	emails := make(map[string]bool, len(f.Users))
+	for i, u := range f.Users {
+		switch {
+		case !strings.Contains(u.Email, "@"):
+			errs = append(errs, fmt.Errorf("users[%d].email: %q is not an email address", i, u.Email))
+		case emails[u.Email]:
+			errs = append(errs, fmt.Errorf("users[%d].email: %s is used by another user", i, u.Email))
+		}
+		emails[u.Email] = true
+		if utf8.RuneCountInString(u.Password) < auth.MinPasswordLength {
+			errs = append(errs, fmt.Errorf("users[%d].password: must be at least %d characters", i, auth.MinPasswordLength))
+		}
+	}
+
+	statuses := []task.TaskStatus{task.StatusPending, task.StatusInProgress, task.StatusCompleted, task.StatusCancelled}
+	priorities := []task.TaskPriority{task.PriorityLow, task.PriorityMedium, task.PriorityHigh}
+	for i, t := range f.Tasks {
+		if !emails[t.Owner] {
+			errs = append(errs, fmt.Errorf("tasks[%d].owner: %q is not a fixture user", i, t.Owner))
+		}
+		if strings.TrimSpace(t.Title) == "" {
+			errs = append(errs, fmt.Errorf("tasks[%d].title: must be set", i))
+		}
+		if t.Status != "" && !slices.Contains(statuses, t.Status) {
+			errs = append(errs, fmt.Errorf("tasks[%d].status: %q is not a task status", i, t.Status))
+		}
+		if t.Priority != "" && !slices.Contains(priorities, t.Priority) {
+			errs = append(errs, fmt.Errorf("tasks[%d].priority: %q is not a task priority", i, t.Priority))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewUsers returns the users of the fixtures, in order, with the tenants of their slugs
+func (f *Fixtures) NewUsers(tenants TenantDirectory) ([]*auth.User, error) {
+	now := time.Now()
+	users := make([]*auth.User, len(f.Users))
+	for i, u := range f.Users {
+		user := &auth.User{
+			ID:        u.ID,
+			Email:     u.Email,
+			Password:  u.Password,
+			TenantID:  tenant.DefaultTenantID,
+			Admin:     u.Admin,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if user.ID == uuid.Nil {
+			user.ID = uuid.New()
+		}
+		if u.Tenant != "" {
+			t, err := tenants.GetTenantBySlug(u.Tenant)
+			if err != nil {
+				return nil, fmt.Errorf("users[%d]: tenant %s: %w", i, u.Tenant, err)
+			}
+			user.TenantID = t.ID
+		}
+		users[i] = user
+	}
+	return users, nil
+}
+
+// NewTasks returns the tasks of the fixtures, in order, owned by the users of the directory
+// with the owner emails. Tasks of owners the directory does not know are left out, such as
+// those of the users storage started without.
+func (f *Fixtures) NewTasks(users UserDirectory) []*task.Task {
+	var tasks []*task.Task
+	for _, t := range f.Tasks {
+		owner, err := users.GetUserByEmail(t.Owner)
+		if err != nil || owner == nil {
+			continue
+		}
+
+		created := task.NewTask(t.Title, owner.ID)
+		created.Description = t.Description
+		if t.Priority != "" {
+			created.Priority = t.Priority
+		}
+		created.DueDate = t.DueDate
+		if t.Status != "" {
+			created.SetStatus(t.Status)
+		}
+		tasks = append(tasks, created)
+	}
+	return tasks
+}
+
+// Apply creates the users of the fixtures and then their tasks, stopping at the first
+// failure, such as a user whose email is taken
+func (f *Fixtures) Apply(users UserStore, tenants TenantDirectory, tasks TaskStore) error {
+	created, err := f.NewUsers(tenants)
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]uuid.UUID, len(created))
+	for i, user := range created {
+		if err := users.CreateUser(user); err != nil {
+			return fmt.Errorf("users[%d]: %w", i, err)
+		}
+		ids[user.Email] = user.ID
+	}
+
+	for i, t := range f.Tasks {
+		ownerID := ids[t.Owner]
+		created, err := tasks.CreateTask(&task.CreateTaskRequest{
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    t.Priority,
+			DueDate:     t.DueDate,
+		}, ownerID)
+		if err != nil {
+			return fmt.Errorf("tasks[%d]: %w", i, err)
+		}
+		if t.Status == "" || t.Status == created.Status {
+			continue
+		}
+		status := t.Status
+		if _, err := tasks.UpdateTask(created.ID, &task.UpdateTaskRequest{Status: &status}, ownerID); err != nil {
+			return fmt.Errorf("tasks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package seed_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	authDomain "todo-api/internal/domain/auth"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/seed"
+	"todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	tenantService "todo-api/internal/service/tenant"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	// The default fixtures stay valid, and are those embedded in the binary
+	f, err := seed.Load("testdata/fixtures.yaml")
+	require.NoError(t, err)
+	assert.NotEmpty(t, f.Users)
+	assert.NotEmpty(t, f.Tasks)
+	assert.Equal(t, f, seed.Default())
+
+	path := writeFile(t, "fixtures.json", `{
+		"users": [{"id": "550e8400-e29b-41d4-a716-446655440099", "email": "sam@example.com", "password": "password123"}],
+		"tasks": [{"owner": "sam@example.com", "title": "Water the plants", "status": "completed", "due_date": "2026-05-01T09:00:00Z"}]
+	}`)
+	f, err = seed.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.MustParse("550e8400-e29b-41d4-a716-446655440099"), f.Users[0].ID)
+	assert.Equal(t, task.StatusCompleted, f.Tasks[0].Status)
+	assert.Equal(t, time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC), *f.Tasks[0].DueDate)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := seed.Load(writeFile(t, "fixtures.yaml", "users:\n  - email: sam@example.com\n    pasword: password123\n"))
+	assert.ErrorContains(t, err, "field pasword not found")
+
+	_, err = seed.Load(writeFile(t, "fixtures.toml", ""))
+	assert.ErrorContains(t, err, `unsupported fixtures format ".toml"`)
+
+	_, err = seed.Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = seed.Load(writeFile(t, "fixtures.yaml", `
+users:
+  - email: sam@example.com
+    password: short
+  - email: sam@example.com
+    password: password123
+tasks:
+  - owner: kim@example.com
+    title: " "
+    status: done
+    priority: urgent
+`))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "users[0].password: must be at least 8 characters")
+	assert.ErrorContains(t, err, "users[1].email: sam@example.com is used by another user")
+	assert.ErrorContains(t, err, `tasks[0].owner: "kim@example.com" is not a fixture user`)
+	assert.ErrorContains(t, err, "tasks[0].title: must be set")
+	assert.ErrorContains(t, err, `tasks[0].status: "done" is not a task status`)
+	assert.ErrorContains(t, err, `tasks[0].priority: "urgent" is not a task priority`)
+}
+
+func TestFixtures_Apply(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}}
+	authSvc := auth.NewServiceWithUsers(cfg, nil)
+	tenants := tenantService.NewService(authSvc)
+	tasks := taskService.NewService(authSvc)
+
+	f, err := seed.Load("testdata/fixtures.yaml")
+	require.NoError(t, err)
+	require.NoError(t, f.Apply(authSvc, tenants, tasks))
+
+	tokens, err := authSvc.Login(&authDomain.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	alice, err := authSvc.GetUserByEmail("alice@acme.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, auth.AcmeTenantID, alice.TenantID)
+	admin, err := authSvc.GetUserByEmail("admin@example.com")
+	require.NoError(t, err)
+	assert.True(t, admin.Admin)
+
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	listed, _, err := tasks.ListTasks(&task.TaskFilter{}, &task.TaskSort{Field: "title", Order: "asc"}, 1, 10, john.ID)
+	require.NoError(t, err)
+	require.Len(t, listed, 3)
+	assert.Equal(t, "Complete project documentation", listed[0].Title)
+	assert.Equal(t, task.StatusInProgress, listed[0].Status)
+	assert.Equal(t, task.PriorityHigh, listed[0].Priority)
+
+	// Users are not created twice
+	err = f.Apply(authSvc, tenants, tasks)
+	assert.EqualError(t, err, "users[0]: "+authDomain.ErrEmailTaken.Error())
+}
+
+func TestFixtures_Apply_UnknownTenant(t *testing.T) {
+	authSvc := auth.NewServiceWithUsers(&config.Config{}, nil)
+	f := &seed.Fixtures{Users: []seed.User{{Email: "sam@example.com", Password: "password123", Tenant: "globex"}}}
+
+	err := f.Apply(authSvc, tenantService.NewService(authSvc), taskService.NewService(authSvc))
+	assert.EqualError(t, err, "users[0]: tenant globex: tenant not found")
+}
+
+func TestFixtures_NewTasks(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}}
+	authSvc := auth.NewService(cfg)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+
+	f := &seed.Fixtures{Tasks: []seed.Task{
+		{Owner: "john.doe@example.com", Title: "Plan the launch", Status: task.StatusCompleted, Priority: task.PriorityHigh},
+		{Owner: "john.doe@example.com", Title: "Write the announcement", Description: "For the blog"},
+		{Owner: "sam@example.com", Title: "Water the plants"},
+	}}
+
+	// Tasks of owners the auth service does not know are left out
+	tasks := f.NewTasks(authSvc)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, john.ID, tasks[0].UserID)
+	assert.Equal(t, task.StatusCompleted, tasks[0].Status)
+	assert.NotNil(t, tasks[0].CompletedAt)
+	assert.Equal(t, task.PriorityHigh, tasks[0].Priority)
+	assert.Equal(t, task.StatusPending, tasks[1].Status)
+	assert.Equal(t, task.PriorityMedium, tasks[1].Priority)
+	assert.Equal(t, "For the blog", tasks[1].Description)
+}
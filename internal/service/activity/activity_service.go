@@ -0,0 +1,111 @@
+package activity
+
+import (
+	"sort"
+	"sync"
+
+	"todo-api/internal/domain/activity"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the activity log service interface
+type Service interface {
+	Record(entries ...*activity.Entry)
+	ListByTask(taskID uuid.UUID) []*activity.Entry
+	ListByActor(actorID uuid.UUID) []*activity.Entry
+	DeleteByTask(taskID uuid.UUID)
+	AnonymizeActor(actorID uuid.UUID) int
+}
+
+// service implements the activity log service
+type service struct {
+	mu      sync.RWMutex
+	entries map[uuid.UUID][]*activity.Entry // Mock activity storage, keyed by task ID
+}
+
+// NewService creates a new activity log service
+func NewService() Service {
+	return &service{
+		entries: make(map[uuid.UUID][]*activity.Entry),
+	}
+}
+
+// Record appends entries to the activity log
+func (s *service) Record(entries ...*activity.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		s.entries[entry.TaskID] = append(s.entries[entry.TaskID], entry)
+	}
+}
+
+// ListByTask returns the activity for a task in chronological order
+func (s *service) ListByTask(taskID uuid.UUID) []*activity.Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := make([]*activity.Entry, len(s.entries[taskID]))
+	copy(history, s.entries[taskID])
+	return history
+}
+
+// ListByActor returns the activity performed by a user in chronological order
+func (s *service) ListByActor(actorID uuid.UUID) []*activity.Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []*activity.Entry
+	for _, history := range s.entries {
+		for _, entry := range history {
+			if entry.ActorID == actorID {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries
+}
+
+// DeleteByTask deletes the activity of a task
+func (s *service) DeleteByTask(taskID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, taskID)
+}
+
+// AnonymizeActor removes a user from the activity log, replacing them with the nil user
+// as actor and in recorded values such as assignees. It returns the number of entries changed.
+func (s *service) AnonymizeActor(actorID uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := actorID.String()
+	anonymized := 0
+	for _, history := range s.entries {
+		for _, entry := range history {
+			changed := false
+			if entry.ActorID == actorID {
+				entry.ActorID = uuid.Nil
+				changed = true
+			}
+			if entry.OldValue == id {
+				entry.OldValue = uuid.Nil.String()
+				changed = true
+			}
+			if entry.NewValue == id {
+				entry.NewValue = uuid.Nil.String()
+				changed = true
+			}
+			if changed {
+				anonymized++
+			}
+		}
+	}
+	return anonymized
+}
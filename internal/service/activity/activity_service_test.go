@@ -0,0 +1,70 @@
+package activity
+
+import (
+	"testing"
+
+	"todo-api/internal/domain/activity"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RecordAndList(t *testing.T) {
+	service := NewService()
+	taskID := uuid.New()
+	otherTaskID := uuid.New()
+	actorID := uuid.New()
+
+	service.Record(activity.NewEntry(taskID, actorID, activity.ActionCreated))
+	service.Record(
+		activity.NewFieldChange(taskID, actorID, "title", "Old", "New"),
+		activity.NewEntry(otherTaskID, actorID, activity.ActionCreated),
+	)
+
+	history := service.ListByTask(taskID)
+
+	require.Len(t, history, 2)
+	assert.Equal(t, activity.ActionCreated, history[0].Action)
+	assert.Equal(t, "title", history[1].Field)
+	assert.Len(t, service.ListByTask(otherTaskID), 1)
+}
+
+func TestService_ListByTask_Empty(t *testing.T) {
+	service := NewService()
+
+	history := service.ListByTask(uuid.New())
+
+	assert.NotNil(t, history)
+	assert.Empty(t, history)
+}
+
+func TestService_ErasingActors(t *testing.T) {
+	service := NewService()
+	taskID := uuid.New()
+	otherTaskID := uuid.New()
+	actorID := uuid.New()
+	otherActorID := uuid.New()
+
+	service.Record(
+		activity.NewEntry(taskID, actorID, activity.ActionCreated),
+		activity.NewFieldChange(taskID, otherActorID, "assignee", "", actorID.String()),
+		activity.NewEntry(otherTaskID, actorID, activity.ActionCreated),
+		activity.NewEntry(otherTaskID, otherActorID, activity.ActionCreated),
+	)
+
+	entries := service.ListByActor(actorID)
+	require.Len(t, entries, 2)
+	assert.Equal(t, actorID, entries[0].ActorID)
+
+	service.DeleteByTask(otherTaskID)
+	assert.Empty(t, service.ListByTask(otherTaskID))
+
+	assert.Equal(t, 2, service.AnonymizeActor(actorID))
+	assert.Empty(t, service.ListByActor(actorID))
+
+	history := service.ListByTask(taskID)
+	assert.Equal(t, uuid.Nil, history[0].ActorID)
+	assert.Equal(t, otherActorID, history[1].ActorID)
+	assert.Equal(t, uuid.Nil.String(), history[1].NewValue)
+}
@@ -0,0 +1,570 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/attachment"
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/blob"
+	"todo-api/pkg/config"
+	"todo-api/pkg/scan"
+
+	"github.com/google/uuid"
+)
+
+// maxAttachmentsPerTask bounds the attachments of a task, including pending ones
+const maxAttachmentsPerTask = 100
+
+// pendingTTL is how long pending attachments are kept after their upload URL was issued.
+// Uploads not completed by then are discarded along with their files.
+const pendingTTL = 24 * time.Hour
+
+// Service defines the attachment service interface. Files are uploaded to and downloaded
+// from object storage with presigned URLs, so they never pass through the API server, except
+// for files the server receives itself.
+type Service interface {
+	// CreateAttachment adds a pending attachment to the task, returning the URL its file is
+	// uploaded to
+	CreateAttachment(taskID uuid.UUID, req *attachment.CreateAttachmentRequest, userID uuid.UUID) (*attachment.Attachment, *blob.PresignedURL, error)
+	// CompleteAttachment makes a pending attachment ready once its file was uploaded, or
+	// quarantines it when the scanner flags the file
+	CompleteAttachment(ctx context.Context, taskID, id uuid.UUID, userID uuid.UUID) (*attachment.Attachment, error)
+	// AttachFile attaches a file received by the server, uploading it to storage
+	AttachFile(ctx context.Context, taskID uuid.UUID, filename, contentType string, data []byte,
+		userID uuid.UUID) (*attachment.Attachment, error)
+	// ListAttachments returns the attachments of the task, with the URLs of the thumbnails
+	// of images
+	ListAttachments(taskID uuid.UUID, userID uuid.UUID) ([]*attachment.Attachment, error)
+	// ListUserAttachments returns the ready attachments the user uploaded to any task, for
+	// exports of their data
+	ListUserAttachments(userID uuid.UUID) []*attachment.Attachment
+	// DownloadAttachment returns the URL the file of a ready attachment is downloaded from
+	DownloadAttachment(taskID, id uuid.UUID, userID uuid.UUID) (*blob.PresignedURL, error)
+	DeleteAttachment(ctx context.Context, taskID, id uuid.UUID, userID uuid.UUID) error
+}
+
+// PlanDirectory resolves the plan of each user and its limits
+type PlanDirectory interface {
+	PlanOf(userID uuid.UUID) (billing.Plan, billing.Limits)
+}
+
+// unlimitedPlans is a directory putting every user on a plan without limits
+type unlimitedPlans struct{}
+
+func (unlimitedPlans) PlanOf(uuid.UUID) (billing.Plan, billing.Limits) {
+	return billing.PlanPro, billing.Limits{}
+}
+
+// service implements the attachment service
+type service struct {
+	mu          sync.Mutex
+	attachments map[uuid.UUID]*attachment.Attachment // Mock attachment storage
+	taskService taskService.Service
+	store       blob.Store
+	plans       PlanDirectory
+	scanner     scan.Scanner // nil when files are not scanned
+	jobs        jobs.Queue   // deletes expired uploads and generates thumbnails of images, if any
+	config      config.AttachmentsConfig
+}
+
+// Deps are the dependencies of an attachment service. Config, Tasks, Store, and Bus are
+// required; the others are optional, and a zero value keeps the default noted next to each.
+type Deps struct {
+	Config config.AttachmentsConfig
+	Tasks  taskService.Service
+	Store  blob.Store // keeps the files
+	Bus    events.Bus // the files of deleted tasks are deleted through it
+	// Plans limits the attachments each user uploads to those allowed by their plan; uploads
+	// are unlimited when it is nil
+	Plans PlanDirectory
+	// Scanner scans uploaded files for malware before they become downloadable. Flagged files
+	// are deleted and their attachments quarantined. Files are not scanned when it is nil.
+	Scanner scan.Scanner
+	// Jobs generates thumbnails of the sizes of the configuration for uploaded images, and
+	// deletes the files of abandoned uploads, so failed deletes are retried. No thumbnails
+	// are generated when it is nil.
+	Jobs jobs.Queue
+}
+
+// NewService creates a new attachment service keeping files in the store. The files of
+// deleted tasks are deleted through the bus.
+func NewService(cfg config.AttachmentsConfig, taskSvc taskService.Service, store blob.Store, bus events.Bus) Service {
+	return NewServiceWithDeps(Deps{Config: cfg, Tasks: taskSvc, Store: store, Bus: bus})
+}
+
+// NewServiceWithDeps creates a new attachment service, using the defaults of Deps for the
+// dependencies left unset
+func NewServiceWithDeps(deps Deps) Service {
+	if deps.Plans == nil {
+		deps.Plans = unlimitedPlans{}
+	}
+	cfg, queue := deps.Config, deps.Jobs
+
+	s := &service{
+		attachments: make(map[uuid.UUID]*attachment.Attachment),
+		taskService: deps.Tasks,
+		store:       deps.Store,
+		plans:       deps.Plans,
+		scanner:     deps.Scanner,
+		config:      cfg,
+	}
+
+	if queue != nil {
+		s.jobs = queue
+		jobs.Handle(queue, func(ctx context.Context, job deleteFilesJob) error {
+			return s.removeFiles(ctx, job.Keys)
+		})
+		if len(cfg.ThumbnailSizes) > 0 {
+			jobs.Handle(queue, func(ctx context.Context, job thumbnailJob) error {
+				return s.generateThumbnails(ctx, job.AttachmentID)
+			})
+		}
+	}
+
+	deps.Bus.Subscribe(func(event events.Event) {
+		if taskEvent, ok := event.(*task.Event); ok && taskEvent.Type == task.EventTaskDeleted {
+			s.deleteTaskAttachments(taskEvent.TaskID)
+		}
+	})
+
+	return s
+}
+
+// CreateAttachment adds a pending attachment to the task. Users who may change the task
+// may attach files to it.
+func (s *service) CreateAttachment(taskID uuid.UUID, req *attachment.CreateAttachmentRequest,
+	userID uuid.UUID) (*attachment.Attachment, *blob.PresignedURL, error) {
+	// Validate request
+	if err := req.Validate(int64(s.config.MaxSize)); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.taskService.AuthorizeTask(taskID, userID, task.ActionUpdate); err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkLimits(taskID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	a := attachment.NewAttachment(taskID, userID, req)
+	upload, err := s.store.PresignUpload(a.Key, a.ContentType, s.config.URLTTL)
+	if err != nil {
+		return nil, nil, storageError(err)
+	}
+	s.attachments[a.ID] = a
+
+	created := *a
+	return &created, upload, nil
+}
+
+// AttachFile attaches a file the server received itself, such as the attachment of an
+// email, uploading it to storage. Users who may change the task may attach files to it.
+func (s *service) AttachFile(ctx context.Context, taskID uuid.UUID, filename, contentType string, data []byte,
+	userID uuid.UUID) (*attachment.Attachment, error) {
+	req := &attachment.CreateAttachmentRequest{Filename: filename, ContentType: contentType, Size: int64(len(data))}
+	if err := req.Validate(int64(s.config.MaxSize)); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.taskService.AuthorizeTask(taskID, userID, task.ActionUpdate); err != nil {
+		return nil, err
+	}
+
+	result, err := s.scan(ctx, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// The attachment is pending while uploading, so it counts against the limits
+	s.mu.Lock()
+	if err := s.checkLimits(taskID, userID); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	a := attachment.NewAttachment(taskID, userID, req)
+	s.attachments[a.ID] = a
+	if result != nil && result.Infected {
+		// Flagged files are never uploaded
+		quarantine(a, result)
+		quarantined := *a
+		s.mu.Unlock()
+		return &quarantined, nil
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	if err := s.store.Upload(ctx, a.Key, a.ContentType, data); err != nil {
+		s.mu.Lock()
+		delete(s.attachments, a.ID)
+		s.mu.Unlock()
+		return nil, storageError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The attachment may have been deleted meanwhile
+	if _, ok := s.attachments[a.ID]; !ok {
+		return nil, attachment.ErrNotFound
+	}
+	now := time.Now()
+	a.Status = attachment.StatusReady
+	a.UploadedAt = &now
+	markScanned(a, result, now)
+	s.startThumbnails(a)
+
+	attached := *a
+	return &attached, nil
+}
+
+// checkLimits returns an error when the task has as many attachments as allowed, or the
+// user's plan allows no more uploads, discarding uploads abandoned long ago. The caller must
+// hold the lock.
+func (s *service) checkLimits(taskID, userID uuid.UUID) error {
+	var expired []string
+	count, uploaded := 0, 0
+	for id, a := range s.attachments {
+		if a.TaskID != taskID && a.UserID != userID {
+			continue
+		}
+		if a.Status == attachment.StatusPending && time.Since(a.CreatedAt) > pendingTTL {
+			delete(s.attachments, id)
+			expired = append(expired, a.Key)
+			continue
+		}
+		if a.TaskID == taskID {
+			count++
+		}
+		// Quarantined files are kept for the record but are not the user's files
+		if a.UserID == userID && a.Status != attachment.StatusQuarantined {
+			uploaded++
+		}
+	}
+	if len(expired) > 0 {
+		s.deleteFilesLater(expired)
+	}
+	if count >= maxAttachmentsPerTask {
+		return fmt.Errorf("tasks can have at most %d attachments", maxAttachmentsPerTask)
+	}
+	if plan, limits := s.plans.PlanOf(userID); !limits.AllowsAttachments(uploaded) {
+		return billing.UpgradeRequired(plan, limits.MaxAttachments, "attachments")
+	}
+	return nil
+}
+
+// CompleteAttachment makes a pending attachment ready once its file is found in storage.
+// Only the user who created the attachment completes it. The stored size replaces the
+// declared one, and files larger than allowed are deleted. Files are scanned before they
+// become downloadable; flagged ones are deleted and their attachment is returned
+// quarantined. Attachments stay pending when the scanner cannot be reached, so completing
+// them can be retried.
+func (s *service) CompleteAttachment(ctx context.Context, taskID, id uuid.UUID, userID uuid.UUID) (*attachment.Attachment, error) {
+	if _, err := s.taskService.AuthorizeTask(taskID, userID, task.ActionUpdate); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	a, ok := s.attachments[id]
+	if !ok || a.TaskID != taskID || a.UserID != userID {
+		s.mu.Unlock()
+		return nil, attachment.ErrNotFound
+	}
+	if a.Status != attachment.StatusPending {
+		completed := *a
+		s.mu.Unlock()
+		return &completed, nil
+	}
+	key := a.Key
+	s.mu.Unlock()
+
+	// Scanning is bounded on its own, as large files take longer than storage requests
+	scanCtx := ctx
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	object, err := s.store.Stat(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, attachment.ErrNotUploaded
+	}
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	if object.Size > int64(s.config.MaxSize) {
+		if err := s.store.Delete(ctx, key); err != nil {
+			return nil, storageError(err)
+		}
+		s.mu.Lock()
+		delete(s.attachments, id)
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: attachments must be at most %d bytes", attachment.ErrTooLarge, s.config.MaxSize)
+	}
+
+	var result *scan.Result
+	if s.scanner != nil {
+		if result, err = s.scanObject(scanCtx, key); err != nil {
+			return nil, err
+		}
+		if result.Infected {
+			deleteCtx, cancel := context.WithTimeout(scanCtx, s.config.Timeout)
+			defer cancel()
+			if err := s.store.Delete(deleteCtx, key); err != nil {
+				return nil, storageError(err)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The attachment may have been deleted meanwhile
+	if a, ok = s.attachments[id]; !ok {
+		return nil, attachment.ErrNotFound
+	}
+	a.Size = object.Size
+	if result != nil && result.Infected {
+		quarantine(a, result)
+		quarantined := *a
+		return &quarantined, nil
+	}
+	now := time.Now()
+	a.Status = attachment.StatusReady
+	a.UploadedAt = &now
+	markScanned(a, result, now)
+	s.startThumbnails(a)
+
+	completed := *a
+	return &completed, nil
+}
+
+// ListAttachments returns the ready attachments of the task, oldest first, along with the
+// user's own pending ones
+func (s *service) ListAttachments(taskID uuid.UUID, userID uuid.UUID) ([]*attachment.Attachment, error) {
+	if _, err := s.taskService.GetTaskByID(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	attachments := []*attachment.Attachment{}
+	for _, a := range s.attachments {
+		if a.TaskID == taskID && (a.Status == attachment.StatusReady || a.UserID == userID) {
+			s.refreshThumbnails(a)
+			listed := *a
+			listed.Thumbnails = slices.Clone(a.Thumbnails)
+			attachments = append(attachments, &listed)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, a := range attachments {
+		if err := s.presignThumbnails(a); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].CreatedAt.Before(attachments[j].CreatedAt) })
+	return attachments, nil
+}
+
+// ListUserAttachments returns the ready attachments the user uploaded, oldest first
+func (s *service) ListUserAttachments(userID uuid.UUID) []*attachment.Attachment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachments := []*attachment.Attachment{}
+	for _, a := range s.attachments {
+		if a.UserID == userID && a.Status == attachment.StatusReady {
+			listed := *a
+			attachments = append(attachments, &listed)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].CreatedAt.Before(attachments[j].CreatedAt) })
+	return attachments
+}
+
+// DownloadAttachment returns the URL the file of a ready attachment is downloaded from,
+// saved under its file name
+func (s *service) DownloadAttachment(taskID, id uuid.UUID, userID uuid.UUID) (*blob.PresignedURL, error) {
+	if _, err := s.taskService.GetTaskByID(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	a, ok := s.attachments[id]
+	if !ok || a.TaskID != taskID || a.Status != attachment.StatusReady {
+		s.mu.Unlock()
+		return nil, attachment.ErrNotFound
+	}
+	key, filename := a.Key, a.Filename
+	s.mu.Unlock()
+
+	download, err := s.store.PresignDownload(key, filename, s.config.URLTTL)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	return download, nil
+}
+
+// DeleteAttachment deletes an attachment and its file. Users who may change the task may
+// delete its attachments.
+func (s *service) DeleteAttachment(ctx context.Context, taskID, id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.taskService.AuthorizeTask(taskID, userID, task.ActionUpdate); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	a, ok := s.attachments[id]
+	if !ok || a.TaskID != taskID || (a.Status == attachment.StatusPending && a.UserID != userID) {
+		s.mu.Unlock()
+		return attachment.ErrNotFound
+	}
+	delete(s.attachments, id)
+	thumbnails := thumbnailKeys(a)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	if err := s.store.Delete(ctx, a.Key); err != nil {
+		return storageError(err)
+	}
+	s.deleteFiles(thumbnails)
+	return nil
+}
+
+// scanObject scans the stored file under the key
+func (s *service) scanObject(ctx context.Context, key string) (*scan.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.ScannerTimeout)
+	defer cancel()
+
+	file, err := s.store.Open(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, attachment.ErrNotUploaded
+	}
+	if err != nil {
+		return nil, storageError(err)
+	}
+	defer file.Close()
+	return s.scan(ctx, file)
+}
+
+// scan scans a file for malware, returning nil without a scanner. Files are never let
+// through unscanned: failures of the scanner are returned.
+func (s *service) scan(ctx context.Context, r io.Reader) (*scan.Result, error) {
+	if s.scanner == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.ScannerTimeout)
+	defer cancel()
+
+	result, err := s.scanner.Scan(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", attachment.ErrScanUnavailable, err)
+	}
+	return result, nil
+}
+
+// quarantine records that the scanner flagged the file of an attachment, which was deleted
+// or never stored
+func quarantine(a *attachment.Attachment, result *scan.Result) {
+	now := time.Now()
+	a.Status = attachment.StatusQuarantined
+	a.ScanStatus = attachment.ScanInfected
+	a.Threat = result.Threat
+	a.ScannedAt = &now
+	log.Printf("Quarantined attachment %s of task %s uploaded by %s: %s", a.ID, a.TaskID, a.UserID, result.Threat)
+}
+
+// markScanned records that the file of an attachment was found clean, when it was scanned
+func markScanned(a *attachment.Attachment, result *scan.Result, at time.Time) {
+	if result == nil {
+		return
+	}
+	a.ScanStatus = attachment.ScanClean
+	a.ScannedAt = &at
+}
+
+// storageError reports a failure of the object storage
+func storageError(err error) error {
+	return fmt.Errorf("%w: %v", attachment.ErrStorageUnavailable, err)
+}
+
+// deleteTaskAttachments deletes the attachments of a deleted task along with their files
+func (s *service) deleteTaskAttachments(taskID uuid.UUID) {
+	s.mu.Lock()
+	var keys []string
+	for id, a := range s.attachments {
+		if a.TaskID == taskID {
+			delete(s.attachments, id)
+			keys = append(keys, a.Key)
+			keys = append(keys, thumbnailKeys(a)...)
+		}
+	}
+	s.mu.Unlock()
+
+	s.deleteFiles(keys)
+}
+
+// deleteFilesJob deletes files from storage
+type deleteFilesJob struct {
+	Keys []string `json:"keys"`
+}
+
+func (deleteFilesJob) JobType() string { return "attachment.delete_files" }
+
+// deleteFilesLater deletes files from storage with a job, retried until the files are gone,
+// without waiting for storage. Without a queue the files are deleted in the background once,
+// and failures are logged.
+func (s *service) deleteFilesLater(keys []string) {
+	if s.jobs == nil {
+		go s.deleteFiles(keys)
+		return
+	}
+	if _, err := s.jobs.Enqueue(deleteFilesJob{Keys: keys}); err != nil {
+		log.Printf("Failed to enqueue deleting %d attachment files: %v", len(keys), err)
+	}
+}
+
+// removeFiles deletes files from storage, returning the failures. Deleting a file that is
+// gone succeeds, so the files can be deleted again.
+func (s *service) removeFiles(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		deleteCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+		if err := s.store.Delete(deleteCtx, key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete attachment file %s: %w", key, err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// deleteFiles deletes files from storage, logging failures. Files left behind are orphaned
+// but never served again.
+func (s *service) deleteFiles(keys []string) {
+	for _, key := range keys {
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete attachment file %s: %v", key, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,386 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/attachment"
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/blob"
+	"todo-api/pkg/config"
+	"todo-api/pkg/scan"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Users of the mock user storage
+var (
+	johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	janeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+)
+
+func setupTestService(t *testing.T) (Service, taskService.Service, *blob.MemoryStore) {
+	cfg := &config.Config{Files: config.AttachmentsConfig{
+		Storage: "s3",
+		MaxSize: 1024,
+		URLTTL:  15 * time.Minute,
+		Timeout: time.Second,
+	}}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	store := blob.NewMemoryStore()
+	return NewService(cfg.Files, taskSvc, store, bus), taskSvc, store
+}
+
+func TestService_Upload(t *testing.T) {
+	svc, taskSvc, store := setupTestService(t)
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+
+	a, upload, err := svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{
+		Filename: "draft.txt", ContentType: "text/plain", Size: 5,
+	}, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusPending, a.Status)
+	assert.Equal(t, "PUT", upload.Method)
+	assert.Equal(t, "text/plain", upload.Headers["Content-Type"])
+
+	// Uploads are completed once the file is stored
+	_, err = svc.CompleteAttachment(context.Background(), created.ID, a.ID, johnID)
+	assert.ErrorIs(t, err, attachment.ErrNotUploaded)
+	_, err = svc.DownloadAttachment(created.ID, a.ID, johnID)
+	assert.ErrorIs(t, err, attachment.ErrNotFound)
+
+	store.Put(a.Key, "text/plain", []byte("draft!"))
+	completed, err := svc.CompleteAttachment(context.Background(), created.ID, a.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusReady, completed.Status)
+	assert.Equal(t, int64(6), completed.Size)
+	assert.NotNil(t, completed.UploadedAt)
+
+	download, err := svc.DownloadAttachment(created.ID, a.ID, johnID)
+	require.NoError(t, err)
+	assert.Contains(t, download.URL, a.Key)
+
+	attachments, err := svc.ListAttachments(created.ID, johnID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "draft.txt", attachments[0].Filename)
+
+	require.NoError(t, svc.DeleteAttachment(context.Background(), created.ID, a.ID, johnID))
+	assert.False(t, store.Has(a.Key))
+	attachments, err = svc.ListAttachments(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+}
+
+func TestService_AttachFile(t *testing.T) {
+	svc, taskSvc, store := setupTestService(t)
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+
+	// Files received by the server are uploaded by it and ready at once
+	a, err := svc.AttachFile(context.Background(), created.ID, "notes/draft.txt", "", []byte("draft!"), johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusReady, a.Status)
+	assert.Equal(t, "draft.txt", a.Filename)
+	assert.Equal(t, attachment.DefaultContentType, a.ContentType)
+	assert.Equal(t, int64(6), a.Size)
+	assert.NotNil(t, a.UploadedAt)
+	assert.True(t, store.Has(a.Key))
+
+	attachments, err := svc.ListAttachments(created.ID, johnID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, a.ID, attachments[0].ID)
+
+	// Exported with the data of the uploader
+	require.Len(t, svc.ListUserAttachments(johnID), 1)
+	assert.Empty(t, svc.ListUserAttachments(janeID))
+
+	_, err = svc.AttachFile(context.Background(), created.ID, "big.bin", "", make([]byte, 2048), johnID)
+	assert.ErrorIs(t, err, attachment.ErrTooLarge)
+	_, err = svc.AttachFile(context.Background(), created.ID, "draft.txt", "text/plain", []byte("draft!"), janeID)
+	assert.EqualError(t, err, "access denied")
+}
+
+func TestService_TooLarge(t *testing.T) {
+	svc, taskSvc, store := setupTestService(t)
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+
+	_, _, err = svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "big.bin", Size: 2048}, johnID)
+	assert.ErrorIs(t, err, attachment.ErrTooLarge)
+
+	// Files larger than declared are checked once uploaded
+	a, _, err := svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "big.bin", Size: 10}, johnID)
+	require.NoError(t, err)
+	store.Put(a.Key, attachment.DefaultContentType, make([]byte, 2048))
+	_, err = svc.CompleteAttachment(context.Background(), created.ID, a.ID, johnID)
+	assert.ErrorIs(t, err, attachment.ErrTooLarge)
+	assert.False(t, store.Has(a.Key))
+}
+
+// freePlans is a plan directory putting every user on a free plan allowing two attachments
+type freePlans struct{}
+
+func (freePlans) PlanOf(uuid.UUID) (billing.Plan, billing.Limits) {
+	return billing.PlanFree, billing.Limits{MaxAttachments: 2}
+}
+
+func TestService_PlanLimits(t *testing.T) {
+	cfg := config.AttachmentsConfig{MaxSize: 1024, URLTTL: 15 * time.Minute}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithEventBus(authService.NewService(&config.Config{}), bus)
+	svc := NewServiceWithDeps(Deps{Config: cfg, Tasks: taskSvc, Store: blob.NewMemoryStore(), Bus: bus, Plans: freePlans{}})
+
+	first, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+	second, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Review report"}, johnID)
+	require.NoError(t, err)
+
+	// The limit counts the attachments of every task of the user
+	_, _, err = svc.CreateAttachment(first.ID, &attachment.CreateAttachmentRequest{Filename: "a.txt", Size: 1}, johnID)
+	require.NoError(t, err)
+	_, _, err = svc.CreateAttachment(second.ID, &attachment.CreateAttachmentRequest{Filename: "b.txt", Size: 1}, johnID)
+	require.NoError(t, err)
+	_, _, err = svc.CreateAttachment(second.ID, &attachment.CreateAttachmentRequest{Filename: "c.txt", Size: 1}, johnID)
+	assert.ErrorIs(t, err, billing.ErrUpgradeRequired)
+	assert.EqualError(t, err, "upgrade required: the free plan allows at most 2 attachments")
+}
+
+// flakyStore fails to delete files a number of times before deleting them
+type flakyStore struct {
+	*blob.MemoryStore
+	mu       sync.Mutex
+	failures int
+}
+
+func (f *flakyStore) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("service unavailable")
+	}
+	return f.MemoryStore.Delete(ctx, key)
+}
+
+func TestService_ExpiredUploads(t *testing.T) {
+	cfg := config.AttachmentsConfig{MaxSize: 1024, URLTTL: 15 * time.Minute, Timeout: time.Second}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 3, RetryBackoff: time.Millisecond, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	taskSvc := taskService.NewServiceWithEventBus(authService.NewService(&config.Config{}), bus)
+	store := &flakyStore{MemoryStore: blob.NewMemoryStore(), failures: 1}
+	svc := NewServiceWithDeps(Deps{Config: cfg, Tasks: taskSvc, Store: store, Bus: bus, Jobs: queue})
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+	abandoned, _, err := svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "a.txt", Size: 1}, johnID)
+	require.NoError(t, err)
+	store.Put(abandoned.Key, "text/plain", []byte("a"))
+
+	// Uploads abandoned long ago are discarded when the next one starts, and their files are
+	// deleted by a job retried until storage deletes them
+	s := svc.(*service)
+	s.mu.Lock()
+	s.attachments[abandoned.ID].CreatedAt = time.Now().Add(-pendingTTL - time.Minute)
+	s.mu.Unlock()
+	_, _, err = svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "b.txt", Size: 1}, johnID)
+	require.NoError(t, err)
+
+	attachments, err := svc.ListAttachments(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Len(t, attachments, 1)
+	assert.Eventually(t, func() bool {
+		return !store.Has(abandoned.Key)
+	}, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return queue.Stats().Succeeded == 1
+	}, time.Second, 10*time.Millisecond)
+	store.mu.Lock()
+	assert.Zero(t, store.failures)
+	store.mu.Unlock()
+}
+
+// fakeScanner flags files containing the EICAR marker, or fails while down
+type fakeScanner struct {
+	down bool
+}
+
+func (f *fakeScanner) Scan(ctx context.Context, r io.Reader) (*scan.Result, error) {
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte("EICAR")) {
+		return &scan.Result{Infected: true, Threat: "Eicar-Signature"}, nil
+	}
+	return &scan.Result{}, nil
+}
+
+func TestService_Scan(t *testing.T) {
+	cfg := config.AttachmentsConfig{MaxSize: 1024, URLTTL: 15 * time.Minute, Timeout: time.Second, ScannerTimeout: time.Second}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	taskSvc := taskService.NewServiceWithEventBus(authService.NewService(&config.Config{}), bus)
+	store, scanner := blob.NewMemoryStore(), &fakeScanner{}
+	svc := NewServiceWithDeps(Deps{Config: cfg, Tasks: taskSvc, Store: store, Bus: bus, Plans: freePlans{}, Scanner: scanner})
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+
+	// Clean files become ready
+	clean, _, err := svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "a.txt", Size: 5}, johnID)
+	require.NoError(t, err)
+	store.Put(clean.Key, "text/plain", []byte("draft"))
+	completed, err := svc.CompleteAttachment(context.Background(), created.ID, clean.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusReady, completed.Status)
+	assert.Equal(t, attachment.ScanClean, completed.ScanStatus)
+	assert.NotNil(t, completed.ScannedAt)
+
+	// Flagged files are deleted and their attachments quarantined
+	flagged, _, err := svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "b.exe", Size: 5}, johnID)
+	require.NoError(t, err)
+	store.Put(flagged.Key, attachment.DefaultContentType, []byte("EICAR"))
+	completed, err = svc.CompleteAttachment(context.Background(), created.ID, flagged.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusQuarantined, completed.Status)
+	assert.Equal(t, attachment.ScanInfected, completed.ScanStatus)
+	assert.Equal(t, "Eicar-Signature", completed.Threat)
+	assert.Nil(t, completed.UploadedAt)
+	assert.False(t, store.Has(flagged.Key))
+	_, err = svc.DownloadAttachment(created.ID, flagged.ID, johnID)
+	assert.ErrorIs(t, err, attachment.ErrNotFound)
+	completed, err = svc.CompleteAttachment(context.Background(), created.ID, flagged.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusQuarantined, completed.Status)
+
+	// Files received by the server are never uploaded when flagged
+	a, err := svc.AttachFile(context.Background(), created.ID, "c.exe", "", []byte("EICAR"), johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusQuarantined, a.Status)
+	assert.False(t, store.Has(a.Key))
+
+	// The uploader sees quarantined attachments, which count against no plan
+	attachments, err := svc.ListAttachments(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Len(t, attachments, 3)
+	assert.Len(t, svc.ListUserAttachments(johnID), 1)
+
+	// Files stay pending while the scanner is down
+	scanner.down = true
+	pending, _, err := svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "d.txt", Size: 5}, johnID)
+	require.NoError(t, err)
+	store.Put(pending.Key, "text/plain", []byte("draft"))
+	_, err = svc.CompleteAttachment(context.Background(), created.ID, pending.ID, johnID)
+	assert.ErrorIs(t, err, attachment.ErrScanUnavailable)
+	assert.True(t, store.Has(pending.Key))
+	_, err = svc.AttachFile(context.Background(), created.ID, "e.txt", "", []byte("draft"), johnID)
+	assert.ErrorIs(t, err, attachment.ErrScanUnavailable)
+
+	scanner.down = false
+	completed, err = svc.CompleteAttachment(context.Background(), created.ID, pending.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StatusReady, completed.Status)
+}
+
+func TestService_Access(t *testing.T) {
+	svc, taskSvc, store := setupTestService(t)
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+
+	// Users who cannot see the task cannot attach files to it
+	_, _, err = svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "a.txt", Size: 1}, janeID)
+	assert.EqualError(t, err, "access denied")
+	_, err = svc.ListAttachments(created.ID, janeID)
+	assert.EqualError(t, err, "access denied")
+
+	// Attachments of deleted tasks are deleted with their files
+	a, _, err := svc.CreateAttachment(created.ID, &attachment.CreateAttachmentRequest{Filename: "a.txt", Size: 1}, johnID)
+	require.NoError(t, err)
+	store.Put(a.Key, attachment.DefaultContentType, []byte("a"))
+	_, err = svc.CompleteAttachment(context.Background(), created.ID, a.ID, johnID)
+	require.NoError(t, err)
+
+	require.NoError(t, taskSvc.DeleteTask(created.ID, johnID))
+	assert.Eventually(t, func() bool { return !store.Has(a.Key) }, time.Second, 10*time.Millisecond)
+}
+
+func TestService_Thumbnails(t *testing.T) {
+	cfg := config.AttachmentsConfig{MaxSize: 1 << 20, URLTTL: 15 * time.Minute, Timeout: time.Second, ThumbnailSizes: []int{32, 128}}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	taskSvc := taskService.NewServiceWithEventBus(authService.NewService(&config.Config{}), bus)
+	store := blob.NewMemoryStore()
+	svc := NewServiceWithDeps(Deps{Config: cfg, Tasks: taskSvc, Store: store, Bus: bus, Jobs: queue})
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report"}, johnID)
+	require.NoError(t, err)
+
+	var photo bytes.Buffer
+	require.NoError(t, png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 200, 100))))
+	a, err := svc.AttachFile(context.Background(), created.ID, "photo.png", "image/png", photo.Bytes(), johnID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.ThumbnailsPending, a.ThumbnailStatus)
+
+	// Thumbnails are generated in the background and listed with their URLs
+	var listed *attachment.Attachment
+	require.Eventually(t, func() bool {
+		attachments, err := svc.ListAttachments(created.ID, johnID)
+		require.NoError(t, err)
+		listed = attachments[0]
+		return listed.ThumbnailStatus == attachment.ThumbnailsReady
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, listed.Thumbnails, 2)
+	small := listed.Thumbnails[0]
+	assert.Equal(t, []int{32, 32, 16}, []int{small.Size, small.Width, small.Height})
+	assert.Equal(t, "image/png", small.ContentType)
+	assert.Contains(t, small.URL, attachment.ThumbnailKey(a.Key, 32))
+	assert.Contains(t, small.URL, "photo-32.png")
+	assert.Equal(t, []int{128, 64}, []int{listed.Thumbnails[1].Width, listed.Thumbnails[1].Height})
+	assert.True(t, store.Has(attachment.ThumbnailKey(a.Key, 128)))
+
+	// Other files have no thumbnails, and broken images fail
+	text, err := svc.AttachFile(context.Background(), created.ID, "notes.txt", "text/plain", []byte("notes"), johnID)
+	require.NoError(t, err)
+	assert.Empty(t, text.ThumbnailStatus)
+	_, err = svc.AttachFile(context.Background(), created.ID, "broken.jpg", "image/jpeg", []byte("not a jpeg"), johnID)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		attachments, err := svc.ListAttachments(created.ID, johnID)
+		require.NoError(t, err)
+		return attachments[2].ThumbnailStatus == attachment.ThumbnailsFailed
+	}, time.Second, 10*time.Millisecond)
+
+	// Thumbnails are deleted with their attachment
+	require.NoError(t, svc.DeleteAttachment(context.Background(), created.ID, a.ID, johnID))
+	assert.False(t, store.Has(attachment.ThumbnailKey(a.Key, 32)))
+	assert.False(t, store.Has(attachment.ThumbnailKey(a.Key, 128)))
+}
@@ -0,0 +1,160 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+
+	"todo-api/internal/domain/attachment"
+	"todo-api/internal/jobs"
+	"todo-api/pkg/blob"
+	"todo-api/pkg/thumbnail"
+
+	"github.com/google/uuid"
+)
+
+// thumbnailJob generates the thumbnails of an uploaded image
+type thumbnailJob struct {
+	AttachmentID uuid.UUID `json:"attachment_id"`
+}
+
+func (thumbnailJob) JobType() string { return "attachment.thumbnails" }
+
+// startThumbnails starts generating the thumbnails of a ready attachment when it is an image.
+// The lock must be held.
+func (s *service) startThumbnails(a *attachment.Attachment) {
+	if s.jobs == nil || len(s.config.ThumbnailSizes) == 0 || !thumbnail.Supported(a.ContentType) {
+		return
+	}
+
+	// The job waits for the lock, so the status is set before it runs
+	job, err := s.jobs.Enqueue(thumbnailJob{AttachmentID: a.ID}, jobs.WithOwner(a.UserID))
+	if err != nil {
+		log.Printf("Failed to start the thumbnails of attachment %s: %v", a.ID, err)
+		a.ThumbnailStatus = attachment.ThumbnailsFailed
+		return
+	}
+	a.ThumbnailStatus = attachment.ThumbnailsPending
+	a.ThumbnailJobID = job.ID
+}
+
+// refreshThumbnails marks pending thumbnails whose job gave up as failed. The lock must be
+// held.
+func (s *service) refreshThumbnails(a *attachment.Attachment) {
+	if a.ThumbnailStatus != attachment.ThumbnailsPending {
+		return
+	}
+	job, err := s.jobs.Get(a.ThumbnailJobID)
+	if err != nil || job.Status == jobs.StatusFailed || job.Status == jobs.StatusDead {
+		a.ThumbnailStatus = attachment.ThumbnailsFailed
+	}
+}
+
+// generateThumbnails scales the image of an attachment down to each size and uploads the
+// thumbnails next to it. Images that cannot be decoded are not retried.
+func (s *service) generateThumbnails(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	a, ok := s.attachments[id]
+	if !ok || a.ThumbnailStatus != attachment.ThumbnailsPending {
+		// The attachment was deleted or its thumbnails generated in the meantime
+		s.mu.Unlock()
+		return nil
+	}
+	key := a.Key
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	data, err := s.readFile(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return jobs.Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	images, err := thumbnail.Generate(bytes.NewReader(data), s.config.ThumbnailSizes)
+	if err != nil {
+		log.Printf("Failed to generate the thumbnails of attachment %s: %v", id, err)
+		return jobs.Permanent(err)
+	}
+
+	thumbnails := make([]attachment.Thumbnail, len(images))
+	for i, image := range images {
+		size := s.config.ThumbnailSizes[i]
+		thumbnails[i] = attachment.Thumbnail{
+			Size:        size,
+			Width:       image.Width,
+			Height:      image.Height,
+			ContentType: image.ContentType,
+			Key:         attachment.ThumbnailKey(key, size),
+		}
+		if err := s.store.Upload(ctx, thumbnails[i].Key, image.ContentType, image.Data); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	if a, ok = s.attachments[id]; ok {
+		a.Thumbnails = thumbnails
+		a.ThumbnailStatus = attachment.ThumbnailsReady
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		s.deleteFiles(thumbnailKeys(&attachment.Attachment{Thumbnails: thumbnails}))
+	}
+	return nil
+}
+
+// readFile reads the stored file under the key
+func (s *service) readFile(ctx context.Context, key string) ([]byte, error) {
+	file, err := s.store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// presignThumbnails sets the URLs the ready thumbnails of a listed attachment are
+// downloaded from
+func (s *service) presignThumbnails(a *attachment.Attachment) error {
+	if a.ThumbnailStatus != attachment.ThumbnailsReady {
+		return nil
+	}
+	for i := range a.Thumbnails {
+		t := &a.Thumbnails[i]
+		download, err := s.store.PresignDownload(t.Key, thumbnailFilename(a.Filename, *t), s.config.URLTTL)
+		if err != nil {
+			return storageError(err)
+		}
+		t.URL = download.URL
+		t.ExpiresAt = &download.ExpiresAt
+	}
+	return nil
+}
+
+// thumbnailFilename returns the name thumbnails of the file are saved under, such as
+// photo-128.jpg
+func thumbnailFilename(filename string, t attachment.Thumbnail) string {
+	ext := ".png"
+	if t.ContentType == "image/jpeg" {
+		ext = ".jpg"
+	}
+	return strings.TrimSuffix(filename, path.Ext(filename)) + "-" + strconv.Itoa(t.Size) + ext
+}
+
+// thumbnailKeys returns the keys the thumbnails of an attachment are stored under
+func thumbnailKeys(a *attachment.Attachment) []string {
+	keys := make([]string, len(a.Thumbnails))
+	for i, t := range a.Thumbnails {
+		keys[i] = t.Key
+	}
+	return keys
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"maps"
+	"sync"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/events"
+	"todo-api/pkg/types"
+)
+
+// Service defines the audit log service interface. The log is append-only: entries can
+// be recorded and listed but never changed or removed.
+type Service interface {
+	Record(entry *audit.Entry)
+	List(filter *audit.Filter, page, limit int) ([]*audit.Entry, *types.PaginationInfo)
+}
+
+// service implements the audit log service
+type service struct {
+	mu      sync.RWMutex
+	entries []audit.Entry // Mock audit storage, oldest first
+	bus     events.Bus    // optional, publishes recorded entries
+}
+
+// NewService creates a new audit log service
+func NewService() Service {
+	return &service{}
+}
+
+// NewServiceWithEventBus creates a new audit log service publishing each recorded entry on
+// the bus, for consumers of security events such as the event stream
+func NewServiceWithEventBus(bus events.Bus) Service {
+	return &service{bus: bus}
+}
+
+// Record appends an entry to the audit log. Later changes to the entry are not recorded.
+func (s *service) Record(entry *audit.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded := *entry
+	recorded.Details = maps.Clone(entry.Details)
+	s.entries = append(s.entries, recorded)
+
+	// Published under the lock, so entries are published in the order they are recorded
+	if s.bus != nil {
+		published := recorded
+		published.Details = maps.Clone(entry.Details)
+		s.bus.Publish(&published)
+	}
+}
+
+// List returns a page of the entries matching the filter, newest first. The entries are
+// copies, so callers cannot change the log.
+func (s *service) List(filter *audit.Filter, page, limit int) ([]*audit.Entry, *types.PaginationInfo) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []*audit.Entry
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if filter.Matches(&s.entries[i]) {
+			entry := s.entries[i]
+			matched = append(matched, &entry)
+		}
+	}
+
+	total := len(matched)
+	paginationInfo := &types.PaginationInfo{
+		Page:       page,
+		Limit:      limit,
+		Total:      int64(total),
+		TotalPages: (total + limit - 1) / limit,
+	}
+
+	start := min((page-1)*limit, total)
+	end := min(start+limit, total)
+	return append([]*audit.Entry{}, matched[start:end]...), paginationInfo
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/events"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RecordAndList(t *testing.T) {
+	service := NewService()
+	userID := uuid.New()
+
+	service.Record(audit.NewEntry(audit.ActionLoginFailed, nil))
+	service.Record(audit.NewEntry(audit.ActionLoginSucceeded, &userID))
+	refreshed := audit.NewEntry(audit.ActionTokenRefreshed, &userID)
+	service.Record(refreshed)
+	service.Record(audit.NewEntry(audit.ActionTenantCreated, &userID))
+
+	// Newest first
+	entries, pagination := service.List(nil, 1, 10)
+	require.Len(t, entries, 4)
+	assert.Equal(t, audit.ActionTenantCreated, entries[0].Action)
+	assert.Equal(t, int64(4), pagination.Total)
+
+	entries, pagination = service.List(&audit.Filter{UserID: &userID, Action: "auth"}, 1, 1)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.ActionTokenRefreshed, entries[0].Action)
+	assert.Equal(t, 2, pagination.TotalPages)
+
+	entries, _ = service.List(&audit.Filter{UserID: &userID, Action: "auth"}, 3, 1)
+	assert.NotNil(t, entries)
+	assert.Empty(t, entries)
+
+	// Recorded entries cannot be changed afterwards
+	refreshed.Action = audit.ActionAccountErased
+	entries, _ = service.List(&audit.Filter{Action: "account"}, 1, 10)
+	assert.Empty(t, entries)
+
+	entries, _ = service.List(&audit.Filter{Action: "auth.token_refreshed"}, 1, 10)
+	require.Len(t, entries, 1)
+	entries[0].Action = audit.ActionAccountErased
+	entries, _ = service.List(&audit.Filter{Action: "auth.token_refreshed"}, 1, 10)
+	assert.Len(t, entries, 1)
+}
+
+func TestService_PublishesEntries(t *testing.T) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	received := make(chan events.Event, 1)
+	bus.Subscribe(func(event events.Event) { received <- event })
+
+	userID := uuid.New()
+	NewServiceWithEventBus(bus).Record(audit.NewEntry(audit.ActionLoginSucceeded, &userID))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "auth.login_succeeded", event.EventName())
+		assert.Equal(t, userID.String(), event.(events.Keyed).EventKey())
+	case <-time.After(time.Second):
+		t.Fatal("entry not published")
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/tenant"
+	"todo-api/pkg/ldap"
+)
+
+// errPasswordInDirectory is returned for password resets when passwords are kept in the
+// directory
+var errPasswordInDirectory = errors.New("password is managed by the directory")
+
+// authenticateWithDirectory checks the password against the directory, returning the user
+// with the email. A user is provisioned in the default tenant on their first login; users
+// who exist already, such as those created before the directory was enabled, are linked by
+// their email.
+func (s *service) authenticateWithDirectory(email, password string) (*auth.User, error) {
+	if _, err := s.directory.Authenticate(context.Background(), email, password); err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		log.Printf("Failed to authenticate %s against the directory: %v", email, err)
+		return nil, auth.ErrDirectoryUnavailable
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, exists := s.users[email]; exists {
+		return user, nil
+	}
+
+	user := auth.NewUser(email, "")
+	user.TenantID = tenant.DefaultTenantID
+	s.users[email] = user
+	log.Printf("Provisioned user %s on their first login through the directory", email)
+	return user, nil
+}
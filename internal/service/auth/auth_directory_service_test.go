@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/tenant"
+	"todo-api/pkg/config"
+	"todo-api/pkg/ldap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirectory accepts the passwords of its users, failing every login when down
+type fakeDirectory struct {
+	passwords map[string]string
+	down      bool
+}
+
+func (d *fakeDirectory) Authenticate(_ context.Context, username, password string) (string, error) {
+	if d.down {
+		return "", errors.New("ldap: dial tcp: connection refused")
+	}
+	if expected, ok := d.passwords[username]; !ok || expected != password {
+		return "", ldap.ErrInvalidCredentials
+	}
+	return "uid=" + username + ",dc=example,dc=com", nil
+}
+
+func newDirectoryTestService(directory ldap.Client) Service {
+	return NewServiceWithDirectory(&config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		Account: config.AccountConfig{PasswordResetTTL: time.Hour},
+	}, directory)
+}
+
+func TestService_Login_Directory(t *testing.T) {
+	directory := &fakeDirectory{passwords: map[string]string{
+		"new.hire@example.com": "directory-secret",
+		"john.doe@example.com": "directory-secret",
+	}}
+	service := newDirectoryTestService(directory)
+
+	// Users are provisioned in the default tenant on their first login
+	_, err := service.GetUserByEmail("new.hire@example.com")
+	require.Error(t, err)
+	tokenResp, err := service.Login(&auth.LoginRequest{Email: "new.hire@example.com", Password: "directory-secret"})
+	require.NoError(t, err)
+	claims, err := service.ValidateToken(tokenResp.AccessToken)
+	require.NoError(t, err)
+	user, err := service.GetUserByEmail("new.hire@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+	assert.Equal(t, tenant.DefaultTenantID, user.TenantID)
+
+	// Later logins keep the same user
+	tokenResp, err = service.Login(&auth.LoginRequest{Email: "new.hire@example.com", Password: "directory-secret"})
+	require.NoError(t, err)
+	claims, err = service.ValidateToken(tokenResp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	// Existing users are linked by email, and their local password no longer works
+	john, err := service.GetUserByEmail("john.doe@example.com")
+	require.NoError(t, err)
+	tokenResp, err = service.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "directory-secret"})
+	require.NoError(t, err)
+	claims, err = service.ValidateToken(tokenResp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, john.ID, claims.UserID)
+	_, err = service.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	assert.EqualError(t, err, "invalid email or password")
+
+	_, err = service.Login(&auth.LoginRequest{Email: "nobody@example.com", Password: "directory-secret"})
+	assert.EqualError(t, err, "invalid email or password")
+	_, err = service.GetUserByEmail("nobody@example.com")
+	assert.Error(t, err)
+
+	directory.down = true
+	_, err = service.Login(&auth.LoginRequest{Email: "new.hire@example.com", Password: "directory-secret"})
+	assert.ErrorIs(t, err, auth.ErrDirectoryUnavailable)
+}
+
+func TestService_ResetPassword_Directory(t *testing.T) {
+	service := newDirectoryTestService(&fakeDirectory{})
+
+	_, _, err := service.CreatePasswordResetToken("john.doe@example.com")
+	assert.EqualError(t, err, "password is managed by the directory")
+
+	_, err = service.ResetPassword(&auth.ResetPasswordRequest{Token: "token", Password: "new-password"})
+	assert.EqualError(t, err, "password is managed by the directory")
+}
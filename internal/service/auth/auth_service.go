@@ -2,10 +2,16 @@ package auth
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/seed"
 	"todo-api/pkg/config"
+	"todo-api/pkg/ldap"
 	"todo-api/pkg/utils"
 
 	"github.com/google/uuid"
@@ -14,52 +20,111 @@ import (
 // Service defines the authentication service interface
 type Service interface {
 	Login(req *auth.LoginRequest) (*auth.TokenResponse, error)
+	Refresh(req *auth.RefreshRequest) (*auth.TokenResponse, error)
 	ValidateToken(token string) (*utils.JWTClaims, error)
 	GetUserByEmail(email string) (*auth.User, error)
+	GetUserByID(id uuid.UUID) (*auth.User, error)
+	DeleteUser(id uuid.UUID) error
+	CreateUser(user *auth.User) error
+	UpdateUser(id uuid.UUID, email string, active bool) (*auth.User, error)
+	SetPlan(id uuid.UUID, plan, customerID string) (*auth.User, error)
+	ListUsers() []*auth.User
+	CreatePasswordResetToken(email string) (*auth.User, string, error)
+	ResetPassword(req *auth.ResetPasswordRequest) (*auth.User, error)
+	CreateEmailVerificationToken(userID uuid.UUID) (*auth.User, string, error)
+	VerifyEmail(req *auth.VerifyEmailRequest) (*auth.User, error)
+	ListSessions(userID uuid.UUID) []*auth.Session
+	RevokeSession(userID, sessionID uuid.UUID) error
 }
 
+// errInvalidCredentials is returned for unknown emails and wrong passwords alike
+var errInvalidCredentials = errors.New("invalid email or password")
+
+// AcmeTenantID is the tenant of the fixture user alice@acme.example.com
+var AcmeTenantID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+// AcmeSlug is the slug of the tenant of alice@acme.example.com
+const AcmeSlug = "acme"
+
 // service implements the authentication service
 type service struct {
-	config *config.Config
-	users  map[string]*auth.User // Mock user storage
+	config    *config.Config
+	mu        sync.RWMutex                // guards users, which logins through the directory add to, and sessions
+	users     map[string]*auth.User       // Mock user storage
+	tokens    map[string]*accountToken    // Password reset and verification tokens by hash
+	sessions  map[uuid.UUID]*auth.Session // Login sessions refresh tokens are bound to, by ID
+	directory ldap.Client                 // directory passwords are checked against, if any
 }
 
-// NewService creates a new authentication service
+// NewService creates a new authentication service, checking passwords against the
+// directory when the auth provider is ldap
 func NewService(cfg *config.Config) Service {
-	// Initialize mock users
-	users := make(map[string]*auth.User)
+	return NewServiceWithDirectory(cfg, newDirectory(cfg))
+}
 
-	// Create some mock users with fixed UUIDs
-	user1 := &auth.User{
-		ID:        uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"),
-		Email:     "john.doe@example.com",
-		Password:  "password123",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+// NewServiceWithUsers creates a new authentication service starting with the given users
+// instead of those of the default fixtures, such as fixtures loaded from another file.
+// Passwords are checked against the directory like NewService.
+func NewServiceWithUsers(cfg *config.Config, users []*auth.User) Service {
+	byEmail := make(map[string]*auth.User, len(users))
+	for _, user := range users {
+		byEmail[user.Email] = user
+	}
+	return newService(cfg, newDirectory(cfg), byEmail)
+}
+
+// newDirectory returns the directory passwords are checked against when the auth provider is
+// ldap, or nil
+func newDirectory(cfg *config.Config) ldap.Client {
+	if cfg.Auth.Provider == "ldap" {
+		return cfg.LDAP.NewClient()
+	}
+	return nil
+}
+
+// NewServiceWithDirectory creates a new authentication service starting with the users of
+// the default fixtures, checking passwords against the directory. Users are provisioned on
+// their first login. A nil directory checks local passwords instead. The password of the
+// fixture platform admin is published, so the admin is only created in development.
+func NewServiceWithDirectory(cfg *config.Config, directory ldap.Client) Service {
+	users, err := seed.Default().NewUsers(fixtureTenants{})
+	if err != nil {
+		panic(fmt.Sprintf("invalid default fixtures: %v", err))
 	}
-	users["john.doe@example.com"] = user1
 
-	user2 := &auth.User{
-		ID:        uuid.MustParse("550e8400-e29b-41d4-a716-446655440002"),
-		Email:     "jane.smith@example.com",
-		Password:  "password123",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	byEmail := make(map[string]*auth.User, len(users))
+	for _, user := range users {
+		if user.Admin && !cfg.IsDevelopment() {
+			continue
+		}
+		byEmail[user.Email] = user
 	}
-	users["jane.smith@example.com"] = user2
+	return newService(cfg, directory, byEmail)
+}
+
+// fixtureTenants resolves the tenants of the default fixture users, which the tenant service
+// starts with
+type fixtureTenants struct{}
 
-	user3 := &auth.User{
-		ID:        uuid.MustParse("550e8400-e29b-41d4-a716-446655440003"),
-		Email:     "mike.wilson@example.com",
-		Password:  "password123",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+func (fixtureTenants) GetTenantBySlug(slug string) (*tenant.Tenant, error) {
+	switch slug {
+	case tenant.DefaultSlug:
+		return &tenant.Tenant{ID: tenant.DefaultTenantID, Slug: slug}, nil
+	case AcmeSlug:
+		return &tenant.Tenant{ID: AcmeTenantID, Slug: slug}, nil
+	default:
+		return nil, fmt.Errorf("tenant %s is not a tenant of the default fixtures", slug)
 	}
-	users["mike.wilson@example.com"] = user3
+}
 
+// newService creates an authentication service with the users by email
+func newService(cfg *config.Config, directory ldap.Client, users map[string]*auth.User) *service {
 	return &service{
-		config: cfg,
-		users:  users,
+		config:    cfg,
+		users:     users,
+		tokens:    make(map[string]*accountToken),
+		sessions:  make(map[uuid.UUID]*auth.Session),
+		directory: directory,
 	}
 }
 
@@ -70,34 +135,136 @@ func (s *service) Login(req *auth.LoginRequest) (*auth.TokenResponse, error) {
 		return nil, err
 	}
 
-	// Find user by email
-	user, exists := s.users[req.Email]
-	if !exists {
-		return nil, errors.New("invalid email or password")
+	var user *auth.User
+	if s.directory != nil {
+		var err error
+		if user, err = s.authenticateWithDirectory(req.Email, req.Password); err != nil {
+			return nil, err
+		}
+	} else {
+		// Find user by email
+		s.mu.RLock()
+		found, exists := s.users[req.Email]
+		s.mu.RUnlock()
+		if !exists {
+			return nil, errInvalidCredentials
+		}
+
+		// Check password (in a real app, you'd hash and compare)
+		if found.Password != req.Password {
+			return nil, errInvalidCredentials
+		}
+		user = found
+	}
+
+	if !user.IsActive() {
+		return nil, auth.ErrUserDeactivated
+	}
+
+	// Default to every scope the user may hold when none are requested
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = grantableScopes(user)
+	}
+
+	if err := checkGrantable(user, scopes); err != nil {
+		return nil, err
+	}
+
+	session := s.startSession(user, req)
+	return s.issueTokens(user, session.ID, scopes)
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+// Requested scopes must be a subset of the scopes granted to the refresh token, and the client
+// must be the one the token was issued to.
+func (s *service) Refresh(req *auth.RefreshRequest) (*auth.TokenResponse, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.ValidateToken(req.RefreshToken)
+	if err != nil || claims.Type != utils.TokenTypeRefresh {
+		return nil, errInvalidRefreshToken
+	}
+
+	s.mu.RLock()
+	user, exists := s.users[claims.Email]
+	s.mu.RUnlock()
+	if !exists || user.ID != claims.UserID || !user.IsActive() {
+		return nil, errInvalidRefreshToken
+	}
+
+	scopes := claims.Scopes
+	if len(scopes) == 0 {
+		scopes = grantableScopes(user)
+	}
+
+	if len(req.Scopes) > 0 {
+		for _, scope := range req.Scopes {
+			if !claims.HasScope(scope) {
+				return nil, errors.New("requested scope exceeds granted scopes: " + scope)
+			}
+		}
+		scopes = req.Scopes
+	}
+
+	if err := checkGrantable(user, scopes); err != nil {
+		return nil, err
+	}
+
+	if err := s.useSession(claims.SessionID(), user.ID, req.ClientID); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user, claims.SessionID(), scopes)
+}
+
+// grantableScopes returns every scope the user may hold
+func grantableScopes(user *auth.User) []string {
+	if user.Admin {
+		return append(slices.Clone(auth.AllScopes), auth.AdminScopes...)
 	}
+	return auth.AllScopes
+}
 
-	// Check password (in a real app, you'd hash and compare)
-	if user.Password != req.Password {
-		return nil, errors.New("invalid email or password")
+// checkGrantable rejects admin scopes requested for users who are not platform admins
+func checkGrantable(user *auth.User, scopes []string) error {
+	for _, scope := range scopes {
+		if auth.IsAdminScope(scope) && !user.Admin {
+			return errors.New("scope not permitted: " + scope)
+		}
 	}
+	return nil
+}
 
+// issueTokens generates an access and refresh token pair for the user in the session
+func (s *service) issueTokens(user *auth.User, sessionID uuid.UUID, scopes []string) (*auth.TokenResponse, error) {
 	// Generate access token
-	accessToken, err := utils.GenerateToken(
-		s.config.JWT.SecretKey,
+	accessToken, err := utils.GenerateSessionToken(
+		s.config.JWTSecretKey(),
+		utils.TokenTypeAccess,
 		user.ID,
+		user.TenantID,
+		sessionID,
 		user.Email,
 		s.config.JWT.AccessTokenTTL,
+		scopes...,
 	)
 	if err != nil {
 		return nil, errors.New("failed to generate access token")
 	}
 
 	// Generate refresh token
-	refreshToken, err := utils.GenerateToken(
-		s.config.JWT.SecretKey,
+	refreshToken, err := utils.GenerateSessionToken(
+		s.config.JWTSecretKey(),
+		utils.TokenTypeRefresh,
 		user.ID,
+		user.TenantID,
+		sessionID,
 		user.Email,
 		s.config.JWT.RefreshTokenTTL,
+		scopes...,
 	)
 	if err != nil {
 		return nil, errors.New("failed to generate refresh token")
@@ -108,19 +275,149 @@ func (s *service) Login(req *auth.LoginRequest) (*auth.TokenResponse, error) {
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    int64(s.config.JWT.AccessTokenTTL.Seconds()),
+		Scopes:       scopes,
 	}, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateToken validates a JWT token and returns the claims. Tokens signed with the
+// previous JWT secret stay valid for a while after it is rotated. Access tokens of a session
+// stop being valid when the session ends.
 func (s *service) ValidateToken(token string) (*utils.JWTClaims, error) {
-	return utils.ValidateToken(token, s.config.JWT.SecretKey)
+	claims, err := s.verifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.IsAccessToken() && claims.SessionID() != uuid.Nil && !s.sessionLive(claims.SessionID(), claims.UserID) {
+		return nil, errSessionEnded
+	}
+	return claims, nil
+}
+
+// verifyToken checks the signature and expiry of a token with each JWT secret it may be
+// signed with, returning the error of the current secret when none verifies it
+func (s *service) verifyToken(token string) (*utils.JWTClaims, error) {
+	var firstErr error
+	for _, key := range s.config.JWTVerificationKeys() {
+		claims, err := utils.ValidateToken(token, key)
+		if err == nil {
+			return claims, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
 }
 
 // GetUserByEmail retrieves a user by email
 func (s *service) GetUserByEmail(email string) (*auth.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	user, exists := s.users[email]
 	if !exists {
 		return nil, errors.New("user not found")
 	}
 	return user, nil
 }
+
+// GetUserByID retrieves a user by ID
+func (s *service) GetUserByID(id uuid.UUID) (*auth.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+// DeleteUser deletes a user account. The user can no longer log in or refresh tokens.
+func (s *service) DeleteUser(id uuid.UUID) error {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.users, user.Email)
+	s.endSessions(user.ID)
+	s.mu.Unlock()
+	return nil
+}
+
+// CreateUser adds a user, failing when another user has the email
+func (s *service) CreateUser(user *auth.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.Email]; exists {
+		return auth.ErrEmailTaken
+	}
+	s.users[user.Email] = user
+	return nil
+}
+
+// UpdateUser changes the email of a user and deactivates or reactivates them. Deactivating a
+// user ends their sessions, like deleting them. The user is replaced rather than changed in
+// place, so users returned earlier are not modified.
+func (s *service) UpdateUser(id uuid.UUID, email string, active bool) (*auth.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current *auth.User
+	for _, user := range s.users {
+		if user.ID == id {
+			current = user
+			break
+		}
+	}
+	if current == nil {
+		return nil, errors.New("user not found")
+	}
+	if other, exists := s.users[email]; exists && other.ID != id {
+		return nil, auth.ErrEmailTaken
+	}
+
+	updated := *current
+	updated.Email = email
+	if email != current.Email {
+		// The new address has not been proven
+		updated.EmailVerifiedAt = nil
+	}
+	switch {
+	case active:
+		updated.DeactivatedAt = nil
+	case current.IsActive():
+		now := time.Now()
+		updated.DeactivatedAt = &now
+		s.endSessions(id)
+	}
+	updated.UpdatedAt = time.Now()
+
+	delete(s.users, current.Email)
+	s.users[email] = &updated
+	return &updated, nil
+}
+
+// SetPlan changes the subscription plan of a user and the Stripe customer paying for it.
+// Like UpdateUser, the user is replaced rather than changed in place.
+func (s *service) SetPlan(id uuid.UUID, plan, customerID string) (*auth.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for email, user := range s.users {
+		if user.ID == id {
+			updated := *user
+			updated.Plan = plan
+			updated.StripeCustomerID = customerID
+			updated.UpdatedAt = time.Now()
+			s.users[email] = &updated
+			return &updated, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
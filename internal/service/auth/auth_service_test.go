@@ -1,11 +1,19 @@
 package auth
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/seed"
 	"todo-api/pkg/config"
+	"todo-api/pkg/utils"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +34,55 @@ func TestNewService(t *testing.T) {
 	assert.NotNil(t, service)
 }
 
+func TestNewService_DefaultFixtures(t *testing.T) {
+	development := &config.Config{App: config.AppConfig{Environment: "development"}}
+	service := NewService(development)
+
+	// The service starts with the users of the default fixtures
+	fixtures := seed.Default()
+	users := service.ListUsers()
+	require.Len(t, users, len(fixtures.Users))
+	for _, fixture := range fixtures.Users {
+		user, err := service.GetUserByEmail(fixture.Email)
+		require.NoError(t, err)
+		assert.Equal(t, fixture.ID, user.ID)
+		assert.Equal(t, fixture.Admin, user.Admin)
+	}
+	alice, err := service.GetUserByEmail("alice@acme.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, AcmeTenantID, alice.TenantID)
+	john, err := service.GetUserByEmail("john.doe@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, tenant.DefaultTenantID, john.TenantID)
+
+	// Each service has its own copy of the users
+	require.NoError(t, service.DeleteUser(john.ID))
+	_, err = NewService(development).GetUserByEmail("john.doe@example.com")
+	assert.NoError(t, err)
+
+	// The platform admin, whose password is published, is only created in development
+	for _, env := range []string{"production", "staging"} {
+		service := NewService(&config.Config{App: config.AppConfig{Environment: env}})
+		_, err := service.GetUserByEmail("admin@example.com")
+		assert.Error(t, err, env)
+		assert.Len(t, service.ListUsers(), len(fixtures.Users)-1, env)
+	}
+}
+
+func TestNewServiceWithUsers(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}}
+	sam := &auth.User{ID: uuid.New(), Email: "sam@example.com", Password: "password123"}
+
+	service := NewServiceWithUsers(cfg, []*auth.User{sam})
+
+	// The mock users are replaced by the given users
+	assert.Equal(t, []*auth.User{sam}, service.ListUsers())
+	_, err := service.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	assert.Error(t, err)
+	_, err = service.Login(&auth.LoginRequest{Email: "sam@example.com", Password: "password123"})
+	assert.NoError(t, err)
+}
+
 func TestService_Login_ValidCredentials(t *testing.T) {
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
@@ -148,6 +205,13 @@ func TestService_ValidateToken_ValidToken(t *testing.T) {
 	assert.NotNil(t, claims)
 	assert.Equal(t, uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"), claims.UserID)
 	assert.Equal(t, "john.doe@example.com", claims.Email)
+	assert.True(t, claims.IsAccessToken())
+
+	// Refresh tokens are marked as such, so they are not accepted as access tokens
+	claims, err = service.ValidateToken(tokenResp.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, utils.TokenTypeRefresh, claims.Type)
+	assert.False(t, claims.IsAccessToken())
 }
 
 func TestService_ValidateToken_InvalidToken(t *testing.T) {
@@ -167,6 +231,177 @@ func TestService_ValidateToken_InvalidToken(t *testing.T) {
 	assert.Nil(t, claims)
 }
 
+func TestService_Login_WithScopes(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	service := NewService(cfg)
+
+	req := &auth.LoginRequest{
+		Email:    "john.doe@example.com",
+		Password: "password123",
+		Scopes:   []string{auth.ScopeTasksRead},
+	}
+
+	tokenResp, err := service.Login(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.ScopeTasksRead}, tokenResp.Scopes)
+
+	claims, err := service.ValidateToken(tokenResp.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.HasScope(auth.ScopeTasksRead))
+	assert.False(t, claims.HasScope(auth.ScopeTasksWrite))
+}
+
+func TestService_Login_AdminScopes(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		App: config.AppConfig{Environment: "development"},
+	}
+
+	service := NewService(cfg)
+
+	// Platform admins are granted the admin scopes by default
+	tokenResp, err := service.Login(&auth.LoginRequest{
+		Email:    "admin@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, tokenResp.Scopes, auth.ScopeTenantsAdmin)
+	assert.Contains(t, tokenResp.Scopes, auth.ScopeAuditRead)
+	assert.Contains(t, tokenResp.Scopes, auth.ScopeJobsAdmin)
+
+	// Other users are neither granted nor allowed to request them
+	tokenResp, err = service.Login(&auth.LoginRequest{
+		Email:    "john.doe@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, tokenResp.Scopes, auth.ScopeTenantsAdmin)
+
+	_, err = service.Login(&auth.LoginRequest{
+		Email:    "john.doe@example.com",
+		Password: "password123",
+		Scopes:   []string{auth.ScopeTenantsAdmin},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "scope not permitted: tenants:admin", err.Error())
+}
+
+func TestService_Login_TenantClaim(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	service := NewService(cfg)
+
+	tokenResp, err := service.Login(&auth.LoginRequest{
+		Email:    "alice@acme.example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(tokenResp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, AcmeTenantID, claims.TenantID)
+}
+
+func TestService_Refresh(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	service := NewService(cfg)
+
+	tokenResp, err := service.Login(&auth.LoginRequest{
+		Email:    "john.doe@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, auth.AllScopes, tokenResp.Scopes)
+
+	// Narrow the scopes on refresh
+	refreshed, err := service.Refresh(&auth.RefreshRequest{
+		RefreshToken: tokenResp.RefreshToken,
+		Scopes:       []string{auth.ScopeTasksRead},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.ScopeTasksRead}, refreshed.Scopes)
+
+	// Narrowed refresh tokens cannot widen the scopes again
+	_, err = service.Refresh(&auth.RefreshRequest{
+		RefreshToken: refreshed.RefreshToken,
+		Scopes:       []string{auth.ScopeTasksWrite},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "requested scope exceeds granted scopes: tasks:write", err.Error())
+
+	// Invalid refresh tokens are rejected
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: "invalid-token"})
+	require.Error(t, err)
+	assert.Equal(t, "invalid or expired refresh token", err.Error())
+}
+
+func TestService_ValidateToken_RotatedSecret(t *testing.T) {
+	var secret atomic.Value
+	secret.Store("before-rotation")
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":{"data":{"JWT_SECRET_KEY":%q},"metadata":{}}}`, secret.Load())
+	}))
+	defer vault.Close()
+	cfg, err := config.LoadWithOverrides(map[string]string{
+		"SECRETS_PROVIDER":         "vault",
+		"VAULT_ADDR":               vault.URL,
+		"VAULT_TOKEN":              "root",
+		"SECRETS_VAULT_PATH":       "secret/data/todo-api",
+		"SECRETS_REFRESH_INTERVAL": "10ms",
+	})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cfg.WatchSecrets(ctx)
+
+	service := NewService(cfg)
+	before, err := service.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	secret.Store("after-rotation")
+	require.Eventually(t, func() bool { return cfg.JWTSecretKey() == "after-rotation" }, time.Second, 5*time.Millisecond)
+
+	// Tokens issued before the rotation stay valid, and are refreshed into tokens signed with
+	// the new secret
+	claims, err := service.ValidateToken(before.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", claims.Email)
+	refreshed, err := service.Refresh(&auth.RefreshRequest{RefreshToken: before.RefreshToken})
+	require.NoError(t, err)
+	_, err = utils.ValidateToken(refreshed.AccessToken, "after-rotation")
+	assert.NoError(t, err)
+
+	// Tokens signed with other secrets are still rejected
+	forged, err := utils.GenerateToken("other-secret", claims.UserID, claims.Email, time.Minute)
+	require.NoError(t, err)
+	_, err = service.ValidateToken(forged)
+	assert.Error(t, err)
+}
+
 func TestService_GetUserByEmail_ExistingUser(t *testing.T) {
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
@@ -205,6 +440,116 @@ func TestService_GetUserByEmail_NonExistingUser(t *testing.T) {
 	assert.Equal(t, "user not found", err.Error())
 }
 
+func TestService_GetUserByID(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	service := NewService(cfg)
+
+	user, err := service.GetUserByID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440002"))
+	require.NoError(t, err)
+	assert.Equal(t, "jane.smith@example.com", user.Email)
+
+	user, err = service.GetUserByID(uuid.New())
+	require.Error(t, err)
+	assert.Nil(t, user)
+	assert.Equal(t, "user not found", err.Error())
+}
+
+func TestService_DeleteUser(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	service := NewService(cfg)
+	janeID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+
+	require.NoError(t, service.DeleteUser(janeID))
+
+	_, err := service.GetUserByID(janeID)
+	assert.Error(t, err)
+
+	_, err = service.Login(&auth.LoginRequest{Email: "jane.smith@example.com", Password: "password123"})
+	assert.Error(t, err)
+
+	err = service.DeleteUser(janeID)
+	require.Error(t, err)
+	assert.Equal(t, "user not found", err.Error())
+}
+
+func TestService_UpdateUser_Deactivate(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	service := NewService(cfg)
+	janeID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	tokens, err := service.Login(&auth.LoginRequest{Email: "jane.smith@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	// Deactivating the user ends their sessions, so their tokens stop working at once
+	updated, err := service.UpdateUser(janeID, "jane.smith@example.com", false)
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive())
+	assert.Empty(t, service.ListSessions(janeID))
+
+	_, err = service.ValidateToken(tokens.AccessToken)
+	assert.Error(t, err)
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	assert.Error(t, err)
+	_, err = service.Login(&auth.LoginRequest{Email: "jane.smith@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, auth.ErrUserDeactivated)
+
+	// Reactivated users log in again
+	_, err = service.UpdateUser(janeID, "jane.smith@example.com", true)
+	require.NoError(t, err)
+	tokens, err = service.Login(&auth.LoginRequest{Email: "jane.smith@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = service.ValidateToken(tokens.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestService_SetPlan(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	service := NewService(cfg)
+	janeID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	before, err := service.GetUserByID(janeID)
+	require.NoError(t, err)
+
+	updated, err := service.SetPlan(janeID, "pro", "cus_123")
+	require.NoError(t, err)
+	assert.Equal(t, "pro", updated.Plan)
+	assert.Equal(t, "cus_123", updated.StripeCustomerID)
+	assert.Empty(t, before.Plan)
+
+	user, err := service.GetUserByEmail("jane.smith@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, updated, user)
+
+	_, err = service.SetPlan(uuid.New(), "pro", "cus_123")
+	assert.Error(t, err)
+}
+
 func TestService_AllMockUsers(t *testing.T) {
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
@@ -212,6 +557,7 @@ func TestService_AllMockUsers(t *testing.T) {
 			AccessTokenTTL:  15 * time.Minute,
 			RefreshTokenTTL: 7 * 24 * time.Hour,
 		},
+		App: config.AppConfig{Environment: "development"},
 	}
 
 	service := NewService(cfg)
@@ -225,6 +571,8 @@ func TestService_AllMockUsers(t *testing.T) {
 		{"john.doe@example.com", "password123", "3484ec33-20f9-4993-a25f-f49f6f5dbe54"},
 		{"jane.smith@example.com", "password123", "550e8400-e29b-41d4-a716-446655440002"},
 		{"mike.wilson@example.com", "password123", "550e8400-e29b-41d4-a716-446655440003"},
+		{"alice@acme.example.com", "password123", "550e8400-e29b-41d4-a716-446655440004"},
+		{"admin@example.com", "password123", "550e8400-e29b-41d4-a716-446655440010"},
 	}
 
 	for _, mockUser := range mockUsers {
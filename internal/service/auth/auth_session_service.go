@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"todo-api/internal/domain/auth"
+
+	"github.com/google/uuid"
+)
+
+// errInvalidRefreshToken is returned for refresh tokens that are invalid, expired, or whose
+// session was revoked
+var errInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// errSessionEnded is returned for access tokens of a session that was revoked or replaced,
+// or whose user was deleted
+var errSessionEnded = errors.New("session has ended")
+
+// startSession records a login of the user from the client of the request. Logging in again
+// from a client replaces its session, so each client is listed once and the refresh tokens of
+// its previous login stop working. Expired sessions of the user are dropped.
+func (s *service) startSession(user *auth.User, req *auth.LoginRequest) *auth.Session {
+	now := time.Now()
+	session := &auth.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		ClientID:   req.ClientID,
+		UserAgent:  req.UserAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, existing := range s.sessions {
+		if existing.UserID != user.ID {
+			continue
+		}
+		if s.sessionExpired(existing, now) || (req.ClientID != "" && existing.ClientID == req.ClientID) {
+			delete(s.sessions, id)
+		}
+	}
+	s.sessions[session.ID] = session
+
+	stored := *session
+	return &stored
+}
+
+// useSession checks that a refresh token of the session belongs to a live session of the user
+// and is used by the client it was issued to, recording the use
+func (s *service) useSession(sessionID, userID uuid.UUID, clientID string) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, exists := s.sessions[sessionID]
+	if !exists || session.UserID != userID || s.sessionExpired(session, now) {
+		return errInvalidRefreshToken
+	}
+	if session.ClientID != clientID {
+		return auth.ErrClientMismatch
+	}
+	session.LastUsedAt = now
+	return nil
+}
+
+// sessionLive reports whether the session of the user has not ended. Access tokens expire
+// before the refresh tokens of their session, so they are not checked for expiry.
+func (s *service) sessionLive(sessionID, userID uuid.UUID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, exists := s.sessions[sessionID]
+	return exists && session.UserID == userID
+}
+
+// sessionExpired reports whether the refresh tokens of the session all expired, the last
+// having been issued when it was last used. The caller must hold the lock.
+func (s *service) sessionExpired(session *auth.Session, now time.Time) bool {
+	return !now.Before(session.LastUsedAt.Add(s.config.JWT.RefreshTokenTTL))
+}
+
+// ListSessions returns the live sessions of the user, most recently used first
+func (s *service) ListSessions(userID uuid.UUID) []*auth.Session {
+	now := time.Now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var sessions []*auth.Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && !s.sessionExpired(session, now) {
+			listed := *session
+			sessions = append(sessions, &listed)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions
+}
+
+// RevokeSession ends a session of the user, so its access and refresh tokens stop working
+func (s *service) RevokeSession(userID, sessionID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, exists := s.sessions[sessionID]
+	if !exists || session.UserID != userID {
+		return auth.ErrSessionNotFound
+	}
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// endSessions ends every session of the user. The caller must hold the lock.
+func (s *service) endSessions(userID uuid.UUID) {
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+}
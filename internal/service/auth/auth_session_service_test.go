@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RefreshBoundToClient(t *testing.T) {
+	service := newTokenTestService()
+
+	tokens, err := service.Login(&auth.LoginRequest{
+		Email:     "john.doe@example.com",
+		Password:  "password123",
+		ClientID:  "phone-1",
+		UserAgent: "TodoApp/2.1 (iOS)",
+	})
+	require.NoError(t, err)
+
+	// Refresh tokens only work for the client they were issued to
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: tokens.RefreshToken, ClientID: "laptop-1"})
+	assert.ErrorIs(t, err, auth.ErrClientMismatch)
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	assert.ErrorIs(t, err, auth.ErrClientMismatch)
+
+	refreshed, err := service.Refresh(&auth.RefreshRequest{RefreshToken: tokens.RefreshToken, ClientID: "phone-1"})
+	require.NoError(t, err)
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: refreshed.RefreshToken, ClientID: "phone-1"})
+	require.NoError(t, err)
+
+	// Tokens issued outside of a session cannot be refreshed
+	john, err := service.GetUserByEmail("john.doe@example.com")
+	require.NoError(t, err)
+	unbound, err := utils.GenerateToken("test-secret", john.ID, john.Email, time.Hour)
+	require.NoError(t, err)
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: unbound})
+	assert.EqualError(t, err, "invalid or expired refresh token")
+
+	// Access tokens cannot be refreshed
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: refreshed.AccessToken, ClientID: "phone-1"})
+	assert.EqualError(t, err, "invalid or expired refresh token")
+}
+
+func TestService_Sessions(t *testing.T) {
+	service := newTokenTestService()
+	login := func(email, clientID string) *auth.TokenResponse {
+		tokens, err := service.Login(&auth.LoginRequest{Email: email, Password: "password123", ClientID: clientID})
+		require.NoError(t, err)
+		return tokens
+	}
+
+	replaced := login("john.doe@example.com", "phone-1")
+	phone := login("john.doe@example.com", "phone-1")
+	laptop := login("john.doe@example.com", "laptop-1")
+	login("jane.smith@example.com", "phone-2")
+
+	john, err := service.GetUserByEmail("john.doe@example.com")
+	require.NoError(t, err)
+
+	// Logging in again from a client replaces its session
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: replaced.RefreshToken, ClientID: "phone-1"})
+	assert.EqualError(t, err, "invalid or expired refresh token")
+	_, err = service.ValidateToken(replaced.AccessToken)
+	assert.EqualError(t, err, "session has ended")
+
+	sessions := service.ListSessions(john.ID)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "laptop-1", sessions[0].ClientID)
+	assert.Equal(t, "phone-1", sessions[1].ClientID)
+
+	// Revoked sessions cannot be refreshed, and users only revoke their own sessions
+	claims, err := service.ValidateToken(laptop.AccessToken)
+	require.NoError(t, err)
+	jane, err := service.GetUserByEmail("jane.smith@example.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.RevokeSession(jane.ID, claims.SessionID()), auth.ErrSessionNotFound)
+	require.NoError(t, service.RevokeSession(john.ID, claims.SessionID()))
+	assert.ErrorIs(t, service.RevokeSession(john.ID, claims.SessionID()), auth.ErrSessionNotFound)
+	assert.ErrorIs(t, service.RevokeSession(john.ID, uuid.New()), auth.ErrSessionNotFound)
+
+	// Revoked sessions cannot be refreshed, and their access tokens stop working
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: laptop.RefreshToken, ClientID: "laptop-1"})
+	assert.EqualError(t, err, "invalid or expired refresh token")
+	_, err = service.ValidateToken(laptop.AccessToken)
+	assert.EqualError(t, err, "session has ended")
+	_, err = service.Refresh(&auth.RefreshRequest{RefreshToken: phone.RefreshToken, ClientID: "phone-1"})
+	require.NoError(t, err)
+	_, err = service.ValidateToken(phone.AccessToken)
+	require.NoError(t, err)
+	assert.Len(t, service.ListSessions(john.ID), 1)
+
+	// Deleting the account ends its sessions
+	require.NoError(t, service.DeleteUser(john.ID))
+	assert.Empty(t, service.ListSessions(john.ID))
+	_, err = service.ValidateToken(phone.AccessToken)
+	assert.EqualError(t, err, "session has ended")
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	"todo-api/internal/domain/auth"
+
+	"github.com/google/uuid"
+)
+
+// tokenPurpose is what an account token may be used for
+type tokenPurpose string
+
+const (
+	purposePasswordReset     tokenPurpose = "password_reset"
+	purposeEmailVerification tokenPurpose = "email_verification"
+)
+
+// accountToken is a single-use token sent by email to prove control of the address
+type accountToken struct {
+	userID    uuid.UUID
+	purpose   tokenPurpose
+	email     string // address the token was sent to
+	expiresAt time.Time
+}
+
+// errInvalidToken is returned for unknown, expired, or already used tokens
+var errInvalidToken = errors.New("invalid or expired token")
+
+// ListUsers returns every user, oldest first, then by email
+func (s *service) ListUsers() []*auth.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*auth.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].Email < users[j].Email
+	})
+	return users
+}
+
+// CreatePasswordResetToken creates a token allowing the user with the email to choose a new
+// password. Previous password reset tokens of the user are revoked.
+func (s *service) CreatePasswordResetToken(email string) (*auth.User, string, error) {
+	if s.directory != nil {
+		return nil, "", errPasswordInDirectory
+	}
+
+	user, err := s.GetUserByEmail(email)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := s.createToken(user, purposePasswordReset, s.config.Account.PasswordResetTTL)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, token, nil
+}
+
+// ResetPassword sets a new password with a password reset token, using up the token
+func (s *service) ResetPassword(req *auth.ResetPasswordRequest) (*auth.User, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if s.directory != nil {
+		return nil, errPasswordInDirectory
+	}
+
+	user, err := s.useToken(req.Token, purposePasswordReset)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Password = req.Password
+	user.UpdatedAt = time.Now()
+	return user, nil
+}
+
+// CreateEmailVerificationToken creates a token allowing the user to verify their email address.
+// Previous verification tokens of the user are revoked.
+func (s *service) CreateEmailVerificationToken(userID uuid.UUID) (*auth.User, string, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user.IsEmailVerified() {
+		return nil, "", errors.New("email already verified")
+	}
+
+	token, err := s.createToken(user, purposeEmailVerification, s.config.Account.EmailVerificationTTL)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, token, nil
+}
+
+// VerifyEmail marks the email address of the user as verified with a verification token,
+// using up the token
+func (s *service) VerifyEmail(req *auth.VerifyEmailRequest) (*auth.User, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.useToken(req.Token, purposeEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	return user, nil
+}
+
+// createToken creates a random token for the purpose, valid for the TTL. Only a hash of the
+// token is kept, so tokens cannot be recovered from storage.
+func (s *service) createToken(user *auth.User, purpose tokenPurpose, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	for hash, t := range s.tokens {
+		if (t.userID == user.ID && t.purpose == purpose) || now.After(t.expiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+
+	s.tokens[hashToken(token)] = &accountToken{
+		userID:    user.ID,
+		purpose:   purpose,
+		email:     user.Email,
+		expiresAt: now.Add(ttl),
+	}
+	return token, nil
+}
+
+// useToken returns the user of a valid token for the purpose and deletes the token. Tokens
+// are invalidated when the email address of the user changed since they were sent.
+func (s *service) useToken(token string, purpose tokenPurpose) (*auth.User, error) {
+	hash := hashToken(token)
+	t, exists := s.tokens[hash]
+	if !exists || t.purpose != purpose {
+		return nil, errInvalidToken
+	}
+	delete(s.tokens, hash)
+
+	if time.Now().After(t.expiresAt) {
+		return nil, errInvalidToken
+	}
+	user, err := s.GetUserByID(t.userID)
+	if err != nil || user.Email != t.email {
+		return nil, errInvalidToken
+	}
+	return user, nil
+}
+
+// hashToken returns the hash tokens are stored by
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenTestService() Service {
+	return NewService(&config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		Account: config.AccountConfig{
+			PasswordResetTTL:     time.Hour,
+			EmailVerificationTTL: 24 * time.Hour,
+		},
+	})
+}
+
+func TestService_ResetPassword(t *testing.T) {
+	service := newTokenTestService()
+
+	_, _, err := service.CreatePasswordResetToken("nobody@example.com")
+	assert.EqualError(t, err, "user not found")
+
+	_, revoked, err := service.CreatePasswordResetToken("john.doe@example.com")
+	require.NoError(t, err)
+	user, token, err := service.CreatePasswordResetToken("john.doe@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", user.Email)
+
+	// Only the latest token is valid
+	_, err = service.ResetPassword(&auth.ResetPasswordRequest{Token: revoked, Password: "new-password"})
+	assert.EqualError(t, err, "invalid or expired token")
+
+	_, err = service.ResetPassword(&auth.ResetPasswordRequest{Token: token, Password: "new-password"})
+	require.NoError(t, err)
+	_, err = service.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "new-password"})
+	assert.NoError(t, err)
+	_, err = service.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	assert.Error(t, err)
+
+	// Tokens are single-use
+	_, err = service.ResetPassword(&auth.ResetPasswordRequest{Token: token, Password: "other-password"})
+	assert.EqualError(t, err, "invalid or expired token")
+}
+
+func TestService_ResetPassword_Expired(t *testing.T) {
+	service := newTokenTestService().(*service)
+
+	_, token, err := service.CreatePasswordResetToken("john.doe@example.com")
+	require.NoError(t, err)
+	service.tokens[hashToken(token)].expiresAt = time.Now().Add(-time.Second)
+
+	_, err = service.ResetPassword(&auth.ResetPasswordRequest{Token: token, Password: "new-password"})
+	assert.EqualError(t, err, "invalid or expired token")
+}
+
+func TestService_VerifyEmail(t *testing.T) {
+	service := newTokenTestService()
+	jane, err := service.GetUserByEmail("jane.smith@example.com")
+	require.NoError(t, err)
+
+	_, token, err := service.CreateEmailVerificationToken(jane.ID)
+	require.NoError(t, err)
+
+	// Tokens cannot be used for another purpose
+	_, err = service.ResetPassword(&auth.ResetPasswordRequest{Token: token, Password: "new-password"})
+	assert.EqualError(t, err, "invalid or expired token")
+
+	_, token, err = service.CreateEmailVerificationToken(jane.ID)
+	require.NoError(t, err)
+	user, err := service.VerifyEmail(&auth.VerifyEmailRequest{Token: token})
+	require.NoError(t, err)
+	assert.True(t, user.IsEmailVerified())
+
+	_, _, err = service.CreateEmailVerificationToken(jane.ID)
+	assert.EqualError(t, err, "email already verified")
+}
@@ -0,0 +1,434 @@
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"todo-api/internal/domain/automation"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+
+	"github.com/google/uuid"
+)
+
+// maxRulesPerUser bounds the automation rules of a user
+const maxRulesPerUser = 50
+
+// maxExecutionsPerRule bounds the executions kept of each rule, dropping the oldest beyond
+const maxExecutionsPerRule = 100
+
+// maxRunsPerTask bounds the rule runs changing a task within runWindow, so rules undoing
+// each other's changes do not run forever
+const (
+	maxRunsPerTask = 10
+	runWindow      = time.Minute
+)
+
+// ruleJob takes the delayed actions of a rule on a task
+type ruleJob struct {
+	RuleID  uuid.UUID           `json:"rule_id"`
+	TaskID  uuid.UUID           `json:"task_id"`
+	Actions []automation.Action `json:"actions"`
+}
+
+func (ruleJob) JobType() string { return "automation.rule" }
+
+// CreateRule creates an automation rule of the user
+func (s *service) CreateRule(userID uuid.UUID, req *automation.RuleRequest) (*automation.Rule, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rulesOf(userID)) >= maxRulesPerUser {
+		return nil, fmt.Errorf("a user has at most %d automation rules", maxRulesPerUser)
+	}
+	rule := automation.NewRule(userID, req)
+	s.rules[rule.ID] = rule
+
+	return copyRule(rule), nil
+}
+
+// ListRules returns the user's automation rules, oldest first
+func (s *service) ListRules(userID uuid.UUID) []*automation.Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := s.rulesOf(userID)
+	rules := make([]*automation.Rule, len(owned))
+	for i, rule := range owned {
+		rules[i] = copyRule(rule)
+	}
+	return rules
+}
+
+// GetRule returns an automation rule of the user
+func (s *service) GetRule(userID, id uuid.UUID) (*automation.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[id]
+	if !exists || rule.UserID != userID {
+		return nil, automation.ErrRuleNotFound
+	}
+	return copyRule(rule), nil
+}
+
+// UpdateRule replaces the settings of an automation rule of the user. Delayed actions
+// already scheduled take the actions they were scheduled with, if the rule is still enabled
+// and its conditions hold.
+func (s *service) UpdateRule(userID, id uuid.UUID, req *automation.RuleRequest) (*automation.Rule, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[id]
+	if !exists || rule.UserID != userID {
+		return nil, automation.ErrRuleNotFound
+	}
+	req.Apply(rule)
+	return copyRule(rule), nil
+}
+
+// DeleteRule deletes an automation rule of the user with its executions. Its delayed actions
+// are not taken.
+func (s *service) DeleteRule(userID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[id]
+	if !exists || rule.UserID != userID {
+		return automation.ErrRuleNotFound
+	}
+	delete(s.rules, id)
+	delete(s.executions, id)
+	return nil
+}
+
+// ListExecutions returns the recent executions of an automation rule of the user, newest
+// first
+func (s *service) ListExecutions(userID, id uuid.UUID) ([]*automation.Execution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[id]
+	if !exists || rule.UserID != userID {
+		return nil, automation.ErrRuleNotFound
+	}
+
+	kept := s.executions[id]
+	executions := make([]*automation.Execution, 0, len(kept))
+	for i := len(kept) - 1; i >= 0; i-- {
+		e := *kept[i]
+		e.Actions = slices.Clone(kept[i].Actions)
+		executions = append(executions, &e)
+	}
+	return executions, nil
+}
+
+// evaluate runs the rules the task event triggers whose conditions hold for the task. Runs
+// that change nothing and schedule nothing are not recorded.
+func (s *service) evaluate(e *task.Event) {
+	if e.Type != task.EventTaskCreated && e.Type != task.EventTaskUpdated {
+		return
+	}
+
+	s.mu.Lock()
+	var rules []*automation.Rule
+	for _, rule := range s.rulesOf(e.Task.UserID) {
+		if rule.Triggers(e) && rule.Matches(e.Task) {
+			rules = append(rules, copyRule(rule))
+		}
+	}
+	s.mu.Unlock()
+
+	for _, rule := range rules {
+		var now, later []automation.Action
+		for _, action := range rule.Actions {
+			if action.After == "" {
+				now = append(now, action)
+			} else {
+				later = append(later, action)
+			}
+		}
+
+		if len(now) > 0 && !s.allowRun(e.TaskID) {
+			s.record(rule, e.TaskID, automation.ExecutionSkipped, nil,
+				errors.New("too many automation runs on the task, rules may be undoing each other"))
+			continue
+		}
+
+		applied, err := s.apply(rule, e.TaskID, now)
+		scheduled, scheduleErr := s.schedule(rule, e.TaskID, later)
+		err = errors.Join(err, scheduleErr)
+
+		status := automation.ExecutionSucceeded
+		switch {
+		case err != nil:
+			status = automation.ExecutionFailed
+		case len(applied) == 0 && len(scheduled) == 0:
+			continue
+		case len(applied) == 0:
+			status = automation.ExecutionScheduled
+		}
+		s.record(rule, e.TaskID, status, append(applied, scheduled...), err)
+	}
+}
+
+// runDelayed takes the delayed actions of a rule if it is still enabled and its conditions
+// still hold for the task. Actions that fail are not retried.
+func (s *service) runDelayed(job ruleJob) error {
+	if len(job.Actions) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	stored, exists := s.rules[job.RuleID]
+	var rule *automation.Rule
+	if exists {
+		rule = copyRule(stored)
+	}
+	delete(s.pending, pendingKey(job.RuleID, job.TaskID, job.Actions[0].After))
+	s.mu.Unlock()
+	if !exists || !rule.Enabled {
+		return nil
+	}
+
+	t, err := s.taskService.GetTaskByID(job.TaskID, rule.UserID)
+	if err != nil || t.UserID != rule.UserID {
+		// The task was deleted or given away
+		return nil
+	}
+	if !rule.Matches(t) {
+		s.record(rule, t.ID, automation.ExecutionSkipped, nil, errors.New("the conditions no longer hold"))
+		return nil
+	}
+	if !s.allowRun(t.ID) {
+		s.record(rule, t.ID, automation.ExecutionSkipped, nil,
+			errors.New("too many automation runs on the task, rules may be undoing each other"))
+		return nil
+	}
+
+	applied, err := s.apply(rule, t.ID, job.Actions)
+	if err != nil {
+		s.record(rule, t.ID, automation.ExecutionFailed, applied, err)
+		return jobs.Permanent(err)
+	}
+	if len(applied) > 0 {
+		s.record(rule, t.ID, automation.ExecutionSucceeded, applied, nil)
+	}
+	return nil
+}
+
+// apply takes the actions on the task as the user of the rule, skipping those whose change
+// the task already has. It returns the actions that changed the task.
+func (s *service) apply(rule *automation.Rule, taskID uuid.UUID, actions []automation.Action) ([]string, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	t, err := s.taskService.GetTaskByID(taskID, rule.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	req := &task.UpdateTaskRequest{}
+	update, archive := false, false
+	tags := slices.Clone(t.Tags)
+	for _, action := range actions {
+		switch action.Type {
+		case automation.ActionSetStatus:
+			status := task.TaskStatus(action.Value)
+			if t.Status == status && req.Status == nil {
+				continue
+			}
+			req.Status = &status
+		case automation.ActionSetPriority:
+			priority := task.TaskPriority(action.Value)
+			if t.Priority == priority && req.Priority == nil {
+				continue
+			}
+			req.Priority = &priority
+		case automation.ActionAddTag:
+			if slices.Contains(tags, action.Value) {
+				continue
+			}
+			tags = append(tags, action.Value)
+			req.Tags = &tags
+		case automation.ActionRemoveTag:
+			if !slices.Contains(tags, action.Value) {
+				continue
+			}
+			tags = slices.DeleteFunc(tags, func(tag string) bool { return tag == action.Value })
+			req.Tags = &tags
+		case automation.ActionArchive:
+			archive = !t.IsArchived()
+			continue
+		}
+		update = true
+		applied = append(applied, automation.Action{Type: action.Type, Value: action.Value}.String())
+	}
+
+	if update {
+		if _, err := s.taskService.UpdateTask(taskID, req, rule.UserID); err != nil {
+			return nil, err
+		}
+	}
+	if archive {
+		if _, err := s.taskService.ArchiveTask(taskID, rule.UserID); err != nil {
+			return applied, err
+		}
+		applied = append(applied, automation.ActionArchive)
+	}
+	return applied, nil
+}
+
+// schedule enqueues the delayed actions of a rule on the task, one job per delay. Actions
+// still waiting from an earlier run are not scheduled again.
+func (s *service) schedule(rule *automation.Rule, taskID uuid.UUID, actions []automation.Action) ([]string, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+	if s.jobs == nil {
+		return nil, errors.New("delayed actions are unavailable")
+	}
+
+	byDelay := make(map[string][]automation.Action)
+	var delays []string
+	for _, action := range actions {
+		if _, ok := byDelay[action.After]; !ok {
+			delays = append(delays, action.After)
+		}
+		byDelay[action.After] = append(byDelay[action.After], action)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var scheduled []string
+	var errs []error
+	for _, after := range delays {
+		key := pendingKey(rule.ID, taskID, after)
+		if id, ok := s.pending[key]; ok {
+			if job, err := s.jobs.Get(id); err == nil && !job.Done() {
+				continue
+			}
+		}
+
+		group := byDelay[after]
+		job, err := s.jobs.Enqueue(ruleJob{RuleID: rule.ID, TaskID: taskID, Actions: group},
+			jobs.WithDelay(group[0].Delay()), jobs.WithOwner(rule.UserID))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to schedule the actions after %s: %w", after, err))
+			continue
+		}
+		s.pending[key] = job.ID
+		for _, action := range group {
+			scheduled = append(scheduled, action.String())
+		}
+	}
+	return scheduled, errors.Join(errs...)
+}
+
+// allowRun counts a run of rules changing the task, reporting whether it stays within
+// maxRunsPerTask in runWindow
+func (s *service) allowRun(taskID uuid.UUID) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Runs outside the window are forgotten, for every task
+	for id, runs := range s.runs {
+		runs = slices.DeleteFunc(runs, func(at time.Time) bool { return now.Sub(at) >= runWindow })
+		if len(runs) == 0 {
+			delete(s.runs, id)
+		} else {
+			s.runs[id] = runs
+		}
+	}
+
+	if len(s.runs[taskID]) >= maxRunsPerTask {
+		return false
+	}
+	s.runs[taskID] = append(s.runs[taskID], now)
+	return true
+}
+
+// record adds an execution of the rule on the task to its log, unless the rule was deleted
+func (s *service) record(rule *automation.Rule, taskID uuid.UUID, status string, actions []string, err error) {
+	execution := &automation.Execution{
+		ID:      uuid.New(),
+		RuleID:  rule.ID,
+		TaskID:  taskID,
+		Status:  status,
+		Actions: actions,
+		RanAt:   time.Now(),
+	}
+	if err != nil {
+		execution.Error = err.Error()
+		if status == automation.ExecutionFailed {
+			log.Printf("Automation rule %s failed on task %s: %v", rule.ID, taskID, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.rules[rule.ID]; !exists {
+		return
+	}
+	executions := append(s.executions[rule.ID], execution)
+	if len(executions) > maxExecutionsPerRule {
+		executions = executions[len(executions)-maxExecutionsPerRule:]
+	}
+	s.executions[rule.ID] = executions
+}
+
+// rulesOf returns the rules of the user, oldest first. The caller must hold the lock.
+func (s *service) rulesOf(userID uuid.UUID) []*automation.Rule {
+	var rules []*automation.Rule
+	for _, rule := range s.rules {
+		if rule.UserID == userID {
+			rules = append(rules, rule)
+		}
+	}
+	slices.SortFunc(rules, func(a, b *automation.Rule) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return rules
+}
+
+// subscribeRules evaluates the rules on the task events of the bus
+func (s *service) subscribeRules(bus events.Bus) {
+	bus.Subscribe(func(event events.Event) {
+		if taskEvent, ok := event.(*task.Event); ok && taskEvent.Task != nil {
+			s.evaluate(taskEvent)
+		}
+	})
+}
+
+// pendingKey identifies the delayed actions of a rule on a task with the delay
+func pendingKey(ruleID, taskID uuid.UUID, after string) string {
+	return ruleID.String() + "/" + taskID.String() + "/" + after
+}
+
+// copyRule returns a copy of the rule sharing no slices with it
+func copyRule(rule *automation.Rule) *automation.Rule {
+	copied := *rule
+	copied.Conditions = slices.Clone(rule.Conditions)
+	copied.Actions = slices.Clone(rule.Actions)
+	return &copied
+}
@@ -0,0 +1,200 @@
+package automation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/automation"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRuleService creates an automation service running rules on the task events of a bus
+func setupRuleService(t *testing.T) (*service, taskService.Service) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, Retention: time.Hour})
+	t.Cleanup(func() { queue.Shutdown(context.Background()) })
+
+	cfg := &config.Config{}
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	return NewServiceWithDeps(Deps{Config: cfg, Auth: authSvc, Tasks: taskSvc, Bus: bus, Jobs: queue}).(*service), taskSvc
+}
+
+// executions waits until the rule has the number of executions and returns them, newest
+// first
+func executions(t *testing.T, s *service, ruleID uuid.UUID, count int) []*automation.Execution {
+	var list []*automation.Execution
+	require.Eventually(t, func() bool {
+		var err error
+		list, err = s.ListExecutions(johnID, ruleID)
+		return err == nil && len(list) >= count
+	}, time.Second, time.Millisecond)
+	return list
+}
+
+func TestService_Rules(t *testing.T) {
+	s, _ := setupRuleService(t)
+	janeID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+
+	_, err := s.CreateRule(johnID, &automation.RuleRequest{Name: "Empty", Trigger: automation.Trigger{Event: automation.TriggerTaskCreated}})
+	assert.EqualError(t, err, "at least one action is required")
+
+	rule, err := s.CreateRule(johnID, &automation.RuleRequest{
+		Name:    "Archive new",
+		Trigger: automation.Trigger{Event: automation.TriggerTaskCreated},
+		Actions: []automation.Action{{Type: automation.ActionArchive}},
+	})
+	require.NoError(t, err)
+	assert.True(t, rule.Enabled)
+	assert.Len(t, s.ListRules(johnID), 1)
+	assert.Empty(t, s.ListRules(janeID))
+
+	disabled := false
+	updated, err := s.UpdateRule(johnID, rule.ID, &automation.RuleRequest{
+		Name:    "Prioritize new",
+		Trigger: automation.Trigger{Event: automation.TriggerTaskCreated},
+		Actions: []automation.Action{{Type: automation.ActionSetPriority, Value: "high"}},
+		Enabled: &disabled,
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, rule.CreatedAt, updated.CreatedAt)
+
+	// Rules of other users are not found
+	_, err = s.GetRule(janeID, rule.ID)
+	assert.ErrorIs(t, err, automation.ErrRuleNotFound)
+	_, err = s.ListExecutions(janeID, rule.ID)
+	assert.ErrorIs(t, err, automation.ErrRuleNotFound)
+	assert.ErrorIs(t, s.DeleteRule(janeID, rule.ID), automation.ErrRuleNotFound)
+
+	require.NoError(t, s.DeleteRule(johnID, rule.ID))
+	assert.Empty(t, s.ListRules(johnID))
+}
+
+func TestService_RulesRunOnTaskEvents(t *testing.T) {
+	s, taskSvc := setupRuleService(t)
+
+	rule, err := s.CreateRule(johnID, &automation.RuleRequest{
+		Name:       "Triage bugs",
+		Trigger:    automation.Trigger{Event: automation.TriggerTaskCreated},
+		Conditions: []automation.Condition{{Field: automation.FieldTags, Operator: automation.OperatorContains, Value: "bug"}},
+		Actions: []automation.Action{
+			{Type: automation.ActionSetPriority, Value: "high"},
+			{Type: automation.ActionAddTag, Value: "triage"},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Tidy docs"}, johnID)
+	require.NoError(t, err)
+	bug, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Crash on login", Tags: []string{"bug"}}, johnID)
+	require.NoError(t, err)
+
+	// Only the task matching the conditions is changed
+	list := executions(t, s, rule.ID, 1)
+	require.Len(t, list, 1)
+	assert.Equal(t, automation.ExecutionSucceeded, list[0].Status)
+	assert.Equal(t, bug.ID, list[0].TaskID)
+	assert.Equal(t, []string{"set_priority high", "add_tag triage"}, list[0].Actions)
+
+	updated, err := taskSvc.GetTaskByID(bug.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, task.PriorityHigh, updated.Priority)
+	assert.Equal(t, []string{"bug", "triage"}, updated.Tags)
+}
+
+func TestService_RulesDelayedActions(t *testing.T) {
+	s, taskSvc := setupRuleService(t)
+
+	rule, err := s.CreateRule(johnID, &automation.RuleRequest{
+		Name:       "Archive done",
+		Trigger:    automation.Trigger{Event: automation.TriggerTaskUpdated, Field: automation.FieldStatus},
+		Conditions: []automation.Condition{{Field: automation.FieldStatus, Operator: automation.OperatorEquals, Value: "completed"}},
+		Actions:    []automation.Action{{Type: automation.ActionArchive, After: "168h"}},
+	})
+	require.NoError(t, err)
+
+	done, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Water plants"}, johnID)
+	require.NoError(t, err)
+	reopened, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "File taxes"}, johnID)
+	require.NoError(t, err)
+	_, err = taskSvc.CompleteTask(done.ID, johnID)
+	require.NoError(t, err)
+	_, err = taskSvc.CompleteTask(reopened.ID, johnID)
+	require.NoError(t, err)
+
+	list := executions(t, s, rule.ID, 2)
+	assert.Equal(t, automation.ExecutionScheduled, list[0].Status)
+	assert.Equal(t, []string{"archive after 168h"}, list[0].Actions)
+
+	// Once the delay passed, the task is archived if it is still completed
+	_, err = taskSvc.ReopenTask(reopened.ID, johnID)
+	require.NoError(t, err)
+	actions := []automation.Action{{Type: automation.ActionArchive, After: "168h"}}
+	require.NoError(t, s.runDelayed(ruleJob{RuleID: rule.ID, TaskID: done.ID, Actions: actions}))
+	require.NoError(t, s.runDelayed(ruleJob{RuleID: rule.ID, TaskID: reopened.ID, Actions: actions}))
+
+	archived, err := taskSvc.GetTaskByID(done.ID, johnID)
+	require.NoError(t, err)
+	assert.True(t, archived.IsArchived())
+	kept, err := taskSvc.GetTaskByID(reopened.ID, johnID)
+	require.NoError(t, err)
+	assert.False(t, kept.IsArchived())
+
+	list = executions(t, s, rule.ID, 4)
+	assert.Equal(t, automation.ExecutionSkipped, list[0].Status)
+	assert.Equal(t, "the conditions no longer hold", list[0].Error)
+	assert.Equal(t, automation.ExecutionSucceeded, list[1].Status)
+	assert.Equal(t, []string{"archive"}, list[1].Actions)
+}
+
+func TestService_RulesLoopGuard(t *testing.T) {
+	s, taskSvc := setupRuleService(t)
+
+	// The rules undo each other's change
+	flip := func(from, to string) *automation.Rule {
+		rule, err := s.CreateRule(johnID, &automation.RuleRequest{
+			Name:       "Flip " + from,
+			Trigger:    automation.Trigger{Event: automation.TriggerTaskUpdated, Field: automation.FieldPriority},
+			Conditions: []automation.Condition{{Field: automation.FieldPriority, Operator: automation.OperatorEquals, Value: from}},
+			Actions:    []automation.Action{{Type: automation.ActionSetPriority, Value: to}},
+		})
+		require.NoError(t, err)
+		return rule
+	}
+	high := flip("high", "low")
+	low := flip("low", "high")
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Crash on login"}, johnID)
+	require.NoError(t, err)
+	priority := task.PriorityHigh
+	_, err = taskSvc.UpdateTask(created.ID, &task.UpdateTaskRequest{Priority: &priority}, johnID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		for _, id := range []uuid.UUID{high.ID, low.ID} {
+			list, err := s.ListExecutions(johnID, id)
+			if err == nil && len(list) > 0 && list[0].Status == automation.ExecutionSkipped {
+				return true
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond)
+
+	highRuns, err := s.ListExecutions(johnID, high.ID)
+	require.NoError(t, err)
+	lowRuns, err := s.ListExecutions(johnID, low.ID)
+	require.NoError(t, err)
+	assert.Equal(t, maxRunsPerTask+1, len(highRuns)+len(lowRuns))
+}
@@ -0,0 +1,297 @@
+package automation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/automation"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+// maxKeysPerUser bounds the API keys of a user
+const maxKeysPerUser = 20
+
+// keyPrefixLength is the number of characters of a key kept to tell keys apart
+const keyPrefixLength = 12
+
+// lastUsedInterval is how often the time a key was last used is updated, so polling does
+// not rewrite it on every request
+const lastUsedInterval = time.Minute
+
+var errInvalidKey = errors.New("invalid api key")
+
+// Service defines the automation service interface. It manages the API keys automation
+// platforms such as Zapier and IFTTT authenticate with, lists the tasks their polling
+// triggers return, and runs the automation rules users define on task events.
+type Service interface {
+	CreateAPIKey(userID uuid.UUID, req *automation.CreateAPIKeyRequest) (*automation.CreatedAPIKey, error)
+	ListAPIKeys(userID uuid.UUID) []*automation.APIKey
+	RevokeAPIKey(userID, id uuid.UUID) error
+	// Authenticate returns the key and its user for a valid API key
+	Authenticate(key string) (*automation.APIKey, *auth.User, error)
+	// NewTasks returns the user's most recently created tasks, newest first
+	NewTasks(userID uuid.UUID, limit int) ([]*automation.TaskItem, error)
+	// CompletedTasks returns the user's most recently completed tasks, newest first
+	CompletedTasks(userID uuid.UUID, limit int) ([]*automation.TaskItem, error)
+	CreateRule(userID uuid.UUID, req *automation.RuleRequest) (*automation.Rule, error)
+	ListRules(userID uuid.UUID) []*automation.Rule
+	GetRule(userID, id uuid.UUID) (*automation.Rule, error)
+	UpdateRule(userID, id uuid.UUID, req *automation.RuleRequest) (*automation.Rule, error)
+	DeleteRule(userID, id uuid.UUID) error
+	// ListExecutions returns the recent executions of a rule, newest first
+	ListExecutions(userID, id uuid.UUID) ([]*automation.Execution, error)
+	// DeleteUserData deletes the user's API keys and rules when the account is erased,
+	// satisfying the eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+}
+
+// service implements the automation service
+type service struct {
+	mu          sync.Mutex
+	keys        map[string]*automation.APIKey // Mock API key storage by hash
+	rules       map[uuid.UUID]*automation.Rule
+	executions  map[uuid.UUID][]*automation.Execution // recent executions by rule, oldest first
+	pending     map[string]uuid.UUID                  // jobs of delayed actions, by rule, task and delay
+	runs        map[uuid.UUID][]time.Time             // recent rule runs changing each task
+	config      *config.Config
+	authService authService.Service
+	taskService taskService.Service
+	jobs        jobs.Queue // takes delayed actions, if any
+}
+
+// Deps are the dependencies of an automation service. Bus and Jobs are optional, and a
+// zero value keeps the default noted next to each.
+type Deps struct {
+	Config *config.Config
+	Auth   authService.Service
+	Tasks  taskService.Service
+	Bus    events.Bus // rules run on its task events; they are stored but never run when it is nil
+	// Jobs takes the delayed actions of rules; rules with delayed actions fail when it is nil
+	Jobs jobs.Queue
+}
+
+// NewService creates a new automation service. Its rules are stored but never run.
+func NewService(cfg *config.Config, authSvc authService.Service, taskSvc taskService.Service) Service {
+	return NewServiceWithDeps(Deps{Config: cfg, Auth: authSvc, Tasks: taskSvc})
+}
+
+// NewServiceWithDeps creates a new automation service, using the defaults of Deps for the
+// dependencies left unset
+func NewServiceWithDeps(deps Deps) Service {
+	queue := deps.Jobs
+	s := &service{
+		keys:        make(map[string]*automation.APIKey),
+		rules:       make(map[uuid.UUID]*automation.Rule),
+		executions:  make(map[uuid.UUID][]*automation.Execution),
+		pending:     make(map[string]uuid.UUID),
+		runs:        make(map[uuid.UUID][]time.Time),
+		config:      deps.Config,
+		authService: deps.Auth,
+		taskService: deps.Tasks,
+		jobs:        queue,
+	}
+
+	if queue != nil {
+		jobs.Handle(queue, func(ctx context.Context, job ruleJob) error {
+			return s.runDelayed(job)
+		})
+	}
+	if deps.Bus != nil {
+		s.subscribeRules(deps.Bus)
+	}
+
+	return s
+}
+
+// CreateAPIKey creates an API key for the user. The key is only returned here.
+func (s *service) CreateAPIKey(userID uuid.UUID, req *automation.CreateAPIKeyRequest) (*automation.CreatedAPIKey, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.New("failed to generate api key")
+	}
+	key := automation.KeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = auth.AllScopes
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.keysOf(userID)) >= maxKeysPerUser {
+		return nil, errors.New("api key limit reached")
+	}
+
+	k := &automation.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    key[:keyPrefixLength],
+		Hash:      hashKey(key),
+		Scopes:    slices.Clone(scopes),
+		CreatedAt: time.Now(),
+	}
+	s.keys[k.Hash] = k
+
+	created := *k
+	return &automation.CreatedAPIKey{APIKey: &created, Key: key}, nil
+}
+
+// ListAPIKeys returns the user's API keys, most recently created first
+func (s *service) ListAPIKeys(userID uuid.UUID) []*automation.APIKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := s.keysOf(userID)
+	keys := make([]*automation.APIKey, 0, len(owned))
+	for i := len(owned) - 1; i >= 0; i-- {
+		k := *owned[i]
+		keys = append(keys, &k)
+	}
+	return keys
+}
+
+// RevokeAPIKey deletes the user's API key, so it can no longer be used
+func (s *service) RevokeAPIKey(userID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, k := range s.keys {
+		if k.ID == id && k.UserID == userID {
+			delete(s.keys, hash)
+			return nil
+		}
+	}
+	return errors.New("api key not found")
+}
+
+// DeleteUserData revokes every API key of the user and deletes the user's automation rules
+// with their executions
+func (s *service) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, k := range s.keys {
+		if k.UserID == userID {
+			delete(s.keys, hash)
+		}
+	}
+	for id, rule := range s.rules {
+		if rule.UserID == userID {
+			delete(s.rules, id)
+			delete(s.executions, id)
+		}
+	}
+}
+
+// Authenticate returns the key and its user for a valid API key, recording that it was used.
+// Keys of deleted users are invalid.
+func (s *service) Authenticate(key string) (*automation.APIKey, *auth.User, error) {
+	s.mu.Lock()
+	k, exists := s.keys[hashKey(key)]
+	if !exists {
+		s.mu.Unlock()
+		return nil, nil, errInvalidKey
+	}
+	now := time.Now()
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= lastUsedInterval {
+		k.LastUsedAt = &now
+	}
+	used := *k
+	s.mu.Unlock()
+
+	user, err := s.authService.GetUserByID(used.UserID)
+	if err != nil || !user.IsActive() {
+		return nil, nil, errInvalidKey
+	}
+	return &used, user, nil
+}
+
+// NewTasks returns the user's most recently created tasks, newest first
+func (s *service) NewTasks(userID uuid.UUID, limit int) ([]*automation.TaskItem, error) {
+	tasks, _, err := s.taskService.ListTasks(nil, task.NewTaskSort("created_at", "desc"), 1, automation.ClampTriggerLimit(limit), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*automation.TaskItem, 0, len(tasks))
+	for _, t := range tasks {
+		items = append(items, automation.NewTaskItem(t, s.taskURL(t)))
+	}
+	return items, nil
+}
+
+// CompletedTasks returns the user's most recently completed tasks, newest first. Tasks are
+// ordered by when they were completed rather than last updated, since completed tasks may
+// be edited afterwards.
+func (s *service) CompletedTasks(userID uuid.UUID, limit int) ([]*automation.TaskItem, error) {
+	completed := task.StatusCompleted
+	var tasks []*task.Task
+	err := s.taskService.ExportTasks(&task.TaskFilter{Status: &completed}, nil, userID, func(t *task.Task) error {
+		if t.CompletedAt != nil {
+			tasks = append(tasks, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(tasks, func(a, b *task.Task) int {
+		return b.CompletedAt.Compare(*a.CompletedAt)
+	})
+	tasks = tasks[:min(len(tasks), automation.ClampTriggerLimit(limit))]
+
+	items := make([]*automation.TaskItem, 0, len(tasks))
+	for _, t := range tasks {
+		items = append(items, automation.CompletedTaskItem(t, s.taskURL(t)))
+	}
+	return items, nil
+}
+
+// keysOf returns the user's API keys in the order they were created. The caller must hold
+// the lock.
+func (s *service) keysOf(userID uuid.UUID) []*automation.APIKey {
+	var owned []*automation.APIKey
+	for _, k := range s.keys {
+		if k.UserID == userID {
+			owned = append(owned, k)
+		}
+	}
+	slices.SortFunc(owned, func(a, b *automation.APIKey) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return owned
+}
+
+// taskURL returns the link to the task in the app
+func (s *service) taskURL(t *task.Task) string {
+	return s.config.App.BaseURL + "/tasks/" + t.ID.String()
+}
+
+// hashKey returns the hash API keys are stored by
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+package automation
+
+import (
+	"strings"
+	"testing"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/automation"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+func setupTestService(t *testing.T) (Service, authService.Service, taskService.Service) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "https://todo.example.com"}}
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	return NewService(cfg, authSvc, taskSvc), authSvc, taskSvc
+}
+
+func TestService_APIKeys(t *testing.T) {
+	service, _, _ := setupTestService(t)
+
+	_, err := service.CreateAPIKey(johnID, &automation.CreateAPIKeyRequest{})
+	assert.EqualError(t, err, "name is required")
+
+	created, err := service.CreateAPIKey(johnID, &automation.CreateAPIKeyRequest{Name: "Zapier"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, automation.KeyPrefix))
+	assert.Equal(t, created.Key[:keyPrefixLength], created.Prefix)
+	assert.Equal(t, auth.AllScopes, created.Scopes)
+
+	scoped, err := service.CreateAPIKey(johnID, &automation.CreateAPIKeyRequest{Name: "IFTTT", Scopes: []string{auth.ScopeTasksRead}})
+	require.NoError(t, err)
+
+	key, user, err := service.Authenticate(created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, key.ID)
+	assert.Equal(t, "john.doe@example.com", user.Email)
+
+	_, _, err = service.Authenticate(automation.KeyPrefix + "unknown")
+	assert.Error(t, err)
+
+	// Keys are listed newest first, with when they were last used but without the key
+	keys := service.ListAPIKeys(johnID)
+	require.Len(t, keys, 2)
+	assert.Equal(t, scoped.ID, keys[0].ID)
+	assert.Nil(t, keys[0].LastUsedAt)
+	assert.NotNil(t, keys[1].LastUsedAt)
+	assert.Empty(t, service.ListAPIKeys(uuid.New()))
+
+	// Keys are revoked by their user only
+	assert.EqualError(t, service.RevokeAPIKey(uuid.New(), created.ID), "api key not found")
+	require.NoError(t, service.RevokeAPIKey(johnID, created.ID))
+	_, _, err = service.Authenticate(created.Key)
+	assert.Error(t, err)
+}
+
+func TestService_APIKeyLimit(t *testing.T) {
+	service, _, _ := setupTestService(t)
+
+	for i := 0; i < maxKeysPerUser; i++ {
+		_, err := service.CreateAPIKey(johnID, &automation.CreateAPIKeyRequest{Name: "Zapier"})
+		require.NoError(t, err)
+	}
+	_, err := service.CreateAPIKey(johnID, &automation.CreateAPIKeyRequest{Name: "Zapier"})
+	assert.EqualError(t, err, "api key limit reached")
+}
+
+func TestService_Triggers(t *testing.T) {
+	service, _, taskSvc := setupTestService(t)
+
+	first, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Water plants"}, johnID)
+	require.NoError(t, err)
+	second, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "File taxes"}, johnID)
+	require.NoError(t, err)
+
+	// New tasks are returned newest first, identified by the task
+	items, err := service.NewTasks(johnID, 1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, second.ID.String(), items[0].ID)
+	assert.Equal(t, "https://todo.example.com/tasks/"+second.ID.String(), items[0].URL)
+
+	// Completed tasks are ordered by when they were completed
+	_, err = taskSvc.CompleteTask(second.ID, johnID)
+	require.NoError(t, err)
+	_, err = taskSvc.CompleteTask(first.ID, johnID)
+	require.NoError(t, err)
+
+	items, err = service.CompletedTasks(johnID, 0)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(items), 2)
+	assert.Equal(t, first.ID, items[0].TaskID)
+	assert.Equal(t, second.ID, items[1].TaskID)
+
+	// Completing a reopened task again is a new item
+	completedID := items[1].ID
+	_, err = taskSvc.ReopenTask(second.ID, johnID)
+	require.NoError(t, err)
+	_, err = taskSvc.CompleteTask(second.ID, johnID)
+	require.NoError(t, err)
+
+	items, err = service.CompletedTasks(johnID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, items[0].TaskID)
+	assert.NotEqual(t, completedID, items[0].ID)
+}
@@ -0,0 +1,328 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	"todo-api/pkg/config"
+	"todo-api/pkg/stripe"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the billing service interface. Users subscribe to the pro plan through
+// Stripe Checkout, and the webhook keeps the plan stored on each user in step with their
+// subscription. The service also resolves the limits of each user's plan for the services
+// enforcing them.
+type Service interface {
+	GetSubscription(userID uuid.UUID) (*billing.Subscription, error)
+	// CreateCheckoutSession creates the Checkout Session the user pays for the pro plan on
+	CreateCheckoutSession(ctx context.Context, userID uuid.UUID) (*billing.CheckoutSession, error)
+	// HandleWebhook applies a webhook event signed by Stripe
+	HandleWebhook(payload []byte, signature string) error
+
+	// LimitsOf returns the task limits of the user, satisfying the task limits directory
+	LimitsOf(userID uuid.UUID) task.Limits
+	// PlanOf returns the plan of the user and its limits, satisfying the attachment plan
+	// directory
+	PlanOf(userID uuid.UUID) (billing.Plan, billing.Limits)
+	// AllowIntegration returns an error when the user's plan does not allow connecting the
+	// named integration besides those already connected, satisfying the integration plan
+	// directory
+	AllowIntegration(userID uuid.UUID, name string) error
+	// RegisterIntegration registers an integration counted against the plan limits, with
+	// how to tell whether a user connected it
+	RegisterIntegration(name string, connected func(userID uuid.UUID) bool)
+}
+
+// subscription holds the state of a user's Stripe subscription
+type subscription struct {
+	id               string
+	status           string
+	currentPeriodEnd time.Time
+}
+
+// webhookJob applies the change a webhook event reported: a completed checkout, or a
+// subscription whose status changed
+type webhookJob struct {
+	EventID      string                  `json:"event_id"`
+	Checkout     *stripe.CheckoutSession `json:"checkout,omitempty"`
+	Subscription *stripe.Subscription    `json:"subscription,omitempty"`
+}
+
+func (webhookJob) JobType() string { return "billing.stripe_event" }
+
+// service implements the billing service
+type service struct {
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]*subscription // Mock subscription storage by user ID
+	integrations  map[string]func(userID uuid.UUID) bool
+	authService   authService.Service
+	client        stripe.Client
+	jobs          jobs.Queue // applies webhook events, if any
+	config        config.BillingConfig
+	limits        config.LimitsConfig
+}
+
+// NewService creates a new billing service paying through the Stripe client. Plan limits
+// are only enforced when billing is enabled.
+func NewService(cfg *config.Config, authSvc authService.Service, client stripe.Client) Service {
+	return NewServiceWithJobs(cfg, authSvc, client, nil)
+}
+
+// NewServiceWithJobs creates a new billing service applying webhook events as jobs of the
+// queue, so Stripe is answered once an event is verified and failures are retried
+func NewServiceWithJobs(cfg *config.Config, authSvc authService.Service, client stripe.Client, queue jobs.Queue) Service {
+	s := &service{
+		subscriptions: make(map[uuid.UUID]*subscription),
+		integrations:  make(map[string]func(userID uuid.UUID) bool),
+		authService:   authSvc,
+		client:        client,
+		jobs:          queue,
+		config:        cfg.Billing,
+		limits:        cfg.Limits,
+	}
+
+	if queue != nil {
+		jobs.Handle(queue, func(ctx context.Context, job webhookJob) error {
+			return s.apply(&job)
+		})
+	}
+
+	return s
+}
+
+// GetSubscription returns the plan of the user and its limits, with the status of their
+// subscription if they ever subscribed
+func (s *service) GetSubscription(userID uuid.UUID) (*billing.Subscription, error) {
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, limits := s.planOf(user)
+	result := &billing.Subscription{Plan: plan, Limits: limits}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub, exists := s.subscriptions[userID]; exists {
+		result.Status = sub.status
+		if !sub.currentPeriodEnd.IsZero() {
+			end := sub.currentPeriodEnd
+			result.CurrentPeriodEnd = &end
+		}
+	}
+	return result, nil
+}
+
+// CreateCheckoutSession creates a Checkout Session subscribing the user to the pro plan.
+// Returning customers are checked out as the same Stripe customer.
+func (s *service) CreateCheckoutSession(ctx context.Context, userID uuid.UUID) (*billing.CheckoutSession, error) {
+	if !s.config.Enabled() {
+		return nil, billing.ErrNotEnabled
+	}
+
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if billing.PlanOf(user.Plan) == billing.PlanPro {
+		return nil, billing.ErrAlreadySubscribed
+	}
+
+	req := &stripe.CheckoutRequest{
+		PriceID:           s.config.ProPriceID,
+		ClientReferenceID: userID.String(),
+		CustomerID:        user.StripeCustomerID,
+		CustomerEmail:     user.Email,
+		SuccessURL:        s.config.SuccessURL,
+		CancelURL:         s.config.CancelURL,
+	}
+	session, err := s.client.CreateCheckoutSession(ctx, req)
+	if err != nil {
+		log.Printf("Failed to create Stripe checkout session for user %s: %v", userID, err)
+		return nil, billing.ErrPaymentProviderUnavailable
+	}
+
+	return &billing.CheckoutSession{ID: session.ID, URL: session.URL}, nil
+}
+
+// HandleWebhook applies a webhook event, or enqueues it with a job queue. Completed
+// checkouts upgrade the user to the pro plan; subscriptions that are no longer paid for, or
+// were canceled, downgrade them to the free plan. Other events are ignored.
+func (s *service) HandleWebhook(payload []byte, signature string) error {
+	event, err := stripe.ParseEvent(payload, signature, s.config.WebhookSecret, stripe.DefaultTolerance, time.Now())
+	if err != nil {
+		return err
+	}
+
+	job := &webhookJob{EventID: event.ID}
+	switch event.Type {
+	case stripe.EventCheckoutSessionCompleted:
+		if job.Checkout, err = event.CheckoutSession(); err != nil {
+			return err
+		}
+	case stripe.EventSubscriptionUpdated, stripe.EventSubscriptionDeleted:
+		if job.Subscription, err = event.Subscription(); err != nil {
+			return err
+		}
+		if event.Type == stripe.EventSubscriptionDeleted {
+			job.Subscription.Status = "canceled"
+		}
+	default:
+		return nil
+	}
+
+	if s.jobs != nil {
+		_, err := s.jobs.Enqueue(*job)
+		return err
+	}
+	return s.apply(job)
+}
+
+// apply applies the change reported by a webhook event
+func (s *service) apply(job *webhookJob) error {
+	if job.Checkout != nil {
+		return s.completeCheckout(job.Checkout)
+	}
+	return s.updateSubscription(job.Subscription)
+}
+
+// completeCheckout upgrades the user who checked out to the pro plan
+func (s *service) completeCheckout(session *stripe.CheckoutSession) error {
+	userID, err := uuid.Parse(session.ClientReferenceID)
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("checkout session %s has no user", session.ID))
+	}
+
+	if _, err := s.authService.SetPlan(userID, string(billing.PlanPro), session.Customer); err != nil {
+		// Such as users who deleted their account while paying; retries would not help
+		log.Printf("Failed to upgrade user %s after checkout session %s: %v", userID, session.ID, err)
+		return nil
+	}
+
+	s.mu.Lock()
+	s.subscriptions[userID] = &subscription{id: session.Subscription, status: stripe.SubscriptionActive}
+	s.mu.Unlock()
+	return nil
+}
+
+// updateSubscription sets the plan of the customer's user from the status of their
+// subscription
+func (s *service) updateSubscription(sub *stripe.Subscription) error {
+	user := s.customer(sub.Customer)
+	if user == nil {
+		log.Printf("Ignoring Stripe subscription %s of unknown customer %s", sub.ID, sub.Customer)
+		return nil
+	}
+
+	plan := billing.PlanFree
+	if sub.IsPaid() {
+		plan = billing.PlanPro
+	}
+	if _, err := s.authService.SetPlan(user.ID, string(plan), user.StripeCustomerID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := &subscription{id: sub.ID, status: sub.Status}
+	if sub.CurrentPeriodEnd > 0 {
+		state.currentPeriodEnd = time.Unix(sub.CurrentPeriodEnd, 0).UTC()
+	}
+	s.subscriptions[user.ID] = state
+	return nil
+}
+
+// LimitsOf returns the task limits of the user: the per-user limit, or the limit of their
+// plan when it is lower
+func (s *service) LimitsOf(userID uuid.UUID) task.Limits {
+	limits := task.Limits{MaxTasks: s.limits.MaxTasksPerUser}
+
+	plan, planLimits := s.PlanOf(userID)
+	if planLimits.MaxTasks > 0 && (limits.MaxTasks == 0 || planLimits.MaxTasks < limits.MaxTasks) {
+		limits = task.Limits{MaxTasks: planLimits.MaxTasks, Plan: string(plan)}
+	}
+	return limits
+}
+
+// PlanOf returns the plan of the user and its limits. Every plan is unlimited unless billing
+// is enabled.
+func (s *service) PlanOf(userID uuid.UUID) (billing.Plan, billing.Limits) {
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil {
+		return billing.PlanFree, billing.Limits{}
+	}
+	return s.planOf(user)
+}
+
+// AllowIntegration returns an error when the user's plan allows no more integrations.
+// Reconnecting an integration the user already connected is always allowed.
+func (s *service) AllowIntegration(userID uuid.UUID, name string) error {
+	plan, limits := s.PlanOf(userID)
+	if limits.MaxIntegrations == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	integrations := make(map[string]func(uuid.UUID) bool, len(s.integrations))
+	for other, connected := range s.integrations {
+		integrations[other] = connected
+	}
+	s.mu.Unlock()
+
+	count := 0
+	for other, connected := range integrations {
+		if connected(userID) {
+			if other == name {
+				return nil
+			}
+			count++
+		}
+	}
+	if !limits.AllowsIntegrations(count) {
+		return billing.UpgradeRequired(plan, limits.MaxIntegrations, "integrations")
+	}
+	return nil
+}
+
+// RegisterIntegration registers an integration counted against the plan limits
+func (s *service) RegisterIntegration(name string, connected func(userID uuid.UUID) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.integrations[name] = connected
+}
+
+// planOf returns the plan of the user and its limits
+func (s *service) planOf(user *auth.User) (billing.Plan, billing.Limits) {
+	plan := billing.PlanOf(user.Plan)
+	if !s.config.Enabled() || plan == billing.PlanPro {
+		return plan, billing.Limits{}
+	}
+	return plan, billing.Limits{
+		MaxTasks:        s.config.FreeMaxTasks,
+		MaxAttachments:  s.config.FreeMaxAttachments,
+		MaxIntegrations: s.config.FreeMaxIntegrations,
+	}
+}
+
+// customer returns the user paying as the Stripe customer, if any
+func (s *service) customer(customerID string) *auth.User {
+	if customerID == "" {
+		return nil
+	}
+	for _, user := range s.authService.ListUsers() {
+		if user.StripeCustomerID == customerID {
+			return user
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	"todo-api/pkg/config"
+	"todo-api/pkg/stripe"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var janeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+
+const webhookSecret = "whsec_test"
+
+// fakeClient records the Checkout Sessions created
+type fakeClient struct {
+	requests []*stripe.CheckoutRequest
+	failWith error
+}
+
+func (c *fakeClient) CreateCheckoutSession(ctx context.Context, req *stripe.CheckoutRequest) (*stripe.CheckoutSession, error) {
+	if c.failWith != nil {
+		return nil, c.failWith
+	}
+	c.requests = append(c.requests, req)
+	return &stripe.CheckoutSession{ID: "cs_1", URL: "https://checkout.stripe.com/c/pay/cs_1"}, nil
+}
+
+func setupTestService(t *testing.T, enabled bool) (Service, authService.Service, *fakeClient) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute},
+		Billing: config.BillingConfig{
+			WebhookSecret:       webhookSecret,
+			ProPriceID:          "price_pro",
+			SuccessURL:          "https://todo.example.com/billing/success",
+			CancelURL:           "https://todo.example.com/billing",
+			FreeMaxTasks:        100,
+			FreeMaxAttachments:  20,
+			FreeMaxIntegrations: 1,
+		},
+		Limits: config.LimitsConfig{MaxTasksPerUser: 500},
+	}
+	if enabled {
+		cfg.Billing.SecretKey = "sk_test"
+	}
+
+	authSvc := authService.NewService(cfg)
+	client := &fakeClient{}
+	return NewService(cfg, authSvc, client), authSvc, client
+}
+
+// webhook sends a signed webhook event
+func webhook(service Service, payload string) error {
+	return service.HandleWebhook([]byte(payload), stripe.SignatureHeaderValue([]byte(payload), webhookSecret, time.Now()))
+}
+
+func TestService_Disabled(t *testing.T) {
+	service, _, _ := setupTestService(t, false)
+
+	plan, limits := service.PlanOf(janeID)
+	assert.Equal(t, billing.PlanFree, plan)
+	assert.Equal(t, billing.Limits{}, limits)
+	assert.Equal(t, 500, service.LimitsOf(janeID).MaxTasks)
+	assert.Empty(t, service.LimitsOf(janeID).Plan)
+
+	_, err := service.CreateCheckoutSession(context.Background(), janeID)
+	assert.ErrorIs(t, err, billing.ErrNotEnabled)
+}
+
+func TestService_Checkout(t *testing.T) {
+	service, _, client := setupTestService(t, true)
+
+	subscription, err := service.GetSubscription(janeID)
+	require.NoError(t, err)
+	assert.Equal(t, billing.PlanFree, subscription.Plan)
+	assert.Equal(t, 100, subscription.Limits.MaxTasks)
+
+	// The plan limit applies when lower than the per-user limit
+	limits := service.LimitsOf(janeID)
+	assert.Equal(t, 100, limits.MaxTasks)
+	assert.Equal(t, "free", limits.Plan)
+
+	session, err := service.CreateCheckoutSession(context.Background(), janeID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://checkout.stripe.com/c/pay/cs_1", session.URL)
+	require.Len(t, client.requests, 1)
+	assert.Equal(t, janeID.String(), client.requests[0].ClientReferenceID)
+	assert.Equal(t, "jane.smith@example.com", client.requests[0].CustomerEmail)
+	assert.Equal(t, "price_pro", client.requests[0].PriceID)
+
+	require.NoError(t, webhook(service, `{"id":"evt_1","type":"checkout.session.completed","data":{"object":{
+		"id":"cs_1","client_reference_id":"`+janeID.String()+`","customer":"cus_1","subscription":"sub_1"}}}`))
+
+	subscription, err = service.GetSubscription(janeID)
+	require.NoError(t, err)
+	assert.Equal(t, billing.PlanPro, subscription.Plan)
+	assert.Equal(t, billing.Limits{}, subscription.Limits)
+	assert.Equal(t, "active", subscription.Status)
+	assert.Equal(t, 500, service.LimitsOf(janeID).MaxTasks)
+
+	_, err = service.CreateCheckoutSession(context.Background(), janeID)
+	assert.ErrorIs(t, err, billing.ErrAlreadySubscribed)
+}
+
+func TestService_SubscriptionLapses(t *testing.T) {
+	service, authSvc, client := setupTestService(t, true)
+	_, err := authSvc.SetPlan(janeID, "pro", "cus_1")
+	require.NoError(t, err)
+
+	require.NoError(t, webhook(service, `{"id":"evt_2","type":"customer.subscription.updated","data":{"object":{
+		"id":"sub_1","customer":"cus_1","status":"past_due","current_period_end":1700000000}}}`))
+	subscription, err := service.GetSubscription(janeID)
+	require.NoError(t, err)
+	assert.Equal(t, billing.PlanFree, subscription.Plan)
+	assert.Equal(t, "past_due", subscription.Status)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), *subscription.CurrentPeriodEnd)
+
+	require.NoError(t, webhook(service, `{"id":"evt_3","type":"customer.subscription.updated","data":{"object":{
+		"id":"sub_1","customer":"cus_1","status":"active"}}}`))
+	plan, _ := service.PlanOf(janeID)
+	assert.Equal(t, billing.PlanPro, plan)
+
+	require.NoError(t, webhook(service, `{"id":"evt_4","type":"customer.subscription.deleted","data":{"object":{
+		"id":"sub_1","customer":"cus_1","status":"canceled"}}}`))
+	plan, _ = service.PlanOf(janeID)
+	assert.Equal(t, billing.PlanFree, plan)
+
+	// Returning customers check out as the same customer
+	_, err = service.CreateCheckoutSession(context.Background(), janeID)
+	require.NoError(t, err)
+	assert.Equal(t, "cus_1", client.requests[0].CustomerID)
+
+	// Unknown customers and event types are ignored
+	assert.NoError(t, webhook(service, `{"id":"evt_5","type":"customer.subscription.deleted","data":{"object":{"customer":"cus_9"}}}`))
+	assert.NoError(t, webhook(service, `{"id":"evt_6","type":"invoice.paid","data":{"object":{}}}`))
+}
+
+func TestService_Webhook_InvalidSignature(t *testing.T) {
+	service, _, _ := setupTestService(t, true)
+	payload := []byte(`{"id":"evt_1","type":"checkout.session.completed"}`)
+
+	err := service.HandleWebhook(payload, stripe.SignatureHeaderValue(payload, "whsec_other", time.Now()))
+	assert.ErrorIs(t, err, stripe.ErrInvalidSignature)
+}
+
+func TestService_CheckoutUnavailable(t *testing.T) {
+	service, _, client := setupTestService(t, true)
+	client.failWith = errors.New("connection refused")
+
+	_, err := service.CreateCheckoutSession(context.Background(), janeID)
+	assert.ErrorIs(t, err, billing.ErrPaymentProviderUnavailable)
+}
+
+func TestService_AllowIntegration(t *testing.T) {
+	service, authSvc, _ := setupTestService(t, true)
+	connected := map[string]bool{"slack": true}
+	for _, name := range []string{"slack", "github"} {
+		service.RegisterIntegration(name, func(uuid.UUID) bool { return connected[name] })
+	}
+
+	// Reconnecting counts no further integration
+	assert.NoError(t, service.AllowIntegration(janeID, "slack"))
+	err := service.AllowIntegration(janeID, "github")
+	assert.ErrorIs(t, err, billing.ErrUpgradeRequired)
+	assert.EqualError(t, err, "upgrade required: the free plan allows at most 1 integration")
+
+	_, err = authSvc.SetPlan(janeID, "pro", "cus_1")
+	require.NoError(t, err)
+	assert.NoError(t, service.AllowIntegration(janeID, "github"))
+}
+
+func TestService_WebhookJobs(t *testing.T) {
+	_, authSvc, client := setupTestService(t, true)
+	cfg := &config.Config{Billing: config.BillingConfig{SecretKey: "sk_test", WebhookSecret: webhookSecret, FreeMaxTasks: 100}}
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	service := NewServiceWithJobs(cfg, authSvc, client, queue)
+
+	// Events are verified at once and applied by a worker
+	require.NoError(t, webhook(service, `{"id":"evt_1","type":"checkout.session.completed","data":{"object":{
+		"id":"cs_1","client_reference_id":"`+janeID.String()+`","customer":"cus_1","subscription":"sub_1"}}}`))
+	require.Eventually(t, func() bool {
+		plan, _ := service.PlanOf(janeID)
+		return plan == billing.PlanPro
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, queue.Stats().Succeeded)
+
+	payload := []byte(`{"id":"evt_2","type":"checkout.session.completed"}`)
+	err := service.HandleWebhook(payload, stripe.SignatureHeaderValue(payload, "whsec_other", time.Now()))
+	assert.ErrorIs(t, err, stripe.ErrInvalidSignature)
+}
@@ -0,0 +1,256 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/device"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/pkg/push"
+
+	"github.com/google/uuid"
+)
+
+// dateFormat is how due dates are shown in push notifications
+const dateFormat = "Mon, Jan 2 2006 15:04 MST"
+
+// maxDevicesPerUser bounds the devices of a user. Registering another one replaces the
+// device registered or refreshed least recently.
+const maxDevicesPerUser = 20
+
+// Types of push notifications, passed to the app in the notification data
+const (
+	notificationAssigned = "task_assigned"
+	notificationDueSoon  = "task_due_soon"
+)
+
+// Service defines the device service interface. It registers the user's mobile devices and
+// sends them push notifications about task assignments and reminders.
+type Service interface {
+	// RegisterDevice registers the device for push notifications, reporting whether it was
+	// newly registered rather than refreshed
+	RegisterDevice(userID uuid.UUID, req *device.RegisterDeviceRequest) (*device.Device, bool, error)
+	ListDevices(userID uuid.UUID) []*device.Device
+	RemoveDevice(userID, id uuid.UUID) error
+	// DeleteUserData removes the user's devices when the account is erased, satisfying the
+	// eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	// NotificationChannel and Remind send reminders about tasks coming due to the user's
+	// devices, satisfying the notification channel interface
+	NotificationChannel() string
+	Remind(ctx context.Context, user *auth.User, t *task.Task, now time.Time) (bool, error)
+}
+
+// service implements the device service
+type service struct {
+	mu      sync.RWMutex
+	devices map[uuid.UUID]*device.Device // Mock device storage
+	senders map[string]push.Sender       // senders by platform
+	allows  notification.Filter          // notification settings of users
+}
+
+// NewService creates a new device service sending push notifications through the sender of
+// each platform, and notifying assignees of tasks assigned to them through the bus
+func NewService(senders map[string]push.Sender, bus events.Bus) Service {
+	return NewServiceWithFilter(senders, bus, notification.AllowAll)
+}
+
+// NewServiceWithFilter creates a new device service only notifying assignees who did not
+// turn assignment push notifications off
+func NewServiceWithFilter(senders map[string]push.Sender, bus events.Bus, allows notification.Filter) Service {
+	s := &service{
+		devices: make(map[uuid.UUID]*device.Device),
+		senders: senders,
+		allows:  allows,
+	}
+
+	bus.Subscribe(func(event events.Event) {
+		if taskEvent, ok := event.(*task.Event); ok {
+			s.notify(context.Background(), taskEvent)
+		}
+	})
+
+	return s
+}
+
+// RegisterDevice registers the device for push notifications. A push token identifies an
+// app installation, so registering a known token refreshes its device, moving it to the
+// user if another user registered it before, e.g. after signing out and in as someone else.
+func (s *service) RegisterDevice(userID uuid.UUID, req *device.RegisterDeviceRequest) (*device.Device, bool, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.devices {
+		if d.Platform == req.Platform && d.Token == req.Token {
+			d.UserID = userID
+			d.Name = req.Name
+			d.UpdatedAt = time.Now()
+			registered := *d
+			return &registered, false, nil
+		}
+	}
+
+	if owned := s.devicesOf(userID); len(owned) >= maxDevicesPerUser {
+		delete(s.devices, owned[0].ID)
+	}
+
+	d := device.NewDevice(userID, req)
+	s.devices[d.ID] = d
+
+	registered := *d
+	return &registered, true, nil
+}
+
+// ListDevices returns the user's devices, most recently registered or refreshed first
+func (s *service) ListDevices(userID uuid.UUID) []*device.Device {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	owned := s.devicesOf(userID)
+	devices := make([]*device.Device, 0, len(owned))
+	for i := len(owned) - 1; i >= 0; i-- {
+		d := *owned[i]
+		devices = append(devices, &d)
+	}
+	return devices
+}
+
+// RemoveDevice removes the user's device, so it no longer receives push notifications
+func (s *service) RemoveDevice(userID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, exists := s.devices[id]
+	if !exists || d.UserID != userID {
+		return errors.New("device not found")
+	}
+	delete(s.devices, id)
+	return nil
+}
+
+// DeleteUserData removes every device of the user, with its push token
+func (s *service) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, d := range s.devices {
+		if d.UserID == userID {
+			delete(s.devices, id)
+		}
+	}
+}
+
+// NotificationChannel returns the channel of push notifications in notification settings
+func (s *service) NotificationChannel() string {
+	return notification.ChannelPush
+}
+
+// Remind sends a reminder about the task coming due to the user's devices, reporting
+// whether any device received it
+func (s *service) Remind(ctx context.Context, user *auth.User, t *task.Task, now time.Time) (bool, error) {
+	body := "Due " + t.DueDate.UTC().Format(dateFormat)
+	if t.DueDate.Before(now) {
+		body = "Was due " + t.DueDate.UTC().Format(dateFormat)
+	}
+
+	return s.send(ctx, user.ID, &push.Notification{
+		Title: t.Title,
+		Body:  body,
+		Data:  map[string]string{"type": notificationDueSoon, "task_id": t.ID.String()},
+	})
+}
+
+// notify sends the new assignee of a task a notification about it, unless they assigned the
+// task to themselves or turned assignment push notifications off
+func (s *service) notify(ctx context.Context, e *task.Event) {
+	if e.Type != task.EventTaskUpdated || e.Task == nil {
+		return
+	}
+	change, ok := e.Change("assignee_id")
+	if !ok || change.NewValue == "" {
+		return
+	}
+	assigneeID, err := uuid.Parse(change.NewValue)
+	if err != nil || assigneeID == e.ActorID || !s.allows(assigneeID, notification.EventAssigned, notification.ChannelPush) {
+		return
+	}
+
+	n := &push.Notification{
+		Title: "You were assigned a task",
+		Body:  e.Task.Title,
+		Data:  map[string]string{"type": notificationAssigned, "task_id": e.Task.ID.String()},
+	}
+	if _, err := s.send(ctx, assigneeID, n); err != nil {
+		log.Printf("Failed to send assignment notification to user %s: %v", assigneeID, err)
+	}
+}
+
+// send sends the notification to each of the user's devices, reporting whether any received
+// it. Devices whose tokens the push service rejects are removed, so they are not retried.
+func (s *service) send(ctx context.Context, userID uuid.UUID, n *push.Notification) (bool, error) {
+	s.mu.RLock()
+	var owned []device.Device
+	for _, d := range s.devicesOf(userID) {
+		owned = append(owned, *d)
+	}
+	s.mu.RUnlock()
+
+	delivered := false
+	var errs []error
+	for _, d := range owned {
+		sender, exists := s.senders[d.Platform]
+		if !exists {
+			continue
+		}
+
+		err := sender.Send(ctx, d.Token, n)
+		switch {
+		case err == nil:
+			delivered = true
+		case errors.Is(err, push.ErrInvalidToken):
+			log.Printf("Removing %s device %s of user %s: %v", d.Platform, d.ID, userID, err)
+			s.remove(d)
+		default:
+			errs = append(errs, fmt.Errorf("%s device %s: %w", d.Platform, d.ID, err))
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// remove removes the device, unless it was registered again since it was read
+func (s *service) remove(d device.Device) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, exists := s.devices[d.ID]; exists && current.UserID == d.UserID && current.Token == d.Token {
+		delete(s.devices, d.ID)
+	}
+}
+
+// devicesOf returns the user's devices, least recently registered or refreshed first. The
+// caller must hold the lock.
+func (s *service) devicesOf(userID uuid.UUID) []*device.Device {
+	var owned []*device.Device
+	for _, d := range s.devices {
+		if d.UserID == userID {
+			owned = append(owned, d)
+		}
+	}
+	slices.SortFunc(owned, func(a, b *device.Device) int {
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	})
+	return owned
+}
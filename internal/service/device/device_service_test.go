@@ -0,0 +1,172 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/device"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/push"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const iosToken = "a1b2c3d4e5f6"
+
+func setupTestService(t *testing.T) (Service, authService.Service, taskService.Service, *push.MemorySender, *push.MemorySender) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := authService.NewService(&config.Config{})
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	android, ios := push.NewMemorySender(), push.NewMemorySender()
+	senders := map[string]push.Sender{device.PlatformAndroid: android, device.PlatformIOS: ios}
+	return NewService(senders, bus), authSvc, taskSvc, android, ios
+}
+
+func TestService_RegisterDevice(t *testing.T) {
+	service, authSvc, _, _, _ := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	jane, _ := authSvc.GetUserByEmail("jane.smith@example.com")
+
+	_, _, err := service.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: "web", Token: "token"})
+	assert.Error(t, err)
+
+	registered, created, err := service.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: device.PlatformIOS, Token: iosToken})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// Registering the token again refreshes the device
+	refreshed, created, err := service.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: device.PlatformIOS, Token: iosToken, Name: "iPhone"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, registered.ID, refreshed.ID)
+	assert.Equal(t, "iPhone", refreshed.Name)
+	assert.Len(t, service.ListDevices(john.ID), 1)
+
+	// The token moves to the user signed in on the device
+	_, created, err = service.RegisterDevice(jane.ID, &device.RegisterDeviceRequest{Platform: device.PlatformIOS, Token: iosToken})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Empty(t, service.ListDevices(john.ID))
+	assert.Len(t, service.ListDevices(jane.ID), 1)
+
+	assert.Error(t, service.RemoveDevice(john.ID, registered.ID))
+	require.NoError(t, service.RemoveDevice(jane.ID, registered.ID))
+	assert.Empty(t, service.ListDevices(jane.ID))
+}
+
+func TestService_NotifiesAssignee(t *testing.T) {
+	service, authSvc, taskSvc, android, _ := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	jane, _ := authSvc.GetUserByEmail("jane.smith@example.com")
+
+	_, _, err := service.RegisterDevice(jane.ID, &device.RegisterDeviceRequest{Platform: device.PlatformAndroid, Token: "jane-phone"})
+	require.NoError(t, err)
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Review notes"}, john.ID)
+	require.NoError(t, err)
+	_, err = taskSvc.AssignTask(created.ID, &task.AssignTaskRequest{UserID: &jane.ID}, john.ID)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return len(android.Sent("jane-phone")) == 1 }, time.Second, 10*time.Millisecond)
+	sent := android.Sent("jane-phone")[0]
+	assert.Equal(t, "Review notes", sent.Body)
+	assert.Equal(t, map[string]string{"type": "task_assigned", "task_id": created.ID.String()}, sent.Data)
+}
+
+func TestService_NotifiesAssignee_Filter(t *testing.T) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	authSvc := authService.NewService(&config.Config{})
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	jane, _ := authSvc.GetUserByEmail("jane.smith@example.com")
+
+	// Jane turned assignment push notifications off
+	android := push.NewMemorySender()
+	service := NewServiceWithFilter(map[string]push.Sender{device.PlatformAndroid: android}, bus,
+		func(userID uuid.UUID, event, channel string) bool {
+			return userID != jane.ID || event != notification.EventAssigned || channel != notification.ChannelPush
+		})
+	_, _, err := service.RegisterDevice(jane.ID, &device.RegisterDeviceRequest{Platform: device.PlatformAndroid, Token: "jane-phone"})
+	require.NoError(t, err)
+	_, _, err = service.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: device.PlatformAndroid, Token: "john-phone"})
+	require.NoError(t, err)
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Review notes"}, john.ID)
+	require.NoError(t, err)
+	_, err = taskSvc.AssignTask(created.ID, &task.AssignTaskRequest{UserID: &jane.ID}, john.ID)
+	require.NoError(t, err)
+	other, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Book venue"}, jane.ID)
+	require.NoError(t, err)
+	_, err = taskSvc.AssignTask(other.ID, &task.AssignTaskRequest{UserID: &john.ID}, jane.ID)
+	require.NoError(t, err)
+
+	// Events are handled in order, so Jane was skipped once John is notified
+	assert.Eventually(t, func() bool { return len(android.Sent("john-phone")) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Empty(t, android.Sent("jane-phone"))
+}
+
+func TestService_Remind(t *testing.T) {
+	service, authSvc, _, android, ios := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	due := now.Add(2 * time.Hour)
+	t1 := &task.Task{Title: "Pay rent", DueDate: &due}
+
+	// Users without devices are not reminded
+	delivered, err := service.Remind(context.Background(), john, t1, now)
+	require.NoError(t, err)
+	assert.False(t, delivered)
+
+	_, _, err = service.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: device.PlatformAndroid, Token: "john-phone"})
+	require.NoError(t, err)
+	_, _, err = service.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: device.PlatformIOS, Token: iosToken})
+	require.NoError(t, err)
+
+	// Devices whose tokens are rejected are removed
+	ios.Invalidate(iosToken)
+	delivered, err = service.Remind(context.Background(), john, t1, now)
+	require.NoError(t, err)
+	assert.True(t, delivered)
+	assert.Equal(t, "Due Tue, Mar 10 2026 14:00 UTC", android.Sent("john-phone")[0].Body)
+	devices := service.ListDevices(john.ID)
+	require.Len(t, devices, 1)
+	assert.Equal(t, device.PlatformAndroid, devices[0].Platform)
+}
+
+// failingSender fails every send
+type failingSender struct{}
+
+func (failingSender) Send(ctx context.Context, token string, n *push.Notification) error {
+	return &push.RetryableError{Err: errors.New("unavailable")}
+}
+
+func TestService_RemindFailure(t *testing.T) {
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	authSvc := authService.NewService(&config.Config{})
+	service := NewService(map[string]push.Sender{device.PlatformAndroid: failingSender{}}, bus)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+
+	_, _, err := service.RegisterDevice(john.ID, &device.RegisterDeviceRequest{Platform: device.PlatformAndroid, Token: "john-phone"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	delivered, err := service.Remind(context.Background(), john, &task.Task{Title: "Pay rent", DueDate: &now}, now)
+	assert.Error(t, err)
+	assert.False(t, delivered)
+
+	// Transient failures keep the device
+	assert.Len(t, service.ListDevices(john.ID), 1)
+}
@@ -0,0 +1,282 @@
+package escalation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/escalation"
+	"todo-api/internal/domain/task"
+	authService "todo-api/internal/service/auth"
+	notificationService "todo-api/internal/service/notification"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+
+	"github.com/google/uuid"
+)
+
+// maxRulesPerWorkspace bounds the escalation rules of a workspace
+const maxRulesPerWorkspace = 50
+
+// Service defines the escalation service interface. It manages the escalation rules of
+// workspaces and escalates the overdue tasks they match, run by the scheduler.
+type Service interface {
+	CreateRule(workspaceID uuid.UUID, req *escalation.RuleRequest, userID uuid.UUID) (*escalation.Rule, error)
+	ListRules(workspaceID uuid.UUID) []*escalation.Rule
+	GetRule(workspaceID, id uuid.UUID) (*escalation.Rule, error)
+	UpdateRule(workspaceID, id uuid.UUID, req *escalation.RuleRequest) (*escalation.Rule, error)
+	DeleteRule(workspaceID, id uuid.UUID) error
+	// Escalate evaluates every rule at the time, escalating each matching task once per
+	// rule and due date. It returns the number of escalations sent.
+	Escalate(ctx context.Context, now time.Time) (sent int, err error)
+	// Run escalates the tasks overdue now, run by the scheduler
+	Run(ctx context.Context) error
+}
+
+// service implements the escalation service
+type service struct {
+	mu                  sync.Mutex
+	rules               map[uuid.UUID]*escalation.Rule // Mock rule storage
+	escalateMu          sync.Mutex                     // serializes escalating
+	escalated           map[string]time.Time           // due date each task was escalated of, by rule and task
+	authService         authService.Service
+	taskService         taskService.Service
+	workspaceService    workspaceService.Service
+	notificationService notificationService.Service
+}
+
+// NewService creates a new escalation service emailing escalations through the
+// notification service
+func NewService(authSvc authService.Service, taskSvc taskService.Service, workspaceSvc workspaceService.Service,
+	notificationSvc notificationService.Service) Service {
+	return &service{
+		rules:               make(map[uuid.UUID]*escalation.Rule),
+		escalated:           make(map[string]time.Time),
+		authService:         authSvc,
+		taskService:         taskSvc,
+		workspaceService:    workspaceSvc,
+		notificationService: notificationSvc,
+	}
+}
+
+// CreateRule creates an escalation rule in the workspace
+func (s *service) CreateRule(workspaceID uuid.UUID, req *escalation.RuleRequest, userID uuid.UUID) (*escalation.Rule, error) {
+	if err := s.validate(workspaceID, req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rulesOf(workspaceID)) >= maxRulesPerWorkspace {
+		return nil, fmt.Errorf("a workspace has at most %d escalation rules", maxRulesPerWorkspace)
+	}
+	rule := escalation.NewRule(workspaceID, req, userID)
+	s.rules[rule.ID] = rule
+
+	return copyRule(rule), nil
+}
+
+// ListRules returns the escalation rules of the workspace, oldest first
+func (s *service) ListRules(workspaceID uuid.UUID) []*escalation.Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := s.rulesOf(workspaceID)
+	rules := make([]*escalation.Rule, len(owned))
+	for i, rule := range owned {
+		rules[i] = copyRule(rule)
+	}
+	return rules
+}
+
+// GetRule returns an escalation rule of the workspace
+func (s *service) GetRule(workspaceID, id uuid.UUID) (*escalation.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[id]
+	if !exists || rule.WorkspaceID != workspaceID {
+		return nil, escalation.ErrRuleNotFound
+	}
+	return copyRule(rule), nil
+}
+
+// UpdateRule replaces the settings of an escalation rule of the workspace. Tasks it already
+// escalated are not escalated again.
+func (s *service) UpdateRule(workspaceID, id uuid.UUID, req *escalation.RuleRequest) (*escalation.Rule, error) {
+	if err := s.validate(workspaceID, req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[id]
+	if !exists || rule.WorkspaceID != workspaceID {
+		return nil, escalation.ErrRuleNotFound
+	}
+	req.Apply(rule)
+	return copyRule(rule), nil
+}
+
+// DeleteRule deletes an escalation rule of the workspace
+func (s *service) DeleteRule(workspaceID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[id]
+	if !exists || rule.WorkspaceID != workspaceID {
+		return escalation.ErrRuleNotFound
+	}
+	delete(s.rules, id)
+	return nil
+}
+
+// Escalate emails the recipients of each rule about the open tasks of its workspace overdue
+// by its threshold. A task is escalated once per rule and due date; escalations that
+// failed are retried on the next run.
+func (s *service) Escalate(ctx context.Context, now time.Time) (int, error) {
+	s.escalateMu.Lock()
+	defer s.escalateMu.Unlock()
+
+	s.mu.Lock()
+	rules := make([]*escalation.Rule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		if rule.Enabled {
+			rules = append(rules, copyRule(rule))
+		}
+	}
+	s.mu.Unlock()
+	if len(rules) == 0 {
+		s.escalated = make(map[string]time.Time)
+		return 0, nil
+	}
+
+	// Escalations of tasks no longer overdue are forgotten, so they are escalated again if
+	// they are overdue again
+	escalated := make(map[string]time.Time, len(s.escalated))
+	var errs []error
+	sent := 0
+	for _, t := range s.taskService.DueTasks(now) {
+		for _, rule := range rules {
+			if !rule.Matches(t, now) {
+				continue
+			}
+			key := rule.ID.String() + "/" + t.ID.String()
+			if last, ok := s.escalated[key]; ok && last.Equal(*t.DueDate) {
+				escalated[key] = last
+				continue
+			}
+
+			ok, err := s.notificationService.SendEscalation(ctx, t, rule.Name,
+				s.recipients(rule.Notify, t), s.recipients(rule.CC, t), now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rule %s, task %s: %w", rule.ID, t.ID, err))
+				continue
+			}
+			// Tasks without any recipient left are not escalated again either
+			escalated[key] = *t.DueDate
+			if ok {
+				sent++
+			}
+		}
+	}
+	s.escalated = escalated
+
+	return sent, errors.Join(errs...)
+}
+
+// Run escalates the tasks overdue now
+func (s *service) Run(ctx context.Context) error {
+	if sent, err := s.Escalate(ctx, time.Now()); err != nil {
+		return fmt.Errorf("%d escalations sent: %w", sent, err)
+	}
+	return nil
+}
+
+// recipients returns the users the recipients of a rule stand for on the task, leaving out
+// users who are no longer members of its workspace or whose account was erased
+func (s *service) recipients(names []string, t *task.Task) []*auth.User {
+	var ids []uuid.UUID
+	for _, name := range names {
+		switch name {
+		case escalation.RecipientAssignee:
+			if t.AssigneeID != nil {
+				ids = append(ids, *t.AssigneeID)
+			} else {
+				ids = append(ids, t.UserID)
+			}
+		case escalation.RecipientCreator:
+			ids = append(ids, t.UserID)
+		case escalation.RecipientProjectOwner:
+			if owner, ok := s.projectOwner(*t.WorkspaceID, t.ProjectID); ok {
+				ids = append(ids, owner)
+			}
+		}
+	}
+
+	var users []*auth.User
+	for _, id := range ids {
+		if slices.ContainsFunc(users, func(u *auth.User) bool { return u.ID == id }) {
+			continue
+		}
+		if _, err := s.workspaceService.GetMember(*t.WorkspaceID, id); err != nil {
+			continue
+		}
+		if user, err := s.authService.GetUserByID(id); err == nil {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// projectOwner returns the user who created the project of the workspace
+func (s *service) projectOwner(workspaceID uuid.UUID, projectID *uuid.UUID) (uuid.UUID, bool) {
+	if projectID == nil {
+		return uuid.Nil, false
+	}
+	for _, project := range s.workspaceService.ListProjects(workspaceID) {
+		if project.ID == *projectID {
+			return project.CreatedBy, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// validate validates the rule request, whose project must belong to the workspace
+func (s *service) validate(workspaceID uuid.UUID, req *escalation.RuleRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if req.ProjectID != nil && !s.workspaceService.HasProject(workspaceID, *req.ProjectID) {
+		return errors.New("project not found in workspace")
+	}
+	return nil
+}
+
+// rulesOf returns the rules of the workspace, oldest first. The caller must hold the lock.
+func (s *service) rulesOf(workspaceID uuid.UUID) []*escalation.Rule {
+	var rules []*escalation.Rule
+	for _, rule := range s.rules {
+		if rule.WorkspaceID == workspaceID {
+			rules = append(rules, rule)
+		}
+	}
+	slices.SortFunc(rules, func(a, b *escalation.Rule) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return rules
+}
+
+// copyRule returns a copy of the rule sharing no slices with it
+func copyRule(rule *escalation.Rule) *escalation.Rule {
+	copied := *rule
+	copied.Notify = slices.Clone(rule.Notify)
+	copied.CC = slices.Clone(rule.CC)
+	return &copied
+}
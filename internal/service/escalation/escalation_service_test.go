@@ -0,0 +1,167 @@
+package escalation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/escalation"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	notificationService "todo-api/internal/service/notification"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	janeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+)
+
+type testEnv struct {
+	service       Service
+	tasks         taskService.Service
+	notifications notificationService.Service
+	mailer        *mailer.MemoryMailer
+	workspaceID   uuid.UUID
+	projectID     uuid.UUID
+}
+
+// setupTestService creates an escalation service of a workspace owned by John, who created
+// its project, with Jane as a member
+func setupTestService(t *testing.T) *testEnv {
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "https://todo.example.com"}}
+	authSvc := authService.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+
+	created, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	owner, err := workspaces.GetMember(created.ID, johnID)
+	require.NoError(t, err)
+	invitation, err := workspaces.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "jane.smith@example.com"}, owner)
+	require.NoError(t, err)
+	_, err = workspaces.AcceptInvitation(invitation.ID, janeID, "jane.smith@example.com")
+	require.NoError(t, err)
+	project, err := workspaces.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "Launch"}, johnID)
+	require.NoError(t, err)
+
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	tasks := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces})
+	m := mailer.NewMemoryMailer()
+	notifications := notificationService.NewService(cfg, authSvc, tasks, m)
+
+	return &testEnv{
+		service:       NewService(authSvc, tasks, workspaces, notifications),
+		tasks:         tasks,
+		notifications: notifications,
+		mailer:        m,
+		workspaceID:   created.ID,
+		projectID:     project.ID,
+	}
+}
+
+func TestService_Rules(t *testing.T) {
+	env := setupTestService(t)
+
+	_, err := env.service.CreateRule(env.workspaceID, &escalation.RuleRequest{Name: "Late", OverdueBy: "1h"}, johnID)
+	assert.Error(t, err)
+	otherProject := uuid.New()
+	_, err = env.service.CreateRule(env.workspaceID, &escalation.RuleRequest{
+		Name: "Late", OverdueBy: "1h", Notify: []string{escalation.RecipientAssignee}, ProjectID: &otherProject,
+	}, johnID)
+	assert.Error(t, err)
+
+	rule, err := env.service.CreateRule(env.workspaceID, &escalation.RuleRequest{
+		Name: "Late", OverdueBy: "1h", Notify: []string{escalation.RecipientAssignee},
+	}, johnID)
+	require.NoError(t, err)
+	assert.True(t, rule.Enabled)
+	assert.Len(t, env.service.ListRules(env.workspaceID), 1)
+	assert.Empty(t, env.service.ListRules(uuid.New()))
+
+	updated, err := env.service.UpdateRule(env.workspaceID, rule.ID, &escalation.RuleRequest{
+		Name: "Very late", OverdueBy: "48h", Notify: []string{escalation.RecipientCreator},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "48h", updated.OverdueBy)
+	assert.Equal(t, rule.CreatedAt, updated.CreatedAt)
+
+	// Rules of other workspaces are not found
+	_, err = env.service.GetRule(uuid.New(), rule.ID)
+	assert.ErrorIs(t, err, escalation.ErrRuleNotFound)
+	assert.ErrorIs(t, env.service.DeleteRule(uuid.New(), rule.ID), escalation.ErrRuleNotFound)
+
+	require.NoError(t, env.service.DeleteRule(env.workspaceID, rule.ID))
+	assert.Empty(t, env.service.ListRules(env.workspaceID))
+}
+
+func TestService_Escalate(t *testing.T) {
+	env := setupTestService(t)
+	now := time.Now()
+	due := now.Add(-30 * time.Hour)
+
+	urgent, err := env.tasks.CreateWorkspaceTask(env.workspaceID, &task.CreateTaskRequest{
+		Title: "Fix outage", Priority: task.PriorityHigh, DueDate: &due, ProjectID: &env.projectID,
+	}, janeID)
+	require.NoError(t, err)
+	_, err = env.tasks.CreateWorkspaceTask(env.workspaceID, &task.CreateTaskRequest{
+		Title: "Tidy docs", Priority: task.PriorityLow, DueDate: &due, ProjectID: &env.projectID,
+	}, janeID)
+	require.NoError(t, err)
+
+	_, err = env.service.CreateRule(env.workspaceID, &escalation.RuleRequest{
+		Name:      "Urgent overdue",
+		Priority:  task.PriorityHigh,
+		OverdueBy: "24h",
+		Notify:    []string{escalation.RecipientAssignee},
+		CC:        []string{escalation.RecipientProjectOwner},
+	}, johnID)
+	require.NoError(t, err)
+
+	// Tasks are not escalated before they are overdue by the threshold
+	sent, err := env.service.Escalate(context.Background(), now.Add(-7*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	// The assignee is notified again with the project owner in copy
+	sent, err = env.service.Escalate(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	messages := env.mailer.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Escalated: Fix outage", messages[0].Subject)
+	assert.Equal(t, []string{"jane.smith@example.com"}, messages[0].To)
+	assert.Equal(t, []string{"john.doe@example.com"}, messages[0].Cc)
+	assert.Contains(t, messages[0].Text, `rule "Urgent overdue"`)
+
+	// Tasks are escalated once per due date
+	sent, err = env.service.Escalate(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	newDue := now.Add(-25 * time.Hour)
+	_, err = env.tasks.UpdateTask(urgent.ID, &task.UpdateTaskRequest{DueDate: &newDue}, janeID)
+	require.NoError(t, err)
+	env.notifications.UpdateSettings(janeID, notification.UpdateSettingsRequest{
+		notification.EventEscalated: {notification.ChannelEmail: false},
+	})
+
+	// The copied users are emailed directly when the recipients turned escalations off
+	sent, err = env.service.Escalate(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	messages = env.mailer.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, []string{"john.doe@example.com"}, messages[1].To)
+	assert.Empty(t, messages[1].Cc)
+}
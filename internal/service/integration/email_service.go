@@ -0,0 +1,302 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	attachmentService "todo-api/internal/service/attachment"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/inbound"
+
+	"github.com/google/uuid"
+)
+
+// signatureDelimiter separates the body of an email from the signature of the sender
+const signatureDelimiter = "\n-- \n"
+
+// addressTokenEncoding encodes the tokens of email-in addresses in lowercase, as some mail
+// servers do not preserve the case of local parts
+var addressTokenEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// EmailService defines the email-in service interface. Users create tasks by sending emails
+// to their email-in address from their account's email address; the subject is the title of
+// the task, the text its description, and the attachments are attached to it.
+type EmailService interface {
+	GetEmailAddress(userID uuid.UUID) (*integration.EmailAddress, error)
+	// CreateEmailAddress creates the email-in address of the user, replacing any previous
+	// one, which stops working
+	CreateEmailAddress(userID uuid.UUID) (*integration.EmailAddress, error)
+	DeleteEmailAddress(userID uuid.UUID) error
+	// DeleteUserData deletes the user's address when the account is erased, satisfying the
+	// eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	// ReceiveMailgun verifies an email forwarded by a Mailgun route and creates its task
+	ReceiveMailgun(ctx context.Context, values url.Values, files map[string][]*multipart.FileHeader, now time.Time) (*task.Task, error)
+	// ReceiveSNS verifies a message posted by SNS. The task of an email received by SES is
+	// created, while subscriptions are confirmed, returning no task.
+	ReceiveSNS(ctx context.Context, body []byte) (*task.Task, error)
+}
+
+// emailService implements the email-in service
+type emailService struct {
+	mu                sync.Mutex
+	addresses         map[string]*integration.EmailAddress // Mock address storage by token
+	authService       authService.Service
+	taskService       taskService.Service
+	attachmentService attachmentService.Service // attachments of emails are dropped when nil
+	sns               *inbound.SNSVerifier
+	plans             PlanDirectory
+	config            *config.Config
+}
+
+// NewEmailService creates a new email-in service creating tasks through the task service,
+// with the attachments of emails attached through the attachment service, if any. SNS
+// certificates are downloaded through the transport, http.DefaultTransport when nil.
+func NewEmailService(cfg *config.Config, authSvc authService.Service, taskSvc taskService.Service,
+	attachmentSvc attachmentService.Service, transport http.RoundTripper) EmailService {
+	return NewEmailServiceWithPlans(cfg, authSvc, taskSvc, attachmentSvc, transport, unlimitedPlans{})
+}
+
+// NewEmailServiceWithPlans creates a new email-in service creating addresses only when the
+// user's plan allows email-in
+func NewEmailServiceWithPlans(cfg *config.Config, authSvc authService.Service, taskSvc taskService.Service,
+	attachmentSvc attachmentService.Service, transport http.RoundTripper, plans PlanDirectory) EmailService {
+	client := &http.Client{Transport: transport, Timeout: cfg.Inbound.Timeout}
+	return &emailService{
+		addresses:         make(map[string]*integration.EmailAddress),
+		authService:       authSvc,
+		taskService:       taskSvc,
+		attachmentService: attachmentSvc,
+		sns:               inbound.NewSNSVerifier(client),
+		plans:             plans,
+		config:            cfg,
+	}
+}
+
+// GetEmailAddress returns the email-in address of the user
+func (s *emailService) GetEmailAddress(userID uuid.UUID) (*integration.EmailAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, address := range s.addresses {
+		if address.UserID == userID {
+			found := *address
+			return &found, nil
+		}
+	}
+	return nil, errors.New("email-in address not found")
+}
+
+// CreateEmailAddress creates the email-in address of the user, replacing any previous one
+func (s *emailService) CreateEmailAddress(userID uuid.UUID) (*integration.EmailAddress, error) {
+	s.mu.Lock()
+	_, replacing := s.findAddress(userID)
+	s.mu.Unlock()
+	if !replacing {
+		if err := s.plans.AllowIntegration(userID, integration.NameEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.New("failed to generate email-in address")
+	}
+	token := addressTokenEncoding.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.findAddress(userID); ok {
+		delete(s.addresses, previous.Token)
+	}
+	address := &integration.EmailAddress{
+		UserID:    userID,
+		Token:     token,
+		Address:   integration.EmailAddressPrefix + token + "@" + s.config.Inbound.Domain,
+		CreatedAt: time.Now(),
+	}
+	s.addresses[token] = address
+
+	created := *address
+	return &created, nil
+}
+
+// DeleteEmailAddress deletes the email-in address of the user, so emails sent to it are rejected
+func (s *emailService) DeleteEmailAddress(userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	address, ok := s.findAddress(userID)
+	if !ok {
+		return errors.New("email-in address not found")
+	}
+	delete(s.addresses, address.Token)
+	return nil
+}
+
+// DeleteUserData deletes the email-in address of the user, if any, so emails sent to it no
+// longer create tasks
+func (s *emailService) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token, address := range s.addresses {
+		if address.UserID == userID {
+			delete(s.addresses, token)
+		}
+	}
+}
+
+// ReceiveMailgun verifies the signature of an email forwarded by a Mailgun route, then
+// creates its task
+func (s *emailService) ReceiveMailgun(ctx context.Context, values url.Values, files map[string][]*multipart.FileHeader,
+	now time.Time) (*task.Task, error) {
+	if err := inbound.VerifyMailgunSignature(s.config.Inbound.SigningKey, values.Get("timestamp"), values.Get("token"),
+		values.Get("signature"), now); err != nil {
+		return nil, inbound.ErrInvalidSignature
+	}
+
+	msg, err := inbound.ParseMailgun(values, files)
+	if err != nil {
+		return nil, err
+	}
+	return s.receive(ctx, msg)
+}
+
+// ReceiveSNS verifies the signature of a message SNS posted for the configured topic. The
+// subscription of the topic is confirmed, and the task of an email received by SES created.
+func (s *emailService) ReceiveSNS(ctx context.Context, body []byte) (*task.Task, error) {
+	msg, err := inbound.ParseSNSMessage(body)
+	if err != nil {
+		return nil, err
+	}
+	// Only the configured topic is trusted, as anyone may publish to their own topics
+	if msg.TopicARN != s.config.Inbound.SESTopicARN {
+		return nil, inbound.ErrInvalidSignature
+	}
+	if err := s.sns.Verify(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	switch msg.Type {
+	case inbound.SNSTypeSubscriptionConfirmation:
+		return nil, s.sns.ConfirmSubscription(ctx, msg)
+	case inbound.SNSTypeNotification:
+		email, err := inbound.ParseSES(msg.Message)
+		if err != nil {
+			return nil, err
+		}
+		return s.receive(ctx, email)
+	default:
+		return nil, nil
+	}
+}
+
+// receive creates the task of an email sent to an email-in address by its user. The sender
+// must be the email address of the user, and the provider must have authenticated it, so
+// the From address is not forged. Attachments that cannot be attached are skipped.
+func (s *emailService) receive(ctx context.Context, msg *inbound.Message) (*task.Task, error) {
+	userID, ok := s.recipient(msg.Recipients)
+	if !ok {
+		return nil, fmt.Errorf("%w: no recipient is an email-in address", integration.ErrEmailRejected)
+	}
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil || user.DeactivatedAt != nil {
+		return nil, fmt.Errorf("%w: no recipient is an email-in address", integration.ErrEmailRejected)
+	}
+	if !msg.Authenticated {
+		return nil, fmt.Errorf("%w: the sender failed SPF and DKIM or the email is spam", integration.ErrEmailRejected)
+	}
+	if !strings.EqualFold(msg.From, user.Email) {
+		return nil, fmt.Errorf("%w: the sender is not the email address of the user", integration.ErrEmailRejected)
+	}
+
+	created, err := s.taskService.CreateTask(taskFromEmail(msg), userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", integration.ErrEmailRejected, err)
+	}
+
+	for _, a := range msg.Attachments {
+		if s.attachmentService == nil {
+			log.Printf("Dropped %d attachments of email to task %s: attachments are disabled", len(msg.Attachments), created.ID)
+			break
+		}
+		if _, err := s.attachmentService.AttachFile(ctx, created.ID, a.Filename, a.ContentType, a.Data, userID); err != nil {
+			log.Printf("Failed to attach %q of email to task %s: %v", a.Filename, created.ID, err)
+		}
+	}
+	return created, nil
+}
+
+// recipient returns the user of the first recipient that is an email-in address
+func (s *emailService) recipient(recipients []string) (uuid.UUID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, recipient := range recipients {
+		local, domain, ok := strings.Cut(strings.ToLower(recipient), "@")
+		token, hasPrefix := strings.CutPrefix(local, integration.EmailAddressPrefix)
+		if !ok || !hasPrefix || domain != s.config.Inbound.Domain {
+			continue
+		}
+		if address, exists := s.addresses[token]; exists {
+			return address.UserID, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// findAddress returns the email-in address of the user. The caller must hold the lock.
+func (s *emailService) findAddress(userID uuid.UUID) (*integration.EmailAddress, bool) {
+	for _, address := range s.addresses {
+		if address.UserID == userID {
+			return address, true
+		}
+	}
+	return nil, false
+}
+
+// taskFromEmail returns the request creating the task of the email: the subject is its
+// title, or else the first line of the text, and the text without the signature its
+// description, both cut to their maximum length
+func taskFromEmail(msg *inbound.Message) *task.CreateTaskRequest {
+	text := strings.ReplaceAll(msg.Text, "\r\n", "\n")
+	if i := strings.Index(text, signatureDelimiter); i != -1 {
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+
+	title := strings.Join(strings.Fields(msg.Subject), " ")
+	if title == "" {
+		firstLine, rest, _ := strings.Cut(text, "\n")
+		title, text = strings.TrimSpace(firstLine), strings.TrimSpace(rest)
+	}
+	if utf8.RuneCountInString(title) > task.MaxTitleLength {
+		title = string([]rune(title)[:task.MaxTitleLength])
+	}
+
+	if len(text) > task.MaxDescriptionLength {
+		cut := task.MaxDescriptionLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+	return &task.CreateTaskRequest{Title: title, Description: text}
+}
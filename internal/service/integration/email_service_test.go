@@ -0,0 +1,221 @@
+package integration
+
+import (
+	"context"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	attachmentService "todo-api/internal/service/attachment"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/blob"
+	"todo-api/pkg/config"
+	"todo-api/pkg/inbound"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEmailService(t *testing.T) (EmailService, authService.Service, attachmentService.Service) {
+	cfg := &config.Config{
+		Inbound: config.InboundEmailConfig{
+			Domain:      "todo.example",
+			Provider:    "mailgun",
+			SigningKey:  "key",
+			SESTopicARN: "arn:aws:sns:us-east-1:123456789012:inbound",
+			Timeout:     time.Second,
+		},
+		Files: config.AttachmentsConfig{MaxSize: 1024, URLTTL: time.Minute, Timeout: time.Second},
+	}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	attachmentSvc := attachmentService.NewService(cfg.Files, taskSvc, blob.NewMemoryStore(), bus)
+	return NewEmailService(cfg, authSvc, taskSvc, attachmentSvc, nil), authSvc, attachmentSvc
+}
+
+// mailgunEmail returns the signed form fields of an email forwarded by Mailgun, from a sender
+// that passed SPF
+func mailgunEmail(from, recipient, subject, text string, now time.Time) url.Values {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	return url.Values{
+		"timestamp":       {timestamp},
+		"token":           {"token"},
+		"signature":       {inbound.SignMailgun("key", timestamp, "token")},
+		"recipient":       {recipient},
+		"from":            {from},
+		"subject":         {subject},
+		"body-plain":      {text},
+		"message-headers": {`[["X-Mailgun-Spf","Pass"]]`},
+	}
+}
+
+func TestEmailService_Address(t *testing.T) {
+	service, authSvc, _ := setupEmailService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+
+	_, err := service.GetEmailAddress(john.ID)
+	assert.Error(t, err)
+
+	address, err := service.CreateEmailAddress(john.ID)
+	require.NoError(t, err)
+	assert.Regexp(t, `^add\+[a-z2-7]{26}@todo\.example$`, address.Address)
+
+	found, err := service.GetEmailAddress(john.ID)
+	require.NoError(t, err)
+	assert.Equal(t, address.Address, found.Address)
+
+	// Creating another address replaces the first
+	replaced, err := service.CreateEmailAddress(john.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, address.Address, replaced.Address)
+	found, err = service.GetEmailAddress(john.ID)
+	require.NoError(t, err)
+	assert.Equal(t, replaced.Address, found.Address)
+
+	require.NoError(t, service.DeleteEmailAddress(john.ID))
+	assert.Error(t, service.DeleteEmailAddress(john.ID))
+
+	_, err = service.CreateEmailAddress(john.ID)
+	require.NoError(t, err)
+	service.DeleteUserData(john.ID)
+	_, err = service.GetEmailAddress(john.ID)
+	assert.Error(t, err)
+}
+
+func TestEmailService_ReceiveMailgun(t *testing.T) {
+	service, authSvc, attachmentSvc := setupEmailService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	address, err := service.CreateEmailAddress(john.ID)
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now()
+
+	values := mailgunEmail("John Doe <John.Doe@example.com>", strings.ToUpper(address.Address), "Buy  milk",
+		"Semi-skimmed\r\n\r\n-- \r\nJohn", now)
+	created, err := service.ReceiveMailgun(ctx, values, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Buy milk", created.Title)
+	assert.Equal(t, "Semi-skimmed", created.Description)
+	assert.Equal(t, john.ID, created.UserID)
+
+	// Unsigned webhooks are rejected
+	values.Set("signature", "forged")
+	_, err = service.ReceiveMailgun(ctx, values, nil, now)
+	assert.ErrorIs(t, err, inbound.ErrInvalidSignature)
+
+	rejected := []struct {
+		name   string
+		values url.Values
+	}{
+		{"other sender", mailgunEmail("jane.smith@example.com", address.Address, "Buy milk", "", now)},
+		{"unknown address", mailgunEmail(john.Email, "add+unknown@todo.example", "Buy milk", "", now)},
+		{"other domain", mailgunEmail(john.Email, strings.Replace(address.Address, "todo.example", "other.example", 1), "Buy milk", "", now)},
+		{"no title", mailgunEmail(john.Email, address.Address, "", "", now)},
+	}
+	unauthenticated := mailgunEmail(john.Email, address.Address, "Buy milk", "", now)
+	unauthenticated.Set("message-headers", `[["X-Mailgun-Spf","Fail"]]`)
+	rejected = append(rejected, struct {
+		name   string
+		values url.Values
+	}{"forged sender", unauthenticated})
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ReceiveMailgun(ctx, tt.values, nil, now)
+			assert.ErrorIs(t, err, integration.ErrEmailRejected)
+		})
+	}
+
+	// Emails to replaced addresses are rejected
+	_, err = service.CreateEmailAddress(john.ID)
+	require.NoError(t, err)
+	_, err = service.ReceiveMailgun(ctx, mailgunEmail(john.Email, address.Address, "Buy milk", "", now), nil, now)
+	assert.ErrorIs(t, err, integration.ErrEmailRejected)
+
+	attachments, err := attachmentSvc.ListAttachments(created.ID, john.ID)
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+}
+
+func TestEmailService_Attachments(t *testing.T) {
+	service, authSvc, attachmentSvc := setupEmailService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	address, err := service.CreateEmailAddress(john.ID)
+	require.NoError(t, err)
+	now := time.Now()
+
+	form := newMultipartForm(t, map[string]string{"attachment-1": "list.txt", "attachment-2": "huge.bin"},
+		map[string]string{"list.txt": "milk, eggs", "huge.bin": strings.Repeat("x", 2048)})
+	created, err := service.ReceiveMailgun(context.Background(), mailgunEmail(john.Email, address.Address, "Shopping", "", now),
+		form.File, now)
+	require.NoError(t, err)
+
+	// Files larger than allowed are skipped
+	attachments, err := attachmentSvc.ListAttachments(created.ID, john.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "list.txt", attachments[0].Filename)
+	assert.Equal(t, int64(10), attachments[0].Size)
+}
+
+func TestEmailService_ReceiveSNS(t *testing.T) {
+	service, _, _ := setupEmailService(t)
+
+	// Messages of other topics are rejected before their signature is checked
+	body := `{"Type":"Notification","TopicArn":"arn:aws:sns:us-east-1:999999999999:other","Message":"{}"}`
+	_, err := service.ReceiveSNS(context.Background(), []byte(body))
+	assert.ErrorIs(t, err, inbound.ErrInvalidSignature)
+
+	_, err = service.ReceiveSNS(context.Background(), []byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestTaskFromEmail(t *testing.T) {
+	tests := []struct {
+		name        string
+		subject     string
+		text        string
+		title       string
+		description string
+	}{
+		{"subject", "  Buy\tmilk ", "Semi-skimmed\n", "Buy milk", "Semi-skimmed"},
+		{"signature", "Buy milk", "Semi-skimmed\r\n-- \r\nJohn Doe\r\n", "Buy milk", "Semi-skimmed"},
+		{"no subject", "", "\nBuy milk\nSemi-skimmed\n", "Buy milk", "Semi-skimmed"},
+		{"long subject", strings.Repeat("é", task.MaxTitleLength+1), "", strings.Repeat("é", task.MaxTitleLength), ""},
+		{"long text", "Read", strings.Repeat("é", task.MaxDescriptionLength), "Read", strings.Repeat("é", task.MaxDescriptionLength/2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := taskFromEmail(&inbound.Message{Subject: tt.subject, Text: tt.text})
+			assert.Equal(t, tt.title, req.Title)
+			assert.Equal(t, tt.description, req.Description)
+		})
+	}
+}
+
+// newMultipartForm returns a form with the files of the fields, named by field, with the
+// content of each file name
+func newMultipartForm(t *testing.T, fields map[string]string, contents map[string]string) *multipart.Form {
+	var body strings.Builder
+	writer := multipart.NewWriter(&body)
+	for field, filename := range fields {
+		part, err := writer.CreateFormFile(field, filename)
+		require.NoError(t, err)
+		part.Write([]byte(contents[filename]))
+	}
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(strings.NewReader(body.String()), writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+	return form
+}
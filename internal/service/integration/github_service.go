@@ -0,0 +1,729 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+	"todo-api/pkg/github"
+
+	"github.com/google/uuid"
+)
+
+// maxTitleLength is the longest task title; longer issue titles are truncated
+const maxTitleLength = 200
+
+// GitHubService defines the GitHub integration service interface. Users authorize the OAuth
+// app, then workspace admins link projects to repositories: open issues become tasks of the
+// project, and titles and open or closed states are synced both ways.
+type GitHubService interface {
+	AuthorizeGitHub(userID uuid.UUID) (*integration.GitHubAuthorization, error)
+	// CompleteGitHubAuthorization exchanges the code GitHub redirected the user back with
+	CompleteGitHubAuthorization(ctx context.Context, state, code string) (*integration.GitHubAccount, error)
+	GetGitHubAccount(userID uuid.UUID) (*integration.GitHubAccount, error)
+	DisconnectGitHub(userID uuid.UUID) error
+	// DeleteUserData deletes the user's account and its token when the account is erased,
+	// satisfying the eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	LinkProject(ctx context.Context, workspaceID, projectID, userID uuid.UUID, req *integration.LinkGitHubRequest) (*integration.GitHubLink, error)
+	GetProjectLink(workspaceID, projectID uuid.UUID) (*integration.GitHubLink, error)
+	UnlinkProject(workspaceID, projectID uuid.UUID) error
+	// HandleIssuesEvent applies an issue change delivered by the GitHub webhook
+	HandleIssuesEvent(ctx context.Context, event *github.IssuesEvent)
+	// Sync syncs every linked project with its repository
+	Sync(ctx context.Context) error
+}
+
+// oauthState is a pending OAuth authorization, stored by its hashed state
+type oauthState struct {
+	userID    uuid.UUID
+	expiresAt time.Time
+}
+
+// githubLink is a linked project with its sync state
+type githubLink struct {
+	integration.GitHubLink
+	cursor time.Time            // last update of the issues listed, where the next listing starts
+	issues map[int]*syncedIssue // synced issues by number
+}
+
+// syncedIssue is an issue synced with a task, with the values both had when last synced.
+// Changes made since are detected by comparing the update times.
+type syncedIssue struct {
+	taskID         uuid.UUID
+	title          string
+	state          string
+	issueUpdatedAt time.Time
+	taskUpdatedAt  time.Time
+}
+
+// githubService implements the GitHub integration service
+type githubService struct {
+	mu       sync.Mutex                               // guards the maps and link fields
+	accounts map[uuid.UUID]*integration.GitHubAccount // Mock account storage
+	states   map[string]*oauthState                   // Pending authorizations by state
+	links    map[uuid.UUID]*githubLink                // Mock link storage by project ID
+	// syncMu serializes syncing, so the changes of the worker, the webhook, and task events
+	// are merged one at a time
+	syncMu           sync.Mutex
+	taskService      taskService.Service
+	workspaceService workspaceService.Service
+	client           github.Client
+	plans            PlanDirectory
+	jobs             jobs.Queue // applies webhook events, if any
+	config           *config.Config
+}
+
+// issuesEventJob applies an issue change delivered by the webhook
+type issuesEventJob struct {
+	Event github.IssuesEvent `json:"event"`
+}
+
+func (issuesEventJob) JobType() string { return "github.issues_event" }
+
+// GitHubDeps are the dependencies of a GitHub integration service. Plans and Jobs are
+// optional, and a zero value keeps the default noted next to each.
+type GitHubDeps struct {
+	Config     *config.Config
+	Tasks      taskService.Service
+	Workspaces workspaceService.Service
+	Client     github.Client
+	Bus        events.Bus    // task changes published on it are pushed to their issues
+	Plans      PlanDirectory // every plan allows GitHub
+	// Jobs applies webhook events as jobs, so deliveries are answered at once and failures
+	// are retried; events are applied within the delivery when it is nil
+	Jobs jobs.Queue
+}
+
+// NewGitHubService creates a new GitHub integration service. Task changes published on the
+// bus are pushed to their issues as they happen; the sync worker catches up on the rest.
+func NewGitHubService(cfg *config.Config, taskSvc taskService.Service, workspaceSvc workspaceService.Service, client github.Client,
+	bus events.Bus) GitHubService {
+	return NewGitHubServiceWithDeps(GitHubDeps{Config: cfg, Tasks: taskSvc, Workspaces: workspaceSvc, Client: client, Bus: bus})
+}
+
+// NewGitHubServiceWithDeps creates a new GitHub integration service, using the defaults of
+// GitHubDeps for the dependencies left unset
+func NewGitHubServiceWithDeps(deps GitHubDeps) GitHubService {
+	if deps.Plans == nil {
+		deps.Plans = unlimitedPlans{}
+	}
+	queue := deps.Jobs
+
+	s := &githubService{
+		accounts:         make(map[uuid.UUID]*integration.GitHubAccount),
+		states:           make(map[string]*oauthState),
+		links:            make(map[uuid.UUID]*githubLink),
+		taskService:      deps.Tasks,
+		workspaceService: deps.Workspaces,
+		client:           deps.Client,
+		plans:            deps.Plans,
+		jobs:             queue,
+		config:           deps.Config,
+	}
+
+	deps.Bus.Subscribe(func(event events.Event) {
+		if taskEvent, ok := event.(*task.Event); ok {
+			s.pushTaskEvent(context.Background(), taskEvent)
+		}
+	})
+	if queue != nil {
+		jobs.Handle(queue, func(ctx context.Context, job issuesEventJob) error {
+			return s.applyIssuesEvent(ctx, &job.Event)
+		})
+	}
+
+	return s
+}
+
+// AuthorizeGitHub starts authorizing the OAuth app, returning the URL to send the user to
+func (s *githubService) AuthorizeGitHub(userID uuid.UUID) (*integration.GitHubAuthorization, error) {
+	if s.config.GitHub.ClientID == "" {
+		return nil, errors.New("github is not configured")
+	}
+	if err := s.plans.AllowIntegration(userID, integration.NameGitHub); err != nil {
+		return nil, err
+	}
+
+	state, err := newOAuthState()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, pending := range s.states {
+		if pending.userID == userID || now.After(pending.expiresAt) {
+			delete(s.states, key)
+		}
+	}
+	expiresAt := now.Add(s.config.GitHub.AuthorizationTTL)
+	s.states[hashCode(state)] = &oauthState{userID: userID, expiresAt: expiresAt}
+
+	return &integration.GitHubAuthorization{
+		URL:       s.client.AuthorizeURL(s.redirectURI(), state),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CompleteGitHubAuthorization exchanges the code for an access token of the user who
+// started the authorization with the state. States are single-use.
+func (s *githubService) CompleteGitHubAuthorization(ctx context.Context, state, code string) (*integration.GitHubAccount, error) {
+	s.mu.Lock()
+	pending, exists := s.states[hashCode(state)]
+	delete(s.states, hashCode(state))
+	s.mu.Unlock()
+
+	if !exists || time.Now().After(pending.expiresAt) {
+		return nil, errors.New("invalid or expired state")
+	}
+	// Other integrations may have been connected since the authorization started
+	if err := s.plans.AllowIntegration(pending.userID, integration.NameGitHub); err != nil {
+		return nil, err
+	}
+
+	token, err := s.client.ExchangeCode(ctx, code, s.redirectURI())
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize github: %w", err)
+	}
+	user, err := s.client.GetUser(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize github: %w", err)
+	}
+
+	account := &integration.GitHubAccount{
+		UserID:       pending.userID,
+		Login:        user.Login,
+		AccessToken:  token,
+		AuthorizedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	s.accounts[pending.userID] = account
+	s.mu.Unlock()
+
+	authorized := *account
+	return &authorized, nil
+}
+
+// GetGitHubAccount returns the GitHub account the user authorized
+func (s *githubService) GetGitHubAccount(userID uuid.UUID) (*integration.GitHubAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, exists := s.accounts[userID]
+	if !exists {
+		return nil, errors.New("github is not connected")
+	}
+	copied := *account
+	return &copied, nil
+}
+
+// DisconnectGitHub forgets the user's GitHub account. Projects the user linked stop syncing
+// until they authorize again.
+func (s *githubService) DisconnectGitHub(userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.accounts[userID]; !exists {
+		return errors.New("github is not connected")
+	}
+	delete(s.accounts, userID)
+	return nil
+}
+
+// DeleteUserData deletes the GitHub account of the user, if any, with its token, and the
+// user's pending authorizations. Projects the user linked stay linked, like on disconnect.
+func (s *githubService) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.accounts, userID)
+	for hash, pending := range s.states {
+		if pending.userID == userID {
+			delete(s.states, hash)
+		}
+	}
+}
+
+// LinkProject links the project to the repository with the user's GitHub account, replacing
+// any previous link. The user must be able to push to the repository to close its issues.
+// Open issues are imported by the next sync.
+func (s *githubService) LinkProject(ctx context.Context, workspaceID, projectID, userID uuid.UUID,
+	req *integration.LinkGitHubRequest) (*integration.GitHubLink, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !s.workspaceService.HasProject(workspaceID, projectID) {
+		return nil, errors.New("project not found")
+	}
+
+	token, err := s.token(userID)
+	if err != nil {
+		return nil, err
+	}
+	owner, name, _ := strings.Cut(req.Repository, "/")
+	repository, err := s.client.GetRepository(ctx, token, owner, name)
+	switch {
+	case github.IsNotFound(err):
+		return nil, errors.New("repository not found")
+	case err != nil:
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	case !repository.Permissions.Push:
+		return nil, errors.New("github account cannot push to the repository")
+	}
+
+	link := &githubLink{
+		GitHubLink: integration.GitHubLink{
+			ProjectID:   projectID,
+			WorkspaceID: workspaceID,
+			Repository:  repository.FullName,
+			LinkedBy:    userID,
+			CreatedAt:   time.Now(),
+		},
+		issues: make(map[int]*syncedIssue),
+	}
+
+	s.mu.Lock()
+	s.links[projectID] = link
+	s.mu.Unlock()
+
+	linked := link.GitHubLink
+	return &linked, nil
+}
+
+// GetProjectLink returns the repository the project is linked to, with its sync status
+func (s *githubService) GetProjectLink(workspaceID, projectID uuid.UUID) (*integration.GitHubLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, exists := s.links[projectID]
+	if !exists || link.WorkspaceID != workspaceID {
+		return nil, errors.New("project is not linked to github")
+	}
+	linked := link.GitHubLink
+	return &linked, nil
+}
+
+// UnlinkProject stops syncing the project. Its tasks are kept.
+func (s *githubService) UnlinkProject(workspaceID, projectID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, exists := s.links[projectID]
+	if !exists || link.WorkspaceID != workspaceID {
+		return errors.New("project is not linked to github")
+	}
+	delete(s.links, projectID)
+	return nil
+}
+
+// HandleIssuesEvent applies the issue change to the projects linked to its repository, or
+// enqueues it with a job queue, which retries failures. Otherwise failures are logged and
+// left for the sync worker.
+func (s *githubService) HandleIssuesEvent(ctx context.Context, event *github.IssuesEvent) {
+	if event.Issue == nil || event.Issue.IsPullRequest() {
+		return
+	}
+
+	if s.jobs != nil {
+		if _, err := s.jobs.Enqueue(issuesEventJob{Event: *event}); err != nil {
+			log.Printf("Failed to enqueue issue #%d of %s: %v", event.Issue.Number, event.Repository.FullName, err)
+		}
+		return
+	}
+	if err := s.applyIssuesEvent(ctx, event); err != nil {
+		log.Printf("Failed to sync issue #%d: %v", event.Issue.Number, err)
+	}
+}
+
+// applyIssuesEvent applies the issue change to the projects linked to its repository,
+// reporting the projects that failed
+func (s *githubService) applyIssuesEvent(ctx context.Context, event *github.IssuesEvent) error {
+	var errs []error
+	for _, link := range s.linkedTo(event.Repository.FullName) {
+		s.syncMu.Lock()
+		switch event.Action {
+		case "deleted", "transferred":
+			// The issue is gone from the repository; its task is kept
+			delete(link.issues, event.Issue.Number)
+		default:
+			token, err := s.token(link.LinkedBy)
+			if err == nil {
+				err = s.applyIssue(ctx, token, link, event.Issue)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", link.Repository, err))
+			}
+		}
+		s.syncMu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Sync syncs every linked project, reporting the projects that failed
+func (s *githubService) Sync(ctx context.Context) error {
+	s.mu.Lock()
+	links := make([]*githubLink, 0, len(s.links))
+	for _, link := range s.links {
+		links = append(links, link)
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, link := range links {
+		if err := s.syncLink(ctx, link); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", link.Repository, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncLink applies the issues changed since the last sync to the project, then pushes the
+// tasks changed since to their issues. The outcome is recorded on the link.
+func (s *githubService) syncLink(ctx context.Context, link *githubLink) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	startedAt := time.Now()
+	err := s.pull(ctx, link)
+	if err == nil {
+		err = s.push(ctx, link)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		link.LastSyncError = err.Error()
+		return err
+	}
+	link.LastSyncedAt = &startedAt
+	link.LastSyncError = ""
+	return nil
+}
+
+// pull applies the issues changed since the last listing. The caller must hold syncMu.
+func (s *githubService) pull(ctx context.Context, link *githubLink) error {
+	token, err := s.token(link.LinkedBy)
+	if err != nil {
+		return err
+	}
+
+	issues, err := s.client.ListIssues(ctx, token, link.Owner(), link.Name(), link.cursor)
+	if err != nil {
+		s.checkRevoked(link.LinkedBy, err)
+		return err
+	}
+	for _, issue := range issues {
+		if !issue.IsPullRequest() {
+			if err := s.applyIssue(ctx, token, link, issue); err != nil {
+				return fmt.Errorf("issue #%d: %w", issue.Number, err)
+			}
+		}
+		// Issues are listed by update time, so the next listing resumes after this one
+		if issue.UpdatedAt.After(link.cursor) {
+			link.cursor = issue.UpdatedAt
+		}
+	}
+	return nil
+}
+
+// push pushes the tasks changed since they were last synced to their issues, and forgets the
+// issues of deleted tasks. The caller must hold syncMu.
+func (s *githubService) push(ctx context.Context, link *githubLink) error {
+	for number, synced := range link.issues {
+		t, err := s.taskService.GetTaskByID(synced.taskID, link.LinkedBy)
+		if err != nil {
+			if err.Error() == "task not found" {
+				delete(link.issues, number)
+				continue
+			}
+			return err
+		}
+		if !t.UpdatedAt.After(synced.taskUpdatedAt) {
+			continue
+		}
+		if err := s.pushTask(ctx, link, number, synced, t); err != nil {
+			return fmt.Errorf("issue #%d: %w", number, err)
+		}
+	}
+	return nil
+}
+
+// pushTaskEvent pushes a change to the title or status of a synced task to its issue as it
+// happens. Changes made by syncing are not pushed back.
+func (s *githubService) pushTaskEvent(ctx context.Context, e *task.Event) {
+	if e.Type != task.EventTaskUpdated || e.Task == nil {
+		return
+	}
+	if _, titleChanged := e.Change("title"); !titleChanged {
+		if _, statusChanged := e.Change("status"); !statusChanged {
+			return
+		}
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	link, number, synced := s.syncedTask(e.TaskID)
+	if synced == nil || !e.Task.UpdatedAt.After(synced.taskUpdatedAt) {
+		return
+	}
+	t, err := s.taskService.GetTaskByID(e.TaskID, link.LinkedBy)
+	if err == nil {
+		err = s.pushTask(ctx, link, number, synced, t)
+	}
+	if err != nil {
+		log.Printf("Failed to push task %s to issue #%d of %s: %v", e.TaskID, number, link.Repository, err)
+	}
+}
+
+// pushTask merges the task with the current state of its issue. The caller must hold syncMu.
+func (s *githubService) pushTask(ctx context.Context, link *githubLink, number int, synced *syncedIssue, t *task.Task) error {
+	token, err := s.token(link.LinkedBy)
+	if err != nil {
+		return err
+	}
+	issue, err := s.client.GetIssue(ctx, token, link.Owner(), link.Name(), number)
+	if github.IsNotFound(err) {
+		delete(link.issues, number)
+		return nil
+	}
+	if err != nil {
+		s.checkRevoked(link.LinkedBy, err)
+		return err
+	}
+	return s.merge(ctx, token, link, number, synced, issue, t)
+}
+
+// applyIssue applies an issue listed or delivered by the webhook: open issues not synced yet
+// become tasks of the project, and changed issues are merged with their tasks. The caller
+// must hold syncMu.
+func (s *githubService) applyIssue(ctx context.Context, token string, link *githubLink, issue *github.Issue) error {
+	synced, exists := link.issues[issue.Number]
+	if !exists {
+		// Issues closed before the project was linked are not imported
+		if issue.State != github.StateOpen {
+			return nil
+		}
+		return s.importIssue(link, issue)
+	}
+	if !issue.UpdatedAt.After(synced.issueUpdatedAt) {
+		return nil
+	}
+
+	t, err := s.taskService.GetTaskByID(synced.taskID, link.LinkedBy)
+	if err != nil {
+		if err.Error() == "task not found" {
+			delete(link.issues, issue.Number)
+			return nil
+		}
+		return err
+	}
+	return s.merge(ctx, token, link, issue.Number, synced, issue, t)
+}
+
+// importIssue creates a task of the project for the issue. The caller must hold syncMu.
+func (s *githubService) importIssue(link *githubLink, issue *github.Issue) error {
+	projectID := link.ProjectID
+	created, err := s.taskService.CreateWorkspaceTask(link.WorkspaceID, &task.CreateTaskRequest{
+		Title:       issueTitle(issue),
+		Description: issueDescription(issue),
+		ProjectID:   &projectID,
+	}, link.LinkedBy)
+	if err != nil {
+		return err
+	}
+
+	link.issues[issue.Number] = &syncedIssue{
+		taskID:         created.ID,
+		title:          created.Title,
+		state:          github.StateOpen,
+		issueUpdatedAt: issue.UpdatedAt,
+		taskUpdatedAt:  created.UpdatedAt,
+	}
+	return nil
+}
+
+// merge merges the title and state of the issue and its task, updating whichever side is
+// behind. When both changed the same field since the last sync, the side changed last wins
+// and the conflict is counted on the link. The caller must hold syncMu.
+func (s *githubService) merge(ctx context.Context, token string, link *githubLink, number int, synced *syncedIssue,
+	issue *github.Issue, t *task.Task) error {
+	current := *t
+	title, titleConflict := integration.MergeGitHubField(synced.title, issueTitle(issue), current.Title, issue.UpdatedAt, current.UpdatedAt)
+	state, stateConflict := integration.MergeGitHubField(synced.state, issue.State, taskState(&current), issue.UpdatedAt, current.UpdatedAt)
+
+	// Bring the task up to date
+	taskUpdatedAt := current.UpdatedAt
+	if title != current.Title {
+		updated, err := s.taskService.UpdateTask(current.ID, &task.UpdateTaskRequest{Title: &title}, link.LinkedBy)
+		if err != nil {
+			return err
+		}
+		taskUpdatedAt = updated.UpdatedAt
+	}
+	if state != taskState(&current) {
+		var updated *task.Task
+		var err error
+		if state == github.StateClosed {
+			updated, err = s.taskService.CompleteTask(current.ID, link.LinkedBy)
+		} else {
+			updated, err = s.taskService.ReopenTask(current.ID, link.LinkedBy)
+		}
+		if err != nil {
+			return err
+		}
+		taskUpdatedAt = updated.UpdatedAt
+	}
+
+	// Bring the issue up to date
+	issueUpdatedAt := issue.UpdatedAt
+	update := github.IssueUpdate{}
+	if title != issueTitle(issue) {
+		update.Title = title
+	}
+	if state != issue.State {
+		update.State = state
+	}
+	if update != (github.IssueUpdate{}) {
+		updated, err := s.client.UpdateIssue(ctx, token, link.Owner(), link.Name(), number, &update)
+		if err != nil {
+			s.checkRevoked(link.LinkedBy, err)
+			return err
+		}
+		issueUpdatedAt = updated.UpdatedAt
+	}
+
+	synced.title, synced.state = title, state
+	synced.issueUpdatedAt, synced.taskUpdatedAt = issueUpdatedAt, taskUpdatedAt
+
+	if titleConflict || stateConflict {
+		log.Printf("Issue #%d of %s and task %s both changed; kept the latest change", number, link.Repository, current.ID)
+		s.mu.Lock()
+		link.Conflicts++
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// syncedTask returns the link and issue the task is synced with, if any. The caller must
+// hold syncMu.
+func (s *githubService) syncedTask(taskID uuid.UUID) (*githubLink, int, *syncedIssue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, link := range s.links {
+		for number, synced := range link.issues {
+			if synced.taskID == taskID {
+				return link, number, synced
+			}
+		}
+	}
+	return nil, 0, nil
+}
+
+// linkedTo returns the links to the repository
+func (s *githubService) linkedTo(repository string) []*githubLink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var links []*githubLink
+	for _, link := range s.links {
+		if strings.EqualFold(link.Repository, repository) {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// token returns the access token of the user's GitHub account
+func (s *githubService) token(userID uuid.UUID) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, exists := s.accounts[userID]
+	if !exists {
+		return "", errors.New("github is not connected")
+	}
+	return account.AccessToken, nil
+}
+
+// checkRevoked forgets the user's GitHub account when GitHub rejected its token, so the user
+// is asked to authorize again rather than the token being retried
+func (s *githubService) checkRevoked(userID uuid.UUID, err error) {
+	if !github.IsUnauthorized(err) {
+		return
+	}
+	log.Printf("Removing GitHub account of user %s: %v", userID, err)
+	s.mu.Lock()
+	delete(s.accounts, userID)
+	s.mu.Unlock()
+}
+
+// redirectURI returns the URL GitHub redirects users to after authorizing the app
+func (s *githubService) redirectURI() string {
+	return s.config.App.BaseURL + "/integrations/github/callback"
+}
+
+// newOAuthState generates the state of an OAuth authorization
+func newOAuthState() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate state")
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// taskState returns the issue state matching the task's status
+func taskState(t *task.Task) string {
+	if t.Status.IsClosed() {
+		return github.StateClosed
+	}
+	return github.StateOpen
+}
+
+// issueTitle returns the task title of the issue, truncated to the longest title allowed
+func issueTitle(issue *github.Issue) string {
+	return truncateRunes(strings.TrimSpace(issue.Title), maxTitleLength)
+}
+
+// issueDescription returns the task description of the issue: its body, truncated to the
+// longest description allowed, and a link to it
+func issueDescription(issue *github.Issue) string {
+	link := "GitHub issue #" + fmt.Sprint(issue.Number) + ": " + issue.HTMLURL
+	body := strings.TrimSpace(issue.Body)
+	if body == "" {
+		return link
+	}
+	return truncateRunes(body, task.MaxDescriptionLength-len(link)-2) + "\n\n" + link
+}
+
+// truncateRunes shortens the text to at most max bytes without splitting a character
+func truncateRunes(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max]
+}
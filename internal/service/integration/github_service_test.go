@@ -0,0 +1,256 @@
+package integration
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+	"todo-api/pkg/github"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+// fakeGitHub serves the issues of a single repository
+type fakeGitHub struct {
+	mu     sync.Mutex
+	issues map[int]*github.Issue
+}
+
+func (g *fakeGitHub) AuthorizeURL(redirectURI, state string) string {
+	return "https://github.com/login/oauth/authorize?" + url.Values{"redirect_uri": {redirectURI}, "state": {state}}.Encode()
+}
+
+func (g *fakeGitHub) ExchangeCode(ctx context.Context, code, redirectURI string) (string, error) {
+	if code != "good-code" {
+		return "", &github.APIError{StatusCode: 400, Message: "bad_verification_code"}
+	}
+	return "gho_token", nil
+}
+
+func (g *fakeGitHub) GetUser(ctx context.Context, token string) (*github.User, error) {
+	return &github.User{ID: 1, Login: "octocat"}, nil
+}
+
+func (g *fakeGitHub) GetRepository(ctx context.Context, token, owner, repo string) (*github.Repository, error) {
+	if owner+"/"+repo != "acme/app" {
+		return nil, &github.APIError{StatusCode: 404, Message: "Not Found"}
+	}
+	repository := &github.Repository{FullName: "acme/app"}
+	repository.Permissions.Push = true
+	return repository, nil
+}
+
+func (g *fakeGitHub) ListIssues(ctx context.Context, token, owner, repo string, since time.Time) ([]*github.Issue, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var issues []*github.Issue
+	for _, issue := range g.issues {
+		if !issue.UpdatedAt.Before(since) {
+			copied := *issue
+			issues = append(issues, &copied)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].UpdatedAt.Before(issues[j].UpdatedAt) })
+	return issues, nil
+}
+
+func (g *fakeGitHub) GetIssue(ctx context.Context, token, owner, repo string, number int) (*github.Issue, error) {
+	return g.issue(number), nil
+}
+
+func (g *fakeGitHub) UpdateIssue(ctx context.Context, token, owner, repo string, number int, update *github.IssueUpdate) (*github.Issue, error) {
+	g.mu.Lock()
+	issue := g.issues[number]
+	if update.Title != "" {
+		issue.Title = update.Title
+	}
+	if update.State != "" {
+		issue.State = update.State
+	}
+	issue.UpdatedAt = time.Now()
+	g.mu.Unlock()
+	return g.issue(number), nil
+}
+
+// edit changes the issue on GitHub
+func (g *fakeGitHub) edit(number int, title, state string) *github.Issue {
+	g.mu.Lock()
+	g.issues[number].Title = title
+	g.issues[number].State = state
+	g.issues[number].UpdatedAt = time.Now()
+	g.mu.Unlock()
+	return g.issue(number)
+}
+
+func (g *fakeGitHub) issue(number int) *github.Issue {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	copied := *g.issues[number]
+	return &copied
+}
+
+func setupGitHubService(t *testing.T) (GitHubService, taskService.Service, *fakeGitHub, uuid.UUID, uuid.UUID) {
+	cfg := &config.Config{
+		App:    config.AppConfig{BaseURL: "https://todo.example.com"},
+		GitHub: config.GitHubConfig{ClientID: "client-id", AuthorizationTTL: 10 * time.Minute},
+	}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := authService.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+	created, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	project, err := workspaces.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "App"}, johnID)
+	require.NoError(t, err)
+
+	taskSvc := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces})
+	earlier := time.Now().Add(-time.Hour)
+	client := &fakeGitHub{issues: map[int]*github.Issue{
+		1: {Number: 1, Title: "Crash on start", State: github.StateOpen, HTMLURL: "https://github.com/acme/app/issues/1", UpdatedAt: earlier},
+		2: {Number: 2, Title: "Old bug", State: github.StateClosed, UpdatedAt: earlier},
+		3: {Number: 3, Title: "Fix crash", State: github.StateOpen, PullRequest: &struct{}{}, UpdatedAt: earlier},
+	}}
+	return NewGitHubService(cfg, taskSvc, workspaces, client, bus), taskSvc, client, created.ID, project.ID
+}
+
+// connectGitHub authorizes the OAuth app as John
+func connectGitHub(t *testing.T, svc GitHubService) {
+	authorization, err := svc.AuthorizeGitHub(johnID)
+	require.NoError(t, err)
+	u, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://todo.example.com/integrations/github/callback", u.Query().Get("redirect_uri"))
+
+	account, err := svc.CompleteGitHubAuthorization(context.Background(), u.Query().Get("state"), "good-code")
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", account.Login)
+
+	// States are single-use
+	_, err = svc.CompleteGitHubAuthorization(context.Background(), u.Query().Get("state"), "good-code")
+	assert.EqualError(t, err, "invalid or expired state")
+}
+
+func TestGitHubService_LinkProject(t *testing.T) {
+	svc, _, _, workspaceID, projectID := setupGitHubService(t)
+	req := &integration.LinkGitHubRequest{Repository: "acme/app"}
+
+	_, err := svc.LinkProject(context.Background(), workspaceID, projectID, johnID, req)
+	assert.EqualError(t, err, "github is not connected")
+
+	connectGitHub(t, svc)
+
+	_, err = svc.LinkProject(context.Background(), workspaceID, uuid.New(), johnID, req)
+	assert.EqualError(t, err, "project not found")
+	_, err = svc.LinkProject(context.Background(), workspaceID, projectID, johnID, &integration.LinkGitHubRequest{Repository: "acme/missing"})
+	assert.EqualError(t, err, "repository not found")
+
+	link, err := svc.LinkProject(context.Background(), workspaceID, projectID, johnID, req)
+	require.NoError(t, err)
+	assert.Equal(t, "acme/app", link.Repository)
+
+	_, err = svc.GetProjectLink(uuid.New(), projectID)
+	assert.Error(t, err)
+	require.NoError(t, svc.UnlinkProject(workspaceID, projectID))
+	_, err = svc.GetProjectLink(workspaceID, projectID)
+	assert.Error(t, err)
+}
+
+func TestGitHubService_DeleteUserData(t *testing.T) {
+	svc, _, _, workspaceID, projectID := setupGitHubService(t)
+	connectGitHub(t, svc)
+	_, err := svc.AuthorizeGitHub(johnID)
+	require.NoError(t, err)
+
+	// The account is deleted with its token, so the user's projects can no longer be linked
+	svc.DeleteUserData(johnID)
+	_, err = svc.GetGitHubAccount(johnID)
+	assert.EqualError(t, err, "github is not connected")
+	_, err = svc.LinkProject(context.Background(), workspaceID, projectID, johnID, &integration.LinkGitHubRequest{Repository: "acme/app"})
+	assert.EqualError(t, err, "github is not connected")
+}
+
+func TestGitHubService_Sync(t *testing.T) {
+	svc, taskSvc, client, workspaceID, projectID := setupGitHubService(t)
+	connectGitHub(t, svc)
+	_, err := svc.LinkProject(context.Background(), workspaceID, projectID, johnID, &integration.LinkGitHubRequest{Repository: "acme/app"})
+	require.NoError(t, err)
+
+	// Open issues are imported; closed issues and pull requests are not
+	require.NoError(t, svc.Sync(context.Background()))
+	tasks, _, err := taskSvc.ListWorkspaceTasks(workspaceID, &task.TaskFilter{}, nil, 1, 10, johnID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	imported := tasks[0]
+	assert.Equal(t, "Crash on start", imported.Title)
+	assert.Equal(t, projectID, *imported.ProjectID)
+	assert.Contains(t, imported.Description, "https://github.com/acme/app/issues/1")
+
+	link, err := svc.GetProjectLink(workspaceID, projectID)
+	require.NoError(t, err)
+	assert.NotNil(t, link.LastSyncedAt)
+
+	// Syncing again does not import the issue twice
+	require.NoError(t, svc.Sync(context.Background()))
+	tasks, _, err = taskSvc.ListWorkspaceTasks(workspaceID, &task.TaskFilter{}, nil, 1, 10, johnID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	// Completing the task closes the issue
+	_, err = taskSvc.CompleteTask(imported.ID, johnID)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return client.issue(1).State == github.StateClosed
+	}, time.Second, 10*time.Millisecond)
+
+	// Issue changes delivered by the webhook are applied to the task
+	event := &github.IssuesEvent{Action: "reopened", Issue: client.edit(1, "Crash on start with no network", github.StateOpen)}
+	event.Repository.FullName = "ACME/app"
+	svc.HandleIssuesEvent(context.Background(), event)
+	reopened, err := taskSvc.GetTaskByID(imported.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, "Crash on start with no network", reopened.Title)
+	assert.False(t, reopened.Status.IsClosed())
+}
+
+func TestGitHubService_SyncConflict(t *testing.T) {
+	svc, taskSvc, client, workspaceID, projectID := setupGitHubService(t)
+	connectGitHub(t, svc)
+	_, err := svc.LinkProject(context.Background(), workspaceID, projectID, johnID, &integration.LinkGitHubRequest{Repository: "acme/app"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Sync(context.Background()))
+	tasks, _, err := taskSvc.ListWorkspaceTasks(workspaceID, &task.TaskFilter{}, nil, 1, 10, johnID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	// Both sides rename the issue; the task is renamed last, so its title wins
+	client.edit(1, "Renamed on GitHub", github.StateOpen)
+	title := "Renamed in the app"
+	_, err = taskSvc.UpdateTask(tasks[0].ID, &task.UpdateTaskRequest{Title: &title}, johnID)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		link, err := svc.GetProjectLink(workspaceID, projectID)
+		return err == nil && link.Conflicts == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "Renamed in the app", client.issue(1).Title)
+
+	synced, err := taskSvc.GetTaskByID(tasks[0].ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed in the app", synced.Title)
+}
@@ -0,0 +1,468 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/gcal"
+
+	"github.com/google/uuid"
+)
+
+// Private properties of the events of tasks. Every event gets the source, so the sync lists
+// the events of the app only, and the ID of its task.
+const (
+	eventPropertySource = "source"
+	eventSource         = "todo-api"
+	eventPropertyTaskID = "todoTaskId"
+)
+
+// tokenRefreshMargin is how long before they expire access tokens are refreshed
+const tokenRefreshMargin = time.Minute
+
+// GoogleCalendarService defines the Google Calendar integration service interface. Users
+// grant access to their calendar; their open tasks with due dates then get events, kept up
+// to date with the tasks, and rescheduling an event reschedules its task.
+type GoogleCalendarService interface {
+	AuthorizeGoogleCalendar(userID uuid.UUID) (*integration.GoogleCalendarAuthorization, error)
+	// CompleteGoogleCalendarAuthorization exchanges the code Google redirected the user back with
+	CompleteGoogleCalendarAuthorization(ctx context.Context, state, code string) (*integration.GoogleCalendarConnection, error)
+	GetGoogleCalendar(userID uuid.UUID) (*integration.GoogleCalendarConnection, error)
+	DisconnectGoogleCalendar(userID uuid.UUID) error
+	// DeleteUserData deletes the user's connection and its tokens when the account is erased,
+	// satisfying the eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	// Sync syncs the tasks of every connected user with their calendar
+	Sync(ctx context.Context) error
+}
+
+// calendarSync is a connected calendar with its sync state
+type calendarSync struct {
+	integration.GoogleCalendarConnection
+	cursor time.Time                  // last update of the events listed, where the next listing starts
+	events map[uuid.UUID]*syncedEvent // synced events by task ID
+}
+
+// syncedEvent is the event of a task, with the title and due date both had when last
+// synced. Changes made since are detected by comparing the update times. Events deleted by
+// the user have no ID; they are created again when the task is rescheduled.
+type syncedEvent struct {
+	eventID        string
+	title          string
+	dueDate        time.Time
+	eventUpdatedAt time.Time
+	taskUpdatedAt  time.Time
+}
+
+// googleCalendarService implements the Google Calendar integration service
+type googleCalendarService struct {
+	mu        sync.Mutex                  // guards the maps and connection fields
+	calendars map[uuid.UUID]*calendarSync // Mock connection storage by user ID
+	states    map[string]*oauthState      // Pending authorizations by state
+	// syncMu serializes syncing, so a slow sync is not overlapped by the next
+	syncMu      sync.Mutex
+	taskService taskService.Service
+	client      gcal.Client
+	plans       PlanDirectory
+	config      *config.Config
+}
+
+// NewGoogleCalendarService creates a new Google Calendar integration service
+func NewGoogleCalendarService(cfg *config.Config, taskSvc taskService.Service, client gcal.Client) GoogleCalendarService {
+	return NewGoogleCalendarServiceWithPlans(cfg, taskSvc, client, unlimitedPlans{})
+}
+
+// NewGoogleCalendarServiceWithPlans creates a new Google Calendar integration service
+// connecting calendars only when the user's plan allows it
+func NewGoogleCalendarServiceWithPlans(cfg *config.Config, taskSvc taskService.Service, client gcal.Client,
+	plans PlanDirectory) GoogleCalendarService {
+	return &googleCalendarService{
+		calendars:   make(map[uuid.UUID]*calendarSync),
+		states:      make(map[string]*oauthState),
+		taskService: taskSvc,
+		client:      client,
+		plans:       plans,
+		config:      cfg,
+	}
+}
+
+// AuthorizeGoogleCalendar starts granting access to the user's calendar, returning the URL
+// to send the user to
+func (s *googleCalendarService) AuthorizeGoogleCalendar(userID uuid.UUID) (*integration.GoogleCalendarAuthorization, error) {
+	if s.config.Calendar.ClientID == "" {
+		return nil, errors.New("google calendar is not configured")
+	}
+	if err := s.plans.AllowIntegration(userID, integration.NameGoogleCalendar); err != nil {
+		return nil, err
+	}
+
+	state, err := newOAuthState()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, pending := range s.states {
+		if pending.userID == userID || now.After(pending.expiresAt) {
+			delete(s.states, key)
+		}
+	}
+	expiresAt := now.Add(s.config.Calendar.AuthorizationTTL)
+	s.states[hashCode(state)] = &oauthState{userID: userID, expiresAt: expiresAt}
+
+	return &integration.GoogleCalendarAuthorization{
+		URL:       s.client.AuthorizeURL(s.redirectURI(), state),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CompleteGoogleCalendarAuthorization exchanges the code for the tokens of the user who
+// started the authorization with the state, connecting their primary calendar. States are
+// single-use. Reconnecting the same account keeps the events synced before.
+func (s *googleCalendarService) CompleteGoogleCalendarAuthorization(ctx context.Context, state, code string) (*integration.GoogleCalendarConnection, error) {
+	s.mu.Lock()
+	pending, exists := s.states[hashCode(state)]
+	delete(s.states, hashCode(state))
+	s.mu.Unlock()
+
+	if !exists || time.Now().After(pending.expiresAt) {
+		return nil, errors.New("invalid or expired state")
+	}
+	// Other integrations may have been connected since the authorization started
+	if err := s.plans.AllowIntegration(pending.userID, integration.NameGoogleCalendar); err != nil {
+		return nil, err
+	}
+
+	token, err := s.client.ExchangeCode(ctx, code, s.redirectURI())
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize google calendar: %w", err)
+	}
+	email, err := s.client.GetEmail(ctx, token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize google calendar: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, exists := s.calendars[pending.userID]
+	if !exists || cal.Email != email {
+		cal = &calendarSync{
+			GoogleCalendarConnection: integration.GoogleCalendarConnection{
+				UserID:     pending.userID,
+				Email:      email,
+				CalendarID: gcal.PrimaryCalendar,
+			},
+			events: make(map[uuid.UUID]*syncedEvent),
+		}
+		s.calendars[pending.userID] = cal
+	}
+	cal.AccessToken = token.AccessToken
+	cal.RefreshToken = token.RefreshToken
+	cal.TokenExpiry = token.Expiry
+	cal.ConnectedAt = time.Now()
+
+	connected := cal.GoogleCalendarConnection
+	return &connected, nil
+}
+
+// GetGoogleCalendar returns the calendar the user connected, with its sync status
+func (s *googleCalendarService) GetGoogleCalendar(userID uuid.UUID) (*integration.GoogleCalendarConnection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, exists := s.calendars[userID]
+	if !exists {
+		return nil, errors.New("google calendar is not connected")
+	}
+	connected := cal.GoogleCalendarConnection
+	return &connected, nil
+}
+
+// DisconnectGoogleCalendar stops syncing the user's calendar. Events created so far are
+// left in the calendar.
+func (s *googleCalendarService) DisconnectGoogleCalendar(userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.calendars[userID]; !exists {
+		return errors.New("google calendar is not connected")
+	}
+	delete(s.calendars, userID)
+	return nil
+}
+
+// DeleteUserData deletes the Google Calendar connection of the user, if any, with its tokens,
+// and the user's pending authorizations
+func (s *googleCalendarService) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.calendars, userID)
+	for hash, pending := range s.states {
+		if pending.userID == userID {
+			delete(s.states, hash)
+		}
+	}
+}
+
+// Sync syncs every connected calendar, reporting the users whose sync failed
+func (s *googleCalendarService) Sync(ctx context.Context) error {
+	s.mu.Lock()
+	calendars := make([]*calendarSync, 0, len(s.calendars))
+	for _, cal := range s.calendars {
+		calendars = append(calendars, cal)
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, cal := range calendars {
+		if err := s.syncCalendar(ctx, cal); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", cal.UserID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncCalendar applies the events rescheduled since the last sync to their tasks, then brings
+// the events up to date with the user's tasks. The outcome is recorded on the connection;
+// connections whose access was revoked are removed.
+func (s *googleCalendarService) syncCalendar(ctx context.Context, cal *calendarSync) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	startedAt := time.Now()
+	accessToken, err := s.accessToken(ctx, cal)
+	if err == nil {
+		err = s.pull(ctx, cal, accessToken)
+	}
+	if err == nil {
+		err = s.push(ctx, cal, accessToken)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gcal.IsUnauthorized(err) {
+		log.Printf("Removing Google Calendar connection of user %s: %v", cal.UserID, err)
+		if s.calendars[cal.UserID] == cal {
+			delete(s.calendars, cal.UserID)
+		}
+		return err
+	}
+	cal.SyncedTasks = 0
+	for _, synced := range cal.events {
+		if synced.eventID != "" {
+			cal.SyncedTasks++
+		}
+	}
+	if err != nil {
+		cal.LastSyncError = err.Error()
+		return err
+	}
+	cal.LastSyncedAt = &startedAt
+	cal.LastSyncError = ""
+	return nil
+}
+
+// accessToken returns an access token of the connection, refreshing it when it is about to
+// expire
+func (s *googleCalendarService) accessToken(ctx context.Context, cal *calendarSync) (string, error) {
+	s.mu.Lock()
+	accessToken, refreshToken, expiry := cal.AccessToken, cal.RefreshToken, cal.TokenExpiry
+	s.mu.Unlock()
+
+	if time.Now().Add(tokenRefreshMargin).Before(expiry) {
+		return accessToken, nil
+	}
+	token, err := s.client.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	cal.AccessToken = token.AccessToken
+	cal.RefreshToken = token.RefreshToken
+	cal.TokenExpiry = token.Expiry
+	s.mu.Unlock()
+	return token.AccessToken, nil
+}
+
+// pull applies the events changed since the last listing to their tasks. Events deleted by
+// the user are forgotten. The caller must hold syncMu.
+func (s *googleCalendarService) pull(ctx context.Context, cal *calendarSync, accessToken string) error {
+	events, err := s.client.ListEvents(ctx, accessToken, cal.CalendarID, eventPropertySource, eventSource, cal.cursor)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if err := s.applyEvent(ctx, cal, accessToken, event); err != nil {
+			return fmt.Errorf("event %s: %w", event.ID, err)
+		}
+		// Events are listed by update time, so the next listing resumes after this one
+		if event.UpdatedAt().After(cal.cursor) {
+			cal.cursor = event.UpdatedAt()
+		}
+	}
+	return nil
+}
+
+// applyEvent applies a changed event to its task. The caller must hold syncMu.
+func (s *googleCalendarService) applyEvent(ctx context.Context, cal *calendarSync, accessToken string, event *gcal.Event) error {
+	taskID, err := uuid.Parse(event.Private(eventPropertyTaskID))
+	if err != nil {
+		return nil
+	}
+	synced, exists := cal.events[taskID]
+	if !exists || synced.eventID != event.ID || !event.UpdatedAt().After(synced.eventUpdatedAt) {
+		return nil
+	}
+	if event.Status == gcal.StatusCancelled {
+		synced.eventID = ""
+		return nil
+	}
+
+	// Tasks closed, deleted, or without a due date are left to push, which deletes their event
+	t, err := s.taskService.GetTaskByID(taskID, cal.UserID)
+	if err != nil || t.DueDate == nil {
+		return nil
+	}
+	return s.merge(ctx, cal, accessToken, synced, event, t)
+}
+
+// merge merges the due date of the task with the start of its event, updating whichever
+// side is behind, and renames the event after the task. When both were rescheduled since
+// the last sync, the side changed last wins and the conflict is counted on the connection.
+// The caller must hold syncMu.
+func (s *googleCalendarService) merge(ctx context.Context, cal *calendarSync, accessToken string, synced *syncedEvent,
+	event *gcal.Event, t *task.Task) error {
+	if event.Start == nil {
+		return errors.New("event has no start")
+	}
+	eventDue, err := event.Start.Time()
+	if err != nil {
+		return fmt.Errorf("invalid event start: %w", err)
+	}
+	current := *t
+	dueDate, conflict := integration.MergeDueDate(synced.dueDate, eventDue, *current.DueDate, event.UpdatedAt(), current.UpdatedAt)
+
+	// Bring the task up to date
+	taskUpdatedAt := current.UpdatedAt
+	if !dueDate.Equal(*current.DueDate) {
+		updated, err := s.taskService.UpdateTask(current.ID, &task.UpdateTaskRequest{DueDate: &dueDate}, cal.UserID)
+		if err != nil {
+			return err
+		}
+		taskUpdatedAt = updated.UpdatedAt
+	}
+
+	// Bring the event up to date
+	eventUpdatedAt := event.UpdatedAt()
+	if !dueDate.Equal(eventDue) || event.Summary != current.Title {
+		patched, err := s.client.PatchEvent(ctx, accessToken, cal.CalendarID, event.ID, s.event(current.ID, current.Title, dueDate))
+		if err != nil {
+			return err
+		}
+		eventUpdatedAt = patched.UpdatedAt()
+	}
+
+	synced.title, synced.dueDate = current.Title, dueDate
+	synced.eventUpdatedAt, synced.taskUpdatedAt = eventUpdatedAt, taskUpdatedAt
+
+	if conflict {
+		log.Printf("Task %s and its event were both rescheduled; kept the latest change", current.ID)
+		s.mu.Lock()
+		cal.Conflicts++
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// push creates the events of the user's open tasks with due dates, updates those of tasks
+// renamed or rescheduled, and deletes those of other tasks. The caller must hold syncMu.
+func (s *googleCalendarService) push(ctx context.Context, cal *calendarSync, accessToken string) error {
+	due := make(map[uuid.UUID]*task.Task)
+	for _, t := range s.taskService.OpenTasks(cal.UserID) {
+		if t.DueDate != nil {
+			due[t.ID] = t
+		}
+	}
+
+	for id, t := range due {
+		synced, exists := cal.events[id]
+		switch {
+		case !exists, synced.eventID == "" && !t.DueDate.Equal(synced.dueDate):
+			created, err := s.client.InsertEvent(ctx, accessToken, cal.CalendarID, s.event(t.ID, t.Title, *t.DueDate))
+			if err != nil {
+				return fmt.Errorf("task %s: %w", id, err)
+			}
+			cal.events[id] = &syncedEvent{
+				eventID:        created.ID,
+				title:          t.Title,
+				dueDate:        *t.DueDate,
+				eventUpdatedAt: created.UpdatedAt(),
+				taskUpdatedAt:  t.UpdatedAt,
+			}
+		case synced.eventID != "" && (t.Title != synced.title || !t.DueDate.Equal(synced.dueDate)):
+			patched, err := s.client.PatchEvent(ctx, accessToken, cal.CalendarID, synced.eventID, s.event(t.ID, t.Title, *t.DueDate))
+			if gcal.IsNotFound(err) {
+				synced.eventID = ""
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("task %s: %w", id, err)
+			}
+			synced.title, synced.dueDate = t.Title, *t.DueDate
+			synced.eventUpdatedAt, synced.taskUpdatedAt = patched.UpdatedAt(), t.UpdatedAt
+		}
+	}
+
+	for id, synced := range cal.events {
+		if _, open := due[id]; open {
+			continue
+		}
+		if synced.eventID != "" {
+			err := s.client.DeleteEvent(ctx, accessToken, cal.CalendarID, synced.eventID)
+			if err != nil && !gcal.IsNotFound(err) {
+				return fmt.Errorf("task %s: %w", id, err)
+			}
+		}
+		delete(cal.events, id)
+	}
+	return nil
+}
+
+// event returns the event of a task with the title due at the due date: an all-day event
+// for tasks due on a date, a short event starting at the due date otherwise
+func (s *googleCalendarService) event(taskID uuid.UUID, title string, dueDate time.Time) *gcal.Event {
+	start, end := gcal.At(dueDate), gcal.At(dueDate.Add(integration.GoogleCalendarEventDuration))
+	if integration.IsAllDay(dueDate) {
+		start, end = gcal.AllDay(dueDate), gcal.AllDay(dueDate.AddDate(0, 0, 1))
+	}
+	return &gcal.Event{
+		Summary:     title,
+		Description: s.config.App.BaseURL + "/tasks/" + taskID.String(),
+		Start:       &start,
+		End:         &end,
+		ExtendedProperties: &gcal.ExtendedProperties{Private: map[string]string{
+			eventPropertySource: eventSource,
+			eventPropertyTaskID: taskID.String(),
+		}},
+	}
+}
+
+// redirectURI returns the URL Google redirects users to after granting access
+func (s *googleCalendarService) redirectURI() string {
+	return s.config.App.BaseURL + "/integrations/google-calendar/callback"
+}
@@ -0,0 +1,300 @@
+package integration
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/gcal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCalendar serves the primary calendar of a single user
+type fakeCalendar struct {
+	mu          sync.Mutex
+	events      map[string]*gcal.Event
+	nextID      int
+	accessToken string // the valid access token
+	expiry      time.Time
+	revoked     bool
+}
+
+func (c *fakeCalendar) AuthorizeURL(redirectURI, state string) string {
+	return "https://accounts.google.com/o/oauth2/v2/auth?" + url.Values{"redirect_uri": {redirectURI}, "state": {state}}.Encode()
+}
+
+func (c *fakeCalendar) ExchangeCode(ctx context.Context, code, redirectURI string) (*gcal.Token, error) {
+	if code != "good-code" {
+		return nil, gcal.ErrInvalidGrant
+	}
+	return c.token("ya29.first"), nil
+}
+
+func (c *fakeCalendar) RefreshToken(ctx context.Context, refreshToken string) (*gcal.Token, error) {
+	c.mu.Lock()
+	revoked := c.revoked
+	c.mu.Unlock()
+	if revoked {
+		return nil, gcal.ErrInvalidGrant
+	}
+	return c.token("ya29.refreshed"), nil
+}
+
+func (c *fakeCalendar) token(accessToken string) *gcal.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = accessToken
+	return &gcal.Token{AccessToken: accessToken, RefreshToken: "1//refresh", Expiry: c.expiry}
+}
+
+func (c *fakeCalendar) GetEmail(ctx context.Context, accessToken string) (string, error) {
+	return "john.doe@example.com", nil
+}
+
+func (c *fakeCalendar) ListEvents(ctx context.Context, accessToken, calendarID, property, value string, since time.Time) ([]*gcal.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if accessToken != c.accessToken {
+		return nil, &gcal.APIError{StatusCode: 401, Message: "Invalid Credentials"}
+	}
+	var events []*gcal.Event
+	for _, event := range c.events {
+		if event.Private(property) == value && !event.UpdatedAt().Before(since) {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].UpdatedAt().Before(events[j].UpdatedAt()) })
+	return events, nil
+}
+
+func (c *fakeCalendar) InsertEvent(ctx context.Context, accessToken, calendarID string, event *gcal.Event) (*gcal.Event, error) {
+	c.mu.Lock()
+	c.nextID++
+	created := *event
+	created.ID = "event" + strconv.Itoa(c.nextID)
+	created.Status = gcal.StatusConfirmed
+	c.events[created.ID] = &created
+	c.mu.Unlock()
+	return c.edit(created.ID, func(*gcal.Event) {}), nil
+}
+
+func (c *fakeCalendar) PatchEvent(ctx context.Context, accessToken, calendarID, eventID string, event *gcal.Event) (*gcal.Event, error) {
+	return c.edit(eventID, func(existing *gcal.Event) {
+		existing.Summary = event.Summary
+		existing.Start, existing.End = event.Start, event.End
+	}), nil
+}
+
+func (c *fakeCalendar) DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error {
+	c.edit(eventID, func(existing *gcal.Event) {
+		existing.Status = gcal.StatusCancelled
+	})
+	return nil
+}
+
+// edit changes the event in the calendar
+func (c *fakeCalendar) edit(eventID string, change func(*gcal.Event)) *gcal.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	event := c.events[eventID]
+	change(event)
+	updated := time.Now()
+	event.Updated = &updated
+	copied := *event
+	return &copied
+}
+
+// list returns the events of the calendar that were not deleted
+func (c *fakeCalendar) list() []*gcal.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var events []*gcal.Event
+	for _, event := range c.events {
+		if event.Status != gcal.StatusCancelled {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events
+}
+
+func setupGoogleCalendarService(t *testing.T) (GoogleCalendarService, taskService.Service, *fakeCalendar) {
+	cfg := &config.Config{
+		App:      config.AppConfig{BaseURL: "https://todo.example.com"},
+		Calendar: config.GoogleCalendarConfig{ClientID: "client-id", AuthorizationTTL: 10 * time.Minute},
+	}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	client := &fakeCalendar{events: make(map[string]*gcal.Event), expiry: time.Now().Add(time.Hour)}
+	svc := NewGoogleCalendarService(cfg, taskSvc, client)
+	connectGoogleCalendar(t, svc)
+	return svc, taskSvc, client
+}
+
+// connectGoogleCalendar grants access to John's calendar
+func connectGoogleCalendar(t *testing.T, svc GoogleCalendarService) {
+	authorization, err := svc.AuthorizeGoogleCalendar(johnID)
+	require.NoError(t, err)
+	u, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://todo.example.com/integrations/google-calendar/callback", u.Query().Get("redirect_uri"))
+
+	connection, err := svc.CompleteGoogleCalendarAuthorization(context.Background(), u.Query().Get("state"), "good-code")
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", connection.Email)
+
+	// States are single-use
+	_, err = svc.CompleteGoogleCalendarAuthorization(context.Background(), u.Query().Get("state"), "good-code")
+	assert.EqualError(t, err, "invalid or expired state")
+}
+
+func TestGoogleCalendarService_Sync(t *testing.T) {
+	svc, taskSvc, client := setupGoogleCalendarService(t)
+
+	dueDate := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report", DueDate: &dueDate}, johnID)
+	require.NoError(t, err)
+	_, err = taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Someday"}, johnID)
+	require.NoError(t, err)
+
+	// Tasks with due dates get events
+	require.NoError(t, svc.Sync(context.Background()))
+	events := client.list()
+	require.Len(t, events, 1)
+	assert.Equal(t, "Write report", events[0].Summary)
+	assert.Equal(t, "2026-03-10T09:00:00Z", events[0].Start.DateTime)
+	assert.Equal(t, "https://todo.example.com/tasks/"+created.ID.String(), events[0].Description)
+
+	connection, err := svc.GetGoogleCalendar(johnID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, connection.SyncedTasks)
+	assert.NotNil(t, connection.LastSyncedAt)
+
+	// Rescheduling the event reschedules the task
+	client.edit(events[0].ID, func(event *gcal.Event) {
+		start, end := gcal.At(dueDate.Add(24*time.Hour)), gcal.At(dueDate.Add(24*time.Hour+time.Hour))
+		event.Start, event.End = &start, &end
+	})
+	require.NoError(t, svc.Sync(context.Background()))
+	rescheduled, err := taskSvc.GetTaskByID(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, dueDate.Add(24*time.Hour), rescheduled.DueDate.UTC())
+
+	// Renaming the task or moving it to a date renames and moves the event
+	title := "Write quarterly report"
+	onDate := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+	_, err = taskSvc.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: &title, DueDate: &onDate}, johnID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Sync(context.Background()))
+	events = client.list()
+	require.Len(t, events, 1)
+	assert.Equal(t, "Write quarterly report", events[0].Summary)
+	assert.Equal(t, "2026-03-12", events[0].Start.Date)
+	assert.Equal(t, "2026-03-13", events[0].End.Date)
+
+	// Completing the task deletes its event
+	_, err = taskSvc.CompleteTask(created.ID, johnID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Sync(context.Background()))
+	assert.Empty(t, client.list())
+}
+
+func TestGoogleCalendarService_SyncConflict(t *testing.T) {
+	svc, taskSvc, client := setupGoogleCalendarService(t)
+
+	dueDate := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report", DueDate: &dueDate}, johnID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Sync(context.Background()))
+	events := client.list()
+	require.Len(t, events, 1)
+
+	// Both are rescheduled; the task is rescheduled last, so its due date wins
+	client.edit(events[0].ID, func(event *gcal.Event) {
+		start := gcal.At(dueDate.Add(time.Hour))
+		event.Start = &start
+	})
+	taskDue := dueDate.Add(2 * time.Hour)
+	_, err = taskSvc.UpdateTask(created.ID, &task.UpdateTaskRequest{DueDate: &taskDue}, johnID)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Sync(context.Background()))
+	events = client.list()
+	require.Len(t, events, 1)
+	assert.Equal(t, "2026-03-10T11:00:00Z", events[0].Start.DateTime)
+
+	connection, err := svc.GetGoogleCalendar(johnID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, connection.Conflicts)
+
+	synced, err := taskSvc.GetTaskByID(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, taskDue, synced.DueDate.UTC())
+}
+
+func TestGoogleCalendarService_DeletedEvent(t *testing.T) {
+	svc, taskSvc, client := setupGoogleCalendarService(t)
+
+	dueDate := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Write report", DueDate: &dueDate}, johnID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Sync(context.Background()))
+	events := client.list()
+	require.Len(t, events, 1)
+
+	// Events deleted by the user stay deleted until the task is rescheduled
+	require.NoError(t, client.DeleteEvent(context.Background(), "", gcal.PrimaryCalendar, events[0].ID))
+	require.NoError(t, svc.Sync(context.Background()))
+	assert.Empty(t, client.list())
+
+	rescheduled := dueDate.Add(24 * time.Hour)
+	_, err = taskSvc.UpdateTask(created.ID, &task.UpdateTaskRequest{DueDate: &rescheduled}, johnID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Sync(context.Background()))
+	assert.Len(t, client.list(), 1)
+}
+
+func TestGoogleCalendarService_DeleteUserData(t *testing.T) {
+	svc, _, _ := setupGoogleCalendarService(t)
+	connectGoogleCalendar(t, svc)
+
+	svc.DeleteUserData(johnID)
+	_, err := svc.GetGoogleCalendar(johnID)
+	assert.EqualError(t, err, "google calendar is not connected")
+	require.NoError(t, svc.Sync(context.Background()))
+}
+
+func TestGoogleCalendarService_Tokens(t *testing.T) {
+	svc, _, client := setupGoogleCalendarService(t)
+
+	// Expired access tokens are refreshed
+	client.mu.Lock()
+	client.expiry = time.Now().Add(-time.Minute)
+	client.mu.Unlock()
+	connectGoogleCalendar(t, svc)
+	require.NoError(t, svc.Sync(context.Background()))
+	assert.Equal(t, "ya29.refreshed", client.accessToken)
+
+	// Connections whose access was revoked are removed
+	client.mu.Lock()
+	client.revoked = true
+	client.mu.Unlock()
+	assert.True(t, gcal.IsUnauthorized(svc.Sync(context.Background())))
+	_, err := svc.GetGoogleCalendar(johnID)
+	assert.EqualError(t, err, "google calendar is not connected")
+}
@@ -0,0 +1,19 @@
+package integration
+
+import (
+	"github.com/google/uuid"
+)
+
+// PlanDirectory decides whether the plans of users allow connecting integrations. It is
+// consulted without holding the locks of the integration services, since it may look up
+// which integrations the user already connected.
+type PlanDirectory interface {
+	// AllowIntegration returns an error when the user's plan does not allow connecting the
+	// named integration besides those already connected
+	AllowIntegration(userID uuid.UUID, name string) error
+}
+
+// unlimitedPlans is a directory allowing every user to connect every integration
+type unlimitedPlans struct{}
+
+func (unlimitedPlans) AllowIntegration(uuid.UUID, string) error { return nil }
@@ -0,0 +1,329 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/slack"
+
+	"github.com/google/uuid"
+)
+
+// dateFormat is how due dates are shown in Slack messages
+const dateFormat = "Mon, Jan 2 2006 15:04 MST"
+
+// slashCommandUsage lists the slash commands, shown for help and unknown commands
+const slashCommandUsage = "Usage: `/todo add TITLE` adds a task"
+
+// SlackService defines the Slack integration service interface. It notifies connected users
+// of task events in Slack and handles slash commands sent by their Slack accounts.
+type SlackService interface {
+	GetSlackConnection(userID uuid.UUID) (*integration.SlackConnection, error)
+	ConnectSlack(userID uuid.UUID, req *integration.ConnectSlackRequest) (*integration.SlackConnection, error)
+	DisconnectSlack(userID uuid.UUID) error
+	// DeleteUserData deletes the user's connection when the account is erased, satisfying
+	// the eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	HandleSlashCommand(cmd *integration.SlashCommand) string
+	// NotificationChannel and Remind post reminders about tasks coming due, satisfying the
+	// notification channel interface
+	NotificationChannel() string
+	Remind(ctx context.Context, user *auth.User, t *task.Task, now time.Time) (bool, error)
+}
+
+// slackService implements the Slack integration service
+type slackService struct {
+	mu          sync.RWMutex
+	connections map[uuid.UUID]*integration.SlackConnection // Mock connection storage
+	authService authService.Service
+	taskService taskService.Service
+	client      slack.Client
+	plans       PlanDirectory
+	allows      notification.Filter // notification settings of users
+	config      *config.Config
+}
+
+// SlackDeps are the dependencies of a Slack integration service. Plans and Allows are
+// optional, and a zero value keeps the default noted next to each.
+type SlackDeps struct {
+	Config *config.Config
+	Auth   authService.Service
+	Tasks  taskService.Service
+	Client slack.Client
+	Bus    events.Bus    // task events published on it are posted to connected users
+	Plans  PlanDirectory // every plan allows Slack
+	// Allows reports whether users turned Slack notifications of an event off in their
+	// notification settings; every event is posted when it is nil
+	Allows notification.Filter
+}
+
+// NewSlackService creates a new Slack integration service posting task events published
+// on the bus to connected users
+func NewSlackService(cfg *config.Config, authSvc authService.Service, taskSvc taskService.Service, client slack.Client,
+	bus events.Bus) SlackService {
+	return NewSlackServiceWithDeps(SlackDeps{Config: cfg, Auth: authSvc, Tasks: taskSvc, Client: client, Bus: bus})
+}
+
+// NewSlackServiceWithDeps creates a new Slack integration service, using the defaults of
+// SlackDeps for the dependencies left unset
+func NewSlackServiceWithDeps(deps SlackDeps) SlackService {
+	if deps.Plans == nil {
+		deps.Plans = unlimitedPlans{}
+	}
+	if deps.Allows == nil {
+		deps.Allows = notification.AllowAll
+	}
+
+	s := &slackService{
+		connections: make(map[uuid.UUID]*integration.SlackConnection),
+		authService: deps.Auth,
+		taskService: deps.Tasks,
+		client:      deps.Client,
+		plans:       deps.Plans,
+		allows:      deps.Allows,
+		config:      deps.Config,
+	}
+
+	deps.Bus.Subscribe(func(event events.Event) {
+		if taskEvent, ok := event.(*task.Event); ok {
+			s.notify(context.Background(), taskEvent)
+		}
+	})
+
+	return s
+}
+
+// GetSlackConnection returns the user's Slack connection
+func (s *slackService) GetSlackConnection(userID uuid.UUID) (*integration.SlackConnection, error) {
+	conn := s.connection(userID)
+	if conn == nil {
+		return nil, errors.New("slack is not connected")
+	}
+	return conn, nil
+}
+
+// ConnectSlack connects the user's Slack, replacing any previous connection
+func (s *slackService) ConnectSlack(userID uuid.UUID, req *integration.ConnectSlackRequest) (*integration.SlackConnection, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.plans.AllowIntegration(userID, integration.NameSlack); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Each Slack account acts on the tasks of a single user
+	if req.SlackUserID != "" {
+		for id, conn := range s.connections {
+			if id != userID && conn.AcceptsCommandsFrom(req.TeamID, req.SlackUserID) {
+				return nil, errors.New("slack account is connected to another user")
+			}
+		}
+	}
+
+	conn := integration.NewSlackConnection(userID, req)
+	if previous, exists := s.connections[userID]; exists {
+		conn.CreatedAt = previous.CreatedAt
+	}
+	s.connections[userID] = conn
+
+	connected := *conn
+	return &connected, nil
+}
+
+// DisconnectSlack removes the user's Slack connection
+func (s *slackService) DisconnectSlack(userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.connections[userID]; !exists {
+		return errors.New("slack is not connected")
+	}
+	delete(s.connections, userID)
+	return nil
+}
+
+// DeleteUserData deletes the Slack connection of the user, if any
+func (s *slackService) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.connections, userID)
+}
+
+// HandleSlashCommand runs a slash command sent by a connected Slack account and returns the
+// response shown to the Slack user
+func (s *slackService) HandleSlashCommand(cmd *integration.SlashCommand) string {
+	userID, ok := s.commandUser(cmd.TeamID, cmd.UserID)
+	if !ok {
+		return "Your Slack account is not connected to Todo API. Connect Slack with your team and user IDs first."
+	}
+
+	name, args, _ := strings.Cut(strings.TrimSpace(cmd.Text), " ")
+	switch strings.ToLower(name) {
+	case "add":
+		created, err := s.taskService.CreateTask(&task.CreateTaskRequest{Title: strings.TrimSpace(args)}, userID)
+		if err != nil {
+			return "Could not add the task: " + slack.Escape(err.Error())
+		}
+		return "Added " + slack.Link(s.taskURL(created), created.Title)
+	case "", "help":
+		return slashCommandUsage
+	default:
+		return fmt.Sprintf("Unknown command %q. %s", slack.Escape(name), slashCommandUsage)
+	}
+}
+
+// commandUser returns the user whose tasks slash commands of the Slack account act on.
+// Connections of erased accounts are removed.
+func (s *slackService) commandUser(teamID, slackUserID string) (uuid.UUID, bool) {
+	s.mu.RLock()
+	var userID uuid.UUID
+	found := false
+	for id, conn := range s.connections {
+		if conn.AcceptsCommandsFrom(teamID, slackUserID) {
+			userID, found = id, true
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if !found {
+		return uuid.Nil, false
+	}
+	if _, err := s.authService.GetUserByID(userID); err != nil {
+		s.remove(userID)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// NotificationChannel returns the channel of Slack messages in notification settings
+func (s *slackService) NotificationChannel() string {
+	return notification.ChannelSlack
+}
+
+// Remind posts a reminder about the task coming due to the user's Slack, unless the user
+// did not connect Slack or opted out of due soon messages
+func (s *slackService) Remind(ctx context.Context, user *auth.User, t *task.Task, now time.Time) (bool, error) {
+	conn := s.connection(user.ID)
+	if conn == nil || !conn.Notifies(integration.SlackEventDueSoon) {
+		return false, nil
+	}
+
+	text := ":alarm_clock: " + slack.Link(s.taskURL(t), t.Title)
+	if t.DueDate.Before(now) {
+		text += " was due " + t.DueDate.UTC().Format(dateFormat)
+	} else {
+		text += " is due " + t.DueDate.UTC().Format(dateFormat)
+	}
+
+	if err := s.post(ctx, conn, text); err != nil {
+		return false, fmt.Errorf("slack: %w", err)
+	}
+	return true, nil
+}
+
+// notify posts messages about the task event to the connected users it concerns: the new
+// assignee of a task, and the owner and assignee of a completed task. Users are not notified
+// of their own changes.
+func (s *slackService) notify(ctx context.Context, e *task.Event) {
+	if e.Type != task.EventTaskUpdated || e.Task == nil {
+		return
+	}
+	link := slack.Link(s.taskURL(e.Task), e.Task.Title)
+
+	if change, ok := e.Change("assignee_id"); ok && change.NewValue != "" {
+		if assigneeID, err := uuid.Parse(change.NewValue); err == nil && assigneeID != e.ActorID {
+			s.notifyUser(ctx, assigneeID, integration.SlackEventAssigned, ":inbox_tray: You were assigned "+link)
+		}
+	}
+
+	if change, ok := e.Change("status"); ok && change.NewValue == string(task.StatusCompleted) {
+		recipients := []uuid.UUID{e.Task.UserID}
+		if e.Task.AssigneeID != nil && *e.Task.AssigneeID != e.Task.UserID {
+			recipients = append(recipients, *e.Task.AssigneeID)
+		}
+		for _, userID := range recipients {
+			if userID != e.ActorID {
+				s.notifyUser(ctx, userID, integration.SlackEventCompleted, ":white_check_mark: "+link+" was completed")
+			}
+		}
+	}
+}
+
+// notifyUser posts the message to the user's Slack if they connected it and are notified of
+// the event, by the connection and their notification settings. Failures are logged; event
+// notifications are not retried.
+func (s *slackService) notifyUser(ctx context.Context, userID uuid.UUID, event, text string) {
+	if !s.allows(userID, event, notification.ChannelSlack) {
+		return
+	}
+	conn := s.connection(userID)
+	if conn == nil || !conn.Notifies(event) {
+		return
+	}
+	if err := s.post(ctx, conn, text); err != nil {
+		log.Printf("Failed to post %s message to Slack for user %s: %v", event, userID, err)
+	}
+}
+
+// post posts the text to the connection. Connections whose credentials were revoked are
+// removed, so they are not retried.
+func (s *slackService) post(ctx context.Context, conn *integration.SlackConnection, text string) error {
+	msg := &slack.Message{Text: text}
+
+	var err error
+	if conn.Mode == integration.SlackModeBot {
+		err = s.client.PostMessage(ctx, conn.BotToken, conn.Channel, msg)
+	} else {
+		err = s.client.PostWebhook(ctx, conn.WebhookURL, msg)
+	}
+
+	if slack.IsRevoked(err) {
+		log.Printf("Removing Slack connection of user %s: %v", conn.UserID, err)
+		s.remove(conn.UserID)
+	}
+	return err
+}
+
+// connection returns a copy of the user's Slack connection, or nil
+func (s *slackService) connection(userID uuid.UUID) *integration.SlackConnection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conn, exists := s.connections[userID]
+	if !exists {
+		return nil
+	}
+	copied := *conn
+	return &copied
+}
+
+// remove removes the user's Slack connection, if any
+func (s *slackService) remove(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.connections, userID)
+}
+
+// taskURL returns the URL of the task in the application
+func (s *slackService) taskURL(t *task.Task) string {
+	return s.config.App.BaseURL + "/tasks/" + t.ID.String()
+}
@@ -0,0 +1,189 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/slack"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient records the messages posted to Slack
+type fakeClient struct {
+	mu       sync.Mutex
+	posted   []string // destination and text of each message
+	failWith error
+}
+
+func (c *fakeClient) PostWebhook(ctx context.Context, webhookURL string, msg *slack.Message) error {
+	return c.record(webhookURL, msg)
+}
+
+func (c *fakeClient) PostMessage(ctx context.Context, token, channel string, msg *slack.Message) error {
+	return c.record(channel, msg)
+}
+
+func (c *fakeClient) record(destination string, msg *slack.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failWith != nil {
+		return c.failWith
+	}
+	c.posted = append(c.posted, destination+" "+msg.Text)
+	return nil
+}
+
+func (c *fakeClient) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.posted...)
+}
+
+const webhookURL = "https://hooks.slack.com/services/T000/B000/XXXX"
+
+func setupTestService(t *testing.T) (SlackService, authService.Service, taskService.Service, *fakeClient) {
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "https://todo.example.com"}}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewServiceWithEventBus(authSvc, bus)
+	client := &fakeClient{}
+	return NewSlackService(cfg, authSvc, taskSvc, client, bus), authSvc, taskSvc, client
+}
+
+func TestSlackService_Connect(t *testing.T) {
+	service, authSvc, _, _ := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	jane, _ := authSvc.GetUserByEmail("jane.smith@example.com")
+
+	_, err := service.GetSlackConnection(john.ID)
+	assert.Error(t, err)
+
+	_, err = service.ConnectSlack(john.ID, &integration.ConnectSlackRequest{WebhookURL: "https://example.com/hook"})
+	assert.EqualError(t, err, "webhook_url must be a Slack incoming webhook URL")
+
+	conn, err := service.ConnectSlack(john.ID, &integration.ConnectSlackRequest{
+		BotToken: "xoxb-token", Channel: "C123", TeamID: "T1", SlackUserID: "U1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, integration.SlackModeBot, conn.Mode)
+	assert.Equal(t, integration.SlackEvents, conn.Events)
+
+	// A Slack account acts on the tasks of a single user
+	_, err = service.ConnectSlack(jane.ID, &integration.ConnectSlackRequest{WebhookURL: webhookURL, TeamID: "T1", SlackUserID: "U1"})
+	assert.EqualError(t, err, "slack account is connected to another user")
+
+	require.NoError(t, service.DisconnectSlack(john.ID))
+	assert.Error(t, service.DisconnectSlack(john.ID))
+}
+
+// deniedPlans is a plan directory refusing every integration
+type deniedPlans struct{}
+
+func (deniedPlans) AllowIntegration(uuid.UUID, string) error {
+	return errors.New("upgrade required")
+}
+
+func TestSlackService_Connect_PlanLimit(t *testing.T) {
+	cfg := &config.Config{}
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	authSvc := authService.NewService(cfg)
+	service := NewSlackServiceWithDeps(SlackDeps{Config: cfg, Auth: authSvc, Tasks: taskService.NewServiceWithEventBus(authSvc, bus),
+		Client: &fakeClient{}, Bus: bus, Plans: deniedPlans{}})
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+
+	_, err := service.ConnectSlack(john.ID, &integration.ConnectSlackRequest{WebhookURL: webhookURL})
+	assert.EqualError(t, err, "upgrade required")
+	_, err = service.GetSlackConnection(john.ID)
+	assert.Error(t, err)
+}
+
+func TestSlackService_NotifiesAssignedAndCompleted(t *testing.T) {
+	service, authSvc, taskSvc, client := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	jane, _ := authSvc.GetUserByEmail("jane.smith@example.com")
+
+	_, err := service.ConnectSlack(john.ID, &integration.ConnectSlackRequest{WebhookURL: webhookURL})
+	require.NoError(t, err)
+	_, err = service.ConnectSlack(jane.ID, &integration.ConnectSlackRequest{BotToken: "xoxb-token", Channel: "C123"})
+	require.NoError(t, err)
+
+	created, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Ship <release>"}, john.ID)
+	require.NoError(t, err)
+	_, err = taskSvc.AssignTask(created.ID, &task.AssignTaskRequest{UserID: &jane.ID}, john.ID)
+	require.NoError(t, err)
+	_, err = taskSvc.CompleteTask(created.ID, jane.ID)
+	require.NoError(t, err)
+
+	link := "<https://todo.example.com/tasks/" + created.ID.String() + "|Ship &lt;release&gt;>"
+	// Jane is told about the assignment, John about the completion; neither about their own change
+	assert.Eventually(t, func() bool { return len(client.messages()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{
+		"C123 :inbox_tray: You were assigned " + link,
+		webhookURL + " :white_check_mark: " + link + " was completed",
+	}, client.messages())
+}
+
+func TestSlackService_Remind(t *testing.T) {
+	service, authSvc, taskSvc, client := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	dueSoon, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Pay rent", DueDate: &soon}, john.ID)
+	require.NoError(t, err)
+
+	// Users who did not connect Slack are not reminded
+	delivered, err := service.Remind(context.Background(), john, dueSoon, now)
+	require.NoError(t, err)
+	assert.False(t, delivered)
+
+	_, err = service.ConnectSlack(john.ID, &integration.ConnectSlackRequest{WebhookURL: webhookURL})
+	require.NoError(t, err)
+	delivered, err = service.Remind(context.Background(), john, dueSoon, now)
+	require.NoError(t, err)
+	assert.True(t, delivered)
+	require.Len(t, client.messages(), 1)
+	assert.Contains(t, client.messages()[0], "Pay rent> is due ")
+
+	// Revoked webhooks are disconnected
+	client.failWith = &slack.APIError{Code: "no_service"}
+	_, err = service.Remind(context.Background(), john, dueSoon, now)
+	assert.Error(t, err)
+	_, err = service.GetSlackConnection(john.ID)
+	assert.Error(t, err)
+}
+
+func TestSlackService_HandleSlashCommand(t *testing.T) {
+	service, authSvc, taskSvc, _ := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+
+	cmd := &integration.SlashCommand{TeamID: "T1", UserID: "U1", Command: "/todo", Text: "add Buy milk"}
+	assert.Contains(t, service.HandleSlashCommand(cmd), "not connected")
+
+	_, err := service.ConnectSlack(john.ID, &integration.ConnectSlackRequest{WebhookURL: webhookURL, TeamID: "T1", SlackUserID: "U1"})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(service.HandleSlashCommand(cmd), "Added <https://todo.example.com/tasks/"))
+	tasks, _, err := taskSvc.ListTasks(&task.TaskFilter{Search: "Buy milk"}, task.NewTaskSort("", ""), 1, 10, john.ID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	assert.Contains(t, service.HandleSlashCommand(&integration.SlashCommand{TeamID: "T1", UserID: "U1", Text: "add"}), "Could not add the task")
+	assert.Contains(t, service.HandleSlashCommand(&integration.SlashCommand{TeamID: "T1", UserID: "U1", Text: "help"}), "Usage")
+	assert.Contains(t, service.HandleSlashCommand(&integration.SlashCommand{TeamID: "T1", UserID: "U1", Text: "drop all"}), `Unknown command "drop"`)
+}
@@ -0,0 +1,409 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/twilio"
+
+	"github.com/google/uuid"
+)
+
+// smsStatusCallbackPath is the path Twilio posts the delivery status of messages to
+const smsStatusCallbackPath = "/integrations/twilio/status"
+
+const (
+	// smsCodeAttempts is how many wrong codes may be entered before a verification code is revoked
+	smsCodeAttempts = 5
+	// smsResendInterval is how long users wait before another verification code is sent
+	smsResendInterval = time.Minute
+	// smsMessageHistory is how many of the latest messages sent to each user are kept
+	smsMessageHistory = 50
+	// smsTitleLength bounds the task titles in reminders, so reminders fit in a message or two
+	smsTitleLength = 80
+)
+
+// SMSService defines the SMS integration service interface. Users verify a phone number with
+// a code sent to it, then opt into SMS reminders about their high-priority tasks.
+type SMSService interface {
+	GetSMSSettings(userID uuid.UUID) (*integration.SMSSettings, error)
+	// VerifySMSPhone sends a verification code to the phone number
+	VerifySMSPhone(ctx context.Context, userID uuid.UUID, req *integration.VerifySMSPhoneRequest) (*integration.SMSVerification, error)
+	// ConfirmSMSPhone verifies the phone number with the code sent to it
+	ConfirmSMSPhone(userID uuid.UUID, req *integration.ConfirmSMSPhoneRequest) (*integration.SMSSettings, error)
+	UpdateSMSSettings(userID uuid.UUID, req *integration.UpdateSMSSettingsRequest) (*integration.SMSSettings, error)
+	RemoveSMS(userID uuid.UUID) error
+	// DeleteUserData deletes the user's phone number and messages when the account is erased,
+	// satisfying the eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	// ListSMSMessages lists the latest messages sent to the user, newest first
+	ListSMSMessages(userID uuid.UUID) []*integration.SMSMessage
+	// HandleStatusCallback records the delivery status Twilio posted to the status callback
+	HandleStatusCallback(params url.Values, signature string) error
+	// NotificationChannel and Remind text reminders about tasks coming due, satisfying the
+	// notification channel interface
+	NotificationChannel() string
+	Remind(ctx context.Context, user *auth.User, t *task.Task, now time.Time) (bool, error)
+}
+
+// smsVerification is a pending phone verification, with the code stored by hash
+type smsVerification struct {
+	phoneNumber string
+	codeHash    string
+	attempts    int
+	sentAt      time.Time
+	expiresAt   time.Time
+}
+
+// smsService implements the SMS integration service
+type smsService struct {
+	mu            sync.Mutex
+	settings      map[uuid.UUID]*integration.SMSSettings  // Mock settings storage
+	verifications map[uuid.UUID]*smsVerification          // Pending verifications by user
+	messages      map[uuid.UUID][]*integration.SMSMessage // Messages sent to each user, oldest first
+	bySID         map[string]*integration.SMSMessage      // Messages by Twilio SID
+	client        twilio.Client
+	config        *config.Config
+}
+
+// NewSMSService creates a new SMS integration service sending messages through Twilio
+func NewSMSService(cfg *config.Config, client twilio.Client) SMSService {
+	return &smsService{
+		settings:      make(map[uuid.UUID]*integration.SMSSettings),
+		verifications: make(map[uuid.UUID]*smsVerification),
+		messages:      make(map[uuid.UUID][]*integration.SMSMessage),
+		bySID:         make(map[string]*integration.SMSMessage),
+		client:        client,
+		config:        cfg,
+	}
+}
+
+// GetSMSSettings returns the user's SMS settings
+func (s *smsService) GetSMSSettings(userID uuid.UUID) (*integration.SMSSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, exists := s.settings[userID]
+	if !exists {
+		return nil, errors.New("sms is not set up")
+	}
+	return copySMSSettings(settings), nil
+}
+
+// VerifySMSPhone sends a code verifying the phone number, revoking previous codes of the
+// user. The user's current number, if any, is kept until the new one is verified.
+func (s *smsService) VerifySMSPhone(ctx context.Context, userID uuid.UUID, req *integration.VerifySMSPhoneRequest) (*integration.SMSVerification, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return nil, errors.New("failed to generate verification code")
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	s.mu.Lock()
+	now := time.Now()
+	if pending, exists := s.verifications[userID]; exists && now.Sub(pending.sentAt) < smsResendInterval {
+		s.mu.Unlock()
+		return nil, errors.New("verification code was sent recently")
+	}
+	if s.verifiedByOther(userID, req.PhoneNumber) {
+		s.mu.Unlock()
+		return nil, errors.New("phone number is verified by another user")
+	}
+	verification := &smsVerification{
+		phoneNumber: req.PhoneNumber,
+		codeHash:    hashCode(code),
+		sentAt:      now,
+		expiresAt:   now.Add(s.config.Twilio.CodeTTL),
+	}
+	s.verifications[userID] = verification
+	s.mu.Unlock()
+
+	_, err = s.client.SendSMS(ctx, &twilio.Message{
+		To:   req.PhoneNumber,
+		From: s.config.Twilio.From,
+		Body: "Your Todo API verification code is " + code,
+	})
+	if err != nil {
+		s.mu.Lock()
+		if s.verifications[userID] == verification {
+			delete(s.verifications, userID)
+		}
+		s.mu.Unlock()
+		if twilio.IsUnreachable(err) {
+			return nil, errors.New("phone number cannot receive sms")
+		}
+		return nil, fmt.Errorf("twilio: %w", err)
+	}
+
+	return &integration.SMSVerification{PhoneNumber: req.PhoneNumber, ExpiresAt: verification.expiresAt}, nil
+}
+
+// ConfirmSMSPhone verifies the phone number the code was sent to, replacing the user's
+// previous number. Reminders stay off until the user opts into them.
+func (s *smsService) ConfirmSMSPhone(userID uuid.UUID, req *integration.ConfirmSMSPhoneRequest) (*integration.SMSSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	pending, exists := s.verifications[userID]
+	if !exists || now.After(pending.expiresAt) {
+		delete(s.verifications, userID)
+		return nil, errors.New("invalid or expired verification code")
+	}
+	if hashCode(req.Code) != pending.codeHash {
+		// Codes are short, so guessing is cut short too
+		pending.attempts++
+		if pending.attempts >= smsCodeAttempts {
+			delete(s.verifications, userID)
+		}
+		return nil, errors.New("invalid or expired verification code")
+	}
+	delete(s.verifications, userID)
+
+	if s.verifiedByOther(userID, pending.phoneNumber) {
+		return nil, errors.New("phone number is verified by another user")
+	}
+
+	settings, exists := s.settings[userID]
+	if !exists {
+		settings = &integration.SMSSettings{UserID: userID}
+		s.settings[userID] = settings
+	}
+	settings.PhoneNumber = pending.phoneNumber
+	settings.VerifiedAt = now
+	settings.UpdatedAt = now
+	return copySMSSettings(settings), nil
+}
+
+// UpdateSMSSettings updates the SMS settings of the user, who must have verified a number
+func (s *smsService) UpdateSMSSettings(userID uuid.UUID, req *integration.UpdateSMSSettingsRequest) (*integration.SMSSettings, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, exists := s.settings[userID]
+	if !exists {
+		return nil, errors.New("sms is not set up")
+	}
+	req.Apply(settings)
+	return copySMSSettings(settings), nil
+}
+
+// RemoveSMS removes the user's phone number, pending verification, and messages
+func (s *smsService) RemoveSMS(userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, verified := s.settings[userID]
+	_, pending := s.verifications[userID]
+	if !verified && !pending {
+		return errors.New("sms is not set up")
+	}
+	s.remove(userID)
+	return nil
+}
+
+// DeleteUserData deletes the phone number of the user, verified or pending, and the
+// messages sent to it
+func (s *smsService) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(userID)
+}
+
+// remove deletes the settings, verification, and messages of the user. The caller must hold
+// the lock.
+func (s *smsService) remove(userID uuid.UUID) {
+	delete(s.settings, userID)
+	delete(s.verifications, userID)
+	for _, msg := range s.messages[userID] {
+		delete(s.bySID, msg.SID)
+	}
+	delete(s.messages, userID)
+}
+
+// ListSMSMessages lists the latest messages sent to the user, newest first
+func (s *smsService) ListSMSMessages(userID uuid.UUID) []*integration.SMSMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := s.messages[userID]
+	messages := make([]*integration.SMSMessage, 0, len(sent))
+	for i := len(sent) - 1; i >= 0; i-- {
+		msg := *sent[i]
+		messages = append(messages, &msg)
+	}
+	return messages
+}
+
+// HandleStatusCallback records the delivery status of a message, verifying the request
+// was signed by Twilio. Statuses of unknown messages are ignored, and so are statuses
+// arriving after a final one, as Twilio may post them out of order. Reminders are turned
+// off for numbers that cannot receive messages, such as numbers that replied STOP.
+func (s *smsService) HandleStatusCallback(params url.Values, signature string) error {
+	if err := twilio.VerifySignature(s.config.Twilio.AuthToken, s.statusCallbackURL(), params, signature); err != nil {
+		return err
+	}
+	errorCode, _ := strconv.Atoi(params.Get("ErrorCode"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, exists := s.bySID[params.Get("MessageSid")]
+	if !exists || twilio.IsFinalStatus(msg.Status) {
+		return nil
+	}
+	if status := params.Get("MessageStatus"); status != "" {
+		msg.Status = status
+	}
+	msg.ErrorCode = errorCode
+	msg.UpdatedAt = time.Now()
+
+	if twilio.IsUnreachableCode(errorCode) {
+		s.disableReminders(msg.UserID, msg.To, errorCode)
+	}
+	return nil
+}
+
+// NotificationChannel returns the channel of text messages in notification settings
+func (s *smsService) NotificationChannel() string {
+	return notification.ChannelSMS
+}
+
+// Remind texts a reminder about the task coming due to the user's verified number. Only
+// high-priority tasks of users who opted into SMS reminders are reminded of. Reminders
+// are not sent during the user's quiet hours; the notifier tries again on its next run
+// unless another channel delivered the reminder.
+func (s *smsService) Remind(ctx context.Context, user *auth.User, t *task.Task, now time.Time) (bool, error) {
+	if t.Priority != task.PriorityHigh {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	settings, exists := s.settings[user.ID]
+	if !exists || !settings.Reminders || (settings.QuietHours != nil && settings.QuietHours.Contains(now)) {
+		s.mu.Unlock()
+		return false, nil
+	}
+	to := settings.PhoneNumber
+	s.mu.Unlock()
+
+	title := t.Title
+	if utf8.RuneCountInString(title) > smsTitleLength {
+		title = string([]rune(title)[:smsTitleLength-1]) + "…"
+	}
+	body := "Reminder: " + title
+	if t.DueDate.Before(now) {
+		body += " was due " + t.DueDate.UTC().Format(dateFormat)
+	} else {
+		body += " is due " + t.DueDate.UTC().Format(dateFormat)
+	}
+	body += "\n" + s.config.App.BaseURL + "/tasks/" + t.ID.String()
+
+	sent, err := s.client.SendSMS(ctx, &twilio.Message{
+		To:             to,
+		From:           s.config.Twilio.From,
+		Body:           body,
+		StatusCallback: s.statusCallbackURL(),
+	})
+	if err != nil {
+		var apiErr *twilio.APIError
+		if errors.As(err, &apiErr) && twilio.IsUnreachableCode(apiErr.Code) {
+			s.mu.Lock()
+			s.disableReminders(user.ID, to, apiErr.Code)
+			s.mu.Unlock()
+		}
+		return false, fmt.Errorf("twilio: %w", err)
+	}
+
+	s.record(&integration.SMSMessage{
+		SID:       sent.SID,
+		UserID:    user.ID,
+		TaskID:    t.ID,
+		To:        to,
+		Status:    sent.Status,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	return true, nil
+}
+
+// record keeps the message sent to the user, dropping the user's oldest messages beyond
+// the history limit
+func (s *smsService) record(msg *integration.SMSMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := append(s.messages[msg.UserID], msg)
+	if len(messages) > smsMessageHistory {
+		for _, dropped := range messages[:len(messages)-smsMessageHistory] {
+			delete(s.bySID, dropped.SID)
+		}
+		messages = slices.Clone(messages[len(messages)-smsMessageHistory:])
+	}
+	s.messages[msg.UserID] = messages
+	s.bySID[msg.SID] = msg
+}
+
+// disableReminders turns off the reminders of the user if their number is still the one
+// that cannot receive messages. The caller must hold the lock.
+func (s *smsService) disableReminders(userID uuid.UUID, phoneNumber string, errorCode int) {
+	settings, exists := s.settings[userID]
+	if !exists || settings.PhoneNumber != phoneNumber || !settings.Reminders {
+		return
+	}
+	log.Printf("Turning off SMS reminders of user %s: Twilio error %d", userID, errorCode)
+	settings.Reminders = false
+	settings.UpdatedAt = time.Now()
+}
+
+// verifiedByOther reports whether another user verified the phone number. The caller must
+// hold the lock.
+func (s *smsService) verifiedByOther(userID uuid.UUID, phoneNumber string) bool {
+	for id, settings := range s.settings {
+		if id != userID && settings.PhoneNumber == phoneNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// statusCallbackURL returns the URL Twilio posts the delivery status of messages to
+func (s *smsService) statusCallbackURL() string {
+	return s.config.App.BaseURL + smsStatusCallbackPath
+}
+
+// copySMSSettings returns a copy of the settings, quiet hours included
+func copySMSSettings(settings *integration.SMSSettings) *integration.SMSSettings {
+	copied := *settings
+	if settings.QuietHours != nil {
+		quiet := *settings.QuietHours
+		copied.QuietHours = &quiet
+	}
+	return &copied
+}
@@ -0,0 +1,251 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/twilio"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTwilio records the messages sent through Twilio
+type fakeTwilio struct {
+	mu       sync.Mutex
+	sent     []*twilio.Message
+	failWith error
+}
+
+func (c *fakeTwilio) SendSMS(ctx context.Context, msg *twilio.Message) (*twilio.SentMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failWith != nil {
+		return nil, c.failWith
+	}
+	c.sent = append(c.sent, msg)
+	return &twilio.SentMessage{SID: fmt.Sprintf("SM%032d", len(c.sent)), Status: twilio.StatusQueued}, nil
+}
+
+// last returns the last message sent
+func (c *fakeTwilio) last() *twilio.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.sent) == 0 {
+		return nil
+	}
+	return c.sent[len(c.sent)-1]
+}
+
+const smsAuthToken = "test-auth-token"
+
+func setupSMSService(t *testing.T) (SMSService, *fakeTwilio) {
+	cfg := &config.Config{
+		App:    config.AppConfig{BaseURL: "https://todo.example.com"},
+		Twilio: config.TwilioConfig{AccountSID: "AC123", AuthToken: smsAuthToken, From: "+14155550199", CodeTTL: 10 * time.Minute},
+	}
+	client := &fakeTwilio{}
+	return NewSMSService(cfg, client), client
+}
+
+// verifyPhone verifies the phone number of the user with the code texted to it
+func verifyPhone(t *testing.T, service SMSService, client *fakeTwilio, userID uuid.UUID, number string) {
+	_, err := service.VerifySMSPhone(context.Background(), userID, &integration.VerifySMSPhoneRequest{PhoneNumber: number})
+	require.NoError(t, err)
+	code := strings.TrimPrefix(client.last().Body, "Your Todo API verification code is ")
+	_, err = service.ConfirmSMSPhone(userID, &integration.ConfirmSMSPhoneRequest{Code: code})
+	require.NoError(t, err)
+}
+
+func TestSMSService_Verification(t *testing.T) {
+	service, client := setupSMSService(t)
+	userID := uuid.New()
+
+	_, err := service.GetSMSSettings(userID)
+	assert.EqualError(t, err, "sms is not set up")
+
+	verification, err := service.VerifySMSPhone(context.Background(), userID, &integration.VerifySMSPhoneRequest{PhoneNumber: "+14155550100"})
+	require.NoError(t, err)
+	assert.Equal(t, "+14155550100", verification.PhoneNumber)
+	assert.Equal(t, "+14155550100", client.last().To)
+	assert.Equal(t, "+14155550199", client.last().From)
+	code := strings.TrimPrefix(client.last().Body, "Your Todo API verification code is ")
+	assert.Len(t, code, 6)
+
+	// Codes are not resent right away
+	_, err = service.VerifySMSPhone(context.Background(), userID, &integration.VerifySMSPhoneRequest{PhoneNumber: "+14155550100"})
+	assert.EqualError(t, err, "verification code was sent recently")
+
+	_, err = service.ConfirmSMSPhone(userID, &integration.ConfirmSMSPhoneRequest{Code: "wrong"})
+	assert.EqualError(t, err, "invalid or expired verification code")
+
+	settings, err := service.ConfirmSMSPhone(userID, &integration.ConfirmSMSPhoneRequest{Code: code})
+	require.NoError(t, err)
+	assert.Equal(t, "+14155550100", settings.PhoneNumber)
+	assert.False(t, settings.Reminders)
+	assert.False(t, settings.VerifiedAt.IsZero())
+
+	// Codes are single use
+	_, err = service.ConfirmSMSPhone(userID, &integration.ConfirmSMSPhoneRequest{Code: code})
+	assert.EqualError(t, err, "invalid or expired verification code")
+
+	// A number belongs to a single user
+	_, err = service.VerifySMSPhone(context.Background(), uuid.New(), &integration.VerifySMSPhoneRequest{PhoneNumber: "+14155550100"})
+	assert.EqualError(t, err, "phone number is verified by another user")
+
+	require.NoError(t, service.RemoveSMS(userID))
+	assert.EqualError(t, service.RemoveSMS(userID), "sms is not set up")
+}
+
+func TestSMSService_DeleteUserData(t *testing.T) {
+	service, client := setupSMSService(t)
+	userID := uuid.New()
+
+	_, err := service.VerifySMSPhone(context.Background(), userID, &integration.VerifySMSPhoneRequest{PhoneNumber: "+14155550100"})
+	require.NoError(t, err)
+	code := strings.TrimPrefix(client.last().Body, "Your Todo API verification code is ")
+	_, err = service.ConfirmSMSPhone(userID, &integration.ConfirmSMSPhoneRequest{Code: code})
+	require.NoError(t, err)
+
+	// The number is deleted, so another user can verify it
+	service.DeleteUserData(userID)
+	_, err = service.GetSMSSettings(userID)
+	assert.EqualError(t, err, "sms is not set up")
+	_, err = service.VerifySMSPhone(context.Background(), uuid.New(), &integration.VerifySMSPhoneRequest{PhoneNumber: "+14155550100"})
+	assert.NoError(t, err)
+}
+
+func TestSMSService_VerificationAttempts(t *testing.T) {
+	service, client := setupSMSService(t)
+	userID := uuid.New()
+
+	_, err := service.VerifySMSPhone(context.Background(), userID, &integration.VerifySMSPhoneRequest{PhoneNumber: "+14155550100"})
+	require.NoError(t, err)
+	code := strings.TrimPrefix(client.last().Body, "Your Todo API verification code is ")
+
+	// Codes are revoked after too many wrong guesses
+	for i := 0; i < smsCodeAttempts; i++ {
+		_, err = service.ConfirmSMSPhone(userID, &integration.ConfirmSMSPhoneRequest{Code: "000000x"})
+		assert.Error(t, err)
+	}
+	_, err = service.ConfirmSMSPhone(userID, &integration.ConfirmSMSPhoneRequest{Code: code})
+	assert.EqualError(t, err, "invalid or expired verification code")
+
+	// Numbers that cannot receive messages are rejected
+	client.failWith = &twilio.APIError{StatusCode: 400, Code: twilio.ErrorInvalidNumber, Message: "invalid"}
+	_, err = service.VerifySMSPhone(context.Background(), uuid.New(), &integration.VerifySMSPhoneRequest{PhoneNumber: "+14155550101"})
+	assert.EqualError(t, err, "phone number cannot receive sms")
+}
+
+func TestSMSService_Remind(t *testing.T) {
+	service, client := setupSMSService(t)
+	user := &auth.User{ID: uuid.New()}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	due := now.Add(time.Hour)
+	urgent := &task.Task{ID: uuid.New(), Title: "File taxes", Priority: task.PriorityHigh, DueDate: &due}
+
+	// Users without a verified number are not texted
+	delivered, err := service.Remind(context.Background(), user, urgent, now)
+	require.NoError(t, err)
+	assert.False(t, delivered)
+
+	verifyPhone(t, service, client, user.ID, "+14155550100")
+
+	// Nor are users who did not opt in
+	delivered, err = service.Remind(context.Background(), user, urgent, now)
+	require.NoError(t, err)
+	assert.False(t, delivered)
+
+	reminders := true
+	_, err = service.UpdateSMSSettings(user.ID, &integration.UpdateSMSSettingsRequest{
+		Reminders:  &reminders,
+		QuietHours: &integration.QuietHours{Start: "22:00", End: "07:00", TimeZone: "UTC"},
+	})
+	require.NoError(t, err)
+
+	// Only high-priority tasks are reminded of
+	delivered, err = service.Remind(context.Background(), user, &task.Task{ID: uuid.New(), Title: "Water plants", Priority: task.PriorityMedium, DueDate: &due}, now)
+	require.NoError(t, err)
+	assert.False(t, delivered)
+
+	// Reminders wait for the end of quiet hours
+	delivered, err = service.Remind(context.Background(), user, urgent, now.Add(11*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, delivered)
+
+	delivered, err = service.Remind(context.Background(), user, urgent, now)
+	require.NoError(t, err)
+	assert.True(t, delivered)
+	msg := client.last()
+	assert.Equal(t, "+14155550100", msg.To)
+	assert.Equal(t, "https://todo.example.com/integrations/twilio/status", msg.StatusCallback)
+	assert.Contains(t, msg.Body, "Reminder: File taxes is due Tue, Mar 10 2026 13:00 UTC")
+	assert.Contains(t, msg.Body, "https://todo.example.com/tasks/"+urgent.ID.String())
+
+	messages := service.ListSMSMessages(user.ID)
+	require.Len(t, messages, 1)
+	assert.Equal(t, urgent.ID, messages[0].TaskID)
+	assert.Equal(t, twilio.StatusQueued, messages[0].Status)
+
+	// Recipients who unsubscribed are no longer texted
+	client.failWith = &twilio.APIError{StatusCode: 400, Code: twilio.ErrorUnsubscribed, Message: "unsubscribed"}
+	_, err = service.Remind(context.Background(), user, urgent, now)
+	assert.Error(t, err)
+	settings, err := service.GetSMSSettings(user.ID)
+	require.NoError(t, err)
+	assert.False(t, settings.Reminders)
+}
+
+func TestSMSService_StatusCallback(t *testing.T) {
+	service, client := setupSMSService(t)
+	user := &auth.User{ID: uuid.New()}
+	now := time.Now()
+	due := now.Add(time.Hour)
+	verifyPhone(t, service, client, user.ID, "+14155550100")
+	reminders := true
+	_, err := service.UpdateSMSSettings(user.ID, &integration.UpdateSMSSettingsRequest{Reminders: &reminders})
+	require.NoError(t, err)
+
+	delivered, err := service.Remind(context.Background(), user, &task.Task{ID: uuid.New(), Title: "File taxes", Priority: task.PriorityHigh, DueDate: &due}, now)
+	require.NoError(t, err)
+	require.True(t, delivered)
+	sid := service.ListSMSMessages(user.ID)[0].SID
+
+	callbackURL := "https://todo.example.com/integrations/twilio/status"
+	report := func(status, errorCode string) error {
+		params := url.Values{"MessageSid": {sid}, "MessageStatus": {status}}
+		if errorCode != "" {
+			params.Set("ErrorCode", errorCode)
+		}
+		return service.HandleStatusCallback(params, twilio.Sign(smsAuthToken, callbackURL, params))
+	}
+
+	// Callbacks must be signed by Twilio
+	params := url.Values{"MessageSid": {sid}, "MessageStatus": {"delivered"}}
+	assert.ErrorIs(t, service.HandleStatusCallback(params, twilio.Sign("other-token", callbackURL, params)), twilio.ErrInvalidSignature)
+
+	require.NoError(t, report(twilio.StatusUndelivered, "21610"))
+	assert.Equal(t, twilio.StatusUndelivered, service.ListSMSMessages(user.ID)[0].Status)
+	assert.Equal(t, 21610, service.ListSMSMessages(user.ID)[0].ErrorCode)
+	settings, err := service.GetSMSSettings(user.ID)
+	require.NoError(t, err)
+	assert.False(t, settings.Reminders)
+
+	// Statuses arriving after a final one are ignored
+	require.NoError(t, report(twilio.StatusSent, ""))
+	assert.Equal(t, twilio.StatusUndelivered, service.ListSMSMessages(user.ID)[0].Status)
+
+	// Unknown messages are acknowledged
+	sid = "SMunknown"
+	assert.NoError(t, report(twilio.StatusDelivered, ""))
+}
@@ -0,0 +1,347 @@
+package integration
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/integration"
+	"todo-api/internal/domain/task"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+// telegramHelp lists the bot commands
+const telegramHelp = `Commands:
+/today - list the tasks due today or overdue
+/add TITLE - add a task
+/done NUMBER - complete a task listed by /today
+/unlink - unlink this chat from your account`
+
+// telegramNotLinked is the reply to commands sent from chats not linked to an account
+const telegramNotLinked = "This chat is not linked to a Todo API account. Create a link code in the app and send /start CODE."
+
+// TelegramService defines the Telegram bot service interface. Users link a private chat
+// with the bot to their account, then manage their tasks through bot commands.
+type TelegramService interface {
+	CreateTelegramLinkCode(userID uuid.UUID, scopes []string) (*integration.TelegramLinkCode, error)
+	GetTelegramLink(userID uuid.UUID) (*integration.TelegramLink, error)
+	UnlinkTelegram(userID uuid.UUID) error
+	// DeleteUserData deletes the user's link and pending link codes when the account is
+	// erased, satisfying the eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	// HandleMessage runs the bot command in the message and returns the reply, if any
+	HandleMessage(msg *telegram.Message, now time.Time) string
+}
+
+// telegramLinkCode is a pending link code, stored by hash
+type telegramLinkCode struct {
+	userID    uuid.UUID
+	scopes    []string
+	expiresAt time.Time
+}
+
+// telegramService implements the Telegram bot service
+type telegramService struct {
+	mu          sync.Mutex
+	links       map[int64]*integration.TelegramLink // Mock link storage by chat ID
+	codes       map[string]*telegramLinkCode        // Pending link codes by hash
+	listed      map[int64][]uuid.UUID               // tasks last listed in each chat, numbered from 1
+	authService authService.Service
+	taskService taskService.Service
+	plans       PlanDirectory
+	config      *config.Config
+}
+
+// NewTelegramService creates a new Telegram bot service acting on tasks through the task service
+func NewTelegramService(cfg *config.Config, authSvc authService.Service, taskSvc taskService.Service) TelegramService {
+	return NewTelegramServiceWithPlans(cfg, authSvc, taskSvc, unlimitedPlans{})
+}
+
+// NewTelegramServiceWithPlans creates a new Telegram bot service issuing link codes only
+// when the user's plan allows linking Telegram
+func NewTelegramServiceWithPlans(cfg *config.Config, authSvc authService.Service, taskSvc taskService.Service,
+	plans PlanDirectory) TelegramService {
+	return &telegramService{
+		links:       make(map[int64]*integration.TelegramLink),
+		codes:       make(map[string]*telegramLinkCode),
+		listed:      make(map[int64][]uuid.UUID),
+		authService: authSvc,
+		taskService: taskSvc,
+		plans:       plans,
+		config:      cfg,
+	}
+}
+
+// CreateTelegramLinkCode creates a one-time code linking a Telegram chat to the user. The
+// chat may act with the task scopes among the given token scopes, every task scope when the
+// token has none. Previous codes of the user are revoked.
+func (s *telegramService) CreateTelegramLinkCode(userID uuid.UUID, scopes []string) (*integration.TelegramLinkCode, error) {
+	if err := s.plans.AllowIntegration(userID, integration.NameTelegram); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.New("failed to generate link code")
+	}
+	code := base64.RawURLEncoding.EncodeToString(raw)
+
+	granted := slices.Clone(auth.AllScopes)
+	if len(scopes) > 0 {
+		granted = slices.DeleteFunc(granted, func(scope string) bool {
+			return !slices.Contains(scopes, scope)
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, pending := range s.codes {
+		if pending.userID == userID || now.After(pending.expiresAt) {
+			delete(s.codes, hash)
+		}
+	}
+
+	expiresAt := now.Add(s.config.Telegram.LinkTTL)
+	s.codes[hashCode(code)] = &telegramLinkCode{userID: userID, scopes: granted, expiresAt: expiresAt}
+
+	linkCode := &integration.TelegramLinkCode{Code: code, ExpiresAt: expiresAt}
+	if username := s.config.Telegram.BotUsername; username != "" {
+		linkCode.URL = "https://t.me/" + username + "?start=" + code
+	}
+	return linkCode, nil
+}
+
+// GetTelegramLink returns the Telegram chat linked to the user
+func (s *telegramService) GetTelegramLink(userID uuid.UUID) (*integration.TelegramLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, link := range s.links {
+		if link.UserID == userID {
+			linked := *link
+			return &linked, nil
+		}
+	}
+	return nil, errors.New("telegram is not linked")
+}
+
+// UnlinkTelegram unlinks the Telegram chat linked to the user
+func (s *telegramService) UnlinkTelegram(userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for chatID, link := range s.links {
+		if link.UserID == userID {
+			s.unlink(chatID)
+			return nil
+		}
+	}
+	return errors.New("telegram is not linked")
+}
+
+// DeleteUserData unlinks the chat of the user, if any, and deletes the user's pending link
+// codes
+func (s *telegramService) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for chatID, link := range s.links {
+		if link.UserID == userID {
+			s.unlink(chatID)
+		}
+	}
+	for hash, pending := range s.codes {
+		if pending.userID == userID {
+			delete(s.codes, hash)
+		}
+	}
+}
+
+// HandleMessage runs the bot command in the message and returns the reply. Only private
+// chats are served, so nobody acts on a user's tasks from a group the user's chat is in.
+func (s *telegramService) HandleMessage(msg *telegram.Message, now time.Time) string {
+	if msg.Chat.Type != telegram.ChatTypePrivate {
+		return "Please message me in a private chat."
+	}
+
+	text := strings.TrimSpace(msg.Text)
+	if !strings.HasPrefix(text, "/") {
+		return telegramHelp
+	}
+	command, args, _ := strings.Cut(text, " ")
+	// Commands may be addressed to the bot, as in /today@todo_bot
+	command, _, _ = strings.Cut(strings.ToLower(command), "@")
+	args = strings.TrimSpace(args)
+
+	if command == "/start" && args != "" {
+		return s.link(msg, args, now)
+	}
+
+	session, ok := s.session(msg.Chat.ID)
+	if !ok {
+		return telegramNotLinked
+	}
+
+	switch command {
+	case "/start", "/help":
+		return telegramHelp
+	case "/today":
+		if !session.HasScope(auth.ScopeTasksRead) {
+			return "This chat may not read your tasks."
+		}
+		return s.today(msg.Chat.ID, session, now)
+	case "/add":
+		if !session.HasScope(auth.ScopeTasksWrite) {
+			return "This chat may not change your tasks."
+		}
+		created, err := s.taskService.CreateTask(&task.CreateTaskRequest{Title: args}, session.UserID)
+		if err != nil {
+			return "Could not add the task: " + err.Error()
+		}
+		return "Added: " + created.Title
+	case "/done":
+		if !session.HasScope(auth.ScopeTasksWrite) {
+			return "This chat may not change your tasks."
+		}
+		return s.done(msg.Chat.ID, session, args)
+	case "/unlink":
+		s.mu.Lock()
+		s.unlink(msg.Chat.ID)
+		s.mu.Unlock()
+		return "This chat is no longer linked to your account."
+	default:
+		return "Unknown command.\n\n" + telegramHelp
+	}
+}
+
+// link links the chat to the user of the link code, replacing any link of the chat or user
+func (s *telegramService) link(msg *telegram.Message, code string, now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := hashCode(code)
+	pending, exists := s.codes[hash]
+	if !exists || now.After(pending.expiresAt) {
+		return "This link code is invalid or expired. Create a new one in the app."
+	}
+	delete(s.codes, hash)
+
+	if _, err := s.authService.GetUserByID(pending.userID); err != nil {
+		return "This link code is invalid or expired. Create a new one in the app."
+	}
+
+	for chatID, link := range s.links {
+		if link.UserID == pending.userID {
+			s.unlink(chatID)
+		}
+	}
+	s.unlink(msg.Chat.ID)
+
+	link := &integration.TelegramLink{
+		UserID:   pending.userID,
+		ChatID:   msg.Chat.ID,
+		Scopes:   pending.scopes,
+		LinkedAt: now,
+	}
+	if msg.From != nil {
+		link.Username = msg.From.Username
+	}
+	s.links[msg.Chat.ID] = link
+
+	return "This chat is now linked to your Todo API account.\n\n" + telegramHelp
+}
+
+// session returns the session of the user linked to the chat. Links of erased accounts are
+// removed.
+func (s *telegramService) session(chatID int64) (*integration.BotSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, exists := s.links[chatID]
+	if !exists {
+		return nil, false
+	}
+	if _, err := s.authService.GetUserByID(link.UserID); err != nil {
+		s.unlink(chatID)
+		return nil, false
+	}
+	return &integration.BotSession{UserID: link.UserID, Scopes: slices.Clone(link.Scopes)}, true
+}
+
+// today lists the user's open tasks due by the end of the day, in UTC, numbering them for /done
+func (s *telegramService) today(chatID int64, session *integration.BotSession, now time.Time) string {
+	year, month, day := now.UTC().Date()
+	endOfDay := time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+
+	var lines []string
+	var listed []uuid.UUID
+	for _, t := range s.taskService.OpenTasks(session.UserID) {
+		if t.DueDate == nil || !t.DueDate.Before(endOfDay) {
+			continue
+		}
+		status := "due " + t.DueDate.UTC().Format("15:04 MST")
+		if t.DueDate.Before(now) {
+			status = "overdue since " + t.DueDate.UTC().Format(dateFormat)
+		}
+		listed = append(listed, t.ID)
+		lines = append(lines, fmt.Sprintf("%d. %s (%s)", len(listed), t.Title, status))
+	}
+
+	s.mu.Lock()
+	s.listed[chatID] = listed
+	s.mu.Unlock()
+
+	if len(lines) == 0 {
+		return "Nothing due today."
+	}
+	return "Due today:\n" + strings.Join(lines, "\n") + "\n\nSend /done NUMBER to complete a task."
+}
+
+// done completes the task with the number it was listed with by /today, or with the ID
+func (s *telegramService) done(chatID int64, session *integration.BotSession, arg string) string {
+	id, err := uuid.Parse(arg)
+	if err != nil {
+		n, convErr := strconv.Atoi(arg)
+		s.mu.Lock()
+		listed := s.listed[chatID]
+		s.mu.Unlock()
+		if convErr != nil || n < 1 || n > len(listed) {
+			return "Send /done with the number of a task listed by /today."
+		}
+		id = listed[n-1]
+	}
+
+	completed, err := s.taskService.CompleteTask(id, session.UserID)
+	if err != nil {
+		return "Could not complete the task: " + err.Error()
+	}
+	return "Completed: " + completed.Title
+}
+
+// unlink removes the link of the chat and the tasks listed in it. The caller must hold the lock.
+func (s *telegramService) unlink(chatID int64) {
+	delete(s.links, chatID)
+	delete(s.listed, chatID)
+}
+
+// hashCode returns the hash link codes are stored by
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
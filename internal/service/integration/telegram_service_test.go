@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/task"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/telegram"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTelegramService(t *testing.T) (TelegramService, authService.Service, taskService.Service) {
+	cfg := &config.Config{Telegram: config.TelegramConfig{BotUsername: "todo_bot", LinkTTL: 10 * time.Minute}}
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	return NewTelegramService(cfg, authSvc, taskSvc), authSvc, taskSvc
+}
+
+// privateMessage returns a message sent in the private chat with ID 42
+func privateMessage(text string) *telegram.Message {
+	return &telegram.Message{
+		From: &telegram.User{ID: 7, Username: "john"},
+		Chat: telegram.Chat{ID: 42, Type: telegram.ChatTypePrivate},
+		Text: text,
+	}
+}
+
+func TestTelegramService_Link(t *testing.T) {
+	service, authSvc, _ := setupTelegramService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	now := time.Now()
+
+	assert.Equal(t, telegramNotLinked, service.HandleMessage(privateMessage("/today"), now))
+
+	code, err := service.CreateTelegramLinkCode(john.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://t.me/todo_bot?start="+code.Code, code.URL)
+
+	// Group chats cannot be linked
+	group := privateMessage("/start " + code.Code)
+	group.Chat.Type = "group"
+	assert.Contains(t, service.HandleMessage(group, now), "private chat")
+
+	// Codes expire
+	assert.Contains(t, service.HandleMessage(privateMessage("/start "+code.Code), now.Add(time.Hour)), "invalid or expired")
+
+	code, err = service.CreateTelegramLinkCode(john.ID, nil)
+	require.NoError(t, err)
+	assert.Contains(t, service.HandleMessage(privateMessage("/start "+code.Code), now), "now linked")
+
+	link, err := service.GetTelegramLink(john.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), link.ChatID)
+	assert.Equal(t, "john", link.Username)
+	assert.Equal(t, auth.AllScopes, link.Scopes)
+
+	// Codes are single use
+	assert.Contains(t, service.HandleMessage(privateMessage("/start "+code.Code), now), "invalid or expired")
+
+	assert.Contains(t, service.HandleMessage(privateMessage("/unlink"), now), "no longer linked")
+	assert.Error(t, service.UnlinkTelegram(john.ID))
+}
+
+func TestTelegramService_Commands(t *testing.T) {
+	service, authSvc, taskSvc := setupTelegramService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	code, err := service.CreateTelegramLinkCode(john.ID, nil)
+	require.NoError(t, err)
+	service.HandleMessage(privateMessage("/start "+code.Code), now)
+
+	overdue := now.Add(-26 * time.Hour)
+	tonight := now.Add(6 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+	for title, due := range map[string]*time.Time{"File taxes": &overdue, "Call mom": &tonight, "Plan trip": &tomorrow} {
+		_, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: title, DueDate: due}, john.ID)
+		require.NoError(t, err)
+	}
+
+	reply := service.HandleMessage(privateMessage("/today@todo_bot"), now)
+	assert.Contains(t, reply, "1. File taxes (overdue since Mon, Mar 9 2026 10:00 UTC)")
+	assert.Contains(t, reply, "2. Call mom (due 18:00 UTC)")
+	assert.NotContains(t, reply, "Plan trip")
+
+	assert.Equal(t, "Completed: Call mom", service.HandleMessage(privateMessage("/done 2"), now))
+	assert.Contains(t, service.HandleMessage(privateMessage("/done 3"), now), "Send /done with the number")
+	assert.NotContains(t, service.HandleMessage(privateMessage("/today"), now), "Call mom")
+
+	assert.Equal(t, "Added: Buy milk", service.HandleMessage(privateMessage("/add Buy milk"), now))
+	assert.Contains(t, service.HandleMessage(privateMessage("/add"), now), "Could not add the task")
+	assert.True(t, strings.HasPrefix(service.HandleMessage(privateMessage("/dance"), now), "Unknown command."))
+}
+
+func TestTelegramService_ReadOnlySession(t *testing.T) {
+	service, authSvc, _ := setupTelegramService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	now := time.Now()
+
+	// Chats linked with a read-only token cannot change tasks
+	code, err := service.CreateTelegramLinkCode(john.ID, []string{auth.ScopeTasksRead})
+	require.NoError(t, err)
+	service.HandleMessage(privateMessage("/start "+code.Code), now)
+
+	assert.Contains(t, service.HandleMessage(privateMessage("/add Buy milk"), now), "may not change your tasks")
+	assert.NotContains(t, service.HandleMessage(privateMessage("/today"), now), "may not")
+
+	// Chats of erased accounts are unlinked
+	require.NoError(t, authSvc.DeleteUser(john.ID))
+	assert.Equal(t, telegramNotLinked, service.HandleMessage(privateMessage("/today"), now))
+}
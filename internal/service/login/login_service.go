@@ -0,0 +1,150 @@
+// Package login logs users in for every transport, throttling the attempts flagged by the
+// login guard and recording them in the audit log, so the REST and gRPC APIs are guarded
+// alike.
+package login
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/security"
+	auditService "todo-api/internal/service/audit"
+	authService "todo-api/internal/service/auth"
+	loginGuard "todo-api/internal/service/loginguard"
+
+	"github.com/google/uuid"
+)
+
+// ThrottledError is returned for login attempts the login guard rejected, with how long the
+// client must wait before trying again
+type ThrottledError struct {
+	Wait time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return "too many login attempts"
+}
+
+// Client identifies where a login attempt comes from
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Service defines the login service interface
+type Service interface {
+	// Login authenticates a user and returns tokens. Attempts the login guard rejects fail
+	// with a *ThrottledError without checking the password.
+	Login(req *auth.LoginRequest, client Client) (*auth.TokenResponse, error)
+}
+
+// Deps are the services logins go through. Audit and Guard are optional.
+type Deps struct {
+	Auth  authService.Service
+	Audit auditService.Service // records login attempts and the anomalies detected
+	Guard loginGuard.Service   // throttles suspicious logins
+}
+
+// service implements the login service
+type service struct {
+	auth  authService.Service
+	audit auditService.Service
+	guard loginGuard.Service
+}
+
+// NewService creates a new login service
+func NewService(deps Deps) Service {
+	return &service{auth: deps.Auth, audit: deps.Audit, guard: deps.Guard}
+}
+
+// Login authenticates a user through the auth service, once the login guard lets the attempt
+// proceed, recording the outcome in the audit log and reporting it to the guard
+func (s *service) Login(req *auth.LoginRequest, client Client) (*auth.TokenResponse, error) {
+	if s.guard != nil {
+		if wait := s.guard.Check(req.Email, client.IP); wait > 0 {
+			err := &ThrottledError{Wait: wait}
+			s.recordLogin(req.Email, client, err)
+			return nil, err
+		}
+	}
+
+	// The user agent is shown in the sessions of the user
+	req.UserAgent = client.UserAgent
+
+	tokenResponse, err := s.auth.Login(req)
+	s.recordLogin(req.Email, client, err)
+	// When the directory is unavailable the password was never checked, so the attempt does
+	// not count as a failure
+	if !errors.Is(err, auth.ErrDirectoryUnavailable) {
+		s.guardLogin(req.Email, client, err)
+	}
+	return tokenResponse, err
+}
+
+// guardLogin reports the outcome of a login attempt to the login guard, recording the
+// anomalies it detects in the audit log
+func (s *service) guardLogin(email string, client Client, loginErr error) {
+	if s.guard == nil {
+		return
+	}
+
+	var anomalies []*security.Anomaly
+	if loginErr != nil {
+		anomalies = s.guard.RecordFailure(email, client.IP)
+	} else if user, err := s.auth.GetUserByEmail(email); err == nil {
+		anomalies = s.guard.RecordSuccess(user.ID, email, client.IP)
+	}
+
+	for _, anomaly := range anomalies {
+		log.Printf("Login anomaly %s from %s affecting %s", anomaly.Type, anomaly.IP, strings.Join(anomaly.Emails, ", "))
+
+		entry := audit.NewEntry(audit.ActionAnomalyDetected, nil)
+		entry.TargetID = anomaly.UserID
+		entry.Subject = email
+		entry.Details = map[string]string{
+			"type":          string(anomaly.Type),
+			"accounts":      strings.Join(anomaly.Emails, ","),
+			"flagged_until": anomaly.FlaggedUntil.UTC().Format(time.RFC3339),
+		}
+		s.record(entry, client)
+	}
+}
+
+// recordLogin records the outcome of a login attempt in the audit log
+func (s *service) recordLogin(email string, client Client, loginErr error) {
+	if s.audit == nil {
+		return
+	}
+
+	var userID *uuid.UUID
+	if user, err := s.auth.GetUserByEmail(email); err == nil {
+		userID = &user.ID
+	}
+
+	var entry *audit.Entry
+	if loginErr == nil {
+		entry = audit.NewEntry(audit.ActionLoginSucceeded, userID)
+	} else {
+		// The attempt may not come from the account owner, so the account is the target
+		entry = audit.NewEntry(audit.ActionLoginFailed, nil)
+		entry.TargetID = userID
+		entry.Details = map[string]string{"reason": loginErr.Error()}
+	}
+	entry.Subject = email
+
+	s.record(entry, client)
+}
+
+// record records an entry in the audit log with the client of the attempt
+func (s *service) record(entry *audit.Entry, client Client) {
+	if s.audit == nil {
+		return
+	}
+	entry.IP = client.IP
+	entry.UserAgent = client.UserAgent
+	s.audit.Record(entry)
+}
@@ -0,0 +1,103 @@
+package login
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/audit"
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/metrics"
+	"todo-api/internal/mocks"
+	auditService "todo-api/internal/service/audit"
+	loginGuard "todo-api/internal/service/loginguard"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// john is the user the mocked auth service knows
+var john = &auth.User{ID: uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54"), Email: "john.doe@example.com"}
+
+// client is the client of the login attempts
+var client = Client{IP: "203.0.113.7", UserAgent: "todo-cli/1.0"}
+
+func newTestGuard(maxAccountFailures int) loginGuard.Service {
+	return loginGuard.NewService(config.LoginGuardConfig{
+		Window:             time.Minute,
+		MaxAccountFailures: maxAccountFailures,
+		FlagDuration:       time.Minute,
+		ThrottleInterval:   time.Minute,
+	}, metrics.NewRegistry())
+}
+
+func TestService_Login(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	auditSvc := auditService.NewService()
+	service := NewService(Deps{Auth: authSvc, Audit: auditSvc, Guard: newTestGuard(5)})
+
+	req := &auth.LoginRequest{Email: john.Email, Password: "password123"}
+	authSvc.On("GetUserByEmail", john.Email).Return(john, nil)
+	authSvc.On("Login", req).Return(&auth.TokenResponse{AccessToken: "access"}, nil).Once()
+
+	tokenResponse, err := service.Login(req, client)
+
+	require.NoError(t, err)
+	assert.Equal(t, "access", tokenResponse.AccessToken)
+	assert.Equal(t, client.UserAgent, req.UserAgent)
+
+	entries, _ := auditSvc.List(nil, 1, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.ActionLoginSucceeded, entries[0].Action)
+	assert.Equal(t, john.ID, *entries[0].ActorID)
+	assert.Equal(t, client.IP, entries[0].IP)
+	assert.Equal(t, client.UserAgent, entries[0].UserAgent)
+}
+
+func TestService_Login_Throttled(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	auditSvc := auditService.NewService()
+	service := NewService(Deps{Auth: authSvc, Audit: auditSvc, Guard: newTestGuard(2)})
+
+	req := &auth.LoginRequest{Email: john.Email, Password: "wrongpassword"}
+	authSvc.On("GetUserByEmail", john.Email).Return(john, nil)
+	authSvc.On("Login", req).Return(nil, errors.New("invalid email or password")).Times(3)
+
+	for i := 0; i < 3; i++ {
+		_, err := service.Login(req, client)
+		assert.EqualError(t, err, "invalid email or password")
+	}
+
+	// The flagged account must now wait, without the password being checked
+	_, err := service.Login(req, client)
+	var throttled *ThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.InDelta(t, time.Minute, throttled.Wait, float64(time.Second))
+
+	entries, _ := auditSvc.List(&audit.Filter{Action: string(audit.ActionAnomalyDetected)}, 1, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "account_failures", entries[0].Details["type"])
+	assert.Equal(t, client.IP, entries[0].IP)
+
+	entries, _ = auditSvc.List(nil, 1, 1)
+	assert.Equal(t, audit.ActionLoginFailed, entries[0].Action)
+	assert.Equal(t, john.ID, *entries[0].TargetID)
+	assert.Equal(t, "too many login attempts", entries[0].Details["reason"])
+}
+
+func TestService_Login_DirectoryUnavailable(t *testing.T) {
+	authSvc := mocks.NewAuthService(t)
+	service := NewService(Deps{Auth: authSvc, Guard: newTestGuard(1)})
+
+	req := &auth.LoginRequest{Email: john.Email, Password: "password123"}
+	authSvc.On("Login", req).Return(nil, fmt.Errorf("%w: connection refused", auth.ErrDirectoryUnavailable))
+
+	// Outages are not held against the account by the login guard
+	for i := 0; i < 3; i++ {
+		_, err := service.Login(req, client)
+		assert.ErrorIs(t, err, auth.ErrDirectoryUnavailable)
+	}
+}
@@ -0,0 +1,249 @@
+package loginguard
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/security"
+	"todo-api/internal/metrics"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the login guard interface. It watches login attempts for unusual
+// patterns and throttles the addresses and accounts involved.
+type Service interface {
+	// Check returns how long a login attempt for the email from the address must wait,
+	// or zero when it may proceed
+	Check(email, ip string) time.Duration
+	// RecordFailure records a failed login and returns the anomalies it reveals
+	RecordFailure(email, ip string) []*security.Anomaly
+	// RecordSuccess records a successful login and returns the anomalies it reveals
+	RecordSuccess(userID uuid.UUID, email, ip string) []*security.Anomaly
+}
+
+// Locator resolves the location of an address. Impossible travel is only detected when
+// a locator is configured.
+type Locator interface {
+	Locate(ip string) (security.Location, bool)
+}
+
+// failure represents a failed login
+type failure struct {
+	email string
+	ip    string
+	at    time.Time
+}
+
+// login represents a successful login
+type login struct {
+	location security.Location
+	at       time.Time
+}
+
+// service implements the login guard
+type service struct {
+	mu      sync.Mutex
+	cfg     config.LoginGuardConfig
+	locator Locator
+	now     func() time.Time
+
+	failures    []failure            // failures within the window, oldest first
+	flagged     map[string]time.Time // "ip:<ip>" or "account:<email>" to end of throttling
+	lastAttempt map[string]time.Time // last attempt allowed for a flagged key
+	lastLogin   map[uuid.UUID]login  // last located login of each user
+	attempts    *metrics.CounterVec  // login attempts by result
+	anomalies   *metrics.CounterVec  // detected anomalies by type
+}
+
+// NewService creates a new login guard exposing its metrics in the registry
+func NewService(cfg config.LoginGuardConfig, registry *metrics.Registry) Service {
+	return NewServiceWithLocator(cfg, registry, nil)
+}
+
+// NewServiceWithLocator creates a new login guard that also detects impossible travel
+// between logins located by the locator
+func NewServiceWithLocator(cfg config.LoginGuardConfig, registry *metrics.Registry, locator Locator) Service {
+	return &service{
+		cfg:         cfg,
+		locator:     locator,
+		now:         time.Now,
+		flagged:     make(map[string]time.Time),
+		lastAttempt: make(map[string]time.Time),
+		lastLogin:   make(map[uuid.UUID]login),
+		attempts: registry.NewCounterVec("auth_login_attempts_total",
+			"Login attempts by result: success, failure, or throttled.", "result"),
+		anomalies: registry.NewCounterVec("auth_anomalies_total",
+			"Unusual login patterns detected, by type.", "type"),
+	}
+}
+
+// Check returns how long a login attempt must wait. Attempts for flagged accounts or from
+// flagged addresses are limited to one per throttle interval.
+func (s *service) Check(email, ip string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	keys := []string{ipKey(ip), accountKey(email)}
+
+	var wait time.Duration
+	var throttled []string
+	for _, key := range keys {
+		until, ok := s.flagged[key]
+		if !ok {
+			continue
+		}
+		if !now.Before(until) {
+			delete(s.flagged, key)
+			delete(s.lastAttempt, key)
+			continue
+		}
+
+		throttled = append(throttled, key)
+		if last, ok := s.lastAttempt[key]; ok {
+			wait = max(wait, last.Add(s.cfg.ThrottleInterval).Sub(now))
+		}
+	}
+
+	if wait > 0 {
+		s.attempts.Inc("throttled")
+		return wait
+	}
+
+	for _, key := range throttled {
+		s.lastAttempt[key] = now
+	}
+	return 0
+}
+
+// RecordFailure records a failed login. Too many failures from the address flag it and
+// every account it targeted; too many failures for an account flag the account.
+func (s *service) RecordFailure(email, ip string) []*security.Anomaly {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts.Inc("failure")
+
+	now := s.now()
+	email = normalizeEmail(email)
+	s.prune(now)
+	s.failures = append(s.failures, failure{email: email, ip: ip, at: now})
+
+	var anomalies []*security.Anomaly
+
+	var ipFailures int
+	var targeted []string
+	for _, f := range s.failures {
+		if f.ip == ip {
+			ipFailures++
+			if !slices.Contains(targeted, f.email) {
+				targeted = append(targeted, f.email)
+			}
+		}
+	}
+	if exceeds(ipFailures, s.cfg.MaxIPFailures) && !s.isFlagged(ipKey(ip), now) {
+		anomaly := s.flag(security.AnomalyIPFailures, ip, nil, targeted, now)
+		s.flagged[ipKey(ip)] = anomaly.FlaggedUntil
+		anomalies = append(anomalies, anomaly)
+	}
+
+	var accountFailures int
+	for _, f := range s.failures {
+		if f.email == email {
+			accountFailures++
+		}
+	}
+	if exceeds(accountFailures, s.cfg.MaxAccountFailures) && !s.isFlagged(accountKey(email), now) {
+		anomalies = append(anomalies, s.flag(security.AnomalyAccountFailures, ip, nil, []string{email}, now))
+	}
+
+	return anomalies
+}
+
+// RecordSuccess records a successful login, clearing the account's failures. A login
+// from a place the user could not have reached since their previous login flags the
+// account.
+func (s *service) RecordSuccess(userID uuid.UUID, email, ip string) []*security.Anomaly {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts.Inc("success")
+
+	now := s.now()
+	email = normalizeEmail(email)
+	s.failures = slices.DeleteFunc(s.failures, func(f failure) bool { return f.email == email })
+
+	if s.locator == nil {
+		return nil
+	}
+	location, ok := s.locator.Locate(ip)
+	if !ok {
+		return nil
+	}
+
+	previous, seen := s.lastLogin[userID]
+	s.lastLogin[userID] = login{location: location, at: now}
+
+	if !seen || s.cfg.MaxTravelSpeedKmh <= 0 ||
+		security.SpeedKmh(previous.location, location, now.Sub(previous.at)) <= s.cfg.MaxTravelSpeedKmh {
+		return nil
+	}
+
+	return []*security.Anomaly{s.flag(security.AnomalyImpossibleTravel, ip, &userID, []string{email}, now)}
+}
+
+// flag flags the accounts for the flag duration and counts the anomaly
+func (s *service) flag(anomalyType security.AnomalyType, ip string, userID *uuid.UUID, emails []string, now time.Time) *security.Anomaly {
+	anomaly := &security.Anomaly{
+		Type:         anomalyType,
+		IP:           ip,
+		UserID:       userID,
+		Emails:       emails,
+		DetectedAt:   now,
+		FlaggedUntil: now.Add(s.cfg.FlagDuration),
+	}
+
+	for _, email := range emails {
+		s.flagged[accountKey(email)] = anomaly.FlaggedUntil
+	}
+	s.anomalies.Inc(string(anomalyType))
+
+	return anomaly
+}
+
+// isFlagged reports whether the key is flagged at the time
+func (s *service) isFlagged(key string, now time.Time) bool {
+	until, ok := s.flagged[key]
+	return ok && now.Before(until)
+}
+
+// prune forgets failures older than the window
+func (s *service) prune(now time.Time) {
+	cutoff := now.Add(-s.cfg.Window)
+	i := 0
+	for i < len(s.failures) && !s.failures[i].at.After(cutoff) {
+		i++
+	}
+	s.failures = s.failures[i:]
+}
+
+// exceeds reports whether the count reached a limit. A limit of zero disables the check.
+func exceeds(count, limit int) bool {
+	return limit > 0 && count >= limit
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func accountKey(email string) string {
+	return "account:" + normalizeEmail(email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,134 @@
+package loginguard
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/security"
+	"todo-api/internal/metrics"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.LoginGuardConfig{
+	Window:             10 * time.Minute,
+	MaxIPFailures:      4,
+	MaxAccountFailures: 3,
+	FlagDuration:       15 * time.Minute,
+	ThrottleInterval:   30 * time.Second,
+	MaxTravelSpeedKmh:  1000,
+}
+
+// fakeLocator locates addresses from a fixed table
+type fakeLocator map[string]security.Location
+
+func (l fakeLocator) Locate(ip string) (security.Location, bool) {
+	location, ok := l[ip]
+	return location, ok
+}
+
+// newTestService creates a login guard with a clock the test controls
+func newTestService(locator Locator) (*service, *metrics.Registry, *time.Time) {
+	registry := metrics.NewRegistry()
+	s := NewServiceWithLocator(testConfig, registry, locator).(*service)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, registry, &now
+}
+
+func TestService_AccountFailures(t *testing.T) {
+	s, _, now := newTestService(nil)
+
+	assert.Empty(t, s.RecordFailure("john@example.com", "198.51.100.1"))
+	assert.Empty(t, s.RecordFailure("John@example.com", "198.51.100.2"))
+	assert.Zero(t, s.Check("john@example.com", "198.51.100.3"))
+
+	anomalies := s.RecordFailure("john@example.com", "198.51.100.3")
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, security.AnomalyAccountFailures, anomalies[0].Type)
+	assert.Equal(t, []string{"john@example.com"}, anomalies[0].Emails)
+	assert.Equal(t, now.Add(15*time.Minute), anomalies[0].FlaggedUntil)
+
+	// Flagged accounts get one attempt per throttle interval, from any address
+	assert.Zero(t, s.Check("john@example.com", "203.0.113.1"))
+	assert.Equal(t, 30*time.Second, s.Check("JOHN@example.com", "203.0.113.2"))
+	*now = now.Add(10 * time.Second)
+	assert.Equal(t, 20*time.Second, s.Check("john@example.com", "203.0.113.1"))
+	assert.Zero(t, s.Check("jane@example.com", "203.0.113.1"))
+
+	// Further failures do not raise the anomaly again while flagged
+	assert.Empty(t, s.RecordFailure("john@example.com", "203.0.113.1"))
+
+	*now = now.Add(15 * time.Minute)
+	assert.Zero(t, s.Check("john@example.com", "203.0.113.1"))
+	assert.Zero(t, s.Check("john@example.com", "203.0.113.1"))
+}
+
+func TestService_IPFailures(t *testing.T) {
+	s, registry, now := newTestService(nil)
+	attacker := "203.0.113.66"
+
+	s.RecordFailure("a@example.com", attacker)
+	s.RecordFailure("b@example.com", attacker)
+
+	// Failures outside the window are forgotten
+	*now = now.Add(11 * time.Minute)
+	s.RecordFailure("c@example.com", attacker)
+	s.RecordFailure("d@example.com", attacker)
+	s.RecordFailure("e@example.com", attacker)
+
+	anomalies := s.RecordFailure("f@example.com", attacker)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, security.AnomalyIPFailures, anomalies[0].Type)
+	assert.Equal(t, []string{"c@example.com", "d@example.com", "e@example.com", "f@example.com"}, anomalies[0].Emails)
+
+	// The address and every account it targeted are throttled
+	assert.Zero(t, s.Check("g@example.com", attacker))
+	assert.Positive(t, s.Check("h@example.com", attacker))
+	assert.Zero(t, s.Check("c@example.com", "198.51.100.1"))
+	assert.Positive(t, s.Check("c@example.com", "198.51.100.1"))
+	assert.Zero(t, s.Check("a@example.com", "198.51.100.1"))
+
+	// A successful login clears the account's failures
+	s.RecordSuccess(uuid.New(), "g@example.com", "198.51.100.1")
+
+	var out bytes.Buffer
+	require.NoError(t, registry.Write(&out))
+	assert.Contains(t, out.String(), `auth_login_attempts_total{result="failure"} 6`)
+	assert.Contains(t, out.String(), `auth_anomalies_total{type="ip_failures"} 1`)
+	assert.Equal(t, float64(6), s.attempts.Value("failure"))
+	assert.Equal(t, float64(2), s.attempts.Value("throttled"))
+	assert.Equal(t, float64(1), s.anomalies.Value("ip_failures"))
+}
+
+func TestService_ImpossibleTravel(t *testing.T) {
+	locator := fakeLocator{
+		"198.51.100.1": {Latitude: 48.8566, Longitude: 2.3522},   // Paris
+		"198.51.100.2": {Latitude: 51.5074, Longitude: -0.1278},  // London
+		"198.51.100.3": {Latitude: 40.7128, Longitude: -74.0060}, // New York
+	}
+	s, _, now := newTestService(locator)
+	userID := uuid.New()
+
+	assert.Empty(t, s.RecordSuccess(userID, "john@example.com", "198.51.100.1"))
+
+	// Paris to London in an hour is possible, London to New York in an hour is not
+	*now = now.Add(time.Hour)
+	assert.Empty(t, s.RecordSuccess(userID, "john@example.com", "198.51.100.2"))
+	*now = now.Add(time.Hour)
+	anomalies := s.RecordSuccess(userID, "john@example.com", "198.51.100.3")
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, security.AnomalyImpossibleTravel, anomalies[0].Type)
+	assert.Equal(t, userID, *anomalies[0].UserID)
+
+	assert.Zero(t, s.Check("john@example.com", "198.51.100.3"))
+	assert.Positive(t, s.Check("john@example.com", "198.51.100.3"))
+
+	// Unknown addresses are not located
+	*now = now.Add(time.Minute)
+	assert.Empty(t, s.RecordSuccess(userID, "john@example.com", "192.0.2.1"))
+}
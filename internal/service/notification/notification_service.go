@@ -0,0 +1,475 @@
+package notification
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+
+	"github.com/google/uuid"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates are the email templates, with a text and an HTML body each
+var templates = mailer.MustParseTemplates(templateFS, "templates")
+
+// dateFormat is how due dates are shown in emails
+const dateFormat = "Mon, Jan 2 2006 15:04 MST"
+
+// Service defines the notification service interface
+type Service interface {
+	GetPreferences(userID uuid.UUID) *notification.Preferences
+	UpdatePreferences(userID uuid.UUID, req *notification.UpdatePreferencesRequest) *notification.Preferences
+	GetSettings(userID uuid.UUID) notification.Settings
+	UpdateSettings(userID uuid.UUID, req notification.UpdateSettingsRequest) notification.Settings
+	// Allows reports whether the user wants notifications of the event through the channel,
+	// satisfying the notification filter of services notifying users of task events
+	Allows(userID uuid.UUID, event, channel string) bool
+	SendPasswordReset(ctx context.Context, user *auth.User, token string) error
+	SendEmailVerification(ctx context.Context, user *auth.User, token string) error
+	SendReminders(ctx context.Context, now time.Time) (sent int, err error)
+	SendDigests(ctx context.Context, now time.Time) (sent int, err error)
+	// Digest returns the summary of the user's open tasks at the time their digest is sent
+	// with, counting days in their time zone
+	Digest(userID uuid.UUID, now time.Time) *notification.Digest
+	// SendEscalation emails the recipients, copying the copied users, that the overdue task
+	// was escalated by the rule. It reports whether anyone was emailed.
+	SendEscalation(ctx context.Context, t *task.Task, rule string, to, cc []*auth.User, now time.Time) (bool, error)
+	RunReminders(ctx context.Context) error
+	RunDigests(ctx context.Context) error
+}
+
+// Channel delivers reminders through a medium other than email, such as a chat app. It
+// reports whether the reminder was delivered, which it is not to users who did not connect
+// the channel or opted out of reminders through it. Reminders are only sent through the
+// channels the user did not turn off in their notification settings.
+type Channel interface {
+	// NotificationChannel returns the channel in notification settings, such as slack
+	NotificationChannel() string
+	Remind(ctx context.Context, user *auth.User, t *task.Task, now time.Time) (bool, error)
+}
+
+// remindersJob sends the reminders due at the time
+type remindersJob struct {
+	At time.Time `json:"at"`
+}
+
+func (remindersJob) JobType() string { return "notification.reminders" }
+
+// digestJob emails the user their digest, retried on its own so the digests of other users
+// are not sent twice
+type digestJob struct {
+	UserID uuid.UUID `json:"user_id"`
+	At     time.Time `json:"at"`
+}
+
+func (digestJob) JobType() string { return "notification.digest" }
+
+// service implements the notification service
+type service struct {
+	mu          sync.Mutex                              // guards preferences
+	preferences map[uuid.UUID]*notification.Preferences // Mock preferences storage
+	remindMu    sync.Mutex                              // serializes sending reminders
+	reminded    map[uuid.UUID]time.Time                 // due date each task was last reminded of
+	authService authService.Service
+	taskService taskService.Service
+	mailer      mailer.Mailer
+	channels    []Channel
+	jobs        jobs.Queue // runs scheduled reminders and digests, if any
+	config      *config.Config
+}
+
+// Deps are the dependencies of a notification service. Config, Auth, Tasks, and Mailer are
+// required; the others are optional, and a zero value keeps the default noted next to each.
+type Deps struct {
+	Config   *config.Config
+	Auth     authService.Service
+	Tasks    taskService.Service
+	Mailer   mailer.Mailer
+	Channels []Channel // reminders are only emailed
+	// Jobs runs scheduled reminders and digests as jobs, retried when sending fails; they are
+	// sent by the scheduler itself when it is nil
+	Jobs jobs.Queue
+}
+
+// NewService creates a new notification service sending emails through the mailer
+func NewService(cfg *config.Config, authSvc authService.Service, taskSvc taskService.Service, m mailer.Mailer) Service {
+	return NewServiceWithDeps(Deps{Config: cfg, Auth: authSvc, Tasks: taskSvc, Mailer: m})
+}
+
+// NewServiceWithDeps creates a new notification service, using the defaults of Deps for the
+// dependencies left unset
+func NewServiceWithDeps(deps Deps) Service {
+	queue := deps.Jobs
+	s := &service{
+		preferences: make(map[uuid.UUID]*notification.Preferences),
+		reminded:    make(map[uuid.UUID]time.Time),
+		authService: deps.Auth,
+		taskService: deps.Tasks,
+		mailer:      deps.Mailer,
+		channels:    deps.Channels,
+		jobs:        queue,
+		config:      deps.Config,
+	}
+
+	if queue != nil {
+		jobs.Handle(queue, func(ctx context.Context, job remindersJob) error {
+			_, err := s.SendReminders(ctx, job.At)
+			return err
+		})
+		jobs.Handle(queue, func(ctx context.Context, job digestJob) error {
+			user, err := s.authService.GetUserByID(job.UserID)
+			if err != nil {
+				// Erased accounts get no digest
+				return nil
+			}
+			_, err = s.sendDigest(ctx, user, job.At)
+			return err
+		})
+	}
+
+	return s
+}
+
+// GetPreferences returns the notification preferences of the user
+func (s *service) GetPreferences(userID uuid.UUID) *notification.Preferences {
+	prefs := s.preferencesOf(userID)
+	return &prefs
+}
+
+// UpdatePreferences updates the notification preferences of the user
+func (s *service) UpdatePreferences(userID uuid.UUID, req *notification.UpdatePreferencesRequest) *notification.Preferences {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefs := s.storedPreferences(userID)
+	req.Apply(&prefs)
+	s.preferences[userID] = &prefs
+
+	updated := prefs
+	return &updated
+}
+
+// GetSettings returns the notification settings matrix of the user
+func (s *service) GetSettings(userID uuid.UUID) notification.Settings {
+	prefs := s.preferencesOf(userID)
+	return prefs.Settings()
+}
+
+// UpdateSettings updates the notification settings of the user and returns the matrix
+func (s *service) UpdateSettings(userID uuid.UUID, req notification.UpdateSettingsRequest) notification.Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefs := s.storedPreferences(userID)
+	req.Apply(&prefs)
+	s.preferences[userID] = &prefs
+
+	return prefs.Settings()
+}
+
+// Allows reports whether the user wants notifications of the event through the channel
+func (s *service) Allows(userID uuid.UUID, event, channel string) bool {
+	prefs := s.preferencesOf(userID)
+	return prefs.Allows(event, channel)
+}
+
+// preferencesOf returns a copy of the preferences of the user, or the defaults
+func (s *service) preferencesOf(userID uuid.UUID) notification.Preferences {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storedPreferences(userID)
+}
+
+// storedPreferences returns a copy of the preferences of the user, or the defaults. The
+// caller must hold the lock.
+func (s *service) storedPreferences(userID uuid.UUID) notification.Preferences {
+	if prefs, exists := s.preferences[userID]; exists {
+		return *prefs
+	}
+	return *notification.DefaultPreferences()
+}
+
+// SendPasswordReset emails the user a link to choose a new password with the token
+func (s *service) SendPasswordReset(ctx context.Context, user *auth.User, token string) error {
+	return s.send(ctx, "password_reset", user.Email, map[string]string{
+		"Email":     user.Email,
+		"URL":       s.link("/reset-password", url.Values{"token": {token}}),
+		"ExpiresIn": formatTTL(s.config.Account.PasswordResetTTL),
+	})
+}
+
+// SendEmailVerification emails the user a link to verify their address with the token
+func (s *service) SendEmailVerification(ctx context.Context, user *auth.User, token string) error {
+	return s.send(ctx, "email_verification", user.Email, map[string]string{
+		"Email":     user.Email,
+		"URL":       s.link("/verify-email", url.Values{"token": {token}}),
+		"ExpiresIn": formatTTL(s.config.Account.EmailVerificationTTL),
+	})
+}
+
+// taskData is a task as shown in emails
+type taskData struct {
+	Title   string
+	DueDate string
+	Overdue bool
+	URL     string
+}
+
+// newTaskData returns the task as shown in emails sent at the time
+func (s *service) newTaskData(t *task.Task, now time.Time) taskData {
+	data := taskData{
+		Title: t.Title,
+		URL:   s.link("/tasks/"+t.ID.String(), nil),
+	}
+	if t.DueDate != nil {
+		data.DueDate = t.DueDate.UTC().Format(dateFormat)
+		data.Overdue = t.DueDate.Before(now)
+	}
+	return data
+}
+
+// SendReminders sends a reminder about each open task due within the reminder lead time to
+// the task's assignee, or its owner when unassigned, by email and through every channel they
+// connected, unless the recipient turned reminders off for the channel. Each task is reminded of
+// once per due date; a task whose reminder was delivered nowhere is retried on the next run.
+// It returns the number of tasks reminded of.
+func (s *service) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	s.remindMu.Lock()
+	defer s.remindMu.Unlock()
+
+	due := s.taskService.DueTasks(now.Add(s.config.Notify.ReminderLeadTime))
+
+	// Tasks no longer due are forgotten, so they are reminded of again if they come due again
+	reminded := make(map[uuid.UUID]time.Time, len(due))
+	var errs []error
+	sent := 0
+	for _, t := range due {
+		if last, ok := s.reminded[t.ID]; ok && last.Equal(*t.DueDate) {
+			reminded[t.ID] = last
+			continue
+		}
+
+		recipientID := t.UserID
+		if t.AssigneeID != nil {
+			recipientID = *t.AssigneeID
+		}
+		recipient, err := s.authService.GetUserByID(recipientID)
+		if err != nil {
+			// Tasks of erased accounts have no owner to remind
+			continue
+		}
+
+		prefs := s.preferencesOf(recipientID)
+		delivered := false
+		if prefs.Allows(notification.EventDueSoon, notification.ChannelEmail) {
+			if err := s.send(ctx, "reminder", recipient.Email, s.newTaskData(t, now)); err != nil {
+				errs = append(errs, fmt.Errorf("task %s: %w", t.ID, err))
+			} else {
+				delivered = true
+			}
+		}
+		for _, channel := range s.channels {
+			if !prefs.Allows(notification.EventDueSoon, channel.NotificationChannel()) {
+				continue
+			}
+			ok, err := channel.Remind(ctx, recipient, t, now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("task %s: %w", t.ID, err))
+			}
+			delivered = delivered || ok
+		}
+
+		if delivered {
+			reminded[t.ID] = *t.DueDate
+			sent++
+		}
+	}
+	s.reminded = reminded
+
+	return sent, errors.Join(errs...)
+}
+
+// SendDigests emails every user who did not turn digests off a summary of their open tasks.
+// Users without open tasks are skipped. It returns the number of digests sent.
+func (s *service) SendDigests(ctx context.Context, now time.Time) (int, error) {
+	var errs []error
+	sent := 0
+	for _, user := range s.authService.ListUsers() {
+		ok, err := s.sendDigest(ctx, user, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", user.ID, err))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// Digest returns the open tasks of the user due today or overdue
+func (s *service) Digest(userID uuid.UUID, now time.Time) *notification.Digest {
+	prefs := s.preferencesOf(userID)
+	return notification.NewDigest(s.taskService.OpenTasks(userID), now, prefs.Location())
+}
+
+// sendDigest emails the user a summary of their open tasks, unless they turned digests off
+// or have no open task. It reports whether the digest was sent.
+func (s *service) sendDigest(ctx context.Context, user *auth.User, now time.Time) (bool, error) {
+	prefs := s.preferencesOf(user.ID)
+	if !prefs.Digest {
+		return false, nil
+	}
+
+	open := s.taskService.OpenTasks(user.ID)
+	if len(open) == 0 {
+		return false, nil
+	}
+	tasks := make([]taskData, len(open))
+	for i, t := range open {
+		tasks[i] = s.newTaskData(t, now)
+	}
+	digest := notification.NewDigest(open, now, prefs.Location())
+
+	if err := s.send(ctx, "digest", user.Email, map[string]interface{}{
+		"Tasks":    tasks,
+		"Overdue":  len(digest.Overdue),
+		"DueToday": len(digest.DueToday),
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SendEscalation emails the recipients that the overdue task was escalated by the rule, with
+// the copied users in copy. Users who turned escalation emails off are left out; when every
+// recipient did, the copied users are emailed directly.
+func (s *service) SendEscalation(ctx context.Context, t *task.Task, rule string, to, cc []*auth.User, now time.Time) (bool, error) {
+	recipients, copied := s.escalationEmails(to), s.escalationEmails(cc)
+	if len(recipients) == 0 {
+		recipients, copied = copied, nil
+	}
+	if len(recipients) == 0 {
+		return false, nil
+	}
+
+	msg, err := templates.Render("escalation", recipients[0], struct {
+		taskData
+		Rule string
+	}{s.newTaskData(t, now), rule})
+	if err != nil {
+		return false, err
+	}
+	msg.To = recipients
+	msg.Cc = copied
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// escalationEmails returns the addresses of the users who did not turn escalation emails off
+func (s *service) escalationEmails(users []*auth.User) []string {
+	var emails []string
+	for _, user := range users {
+		prefs := s.preferencesOf(user.ID)
+		if prefs.Allows(notification.EventEscalated, notification.ChannelEmail) {
+			emails = append(emails, user.Email)
+		}
+	}
+	return emails
+}
+
+// RunReminders sends the reminders due now, or with a job queue, enqueues a job sending
+// them. It is run by the scheduler.
+func (s *service) RunReminders(ctx context.Context) error {
+	now := time.Now()
+	if s.jobs != nil {
+		if _, err := s.jobs.Enqueue(remindersJob{At: now}); err != nil {
+			return fmt.Errorf("failed to enqueue reminders: %w", err)
+		}
+		return nil
+	}
+
+	if sent, err := s.SendReminders(ctx, now); err != nil {
+		return fmt.Errorf("%d reminders sent: %w", sent, err)
+	}
+	return nil
+}
+
+// RunDigests sends the digests, or with a job queue, enqueues a job sending the digest of
+// each user who did not turn digests off. It is run by the scheduler.
+func (s *service) RunDigests(ctx context.Context) error {
+	now := time.Now()
+	if s.jobs == nil {
+		if sent, err := s.SendDigests(ctx, now); err != nil {
+			return fmt.Errorf("%d digests sent: %w", sent, err)
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, user := range s.authService.ListUsers() {
+		if !s.preferencesOf(user.ID).Digest {
+			continue
+		}
+		if _, err := s.jobs.Enqueue(digestJob{UserID: user.ID, At: now}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to enqueue the digest of user %s: %w", user.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send renders the template and sends the message to the recipient
+func (s *service) send(ctx context.Context, name, to string, data interface{}) error {
+	msg, err := templates.Render(name, to, data)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+// link returns the URL of the path of the application, with the query
+func (s *service) link(path string, query url.Values) string {
+	link := s.config.App.BaseURL + path
+	if len(query) > 0 {
+		link += "?" + query.Encode()
+	}
+	return link
+}
+
+// formatTTL formats how long a link is valid, such as 1 hour or 30 minutes
+func formatTTL(ttl time.Duration) string {
+	switch {
+	case ttl >= time.Hour && ttl%time.Hour == 0:
+		return plural(int(ttl/time.Hour), "hour")
+	case ttl >= time.Minute && ttl%time.Minute == 0:
+		return plural(int(ttl/time.Minute), "minute")
+	default:
+		return ttl.String()
+	}
+}
+
+// plural formats the count with the unit, pluralized unless the count is one
+func plural(count int, unit string) string {
+	if count == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
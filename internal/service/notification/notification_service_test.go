@@ -0,0 +1,259 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/notification"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/mailer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestService(t *testing.T) (Service, authService.Service, taskService.Service, *mailer.MemoryMailer) {
+	cfg := &config.Config{
+		App:     config.AppConfig{BaseURL: "https://todo.example.com"},
+		Notify:  config.NotificationsConfig{ReminderLeadTime: 24 * time.Hour},
+		Account: config.AccountConfig{PasswordResetTTL: time.Hour, EmailVerificationTTL: 24 * time.Hour},
+	}
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	m := mailer.NewMemoryMailer()
+	return NewService(cfg, authSvc, taskSvc, m), authSvc, taskSvc, m
+}
+
+func TestService_SendPasswordReset(t *testing.T) {
+	service, authSvc, _, m := setupTestService(t)
+	user, token, err := authSvc.CreatePasswordResetToken("john.doe@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, service.SendPasswordReset(context.Background(), user, token))
+
+	messages := m.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"john.doe@example.com"}, messages[0].To)
+	assert.Equal(t, "Reset your Todo API password", messages[0].Subject)
+	assert.Contains(t, messages[0].Text, "https://todo.example.com/reset-password?token="+token)
+	assert.Contains(t, messages[0].Text, "within 1 hour")
+	assert.Contains(t, messages[0].HTML, `<a href="https://todo.example.com/reset-password?token=`+token+`">`)
+}
+
+func TestService_SendReminders(t *testing.T) {
+	service, authSvc, taskSvc, m := setupTestService(t)
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	jane, _ := authSvc.GetUserByEmail("jane.smith@example.com")
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	later := now.Add(72 * time.Hour)
+
+	dueSoon, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Ship <release>", DueDate: &soon}, john.ID)
+	require.NoError(t, err)
+	_, err = taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Plan next quarter", DueDate: &later}, john.ID)
+	require.NoError(t, err)
+	assigned, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Review release notes", DueDate: &soon}, john.ID)
+	require.NoError(t, err)
+	_, err = taskSvc.AssignTask(assigned.ID, &task.AssignTaskRequest{UserID: &jane.ID}, john.ID)
+	require.NoError(t, err)
+
+	// Reminders go to the assignee, or the owner of unassigned tasks
+	sent, err := service.SendReminders(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	recipients := map[string]string{}
+	for _, msg := range m.Messages() {
+		recipients[msg.Subject] = msg.To[0]
+	}
+	assert.Equal(t, map[string]string{
+		"Due soon: Ship <release>":       "john.doe@example.com",
+		"Due soon: Review release notes": "jane.smith@example.com",
+	}, recipients)
+	assert.Contains(t, m.Messages()[0].HTML+m.Messages()[1].HTML, "Ship &lt;release&gt;")
+
+	// Tasks are reminded of once per due date
+	sent, err = service.SendReminders(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	newDue := soon.Add(time.Minute)
+	_, err = taskSvc.UpdateTask(dueSoon.ID, &task.UpdateTaskRequest{DueDate: &newDue}, john.ID)
+	require.NoError(t, err)
+	off := false
+	service.UpdatePreferences(jane.ID, &notification.UpdatePreferencesRequest{Reminders: &off})
+	_, err = taskSvc.UpdateTask(assigned.ID, &task.UpdateTaskRequest{DueDate: &newDue}, john.ID)
+	require.NoError(t, err)
+
+	// Users who turned reminders off are not reminded
+	sent, err = service.SendReminders(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, m.Messages(), 3)
+}
+
+// recordingChannel records the tasks it reminds users of
+type recordingChannel struct {
+	name     string
+	reminded []string
+}
+
+func (c *recordingChannel) NotificationChannel() string { return c.name }
+
+func (c *recordingChannel) Remind(_ context.Context, user *auth.User, t *task.Task, _ time.Time) (bool, error) {
+	c.reminded = append(c.reminded, user.Email+": "+t.Title)
+	return true, nil
+}
+
+func TestService_SendReminders_Settings(t *testing.T) {
+	cfg := &config.Config{Notify: config.NotificationsConfig{ReminderLeadTime: 24 * time.Hour}}
+	authSvc := authService.NewService(cfg)
+	taskSvc := taskService.NewService(authSvc)
+	m := mailer.NewMemoryMailer()
+	slack := &recordingChannel{name: notification.ChannelSlack}
+	push := &recordingChannel{name: notification.ChannelPush}
+	service := NewServiceWithDeps(Deps{Config: cfg, Auth: authSvc, Tasks: taskSvc, Mailer: m, Channels: []Channel{slack, push}})
+
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	_, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: "Ship release", DueDate: &soon}, john.ID)
+	require.NoError(t, err)
+
+	settings := service.UpdateSettings(john.ID, notification.UpdateSettingsRequest{
+		notification.EventDueSoon: {notification.ChannelEmail: false, notification.ChannelSlack: false},
+	})
+	assert.False(t, settings[notification.EventDueSoon][notification.ChannelEmail])
+	assert.True(t, settings[notification.EventDueSoon][notification.ChannelPush])
+	assert.False(t, service.GetPreferences(john.ID).Reminders)
+
+	// Reminders are only sent through the channels left on
+	sent, err := service.SendReminders(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Empty(t, m.Messages())
+	assert.Empty(t, slack.reminded)
+	assert.Equal(t, []string{"john.doe@example.com: Ship release"}, push.reminded)
+	assert.False(t, service.Allows(john.ID, notification.EventDueSoon, notification.ChannelSlack))
+}
+
+func TestService_SendDigests(t *testing.T) {
+	service, authSvc, _, m := setupTestService(t)
+	mike, _ := authSvc.GetUserByEmail("mike.wilson@example.com")
+	off := false
+	service.UpdatePreferences(mike.ID, &notification.UpdatePreferencesRequest{Digest: &off})
+
+	sent, err := service.SendDigests(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	// Only John, Jane, and Alice have open fixture tasks, and Mike turned digests off
+	recipients := make([]string, 0, sent)
+	for _, msg := range m.Messages() {
+		recipients = append(recipients, msg.To[0])
+		assert.True(t, strings.HasPrefix(msg.Subject, "You have "))
+	}
+	assert.ElementsMatch(t, []string{"john.doe@example.com", "jane.smith@example.com", "alice@acme.example.com"}, recipients)
+
+	prefs := service.GetPreferences(mike.ID)
+	assert.True(t, prefs.Reminders)
+	assert.False(t, prefs.Digest)
+}
+
+func TestService_Digest(t *testing.T) {
+	service, authSvc, taskSvc, m := setupTestService(t)
+	mike, _ := authSvc.GetUserByEmail("mike.wilson@example.com")
+	morning := time.Date(2099, 1, 15, 3, 0, 0, 0, time.UTC)
+	evening := time.Date(2099, 1, 15, 20, 0, 0, 0, time.UTC)
+	for title, due := range map[string]time.Time{"Standup notes": morning, "Pay rent": evening} {
+		_, err := taskSvc.CreateTask(&task.CreateTaskRequest{Title: title, DueDate: &due}, mike.ID)
+		require.NoError(t, err)
+	}
+	now := time.Date(2099, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	digest := service.Digest(mike.ID, now)
+	assert.Equal(t, "2099-01-15", digest.Date)
+	require.Len(t, digest.Overdue, 1)
+	assert.Equal(t, "Standup notes", digest.Overdue[0].Title)
+	require.Len(t, digest.DueToday, 1)
+	assert.Equal(t, "Pay rent", digest.DueToday[0].Title)
+
+	// Days are counted in the time zone of the user, where the evening is already tomorrow
+	zone := "Asia/Jakarta"
+	service.UpdatePreferences(mike.ID, &notification.UpdatePreferencesRequest{TimeZone: &zone})
+	digest = service.Digest(mike.ID, now)
+	assert.Equal(t, "2099-01-15", digest.Date)
+	assert.Empty(t, digest.DueToday)
+
+	// Digest emails lead with the same summary
+	_, err := service.SendDigests(context.Background(), now)
+	require.NoError(t, err)
+	var text string
+	for _, msg := range m.Messages() {
+		if msg.To[0] == mike.Email {
+			text = msg.Text
+		}
+	}
+	assert.Contains(t, text, "Here are your open tasks, 1 overdue and 0 due today:")
+}
+
+// flakyMailer fails its first send
+type flakyMailer struct {
+	*mailer.MemoryMailer
+	failed bool
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	if !m.failed {
+		m.failed = true
+		return errors.New("connection reset")
+	}
+	return m.MemoryMailer.Send(ctx, msg)
+}
+
+func TestService_DigestJobs(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "https://todo.example.com"}}
+	authSvc := authService.NewService(cfg)
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 3, RetryBackoff: time.Millisecond, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	m := &flakyMailer{MemoryMailer: mailer.NewMemoryMailer()}
+	NewServiceWithDeps(Deps{Config: cfg, Auth: authSvc, Tasks: taskService.NewService(authSvc), Mailer: m, Jobs: queue})
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+
+	// Digests that fail to send are retried
+	job, err := queue.Enqueue(digestJob{UserID: john.ID, At: time.Now()})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, err = queue.Get(job.ID)
+		return err == nil && job.Done()
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, jobs.StatusSucceeded, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+	require.Len(t, m.Messages(), 1)
+	assert.Equal(t, []string{"john.doe@example.com"}, m.Messages()[0].To)
+}
+
+func TestService_RunDigests(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "https://todo.example.com"}}
+	authSvc := authService.NewService(cfg)
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	svc := NewServiceWithDeps(Deps{Config: cfg, Auth: authSvc, Tasks: taskService.NewService(authSvc), Mailer: mailer.NewMemoryMailer(),
+		Jobs: queue})
+
+	// One digest job is enqueued per user who did not turn digests off
+	john, _ := authSvc.GetUserByEmail("john.doe@example.com")
+	digest := false
+	svc.UpdatePreferences(john.ID, &notification.UpdatePreferencesRequest{Digest: &digest})
+	require.NoError(t, svc.RunDigests(context.Background()))
+	require.Eventually(t, func() bool {
+		return queue.Stats().Succeeded == len(authSvc.ListUsers())-1
+	}, time.Second, time.Millisecond)
+	require.NoError(t, svc.RunReminders(context.Background()))
+}
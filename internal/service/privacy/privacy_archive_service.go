@@ -0,0 +1,211 @@
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"time"
+
+	"todo-api/internal/domain/privacy"
+	"todo-api/internal/jobs"
+
+	"github.com/google/uuid"
+)
+
+// archiveFailedMessage is the error of archives whose job failed; the cause is only logged,
+// as it may describe the storage
+const archiveFailedMessage = "export failed, start another export"
+
+// archiveJob builds the archive of a user's data and uploads it to storage
+type archiveJob struct {
+	ArchiveID uuid.UUID `json:"archive_id"`
+}
+
+func (archiveJob) JobType() string { return "privacy.archive" }
+
+// expireArchiveJob deletes an archive from storage once it expires
+type expireArchiveJob struct {
+	ArchiveID uuid.UUID `json:"archive_id"`
+}
+
+func (expireArchiveJob) JobType() string { return "privacy.archive_expiry" }
+
+// StartArchive starts building an archive of the user's data
+func (s *service) StartArchive(userID uuid.UUID) (*privacy.Archive, error) {
+	if s.store == nil || s.jobs == nil {
+		return nil, privacy.ErrArchivesUnavailable
+	}
+	if _, err := s.authService.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.archives {
+		if a.UserID == userID && (a.Status == privacy.ArchivePending || a.Status == privacy.ArchiveRunning) {
+			s.refreshArchive(a)
+			if a.Status != privacy.ArchiveFailed {
+				started := *a
+				return &started, nil
+			}
+		}
+	}
+
+	// The job waits for the lock, so the archive is kept before it runs
+	a := privacy.NewArchive(userID)
+	job, err := s.jobs.Enqueue(archiveJob{ArchiveID: a.ID}, jobs.WithOwner(userID))
+	if err != nil {
+		return nil, privacy.ErrArchivesUnavailable
+	}
+	a.JobID = job.ID
+	s.archives[a.ID] = a
+
+	started := *a
+	return &started, nil
+}
+
+// GetArchive returns an archive of the user. Ready archives come with a URL they are
+// downloaded from until they expire.
+func (s *service) GetArchive(id, userID uuid.UUID) (*privacy.Archive, error) {
+	s.mu.Lock()
+	a, ok := s.archives[id]
+	if !ok || a.UserID != userID {
+		s.mu.Unlock()
+		return nil, privacy.ErrArchiveNotFound
+	}
+	s.refreshArchive(a)
+	archive := *a
+	s.mu.Unlock()
+
+	if archive.Status == privacy.ArchiveReady {
+		download, err := s.store.PresignDownload(archive.Key, archive.Filename(), time.Until(*archive.ExpiresAt))
+		if err != nil {
+			log.Printf("Failed to presign data export %s: %v", archive.ID, err)
+			return nil, privacy.ErrArchivesUnavailable
+		}
+		archive.DownloadURL = download.URL
+	}
+	return &archive, nil
+}
+
+// refreshArchive updates the status of an archive whose job failed or that expired. The
+// lock must be held.
+func (s *service) refreshArchive(a *privacy.Archive) {
+	switch a.Status {
+	case privacy.ArchivePending, privacy.ArchiveRunning:
+		job, err := s.jobs.Get(a.JobID)
+		if err != nil || job.Status == jobs.StatusFailed || job.Status == jobs.StatusDead {
+			a.Status = privacy.ArchiveFailed
+			a.Error = archiveFailedMessage
+		}
+	case privacy.ArchiveReady:
+		// Until the expiry job deletes it
+		if !time.Now().Before(*a.ExpiresAt) {
+			a.Status = privacy.ArchiveExpired
+		}
+	}
+}
+
+// buildArchive exports the data of the user of an archive and uploads it to storage,
+// scheduling its deletion once it expires
+func (s *service) buildArchive(ctx context.Context, id uuid.UUID) error {
+	userID, key, ok := s.updateArchive(id, func(a *privacy.Archive) {
+		a.Status = privacy.ArchiveRunning
+	})
+	if !ok {
+		// The account was erased in the meantime
+		return nil
+	}
+
+	export, err := s.ExportUserData(userID)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	var data bytes.Buffer
+	if err := export.WriteZip(&data); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	// Archives are pending again between attempts
+	if err := s.store.Upload(ctx, key, "application/zip", data.Bytes()); err != nil {
+		log.Printf("Failed to upload data export %s: %v", id, err)
+		s.updateArchive(id, func(a *privacy.Archive) {
+			a.Status = privacy.ArchivePending
+		})
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.archiveTTL)
+	if _, _, ok := s.updateArchive(id, func(a *privacy.Archive) {
+		a.Status = privacy.ArchiveReady
+		a.Size = int64(data.Len())
+		a.CompletedAt = &now
+		a.ExpiresAt = &expiresAt
+	}); !ok {
+		return s.store.Delete(ctx, key)
+	}
+
+	// Archives left behind when the expiry job is lost, such as on restart, are orphaned but
+	// never served again
+	if _, err := s.jobs.Enqueue(expireArchiveJob{ArchiveID: id}, jobs.WithDelay(s.archiveTTL)); err != nil {
+		log.Printf("Failed to schedule the expiry of data export %s: %v", id, err)
+	}
+	return nil
+}
+
+// expireArchive deletes an expired archive from storage
+func (s *service) expireArchive(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	a, ok := s.archives[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	key := a.Key
+	s.mu.Unlock()
+
+	if err := s.store.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	s.updateArchive(id, func(a *privacy.Archive) {
+		a.Status = privacy.ArchiveExpired
+	})
+	return nil
+}
+
+// updateArchive applies the update to a kept archive, returning its user and key, or false
+// when it is no longer kept
+func (s *service) updateArchive(id uuid.UUID, update func(a *privacy.Archive)) (uuid.UUID, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.archives[id]
+	if !ok {
+		return uuid.Nil, "", false
+	}
+	update(a)
+	return a.UserID, a.Key, true
+}
+
+// deleteArchives forgets the archives of an erased user and deletes them from storage,
+// logging failures
+func (s *service) deleteArchives(userID uuid.UUID) {
+	s.mu.Lock()
+	var keys []string
+	for id, a := range s.archives {
+		if a.UserID == userID {
+			delete(s.archives, id)
+			keys = append(keys, a.Key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, key := range keys {
+		if err := s.store.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete data export %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package privacy
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/attachment"
+	"todo-api/internal/domain/privacy"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/jobs"
+	attachmentService "todo-api/internal/service/attachment"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/blob"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the privacy service interface, handling data export and erasure requests
+type Service interface {
+	ExportUserData(userID uuid.UUID) (*privacy.Export, error)
+	// StartArchive starts building a ZIP archive of the user's data in the background. An
+	// archive of the user still being built is returned instead of starting another.
+	StartArchive(userID uuid.UUID) (*privacy.Archive, error)
+	// GetArchive returns an archive of the user, with its download URL once ready
+	GetArchive(id, userID uuid.UUID) (*privacy.Archive, error)
+	EraseUser(userID uuid.UUID) (*privacy.ErasureRecord, error)
+	ListErasures() []*privacy.ErasureRecord
+	// RegisterEraser adds a service whose data of users is erased with their accounts
+	RegisterEraser(eraser Eraser)
+}
+
+// Eraser is implemented by services keeping data of users outside of their accounts and
+// tasks, such as integrations, device tokens, and API keys
+type Eraser interface {
+	// DeleteUserData deletes everything the service keeps about the user
+	DeleteUserData(userID uuid.UUID)
+}
+
+// service implements the privacy service
+type service struct {
+	mu                sync.Mutex
+	erasures          []*privacy.ErasureRecord // Mock erasure record storage, oldest first
+	erasers           []Eraser
+	archives          map[uuid.UUID]*privacy.Archive
+	authService       authService.Service
+	taskService       taskService.Service
+	workspaceService  workspaceService.Service
+	attachmentService attachmentService.Service // optional, exports the attachments of the user
+	store             blob.Store                // keeps archives, if any
+	jobs              jobs.Queue                // builds and expires archives, if any
+	archiveTTL        time.Duration
+}
+
+// Deps are the dependencies of a privacy service. Attachments, Store, and Jobs are
+// optional, and a zero value keeps the default noted next to each.
+type Deps struct {
+	Auth        authService.Service
+	Tasks       taskService.Service
+	Workspaces  workspaceService.Service
+	Attachments attachmentService.Service // exports leave attachments out
+	// Store keeps the archives of exports, built with jobs of Jobs and kept for ArchiveTTL;
+	// archives are unavailable without both
+	Store      blob.Store
+	Jobs       jobs.Queue
+	ArchiveTTL time.Duration
+}
+
+// NewService creates a new privacy service
+func NewService(authSvc authService.Service, taskSvc taskService.Service, workspaceSvc workspaceService.Service) Service {
+	return NewServiceWithDeps(Deps{Auth: authSvc, Tasks: taskSvc, Workspaces: workspaceSvc})
+}
+
+// NewServiceWithDeps creates a new privacy service, using the defaults of Deps for the
+// dependencies left unset
+func NewServiceWithDeps(deps Deps) Service {
+	store, queue := deps.Store, deps.Jobs
+	s := &service{
+		archives:          make(map[uuid.UUID]*privacy.Archive),
+		authService:       deps.Auth,
+		taskService:       deps.Tasks,
+		workspaceService:  deps.Workspaces,
+		attachmentService: deps.Attachments,
+		store:             store,
+		jobs:              queue,
+		archiveTTL:        deps.ArchiveTTL,
+	}
+
+	if store != nil && queue != nil {
+		jobs.Handle(queue, func(ctx context.Context, job archiveJob) error {
+			return s.buildArchive(ctx, job.ArchiveID)
+		})
+		jobs.Handle(queue, func(ctx context.Context, job expireArchiveJob) error {
+			return s.expireArchive(ctx, job.ArchiveID)
+		})
+	}
+
+	return s
+}
+
+// ExportUserData collects all the data kept about a user
+func (s *service) ExportUserData(userID uuid.UUID) (*privacy.Export, error) {
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, entries := s.taskService.ExportUserData(userID)
+
+	export := &privacy.Export{
+		ExportedAt: time.Now(),
+		Profile: privacy.Profile{
+			ID:        user.ID,
+			Email:     user.Email,
+			TenantID:  user.TenantID,
+			CreatedAt: user.CreatedAt,
+		},
+		Tasks:       append(make([]*task.Task, 0, len(tasks)), tasks...),
+		Activity:    append(make([]*activity.Entry, 0, len(entries)), entries...),
+		Workspaces:  []privacy.Membership{},
+		Projects:    []*workspace.Project{},
+		Attachments: []*attachment.Attachment{},
+	}
+
+	for _, w := range s.workspaceService.ListWorkspaces(userID) {
+		member, err := s.workspaceService.GetMember(w.ID, userID)
+		if err != nil {
+			continue
+		}
+		export.Workspaces = append(export.Workspaces, privacy.Membership{
+			WorkspaceID:   w.ID,
+			WorkspaceName: w.Name,
+			Role:          member.Role,
+			JoinedAt:      member.JoinedAt,
+		})
+
+		for _, project := range s.workspaceService.ListProjects(w.ID) {
+			if project.CreatedBy == userID {
+				export.Projects = append(export.Projects, project)
+			}
+		}
+	}
+
+	if s.attachmentService != nil {
+		export.Attachments = s.attachmentService.ListUserAttachments(userID)
+	}
+
+	return export, nil
+}
+
+// EraseUser erases a user's account and data, recording the erasure. It fails without
+// changing anything when the user owns a workspace with other members.
+func (s *service) EraseUser(userID uuid.UUID) (*privacy.ErasureRecord, error) {
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedWorkspaces, err := s.workspaceService.RemoveUser(userID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	record := privacy.NewErasureRecord(userID, user.TenantID)
+	record.WorkspacesDeleted = len(deletedWorkspaces)
+	record.TasksDeleted, record.TasksAnonymized = s.taskService.EraseUserData(userID, deletedWorkspaces)
+	s.mu.Lock()
+	erasers := s.erasers
+	s.mu.Unlock()
+	for _, eraser := range erasers {
+		eraser.DeleteUserData(userID)
+	}
+
+	if err := s.authService.DeleteUser(userID); err != nil {
+		return nil, err
+	}
+	record.ErasedAt = time.Now()
+	s.deleteArchives(userID)
+
+	s.mu.Lock()
+	s.erasures = append(s.erasures, record)
+	s.mu.Unlock()
+
+	log.Printf("Erased account %s: %d tasks deleted, %d tasks anonymized, %d workspaces deleted",
+		userID, record.TasksDeleted, record.TasksAnonymized, record.WorkspacesDeleted)
+
+	return record, nil
+}
+
+// RegisterEraser adds a service whose data of users is erased with their accounts
+func (s *service) RegisterEraser(eraser Eraser) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.erasers = append(s.erasers, eraser)
+}
+
+// ListErasures returns the erasure records, oldest first
+func (s *service) ListErasures() []*privacy.ErasureRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*privacy.ErasureRecord(nil), s.erasures...)
+}
@@ -0,0 +1,203 @@
+package privacy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/privacy"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	"todo-api/internal/jobs"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/blob"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	janeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+)
+
+func setupTestService(t *testing.T) (Service, authService.Service, workspaceService.Service) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := authService.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	tasks := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces})
+
+	return NewService(authSvc, tasks, workspaces), authSvc, workspaces
+}
+
+func TestService_ExportUserData(t *testing.T) {
+	service, _, workspaces := setupTestService(t)
+
+	created, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	project, err := workspaces.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "Launch"}, johnID)
+	require.NoError(t, err)
+
+	export, err := service.ExportUserData(johnID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", export.Profile.Email)
+	assert.Len(t, export.Tasks, 3)
+	assert.Empty(t, export.Activity)
+	require.Len(t, export.Workspaces, 1)
+	assert.Equal(t, created.ID, export.Workspaces[0].WorkspaceID)
+	assert.Equal(t, workspace.RoleOwner, export.Workspaces[0].Role)
+	require.Len(t, export.Projects, 1)
+	assert.Equal(t, project.ID, export.Projects[0].ID)
+	assert.Empty(t, export.Attachments)
+
+	_, err = service.ExportUserData(uuid.New())
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestService_EraseUser(t *testing.T) {
+	service, authSvc, workspaces := setupTestService(t)
+
+	shared, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, janeID)
+	require.NoError(t, err)
+	owner, _ := workspaces.GetMember(shared.ID, janeID)
+	invitation, err := workspaces.InviteMember(shared.ID, &workspace.InviteMemberRequest{Email: "john.doe@example.com"}, owner)
+	require.NoError(t, err)
+	_, err = workspaces.AcceptInvitation(invitation.ID, johnID, "john.doe@example.com")
+	require.NoError(t, err)
+
+	// Owners of workspaces with other members must hand them over first
+	_, err = service.EraseUser(janeID)
+	assert.EqualError(t, err, "account owns workspaces with other members")
+	_, err = authSvc.GetUserByID(janeID)
+	require.NoError(t, err)
+	assert.Empty(t, service.ListErasures())
+
+	require.NoError(t, workspaces.RemoveMember(shared.ID, johnID, owner))
+
+	record, err := service.EraseUser(janeID)
+	require.NoError(t, err)
+	assert.Equal(t, janeID, record.UserID)
+	assert.Equal(t, 2, record.TasksDeleted)
+	assert.Equal(t, 0, record.TasksAnonymized)
+	assert.Equal(t, 1, record.WorkspacesDeleted)
+	assert.Equal(t, []*privacy.ErasureRecord{record}, service.ListErasures())
+
+	// The account is gone
+	_, err = authSvc.Login(&auth.LoginRequest{Email: "jane.smith@example.com", Password: "password123"})
+	assert.Error(t, err)
+	_, err = service.EraseUser(janeID)
+	assert.EqualError(t, err, "user not found")
+}
+
+// fakeEraser records the users whose data it was asked to delete
+type fakeEraser struct {
+	erased []uuid.UUID
+}
+
+func (e *fakeEraser) DeleteUserData(userID uuid.UUID) {
+	e.erased = append(e.erased, userID)
+}
+
+func TestService_EraseUser_Erasers(t *testing.T) {
+	service, _, workspaces := setupTestService(t)
+	eraser := &fakeEraser{}
+	service.RegisterEraser(eraser)
+
+	// Nothing is erased when the user cannot be
+	shared, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, janeID)
+	require.NoError(t, err)
+	owner, _ := workspaces.GetMember(shared.ID, janeID)
+	invitation, err := workspaces.InviteMember(shared.ID, &workspace.InviteMemberRequest{Email: "john.doe@example.com"}, owner)
+	require.NoError(t, err)
+	_, err = workspaces.AcceptInvitation(invitation.ID, johnID, "john.doe@example.com")
+	require.NoError(t, err)
+	_, err = service.EraseUser(janeID)
+	require.Error(t, err)
+	assert.Empty(t, eraser.erased)
+
+	_, err = service.EraseUser(johnID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{johnID}, eraser.erased)
+}
+
+func TestService_Archives(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute}}
+	authSvc := authService.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	tasks := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces})
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, Retention: time.Hour})
+	defer queue.Shutdown(context.Background())
+	store := blob.NewMemoryStore()
+	service := NewServiceWithDeps(Deps{Auth: authSvc, Tasks: tasks, Workspaces: workspaces, Store: store, Jobs: queue,
+		ArchiveTTL: 100 * time.Millisecond})
+
+	// ready waits for the archive to be built
+	ready := func(id uuid.UUID) *privacy.Archive {
+		var archive *privacy.Archive
+		require.Eventually(t, func() bool {
+			var err error
+			archive, err = service.GetArchive(id, johnID)
+			return err == nil && archive.Status != privacy.ArchivePending && archive.Status != privacy.ArchiveRunning
+		}, time.Second, time.Millisecond)
+		return archive
+	}
+
+	started, err := service.StartArchive(johnID)
+	require.NoError(t, err)
+	assert.Equal(t, privacy.ArchivePending, started.Status)
+
+	archive := ready(started.ID)
+	assert.Equal(t, privacy.ArchiveReady, archive.Status)
+	assert.Positive(t, archive.Size)
+	assert.Contains(t, archive.DownloadURL, archive.Key)
+	require.NotNil(t, archive.ExpiresAt)
+	assert.True(t, store.Has(archive.Key))
+
+	// Archives are only shown to their user
+	_, err = service.GetArchive(started.ID, janeID)
+	assert.ErrorIs(t, err, privacy.ErrArchiveNotFound)
+
+	// Expired archives are deleted
+	require.Eventually(t, func() bool { return !store.Has(archive.Key) }, time.Second, time.Millisecond)
+	expired, err := service.GetArchive(started.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, privacy.ArchiveExpired, expired.Status)
+	assert.Empty(t, expired.DownloadURL)
+
+	// Archives are erased with the account
+	again, err := service.StartArchive(johnID)
+	require.NoError(t, err)
+	archive = ready(again.ID)
+	require.Equal(t, privacy.ArchiveReady, archive.Status)
+	_, err = service.EraseUser(johnID)
+	require.NoError(t, err)
+	assert.False(t, store.Has(archive.Key))
+	_, err = service.GetArchive(again.ID, johnID)
+	assert.ErrorIs(t, err, privacy.ErrArchiveNotFound)
+	_, err = service.StartArchive(johnID)
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestService_Archives_Unavailable(t *testing.T) {
+	service, _, _ := setupTestService(t)
+
+	_, err := service.StartArchive(johnID)
+	assert.ErrorIs(t, err, privacy.ErrArchivesUnavailable)
+}
@@ -0,0 +1,211 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/report"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/jobs"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/webhook"
+
+	"github.com/google/uuid"
+)
+
+// ErrProjectNotFound is returned for projects that are not in the workspace
+var ErrProjectNotFound = errors.New("project not found")
+
+// Service defines the report service interface. It serves the burndown of projects from
+// days aggregated by a nightly job, so only the days not aggregated yet are computed on
+// request, and pushes task statistics to the stats webhooks of users.
+type Service interface {
+	// Burndown returns the burndown of a project of the workspace over the range. Days after
+	// the day of the time are left out.
+	Burndown(workspaceID, projectID uuid.UUID, r *task.StatsRange, now time.Time) (*task.Burndown, error)
+	// Aggregate stores the burndown days of every project before the day of the time that
+	// are not stored yet, from the day the project was created and up to a year back. It
+	// returns the number of days stored.
+	Aggregate(ctx context.Context, now time.Time) (int, error)
+	// Run aggregates the days before today, run by the scheduler
+	Run(ctx context.Context) error
+	// CreateWebhook creates a stats webhook of the user, returning its signing secret once
+	CreateWebhook(userID uuid.UUID, req *report.WebhookRequest) (*report.CreatedWebhook, error)
+	ListWebhooks(userID uuid.UUID) []*report.Webhook
+	GetWebhook(userID, id uuid.UUID) (*report.Webhook, error)
+	UpdateWebhook(userID, id uuid.UUID, req *report.WebhookRequest) (*report.Webhook, error)
+	// RotateWebhookSecret replaces the signing secret of a stats webhook, returning it once.
+	// Deliveries are also signed with the previous secret for a day.
+	RotateWebhookSecret(userID, id uuid.UUID) (*report.CreatedWebhook, error)
+	DeleteWebhook(userID, id uuid.UUID) error
+	// DeleteUserData deletes the user's stats webhooks when the account is erased, satisfying
+	// the eraser interface of the privacy service
+	DeleteUserData(userID uuid.UUID)
+	// DeliverWebhooks enqueues a delivery of every enabled webhook whose last period ended
+	// before the day of the time and was not delivered yet. It returns the number of
+	// deliveries enqueued.
+	DeliverWebhooks(ctx context.Context, now time.Time) (int, error)
+	// RunWebhooks delivers the periods that ended before today, run by the scheduler
+	RunWebhooks(ctx context.Context) error
+}
+
+// service implements the report service
+type service struct {
+	mu               sync.Mutex
+	days             map[uuid.UUID]map[string]task.BurndownDay // aggregated days by project and date
+	aggregateMu      sync.Mutex                                // serializes aggregating
+	webhooks         map[uuid.UUID]*report.Webhook             // Mock webhook storage
+	delivered        map[uuid.UUID]time.Time                   // end of the last period enqueued, by webhook
+	taskService      taskService.Service
+	workspaceService workspaceService.Service
+	client           webhook.Client
+	jobs             jobs.Queue
+}
+
+// Deps are the dependencies of a report service. Client and Jobs are optional; stats
+// webhooks can be managed but are not delivered without both.
+type Deps struct {
+	Tasks      taskService.Service
+	Workspaces workspaceService.Service
+	Client     webhook.Client // delivers stats webhooks
+	Jobs       jobs.Queue     // retries failed deliveries
+}
+
+// NewService creates a new report service. Stats webhooks can be managed but are not
+// delivered.
+func NewService(taskSvc taskService.Service, workspaceSvc workspaceService.Service) Service {
+	return NewServiceWithDeps(Deps{Tasks: taskSvc, Workspaces: workspaceSvc})
+}
+
+// NewServiceWithDeps creates a new report service, using the defaults of Deps for the
+// dependencies left unset
+func NewServiceWithDeps(deps Deps) Service {
+	queue := deps.Jobs
+	s := &service{
+		days:             make(map[uuid.UUID]map[string]task.BurndownDay),
+		webhooks:         make(map[uuid.UUID]*report.Webhook),
+		delivered:        make(map[uuid.UUID]time.Time),
+		taskService:      deps.Tasks,
+		workspaceService: deps.Workspaces,
+		client:           deps.Client,
+		jobs:             queue,
+	}
+	if queue != nil {
+		jobs.Handle(queue, func(ctx context.Context, job webhookJob) error {
+			return s.deliver(ctx, job)
+		})
+	}
+	return s
+}
+
+// Burndown returns the burndown of a project of the workspace over the range. Aggregated
+// days are read from storage; the others, such as today, are computed in one pass.
+func (s *service) Burndown(workspaceID, projectID uuid.UUID, r *task.StatsRange, now time.Time) (*task.Burndown, error) {
+	if _, err := s.workspaceService.GetProject(workspaceID, projectID); err != nil {
+		return nil, ErrProjectNotFound
+	}
+
+	today := startOfDay(now)
+	if r.To.After(today) {
+		r = &task.StatsRange{From: r.From, To: today}
+	}
+	if r.From.After(r.To) {
+		return task.NewBurndown(projectID, r, nil), nil
+	}
+
+	days := make([]task.BurndownDay, r.Days())
+	var missing *task.StatsRange
+	s.mu.Lock()
+	for i := range days {
+		date := r.From.AddDate(0, 0, i)
+		day, ok := s.days[projectID][date.Format(time.DateOnly)]
+		if !ok && missing == nil {
+			missing = &task.StatsRange{From: date, To: r.To}
+		}
+		days[i] = day
+	}
+	s.mu.Unlock()
+
+	if missing != nil {
+		offset := r.Days() - missing.Days()
+		for i, day := range s.taskService.ProjectBurndown(projectID, missing) {
+			if days[offset+i].Date == "" {
+				days[offset+i] = day
+			}
+		}
+	}
+	return task.NewBurndown(projectID, r, days), nil
+}
+
+// Aggregate stores the days of every project before the day of the time that are not
+// stored yet. Days more than a year back are dropped.
+func (s *service) Aggregate(ctx context.Context, now time.Time) (int, error) {
+	s.aggregateMu.Lock()
+	defer s.aggregateMu.Unlock()
+
+	yesterday := startOfDay(now).AddDate(0, 0, -1)
+	oldest := yesterday.AddDate(0, 0, 1-task.MaxStatsDays)
+	stored := 0
+	for _, project := range s.workspaceService.AllProjects() {
+		if err := ctx.Err(); err != nil {
+			return stored, err
+		}
+
+		from := startOfDay(project.CreatedAt)
+		if from.Before(oldest) {
+			from = oldest
+		}
+		if from.After(yesterday) {
+			continue
+		}
+
+		// Only the days from the first one missing are computed
+		s.mu.Lock()
+		for ; !from.After(yesterday); from = from.AddDate(0, 0, 1) {
+			if _, ok := s.days[project.ID][from.Format(time.DateOnly)]; !ok {
+				break
+			}
+		}
+		s.mu.Unlock()
+		if from.After(yesterday) {
+			continue
+		}
+
+		days := s.taskService.ProjectBurndown(project.ID, &task.StatsRange{From: from, To: yesterday})
+
+		s.mu.Lock()
+		if s.days[project.ID] == nil {
+			s.days[project.ID] = make(map[string]task.BurndownDay)
+		}
+		for _, day := range days {
+			if _, ok := s.days[project.ID][day.Date]; !ok {
+				s.days[project.ID][day.Date] = day
+				stored++
+			}
+		}
+		for date := range s.days[project.ID] {
+			if date < oldest.Format(time.DateOnly) {
+				delete(s.days[project.ID], date)
+			}
+		}
+		s.mu.Unlock()
+	}
+	return stored, nil
+}
+
+// Run aggregates the days before today
+func (s *service) Run(ctx context.Context) error {
+	if stored, err := s.Aggregate(ctx, time.Now()); err != nil {
+		return fmt.Errorf("%d burndown days aggregated: %w", stored, err)
+	}
+	return nil
+}
+
+// startOfDay returns the start of the UTC day of the time
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
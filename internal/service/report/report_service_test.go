@@ -0,0 +1,118 @@
+package report
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+type testEnv struct {
+	service     Service
+	tasks       taskService.Service
+	workspaces  workspaceService.Service
+	workspaceID uuid.UUID
+	projectID   uuid.UUID
+}
+
+// setupTestService creates a report service of a workspace owned by John with a project
+func setupTestService(t *testing.T) *testEnv {
+	authSvc := authService.NewService(&config.Config{})
+	workspaces := workspaceService.NewService(authSvc)
+
+	created, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	project, err := workspaces.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "Launch"}, johnID)
+	require.NoError(t, err)
+
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	tasks := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces})
+
+	return &testEnv{
+		service:     NewService(tasks, workspaces),
+		tasks:       tasks,
+		workspaces:  workspaces,
+		workspaceID: created.ID,
+		projectID:   project.ID,
+	}
+}
+
+func TestService_Burndown(t *testing.T) {
+	env := setupTestService(t)
+
+	for _, title := range []string{"Book venue", "Send invites"} {
+		_, err := env.tasks.CreateWorkspaceTask(env.workspaceID, &task.CreateTaskRequest{Title: title, ProjectID: &env.projectID}, johnID)
+		require.NoError(t, err)
+	}
+	done, err := env.tasks.CreateWorkspaceTask(env.workspaceID, &task.CreateTaskRequest{Title: "Pick date", ProjectID: &env.projectID}, johnID)
+	require.NoError(t, err)
+	_, err = env.tasks.CompleteTask(done.ID, johnID)
+	require.NoError(t, err)
+
+	r, err := task.NewStatsRange(time.Now(), time.Now().AddDate(0, 0, 5))
+	require.NoError(t, err)
+
+	// Days after today are left out, and today is computed live
+	b, err := env.service.Burndown(env.workspaceID, env.projectID, r, time.Now())
+	require.NoError(t, err)
+	require.Len(t, b.Days, 1)
+	assert.Equal(t, task.BurndownDay{Date: r.From.Format(time.DateOnly), Open: 2, Closed: 1, Completed: 1}, b.Days[0])
+	assert.Equal(t, 1, b.Velocity.Completed)
+	require.NotNil(t, b.Velocity.DaysToFinish)
+	assert.Equal(t, 2.0, *b.Velocity.DaysToFinish)
+
+	_, err = env.service.Burndown(env.workspaceID, uuid.New(), r, time.Now())
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+	_, err = env.service.Burndown(uuid.New(), env.projectID, r, time.Now())
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+}
+
+func TestService_Aggregate(t *testing.T) {
+	env := setupTestService(t)
+	ctx := context.Background()
+
+	open, err := env.tasks.CreateWorkspaceTask(env.workspaceID, &task.CreateTaskRequest{Title: "Book venue", ProjectID: &env.projectID}, johnID)
+	require.NoError(t, err)
+
+	// Two days later, today and tomorrow are aggregated once
+	later := time.Now().Add(48 * time.Hour)
+	stored, err := env.service.Aggregate(ctx, later)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored)
+	stored, err = env.service.Aggregate(ctx, later)
+	require.NoError(t, err)
+	assert.Zero(t, stored)
+
+	// Aggregated days keep their state while the day of the time is computed live
+	_, err = env.tasks.CompleteTask(open.ID, johnID)
+	require.NoError(t, err)
+	r, err := task.NewStatsRange(time.Now(), later)
+	require.NoError(t, err)
+	b, err := env.service.Burndown(env.workspaceID, env.projectID, r, later)
+	require.NoError(t, err)
+	require.Len(t, b.Days, 3)
+	assert.Equal(t, 1, b.Days[0].Open)
+	assert.Zero(t, b.Days[0].Completed)
+	assert.Equal(t, 1, b.Days[1].Open)
+	assert.Equal(t, task.BurndownDay{Date: r.To.Format(time.DateOnly), Closed: 1}, b.Days[2])
+
+	// Aggregation stops when the context is done
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = env.service.Aggregate(cancelled, later.Add(24*time.Hour))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,348 @@
+package report
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"todo-api/internal/domain/report"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/jobs"
+	"todo-api/pkg/webhook"
+
+	"github.com/google/uuid"
+)
+
+// maxWebhooksPerUser bounds the stats webhooks of a user
+const maxWebhooksPerUser = 10
+
+// webhookJob delivers the statistics of a period to a stats webhook
+type webhookJob struct {
+	WebhookID  uuid.UUID `json:"webhook_id"`
+	DeliveryID uuid.UUID `json:"delivery_id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+}
+
+func (webhookJob) JobType() string { return "report.webhook" }
+
+// CreateWebhook creates a stats webhook of the user. Webhooks of a workspace are created by
+// its admins.
+func (s *service) CreateWebhook(userID uuid.UUID, req *report.WebhookRequest) (*report.CreatedWebhook, error) {
+	if err := s.validateWebhook(userID, req); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.webhooksOf(userID)) >= maxWebhooksPerUser {
+		return nil, fmt.Errorf("a user has at most %d stats webhooks", maxWebhooksPerUser)
+	}
+	w := report.NewWebhook(userID, req, secret)
+	s.webhooks[w.ID] = w
+
+	return &report.CreatedWebhook{Webhook: copyWebhook(w), Secret: secret}, nil
+}
+
+// ListWebhooks returns the stats webhooks of the user, oldest first
+func (s *service) ListWebhooks(userID uuid.UUID) []*report.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := s.webhooksOf(userID)
+	webhooks := make([]*report.Webhook, len(owned))
+	for i, w := range owned {
+		webhooks[i] = copyWebhook(w)
+	}
+	return webhooks
+}
+
+// GetWebhook returns a stats webhook of the user
+func (s *service) GetWebhook(userID, id uuid.UUID) (*report.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, exists := s.webhooks[id]
+	if !exists || w.UserID != userID {
+		return nil, report.ErrWebhookNotFound
+	}
+	return copyWebhook(w), nil
+}
+
+// UpdateWebhook replaces the settings of a stats webhook of the user, keeping its secret.
+// Periods already delivered are not delivered again.
+func (s *service) UpdateWebhook(userID, id uuid.UUID, req *report.WebhookRequest) (*report.Webhook, error) {
+	if err := s.validateWebhook(userID, req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, exists := s.webhooks[id]
+	if !exists || w.UserID != userID {
+		return nil, report.ErrWebhookNotFound
+	}
+	w.Apply(req)
+
+	return copyWebhook(w), nil
+}
+
+// RotateWebhookSecret replaces the signing secret of a stats webhook of the user, returning
+// the new secret once. Deliveries are signed with the previous secret as well until it
+// expires.
+func (s *service) RotateWebhookSecret(userID, id uuid.UUID) (*report.CreatedWebhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, exists := s.webhooks[id]
+	if !exists || w.UserID != userID {
+		return nil, report.ErrWebhookNotFound
+	}
+	w.RotateSecret(secret, time.Now())
+
+	return &report.CreatedWebhook{Webhook: copyWebhook(w), Secret: secret}, nil
+}
+
+// DeleteWebhook deletes a stats webhook of the user. Deliveries already enqueued are
+// dropped.
+func (s *service) DeleteWebhook(userID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, exists := s.webhooks[id]
+	if !exists || w.UserID != userID {
+		return report.ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+	delete(s.delivered, id)
+	return nil
+}
+
+// DeleteUserData deletes every stats webhook of the user, with its secrets. Deliveries
+// already enqueued are dropped.
+func (s *service) DeleteUserData(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, w := range s.webhooks {
+		if w.UserID == userID {
+			delete(s.webhooks, id)
+			delete(s.delivered, id)
+		}
+	}
+}
+
+// DeliverWebhooks enqueues a delivery of the last period of every enabled webhook, once per
+// period
+func (s *service) DeliverWebhooks(ctx context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enqueued := 0
+	var errs []error
+	for _, w := range s.webhooks {
+		if err := ctx.Err(); err != nil {
+			return enqueued, err
+		}
+
+		period := w.Period(now)
+		if !w.Enabled || period == nil {
+			continue
+		}
+		if last, ok := s.delivered[w.ID]; ok && !last.Before(period.To) {
+			continue
+		}
+		if s.jobs == nil {
+			return enqueued, errors.New("stats webhooks are not delivered without a job queue")
+		}
+
+		job := webhookJob{
+			WebhookID:  w.ID,
+			DeliveryID: uuid.New(),
+			From:       period.From.Format(time.DateOnly),
+			To:         period.To.Format(time.DateOnly),
+		}
+		if _, err := s.jobs.Enqueue(job, jobs.WithOwner(w.UserID)); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", w.ID, err))
+			continue
+		}
+		s.delivered[w.ID] = period.To
+		enqueued++
+	}
+	return enqueued, errors.Join(errs...)
+}
+
+// RunWebhooks delivers the periods that ended before today
+func (s *service) RunWebhooks(ctx context.Context) error {
+	if enqueued, err := s.DeliverWebhooks(ctx, time.Now()); err != nil {
+		return fmt.Errorf("%d stats webhook deliveries enqueued: %w", enqueued, err)
+	}
+	return nil
+}
+
+// deliver posts the statistics of the period to the webhook, recording the outcome.
+// Deliveries the endpoint rejects, or that the user may no longer make, are not retried.
+func (s *service) deliver(ctx context.Context, job webhookJob) error {
+	s.mu.Lock()
+	stored, exists := s.webhooks[job.WebhookID]
+	var w *report.Webhook
+	if exists {
+		w = copyWebhook(stored)
+	}
+	s.mu.Unlock()
+	if !exists || !w.Enabled {
+		return nil
+	}
+
+	from, err := time.Parse(time.DateOnly, job.From)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	to, err := time.Parse(time.DateOnly, job.To)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	r, err := task.NewStatsRange(from, to)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	payload, err := s.payload(w, r)
+	if err != nil {
+		s.recordDelivery(w.ID, job, err)
+		return jobs.Permanent(err)
+	}
+	err = s.client.Post(ctx, w.URL, w.SigningSecrets(time.Now()), job.DeliveryID.String(), payload)
+	s.recordDelivery(w.ID, job, err)
+	if webhook.IsRejected(err) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// payload computes the statistics of the tasks of the webhook over the range: those of the
+// workspace, as long as the user is still one of its admins, or else the user's own
+func (s *service) payload(w *report.Webhook, r *task.StatsRange) (*report.StatsPayload, error) {
+	payload := &report.StatsPayload{
+		Type:      w.EventType(),
+		WebhookID: w.ID,
+		From:      r.From.Format(time.DateOnly),
+		To:        r.To.Format(time.DateOnly),
+	}
+
+	var err error
+	if w.WorkspaceID != nil {
+		if !s.isWorkspaceAdmin(*w.WorkspaceID, w.UserID) {
+			return nil, errors.New("the user is no longer an admin of the workspace")
+		}
+		payload.WorkspaceID = w.WorkspaceID
+		payload.Stats, err = s.taskService.GetWorkspaceStats(*w.WorkspaceID, r, w.UserID)
+	} else {
+		userID := w.UserID
+		payload.UserID = &userID
+		payload.Stats, err = s.taskService.GetStats(r, w.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// recordDelivery records the outcome of an attempt to deliver a period as the last delivery
+// of the webhook
+func (s *service) recordDelivery(webhookID uuid.UUID, job webhookJob, err error) {
+	delivery := &report.Delivery{
+		ID:          job.DeliveryID,
+		From:        job.From,
+		To:          job.To,
+		Status:      report.DeliverySucceeded,
+		DeliveredAt: time.Now(),
+	}
+	if err != nil {
+		delivery.Status = report.DeliveryFailed
+		delivery.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w, exists := s.webhooks[webhookID]; exists {
+		w.LastDelivery = delivery
+	}
+}
+
+// validateWebhook validates the request, checking the user administers the workspace of
+// workspace webhooks
+func (s *service) validateWebhook(userID uuid.UUID, req *report.WebhookRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if req.WorkspaceID != nil && !s.isWorkspaceAdmin(*req.WorkspaceID, userID) {
+		return errors.New("only workspace admins can add stats webhooks of the workspace")
+	}
+	return nil
+}
+
+// isWorkspaceAdmin reports whether the user is an admin or the owner of the workspace
+func (s *service) isWorkspaceAdmin(workspaceID, userID uuid.UUID) bool {
+	role, ok := s.workspaceService.Role(workspaceID, userID)
+	return ok && role.AtLeast(workspace.RoleAdmin)
+}
+
+// webhooksOf returns the webhooks of the user, oldest first. The caller must hold mu.
+func (s *service) webhooksOf(userID uuid.UUID) []*report.Webhook {
+	var webhooks []*report.Webhook
+	for _, w := range s.webhooks {
+		if w.UserID == userID {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks
+}
+
+// newWebhookSecret generates a random signing secret
+func newWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate webhook secret")
+	}
+	return report.SecretPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// copyWebhook returns a copy of the webhook, so callers cannot change the stored one
+func copyWebhook(w *report.Webhook) *report.Webhook {
+	c := *w
+	if w.WorkspaceID != nil {
+		id := *w.WorkspaceID
+		c.WorkspaceID = &id
+	}
+	if w.PreviousSecretExpiresAt != nil {
+		expiresAt := *w.PreviousSecretExpiresAt
+		c.PreviousSecretExpiresAt = &expiresAt
+	}
+	if w.LastDelivery != nil {
+		delivery := *w.LastDelivery
+		c.LastDelivery = &delivery
+	}
+	return &c
+}
@@ -0,0 +1,209 @@
+package report
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/report"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/jobs"
+	"todo-api/pkg/webhook"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var janeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+
+// fakeClient records the payloads posted to webhooks, failing with err when set
+type fakeClient struct {
+	mu       sync.Mutex
+	err      error
+	payloads []*report.StatsPayload
+	secrets  [][]string
+}
+
+func (c *fakeClient) Post(_ context.Context, _ string, secrets []string, _ string, payload interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.payloads = append(c.payloads, payload.(*report.StatsPayload))
+	c.secrets = append(c.secrets, secrets)
+	return nil
+}
+
+func (c *fakeClient) posted() []*report.StatsPayload {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.payloads
+}
+
+// setupWebhookService creates a report service delivering webhooks through a fake client,
+// with jobs of a queue that does not retry
+func setupWebhookService(t *testing.T) (*testEnv, *service, *fakeClient) {
+	env := setupTestService(t)
+	queue := jobs.NewMemoryQueue(jobs.Config{Workers: 1, MaxAttempts: 1, Retention: time.Hour})
+	t.Cleanup(func() { queue.Shutdown(context.Background()) })
+
+	client := &fakeClient{}
+	s := NewServiceWithDeps(Deps{Tasks: env.tasks, Workspaces: env.workspaces, Client: client, Jobs: queue}).(*service)
+	env.service = s
+	return env, s, client
+}
+
+// lastDelivery waits until the webhook has a delivery and returns it
+func lastDelivery(t *testing.T, s *service, userID, id uuid.UUID) *report.Delivery {
+	var delivery *report.Delivery
+	require.Eventually(t, func() bool {
+		w, err := s.GetWebhook(userID, id)
+		if err != nil {
+			return false
+		}
+		delivery = w.LastDelivery
+		return delivery != nil
+	}, time.Second, time.Millisecond)
+	return delivery
+}
+
+func TestService_Webhooks(t *testing.T) {
+	env, s, _ := setupWebhookService(t)
+
+	_, err := s.CreateWebhook(johnID, &report.WebhookRequest{URL: "http://bi.example.com"})
+	assert.EqualError(t, err, "url must be an https URL")
+
+	created, err := s.CreateWebhook(johnID, &report.WebhookRequest{URL: "https://bi.example.com/ingest"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Secret, report.SecretPrefix))
+	assert.Equal(t, report.FrequencyDaily, created.Frequency)
+	assert.True(t, created.Enabled)
+	assert.Len(t, s.ListWebhooks(johnID), 1)
+	assert.Empty(t, s.ListWebhooks(janeID))
+
+	// Only admins add webhooks of a workspace
+	_, err = s.CreateWebhook(janeID, &report.WebhookRequest{URL: "https://bi.example.com", WorkspaceID: &env.workspaceID})
+	assert.EqualError(t, err, "only workspace admins can add stats webhooks of the workspace")
+
+	disabled := false
+	updated, err := s.UpdateWebhook(johnID, created.ID, &report.WebhookRequest{
+		URL: "https://bi.example.com/v2", WorkspaceID: &env.workspaceID, Frequency: report.FrequencyWeekly, Enabled: &disabled,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &env.workspaceID, updated.WorkspaceID)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, created.Webhook.Secret, updated.Secret)
+
+	rotated, err := s.RotateWebhookSecret(johnID, created.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	assert.Equal(t, created.Secret, rotated.Webhook.PreviousSecret)
+	require.NotNil(t, rotated.PreviousSecretExpiresAt)
+	_, err = s.RotateWebhookSecret(janeID, created.ID)
+	assert.ErrorIs(t, err, report.ErrWebhookNotFound)
+
+	// Webhooks of other users are not found
+	_, err = s.GetWebhook(janeID, created.ID)
+	assert.ErrorIs(t, err, report.ErrWebhookNotFound)
+	assert.ErrorIs(t, s.DeleteWebhook(janeID, created.ID), report.ErrWebhookNotFound)
+
+	require.NoError(t, s.DeleteWebhook(johnID, created.ID))
+	assert.Empty(t, s.ListWebhooks(johnID))
+}
+
+func TestService_DeliverWebhooks(t *testing.T) {
+	env, s, client := setupWebhookService(t)
+	ctx := context.Background()
+
+	personal, err := s.CreateWebhook(johnID, &report.WebhookRequest{URL: "https://bi.example.com/john"})
+	require.NoError(t, err)
+	team, err := s.CreateWebhook(johnID, &report.WebhookRequest{URL: "https://bi.example.com/team", WorkspaceID: &env.workspaceID})
+	require.NoError(t, err)
+	_, err = s.CreateWebhook(johnID, &report.WebhookRequest{URL: "https://bi.example.com/weekly", Frequency: report.FrequencyWeekly})
+	require.NoError(t, err)
+
+	_, err = env.tasks.CreateWorkspaceTask(env.workspaceID, &task.CreateTaskRequest{Title: "Book venue"}, johnID)
+	require.NoError(t, err)
+
+	// Nothing is due on the day webhooks are created
+	enqueued, err := s.DeliverWebhooks(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, enqueued)
+
+	// The next day, daily webhooks deliver the day before, once
+	tomorrow := time.Now().Add(24 * time.Hour)
+	enqueued, err = s.DeliverWebhooks(ctx, tomorrow)
+	require.NoError(t, err)
+	assert.Equal(t, 2, enqueued)
+	enqueued, err = s.DeliverWebhooks(ctx, tomorrow)
+	require.NoError(t, err)
+	assert.Zero(t, enqueued)
+
+	assert.Equal(t, report.DeliverySucceeded, lastDelivery(t, s, johnID, personal.ID).Status)
+	delivery := lastDelivery(t, s, johnID, team.ID)
+	assert.Equal(t, report.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, time.Now().UTC().Format(time.DateOnly), delivery.From)
+
+	// Deliveries are signed with the secret of their webhook
+	payloads := client.posted()
+	require.Len(t, payloads, 2)
+	assert.ElementsMatch(t, [][]string{{personal.Secret}, {team.Secret}}, client.secrets)
+	for _, payload := range payloads {
+		assert.Equal(t, "stats.daily", payload.Type)
+		require.Len(t, payload.Stats.Daily, 1)
+		if payload.WebhookID == team.ID {
+			assert.Equal(t, &env.workspaceID, payload.WorkspaceID)
+			assert.Nil(t, payload.UserID)
+			assert.Equal(t, 1, payload.Stats.Total)
+			assert.Equal(t, 1, payload.Stats.Daily[0].Created)
+		} else {
+			assert.Equal(t, &johnID, payload.UserID)
+		}
+	}
+
+	// Once rotated, deliveries are signed with the new and the previous secret
+	rotated, err := s.RotateWebhookSecret(johnID, personal.ID)
+	require.NoError(t, err)
+	require.NoError(t, s.deliver(ctx, webhookJob{WebhookID: personal.ID, DeliveryID: uuid.New(), From: "2024-01-10", To: "2024-01-10"}))
+	require.Len(t, client.secrets, 3)
+	assert.Equal(t, []string{rotated.Secret, personal.Secret}, client.secrets[2])
+}
+
+func TestService_DeliverWebhooksFailure(t *testing.T) {
+	env, s, client := setupWebhookService(t)
+	client.err = &webhook.StatusError{StatusCode: 410}
+
+	created, err := s.CreateWebhook(johnID, &report.WebhookRequest{URL: "https://bi.example.com/gone"})
+	require.NoError(t, err)
+	_, err = s.DeliverWebhooks(context.Background(), time.Now().Add(24*time.Hour))
+	require.NoError(t, err)
+
+	delivery := lastDelivery(t, s, johnID, created.ID)
+	assert.Equal(t, report.DeliveryFailed, delivery.Status)
+	assert.Equal(t, "webhook endpoint responded with status 410", delivery.Error)
+
+	// Webhooks of workspaces fail once their user is no longer an admin
+	client.err = nil
+	owner, err := env.workspaces.GetMember(env.workspaceID, johnID)
+	require.NoError(t, err)
+	invitation, err := env.workspaces.InviteMember(env.workspaceID,
+		&workspace.InviteMemberRequest{Email: "jane.smith@example.com", Role: workspace.RoleAdmin}, owner)
+	require.NoError(t, err)
+	_, err = env.workspaces.AcceptInvitation(invitation.ID, janeID, "jane.smith@example.com")
+	require.NoError(t, err)
+	team, err := s.CreateWebhook(janeID, &report.WebhookRequest{URL: "https://bi.example.com/team", WorkspaceID: &env.workspaceID})
+	require.NoError(t, err)
+	require.NoError(t, env.workspaces.RemoveMember(env.workspaceID, janeID, owner))
+
+	err = s.deliver(context.Background(), webhookJob{WebhookID: team.ID, DeliveryID: uuid.New(), From: "2024-01-10", To: "2024-01-10"})
+	assert.True(t, jobs.IsPermanent(err))
+	delivery = lastDelivery(t, s, janeID, team.ID)
+	assert.Equal(t, report.DeliveryFailed, delivery.Status)
+	assert.Equal(t, "the user is no longer an admin of the workspace", delivery.Error)
+	assert.Empty(t, client.posted())
+}
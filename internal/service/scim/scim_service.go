@@ -0,0 +1,236 @@
+package scim
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/privacy"
+	"todo-api/internal/domain/scim"
+	"todo-api/internal/domain/tenant"
+	authService "todo-api/internal/service/auth"
+	privacyService "todo-api/internal/service/privacy"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the SCIM service interface, provisioning users on behalf of an identity
+// provider. Users are provisioned in the default tenant, which holds every user the
+// identity provider manages; platform admins are never managed.
+type Service interface {
+	CreateUser(req *scim.User) (*scim.User, error)
+	GetUser(id uuid.UUID) (*scim.User, error)
+	ListUsers(filter *scim.Filter, startIndex, count int) *scim.ListResponse
+	ReplaceUser(id uuid.UUID, req *scim.User) (*scim.User, error)
+	PatchUser(id uuid.UUID, req *scim.PatchRequest) (*scim.User, error)
+	DeleteUser(id uuid.UUID) (*privacy.ErasureRecord, error)
+}
+
+// profile holds the attributes the identity provider keeps about a user besides their email
+// and whether they are active
+type profile struct {
+	externalID  string
+	name        *scim.Name
+	displayName string
+}
+
+// service implements the SCIM service
+type service struct {
+	mu             sync.Mutex
+	profiles       map[uuid.UUID]*profile // Mock profile storage by user ID
+	authService    authService.Service
+	privacyService privacyService.Service
+	baseURL        string
+}
+
+// NewService creates a new SCIM service
+func NewService(cfg *config.Config, authSvc authService.Service, privacySvc privacyService.Service) Service {
+	return &service{
+		profiles:       make(map[uuid.UUID]*profile),
+		authService:    authSvc,
+		privacyService: privacySvc,
+		baseURL:        strings.TrimSuffix(cfg.App.BaseURL, "/"),
+	}
+}
+
+// CreateUser provisions a user. Provisioned users have no password: they log in through
+// the directory, or choose a password with a password reset email.
+func (s *service) CreateUser(req *scim.User) (*scim.User, error) {
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	user := auth.NewUser(req.UserName, "")
+	user.TenantID = tenant.DefaultTenantID
+	if !req.IsActive() {
+		user.DeactivatedAt = &user.CreatedAt
+	}
+	if err := s.authService.CreateUser(user); err != nil {
+		if errors.Is(err, auth.ErrEmailTaken) {
+			return nil, scim.ErrUniqueness
+		}
+		return nil, err
+	}
+
+	p := profileOf(req)
+	s.mu.Lock()
+	s.profiles[user.ID] = p
+	s.mu.Unlock()
+
+	return s.resource(user, p), nil
+}
+
+// GetUser returns a user managed by the identity provider
+func (s *service) GetUser(id uuid.UUID) (*scim.User, error) {
+	user, err := s.managedUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resource(user, s.profiles[id]), nil
+}
+
+// ListUsers returns a page of the users matching the filter, oldest first. The start index
+// is 1-based.
+func (s *service) ListUsers(filter *scim.Filter, startIndex, count int) *scim.ListResponse {
+	startIndex = max(startIndex, 1)
+	count = max(count, 0)
+
+	s.mu.Lock()
+	var matched []*scim.User
+	for _, user := range s.authService.ListUsers() {
+		if !isManaged(user) {
+			continue
+		}
+		if resource := s.resource(user, s.profiles[user.ID]); filter.Matches(resource) {
+			matched = append(matched, resource)
+		}
+	}
+	s.mu.Unlock()
+
+	page := matched[min(startIndex-1, len(matched)):]
+	page = page[:min(count, len(page))]
+	return scim.NewListResponse(page, len(matched), startIndex)
+}
+
+// ReplaceUser replaces every attribute of a user. Users are deactivated by replacing them
+// with active set to false.
+func (s *service) ReplaceUser(id uuid.UUID, req *scim.User) (*scim.User, error) {
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.managedUser(id); err != nil {
+		return nil, err
+	}
+	return s.save(id, req)
+}
+
+// PatchUser changes some attributes of a user. Identity providers deactivate users by
+// setting active to false.
+func (s *service) PatchUser(id uuid.UUID, req *scim.PatchRequest) (*scim.User, error) {
+	current, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := current.ApplyPatch(req); err != nil {
+		return nil, err
+	}
+	current.Normalize()
+	if err := current.Validate(); err != nil {
+		return nil, err
+	}
+	return s.save(id, current)
+}
+
+// DeleteUser erases a user's account and data, like users deleting their own account
+func (s *service) DeleteUser(id uuid.UUID) (*privacy.ErasureRecord, error) {
+	if _, err := s.managedUser(id); err != nil {
+		return nil, err
+	}
+
+	record, err := s.privacyService.EraseUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.profiles, id)
+	s.mu.Unlock()
+	return record, nil
+}
+
+// save sets the email, activity, and profile of a user
+func (s *service) save(id uuid.UUID, u *scim.User) (*scim.User, error) {
+	user, err := s.authService.UpdateUser(id, u.UserName, u.IsActive())
+	if err != nil {
+		if errors.Is(err, auth.ErrEmailTaken) {
+			return nil, scim.ErrUniqueness
+		}
+		return nil, err
+	}
+
+	p := profileOf(u)
+	s.mu.Lock()
+	s.profiles[id] = p
+	s.mu.Unlock()
+
+	return s.resource(user, p), nil
+}
+
+// managedUser returns the user if the identity provider manages them
+func (s *service) managedUser(id uuid.UUID) (*auth.User, error) {
+	user, err := s.authService.GetUserByID(id)
+	if err != nil || !isManaged(user) {
+		return nil, scim.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// resource returns the SCIM representation of a user with their profile, if any
+func (s *service) resource(user *auth.User, p *profile) *scim.User {
+	active := user.IsActive()
+	resource := &scim.User{
+		Schemas:  []string{scim.SchemaUser},
+		ID:       user.ID.String(),
+		UserName: user.Email,
+		Emails:   []scim.Email{{Value: user.Email, Type: "work", Primary: true}},
+		Active:   &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     s.baseURL + "/scim/v2/Users/" + user.ID.String(),
+		},
+	}
+	if p != nil {
+		resource.ExternalID = p.externalID
+		resource.DisplayName = p.displayName
+		if p.name != nil {
+			name := *p.name
+			resource.Name = &name
+		}
+	}
+	return resource
+}
+
+// profileOf returns the profile of a user as sent by the identity provider
+func profileOf(u *scim.User) *profile {
+	p := &profile{externalID: u.ExternalID, displayName: u.DisplayName}
+	if u.Name != nil && *u.Name != (scim.Name{}) {
+		name := *u.Name
+		p.name = &name
+	}
+	return p
+}
+
+// isManaged reports whether the identity provider manages the user
+func isManaged(user *auth.User) bool {
+	return user.TenantID == tenant.DefaultTenantID && !user.Admin
+}
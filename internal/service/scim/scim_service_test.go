@@ -0,0 +1,172 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/auth"
+	"todo-api/internal/domain/scim"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/events"
+	authService "todo-api/internal/service/auth"
+	privacyService "todo-api/internal/service/privacy"
+	taskService "todo-api/internal/service/task"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	johnID  = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	adminID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440010")
+	aliceID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440004")
+)
+
+func setupTestService(t *testing.T) (Service, authService.Service) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		App: config.AppConfig{BaseURL: "https://todo.example.com/"},
+	}
+
+	authSvc := authService.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	tasks := taskService.NewServiceWithDeps(taskService.Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces})
+	return NewService(cfg, authSvc, privacyService.NewService(authSvc, tasks, workspaces)), authSvc
+}
+
+func TestService_CreateUser(t *testing.T) {
+	service, authSvc := setupTestService(t)
+
+	created, err := service.CreateUser(&scim.User{
+		UserName:   "New.Hire@Example.com",
+		ExternalID: "00u1",
+		Name:       &scim.Name{GivenName: "New", FamilyName: "Hire"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new.hire@example.com", created.UserName)
+	assert.Equal(t, "00u1", created.ExternalID)
+	assert.True(t, created.IsActive())
+	assert.Equal(t, "https://todo.example.com/scim/v2/Users/"+created.ID, created.Meta.Location)
+
+	user, err := authSvc.GetUserByEmail("new.hire@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, user.ID.String())
+	assert.Equal(t, tenant.DefaultTenantID, user.TenantID)
+
+	fetched, err := service.GetUser(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, fetched)
+
+	_, err = service.CreateUser(&scim.User{UserName: "john.doe@example.com"})
+	assert.ErrorIs(t, err, scim.ErrUniqueness)
+	_, err = service.CreateUser(&scim.User{UserName: "john"})
+	assert.ErrorIs(t, err, scim.ErrInvalidValue)
+
+	inactive := false
+	created, err = service.CreateUser(&scim.User{UserName: "contractor@example.com", Active: &inactive})
+	require.NoError(t, err)
+	assert.False(t, created.IsActive())
+}
+
+func TestService_ListUsers(t *testing.T) {
+	service, _ := setupTestService(t)
+
+	// Platform admins and users of other tenants are not managed
+	list := service.ListUsers(nil, 1, scim.DefaultCount)
+	assert.Equal(t, 3, list.TotalResults)
+	for _, u := range list.Resources {
+		assert.NotEqual(t, adminID.String(), u.ID)
+		assert.NotEqual(t, aliceID.String(), u.ID)
+	}
+	_, err := service.GetUser(adminID)
+	assert.ErrorIs(t, err, scim.ErrUserNotFound)
+
+	page := service.ListUsers(nil, 2, 1)
+	assert.Equal(t, 3, page.TotalResults)
+	assert.Equal(t, 2, page.StartIndex)
+	assert.Equal(t, 1, page.ItemsPerPage)
+	assert.Equal(t, list.Resources[1], page.Resources[0])
+	assert.Empty(t, service.ListUsers(nil, 10, 5).Resources)
+
+	list = service.ListUsers(&scim.Filter{Attribute: "userName", Value: "John.Doe@example.com"}, 1, scim.DefaultCount)
+	require.Equal(t, 1, list.TotalResults)
+	assert.Equal(t, johnID.String(), list.Resources[0].ID)
+
+	// The total alone is returned for a count of 0
+	list = service.ListUsers(&scim.Filter{Attribute: "externalId", Value: "missing"}, 1, 0)
+	assert.Equal(t, 0, list.TotalResults)
+	assert.NotNil(t, list.Resources)
+}
+
+func TestService_DeactivateUser(t *testing.T) {
+	service, authSvc := setupTestService(t)
+
+	tokens, err := authSvc.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	var patch scim.PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations": [{"op": "replace", "path": "active", "value": false}]}`), &patch))
+	updated, err := service.PatchUser(johnID, &patch)
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive())
+
+	// Deactivated users can neither log in nor refresh their tokens
+	_, err = authSvc.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, auth.ErrUserDeactivated)
+	_, err = authSvc.Refresh(&auth.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	assert.Error(t, err)
+
+	active := true
+	updated, err = service.ReplaceUser(johnID, &scim.User{UserName: "john.doe@example.com", Active: &active, ExternalID: "00u2"})
+	require.NoError(t, err)
+	assert.True(t, updated.IsActive())
+	assert.Equal(t, "00u2", updated.ExternalID)
+	_, err = authSvc.Login(&auth.LoginRequest{Email: "john.doe@example.com", Password: "password123"})
+	assert.NoError(t, err)
+}
+
+func TestService_ReplaceUser_Rename(t *testing.T) {
+	service, authSvc := setupTestService(t)
+
+	_, err := service.ReplaceUser(johnID, &scim.User{UserName: "jane.smith@example.com"})
+	assert.ErrorIs(t, err, scim.ErrUniqueness)
+
+	updated, err := service.ReplaceUser(johnID, &scim.User{UserName: "John.Smith@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "john.smith@example.com", updated.UserName)
+
+	_, err = authSvc.GetUserByEmail("john.doe@example.com")
+	assert.Error(t, err)
+	_, err = authSvc.Login(&auth.LoginRequest{Email: "john.smith@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	_, err = service.ReplaceUser(uuid.New(), &scim.User{UserName: "ghost@example.com"})
+	assert.ErrorIs(t, err, scim.ErrUserNotFound)
+}
+
+func TestService_DeleteUser(t *testing.T) {
+	service, authSvc := setupTestService(t)
+
+	record, err := service.DeleteUser(johnID)
+	require.NoError(t, err)
+	assert.Equal(t, johnID, record.UserID)
+
+	_, err = authSvc.GetUserByID(johnID)
+	assert.Error(t, err)
+	_, err = service.GetUser(johnID)
+	assert.ErrorIs(t, err, scim.ErrUserNotFound)
+
+	_, err = service.DeleteUser(aliceID)
+	assert.ErrorIs(t, err, scim.ErrUserNotFound)
+}
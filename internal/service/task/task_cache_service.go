@@ -0,0 +1,146 @@
+package task
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// cacheTimeout bounds each cache request, so a slow cache does not slow reads down further
+// than a cache miss
+const cacheTimeout = 100 * time.Millisecond
+
+// cachedList is a cached page of a task listing
+type cachedList struct {
+	Tasks      []*task.Task          `json:"tasks"`
+	Pagination *types.PaginationInfo `json:"pagination"`
+}
+
+// taskGroup is the cache group of the reads of a task by ID, one value per user
+func taskGroup(id uuid.UUID) string {
+	return "task:" + id.String()
+}
+
+// listGroup is the cache group of the listings of a user, one value per query
+func listGroup(userID uuid.UUID) string {
+	return "tasks:" + userID.String()
+}
+
+// listKey identifies a listing query within the listings of a user
+func listKey(filter *task.TaskFilter, sort *task.TaskSort, limit int) string {
+	data, _ := json.Marshal(struct {
+		Filter *task.TaskFilter `json:"filter"`
+		Sort   *task.TaskSort   `json:"sort"`
+		Limit  int              `json:"limit"`
+	}{filter, sort, limit})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// cachedTask returns the task cached for the user, if any. Workspace memberships and roles
+// are not tracked by invalidations, so the user must still be allowed to read the task.
+func (s *service) cachedTask(id, userID uuid.UUID) (*task.Task, bool) {
+	var t task.Task
+	if !s.cacheGet(taskGroup(id), userID.String(), &t) || !s.can(&t, userID, task.ActionRead) {
+		return nil, false
+	}
+	t.TenantID = s.tenants.TenantOf(userID)
+	return &t, true
+}
+
+// cachedList returns the listing cached for the user, if any. Like cached tasks, it is only
+// used while the user may still read each of its tasks.
+func (s *service) cachedList(filter *task.TaskFilter, sort *task.TaskSort, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, bool) {
+	var list cachedList
+	if !s.cacheGet(listGroup(userID), listKey(filter, sort, limit), &list) {
+		return nil, nil, false
+	}
+	tenantID := s.tenants.TenantOf(userID)
+	for _, t := range list.Tasks {
+		if !s.can(t, userID, task.ActionRead) {
+			return nil, nil, false
+		}
+		t.TenantID = tenantID
+	}
+	return list.Tasks, list.Pagination, true
+}
+
+// cacheGeneration returns the number of invalidations so far. Values read from storage are
+// only cached if no invalidation happened meanwhile, so a concurrent change is not hidden by
+// a value read before it.
+func (s *service) cacheGeneration() uint64 {
+	return s.cacheInvalidations.Load()
+}
+
+// cacheGet decodes the cached value of the key in the group into v, reporting whether it was
+// found. Cache failures are logged and treated as misses.
+func (s *service) cacheGet(group, key string, v interface{}) bool {
+	if s.cache == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	data, found, err := s.cache.Get(ctx, group, key)
+	if err != nil {
+		log.Printf("Failed to read task cache: %v", err)
+		return false
+	}
+	return found && json.Unmarshal(data, v) == nil
+}
+
+// cacheSet caches the value of the key in the group, unless an invalidation happened since
+// the generation. Failures are logged.
+func (s *service) cacheSet(generation uint64, group, key string, v interface{}) {
+	if s.cache == nil || s.cacheGeneration() != generation {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	if err := s.cache.Set(ctx, group, key, data, s.cacheTTL); err != nil {
+		log.Printf("Failed to write task cache: %v", err)
+	}
+}
+
+// invalidateCache removes the cached reads of the task of the event, and the cached listings
+// of the users who may list it, including users it was just unassigned from or unshared
+// with. Failures are logged; the entries then expire after the TTL.
+func (s *service) invalidateCache(event *task.Event) {
+	if s.cache == nil {
+		return
+	}
+	s.cacheInvalidations.Add(1)
+
+	groups := []string{taskGroup(event.TaskID), listGroup(event.UserID)}
+	for _, id := range event.Viewers {
+		groups = append(groups, listGroup(id))
+	}
+	for _, change := range event.Changes {
+		if change.Field != "assignee_id" && change.Field != "shared_with" {
+			continue
+		}
+		if id, err := uuid.Parse(change.OldValue); err == nil {
+			groups = append(groups, listGroup(id))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	if err := s.cache.Invalidate(ctx, groups...); err != nil {
+		log.Printf("Failed to invalidate task cache for task %s: %v", event.TaskID, err)
+	}
+}
@@ -0,0 +1,169 @@
+package task
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"todo-api/internal/cache"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	"todo-api/internal/service/auth"
+	tenantService "todo-api/internal/service/tenant"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCache counts the values found in a cache
+type countingCache struct {
+	cache.Cache
+	hits atomic.Int32
+}
+
+func (c *countingCache) Get(ctx context.Context, group, key string) ([]byte, bool, error) {
+	value, found, err := c.Cache.Get(ctx, group, key)
+	if found {
+		c.hits.Add(1)
+	}
+	return value, found, err
+}
+
+func setupCachedService(t *testing.T) (Service, *countingCache) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}}
+	authSvc := auth.NewService(cfg)
+	tenants := tenantService.NewService(authSvc)
+	workspaces := workspaceService.NewServiceWithTenants(authSvc, tenants)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	c := &countingCache{Cache: cache.NewMemoryCache()}
+	return NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces, Tenants: tenants, Cache: c, CacheTTL: time.Minute}), c
+}
+
+func titles(tasks []*task.Task) []string {
+	result := make([]string, len(tasks))
+	for i, t := range tasks {
+		result[i] = t.Title
+	}
+	return result
+}
+
+func TestService_Cache_GetTaskByID(t *testing.T) {
+	service, c := setupCachedService(t)
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Write release notes"}, johnID)
+	require.NoError(t, err)
+
+	_, err = service.GetTaskByID(created.ID, johnID)
+	require.NoError(t, err)
+	cached, err := service.GetTaskByID(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), c.hits.Load())
+	assert.Equal(t, "Write release notes", cached.Title)
+	assert.Equal(t, created.TenantID, cached.TenantID)
+
+	// Other users are not served the reads of the owner
+	_, err = service.GetTaskByID(created.ID, janeID)
+	assert.EqualError(t, err, "access denied")
+
+	// Changes invalidate the cached reads
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: stringPtr("Publish release notes")}, johnID)
+	require.NoError(t, err)
+	updated, err := service.GetTaskByID(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, "Publish release notes", updated.Title)
+
+	require.NoError(t, service.DeleteTask(created.ID, johnID))
+	_, err = service.GetTaskByID(created.ID, johnID)
+	assert.EqualError(t, err, "task not found")
+}
+
+func TestService_Cache_ListTasks(t *testing.T) {
+	service, c := setupCachedService(t)
+	sort := &task.TaskSort{Field: "title", Order: "asc"}
+
+	first, _, err := service.ListTasks(&task.TaskFilter{}, sort, 1, 10, johnID)
+	require.NoError(t, err)
+	cached, pagination, err := service.ListTasks(&task.TaskFilter{}, sort, 1, 10, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), c.hits.Load())
+	assert.Equal(t, titles(first), titles(cached))
+	assert.Equal(t, int64(len(first)), pagination.Total)
+
+	// Queries are cached separately, and only their first page
+	_, _, err = service.ListTasks(&task.TaskFilter{}, sort, 1, 1, johnID)
+	require.NoError(t, err)
+	_, _, err = service.ListTasks(&task.TaskFilter{}, sort, 2, 1, johnID)
+	require.NoError(t, err)
+	_, _, err = service.ListTasks(&task.TaskFilter{}, sort, 2, 1, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), c.hits.Load())
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Aaa first task"}, johnID)
+	require.NoError(t, err)
+	tasks, _, err := service.ListTasks(&task.TaskFilter{}, sort, 1, 10, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, "Aaa first task", tasks[0].Title)
+
+	// Users a task is shared with, or no longer shared with, see the change
+	_, _, err = service.ListTasks(&task.TaskFilter{}, sort, 1, 10, janeID)
+	require.NoError(t, err)
+	_, err = service.ShareTask(created.ID, &task.ShareTaskRequest{UserID: janeID}, johnID)
+	require.NoError(t, err)
+	tasks, _, err = service.ListTasks(&task.TaskFilter{}, sort, 1, 10, janeID)
+	require.NoError(t, err)
+	assert.Contains(t, titles(tasks), "Aaa first task")
+
+	_, err = service.UnshareTask(created.ID, janeID, johnID)
+	require.NoError(t, err)
+	tasks, _, err = service.ListTasks(&task.TaskFilter{}, sort, 1, 10, janeID)
+	require.NoError(t, err)
+	assert.NotContains(t, titles(tasks), "Aaa first task")
+}
+
+func TestService_Cache_RemovedWorkspaceMember(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}}
+	authSvc := auth.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	service := NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces, Cache: cache.NewMemoryCache(), CacheTTL: time.Minute})
+
+	created, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	owner, err := workspaces.GetMember(created.ID, johnID)
+	require.NoError(t, err)
+	invitation, err := workspaces.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "jane.smith@example.com"}, owner)
+	require.NoError(t, err)
+	_, err = workspaces.AcceptInvitation(invitation.ID, janeID, "jane.smith@example.com")
+	require.NoError(t, err)
+
+	workspaceTask, err := service.CreateWorkspaceTask(created.ID, &task.CreateTaskRequest{Title: "Plan the launch"}, johnID)
+	require.NoError(t, err)
+	_, err = service.GetTaskByID(workspaceTask.ID, janeID)
+	require.NoError(t, err)
+
+	// Membership changes publish no task events, yet removed members lose access at once
+	require.NoError(t, workspaces.RemoveMember(created.ID, janeID, owner))
+	_, err = service.GetTaskByID(workspaceTask.ID, janeID)
+	assert.EqualError(t, err, "access denied")
+}
+
+func TestService_Cache_SkipsValuesReadBeforeChanges(t *testing.T) {
+	svc, _ := setupCachedService(t)
+	s := svc.(*service)
+
+	generation := s.cacheGeneration()
+	s.invalidateCache(&task.Event{TaskID: uuid.New(), UserID: johnID})
+	s.cacheSet(generation, listGroup(johnID), "query", &cachedList{})
+
+	_, _, ok := s.cachedList(nil, nil, 10, johnID)
+	assert.False(t, ok)
+	var list cachedList
+	assert.False(t, s.cacheGet(listGroup(johnID), "query", &list))
+}
@@ -0,0 +1,62 @@
+package task
+
+import (
+	"strconv"
+	"sync"
+
+	"todo-api/internal/domain/task"
+	"todo-api/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// listFlights coalesces identical listings running at the same time, such as a dashboard
+// refreshing in several tabs, so they share a single read of the tasks
+type listFlights struct {
+	mu    sync.Mutex
+	calls map[string]*listFlight
+}
+
+// listFlight is a listing in progress, and its result once done
+type listFlight struct {
+	done       chan struct{}
+	duplicates int // callers waiting for the listing besides the one reading it
+	tasks      []*task.Task
+	pagination *types.PaginationInfo
+}
+
+// newListFlights creates an empty set of listings in progress
+func newListFlights() *listFlights {
+	return &listFlights{calls: make(map[string]*listFlight)}
+}
+
+// do returns the result of read for the key, calling it unless a read of the same key is
+// already in progress, whose result is then returned to every caller waiting for it. The
+// tasks returned are shared, so they must not be modified.
+func (f *listFlights) do(key string, read func() ([]*task.Task, *types.PaginationInfo)) ([]*task.Task, *types.PaginationInfo) {
+	f.mu.Lock()
+	if call, ok := f.calls[key]; ok {
+		call.duplicates++
+		f.mu.Unlock()
+		<-call.done
+		return call.tasks, call.pagination
+	}
+	call := &listFlight{done: make(chan struct{})}
+	f.calls[key] = call
+	f.mu.Unlock()
+
+	// Later callers start a read of their own, so they see changes made after this one
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+		close(call.done)
+	}()
+	call.tasks, call.pagination = read()
+	return call.tasks, call.pagination
+}
+
+// flightKey identifies a listing of a page among the listings of every user
+func flightKey(filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) string {
+	return userID.String() + ":" + listKey(filter, sort, limit) + ":" + strconv.Itoa(page)
+}
@@ -0,0 +1,75 @@
+package task
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/pkg/types"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFlights_Do(t *testing.T) {
+	flights := newListFlights()
+	var reads atomic.Int32
+	release := make(chan struct{})
+	listed := []*task.Task{task.NewTask("Shared", uuid.New())}
+	read := func() ([]*task.Task, *types.PaginationInfo) {
+		reads.Add(1)
+		<-release
+		return listed, &types.PaginationInfo{Page: 1, Limit: 10, Total: 1, TotalPages: 1}
+	}
+
+	// Listings of the same key wait for the read in progress
+	var wg sync.WaitGroup
+	results := make([][]*task.Task, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = flights.do("john:tasks:1", read)
+		}()
+		if i == 0 {
+			assert.Eventually(t, func() bool { return reads.Load() == 1 }, time.Second, time.Millisecond)
+		}
+	}
+	assert.Eventually(t, func() bool {
+		flights.mu.Lock()
+		defer flights.mu.Unlock()
+		return flights.calls["john:tasks:1"].duplicates == 3
+	}, time.Second, time.Millisecond)
+
+	// Other keys are read on their own
+	other, _ := flights.do("jane:tasks:1", func() ([]*task.Task, *types.PaginationInfo) {
+		return nil, &types.PaginationInfo{}
+	})
+	assert.Nil(t, other)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), reads.Load())
+	for _, result := range results {
+		assert.Equal(t, listed, result)
+	}
+
+	// Once done, the next listing reads again, so it sees later changes
+	flights.do("john:tasks:1", read)
+	assert.Equal(t, int32(2), reads.Load())
+	assert.Empty(t, flights.calls)
+}
+
+func TestFlightKey(t *testing.T) {
+	john := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	jane := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	pending := task.StatusPending
+	key := flightKey(&task.TaskFilter{Status: &pending}, nil, 1, 10, john)
+
+	assert.Equal(t, key, flightKey(&task.TaskFilter{Status: &pending}, nil, 1, 10, john))
+	assert.NotEqual(t, key, flightKey(&task.TaskFilter{Status: &pending}, nil, 2, 10, john))
+	assert.NotEqual(t, key, flightKey(&task.TaskFilter{Status: &pending}, nil, 1, 10, jane))
+	assert.NotEqual(t, key, flightKey(nil, nil, 1, 10, john))
+}
@@ -0,0 +1,213 @@
+package task
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+
+	"todo-api/internal/domain/task"
+	"todo-api/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// listingIndex holds, for each user, the tasks they may list sorted newest first, updated as
+// tasks change. Listings in the default order filtered by status at most read their page from
+// it rather than scanning and sorting every task of the tenant.
+type listingIndex struct {
+	users   map[uuid.UUID]*userListing
+	entries map[uuid.UUID]listingEntry // where each task is indexed, by task ID
+}
+
+// userListing is the part of the listing index of one user
+type userListing struct {
+	tasks  []*task.Task                     // every task the user may list, archived ones too
+	active map[task.TaskStatus][]*task.Task // tasks that are not archived, by status
+}
+
+// listingEntry records where a task is indexed, as the task itself has changed by the time
+// it is indexed again
+type listingEntry struct {
+	task     *task.Task
+	users    []uuid.UUID
+	status   task.TaskStatus
+	archived bool
+}
+
+// newListingIndex creates an empty listing index
+func newListingIndex() *listingIndex {
+	return &listingIndex{
+		users:   make(map[uuid.UUID]*userListing),
+		entries: make(map[uuid.UUID]listingEntry),
+	}
+}
+
+// newestFirst reports whether task a is listed before task b in the default order, newest
+// first with ties broken by ID
+func newestFirst(a, b *task.Task) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) < 0
+}
+
+// insertSorted inserts the task into tasks sorted newest first
+func insertSorted(tasks []*task.Task, t *task.Task) []*task.Task {
+	i := sort.Search(len(tasks), func(i int) bool { return !newestFirst(tasks[i], t) })
+	return slices.Insert(tasks, i, t)
+}
+
+// removeSorted removes the task from tasks sorted newest first, if present. The creation
+// time of tasks never changes, so the task is found where it was inserted.
+func removeSorted(tasks []*task.Task, t *task.Task) []*task.Task {
+	i := sort.Search(len(tasks), func(i int) bool { return !newestFirst(tasks[i], t) })
+	if i < len(tasks) && tasks[i].ID == t.ID {
+		return slices.Delete(tasks, i, i+1)
+	}
+	return tasks
+}
+
+// put indexes the task for the users who may list it, replacing where it was indexed before
+func (x *listingIndex) put(t *task.Task, users []uuid.UUID) {
+	x.remove(t.ID)
+
+	entry := listingEntry{task: t, users: users, status: t.Status, archived: t.IsArchived()}
+	for _, userID := range users {
+		listing, ok := x.users[userID]
+		if !ok {
+			listing = &userListing{active: make(map[task.TaskStatus][]*task.Task)}
+			x.users[userID] = listing
+		}
+		listing.tasks = insertSorted(listing.tasks, t)
+		if !entry.archived {
+			listing.active[entry.status] = insertSorted(listing.active[entry.status], t)
+		}
+	}
+	x.entries[t.ID] = entry
+}
+
+// remove removes the task with the ID from the index, if present
+func (x *listingIndex) remove(id uuid.UUID) {
+	entry, ok := x.entries[id]
+	if !ok {
+		return
+	}
+
+	for _, userID := range entry.users {
+		listing := x.users[userID]
+		listing.tasks = removeSorted(listing.tasks, entry.task)
+		if !entry.archived {
+			listing.active[entry.status] = removeSorted(listing.active[entry.status], entry.task)
+		}
+	}
+	delete(x.entries, id)
+}
+
+// tasks returns every task the user may list, newest first. The slice must not be modified.
+func (x *listingIndex) tasks(userID uuid.UUID) []*task.Task {
+	if listing, ok := x.users[userID]; ok {
+		return listing.tasks
+	}
+	return nil
+}
+
+// page returns a page of the tasks of the user that are not archived and have one of the
+// statuses, or any status if none is given, newest first, together with their total
+func (x *listingIndex) page(userID uuid.UUID, statuses []task.TaskStatus, page, limit int) ([]*task.Task, int) {
+	listing, ok := x.users[userID]
+	if !ok {
+		return []*task.Task{}, 0
+	}
+	if len(statuses) == 0 {
+		for status := range listing.active {
+			statuses = append(statuses, status)
+		}
+	}
+
+	// Each status is sorted, so the page is read by merging the statuses up to its end
+	var lists [][]*task.Task
+	total := 0
+	for _, status := range statuses {
+		if list := listing.active[status]; len(list) > 0 {
+			lists = append(lists, list)
+			total += len(list)
+		}
+	}
+
+	start, end := (page-1)*limit, min(page*limit, total)
+	if start >= end {
+		return []*task.Task{}, total
+	}
+	if len(lists) == 1 {
+		return slices.Clone(lists[0][start:end]), total
+	}
+
+	result := make([]*task.Task, 0, end-start)
+	heads := make([]int, len(lists))
+	for i := 0; i < end; i++ {
+		next := -1
+		for l, list := range lists {
+			if heads[l] < len(list) && (next == -1 || newestFirst(list[heads[l]], lists[next][heads[next]])) {
+				next = l
+			}
+		}
+		if i >= start {
+			result = append(result, lists[next][heads[next]])
+		}
+		heads[next]++
+	}
+	return result, total
+}
+
+// indexListing updates the listing index after a change to the task with the ID, indexing it
+// for the users who may list it: its owner, assignee, and the users it is shared with, if the
+// policy lets them read it and they belong to its tenant. Deleted tasks are removed. The
+// caller must hold the lock.
+func (s *service) indexListing(id uuid.UUID) {
+	t, exists := s.tasks[id]
+	if !exists {
+		s.listings.remove(id)
+		return
+	}
+
+	candidates := append([]uuid.UUID{t.UserID}, t.Viewers()...)
+	var users []uuid.UUID
+	for _, userID := range candidates {
+		if slices.Contains(users, userID) {
+			continue
+		}
+		if s.policy.Enforce(t.RolesFor(userID), task.ResourceType, task.ActionRead) && s.tenants.TenantOf(userID) == t.TenantID {
+			users = append(users, userID)
+		}
+	}
+	s.listings.put(t, users)
+}
+
+// listIndexed returns a page of a listing from the listing index, reporting false when the
+// index cannot serve it: only listings in the default order, newest first, filtered by status
+// at most are served. The caller must hold the lock.
+func (s *service) listIndexed(filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, bool) {
+	if sort != nil && (sort.Field != "created_at" || sort.Order != "desc" || len(sort.ThenBy) > 0) {
+		return nil, nil, false
+	}
+
+	var statuses []task.TaskStatus
+	if filter != nil {
+		if filter.Search != "" || filter.CreatedAfter != nil || filter.CreatedBefore != nil ||
+			filter.DueFrom != nil || filter.DueBefore != nil || filter.AssigneeID != nil ||
+			filter.ProjectID != nil || filter.Archived != nil || (filter.Status != nil && len(filter.Statuses) > 0) {
+			return nil, nil, false
+		}
+		if filter.Status != nil {
+			statuses = []task.TaskStatus{*filter.Status}
+		}
+		for _, status := range filter.Statuses {
+			if !slices.Contains(statuses, status) {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+
+	tasks, total := s.listings.page(userID, statuses, page, limit)
+	return tasks, pageInfo(total, page, limit), true
+}
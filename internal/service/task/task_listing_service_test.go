@@ -0,0 +1,102 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scanListing returns a page of a listing in the default order by checking every stored task,
+// the way listings worked before the listing index
+func scanListing(s *service, filter *task.TaskFilter, page, limit int, userID uuid.UUID) ([]*task.Task, int64) {
+	var listed []*task.Task
+	for _, t := range s.tasks {
+		if t.TenantID == s.tenants.TenantOf(userID) && s.policy.Enforce(t.RolesFor(userID), task.ResourceType, task.ActionRead) && filter.Matches(t) {
+			listed = append(listed, t)
+		}
+	}
+	sort.Slice(listed, func(i, j int) bool { return newestFirst(listed[i], listed[j]) })
+	tasks, info := paginate(listed, page, limit)
+	return tasks, info.Total
+}
+
+// assertIndexed checks listings served by the listing index match listings scanning every task
+func assertIndexed(t *testing.T, s *service, step string, userIDs ...uuid.UUID) {
+	t.Helper()
+	pending := task.StatusPending
+	filters := []*task.TaskFilter{
+		nil,
+		{Status: &pending},
+		{Statuses: []task.TaskStatus{task.StatusPending, task.StatusCompleted}},
+		{Statuses: []task.TaskStatus{task.StatusInProgress, task.StatusInProgress}},
+	}
+
+	for _, userID := range userIDs {
+		for i, filter := range filters {
+			for page := 1; page <= 3; page++ {
+				tasks, info, ok := s.listIndexed(filter, nil, page, 2, userID)
+				require.True(t, ok)
+				expected, total := scanListing(s, filter, page, 2, userID)
+				name := fmt.Sprintf("%s: user %s, filter %d, page %d", step, userID, i, page)
+				assert.Equal(t, expected, tasks, name)
+				assert.Equal(t, total, info.Total, name)
+			}
+		}
+	}
+}
+
+func TestService_ListTasks_Indexed(t *testing.T) {
+	s := setupTestService(t).(*service)
+	john := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	jane := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	assertIndexed(t, s, "fixture tasks", john, jane)
+
+	var created []*task.Task
+	for i := 0; i < 5; i++ {
+		newTask, err := s.CreateTask(&task.CreateTaskRequest{Title: fmt.Sprintf("Task %d", i)}, john)
+		require.NoError(t, err)
+		created = append(created, newTask)
+	}
+	assertIndexed(t, s, "created", john, jane)
+
+	_, err := s.CompleteTask(created[0].ID, john)
+	require.NoError(t, err)
+	inProgress := task.StatusInProgress
+	_, err = s.UpdateTask(created[1].ID, &task.UpdateTaskRequest{Status: &inProgress}, john)
+	require.NoError(t, err)
+	assertIndexed(t, s, "status changed", john, jane)
+
+	_, err = s.ArchiveTask(created[2].ID, john)
+	require.NoError(t, err)
+	assertIndexed(t, s, "archived", john, jane)
+
+	_, err = s.ShareTask(created[3].ID, &task.ShareTaskRequest{UserID: jane}, john)
+	require.NoError(t, err)
+	_, err = s.AssignTask(created[4].ID, &task.AssignTaskRequest{UserID: &jane}, john)
+	require.NoError(t, err)
+	assertIndexed(t, s, "shared and assigned", john, jane)
+
+	_, err = s.UnshareTask(created[3].ID, jane, john)
+	require.NoError(t, err)
+	_, err = s.UnarchiveTask(created[2].ID, john)
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteTask(created[4].ID, john))
+	assertIndexed(t, s, "unshared and deleted", john, jane)
+
+	// Listings the index cannot serve fall back to scanning
+	_, _, ok := s.listIndexed(&task.TaskFilter{Search: "task"}, nil, 1, 10, john)
+	assert.False(t, ok)
+	_, _, ok = s.listIndexed(nil, &task.TaskSort{Field: "title", Order: "asc"}, 1, 10, john)
+	assert.False(t, ok)
+	listed, info, err := s.ListTasks(&task.TaskFilter{Search: "task 3"}, nil, 1, 10, john)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, created[3].ID, listed[0].ID)
+	assert.Equal(t, int64(1), info.Total)
+}
@@ -0,0 +1,87 @@
+package task
+
+import (
+	"sort"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// DueTasks returns a copy of the open tasks of every tenant due before the time, soonest
+// first. Archived tasks are left out.
+func (s *service) DueTasks(before time.Time) []*task.Task {
+	s.mu.RLock()
+	var due []*task.Task
+	for _, t := range s.tasks {
+		if isOpen(t) && t.DueDate != nil && t.DueDate.Before(before) {
+			due = append(due, t.Snapshot())
+		}
+	}
+	s.mu.RUnlock()
+
+	sortByDueDate(due)
+	return due
+}
+
+// OpenTasks returns a copy of the open tasks the user owns or is assigned to, soonest due
+// first and tasks without a due date last. Archived tasks are left out.
+func (s *service) OpenTasks(userID uuid.UUID) []*task.Task {
+	s.mu.RLock()
+	var open []*task.Task
+	for _, t := range s.tasks {
+		if isOpen(t) && (t.UserID == userID || (t.AssigneeID != nil && *t.AssigneeID == userID)) {
+			open = append(open, t.Snapshot())
+		}
+	}
+	s.mu.RUnlock()
+
+	sortByDueDate(open)
+	return open
+}
+
+// FindDuplicate returns the most recent open personal task of the user created since the
+// time whose title matches once normalized, or nil. Workspace tasks are not compared.
+func (s *service) FindDuplicate(title string, since time.Time, userID uuid.UUID) *task.Task {
+	normalized := task.NormalizeTitle(title)
+	if normalized == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var duplicate *task.Task
+	for _, t := range s.tasks {
+		if t.UserID != userID || t.WorkspaceID != nil || !isOpen(t) || t.CreatedAt.Before(since) {
+			continue
+		}
+		if task.NormalizeTitle(t.Title) == normalized && (duplicate == nil || t.CreatedAt.After(duplicate.CreatedAt)) {
+			duplicate = t
+		}
+	}
+	if duplicate == nil {
+		return nil
+	}
+	return duplicate.Snapshot()
+}
+
+// isOpen reports whether the task is neither closed nor archived
+func isOpen(t *task.Task) bool {
+	return !t.Status.IsClosed() && !t.IsArchived()
+}
+
+// sortByDueDate sorts tasks soonest due first, with tasks without a due date last
+func sortByDueDate(tasks []*task.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].DueDate, tasks[j].DueDate
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+}
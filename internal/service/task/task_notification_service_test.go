@@ -0,0 +1,111 @@
+package task
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_DueAndOpenTasks(t *testing.T) {
+	service, _, _ := setupWorkspaceService(t)
+	now := time.Now()
+	dueAt := func(d time.Duration) *time.Time {
+		due := now.Add(d)
+		return &due
+	}
+
+	later, err := service.CreateTask(&task.CreateTaskRequest{Title: "Later", DueDate: dueAt(48 * time.Hour)}, johnID)
+	require.NoError(t, err)
+	soon, err := service.CreateTask(&task.CreateTaskRequest{Title: "Soon", DueDate: dueAt(time.Hour)}, johnID)
+	require.NoError(t, err)
+	overdue, err := service.CreateTask(&task.CreateTaskRequest{Title: "Overdue", DueDate: dueAt(-time.Hour)}, janeID)
+	require.NoError(t, err)
+	done, err := service.CreateTask(&task.CreateTaskRequest{Title: "Done", DueDate: dueAt(time.Hour)}, johnID)
+	require.NoError(t, err)
+	_, err = service.CompleteTask(done.ID, johnID)
+	require.NoError(t, err)
+	archived, err := service.CreateTask(&task.CreateTaskRequest{Title: "Archived", DueDate: dueAt(time.Hour)}, johnID)
+	require.NoError(t, err)
+	_, err = service.ArchiveTask(archived.ID, johnID)
+	require.NoError(t, err)
+
+	// Closed and archived tasks are left out
+	due := service.DueTasks(now.Add(24 * time.Hour))
+	require.Len(t, due, 2)
+	assert.Equal(t, overdue.ID, due[0].ID)
+	assert.Equal(t, soon.ID, due[1].ID)
+
+	// Assigned tasks are open tasks of the assignee, and tasks without a due date come last
+	_, err = service.AssignTask(overdue.ID, &task.AssignTaskRequest{UserID: &johnID}, janeID)
+	require.NoError(t, err)
+	open := service.OpenTasks(johnID)
+	require.GreaterOrEqual(t, len(open), 3)
+	assert.Equal(t, []string{"Overdue", "Soon", "Later"}, []string{open[0].Title, open[1].Title, open[2].Title})
+	assert.Equal(t, later.ID, open[2].ID)
+	for _, other := range open[3:] {
+		assert.Nil(t, other.DueDate)
+	}
+}
+
+func TestService_DueTasks_ConcurrentChanges(t *testing.T) {
+	service, _, _ := setupWorkspaceService(t)
+	dueDate := time.Now().Add(time.Hour)
+	before := service.GetTaskUsage(johnID).Used
+
+	// Background jobs read tasks while requests create and change them; run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Concurrent", DueDate: &dueDate}, johnID)
+				require.NoError(t, err)
+				_, err = service.CompleteTask(created.ID, johnID)
+				require.NoError(t, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				for _, due := range service.DueTasks(dueDate.Add(time.Minute)) {
+					assert.NotEmpty(t, due.Title)
+				}
+				service.OpenTasks(johnID)
+				service.AllTasks()
+				_, _, err := service.ListTasks(nil, nil, 1, 10, johnID)
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, service.DueTasks(dueDate.Add(time.Minute)))
+	assert.Equal(t, before+100, service.GetTaskUsage(johnID).Used)
+}
+
+func TestService_FindDuplicate(t *testing.T) {
+	service, _, _ := setupWorkspaceService(t)
+	since := time.Now().Add(-task.DuplicateWindow)
+
+	rent, err := service.CreateTask(&task.CreateTaskRequest{Title: "Pay rent for October"}, johnID)
+	require.NoError(t, err)
+
+	found := service.FindDuplicate("pay rent  for October!", since, johnID)
+	require.NotNil(t, found)
+	assert.Equal(t, rent.ID, found.ID)
+
+	// Other users' tasks, tasks created before the window, and closed tasks are not duplicates
+	assert.Nil(t, service.FindDuplicate("Pay rent for October", since, janeID))
+	assert.Nil(t, service.FindDuplicate("Pay rent for October", time.Now().Add(time.Minute), johnID))
+	_, err = service.CompleteTask(rent.ID, johnID)
+	require.NoError(t, err)
+	assert.Nil(t, service.FindDuplicate("Pay rent for October", since, johnID))
+
+	assert.Nil(t, service.FindDuplicate("?!", since, johnID))
+}
@@ -0,0 +1,213 @@
+package task
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// presenceHub tracks the users who have tasks open in WebSocket sessions and delivers
+// presence changes to subscribers. Sessions update it from their own goroutines, so it has
+// a mutex of its own, always locked after the lock of the service.
+type presenceHub struct {
+	mu          sync.Mutex
+	tasks       map[uuid.UUID]map[string]*presenceSession // sessions by task and session ID
+	subscribers map[*presenceSubscriber]struct{}
+}
+
+// presenceSession is a WebSocket session that has a task open
+type presenceSession struct {
+	userID uuid.UUID
+	state  task.PresenceState
+	since  time.Time // when the session opened the task
+}
+
+// presenceSubscriber receives the presence changes a user may see
+type presenceSubscriber struct {
+	userID uuid.UUID
+	events chan *task.PresenceEvent
+}
+
+// newPresenceHub creates a presence hub without sessions
+func newPresenceHub() *presenceHub {
+	return &presenceHub{
+		tasks:       make(map[uuid.UUID]map[string]*presenceSession),
+		subscribers: make(map[*presenceSubscriber]struct{}),
+	}
+}
+
+// UpdatePresence records the presence of the user on a task in a WebSocket session. A
+// change of what the user is doing with the task, across all their sessions, is sent to
+// the other users who can see the task.
+func (s *service) UpdatePresence(session string, taskID uuid.UUID, state task.PresenceState, userID uuid.UUID) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, err := s.authorize(taskID, userID, task.ActionRead)
+	if err != nil {
+		return err
+	}
+
+	h := s.presence
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	before := h.stateOf(taskID, userID)
+	if state == task.PresenceLeft {
+		h.leave(taskID, session)
+	} else {
+		if h.tasks[taskID] == nil {
+			h.tasks[taskID] = make(map[string]*presenceSession)
+		}
+		if entry, ok := h.tasks[taskID][session]; ok {
+			entry.state = state
+		} else {
+			h.tasks[taskID][session] = &presenceSession{userID: userID, state: state, since: time.Now()}
+		}
+	}
+
+	if after := h.stateOf(taskID, userID); after != before {
+		s.broadcastPresence(t, userID, after)
+	}
+	return nil
+}
+
+// EndPresence removes a closed WebSocket session from all the tasks it had open
+func (s *service) EndPresence(session string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h := s.presence
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for taskID, sessions := range h.tasks {
+		entry, ok := sessions[session]
+		if !ok {
+			continue
+		}
+		before := h.stateOf(taskID, entry.userID)
+		h.leave(taskID, session)
+
+		// Deleted tasks have no one left to tell
+		t, exists := s.tasks[taskID]
+		if after := h.stateOf(taskID, entry.userID); exists && after != before {
+			s.broadcastPresence(t, entry.userID, after)
+		}
+	}
+}
+
+// TaskPresence returns the users who have the task open, the longest-present first
+func (s *service) TaskPresence(taskID uuid.UUID, userID uuid.UUID) ([]task.Presence, error) {
+	s.mu.RLock()
+	_, err := s.authorize(taskID, userID, task.ActionRead)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	h := s.presence
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// One entry per user, editing in any of their sessions
+	byUser := make(map[uuid.UUID]*task.Presence)
+	presence := []task.Presence{}
+	for _, entry := range h.tasks[taskID] {
+		p, ok := byUser[entry.userID]
+		if !ok {
+			p = &task.Presence{UserID: entry.userID, State: entry.state, Since: entry.since}
+			byUser[entry.userID] = p
+			continue
+		}
+		if entry.state == task.PresenceEditing {
+			p.State = task.PresenceEditing
+		}
+		if entry.since.Before(p.Since) {
+			p.Since = entry.since
+		}
+	}
+	for _, p := range byUser {
+		presence = append(presence, *p)
+	}
+	slices.SortFunc(presence, func(a, b task.Presence) int {
+		return a.Since.Compare(b.Since)
+	})
+	return presence, nil
+}
+
+// SubscribePresence registers for presence changes on the tasks the user can see, other
+// than their own. The returned function must be called to unsubscribe.
+func (s *service) SubscribePresence(userID uuid.UUID) (<-chan *task.PresenceEvent, func()) {
+	h := s.presence
+	sub := &presenceSubscriber{userID: userID, events: make(chan *task.PresenceEvent, subscriberBuffer)}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, sub)
+			h.mu.Unlock()
+			close(sub.events)
+		})
+	}
+
+	return sub.events, unsubscribe
+}
+
+// broadcastPresence sends the user's new state on the task to the subscribers who can see
+// the task. The service and the hub must be locked.
+func (s *service) broadcastPresence(t *task.Task, userID uuid.UUID, state task.PresenceState) {
+	event := &task.PresenceEvent{
+		Type:        task.EventTaskPresence,
+		TaskID:      t.ID,
+		UserID:      userID,
+		State:       state,
+		OccurredAt:  time.Now(),
+		Audience:    append([]uuid.UUID{t.UserID}, t.Viewers()...),
+		WorkspaceID: t.WorkspaceID,
+	}
+
+	for sub := range s.presence.subscribers {
+		if !event.VisibleTo(sub.userID) && (sub.userID == userID || !s.isWorkspaceMember(event.WorkspaceID, sub.userID)) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// Slow subscriber, drop the event rather than block other sessions
+		}
+	}
+}
+
+// stateOf returns what the user is doing with the task across their sessions: editing in
+// any of them, otherwise viewing in any of them, otherwise left
+func (h *presenceHub) stateOf(taskID uuid.UUID, userID uuid.UUID) task.PresenceState {
+	state := task.PresenceLeft
+	for _, entry := range h.tasks[taskID] {
+		if entry.userID != userID {
+			continue
+		}
+		if entry.state == task.PresenceEditing {
+			return task.PresenceEditing
+		}
+		state = task.PresenceViewing
+	}
+	return state
+}
+
+// leave removes the session from the task
+func (h *presenceHub) leave(taskID uuid.UUID, session string) {
+	delete(h.tasks[taskID], session)
+	if len(h.tasks[taskID]) == 0 {
+		delete(h.tasks, taskID)
+	}
+}
@@ -0,0 +1,91 @@
+package task
+
+import (
+	"testing"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextPresence returns the next presence event delivered to the subscriber
+func nextPresence(t *testing.T, events <-chan *task.PresenceEvent) *task.PresenceEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	default:
+		t.Fatal("no presence event delivered")
+		return nil
+	}
+}
+
+func TestService_Presence(t *testing.T) {
+	service, workspaceID, _ := setupWorkspaceService(t)
+
+	teamTask, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Team"}, janeID)
+	require.NoError(t, err)
+
+	johnEvents, unsubscribeJohn := service.SubscribePresence(johnID)
+	defer unsubscribeJohn()
+	janeEvents, unsubscribeJane := service.SubscribePresence(janeID)
+	defer unsubscribeJane()
+
+	require.NoError(t, service.UpdatePresence("phone", teamTask.ID, task.PresenceViewing, janeID))
+	event := nextPresence(t, johnEvents)
+	assert.Equal(t, task.EventTaskPresence, event.Type)
+	assert.Equal(t, teamTask.ID, event.TaskID)
+	assert.Equal(t, janeID, event.UserID)
+	assert.Equal(t, task.PresenceViewing, event.State)
+
+	// Editing in another session
+	require.NoError(t, service.UpdatePresence("laptop", teamTask.ID, task.PresenceEditing, janeID))
+	assert.Equal(t, task.PresenceEditing, nextPresence(t, johnEvents).State)
+
+	// Still editing on the laptop, so viewing on the phone changes nothing
+	require.NoError(t, service.UpdatePresence("phone", teamTask.ID, task.PresenceViewing, janeID))
+	assert.Empty(t, johnEvents)
+
+	presence, err := service.TaskPresence(teamTask.ID, johnID)
+	require.NoError(t, err)
+	require.Len(t, presence, 1)
+	assert.Equal(t, janeID, presence[0].UserID)
+	assert.Equal(t, task.PresenceEditing, presence[0].State)
+
+	// Closing the laptop, then leaving on the phone
+	service.EndPresence("laptop")
+	assert.Equal(t, task.PresenceViewing, nextPresence(t, johnEvents).State)
+	require.NoError(t, service.UpdatePresence("phone", teamTask.ID, task.PresenceLeft, janeID))
+	assert.Equal(t, task.PresenceLeft, nextPresence(t, johnEvents).State)
+
+	presence, err = service.TaskPresence(teamTask.ID, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, presence)
+
+	// Users do not receive their own presence
+	assert.Empty(t, janeEvents)
+}
+
+func TestService_Presence_Access(t *testing.T) {
+	service, _, _ := setupWorkspaceService(t)
+
+	private, err := service.CreateTask(&task.CreateTaskRequest{Title: "Private"}, johnID)
+	require.NoError(t, err)
+
+	janeEvents, unsubscribe := service.SubscribePresence(janeID)
+	defer unsubscribe()
+
+	require.NoError(t, service.UpdatePresence("laptop", private.ID, task.PresenceEditing, johnID))
+	assert.Empty(t, janeEvents)
+
+	assert.Error(t, service.UpdatePresence("phone", private.ID, task.PresenceViewing, janeID))
+	_, err = service.TaskPresence(private.ID, janeID)
+	assert.Error(t, err)
+
+	// Shared users see the presence of the owner
+	_, err = service.ShareTask(private.ID, &task.ShareTaskRequest{UserID: janeID}, johnID)
+	require.NoError(t, err)
+	require.NoError(t, service.UpdatePresence("laptop", private.ID, task.PresenceViewing, johnID))
+	assert.Equal(t, johnID, nextPresence(t, janeEvents).UserID)
+}
@@ -0,0 +1,69 @@
+package task
+
+import (
+	"slices"
+	"sort"
+
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// ExportUserData returns every task the user owns, archived or not, oldest first, together
+// with the activity the user performed on any task
+func (s *service) ExportUserData(userID uuid.UUID) ([]*task.Task, []*activity.Entry) {
+	s.mu.RLock()
+	var owned []*task.Task
+	for _, t := range s.tasks {
+		if t.UserID == userID {
+			owned = append(owned, t.Snapshot())
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].CreatedAt.Before(owned[j].CreatedAt)
+	})
+	return owned, s.activityService.ListByActor(userID)
+}
+
+// EraseUserData erases an account's tasks. The user's personal tasks are deleted with their
+// history, while tasks they created in workspaces are kept for the team without an owner.
+// Tasks of the deleted workspaces are deleted whoever created them. The user is removed as
+// assignee and from shares, and anonymized in the activity of the remaining tasks. It returns
+// the number of tasks deleted and anonymized.
+func (s *service) EraseUserData(userID uuid.UUID, deletedWorkspaces []uuid.UUID) (deleted, anonymized int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, t := range s.tasks {
+		inDeletedWorkspace := t.WorkspaceID != nil && slices.Contains(deletedWorkspaces, *t.WorkspaceID)
+
+		switch {
+		case inDeletedWorkspace || (t.UserID == userID && t.WorkspaceID == nil):
+			delete(s.tasks, id)
+			s.activityService.DeleteByTask(id)
+			s.publish(task.NewEvent(task.EventTaskDeleted, t))
+			deleted++
+		case t.UserID == userID:
+			t.UserID = uuid.Nil
+			s.publish(task.NewEvent(task.EventTaskUpdated, t))
+			anonymized++
+		default:
+			changes := 0
+			if t.AssigneeID != nil && *t.AssigneeID == userID {
+				changes += len(t.Assign(nil))
+			}
+			if unshared, err := t.Unshare(userID); err == nil {
+				changes += len(unshared)
+			}
+			if changes > 0 {
+				s.publish(task.NewEvent(task.EventTaskUpdated, t))
+			}
+		}
+	}
+
+	s.activityService.AnonymizeActor(userID)
+	return deleted, anonymized
+}
@@ -0,0 +1,62 @@
+package task
+
+import (
+	"testing"
+
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ExportAndEraseUserData(t *testing.T) {
+	service, workspaceID, _ := setupWorkspaceService(t)
+
+	teamTask, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Team task"}, janeID)
+	require.NoError(t, err)
+	johnTasks, _, err := service.ListTasks(nil, nil, 1, 100, johnID)
+	require.NoError(t, err)
+	johnTask := johnTasks[0]
+	_, err = service.AssignTask(johnTask.ID, &task.AssignTaskRequest{UserID: &janeID}, johnID)
+	require.NoError(t, err)
+	_, err = service.ShareTask(johnTask.ID, &task.ShareTaskRequest{UserID: janeID}, johnID)
+	require.NoError(t, err)
+
+	// Jane owns two fixture tasks and the team task
+	owned, entries := service.ExportUserData(janeID)
+	require.Len(t, owned, 3)
+	assert.Equal(t, teamTask.ID, owned[2].ID)
+	require.Len(t, entries, 1)
+	assert.Equal(t, activity.ActionCreated, entries[0].Action)
+
+	deleted, anonymized := service.EraseUserData(janeID, nil)
+	assert.Equal(t, 2, deleted)
+	assert.Equal(t, 1, anonymized)
+
+	owned, entries = service.ExportUserData(janeID)
+	assert.Empty(t, owned)
+	assert.Empty(t, entries)
+
+	// The team keeps the task, managed by the workspace admins
+	kept, err := service.GetTaskByID(teamTask.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, kept.UserID)
+
+	// Jane is removed from other tasks and their history
+	assert.Nil(t, johnTask.AssigneeID)
+	assert.Empty(t, johnTask.SharedWith)
+	history, err := service.GetTaskHistory(johnTask.ID, johnID)
+	require.NoError(t, err)
+	for _, entry := range history {
+		assert.NotEqual(t, janeID.String(), entry.NewValue)
+	}
+
+	// Tasks of deleted workspaces are deleted too
+	deleted, anonymized = service.EraseUserData(mikeID, []uuid.UUID{workspaceID})
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, 0, anonymized)
+	_, err = service.GetTaskByID(teamTask.ID, johnID)
+	assert.EqualError(t, err, "task not found")
+}
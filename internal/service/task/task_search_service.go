@@ -0,0 +1,168 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/search"
+
+	"github.com/google/uuid"
+)
+
+// SearchFieldWeights ranks title matches above matches in the rest of a task
+var SearchFieldWeights = map[string]float64{
+	"title":       3,
+	"description": 1,
+	"checklist":   1,
+}
+
+// searchDocument builds the search index document of a task
+func searchDocument(t *task.Task) search.Document {
+	items := make([]string, len(t.Checklist))
+	for i, item := range t.Checklist {
+		items[i] = item.Text
+	}
+
+	return search.Document{
+		ID: t.ID,
+		Fields: map[string]string{
+			"title":       t.Title,
+			"description": t.Description,
+			"checklist":   strings.Join(items, "\n"),
+		},
+	}
+}
+
+// publish publishes a task change, first applying it to the listing index, the storage usage,
+// and the search index unless the latter is updated from the event bus, and invalidating the
+// cached reads it affects. The caller must hold the lock.
+func (s *service) publish(event *task.Event) {
+	s.indexListing(event.TaskID)
+	s.measureTask(event.TaskID)
+	if s.syncIndex {
+		s.indexEvent(event)
+	} else {
+		s.markUnindexed(event.TaskID)
+	}
+	s.invalidateCache(event)
+	s.recordChange(event)
+
+	s.eventBus.Publish(event)
+}
+
+// indexEvent applies a task change to the search index updated as part of each change.
+// Failures are logged; the task is indexed again on its next change.
+func (s *service) indexEvent(event *task.Event) {
+	var err error
+	if event.Type == task.EventTaskDeleted {
+		err = s.index.Remove(event.TaskID)
+	} else {
+		err = s.index.Index(searchDocument(event.Task))
+	}
+
+	if err != nil {
+		log.Printf("Failed to update search index for task %s: %v", event.TaskID, err)
+	}
+}
+
+// markUnindexed records that the task changed after it was last indexed. The caller must
+// hold the lock.
+func (s *service) markUnindexed(taskID uuid.UUID) {
+	s.lastChange++
+	s.unindexed[taskID] = s.lastChange
+}
+
+// reindex applies the current state of a task marked unindexed to the search index, indexing
+// it or removing it once deleted. The task stays marked if the update fails, or if it changed
+// again meanwhile.
+func (s *service) reindex(taskID uuid.UUID) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	s.mu.RLock()
+	change, pending := s.unindexed[taskID]
+	t, exists := s.tasks[taskID]
+	var doc search.Document
+	if exists {
+		doc = searchDocument(t)
+	}
+	s.mu.RUnlock()
+	if !pending {
+		return nil
+	}
+
+	var err error
+	if exists {
+		err = s.index.Index(doc)
+	} else {
+		err = s.index.Remove(taskID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.unindexed[taskID] == change {
+		delete(s.unindexed, taskID)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// ReconcileSearchIndex indexes again every task marked unindexed, stopping at the first
+// failure, as the index is likely unreachable
+func (s *service) ReconcileSearchIndex(ctx context.Context) error {
+	s.mu.RLock()
+	taskIDs := make([]uuid.UUID, 0, len(s.unindexed))
+	for taskID := range s.unindexed {
+		taskIDs = append(taskIDs, taskID)
+	}
+	s.mu.RUnlock()
+
+	for i, taskID := range taskIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.reindex(taskID); err != nil {
+			return fmt.Errorf("%d of %d tasks indexed: %w", i, len(taskIDs), err)
+		}
+	}
+	return nil
+}
+
+// SearchTasks returns up to limit tasks visible to the user matching the query, best match
+// first. Like listings, archived tasks are excluded.
+func (s *service) SearchTasks(query string, limit int, userID uuid.UUID) ([]*task.SearchHit, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query is required")
+	}
+
+	tenantID := s.tenants.TenantOf(userID)
+
+	indexHits, err := s.index.Search(query)
+	if err != nil {
+		return nil, fmt.Errorf("search index: %w", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hits := []*task.SearchHit{}
+	for _, hit := range indexHits {
+		if len(hits) == limit {
+			break
+		}
+
+		t, exists := s.tasks[hit.ID]
+		if !exists || t.TenantID != tenantID || !s.can(t, userID, task.ActionRead) || t.IsArchived() {
+			continue
+		}
+		hits = append(hits, &task.SearchHit{Task: t.Snapshot(), Score: hit.Score})
+	}
+
+	return hits, nil
+}
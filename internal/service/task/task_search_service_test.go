@@ -0,0 +1,250 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/search"
+	"todo-api/internal/service/auth"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hitIDs(hits []*task.SearchHit) []uuid.UUID {
+	ids := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Task.ID
+	}
+	return ids
+}
+
+func TestService_SearchTasks(t *testing.T) {
+	service, _ := setupTenantService(t)
+
+	release, err := service.CreateTask(&task.CreateTaskRequest{Title: "Prepare release", Description: "Update the changelog"}, johnID)
+	require.NoError(t, err)
+	changelog, err := service.CreateTask(&task.CreateTaskRequest{Title: "Changelog tooling"}, johnID)
+	require.NoError(t, err)
+
+	// Seeded tasks are indexed too
+	hits, err := service.SearchTasks("documentation", 20, johnID)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "Complete project documentation", hits[0].Task.Title)
+
+	// Title matches rank first, the last term matches as a prefix
+	hits, err = service.SearchTasks("changel", 20, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{changelog.ID, release.ID}, hitIDs(hits))
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	hits, err = service.SearchTasks("changel", 1, johnID)
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	// The index follows updates, checklist changes and deletes
+	_, err = service.UpdateTask(release.ID, &task.UpdateTaskRequest{Description: stringPtr("Tag the version")}, johnID)
+	require.NoError(t, err)
+	_, err = service.AddChecklistItem(changelog.ID, &task.AddChecklistItemRequest{Text: "Pick a version scheme"}, johnID)
+	require.NoError(t, err)
+
+	hits, err = service.SearchTasks("version", 20, johnID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{release.ID, changelog.ID}, hitIDs(hits))
+
+	require.NoError(t, service.DeleteTask(changelog.ID, johnID))
+	hits, err = service.SearchTasks("changelog", 20, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// Archived tasks are excluded
+	_, err = service.ArchiveTask(release.ID, johnID)
+	require.NoError(t, err)
+	hits, err = service.SearchTasks("tag", 20, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	_, err = service.SearchTasks("  ", 20, johnID)
+	require.Error(t, err)
+	assert.Equal(t, "query is required", err.Error())
+}
+
+func TestService_SearchTasks_Visibility(t *testing.T) {
+	service, _ := setupTenantService(t)
+
+	private, err := service.CreateTask(&task.CreateTaskRequest{Title: "Quarterly budget"}, johnID)
+	require.NoError(t, err)
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Quarterly budget"}, aliceID)
+	require.NoError(t, err)
+
+	// Tasks of other users and other tenants are not found
+	hits, err := service.SearchTasks("budget", 20, janeID)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	hits, err = service.SearchTasks("budget", 20, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{private.ID}, hitIDs(hits))
+
+	// Shared tasks are found
+	_, err = service.ShareTask(private.ID, &task.ShareTaskRequest{UserID: janeID}, johnID)
+	require.NoError(t, err)
+
+	hits, err = service.SearchTasks("budget", 20, janeID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{private.ID}, hitIDs(hits))
+}
+
+func TestService_SearchTasks_AsyncIndex(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	service := NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceService.NewService(authSvc),
+		Index: search.NewMemoryIndex(nil)})
+
+	// Seeded tasks are indexed up front
+	hits, err := service.SearchTasks("documentation", 20, johnID)
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	// Changes become searchable once delivered by the event bus
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Water the plants"}, johnID)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		hits, err := service.SearchTasks("plants", 20, johnID)
+		return err == nil && len(hits) == 1 && hits[0].Task.ID == created.ID
+	}, time.Second, 10*time.Millisecond)
+}
+
+// failingIndex is a search index whose updates fail while fail is set, like a cluster that
+// cannot be reached
+type failingIndex struct {
+	index    search.Index
+	mu       sync.Mutex
+	fail     bool
+	attempts int
+}
+
+func (i *failingIndex) setFailing(fail bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.fail = fail
+}
+
+func (i *failingIndex) attempted() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.attempts
+}
+
+func (i *failingIndex) Index(doc search.Document) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.attempts++
+	if i.fail {
+		return errors.New("connection refused")
+	}
+	return i.index.Index(doc)
+}
+
+func (i *failingIndex) Remove(id uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.attempts++
+	if i.fail {
+		return errors.New("connection refused")
+	}
+	return i.index.Remove(id)
+}
+
+func (i *failingIndex) Search(query string) ([]search.Hit, error) {
+	return i.index.Search(query)
+}
+
+// droppingBus is an event bus dropping every event, like a bus whose subscriber buffers are
+// full
+type droppingBus struct {
+	events.Bus
+}
+
+func (droppingBus) Publish(events.Event) {}
+
+func TestService_ReconcileSearchIndex(t *testing.T) {
+	authSvc := auth.NewService(&config.Config{})
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	index := &failingIndex{index: search.NewMemoryIndex(nil)}
+	service := NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceService.NewService(authSvc), Index: index})
+	ctx := context.Background()
+
+	// Failed updates are not retried by the event bus
+	index.setFailing(true)
+	attempts := index.attempted()
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Water the plants"}, johnID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return index.attempted() > attempts }, time.Second, 10*time.Millisecond)
+	hits, err := service.SearchTasks("plants", 20, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// but by reconciling, once the index is reachable again
+	assert.ErrorContains(t, service.ReconcileSearchIndex(ctx), "0 of 1 tasks indexed: connection refused")
+	index.setFailing(false)
+	require.NoError(t, service.ReconcileSearchIndex(ctx))
+	hits, err = service.SearchTasks("plants", 20, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{created.ID}, hitIDs(hits))
+
+	// Reconciled tasks are not indexed again
+	attempts = index.attempted()
+	require.NoError(t, service.ReconcileSearchIndex(ctx))
+	assert.Equal(t, attempts, index.attempted())
+}
+
+func TestService_ReconcileSearchIndex_DroppedEvents(t *testing.T) {
+	authSvc := auth.NewService(&config.Config{})
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	service := NewServiceWithDeps(Deps{Auth: authSvc, Bus: droppingBus{bus}, Workspaces: workspaceService.NewService(authSvc),
+		Index: search.NewMemoryIndex(nil)})
+	ctx := context.Background()
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Water the plants"}, johnID)
+	require.NoError(t, err)
+	seeded, _, err := service.ListTasks(&task.TaskFilter{}, nil, 1, 10, johnID)
+	require.NoError(t, err)
+	documentation := seeded[len(seeded)-1]
+	require.Equal(t, "Complete project documentation", documentation.Title)
+	require.NoError(t, service.DeleteTask(documentation.ID, johnID))
+
+	hits, err := service.SearchTasks("plants", 20, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// Changes whose events were dropped reach the index by reconciling
+	require.NoError(t, service.ReconcileSearchIndex(ctx))
+	hits, err = service.SearchTasks("plants", 20, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{created.ID}, hitIDs(hits))
+	hits, err = service.SearchTasks("documentation", 20, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
@@ -1,11 +1,26 @@
 package task
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
 	"sort"
-	"strings"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"todo-api/internal/cache"
+	"todo-api/internal/domain/activity"
+	"todo-api/internal/domain/billing"
 	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/metrics"
+	"todo-api/internal/policy"
+	"todo-api/internal/search"
+	"todo-api/internal/seed"
+	activityService "todo-api/internal/service/activity"
 	authService "todo-api/internal/service/auth"
 	"todo-api/pkg/types"
 
@@ -16,62 +31,238 @@ import (
 type Service interface {
 	CreateTask(req *task.CreateTaskRequest, userID uuid.UUID) (*task.Task, error)
 	GetTaskByID(id uuid.UUID, userID uuid.UUID) (*task.Task, error)
+	// AuthorizeTask finds a task the user may perform the action on, for features built on
+	// tasks such as attachments
+	AuthorizeTask(id uuid.UUID, userID uuid.UUID, action string) (*task.Task, error)
 	UpdateTask(id uuid.UUID, req *task.UpdateTaskRequest, userID uuid.UUID) (*task.Task, error)
 	DeleteTask(id uuid.UUID, userID uuid.UUID) error
+	MoveTask(id uuid.UUID, req *task.MoveTaskRequest, userID uuid.UUID) (*task.Task, error)
+	CompleteTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error)
+	ReopenTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error)
+	ArchiveTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error)
+	UnarchiveTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error)
+	AddChecklistItem(id uuid.UUID, req *task.AddChecklistItemRequest, userID uuid.UUID) (*task.Task, error)
+	UpdateChecklistItem(id, itemID uuid.UUID, req *task.UpdateChecklistItemRequest, userID uuid.UUID) (*task.Task, error)
+	MoveChecklistItem(id, itemID uuid.UUID, req *task.MoveChecklistItemRequest, userID uuid.UUID) (*task.Task, error)
+	RemoveChecklistItem(id, itemID uuid.UUID, userID uuid.UUID) (*task.Task, error)
+	AssignTask(id uuid.UUID, req *task.AssignTaskRequest, userID uuid.UUID) (*task.Task, error)
+	ShareTask(id uuid.UUID, req *task.ShareTaskRequest, userID uuid.UUID) (*task.Task, error)
+	UnshareTask(id, sharedUserID uuid.UUID, userID uuid.UUID) (*task.Task, error)
 	ListTasks(filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, error)
+	ImportTasks(reqs []*task.ImportTaskRequest, userID uuid.UUID) (*task.ImportResult, error)
+	GetStats(r *task.StatsRange, userID uuid.UUID) (*task.Stats, error)
+	GetTaskUsage(userID uuid.UUID) task.Usage
+	SearchTasks(query string, limit int, userID uuid.UUID) ([]*task.SearchHit, error)
+	// ReconcileSearchIndex indexes again the tasks whose changes did not reach a search index
+	// updated from the event bus, because the update failed or the event was dropped. It is
+	// run by the scheduler.
+	ReconcileSearchIndex(ctx context.Context) error
+	ExportTasks(filter *task.TaskFilter, sort *task.TaskSort, userID uuid.UUID, fn func(*task.Task) error) error
+	GetTaskHistory(id uuid.UUID, userID uuid.UUID) ([]*activity.Entry, error)
+	ExportUserData(userID uuid.UUID) ([]*task.Task, []*activity.Entry)
+	EraseUserData(userID uuid.UUID, deletedWorkspaces []uuid.UUID) (deleted, anonymized int)
+	Subscribe(userID uuid.UUID) (<-chan *task.Event, func())
+	CreateWorkspaceTask(workspaceID uuid.UUID, req *task.CreateTaskRequest, userID uuid.UUID) (*task.Task, error)
+	ListWorkspaceTasks(workspaceID uuid.UUID, filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, error)
+	// WorkspaceAgingReport reports how long the tasks of the workspace stay open at the time,
+	// from their activity history
+	WorkspaceAgingReport(workspaceID uuid.UUID, now time.Time, userID uuid.UUID) (*task.AgingReport, error)
+	GetWorkspaceStats(workspaceID uuid.UUID, r *task.StatsRange, userID uuid.UUID) (*task.Stats, error)
+	// ProjectBurndown returns the open and closed tasks of the project at the end of each day
+	// of the range, without checking access
+	ProjectBurndown(projectID uuid.UUID, r *task.StatsRange) []task.BurndownDay
+	DueTasks(before time.Time) []*task.Task
+	OpenTasks(userID uuid.UUID) []*task.Task
+	// AllTasks returns every task, archived ones included, oldest first, without checking
+	// access, for exports
+	AllTasks() []*task.Task
+	// CheckStorage returns task.ErrStorageFull once the storage budget leaves no room for
+	// new tasks, for health checks
+	CheckStorage() error
+	// FindDuplicate returns the most recent open personal task of the user created since the
+	// time with the same normalized title, or nil
+	FindDuplicate(title string, since time.Time, userID uuid.UUID) *task.Task
+	// TaskChanges returns the tasks created, updated, and deleted for the user since a sync
+	// cursor, for offline clients
+	TaskChanges(since string, limit int, userID uuid.UUID) (*task.ChangeSet, error)
+	// ResolveTask applies the merge of a conflicting offline edit with the server copy of a
+	// task; the merge must carry the version of the server copy it is based on
+	ResolveTask(id uuid.UUID, req *task.UpdateTaskRequest, userID uuid.UUID) (*task.Task, error)
+	// UpdatePresence records the presence of the user on a task in a WebSocket session and
+	// sends changes to the other users who can see the task
+	UpdatePresence(session string, taskID uuid.UUID, state task.PresenceState, userID uuid.UUID) error
+	// EndPresence removes a closed WebSocket session from all the tasks it had open
+	EndPresence(session string)
+	// TaskPresence returns the users who have the task open
+	TaskPresence(taskID uuid.UUID, userID uuid.UUID) ([]task.Presence, error)
+	// SubscribePresence registers for presence changes on the tasks the user can see.
+	// The returned function must be called to unsubscribe.
+	SubscribePresence(userID uuid.UUID) (<-chan *task.PresenceEvent, func())
+	// CreateShareLink creates a read-only link to a task or project for people without an
+	// account, returning its token once
+	CreateShareLink(target task.ShareTarget, req *task.CreateShareLinkRequest, userID uuid.UUID) (*task.CreatedShareLink, error)
+	ListShareLinks(target task.ShareTarget, userID uuid.UUID) ([]*task.ShareLink, error)
+	RevokeShareLink(target task.ShareTarget, linkID uuid.UUID, userID uuid.UUID) (*task.ShareLink, error)
+	// ViewShareLink returns the task or project of a share link token, counting the view
+	ViewShareLink(token string) (*task.SharedView, error)
+	// ProjectBoard returns a page of the board of the project of a share link token,
+	// counting the view
+	ProjectBoard(token string, page, limit int) (*task.ProjectBoard, *types.PaginationInfo, error)
+}
+
+// LimitsDirectory resolves the task limits of each user, such as those of their plan.
+// task.Limits is a directory applying the same limits to every user.
+type LimitsDirectory interface {
+	LimitsOf(userID uuid.UUID) task.Limits
 }
 
 // service implements the task service
 type service struct {
-	tasks       map[uuid.UUID]*task.Task // Mock task storage
-	authService authService.Service
+	// mu guards tasks and the tasks in it, shareLinks, listings, changes, and unindexed.
+	// Background jobs read tasks concurrently with requests changing them.
+	mu              sync.RWMutex
+	tasks           map[uuid.UUID]*task.Task // Mock task storage
+	authService     authService.Service
+	activityService activityService.Service
+	eventBus        events.Bus
+	workspaces      WorkspaceDirectory
+	tenants         TenantDirectory
+	limits          LimitsDirectory
+	index           search.Index
+	syncIndex       bool // update the index as part of each change rather than from the event bus
+	// unindexed holds the tasks changed since they were last indexed, when the index is
+	// updated from the event bus, with the number of their last change. indexMu serializes
+	// those updates, and is always locked before mu.
+	unindexed  map[uuid.UUID]uint64
+	indexMu    sync.Mutex
+	lastChange uint64
+	listings   *listingIndex
+	flights    *listFlights
+	storage    *storageUsage
+	changes    *changeLog
+	presence   *presenceHub
+	shareLinks map[string]*task.ShareLink // by hash of their token
+	policy     *policy.Enforcer
+	// cache, if set, holds reads of tasks by ID and first pages of listings for cacheTTL,
+	// invalidated as tasks change
+	cache              cache.Cache
+	cacheTTL           time.Duration
+	cacheInvalidations atomic.Uint64
+}
+
+// subscriberBuffer is the number of events buffered per subscriber before events are dropped
+const subscriberBuffer = 16
+
+// Deps are the dependencies of a task service. Auth is required; the others are optional,
+// and a zero value keeps the default noted next to each.
+type Deps struct {
+	Auth       authService.Service
+	Bus        events.Bus         // in-process channel bus
+	Workspaces WorkspaceDirectory // no workspaces
+	Tenants    TenantDirectory    // a single tenant
+	Limits     LimitsDirectory    // no limits
+	// Index searches tasks, such as an Elasticsearch cluster. It is updated asynchronously
+	// from the event bus, so changes become searchable shortly after they are made. Tasks are
+	// searched through an in-memory index updated as part of each change when it is nil.
+	Index search.Index
+	// Cache, if set, holds tasks read by ID and the first page of each task listing for
+	// CacheTTL, invalidated by the events of the tasks they hold as part of each change
+	Cache    cache.Cache
+	CacheTTL time.Duration
+	// StorageBudget caps the tasks stored across every user; new tasks beyond it are rejected
+	StorageBudget task.StorageBudget
+	// Registry exposes the number and estimated size of the stored tasks, and the tasks
+	// rejected; they are kept private when it is nil
+	Registry *metrics.Registry
 }
 
-// NewService creates a new task service
+// NewService creates a new task service with an in-process event bus
 func NewService(authSvc authService.Service) Service {
-	// Initialize mock tasks
-	tasks := make(map[uuid.UUID]*task.Task)
+	return NewServiceWithDeps(Deps{Auth: authSvc})
+}
 
-	// Get actual user IDs from auth service
-	user1, _ := authSvc.GetUserByEmail("john.doe@example.com")
-	user2, _ := authSvc.GetUserByEmail("jane.smith@example.com")
+// NewServiceWithEventBus creates a new task service publishing domain events to the given bus
+func NewServiceWithEventBus(authSvc authService.Service, bus events.Bus) Service {
+	return NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus})
+}
 
-	if user1 != nil {
-		// Tasks for user 1
-		task1 := task.NewTask(
-			"Complete project documentation",
-			user1.ID,
-		)
-		task1.Status = task.StatusInProgress
-		tasks[task1.ID] = task1
+// NewServiceWithDeps creates a new task service with the tasks of the default fixtures, using
+// the defaults of Deps for the dependencies left unset
+func NewServiceWithDeps(deps Deps) Service {
+	if deps.Bus == nil {
+		deps.Bus = events.NewChannelBus(events.DefaultBufferSize)
+	}
+	if deps.Workspaces == nil {
+		deps.Workspaces = noWorkspaces{}
+	}
+	if deps.Tenants == nil {
+		deps.Tenants = singleTenant{}
+	}
+	if deps.Limits == nil {
+		deps.Limits = task.Limits{}
+	}
+	if deps.Registry == nil {
+		deps.Registry = metrics.NewRegistry()
+	}
+	// A given index is updated by a subscriber of the event bus rather than as part of each
+	// change
+	asyncIndex := deps.Index != nil
+	if deps.Index == nil {
+		deps.Index = search.NewMemoryIndex(SearchFieldWeights)
+	}
 
-		task2 := task.NewTask(
-			"Review code changes",
-			user1.ID,
-		)
-		tasks[task2.ID] = task2
+	// Start with the tasks of the default fixtures whose owners the auth service knows
+	tasks := make(map[uuid.UUID]*task.Task)
+	for _, t := range seed.Default().NewTasks(deps.Auth) {
+		t.TenantID = deps.Tenants.TenantOf(t.UserID)
+		tasks[t.ID] = t
 	}
 
-	if user2 != nil {
-		// Tasks for user 2
-		task3 := task.NewTask(
-			"Plan team meeting",
-			user2.ID,
-		)
-		task3.Status = task.StatusCompleted
-		tasks[task3.ID] = task3
+	s := &service{
+		tasks:           tasks,
+		authService:     deps.Auth,
+		activityService: activityService.NewService(),
+		eventBus:        deps.Bus,
+		workspaces:      deps.Workspaces,
+		tenants:         deps.Tenants,
+		limits:          deps.Limits,
+		index:           deps.Index,
+		syncIndex:       !asyncIndex,
+		unindexed:       make(map[uuid.UUID]uint64),
+		listings:        newListingIndex(),
+		flights:         newListFlights(),
+		storage:         newStorageUsage(deps.StorageBudget, deps.Registry),
+		changes:         newChangeLog(),
+		presence:        newPresenceHub(),
+		shareLinks:      make(map[string]*task.ShareLink),
+		policy:          policy.Default(),
+		cache:           deps.Cache,
+		cacheTTL:        deps.CacheTTL,
+	}
 
-		task4 := task.NewTask(
-			"Update system configuration",
-			user2.ID,
-		)
-		tasks[task4.ID] = task4
+	for _, t := range tasks {
+		if err := deps.Index.Index(searchDocument(t)); err != nil {
+			log.Printf("Failed to index task %s: %v", t.ID, err)
+			if asyncIndex {
+				s.markUnindexed(t.ID)
+			}
+		}
+		s.indexListing(t.ID)
+		s.measureTask(t.ID)
 	}
 
-	return &service{
-		tasks:       tasks,
-		authService: authSvc,
+	if asyncIndex {
+		deps.Bus.Subscribe(func(event events.Event) {
+			if taskEvent, ok := event.(*task.Event); ok {
+				if err := s.reindex(taskEvent.TaskID); err != nil {
+					log.Printf("Failed to update search index for task %s, retrying on the next reconcile: %v",
+						taskEvent.TaskID, err)
+				}
+			}
+		})
 	}
+
+	return s
 }
 
 // CreateTask creates a new task
@@ -81,28 +272,166 @@ func (s *service) CreateTask(req *task.CreateTaskRequest, userID uuid.UUID) (*ta
 		return nil, err
 	}
 
+	if req.ProjectID != nil {
+		return nil, errors.New("project_id is only allowed for workspace tasks")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Create new task
+	newTask := newTaskFromRequest(req, userID)
+	if err := s.addTask(newTask); err != nil {
+		return nil, err
+	}
+
+	return newTask.Snapshot(), nil
+}
+
+// newTaskFromRequest creates a task from the fields of a create request
+func newTaskFromRequest(req *task.CreateTaskRequest, userID uuid.UUID) *task.Task {
 	newTask := task.NewTask(req.Title, userID)
+	newTask.Description = req.Description
+	if req.Priority != "" {
+		newTask.Priority = req.Priority
+	}
+	newTask.DueDate = req.DueDate
+	newTask.ProjectID = req.ProjectID
+	newTask.Tags = req.Tags
+	return newTask
+}
+
+// ImportTasks creates tasks in batch. Invalid rows are reported and skipped
+// while valid rows are still imported; rows are numbered from 1.
+func (s *service) ImportTasks(reqs []*task.ImportTaskRequest, userID uuid.UUID) (*task.ImportResult, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("import contains no rows")
+	}
+	if len(reqs) > task.MaxImportRows {
+		return nil, fmt.Errorf("import exceeds maximum of %d rows", task.MaxImportRows)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The whole import must fit within the user's task limit
+	if usage := s.taskUsage(userID); !usage.Allows(len(reqs)) {
+		if err := s.planLimitExceeded(userID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: importing %d tasks exceeds the %d remaining of %d tasks per user",
+			task.ErrLimitExceeded, len(reqs), *usage.Remaining, usage.Limit)
+	}
+
+	result := &task.ImportResult{
+		Total:  len(reqs),
+		Tasks:  []*task.Task{},
+		Errors: []task.ImportRowError{},
+	}
+
+	for i, req := range reqs {
+		if err := req.Validate(); err != nil {
+			result.Errors = append(result.Errors, task.ImportRowError{Row: i + 1, Message: err.Error()})
+			continue
+		}
+
+		newTask := task.NewTask(req.Title, userID)
+		if req.Status != "" {
+			newTask.SetStatus(req.Status)
+		}
+		if err := s.addTask(newTask); err != nil {
+			result.Errors = append(result.Errors, task.ImportRowError{Row: i + 1, Message: err.Error()})
+			continue
+		}
+
+		result.Tasks = append(result.Tasks, newTask.Snapshot())
+	}
+
+	result.Imported = len(result.Tasks)
+	result.Failed = len(result.Errors)
+
+	return result, nil
+}
+
+// addTask stores a new task in its owner's tenant at the end of its status column, records its
+// creation and publishes the created event. It fails when the owner's task limit, the tenant's
+// task quota, or the storage budget is exhausted. The caller must hold the lock.
+func (s *service) addTask(newTask *task.Task) error {
+	if usage := s.taskUsage(newTask.UserID); !usage.Allows(1) {
+		if err := s.planLimitExceeded(newTask.UserID); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: at most %d tasks per user", task.ErrLimitExceeded, usage.Limit)
+	}
+
+	newTask.TenantID = s.tenants.TenantOf(newTask.UserID)
+	if err := s.checkTaskQuota(newTask.TenantID); err != nil {
+		return err
+	}
+	if err := s.checkStorageBudget(newTask); err != nil {
+		return err
+	}
+
+	column := s.column(newTask, newTask.Status, uuid.Nil)
+	task.PlaceAt(newTask, column, len(column))
 
 	// Store task
 	s.tasks[newTask.ID] = newTask
 
-	return newTask, nil
+	// Record activity
+	s.activityService.Record(activity.NewEntry(newTask.ID, newTask.UserID, activity.ActionCreated))
+	s.publish(task.NewEvent(task.EventTaskCreated, newTask))
+
+	return nil
 }
 
 // GetTaskByID retrieves a task by ID
 func (s *service) GetTaskByID(id uuid.UUID, userID uuid.UUID) (*task.Task, error) {
-	task, exists := s.tasks[id]
-	if !exists {
+	if cached, ok := s.cachedTask(id, userID); ok {
+		return cached, nil
+	}
+
+	generation := s.cacheGeneration()
+	s.mu.RLock()
+	found, err := s.authorize(id, userID, task.ActionRead)
+	if err == nil {
+		found = found.Snapshot()
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	s.cacheSet(generation, taskGroup(id), userID.String(), found)
+	return found, nil
+}
+
+// AuthorizeTask finds a task and checks the authorization policy lets the user perform the
+// action on it
+func (s *service) AuthorizeTask(id uuid.UUID, userID uuid.UUID, action string) (*task.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found, err := s.authorize(id, userID, action)
+	if err != nil {
+		return nil, err
+	}
+	return found.Snapshot(), nil
+}
+
+// authorize finds a task and checks the authorization policy lets the user perform the
+// action on it. The caller must hold the lock.
+func (s *service) authorize(id uuid.UUID, userID uuid.UUID, action string) (*task.Task, error) {
+	// Tasks of other tenants are reported as not found
+	existing, exists := s.tasks[id]
+	if !exists || existing.TenantID != s.tenants.TenantOf(userID) {
 		return nil, errors.New("task not found")
 	}
 
-	// Check if user owns the task (or is admin)
-	if task.UserID != userID {
+	if !s.can(existing, userID, action) {
 		return nil, errors.New("access denied")
 	}
 
-	return task, nil
+	return existing, nil
 }
 
 // UpdateTask updates an existing task
@@ -112,51 +441,486 @@ func (s *service) UpdateTask(id uuid.UUID, req *task.UpdateTaskRequest, userID u
 		return nil, err
 	}
 
-	// Find task
-	task, exists := s.tasks[id]
-	if !exists {
-		return nil, errors.New("task not found")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Find task and check the user may change it
+	existing, err := s.authorize(id, userID, task.ActionUpdate)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if user owns the task (or is admin)
-	if task.UserID != userID {
-		return nil, errors.New("access denied")
+	// Check the edit is not based on a stale version of the task
+	if err := existing.CheckVersion(req); err != nil {
+		return nil, err
+	}
+
+	// Check the status change is allowed
+	if req.Status != nil {
+		if err := existing.CheckTransition(*req.Status); err != nil {
+			return nil, err
+		}
 	}
 
 	// Update task
-	task.Update(req)
+	changes := existing.Update(req)
+
+	// Record activity
+	for _, change := range changes {
+		s.activityService.Record(activity.NewFieldChange(id, userID, change.Field, change.OldValue, change.NewValue))
+	}
+	s.publish(newUpdateEvent(existing, userID, changes))
 
-	return task, nil
+	return existing.Snapshot(), nil
 }
 
 // DeleteTask deletes a task
 func (s *service) DeleteTask(id uuid.UUID, userID uuid.UUID) error {
-	// Find task
-	task, exists := s.tasks[id]
-	if !exists {
-		return errors.New("task not found")
-	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// Check if user owns the task (or is admin)
-	if task.UserID != userID {
-		return errors.New("access denied")
+	// Find task, only the owner may delete it
+	existing, err := s.authorize(id, userID, task.ActionDelete)
+	if err != nil {
+		return err
 	}
 
 	// Delete task
 	delete(s.tasks, id)
 
+	// Record activity
+	s.activityService.Record(activity.NewEntry(id, userID, activity.ActionDeleted))
+	s.publish(task.NewEvent(task.EventTaskDeleted, existing))
+
 	return nil
 }
 
-// ListTasks retrieves tasks with filtering, sorting, and pagination
+// MoveTask moves a task to a new position within its status column, or into another column
+func (s *service) MoveTask(id uuid.UUID, req *task.MoveTaskRequest, userID uuid.UUID) (*task.Task, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Find task and check the user may change it
+	existing, err := s.authorize(id, userID, task.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	targetStatus := existing.Status
+	if req.Status != nil {
+		targetStatus = *req.Status
+	}
+	if err := existing.CheckTransition(targetStatus); err != nil {
+		return nil, err
+	}
+
+	// Resolve the insertion index within the target column
+	// Columns belong to the board of the task owner or workspace
+	column := s.column(existing, targetStatus, id)
+
+	var index int
+	switch {
+	case req.Position != nil:
+		index = *req.Position
+	case req.BeforeID != nil:
+		if index = indexOf(column, *req.BeforeID); index == -1 {
+			return nil, errors.New("reference task not found in target column")
+		}
+	case req.AfterID != nil:
+		if index = indexOf(column, *req.AfterID); index == -1 {
+			return nil, errors.New("reference task not found in target column")
+		}
+		index++
+	}
+
+	var changes []task.FieldChange
+	if targetStatus != existing.Status {
+		changes = existing.Update(&task.UpdateTaskRequest{Status: &targetStatus})
+	}
+
+	oldPosition := existing.Position
+	moved := task.PlaceAt(existing, column, index)
+	existing.Touch()
+
+	// Record activity
+	changes = append(changes, task.FieldChange{
+		Field:    "position",
+		OldValue: strconv.FormatFloat(oldPosition, 'f', -1, 64),
+		NewValue: strconv.FormatFloat(existing.Position, 'f', -1, 64),
+	})
+	for _, change := range changes {
+		s.activityService.Record(activity.NewFieldChange(id, userID, change.Field, change.OldValue, change.NewValue))
+	}
+
+	// Re-indexed neighbours changed too
+	for _, t := range moved {
+		if t == existing {
+			s.publish(newUpdateEvent(t, userID, changes))
+			continue
+		}
+		s.publish(task.NewEvent(task.EventTaskUpdated, t))
+	}
+
+	return existing.Snapshot(), nil
+}
+
+// CompleteTask marks a task as completed
+func (s *service) CompleteTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+	return s.transitionTask(id, userID, (*task.Task).Complete)
+}
+
+// ReopenTask moves a completed or cancelled task back to pending
+func (s *service) ReopenTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+	return s.transitionTask(id, userID, (*task.Task).Reopen)
+}
+
+// ArchiveTask hides a task from default listings without deleting it
+func (s *service) ArchiveTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+	return s.transitionTask(id, userID, (*task.Task).Archive)
+}
+
+// UnarchiveTask restores an archived task to default listings
+func (s *service) UnarchiveTask(id uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+	return s.transitionTask(id, userID, (*task.Task).Unarchive)
+}
+
+// transitionTask applies a lifecycle change such as a status transition or archiving to a task,
+// recording the change
+func (s *service) transitionTask(id uuid.UUID, userID uuid.UUID, transition func(*task.Task) ([]task.FieldChange, error)) (*task.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Find task and check the user may change it
+	existing, err := s.authorize(id, userID, task.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := transition(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record activity
+	for _, change := range changes {
+		s.activityService.Record(activity.NewFieldChange(id, userID, change.Field, change.OldValue, change.NewValue))
+	}
+	s.publish(newUpdateEvent(existing, userID, changes))
+
+	return existing.Snapshot(), nil
+}
+
+// AddChecklistItem appends an item to a task's checklist
+func (s *service) AddChecklistItem(id uuid.UUID, req *task.AddChecklistItemRequest, userID uuid.UUID) (*task.Task, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.changeChecklist(id, userID, func(t *task.Task) (*task.FieldChange, error) {
+		item, err := t.AddChecklistItem(req.Text)
+		if err != nil {
+			return nil, err
+		}
+		return &task.FieldChange{Field: "checklist", NewValue: item.String()}, nil
+	})
+}
+
+// UpdateChecklistItem edits or toggles an item of a task's checklist
+func (s *service) UpdateChecklistItem(id, itemID uuid.UUID, req *task.UpdateChecklistItemRequest, userID uuid.UUID) (*task.Task, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.changeChecklist(id, userID, func(t *task.Task) (*task.FieldChange, error) {
+		var old string
+		for _, item := range t.Checklist {
+			if item.ID == itemID {
+				old = item.String()
+			}
+		}
+
+		item, err := t.UpdateChecklistItem(itemID, req)
+		if err != nil {
+			return nil, err
+		}
+		return &task.FieldChange{Field: "checklist", OldValue: old, NewValue: item.String()}, nil
+	})
+}
+
+// MoveChecklistItem reorders an item of a task's checklist
+func (s *service) MoveChecklistItem(id, itemID uuid.UUID, req *task.MoveChecklistItemRequest, userID uuid.UUID) (*task.Task, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.changeChecklist(id, userID, func(t *task.Task) (*task.FieldChange, error) {
+		from := 0
+		for i, item := range t.Checklist {
+			if item.ID == itemID {
+				from = i
+			}
+		}
+
+		item, err := t.MoveChecklistItem(itemID, *req.Position)
+		if err != nil {
+			return nil, err
+		}
+
+		to := min(*req.Position, len(t.Checklist)-1)
+		return &task.FieldChange{
+			Field:    "checklist",
+			OldValue: strconv.Itoa(from+1) + ". " + item.Text,
+			NewValue: strconv.Itoa(to+1) + ". " + item.Text,
+		}, nil
+	})
+}
+
+// RemoveChecklistItem deletes an item from a task's checklist
+func (s *service) RemoveChecklistItem(id, itemID uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+	return s.changeChecklist(id, userID, func(t *task.Task) (*task.FieldChange, error) {
+		item, err := t.RemoveChecklistItem(itemID)
+		if err != nil {
+			return nil, err
+		}
+		return &task.FieldChange{Field: "checklist", OldValue: item.String()}, nil
+	})
+}
+
+// changeChecklist applies a checklist change to a task, recording it in the task history
+func (s *service) changeChecklist(id uuid.UUID, userID uuid.UUID, change func(*task.Task) (*task.FieldChange, error)) (*task.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Find task and check the user may change it
+	existing, err := s.authorize(id, userID, task.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldChange, err := change(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record activity
+	s.activityService.Record(activity.NewFieldChange(id, userID, fieldChange.Field, fieldChange.OldValue, fieldChange.NewValue))
+	s.publish(newUpdateEvent(existing, userID, []task.FieldChange{*fieldChange}))
+
+	return existing.Snapshot(), nil
+}
+
+// AssignTask sets or clears the user a task is assigned to. Only the owner may assign a task.
+func (s *service) AssignTask(id uuid.UUID, req *task.AssignTaskRequest, userID uuid.UUID) (*task.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.authorize(id, userID, task.ActionAssign)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assignees must be registered users of the same tenant
+	if req.UserID != nil {
+		if err := s.findUser(*req.UserID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The previous assignee is notified of the change too
+	viewers := existing.Viewers()
+	changes := existing.Assign(req.UserID)
+	s.recordSharing(existing, userID, changes, viewers)
+
+	return existing.Snapshot(), nil
+}
+
+// ShareTask gives a user read-only access to a task. Only the owner may share a task.
+func (s *service) ShareTask(id uuid.UUID, req *task.ShareTaskRequest, userID uuid.UUID) (*task.Task, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.authorize(id, userID, task.ActionShare)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.findUser(req.UserID, userID); err != nil {
+		return nil, err
+	}
+
+	changes, err := existing.Share(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	s.recordSharing(existing, userID, changes, nil)
+
+	return existing.Snapshot(), nil
+}
+
+// UnshareTask revokes a user's read-only access to a task. Only the owner may unshare a task.
+func (s *service) UnshareTask(id, sharedUserID uuid.UUID, userID uuid.UUID) (*task.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.authorize(id, userID, task.ActionShare)
+	if err != nil {
+		return nil, err
+	}
+
+	// The removed user is notified of the change too
+	viewers := existing.Viewers()
+	changes, err := existing.Unshare(sharedUserID)
+	if err != nil {
+		return nil, err
+	}
+	s.recordSharing(existing, userID, changes, viewers)
+
+	return existing.Snapshot(), nil
+}
+
+// recordSharing records assignment and sharing changes and publishes an update when anything
+// changed. The previous viewers receive the update too, so users who lost access learn about it.
+func (s *service) recordSharing(t *task.Task, userID uuid.UUID, changes []task.FieldChange, previousViewers []uuid.UUID) {
+	if len(changes) == 0 {
+		return
+	}
+
+	for _, change := range changes {
+		s.activityService.Record(activity.NewFieldChange(t.ID, userID, change.Field, change.OldValue, change.NewValue))
+	}
+
+	event := newUpdateEvent(t, userID, changes)
+	event.Viewers = append(event.Viewers, previousViewers...)
+	s.publish(event)
+}
+
+// newUpdateEvent creates an update event for the changes the user made to the task
+func newUpdateEvent(t *task.Task, userID uuid.UUID, changes []task.FieldChange) *task.Event {
+	event := task.NewEvent(task.EventTaskUpdated, t)
+	event.ActorID = userID
+	event.Changes = changes
+	return event
+}
+
+// visibleTasks returns the tasks the user owns, is assigned to, or has been shared, newest
+// first. The slice must not be modified. The caller must hold the lock.
+func (s *service) visibleTasks(userID uuid.UUID) []*task.Task {
+	return s.listings.tasks(userID)
+}
+
+// column returns the tasks on the same board as the given task with the status, sorted by
+// position and excluding one task. Workspace tasks share a board per workspace; other
+// tasks are on their owner's board. The caller must hold the lock.
+func (s *service) column(board *task.Task, status task.TaskStatus, exclude uuid.UUID) []*task.Task {
+	var column []*task.Task
+	for _, t := range s.tasks {
+		if t.TenantID == board.TenantID && sameBoard(t, board) && t.Status == status && t.ID != exclude {
+			column = append(column, t)
+		}
+	}
+
+	task.SortByPosition(column)
+	return column
+}
+
+// GetTaskHistory retrieves the activity history of a task
+func (s *service) GetTaskHistory(id uuid.UUID, userID uuid.UUID) ([]*activity.Entry, error) {
+	// Check the task exists and is accessible
+	if _, err := s.GetTaskByID(id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.activityService.ListByTask(id), nil
+}
+
+// Subscribe registers for events on the user's tasks.
+// The returned function must be called to unsubscribe.
+func (s *service) Subscribe(userID uuid.UUID) (<-chan *task.Event, func()) {
+	ch := make(chan *task.Event, subscriberBuffer)
+
+	unsubscribeBus := s.eventBus.Subscribe(func(event events.Event) {
+		taskEvent, ok := event.(*task.Event)
+		if !ok || !(taskEvent.VisibleTo(userID) || s.isWorkspaceMember(taskEvent.WorkspaceID, userID)) {
+			return
+		}
+
+		select {
+		case ch <- taskEvent:
+		default:
+			// Slow subscriber, drop the event rather than block the bus
+		}
+	})
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			// Waits for in-flight deliveries, so closing the channel is safe
+			unsubscribeBus()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// ListTasks retrieves tasks with filtering, sorting, and pagination. The tasks returned may be
+// shared with concurrent identical listings, so they must not be modified.
 func (s *service) ListTasks(filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, error) {
-	// Get all tasks for the user
-	var userTasks []*task.Task
-	for _, task := range s.tasks {
-		if task.UserID == userID {
-			userTasks = append(userTasks, task)
+	// The first page is the one read most, so it is the only one cached
+	if page == 1 {
+		if tasks, paginationInfo, ok := s.cachedList(filter, sort, limit, userID); ok {
+			return tasks, paginationInfo, nil
 		}
 	}
+	generation := s.cacheGeneration()
+
+	// Listings in the default order are read from the listing index, other listings filter
+	// and sort every visible task. Identical listings running at the same time share a read.
+	paginatedTasks, paginationInfo := s.flights.do(flightKey(filter, sort, page, limit, userID), func() ([]*task.Task, *types.PaginationInfo) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		tasks, paginationInfo, ok := s.listIndexed(filter, sort, page, limit, userID)
+		if !ok {
+			tasks, paginationInfo = s.listScanned(filter, sort, page, limit, userID)
+		}
+		return snapshots(tasks), paginationInfo
+	})
+
+	if page == 1 {
+		s.cacheSet(generation, listGroup(userID), listKey(filter, sort, limit), &cachedList{Tasks: paginatedTasks, Pagination: paginationInfo})
+	}
+	return paginatedTasks, paginationInfo, nil
+}
+
+// snapshots returns copies of the tasks, which are read after the lock is released. The caller
+// must hold the lock.
+func snapshots(tasks []*task.Task) []*task.Task {
+	copies := make([]*task.Task, len(tasks))
+	for i, t := range tasks {
+		copies[i] = t.Snapshot()
+	}
+	return copies
+}
+
+// listScanned returns a page of a listing by filtering and sorting every task visible to the
+// user. The caller must hold the lock.
+func (s *service) listScanned(filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo) {
+	// Get all tasks visible to the user
+	userTasks := s.visibleTasks(userID)
 
 	// Apply filters
 	filteredTasks := s.applyFilters(userTasks, filter)
@@ -164,62 +928,135 @@ func (s *service) ListTasks(filter *task.TaskFilter, sort *task.TaskSort, page,
 	// Apply sorting
 	sortedTasks := s.applySorting(filteredTasks, sort)
 
-	// Calculate pagination
-	total := int64(len(sortedTasks))
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	// Apply pagination
+	return paginate(sortedTasks, page, limit)
+}
+
+// paginate returns the requested page of tasks together with pagination information
+func paginate(tasks []*task.Task, page, limit int) ([]*task.Task, *types.PaginationInfo) {
+	paginationInfo := pageInfo(len(tasks), page, limit)
 
 	// Apply pagination
 	start := (page - 1) * limit
 	end := start + limit
 
-	if start >= len(sortedTasks) {
-		return []*task.Task{}, &types.PaginationInfo{
-			Page:       page,
-			Limit:      limit,
-			Total:      total,
-			TotalPages: totalPages,
-		}, nil
+	if start >= len(tasks) {
+		return []*task.Task{}, paginationInfo
 	}
 
-	if end > len(sortedTasks) {
-		end = len(sortedTasks)
+	if end > len(tasks) {
+		end = len(tasks)
 	}
 
-	paginatedTasks := sortedTasks[start:end]
+	return tasks[start:end], paginationInfo
+}
 
-	paginationInfo := &types.PaginationInfo{
+// pageInfo returns the pagination information of a page of a listing of total tasks
+func pageInfo(total, page, limit int) *types.PaginationInfo {
+	return &types.PaginationInfo{
 		Page:       page,
 		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      int64(total),
+		TotalPages: (total + limit - 1) / limit,
 	}
+}
 
-	return paginatedTasks, paginationInfo, nil
+// GetStats computes analytics over the user's tasks for the given date range
+func (s *service) GetStats(r *task.StatsRange, userID uuid.UUID) (*task.Stats, error) {
+	if r == nil {
+		return nil, errors.New("date range is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var userTasks []*task.Task
+	for _, t := range s.tenantTasks(userID) {
+		if t.UserID == userID {
+			userTasks = append(userTasks, t)
+		}
+	}
+
+	return task.NewStats(userTasks, r), nil
 }
 
-// applyFilters applies filters to the task list
-func (s *service) applyFilters(tasks []*task.Task, filter *task.TaskFilter) []*task.Task {
-	if filter == nil {
-		return tasks
+// GetTaskUsage reports the number of tasks the user owns against the per-user task limit
+func (s *service) GetTaskUsage(userID uuid.UUID) task.Usage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.taskUsage(userID)
+}
+
+// taskUsage reports the number of tasks the user owns against the per-user task limit. The
+// caller must hold the lock.
+func (s *service) taskUsage(userID uuid.UUID) task.Usage {
+	owned := 0
+	for _, t := range s.tasks {
+		if t.UserID == userID {
+			owned++
+		}
 	}
 
-	var filtered []*task.Task
-	for _, task := range tasks {
-		// Status filter
-		if filter.Status != nil && task.Status != *filter.Status {
-			continue
+	return task.NewUsage(owned, s.limits.LimitsOf(userID).MaxTasks)
+}
+
+// planLimitExceeded returns the error of exceeding the user's task limit when it is imposed
+// by their plan, so upgrading lifts it, and nil otherwise
+func (s *service) planLimitExceeded(userID uuid.UUID) error {
+	limits := s.limits.LimitsOf(userID)
+	if limits.Plan == "" {
+		return nil
+	}
+	return billing.UpgradeRequired(billing.Plan(limits.Plan), limits.MaxTasks, "tasks")
+}
+
+// ExportTasks calls fn for every task matching the filter in sort order, without pagination.
+// Iteration stops at the first error returned by fn, which is called without holding the lock.
+func (s *service) ExportTasks(filter *task.TaskFilter, sort *task.TaskSort, userID uuid.UUID, fn func(*task.Task) error) error {
+	// Get all tasks visible to the user
+	s.mu.RLock()
+	userTasks := s.applySorting(s.applyFilters(s.visibleTasks(userID), filter), sort)
+	for i, t := range userTasks {
+		userTasks[i] = t.Snapshot()
+	}
+	s.mu.RUnlock()
+
+	for _, t := range userTasks {
+		if err := fn(t); err != nil {
+			return err
 		}
+	}
 
-		// Search filter
-		if filter.Search != "" {
-			searchLower := strings.ToLower(filter.Search)
-			titleMatch := strings.Contains(strings.ToLower(task.Title), searchLower)
-			if !titleMatch {
-				continue
-			}
+	return nil
+}
+
+// AllTasks returns a copy of every task of every user, oldest first
+func (s *service) AllTasks() []*task.Task {
+	s.mu.RLock()
+	all := make([]*task.Task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		all = append(all, t.Snapshot())
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.Before(all[j].CreatedAt)
 		}
+		return all[i].ID.String() < all[j].ID.String()
+	})
+	return all
+}
 
-		filtered = append(filtered, task)
+// applyFilters applies filters to the task list
+func (s *service) applyFilters(tasks []*task.Task, filter *task.TaskFilter) []*task.Task {
+	// A nil filter still excludes archived tasks
+	var filtered []*task.Task
+	for _, t := range tasks {
+		if filter.Matches(t) {
+			filtered = append(filtered, t)
+		}
 	}
 
 	return filtered
@@ -232,38 +1069,19 @@ func (s *service) applySorting(tasks []*task.Task, sortOptions *task.TaskSort) [
 		sortOptions = &task.TaskSort{Field: "created_at", Order: "desc"}
 	}
 
-	sort.Slice(tasks, func(i, j int) bool {
-		switch sortOptions.Field {
-		case "title":
-			if sortOptions.Order == "asc" {
-				return tasks[i].Title < tasks[j].Title
-			}
-			return tasks[i].Title > tasks[j].Title
-		case "status":
-			statusOrder := map[task.TaskStatus]int{
-				task.StatusPending:    1,
-				task.StatusInProgress: 2,
-				task.StatusCompleted:  3,
-				task.StatusCancelled:  4,
-			}
-			if sortOptions.Order == "asc" {
-				return statusOrder[tasks[i].Status] < statusOrder[tasks[j].Status]
-			}
-			return statusOrder[tasks[i].Status] > statusOrder[tasks[j].Status]
-		case "updated_at":
-			if sortOptions.Order == "asc" {
-				return tasks[i].UpdatedAt.Before(tasks[j].UpdatedAt)
-			}
-			return tasks[i].UpdatedAt.After(tasks[j].UpdatedAt)
-		case "created_at":
-			fallthrough
-		default:
-			if sortOptions.Order == "asc" {
-				return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
-			}
-			return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
-		}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return sortOptions.Less(tasks[i], tasks[j])
 	})
 
 	return tasks
 }
+
+// indexOf returns the index of the task with the given ID, or -1
+func indexOf(tasks []*task.Task, id uuid.UUID) int {
+	for i, t := range tasks {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+package task
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// benchmarkSizes are the numbers of tasks the listing benchmarks run against
+var benchmarkSizes = []int{10_000, 100_000}
+
+// benchmarkTitles are combined with a sequence number to give tasks varied titles, a tenth
+// of which contain "report"
+var benchmarkTitles = []string{"Write", "Review", "Plan", "Fix", "Call", "Draft", "Email", "Test", "Ship", "Report"}
+
+// benchmarkNow anchors the creation and due dates of benchmark tasks, so runs are comparable
+var benchmarkNow = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// setupBenchmarkService returns a service whose fixture tasks are replaced by n tasks owned by
+// John, spread evenly over statuses and priorities and created an hour apart
+func setupBenchmarkService(b *testing.B, n int) (*service, uuid.UUID) {
+	b.Helper()
+	s := setupTestService(b).(*service)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54") // john.doe@example.com
+	tenantID := s.tenants.TenantOf(userID)
+
+	statuses := []task.TaskStatus{task.StatusPending, task.StatusInProgress, task.StatusCompleted, task.StatusCancelled}
+	priorities := []task.TaskPriority{task.PriorityLow, task.PriorityMedium, task.PriorityHigh}
+	s.tasks = make(map[uuid.UUID]*task.Task, n)
+	s.listings = newListingIndex()
+	for i := 0; i < n; i++ {
+		t := task.NewTask(fmt.Sprintf("%s item %d", benchmarkTitles[i%len(benchmarkTitles)], i), userID)
+		t.Status = statuses[i%len(statuses)]
+		t.Priority = priorities[i%len(priorities)]
+		t.CreatedAt = benchmarkNow.Add(-time.Duration(i) * time.Hour)
+		t.UpdatedAt = t.CreatedAt
+		if i%2 == 0 {
+			due := benchmarkNow.Add(time.Duration(i%90) * 24 * time.Hour)
+			t.DueDate = &due
+		}
+		t.TenantID = tenantID
+		s.tasks[t.ID] = t
+		s.indexListing(t.ID)
+	}
+	return s, userID
+}
+
+func BenchmarkService_ListTasks(b *testing.B) {
+	pending := task.StatusPending
+	after, before := benchmarkNow.AddDate(0, 0, -30), benchmarkNow
+	cases := []struct {
+		name   string
+		filter *task.TaskFilter
+		sort   *task.TaskSort
+		page   int
+	}{
+		{"default", nil, nil, 1},
+		{"deep_page", nil, nil, 200},
+		{"status", &task.TaskFilter{Status: &pending}, &task.TaskSort{Field: "created_at", Order: "desc"}, 1},
+		{"statuses", &task.TaskFilter{Statuses: []task.TaskStatus{task.StatusPending, task.StatusInProgress}}, nil, 1},
+		{"statuses_by_priority", &task.TaskFilter{Statuses: []task.TaskStatus{task.StatusPending, task.StatusInProgress}},
+			&task.TaskSort{Field: "priority", Order: "desc", ThenBy: []task.SortKey{{Field: "due_date", Order: "asc"}}}, 1},
+		{"search_by_title", &task.TaskFilter{Search: "report"}, &task.TaskSort{Field: "title", Order: "asc"}, 1},
+		{"created_range_by_due_date", &task.TaskFilter{CreatedAfter: &after, CreatedBefore: &before}, &task.TaskSort{Field: "due_date", Order: "asc"}, 1},
+	}
+
+	for _, n := range benchmarkSizes {
+		s, userID := setupBenchmarkService(b, n)
+		for _, tc := range cases {
+			b.Run(fmt.Sprintf("tasks=%d/%s", n, tc.name), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, _, err := s.ListTasks(tc.filter, tc.sort, tc.page, 20, userID); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"todo-api/internal/domain/activity"
 	"todo-api/internal/domain/task"
 	"todo-api/internal/service/auth"
 	"todo-api/pkg/config"
@@ -15,7 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func setupTestService(t *testing.T) Service {
+func setupTestService(t testing.TB) Service {
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
 			SecretKey:       "test-secret",
@@ -233,6 +234,77 @@ func TestService_DeleteTask_NonExistingTask(t *testing.T) {
 	assert.Equal(t, "task not found", err.Error())
 }
 
+func TestService_GetTaskHistory(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	otherUserID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+
+	createdTask, err := service.CreateTask(&task.CreateTaskRequest{Title: "Tracked Task"}, userID)
+	require.NoError(t, err)
+
+	_, err = service.UpdateTask(createdTask.ID, &task.UpdateTaskRequest{
+		Title:  stringPtr("Renamed Task"),
+		Status: statusPtr(task.StatusCompleted),
+	}, userID)
+	require.NoError(t, err)
+
+	history, err := service.GetTaskHistory(createdTask.ID, userID)
+
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, activity.ActionCreated, history[0].Action)
+	assert.Equal(t, "title", history[1].Field)
+	assert.Equal(t, "Tracked Task", history[1].OldValue)
+	assert.Equal(t, "Renamed Task", history[1].NewValue)
+	assert.Equal(t, "status", history[2].Field)
+	assert.Equal(t, userID, history[2].ActorID)
+
+	// Other users cannot read the history
+	_, err = service.GetTaskHistory(createdTask.ID, otherUserID)
+	require.Error(t, err)
+	assert.Equal(t, "access denied", err.Error())
+}
+
+func TestService_Subscribe(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	otherUserID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+
+	events, unsubscribe := service.Subscribe(userID)
+	otherEvents, otherUnsubscribe := service.Subscribe(otherUserID)
+	defer otherUnsubscribe()
+
+	createdTask, err := service.CreateTask(&task.CreateTaskRequest{Title: "Live Task"}, userID)
+	require.NoError(t, err)
+
+	_, err = service.UpdateTask(createdTask.ID, &task.UpdateTaskRequest{Status: statusPtr(task.StatusCompleted)}, userID)
+	require.NoError(t, err)
+
+	err = service.DeleteTask(createdTask.ID, userID)
+	require.NoError(t, err)
+
+	created := <-events
+	assert.Equal(t, task.EventTaskCreated, created.Type)
+	assert.Equal(t, "Live Task", created.Task.Title)
+
+	updated := <-events
+	assert.Equal(t, task.EventTaskUpdated, updated.Type)
+	assert.Equal(t, task.StatusCompleted, updated.Task.Status)
+
+	deleted := <-events
+	assert.Equal(t, task.EventTaskDeleted, deleted.Type)
+	assert.Equal(t, createdTask.ID, deleted.TaskID)
+	assert.Nil(t, deleted.Task)
+
+	// Events are only delivered to the task owner
+	assert.Empty(t, otherEvents)
+
+	// Unsubscribing closes the channel
+	unsubscribe()
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
 func TestService_ListTasks_NoFilters(t *testing.T) {
 	service := setupTestService(t)
 	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
@@ -379,6 +451,421 @@ func TestService_ListTasks_Pagination(t *testing.T) {
 	assert.LessOrEqual(t, len(tasks), 2)
 }
 
+func TestService_ImportTasks(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	result, err := service.ImportTasks([]*task.ImportTaskRequest{
+		{Title: "Imported task"},
+		{Title: "Finished task", Status: task.StatusCompleted},
+		{Title: ""},
+		{Title: "Bad status", Status: "done"},
+	}, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, task.StatusPending, result.Tasks[0].Status)
+	assert.Equal(t, task.StatusCompleted, result.Tasks[1].Status)
+	assert.Equal(t, []task.ImportRowError{
+		{Row: 3, Message: "title is required"},
+		{Row: 4, Message: "invalid status"},
+	}, result.Errors)
+
+	// Imported tasks are stored for the user
+	stored, err := service.GetTaskByID(result.Tasks[1].ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Finished task", stored.Title)
+}
+
+func TestService_ImportTasks_Limits(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	_, err := service.ImportTasks(nil, userID)
+	require.Error(t, err)
+	assert.Equal(t, "import contains no rows", err.Error())
+
+	reqs := make([]*task.ImportTaskRequest, task.MaxImportRows+1)
+	for i := range reqs {
+		reqs[i] = &task.ImportTaskRequest{Title: "Task"}
+	}
+
+	_, err = service.ImportTasks(reqs, userID)
+	require.Error(t, err)
+	assert.Equal(t, "import exceeds maximum of 1000 rows", err.Error())
+}
+
+func TestService_MoveTask(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+
+	var titles []string
+	column := func(status task.TaskStatus) []string {
+		tasks, _, err := service.ListTasks(&task.TaskFilter{Status: &status}, &task.TaskSort{Field: "position", Order: "asc"}, 1, 100, userID)
+		require.NoError(t, err)
+		titles = titles[:0]
+		for _, t := range tasks {
+			titles = append(titles, t.Title)
+		}
+		return titles
+	}
+
+	a, _ := service.CreateTask(&task.CreateTaskRequest{Title: "A"}, userID)
+	b, _ := service.CreateTask(&task.CreateTaskRequest{Title: "B"}, userID)
+	c, _ := service.CreateTask(&task.CreateTaskRequest{Title: "C"}, userID)
+	assert.Equal(t, []string{"A", "B", "C"}, column(task.StatusPending))
+
+	// Move to an index
+	_, err := service.MoveTask(c.ID, &task.MoveTaskRequest{Position: intPtr(0)}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C", "A", "B"}, column(task.StatusPending))
+
+	// Move relative to another task
+	_, err = service.MoveTask(c.ID, &task.MoveTaskRequest{AfterID: &a.ID}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "C", "B"}, column(task.StatusPending))
+
+	_, err = service.MoveTask(b.ID, &task.MoveTaskRequest{BeforeID: &a.ID}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B", "A", "C"}, column(task.StatusPending))
+
+	// Move into another column
+	moved, err := service.MoveTask(a.ID, &task.MoveTaskRequest{Position: intPtr(0), Status: statusPtr(task.StatusInProgress)}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, task.StatusInProgress, moved.Status)
+	assert.Equal(t, []string{"B", "C"}, column(task.StatusPending))
+	assert.Equal(t, []string{"A"}, column(task.StatusInProgress))
+
+	history, err := service.GetTaskHistory(a.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "status", history[len(history)-2].Field)
+	assert.Equal(t, "position", history[len(history)-1].Field)
+
+	// Reference tasks must be in the target column
+	_, err = service.MoveTask(b.ID, &task.MoveTaskRequest{BeforeID: &a.ID}, userID)
+	require.Error(t, err)
+	assert.Equal(t, "reference task not found in target column", err.Error())
+
+	// Repeated moves into the same gap keep the order stable
+	for i := 0; i < 60; i++ {
+		_, err = service.MoveTask(b.ID, &task.MoveTaskRequest{AfterID: &c.ID}, userID)
+		require.NoError(t, err)
+		_, err = service.MoveTask(c.ID, &task.MoveTaskRequest{AfterID: &b.ID}, userID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"B", "C"}, column(task.StatusPending))
+}
+
+func TestService_CompleteAndReopenTask(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Finish report"}, userID)
+	require.NoError(t, err)
+
+	completed, err := service.CompleteTask(created.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, task.StatusCompleted, completed.Status)
+	assert.NotNil(t, completed.CompletedAt)
+
+	_, err = service.CompleteTask(created.ID, userID)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, task.ErrInvalidTransition)
+
+	reopened, err := service.ReopenTask(created.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, task.StatusPending, reopened.Status)
+	assert.Nil(t, reopened.CompletedAt)
+
+	history, err := service.GetTaskHistory(created.ID, userID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "completed", history[1].NewValue)
+	assert.Equal(t, "pending", history[2].NewValue)
+
+	// Other users cannot change the task
+	_, err = service.CompleteTask(created.ID, uuid.New())
+	require.Error(t, err)
+	assert.Equal(t, "access denied", err.Error())
+
+	_, err = service.ReopenTask(uuid.New(), userID)
+	require.Error(t, err)
+	assert.Equal(t, "task not found", err.Error())
+}
+
+func TestService_ArchiveAndUnarchiveTask(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Old idea"}, userID)
+	require.NoError(t, err)
+
+	archived, err := service.ArchiveTask(created.ID, userID)
+	require.NoError(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+
+	// Archived tasks are left out of the default list but can still be fetched
+	tasks, _, err := service.ListTasks(nil, nil, 1, 10, userID)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	archivedOnly := true
+	tasks, _, err = service.ListTasks(&task.TaskFilter{Archived: &archivedOnly}, nil, 1, 10, userID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, created.ID, tasks[0].ID)
+
+	_, err = service.GetTaskByID(created.ID, userID)
+	require.NoError(t, err)
+
+	_, err = service.ArchiveTask(created.ID, userID)
+	assert.EqualError(t, err, "task is already archived")
+
+	_, err = service.UnarchiveTask(created.ID, uuid.New())
+	assert.EqualError(t, err, "access denied")
+
+	unarchived, err := service.UnarchiveTask(created.ID, userID)
+	require.NoError(t, err)
+	assert.Nil(t, unarchived.ArchivedAt)
+
+	tasks, _, err = service.ListTasks(nil, nil, 1, 10, userID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	history, err := service.GetTaskHistory(created.ID, userID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "archived", history[1].Field)
+}
+
+func TestService_UpdateTask_InvalidTransition(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Dropped idea"}, userID)
+	require.NoError(t, err)
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Status: statusPtr(task.StatusCancelled)}, userID)
+	require.NoError(t, err)
+
+	// Cancelled tasks must be reopened before completing them
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: stringPtr("Revived idea"), Status: statusPtr(task.StatusCompleted)}, userID)
+	require.Error(t, err)
+	assert.Equal(t, "invalid status transition from cancelled to completed", err.Error())
+
+	unchanged, err := service.GetTaskByID(created.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Dropped idea", unchanged.Title)
+	assert.Equal(t, task.StatusCancelled, unchanged.Status)
+
+	_, err = service.MoveTask(created.ID, &task.MoveTaskRequest{Position: intPtr(0), Status: statusPtr(task.StatusInProgress)}, userID)
+	assert.ErrorIs(t, err, task.ErrInvalidTransition)
+}
+
+func TestService_Checklist(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Plan trip"}, userID)
+	require.NoError(t, err)
+
+	updated, err := service.AddChecklistItem(created.ID, &task.AddChecklistItemRequest{Text: "Book flights"}, userID)
+	require.NoError(t, err)
+	updated, err = service.AddChecklistItem(created.ID, &task.AddChecklistItemRequest{Text: "Book hotel"}, userID)
+	require.NoError(t, err)
+	require.Len(t, updated.Checklist, 2)
+	flightsID, hotelID := updated.Checklist[0].ID, updated.Checklist[1].ID
+
+	done := true
+	updated, err = service.UpdateChecklistItem(created.ID, hotelID, &task.UpdateChecklistItemRequest{Done: &done}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 50, *updated.Progress)
+
+	updated, err = service.MoveChecklistItem(created.ID, hotelID, &task.MoveChecklistItemRequest{Position: intPtr(0)}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, hotelID, updated.Checklist[0].ID)
+
+	updated, err = service.RemoveChecklistItem(created.ID, flightsID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 100, *updated.Progress)
+
+	history, err := service.GetTaskHistory(created.ID, userID)
+	require.NoError(t, err)
+	require.Len(t, history, 6)
+	assert.Equal(t, "checklist", history[3].Field)
+	assert.Equal(t, "[ ] Book hotel", history[3].OldValue)
+	assert.Equal(t, "[x] Book hotel", history[3].NewValue)
+	assert.Equal(t, "2. Book hotel", history[4].OldValue)
+	assert.Equal(t, "1. Book hotel", history[4].NewValue)
+	assert.Equal(t, "[ ] Book flights", history[5].OldValue)
+
+	// Errors
+	_, err = service.RemoveChecklistItem(created.ID, flightsID, userID)
+	assert.EqualError(t, err, "checklist item not found")
+
+	_, err = service.AddChecklistItem(created.ID, &task.AddChecklistItemRequest{Text: "Pack"}, uuid.New())
+	assert.EqualError(t, err, "access denied")
+
+	_, err = service.AddChecklistItem(created.ID, &task.AddChecklistItemRequest{}, userID)
+	assert.EqualError(t, err, "text is required")
+}
+
+func TestService_AssignAndShareTask(t *testing.T) {
+	service := setupTestService(t)
+	ownerID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	assigneeID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	viewerID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Quarterly report"}, ownerID)
+	require.NoError(t, err)
+
+	events, unsubscribe := service.Subscribe(viewerID)
+	defer unsubscribe()
+
+	// Assign and share
+	_, err = service.AssignTask(created.ID, &task.AssignTaskRequest{UserID: &assigneeID}, ownerID)
+	require.NoError(t, err)
+	_, err = service.ShareTask(created.ID, &task.ShareTaskRequest{UserID: viewerID}, ownerID)
+	require.NoError(t, err)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, created.ID, event.TaskID)
+	case <-time.After(time.Second):
+		t.Fatal("shared user did not receive the update")
+	}
+
+	// Unknown users cannot be assigned
+	unknownID := uuid.New()
+	_, err = service.AssignTask(created.ID, &task.AssignTaskRequest{UserID: &unknownID}, ownerID)
+	assert.EqualError(t, err, "user not found")
+
+	// The assignee can update and complete but not delete or reassign
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: stringPtr("Quarterly report draft")}, assigneeID)
+	require.NoError(t, err)
+	_, err = service.CompleteTask(created.ID, assigneeID)
+	require.NoError(t, err)
+	assert.EqualError(t, service.DeleteTask(created.ID, assigneeID), "access denied")
+	_, err = service.AssignTask(created.ID, &task.AssignTaskRequest{}, assigneeID)
+	assert.EqualError(t, err, "access denied")
+
+	// Shared users can only read
+	viewed, err := service.GetTaskByID(created.ID, viewerID)
+	require.NoError(t, err)
+	assert.Equal(t, "Quarterly report draft", viewed.Title)
+	_, err = service.GetTaskHistory(created.ID, viewerID)
+	require.NoError(t, err)
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: stringPtr("Hijacked")}, viewerID)
+	assert.EqualError(t, err, "access denied")
+
+	// Assigned and shared tasks are listed
+	tasks, _, err := service.ListTasks(nil, task.NewTaskSort("created_at", "desc"), 1, 10, viewerID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	tasks, _, err = service.ListTasks(&task.TaskFilter{AssigneeID: &assigneeID}, task.NewTaskSort("created_at", "desc"), 1, 10, assigneeID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, created.ID, tasks[0].ID)
+
+	// Unsharing revokes access
+	_, err = service.UnshareTask(created.ID, viewerID, ownerID)
+	require.NoError(t, err)
+	_, err = service.GetTaskByID(created.ID, viewerID)
+	assert.EqualError(t, err, "access denied")
+
+	_, err = service.UnshareTask(created.ID, viewerID, ownerID)
+	assert.EqualError(t, err, "task is not shared with user")
+}
+
+func TestService_GetStats(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Finish me", Priority: task.PriorityHigh}, userID)
+	require.NoError(t, err)
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Status: statusPtr(task.StatusCompleted)}, userID)
+	require.NoError(t, err)
+
+	r, err := task.NewStatsRange(time.Now().AddDate(0, 0, -6), time.Now())
+	require.NoError(t, err)
+
+	stats, err := service.GetStats(r, userID)
+
+	require.NoError(t, err)
+	// Three seeded tasks, one of high priority, plus the new one
+	assert.Equal(t, 4, stats.Total)
+	assert.Equal(t, 1, stats.ByStatus[task.StatusCompleted])
+	assert.Equal(t, 2, stats.ByPriority[task.PriorityHigh])
+	require.Len(t, stats.Daily, 7)
+	assert.Equal(t, 4, stats.Daily[6].Created)
+	assert.Equal(t, 1, stats.Daily[6].Completed)
+	assert.NotNil(t, stats.AverageCompletionSeconds)
+
+	_, err = service.GetStats(nil, userID)
+	require.Error(t, err)
+}
+
+func TestService_ExportTasks(t *testing.T) {
+	service := setupTestService(t)
+	userID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+	// Create more tasks than fit on a single page
+	for i := 0; i < 15; i++ {
+		req := &task.CreateTaskRequest{Title: fmt.Sprintf("Export %02d", i)}
+		_, err := service.CreateTask(req, userID)
+		require.NoError(t, err)
+	}
+
+	var exported []*task.Task
+	filter := &task.TaskFilter{Search: "export"}
+	sort := &task.TaskSort{Field: "title", Order: "asc"}
+
+	err := service.ExportTasks(filter, sort, userID, func(t *task.Task) error {
+		exported = append(exported, t)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, exported, 15)
+	assert.Equal(t, "Export 00", exported[0].Title)
+	assert.Equal(t, "Export 14", exported[14].Title)
+
+	// Iteration stops at the first error
+	calls := 0
+	err = service.ExportTasks(filter, sort, userID, func(t *task.Task) error {
+		calls++
+		return fmt.Errorf("write failed")
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestService_AllTasks(t *testing.T) {
+	service := setupTestService(t)
+	johnID := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	janeID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+
+	before := len(service.AllTasks())
+	johns, err := service.CreateTask(&task.CreateTaskRequest{Title: "Pay rent"}, johnID)
+	require.NoError(t, err)
+	janes, err := service.CreateTask(&task.CreateTaskRequest{Title: "Book flights"}, janeID)
+	require.NoError(t, err)
+	janes, err = service.ArchiveTask(janes.ID, janeID)
+	require.NoError(t, err)
+
+	// Tasks of every user are returned, archived ones included, oldest first
+	all := service.AllTasks()
+	require.Len(t, all, before+2)
+	assert.Contains(t, all, johns)
+	assert.Contains(t, all, janes)
+	for i := 1; i < len(all); i++ {
+		assert.False(t, all[i].CreatedAt.Before(all[i-1].CreatedAt))
+	}
+}
+
 // Helper functions for tests
 func stringPtr(s string) *string {
 	return &s
@@ -387,3 +874,7 @@ func stringPtr(s string) *string {
 func statusPtr(s task.TaskStatus) *task.TaskStatus {
 	return &s
 }
+
+func intPtr(i int) *int {
+	return &i
+}
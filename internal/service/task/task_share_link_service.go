@@ -0,0 +1,260 @@
+package task
+
+import (
+	"cmp"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// CreateShareLink creates a read-only link to a task or project for people without an
+// account. Links to a task are created by users who may share it, links to a project by
+// admins of its workspace.
+func (s *service) CreateShareLink(target task.ShareTarget, req *task.CreateShareLinkRequest, userID uuid.UUID) (*task.CreatedShareLink, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.authorizeShareTarget(target, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := 0
+	for _, link := range s.shareLinks {
+		if link.Shares(target) && link.IsActive(now) {
+			active++
+		}
+	}
+	if active >= task.MaxShareLinks {
+		return nil, errors.New("too many active share links, revoke one first")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := task.ShareLinkPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	link := &task.ShareLink{
+		ID:          uuid.New(),
+		TaskID:      target.TaskID,
+		WorkspaceID: target.WorkspaceID,
+		ProjectID:   target.ProjectID,
+		Hash:        hashShareToken(token),
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		ExpiresAt:   now.AddDate(0, 0, req.ExpiresInDays),
+	}
+	s.shareLinks[link.Hash] = link
+
+	return &task.CreatedShareLink{ShareLink: link.Snapshot(), Token: token}, nil
+}
+
+// ListShareLinks returns the share links of a task or project, newest first, including
+// expired and revoked ones with their views
+func (s *service) ListShareLinks(target task.ShareTarget, userID uuid.UUID) ([]*task.ShareLink, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.authorizeShareTarget(target, userID); err != nil {
+		return nil, err
+	}
+
+	links := []*task.ShareLink{}
+	for _, link := range s.shareLinks {
+		if link.Shares(target) {
+			links = append(links, link.Snapshot())
+		}
+	}
+	slices.SortFunc(links, func(a, b *task.ShareLink) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return links, nil
+}
+
+// RevokeShareLink revokes a share link of a task or project, so its token can no longer be
+// viewed. Revoking a revoked link changes nothing.
+func (s *service) RevokeShareLink(target task.ShareTarget, linkID uuid.UUID, userID uuid.UUID) (*task.ShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.authorizeShareTarget(target, userID); err != nil {
+		return nil, err
+	}
+
+	for _, link := range s.shareLinks {
+		if link.ID != linkID || !link.Shares(target) {
+			continue
+		}
+		if link.RevokedAt == nil {
+			now := time.Now()
+			link.RevokedAt = &now
+		}
+		return link.Snapshot(), nil
+	}
+	return nil, errors.New("share link not found")
+}
+
+// ViewShareLink returns the task or project of a share link token, counting the view
+func (s *service) ViewShareLink(token string) (*task.SharedView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, err := s.activeShareLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &task.SharedView{}
+	if link.TaskID != nil {
+		t, exists := s.tasks[*link.TaskID]
+		if !exists {
+			return nil, task.ErrShareLinkNotFound
+		}
+		view.Task = task.NewPublicTask(t)
+	} else {
+		name, exists := s.workspaces.ProjectName(*link.WorkspaceID, *link.ProjectID)
+		if !exists {
+			return nil, task.ErrShareLinkNotFound
+		}
+		view.Project = &task.SharedProject{ID: *link.ProjectID, Name: name, Tasks: s.publicProjectTasks(*link.WorkspaceID, *link.ProjectID)}
+	}
+
+	countView(link)
+	return view, nil
+}
+
+// ProjectBoard returns a page of the board of the project of a share link token, counting
+// the view. Tasks are ordered by status, then by their position in the status column.
+func (s *service) ProjectBoard(token string, page, limit int) (*task.ProjectBoard, *types.PaginationInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, err := s.activeShareLink(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if link.ProjectID == nil {
+		return nil, nil, task.ErrShareLinkNotFound
+	}
+	name, exists := s.workspaces.ProjectName(*link.WorkspaceID, *link.ProjectID)
+	if !exists {
+		return nil, nil, task.ErrShareLinkNotFound
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > task.MaxBoardLimit {
+		limit = task.DefaultBoardLimit
+	}
+
+	tasks := s.projectTasks(*link.WorkspaceID, *link.ProjectID)
+	slices.SortStableFunc(tasks, func(a, b *task.Task) int {
+		if a.Status != b.Status {
+			return cmp.Compare(slices.Index(boardColumns, a.Status), slices.Index(boardColumns, b.Status))
+		}
+		return cmp.Compare(a.Position, b.Position)
+	})
+
+	board := &task.ProjectBoard{ID: *link.ProjectID, Name: name, Tasks: []*task.BoardTask{}}
+	start := min((page-1)*limit, len(tasks))
+	for _, t := range tasks[start:min(start+limit, len(tasks))] {
+		board.Tasks = append(board.Tasks, task.NewBoardTask(t))
+		if board.UpdatedAt == nil || t.UpdatedAt.After(*board.UpdatedAt) {
+			updatedAt := t.UpdatedAt
+			board.UpdatedAt = &updatedAt
+		}
+	}
+
+	countView(link)
+	return board, pageInfo(len(tasks), page, limit), nil
+}
+
+// boardColumns orders the statuses of tasks on project boards
+var boardColumns = []task.TaskStatus{task.StatusInProgress, task.StatusPending, task.StatusCompleted, task.StatusCancelled}
+
+// activeShareLink returns the share link of the token, if it can be viewed. The caller must
+// hold the lock.
+func (s *service) activeShareLink(token string) (*task.ShareLink, error) {
+	link, ok := s.shareLinks[hashShareToken(token)]
+	if !ok || !link.IsActive(time.Now()) {
+		return nil, task.ErrShareLinkNotFound
+	}
+	return link, nil
+}
+
+// countView records a view of the share link. The caller must hold the lock.
+func countView(link *task.ShareLink) {
+	now := time.Now()
+	link.Views++
+	link.LastViewedAt = &now
+}
+
+// projectTasks returns the tasks of the project that are not archived, oldest first. The
+// caller must hold the lock.
+func (s *service) projectTasks(workspaceID, projectID uuid.UUID) []*task.Task {
+	var tasks []*task.Task
+	for _, t := range s.tasks {
+		if t.WorkspaceID != nil && *t.WorkspaceID == workspaceID && t.ProjectID != nil && *t.ProjectID == projectID && !t.IsArchived() {
+			tasks = append(tasks, t)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b *task.Task) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return tasks
+}
+
+// publicProjectTasks returns the public view of the tasks of the project, oldest first
+func (s *service) publicProjectTasks(workspaceID, projectID uuid.UUID) []*task.PublicTask {
+	tasks := s.projectTasks(workspaceID, projectID)
+	public := make([]*task.PublicTask, 0, len(tasks))
+	for _, t := range tasks {
+		public = append(public, task.NewPublicTask(t))
+	}
+	return public
+}
+
+// authorizeShareTarget checks the user may manage the share links of the task or project.
+// The caller must hold the lock.
+func (s *service) authorizeShareTarget(target task.ShareTarget, userID uuid.UUID) error {
+	if target.TaskID != nil {
+		_, err := s.authorize(*target.TaskID, userID, task.ActionShare)
+		return err
+	}
+
+	if target.WorkspaceID == nil || target.ProjectID == nil {
+		return errors.New("task or project is required")
+	}
+	role, ok := s.workspaces.Role(*target.WorkspaceID, userID)
+	if !ok {
+		return errors.New("workspace not found")
+	}
+	if !role.AtLeast(workspace.RoleAdmin) {
+		return errors.New("access denied")
+	}
+	if !s.workspaces.HasProject(*target.WorkspaceID, *target.ProjectID) {
+		return errors.New("project not found")
+	}
+	return nil
+}
+
+// hashShareToken returns the hash share links are stored by
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
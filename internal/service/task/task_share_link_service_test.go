@@ -0,0 +1,148 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ShareLinks(t *testing.T) {
+	service, _, _ := setupWorkspaceService(t)
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Plan the offsite", Tags: []string{"team"}}, johnID)
+	require.NoError(t, err)
+	target := task.ShareTarget{TaskID: &created.ID}
+
+	link, err := service.CreateShareLink(target, &task.CreateShareLinkRequest{}, johnID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link.Token, task.ShareLinkPrefix))
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, task.DefaultShareLinkDays), link.ExpiresAt, time.Minute)
+
+	// Viewed without an account, without the users of the task
+	view, err := service.ViewShareLink(link.Token)
+	require.NoError(t, err)
+	require.NotNil(t, view.Task)
+	assert.Equal(t, "Plan the offsite", view.Task.Title)
+	assert.Equal(t, []string{"team"}, view.Task.Tags)
+	_, err = service.ViewShareLink(link.Token)
+	require.NoError(t, err)
+
+	links, err := service.ListShareLinks(target, johnID)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, 2, links[0].Views)
+	assert.NotNil(t, links[0].LastViewedAt)
+
+	// Only users who may share the task manage its links
+	_, err = service.CreateShareLink(target, &task.CreateShareLinkRequest{}, janeID)
+	assert.Error(t, err)
+	_, err = service.ListShareLinks(target, janeID)
+	assert.Error(t, err)
+
+	revoked, err := service.RevokeShareLink(target, link.ID, johnID)
+	require.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+	_, err = service.ViewShareLink(link.Token)
+	assert.ErrorIs(t, err, task.ErrShareLinkNotFound)
+
+	_, err = service.RevokeShareLink(target, uuid.New(), johnID)
+	assert.EqualError(t, err, "share link not found")
+	_, err = service.ViewShareLink(task.ShareLinkPrefix + "unknown")
+	assert.ErrorIs(t, err, task.ErrShareLinkNotFound)
+
+	// Links stop working once the task is deleted
+	another, err := service.CreateShareLink(target, &task.CreateShareLinkRequest{ExpiresInDays: 1}, johnID)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(created.ID, johnID))
+	_, err = service.ViewShareLink(another.Token)
+	assert.ErrorIs(t, err, task.ErrShareLinkNotFound)
+}
+
+func TestService_ShareLinks_Project(t *testing.T) {
+	service, workspaceID, projectID := setupWorkspaceService(t)
+	target := task.ShareTarget{WorkspaceID: &workspaceID, ProjectID: &projectID}
+
+	first, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Draft", ProjectID: &projectID}, johnID)
+	require.NoError(t, err)
+	archived, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Old", ProjectID: &projectID}, johnID)
+	require.NoError(t, err)
+	_, err = service.ArchiveTask(archived.ID, johnID)
+	require.NoError(t, err)
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Elsewhere"}, johnID)
+	require.NoError(t, err)
+
+	// Members who are not admins cannot share projects
+	_, err = service.CreateShareLink(target, &task.CreateShareLinkRequest{}, janeID)
+	assert.EqualError(t, err, "access denied")
+
+	link, err := service.CreateShareLink(target, &task.CreateShareLinkRequest{ExpiresInDays: 30}, johnID)
+	require.NoError(t, err)
+
+	view, err := service.ViewShareLink(link.Token)
+	require.NoError(t, err)
+	require.NotNil(t, view.Project)
+	assert.Equal(t, "Launch", view.Project.Name)
+	require.Len(t, view.Project.Tasks, 1)
+	assert.Equal(t, first.ID, view.Project.Tasks[0].ID)
+
+	// Active links are bounded
+	for i := 1; i < task.MaxShareLinks; i++ {
+		_, err := service.CreateShareLink(target, &task.CreateShareLinkRequest{}, johnID)
+		require.NoError(t, err)
+	}
+	_, err = service.CreateShareLink(target, &task.CreateShareLinkRequest{}, johnID)
+	assert.EqualError(t, err, "too many active share links, revoke one first")
+}
+
+func TestService_ProjectBoard(t *testing.T) {
+	service, workspaceID, projectID := setupWorkspaceService(t)
+
+	titles := []string{"Write copy", "Design banner", "Book venue"}
+	created := make([]*task.Task, len(titles))
+	for i, title := range titles {
+		var err error
+		created[i], err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: title, ProjectID: &projectID}, johnID)
+		require.NoError(t, err)
+	}
+	inProgress, completed := task.StatusInProgress, task.StatusCompleted
+	_, err := service.UpdateTask(created[0].ID, &task.UpdateTaskRequest{Status: &completed}, johnID)
+	require.NoError(t, err)
+	_, err = service.UpdateTask(created[2].ID, &task.UpdateTaskRequest{Status: &inProgress}, johnID)
+	require.NoError(t, err)
+
+	link, err := service.CreateShareLink(task.ShareTarget{WorkspaceID: &workspaceID, ProjectID: &projectID}, &task.CreateShareLinkRequest{}, johnID)
+	require.NoError(t, err)
+
+	// Ordered by status, then position
+	board, pagination, err := service.ProjectBoard(link.Token, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Launch", board.Name)
+	require.Len(t, board.Tasks, 2)
+	assert.Equal(t, "Book venue", board.Tasks[0].Title)
+	assert.Equal(t, "Design banner", board.Tasks[1].Title)
+	assert.NotNil(t, board.UpdatedAt)
+	assert.Equal(t, int64(3), pagination.Total)
+	assert.Equal(t, 2, pagination.TotalPages)
+
+	board, _, err = service.ProjectBoard(link.Token, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, board.Tasks, 1)
+	assert.Equal(t, "Write copy", board.Tasks[0].Title)
+	assert.Equal(t, task.StatusCompleted, board.Tasks[0].Status)
+
+	links, err := service.ListShareLinks(task.ShareTarget{WorkspaceID: &workspaceID, ProjectID: &projectID}, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, links[0].Views)
+
+	// Only projects have boards
+	taskLink, err := service.CreateShareLink(task.ShareTarget{TaskID: &created[0].ID}, &task.CreateShareLinkRequest{}, johnID)
+	require.NoError(t, err)
+	_, _, err = service.ProjectBoard(taskLink.Token, 1, 0)
+	assert.ErrorIs(t, err, task.ErrShareLinkNotFound)
+}
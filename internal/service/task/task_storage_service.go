@@ -0,0 +1,98 @@
+package task
+
+import (
+	"fmt"
+	"sync/atomic"
+	"unsafe"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/metrics"
+
+	"github.com/google/uuid"
+)
+
+// storedTaskOverhead is the estimated memory a stored task takes beyond its own fields, in
+// the task map, the listing and search indexes, and its activity
+const storedTaskOverhead = 512
+
+// storageUsage tracks the number and estimated size of the stored tasks against the storage
+// budget. The totals are atomic, as metrics are read concurrently with changes.
+type storageUsage struct {
+	budget     task.StorageBudget
+	sizes      map[uuid.UUID]int // estimated size of each stored task
+	tasks      atomic.Int64
+	bytes      atomic.Int64
+	rejections *metrics.CounterVec // new tasks rejected by the budget, by the limit reached
+}
+
+// newStorageUsage creates the usage of empty storage, exposing it in the registry
+func newStorageUsage(budget task.StorageBudget, registry *metrics.Registry) *storageUsage {
+	u := &storageUsage{
+		budget:     budget,
+		sizes:      make(map[uuid.UUID]int),
+		rejections: registry.NewCounterVec("task_storage_rejections_total", "New tasks rejected by the storage budget, by the limit reached.", "limit"),
+	}
+	registry.NewGaugeFunc("task_storage_tasks", "Tasks stored.", func() float64 { return float64(u.tasks.Load()) })
+	registry.NewGaugeFunc("task_storage_bytes", "Estimated size of the stored tasks in bytes.", func() float64 { return float64(u.bytes.Load()) })
+	return u
+}
+
+// estimatedSize returns the estimated memory a stored task takes in bytes
+func estimatedSize(t *task.Task) int {
+	size := int(unsafe.Sizeof(*t)) + storedTaskOverhead + len(t.Title) + len(t.Description) + len(t.SharedWith)*len(uuid.UUID{})
+	for _, item := range t.Checklist {
+		size += int(unsafe.Sizeof(item)) + len(item.Text)
+	}
+	return size
+}
+
+// measureTask updates the storage usage after a change to the task with the ID. The caller
+// must hold the lock.
+func (s *service) measureTask(id uuid.UUID) {
+	u := s.storage
+	previous, stored := u.sizes[id]
+
+	t, exists := s.tasks[id]
+	if !exists {
+		if stored {
+			delete(u.sizes, id)
+			u.tasks.Add(-1)
+			u.bytes.Add(-int64(previous))
+		}
+		return
+	}
+
+	size := estimatedSize(t)
+	u.sizes[id] = size
+	if !stored {
+		u.tasks.Add(1)
+	}
+	u.bytes.Add(int64(size - previous))
+}
+
+// CheckStorage returns an error once not even an empty task fits in the storage budget
+func (s *service) CheckStorage() error {
+	u := s.storage
+	if max := u.budget.MaxTasks; max > 0 && u.tasks.Load() >= int64(max) {
+		return fmt.Errorf("%w: %d of %d tasks stored", task.ErrStorageFull, u.tasks.Load(), max)
+	}
+	if max := u.budget.MaxBytes; max > 0 && u.bytes.Load()+int64(estimatedSize(&task.Task{})) > int64(max) {
+		return fmt.Errorf("%w: %d of %d bytes of tasks stored", task.ErrStorageFull, u.bytes.Load(), max)
+	}
+	return nil
+}
+
+// checkStorageBudget returns an error when the new task does not fit in the storage budget.
+// Changes to stored tasks are not limited, but the size they add counts toward new tasks.
+func (s *service) checkStorageBudget(newTask *task.Task) error {
+	u := s.storage
+	if max := u.budget.MaxTasks; max > 0 && u.tasks.Load() >= int64(max) {
+		u.rejections.Inc("tasks")
+		return fmt.Errorf("%w: at most %d tasks can be stored", task.ErrStorageFull, max)
+	}
+	if max := u.budget.MaxBytes; max > 0 && u.bytes.Load()+int64(estimatedSize(newTask)) > int64(max) {
+		u.rejections.Inc("bytes")
+		return fmt.Errorf("%w: at most %d bytes of tasks can be stored", task.ErrStorageFull, max)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package task
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/events"
+	"todo-api/internal/metrics"
+	"todo-api/internal/service/auth"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBudgetedService(t *testing.T, budget task.StorageBudget) (*service, *metrics.Registry) {
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}}
+	authSvc := auth.NewService(cfg)
+	registry := metrics.NewRegistry()
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	svc := NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, StorageBudget: budget, Registry: registry})
+	return svc.(*service), registry
+}
+
+func TestService_StorageBudget_MaxTasks(t *testing.T) {
+	s, registry := setupBudgetedService(t, task.StorageBudget{MaxTasks: 8})
+	john := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	require.Equal(t, int64(len(s.tasks)), s.storage.tasks.Load())
+	assert.NoError(t, s.CheckStorage())
+
+	var created *task.Task
+	for s.storage.tasks.Load() < 8 {
+		var err error
+		created, err = s.CreateTask(&task.CreateTaskRequest{Title: "Fill up"}, john)
+		require.NoError(t, err)
+	}
+
+	_, err := s.CreateTask(&task.CreateTaskRequest{Title: "One too many"}, john)
+	assert.ErrorIs(t, err, task.ErrStorageFull)
+	assert.EqualError(t, err, "task storage is full: at most 8 tasks can be stored")
+	result, err := s.ImportTasks([]*task.ImportTaskRequest{{Title: "Imported"}}, john)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, float64(2), s.storage.rejections.Value("tasks"))
+	assert.EqualError(t, s.CheckStorage(), "task storage is full: 8 of 8 tasks stored")
+
+	// Deleted tasks free their place
+	require.NoError(t, s.DeleteTask(created.ID, john))
+	assert.NoError(t, s.CheckStorage())
+	_, err = s.CreateTask(&task.CreateTaskRequest{Title: "Fits again"}, john)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, registry.Write(&out))
+	assert.Contains(t, out.String(), `task_storage_rejections_total{limit="tasks"} 2`)
+	assert.Contains(t, out.String(), "task_storage_tasks 8\n")
+}
+
+func TestService_StorageBudget_MaxBytes(t *testing.T) {
+	s, _ := setupBudgetedService(t, task.StorageBudget{})
+	john := uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	initial := s.storage.bytes.Load()
+	s.storage.budget.MaxBytes = int(initial) + 2*estimatedSize(task.NewTask("Fill up", john))
+
+	created, err := s.CreateTask(&task.CreateTaskRequest{Title: "Fill up"}, john)
+	require.NoError(t, err)
+	assert.Equal(t, initial+int64(estimatedSize(created)), s.storage.bytes.Load())
+
+	// Changes to stored tasks are allowed, but count toward new tasks
+	description := strings.Repeat("a", 1000)
+	updated, err := s.UpdateTask(created.ID, &task.UpdateTaskRequest{Description: &description}, john)
+	require.NoError(t, err)
+	assert.Equal(t, initial+int64(estimatedSize(updated)), s.storage.bytes.Load())
+
+	_, err = s.CreateTask(&task.CreateTaskRequest{Title: "Fill up"}, john)
+	assert.ErrorIs(t, err, task.ErrStorageFull)
+	assert.Equal(t, float64(1), s.storage.rejections.Value("bytes"))
+
+	require.NoError(t, s.DeleteTask(created.ID, john))
+	assert.Equal(t, initial, s.storage.bytes.Load())
+}
@@ -0,0 +1,174 @@
+package task
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+)
+
+// changeLog records the most recent task changes for offline clients to sync. Changes are
+// numbered in order; cursors carry the number of the last change a client has seen and the
+// epoch of the log, so cursors issued before a restart are recognized as expired.
+type changeLog struct {
+	epoch     string
+	last      uint64        // number of the latest change, 0 before any change
+	entries   []changeEntry // oldest first, at most task.MaxSyncChanges
+	audiences map[uuid.UUID][]uuid.UUID
+}
+
+// changeEntry is a change to a task
+type changeEntry struct {
+	seq         uint64
+	taskID      uuid.UUID
+	eventType   task.EventType
+	users       []uuid.UUID // owner, assignee, and shared users before and after the change
+	workspaceID *uuid.UUID
+}
+
+// newChangeLog creates an empty change log
+func newChangeLog() *changeLog {
+	return &changeLog{
+		epoch:     strconv.FormatInt(time.Now().UnixNano(), 36),
+		audiences: make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+// recordChange adds the change of the event to the change log. Users who could see the task
+// before the change are kept, so they learn when they lose access to it. The caller must hold
+// the lock.
+func (s *service) recordChange(event *task.Event) {
+	l := s.changes
+	users := append([]uuid.UUID{event.UserID}, event.Viewers...)
+	for _, previous := range l.audiences[event.TaskID] {
+		if !slices.Contains(users, previous) {
+			users = append(users, previous)
+		}
+	}
+	if event.Type == task.EventTaskDeleted {
+		delete(l.audiences, event.TaskID)
+	} else {
+		l.audiences[event.TaskID] = append([]uuid.UUID{event.UserID}, event.Viewers...)
+	}
+
+	l.last++
+	l.entries = append(l.entries, changeEntry{
+		seq:         l.last,
+		taskID:      event.TaskID,
+		eventType:   event.Type,
+		users:       users,
+		workspaceID: event.WorkspaceID,
+	})
+	if len(l.entries) > task.MaxSyncChanges {
+		l.entries = slices.Delete(l.entries, 0, len(l.entries)-task.MaxSyncChanges)
+	}
+}
+
+// TaskChanges returns the tasks created, updated, and deleted for the user since the cursor,
+// at most limit of them. Without a cursor, only the current cursor is returned, to sync
+// from once all tasks were fetched.
+func (s *service) TaskChanges(since string, limit int, userID uuid.UUID) (*task.ChangeSet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := s.changes
+	if limit <= 0 || limit > task.MaxSyncLimit {
+		limit = task.DefaultSyncLimit
+	}
+
+	changes := &task.ChangeSet{Created: []uuid.UUID{}, Updated: []uuid.UUID{}, Deleted: []uuid.UUID{}}
+	if since == "" {
+		changes.Cursor = l.cursor(l.last)
+		return changes, nil
+	}
+
+	seq, err := l.parseCursor(since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries after the cursor, of tasks the user could see at the time of the change
+	start, _ := slices.BinarySearchFunc(l.entries, seq+1, func(e changeEntry, seq uint64) int {
+		return cmp.Compare(e.seq, seq)
+	})
+	created := make(map[uuid.UUID]bool) // tasks created since the cursor, seen by the user or not
+	changed := make(map[uuid.UUID]bool)
+	var order []uuid.UUID
+	end := seq
+	for _, entry := range l.entries[start:] {
+		visible := slices.Contains(entry.users, userID) || s.isWorkspaceMember(entry.workspaceID, userID)
+		if visible && !changed[entry.taskID] {
+			if len(order) == limit {
+				changes.HasMore = true
+				break
+			}
+			order = append(order, entry.taskID)
+			changed[entry.taskID] = true
+		}
+		if entry.eventType == task.EventTaskCreated {
+			created[entry.taskID] = true
+		}
+		end = entry.seq
+	}
+
+	// Tasks are reported by their current state
+	for _, id := range order {
+		switch {
+		case s.canSync(id, userID) && created[id]:
+			changes.Created = append(changes.Created, id)
+		case s.canSync(id, userID):
+			changes.Updated = append(changes.Updated, id)
+		default:
+			changes.Deleted = append(changes.Deleted, id)
+		}
+	}
+	changes.Cursor = l.cursor(end)
+	return changes, nil
+}
+
+// ResolveTask applies the merge of a conflicting edit with the server copy of a task. A
+// merge based on a version that is stale again conflicts like any other edit.
+func (s *service) ResolveTask(id uuid.UUID, req *task.UpdateTaskRequest, userID uuid.UUID) (*task.Task, error) {
+	if req.BaseVersion == nil {
+		return nil, task.ErrBaseVersionRequired
+	}
+	return s.UpdateTask(id, req, userID)
+}
+
+// canSync reports whether the task exists and the user may read it. The caller must hold the
+// lock.
+func (s *service) canSync(id uuid.UUID, userID uuid.UUID) bool {
+	_, err := s.authorize(id, userID, task.ActionRead)
+	return err == nil
+}
+
+// cursor returns the cursor of the change with the number
+func (l *changeLog) cursor(seq uint64) string {
+	return l.epoch + "." + strconv.FormatUint(seq, 10)
+}
+
+// parseCursor returns the number of the change of the cursor. Cursors of another epoch, or
+// older than the changes kept, are expired.
+func (l *changeLog) parseCursor(cursor string) (uint64, error) {
+	epoch, rawSeq, ok := strings.Cut(cursor, ".")
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if !ok || err != nil || epoch == "" {
+		return 0, task.ErrInvalidCursor
+	}
+	if epoch != l.epoch {
+		return 0, task.ErrCursorExpired
+	}
+	if seq > l.last {
+		return 0, task.ErrInvalidCursor
+	}
+	// The change right after the cursor must still be kept
+	if len(l.entries) > 0 && seq+1 < l.entries[0].seq {
+		return 0, task.ErrCursorExpired
+	}
+	return seq, nil
+}
@@ -0,0 +1,140 @@
+package task
+
+import (
+	"testing"
+
+	"todo-api/internal/domain/task"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_TaskChanges(t *testing.T) {
+	service, workspaceID, _ := setupWorkspaceService(t)
+
+	// Without a cursor, only the current cursor is returned
+	initial, err := service.TaskChanges("", 0, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, initial.Created)
+	assert.NotEmpty(t, initial.Cursor)
+
+	kept, err := service.CreateTask(&task.CreateTaskRequest{Title: "Kept"}, johnID)
+	require.NoError(t, err)
+	edited, err := service.CreateTask(&task.CreateTaskRequest{Title: "Edited"}, johnID)
+	require.NoError(t, err)
+	removed, err := service.CreateTask(&task.CreateTaskRequest{Title: "Removed"}, johnID)
+	require.NoError(t, err)
+	janes, err := service.CreateTask(&task.CreateTaskRequest{Title: "Jane's"}, janeID)
+	require.NoError(t, err)
+	shared, err := service.CreateTask(&task.CreateTaskRequest{Title: "Shared"}, janeID)
+	require.NoError(t, err)
+	_, err = service.ShareTask(shared.ID, &task.ShareTaskRequest{UserID: johnID}, janeID)
+	require.NoError(t, err)
+	teamTask, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Team"}, janeID)
+	require.NoError(t, err)
+
+	first, err := service.TaskChanges(initial.Cursor, 0, johnID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{kept.ID, edited.ID, removed.ID, shared.ID, teamTask.ID}, first.Created)
+	assert.NotContains(t, first.Created, janes.ID)
+	assert.False(t, first.HasMore)
+
+	// Changes since the previous sync, with tombstones for deleted and unshared tasks
+	title := "Edited again"
+	_, err = service.UpdateTask(edited.ID, &task.UpdateTaskRequest{Title: &title}, johnID)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(removed.ID, johnID))
+	_, err = service.UnshareTask(shared.ID, johnID, janeID)
+	require.NoError(t, err)
+
+	second, err := service.TaskChanges(first.Cursor, 0, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, second.Created)
+	assert.Equal(t, []uuid.UUID{edited.ID}, second.Updated)
+	assert.ElementsMatch(t, []uuid.UUID{removed.ID, shared.ID}, second.Deleted)
+
+	// Nothing changed since
+	third, err := service.TaskChanges(second.Cursor, 0, johnID)
+	require.NoError(t, err)
+	assert.Empty(t, third.Updated)
+	assert.Equal(t, second.Cursor, third.Cursor)
+
+	// Pages of at most limit tasks
+	page, err := service.TaskChanges(initial.Cursor, 2, johnID)
+	require.NoError(t, err)
+	assert.Len(t, page.Created, 2)
+	assert.True(t, page.HasMore)
+	rest, err := service.TaskChanges(page.Cursor, 10, johnID)
+	require.NoError(t, err)
+	assert.False(t, rest.HasMore)
+	// Tasks changed again after the cursor of the first page are reported again
+	assert.ElementsMatch(t, []uuid.UUID{removed.ID, shared.ID, teamTask.ID, edited.ID},
+		append(append(rest.Created, rest.Updated...), rest.Deleted...))
+}
+
+func TestService_TaskChanges_Cursors(t *testing.T) {
+	service, _, _ := setupWorkspaceService(t)
+
+	for _, cursor := range []string{"garbage", "abc", "x.", "x.-1"} {
+		_, err := service.TaskChanges(cursor, 0, johnID)
+		assert.ErrorIs(t, err, task.ErrInvalidCursor, cursor)
+	}
+
+	// Cursors issued before a restart have another epoch
+	_, err := service.TaskChanges("0.0", 0, johnID)
+	assert.ErrorIs(t, err, task.ErrCursorExpired)
+
+	// Cursors older than the changes kept
+	initial, err := service.TaskChanges("", 0, johnID)
+	require.NoError(t, err)
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Busy"}, johnID)
+	require.NoError(t, err)
+	for i := 0; i < task.MaxSyncChanges; i++ {
+		_, err := service.MoveTask(created.ID, &task.MoveTaskRequest{Position: intPtr(0)}, johnID)
+		require.NoError(t, err)
+	}
+	_, err = service.TaskChanges(initial.Cursor, 0, johnID)
+	assert.ErrorIs(t, err, task.ErrCursorExpired)
+}
+
+func TestService_UpdateTask_StaleVersion(t *testing.T) {
+	service, _, _ := setupWorkspaceService(t)
+
+	created, err := service.CreateTask(&task.CreateTaskRequest{Title: "Call the plumber"}, johnID)
+	require.NoError(t, err)
+	base := created.Version
+
+	// Changed on another device
+	title := "Call the plumber today"
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: &title, BaseVersion: &base}, johnID)
+	require.NoError(t, err)
+
+	// An offline edit of the same field conflicts and changes nothing
+	offline := "Call the electrician"
+	priority := task.PriorityHigh
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: &offline, Priority: &priority, BaseVersion: &base}, johnID)
+	var conflict *task.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, title, conflict.Server.Title)
+	assert.Equal(t, base+1, conflict.Server.Version)
+	require.Len(t, conflict.Client, 2)
+	assert.Equal(t, "title", conflict.Client[0].Field)
+	assert.Equal(t, "priority", conflict.Client[1].Field)
+
+	current, err := service.GetTaskByID(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, task.PriorityMedium, current.Priority)
+
+	// The merge must be based on the server copy
+	_, err = service.ResolveTask(created.ID, &task.UpdateTaskRequest{Title: &offline}, johnID)
+	assert.ErrorIs(t, err, task.ErrBaseVersionRequired)
+	_, err = service.ResolveTask(created.ID, &task.UpdateTaskRequest{Title: &offline, BaseVersion: &base}, johnID)
+	assert.ErrorIs(t, err, task.ErrConflict)
+
+	resolved, err := service.ResolveTask(created.ID, &task.UpdateTaskRequest{Title: &title, Priority: &priority, BaseVersion: &conflict.Server.Version}, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, title, resolved.Title)
+	assert.Equal(t, task.PriorityHigh, resolved.Priority)
+	assert.Equal(t, base+2, resolved.Version)
+}
@@ -0,0 +1,72 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/tenant"
+
+	"github.com/google/uuid"
+)
+
+// TenantDirectory resolves the tenant users belong to and the quota of each tenant
+type TenantDirectory interface {
+	TenantOf(userID uuid.UUID) uuid.UUID
+	Quota(tenantID uuid.UUID) tenant.Quota
+}
+
+// singleTenant is a directory placing every user in the default tenant without quotas
+type singleTenant struct{}
+
+func (singleTenant) TenantOf(uuid.UUID) uuid.UUID { return tenant.DefaultTenantID }
+func (singleTenant) Quota(uuid.UUID) tenant.Quota { return tenant.Quota{} }
+
+// tenantTasks returns every task of the user's tenant. Queries over the task store must go
+// through it, or check the tenant themselves, so that tenants never see each other's tasks.
+// The caller must hold the lock.
+func (s *service) tenantTasks(userID uuid.UUID) []*task.Task {
+	tenantID := s.tenants.TenantOf(userID)
+
+	var tasks []*task.Task
+	for _, t := range s.tasks {
+		if t.TenantID == tenantID {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// checkTaskQuota returns an error when the tenant cannot hold another task. The caller must
+// hold the lock.
+func (s *service) checkTaskQuota(tenantID uuid.UUID) error {
+	quota := s.tenants.Quota(tenantID)
+	if quota.MaxTasks == 0 {
+		return nil
+	}
+
+	count := 0
+	for _, t := range s.tasks {
+		if t.TenantID == tenantID {
+			count++
+		}
+	}
+
+	if !quota.AllowsTasks(count) {
+		return fmt.Errorf("%w: at most %d tasks allowed", tenant.ErrQuotaExceeded, quota.MaxTasks)
+	}
+	return nil
+}
+
+// findUser finds a registered user of the same tenant as userID. Users of other
+// tenants are reported as not found.
+func (s *service) findUser(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.authService.GetUserByID(id); err != nil {
+		return err
+	}
+
+	if s.tenants.TenantOf(id) != s.tenants.TenantOf(userID) {
+		return errors.New("user not found")
+	}
+	return nil
+}
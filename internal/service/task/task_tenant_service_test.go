@@ -0,0 +1,184 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/billing"
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/events"
+	"todo-api/internal/service/auth"
+	tenantService "todo-api/internal/service/tenant"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var aliceID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440004")
+
+// setupTenantService creates a task service isolating John's default tenant from Alice's Acme tenant
+func setupTenantService(t *testing.T) (Service, tenantService.Service) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	tenants := tenantService.NewService(authSvc)
+	workspaces := workspaceService.NewServiceWithTenants(authSvc, tenants)
+
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	return NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces, Tenants: tenants}), tenants
+}
+
+func TestService_TenantIsolation(t *testing.T) {
+	service, _ := setupTenantService(t)
+
+	aliceTask, err := service.CreateTask(&task.CreateTaskRequest{Title: "Acme roadmap"}, aliceID)
+	require.NoError(t, err)
+
+	// Alice only sees the tasks of her tenant, her fixture task and the new one
+	tasks, _, err := service.ListTasks(nil, nil, 1, 100, aliceID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	for _, tk := range tasks {
+		assert.Equal(t, auth.AcmeTenantID, tk.TenantID)
+	}
+
+	johnTasks, _, err := service.ListTasks(nil, nil, 1, 100, johnID)
+	require.NoError(t, err)
+	require.NotEmpty(t, johnTasks)
+	for _, tk := range johnTasks {
+		assert.NotEqual(t, aliceTask.ID, tk.ID)
+	}
+
+	// Tasks of other tenants are not found, even by ID
+	_, err = service.GetTaskByID(aliceTask.ID, johnID)
+	require.Error(t, err)
+	assert.Equal(t, "task not found", err.Error())
+
+	_, err = service.GetTaskByID(johnTasks[0].ID, aliceID)
+	require.Error(t, err)
+	assert.Equal(t, "task not found", err.Error())
+
+	// Tasks cannot be shared with or assigned to users of other tenants
+	_, err = service.ShareTask(aliceTask.ID, &task.ShareTaskRequest{UserID: johnID}, aliceID)
+	require.Error(t, err)
+	assert.Equal(t, "user not found", err.Error())
+
+	_, err = service.AssignTask(johnTasks[0].ID, &task.AssignTaskRequest{UserID: &aliceID}, johnID)
+	require.Error(t, err)
+	assert.Equal(t, "user not found", err.Error())
+}
+
+func TestService_TenantTaskQuota(t *testing.T) {
+	service, tenants := setupTenantService(t)
+
+	_, err := tenants.UpdateTenant(auth.AcmeTenantID, &tenant.UpdateTenantRequest{Quota: &tenant.Quota{MaxTasks: 3}})
+	require.NoError(t, err)
+
+	// Alice already owns a fixture task
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "First"}, aliceID)
+	require.NoError(t, err)
+
+	// Imported rows beyond the quota are reported as failed
+	result, err := service.ImportTasks([]*task.ImportTaskRequest{{Title: "Second"}, {Title: "Third"}}, aliceID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 2, result.Errors[0].Row)
+
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Fourth"}, aliceID)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, tenant.ErrQuotaExceeded))
+	assert.Equal(t, "tenant quota exceeded: at most 3 tasks allowed", err.Error())
+
+	// Other tenants are not affected
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Unaffected"}, johnID)
+	require.NoError(t, err)
+}
+
+func TestService_TaskLimits(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	service := NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceService.NewService(authSvc),
+		Tenants: tenantService.NewService(authSvc), Limits: task.Limits{MaxTasks: 2}})
+
+	// Mike owns no fixture tasks
+	usage := service.GetTaskUsage(mikeID)
+	assert.Equal(t, 0, usage.Used)
+	assert.Equal(t, 2, usage.Limit)
+	assert.Equal(t, 2, *usage.Remaining)
+
+	_, err := service.ImportTasks([]*task.ImportTaskRequest{{Title: "One"}, {Title: "Two"}, {Title: "Three"}}, mikeID)
+	require.ErrorIs(t, err, task.ErrLimitExceeded)
+	assert.Equal(t, "limit exceeded: importing 3 tasks exceeds the 2 remaining of 2 tasks per user", err.Error())
+	assert.Equal(t, 0, service.GetTaskUsage(mikeID).Used)
+
+	_, err = service.ImportTasks([]*task.ImportTaskRequest{{Title: "One"}, {Title: "Two"}}, mikeID)
+	require.NoError(t, err)
+
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Three"}, mikeID)
+	require.ErrorIs(t, err, task.ErrLimitExceeded)
+
+	// Limits apply per user
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Mine"}, aliceID)
+	require.NoError(t, err)
+}
+
+// planLimits is a limits directory putting Mike on a plan allowing two tasks
+type planLimits struct{}
+
+func (planLimits) LimitsOf(userID uuid.UUID) task.Limits {
+	if userID == mikeID {
+		return task.Limits{MaxTasks: 2, Plan: string(billing.PlanFree)}
+	}
+	return task.Limits{}
+}
+
+func TestService_PlanLimits(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+	service := NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaceService.NewService(authSvc),
+		Tenants: tenantService.NewService(authSvc), Limits: planLimits{}})
+
+	assert.Equal(t, 2, service.GetTaskUsage(mikeID).Limit)
+	assert.Equal(t, 0, service.GetTaskUsage(aliceID).Limit)
+
+	_, err := service.ImportTasks([]*task.ImportTaskRequest{{Title: "One"}, {Title: "Two"}, {Title: "Three"}}, mikeID)
+	require.ErrorIs(t, err, billing.ErrUpgradeRequired)
+
+	_, err = service.ImportTasks([]*task.ImportTaskRequest{{Title: "One"}, {Title: "Two"}}, mikeID)
+	require.NoError(t, err)
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Three"}, mikeID)
+	require.ErrorIs(t, err, billing.ErrUpgradeRequired)
+	assert.Equal(t, "upgrade required: the free plan allows at most 2 tasks", err.Error())
+}
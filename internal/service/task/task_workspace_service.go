@@ -0,0 +1,193 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceDirectory resolves workspace membership and projects for workspace tasks
+type WorkspaceDirectory interface {
+	Role(workspaceID, userID uuid.UUID) (workspace.Role, bool)
+	HasProject(workspaceID, projectID uuid.UUID) bool
+	// ProjectName returns the name of a project of the workspace, reporting false when the
+	// workspace has no such project
+	ProjectName(workspaceID, projectID uuid.UUID) (string, bool)
+}
+
+// noWorkspaces is a directory without any workspaces
+type noWorkspaces struct{}
+
+func (noWorkspaces) Role(uuid.UUID, uuid.UUID) (workspace.Role, bool) { return "", false }
+func (noWorkspaces) HasProject(uuid.UUID, uuid.UUID) bool             { return false }
+func (noWorkspaces) ProjectName(uuid.UUID, uuid.UUID) (string, bool)  { return "", false }
+
+// CreateWorkspaceTask creates a task in a workspace, optionally within one of its projects
+func (s *service) CreateWorkspaceTask(workspaceID uuid.UUID, req *task.CreateTaskRequest, userID uuid.UUID) (*task.Task, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !s.isWorkspaceMember(&workspaceID, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	if req.ProjectID != nil && !s.workspaces.HasProject(workspaceID, *req.ProjectID) {
+		return nil, errors.New("project not found")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Create new task
+	newTask := newTaskFromRequest(req, userID)
+	newTask.WorkspaceID = &workspaceID
+	if err := s.addTask(newTask); err != nil {
+		return nil, err
+	}
+
+	return newTask, nil
+}
+
+// ListWorkspaceTasks retrieves a workspace's tasks with filtering, sorting, and pagination
+func (s *service) ListWorkspaceTasks(workspaceID uuid.UUID, filter *task.TaskFilter, sort *task.TaskSort, page, limit int, userID uuid.UUID) ([]*task.Task, *types.PaginationInfo, error) {
+	if !s.isWorkspaceMember(&workspaceID, userID) {
+		return nil, nil, errors.New("access denied")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var workspaceTasks []*task.Task
+	for _, t := range s.tenantTasks(userID) {
+		if t.WorkspaceID != nil && *t.WorkspaceID == workspaceID {
+			workspaceTasks = append(workspaceTasks, t)
+		}
+	}
+
+	tasks, paginationInfo := paginate(s.applySorting(s.applyFilters(workspaceTasks, filter), sort), page, limit)
+	return snapshots(tasks), paginationInfo, nil
+}
+
+// WorkspaceAgingReport reports the open tasks of the workspace by age and the average time
+// its tasks spent in each open status, by the status changes in their activity history
+func (s *service) WorkspaceAgingReport(workspaceID uuid.UUID, now time.Time, userID uuid.UUID) (*task.AgingReport, error) {
+	if !s.isWorkspaceMember(&workspaceID, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var workspaceTasks []*task.Task
+	history := make(map[uuid.UUID][]task.StatusChange)
+	for _, t := range s.tenantTasks(userID) {
+		if t.WorkspaceID == nil || *t.WorkspaceID != workspaceID {
+			continue
+		}
+		workspaceTasks = append(workspaceTasks, t)
+		history[t.ID] = s.statusChanges(t.ID)
+	}
+
+	return task.NewAgingReport(workspaceTasks, history, now), nil
+}
+
+// GetWorkspaceStats computes analytics over the tasks of the workspace for the given date
+// range
+func (s *service) GetWorkspaceStats(workspaceID uuid.UUID, r *task.StatsRange, userID uuid.UUID) (*task.Stats, error) {
+	if r == nil {
+		return nil, errors.New("date range is required")
+	}
+	if !s.isWorkspaceMember(&workspaceID, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var workspaceTasks []*task.Task
+	for _, t := range s.tenantTasks(userID) {
+		if t.WorkspaceID != nil && *t.WorkspaceID == workspaceID {
+			workspaceTasks = append(workspaceTasks, t)
+		}
+	}
+
+	return task.NewStats(workspaceTasks, r), nil
+}
+
+// ProjectBurndown returns the open and closed tasks of the project at the end of each day of
+// the range, by the status changes in their activity history. It is meant for background
+// aggregation and does not check access.
+func (s *service) ProjectBurndown(projectID uuid.UUID, r *task.StatsRange) []task.BurndownDay {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var projectTasks []*task.Task
+	history := make(map[uuid.UUID][]task.StatusChange)
+	for _, t := range s.tasks {
+		if t.ProjectID == nil || *t.ProjectID != projectID {
+			continue
+		}
+		projectTasks = append(projectTasks, t)
+		history[t.ID] = s.statusChanges(t.ID)
+	}
+
+	return task.BurndownDays(projectTasks, history, r)
+}
+
+// statusChanges returns the status changes in the activity history of a task
+func (s *service) statusChanges(taskID uuid.UUID) []task.StatusChange {
+	var changes []task.StatusChange
+	for _, entry := range s.activityService.ListByTask(taskID) {
+		if entry.Field == "status" {
+			changes = append(changes, task.StatusChange{
+				At:   entry.CreatedAt,
+				From: task.TaskStatus(entry.OldValue),
+				To:   task.TaskStatus(entry.NewValue),
+			})
+		}
+	}
+	return changes
+}
+
+// roles returns the user's roles on a task, including the role the user holds in the
+// task's workspace as "workspace_<role>"
+func (s *service) roles(t *task.Task, userID uuid.UUID) []string {
+	roles := t.RolesFor(userID)
+	if t.WorkspaceID == nil {
+		return roles
+	}
+
+	if role, ok := s.workspaces.Role(*t.WorkspaceID, userID); ok {
+		roles = append(roles, "workspace_"+string(role))
+	}
+	return roles
+}
+
+// can reports whether the authorization policy lets the user perform the action on a task
+func (s *service) can(t *task.Task, userID uuid.UUID, action string) bool {
+	return s.policy.Enforce(s.roles(t, userID), task.ResourceType, action)
+}
+
+// isWorkspaceMember reports whether the user belongs to the workspace, if any
+func (s *service) isWorkspaceMember(workspaceID *uuid.UUID, userID uuid.UUID) bool {
+	if workspaceID == nil {
+		return false
+	}
+	_, ok := s.workspaces.Role(*workspaceID, userID)
+	return ok
+}
+
+// sameBoard reports whether two tasks are shown on the same board
+func sameBoard(a, b *task.Task) bool {
+	if a.WorkspaceID != nil || b.WorkspaceID != nil {
+		return a.WorkspaceID != nil && b.WorkspaceID != nil && *a.WorkspaceID == *b.WorkspaceID
+	}
+	return a.UserID == b.UserID
+}
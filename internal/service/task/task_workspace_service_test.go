@@ -0,0 +1,191 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/events"
+	"todo-api/internal/service/auth"
+	workspaceService "todo-api/internal/service/workspace"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWorkspaceService creates a task service backed by a workspace owned by John, with Jane as
+// a member and Mike outside of it
+func setupWorkspaceService(t *testing.T) (Service, uuid.UUID, uuid.UUID) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	authSvc := auth.NewService(cfg)
+	workspaces := workspaceService.NewService(authSvc)
+
+	created, err := workspaces.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	owner, err := workspaces.GetMember(created.ID, johnID)
+	require.NoError(t, err)
+
+	invitation, err := workspaces.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "jane.smith@example.com"}, owner)
+	require.NoError(t, err)
+	_, err = workspaces.AcceptInvitation(invitation.ID, janeID, "jane.smith@example.com")
+	require.NoError(t, err)
+
+	project, err := workspaces.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "Launch"}, johnID)
+	require.NoError(t, err)
+
+	bus := events.NewChannelBus(events.DefaultBufferSize)
+	t.Cleanup(bus.Close)
+
+	return NewServiceWithDeps(Deps{Auth: authSvc, Bus: bus, Workspaces: workspaces}), created.ID, project.ID
+}
+
+var (
+	johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	janeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	mikeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+)
+
+func TestService_CreateWorkspaceTask(t *testing.T) {
+	service, workspaceID, projectID := setupWorkspaceService(t)
+
+	created, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Write launch plan", ProjectID: &projectID}, janeID)
+	require.NoError(t, err)
+	assert.Equal(t, workspaceID, *created.WorkspaceID)
+	assert.Equal(t, projectID, *created.ProjectID)
+
+	otherProject := uuid.New()
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Task", ProjectID: &otherProject}, janeID)
+	assert.EqualError(t, err, "project not found")
+
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Task"}, mikeID)
+	assert.EqualError(t, err, "access denied")
+
+	// Projects only exist within workspaces
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Task", ProjectID: &projectID}, janeID)
+	assert.EqualError(t, err, "project_id is only allowed for workspace tasks")
+}
+
+func TestService_WorkspaceTaskAccess(t *testing.T) {
+	service, workspaceID, projectID := setupWorkspaceService(t)
+
+	created, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Write launch plan"}, janeID)
+	require.NoError(t, err)
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Book venue", ProjectID: &projectID}, janeID)
+	require.NoError(t, err)
+
+	// Members can modify each other's tasks, the workspace owner can also delete them
+	_, err = service.UpdateTask(created.ID, &task.UpdateTaskRequest{Title: stringPtr("Write launch plan v2")}, johnID)
+	require.NoError(t, err)
+	_, err = service.GetTaskByID(created.ID, mikeID)
+	assert.EqualError(t, err, "access denied")
+
+	// Listing and filtering by project
+	tasks, pagination, err := service.ListWorkspaceTasks(workspaceID, nil, task.NewTaskSort("created_at", "asc"), 1, 10, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pagination.Total)
+	assert.Equal(t, "Write launch plan v2", tasks[0].Title)
+
+	tasks, _, err = service.ListWorkspaceTasks(workspaceID, &task.TaskFilter{ProjectID: &projectID}, task.NewTaskSort("created_at", "asc"), 1, 10, johnID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Book venue", tasks[0].Title)
+
+	_, _, err = service.ListWorkspaceTasks(workspaceID, nil, task.NewTaskSort("created_at", "asc"), 1, 10, mikeID)
+	assert.EqualError(t, err, "access denied")
+
+	// Workspace tasks share one board, separate from personal tasks
+	moved, err := service.MoveTask(created.ID, &task.MoveTaskRequest{Position: intPtr(1)}, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, 3*task.PositionGap, moved.Position)
+
+	require.NoError(t, service.DeleteTask(created.ID, johnID))
+}
+
+func TestService_WorkspaceAgingReport(t *testing.T) {
+	service, workspaceID, projectID := setupWorkspaceService(t)
+
+	started, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Book venue", ProjectID: &projectID}, janeID)
+	require.NoError(t, err)
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Write launch plan"}, johnID)
+	require.NoError(t, err)
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Personal"}, johnID)
+	require.NoError(t, err)
+
+	inProgress := task.StatusInProgress
+	_, err = service.UpdateTask(started.ID, &task.UpdateTaskRequest{Status: &inProgress}, janeID)
+	require.NoError(t, err)
+
+	// Two days later, both tasks are still open and the started one has been in progress since
+	now := time.Now().Add(48 * time.Hour)
+	report, err := service.WorkspaceAgingReport(workspaceID, now, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Open)
+	assert.Equal(t, task.AgingBuckets{DayToWeek: 2}, report.Aging)
+	require.NotNil(t, report.AverageSecondsInStatus[task.StatusInProgress])
+	assert.InDelta(t, (48 * time.Hour).Seconds(), *report.AverageSecondsInStatus[task.StatusInProgress], 5)
+	assert.Len(t, report.ByUser, 2)
+	require.Len(t, report.ByProject, 2)
+	assert.Equal(t, projectID, *report.ByProject[0].ProjectID)
+
+	_, err = service.WorkspaceAgingReport(workspaceID, now, mikeID)
+	assert.EqualError(t, err, "access denied")
+}
+
+func TestService_ProjectBurndown(t *testing.T) {
+	service, workspaceID, projectID := setupWorkspaceService(t)
+
+	done, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Book venue", ProjectID: &projectID}, janeID)
+	require.NoError(t, err)
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Send invites", ProjectID: &projectID}, janeID)
+	require.NoError(t, err)
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Write launch plan"}, johnID)
+	require.NoError(t, err)
+	_, err = service.CompleteTask(done.ID, janeID)
+	require.NoError(t, err)
+
+	// Only the tasks of the project count, from the day they were created
+	r, err := task.NewStatsRange(time.Now().AddDate(0, 0, -1), time.Now())
+	require.NoError(t, err)
+	days := service.ProjectBurndown(projectID, r)
+	require.Len(t, days, 2)
+	assert.Equal(t, task.BurndownDay{Date: r.From.Format(time.DateOnly)}, days[0])
+	assert.Equal(t, task.BurndownDay{Date: r.To.Format(time.DateOnly), Open: 1, Closed: 1, Completed: 1}, days[1])
+}
+
+func TestService_GetWorkspaceStats(t *testing.T) {
+	service, workspaceID, _ := setupWorkspaceService(t)
+
+	done, err := service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Book venue"}, janeID)
+	require.NoError(t, err)
+	_, err = service.CreateWorkspaceTask(workspaceID, &task.CreateTaskRequest{Title: "Write launch plan"}, johnID)
+	require.NoError(t, err)
+	_, err = service.CreateTask(&task.CreateTaskRequest{Title: "Personal"}, johnID)
+	require.NoError(t, err)
+	_, err = service.CompleteTask(done.ID, janeID)
+	require.NoError(t, err)
+
+	r, err := task.NewStatsRange(time.Now(), time.Now())
+	require.NoError(t, err)
+	stats, err := service.GetWorkspaceStats(workspaceID, r, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Total)
+	assert.Equal(t, 1, stats.ByStatus[task.StatusCompleted])
+	require.Len(t, stats.Daily, 1)
+	assert.Equal(t, 2, stats.Daily[0].Created)
+	assert.Equal(t, 1, stats.Daily[0].Completed)
+
+	_, err = service.GetWorkspaceStats(workspaceID, r, mikeID)
+	assert.EqualError(t, err, "access denied")
+	_, err = service.GetWorkspaceStats(workspaceID, nil, johnID)
+	assert.EqualError(t, err, "date range is required")
+}
@@ -0,0 +1,172 @@
+package tenant
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/tenant"
+	authService "todo-api/internal/service/auth"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the tenant service interface
+type Service interface {
+	CreateTenant(req *tenant.CreateTenantRequest) (*tenant.Tenant, error)
+	ListTenants() []*tenant.Tenant
+	GetTenant(id uuid.UUID) (*tenant.Tenant, error)
+	GetTenantBySlug(slug string) (*tenant.Tenant, error)
+	UpdateTenant(id uuid.UUID, req *tenant.UpdateTenantRequest) (*tenant.Tenant, error)
+	TenantOf(userID uuid.UUID) uuid.UUID
+	Quota(tenantID uuid.UUID) tenant.Quota
+}
+
+// service implements the tenant service
+type service struct {
+	mu          sync.RWMutex
+	tenants     map[uuid.UUID]*tenant.Tenant // Mock tenant storage
+	authService authService.Service
+}
+
+// NewService creates a new tenant service
+func NewService(authSvc authService.Service) Service {
+	// Initialize mock tenants owning the default fixture users
+	defaultTenant := tenant.NewTenant(tenant.DefaultSlug, "Default", tenant.Quota{})
+	defaultTenant.ID = tenant.DefaultTenantID
+
+	acme := tenant.NewTenant(authService.AcmeSlug, "Acme Corp", tenant.Quota{MaxTasks: 100, MaxWorkspaces: 5})
+	acme.ID = authService.AcmeTenantID
+
+	return &service{
+		tenants: map[uuid.UUID]*tenant.Tenant{
+			defaultTenant.ID: defaultTenant,
+			acme.ID:          acme,
+		},
+		authService: authSvc,
+	}
+}
+
+// CreateTenant creates a new tenant with a unique slug
+func (s *service) CreateTenant(req *tenant.CreateTenantRequest) (*tenant.Tenant, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findBySlug(req.Slug) != nil {
+		return nil, errors.New("slug is already taken")
+	}
+
+	newTenant := tenant.NewTenant(req.Slug, req.Name, req.Quota)
+	s.tenants[newTenant.ID] = newTenant
+
+	return newTenant, nil
+}
+
+// ListTenants retrieves every tenant, oldest first
+func (s *service) ListTenants() []*tenant.Tenant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenants := make([]*tenant.Tenant, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		tenants = append(tenants, t)
+	}
+
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].CreatedAt.Before(tenants[j].CreatedAt)
+	})
+	return tenants
+}
+
+// GetTenant retrieves a tenant by ID
+func (s *service) GetTenant(id uuid.UUID) (*tenant.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, exists := s.tenants[id]
+	if !exists {
+		return nil, errors.New("tenant not found")
+	}
+	return t, nil
+}
+
+// GetTenantBySlug retrieves a tenant by its slug
+func (s *service) GetTenantBySlug(slug string) (*tenant.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t := s.findBySlug(slug)
+	if t == nil {
+		return nil, errors.New("tenant not found")
+	}
+	return t, nil
+}
+
+// UpdateTenant renames, suspends or reactivates a tenant, or changes its quota
+func (s *service) UpdateTenant(id uuid.UUID, req *tenant.UpdateTenantRequest) (*tenant.Tenant, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, exists := s.tenants[id]
+	if !exists {
+		return nil, errors.New("tenant not found")
+	}
+
+	if req.Status != nil && *req.Status == tenant.StatusSuspended && id == tenant.DefaultTenantID {
+		return nil, errors.New("the default tenant cannot be suspended")
+	}
+
+	if req.Name != nil {
+		t.Name = *req.Name
+	}
+	if req.Status != nil {
+		t.Status = *req.Status
+	}
+	if req.Quota != nil {
+		t.Quota = *req.Quota
+	}
+	t.UpdatedAt = time.Now()
+
+	return t, nil
+}
+
+// TenantOf returns the tenant a user belongs to. Unknown users belong to the default tenant.
+func (s *service) TenantOf(userID uuid.UUID) uuid.UUID {
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil || user.TenantID == uuid.Nil {
+		return tenant.DefaultTenantID
+	}
+	return user.TenantID
+}
+
+// Quota returns the quota of a tenant. Unknown tenants are unlimited.
+func (s *service) Quota(tenantID uuid.UUID) tenant.Quota {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if t, exists := s.tenants[tenantID]; exists {
+		return t.Quota
+	}
+	return tenant.Quota{}
+}
+
+// findBySlug returns the tenant with the given slug, if any. Callers must hold the lock.
+func (s *service) findBySlug(slug string) *tenant.Tenant {
+	for _, t := range s.tenants {
+		if t.Slug == slug {
+			return t
+		}
+	}
+	return nil
+}
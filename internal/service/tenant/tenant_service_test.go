@@ -0,0 +1,91 @@
+package tenant
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/service/auth"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	johnID  = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	aliceID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440004")
+)
+
+func setupTestService(t *testing.T) Service {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	return NewService(auth.NewService(cfg))
+}
+
+func TestService_CreateTenant(t *testing.T) {
+	service := setupTestService(t)
+
+	created, err := service.CreateTenant(&tenant.CreateTenantRequest{
+		Slug:  "Globex",
+		Name:  "Globex",
+		Quota: tenant.Quota{MaxTasks: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "globex", created.Slug)
+	assert.Equal(t, tenant.StatusActive, created.Status)
+	assert.Equal(t, 10, created.Quota.MaxTasks)
+
+	found, err := service.GetTenantBySlug("globex")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.Len(t, service.ListTenants(), 3)
+
+	// Slugs are unique
+	_, err = service.CreateTenant(&tenant.CreateTenantRequest{Slug: "globex", Name: "Other"})
+	require.Error(t, err)
+	assert.Equal(t, "slug is already taken", err.Error())
+
+	_, err = service.CreateTenant(&tenant.CreateTenantRequest{Slug: "globex!", Name: "Other"})
+	require.Error(t, err)
+}
+
+func TestService_UpdateTenant(t *testing.T) {
+	service := setupTestService(t)
+
+	suspended := tenant.StatusSuspended
+	updated, err := service.UpdateTenant(auth.AcmeTenantID, &tenant.UpdateTenantRequest{
+		Status: &suspended,
+		Quota:  &tenant.Quota{MaxTasks: 1},
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive())
+	assert.Equal(t, tenant.Quota{MaxTasks: 1}, service.Quota(auth.AcmeTenantID))
+
+	_, err = service.UpdateTenant(tenant.DefaultTenantID, &tenant.UpdateTenantRequest{Status: &suspended})
+	require.Error(t, err)
+	assert.Equal(t, "the default tenant cannot be suspended", err.Error())
+
+	_, err = service.UpdateTenant(uuid.New(), &tenant.UpdateTenantRequest{})
+	require.Error(t, err)
+	assert.Equal(t, "tenant not found", err.Error())
+}
+
+func TestService_TenantOf(t *testing.T) {
+	service := setupTestService(t)
+
+	assert.Equal(t, tenant.DefaultTenantID, service.TenantOf(johnID))
+	assert.Equal(t, auth.AcmeTenantID, service.TenantOf(aliceID))
+	assert.Equal(t, tenant.DefaultTenantID, service.TenantOf(uuid.New()))
+
+	_, err := service.GetTenant(uuid.New())
+	require.Error(t, err)
+	assert.Equal(t, tenant.Quota{}, service.Quota(uuid.New()))
+}
@@ -0,0 +1,144 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"todo-api/internal/domain/task"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/warehouse"
+
+	"github.com/google/uuid"
+)
+
+// TasksTable is the table the daily snapshots of tasks are inserted into
+const TasksTable = "task_snapshots"
+
+// Service defines the warehouse service interface. It exports a snapshot of every task to
+// the data warehouse each day, for analytics over longer periods than the API serves.
+type Service interface {
+	// Snapshot inserts the state of every task at the time into the warehouse, as the
+	// snapshot of its UTC day, and returns the number of tasks inserted. Each day is
+	// snapshotted once; a failed snapshot is taken again whole on the next run.
+	Snapshot(ctx context.Context, now time.Time) (int, error)
+	// Run snapshots the tasks of today, run by the scheduler
+	Run(ctx context.Context) error
+}
+
+// service implements the warehouse service
+type service struct {
+	mu          sync.Mutex // serializes snapshots
+	last        string     // date of the last complete snapshot
+	taskService taskService.Service
+	sink        warehouse.Sink
+	batchSize   int
+}
+
+// NewService creates a new warehouse service inserting snapshots into the sink, in batches
+// of up to batchSize tasks
+func NewService(taskSvc taskService.Service, sink warehouse.Sink, batchSize int) Service {
+	return &service{
+		taskService: taskSvc,
+		sink:        sink,
+		batchSize:   batchSize,
+	}
+}
+
+// Snapshot inserts the tasks in batches, stopping at the first failure. Rows are identified
+// by the date and task, so sinks discard rows inserted again by a retried snapshot.
+func (s *service) Snapshot(ctx context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	date := now.UTC().Format(time.DateOnly)
+	if s.last == date {
+		return 0, nil
+	}
+
+	tasks := s.taskService.AllTasks()
+	inserted := 0
+	for start := 0; start < len(tasks); start += s.batchSize {
+		if err := ctx.Err(); err != nil {
+			return inserted, err
+		}
+
+		batch := tasks[start:min(start+s.batchSize, len(tasks))]
+		rows := make([]warehouse.Row, len(batch))
+		for i, t := range batch {
+			rows[i] = snapshotRow(date, t)
+		}
+		if err := s.sink.Insert(ctx, TasksTable, rows); err != nil {
+			return inserted, err
+		}
+		inserted += len(batch)
+	}
+
+	s.last = date
+	return inserted, nil
+}
+
+// Run snapshots the tasks of today
+func (s *service) Run(ctx context.Context) error {
+	if inserted, err := s.Snapshot(ctx, time.Now()); err != nil {
+		return fmt.Errorf("%d tasks snapshotted: %w", inserted, err)
+	}
+	return nil
+}
+
+// snapshotRow returns the row of the task in the snapshot of the date. Titles,
+// descriptions, and checklist texts are left out, so the warehouse holds no content of
+// tasks; tags are a JSON array.
+func snapshotRow(date string, t *task.Task) warehouse.Row {
+	tags, _ := json.Marshal(t.Tags)
+	if t.Tags == nil {
+		tags = []byte("[]")
+	}
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+
+	return warehouse.Row{
+		ID: date + "/" + t.ID.String(),
+		Values: map[string]interface{}{
+			"snapshot_date":   date,
+			"task_id":         t.ID.String(),
+			"tenant_id":       t.TenantID.String(),
+			"user_id":         t.UserID.String(),
+			"assignee_id":     optionalID(t.AssigneeID),
+			"workspace_id":    optionalID(t.WorkspaceID),
+			"project_id":      optionalID(t.ProjectID),
+			"status":          string(t.Status),
+			"priority":        string(t.Priority),
+			"tags":            string(tags),
+			"checklist_items": len(t.Checklist),
+			"checklist_done":  done,
+			"due_date":        optionalTime(t.DueDate),
+			"created_at":      t.CreatedAt,
+			"updated_at":      t.UpdatedAt,
+			"completed_at":    optionalTime(t.CompletedAt),
+			"archived_at":     optionalTime(t.ArchivedAt),
+		},
+	}
+}
+
+// optionalID returns the ID as a string, or nil for NULL when unset
+func optionalID(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
+
+// optionalTime returns the time, or nil for NULL when unset
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
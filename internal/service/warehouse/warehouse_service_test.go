@@ -0,0 +1,116 @@
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/task"
+	authService "todo-api/internal/service/auth"
+	taskService "todo-api/internal/service/task"
+	"todo-api/pkg/config"
+	"todo-api/pkg/warehouse"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+
+// fakeSink records the batches inserted into each table, failing the batch numbered failAt
+type fakeSink struct {
+	batches [][]warehouse.Row
+	failAt  int // 1-based, 0 never fails
+}
+
+func (s *fakeSink) Insert(_ context.Context, table string, rows []warehouse.Row) error {
+	if table != TasksTable {
+		return errors.New("unexpected table " + table)
+	}
+	if len(s.batches)+1 == s.failAt {
+		s.failAt = 0
+		return errors.New("warehouse unavailable")
+	}
+	s.batches = append(s.batches, rows)
+	return nil
+}
+
+func (s *fakeSink) rows() []warehouse.Row {
+	var rows []warehouse.Row
+	for _, batch := range s.batches {
+		rows = append(rows, batch...)
+	}
+	return rows
+}
+
+func TestService_Snapshot(t *testing.T) {
+	tasks := taskService.NewService(authService.NewService(&config.Config{}))
+	created, err := tasks.CreateTask(&task.CreateTaskRequest{Title: "Pay rent", Tags: []string{"home"}}, johnID)
+	require.NoError(t, err)
+	_, err = tasks.AddChecklistItem(created.ID, &task.AddChecklistItemRequest{Text: "Transfer"}, johnID)
+	require.NoError(t, err)
+	all := len(tasks.AllTasks())
+
+	sink := &fakeSink{}
+	svc := NewService(tasks, sink, 2)
+	now := time.Date(2024, 1, 15, 23, 30, 0, 0, time.FixedZone("WIB", 7*3600))
+
+	inserted, err := svc.Snapshot(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, all, inserted)
+	assert.Len(t, sink.batches, (all+1)/2)
+
+	// Rows carry the state of tasks on the UTC day of the time, without their content
+	var row warehouse.Row
+	for _, r := range sink.rows() {
+		if r.Values["task_id"] == created.ID.String() {
+			row = r
+		}
+	}
+	assert.Equal(t, "2024-01-15/"+created.ID.String(), row.ID)
+	assert.Equal(t, "2024-01-15", row.Values["snapshot_date"])
+	assert.Equal(t, johnID.String(), row.Values["user_id"])
+	assert.Equal(t, "pending", row.Values["status"])
+	assert.Equal(t, `["home"]`, row.Values["tags"])
+	assert.Equal(t, 1, row.Values["checklist_items"])
+	assert.Equal(t, 0, row.Values["checklist_done"])
+	assert.Nil(t, row.Values["workspace_id"])
+	assert.Nil(t, row.Values["completed_at"])
+	assert.NotContains(t, row.Values, "title")
+
+	// Each day is snapshotted once
+	inserted, err = svc.Snapshot(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, inserted)
+}
+
+func TestService_SnapshotFailure(t *testing.T) {
+	tasks := taskService.NewService(authService.NewService(&config.Config{}))
+	for _, title := range []string{"Pay rent", "Book flights", "Call mom"} {
+		_, err := tasks.CreateTask(&task.CreateTaskRequest{Title: title}, johnID)
+		require.NoError(t, err)
+	}
+	all := len(tasks.AllTasks())
+
+	sink := &fakeSink{failAt: 2}
+	svc := NewService(tasks, sink, 1)
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	inserted, err := svc.Snapshot(context.Background(), now)
+	assert.EqualError(t, err, "warehouse unavailable")
+	assert.Equal(t, 1, inserted)
+
+	// A failed snapshot is taken again whole, with the same row IDs
+	inserted, err = svc.Snapshot(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, all, inserted)
+	assert.Equal(t, sink.batches[0][0].ID, sink.batches[1][0].ID)
+
+	// Snapshots stop when the context is done
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = svc.Snapshot(cancelled, now.Add(24*time.Hour))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,427 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/domain/workspace"
+	authService "todo-api/internal/service/auth"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the workspace service interface
+type Service interface {
+	CreateWorkspace(req *workspace.CreateWorkspaceRequest, userID uuid.UUID) (*workspace.Workspace, error)
+	ListWorkspaces(userID uuid.UUID) []*workspace.Workspace
+	GetWorkspace(id uuid.UUID) (*workspace.Workspace, error)
+	GetMember(workspaceID, userID uuid.UUID) (*workspace.Member, error)
+	ListMembers(workspaceID uuid.UUID) []*workspace.Member
+	RemoveMember(workspaceID, userID uuid.UUID, actor *workspace.Member) error
+	InviteMember(workspaceID uuid.UUID, req *workspace.InviteMemberRequest, actor *workspace.Member) (*workspace.Invitation, error)
+	ListInvitations(workspaceID uuid.UUID) []*workspace.Invitation
+	ListInvitationsForEmail(email string) []*workspace.Invitation
+	RevokeInvitation(workspaceID, invitationID uuid.UUID) error
+	AcceptInvitation(invitationID, userID uuid.UUID, email string) (*workspace.Member, error)
+	CreateProject(workspaceID uuid.UUID, req *workspace.CreateProjectRequest, userID uuid.UUID) (*workspace.Project, error)
+	ListProjects(workspaceID uuid.UUID) []*workspace.Project
+	GetProject(workspaceID, projectID uuid.UUID) (*workspace.Project, error)
+	// AllProjects returns the projects of every workspace, for background jobs
+	AllProjects() []*workspace.Project
+	Role(workspaceID, userID uuid.UUID) (workspace.Role, bool)
+	HasProject(workspaceID, projectID uuid.UUID) bool
+	ProjectName(workspaceID, projectID uuid.UUID) (string, bool)
+	RemoveUser(userID uuid.UUID, email string) ([]uuid.UUID, error)
+}
+
+// TenantDirectory resolves the tenant users belong to and the quota of each tenant
+type TenantDirectory interface {
+	TenantOf(userID uuid.UUID) uuid.UUID
+	Quota(tenantID uuid.UUID) tenant.Quota
+}
+
+// singleTenant is a directory placing every user in the default tenant without quotas
+type singleTenant struct{}
+
+func (singleTenant) TenantOf(uuid.UUID) uuid.UUID { return tenant.DefaultTenantID }
+func (singleTenant) Quota(uuid.UUID) tenant.Quota { return tenant.Quota{} }
+
+// service implements the workspace service
+type service struct {
+	workspaces  map[uuid.UUID]*workspace.Workspace            // Mock workspace storage
+	members     map[uuid.UUID]map[uuid.UUID]*workspace.Member // workspace ID -> user ID -> member
+	invitations map[uuid.UUID]*workspace.Invitation           // Mock invitation storage
+	projects    map[uuid.UUID]*workspace.Project              // Mock project storage
+	authService authService.Service
+	tenants     TenantDirectory
+}
+
+// NewService creates a new workspace service
+func NewService(authSvc authService.Service) Service {
+	return NewServiceWithTenants(authSvc, singleTenant{})
+}
+
+// NewServiceWithTenants creates a new workspace service isolating workspaces per tenant
+// as resolved by the given tenant directory
+func NewServiceWithTenants(authSvc authService.Service, tenants TenantDirectory) Service {
+	return &service{
+		workspaces:  make(map[uuid.UUID]*workspace.Workspace),
+		members:     make(map[uuid.UUID]map[uuid.UUID]*workspace.Member),
+		invitations: make(map[uuid.UUID]*workspace.Invitation),
+		projects:    make(map[uuid.UUID]*workspace.Project),
+		authService: authSvc,
+		tenants:     tenants,
+	}
+}
+
+// CreateWorkspace creates a workspace owned by the user
+func (s *service) CreateWorkspace(req *workspace.CreateWorkspaceRequest, userID uuid.UUID) (*workspace.Workspace, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	tenantID := s.tenants.TenantOf(userID)
+	if err := s.checkWorkspaceQuota(tenantID); err != nil {
+		return nil, err
+	}
+
+	newWorkspace := workspace.NewWorkspace(req.Name, userID)
+	newWorkspace.TenantID = tenantID
+	s.workspaces[newWorkspace.ID] = newWorkspace
+	s.members[newWorkspace.ID] = map[uuid.UUID]*workspace.Member{
+		userID: workspace.NewMember(newWorkspace.ID, userID, workspace.RoleOwner),
+	}
+
+	return newWorkspace, nil
+}
+
+// ListWorkspaces retrieves the workspaces the user is a member of, oldest first
+func (s *service) ListWorkspaces(userID uuid.UUID) []*workspace.Workspace {
+	var workspaces []*workspace.Workspace
+	for id, w := range s.workspaces {
+		if _, ok := s.members[id][userID]; ok {
+			workspaces = append(workspaces, w)
+		}
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt)
+	})
+	return workspaces
+}
+
+// GetWorkspace retrieves a workspace by ID
+func (s *service) GetWorkspace(id uuid.UUID) (*workspace.Workspace, error) {
+	w, exists := s.workspaces[id]
+	if !exists {
+		return nil, errors.New("workspace not found")
+	}
+	return w, nil
+}
+
+// GetMember retrieves the user's membership in a workspace
+func (s *service) GetMember(workspaceID, userID uuid.UUID) (*workspace.Member, error) {
+	if _, err := s.GetWorkspace(workspaceID); err != nil {
+		return nil, err
+	}
+
+	member, ok := s.members[workspaceID][userID]
+	if !ok {
+		return nil, errors.New("not a member of this workspace")
+	}
+	return member, nil
+}
+
+// ListMembers retrieves the members of a workspace, most privileged first
+func (s *service) ListMembers(workspaceID uuid.UUID) []*workspace.Member {
+	var members []*workspace.Member
+	for _, member := range s.members[workspaceID] {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Role != members[j].Role {
+			return members[i].Role.AtLeast(members[j].Role)
+		}
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+	return members
+}
+
+// RemoveMember removes a user from a workspace. Members may leave; admins may remove
+// members below their role. The owner cannot be removed.
+func (s *service) RemoveMember(workspaceID, userID uuid.UUID, actor *workspace.Member) error {
+	member, err := s.GetMember(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == workspace.RoleOwner {
+		return errors.New("the workspace owner cannot be removed")
+	}
+
+	leaving := actor.UserID == userID
+	if !leaving && (!actor.Role.AtLeast(workspace.RoleAdmin) || member.Role.AtLeast(actor.Role)) {
+		return errors.New("access denied")
+	}
+
+	delete(s.members[workspaceID], userID)
+	return nil
+}
+
+// InviteMember invites a user by email. Only admins and the owner may invite.
+func (s *service) InviteMember(workspaceID uuid.UUID, req *workspace.InviteMemberRequest, actor *workspace.Member) (*workspace.Invitation, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !actor.Role.AtLeast(workspace.RoleAdmin) {
+		return nil, errors.New("access denied")
+	}
+
+	if user, err := s.authService.GetUserByEmail(strings.ToLower(req.Email)); err == nil {
+		if _, ok := s.members[workspaceID][user.ID]; ok {
+			return nil, errors.New("user is already a member")
+		}
+	}
+
+	invitation := workspace.NewInvitation(workspaceID, req.Email, req.Role, actor.UserID)
+	s.invitations[invitation.ID] = invitation
+
+	return invitation, nil
+}
+
+// ListInvitations retrieves the pending invitations of a workspace, oldest first
+func (s *service) ListInvitations(workspaceID uuid.UUID) []*workspace.Invitation {
+	return s.pendingInvitations(func(i *workspace.Invitation) bool {
+		return i.WorkspaceID == workspaceID
+	})
+}
+
+// ListInvitationsForEmail retrieves the pending invitations sent to an email address, oldest
+// first. Invitations to workspaces of other tenants than the user's are left out.
+func (s *service) ListInvitationsForEmail(email string) []*workspace.Invitation {
+	email = strings.ToLower(email)
+	tenantID := tenant.DefaultTenantID
+	if user, err := s.authService.GetUserByEmail(email); err == nil {
+		tenantID = s.tenants.TenantOf(user.ID)
+	}
+
+	return s.pendingInvitations(func(i *workspace.Invitation) bool {
+		w, exists := s.workspaces[i.WorkspaceID]
+		return i.Email == email && exists && w.TenantID == tenantID
+	})
+}
+
+// RevokeInvitation cancels a pending invitation
+func (s *service) RevokeInvitation(workspaceID, invitationID uuid.UUID) error {
+	invitation, exists := s.invitations[invitationID]
+	if !exists || invitation.WorkspaceID != workspaceID || invitation.Status != workspace.InvitationPending {
+		return errors.New("invitation not found")
+	}
+
+	invitation.Status = workspace.InvitationRevoked
+	return nil
+}
+
+// AcceptInvitation adds the user to the invitation's workspace.
+// The invitation must have been sent to the user's email address.
+func (s *service) AcceptInvitation(invitationID, userID uuid.UUID, email string) (*workspace.Member, error) {
+	invitation, exists := s.invitations[invitationID]
+	if !exists || invitation.Status != workspace.InvitationPending || invitation.Email != strings.ToLower(email) {
+		return nil, errors.New("invitation not found")
+	}
+
+	if invitation.IsExpired() {
+		return nil, errors.New("invitation has expired")
+	}
+
+	w, err := s.GetWorkspace(invitation.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users can only join workspaces of their own tenant
+	if w.TenantID != s.tenants.TenantOf(userID) {
+		return nil, errors.New("invitation not found")
+	}
+
+	invitation.Status = workspace.InvitationAccepted
+
+	// Accepting again keeps the existing role
+	if member, ok := s.members[invitation.WorkspaceID][userID]; ok {
+		return member, nil
+	}
+
+	member := workspace.NewMember(invitation.WorkspaceID, userID, invitation.Role)
+	s.members[invitation.WorkspaceID][userID] = member
+
+	return member, nil
+}
+
+// CreateProject creates a project in a workspace
+func (s *service) CreateProject(workspaceID uuid.UUID, req *workspace.CreateProjectRequest, userID uuid.UUID) (*workspace.Project, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetWorkspace(workspaceID); err != nil {
+		return nil, err
+	}
+
+	project := workspace.NewProject(workspaceID, req.Name, userID)
+	s.projects[project.ID] = project
+
+	return project, nil
+}
+
+// ListProjects retrieves the projects of a workspace, oldest first
+func (s *service) ListProjects(workspaceID uuid.UUID) []*workspace.Project {
+	var projects []*workspace.Project
+	for _, project := range s.projects {
+		if project.WorkspaceID == workspaceID {
+			projects = append(projects, project)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].CreatedAt.Before(projects[j].CreatedAt)
+	})
+	return projects
+}
+
+// GetProject retrieves a project of a workspace
+func (s *service) GetProject(workspaceID, projectID uuid.UUID) (*workspace.Project, error) {
+	project, exists := s.projects[projectID]
+	if !exists || project.WorkspaceID != workspaceID {
+		return nil, errors.New("project not found")
+	}
+	return project, nil
+}
+
+// AllProjects retrieves the projects of every workspace, oldest first
+func (s *service) AllProjects() []*workspace.Project {
+	projects := make([]*workspace.Project, 0, len(s.projects))
+	for _, project := range s.projects {
+		projects = append(projects, project)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].CreatedAt.Before(projects[j].CreatedAt)
+	})
+	return projects
+}
+
+// Role returns the user's role in a workspace, reporting false when the user is not a member
+func (s *service) Role(workspaceID, userID uuid.UUID) (workspace.Role, bool) {
+	member, ok := s.members[workspaceID][userID]
+	if !ok {
+		return "", false
+	}
+	return member.Role, true
+}
+
+// HasProject reports whether the project belongs to the workspace
+func (s *service) HasProject(workspaceID, projectID uuid.UUID) bool {
+	project, exists := s.projects[projectID]
+	return exists && project.WorkspaceID == workspaceID
+}
+
+// ProjectName returns the name of a project of the workspace
+func (s *service) ProjectName(workspaceID, projectID uuid.UUID) (string, bool) {
+	project, exists := s.projects[projectID]
+	if !exists || project.WorkspaceID != workspaceID {
+		return "", false
+	}
+	return project.Name, true
+}
+
+// RemoveUser removes an erased user from every workspace, revoking the invitations sent to
+// their email address and anonymizing the invitations and projects they created. Workspaces
+// the user owns alone are deleted and their IDs returned. Nothing changes when the user owns a
+// workspace with other members, as someone must take over its tasks first.
+func (s *service) RemoveUser(userID uuid.UUID, email string) ([]uuid.UUID, error) {
+	var owned []uuid.UUID
+	for id, members := range s.members {
+		if member, ok := members[userID]; ok && member.Role == workspace.RoleOwner {
+			if len(members) > 1 {
+				return nil, errors.New("account owns workspaces with other members")
+			}
+			owned = append(owned, id)
+		}
+	}
+
+	for _, id := range owned {
+		delete(s.workspaces, id)
+		delete(s.members, id)
+	}
+	for _, members := range s.members {
+		delete(members, userID)
+	}
+
+	for id, invitation := range s.invitations {
+		switch {
+		case !s.exists(invitation.WorkspaceID):
+			delete(s.invitations, id)
+		case invitation.Email == strings.ToLower(email) && invitation.Status == workspace.InvitationPending:
+			invitation.Status = workspace.InvitationRevoked
+		}
+		if invitation.InvitedBy == userID {
+			invitation.InvitedBy = uuid.Nil
+		}
+	}
+
+	for id, project := range s.projects {
+		if !s.exists(project.WorkspaceID) {
+			delete(s.projects, id)
+		} else if project.CreatedBy == userID {
+			project.CreatedBy = uuid.Nil
+		}
+	}
+
+	return owned, nil
+}
+
+// exists reports whether the workspace exists
+func (s *service) exists(workspaceID uuid.UUID) bool {
+	_, exists := s.workspaces[workspaceID]
+	return exists
+}
+
+// pendingInvitations returns the pending, unexpired invitations matching the predicate, oldest first
+func (s *service) pendingInvitations(match func(*workspace.Invitation) bool) []*workspace.Invitation {
+	var invitations []*workspace.Invitation
+	for _, invitation := range s.invitations {
+		if invitation.Status == workspace.InvitationPending && !invitation.IsExpired() && match(invitation) {
+			invitations = append(invitations, invitation)
+		}
+	}
+
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+	})
+	return invitations
+}
+
+// checkWorkspaceQuota returns an error when the tenant cannot hold another workspace
+func (s *service) checkWorkspaceQuota(tenantID uuid.UUID) error {
+	quota := s.tenants.Quota(tenantID)
+
+	count := 0
+	for _, w := range s.workspaces {
+		if w.TenantID == tenantID {
+			count++
+		}
+	}
+
+	if !quota.AllowsWorkspaces(count) {
+		return fmt.Errorf("%w: at most %d workspaces allowed", tenant.ErrQuotaExceeded, quota.MaxWorkspaces)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	"todo-api/internal/domain/tenant"
+	"todo-api/internal/domain/workspace"
+	"todo-api/internal/service/auth"
+	tenantService "todo-api/internal/service/tenant"
+	"todo-api/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	johnID = uuid.MustParse("3484ec33-20f9-4993-a25f-f49f6f5dbe54")
+	janeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	mikeID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440003")
+)
+
+func setupTestService(t *testing.T) Service {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+
+	return NewService(auth.NewService(cfg))
+}
+
+// join invites the user by email and accepts the invitation on their behalf
+func join(t *testing.T, service Service, workspaceID uuid.UUID, inviter *workspace.Member, userID uuid.UUID, email string, role workspace.Role) *workspace.Member {
+	invitation, err := service.InviteMember(workspaceID, &workspace.InviteMemberRequest{Email: email, Role: role}, inviter)
+	require.NoError(t, err)
+
+	member, err := service.AcceptInvitation(invitation.ID, userID, email)
+	require.NoError(t, err)
+	return member
+}
+
+func TestService_CreateWorkspace(t *testing.T) {
+	service := setupTestService(t)
+
+	created, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, johnID, created.OwnerID)
+
+	owner, err := service.GetMember(created.ID, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, workspace.RoleOwner, owner.Role)
+
+	assert.Len(t, service.ListWorkspaces(johnID), 1)
+	assert.Empty(t, service.ListWorkspaces(janeID))
+
+	_, err = service.GetMember(created.ID, janeID)
+	assert.EqualError(t, err, "not a member of this workspace")
+	_, err = service.GetMember(uuid.New(), johnID)
+	assert.EqualError(t, err, "workspace not found")
+
+	_, err = service.CreateWorkspace(&workspace.CreateWorkspaceRequest{}, johnID)
+	assert.EqualError(t, err, "name is required")
+}
+
+func TestService_Invitations(t *testing.T) {
+	service := setupTestService(t)
+	created, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	owner, _ := service.GetMember(created.ID, johnID)
+
+	invitation, err := service.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "Jane.Smith@example.com"}, owner)
+	require.NoError(t, err)
+	assert.Len(t, service.ListInvitations(created.ID), 1)
+	assert.Len(t, service.ListInvitationsForEmail("jane.smith@example.com"), 1)
+
+	// Only the invited email address can accept
+	_, err = service.AcceptInvitation(invitation.ID, mikeID, "mike.wilson@example.com")
+	assert.EqualError(t, err, "invitation not found")
+
+	member, err := service.AcceptInvitation(invitation.ID, janeID, "jane.smith@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, workspace.RoleMember, member.Role)
+	assert.Empty(t, service.ListInvitations(created.ID))
+
+	role, ok := service.Role(created.ID, janeID)
+	assert.True(t, ok)
+	assert.Equal(t, workspace.RoleMember, role)
+
+	// Members cannot invite, and existing members cannot be invited again
+	_, err = service.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "mike.wilson@example.com"}, member)
+	assert.EqualError(t, err, "access denied")
+	_, err = service.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "jane.smith@example.com"}, owner)
+	assert.EqualError(t, err, "user is already a member")
+
+	// Revoked and expired invitations cannot be accepted
+	revoked, err := service.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "mike.wilson@example.com"}, owner)
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeInvitation(created.ID, revoked.ID))
+	_, err = service.AcceptInvitation(revoked.ID, mikeID, "mike.wilson@example.com")
+	assert.EqualError(t, err, "invitation not found")
+
+	expired, err := service.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "mike.wilson@example.com"}, owner)
+	require.NoError(t, err)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = service.AcceptInvitation(expired.ID, mikeID, "mike.wilson@example.com")
+	assert.EqualError(t, err, "invitation has expired")
+}
+
+func TestService_RemoveMember(t *testing.T) {
+	service := setupTestService(t)
+	created, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	owner, _ := service.GetMember(created.ID, johnID)
+
+	admin := join(t, service, created.ID, owner, janeID, "jane.smith@example.com", workspace.RoleAdmin)
+	member := join(t, service, created.ID, owner, mikeID, "mike.wilson@example.com", workspace.RoleMember)
+
+	members := service.ListMembers(created.ID)
+	require.Len(t, members, 3)
+	assert.Equal(t, []workspace.Role{workspace.RoleOwner, workspace.RoleAdmin, workspace.RoleMember},
+		[]workspace.Role{members[0].Role, members[1].Role, members[2].Role})
+
+	// Members cannot remove others, and nobody can remove the owner
+	assert.EqualError(t, service.RemoveMember(created.ID, janeID, member), "access denied")
+	assert.EqualError(t, service.RemoveMember(created.ID, johnID, admin), "the workspace owner cannot be removed")
+
+	// Admins remove members; members may leave
+	require.NoError(t, service.RemoveMember(created.ID, mikeID, admin))
+	require.NoError(t, service.RemoveMember(created.ID, janeID, admin))
+	assert.Len(t, service.ListMembers(created.ID), 1)
+}
+
+func TestService_RemoveUser(t *testing.T) {
+	service := setupTestService(t)
+	shared, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	owner, _ := service.GetMember(shared.ID, johnID)
+	admin := join(t, service, shared.ID, owner, janeID, "jane.smith@example.com", workspace.RoleAdmin)
+
+	solo, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Side project"}, janeID)
+	require.NoError(t, err)
+	_, err = service.CreateProject(shared.ID, &workspace.CreateProjectRequest{Name: "Launch"}, janeID)
+	require.NoError(t, err)
+	_, err = service.InviteMember(shared.ID, &workspace.InviteMemberRequest{Email: "mike.wilson@example.com"}, admin)
+	require.NoError(t, err)
+	ops, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Ops"}, johnID)
+	require.NoError(t, err)
+	opsOwner, _ := service.GetMember(ops.ID, johnID)
+	_, err = service.InviteMember(ops.ID, &workspace.InviteMemberRequest{Email: "Jane.Smith@example.com"}, opsOwner)
+	require.NoError(t, err)
+
+	// Owners of workspaces with other members cannot be removed
+	_, err = service.RemoveUser(johnID, "john.doe@example.com")
+	assert.EqualError(t, err, "account owns workspaces with other members")
+	assert.Len(t, service.ListMembers(shared.ID), 2)
+
+	deleted, err := service.RemoveUser(janeID, "jane.smith@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{solo.ID}, deleted)
+
+	_, err = service.GetWorkspace(solo.ID)
+	assert.EqualError(t, err, "workspace not found")
+	assert.Empty(t, service.ListWorkspaces(janeID))
+	assert.Len(t, service.ListMembers(shared.ID), 1)
+
+	// Invitations to the user are revoked, and what they created is anonymized
+	invitations := service.ListInvitations(shared.ID)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, "mike.wilson@example.com", invitations[0].Email)
+	assert.Equal(t, uuid.Nil, invitations[0].InvitedBy)
+	assert.Empty(t, service.ListInvitations(ops.ID))
+	assert.Equal(t, uuid.Nil, service.ListProjects(shared.ID)[0].CreatedBy)
+}
+
+func TestService_Projects(t *testing.T) {
+	service := setupTestService(t)
+	created, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+
+	project, err := service.CreateProject(created.ID, &workspace.CreateProjectRequest{Name: "Launch"}, johnID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*workspace.Project{project}, service.ListProjects(created.ID))
+	assert.True(t, service.HasProject(created.ID, project.ID))
+	assert.False(t, service.HasProject(uuid.New(), project.ID))
+
+	found, err := service.GetProject(created.ID, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, project, found)
+	_, err = service.GetProject(uuid.New(), project.ID)
+	assert.EqualError(t, err, "project not found")
+	assert.Equal(t, []*workspace.Project{project}, service.AllProjects())
+
+	_, err = service.CreateProject(uuid.New(), &workspace.CreateProjectRequest{Name: "Orphan"}, johnID)
+	assert.EqualError(t, err, "workspace not found")
+}
+
+func TestService_TenantIsolation(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:       "test-secret",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+	authSvc := auth.NewService(cfg)
+	tenants := tenantService.NewService(authSvc)
+	service := NewServiceWithTenants(authSvc, tenants)
+	aliceID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440004")
+
+	created, err := service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Platform"}, johnID)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.DefaultTenantID, created.TenantID)
+	owner, err := service.GetMember(created.ID, johnID)
+	require.NoError(t, err)
+
+	// Users of other tenants neither see nor accept invitations to the workspace
+	invitation, err := service.InviteMember(created.ID, &workspace.InviteMemberRequest{Email: "alice@acme.example.com"}, owner)
+	require.NoError(t, err)
+	assert.Empty(t, service.ListInvitationsForEmail("alice@acme.example.com"))
+
+	_, err = service.AcceptInvitation(invitation.ID, aliceID, "alice@acme.example.com")
+	assert.EqualError(t, err, "invitation not found")
+
+	// Workspaces count against the quota of their tenant
+	_, err = tenants.UpdateTenant(auth.AcmeTenantID, &tenant.UpdateTenantRequest{Quota: &tenant.Quota{MaxWorkspaces: 1}})
+	require.NoError(t, err)
+
+	_, err = service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Acme"}, aliceID)
+	require.NoError(t, err)
+	_, err = service.CreateWorkspace(&workspace.CreateWorkspaceRequest{Name: "Acme 2"}, aliceID)
+	assert.ErrorIs(t, err, tenant.ErrQuotaExceeded)
+}
@@ -0,0 +1,283 @@
+// Package blob stores files in object storage. Clients upload and download objects directly
+// with presigned URLs, so large files never pass through the API server; only files the
+// server receives itself are uploaded by it.
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxURLTTL is the longest validity of presigned URLs both S3 and GCS accept
+const MaxURLTTL = 7 * 24 * time.Hour
+
+// requestTTL is the validity of the presigned URLs of requests made by the store itself
+const requestTTL = time.Minute
+
+// ErrNotFound is returned for objects missing from the bucket
+var ErrNotFound = errors.New("object not found")
+
+// PresignedURL grants a single kind of request on an object until it expires. Requests must
+// send the headers with the given values, as they are part of the signature.
+type PresignedURL struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Object describes an object stored in the bucket
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Store defines the object storage interface. Keys are object names within the bucket,
+// such as attachments/<task ID>/<attachment ID>.
+type Store interface {
+	// PresignUpload returns a URL the object is uploaded to with a PUT request of the
+	// content type
+	PresignUpload(key, contentType string, ttl time.Duration) (*PresignedURL, error)
+	// PresignDownload returns a URL the object is downloaded from, saved under the file name
+	PresignDownload(key, filename string, ttl time.Duration) (*PresignedURL, error)
+	// Stat describes the object, or returns ErrNotFound when it was not uploaded
+	Stat(ctx context.Context, key string) (*Object, error)
+	// Open returns the content of the object, for files the server reads itself, such as
+	// uploads to scan. The caller must close it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Upload stores the object with the content type, for files received by the server
+	// itself, such as the attachments of emails
+	Upload(ctx context.Context, key, contentType string, data []byte) error
+	// Delete deletes the object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// ContentDisposition returns the Content-Disposition of downloads saved under the file name
+func ContentDisposition(filename string) string {
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename}); disposition != "" {
+		return disposition
+	}
+	return "attachment"
+}
+
+// signer presigns requests on the objects of a bucket, returning the URL to request
+type signer interface {
+	presign(method, key string, query url.Values, headers map[string]string, now time.Time, ttl time.Duration) (string, error)
+}
+
+// presignedStore implements a store whose own requests are made with presigned URLs too,
+// so every request is authenticated the same way
+type presignedStore struct {
+	name   string // name of the service in errors
+	signer signer
+	client *http.Client
+	now    func() time.Time
+}
+
+// PresignUpload returns a URL the object is uploaded to
+func (s *presignedStore) PresignUpload(key, contentType string, ttl time.Duration) (*PresignedURL, error) {
+	return s.presign(http.MethodPut, key, nil, map[string]string{"Content-Type": contentType}, ttl)
+}
+
+// PresignDownload returns a URL the object is downloaded from
+func (s *presignedStore) PresignDownload(key, filename string, ttl time.Duration) (*PresignedURL, error) {
+	query := url.Values{"response-content-disposition": {ContentDisposition(filename)}}
+	return s.presign(http.MethodGet, key, query, nil, ttl)
+}
+
+// presign presigns a request valid for the TTL
+func (s *presignedStore) presign(method, key string, query url.Values, headers map[string]string,
+	ttl time.Duration) (*PresignedURL, error) {
+	if ttl <= 0 || ttl > MaxURLTTL {
+		return nil, fmt.Errorf("presigned URLs must be valid for up to %s", MaxURLTTL)
+	}
+
+	now := s.now().UTC()
+	signedURL, err := s.signer.presign(method, key, query, headers, now, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &PresignedURL{Method: method, URL: signedURL, Headers: headers, ExpiresAt: now.Add(ttl)}, nil
+}
+
+// Stat describes the object
+func (s *presignedStore) Stat(ctx context.Context, key string) (*Object, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &Object{Key: key, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, s.statusError(resp)
+	}
+}
+
+// Open returns the content of the object
+func (s *presignedStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.statusError(resp)
+	}
+}
+
+// Upload stores the object
+func (s *presignedStore) Upload(ctx context.Context, key, contentType string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, map[string]string{"Content-Type": contentType}, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.statusError(resp)
+	}
+	return nil
+}
+
+// Delete deletes the object
+func (s *presignedStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s.statusError(resp)
+	}
+}
+
+// do makes a request on the object with a short-lived presigned URL, sending the signed
+// headers and the body, if any
+func (s *presignedStore) do(ctx context.Context, method, key string, headers map[string]string, body []byte) (*http.Response, error) {
+	signedURL, err := s.signer.presign(method, key, nil, headers, s.now().UTC(), requestTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, signedURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return s.client.Do(req)
+}
+
+// statusError describes an unexpected response
+func (s *presignedStore) statusError(resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s responded with status %d: %s", s.name, resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// signedHeaderNames returns the names of the headers signed along with the host, lowercase,
+// sorted, and separated by semicolons
+func signedHeaderNames(headers map[string]string) string {
+	names := []string{"host"}
+	for name := range headers {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ";")
+}
+
+// canonicalRequest returns the canonical form of a presigned request signed by both S3 and
+// GCS, whose payload is not signed
+func canonicalRequest(method, path, host string, query url.Values, headers map[string]string) string {
+	lowered := map[string]string{"host": host}
+	for name, value := range headers {
+		lowered[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	signedHeaders := signedHeaderNames(headers)
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		canonicalHeaders.WriteString(name + ":" + lowered[name] + "\n")
+	}
+
+	return strings.Join([]string{
+		method,
+		encodePath(path),
+		canonicalQuery(query),
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+}
+
+// canonicalQuery returns the query string sorted by name, with names and values encoded
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, encode(name, true)+"="+encode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// encodePath encodes every segment of the path
+func encodePath(path string) string {
+	return encode(path, false)
+}
+
+// encode percent-encodes every byte but unreserved characters, and slashes unless
+// encodeSlash is set, as both S3 and GCS expect in signatures
+func encode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hashHex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,101 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3Service is the service name used to sign S3 requests
+const s3Service = "s3"
+
+// S3Config configures an Amazon S3 bucket, or a bucket of an S3-compatible service
+type S3Config struct {
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // optional, for temporary credentials
+	Endpoint        string // optional, e.g. for MinIO; defaults to the regional endpoint
+	PathStyle       bool   // address the bucket in the path rather than the host name
+	Timeout         time.Duration
+	Transport       http.RoundTripper // defaults to http.DefaultTransport
+}
+
+// s3Signer presigns requests with AWS Signature Version 4
+type s3Signer struct {
+	cfg      S3Config
+	endpoint *url.URL
+}
+
+// NewS3Store creates a store of the objects of an S3 bucket
+func NewS3Store(cfg S3Config) (Store, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", s3Service, cfg.Region)
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+
+	return &presignedStore{
+		name:   "s3",
+		signer: &s3Signer{cfg: cfg, endpoint: u},
+		client: &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport},
+		now:    time.Now,
+	}, nil
+}
+
+// presign presigns a request on the object
+func (s *s3Signer) presign(method, key string, query url.Values, headers map[string]string, now time.Time,
+	ttl time.Duration) (string, error) {
+	host := s.endpoint.Host
+	path := s.endpoint.Path + "/" + key
+	if s.cfg.PathStyle {
+		path = s.endpoint.Path + "/" + s.cfg.Bucket + "/" + key
+	} else {
+		host = s.cfg.Bucket + "." + host
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := strings.Join([]string{date, s.cfg.Region, s3Service, "aws4_request"}, "/")
+
+	signed := url.Values{}
+	for name, values := range query {
+		signed[name] = values
+	}
+	signed.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	signed.Set("X-Amz-Credential", s.cfg.AccessKeyID+"/"+scope)
+	signed.Set("X-Amz-Date", amzDate)
+	signed.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	signed.Set("X-Amz-SignedHeaders", signedHeaderNames(headers))
+	if s.cfg.SessionToken != "" {
+		signed.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+
+	canonical := canonicalRequest(method, path, host, signed, headers)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex(canonical)}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.cfg.Region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return s.endpoint.Scheme + "://" + host + encodePath(path) + "?" + canonicalQuery(signed) +
+		"&X-Amz-Signature=" + signature, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -1,28 +1,142 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"todo-api/pkg/blob"
+	"todo-api/pkg/cron"
+	"todo-api/pkg/gcal"
+	"todo-api/pkg/github"
+	"todo-api/pkg/ldap"
+	"todo-api/pkg/mailer"
+	"todo-api/pkg/push"
+	"todo-api/pkg/redis"
+	"todo-api/pkg/resilience"
+	"todo-api/pkg/scan"
+	"todo-api/pkg/secrets"
+	"todo-api/pkg/slack"
+	"todo-api/pkg/stream"
+	"todo-api/pkg/stripe"
+	"todo-api/pkg/twilio"
+	"todo-api/pkg/warehouse"
+
 	"github.com/joho/godotenv"
 )
 
+// DefaultJWTSecretKey is the JWT secret used when none is configured. It is public, so it
+// is rejected in production.
+const DefaultJWTSecretKey = "todo-api-secret-key-change-in-production"
+
+// MinProductionSecretLength is the minimum length of the JWT secret in production
+const MinProductionSecretLength = 32
+
+// minBufferSize is the smallest connection buffer that holds the request line and headers
+// of ordinary requests
+const minBufferSize = 1024
+
 // Config holds all configuration for the application
 type Config struct {
-	Server ServerConfig
-	JWT    JWTConfig
-	App    AppConfig
+	Server     ServerConfig
+	TLS        TLSConfig
+	JWT        JWTConfig
+	Auth       AuthConfig
+	LDAP       LDAPConfig
+	SCIM       SCIMConfig
+	App        AppConfig
+	Limits     LimitsConfig
+	Search     SearchConfig
+	CORS       CORSConfig
+	IP         IPConfig
+	Login      LoginGuardConfig
+	Storage    StorageConfig
+	Secrets    SecretsConfig
+	Mail       MailConfig
+	Notify     NotificationsConfig
+	Account    AccountConfig
+	Slack      SlackConfig
+	Telegram   TelegramConfig
+	Twilio     TwilioConfig
+	Inbound    InboundEmailConfig
+	Billing    BillingConfig
+	Push       PushConfig
+	GitHub     GitHubConfig
+	Calendar   GoogleCalendarConfig
+	Files      AttachmentsConfig
+	Stream     EventStreamConfig
+	Jobs       JobsConfig
+	Reports    ReportsConfig
+	Warehouse  WarehouseConfig
+	Cache      CacheConfig
+	Redis      RedisConfig
+	Resilience ResilienceConfig
+	Health     HealthConfig
+
+	provider secrets.Provider        // secrets provider, if any
+	jwtKeys  atomic.Pointer[jwtKeys] // JWT secrets rotated by the secrets provider
+}
+
+// jwtKeys are the JWT secrets once the secrets provider rotated the configured one
+type jwtKeys struct {
+	current       string
+	previous      string // still verifies the tokens it signed until previousUntil
+	previousUntil time.Time
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port         string
-	Host         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	Port     string
+	Host     string
+	GRPCPort string
+	// TenantBaseDomain enables resolving tenants from subdomains such as acme.<TenantBaseDomain>
+	TenantBaseDomain string
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+	IdleTimeout      time.Duration
+	ShutdownTimeout  time.Duration // how long in-flight work may take to finish on shutdown
+	// RequestTimeout bounds the handling of API requests, and BulkRequestTimeout that of
+	// imports and exports. Zero disables them.
+	RequestTimeout     time.Duration
+	BulkRequestTimeout time.Duration
+	// SlowRequestThreshold is the duration from which requests are logged as slow. Zero
+	// disables the logs.
+	SlowRequestThreshold time.Duration
+	MaxConnections       int // concurrent connections served; more are refused
+	ReadBufferSize       int // per-connection read buffer in bytes, which also bounds request headers
+	WriteBufferSize      int // per-connection write buffer in bytes
+	// PublicRateLimit is the number of requests per minute each client address may make to
+	// public endpoints, such as shared project boards. Zero disables the limit.
+	PublicRateLimit int
+}
+
+// TLSConfig holds the configuration of TLS terminated by the HTTP server. TLS is enabled
+// with either a certificate and key or automatic certificates.
+type TLSConfig struct {
+	CertFile         string
+	KeyFile          string
+	AutocertDomains  []string // domains to obtain certificates for from Let's Encrypt
+	AutocertEmail    string   // optional contact address for the ACME account
+	AutocertCacheDir string   // directory certificates are cached in across restarts
+	RedirectPort     string   // port redirecting plain HTTP to HTTPS; empty disables
+
+	// Mutual TLS for internal deployments, enabled by ClientCAFile
+	ClientCAFile     string   // CA certificates client certificates are verified against
+	ClientAuthPaths  []string // path prefixes requiring a client certificate; empty means every path
+	ClientIdentities []string // service identities allowed; empty allows every verified certificate
 }
 
 // JWTConfig holds JWT configuration
@@ -33,89 +147,2079 @@ type JWTConfig struct {
 	Issuer          string
 }
 
+// AuthConfig holds the configuration of how users are authenticated
+type AuthConfig struct {
+	Provider string // local or ldap
+}
+
+// LDAPConfig holds the directory users are authenticated against when the auth provider
+// is ldap. Users are found by the user filter, then authenticated by binding as their entry.
+type LDAPConfig struct {
+	URL          string // ldap:// or ldaps:// URL of the directory
+	BindDN       string // account searching for users; searches are anonymous when empty
+	BindPassword string
+	BaseDN       string // entry users are searched under
+	UserFilter   string // filter finding a user, with {username} in place of the login email
+	StartTLS     bool   // upgrade ldap:// connections to TLS before binding
+	Timeout      time.Duration
+}
+
+// SCIMConfig holds the configuration of user provisioning by identity providers over SCIM
+type SCIMConfig struct {
+	Token string // bearer token of the identity provider; SCIM is disabled when empty
+}
+
+// LimitsConfig holds per-user limits
+type LimitsConfig struct {
+	MaxTasksPerUser int // 0 means unlimited
+	MaxBodySize     int // maximum request body size in bytes
+}
+
+// SearchConfig holds full-text search configuration
+type SearchConfig struct {
+	Engine                string // memory, elasticsearch, or opensearch
+	ElasticsearchURL      string
+	ElasticsearchIndex    string
+	ElasticsearchUsername string
+	ElasticsearchPassword string
+	ElasticsearchTimeout  time.Duration
+	// ReconcileInterval is how often tasks whose changes did not reach the cluster are
+	// indexed again; 0 disables reconciling
+	ReconcileInterval time.Duration
+}
+
+// CORSConfig holds cross-origin resource sharing configuration
+type CORSConfig struct {
+	AllowOrigins     []string // "*" or origins such as https://app.example.com; empty disables CORS
+	AllowHeaders     []string
+	AllowMethods     []string
+	AllowCredentials bool
+	MaxAge           time.Duration // how long browsers may cache preflight responses
+}
+
+// IPConfig holds client address resolution and IP filtering configuration. Empty lists
+// do not restrict access.
+type IPConfig struct {
+	TrustedProxies []netip.Prefix // proxies whose X-Forwarded-For header is trusted
+	Allow          []netip.Prefix // ranges allowed to call the API
+	Deny           []netip.Prefix // ranges denied from calling the API
+	AdminAllow     []netip.Prefix // ranges additionally allowed to call the admin API
+	AdminDeny      []netip.Prefix // ranges additionally denied from calling the admin API
+}
+
+// LoginGuardConfig holds login throttling and anomaly detection configuration
+type LoginGuardConfig struct {
+	Window             time.Duration // failed logins older than this are forgotten
+	MaxIPFailures      int           // failures from one address that flag it; 0 disables
+	MaxAccountFailures int           // failures for one account that flag it; 0 disables
+	FlagDuration       time.Duration // how long flagged addresses and accounts are throttled
+	ThrottleInterval   time.Duration // minimum time between login attempts while flagged
+	MaxTravelSpeedKmh  float64       // faster travel between logins is impossible; 0 disables
+}
+
+// StorageConfig holds storage configuration
+type StorageConfig struct {
+	Driver string // only memory is supported
+	// SeedFile holds the fixture users and tasks memory storage starts with instead of the
+	// built-in mock data; empty keeps the mock data
+	SeedFile string
+	// MaxTasks and MaxBytes cap the tasks stored across every user, by number and estimated
+	// size, so a misbehaving client cannot exhaust the memory of the process. Zero is unlimited.
+	MaxTasks int
+	MaxBytes int
+}
+
+// SecretsConfig holds the configuration of the secret manager settings are fetched from
+type SecretsConfig struct {
+	Provider        string        // none, vault, or aws
+	RefreshInterval time.Duration // how often secrets are fetched again; 0 disables refresh
+	Timeout         time.Duration
+
+	VaultAddress string
+	VaultToken   string
+	VaultPath    string // API path of the secret, e.g. secret/data/todo-api
+
+	AWSRegion          string
+	AWSSecretID        string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	AWSEndpoint        string // defaults to the regional endpoint
+}
+
+// MailConfig holds the configuration of outgoing email
+type MailConfig struct {
+	Provider string // log or smtp
+	From     string // sender address, e.g. Todo API <no-reply@example.com>
+	Timeout  time.Duration
+
+	SMTPHost        string
+	SMTPPort        string
+	SMTPUsername    string
+	SMTPPassword    string
+	SMTPImplicitTLS bool // connect over TLS rather than upgrading with STARTTLS
+}
+
+// NotificationsConfig holds the configuration of reminder, digest, and escalation emails
+type NotificationsConfig struct {
+	ReminderLeadTime   time.Duration // how long before tasks are due reminders are sent
+	ReminderInterval   time.Duration // how often due tasks are checked; 0 disables reminders
+	DigestInterval     time.Duration // how often digests are sent; 0 disables digests
+	DigestSchedule     string        // cron expression of when digests are sent, in UTC, instead of the interval
+	EscalationInterval time.Duration // how often escalation rules are evaluated; 0 disables escalations
+}
+
+// AccountConfig holds the configuration of account emails
+type AccountConfig struct {
+	PasswordResetTTL     time.Duration // how long password reset links are valid
+	EmailVerificationTTL time.Duration // how long email verification links are valid
+	ExportTTL            time.Duration // how long archives of data exports are kept
+}
+
+// SlackConfig holds the configuration of the Slack integration
+type SlackConfig struct {
+	SigningSecret string // signing secret of the Slack app; slash commands are rejected when empty
+	APIURL        string // base URL of the Slack Web API
+	Timeout       time.Duration
+}
+
+// TelegramConfig holds the configuration of the Telegram bot
+type TelegramConfig struct {
+	WebhookSecret string        // secret token set with setWebhook; the webhook is rejected when empty
+	BotUsername   string        // username of the bot, for links starting a chat with it
+	LinkTTL       time.Duration // how long link codes are valid
+}
+
+// TwilioConfig holds the configuration of SMS reminders sent through Twilio. Users cannot
+// verify phone numbers unless the account is configured.
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string // also verifies status callbacks
+	From       string // phone number, short code, or sender ID messages are sent from
+	APIURL     string // base URL of the REST API
+	Timeout    time.Duration
+	CodeTTL    time.Duration // how long phone verification codes are valid
+}
+
+// InboundEmailConfig holds the configuration of tasks created by email. Users send emails to
+// add+<token>@<domain>, received by Mailgun or Amazon SES and posted to the inbound webhook;
+// email-in is disabled without a domain.
+type InboundEmailConfig struct {
+	Domain      string // domain of the addresses, whose MX records point at the provider
+	Provider    string // mailgun or ses
+	SigningKey  string // HTTP webhook signing key of the Mailgun account
+	SESTopicARN string // SNS topic SES publishes received emails to
+	Timeout     time.Duration
+}
+
+// BillingConfig holds the configuration of subscriptions paid through Stripe. Plans are not
+// enforced unless Stripe is configured, so every user has unlimited use.
+type BillingConfig struct {
+	SecretKey     string // Stripe secret API key
+	WebhookSecret string // signing secret of the webhook endpoint
+	ProPriceID    string // price of the pro plan subscription
+	APIURL        string // base URL of the Stripe API
+	Timeout       time.Duration
+	SuccessURL    string // where customers are redirected after paying
+	CancelURL     string // where customers are redirected when leaving the checkout
+
+	// Limits of the free plan; 0 means unlimited
+	FreeMaxTasks        int
+	FreeMaxAttachments  int // attachments uploaded
+	FreeMaxIntegrations int // connected Slack, Telegram, GitHub, and Google Calendar accounts, and email-in addresses
+}
+
+// PushConfig holds the configuration of push notifications. Platforms without credentials
+// log notifications instead of sending them.
+type PushConfig struct {
+	FCMCredentialsFile string // JSON key of the Firebase service account sending to Android devices
+
+	APNsKeyFile string // .p8 token signing key sending to iOS devices
+	APNsKeyID   string
+	APNsTeamID  string
+	APNsTopic   string // bundle ID of the app
+	APNsSandbox bool   // send to development builds of the app
+
+	Timeout      time.Duration
+	MaxAttempts  int           // sends per notification, including retries
+	RetryBackoff time.Duration // delay before the first retry, doubling after each
+}
+
+// GitHubConfig holds the configuration of the GitHub integration. Projects cannot be linked
+// to repositories unless the OAuth app is configured.
+type GitHubConfig struct {
+	ClientID         string // client ID of the OAuth app
+	ClientSecret     string
+	WebhookSecret    string // secret of the repository webhooks; the webhook is rejected when empty
+	APIURL           string // base URL of the REST API
+	OAuthURL         string // base URL of the OAuth authorization pages
+	Timeout          time.Duration
+	SyncInterval     time.Duration // how often linked projects are synced; 0 disables the sync worker
+	AuthorizationTTL time.Duration // how long users have to authorize the OAuth app
+}
+
+// GoogleCalendarConfig holds the configuration of the Google Calendar integration. Users
+// cannot connect calendars unless the OAuth client is configured.
+type GoogleCalendarConfig struct {
+	ClientID         string // client ID of the OAuth web client
+	ClientSecret     string
+	Timeout          time.Duration
+	SyncInterval     time.Duration // how often connected calendars are synced; 0 disables the sync worker
+	AuthorizationTTL time.Duration // how long users have to grant access
+}
+
+// AttachmentsConfig holds the configuration of task attachments. Files are uploaded to and
+// downloaded from the bucket with presigned URLs; attachments are disabled without storage.
+type AttachmentsConfig struct {
+	Storage string // none, s3, or gcs
+	Bucket  string
+	MaxSize int           // maximum size of an attachment in bytes
+	URLTTL  time.Duration // how long presigned upload and download URLs are valid
+	Timeout time.Duration
+
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3SessionToken    string
+	S3Endpoint        string // defaults to the regional endpoint, e.g. set for MinIO
+	S3PathStyle       bool   // address the bucket in the path rather than the host name
+
+	GCSCredentialsFile string // JSON key of the service account signing URLs
+
+	// Uploaded files are scanned for malware when a scanner is set, and quarantined when
+	// flagged
+	Scanner        string        // none, clamav, or icap
+	ScannerAddress string        // host:port of clamd or the ICAP server, or the path of the clamd socket
+	ScannerService string        // path of the ICAP scanning service, e.g. avscan
+	ScannerTimeout time.Duration // how long scanning a file may take
+
+	// Thumbnails of uploaded images are generated in the background for each size, the
+	// longest side of the thumbnail in pixels. With none, no thumbnails are generated.
+	ThumbnailSizes []int
+}
+
+// EventStreamConfig holds the configuration of the event stream. Task and auth events are
+// kept in an outbox and relayed to the broker; events are not streamed without one.
+type EventStreamConfig struct {
+	Broker      string   // none, nats, or kafka
+	Servers     []string // host:port addresses of the brokers
+	Topic       string   // Kafka topic, or prefix of NATS subjects
+	Username    string
+	Password    string
+	TLS         bool
+	Timeout     time.Duration
+	BatchSize   int           // events published per request
+	MaxBackoff  time.Duration // longest delay between retries of failed deliveries
+	OutboxLimit int           // undelivered events kept, dropping the oldest beyond
+}
+
+// JobsConfig holds the configuration of the background job queue, which runs webhook
+// events, imports, reminders, and digests on a pool of workers
+type JobsConfig struct {
+	Workers         int           // jobs run concurrently
+	MaxAttempts     int           // attempts per job, including retries
+	RetryBackoff    time.Duration // delay before the first retry, doubling after each
+	MaxBackoff      time.Duration // longest delay between retries
+	DeadLetterLimit int           // jobs kept after failing every attempt, dropping the oldest beyond
+	Retention       time.Duration // how long finished jobs are kept for their status to be read
+}
+
+// ReportsConfig holds the configuration of the reports aggregated ahead of requests and of
+// the stats webhooks pushing task statistics
+type ReportsConfig struct {
+	BurndownSchedule string        // cron expression of when project burndowns are aggregated, in UTC; empty disables it
+	WebhookSchedule  string        // cron expression of when stats webhooks are delivered, in UTC; empty disables them
+	WebhookTimeout   time.Duration // timeout of posting to a stats webhook
+}
+
+// WarehouseConfig holds the configuration of the export to a data warehouse. Domain events
+// are kept in an outbox and relayed to the sink, and a snapshot of every task is inserted
+// on a schedule; nothing is exported without a sink.
+type WarehouseConfig struct {
+	Sink             string // none, bigquery, or redshift
+	Events           bool   // stream domain events into the events table
+	SnapshotSchedule string // cron expression of when tasks are snapshotted, in UTC; empty disables it
+	Timeout          time.Duration
+	BatchSize        int           // rows inserted per request
+	MaxBackoff       time.Duration // longest delay between retries of failed event inserts
+	OutboxLimit      int           // events not inserted yet kept, dropping the oldest beyond
+
+	// BigQuery datasets are written with the JSON key of a Google service account
+	GCPCredentialsFile string
+	BigQueryProject    string // defaults to the project of the service account
+	BigQueryDataset    string
+
+	// Redshift databases are written through the Data API with AWS credentials
+	AWSRegion          string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	RedshiftCluster    string // provisioned cluster, or
+	RedshiftWorkgroup  string // Redshift Serverless workgroup
+	RedshiftDatabase   string
+	RedshiftDBUser     string // database user connecting with temporary credentials, or
+	RedshiftSecretARN  string // Secrets Manager secret of the database credentials
+	RedshiftEndpoint   string // defaults to the regional endpoint of the Data API
+}
+
+// CacheConfig holds the configuration of the cache of hot task reads
+type CacheConfig struct {
+	Driver string        // none, memory, or redis
+	TTL    time.Duration // how long cached reads are kept at most
+}
+
+// RedisConfig holds the configuration of the connection to Redis
+type RedisConfig struct {
+	Addr     string // host:port of the server
+	Username string // ACL user, for Redis 6 or later
+	Password string
+	DB       int
+	TLS      bool
+	Timeout  time.Duration // timeout of connecting and of each command
+	PoolSize int           // idle connections kept open
+}
+
+// ResilienceConfig holds the policy of calls to external integrations, such as Slack, GitHub,
+// Stripe, attachment storage, and the mail server. Each integration has its own breaker.
+type ResilienceConfig struct {
+	MaxAttempts      int           // attempts of each call, the first included
+	RetryBackoff     time.Duration // delay before the first retry, doubled for each later one
+	MaxBackoff       time.Duration // longest delay between attempts
+	AttemptTimeout   time.Duration // timeout of each attempt, within the timeout of the integration
+	FailureThreshold int           // consecutive failures opening the breaker of an integration
+	OpenTimeout      time.Duration // how long an open breaker rejects calls before letting one through
+}
+
+// HealthConfig holds the configuration of the dependency checks of the health endpoint
+type HealthConfig struct {
+	Timeout time.Duration // timeout of each check
+	// Interval is how long the results of the checks are reused, so frequent polling by
+	// uptime monitors does not load the dependencies; zero checks on every request
+	Interval time.Duration
+}
+
 // AppConfig holds application configuration
 type AppConfig struct {
 	Environment string
-	LogLevel    string
+	LogLevel    string // debug, info, warn, or error
+	BaseURL     string // public URL of the application, used in links sent by email
+	// AccessLogSampleRate is the share of successful requests written to the access log,
+	// between 0 and 1; failed requests are always written
+	AccessLogSampleRate float64
 }
 
-// Load loads configuration from environment variables
+// LogLevels lists the supported log levels, most verbose first
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// StorageDrivers lists the supported storage drivers
+var StorageDrivers = []string{"memory"}
+
+// SecretsProviders lists the supported secrets providers
+var SecretsProviders = []string{"none", "vault", "aws"}
+
+// AttachmentStorages lists the supported attachment storages
+var AttachmentStorages = []string{"none", "s3", "gcs"}
+
+// AttachmentScanners lists the supported malware scanners of attachments
+var AttachmentScanners = []string{"none", "clamav", "icap"}
+
+// Bounds of the sizes of attachment thumbnails, in pixels
+const (
+	MinThumbnailSize = 16
+	MaxThumbnailSize = 2048
+)
+
+// CacheDrivers lists the supported cache drivers
+var CacheDrivers = []string{"none", "memory", "redis"}
+
+// EventStreamBrokers lists the supported event stream brokers
+var EventStreamBrokers = []string{"none", "nats", "kafka"}
+
+// WarehouseSinks lists the supported data warehouse sinks
+var WarehouseSinks = []string{"none", "bigquery", "redshift"}
+
+// AuthProviders lists the supported auth providers
+var AuthProviders = []string{"local", "ldap"}
+
+// MailProviders lists the supported mail providers
+var MailProviders = []string{"log", "smtp"}
+
+// telegramSecretPattern matches the secret tokens Telegram accepts for webhooks
+var telegramSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Load loads configuration. Each setting is read from the first of these sources that sets
+// it: the secrets provider, if configured; environment variables, including those of a .env
+// file; the configuration file; and the defaults.
 func Load() (*Config, error) {
+	return LoadWithOverrides(nil)
+}
+
+// LoadWithOverrides loads configuration like Load, with overrides taking precedence over
+// every other source. Overrides are keyed by environment variable name, e.g. SERVER_PORT,
+// and may set CONFIG_FILE.
+func LoadWithOverrides(overrides map[string]string) (*Config, error) {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
 		// It's okay if .env file doesn't exist in production
-		fmt.Println("No .env file found, using environment variables")
+		fmt.Fprintln(os.Stderr, "No .env file found, using environment variables")
+	}
+
+	l, err := newLoader(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file: %w", err)
 	}
 
 	config := &Config{}
 
+	// Secrets configuration. Secrets are fetched before any other setting is read, so
+	// they take precedence over the environment and the configuration file.
+	config.Secrets = SecretsConfig{
+		Provider:           l.getEnv("SECRETS_PROVIDER", "none"),
+		RefreshInterval:    l.getDurationEnv("SECRETS_REFRESH_INTERVAL", 0),
+		Timeout:            l.getDurationEnv("SECRETS_TIMEOUT", 10*time.Second),
+		VaultAddress:       l.getEnv("VAULT_ADDR", ""),
+		VaultToken:         l.getEnv("VAULT_TOKEN", ""),
+		VaultPath:          l.getEnv("SECRETS_VAULT_PATH", ""),
+		AWSRegion:          l.getEnv("AWS_REGION", ""),
+		AWSSecretID:        l.getEnv("SECRETS_AWS_SECRET_ID", ""),
+		AWSAccessKeyID:     l.getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey: l.getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		AWSSessionToken:    l.getEnv("AWS_SESSION_TOKEN", ""),
+		AWSEndpoint:        l.getEnv("SECRETS_AWS_ENDPOINT", ""),
+	}
+	// Invalid secrets settings are reported along with the other settings below
+	if len(l.errs) == 0 && config.Secrets.Validate() == nil {
+		config.provider = config.Secrets.NewProvider()
+	}
+	if config.provider != nil {
+		if err := l.fetchSecrets(config.provider, config.Secrets.Timeout); err != nil {
+			return nil, fmt.Errorf("failed to fetch secrets from %s: %w", config.provider.Name(), err)
+		}
+	}
+
 	// Server configuration
 	config.Server = ServerConfig{
-		Port:         getEnv("SERVER_PORT", "3000"),
-		Host:         getEnv("SERVER_HOST", "0.0.0.0"),
-		ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
-		WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
-		IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 120*time.Second),
+		Port:                 l.getEnv("SERVER_PORT", "3000"),
+		Host:                 l.getEnv("SERVER_HOST", "0.0.0.0"),
+		GRPCPort:             l.getEnv("GRPC_PORT", "50051"),
+		TenantBaseDomain:     l.getEnv("TENANT_BASE_DOMAIN", ""),
+		ReadTimeout:          l.getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
+		WriteTimeout:         l.getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
+		IdleTimeout:          l.getDurationEnv("SERVER_IDLE_TIMEOUT", 120*time.Second),
+		ShutdownTimeout:      l.getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+		RequestTimeout:       l.getDurationEnv("SERVER_REQUEST_TIMEOUT", 5*time.Second),
+		BulkRequestTimeout:   l.getDurationEnv("SERVER_BULK_REQUEST_TIMEOUT", 60*time.Second),
+		SlowRequestThreshold: l.getDurationEnv("SERVER_SLOW_REQUEST_THRESHOLD", time.Second),
+		MaxConnections:       l.getIntEnv("SERVER_MAX_CONNECTIONS", 256*1024),
+		ReadBufferSize:       l.getIntEnv("SERVER_READ_BUFFER_SIZE", 4096),
+		WriteBufferSize:      l.getIntEnv("SERVER_WRITE_BUFFER_SIZE", 4096),
+		PublicRateLimit:      l.getIntEnv("SERVER_PUBLIC_RATE_LIMIT", 60),
+	}
+
+	// TLS configuration
+	config.TLS = TLSConfig{
+		CertFile:         l.getEnv("TLS_CERT_FILE", ""),
+		KeyFile:          l.getEnv("TLS_KEY_FILE", ""),
+		AutocertDomains:  l.getListEnv("TLS_AUTOCERT_DOMAINS", ""),
+		AutocertEmail:    l.getEnv("TLS_AUTOCERT_EMAIL", ""),
+		AutocertCacheDir: l.getEnv("TLS_AUTOCERT_CACHE_DIR", "certs"),
+		RedirectPort:     l.getEnv("TLS_REDIRECT_PORT", ""),
+		ClientCAFile:     l.getEnv("TLS_CLIENT_CA_FILE", ""),
+		ClientAuthPaths:  l.getListEnv("TLS_CLIENT_AUTH_PATHS", ""),
+		ClientIdentities: l.getListEnv("TLS_CLIENT_IDENTITIES", ""),
 	}
 
 	// JWT configuration
 	config.JWT = JWTConfig{
-		SecretKey:       getEnv("JWT_SECRET_KEY", "todo-api-secret-key-change-in-production"),
-		AccessTokenTTL:  getDurationEnv("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
-		RefreshTokenTTL: getDurationEnv("JWT_REFRESH_TOKEN_TTL", 7*24*time.Hour),
-		Issuer:          getEnv("JWT_ISSUER", "todo-api"),
+		SecretKey:       l.getEnv("JWT_SECRET_KEY", DefaultJWTSecretKey),
+		AccessTokenTTL:  l.getDurationEnv("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: l.getDurationEnv("JWT_REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		Issuer:          l.getEnv("JWT_ISSUER", "todo-api"),
+	}
+
+	// Auth configuration
+	config.Auth = AuthConfig{
+		Provider: strings.ToLower(l.getEnv("AUTH_PROVIDER", "local")),
+	}
+
+	// LDAP configuration
+	config.LDAP = LDAPConfig{
+		URL:          l.getEnv("LDAP_URL", ""),
+		BindDN:       l.getEnv("LDAP_BIND_DN", ""),
+		BindPassword: l.getEnv("LDAP_BIND_PASSWORD", ""),
+		BaseDN:       l.getEnv("LDAP_BASE_DN", ""),
+		UserFilter:   l.getEnv("LDAP_USER_FILTER", "(mail={username})"),
+		StartTLS:     l.getBoolEnv("LDAP_START_TLS", false),
+		Timeout:      l.getDurationEnv("LDAP_TIMEOUT", 10*time.Second),
+	}
+
+	// SCIM configuration
+	config.SCIM = SCIMConfig{
+		Token: l.getEnv("SCIM_TOKEN", ""),
 	}
 
 	// App configuration
 	config.App = AppConfig{
-		Environment: getEnv("APP_ENV", "development"),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		Environment:         l.getEnv("APP_ENV", "development"),
+		LogLevel:            strings.ToLower(l.getEnv("LOG_LEVEL", "info")),
+		BaseURL:             strings.TrimSuffix(l.getEnv("APP_BASE_URL", "http://localhost:3000"), "/"),
+		AccessLogSampleRate: l.getFloatEnv("ACCESS_LOG_SAMPLE_RATE", 1),
+	}
+
+	// Storage configuration
+	config.Storage = StorageConfig{
+		Driver:   l.getEnv("STORAGE_DRIVER", "memory"),
+		SeedFile: l.getEnv("STORAGE_SEED_FILE", ""),
+		MaxTasks: l.getIntEnv("STORAGE_MAX_TASKS", 0),
+		MaxBytes: l.getIntEnv("STORAGE_MAX_BYTES", 0),
+	}
+
+	// Limits configuration
+	config.Limits = LimitsConfig{
+		MaxTasksPerUser: l.getIntEnv("LIMIT_MAX_TASKS_PER_USER", 0),
+		MaxBodySize:     l.getIntEnv("LIMIT_MAX_BODY_SIZE", 4*1024*1024),
+	}
+
+	// Search configuration
+	config.Search = SearchConfig{
+		Engine:                l.getEnv("SEARCH_ENGINE", "memory"),
+		ElasticsearchURL:      l.getEnv("SEARCH_ELASTICSEARCH_URL", "http://localhost:9200"),
+		ElasticsearchIndex:    l.getEnv("SEARCH_ELASTICSEARCH_INDEX", "tasks"),
+		ElasticsearchUsername: l.getEnv("SEARCH_ELASTICSEARCH_USERNAME", ""),
+		ElasticsearchPassword: l.getEnv("SEARCH_ELASTICSEARCH_PASSWORD", ""),
+		ElasticsearchTimeout:  l.getDurationEnv("SEARCH_ELASTICSEARCH_TIMEOUT", 5*time.Second),
+		ReconcileInterval:     l.getDurationEnv("SEARCH_RECONCILE_INTERVAL", time.Minute),
+	}
+
+	// CORS configuration. Development allows every origin, while other environments
+	// only allow the configured origins.
+	defaultOrigins := ""
+	defaultMaxAge := time.Hour
+	if config.IsDevelopment() {
+		defaultOrigins = "*"
+		defaultMaxAge = 0
+	}
+	config.CORS = CORSConfig{
+		AllowOrigins:     l.getListEnv("CORS_ALLOW_ORIGINS", defaultOrigins),
+		AllowHeaders:     l.getListEnv("CORS_ALLOW_HEADERS", "Origin, Content-Type, Accept, Authorization"),
+		AllowMethods:     l.getListEnv("CORS_ALLOW_METHODS", "GET, POST, PUT, PATCH, DELETE, OPTIONS"),
+		AllowCredentials: l.getBoolEnv("CORS_ALLOW_CREDENTIALS", false),
+		MaxAge:           l.getDurationEnv("CORS_MAX_AGE", defaultMaxAge),
+	}
+
+	// Login guard configuration
+	config.Login = LoginGuardConfig{
+		Window:             l.getDurationEnv("LOGIN_GUARD_WINDOW", 15*time.Minute),
+		MaxIPFailures:      l.getIntEnv("LOGIN_GUARD_MAX_IP_FAILURES", 20),
+		MaxAccountFailures: l.getIntEnv("LOGIN_GUARD_MAX_ACCOUNT_FAILURES", 5),
+		FlagDuration:       l.getDurationEnv("LOGIN_GUARD_FLAG_DURATION", 15*time.Minute),
+		ThrottleInterval:   l.getDurationEnv("LOGIN_GUARD_THROTTLE_INTERVAL", 30*time.Second),
+		MaxTravelSpeedKmh:  l.getFloatEnv("LOGIN_GUARD_MAX_TRAVEL_SPEED", 1000),
+	}
+
+	// Mail configuration. Development logs emails instead of sending them.
+	config.Mail = MailConfig{
+		Provider:        l.getEnv("MAIL_PROVIDER", "log"),
+		From:            l.getEnv("MAIL_FROM", "Todo API <no-reply@localhost>"),
+		Timeout:         l.getDurationEnv("MAIL_TIMEOUT", 10*time.Second),
+		SMTPHost:        l.getEnv("SMTP_HOST", ""),
+		SMTPPort:        l.getEnv("SMTP_PORT", "587"),
+		SMTPUsername:    l.getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:    l.getEnv("SMTP_PASSWORD", ""),
+		SMTPImplicitTLS: l.getBoolEnv("SMTP_IMPLICIT_TLS", false),
+	}
+
+	// Notifications configuration
+	config.Notify = NotificationsConfig{
+		ReminderLeadTime:   l.getDurationEnv("NOTIFY_REMINDER_LEAD_TIME", 24*time.Hour),
+		ReminderInterval:   l.getDurationEnv("NOTIFY_REMINDER_INTERVAL", 5*time.Minute),
+		DigestInterval:     l.getDurationEnv("NOTIFY_DIGEST_INTERVAL", 24*time.Hour),
+		DigestSchedule:     l.getEnv("NOTIFY_DIGEST_SCHEDULE", ""),
+		EscalationInterval: l.getDurationEnv("NOTIFY_ESCALATION_INTERVAL", 5*time.Minute),
+	}
+
+	// Account configuration
+	config.Account = AccountConfig{
+		PasswordResetTTL:     l.getDurationEnv("ACCOUNT_PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL: l.getDurationEnv("ACCOUNT_EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		ExportTTL:            l.getDurationEnv("ACCOUNT_EXPORT_TTL", 24*time.Hour),
+	}
+
+	// Slack configuration
+	config.Slack = SlackConfig{
+		SigningSecret: l.getEnv("SLACK_SIGNING_SECRET", ""),
+		APIURL:        l.getEnv("SLACK_API_URL", slack.DefaultAPIURL),
+		Timeout:       l.getDurationEnv("SLACK_TIMEOUT", 10*time.Second),
+	}
+
+	// Telegram configuration
+	config.Telegram = TelegramConfig{
+		WebhookSecret: l.getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+		BotUsername:   strings.TrimPrefix(l.getEnv("TELEGRAM_BOT_USERNAME", ""), "@"),
+		LinkTTL:       l.getDurationEnv("TELEGRAM_LINK_TTL", 10*time.Minute),
+	}
+
+	// Twilio configuration
+	config.Twilio = TwilioConfig{
+		AccountSID: l.getEnv("TWILIO_ACCOUNT_SID", ""),
+		AuthToken:  l.getEnv("TWILIO_AUTH_TOKEN", ""),
+		From:       l.getEnv("TWILIO_FROM", ""),
+		APIURL:     l.getEnv("TWILIO_API_URL", twilio.DefaultAPIURL),
+		Timeout:    l.getDurationEnv("TWILIO_TIMEOUT", 10*time.Second),
+		CodeTTL:    l.getDurationEnv("TWILIO_VERIFICATION_TTL", 10*time.Minute),
+	}
+
+	// Inbound email configuration
+	config.Inbound = InboundEmailConfig{
+		Domain:      strings.ToLower(l.getEnv("INBOUND_EMAIL_DOMAIN", "")),
+		Provider:    l.getEnv("INBOUND_EMAIL_PROVIDER", "mailgun"),
+		SigningKey:  l.getEnv("INBOUND_EMAIL_SIGNING_KEY", ""),
+		SESTopicARN: l.getEnv("INBOUND_EMAIL_SES_TOPIC_ARN", ""),
+		Timeout:     l.getDurationEnv("INBOUND_EMAIL_TIMEOUT", 10*time.Second),
+	}
+
+	// Billing configuration
+	config.Billing = BillingConfig{
+		SecretKey:           l.getEnv("STRIPE_SECRET_KEY", ""),
+		WebhookSecret:       l.getEnv("STRIPE_WEBHOOK_SECRET", ""),
+		ProPriceID:          l.getEnv("STRIPE_PRO_PRICE_ID", ""),
+		APIURL:              l.getEnv("STRIPE_API_URL", stripe.DefaultAPIURL),
+		Timeout:             l.getDurationEnv("STRIPE_TIMEOUT", 10*time.Second),
+		SuccessURL:          l.getEnv("BILLING_SUCCESS_URL", config.App.BaseURL+"/billing/success"),
+		CancelURL:           l.getEnv("BILLING_CANCEL_URL", config.App.BaseURL+"/billing"),
+		FreeMaxTasks:        l.getIntEnv("BILLING_FREE_MAX_TASKS", 100),
+		FreeMaxAttachments:  l.getIntEnv("BILLING_FREE_MAX_ATTACHMENTS", 20),
+		FreeMaxIntegrations: l.getIntEnv("BILLING_FREE_MAX_INTEGRATIONS", 1),
+	}
+
+	// Push notifications configuration
+	config.Push = PushConfig{
+		FCMCredentialsFile: l.getEnv("PUSH_FCM_CREDENTIALS_FILE", ""),
+		APNsKeyFile:        l.getEnv("PUSH_APNS_KEY_FILE", ""),
+		APNsKeyID:          l.getEnv("PUSH_APNS_KEY_ID", ""),
+		APNsTeamID:         l.getEnv("PUSH_APNS_TEAM_ID", ""),
+		APNsTopic:          l.getEnv("PUSH_APNS_TOPIC", ""),
+		APNsSandbox:        l.getBoolEnv("PUSH_APNS_SANDBOX", false),
+		Timeout:            l.getDurationEnv("PUSH_TIMEOUT", 10*time.Second),
+		MaxAttempts:        l.getIntEnv("PUSH_MAX_ATTEMPTS", 3),
+		RetryBackoff:       l.getDurationEnv("PUSH_RETRY_BACKOFF", time.Second),
+	}
+
+	// GitHub configuration
+	config.GitHub = GitHubConfig{
+		ClientID:         l.getEnv("GITHUB_CLIENT_ID", ""),
+		ClientSecret:     l.getEnv("GITHUB_CLIENT_SECRET", ""),
+		WebhookSecret:    l.getEnv("GITHUB_WEBHOOK_SECRET", ""),
+		APIURL:           l.getEnv("GITHUB_API_URL", github.DefaultAPIURL),
+		OAuthURL:         l.getEnv("GITHUB_OAUTH_URL", github.DefaultOAuthURL),
+		Timeout:          l.getDurationEnv("GITHUB_TIMEOUT", 10*time.Second),
+		SyncInterval:     l.getDurationEnv("GITHUB_SYNC_INTERVAL", 5*time.Minute),
+		AuthorizationTTL: l.getDurationEnv("GITHUB_AUTHORIZATION_TTL", 10*time.Minute),
+	}
+
+	// Google Calendar configuration
+	config.Calendar = GoogleCalendarConfig{
+		ClientID:         l.getEnv("GOOGLE_CALENDAR_CLIENT_ID", ""),
+		ClientSecret:     l.getEnv("GOOGLE_CALENDAR_CLIENT_SECRET", ""),
+		Timeout:          l.getDurationEnv("GOOGLE_CALENDAR_TIMEOUT", 10*time.Second),
+		SyncInterval:     l.getDurationEnv("GOOGLE_CALENDAR_SYNC_INTERVAL", 5*time.Minute),
+		AuthorizationTTL: l.getDurationEnv("GOOGLE_CALENDAR_AUTHORIZATION_TTL", 10*time.Minute),
+	}
+
+	// Attachments configuration
+	config.Files = AttachmentsConfig{
+		Storage:            l.getEnv("ATTACHMENT_STORAGE", "none"),
+		Bucket:             l.getEnv("ATTACHMENT_BUCKET", ""),
+		MaxSize:            l.getIntEnv("ATTACHMENT_MAX_SIZE", 100*1024*1024),
+		URLTTL:             l.getDurationEnv("ATTACHMENT_URL_TTL", 15*time.Minute),
+		Timeout:            l.getDurationEnv("ATTACHMENT_TIMEOUT", 10*time.Second),
+		S3Region:           l.getEnv("ATTACHMENT_S3_REGION", ""),
+		S3AccessKeyID:      l.getEnv("ATTACHMENT_S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey:  l.getEnv("ATTACHMENT_S3_SECRET_ACCESS_KEY", ""),
+		S3SessionToken:     l.getEnv("ATTACHMENT_S3_SESSION_TOKEN", ""),
+		S3Endpoint:         l.getEnv("ATTACHMENT_S3_ENDPOINT", ""),
+		S3PathStyle:        l.getBoolEnv("ATTACHMENT_S3_PATH_STYLE", false),
+		GCSCredentialsFile: l.getEnv("ATTACHMENT_GCS_CREDENTIALS_FILE", ""),
+		Scanner:            l.getEnv("ATTACHMENT_SCANNER", "none"),
+		ScannerAddress:     l.getEnv("ATTACHMENT_SCANNER_ADDRESS", ""),
+		ScannerService:     l.getEnv("ATTACHMENT_SCANNER_SERVICE", "avscan"),
+		ScannerTimeout:     l.getDurationEnv("ATTACHMENT_SCANNER_TIMEOUT", 30*time.Second),
+		ThumbnailSizes:     l.getIntListEnv("ATTACHMENT_THUMBNAIL_SIZES", "128,512"),
+	}
+
+	// Event stream configuration
+	config.Stream = EventStreamConfig{
+		Broker:      l.getEnv("EVENT_STREAM_BROKER", "none"),
+		Servers:     l.getListEnv("EVENT_STREAM_SERVERS", ""),
+		Topic:       l.getEnv("EVENT_STREAM_TOPIC", "todo.events"),
+		Username:    l.getEnv("EVENT_STREAM_USERNAME", ""),
+		Password:    l.getEnv("EVENT_STREAM_PASSWORD", ""),
+		TLS:         l.getBoolEnv("EVENT_STREAM_TLS", false),
+		Timeout:     l.getDurationEnv("EVENT_STREAM_TIMEOUT", 10*time.Second),
+		BatchSize:   l.getIntEnv("EVENT_STREAM_BATCH_SIZE", 100),
+		MaxBackoff:  l.getDurationEnv("EVENT_STREAM_MAX_BACKOFF", time.Minute),
+		OutboxLimit: l.getIntEnv("EVENT_STREAM_OUTBOX_LIMIT", 100000),
+	}
+
+	// Job queue configuration
+	config.Jobs = JobsConfig{
+		Workers:         l.getIntEnv("JOBS_WORKERS", 4),
+		MaxAttempts:     l.getIntEnv("JOBS_MAX_ATTEMPTS", 5),
+		RetryBackoff:    l.getDurationEnv("JOBS_RETRY_BACKOFF", time.Second),
+		MaxBackoff:      l.getDurationEnv("JOBS_MAX_BACKOFF", 5*time.Minute),
+		DeadLetterLimit: l.getIntEnv("JOBS_DEAD_LETTER_LIMIT", 1000),
+		Retention:       l.getDurationEnv("JOBS_RETENTION", 24*time.Hour),
+	}
+
+	// Reports configuration
+	config.Reports = ReportsConfig{
+		BurndownSchedule: l.getEnv("REPORTS_BURNDOWN_SCHEDULE", "10 0 * * *"),
+		WebhookSchedule:  l.getEnv("REPORTS_WEBHOOK_SCHEDULE", "30 0 * * *"),
+		WebhookTimeout:   l.getDurationEnv("REPORTS_WEBHOOK_TIMEOUT", 10*time.Second),
+	}
+
+	// Warehouse configuration
+	config.Warehouse = WarehouseConfig{
+		Sink:               l.getEnv("WAREHOUSE_SINK", "none"),
+		Events:             l.getBoolEnv("WAREHOUSE_EVENTS", true),
+		SnapshotSchedule:   l.getEnv("WAREHOUSE_SNAPSHOT_SCHEDULE", "0 2 * * *"),
+		Timeout:            l.getDurationEnv("WAREHOUSE_TIMEOUT", 30*time.Second),
+		BatchSize:          l.getIntEnv("WAREHOUSE_BATCH_SIZE", 500),
+		MaxBackoff:         l.getDurationEnv("WAREHOUSE_MAX_BACKOFF", 5*time.Minute),
+		OutboxLimit:        l.getIntEnv("WAREHOUSE_OUTBOX_LIMIT", 100000),
+		GCPCredentialsFile: l.getEnv("WAREHOUSE_GCP_CREDENTIALS_FILE", ""),
+		BigQueryProject:    l.getEnv("WAREHOUSE_BIGQUERY_PROJECT", ""),
+		BigQueryDataset:    l.getEnv("WAREHOUSE_BIGQUERY_DATASET", ""),
+		AWSRegion:          l.getEnv("WAREHOUSE_AWS_REGION", ""),
+		AWSAccessKeyID:     l.getEnv("WAREHOUSE_AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey: l.getEnv("WAREHOUSE_AWS_SECRET_ACCESS_KEY", ""),
+		AWSSessionToken:    l.getEnv("WAREHOUSE_AWS_SESSION_TOKEN", ""),
+		RedshiftCluster:    l.getEnv("WAREHOUSE_REDSHIFT_CLUSTER", ""),
+		RedshiftWorkgroup:  l.getEnv("WAREHOUSE_REDSHIFT_WORKGROUP", ""),
+		RedshiftDatabase:   l.getEnv("WAREHOUSE_REDSHIFT_DATABASE", ""),
+		RedshiftDBUser:     l.getEnv("WAREHOUSE_REDSHIFT_DB_USER", ""),
+		RedshiftSecretARN:  l.getEnv("WAREHOUSE_REDSHIFT_SECRET_ARN", ""),
+		RedshiftEndpoint:   l.getEnv("WAREHOUSE_REDSHIFT_ENDPOINT", ""),
+	}
+
+	// Cache configuration
+	config.Cache = CacheConfig{
+		Driver: l.getEnv("CACHE_DRIVER", "none"),
+		TTL:    l.getDurationEnv("CACHE_TTL", time.Minute),
+	}
+
+	// Redis configuration
+	config.Redis = RedisConfig{
+		Addr:     l.getEnv("REDIS_ADDR", "localhost:6379"),
+		Username: l.getEnv("REDIS_USERNAME", ""),
+		Password: l.getEnv("REDIS_PASSWORD", ""),
+		DB:       l.getIntEnv("REDIS_DB", 0),
+		TLS:      l.getBoolEnv("REDIS_TLS", false),
+		Timeout:  l.getDurationEnv("REDIS_TIMEOUT", time.Second),
+		PoolSize: l.getIntEnv("REDIS_POOL_SIZE", 10),
+	}
+
+	// Resilience configuration
+	config.Resilience = ResilienceConfig{
+		MaxAttempts:      l.getIntEnv("RESILIENCE_MAX_ATTEMPTS", 3),
+		RetryBackoff:     l.getDurationEnv("RESILIENCE_RETRY_BACKOFF", 200*time.Millisecond),
+		MaxBackoff:       l.getDurationEnv("RESILIENCE_MAX_BACKOFF", 5*time.Second),
+		AttemptTimeout:   l.getDurationEnv("RESILIENCE_ATTEMPT_TIMEOUT", 5*time.Second),
+		FailureThreshold: l.getIntEnv("RESILIENCE_FAILURE_THRESHOLD", 5),
+		OpenTimeout:      l.getDurationEnv("RESILIENCE_OPEN_TIMEOUT", 30*time.Second),
+	}
+
+	// Health configuration
+	config.Health = HealthConfig{
+		Timeout:  l.getDurationEnv("HEALTH_TIMEOUT", 2*time.Second),
+		Interval: l.getDurationEnv("HEALTH_INTERVAL", 5*time.Second),
+	}
+
+	// IP configuration
+	config.IP = IPConfig{
+		TrustedProxies: l.getPrefixListEnv("TRUSTED_PROXIES"),
+		Allow:          l.getPrefixListEnv("IP_ALLOWLIST"),
+		Deny:           l.getPrefixListEnv("IP_DENYLIST"),
+		AdminAllow:     l.getPrefixListEnv("ADMIN_IP_ALLOWLIST"),
+		AdminDeny:      l.getPrefixListEnv("ADMIN_IP_DENYLIST"),
+	}
+
+	if err := errors.Join(l.errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
 	}
 
 	return config, nil
 }
 
-// IsDevelopment checks if the application is running in development mode
-func (c *Config) IsDevelopment() bool {
-	return c.App.Environment == "development"
+// Validate checks the configuration for invalid or dangerous settings, reporting every
+// problem found
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	// Server
+	check(isPort(c.Server.Port), "SERVER_PORT: %q is not a port number", c.Server.Port)
+	check(isPort(c.Server.GRPCPort), "GRPC_PORT: %q is not a port number", c.Server.GRPCPort)
+	check(c.Server.ReadTimeout >= 0, "SERVER_READ_TIMEOUT: must not be negative")
+	check(c.Server.WriteTimeout >= 0, "SERVER_WRITE_TIMEOUT: must not be negative")
+	check(c.Server.IdleTimeout >= 0, "SERVER_IDLE_TIMEOUT: must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT: must be positive")
+	check(c.Server.RequestTimeout >= 0, "SERVER_REQUEST_TIMEOUT: must not be negative")
+	check(c.Server.BulkRequestTimeout >= 0, "SERVER_BULK_REQUEST_TIMEOUT: must not be negative")
+	check(c.Server.PublicRateLimit >= 0, "SERVER_PUBLIC_RATE_LIMIT: must not be negative")
+	check(c.Server.SlowRequestThreshold >= 0, "SERVER_SLOW_REQUEST_THRESHOLD: must not be negative")
+	check(c.Server.MaxConnections > 0, "SERVER_MAX_CONNECTIONS: must be positive")
+	check(c.Server.ReadBufferSize >= minBufferSize, "SERVER_READ_BUFFER_SIZE: must be at least %d bytes", minBufferSize)
+	check(c.Server.WriteBufferSize >= minBufferSize, "SERVER_WRITE_BUFFER_SIZE: must be at least %d bytes", minBufferSize)
+
+	// TLS
+	if err := c.TLS.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	check(c.TLS.RedirectPort != c.Server.Port, "TLS_REDIRECT_PORT: must differ from SERVER_PORT")
+
+	// JWT
+	if err := c.validateJWTSecretKey(c.JWT.SecretKey); err != nil {
+		errs = append(errs, err)
+	}
+	check(c.JWT.AccessTokenTTL > 0, "JWT_ACCESS_TOKEN_TTL: must be positive")
+	check(c.JWT.RefreshTokenTTL > 0, "JWT_REFRESH_TOKEN_TTL: must be positive")
+	check(c.JWT.RefreshTokenTTL >= c.JWT.AccessTokenTTL, "JWT_REFRESH_TOKEN_TTL: must not be shorter than JWT_ACCESS_TOKEN_TTL")
+
+	// Auth
+	check(slices.Contains(AuthProviders, c.Auth.Provider),
+		"AUTH_PROVIDER: %q is not one of %s", c.Auth.Provider, strings.Join(AuthProviders, ", "))
+	if c.Auth.Provider == "ldap" {
+		if err := c.LDAP.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	check(!c.SCIM.Enabled() || len(c.SCIM.Token) >= MinProductionSecretLength,
+		"SCIM_TOKEN: must be at least %d characters", MinProductionSecretLength)
+
+	// App and storage
+	check(slices.Contains(LogLevels, c.App.LogLevel),
+		"LOG_LEVEL: %q is not one of %s", c.App.LogLevel, strings.Join(LogLevels, ", "))
+	check(slices.Contains(StorageDrivers, c.Storage.Driver),
+		"STORAGE_DRIVER: %q is not supported, expected one of %s", c.Storage.Driver, strings.Join(StorageDrivers, ", "))
+	check(c.Storage.MaxTasks >= 0, "STORAGE_MAX_TASKS: must not be negative")
+	check(c.Storage.MaxBytes >= 0, "STORAGE_MAX_BYTES: must not be negative")
+	baseURL, err := url.Parse(c.App.BaseURL)
+	check(err == nil && (baseURL.Scheme == "http" || baseURL.Scheme == "https") && baseURL.Host != "",
+		"APP_BASE_URL: %q is not an http or https URL", c.App.BaseURL)
+	check(c.App.AccessLogSampleRate >= 0 && c.App.AccessLogSampleRate <= 1,
+		"ACCESS_LOG_SAMPLE_RATE: must be between 0 and 1")
+
+	// Limits
+	check(c.Limits.MaxTasksPerUser >= 0, "LIMIT_MAX_TASKS_PER_USER: must not be negative")
+	check(c.Limits.MaxBodySize > 0, "LIMIT_MAX_BODY_SIZE: must be positive")
+
+	// Search
+	switch c.Search.Engine {
+	case "memory":
+	case "elasticsearch", "opensearch":
+		u, err := url.Parse(c.Search.ElasticsearchURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"SEARCH_ELASTICSEARCH_URL: %q is not an http or https URL", c.Search.ElasticsearchURL)
+		check(c.Search.ElasticsearchIndex != "", "SEARCH_ELASTICSEARCH_INDEX: must be set")
+		check(c.Search.ElasticsearchTimeout > 0, "SEARCH_ELASTICSEARCH_TIMEOUT: must be positive")
+		check(c.Search.ReconcileInterval >= 0, "SEARCH_RECONCILE_INTERVAL: must not be negative")
+	default:
+		check(false, "SEARCH_ENGINE: %q is not one of memory, elasticsearch, opensearch", c.Search.Engine)
+	}
+
+	// Secrets
+	if err := c.Secrets.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Mail
+	if err := c.Mail.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	check(c.Notify.ReminderLeadTime > 0, "NOTIFY_REMINDER_LEAD_TIME: must be positive")
+	check(c.Notify.ReminderInterval >= 0, "NOTIFY_REMINDER_INTERVAL: must not be negative")
+	check(c.Notify.DigestInterval >= 0, "NOTIFY_DIGEST_INTERVAL: must not be negative")
+	check(c.Notify.EscalationInterval >= 0, "NOTIFY_ESCALATION_INTERVAL: must not be negative")
+	if c.Notify.DigestSchedule != "" {
+		if _, err := cron.Parse(c.Notify.DigestSchedule); err != nil {
+			errs = append(errs, fmt.Errorf("NOTIFY_DIGEST_SCHEDULE: %w", err))
+		}
+	}
+	check(c.Account.PasswordResetTTL > 0, "ACCOUNT_PASSWORD_RESET_TTL: must be positive")
+	check(c.Account.EmailVerificationTTL > 0, "ACCOUNT_EMAIL_VERIFICATION_TTL: must be positive")
+	// Archives are downloaded with presigned URLs valid until they expire
+	check(c.Account.ExportTTL > 0 && c.Account.ExportTTL <= blob.MaxURLTTL,
+		"ACCOUNT_EXPORT_TTL: must be positive and at most "+blob.MaxURLTTL.String())
+
+	// Slack
+	slackURL, err := url.Parse(c.Slack.APIURL)
+	check(err == nil && (slackURL.Scheme == "http" || slackURL.Scheme == "https") && slackURL.Host != "",
+		"SLACK_API_URL: %q is not an http or https URL", c.Slack.APIURL)
+	check(c.Slack.Timeout > 0, "SLACK_TIMEOUT: must be positive")
+
+	// Telegram
+	check(c.Telegram.WebhookSecret == "" || telegramSecretPattern.MatchString(c.Telegram.WebhookSecret),
+		"TELEGRAM_WEBHOOK_SECRET: must be 1 to 256 letters, digits, underscores, or hyphens")
+	check(c.Telegram.LinkTTL > 0, "TELEGRAM_LINK_TTL: must be positive")
+
+	// Twilio
+	twilioAccount := []string{c.Twilio.AccountSID, c.Twilio.AuthToken, c.Twilio.From}
+	check(!slices.Contains(twilioAccount, "") || !slices.ContainsFunc(twilioAccount, func(value string) bool { return value != "" }),
+		"TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN, TWILIO_FROM: must be set together")
+	twilioURL, err := url.Parse(c.Twilio.APIURL)
+	check(err == nil && (twilioURL.Scheme == "http" || twilioURL.Scheme == "https") && twilioURL.Host != "",
+		"TWILIO_API_URL: %q is not an http or https URL", c.Twilio.APIURL)
+	check(c.Twilio.Timeout > 0, "TWILIO_TIMEOUT: must be positive")
+	check(c.Twilio.CodeTTL > 0, "TWILIO_VERIFICATION_TTL: must be positive")
+
+	// Inbound email
+	check(c.Inbound.Domain == "" || (!strings.ContainsAny(c.Inbound.Domain, "@/: ") && strings.Contains(c.Inbound.Domain, ".")),
+		"INBOUND_EMAIL_DOMAIN: %q is not a domain name", c.Inbound.Domain)
+	check(c.Inbound.Provider == "mailgun" || c.Inbound.Provider == "ses",
+		"INBOUND_EMAIL_PROVIDER: must be mailgun or ses, got %q", c.Inbound.Provider)
+	check(c.Inbound.Domain == "" || c.Inbound.Provider != "mailgun" || c.Inbound.SigningKey != "",
+		"INBOUND_EMAIL_SIGNING_KEY: required when receiving emails through Mailgun")
+	check(c.Inbound.Domain == "" || c.Inbound.Provider != "ses" || strings.HasPrefix(c.Inbound.SESTopicARN, "arn:aws:sns:"),
+		"INBOUND_EMAIL_SES_TOPIC_ARN: must be the ARN of an SNS topic when receiving emails through SES")
+	check(c.Inbound.Timeout > 0, "INBOUND_EMAIL_TIMEOUT: must be positive")
+
+	// Billing
+	if err := c.Billing.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Push notifications
+	if err := c.Push.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// GitHub
+	check((c.GitHub.ClientID == "") == (c.GitHub.ClientSecret == ""),
+		"GITHUB_CLIENT_ID, GITHUB_CLIENT_SECRET: must be set together")
+	githubAPIURL, err := url.Parse(c.GitHub.APIURL)
+	check(err == nil && (githubAPIURL.Scheme == "http" || githubAPIURL.Scheme == "https") && githubAPIURL.Host != "",
+		"GITHUB_API_URL: %q is not an http or https URL", c.GitHub.APIURL)
+	githubOAuthURL, err := url.Parse(c.GitHub.OAuthURL)
+	check(err == nil && (githubOAuthURL.Scheme == "http" || githubOAuthURL.Scheme == "https") && githubOAuthURL.Host != "",
+		"GITHUB_OAUTH_URL: %q is not an http or https URL", c.GitHub.OAuthURL)
+	check(c.GitHub.Timeout > 0, "GITHUB_TIMEOUT: must be positive")
+	check(c.GitHub.SyncInterval >= 0, "GITHUB_SYNC_INTERVAL: must not be negative")
+	check(c.GitHub.AuthorizationTTL > 0, "GITHUB_AUTHORIZATION_TTL: must be positive")
+
+	// Google Calendar
+	check((c.Calendar.ClientID == "") == (c.Calendar.ClientSecret == ""),
+		"GOOGLE_CALENDAR_CLIENT_ID, GOOGLE_CALENDAR_CLIENT_SECRET: must be set together")
+	check(c.Calendar.Timeout > 0, "GOOGLE_CALENDAR_TIMEOUT: must be positive")
+	check(c.Calendar.SyncInterval >= 0, "GOOGLE_CALENDAR_SYNC_INTERVAL: must not be negative")
+	check(c.Calendar.AuthorizationTTL > 0, "GOOGLE_CALENDAR_AUTHORIZATION_TTL: must be positive")
+
+	// Attachments
+	if err := c.Files.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Event stream
+	if err := c.Stream.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Job queue
+	if err := c.Jobs.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Reports
+	if c.Reports.BurndownSchedule != "" {
+		if _, err := cron.Parse(c.Reports.BurndownSchedule); err != nil {
+			errs = append(errs, fmt.Errorf("REPORTS_BURNDOWN_SCHEDULE: %w", err))
+		}
+	}
+	if c.Reports.WebhookSchedule != "" {
+		if _, err := cron.Parse(c.Reports.WebhookSchedule); err != nil {
+			errs = append(errs, fmt.Errorf("REPORTS_WEBHOOK_SCHEDULE: %w", err))
+		}
+	}
+	check(c.Reports.WebhookTimeout > 0, "REPORTS_WEBHOOK_TIMEOUT: must be positive")
+
+	// Warehouse
+	if err := c.Warehouse.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Cache
+	if err := c.Cache.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Cache.Driver == "redis" {
+		if err := c.Redis.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Resilience
+	if err := c.Resilience.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Health
+	check(c.Health.Timeout > 0, "HEALTH_TIMEOUT: must be positive")
+	check(c.Health.Interval >= 0, "HEALTH_INTERVAL: must not be negative")
+
+	// CORS
+	if err := c.CORS.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("CORS: %w", err))
+	}
+
+	// Login guard
+	check(c.Login.MaxIPFailures >= 0, "LOGIN_GUARD_MAX_IP_FAILURES: must not be negative")
+	check(c.Login.MaxAccountFailures >= 0, "LOGIN_GUARD_MAX_ACCOUNT_FAILURES: must not be negative")
+	check(c.Login.MaxTravelSpeedKmh >= 0, "LOGIN_GUARD_MAX_TRAVEL_SPEED: must not be negative")
+	check(c.Login.ThrottleInterval >= 0, "LOGIN_GUARD_THROTTLE_INTERVAL: must not be negative")
+	if c.Login.MaxIPFailures > 0 || c.Login.MaxAccountFailures > 0 {
+		check(c.Login.Window > 0, "LOGIN_GUARD_WINDOW: must be positive when failures are limited")
+	}
+	check(c.Login.FlagDuration > 0, "LOGIN_GUARD_FLAG_DURATION: must be positive")
+
+	return errors.Join(errs...)
 }
 
-// IsProduction checks if the application is running in production mode
-func (c *Config) IsProduction() bool {
-	return c.App.Environment == "production"
+// Enabled reports whether the HTTP server terminates TLS
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || len(c.AutocertDomains) > 0
 }
 
-// Helper functions
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// ClientAuth reports whether clients authenticate with certificates
+func (c *TLSConfig) ClientAuth() bool {
+	return c.ClientCAFile != ""
 }
 
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+// Autocert reports whether certificates are obtained automatically
+func (c *TLSConfig) Autocert() bool {
+	return len(c.AutocertDomains) > 0
+}
+
+// Validate validates the TLS configuration, reporting every problem found
+func (c *TLSConfig) Validate() error {
+	var errs []error
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE, TLS_KEY_FILE: must be set together"))
+	}
+	if c.CertFile != "" && c.Autocert() {
+		errs = append(errs, errors.New("TLS_AUTOCERT_DOMAINS: cannot be combined with TLS_CERT_FILE"))
+	}
+	if c.Autocert() && c.AutocertCacheDir == "" {
+		errs = append(errs, errors.New("TLS_AUTOCERT_CACHE_DIR: must be set"))
+	}
+	if c.ClientAuth() && !c.Enabled() {
+		errs = append(errs, errors.New("TLS_CLIENT_CA_FILE: requires TLS to be enabled"))
+	}
+	if !c.ClientAuth() && (len(c.ClientAuthPaths) > 0 || len(c.ClientIdentities) > 0) {
+		errs = append(errs, errors.New("TLS_CLIENT_AUTH_PATHS, TLS_CLIENT_IDENTITIES: require TLS_CLIENT_CA_FILE"))
+	}
+	for _, path := range c.ClientAuthPaths {
+		if !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf("TLS_CLIENT_AUTH_PATHS: %q does not start with /", path))
 		}
 	}
-	return defaultValue
+	if c.RedirectPort != "" {
+		if !c.Enabled() {
+			errs = append(errs, errors.New("TLS_REDIRECT_PORT: requires TLS to be enabled"))
+		}
+		if !isPort(c.RedirectPort) {
+			errs = append(errs, fmt.Errorf("TLS_REDIRECT_PORT: %q is not a port number", c.RedirectPort))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
+// validateJWTSecretKey checks that the JWT secret is safe to use in the environment
+func (c *Config) validateJWTSecretKey(key string) error {
+	switch {
+	case key == "":
+		return errors.New("JWT_SECRET_KEY: must be set")
+	case c.IsProduction() && key == DefaultJWTSecretKey:
+		return errors.New("JWT_SECRET_KEY: the default secret must not be used in production")
+	case c.IsProduction() && len(key) < MinProductionSecretLength:
+		return fmt.Errorf("JWT_SECRET_KEY: must be at least %d characters in production", MinProductionSecretLength)
 	}
-	return defaultValue
+	return nil
 }
 
-func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
+// Validate validates the secrets configuration, reporting every problem found
+func (c *SecretsConfig) Validate() error {
+	var errs []error
+	required := func(key, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s: must be set when SECRETS_PROVIDER is %s", key, c.Provider))
 		}
 	}
+
+	switch c.Provider {
+	case "none":
+		return nil
+	case "vault":
+		u, err := url.Parse(c.VaultAddress)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("VAULT_ADDR: %q is not an http or https URL", c.VaultAddress))
+		}
+		required("VAULT_TOKEN", c.VaultToken)
+		required("SECRETS_VAULT_PATH", c.VaultPath)
+	case "aws":
+		required("AWS_REGION", c.AWSRegion)
+		required("SECRETS_AWS_SECRET_ID", c.AWSSecretID)
+		required("AWS_ACCESS_KEY_ID", c.AWSAccessKeyID)
+		required("AWS_SECRET_ACCESS_KEY", c.AWSSecretAccessKey)
+	default:
+		return fmt.Errorf("SECRETS_PROVIDER: %q is not one of %s", c.Provider, strings.Join(SecretsProviders, ", "))
+	}
+
+	if c.RefreshInterval < 0 {
+		errs = append(errs, errors.New("SECRETS_REFRESH_INTERVAL: must not be negative"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("SECRETS_TIMEOUT: must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// NewProvider creates the configured secrets provider, or returns nil when none is configured
+func (c *SecretsConfig) NewProvider() secrets.Provider {
+	switch c.Provider {
+	case "vault":
+		return secrets.NewVaultProvider(secrets.VaultConfig{
+			Address: c.VaultAddress,
+			Token:   c.VaultToken,
+			Path:    c.VaultPath,
+			Timeout: c.Timeout,
+		})
+	case "aws":
+		return secrets.NewAWSProvider(secrets.AWSConfig{
+			Region:          c.AWSRegion,
+			SecretID:        c.AWSSecretID,
+			AccessKeyID:     c.AWSAccessKeyID,
+			SecretAccessKey: c.AWSSecretAccessKey,
+			SessionToken:    c.AWSSessionToken,
+			Endpoint:        c.AWSEndpoint,
+			Timeout:         c.Timeout,
+		})
+	default:
+		return nil
+	}
+}
+
+// Validate validates the mail configuration, reporting every problem found
+func (c *MailConfig) Validate() error {
+	var errs []error
+	if !slices.Contains(MailProviders, c.Provider) {
+		errs = append(errs, fmt.Errorf("MAIL_PROVIDER: %q is not one of %s", c.Provider, strings.Join(MailProviders, ", ")))
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		errs = append(errs, fmt.Errorf("MAIL_FROM: %q is not an email address", c.From))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("MAIL_TIMEOUT: must be positive"))
+	}
+	if c.Provider == "smtp" {
+		if c.SMTPHost == "" {
+			errs = append(errs, errors.New("SMTP_HOST: must be set when MAIL_PROVIDER is smtp"))
+		}
+		if !isPort(c.SMTPPort) {
+			errs = append(errs, fmt.Errorf("SMTP_PORT: %q is not a port number", c.SMTPPort))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the LDAP configuration
+func (c *LDAPConfig) Validate() error {
+	var errs []error
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("LDAP_URL: %q is not an ldap or ldaps URL", c.URL))
+	} else if c.StartTLS && u.Scheme == "ldaps" {
+		errs = append(errs, errors.New("LDAP_START_TLS: must not be set with an ldaps URL"))
+	}
+	if (c.BindDN == "") != (c.BindPassword == "") {
+		errs = append(errs, errors.New("LDAP_BIND_DN, LDAP_BIND_PASSWORD: must be set together"))
+	}
+	if c.BaseDN == "" {
+		errs = append(errs, errors.New("LDAP_BASE_DN: must be set when AUTH_PROVIDER is ldap"))
+	}
+	if !strings.Contains(c.UserFilter, ldap.UsernamePlaceholder) {
+		errs = append(errs, fmt.Errorf("LDAP_USER_FILTER: must contain %s", ldap.UsernamePlaceholder))
+	} else if _, err := ldap.CompileFilter(strings.ReplaceAll(c.UserFilter, ldap.UsernamePlaceholder, "user")); err != nil {
+		errs = append(errs, fmt.Errorf("LDAP_USER_FILTER: %w", err))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("LDAP_TIMEOUT: must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Enabled reports whether identity providers may provision users over SCIM
+func (c *SCIMConfig) Enabled() bool {
+	return c.Token != ""
+}
+
+// NewClient creates the client of the directory
+func (c *LDAPConfig) NewClient() ldap.Client {
+	return ldap.NewClient(ldap.Config{
+		URL:          c.URL,
+		BindDN:       c.BindDN,
+		BindPassword: c.BindPassword,
+		BaseDN:       c.BaseDN,
+		UserFilter:   c.UserFilter,
+		StartTLS:     c.StartTLS,
+		Timeout:      c.Timeout,
+	})
+}
+
+// NewMailer creates the configured mailer
+func (c *MailConfig) NewMailer() mailer.Mailer {
+	if c.Provider != "smtp" {
+		return mailer.NewLogMailer()
+	}
+	return mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Host:        c.SMTPHost,
+		Port:        c.SMTPPort,
+		Username:    c.SMTPUsername,
+		Password:    c.SMTPPassword,
+		From:        c.From,
+		ImplicitTLS: c.SMTPImplicitTLS,
+		Timeout:     c.Timeout,
+	})
+}
+
+// NewClient creates the GitHub client of the OAuth app, sending requests through the
+// transport, http.DefaultTransport when nil
+func (c *GitHubConfig) NewClient(transport http.RoundTripper) github.Client {
+	return github.NewClient(github.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		APIURL:       c.APIURL,
+		OAuthURL:     c.OAuthURL,
+		Timeout:      c.Timeout,
+		Transport:    transport,
+	})
+}
+
+// NewClient creates the Google Calendar client of the OAuth client, sending requests through
+// the transport, http.DefaultTransport when nil
+func (c *GoogleCalendarConfig) NewClient(transport http.RoundTripper) gcal.Client {
+	return gcal.NewClient(gcal.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		Timeout:      c.Timeout,
+		Transport:    transport,
+	})
+}
+
+// Enabled reports whether SMS are sent through Twilio
+func (c *TwilioConfig) Enabled() bool {
+	return c.AccountSID != ""
+}
+
+// NewClient creates the Twilio client of the account, sending requests through the
+// transport, http.DefaultTransport when nil
+func (c *TwilioConfig) NewClient(transport http.RoundTripper) twilio.Client {
+	return twilio.NewClient(twilio.Config{
+		AccountSID: c.AccountSID,
+		AuthToken:  c.AuthToken,
+		APIURL:     c.APIURL,
+		Timeout:    c.Timeout,
+		Transport:  transport,
+	})
+}
+
+// Enabled reports whether users can create tasks by email
+func (c *InboundEmailConfig) Enabled() bool {
+	return c.Domain != ""
+}
+
+// Enabled reports whether subscriptions are paid through Stripe, so plan limits apply
+func (c *BillingConfig) Enabled() bool {
+	return c.SecretKey != ""
+}
+
+// NewClient creates the Stripe client of the account, sending requests through the
+// transport, http.DefaultTransport when nil
+func (c *BillingConfig) NewClient(transport http.RoundTripper) stripe.Client {
+	return stripe.NewClient(stripe.Config{
+		SecretKey: c.SecretKey,
+		APIURL:    c.APIURL,
+		Timeout:   c.Timeout,
+		Transport: transport,
+	})
+}
+
+// Validate validates the billing configuration, reporting every problem found
+func (c *BillingConfig) Validate() error {
+	var errs []error
+	account := []string{c.SecretKey, c.WebhookSecret, c.ProPriceID}
+	if slices.Contains(account, "") && slices.ContainsFunc(account, func(value string) bool { return value != "" }) {
+		errs = append(errs, errors.New("STRIPE_SECRET_KEY, STRIPE_WEBHOOK_SECRET, STRIPE_PRO_PRICE_ID: must be set together"))
+	}
+	for _, setting := range []struct{ name, value string }{
+		{"STRIPE_API_URL", c.APIURL},
+		{"BILLING_SUCCESS_URL", c.SuccessURL},
+		{"BILLING_CANCEL_URL", c.CancelURL},
+	} {
+		if u, err := url.Parse(setting.value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: %q is not an http or https URL", setting.name, setting.value))
+		}
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("STRIPE_TIMEOUT: must be positive"))
+	}
+	if c.FreeMaxTasks < 0 || c.FreeMaxAttachments < 0 || c.FreeMaxIntegrations < 0 {
+		errs = append(errs, errors.New("BILLING_FREE_MAX_TASKS, BILLING_FREE_MAX_ATTACHMENTS, BILLING_FREE_MAX_INTEGRATIONS: must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// APNsEnabled reports whether notifications are sent to iOS devices through APNs
+func (c *PushConfig) APNsEnabled() bool {
+	return c.APNsKeyFile != ""
+}
+
+// Validate validates the push notifications configuration, reporting every problem found
+func (c *PushConfig) Validate() error {
+	var errs []error
+	apns := []string{c.APNsKeyFile, c.APNsKeyID, c.APNsTeamID, c.APNsTopic}
+	if slices.Contains(apns, "") && slices.ContainsFunc(apns, func(value string) bool { return value != "" }) {
+		errs = append(errs, errors.New("PUSH_APNS_KEY_FILE, PUSH_APNS_KEY_ID, PUSH_APNS_TEAM_ID, PUSH_APNS_TOPIC: must be set together"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("PUSH_TIMEOUT: must be positive"))
+	}
+	if c.MaxAttempts < 1 {
+		errs = append(errs, errors.New("PUSH_MAX_ATTEMPTS: must be at least 1"))
+	}
+	if c.RetryBackoff < 0 {
+		errs = append(errs, errors.New("PUSH_RETRY_BACKOFF: must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// NewSenders creates the push senders of Android and iOS devices, reading their credentials.
+// Failed sends are retried as configured.
+func (c *PushConfig) NewSenders() (android, ios push.Sender, err error) {
+	android = push.NewLogSender("android")
+	if c.FCMCredentialsFile != "" {
+		credentials, err := os.ReadFile(c.FCMCredentialsFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("PUSH_FCM_CREDENTIALS_FILE: %w", err)
+		}
+		if android, err = push.NewFCMSender(push.FCMConfig{Credentials: credentials, Timeout: c.Timeout}); err != nil {
+			return nil, nil, fmt.Errorf("PUSH_FCM_CREDENTIALS_FILE: %w", err)
+		}
+	}
+
+	ios = push.NewLogSender("ios")
+	if c.APNsEnabled() {
+		key, err := os.ReadFile(c.APNsKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("PUSH_APNS_KEY_FILE: %w", err)
+		}
+		endpoint := push.APNsProductionEndpoint
+		if c.APNsSandbox {
+			endpoint = push.APNsSandboxEndpoint
+		}
+		ios, err = push.NewAPNsSender(push.APNsConfig{
+			Key:      key,
+			KeyID:    c.APNsKeyID,
+			TeamID:   c.APNsTeamID,
+			Topic:    c.APNsTopic,
+			Endpoint: endpoint,
+			Timeout:  c.Timeout,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("PUSH_APNS_KEY_FILE: %w", err)
+		}
+	}
+
+	return push.NewRetrySender(android, c.MaxAttempts, c.RetryBackoff),
+		push.NewRetrySender(ios, c.MaxAttempts, c.RetryBackoff), nil
+}
+
+// Enabled reports whether tasks can have attachments
+func (c *AttachmentsConfig) Enabled() bool {
+	return c.Storage != "none"
+}
+
+// Validate validates the attachments configuration, reporting every problem found
+func (c *AttachmentsConfig) Validate() error {
+	var errs []error
+	required := func(key, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s: must be set when ATTACHMENT_STORAGE is %s", key, c.Storage))
+		}
+	}
+
+	switch c.Storage {
+	case "none":
+		return nil
+	case "s3":
+		required("ATTACHMENT_S3_REGION", c.S3Region)
+		required("ATTACHMENT_S3_ACCESS_KEY_ID", c.S3AccessKeyID)
+		required("ATTACHMENT_S3_SECRET_ACCESS_KEY", c.S3SecretAccessKey)
+		if c.S3Endpoint != "" {
+			u, err := url.Parse(c.S3Endpoint)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("ATTACHMENT_S3_ENDPOINT: %q is not an http or https URL", c.S3Endpoint))
+			}
+		}
+	case "gcs":
+		required("ATTACHMENT_GCS_CREDENTIALS_FILE", c.GCSCredentialsFile)
+	default:
+		return fmt.Errorf("ATTACHMENT_STORAGE: %q is not one of %s", c.Storage, strings.Join(AttachmentStorages, ", "))
+	}
+
+	required("ATTACHMENT_BUCKET", c.Bucket)
+	if c.MaxSize <= 0 {
+		errs = append(errs, errors.New("ATTACHMENT_MAX_SIZE: must be positive"))
+	}
+	if c.URLTTL <= 0 || c.URLTTL > blob.MaxURLTTL {
+		errs = append(errs, fmt.Errorf("ATTACHMENT_URL_TTL: must be positive and at most %s", blob.MaxURLTTL))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("ATTACHMENT_TIMEOUT: must be positive"))
+	}
+
+	for i, size := range c.ThumbnailSizes {
+		if size < MinThumbnailSize || size > MaxThumbnailSize {
+			errs = append(errs, fmt.Errorf("ATTACHMENT_THUMBNAIL_SIZES: %d is not between %d and %d", size, MinThumbnailSize, MaxThumbnailSize))
+		} else if slices.Contains(c.ThumbnailSizes[:i], size) {
+			errs = append(errs, fmt.Errorf("ATTACHMENT_THUMBNAIL_SIZES: %d is listed twice", size))
+		}
+	}
+
+	switch c.Scanner {
+	case "none":
+	case "clamav", "icap":
+		if c.ScannerAddress == "" {
+			errs = append(errs, fmt.Errorf("ATTACHMENT_SCANNER_ADDRESS: must be set when ATTACHMENT_SCANNER is %s", c.Scanner))
+		}
+		if c.Scanner == "icap" && c.ScannerService == "" {
+			errs = append(errs, errors.New("ATTACHMENT_SCANNER_SERVICE: must be set when ATTACHMENT_SCANNER is icap"))
+		}
+		if c.ScannerTimeout <= 0 {
+			errs = append(errs, errors.New("ATTACHMENT_SCANNER_TIMEOUT: must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("ATTACHMENT_SCANNER: %q is not one of %s", c.Scanner, strings.Join(AttachmentScanners, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// NewScanner creates the scanner of uploaded attachments, or returns nil without one
+func (c *AttachmentsConfig) NewScanner() scan.Scanner {
+	switch c.Scanner {
+	case "clamav":
+		return scan.NewClamAV(scan.ClamAVConfig{Address: c.ScannerAddress, Timeout: c.ScannerTimeout})
+	case "icap":
+		return scan.NewICAP(scan.ICAPConfig{Address: c.ScannerAddress, Service: c.ScannerService, Timeout: c.ScannerTimeout})
+	default:
+		return nil
+	}
+}
+
+// NewStore creates the configured attachment storage, reading its credentials, or returns
+// nil when attachments are disabled. Requests are sent through the transport,
+// http.DefaultTransport when nil.
+func (c *AttachmentsConfig) NewStore(transport http.RoundTripper) (blob.Store, error) {
+	switch c.Storage {
+	case "s3":
+		return blob.NewS3Store(blob.S3Config{
+			Bucket:          c.Bucket,
+			Region:          c.S3Region,
+			AccessKeyID:     c.S3AccessKeyID,
+			SecretAccessKey: c.S3SecretAccessKey,
+			SessionToken:    c.S3SessionToken,
+			Endpoint:        c.S3Endpoint,
+			PathStyle:       c.S3PathStyle,
+			Timeout:         c.Timeout,
+			Transport:       transport,
+		})
+	case "gcs":
+		credentials, err := os.ReadFile(c.GCSCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("ATTACHMENT_GCS_CREDENTIALS_FILE: %w", err)
+		}
+		store, err := blob.NewGCSStore(blob.GCSConfig{
+			Bucket:      c.Bucket,
+			Credentials: credentials,
+			Timeout:     c.Timeout,
+			Transport:   transport,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ATTACHMENT_GCS_CREDENTIALS_FILE: %w", err)
+		}
+		return store, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Enabled reports whether events are streamed to a broker
+func (c *EventStreamConfig) Enabled() bool {
+	return c.Broker != "none"
+}
+
+// Validate validates the event stream configuration, reporting every problem found
+func (c *EventStreamConfig) Validate() error {
+	if !slices.Contains(EventStreamBrokers, c.Broker) {
+		return fmt.Errorf("EVENT_STREAM_BROKER: %q is not one of %s", c.Broker, strings.Join(EventStreamBrokers, ", "))
+	}
+	if !c.Enabled() {
+		return nil
+	}
+
+	var errs []error
+	if len(c.Servers) == 0 {
+		errs = append(errs, fmt.Errorf("EVENT_STREAM_SERVERS: must be set when EVENT_STREAM_BROKER is %s", c.Broker))
+	}
+	for _, server := range c.Servers {
+		if _, port, err := net.SplitHostPort(server); err != nil || port == "" {
+			errs = append(errs, fmt.Errorf("EVENT_STREAM_SERVERS: %q is not a host:port address", server))
+		}
+	}
+	if c.Topic == "" || strings.ContainsAny(c.Topic, " \t\r\n*>") {
+		errs = append(errs, errors.New("EVENT_STREAM_TOPIC: must be set, without spaces or wildcards"))
+	}
+	if c.Password != "" && c.Username == "" {
+		errs = append(errs, errors.New("EVENT_STREAM_USERNAME: must be set when EVENT_STREAM_PASSWORD is"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("EVENT_STREAM_TIMEOUT: must be positive"))
+	}
+	if c.BatchSize < 1 {
+		errs = append(errs, errors.New("EVENT_STREAM_BATCH_SIZE: must be at least 1"))
+	}
+	if c.MaxBackoff <= 0 {
+		errs = append(errs, errors.New("EVENT_STREAM_MAX_BACKOFF: must be positive"))
+	}
+	if c.OutboxLimit < c.BatchSize {
+		errs = append(errs, errors.New("EVENT_STREAM_OUTBOX_LIMIT: must be at least EVENT_STREAM_BATCH_SIZE"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the job queue configuration, reporting every problem found
+func (c *JobsConfig) Validate() error {
+	var errs []error
+	if c.Workers < 1 {
+		errs = append(errs, errors.New("JOBS_WORKERS: must be at least 1"))
+	}
+	if c.MaxAttempts < 1 {
+		errs = append(errs, errors.New("JOBS_MAX_ATTEMPTS: must be at least 1"))
+	}
+	if c.RetryBackoff <= 0 {
+		errs = append(errs, errors.New("JOBS_RETRY_BACKOFF: must be positive"))
+	}
+	if c.MaxBackoff < c.RetryBackoff {
+		errs = append(errs, errors.New("JOBS_MAX_BACKOFF: must be at least JOBS_RETRY_BACKOFF"))
+	}
+	if c.DeadLetterLimit < 1 {
+		errs = append(errs, errors.New("JOBS_DEAD_LETTER_LIMIT: must be at least 1"))
+	}
+	if c.Retention <= 0 {
+		errs = append(errs, errors.New("JOBS_RETENTION: must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the cache configuration, reporting every problem found
+func (c *CacheConfig) Validate() error {
+	var errs []error
+	if !slices.Contains(CacheDrivers, c.Driver) {
+		errs = append(errs, fmt.Errorf("CACHE_DRIVER: %q is not one of %s", c.Driver, strings.Join(CacheDrivers, ", ")))
+	}
+	if c.TTL <= 0 {
+		errs = append(errs, errors.New("CACHE_TTL: must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the Redis configuration, reporting every problem found
+func (c *RedisConfig) Validate() error {
+	var errs []error
+	if _, port, err := net.SplitHostPort(c.Addr); err != nil || port == "" {
+		errs = append(errs, fmt.Errorf("REDIS_ADDR: %q is not a host:port address", c.Addr))
+	}
+	if c.Username != "" && c.Password == "" {
+		errs = append(errs, errors.New("REDIS_PASSWORD: must be set when REDIS_USERNAME is"))
+	}
+	if c.DB < 0 {
+		errs = append(errs, errors.New("REDIS_DB: must not be negative"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("REDIS_TIMEOUT: must be positive"))
+	}
+	if c.PoolSize < 1 {
+		errs = append(errs, errors.New("REDIS_POOL_SIZE: must be at least 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// NewClient creates a client of the Redis server
+func (c *RedisConfig) NewClient() *redis.Client {
+	return redis.NewClient(redis.Config{
+		Addr:     c.Addr,
+		Username: c.Username,
+		Password: c.Password,
+		DB:       c.DB,
+		TLS:      c.TLS,
+		Timeout:  c.Timeout,
+		PoolSize: c.PoolSize,
+	})
+}
+
+// Validate validates the resilience configuration, reporting every problem found
+func (c *ResilienceConfig) Validate() error {
+	var errs []error
+	if c.MaxAttempts < 1 {
+		errs = append(errs, errors.New("RESILIENCE_MAX_ATTEMPTS: must be at least 1"))
+	}
+	if c.RetryBackoff < 0 {
+		errs = append(errs, errors.New("RESILIENCE_RETRY_BACKOFF: must not be negative"))
+	}
+	if c.MaxBackoff < c.RetryBackoff {
+		errs = append(errs, errors.New("RESILIENCE_MAX_BACKOFF: must be at least RESILIENCE_RETRY_BACKOFF"))
+	}
+	if c.AttemptTimeout < 0 {
+		errs = append(errs, errors.New("RESILIENCE_ATTEMPT_TIMEOUT: must not be negative"))
+	}
+	if c.FailureThreshold < 1 {
+		errs = append(errs, errors.New("RESILIENCE_FAILURE_THRESHOLD: must be at least 1"))
+	}
+	if c.OpenTimeout <= 0 {
+		errs = append(errs, errors.New("RESILIENCE_OPEN_TIMEOUT: must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Policy returns the policy of calls to external integrations
+func (c *ResilienceConfig) Policy() resilience.Policy {
+	return resilience.Policy{
+		MaxAttempts:      c.MaxAttempts,
+		RetryBackoff:     c.RetryBackoff,
+		MaxBackoff:       c.MaxBackoff,
+		AttemptTimeout:   c.AttemptTimeout,
+		FailureThreshold: c.FailureThreshold,
+		OpenTimeout:      c.OpenTimeout,
+	}
+}
+
+// Enabled reports whether data is exported to a warehouse
+func (c *WarehouseConfig) Enabled() bool {
+	return c.Sink != "none"
+}
+
+// Validate validates the warehouse configuration, reporting every problem found
+func (c *WarehouseConfig) Validate() error {
+	var errs []error
+	required := func(key, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s: must be set when WAREHOUSE_SINK is %s", key, c.Sink))
+		}
+	}
+
+	switch c.Sink {
+	case "none":
+		return nil
+	case "bigquery":
+		required("WAREHOUSE_GCP_CREDENTIALS_FILE", c.GCPCredentialsFile)
+		if !warehouse.ValidIdentifier(c.BigQueryDataset) {
+			errs = append(errs, errors.New("WAREHOUSE_BIGQUERY_DATASET: must be set to letters, digits, and underscores"))
+		}
+	case "redshift":
+		required("WAREHOUSE_AWS_REGION", c.AWSRegion)
+		required("WAREHOUSE_AWS_ACCESS_KEY_ID", c.AWSAccessKeyID)
+		required("WAREHOUSE_AWS_SECRET_ACCESS_KEY", c.AWSSecretAccessKey)
+		required("WAREHOUSE_REDSHIFT_DATABASE", c.RedshiftDatabase)
+		if (c.RedshiftCluster == "") == (c.RedshiftWorkgroup == "") {
+			errs = append(errs, errors.New("WAREHOUSE_REDSHIFT_CLUSTER, WAREHOUSE_REDSHIFT_WORKGROUP: exactly one must be set"))
+		}
+		if c.RedshiftCluster != "" && c.RedshiftDBUser == "" && c.RedshiftSecretARN == "" {
+			errs = append(errs, errors.New("WAREHOUSE_REDSHIFT_DB_USER, WAREHOUSE_REDSHIFT_SECRET_ARN: one must be set for a cluster"))
+		}
+		if c.RedshiftSecretARN != "" && !strings.HasPrefix(c.RedshiftSecretARN, "arn:aws:secretsmanager:") {
+			errs = append(errs, errors.New("WAREHOUSE_REDSHIFT_SECRET_ARN: must be the ARN of a Secrets Manager secret"))
+		}
+		if c.RedshiftEndpoint != "" {
+			u, err := url.Parse(c.RedshiftEndpoint)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("WAREHOUSE_REDSHIFT_ENDPOINT: %q is not an http or https URL", c.RedshiftEndpoint))
+			}
+		}
+	default:
+		return fmt.Errorf("WAREHOUSE_SINK: %q is not one of %s", c.Sink, strings.Join(WarehouseSinks, ", "))
+	}
+
+	if !c.Events && c.SnapshotSchedule == "" {
+		errs = append(errs, errors.New("WAREHOUSE_EVENTS, WAREHOUSE_SNAPSHOT_SCHEDULE: nothing is exported without events or a schedule"))
+	}
+	if c.SnapshotSchedule != "" {
+		if _, err := cron.Parse(c.SnapshotSchedule); err != nil {
+			errs = append(errs, fmt.Errorf("WAREHOUSE_SNAPSHOT_SCHEDULE: %w", err))
+		}
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("WAREHOUSE_TIMEOUT: must be positive"))
+	}
+	if c.BatchSize < 1 {
+		errs = append(errs, errors.New("WAREHOUSE_BATCH_SIZE: must be at least 1"))
+	}
+	if c.MaxBackoff <= 0 {
+		errs = append(errs, errors.New("WAREHOUSE_MAX_BACKOFF: must be positive"))
+	}
+	if c.Events && c.OutboxLimit < c.BatchSize {
+		errs = append(errs, errors.New("WAREHOUSE_OUTBOX_LIMIT: must be at least WAREHOUSE_BATCH_SIZE"))
+	}
+	return errors.Join(errs...)
+}
+
+// NewSink creates the configured warehouse sink, reading its credentials, or returns nil
+// when nothing is exported
+func (c *WarehouseConfig) NewSink() (warehouse.Sink, error) {
+	switch c.Sink {
+	case "bigquery":
+		credentials, err := os.ReadFile(c.GCPCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("WAREHOUSE_GCP_CREDENTIALS_FILE: %w", err)
+		}
+		sink, err := warehouse.NewBigQuerySink(warehouse.BigQueryConfig{
+			Credentials: credentials,
+			ProjectID:   c.BigQueryProject,
+			Dataset:     c.BigQueryDataset,
+			Timeout:     c.Timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("WAREHOUSE_GCP_CREDENTIALS_FILE: %w", err)
+		}
+		return sink, nil
+	case "redshift":
+		return warehouse.NewRedshiftSink(warehouse.RedshiftConfig{
+			Region:          c.AWSRegion,
+			ClusterID:       c.RedshiftCluster,
+			Workgroup:       c.RedshiftWorkgroup,
+			Database:        c.RedshiftDatabase,
+			DBUser:          c.RedshiftDBUser,
+			SecretARN:       c.RedshiftSecretARN,
+			AccessKeyID:     c.AWSAccessKeyID,
+			SecretAccessKey: c.AWSSecretAccessKey,
+			SessionToken:    c.AWSSessionToken,
+			Endpoint:        c.RedshiftEndpoint,
+			Timeout:         c.Timeout,
+		}), nil
+	default:
+		return nil, nil
+	}
+}
+
+// NewPublisher creates the publisher of the configured broker, or returns nil when events
+// are not streamed
+func (c *EventStreamConfig) NewPublisher() stream.Publisher {
+	cfg := stream.Config{
+		Servers:  c.Servers,
+		Topic:    c.Topic,
+		Username: c.Username,
+		Password: c.Password,
+		TLS:      c.TLS,
+		Timeout:  c.Timeout,
+	}
+	switch c.Broker {
+	case "nats":
+		return stream.NewNATSPublisher(cfg)
+	case "kafka":
+		return stream.NewKafkaProducer(cfg)
+	default:
+		return nil
+	}
+}
+
+// JWTSecretKey returns the current JWT secret, which signs tokens and changes when the
+// secrets provider rotates it
+func (c *Config) JWTSecretKey() string {
+	if keys := c.jwtKeys.Load(); keys != nil {
+		return keys.current
+	}
+	return c.JWT.SecretKey
+}
+
+// JWTVerificationKeys returns the JWT secrets tokens are verified with: the current one and,
+// for one refresh token TTL after a rotation, the previous one, so tokens issued before the
+// rotation stay valid until they expire
+func (c *Config) JWTVerificationKeys() []string {
+	return c.jwtVerificationKeys(time.Now())
+}
+
+func (c *Config) jwtVerificationKeys(now time.Time) []string {
+	keys := c.jwtKeys.Load()
+	if keys == nil {
+		return []string{c.JWT.SecretKey}
+	}
+	if now.Before(keys.previousUntil) {
+		return []string{keys.current, keys.previous}
+	}
+	return []string{keys.current}
+}
+
+// rotateJWTSecret makes the key the current JWT secret, keeping the previous one for
+// verification for one refresh token TTL
+func (c *Config) rotateJWTSecret(key string, now time.Time) {
+	c.jwtKeys.Store(&jwtKeys{
+		current:       key,
+		previous:      c.JWTSecretKey(),
+		previousUntil: now.Add(c.JWT.RefreshTokenTTL),
+	})
+}
+
+// WatchSecrets fetches the secrets again every refresh interval until the context is done.
+// A rotated JWT secret signs tokens immediately, while tokens signed with the previous one
+// stay valid for one refresh token TTL; other secrets only take effect on restart.
+func (c *Config) WatchSecrets(ctx context.Context) {
+	if c.provider == nil || c.Secrets.RefreshInterval <= 0 {
+		return
+	}
+
+	secrets.Watch(ctx, c.provider, c.Secrets.RefreshInterval, func(values map[string]string) {
+		key, ok := values["JWT_SECRET_KEY"]
+		if !ok || key == c.JWTSecretKey() {
+			return
+		}
+		if err := c.validateJWTSecretKey(key); err != nil {
+			log.Printf("Ignoring JWT secret from %s: %v", c.provider.Name(), err)
+			return
+		}
+		c.rotateJWTSecret(key, time.Now())
+		log.Printf("Rotated JWT secret from %s", c.provider.Name())
+	})
+}
+
+// isPort reports whether the value is a TCP port number
+func isPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port <= 65535
+}
+
+// corsMethods lists the methods that can be allowed for cross-origin requests
+var corsMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Validate validates the CORS configuration
+func (c *CORSConfig) Validate() error {
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			if len(c.AllowOrigins) > 1 {
+				return errors.New("wildcard origin cannot be combined with other origins")
+			}
+			if c.AllowCredentials {
+				return errors.New("credentials cannot be allowed for the wildcard origin")
+			}
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			return fmt.Errorf("invalid origin %q: expected scheme://host[:port]", origin)
+		}
+	}
+
+	for _, method := range c.AllowMethods {
+		if !slices.Contains(corsMethods, strings.ToUpper(method)) {
+			return fmt.Errorf("unsupported method %q", method)
+		}
+	}
+
+	if c.MaxAge < 0 {
+		return errors.New("max age must not be negative")
+	}
+
+	return nil
+}
+
+// IsDevelopment checks if the application is running in development mode
+func (c *Config) IsDevelopment() bool {
+	return c.App.Environment == "development"
+}
+
+// IsProduction checks if the application is running in production mode
+func (c *Config) IsProduction() bool {
+	return c.App.Environment == "production"
+}
+
+// loader reads settings by environment variable name from the configuration sources
+type loader struct {
+	overrides map[string]string // values taking precedence over every source, e.g. flags
+	secrets   map[string]string // values of the secrets provider, if any
+	file      map[string]string // values of the configuration file, if any
+	errs      []error           // values that could not be parsed
+}
+
+// newLoader creates a loader reading the configuration file, if any
+func newLoader(overrides map[string]string) (*loader, error) {
+	l := &loader{overrides: overrides}
+
+	path, err := findConfigFile(l.lookup("CONFIG_FILE"))
+	if err != nil || path == "" {
+		return l, err
+	}
+
+	l.file, err = loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(os.Stderr, "Loaded configuration file", path)
+
+	return l, nil
+}
+
+// fetchSecrets reads the secrets of the provider. Unknown setting names are rejected so
+// typos do not go unnoticed.
+func (l *loader) fetchSecrets(provider secrets.Provider, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	values, err := provider.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for key := range values {
+		if !isSetting(key) {
+			errs = append(errs, fmt.Errorf("unknown setting %q", key))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	l.secrets = values
+	return nil
+}
+
+// lookup returns the value of the setting from the source with the highest precedence
+func (l *loader) lookup(key string) string {
+	if value := l.overrides[key]; value != "" {
+		return value
+	}
+	if value := l.secrets[key]; value != "" {
+		return value
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return l.file[key]
+}
+
+// Helper functions
+func (l *loader) getEnv(key, defaultValue string) string {
+	if value := l.lookup(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (l *loader) getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value := l.lookup(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+		l.invalid(key, value, "a duration such as 30s or 15m")
+	}
 	return defaultValue
 }
+
+func (l *loader) getIntEnv(key string, defaultValue int) int {
+	if value := l.lookup(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+		l.invalid(key, value, "an integer")
+	}
+	return defaultValue
+}
+
+// getListEnv returns the comma-separated values of the variable, without blanks
+func (l *loader) getListEnv(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(l.getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getIntListEnv returns the comma-separated integers of the variable, none when it is none
+func (l *loader) getIntListEnv(key, defaultValue string) []int {
+	var values []int
+	if l.getEnv(key, defaultValue) == "none" {
+		return nil
+	}
+	for _, value := range l.getListEnv(key, defaultValue) {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			l.invalid(key, value, "a list of integers")
+			return nil
+		}
+		values = append(values, intValue)
+	}
+	return values
+}
+
+// getPrefixListEnv returns the comma-separated CIDR ranges of the variable. Single
+// addresses are read as ranges containing only that address.
+func (l *loader) getPrefixListEnv(key string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, value := range l.getListEnv(key, "") {
+		if addr, err := netip.ParseAddr(value); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			l.invalid(key, value, "an address or CIDR range")
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+func (l *loader) getFloatEnv(key string, defaultValue float64) float64 {
+	if value := l.lookup(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		l.invalid(key, value, "a number")
+	}
+	return defaultValue
+}
+
+func (l *loader) getBoolEnv(key string, defaultValue bool) bool {
+	if value := l.lookup(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		l.invalid(key, value, "a boolean")
+	}
+	return defaultValue
+}
+
+// invalid records that the value of the setting could not be parsed
+func (l *loader) invalid(key, value, expected string) {
+	l.errs = append(l.errs, fmt.Errorf("%s: invalid value %q, expected %s", key, value, expected))
+}
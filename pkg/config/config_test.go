@@ -0,0 +1,727 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDefaultsAreValid(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateRejectsDefaultSecretInProduction(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	cfg.App.Environment = "production"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the default secret must not be used in production")
+
+	cfg.JWT.SecretKey = "a-production-secret-that-is-long-enough"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	cfg.JWT.AccessTokenTTL = 0
+	cfg.Server.Port = "http"
+	cfg.Storage.Driver = "postgres"
+	cfg.Storage.MaxTasks = -1
+
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JWT_ACCESS_TOKEN_TTL: must be positive")
+	assert.Contains(t, err.Error(), `SERVER_PORT: "http" is not a port number`)
+	assert.Contains(t, err.Error(), `STORAGE_DRIVER: "postgres" is not supported`)
+	assert.Contains(t, err.Error(), "STORAGE_MAX_TASKS: must not be negative")
+}
+
+func TestLoadRejectsUnparsableValues(t *testing.T) {
+	_, err := LoadWithOverrides(map[string]string{
+		"JWT_ACCESS_TOKEN_TTL":        "15",
+		"LOGIN_GUARD_MAX_IP_FAILURES": "many",
+		"ATTACHMENT_THUMBNAIL_SIZES":  "128,large",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `JWT_ACCESS_TOKEN_TTL: invalid value "15", expected a duration`)
+	assert.Contains(t, err.Error(), `LOGIN_GUARD_MAX_IP_FAILURES: invalid value "many", expected an integer`)
+	assert.Contains(t, err.Error(), `ATTACHMENT_THUMBNAIL_SIZES: invalid value "large", expected a list of integers`)
+}
+
+func TestLoadFetchesSecretsFromVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"data":{"JWT_SECRET_KEY":"from-vault"},"metadata":{}}}`))
+	}))
+	defer server.Close()
+
+	vault := map[string]string{
+		"SECRETS_PROVIDER":   "vault",
+		"VAULT_ADDR":         server.URL,
+		"VAULT_TOKEN":        "root",
+		"SECRETS_VAULT_PATH": "secret/data/todo-api",
+	}
+	t.Setenv("JWT_SECRET_KEY", "from-env")
+
+	// Secrets take precedence over environment variables
+	cfg, err := LoadWithOverrides(vault)
+	require.NoError(t, err)
+	assert.Equal(t, "from-vault", cfg.JWTSecretKey())
+
+	// Settings of the provider itself cannot be fetched from it
+	invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"VAULT_TOKEN":"other","JWT_SECRT_KEY":"typo"}}`))
+	}))
+	defer invalid.Close()
+
+	vault["VAULT_ADDR"] = invalid.URL
+	_, err = LoadWithOverrides(vault)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown setting "VAULT_TOKEN"`)
+	assert.Contains(t, err.Error(), `unknown setting "JWT_SECRT_KEY"`)
+}
+
+func TestWatchSecretsKeepsPreviousJWTSecret(t *testing.T) {
+	var secret atomic.Value
+	secret.Store("from-vault")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":{"data":{"JWT_SECRET_KEY":%q},"metadata":{}}}`, secret.Load())
+	}))
+	defer server.Close()
+
+	cfg, err := LoadWithOverrides(map[string]string{
+		"SECRETS_PROVIDER":         "vault",
+		"VAULT_ADDR":               server.URL,
+		"VAULT_TOKEN":              "root",
+		"SECRETS_VAULT_PATH":       "secret/data/todo-api",
+		"SECRETS_REFRESH_INTERVAL": "10ms",
+		"JWT_REFRESH_TOKEN_TTL":    "1h",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"from-vault"}, cfg.JWTVerificationKeys())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cfg.WatchSecrets(ctx)
+
+	// A rotated secret signs tokens at once, while the previous one still verifies them for
+	// one refresh token TTL
+	rotatedAt := time.Now()
+	secret.Store("rotated")
+	require.Eventually(t, func() bool { return cfg.JWTSecretKey() == "rotated" }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"rotated", "from-vault"}, cfg.JWTVerificationKeys())
+	assert.Equal(t, []string{"rotated", "from-vault"}, cfg.jwtVerificationKeys(rotatedAt.Add(time.Hour-time.Second)))
+	assert.Equal(t, []string{"rotated"}, cfg.jwtVerificationKeys(time.Now().Add(time.Hour)))
+
+	// Only the secret rotated last is kept
+	secret.Store("rotated-again")
+	require.Eventually(t, func() bool { return cfg.JWTSecretKey() == "rotated-again" }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"rotated-again", "rotated"}, cfg.JWTVerificationKeys())
+}
+
+func TestValidateSecretsProvider(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	cfg.Secrets.Provider = "aws"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AWS_REGION: must be set when SECRETS_PROVIDER is aws")
+	assert.Contains(t, err.Error(), "SECRETS_AWS_SECRET_ID: must be set when SECRETS_PROVIDER is aws")
+
+	cfg.Secrets.Provider = "keychain"
+	assert.ErrorContains(t, cfg.Validate(), `SECRETS_PROVIDER: "keychain" is not one of none, vault, aws`)
+}
+
+func TestValidateMail(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	cfg.Mail.Provider = "smtp"
+	cfg.Mail.From = "not an address"
+	cfg.App.BaseURL = "todo.example.com"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SMTP_HOST: must be set when MAIL_PROVIDER is smtp")
+	assert.Contains(t, err.Error(), `MAIL_FROM: "not an address" is not an email address`)
+	assert.Contains(t, err.Error(), `APP_BASE_URL: "todo.example.com" is not an http or https URL`)
+
+	cfg.Mail.Provider = "sendmail"
+	assert.ErrorContains(t, cfg.Validate(), `MAIL_PROVIDER: "sendmail" is not one of log, smtp`)
+}
+
+func TestValidateSlack(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://slack.com/api", cfg.Slack.APIURL)
+
+	cfg.Slack.APIURL = "slack.com/api"
+	cfg.Slack.Timeout = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `SLACK_API_URL: "slack.com/api" is not an http or https URL`)
+	assert.Contains(t, err.Error(), "SLACK_TIMEOUT: must be positive")
+}
+
+func TestValidateTelegram(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, cfg.Telegram.LinkTTL)
+
+	cfg.Telegram.WebhookSecret = "not a valid secret!"
+	cfg.Telegram.LinkTTL = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TELEGRAM_WEBHOOK_SECRET: must be 1 to 256 letters, digits, underscores, or hyphens")
+	assert.Contains(t, err.Error(), "TELEGRAM_LINK_TTL: must be positive")
+}
+
+func TestValidateTwilio(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.twilio.com", cfg.Twilio.APIURL)
+	assert.False(t, cfg.Twilio.Enabled())
+
+	cfg.Twilio.AccountSID = "AC123"
+	cfg.Twilio.APIURL = "api.twilio.com"
+	cfg.Twilio.CodeTTL = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN, TWILIO_FROM: must be set together")
+	assert.Contains(t, err.Error(), `TWILIO_API_URL: "api.twilio.com" is not an http or https URL`)
+	assert.Contains(t, err.Error(), "TWILIO_VERIFICATION_TTL: must be positive")
+
+	cfg.Twilio.AuthToken = "token"
+	cfg.Twilio.From = "+14155550100"
+	cfg.Twilio.APIURL = "https://api.twilio.com"
+	cfg.Twilio.CodeTTL = time.Minute
+	require.NoError(t, cfg.Validate())
+	assert.True(t, cfg.Twilio.Enabled())
+}
+
+func TestValidateInboundEmail(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "mailgun", cfg.Inbound.Provider)
+	assert.False(t, cfg.Inbound.Enabled())
+
+	cfg.Inbound.Domain = "todo@example"
+	cfg.Inbound.Provider = "postmark"
+	cfg.Inbound.Timeout = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `INBOUND_EMAIL_DOMAIN: "todo@example" is not a domain name`)
+	assert.Contains(t, err.Error(), `INBOUND_EMAIL_PROVIDER: must be mailgun or ses, got "postmark"`)
+	assert.Contains(t, err.Error(), "INBOUND_EMAIL_TIMEOUT: must be positive")
+
+	cfg.Inbound.Domain = "todo.example"
+	cfg.Inbound.Provider = "mailgun"
+	cfg.Inbound.Timeout = time.Second
+	assert.ErrorContains(t, cfg.Validate(), "INBOUND_EMAIL_SIGNING_KEY: required when receiving emails through Mailgun")
+	cfg.Inbound.SigningKey = "key"
+	require.NoError(t, cfg.Validate())
+	assert.True(t, cfg.Inbound.Enabled())
+
+	cfg.Inbound.Provider = "ses"
+	assert.ErrorContains(t, cfg.Validate(), "INBOUND_EMAIL_SES_TOPIC_ARN: must be the ARN of an SNS topic")
+	cfg.Inbound.SESTopicARN = "arn:aws:sns:us-east-1:123456789012:inbound"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateLDAP(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "local", cfg.Auth.Provider)
+	assert.Equal(t, "(mail={username})", cfg.LDAP.UserFilter)
+
+	cfg.Auth.Provider = "ldap"
+	cfg.LDAP.URL = "ldaps://ldap.example.com"
+	cfg.LDAP.StartTLS = true
+	cfg.LDAP.BindDN = "cn=todo-api,dc=example,dc=com"
+	cfg.LDAP.UserFilter = "(&(objectClass=person)(mail=user))"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LDAP_START_TLS: must not be set with an ldaps URL")
+	assert.Contains(t, err.Error(), "LDAP_BIND_DN, LDAP_BIND_PASSWORD: must be set together")
+	assert.Contains(t, err.Error(), "LDAP_BASE_DN: must be set when AUTH_PROVIDER is ldap")
+	assert.Contains(t, err.Error(), "LDAP_USER_FILTER: must contain {username}")
+
+	cfg.LDAP.UserFilter = "(&(objectClass=person)(mail={username})"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LDAP_USER_FILTER: ldap: invalid filter")
+
+	cfg.LDAP.StartTLS = false
+	cfg.LDAP.BindPassword = "secret"
+	cfg.LDAP.BaseDN = "dc=example,dc=com"
+	cfg.LDAP.UserFilter = "(&(objectClass=person)(userPrincipalName={username}))"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Auth.Provider = "saml"
+	assert.ErrorContains(t, cfg.Validate(), `AUTH_PROVIDER: "saml" is not one of local, ldap`)
+}
+
+func TestValidateSCIM(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.SCIM.Enabled())
+
+	cfg.SCIM.Token = "short"
+	assert.ErrorContains(t, cfg.Validate(), "SCIM_TOKEN: must be at least 32 characters")
+
+	cfg.SCIM.Token = strings.Repeat("t", 32)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateBilling(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Billing.Enabled())
+	assert.Equal(t, "http://localhost:3000/billing/success", cfg.Billing.SuccessURL)
+	assert.Equal(t, 100, cfg.Billing.FreeMaxTasks)
+
+	cfg.Billing.SecretKey = "sk_test_123"
+	assert.ErrorContains(t, cfg.Validate(), "STRIPE_SECRET_KEY, STRIPE_WEBHOOK_SECRET, STRIPE_PRO_PRICE_ID: must be set together")
+
+	cfg.Billing.WebhookSecret = "whsec_123"
+	cfg.Billing.ProPriceID = "price_123"
+	require.NoError(t, cfg.Validate())
+	assert.True(t, cfg.Billing.Enabled())
+
+	cfg.Billing.CancelURL = "/billing"
+	assert.ErrorContains(t, cfg.Validate(), `BILLING_CANCEL_URL: "/billing" is not an http or https URL`)
+	cfg.Billing.CancelURL = "https://todo.example.com/billing"
+
+	cfg.Billing.FreeMaxIntegrations = -1
+	assert.ErrorContains(t, cfg.Validate(), "must not be negative")
+}
+
+func TestValidateJobs(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 4, cfg.Jobs.Workers)
+	assert.Equal(t, 5, cfg.Jobs.MaxAttempts)
+
+	cfg.Jobs.Workers = 0
+	cfg.Jobs.MaxBackoff = 100 * time.Millisecond
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JOBS_WORKERS: must be at least 1")
+	assert.Contains(t, err.Error(), "JOBS_MAX_BACKOFF: must be at least JOBS_RETRY_BACKOFF")
+}
+
+func TestValidateDigestSchedule(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	cfg.Notify.DigestSchedule = "0 8 * * 1-5"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notify.DigestSchedule = "0 8 * *"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NOTIFY_DIGEST_SCHEDULE: invalid cron expression")
+}
+
+func TestValidateBurndownSchedule(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "10 0 * * *", cfg.Reports.BurndownSchedule)
+
+	cfg.Reports.BurndownSchedule = ""
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Reports.BurndownSchedule = "nightly"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REPORTS_BURNDOWN_SCHEDULE: invalid cron expression")
+}
+
+func TestValidateWebhookSchedule(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "30 0 * * *", cfg.Reports.WebhookSchedule)
+	assert.Equal(t, 10*time.Second, cfg.Reports.WebhookTimeout)
+
+	cfg.Reports.WebhookSchedule = ""
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Reports.WebhookSchedule = "daily"
+	cfg.Reports.WebhookTimeout = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REPORTS_WEBHOOK_SCHEDULE: invalid cron expression")
+	assert.Contains(t, err.Error(), "REPORTS_WEBHOOK_TIMEOUT: must be positive")
+}
+
+func TestValidateServerTuning(t *testing.T) {
+	cfg, err := LoadWithOverrides(map[string]string{
+		"SERVER_MAX_CONNECTIONS":  "1000",
+		"SERVER_READ_BUFFER_SIZE": "16384",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1000, cfg.Server.MaxConnections)
+	assert.Equal(t, 16384, cfg.Server.ReadBufferSize)
+	assert.Equal(t, 4096, cfg.Server.WriteBufferSize)
+
+	cfg.Server.MaxConnections = 0
+	cfg.Server.ReadBufferSize = 512
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SERVER_MAX_CONNECTIONS: must be positive")
+	assert.Contains(t, err.Error(), "SERVER_READ_BUFFER_SIZE: must be at least 1024 bytes")
+}
+
+func TestValidateCache(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "none", cfg.Cache.Driver)
+
+	// Redis settings are only checked when Redis is used
+	cfg.Redis.Addr = "localhost"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Cache.Driver = "redis"
+	cfg.Cache.TTL = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CACHE_TTL: must be positive")
+	assert.Contains(t, err.Error(), `REDIS_ADDR: "localhost" is not a host:port address`)
+
+	cfg.Cache.Driver = "memcached"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `CACHE_DRIVER: "memcached" is not one of none, memory, redis`)
+}
+
+func TestValidateGitHub(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com", cfg.GitHub.APIURL)
+	assert.Equal(t, 5*time.Minute, cfg.GitHub.SyncInterval)
+
+	cfg.GitHub.ClientID = "client-id"
+	cfg.GitHub.OAuthURL = "github.com"
+	cfg.GitHub.SyncInterval = -time.Minute
+	cfg.GitHub.AuthorizationTTL = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GITHUB_CLIENT_ID, GITHUB_CLIENT_SECRET: must be set together")
+	assert.Contains(t, err.Error(), `GITHUB_OAUTH_URL: "github.com" is not an http or https URL`)
+	assert.Contains(t, err.Error(), "GITHUB_SYNC_INTERVAL: must not be negative")
+	assert.Contains(t, err.Error(), "GITHUB_AUTHORIZATION_TTL: must be positive")
+}
+
+func TestValidateGoogleCalendar(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.Calendar.SyncInterval)
+
+	cfg.Calendar.ClientSecret = "client-secret"
+	cfg.Calendar.Timeout = 0
+	cfg.Calendar.AuthorizationTTL = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GOOGLE_CALENDAR_CLIENT_ID, GOOGLE_CALENDAR_CLIENT_SECRET: must be set together")
+	assert.Contains(t, err.Error(), "GOOGLE_CALENDAR_TIMEOUT: must be positive")
+	assert.Contains(t, err.Error(), "GOOGLE_CALENDAR_AUTHORIZATION_TTL: must be positive")
+}
+
+func TestValidateAttachments(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Files.Enabled())
+	store, err := cfg.Files.NewStore(nil)
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	cfg.Files.Storage = "s3"
+	cfg.Files.S3Region = "eu-west-1"
+	cfg.Files.S3Endpoint = "minio:9000"
+	cfg.Files.URLTTL = 8 * 24 * time.Hour
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ATTACHMENT_S3_ACCESS_KEY_ID: must be set when ATTACHMENT_STORAGE is s3")
+	assert.Contains(t, err.Error(), `ATTACHMENT_S3_ENDPOINT: "minio:9000" is not an http or https URL`)
+	assert.Contains(t, err.Error(), "ATTACHMENT_BUCKET: must be set when ATTACHMENT_STORAGE is s3")
+	assert.Contains(t, err.Error(), "ATTACHMENT_URL_TTL: must be positive and at most 168h0m0s")
+	assert.Nil(t, cfg.Files.NewScanner())
+
+	cfg.Files.Scanner = "icap"
+	cfg.Files.ScannerService = ""
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ATTACHMENT_SCANNER_ADDRESS: must be set when ATTACHMENT_SCANNER is icap")
+	assert.Contains(t, err.Error(), "ATTACHMENT_SCANNER_SERVICE: must be set when ATTACHMENT_SCANNER is icap")
+
+	cfg.Files.Scanner = "clamav"
+	cfg.Files.ScannerAddress = "clamd:3310"
+	assert.NotNil(t, cfg.Files.NewScanner())
+
+	cfg.Files.Scanner = "none"
+	cfg.Files.ThumbnailSizes = []int{8, 128, 128}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ATTACHMENT_THUMBNAIL_SIZES: 8 is not between 16 and 2048")
+	assert.Contains(t, err.Error(), "ATTACHMENT_THUMBNAIL_SIZES: 128 is listed twice")
+	cfg.Files.ThumbnailSizes = nil
+	disabled, err := LoadWithOverrides(map[string]string{"ATTACHMENT_THUMBNAIL_SIZES": "none"})
+	require.NoError(t, err)
+	assert.Empty(t, disabled.Files.ThumbnailSizes)
+
+	cfg.Files.Scanner = "sophos"
+	assert.ErrorContains(t, cfg.Validate(), `ATTACHMENT_SCANNER: "sophos" is not one of none, clamav, icap`)
+	cfg.Files.Scanner = "none"
+
+	cfg.Files.Storage = "gcs"
+	cfg.Files.GCSCredentialsFile = "missing.json"
+	_, err = cfg.Files.NewStore(nil)
+	assert.ErrorContains(t, err, "ATTACHMENT_GCS_CREDENTIALS_FILE")
+
+	cfg.Files.Storage = "azure"
+	assert.ErrorContains(t, cfg.Validate(), `ATTACHMENT_STORAGE: "azure" is not one of none, s3, gcs`)
+}
+
+func TestValidateEventStream(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Stream.Enabled())
+	assert.Nil(t, cfg.Stream.NewPublisher())
+
+	cfg.Stream.Broker = "kafka"
+	cfg.Stream.Servers = []string{"kafka-1"}
+	cfg.Stream.Topic = "todo.>"
+	cfg.Stream.Password = "secret"
+	cfg.Stream.BatchSize = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `EVENT_STREAM_SERVERS: "kafka-1" is not a host:port address`)
+	assert.Contains(t, err.Error(), "EVENT_STREAM_TOPIC: must be set, without spaces or wildcards")
+	assert.Contains(t, err.Error(), "EVENT_STREAM_USERNAME: must be set when EVENT_STREAM_PASSWORD is")
+	assert.Contains(t, err.Error(), "EVENT_STREAM_BATCH_SIZE: must be at least 1")
+
+	cfg.Stream = EventStreamConfig{Broker: "nats", Topic: "todo.events", Timeout: time.Second, BatchSize: 10,
+		MaxBackoff: time.Minute, OutboxLimit: 100}
+	assert.ErrorContains(t, cfg.Validate(), "EVENT_STREAM_SERVERS: must be set when EVENT_STREAM_BROKER is nats")
+	cfg.Stream.Servers = []string{"nats:4222"}
+	require.NoError(t, cfg.Validate())
+	assert.NotNil(t, cfg.Stream.NewPublisher())
+
+	cfg.Stream.Broker = "rabbitmq"
+	assert.ErrorContains(t, cfg.Validate(), `EVENT_STREAM_BROKER: "rabbitmq" is not one of none, nats, kafka`)
+}
+
+func TestValidateWarehouse(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Warehouse.Enabled())
+	assert.True(t, cfg.Warehouse.Events)
+	assert.Equal(t, "0 2 * * *", cfg.Warehouse.SnapshotSchedule)
+	sink, err := cfg.Warehouse.NewSink()
+	require.NoError(t, err)
+	assert.Nil(t, sink)
+
+	cfg.Warehouse.Sink = "redshift"
+	cfg.Warehouse.AWSRegion = "us-east-1"
+	cfg.Warehouse.RedshiftCluster = "analytics"
+	cfg.Warehouse.RedshiftWorkgroup = "analytics"
+	cfg.Warehouse.RedshiftSecretARN = "warehouse"
+	cfg.Warehouse.RedshiftEndpoint = "localstack:4566"
+	cfg.Warehouse.SnapshotSchedule = "nightly"
+	cfg.Warehouse.OutboxLimit = 10
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WAREHOUSE_AWS_ACCESS_KEY_ID: must be set when WAREHOUSE_SINK is redshift")
+	assert.Contains(t, err.Error(), "WAREHOUSE_REDSHIFT_DATABASE: must be set when WAREHOUSE_SINK is redshift")
+	assert.Contains(t, err.Error(), "WAREHOUSE_REDSHIFT_CLUSTER, WAREHOUSE_REDSHIFT_WORKGROUP: exactly one must be set")
+	assert.Contains(t, err.Error(), "WAREHOUSE_REDSHIFT_SECRET_ARN: must be the ARN of a Secrets Manager secret")
+	assert.Contains(t, err.Error(), `WAREHOUSE_REDSHIFT_ENDPOINT: "localstack:4566" is not an http or https URL`)
+	assert.Contains(t, err.Error(), "WAREHOUSE_SNAPSHOT_SCHEDULE: invalid cron expression")
+	assert.Contains(t, err.Error(), "WAREHOUSE_OUTBOX_LIMIT: must be at least WAREHOUSE_BATCH_SIZE")
+
+	cfg.Warehouse = WarehouseConfig{Sink: "redshift", AWSRegion: "us-east-1", AWSAccessKeyID: "AKID",
+		AWSSecretAccessKey: "secret", RedshiftCluster: "analytics", RedshiftDatabase: "todo", Timeout: time.Second,
+		BatchSize: 100, MaxBackoff: time.Minute, OutboxLimit: 1000}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WAREHOUSE_REDSHIFT_DB_USER, WAREHOUSE_REDSHIFT_SECRET_ARN: one must be set for a cluster")
+	assert.Contains(t, err.Error(), "WAREHOUSE_EVENTS, WAREHOUSE_SNAPSHOT_SCHEDULE: nothing is exported without events or a schedule")
+	cfg.Warehouse.RedshiftDBUser = "exporter"
+	cfg.Warehouse.Events = true
+	require.NoError(t, cfg.Validate())
+	sink, err = cfg.Warehouse.NewSink()
+	require.NoError(t, err)
+	assert.NotNil(t, sink)
+
+	cfg.Warehouse.Sink = "bigquery"
+	cfg.Warehouse.BigQueryDataset = "todo-analytics"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WAREHOUSE_GCP_CREDENTIALS_FILE: must be set when WAREHOUSE_SINK is bigquery")
+	assert.Contains(t, err.Error(), "WAREHOUSE_BIGQUERY_DATASET: must be set to letters, digits, and underscores")
+	cfg.Warehouse.GCPCredentialsFile = "missing.json"
+	_, err = cfg.Warehouse.NewSink()
+	assert.ErrorContains(t, err, "WAREHOUSE_GCP_CREDENTIALS_FILE")
+
+	cfg.Warehouse.Sink = "snowflake"
+	assert.ErrorContains(t, cfg.Validate(), `WAREHOUSE_SINK: "snowflake" is not one of none, bigquery, redshift`)
+}
+
+func TestValidatePush(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.Push.MaxAttempts)
+	assert.False(t, cfg.Push.APNsEnabled())
+
+	// Platforms without credentials log notifications
+	android, ios, err := cfg.Push.NewSenders()
+	require.NoError(t, err)
+	assert.NotNil(t, android)
+	assert.NotNil(t, ios)
+
+	cfg.Push.APNsKeyFile = "AuthKey.p8"
+	cfg.Push.MaxAttempts = 0
+	cfg.Push.RetryBackoff = -time.Second
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PUSH_APNS_KEY_FILE, PUSH_APNS_KEY_ID, PUSH_APNS_TEAM_ID, PUSH_APNS_TOPIC: must be set together")
+	assert.Contains(t, err.Error(), "PUSH_MAX_ATTEMPTS: must be at least 1")
+	assert.Contains(t, err.Error(), "PUSH_RETRY_BACKOFF: must not be negative")
+
+	cfg.Push.FCMCredentialsFile = "missing.json"
+	_, _, err = cfg.Push.NewSenders()
+	assert.ErrorContains(t, err, "PUSH_FCM_CREDENTIALS_FILE")
+}
+
+func TestValidateResilience(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	policy := cfg.Resilience.Policy()
+	assert.Equal(t, 3, policy.MaxAttempts)
+	assert.Equal(t, 5, policy.FailureThreshold)
+
+	cfg.Resilience.MaxAttempts = 0
+	cfg.Resilience.RetryBackoff = 10 * time.Second
+	cfg.Resilience.FailureThreshold = 0
+	cfg.Resilience.OpenTimeout = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RESILIENCE_MAX_ATTEMPTS: must be at least 1")
+	assert.Contains(t, err.Error(), "RESILIENCE_MAX_BACKOFF: must be at least RESILIENCE_RETRY_BACKOFF")
+	assert.Contains(t, err.Error(), "RESILIENCE_FAILURE_THRESHOLD: must be at least 1")
+	assert.Contains(t, err.Error(), "RESILIENCE_OPEN_TIMEOUT: must be positive")
+}
+
+func TestValidateHealth(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, cfg.Health.Timeout)
+	assert.Equal(t, 5*time.Second, cfg.Health.Interval)
+
+	// Checking on every request is allowed
+	cfg.Health.Interval = 0
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Health.Timeout = 0
+	cfg.Health.Interval = -time.Second
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HEALTH_TIMEOUT: must be positive")
+	assert.Contains(t, err.Error(), "HEALTH_INTERVAL: must not be negative")
+}
+
+func TestLoadCORSDefaults(t *testing.T) {
+	// Development allows every origin without caching preflight responses
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*"}, cfg.CORS.AllowOrigins)
+	assert.Equal(t, []string{"Origin", "Content-Type", "Accept", "Authorization"}, cfg.CORS.AllowHeaders)
+	assert.Equal(t, []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}, cfg.CORS.AllowMethods)
+	assert.False(t, cfg.CORS.AllowCredentials)
+	assert.Zero(t, cfg.CORS.MaxAge)
+
+	// Other environments allow no origin until configured
+	cfg, err = LoadWithOverrides(map[string]string{"APP_ENV": "production", "JWT_SECRET_KEY": "a-production-secret-that-is-long-enough"})
+	require.NoError(t, err)
+	assert.Empty(t, cfg.CORS.AllowOrigins)
+	assert.Equal(t, time.Hour, cfg.CORS.MaxAge)
+}
+
+func TestLoadCORS(t *testing.T) {
+	cfg, err := LoadWithOverrides(map[string]string{
+		"CORS_ALLOW_ORIGINS":     " https://app.example.com ,, http://localhost:3000,",
+		"CORS_ALLOW_HEADERS":     "Authorization,X-Request-ID",
+		"CORS_ALLOW_METHODS":     "get, post",
+		"CORS_ALLOW_CREDENTIALS": "true",
+		"CORS_MAX_AGE":           "10m",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com", "http://localhost:3000"}, cfg.CORS.AllowOrigins)
+	assert.Equal(t, []string{"Authorization", "X-Request-ID"}, cfg.CORS.AllowHeaders)
+	assert.Equal(t, []string{"get", "post"}, cfg.CORS.AllowMethods)
+	assert.True(t, cfg.CORS.AllowCredentials)
+	assert.Equal(t, 10*time.Minute, cfg.CORS.MaxAge)
+	assert.NoError(t, cfg.Validate())
+
+	_, err = LoadWithOverrides(map[string]string{"CORS_ALLOW_CREDENTIALS": "sometimes", "CORS_MAX_AGE": "600"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `CORS_ALLOW_CREDENTIALS: invalid value "sometimes", expected a boolean`)
+	assert.Contains(t, err.Error(), `CORS_MAX_AGE: invalid value "600", expected a duration`)
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name string
+		cors CORSConfig
+		want string
+	}{
+		{"disabled", CORSConfig{}, ""},
+		{"wildcard", CORSConfig{AllowOrigins: []string{"*"}}, ""},
+		{"origins with credentials", CORSConfig{AllowOrigins: []string{"https://app.example.com", "http://localhost:3000"},
+			AllowCredentials: true}, ""},
+		{"wildcard with credentials", CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true},
+			"credentials cannot be allowed for the wildcard origin"},
+		{"wildcard with other origins", CORSConfig{AllowOrigins: []string{"https://app.example.com", "*"}},
+			"wildcard origin cannot be combined with other origins"},
+		{"origin with a path", CORSConfig{AllowOrigins: []string{"https://app.example.com/"}},
+			`invalid origin "https://app.example.com/": expected scheme://host[:port]`},
+		{"origin without a scheme", CORSConfig{AllowOrigins: []string{"app.example.com"}},
+			`invalid origin "app.example.com": expected scheme://host[:port]`},
+		{"origin with another scheme", CORSConfig{AllowOrigins: []string{"ftp://app.example.com"}},
+			`invalid origin "ftp://app.example.com": expected scheme://host[:port]`},
+		{"origin with credentials", CORSConfig{AllowOrigins: []string{"https://user@app.example.com"}},
+			`invalid origin "https://user@app.example.com": expected scheme://host[:port]`},
+		{"lowercase methods", CORSConfig{AllowMethods: []string{"get", "Head"}}, ""},
+		{"unsupported method", CORSConfig{AllowMethods: []string{"GET", "TRACE"}}, `unsupported method "TRACE"`},
+		{"negative max age", CORSConfig{MaxAge: -time.Second}, "max age must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cors.Validate()
+			if tt.want == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.want)
+			}
+		})
+	}
+
+	// Credentials are not allowed with the wildcard origin of development
+	_, err := LoadWithOverrides(map[string]string{"CORS_ALLOW_CREDENTIALS": "true"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CORS: credentials cannot be allowed for the wildcard origin")
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFiles are the configuration files looked up in the working directory when
+// CONFIG_FILE is not set
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// fileKeys maps the keys of configuration files to the environment variables they set
+var fileKeys = map[string]string{
+	"server.port":                       "SERVER_PORT",
+	"server.host":                       "SERVER_HOST",
+	"server.grpc_port":                  "GRPC_PORT",
+	"server.tenant_base_domain":         "TENANT_BASE_DOMAIN",
+	"server.read_timeout":               "SERVER_READ_TIMEOUT",
+	"server.write_timeout":              "SERVER_WRITE_TIMEOUT",
+	"server.idle_timeout":               "SERVER_IDLE_TIMEOUT",
+	"server.shutdown_timeout":           "SERVER_SHUTDOWN_TIMEOUT",
+	"server.request_timeout":            "SERVER_REQUEST_TIMEOUT",
+	"server.bulk_request_timeout":       "SERVER_BULK_REQUEST_TIMEOUT",
+	"server.slow_request_threshold":     "SERVER_SLOW_REQUEST_THRESHOLD",
+	"server.max_connections":            "SERVER_MAX_CONNECTIONS",
+	"server.read_buffer_size":           "SERVER_READ_BUFFER_SIZE",
+	"server.write_buffer_size":          "SERVER_WRITE_BUFFER_SIZE",
+	"server.public_rate_limit":          "SERVER_PUBLIC_RATE_LIMIT",
+	"tls.cert_file":                     "TLS_CERT_FILE",
+	"tls.key_file":                      "TLS_KEY_FILE",
+	"tls.autocert_domains":              "TLS_AUTOCERT_DOMAINS",
+	"tls.autocert_email":                "TLS_AUTOCERT_EMAIL",
+	"tls.autocert_cache_dir":            "TLS_AUTOCERT_CACHE_DIR",
+	"tls.redirect_port":                 "TLS_REDIRECT_PORT",
+	"tls.client_ca_file":                "TLS_CLIENT_CA_FILE",
+	"tls.client_auth_paths":             "TLS_CLIENT_AUTH_PATHS",
+	"tls.client_identities":             "TLS_CLIENT_IDENTITIES",
+	"jwt.secret_key":                    "JWT_SECRET_KEY",
+	"jwt.access_token_ttl":              "JWT_ACCESS_TOKEN_TTL",
+	"jwt.refresh_token_ttl":             "JWT_REFRESH_TOKEN_TTL",
+	"jwt.issuer":                        "JWT_ISSUER",
+	"auth.provider":                     "AUTH_PROVIDER",
+	"ldap.url":                          "LDAP_URL",
+	"ldap.bind_dn":                      "LDAP_BIND_DN",
+	"ldap.bind_password":                "LDAP_BIND_PASSWORD",
+	"ldap.base_dn":                      "LDAP_BASE_DN",
+	"ldap.user_filter":                  "LDAP_USER_FILTER",
+	"ldap.start_tls":                    "LDAP_START_TLS",
+	"ldap.timeout":                      "LDAP_TIMEOUT",
+	"scim.token":                        "SCIM_TOKEN",
+	"app.env":                           "APP_ENV",
+	"app.log_level":                     "LOG_LEVEL",
+	"app.base_url":                      "APP_BASE_URL",
+	"app.access_log_sample_rate":        "ACCESS_LOG_SAMPLE_RATE",
+	"limits.max_tasks_per_user":         "LIMIT_MAX_TASKS_PER_USER",
+	"limits.max_body_size":              "LIMIT_MAX_BODY_SIZE",
+	"search.engine":                     "SEARCH_ENGINE",
+	"search.elasticsearch_url":          "SEARCH_ELASTICSEARCH_URL",
+	"search.elasticsearch_index":        "SEARCH_ELASTICSEARCH_INDEX",
+	"search.elasticsearch_username":     "SEARCH_ELASTICSEARCH_USERNAME",
+	"search.elasticsearch_password":     "SEARCH_ELASTICSEARCH_PASSWORD",
+	"search.elasticsearch_timeout":      "SEARCH_ELASTICSEARCH_TIMEOUT",
+	"search.reconcile_interval":         "SEARCH_RECONCILE_INTERVAL",
+	"cors.allow_origins":                "CORS_ALLOW_ORIGINS",
+	"cors.allow_headers":                "CORS_ALLOW_HEADERS",
+	"cors.allow_methods":                "CORS_ALLOW_METHODS",
+	"cors.allow_credentials":            "CORS_ALLOW_CREDENTIALS",
+	"cors.max_age":                      "CORS_MAX_AGE",
+	"login_guard.window":                "LOGIN_GUARD_WINDOW",
+	"login_guard.max_ip_failures":       "LOGIN_GUARD_MAX_IP_FAILURES",
+	"login_guard.max_account_failures":  "LOGIN_GUARD_MAX_ACCOUNT_FAILURES",
+	"login_guard.flag_duration":         "LOGIN_GUARD_FLAG_DURATION",
+	"login_guard.throttle_interval":     "LOGIN_GUARD_THROTTLE_INTERVAL",
+	"login_guard.max_travel_speed":      "LOGIN_GUARD_MAX_TRAVEL_SPEED",
+	"ip.trusted_proxies":                "TRUSTED_PROXIES",
+	"ip.allowlist":                      "IP_ALLOWLIST",
+	"ip.denylist":                       "IP_DENYLIST",
+	"ip.admin_allowlist":                "ADMIN_IP_ALLOWLIST",
+	"ip.admin_denylist":                 "ADMIN_IP_DENYLIST",
+	"storage.driver":                    "STORAGE_DRIVER",
+	"storage.seed_file":                 "STORAGE_SEED_FILE",
+	"storage.max_tasks":                 "STORAGE_MAX_TASKS",
+	"storage.max_bytes":                 "STORAGE_MAX_BYTES",
+	"secrets.provider":                  "SECRETS_PROVIDER",
+	"secrets.refresh_interval":          "SECRETS_REFRESH_INTERVAL",
+	"secrets.timeout":                   "SECRETS_TIMEOUT",
+	"secrets.vault_address":             "VAULT_ADDR",
+	"secrets.vault_token":               "VAULT_TOKEN",
+	"secrets.vault_path":                "SECRETS_VAULT_PATH",
+	"secrets.aws_region":                "AWS_REGION",
+	"secrets.aws_secret_id":             "SECRETS_AWS_SECRET_ID",
+	"secrets.aws_access_key_id":         "AWS_ACCESS_KEY_ID",
+	"secrets.aws_secret_access_key":     "AWS_SECRET_ACCESS_KEY",
+	"secrets.aws_session_token":         "AWS_SESSION_TOKEN",
+	"secrets.aws_endpoint":              "SECRETS_AWS_ENDPOINT",
+	"mail.provider":                     "MAIL_PROVIDER",
+	"mail.from":                         "MAIL_FROM",
+	"mail.timeout":                      "MAIL_TIMEOUT",
+	"mail.smtp_host":                    "SMTP_HOST",
+	"mail.smtp_port":                    "SMTP_PORT",
+	"mail.smtp_username":                "SMTP_USERNAME",
+	"mail.smtp_password":                "SMTP_PASSWORD",
+	"mail.smtp_implicit_tls":            "SMTP_IMPLICIT_TLS",
+	"notifications.reminder_lead_time":  "NOTIFY_REMINDER_LEAD_TIME",
+	"notifications.reminder_interval":   "NOTIFY_REMINDER_INTERVAL",
+	"notifications.digest_interval":     "NOTIFY_DIGEST_INTERVAL",
+	"notifications.digest_schedule":     "NOTIFY_DIGEST_SCHEDULE",
+	"notifications.escalation_interval": "NOTIFY_ESCALATION_INTERVAL",
+	"account.password_reset_ttl":        "ACCOUNT_PASSWORD_RESET_TTL",
+	"account.email_verification_ttl":    "ACCOUNT_EMAIL_VERIFICATION_TTL",
+	"account.export_ttl":                "ACCOUNT_EXPORT_TTL",
+	"slack.signing_secret":              "SLACK_SIGNING_SECRET",
+	"slack.api_url":                     "SLACK_API_URL",
+	"slack.timeout":                     "SLACK_TIMEOUT",
+	"telegram.webhook_secret":           "TELEGRAM_WEBHOOK_SECRET",
+	"telegram.bot_username":             "TELEGRAM_BOT_USERNAME",
+	"telegram.link_ttl":                 "TELEGRAM_LINK_TTL",
+	"twilio.account_sid":                "TWILIO_ACCOUNT_SID",
+	"twilio.auth_token":                 "TWILIO_AUTH_TOKEN",
+	"twilio.from":                       "TWILIO_FROM",
+	"twilio.api_url":                    "TWILIO_API_URL",
+	"twilio.timeout":                    "TWILIO_TIMEOUT",
+	"twilio.verification_ttl":           "TWILIO_VERIFICATION_TTL",
+	"inbound_email.domain":              "INBOUND_EMAIL_DOMAIN",
+	"inbound_email.provider":            "INBOUND_EMAIL_PROVIDER",
+	"inbound_email.signing_key":         "INBOUND_EMAIL_SIGNING_KEY",
+	"inbound_email.ses_topic_arn":       "INBOUND_EMAIL_SES_TOPIC_ARN",
+	"inbound_email.timeout":             "INBOUND_EMAIL_TIMEOUT",
+	"billing.stripe_secret_key":         "STRIPE_SECRET_KEY",
+	"billing.stripe_webhook_secret":     "STRIPE_WEBHOOK_SECRET",
+	"billing.stripe_pro_price_id":       "STRIPE_PRO_PRICE_ID",
+	"billing.stripe_api_url":            "STRIPE_API_URL",
+	"billing.stripe_timeout":            "STRIPE_TIMEOUT",
+	"billing.success_url":               "BILLING_SUCCESS_URL",
+	"billing.cancel_url":                "BILLING_CANCEL_URL",
+	"billing.free_max_tasks":            "BILLING_FREE_MAX_TASKS",
+	"billing.free_max_attachments":      "BILLING_FREE_MAX_ATTACHMENTS",
+	"billing.free_max_integrations":     "BILLING_FREE_MAX_INTEGRATIONS",
+	"push.fcm_credentials_file":         "PUSH_FCM_CREDENTIALS_FILE",
+	"push.apns_key_file":                "PUSH_APNS_KEY_FILE",
+	"push.apns_key_id":                  "PUSH_APNS_KEY_ID",
+	"push.apns_team_id":                 "PUSH_APNS_TEAM_ID",
+	"push.apns_topic":                   "PUSH_APNS_TOPIC",
+	"push.apns_sandbox":                 "PUSH_APNS_SANDBOX",
+	"push.timeout":                      "PUSH_TIMEOUT",
+	"push.max_attempts":                 "PUSH_MAX_ATTEMPTS",
+	"push.retry_backoff":                "PUSH_RETRY_BACKOFF",
+	"github.client_id":                  "GITHUB_CLIENT_ID",
+	"github.client_secret":              "GITHUB_CLIENT_SECRET",
+	"github.webhook_secret":             "GITHUB_WEBHOOK_SECRET",
+	"github.api_url":                    "GITHUB_API_URL",
+	"github.oauth_url":                  "GITHUB_OAUTH_URL",
+	"github.timeout":                    "GITHUB_TIMEOUT",
+	"github.sync_interval":              "GITHUB_SYNC_INTERVAL",
+	"github.authorization_ttl":          "GITHUB_AUTHORIZATION_TTL",
+	"calendar.client_id":                "GOOGLE_CALENDAR_CLIENT_ID",
+	"calendar.client_secret":            "GOOGLE_CALENDAR_CLIENT_SECRET",
+	"calendar.timeout":                  "GOOGLE_CALENDAR_TIMEOUT",
+	"calendar.sync_interval":            "GOOGLE_CALENDAR_SYNC_INTERVAL",
+	"calendar.authorization_ttl":        "GOOGLE_CALENDAR_AUTHORIZATION_TTL",
+	"attachments.storage":               "ATTACHMENT_STORAGE",
+	"attachments.bucket":                "ATTACHMENT_BUCKET",
+	"attachments.max_size":              "ATTACHMENT_MAX_SIZE",
+	"attachments.url_ttl":               "ATTACHMENT_URL_TTL",
+	"attachments.timeout":               "ATTACHMENT_TIMEOUT",
+	"attachments.s3_region":             "ATTACHMENT_S3_REGION",
+	"attachments.s3_access_key_id":      "ATTACHMENT_S3_ACCESS_KEY_ID",
+	"attachments.s3_secret_access_key":  "ATTACHMENT_S3_SECRET_ACCESS_KEY",
+	"attachments.s3_session_token":      "ATTACHMENT_S3_SESSION_TOKEN",
+	"attachments.s3_endpoint":           "ATTACHMENT_S3_ENDPOINT",
+	"attachments.s3_path_style":         "ATTACHMENT_S3_PATH_STYLE",
+	"attachments.gcs_credentials_file":  "ATTACHMENT_GCS_CREDENTIALS_FILE",
+	"attachments.scanner":               "ATTACHMENT_SCANNER",
+	"attachments.scanner_address":       "ATTACHMENT_SCANNER_ADDRESS",
+	"attachments.scanner_service":       "ATTACHMENT_SCANNER_SERVICE",
+	"attachments.scanner_timeout":       "ATTACHMENT_SCANNER_TIMEOUT",
+	"attachments.thumbnail_sizes":       "ATTACHMENT_THUMBNAIL_SIZES",
+	"event_stream.broker":               "EVENT_STREAM_BROKER",
+	"event_stream.servers":              "EVENT_STREAM_SERVERS",
+	"event_stream.topic":                "EVENT_STREAM_TOPIC",
+	"event_stream.username":             "EVENT_STREAM_USERNAME",
+	"event_stream.password":             "EVENT_STREAM_PASSWORD",
+	"event_stream.tls":                  "EVENT_STREAM_TLS",
+	"event_stream.timeout":              "EVENT_STREAM_TIMEOUT",
+	"event_stream.batch_size":           "EVENT_STREAM_BATCH_SIZE",
+	"event_stream.max_backoff":          "EVENT_STREAM_MAX_BACKOFF",
+	"event_stream.outbox_limit":         "EVENT_STREAM_OUTBOX_LIMIT",
+	"jobs.workers":                      "JOBS_WORKERS",
+	"jobs.max_attempts":                 "JOBS_MAX_ATTEMPTS",
+	"jobs.retry_backoff":                "JOBS_RETRY_BACKOFF",
+	"jobs.max_backoff":                  "JOBS_MAX_BACKOFF",
+	"jobs.dead_letter_limit":            "JOBS_DEAD_LETTER_LIMIT",
+	"jobs.retention":                    "JOBS_RETENTION",
+	"reports.burndown_schedule":         "REPORTS_BURNDOWN_SCHEDULE",
+	"reports.webhook_schedule":          "REPORTS_WEBHOOK_SCHEDULE",
+	"reports.webhook_timeout":           "REPORTS_WEBHOOK_TIMEOUT",
+	"warehouse.sink":                    "WAREHOUSE_SINK",
+	"warehouse.events":                  "WAREHOUSE_EVENTS",
+	"warehouse.snapshot_schedule":       "WAREHOUSE_SNAPSHOT_SCHEDULE",
+	"warehouse.timeout":                 "WAREHOUSE_TIMEOUT",
+	"warehouse.batch_size":              "WAREHOUSE_BATCH_SIZE",
+	"warehouse.max_backoff":             "WAREHOUSE_MAX_BACKOFF",
+	"warehouse.outbox_limit":            "WAREHOUSE_OUTBOX_LIMIT",
+	"warehouse.gcp_credentials_file":    "WAREHOUSE_GCP_CREDENTIALS_FILE",
+	"warehouse.bigquery_project":        "WAREHOUSE_BIGQUERY_PROJECT",
+	"warehouse.bigquery_dataset":        "WAREHOUSE_BIGQUERY_DATASET",
+	"warehouse.aws_region":              "WAREHOUSE_AWS_REGION",
+	"warehouse.aws_access_key_id":       "WAREHOUSE_AWS_ACCESS_KEY_ID",
+	"warehouse.aws_secret_access_key":   "WAREHOUSE_AWS_SECRET_ACCESS_KEY",
+	"warehouse.aws_session_token":       "WAREHOUSE_AWS_SESSION_TOKEN",
+	"warehouse.redshift_cluster":        "WAREHOUSE_REDSHIFT_CLUSTER",
+	"warehouse.redshift_workgroup":      "WAREHOUSE_REDSHIFT_WORKGROUP",
+	"warehouse.redshift_database":       "WAREHOUSE_REDSHIFT_DATABASE",
+	"warehouse.redshift_db_user":        "WAREHOUSE_REDSHIFT_DB_USER",
+	"warehouse.redshift_secret_arn":     "WAREHOUSE_REDSHIFT_SECRET_ARN",
+	"warehouse.redshift_endpoint":       "WAREHOUSE_REDSHIFT_ENDPOINT",
+	"cache.driver":                      "CACHE_DRIVER",
+	"cache.ttl":                         "CACHE_TTL",
+	"redis.addr":                        "REDIS_ADDR",
+	"redis.username":                    "REDIS_USERNAME",
+	"redis.password":                    "REDIS_PASSWORD",
+	"redis.db":                          "REDIS_DB",
+	"redis.tls":                         "REDIS_TLS",
+	"redis.timeout":                     "REDIS_TIMEOUT",
+	"redis.pool_size":                   "REDIS_POOL_SIZE",
+	"resilience.max_attempts":           "RESILIENCE_MAX_ATTEMPTS",
+	"resilience.retry_backoff":          "RESILIENCE_RETRY_BACKOFF",
+	"resilience.max_backoff":            "RESILIENCE_MAX_BACKOFF",
+	"resilience.attempt_timeout":        "RESILIENCE_ATTEMPT_TIMEOUT",
+	"resilience.failure_threshold":      "RESILIENCE_FAILURE_THRESHOLD",
+	"resilience.open_timeout":           "RESILIENCE_OPEN_TIMEOUT",
+	"health.timeout":                    "HEALTH_TIMEOUT",
+	"health.interval":                   "HEALTH_INTERVAL",
+}
+
+// findConfigFile returns the configuration file at path when set, or the first default
+// configuration file present, if any
+func findConfigFile(path string) (string, error) {
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	for _, path := range defaultConfigFiles {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// loadFile reads a YAML or TOML configuration file into the values of the environment
+// variables it sets. Unknown keys are rejected so typos do not go unnoticed.
+func loadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		tree, err = parseTOML(data)
+	default:
+		return nil, fmt.Errorf("%s: unsupported format, expected .yaml, .yml, or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flattenFile(tree, "", values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flattenFile stores the values of the tree below prefix by environment variable name
+func flattenFile(tree map[string]interface{}, prefix string, values map[string]string) error {
+	keys := make([]string, 0, len(tree))
+	for key := range tree {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		path := prefix + key
+
+		if env, ok := fileKeys[path]; ok {
+			value, err := formatFileValue(tree[key])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("key %q: %w", path, err))
+				continue
+			}
+			values[env] = value
+			continue
+		}
+
+		if !isFileSection(path) {
+			errs = append(errs, fmt.Errorf("unknown key %q", path))
+			continue
+		}
+		table, ok := tree[key].(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Errorf("key %q: expected a table", path))
+			continue
+		}
+		errs = append(errs, flattenFile(table, path+".", values))
+	}
+	return errors.Join(errs...)
+}
+
+// isSetting reports whether the environment variable is a setting that can be fetched from
+// the secrets provider, which excludes the settings of the provider itself
+func isSetting(key string) bool {
+	for fileKey, env := range fileKeys {
+		if env == key {
+			return !strings.HasPrefix(fileKey, "secrets.")
+		}
+	}
+	return false
+}
+
+// isFileSection reports whether any file key belongs to the section
+func isFileSection(section string) bool {
+	for key := range fileKeys {
+		if strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFileValue formats a scalar or list the way it would be written in an environment
+// variable, with list items separated by commas
+func formatFileValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			formatted, err := formatFileValue(item)
+			if err != nil || strings.Contains(formatted, ",") {
+				return "", errors.New("lists may only hold values without commas")
+			}
+			items[i] = formatted
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", errors.New("expected a value or a list of values")
+	}
+}
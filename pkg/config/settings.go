@@ -0,0 +1,278 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the value of secret settings
+const Redacted = "[redacted]"
+
+// Setting is an effective configuration value, named by its environment variable
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// Settings returns the effective value of every setting, with secrets redacted
+func (c *Config) Settings() []Setting {
+	duration := func(d time.Duration) string { return d.String() }
+	list := func(values []string) string { return strings.Join(values, ",") }
+	prefixes := func(values []netip.Prefix) string {
+		formatted := make([]string, len(values))
+		for i, prefix := range values {
+			formatted[i] = prefix.String()
+		}
+		return list(formatted)
+	}
+	numbers := func(values []int) string {
+		formatted := make([]string, len(values))
+		for i, value := range values {
+			formatted[i] = strconv.Itoa(value)
+		}
+		return list(formatted)
+	}
+	secret := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return Redacted
+	}
+
+	return []Setting{
+		{"APP_ENV", c.App.Environment},
+		{"LOG_LEVEL", c.App.LogLevel},
+		{"APP_BASE_URL", c.App.BaseURL},
+		{"ACCESS_LOG_SAMPLE_RATE", strconv.FormatFloat(c.App.AccessLogSampleRate, 'f', -1, 64)},
+		{"STORAGE_DRIVER", c.Storage.Driver},
+		{"STORAGE_SEED_FILE", c.Storage.SeedFile},
+		{"STORAGE_MAX_TASKS", strconv.Itoa(c.Storage.MaxTasks)},
+		{"STORAGE_MAX_BYTES", strconv.Itoa(c.Storage.MaxBytes)},
+		{"SERVER_HOST", c.Server.Host},
+		{"SERVER_PORT", c.Server.Port},
+		{"GRPC_PORT", c.Server.GRPCPort},
+		{"TENANT_BASE_DOMAIN", c.Server.TenantBaseDomain},
+		{"SERVER_READ_TIMEOUT", duration(c.Server.ReadTimeout)},
+		{"SERVER_WRITE_TIMEOUT", duration(c.Server.WriteTimeout)},
+		{"SERVER_IDLE_TIMEOUT", duration(c.Server.IdleTimeout)},
+		{"SERVER_SHUTDOWN_TIMEOUT", duration(c.Server.ShutdownTimeout)},
+		{"SERVER_REQUEST_TIMEOUT", duration(c.Server.RequestTimeout)},
+		{"SERVER_BULK_REQUEST_TIMEOUT", duration(c.Server.BulkRequestTimeout)},
+		{"SERVER_SLOW_REQUEST_THRESHOLD", duration(c.Server.SlowRequestThreshold)},
+		{"SERVER_MAX_CONNECTIONS", strconv.Itoa(c.Server.MaxConnections)},
+		{"SERVER_READ_BUFFER_SIZE", strconv.Itoa(c.Server.ReadBufferSize)},
+		{"SERVER_WRITE_BUFFER_SIZE", strconv.Itoa(c.Server.WriteBufferSize)},
+		{"SERVER_PUBLIC_RATE_LIMIT", strconv.Itoa(c.Server.PublicRateLimit)},
+		{"TLS_CERT_FILE", c.TLS.CertFile},
+		{"TLS_KEY_FILE", c.TLS.KeyFile},
+		{"TLS_AUTOCERT_DOMAINS", list(c.TLS.AutocertDomains)},
+		{"TLS_AUTOCERT_EMAIL", c.TLS.AutocertEmail},
+		{"TLS_AUTOCERT_CACHE_DIR", c.TLS.AutocertCacheDir},
+		{"TLS_REDIRECT_PORT", c.TLS.RedirectPort},
+		{"TLS_CLIENT_CA_FILE", c.TLS.ClientCAFile},
+		{"TLS_CLIENT_AUTH_PATHS", list(c.TLS.ClientAuthPaths)},
+		{"TLS_CLIENT_IDENTITIES", list(c.TLS.ClientIdentities)},
+		{"JWT_SECRET_KEY", secret(c.JWT.SecretKey)},
+		{"JWT_ACCESS_TOKEN_TTL", duration(c.JWT.AccessTokenTTL)},
+		{"JWT_REFRESH_TOKEN_TTL", duration(c.JWT.RefreshTokenTTL)},
+		{"JWT_ISSUER", c.JWT.Issuer},
+		{"AUTH_PROVIDER", c.Auth.Provider},
+		{"LDAP_URL", c.LDAP.URL},
+		{"LDAP_BIND_DN", c.LDAP.BindDN},
+		{"LDAP_BIND_PASSWORD", secret(c.LDAP.BindPassword)},
+		{"LDAP_BASE_DN", c.LDAP.BaseDN},
+		{"LDAP_USER_FILTER", c.LDAP.UserFilter},
+		{"LDAP_START_TLS", strconv.FormatBool(c.LDAP.StartTLS)},
+		{"LDAP_TIMEOUT", duration(c.LDAP.Timeout)},
+		{"SCIM_TOKEN", secret(c.SCIM.Token)},
+		{"LIMIT_MAX_TASKS_PER_USER", strconv.Itoa(c.Limits.MaxTasksPerUser)},
+		{"LIMIT_MAX_BODY_SIZE", strconv.Itoa(c.Limits.MaxBodySize)},
+		{"SEARCH_ENGINE", c.Search.Engine},
+		{"SEARCH_ELASTICSEARCH_URL", c.Search.ElasticsearchURL},
+		{"SEARCH_ELASTICSEARCH_INDEX", c.Search.ElasticsearchIndex},
+		{"SEARCH_ELASTICSEARCH_USERNAME", c.Search.ElasticsearchUsername},
+		{"SEARCH_ELASTICSEARCH_PASSWORD", secret(c.Search.ElasticsearchPassword)},
+		{"SEARCH_ELASTICSEARCH_TIMEOUT", duration(c.Search.ElasticsearchTimeout)},
+		{"SEARCH_RECONCILE_INTERVAL", duration(c.Search.ReconcileInterval)},
+		{"CORS_ALLOW_ORIGINS", list(c.CORS.AllowOrigins)},
+		{"CORS_ALLOW_HEADERS", list(c.CORS.AllowHeaders)},
+		{"CORS_ALLOW_METHODS", list(c.CORS.AllowMethods)},
+		{"CORS_ALLOW_CREDENTIALS", strconv.FormatBool(c.CORS.AllowCredentials)},
+		{"CORS_MAX_AGE", duration(c.CORS.MaxAge)},
+		{"LOGIN_GUARD_WINDOW", duration(c.Login.Window)},
+		{"LOGIN_GUARD_MAX_IP_FAILURES", strconv.Itoa(c.Login.MaxIPFailures)},
+		{"LOGIN_GUARD_MAX_ACCOUNT_FAILURES", strconv.Itoa(c.Login.MaxAccountFailures)},
+		{"LOGIN_GUARD_FLAG_DURATION", duration(c.Login.FlagDuration)},
+		{"LOGIN_GUARD_THROTTLE_INTERVAL", duration(c.Login.ThrottleInterval)},
+		{"LOGIN_GUARD_MAX_TRAVEL_SPEED", strconv.FormatFloat(c.Login.MaxTravelSpeedKmh, 'f', -1, 64)},
+		{"TRUSTED_PROXIES", prefixes(c.IP.TrustedProxies)},
+		{"IP_ALLOWLIST", prefixes(c.IP.Allow)},
+		{"IP_DENYLIST", prefixes(c.IP.Deny)},
+		{"ADMIN_IP_ALLOWLIST", prefixes(c.IP.AdminAllow)},
+		{"ADMIN_IP_DENYLIST", prefixes(c.IP.AdminDeny)},
+		{"SECRETS_PROVIDER", c.Secrets.Provider},
+		{"SECRETS_REFRESH_INTERVAL", duration(c.Secrets.RefreshInterval)},
+		{"SECRETS_TIMEOUT", duration(c.Secrets.Timeout)},
+		{"VAULT_ADDR", c.Secrets.VaultAddress},
+		{"VAULT_TOKEN", secret(c.Secrets.VaultToken)},
+		{"SECRETS_VAULT_PATH", c.Secrets.VaultPath},
+		{"AWS_REGION", c.Secrets.AWSRegion},
+		{"SECRETS_AWS_SECRET_ID", c.Secrets.AWSSecretID},
+		{"AWS_ACCESS_KEY_ID", c.Secrets.AWSAccessKeyID},
+		{"AWS_SECRET_ACCESS_KEY", secret(c.Secrets.AWSSecretAccessKey)},
+		{"AWS_SESSION_TOKEN", secret(c.Secrets.AWSSessionToken)},
+		{"SECRETS_AWS_ENDPOINT", c.Secrets.AWSEndpoint},
+		{"MAIL_PROVIDER", c.Mail.Provider},
+		{"MAIL_FROM", c.Mail.From},
+		{"MAIL_TIMEOUT", duration(c.Mail.Timeout)},
+		{"SMTP_HOST", c.Mail.SMTPHost},
+		{"SMTP_PORT", c.Mail.SMTPPort},
+		{"SMTP_USERNAME", c.Mail.SMTPUsername},
+		{"SMTP_PASSWORD", secret(c.Mail.SMTPPassword)},
+		{"SMTP_IMPLICIT_TLS", strconv.FormatBool(c.Mail.SMTPImplicitTLS)},
+		{"NOTIFY_REMINDER_LEAD_TIME", duration(c.Notify.ReminderLeadTime)},
+		{"NOTIFY_REMINDER_INTERVAL", duration(c.Notify.ReminderInterval)},
+		{"NOTIFY_DIGEST_INTERVAL", duration(c.Notify.DigestInterval)},
+		{"NOTIFY_DIGEST_SCHEDULE", c.Notify.DigestSchedule},
+		{"NOTIFY_ESCALATION_INTERVAL", duration(c.Notify.EscalationInterval)},
+		{"ACCOUNT_PASSWORD_RESET_TTL", duration(c.Account.PasswordResetTTL)},
+		{"ACCOUNT_EMAIL_VERIFICATION_TTL", duration(c.Account.EmailVerificationTTL)},
+		{"ACCOUNT_EXPORT_TTL", duration(c.Account.ExportTTL)},
+		{"SLACK_SIGNING_SECRET", secret(c.Slack.SigningSecret)},
+		{"SLACK_API_URL", c.Slack.APIURL},
+		{"SLACK_TIMEOUT", duration(c.Slack.Timeout)},
+		{"TELEGRAM_WEBHOOK_SECRET", secret(c.Telegram.WebhookSecret)},
+		{"TELEGRAM_BOT_USERNAME", c.Telegram.BotUsername},
+		{"TELEGRAM_LINK_TTL", duration(c.Telegram.LinkTTL)},
+		{"TWILIO_ACCOUNT_SID", c.Twilio.AccountSID},
+		{"TWILIO_AUTH_TOKEN", secret(c.Twilio.AuthToken)},
+		{"TWILIO_FROM", c.Twilio.From},
+		{"TWILIO_API_URL", c.Twilio.APIURL},
+		{"TWILIO_TIMEOUT", duration(c.Twilio.Timeout)},
+		{"TWILIO_VERIFICATION_TTL", duration(c.Twilio.CodeTTL)},
+		{"INBOUND_EMAIL_DOMAIN", c.Inbound.Domain},
+		{"INBOUND_EMAIL_PROVIDER", c.Inbound.Provider},
+		{"INBOUND_EMAIL_SIGNING_KEY", secret(c.Inbound.SigningKey)},
+		{"INBOUND_EMAIL_SES_TOPIC_ARN", c.Inbound.SESTopicARN},
+		{"INBOUND_EMAIL_TIMEOUT", duration(c.Inbound.Timeout)},
+		{"STRIPE_SECRET_KEY", secret(c.Billing.SecretKey)},
+		{"STRIPE_WEBHOOK_SECRET", secret(c.Billing.WebhookSecret)},
+		{"STRIPE_PRO_PRICE_ID", c.Billing.ProPriceID},
+		{"STRIPE_API_URL", c.Billing.APIURL},
+		{"STRIPE_TIMEOUT", duration(c.Billing.Timeout)},
+		{"BILLING_SUCCESS_URL", c.Billing.SuccessURL},
+		{"BILLING_CANCEL_URL", c.Billing.CancelURL},
+		{"BILLING_FREE_MAX_TASKS", strconv.Itoa(c.Billing.FreeMaxTasks)},
+		{"BILLING_FREE_MAX_ATTACHMENTS", strconv.Itoa(c.Billing.FreeMaxAttachments)},
+		{"BILLING_FREE_MAX_INTEGRATIONS", strconv.Itoa(c.Billing.FreeMaxIntegrations)},
+		{"PUSH_FCM_CREDENTIALS_FILE", c.Push.FCMCredentialsFile},
+		{"PUSH_APNS_KEY_FILE", c.Push.APNsKeyFile},
+		{"PUSH_APNS_KEY_ID", c.Push.APNsKeyID},
+		{"PUSH_APNS_TEAM_ID", c.Push.APNsTeamID},
+		{"PUSH_APNS_TOPIC", c.Push.APNsTopic},
+		{"PUSH_APNS_SANDBOX", strconv.FormatBool(c.Push.APNsSandbox)},
+		{"PUSH_TIMEOUT", duration(c.Push.Timeout)},
+		{"PUSH_MAX_ATTEMPTS", strconv.Itoa(c.Push.MaxAttempts)},
+		{"PUSH_RETRY_BACKOFF", duration(c.Push.RetryBackoff)},
+		{"GITHUB_CLIENT_ID", c.GitHub.ClientID},
+		{"GITHUB_CLIENT_SECRET", secret(c.GitHub.ClientSecret)},
+		{"GITHUB_WEBHOOK_SECRET", secret(c.GitHub.WebhookSecret)},
+		{"GITHUB_API_URL", c.GitHub.APIURL},
+		{"GITHUB_OAUTH_URL", c.GitHub.OAuthURL},
+		{"GITHUB_TIMEOUT", duration(c.GitHub.Timeout)},
+		{"GITHUB_SYNC_INTERVAL", duration(c.GitHub.SyncInterval)},
+		{"GITHUB_AUTHORIZATION_TTL", duration(c.GitHub.AuthorizationTTL)},
+		{"GOOGLE_CALENDAR_CLIENT_ID", c.Calendar.ClientID},
+		{"GOOGLE_CALENDAR_CLIENT_SECRET", secret(c.Calendar.ClientSecret)},
+		{"GOOGLE_CALENDAR_TIMEOUT", duration(c.Calendar.Timeout)},
+		{"GOOGLE_CALENDAR_SYNC_INTERVAL", duration(c.Calendar.SyncInterval)},
+		{"GOOGLE_CALENDAR_AUTHORIZATION_TTL", duration(c.Calendar.AuthorizationTTL)},
+		{"ATTACHMENT_STORAGE", c.Files.Storage},
+		{"ATTACHMENT_BUCKET", c.Files.Bucket},
+		{"ATTACHMENT_MAX_SIZE", strconv.Itoa(c.Files.MaxSize)},
+		{"ATTACHMENT_URL_TTL", duration(c.Files.URLTTL)},
+		{"ATTACHMENT_TIMEOUT", duration(c.Files.Timeout)},
+		{"ATTACHMENT_S3_REGION", c.Files.S3Region},
+		{"ATTACHMENT_S3_ACCESS_KEY_ID", c.Files.S3AccessKeyID},
+		{"ATTACHMENT_S3_SECRET_ACCESS_KEY", secret(c.Files.S3SecretAccessKey)},
+		{"ATTACHMENT_S3_SESSION_TOKEN", secret(c.Files.S3SessionToken)},
+		{"ATTACHMENT_S3_ENDPOINT", c.Files.S3Endpoint},
+		{"ATTACHMENT_S3_PATH_STYLE", strconv.FormatBool(c.Files.S3PathStyle)},
+		{"ATTACHMENT_GCS_CREDENTIALS_FILE", c.Files.GCSCredentialsFile},
+		{"ATTACHMENT_SCANNER", c.Files.Scanner},
+		{"ATTACHMENT_SCANNER_ADDRESS", c.Files.ScannerAddress},
+		{"ATTACHMENT_SCANNER_SERVICE", c.Files.ScannerService},
+		{"ATTACHMENT_SCANNER_TIMEOUT", duration(c.Files.ScannerTimeout)},
+		{"ATTACHMENT_THUMBNAIL_SIZES", numbers(c.Files.ThumbnailSizes)},
+		{"EVENT_STREAM_BROKER", c.Stream.Broker},
+		{"EVENT_STREAM_SERVERS", list(c.Stream.Servers)},
+		{"EVENT_STREAM_TOPIC", c.Stream.Topic},
+		{"EVENT_STREAM_USERNAME", c.Stream.Username},
+		{"EVENT_STREAM_PASSWORD", secret(c.Stream.Password)},
+		{"EVENT_STREAM_TLS", strconv.FormatBool(c.Stream.TLS)},
+		{"EVENT_STREAM_TIMEOUT", duration(c.Stream.Timeout)},
+		{"EVENT_STREAM_BATCH_SIZE", strconv.Itoa(c.Stream.BatchSize)},
+		{"EVENT_STREAM_MAX_BACKOFF", duration(c.Stream.MaxBackoff)},
+		{"EVENT_STREAM_OUTBOX_LIMIT", strconv.Itoa(c.Stream.OutboxLimit)},
+		{"JOBS_WORKERS", strconv.Itoa(c.Jobs.Workers)},
+		{"JOBS_MAX_ATTEMPTS", strconv.Itoa(c.Jobs.MaxAttempts)},
+		{"JOBS_RETRY_BACKOFF", duration(c.Jobs.RetryBackoff)},
+		{"JOBS_MAX_BACKOFF", duration(c.Jobs.MaxBackoff)},
+		{"JOBS_DEAD_LETTER_LIMIT", strconv.Itoa(c.Jobs.DeadLetterLimit)},
+		{"JOBS_RETENTION", duration(c.Jobs.Retention)},
+		{"REPORTS_BURNDOWN_SCHEDULE", c.Reports.BurndownSchedule},
+		{"REPORTS_WEBHOOK_SCHEDULE", c.Reports.WebhookSchedule},
+		{"REPORTS_WEBHOOK_TIMEOUT", duration(c.Reports.WebhookTimeout)},
+		{"WAREHOUSE_SINK", c.Warehouse.Sink},
+		{"WAREHOUSE_EVENTS", strconv.FormatBool(c.Warehouse.Events)},
+		{"WAREHOUSE_SNAPSHOT_SCHEDULE", c.Warehouse.SnapshotSchedule},
+		{"WAREHOUSE_TIMEOUT", duration(c.Warehouse.Timeout)},
+		{"WAREHOUSE_BATCH_SIZE", strconv.Itoa(c.Warehouse.BatchSize)},
+		{"WAREHOUSE_MAX_BACKOFF", duration(c.Warehouse.MaxBackoff)},
+		{"WAREHOUSE_OUTBOX_LIMIT", strconv.Itoa(c.Warehouse.OutboxLimit)},
+		{"WAREHOUSE_GCP_CREDENTIALS_FILE", c.Warehouse.GCPCredentialsFile},
+		{"WAREHOUSE_BIGQUERY_PROJECT", c.Warehouse.BigQueryProject},
+		{"WAREHOUSE_BIGQUERY_DATASET", c.Warehouse.BigQueryDataset},
+		{"WAREHOUSE_AWS_REGION", c.Warehouse.AWSRegion},
+		{"WAREHOUSE_AWS_ACCESS_KEY_ID", c.Warehouse.AWSAccessKeyID},
+		{"WAREHOUSE_AWS_SECRET_ACCESS_KEY", secret(c.Warehouse.AWSSecretAccessKey)},
+		{"WAREHOUSE_AWS_SESSION_TOKEN", secret(c.Warehouse.AWSSessionToken)},
+		{"WAREHOUSE_REDSHIFT_CLUSTER", c.Warehouse.RedshiftCluster},
+		{"WAREHOUSE_REDSHIFT_WORKGROUP", c.Warehouse.RedshiftWorkgroup},
+		{"WAREHOUSE_REDSHIFT_DATABASE", c.Warehouse.RedshiftDatabase},
+		{"WAREHOUSE_REDSHIFT_DB_USER", c.Warehouse.RedshiftDBUser},
+		{"WAREHOUSE_REDSHIFT_SECRET_ARN", c.Warehouse.RedshiftSecretARN},
+		{"WAREHOUSE_REDSHIFT_ENDPOINT", c.Warehouse.RedshiftEndpoint},
+		{"CACHE_DRIVER", c.Cache.Driver},
+		{"CACHE_TTL", duration(c.Cache.TTL)},
+		{"REDIS_ADDR", c.Redis.Addr},
+		{"REDIS_USERNAME", c.Redis.Username},
+		{"REDIS_PASSWORD", secret(c.Redis.Password)},
+		{"REDIS_DB", strconv.Itoa(c.Redis.DB)},
+		{"REDIS_TLS", strconv.FormatBool(c.Redis.TLS)},
+		{"REDIS_TIMEOUT", duration(c.Redis.Timeout)},
+		{"REDIS_POOL_SIZE", strconv.Itoa(c.Redis.PoolSize)},
+		{"RESILIENCE_MAX_ATTEMPTS", strconv.Itoa(c.Resilience.MaxAttempts)},
+		{"RESILIENCE_RETRY_BACKOFF", duration(c.Resilience.RetryBackoff)},
+		{"RESILIENCE_MAX_BACKOFF", duration(c.Resilience.MaxBackoff)},
+		{"RESILIENCE_ATTEMPT_TIMEOUT", duration(c.Resilience.AttemptTimeout)},
+		{"RESILIENCE_FAILURE_THRESHOLD", strconv.Itoa(c.Resilience.FailureThreshold)},
+		{"RESILIENCE_OPEN_TIMEOUT", duration(c.Resilience.OpenTimeout)},
+		{"HEALTH_TIMEOUT", duration(c.Health.Timeout)},
+		{"HEALTH_INTERVAL", duration(c.Health.Interval)},
+	}
+}
+
+// WriteSettings writes the effective settings as NAME=value lines, with secrets redacted
+func (c *Config) WriteSettings(w io.Writer) error {
+	for _, setting := range c.Settings() {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", setting.Name, setting.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML parses the subset of TOML used by configuration files into nested maps:
+// [tables] with dotted names, key = value pairs with bare or dotted keys, comments, and
+// values that are strings, integers, floats, booleans, or single-line arrays of them. Like
+// TOML, each table and key is defined once.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+	currentPath := ""
+	// Tables defined by a [table] header, and by dotted keys, by their dotted path
+	headers := make(map[string]bool)
+	dotted := make(map[string]bool)
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(stripTOMLComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %s", n+1, line)
+			}
+			path := tomlPath(line[1 : len(line)-1])
+			if headers[path] || dotted[path] {
+				return nil, fmt.Errorf("line %d: table %s is already defined", n+1, path)
+			}
+			table, err := tomlTable(root, path)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			headers[path] = true
+			current, currentPath = table, path
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n+1)
+		}
+
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		path := strings.Split(strings.TrimSpace(key), ".")
+		// Dotted keys define their tables, which cannot be defined by a header too
+		for i := 1; i < len(path); i++ {
+			prefix := tomlPath(strings.Join(path[:i], "."))
+			if currentPath != "" {
+				prefix = currentPath + "." + prefix
+			}
+			if headers[prefix] {
+				return nil, fmt.Errorf("line %d: table %s is already defined", n+1, prefix)
+			}
+			dotted[prefix] = true
+		}
+		table, err := tomlTable(current, strings.Join(path[:len(path)-1], "."))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		name := strings.TrimSpace(path[len(path)-1])
+		if !isBareTOMLKey(name) {
+			return nil, fmt.Errorf("line %d: invalid key %q", n+1, name)
+		}
+		if _, exists := table[name]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", n+1, name)
+		}
+		table[name] = value
+	}
+
+	return root, nil
+}
+
+// tomlPath returns the dotted path without the spaces around its keys
+func tomlPath(path string) string {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+	return strings.Join(names, ".")
+}
+
+// tomlTable returns the table at the dotted path below parent, creating missing tables
+func tomlTable(parent map[string]interface{}, path string) (map[string]interface{}, error) {
+	if path == "" {
+		return parent, nil
+	}
+
+	table := parent
+	for _, name := range strings.Split(path, ".") {
+		name = strings.TrimSpace(name)
+		if !isBareTOMLKey(name) {
+			return nil, fmt.Errorf("invalid key %q", name)
+		}
+
+		switch existing := table[name].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			table[name] = next
+			table = next
+		case map[string]interface{}:
+			table = existing
+		default:
+			return nil, fmt.Errorf("key %q is not a table", name)
+		}
+	}
+	return table, nil
+}
+
+// parseTOMLValue parses a string, number, boolean, or single-line array
+func parseTOMLValue(raw string) (interface{}, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	case strings.HasPrefix(raw, `"`):
+		if len(raw) < 2 || !strings.HasSuffix(raw, `"`) {
+			return nil, fmt.Errorf("unterminated string %s", raw)
+		}
+		return unquoteTOML(raw)
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("unterminated string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return nil, fmt.Errorf("arrays must be on a single line")
+		}
+		var values []interface{}
+		for _, item := range splitTOMLArray(raw[1 : len(raw)-1]) {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			value, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	number := strings.ReplaceAll(raw, "_", "")
+	if value, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return int(value), nil
+	}
+	if value, err := strconv.ParseFloat(number, 64); err == nil {
+		return value, nil
+	}
+	return nil, fmt.Errorf("invalid value %s", raw)
+}
+
+// unquoteTOML unquotes a basic string. Unlike Go strings, only the escapes \b, \t, \n, \f,
+// \r, \", \\, \uXXXX, and \UXXXXXXXX are valid, and control characters other than tabs must
+// be escaped.
+func unquoteTOML(raw string) (string, error) {
+	s := raw[1 : len(raw)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			return "", fmt.Errorf("invalid string %s", raw)
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", fmt.Errorf("control character in string %s", raw)
+		case c != '\\':
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		switch s[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(s[i])
+		case 'u', 'U':
+			digits := 4
+			if s[i] == 'U' {
+				digits = 8
+			}
+			if i+digits >= len(s) {
+				return "", fmt.Errorf("invalid escape \\%c in string %s", s[i], raw)
+			}
+			code, err := strconv.ParseUint(s[i+1:i+1+digits], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape \\%s in string %s", s[i:i+1+digits], raw)
+			}
+			b.WriteRune(rune(code))
+			i += digits
+		default:
+			return "", fmt.Errorf("invalid escape \\%c in string %s", s[i], raw)
+		}
+	}
+	return b.String(), nil
+}
+
+// splitTOMLArray splits the items of an array on commas outside strings
+func splitTOMLArray(raw string) []string {
+	var items []string
+	var quote rune
+	start := 0
+	for i, r := range raw {
+		switch {
+		case quote != 0:
+			if r == quote && (quote == '\'' || !escaped(raw, i)) {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, raw[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, raw[start:])
+}
+
+// stripTOMLComment removes a comment started by # outside strings
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote && (quote == '\'' || !escaped(line, i)) {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// escaped reports whether the character at i is preceded by an odd number of backslashes
+func escaped(s string, i int) bool {
+	backslashes := 0
+	for i--; i >= 0 && s[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// isBareTOMLKey reports whether the key only has letters, digits, dashes, and underscores
+func isBareTOMLKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTOML(t *testing.T) {
+	tree, err := parseTOML([]byte(`
+# Server settings
+env = "production" # trailing comment
+port = 8_080
+ratio = 0.5
+debug = false
+origins = ["https://a.example.com", 'https://b.example.com', ]
+database.host = "db.internal"
+database.pool.size = 10
+
+[jwt]
+secret_key = "a\tb \"quoted\" \\ \u00e9 \U0001F600 # not a comment"
+path = 'C:\keys\jwt'
+
+[server.tls]
+cert_file = "cert.pem"
+
+[server]
+port = 443
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"env":     "production",
+		"port":    8080,
+		"ratio":   0.5,
+		"debug":   false,
+		"origins": []interface{}{"https://a.example.com", "https://b.example.com"},
+		"database": map[string]interface{}{
+			"host": "db.internal",
+			"pool": map[string]interface{}{"size": 10},
+		},
+		"jwt": map[string]interface{}{
+			"secret_key": "a\tb \"quoted\" \\ \u00e9 \U0001F600 # not a comment",
+			"path":       `C:\keys\jwt`,
+		},
+		"server": map[string]interface{}{
+			"tls":  map[string]interface{}{"cert_file": "cert.pem"},
+			"port": 443,
+		},
+	}, tree)
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"redefined table", "[jwt]\nsecret_key = \"a\"\n[jwt]\nissuer = \"b\"", "line 3: table jwt is already defined"},
+		{"redefined table with spaces", "[server.tls]\n[ server . tls ]", "line 2: table server.tls is already defined"},
+		{"table defined by dotted keys", "jwt.secret_key = \"a\"\n[jwt]", "line 2: table jwt is already defined"},
+		{"dotted keys into a header table", "[server.tls]\ncert_file = \"a\"\n[server]\ntls.key_file = \"b\"",
+			"line 4: table server.tls is already defined"},
+		{"duplicate key", "port = 1\nport = 2", `line 2: duplicate key "port"`},
+		{"table reopened after others", "[server]\nport = 1\n[jwt]\n[server.tls]\n[server]", "line 5: table server is already defined"},
+		{"duplicate dotted key", "database.host = \"a\"\ndatabase.host = \"b\"", `line 2: duplicate key "host"`},
+		{"key redefined as table", "server = 1\n[server]", `line 2: key "server" is not a table`},
+		{"table redefined as key", "[server.tls]\n[server]\ntls = 1", `line 3: duplicate key "tls"`},
+		{"array of tables", "[[servers]]", "line 1: invalid table header [[servers]]"},
+		{"missing equals", "port 8080", "line 1: expected key = value"},
+		{"missing value", "port =", "line 1: missing value"},
+		{"invalid key", "my key = 1", `line 1: invalid key "my key"`},
+		{"invalid value", "port = eighty", "line 1: invalid value eighty"},
+		{"unterminated string", `name = "todo`, `line 1: unterminated string "todo`},
+		{"multi-line array", "origins = [", "line 1: arrays must be on a single line"},
+		{"Go hex escape", `name = "\x41"`, `line 1: invalid escape \x in string "\x41"`},
+		{"Go octal escape", `name = "\101"`, `line 1: invalid escape \1 in string "\101"`},
+		{"Go bell escape", `name = "\a"`, `line 1: invalid escape \a in string "\a"`},
+		{"escaped single quote", `name = "it\'s"`, `line 1: invalid escape \' in string "it\'s"`},
+		{"short unicode escape", `name = "\u00e"`, `line 1: invalid escape \u in string "\u00e"`},
+		{"invalid unicode escape", `name = "\uD800"`, `line 1: invalid escape \uD800 in string "\uD800"`},
+		{"unescaped quote", `name = "a"b"`, `line 1: invalid string "a"b"`},
+		{"control character", "name = \"a\x01b\"", "line 1: control character in string \"a\x01b\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML([]byte(tt.data))
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
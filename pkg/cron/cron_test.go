@@ -0,0 +1,78 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Next(t *testing.T) {
+	// Friday
+	from := time.Date(2024, 1, 12, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 12, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 12, 10, 30, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2024, 1, 13, 8, 0, 0, 0, time.UTC)},
+		{"30 9-17/2 * * *", time.Date(2024, 1, 12, 11, 30, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		// Either the day of month or the day of week matches when both are restricted
+		{"0 0 20 * 6", time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 12, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+			assert.Equal(t, tt.spec, schedule.String())
+		})
+	}
+}
+
+func TestSchedule_Next_Location(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	schedule, err := Parse("0 8 * * *")
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, 1, 12, 10, 0, 0, 0, tokyo))
+	assert.Equal(t, time.Date(2024, 1, 13, 8, 0, 0, 0, tokyo), next)
+}
+
+func TestSchedule_Next_Never(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,396 @@
+package gcal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default endpoints of Google
+const (
+	DefaultAPIURL      = "https://www.googleapis.com/calendar/v3"
+	DefaultAuthURL     = "https://accounts.google.com/o/oauth2/v2/auth"
+	DefaultTokenURL    = "https://oauth2.googleapis.com/token"
+	DefaultUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+)
+
+// OAuthScope is the scope requested from users, allowing to manage the events of their
+// calendars and to read their email address
+const OAuthScope = "openid email https://www.googleapis.com/auth/calendar.events"
+
+// PrimaryCalendar is the ID of the user's primary calendar
+const PrimaryCalendar = "primary"
+
+// Event statuses
+const (
+	StatusConfirmed = "confirmed"
+	StatusCancelled = "cancelled" // deleted events, listed with showDeleted
+)
+
+// dateLayout is the format of the dates of all-day events
+const dateLayout = "2006-01-02"
+
+// maxPages bounds the pages of events listed at once, so a busy calendar cannot stall the
+// sync. Events are listed oldest change first, so the rest are listed by the next sync.
+const maxPages = 10
+
+// EventTime is the start or end of an event: a date for all-day events, a date and time
+// otherwise
+type EventTime struct {
+	Date     string `json:"date,omitempty"`     // 2006-01-02
+	DateTime string `json:"dateTime,omitempty"` // RFC 3339
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// AllDay returns the time of an all-day event on the date of t
+func AllDay(t time.Time) EventTime {
+	return EventTime{Date: t.Format(dateLayout)}
+}
+
+// At returns the time of an event at t
+func At(t time.Time) EventTime {
+	return EventTime{DateTime: t.Format(time.RFC3339)}
+}
+
+// Time returns the time of the event time; the dates of all-day events start at midnight UTC
+func (t EventTime) Time() (time.Time, error) {
+	if t.DateTime != "" {
+		return time.Parse(time.RFC3339, t.DateTime)
+	}
+	return time.Parse(dateLayout, t.Date)
+}
+
+// ExtendedProperties holds the properties an application attaches to an event
+type ExtendedProperties struct {
+	Private map[string]string `json:"private,omitempty"` // visible to the application only
+}
+
+// Event is a calendar event
+type Event struct {
+	ID                 string              `json:"id,omitempty"`
+	Status             string              `json:"status,omitempty"`
+	Summary            string              `json:"summary,omitempty"`
+	Description        string              `json:"description,omitempty"`
+	HTMLLink           string              `json:"htmlLink,omitempty"`
+	Start              *EventTime          `json:"start,omitempty"`
+	End                *EventTime          `json:"end,omitempty"`
+	Updated            *time.Time          `json:"updated,omitempty"` // read-only
+	ExtendedProperties *ExtendedProperties `json:"extendedProperties,omitempty"`
+}
+
+// UpdatedAt returns when the event was last changed
+func (e *Event) UpdatedAt() time.Time {
+	if e.Updated == nil {
+		return time.Time{}
+	}
+	return *e.Updated
+}
+
+// Private returns the private extended property of the event, or an empty string
+func (e *Event) Private(key string) string {
+	if e.ExtendedProperties == nil {
+		return ""
+	}
+	return e.ExtendedProperties.Private[key]
+}
+
+// Token is an OAuth token. Access tokens expire; the refresh token obtains new ones.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// Config configures a Google Calendar client
+type Config struct {
+	ClientID     string // OAuth client ID
+	ClientSecret string
+	APIURL       string // defaults to DefaultAPIURL
+	AuthURL      string // defaults to DefaultAuthURL
+	TokenURL     string // defaults to DefaultTokenURL
+	UserInfoURL  string // defaults to DefaultUserInfoURL
+	Timeout      time.Duration
+	Transport    http.RoundTripper // defaults to http.DefaultTransport
+}
+
+// Client authorizes users through OAuth and manages the events of their calendars
+type Client interface {
+	// AuthorizeURL returns the URL users grant access at, redirecting back to the redirect
+	// URI with a code and the state. Offline access is requested for a refresh token.
+	AuthorizeURL(redirectURI, state string) string
+	// ExchangeCode exchanges the code of an authorization for a token
+	ExchangeCode(ctx context.Context, code, redirectURI string) (*Token, error)
+	// RefreshToken obtains a new access token with the refresh token
+	RefreshToken(ctx context.Context, refreshToken string) (*Token, error)
+	// GetEmail returns the email address of the user the access token belongs to
+	GetEmail(ctx context.Context, accessToken string) (string, error)
+	// ListEvents lists the events of the calendar with the private property, including
+	// deleted events, updated at or after since, least recently updated first
+	ListEvents(ctx context.Context, accessToken, calendarID, property, value string, since time.Time) ([]*Event, error)
+	InsertEvent(ctx context.Context, accessToken, calendarID string, event *Event) (*Event, error)
+	// PatchEvent changes the fields set in the event
+	PatchEvent(ctx context.Context, accessToken, calendarID, eventID string, event *Event) (*Event, error)
+	DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error
+}
+
+// client implements a Google Calendar client over HTTP
+type client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewClient creates a Google Calendar client
+func NewClient(cfg Config) Client {
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = DefaultAuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = DefaultTokenURL
+	}
+	if cfg.UserInfoURL == "" {
+		cfg.UserInfoURL = DefaultUserInfoURL
+	}
+	cfg.APIURL = strings.TrimSuffix(cfg.APIURL, "/")
+	return &client{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport}}
+}
+
+// AuthorizeURL returns the URL users grant access at. Consent is always prompted, as Google
+// only returns a refresh token on consent.
+func (c *client) AuthorizeURL(redirectURI, state string) string {
+	query := url.Values{
+		"client_id":     {c.cfg.ClientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {OAuthScope},
+		"access_type":   {"offline"},
+		"prompt":        {"consent"},
+		"state":         {state},
+	}
+	return c.cfg.AuthURL + "?" + query.Encode()
+}
+
+// ExchangeCode exchanges the code for a token
+func (c *client) ExchangeCode(ctx context.Context, code, redirectURI string) (*Token, error) {
+	token, err := c.requestToken(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURI},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		return nil, errors.New("google oauth error: no refresh token granted")
+	}
+	return token, nil
+}
+
+// RefreshToken obtains a new access token. The refresh token is kept unless Google rotated it.
+func (c *client) RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
+	token, err := c.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// requestToken requests a token from the token endpoint
+func (c *client) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	form.Set("client_id", c.cfg.ClientID)
+	form.Set("client_secret", c.cfg.ClientSecret)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid google oauth response: %w", err)
+	}
+	if result.Error == "invalid_grant" {
+		return nil, ErrInvalidGrant
+	}
+	if result.AccessToken == "" {
+		return nil, fmt.Errorf("google oauth error: %s %s", result.Error, result.ErrorDescription)
+	}
+	return &Token{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// GetEmail returns the email address of the user the access token belongs to
+func (c *client) GetEmail(ctx context.Context, accessToken string) (string, error) {
+	var info struct {
+		Email string `json:"email"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.cfg.UserInfoURL, accessToken, nil, &info); err != nil {
+		return "", err
+	}
+	return info.Email, nil
+}
+
+// ListEvents lists the events with the private property updated at or after since, following
+// the page tokens of the responses
+func (c *client) ListEvents(ctx context.Context, accessToken, calendarID, property, value string, since time.Time) ([]*Event, error) {
+	query := url.Values{
+		"privateExtendedProperty": {property + "=" + value},
+		"showDeleted":             {"true"},
+		"orderBy":                 {"updated"},
+		"maxResults":              {"250"},
+	}
+	if !since.IsZero() {
+		query.Set("updatedMin", since.UTC().Format(time.RFC3339))
+	}
+
+	var events []*Event
+	for page := 0; page < maxPages; page++ {
+		var result struct {
+			Items         []*Event `json:"items"`
+			NextPageToken string   `json:"nextPageToken"`
+		}
+		if err := c.do(ctx, http.MethodGet, c.eventsURL(calendarID, "")+"?"+query.Encode(), accessToken, nil, &result); err != nil {
+			return nil, err
+		}
+		events = append(events, result.Items...)
+		if result.NextPageToken == "" {
+			break
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+	return events, nil
+}
+
+// InsertEvent creates the event
+func (c *client) InsertEvent(ctx context.Context, accessToken, calendarID string, event *Event) (*Event, error) {
+	var created Event
+	if err := c.do(ctx, http.MethodPost, c.eventsURL(calendarID, ""), accessToken, event, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// PatchEvent changes the fields set in the event
+func (c *client) PatchEvent(ctx context.Context, accessToken, calendarID, eventID string, event *Event) (*Event, error) {
+	var patched Event
+	if err := c.do(ctx, http.MethodPatch, c.eventsURL(calendarID, eventID), accessToken, event, &patched); err != nil {
+		return nil, err
+	}
+	return &patched, nil
+}
+
+// DeleteEvent deletes the event
+func (c *client) DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error {
+	return c.do(ctx, http.MethodDelete, c.eventsURL(calendarID, eventID), accessToken, nil, nil)
+}
+
+// eventsURL returns the URL of the events of the calendar, or of one event
+func (c *client) eventsURL(calendarID, eventID string) string {
+	u := c.cfg.APIURL + "/calendars/" + url.PathEscape(calendarID) + "/events"
+	if eventID != "" {
+		u += "/" + url.PathEscape(eventID)
+	}
+	return u
+}
+
+// do calls the API at the URL, decoding the JSON response into out unless it is nil
+func (c *client) do(ctx context.Context, method, u, accessToken string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var result struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result)
+		return &APIError{StatusCode: resp.StatusCode, Message: result.Error.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid google calendar response: %w", err)
+	}
+	return nil
+}
+
+// ErrInvalidGrant is returned when Google rejects a code or refresh token, e.g. because the
+// user revoked access
+var ErrInvalidGrant = errors.New("google oauth error: invalid_grant")
+
+// APIError is an error response of the Google Calendar API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	return fmt.Sprintf("google calendar responded with status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsUnauthorized reports whether the error means the user revoked access, so the user must
+// grant access again
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.Is(err, ErrInvalidGrant) || (errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized)
+}
+
+// IsNotFound reports whether the error means the event does not exist or was deleted
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusGone)
+}
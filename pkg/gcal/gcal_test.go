@@ -0,0 +1,113 @@
+package gcal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeURL(t *testing.T) {
+	c := NewClient(Config{ClientID: "client-id"})
+	u, err := url.Parse(c.AuthorizeURL("https://todo.example.com/integrations/google-calendar/callback", "state-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "accounts.google.com", u.Host)
+	assert.Equal(t, "client-id", u.Query().Get("client_id"))
+	assert.Equal(t, "offline", u.Query().Get("access_type"))
+	assert.Equal(t, OAuthScope, u.Query().Get("scope"))
+	assert.Equal(t, "state-1", u.Query().Get("state"))
+}
+
+func TestTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.FormValue("client_secret"))
+		switch {
+		case r.FormValue("code") == "good-code":
+			w.Write([]byte(`{"access_token":"ya29.first","refresh_token":"1//refresh","expires_in":3599}`))
+		case r.FormValue("refresh_token") == "1//refresh":
+			w.Write([]byte(`{"access_token":"ya29.second","expires_in":3599}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{ClientID: "client-id", ClientSecret: "secret", TokenURL: server.URL, Timeout: time.Second})
+	token, err := c.ExchangeCode(context.Background(), "good-code", "https://todo.example.com/callback")
+	require.NoError(t, err)
+	assert.Equal(t, "ya29.first", token.AccessToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+
+	// The refresh token is kept when Google does not rotate it
+	token, err = c.RefreshToken(context.Background(), token.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, "ya29.second", token.AccessToken)
+	assert.Equal(t, "1//refresh", token.RefreshToken)
+
+	_, err = c.RefreshToken(context.Background(), "revoked")
+	assert.True(t, IsUnauthorized(err))
+}
+
+func TestListEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer ya29.token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/calendars/primary/events", r.URL.Path)
+		assert.Equal(t, "todoApp=1", r.URL.Query().Get("privateExtendedProperty"))
+		assert.Equal(t, "true", r.URL.Query().Get("showDeleted"))
+
+		if r.URL.Query().Get("pageToken") == "" {
+			assert.Equal(t, "2026-03-01T00:00:00Z", r.URL.Query().Get("updatedMin"))
+			w.Write([]byte(`{"items":[{"id":"a","status":"confirmed","start":{"date":"2026-03-10"},"updated":"2026-03-02T10:00:00Z"}],"nextPageToken":"p2"}`))
+			return
+		}
+		w.Write([]byte(`{"items":[{"id":"b","status":"cancelled","updated":"2026-03-03T10:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{APIURL: server.URL, Timeout: time.Second})
+	events, err := c.ListEvents(context.Background(), "ya29.token", PrimaryCalendar, "todoApp", "1", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	start, err := events[0].Start.Time()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, StatusCancelled, events[1].Status)
+}
+
+func TestPatchAndDeleteEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/calendars/primary/events/a" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"message":"Not Found"}}`))
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		assert.Equal(t, http.MethodPatch, r.Method)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"summary": "Renamed"}, body)
+		w.Write([]byte(`{"id":"a","summary":"Renamed","updated":"2026-03-02T10:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{APIURL: server.URL, Timeout: time.Second})
+	event, err := c.PatchEvent(context.Background(), "ya29.token", PrimaryCalendar, "a", &Event{Summary: "Renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), event.UpdatedAt())
+
+	require.NoError(t, c.DeleteEvent(context.Background(), "ya29.token", PrimaryCalendar, "a"))
+	err = c.DeleteEvent(context.Background(), "ya29.token", PrimaryCalendar, "missing")
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsUnauthorized(err))
+}
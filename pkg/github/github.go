@@ -0,0 +1,312 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default base URLs of github.com; GitHub Enterprise Server uses its own
+const (
+	DefaultAPIURL   = "https://api.github.com"
+	DefaultOAuthURL = "https://github.com"
+)
+
+// OAuthScope is the scope requested from users, allowing to read and close issues of their
+// public and private repositories
+const OAuthScope = "repo"
+
+// Issue states
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+)
+
+// maxPages bounds the pages of issues listed at once, so a huge repository cannot stall the
+// sync. Issues are listed oldest change first, so the rest are listed by the next sync.
+const maxPages = 10
+
+// Issue is a GitHub issue. Pull requests are issues too, with PullRequest set.
+type Issue struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`
+	HTMLURL     string    `json:"html_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+// IsPullRequest reports whether the issue is a pull request
+func (i *Issue) IsPullRequest() bool {
+	return i.PullRequest != nil
+}
+
+// IssueUpdate changes an issue. Empty fields are left unchanged.
+type IssueUpdate struct {
+	Title string `json:"title,omitempty"`
+	State string `json:"state,omitempty"` // open or closed
+}
+
+// User is a GitHub user
+type User struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+// Repository is a GitHub repository, with the permissions of the authenticated user
+type Repository struct {
+	FullName    string `json:"full_name"`
+	Permissions struct {
+		Push bool `json:"push"`
+	} `json:"permissions"`
+}
+
+// Config configures a GitHub client
+type Config struct {
+	ClientID     string // OAuth app client ID
+	ClientSecret string
+	APIURL       string // defaults to DefaultAPIURL
+	OAuthURL     string // defaults to DefaultOAuthURL
+	Timeout      time.Duration
+	Transport    http.RoundTripper // defaults to http.DefaultTransport
+}
+
+// Client authorizes users through an OAuth app and calls the REST API on their behalf
+type Client interface {
+	// AuthorizeURL returns the URL users authorize the OAuth app at, redirecting back to the
+	// redirect URI with a code and the state
+	AuthorizeURL(redirectURI, state string) string
+	// ExchangeCode exchanges the code of an authorization for an access token
+	ExchangeCode(ctx context.Context, code, redirectURI string) (string, error)
+	GetUser(ctx context.Context, token string) (*User, error)
+	GetRepository(ctx context.Context, token, owner, repo string) (*Repository, error)
+	// ListIssues lists the issues and pull requests of the repository updated at or after
+	// since, least recently updated first
+	ListIssues(ctx context.Context, token, owner, repo string, since time.Time) ([]*Issue, error)
+	GetIssue(ctx context.Context, token, owner, repo string, number int) (*Issue, error)
+	UpdateIssue(ctx context.Context, token, owner, repo string, number int, update *IssueUpdate) (*Issue, error)
+}
+
+// client implements a GitHub client over HTTP
+type client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewClient creates a GitHub client
+func NewClient(cfg Config) Client {
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	if cfg.OAuthURL == "" {
+		cfg.OAuthURL = DefaultOAuthURL
+	}
+	cfg.APIURL = strings.TrimSuffix(cfg.APIURL, "/")
+	cfg.OAuthURL = strings.TrimSuffix(cfg.OAuthURL, "/")
+	return &client{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport}}
+}
+
+// AuthorizeURL returns the URL users authorize the OAuth app at
+func (c *client) AuthorizeURL(redirectURI, state string) string {
+	query := url.Values{
+		"client_id":    {c.cfg.ClientID},
+		"redirect_uri": {redirectURI},
+		"scope":        {OAuthScope},
+		"state":        {state},
+	}
+	return c.cfg.OAuthURL + "/login/oauth/authorize?" + query.Encode()
+}
+
+// ExchangeCode exchanges the code for an access token. GitHub reports invalid codes with
+// status 200 and an error in the body.
+func (c *client) ExchangeCode(ctx context.Context, code, redirectURI string) (string, error) {
+	form := url.Values{
+		"client_id":     {c.cfg.ClientID},
+		"client_secret": {c.cfg.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.OAuthURL+"/login/oauth/access_token",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid github oauth response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("github oauth error: %s %s", result.Error, result.ErrorDescription)
+	}
+	return result.AccessToken, nil
+}
+
+// GetUser returns the user the token belongs to
+func (c *client) GetUser(ctx context.Context, token string) (*User, error) {
+	var user User
+	if _, err := c.do(ctx, http.MethodGet, "/user", token, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetRepository returns the repository, with the permissions of the token's user
+func (c *client) GetRepository(ctx context.Context, token, owner, repo string) (*Repository, error) {
+	var repository Repository
+	if _, err := c.do(ctx, http.MethodGet, repoPath(owner, repo), token, nil, &repository); err != nil {
+		return nil, err
+	}
+	return &repository, nil
+}
+
+// ListIssues lists the issues and pull requests updated at or after since, following the
+// pagination links of the responses
+func (c *client) ListIssues(ctx context.Context, token, owner, repo string, since time.Time) ([]*Issue, error) {
+	query := url.Values{
+		"state":     {"all"},
+		"sort":      {"updated"},
+		"direction": {"asc"},
+		"per_page":  {"100"},
+	}
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+
+	var issues []*Issue
+	path := repoPath(owner, repo) + "/issues?" + query.Encode()
+	for page := 0; page < maxPages && path != ""; page++ {
+		var batch []*Issue
+		resp, err := c.do(ctx, http.MethodGet, path, token, nil, &batch)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, batch...)
+		path = strings.TrimPrefix(nextLink(resp.Header.Get("Link")), c.cfg.APIURL)
+	}
+	return issues, nil
+}
+
+// GetIssue returns the issue
+func (c *client) GetIssue(ctx context.Context, token, owner, repo string, number int) (*Issue, error) {
+	var issue Issue
+	path := repoPath(owner, repo) + "/issues/" + strconv.Itoa(number)
+	if _, err := c.do(ctx, http.MethodGet, path, token, nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// UpdateIssue changes the title or state of the issue
+func (c *client) UpdateIssue(ctx context.Context, token, owner, repo string, number int, update *IssueUpdate) (*Issue, error) {
+	var issue Issue
+	path := repoPath(owner, repo) + "/issues/" + strconv.Itoa(number)
+	if _, err := c.do(ctx, http.MethodPatch, path, token, update, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// do calls the REST API at the path, decoding the JSON response into out
+func (c *client) do(ctx context.Context, method, path, token string, in, out interface{}) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.APIURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var result struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: result.Message}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("invalid github response: %w", err)
+	}
+	return resp, nil
+}
+
+// repoPath returns the API path of the repository
+func repoPath(owner, repo string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+// nextLinkPattern matches the URL of the next page in a Link header
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// nextLink returns the URL of the next page of the Link header, or an empty string
+func nextLink(header string) string {
+	if match := nextLinkPattern.FindStringSubmatch(header); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// APIError is an error response of the GitHub REST API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github responded with status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsUnauthorized reports whether the error means the access token was revoked or expired,
+// so the user must authorize the app again
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// IsNotFound reports whether the error means the resource does not exist or the user may
+// not see it
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeURL(t *testing.T) {
+	c := NewClient(Config{ClientID: "client-id"})
+	u, err := url.Parse(c.AuthorizeURL("https://todo.example.com/integrations/github/callback", "state-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "github.com", u.Host)
+	assert.Equal(t, "/login/oauth/authorize", u.Path)
+	assert.Equal(t, "client-id", u.Query().Get("client_id"))
+	assert.Equal(t, "repo", u.Query().Get("scope"))
+	assert.Equal(t, "state-1", u.Query().Get("state"))
+}
+
+func TestExchangeCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/login/oauth/access_token", r.URL.Path)
+		assert.Equal(t, "secret", r.FormValue("client_secret"))
+		if r.FormValue("code") != "good-code" {
+			w.Write([]byte(`{"error":"bad_verification_code","error_description":"The code passed is incorrect or expired."}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"gho_token","token_type":"bearer","scope":"repo"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{ClientID: "client-id", ClientSecret: "secret", OAuthURL: server.URL, Timeout: time.Second})
+	token, err := c.ExchangeCode(context.Background(), "good-code", "https://todo.example.com/callback")
+	require.NoError(t, err)
+	assert.Equal(t, "gho_token", token)
+
+	_, err = c.ExchangeCode(context.Background(), "bad-code", "https://todo.example.com/callback")
+	assert.ErrorContains(t, err, "bad_verification_code")
+}
+
+func TestListIssues(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gho_token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/repos/acme/app/issues", r.URL.Path)
+		assert.Equal(t, "all", r.URL.Query().Get("state"))
+
+		if r.URL.Query().Get("page") == "" {
+			assert.Equal(t, "2026-03-01T00:00:00Z", r.URL.Query().Get("since"))
+			w.Header().Set("Link", `<`+server.URL+`/repos/acme/app/issues?state=all&page=2>; rel="next", <`+server.URL+`/repos/acme/app/issues?state=all&page=2>; rel="last"`)
+			w.Write([]byte(`[{"number":1,"title":"Crash on start","state":"open","updated_at":"2026-03-02T10:00:00Z"}]`))
+			return
+		}
+		w.Write([]byte(`[{"number":2,"title":"Fix crash","state":"closed","pull_request":{"url":"x"},"updated_at":"2026-03-03T10:00:00Z"}]`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{APIURL: server.URL, Timeout: time.Second})
+	issues, err := c.ListIssues(context.Background(), "gho_token", "acme", "app", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "Crash on start", issues[0].Title)
+	assert.False(t, issues[0].IsPullRequest())
+	assert.True(t, issues[1].IsPullRequest())
+}
+
+func TestUpdateIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		if r.Header.Get("Authorization") != "Bearer gho_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"state": "closed"}, body)
+		assert.Equal(t, "/repos/acme/app/issues/7", r.URL.Path)
+		w.Write([]byte(`{"number":7,"state":"` + body["state"] + `"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{APIURL: server.URL, Timeout: time.Second})
+	issue, err := c.UpdateIssue(context.Background(), "gho_token", "acme", "app", 7, &IssueUpdate{State: StateClosed})
+	require.NoError(t, err)
+	assert.Equal(t, StateClosed, issue.State)
+
+	_, err = c.UpdateIssue(context.Background(), "revoked", "acme", "app", 7, &IssueUpdate{State: StateClosed})
+	assert.True(t, IsUnauthorized(err))
+	assert.False(t, IsNotFound(err))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+	// Example from the GitHub webhook documentation
+	assert.Equal(t, "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+		Sign("It's a Secret to Everybody", []byte("Hello, World!")))
+
+	assert.NoError(t, VerifySignature("secret", Sign("secret", body), body))
+	assert.ErrorIs(t, VerifySignature("secret", Sign("other", body), body), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("secret", "", body), ErrInvalidSignature)
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Headers of webhook deliveries
+const (
+	EventHeader     = "X-GitHub-Event"
+	DeliveryHeader  = "X-GitHub-Delivery"
+	SignatureHeader = "X-Hub-Signature-256"
+)
+
+// Webhook events handled
+const (
+	EventPing   = "ping"
+	EventIssues = "issues"
+)
+
+// ErrInvalidSignature is returned for deliveries that were not signed with the webhook secret
+var ErrInvalidSignature = errors.New("invalid github signature")
+
+// IssuesEvent is the payload of issues events, sent when an issue is opened, edited, closed,
+// reopened, or deleted among others
+type IssuesEvent struct {
+	Action     string `json:"action"`
+	Issue      *Issue `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// VerifySignature checks the delivery body was signed with the webhook secret. The signature
+// is the hex encoded HMAC-SHA256 of the body, prefixed with "sha256=".
+func VerifySignature(secret, signature string, body []byte) error {
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the signature of the delivery body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,165 @@
+// Package inbound parses emails received through the inbound webhooks of Mailgun and Amazon
+// SES, and verifies the webhooks were sent by the provider.
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// maxPartDepth bounds the nesting of multipart bodies
+const maxPartDepth = 10
+
+// ErrInvalidSignature is returned for webhooks that were not sent by the provider
+var ErrInvalidSignature = errors.New("invalid inbound email signature")
+
+// Message is an email received by a webhook
+type Message struct {
+	Recipients  []string // addresses the email was delivered to
+	From        string   // address of the From header
+	Subject     string
+	Text        string // plain text body
+	Attachments []Attachment
+	// Authenticated reports whether the provider found that the domain of the sender passed
+	// SPF or DKIM, so the From address is not forged, and did not flag the email as spam
+	Authenticated bool
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// ParseMIME parses a raw email in RFC 5322 format. The first plain text part is the text
+// of the message, and parts with a file name are its attachments. Recipients and
+// authentication are left to the provider to set.
+func ParseMIME(raw []byte) (*Message, error) {
+	email, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+
+	from, err := mail.ParseAddress(email.Header.Get("From"))
+	if err != nil {
+		return nil, errors.New("email has no valid From address")
+	}
+
+	msg := &Message{From: from.Address, Subject: decodeHeader(email.Header.Get("Subject"))}
+	if err := msg.readPart(textproto.MIMEHeader(email.Header), email.Body, 0); err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+	return msg, nil
+}
+
+// readPart reads the text or attachment of a part of the body, or the parts of a multipart one
+func (m *Message) readPart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// Parts without a valid content type are plain text
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxPartDepth {
+			return errors.New("body is nested too deeply")
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			// Raw parts are decoded here, as the reader only decodes quoted-printable ones
+			part, err := reader.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := m.readPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return err
+	}
+
+	filename := partFilename(header, params)
+	switch {
+	case filename != "":
+		m.Attachments = append(m.Attachments, Attachment{Filename: filename, ContentType: mediaType, Data: data})
+	case mediaType == "text/plain" && m.Text == "":
+		m.Text = decodeCharset(params["charset"], data)
+	}
+	return nil
+}
+
+// partFilename returns the file name of an attached part, from its disposition or else its
+// content type, or an empty string when the part is not a file
+func partFilename(header textproto.MIMEHeader, params map[string]string) string {
+	filename := params["name"]
+	if _, dispositionParams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil &&
+		dispositionParams["filename"] != "" {
+		filename = dispositionParams["filename"]
+	}
+	return decodeHeader(filename)
+}
+
+// decodeTransfer decodes the body of a part sent with the content transfer encoding
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// Line breaks are ignored by the decoder
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// decodeCharset converts text in the charset to UTF-8, leaving text in unknown charsets as is
+func decodeCharset(charset string, data []byte) string {
+	if charset == "" || strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "us-ascii") {
+		return string(data)
+	}
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return string(data)
+	}
+	decoded, err := encoding.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// decodeHeader decodes the encoded words of a header, such as =?UTF-8?Q?Caf=C3=A9?=, leaving
+// headers that fail to decode as is
+func decodeHeader(value string) string {
+	decoder := mime.WordDecoder{CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		encoding, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return encoding.NewDecoder().Reader(input), nil
+	}}
+	decoded, err := decoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
@@ -0,0 +1,85 @@
+package inbound
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartEmail is an email with a plain text and an HTML body, and two attachments
+const multipartEmail = "From: Jane Doe <jane@example.com>\r\n" +
+	"To: add+abc@todo.example\r\n" +
+	"Subject: =?UTF-8?Q?Caf=C3=A9_order?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Two caf=E9s, please.\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Two caf\xc3\xa9s, please.</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"Content-Disposition: attachment; filename=\"menu.txt\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"ZXNw\r\n" +
+	"cmVzc28=\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png; name=\"=?UTF-8?B?Y2Fmw6kucG5n?=\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw==\r\n" +
+	"--outer--\r\n"
+
+func TestParseMIME(t *testing.T) {
+	msg, err := ParseMIME([]byte(multipartEmail))
+	require.NoError(t, err)
+
+	assert.Equal(t, "jane@example.com", msg.From)
+	assert.Equal(t, "Café order", msg.Subject)
+	assert.Equal(t, "Two cafés, please.", strings.TrimSpace(msg.Text))
+	require.Len(t, msg.Attachments, 2)
+	assert.Equal(t, Attachment{Filename: "menu.txt", ContentType: "text/plain", Data: []byte("espresso")}, msg.Attachments[0])
+	assert.Equal(t, "café.png", msg.Attachments[1].Filename)
+	assert.Equal(t, "image/png", msg.Attachments[1].ContentType)
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, msg.Attachments[1].Data)
+
+	// Recipients and authentication are set by the provider
+	assert.Empty(t, msg.Recipients)
+	assert.False(t, msg.Authenticated)
+}
+
+func TestParseMIMEPlainText(t *testing.T) {
+	msg, err := ParseMIME([]byte("From: jane@example.com\r\nSubject: Buy milk\r\n\r\nSemi-skimmed\r\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Buy milk", msg.Subject)
+	assert.Equal(t, "Semi-skimmed\r\n", msg.Text)
+	assert.Empty(t, msg.Attachments)
+}
+
+func TestParseMIMEInvalid(t *testing.T) {
+	_, err := ParseMIME([]byte("not an email"))
+	assert.Error(t, err)
+
+	_, err = ParseMIME([]byte("Subject: Buy milk\r\n\r\nNo sender\r\n"))
+	assert.EqualError(t, err, "email has no valid From address")
+
+	nested := "From: jane@example.com\r\nContent-Type: multipart/mixed; boundary=b\r\n\r\n"
+	for range maxPartDepth + 1 {
+		nested += "--b\r\nContent-Type: multipart/mixed; boundary=b\r\n\r\n"
+	}
+	_, err = ParseMIME([]byte(nested))
+	assert.Error(t, err)
+}
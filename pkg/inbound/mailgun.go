@@ -0,0 +1,145 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRequestAge is how old a signed Mailgun webhook may be before it is rejected as a
+// possible replay
+const MaxRequestAge = 5 * time.Minute
+
+// mailgunAttachmentPrefix prefixes the names of the files of attachments, numbered from 1
+const mailgunAttachmentPrefix = "attachment-"
+
+// VerifyMailgunSignature checks the webhook was signed with the HTTP webhook signing key of
+// the Mailgun account and is recent. The signature is the hex HMAC-SHA256 of the timestamp
+// followed by the token.
+func VerifyMailgunSignature(signingKey, timestamp, token, signature string, now time.Time) error {
+	if signingKey == "" {
+		return ErrInvalidSignature
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > MaxRequestAge || age < -MaxRequestAge {
+		return errors.New("mailgun webhook timestamp is too old")
+	}
+
+	expected := SignMailgun(signingKey, timestamp, token)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignMailgun returns the signature of a webhook with the timestamp and token
+func SignMailgun(signingKey, timestamp, token string) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseMailgun parses an email forwarded by a Mailgun route, posted as form fields with its
+// attachments as files. Mailgun checks SPF and DKIM and flags spam in headers it adds to the
+// email.
+func ParseMailgun(values url.Values, files map[string][]*multipart.FileHeader) (*Message, error) {
+	from, err := mail.ParseAddress(values.Get("from"))
+	if err != nil {
+		return nil, errors.New("email has no valid From address")
+	}
+
+	msg := &Message{
+		From:    from.Address,
+		Subject: values.Get("subject"),
+		Text:    values.Get("body-plain"),
+	}
+	for _, recipient := range strings.Split(values.Get("recipient"), ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			msg.Recipients = append(msg.Recipients, recipient)
+		}
+	}
+
+	// Headers are a JSON array of name and value pairs
+	var headers [][2]string
+	if raw := values.Get("message-headers"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+			return nil, errors.New("invalid message-headers")
+		}
+	}
+	var spf, dkim, spam string
+	for _, header := range headers {
+		switch strings.ToLower(header[0]) {
+		case "x-mailgun-spf":
+			spf = header[1]
+		case "x-mailgun-dkim-check-result":
+			dkim = header[1]
+		case "x-mailgun-sflag":
+			spam = header[1]
+		}
+	}
+	msg.Authenticated = (strings.EqualFold(spf, "pass") || strings.EqualFold(dkim, "pass")) &&
+		!strings.EqualFold(spam, "yes")
+
+	attachments, err := readMailgunAttachments(files)
+	if err != nil {
+		return nil, err
+	}
+	msg.Attachments = attachments
+	return msg, nil
+}
+
+// readMailgunAttachments reads the attachment files in the order they are numbered
+func readMailgunAttachments(files map[string][]*multipart.FileHeader) ([]Attachment, error) {
+	type numbered struct {
+		n      int
+		header *multipart.FileHeader
+	}
+	var found []numbered
+	for name, headers := range files {
+		n, err := strconv.Atoi(strings.TrimPrefix(name, mailgunAttachmentPrefix))
+		if !strings.HasPrefix(name, mailgunAttachmentPrefix) || err != nil || len(headers) == 0 {
+			continue
+		}
+		found = append(found, numbered{n: n, header: headers[0]})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].n < found[j].n })
+
+	attachments := make([]Attachment, 0, len(found))
+	for _, f := range found {
+		file, err := f.header.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		contentType := f.header.Header.Get("Content-Type")
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			contentType = mediaType
+		}
+		attachments = append(attachments, Attachment{
+			Filename:    decodeHeader(f.header.Filename),
+			ContentType: contentType,
+			Data:        data,
+		})
+	}
+	return attachments, nil
+}
@@ -0,0 +1,97 @@
+package inbound
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyMailgunSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := SignMailgun("key", timestamp, "token")
+
+	assert.NoError(t, VerifyMailgunSignature("key", timestamp, "token", signature, now))
+	assert.NoError(t, VerifyMailgunSignature("key", timestamp, "token", signature, now.Add(MaxRequestAge)))
+	assert.Error(t, VerifyMailgunSignature("key", timestamp, "token", signature, now.Add(MaxRequestAge+time.Second)))
+	assert.ErrorIs(t, VerifyMailgunSignature("other", timestamp, "token", signature, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyMailgunSignature("key", timestamp, "other", signature, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyMailgunSignature("", timestamp, "token", SignMailgun("", timestamp, "token"), now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyMailgunSignature("key", "yesterday", "token", signature, now), ErrInvalidSignature)
+}
+
+func TestParseMailgun(t *testing.T) {
+	// Mailgun posts emails with attachments as a multipart form
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	fields := map[string]string{
+		"recipient":       "add+abc@todo.example",
+		"from":            "Jane Doe <jane@example.com>",
+		"subject":         "Buy milk",
+		"body-plain":      "Semi-skimmed",
+		"message-headers": `[["X-Mailgun-Spf","Pass"],["X-Mailgun-Dkim-Check-Result","Fail"],["X-Mailgun-Sflag","No"]]`,
+	}
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	for _, n := range []string{"2", "1"} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="attachment-`+n+`"; filename="list-`+n+`.txt"`)
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		part, err := writer.CreatePart(header)
+		require.NoError(t, err)
+		part.Write([]byte("file " + n))
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(1<<20))
+
+	msg, err := ParseMailgun(req.MultipartForm.Value, req.MultipartForm.File)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"add+abc@todo.example"}, msg.Recipients)
+	assert.Equal(t, "jane@example.com", msg.From)
+	assert.Equal(t, "Buy milk", msg.Subject)
+	assert.Equal(t, "Semi-skimmed", msg.Text)
+	assert.True(t, msg.Authenticated)
+	assert.Equal(t, []Attachment{
+		{Filename: "list-1.txt", ContentType: "text/plain", Data: []byte("file 1")},
+		{Filename: "list-2.txt", ContentType: "text/plain", Data: []byte("file 2")},
+	}, msg.Attachments)
+}
+
+func TestParseMailgunAuthentication(t *testing.T) {
+	tests := []struct {
+		name          string
+		headers       string
+		authenticated bool
+	}{
+		{"dkim", `[["X-Mailgun-Dkim-Check-Result","Pass"]]`, true},
+		{"spf and dkim failed", `[["X-Mailgun-Spf","Fail"],["X-Mailgun-Dkim-Check-Result","Fail"]]`, false},
+		{"spam", `[["X-Mailgun-Spf","Pass"],["X-Mailgun-Sflag","Yes"]]`, false},
+		{"no headers", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Emails without attachments are posted as a URL encoded form
+			values := url.Values{"from": {"jane@example.com"}, "message-headers": {tt.headers}}
+			msg, err := ParseMailgun(values, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.authenticated, msg.Authenticated)
+		})
+	}
+
+	_, err := ParseMailgun(url.Values{"from": {"jane"}}, nil)
+	assert.EqualError(t, err, "email has no valid From address")
+	_, err = ParseMailgun(url.Values{"from": {"jane@example.com"}, "message-headers": {"{"}}, nil)
+	assert.EqualError(t, err, "invalid message-headers")
+}
@@ -0,0 +1,266 @@
+package inbound
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Types of the messages SNS posts to HTTPS subscriptions
+const (
+	SNSTypeNotification             = "Notification"
+	SNSTypeSubscriptionConfirmation = "SubscriptionConfirmation"
+	SNSTypeUnsubscribeConfirmation  = "UnsubscribeConfirmation"
+)
+
+// SNSMessageTypeHeader is the header of requests posted by SNS naming the type of their message
+const SNSMessageTypeHeader = "X-Amz-Sns-Message-Type"
+
+// maxCertificateSize bounds the size of signing certificates downloaded from SNS
+const maxCertificateSize = 64 * 1024
+
+// snsHostPattern matches the hosts of SNS, which serves its signing certificates and
+// subscription confirmations
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SNSMessage is a message SNS posts to an HTTPS subscription of a topic: a notification
+// published to the topic, or a request to confirm the subscription
+type SNSMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token,omitempty"`
+	TopicARN         string `json:"TopicArn"`
+	Subject          string `json:"Subject,omitempty"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL,omitempty"`
+}
+
+// ParseSNSMessage parses the body of a request posted by SNS
+func ParseSNSMessage(body []byte) (*SNSMessage, error) {
+	var msg SNSMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, errors.New("invalid SNS message")
+	}
+	if msg.Type == "" || msg.TopicARN == "" {
+		return nil, errors.New("invalid SNS message")
+	}
+	return &msg, nil
+}
+
+// stringToSign returns the fields of the message SNS signs, as name and value lines
+func (m *SNSMessage) stringToSign() string {
+	fields := [][2]string{{"Message", m.Message}, {"MessageId", m.MessageID}}
+	if m.Type == SNSTypeNotification {
+		if m.Subject != "" {
+			fields = append(fields, [2]string{"Subject", m.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", m.Timestamp}, [2]string{"TopicArn", m.TopicARN})
+	} else {
+		fields = append(fields, [2]string{"SubscribeURL", m.SubscribeURL}, [2]string{"Timestamp", m.Timestamp},
+			[2]string{"Token", m.Token}, [2]string{"TopicArn", m.TopicARN})
+	}
+	fields = append(fields, [2]string{"Type", m.Type})
+
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(field[0] + "\n" + field[1] + "\n")
+	}
+	return b.String()
+}
+
+// SNSVerifier verifies the signatures of SNS messages with the certificates of SNS, which
+// it downloads once, and confirms subscriptions
+type SNSVerifier struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate // by URL
+}
+
+// NewSNSVerifier creates a verifier downloading certificates with the client
+func NewSNSVerifier(client *http.Client) *SNSVerifier {
+	return &SNSVerifier{client: client, certs: make(map[string]*x509.Certificate)}
+}
+
+// Verify checks the message was signed by SNS, with the certificate of the URL it names,
+// which must be served by SNS over HTTPS
+func (v *SNSVerifier) Verify(ctx context.Context, msg *SNSMessage) error {
+	var hash crypto.Hash
+	switch msg.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return ErrInvalidSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	cert, err := v.certificate(ctx, msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(msg.stringToSign()))
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(msg.stringToSign()))
+		digest = sum[:]
+	}
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ConfirmSubscription confirms the subscription the verified message asks to confirm, by
+// visiting its subscribe URL
+func (v *SNSVerifier) ConfirmSubscription(ctx context.Context, msg *SNSMessage) error {
+	if msg.Type != SNSTypeSubscriptionConfirmation {
+		return errors.New("SNS message is not a subscription confirmation")
+	}
+	if !isSNSURL(msg.SubscribeURL) {
+		return errors.New("SNS subscribe URL is not served by SNS")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, msg.SubscribeURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SNS responded to the subscription confirmation with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// certificate returns the signing certificate of the URL, downloading it the first time
+func (v *SNSVerifier) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	v.mu.Lock()
+	cert, ok := v.certs[certURL]
+	v.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	if !isSNSURL(certURL) || !strings.HasSuffix(certURL, ".pem") {
+		return nil, ErrInvalidSignature
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download SNS certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SNS responded to the certificate download with status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCertificateSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download SNS certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("SNS certificate is not PEM encoded")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SNS certificate: %w", err)
+	}
+
+	v.mu.Lock()
+	v.certs[certURL] = cert
+	v.mu.Unlock()
+	return cert, nil
+}
+
+// isSNSURL reports whether the URL is served by SNS over HTTPS
+func isSNSURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "https" && snsHostPattern.MatchString(u.Host)
+}
+
+// sesVerdict is the result of a check SES ran on a received email
+type sesVerdict struct {
+	Status string `json:"status"` // PASS, FAIL, GRAY, or PROCESSING_FAILED
+}
+
+// sesNotification is the notification SES publishes for an email received by a receipt rule
+// with an SNS action
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Receipt          struct {
+		Recipients   []string   `json:"recipients"`
+		SPFVerdict   sesVerdict `json:"spfVerdict"`
+		DKIMVerdict  sesVerdict `json:"dkimVerdict"`
+		SpamVerdict  sesVerdict `json:"spamVerdict"`
+		VirusVerdict sesVerdict `json:"virusVerdict"`
+	} `json:"receipt"`
+	Content string `json:"content"` // raw email, encoded as the SNS action is configured
+}
+
+// ParseSES parses the email received by SES from the message of an SNS notification. The
+// SNS action includes the raw email in the notification, either as UTF-8 or base64 encoded.
+func ParseSES(message string) (*Message, error) {
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(message), &notification); err != nil {
+		return nil, errors.New("invalid SES notification")
+	}
+	if notification.NotificationType != "Received" {
+		return nil, fmt.Errorf("SES notification is not about a received email: %q", notification.NotificationType)
+	}
+	if notification.Content == "" {
+		return nil, errors.New("SES notification does not include the email")
+	}
+
+	// Raw emails contain characters outside the base64 alphabet, such as colons
+	raw := []byte(notification.Content)
+	if decoded, err := base64.StdEncoding.DecodeString(notification.Content); err == nil {
+		raw = decoded
+	}
+	msg, err := ParseMIME(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := notification.Receipt
+	msg.Recipients = receipt.Recipients
+	msg.Authenticated = (receipt.SPFVerdict.Status == "PASS" || receipt.DKIMVerdict.Status == "PASS") &&
+		receipt.SpamVerdict.Status != "FAIL" && receipt.VirusVerdict.Status != "FAIL"
+	return msg, nil
+}
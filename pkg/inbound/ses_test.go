@@ -0,0 +1,188 @@
+package inbound
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// certURL is the URL SNS serves its signing certificate at in the tests
+const certURL = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-test.pem"
+
+// roundTripFunc serves the requests of a client
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// fakeSNS signs messages as SNS does, and serves its certificate to the client
+type fakeSNS struct {
+	key      *rsa.PrivateKey
+	certPEM  []byte
+	requests []string // URLs requested by the client
+}
+
+func newFakeSNS(t *testing.T) *fakeSNS {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return &fakeSNS{key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (s *fakeSNS) client() *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		s.requests = append(s.requests, req.URL.String())
+		body := "ok"
+		if req.URL.String() == certURL {
+			body = string(s.certPEM)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+}
+
+// sign signs the message with signature version 2
+func (s *fakeSNS) sign(t *testing.T, msg *SNSMessage) {
+	msg.SignatureVersion = "2"
+	msg.SigningCertURL = certURL
+	digest := sha256.Sum256([]byte(msg.stringToSign()))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	msg.Signature = base64.StdEncoding.EncodeToString(signature)
+}
+
+func TestSNSVerifier(t *testing.T) {
+	sns := newFakeSNS(t)
+	verifier := NewSNSVerifier(sns.client())
+	ctx := context.Background()
+
+	msg := &SNSMessage{
+		Type:      SNSTypeNotification,
+		MessageID: "1",
+		TopicARN:  "arn:aws:sns:us-east-1:123456789012:inbound",
+		Subject:   "Amazon SES Email Receipt Notification",
+		Message:   "{}",
+		Timestamp: "2026-10-16T12:00:00.000Z",
+	}
+	sns.sign(t, msg)
+	require.NoError(t, verifier.Verify(ctx, msg))
+	require.NoError(t, verifier.Verify(ctx, msg))
+	// The certificate is downloaded once
+	assert.Equal(t, []string{certURL}, sns.requests)
+
+	tampered := *msg
+	tampered.Message = `{"tampered":true}`
+	assert.ErrorIs(t, verifier.Verify(ctx, &tampered), ErrInvalidSignature)
+
+	unknownVersion := *msg
+	unknownVersion.SignatureVersion = "3"
+	assert.ErrorIs(t, verifier.Verify(ctx, &unknownVersion), ErrInvalidSignature)
+
+	// Certificates are only downloaded from SNS
+	for _, url := range []string{
+		"https://attacker.example/cert.pem",
+		"http://sns.us-east-1.amazonaws.com/cert.pem",
+		"https://sns.us-east-1.amazonaws.com.attacker.example/cert.pem",
+	} {
+		forged := *msg
+		forged.SigningCertURL = url
+		assert.ErrorIs(t, verifier.Verify(ctx, &forged), ErrInvalidSignature, url)
+	}
+	assert.Len(t, sns.requests, 1)
+}
+
+func TestSNSVerifierConfirmSubscription(t *testing.T) {
+	sns := newFakeSNS(t)
+	verifier := NewSNSVerifier(sns.client())
+	ctx := context.Background()
+
+	body, err := json.Marshal(map[string]string{
+		"Type":         SNSTypeSubscriptionConfirmation,
+		"MessageId":    "1",
+		"Token":        "token",
+		"TopicArn":     "arn:aws:sns:us-east-1:123456789012:inbound",
+		"Message":      "You have chosen to subscribe to the topic.",
+		"SubscribeURL": "https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription&Token=token",
+		"Timestamp":    "2026-10-16T12:00:00.000Z",
+	})
+	require.NoError(t, err)
+	msg, err := ParseSNSMessage(body)
+	require.NoError(t, err)
+	sns.sign(t, msg)
+
+	require.NoError(t, verifier.Verify(ctx, msg))
+	require.NoError(t, verifier.ConfirmSubscription(ctx, msg))
+	assert.Equal(t, []string{certURL, msg.SubscribeURL}, sns.requests)
+
+	forged := *msg
+	forged.SubscribeURL = "https://attacker.example/"
+	assert.Error(t, verifier.ConfirmSubscription(ctx, &forged))
+
+	_, err = ParseSNSMessage([]byte(`{"Type":"Notification"}`))
+	assert.Error(t, err)
+}
+
+func TestParseSES(t *testing.T) {
+	email := "From: jane@example.com\r\nSubject: Buy milk\r\n\r\nSemi-skimmed\r\n"
+	notification := func(content, spf, dkim, spam string) string {
+		data, err := json.Marshal(map[string]any{
+			"notificationType": "Received",
+			"receipt": map[string]any{
+				"recipients":   []string{"add+abc@todo.example"},
+				"spfVerdict":   map[string]string{"status": spf},
+				"dkimVerdict":  map[string]string{"status": dkim},
+				"spamVerdict":  map[string]string{"status": spam},
+				"virusVerdict": map[string]string{"status": "PASS"},
+			},
+			"content": content,
+		})
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	// The raw email is included as UTF-8 or base64 encoded
+	for _, content := range []string{email, base64.StdEncoding.EncodeToString([]byte(email))} {
+		msg, err := ParseSES(notification(content, "PASS", "FAIL", "PASS"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"add+abc@todo.example"}, msg.Recipients)
+		assert.Equal(t, "jane@example.com", msg.From)
+		assert.Equal(t, "Buy milk", msg.Subject)
+		assert.Equal(t, "Semi-skimmed\r\n", msg.Text)
+		assert.True(t, msg.Authenticated)
+	}
+
+	msg, err := ParseSES(notification(email, "FAIL", "GRAY", "PASS"))
+	require.NoError(t, err)
+	assert.False(t, msg.Authenticated)
+	msg, err = ParseSES(notification(email, "PASS", "PASS", "FAIL"))
+	require.NoError(t, err)
+	assert.False(t, msg.Authenticated)
+
+	_, err = ParseSES(notification("", "PASS", "PASS", "PASS"))
+	assert.EqualError(t, err, "SES notification does not include the email")
+	_, err = ParseSES(`{"notificationType":"Bounce"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BER tags of the universal types LDAP messages are built from
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+)
+
+// maxMessageSize bounds the size of messages read from the server
+const maxMessageSize = 1 << 20
+
+var errMalformed = errors.New("ldap: malformed message")
+
+// element encodes a BER element of the tag with the contents
+func element(tag byte, contents ...[]byte) []byte {
+	var value []byte
+	for _, c := range contents {
+		value = append(value, c...)
+	}
+	return append(append([]byte{tag}, encodeLength(len(value))...), value...)
+}
+
+// encodeLength encodes a BER length in the short form below 128 and the long form above
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for ; n > 0; n >>= 8 {
+		digits = append([]byte{byte(n)}, digits...)
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
+
+// integer encodes a non-negative integer with the tag, such as an INTEGER or ENUMERATED
+func integer(tag byte, n int) []byte {
+	value := []byte{byte(n)}
+	for n >>= 8; n > 0; n >>= 8 {
+		value = append([]byte{byte(n)}, value...)
+	}
+	// Keep the number from reading as a negative one
+	if value[0]&0x80 != 0 {
+		value = append([]byte{0}, value...)
+	}
+	return element(tag, value)
+}
+
+// octetString encodes a string with the tag, such as an OCTET STRING
+func octetString(tag byte, s string) []byte {
+	return element(tag, []byte(s))
+}
+
+// boolean encodes a BOOLEAN
+func boolean(b bool) []byte {
+	if b {
+		return element(tagBoolean, []byte{0xff})
+	}
+	return element(tagBoolean, []byte{0x00})
+}
+
+// readElement reads a BER element from the reader, returning its tag and contents
+func readElement(r *bufio.Reader) (byte, []byte, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length := int(first)
+	if first&0x80 != 0 {
+		digits := int(first &^ 0x80)
+		if digits == 0 || digits > 4 {
+			return 0, nil, errMalformed
+		}
+		length = 0
+		for i := 0; i < digits; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxMessageSize {
+		return 0, nil, fmt.Errorf("ldap: message of %d bytes is too large", length)
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return 0, nil, err
+	}
+	return tag, value, nil
+}
+
+// parseElement splits the first BER element off the data, returning its tag and contents
+// and the data following it
+func parseElement(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errMalformed
+	}
+	tag, first := data[0], data[1]
+	data = data[2:]
+
+	length := int(first)
+	if first&0x80 != 0 {
+		digits := int(first &^ 0x80)
+		if digits == 0 || digits > 4 || len(data) < digits {
+			return 0, nil, nil, errMalformed
+		}
+		length = 0
+		for _, b := range data[:digits] {
+			length = length<<8 | int(b)
+		}
+		data = data[digits:]
+	}
+	if length > len(data) {
+		return 0, nil, nil, errMalformed
+	}
+	return tag, data[:length], data[length:], nil
+}
+
+// parseInteger decodes the contents of an INTEGER or ENUMERATED
+func parseInteger(value []byte) (int, error) {
+	if len(value) == 0 || len(value) > 4 {
+		return 0, errMalformed
+	}
+	n := int(int8(value[0]))
+	for _, b := range value[1:] {
+		n = n<<8 | int(b)
+	}
+	return n, nil
+}
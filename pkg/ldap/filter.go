@@ -0,0 +1,232 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// BER tags of search filters
+const (
+	filterAnd       = 0xa0
+	filterOr        = 0xa1
+	filterNot       = 0xa2
+	filterEquality  = 0xa3
+	filterSubstring = 0xa4
+	filterGreater   = 0xa5 // greaterOrEqual
+	filterLess      = 0xa6 // lessOrEqual
+	filterPresent   = 0x87
+	filterApprox    = 0xa8
+
+	substringInitial = 0x80
+	substringAny     = 0x81
+	substringFinal   = 0x82
+)
+
+// EscapeFilter escapes a value for use in a search filter, so user input cannot change the
+// meaning of the filter
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// CompileFilter encodes a search filter in its string representation, such as
+// (&(objectClass=person)(mail=jane@example.com)), in the form sent to the server. Extensible
+// matches are not supported.
+func CompileFilter(filter string) ([]byte, error) {
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+	p := &filterParser{input: filter}
+	encoded, err := p.parseFilter()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	return encoded, nil
+}
+
+// filterParser parses the string representation of search filters
+type filterParser struct {
+	input string
+	pos   int
+}
+
+// parseFilter parses a parenthesized filter
+func (p *filterParser) parseFilter() ([]byte, error) {
+	if !p.consume('(') {
+		return nil, p.errorf("expected (")
+	}
+	if p.pos == len(p.input) {
+		return nil, p.errorf("unexpected end")
+	}
+
+	var encoded []byte
+	var err error
+	switch p.input[p.pos] {
+	case '&':
+		p.pos++
+		encoded, err = p.parseList(filterAnd)
+	case '|':
+		p.pos++
+		encoded, err = p.parseList(filterOr)
+	case '!':
+		p.pos++
+		var inner []byte
+		if inner, err = p.parseFilter(); err == nil {
+			encoded = element(filterNot, inner)
+		}
+	default:
+		encoded, err = p.parseItem()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.consume(')') {
+		return nil, p.errorf("expected )")
+	}
+	return encoded, nil
+}
+
+// parseList parses the filters of an and or or filter
+func (p *filterParser) parseList(tag byte) ([]byte, error) {
+	var filters [][]byte
+	for p.pos < len(p.input) && p.input[p.pos] == '(' {
+		f, err := p.parseFilter()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	if len(filters) == 0 {
+		return nil, p.errorf("expected at least one filter")
+	}
+	return element(tag, filters...), nil
+}
+
+// parseItem parses a comparison such as mail=jane@example.com, up to the closing parenthesis
+func (p *filterParser) parseItem() ([]byte, error) {
+	end := strings.IndexByte(p.input[p.pos:], ')')
+	if end < 0 {
+		return nil, p.errorf("expected )")
+	}
+	item := p.input[p.pos : p.pos+end]
+
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, p.errorf("invalid comparison %q", item)
+	}
+	attr, value := item[:eq], item[eq+1:]
+
+	tag := byte(filterEquality)
+	switch attr[len(attr)-1] {
+	case '~':
+		tag = filterApprox
+	case '>':
+		tag = filterGreater
+	case '<':
+		tag = filterLess
+	}
+	if tag != filterEquality {
+		attr = attr[:len(attr)-1]
+	}
+	if attr == "" || strings.ContainsAny(attr, ":()*\\") {
+		return nil, p.errorf("unsupported attribute %q", attr)
+	}
+
+	var encoded []byte
+	switch {
+	case tag == filterEquality && value == "*":
+		encoded = octetString(filterPresent, attr)
+	case tag == filterEquality && strings.Contains(value, "*"):
+		substrings, err := p.parseSubstrings(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded = element(filterSubstring, octetString(tagOctetString, attr), element(tagSequence, substrings...))
+	default:
+		decoded, err := p.unescape(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded = element(tag, octetString(tagOctetString, attr), octetString(tagOctetString, decoded))
+	}
+
+	p.pos += end
+	return encoded, nil
+}
+
+// parseSubstrings parses the parts of a value with wildcards such as jane*@example.*
+func (p *filterParser) parseSubstrings(value string) ([][]byte, error) {
+	parts := strings.Split(value, "*")
+	var substrings [][]byte
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		decoded, err := p.unescape(part)
+		if err != nil {
+			return nil, err
+		}
+		tag := byte(substringAny)
+		if i == 0 {
+			tag = substringInitial
+		} else if i == len(parts)-1 {
+			tag = substringFinal
+		}
+		substrings = append(substrings, octetString(tag, decoded))
+	}
+	if len(substrings) == 0 {
+		return nil, p.errorf("invalid substring value %q", value)
+	}
+	return substrings, nil
+}
+
+// unescape decodes the \XX escapes of a value
+func (p *filterParser) unescape(value string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\':
+			if i+2 >= len(value) {
+				return "", p.errorf("invalid escape in %q", value)
+			}
+			decoded, err := hex.DecodeString(value[i+1 : i+3])
+			if err != nil {
+				return "", p.errorf("invalid escape in %q", value)
+			}
+			b.Write(decoded)
+			i += 2
+		case '(', '*':
+			return "", p.errorf("unescaped %q in %q", c, value)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// consume skips the character if it is next
+func (p *filterParser) consume(c byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// errorf returns an error about the filter
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("ldap: invalid filter %q: %s", p.input, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,369 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// UsernamePlaceholder is replaced with the escaped username in user filters
+const UsernamePlaceholder = "{username}"
+
+// Result codes of LDAP operations
+const (
+	ResultSuccess            = 0
+	ResultSizeLimitExceeded  = 4
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+)
+
+// BER tags of the protocol operations
+const (
+	opBindRequest      = 0x60
+	opBindResponse     = 0x61
+	opUnbindRequest    = 0x42
+	opSearchRequest    = 0x63
+	opSearchEntry      = 0x64
+	opSearchDone       = 0x65
+	opSearchReference  = 0x73
+	opExtendedRequest  = 0x77
+	opExtendedResponse = 0x78
+
+	protocolVersion = 3
+	authSimple      = 0x80
+	extendedName    = 0x80
+	scopeSubtree    = 2
+	derefNever      = 0
+	noAttributes    = "1.1"
+	startTLSRequest = "1.3.6.1.4.1.1466.20037"
+)
+
+// ErrInvalidCredentials is returned when the username or password is wrong
+var ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+
+// ResultError is an LDAP operation that did not succeed
+type ResultError struct {
+	Code    int
+	Message string
+}
+
+func (e *ResultError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.Code)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.Code, e.Message)
+}
+
+// Config holds the directory users are authenticated against
+type Config struct {
+	URL          string // ldap://host:389 or ldaps://host:636
+	BindDN       string // account searching for users; searches are anonymous when empty
+	BindPassword string
+	BaseDN       string // entry users are searched under
+	UserFilter   string // filter finding a user, with {username} in place of the username
+	StartTLS     bool   // upgrade ldap:// connections to TLS before binding
+	TLSConfig    *tls.Config
+	Timeout      time.Duration
+}
+
+// Client authenticates users against a directory such as OpenLDAP or Active Directory
+type Client interface {
+	// Authenticate finds the user's entry and binds as it with the password, returning the
+	// DN of the entry
+	Authenticate(ctx context.Context, username, password string) (string, error)
+}
+
+// client implements Client over the LDAPv3 protocol
+type client struct {
+	config Config
+}
+
+// NewClient creates a client of the directory
+func NewClient(cfg Config) Client {
+	return &client{config: cfg}
+}
+
+// Authenticate searches for the user's entry with the user filter, as the bind account or
+// anonymously, then binds as the entry with the password. A connection is opened per call.
+func (c *client) Authenticate(ctx context.Context, username, password string) (string, error) {
+	// Servers treat simple binds without a password as anonymous binds, which succeed
+	if username == "" || password == "" {
+		return "", ErrInvalidCredentials
+	}
+
+	filter, err := CompileFilter(strings.ReplaceAll(c.config.UserFilter, UsernamePlaceholder, EscapeFilter(username)))
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.close()
+
+	if c.config.BindDN != "" {
+		if err := conn.bind(c.config.BindDN, c.config.BindPassword); err != nil {
+			return "", fmt.Errorf("ldap: bind as %s: %w", c.config.BindDN, err)
+		}
+	}
+
+	dns, err := conn.search(c.config.BaseDN, filter)
+	if err != nil {
+		return "", err
+	}
+	switch len(dns) {
+	case 0:
+		return "", ErrInvalidCredentials
+	case 1:
+	default:
+		return "", fmt.Errorf("ldap: user filter matched %d entries", len(dns))
+	}
+
+	if err := conn.bind(dns[0], password); err != nil {
+		var resultErr *ResultError
+		if errors.As(err, &resultErr) && resultErr.Code == ResultInvalidCredentials {
+			return "", ErrInvalidCredentials
+		}
+		return "", err
+	}
+	return dns[0], nil
+}
+
+// dial connects to the directory, over TLS for ldaps:// URLs or after StartTLS when enabled
+func (c *client) dial(ctx context.Context) (*conn, error) {
+	u, err := url.Parse(c.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid URL: %w", err)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		port := "389"
+		if u.Scheme == "ldaps" {
+			port = "636"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	deadline := time.Now().Add(c.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+
+	tlsConfig := c.config.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig = tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	var netConn net.Conn
+	if u.Scheme == "ldaps" {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", host)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+	if err := netConn.SetDeadline(deadline); err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+
+	cn := newConn(netConn)
+	if c.config.StartTLS && u.Scheme != "ldaps" {
+		if err := cn.startTLS(tlsConfig, deadline); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// conn is a connection to the directory, sending one request at a time
+type conn struct {
+	netConn   net.Conn
+	reader    *bufio.Reader
+	messageID int
+}
+
+func newConn(netConn net.Conn) *conn {
+	return &conn{netConn: netConn, reader: bufio.NewReader(netConn)}
+}
+
+// bind authenticates the connection as the DN with a simple bind
+func (c *conn) bind(dn, password string) error {
+	id, err := c.send(element(opBindRequest,
+		integer(tagInteger, protocolVersion),
+		octetString(tagOctetString, dn),
+		octetString(authSimple, password),
+	))
+	if err != nil {
+		return err
+	}
+
+	op, value, err := c.receive(id)
+	if err != nil {
+		return err
+	}
+	if op != opBindResponse {
+		return errMalformed
+	}
+	return parseResult(value)
+}
+
+// search returns the DNs of the entries under the base DN matching the filter. At most two
+// entries are asked for, to tell whether the filter is ambiguous.
+func (c *conn) search(baseDN string, filter []byte) ([]string, error) {
+	id, err := c.send(element(opSearchRequest,
+		octetString(tagOctetString, baseDN),
+		integer(tagEnumerated, scopeSubtree),
+		integer(tagEnumerated, derefNever),
+		integer(tagInteger, 2), // size limit
+		integer(tagInteger, 0), // time limit, bounded by the connection deadline instead
+		boolean(false),         // types only
+		filter,
+		element(tagSequence, octetString(tagOctetString, noAttributes)),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var dns []string
+	for {
+		op, value, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case opSearchEntry:
+			tag, dn, _, err := parseElement(value)
+			if err != nil || tag != tagOctetString {
+				return nil, errMalformed
+			}
+			dns = append(dns, string(dn))
+		case opSearchReference:
+			// Referrals to other servers are not followed
+		case opSearchDone:
+			err := parseResult(value)
+			var resultErr *ResultError
+			if errors.As(err, &resultErr) && (resultErr.Code == ResultSizeLimitExceeded || resultErr.Code == ResultNoSuchObject) {
+				err = nil
+			}
+			return dns, err
+		default:
+			return nil, errMalformed
+		}
+	}
+}
+
+// startTLS upgrades the connection to TLS with the StartTLS extended operation
+func (c *conn) startTLS(tlsConfig *tls.Config, deadline time.Time) error {
+	id, err := c.send(element(opExtendedRequest, octetString(extendedName, startTLSRequest)))
+	if err != nil {
+		return err
+	}
+	op, value, err := c.receive(id)
+	if err != nil {
+		return err
+	}
+	if op != opExtendedResponse {
+		return errMalformed
+	}
+	if err := parseResult(value); err != nil {
+		return fmt.Errorf("ldap: StartTLS: %w", err)
+	}
+
+	tlsConn := tls.Client(c.netConn, tlsConfig)
+	if err := tlsConn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("ldap: %w", err)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		return fmt.Errorf("ldap: StartTLS: %w", err)
+	}
+	c.netConn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// close unbinds and closes the connection
+func (c *conn) close() {
+	c.send(element(opUnbindRequest))
+	c.netConn.Close()
+}
+
+// send sends a request as the next message, returning its message ID
+func (c *conn) send(op []byte) (int, error) {
+	c.messageID++
+	if _, err := c.netConn.Write(element(tagSequence, integer(tagInteger, c.messageID), op)); err != nil {
+		return 0, fmt.Errorf("ldap: %w", err)
+	}
+	return c.messageID, nil
+}
+
+// receive reads the next response to the message, returning its operation tag and contents
+func (c *conn) receive(id int) (byte, []byte, error) {
+	for {
+		tag, message, err := readElement(c.reader)
+		if err != nil {
+			return 0, nil, fmt.Errorf("ldap: %w", err)
+		}
+		if tag != tagSequence {
+			return 0, nil, errMalformed
+		}
+
+		tag, value, rest, err := parseElement(message)
+		if err != nil || tag != tagInteger {
+			return 0, nil, errMalformed
+		}
+		messageID, err := parseInteger(value)
+		if err != nil {
+			return 0, nil, err
+		}
+		op, value, _, err := parseElement(rest)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		// Unsolicited notifications such as notices of disconnection have message ID 0
+		if messageID == 0 {
+			return 0, nil, errors.New("ldap: server closed the connection")
+		}
+		if messageID == id {
+			return op, value, nil
+		}
+	}
+}
+
+// parseResult returns the error of an LDAPResult that is not a success
+func parseResult(value []byte) error {
+	tag, code, rest, err := parseElement(value)
+	if err != nil || tag != tagEnumerated {
+		return errMalformed
+	}
+	resultCode, err := parseInteger(code)
+	if err != nil {
+		return err
+	}
+	if resultCode == ResultSuccess {
+		return nil
+	}
+
+	// The matched DN precedes the diagnostic message
+	var message []byte
+	if _, _, rest, err = parseElement(rest); err == nil {
+		_, message, _, _ = parseElement(rest)
+	}
+	return &ResultError{Code: resultCode, Message: string(message)}
+}
@@ -0,0 +1,185 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeFilter(t *testing.T) {
+	assert.Equal(t, `jane@example.com`, EscapeFilter("jane@example.com"))
+	assert.Equal(t, `\2a\29\28uid=\2a\29\5c\00`, EscapeFilter("*)(uid=*)\\\x00"))
+}
+
+func TestCompileFilter(t *testing.T) {
+	equality := element(filterEquality, octetString(tagOctetString, "uid"), octetString(tagOctetString, "jane"))
+	present := octetString(filterPresent, "objectClass")
+
+	tests := []struct {
+		filter string
+		want   []byte
+	}{
+		{"(uid=jane)", equality},
+		{"uid=jane", equality},
+		{"(objectClass=*)", present},
+		{"(&(objectClass=*)(uid=jane))", element(filterAnd, present, equality)},
+		{"(|(uid=jane)(!(objectClass=*)))", element(filterOr, equality, element(filterNot, present))},
+		{"(uid>=jane)", element(filterGreater, octetString(tagOctetString, "uid"), octetString(tagOctetString, "jane"))},
+		{`(cn=a\2ab\29)`, element(filterEquality, octetString(tagOctetString, "cn"), octetString(tagOctetString, "a*b)"))},
+		{"(mail=jane*@*.com)", element(filterSubstring, octetString(tagOctetString, "mail"), element(tagSequence,
+			octetString(substringInitial, "jane"), octetString(substringAny, "@"), octetString(substringFinal, ".com")))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := CompileFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, filter := range []string{"", "()", "(uid=jane", "(&)", "(uid=jane))", "(=jane)", `(uid=\2)`, "(uid:dn:=jane)", "(uid=ja(ne)"} {
+		_, err := CompileFilter(filter)
+		assert.Error(t, err, filter)
+	}
+}
+
+// directoryEntry is an entry of the fake directory
+type directoryEntry struct {
+	dn       string
+	mail     string
+	password string
+}
+
+// fakeDirectory serves binds and searches over entries, searchable after binding as the
+// service account
+func fakeDirectory(t *testing.T, serviceDN, servicePassword string, entries []directoryEntry) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	passwords := map[string]string{serviceDN: servicePassword}
+	for _, e := range entries {
+		passwords[e.dn] = e.password
+	}
+
+	go func() {
+		for {
+			netConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveDirectory(netConn, serviceDN, passwords, entries)
+		}
+	}()
+	return "ldap://" + listener.Addr().String()
+}
+
+func serveDirectory(netConn net.Conn, serviceDN string, passwords map[string]string, entries []directoryEntry) {
+	defer netConn.Close()
+	reader := bufio.NewReader(netConn)
+	reply := func(id int, op byte, contents ...[]byte) {
+		netConn.Write(element(tagSequence, integer(tagInteger, id), element(op, contents...)))
+	}
+	result := func(code int) []byte {
+		return append(integer(tagEnumerated, code), append(octetString(tagOctetString, ""), octetString(tagOctetString, "")...)...)
+	}
+
+	boundDN := ""
+	for {
+		_, message, err := readElement(reader)
+		if err != nil {
+			return
+		}
+		_, value, rest, _ := parseElement(message)
+		id, _ := parseInteger(value)
+		op, request, _, _ := parseElement(rest)
+
+		switch op {
+		case opBindRequest:
+			_, _, rest, _ := parseElement(request) // version
+			_, dn, rest, _ := parseElement(rest)
+			_, password, _, _ := parseElement(rest)
+			if expected, ok := passwords[string(dn)]; ok && expected == string(password) {
+				boundDN = string(dn)
+				reply(id, opBindResponse, result(ResultSuccess))
+			} else {
+				reply(id, opBindResponse, result(ResultInvalidCredentials))
+			}
+		case opSearchRequest:
+			if boundDN != serviceDN {
+				reply(id, opSearchDone, result(50)) // insufficientAccessRights
+				continue
+			}
+			fields := request
+			for i := 0; i < 6; i++ {
+				_, _, fields, _ = parseElement(fields)
+			}
+			tag, filter, _, _ := parseElement(fields)
+			raw := element(tag, filter)
+			for _, e := range entries {
+				if expected, _ := CompileFilter("(mail=" + EscapeFilter(e.mail) + ")"); bytes.Equal(expected, raw) {
+					reply(id, opSearchEntry, octetString(tagOctetString, e.dn), element(tagSequence))
+				}
+			}
+			reply(id, opSearchDone, result(ResultSuccess))
+		case opUnbindRequest:
+			return
+		}
+	}
+}
+
+func TestClient_Authenticate(t *testing.T) {
+	serviceDN := "cn=todo-api,ou=services,dc=example,dc=com"
+	url := fakeDirectory(t, serviceDN, "service-secret", []directoryEntry{
+		{dn: "uid=jane,ou=people,dc=example,dc=com", mail: "jane@example.com", password: "jane-secret"},
+		{dn: "uid=twin1,ou=people,dc=example,dc=com", mail: "twin@example.com", password: "secret"},
+		{dn: "uid=twin2,ou=people,dc=example,dc=com", mail: "twin@example.com", password: "secret"},
+	})
+	cfg := Config{
+		URL:          url,
+		BindDN:       serviceDN,
+		BindPassword: "service-secret",
+		BaseDN:       "dc=example,dc=com",
+		UserFilter:   "(mail={username})",
+		Timeout:      5 * time.Second,
+	}
+	client := NewClient(cfg)
+	ctx := context.Background()
+
+	dn, err := client.Authenticate(ctx, "jane@example.com", "jane-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "uid=jane,ou=people,dc=example,dc=com", dn)
+
+	_, err = client.Authenticate(ctx, "jane@example.com", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Empty passwords would bind anonymously
+	_, err = client.Authenticate(ctx, "jane@example.com", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = client.Authenticate(ctx, "john@example.com", "jane-secret")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Usernames are escaped rather than matching any entry
+	_, err = client.Authenticate(ctx, "*", "jane-secret")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Ambiguous filters are a configuration error
+	_, err = client.Authenticate(ctx, "twin@example.com", "secret")
+	assert.EqualError(t, err, "ldap: user filter matched 2 entries")
+
+	// Failing to bind as the service account is not the user's fault
+	cfg.BindPassword = "wrong"
+	_, err = NewClient(cfg).Authenticate(ctx, "jane@example.com", "jane-secret")
+	var resultErr *ResultError
+	require.True(t, errors.As(err, &resultErr))
+	assert.Equal(t, ResultInvalidCredentials, resultErr.Code)
+	assert.False(t, errors.Is(err, ErrInvalidCredentials))
+}
@@ -0,0 +1,128 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Message is an email with a plain text body and an optional HTML alternative
+type Message struct {
+	To      []string
+	Cc      []string // optional, copied recipients
+	Subject string
+	Text    string
+	HTML    string // optional
+}
+
+// Validate checks that the message can be sent
+func (m *Message) Validate() error {
+	if len(m.To) == 0 {
+		return errors.New("message has no recipient")
+	}
+	for _, to := range append(slices.Clip(m.To), m.Cc...) {
+		if _, err := mail.ParseAddress(to); err != nil || strings.ContainsAny(to, "\r\n") {
+			return fmt.Errorf("invalid recipient %q", to)
+		}
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("subject must not contain line breaks")
+	}
+	if m.Text == "" {
+		return errors.New("message has no text body")
+	}
+	return nil
+}
+
+// Mailer defines an email delivery provider.
+// Providers such as SES or SendGrid can be plugged in by implementing this interface.
+type Mailer interface {
+	// Send delivers the message, giving up once the context is done
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Pinger is implemented by mailers that can check their server is reachable without
+// sending a message, for health checks
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// logMailer implements a mailer logging messages instead of sending them
+type logMailer struct{}
+
+// NewLogMailer creates a mailer that logs the recipients and subject of messages instead
+// of sending them, for development
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+// Send logs the message
+func (logMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	to := strings.Join(msg.To, ", ")
+	if len(msg.Cc) > 0 {
+		to += " (cc " + strings.Join(msg.Cc, ", ") + ")"
+	}
+	log.Printf("Email to %s: %s\n%s", to, msg.Subject, msg.Text)
+	return nil
+}
+
+// guardedMailer implements a mailer sending messages through a guard, such as a circuit
+// breaker
+type guardedMailer struct {
+	mailer Mailer
+	guard  func(ctx context.Context, send func(ctx context.Context) error) error
+}
+
+// NewGuardedMailer creates a mailer sending messages with the mailer through the guard, which
+// may retry or refuse sends. Invalid messages are rejected without reaching the guard, so they
+// do not count as failures of the provider.
+func NewGuardedMailer(mailer Mailer, guard func(ctx context.Context, send func(ctx context.Context) error) error) Mailer {
+	return &guardedMailer{mailer: mailer, guard: guard}
+}
+
+// Send sends the message through the guard
+func (m *guardedMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	return m.guard(ctx, func(ctx context.Context) error {
+		return m.mailer.Send(ctx, msg)
+	})
+}
+
+// MemoryMailer implements a mailer keeping messages in memory, for tests
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+// NewMemoryMailer creates a mailer keeping sent messages in memory
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send records the message
+func (m *MemoryMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (m *MemoryMailer) Messages() []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Message(nil), m.messages...)
+}
@@ -0,0 +1,203 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageValidate(t *testing.T) {
+	valid := Message{To: []string{"alice@example.com"}, Subject: "Hello", Text: "Hi"}
+	assert.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(m *Message){
+		"no recipient":      func(m *Message) { m.To = nil },
+		"invalid recipient": func(m *Message) { m.To = []string{"not an address"} },
+		"invalid copy":      func(m *Message) { m.Cc = []string{"bob@example.com\r\nBcc: eve@example.com"} },
+		"header injection":  func(m *Message) { m.Subject = "Hello\r\nBcc: eve@example.com" },
+		"no text body":      func(m *Message) { m.Text = "" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			msg := valid
+			mutate(&msg)
+			assert.Error(t, msg.Validate())
+		})
+	}
+}
+
+func TestGuardedMailer(t *testing.T) {
+	memory := NewMemoryMailer()
+	guarded := 0
+	m := NewGuardedMailer(memory, func(ctx context.Context, send func(ctx context.Context) error) error {
+		guarded++
+		return send(ctx)
+	})
+
+	require.NoError(t, m.Send(context.Background(), &Message{To: []string{"alice@example.com"}, Subject: "Hello", Text: "Hi"}))
+	assert.Len(t, memory.Messages(), 1)
+
+	// Invalid messages do not reach the guard
+	assert.Error(t, m.Send(context.Background(), &Message{Subject: "Hello", Text: "Hi"}))
+	assert.Equal(t, 1, guarded)
+}
+
+func TestTemplates(t *testing.T) {
+	templates, err := ParseTemplates(fstest.MapFS{
+		"templates/welcome.subject.tmpl": {Data: []byte("Welcome, {{.Name}}\n")},
+		"templates/welcome.txt.tmpl":     {Data: []byte("Hello {{.Name}}")},
+		"templates/welcome.html.tmpl":    {Data: []byte("<p>Hello {{.Name}}</p>")},
+		"templates/plain.subject.tmpl":   {Data: []byte("Plain")},
+		"templates/plain.txt.tmpl":       {Data: []byte("Text only")},
+	}, "templates")
+	require.NoError(t, err)
+
+	msg, err := templates.Render("welcome", "alice@example.com", map[string]string{"Name": "<Alice>"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com"}, msg.To)
+	assert.Equal(t, "Welcome, <Alice>", msg.Subject)
+	assert.Equal(t, "Hello <Alice>", msg.Text)
+	// Values are escaped in HTML bodies
+	assert.Equal(t, "<p>Hello &lt;Alice&gt;</p>", msg.HTML)
+
+	msg, err = templates.Render("plain", "alice@example.com", nil)
+	require.NoError(t, err)
+	assert.Empty(t, msg.HTML)
+
+	_, err = templates.Render("missing", "alice@example.com", nil)
+	assert.ErrorContains(t, err, "template missing.subject.tmpl not found")
+}
+
+// fakeSMTPServer accepts a single SMTP session and returns the envelope and data received
+func fakeSMTPServer(t *testing.T) (addr string, received <-chan []string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+
+		var session []string
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.Fields(line)[0]); verb {
+			case "EHLO":
+				_ = tp.PrintfLine("250 localhost")
+			case "MAIL", "RCPT":
+				session = append(session, line)
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				data, _ := tp.ReadDotBytes()
+				session = append(session, string(data))
+				_ = tp.PrintfLine("250 OK")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				ch <- session
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return ln.Addr().String(), ch
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+
+	m := NewSMTPMailer(SMTPConfig{
+		Host:    host,
+		Port:    port,
+		From:    "Todo API <no-reply@example.com>",
+		Timeout: 5 * time.Second,
+	})
+	err := m.Send(context.Background(), &Message{
+		To:      []string{"Alice <alice@example.com>"},
+		Cc:      []string{"bob@example.com"},
+		Subject: "Tâche due",
+		Text:    "Your task is due",
+		HTML:    "<p>Your task is due</p>",
+	})
+	require.NoError(t, err)
+
+	session := <-received
+	require.Len(t, session, 4)
+	assert.Equal(t, "MAIL FROM:<no-reply@example.com>", session[0])
+	assert.Equal(t, "RCPT TO:<alice@example.com>", session[1])
+	assert.Equal(t, "RCPT TO:<bob@example.com>", session[2])
+
+	msg, err := mail.ReadMessage(strings.NewReader(session[3]))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Tâche due", subject)
+	assert.Equal(t, "Alice <alice@example.com>", msg.Header.Get("To"))
+	assert.Equal(t, "bob@example.com", msg.Header.Get("Cc"))
+	assert.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>"))
+
+	// The text and HTML bodies are alternatives
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, _ := io.ReadAll(bufio.NewReader(part))
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(body))
+	}
+	assert.Equal(t, []string{
+		"text/plain; charset=utf-8: Your task is due",
+		"text/html; charset=utf-8: <p>Your task is due</p>",
+	}, bodies)
+}
+
+func TestSMTPMailer_Ping(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+
+	m := NewSMTPMailer(SMTPConfig{Host: host, Port: port, Timeout: 5 * time.Second})
+	pinger, ok := m.(Pinger)
+	require.True(t, ok)
+	require.NoError(t, pinger.Ping(context.Background()))
+
+	// The session ends without a message
+	assert.Empty(t, <-received)
+
+	// Unreachable servers fail the ping
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ = net.SplitHostPort(ln.Addr().String())
+	ln.Close()
+	m = NewSMTPMailer(SMTPConfig{Host: host, Port: port, Timeout: 5 * time.Second})
+	assert.Error(t, m.(Pinger).Ping(context.Background()))
+	_, ok = NewLogMailer().(Pinger)
+	assert.False(t, ok)
+}
@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates renders messages from templates. Each message is made of up to three files
+// named after it: <name>.subject.tmpl and <name>.txt.tmpl are text templates, and the
+// optional <name>.html.tmpl is an HTML template whose values are escaped.
+type Templates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// ParseTemplates parses the templates in the directory of the file system
+func ParseTemplates(fsys fs.FS, dir string) (*Templates, error) {
+	t := &Templates{
+		text: texttemplate.New(""),
+		html: htmltemplate.New(""),
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".tmpl") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(name, ".html.tmpl") {
+			_, err = t.html.New(name).Parse(string(content))
+		} else {
+			_, err = t.text.New(name).Parse(string(content))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// MustParseTemplates is like ParseTemplates but panics on error, for templates embedded
+// in the binary
+func MustParseTemplates(fsys fs.FS, dir string) *Templates {
+	t, err := ParseTemplates(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render renders the message with the name, addressed to the recipient
+func (t *Templates) Render(name, to string, data interface{}) (*Message, error) {
+	subject, err := t.executeText(name+".subject.tmpl", data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := t.executeText(name+".txt.tmpl", data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		To:      []string{to},
+		Subject: strings.TrimSpace(subject),
+		Text:    text,
+	}
+
+	if html := t.html.Lookup(name + ".html.tmpl"); html != nil {
+		var buf bytes.Buffer
+		if err := html.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render template %s: %w", html.Name(), err)
+		}
+		msg.HTML = buf.String()
+	}
+	return msg, nil
+}
+
+// executeText renders the text template with the name
+func (t *Templates) executeText(name string, data interface{}) (string, error) {
+	tmpl := t.text.Lookup(name)
+	if tmpl == nil {
+		return "", fmt.Errorf("template %s not found", name)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
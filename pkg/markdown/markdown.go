@@ -0,0 +1,505 @@
+// Package markdown renders the Markdown of task descriptions as HTML that is safe to embed in
+// a page. Raw HTML in the source is always escaped, and links only ever lead to http, https,
+// or mailto URLs, so the output needs no further sanitizing.
+//
+// The common subset of Markdown is supported: ATX headings, paragraphs with hard line
+// breaks, emphasis, strong emphasis, strikethrough, code spans, fenced code blocks, block
+// quotes, ordered and unordered lists, thematic breaks, links, and bare http(s) URLs.
+// Images are rendered as links to them, so descriptions cannot load content on their own.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDepth bounds the nesting of block quotes and lists, beyond which they are rendered as
+// paragraphs
+const maxDepth = 8
+
+// linkRel is the rel attribute of every link, which lead to pages outside the app
+const linkRel = "nofollow noopener noreferrer"
+
+var (
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	breakPattern   = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`]*?)[ \t]*$")
+	itemPattern    = regexp.MustCompile(`^( {0,3})([-*+]|(\d{1,9})[.)])(?:[ \t]+|$)`)
+	quotePattern   = regexp.MustCompile(`^ {0,3}> ?`)
+	languageClass  = regexp.MustCompile(`^[A-Za-z0-9_+#.-]+$`)
+)
+
+// Render renders the Markdown source as HTML
+func Render(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		lines[i] = expandIndent(line)
+	}
+
+	var out strings.Builder
+	renderBlocks(&out, lines, 0)
+	return out.String()
+}
+
+// expandIndent replaces the tabs indenting the line with four spaces each
+func expandIndent(line string) string {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if !strings.Contains(line[:indent], "\t") {
+		return line
+	}
+	return strings.ReplaceAll(line[:indent], "\t", "    ") + line[indent:]
+}
+
+// renderBlocks renders lines as a sequence of blocks
+func renderBlocks(out *strings.Builder, lines []string, depth int) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			i = renderFence(out, lines, i)
+
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			out.WriteString("<h" + level + ">" + renderInline(m[2], false) + "</h" + level + ">\n")
+			i++
+
+		case breakPattern.MatchString(line):
+			out.WriteString("<hr>\n")
+			i++
+
+		case depth < maxDepth && quotePattern.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && quotePattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quotePattern.ReplaceAllString(lines[i], ""))
+			}
+			out.WriteString("<blockquote>\n")
+			renderBlocks(out, quoted, depth+1)
+			out.WriteString("</blockquote>\n")
+
+		case depth < maxDepth && itemPattern.MatchString(line):
+			i = renderList(out, lines, i, depth)
+
+		default:
+			i = renderParagraph(out, lines, i, depth)
+		}
+	}
+}
+
+// renderFence renders the fenced code block starting at the line, returning the line after
+// its closing fence, or the end of the lines when it is not closed
+func renderFence(out *strings.Builder, lines []string, start int) int {
+	m := fencePattern.FindStringSubmatch(lines[start])
+	fence := m[1]
+
+	out.WriteString("<pre><code")
+	if language, _, _ := strings.Cut(m[2], " "); languageClass.MatchString(language) {
+		out.WriteString(` class="language-` + html.EscapeString(language) + `"`)
+	}
+	out.WriteString(">")
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence[:3]) && strings.Trim(trimmed, fence[:1]) == "" && len(trimmed) >= len(fence) {
+			i++
+			break
+		}
+		out.WriteString(html.EscapeString(lines[i]) + "\n")
+	}
+	out.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderList renders the list starting at the line, returning the line after it. Items
+// continue on lines indented past their marker, and the list ends at a line that is not
+// indented and starts no item of the same kind.
+func renderList(out *strings.Builder, lines []string, start, depth int) int {
+	first := itemPattern.FindStringSubmatch(lines[start])
+	ordered := first[3] != ""
+	kind := listMarker(first)
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	out.WriteString("<" + tag)
+	if n, _ := strconv.Atoi(first[3]); ordered && n != 1 {
+		out.WriteString(` start="` + strconv.Itoa(n) + `"`)
+	}
+	out.WriteString(">\n")
+
+	i := start
+	for i < len(lines) {
+		m := itemPattern.FindStringSubmatch(lines[i])
+		if m == nil || listMarker(m) != kind {
+			break
+		}
+		indent := len(m[0])
+		item := []string{lines[i][indent:]}
+		loose := false
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line ends the item unless an indented line follows
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) >= indent {
+					item = append(item, "")
+					loose = true
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) >= indent {
+				item = append(item, line[indent:])
+				continue
+			}
+			if itemPattern.MatchString(line) || !isParagraphContinuation(line) {
+				break
+			}
+			// Lazy continuation of the item's paragraph
+			item = append(item, line)
+		}
+
+		var content strings.Builder
+		renderBlocks(&content, item, depth+1)
+		rendered := content.String()
+		// Tight items hold their text without a paragraph
+		if !loose && strings.HasPrefix(rendered, "<p>") && strings.Count(rendered, "<p>") == 1 {
+			rendered = strings.Replace(strings.TrimPrefix(rendered, "<p>"), "</p>", "", 1)
+			rendered = strings.TrimSuffix(rendered, "\n")
+		}
+		out.WriteString("<li>" + rendered + "</li>\n")
+
+		// Blank lines between items keep the list going
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" && i+1 < len(lines) && itemPattern.MatchString(lines[i+1]) {
+			i++
+		}
+	}
+
+	out.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// listMarker returns the bullet of unordered list items, or the delimiter following the
+// number of ordered ones. Items with another marker start a new list.
+func listMarker(item []string) string {
+	return item[2][len(item[2])-1:]
+}
+
+// renderParagraph renders the paragraph starting at the line, returning the line after it
+func renderParagraph(out *strings.Builder, lines []string, start, depth int) int {
+	i := start + 1
+	for i < len(lines) && isParagraphContinuation(lines[i]) && !(depth < maxDepth && startsBlock(lines[i])) {
+		i++
+	}
+
+	out.WriteString("<p>")
+	for j, line := range lines[start:i] {
+		text := strings.TrimLeft(line, " \t")
+		hardBreak := false
+		if j < i-start-1 {
+			if strings.HasSuffix(text, "  ") {
+				hardBreak = true
+			} else if strings.HasSuffix(text, `\`) && !strings.HasSuffix(text, `\\`) {
+				hardBreak = true
+				text = strings.TrimSuffix(text, `\`)
+			}
+		}
+		out.WriteString(renderInline(strings.TrimRight(text, " \t"), false))
+		if j < i-start-1 {
+			if hardBreak {
+				out.WriteString("<br>")
+			}
+			out.WriteString("\n")
+		}
+	}
+	out.WriteString("</p>\n")
+	return i
+}
+
+// isParagraphContinuation reports whether the line may continue a paragraph, rather than
+// ending it
+func isParagraphContinuation(line string) bool {
+	return strings.TrimSpace(line) != "" && !fencePattern.MatchString(line) && !headingPattern.MatchString(line) &&
+		!breakPattern.MatchString(line)
+}
+
+// startsBlock reports whether the line starts a block quote or list, which interrupt
+// paragraphs
+func startsBlock(line string) bool {
+	return quotePattern.MatchString(line) || itemPattern.MatchString(line)
+}
+
+// leadingSpaces returns the indentation of the line, whose tabs were expanded
+func leadingSpaces(line string) int {
+	return runLength(line, ' ')
+}
+
+// renderInline renders the text of a block, without links when inside one
+func renderInline(text string, inLink bool) string {
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			out.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if n, code, ok := codeSpan(text[i:]); ok {
+				out.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += n
+				continue
+			}
+			// An unmatched run of backticks is literal
+			run := runLength(text[i:], '`')
+			out.WriteString(text[i : i+run])
+			i += run
+			continue
+
+		case c == '*' || c == '_' || c == '~':
+			if n, rendered, ok := emphasis(text, i, inLink); ok {
+				out.WriteString(rendered)
+				i += n
+				continue
+			}
+			run := runLength(text[i:], c)
+			out.WriteString(text[i : i+run])
+			i += run
+			continue
+
+		case !inLink && (c == '[' || (c == '!' && strings.HasPrefix(text[i:], "!["))):
+			if n, rendered, ok := link(text[i:]); ok {
+				out.WriteString(rendered)
+				i += n
+				continue
+			}
+
+		case !inLink && c == '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				if href, ok := safeURL(text[i+1 : i+end]); ok && strings.Contains(href, ":") {
+					out.WriteString(anchor(href, html.EscapeString(text[i+1:i+end])))
+					i += end + 1
+					continue
+				}
+			}
+
+		case !inLink && (c == 'h' || c == 'H') && (i == 0 || !isWordByte(text[i-1])):
+			if n := bareURL(text[i:]); n > 0 {
+				out.WriteString(anchor(text[i:i+n], html.EscapeString(text[i:i+n])))
+				i += n
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		out.WriteString(html.EscapeString(text[i : i+size]))
+		i += size
+	}
+	return out.String()
+}
+
+// codeSpan returns the length and the code of the code span the text starts with
+func codeSpan(text string) (int, string, bool) {
+	run := runLength(text, '`')
+	for j := run; j < len(text); {
+		k := strings.IndexByte(text[j:], '`')
+		if k == -1 {
+			return 0, "", false
+		}
+		j += k
+		if runLength(text[j:], '`') == run {
+			code := strings.ReplaceAll(text[run:j], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			return j + run, code, true
+		}
+		j += runLength(text[j:], '`')
+	}
+	return 0, "", false
+}
+
+// emphasis renders the emphasis, strong emphasis, or strikethrough opening at the position,
+// returning the length of the source it spans
+func emphasis(text string, i int, inLink bool) (int, string, bool) {
+	c := text[i]
+	run := runLength(text[i:], c)
+
+	var delimiter, tag string
+	switch {
+	case c == '~' && run == 2:
+		delimiter, tag = "~~", "del"
+	case c == '~':
+		return 0, "", false
+	case run >= 3:
+		delimiter, tag = string([]byte{c, c, c}), "strong"
+	case run == 2:
+		delimiter, tag = string([]byte{c, c}), "strong"
+	default:
+		delimiter, tag = string(c), "em"
+	}
+
+	// Openers precede text, and underscores never open inside words
+	after := i + len(delimiter)
+	if after >= len(text) || isSpaceByte(text[after]) {
+		return 0, "", false
+	}
+	if c == '_' && i > 0 && isWordByte(text[i-1]) {
+		return 0, "", false
+	}
+
+	for j := after + 1; j < len(text); j++ {
+		if text[j] == '`' {
+			if n, _, ok := codeSpan(text[j:]); ok {
+				j += n - 1
+			}
+			continue
+		}
+		if text[j] != c {
+			continue
+		}
+		// Only a run as long as the opener closes it, as others are emphasis of their own
+		if closing := runLength(text[j:], c); closing != len(delimiter) || isSpaceByte(text[j-1]) {
+			j += closing - 1
+			continue
+		}
+		end := j + len(delimiter)
+		if c == '_' && end < len(text) && isWordByte(text[end]) {
+			continue
+		}
+		inner := renderInline(text[after:j], inLink)
+		if len(delimiter) == 3 {
+			inner = "<em>" + inner + "</em>"
+		}
+		return end - i, "<" + tag + ">" + inner + "</" + tag + ">", true
+	}
+	return 0, "", false
+}
+
+// link renders the link or image the text starts with, returning the length of the source
+// it spans
+func link(text string) (int, string, bool) {
+	image := text[0] == '!'
+	start := 1
+	if image {
+		start = 2
+	}
+
+	// The label ends at its matching bracket
+	depth, labelEnd := 1, -1
+	for j := start; j < len(text) && labelEnd == -1; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				labelEnd = j
+			}
+		}
+	}
+	if labelEnd == -1 || labelEnd+1 >= len(text) || text[labelEnd+1] != '(' {
+		return 0, "", false
+	}
+
+	closing := strings.IndexByte(text[labelEnd+2:], ')')
+	if closing == -1 {
+		return 0, "", false
+	}
+	destination := strings.TrimSpace(text[labelEnd+2 : labelEnd+2+closing])
+	// Titles are dropped
+	if target, _, found := strings.Cut(destination, " "); found {
+		destination = target
+	}
+	destination = strings.TrimSuffix(strings.TrimPrefix(destination, "<"), ">")
+	length := labelEnd + 3 + closing
+
+	label := text[start:labelEnd]
+	rendered := renderInline(label, true)
+	if image && label == "" {
+		rendered = html.EscapeString(destination)
+	}
+	href, ok := safeURL(destination)
+	if !ok {
+		// Unsafe links keep their label only
+		return length, rendered, true
+	}
+	return length, anchor(href, rendered), true
+}
+
+// safeURL returns the URL escaped for an attribute when it is relative or uses the http,
+// https, or mailto scheme
+func safeURL(raw string) (string, bool) {
+	if raw == "" || strings.ContainsAny(raw, " <>\"") || strings.IndexFunc(raw, unicode.IsControl) != -1 {
+		return "", false
+	}
+	if colon := strings.IndexByte(raw, ':'); colon != -1 && !strings.ContainsAny(raw[:colon], "/?#") {
+		switch strings.ToLower(raw[:colon]) {
+		case "http", "https", "mailto":
+		default:
+			return "", false
+		}
+	}
+	return html.EscapeString(raw), true
+}
+
+// bareURL returns the length of the http or https URL the text starts with, without the
+// punctuation that ends its sentence
+func bareURL(text string) int {
+	lower := strings.ToLower(text[:min(len(text), 8)])
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return 0
+	}
+	n := strings.IndexFunc(text, func(r rune) bool { return unicode.IsSpace(r) || r == '<' || r == '>' || r == '"' })
+	if n == -1 {
+		n = len(text)
+	}
+	for n > 0 && strings.ContainsRune(".,:;!?'*_~", rune(text[n-1])) {
+		n--
+	}
+	// Closing parentheses end the URL unless it opened them
+	for n > 0 && text[n-1] == ')' && strings.Count(text[:n], "(") < strings.Count(text[:n], ")") {
+		n--
+	}
+	if n <= len("https://") {
+		return 0
+	}
+	return n
+}
+
+// anchor returns a link to the escaped URL
+func anchor(href, label string) string {
+	return `<a href="` + href + `" rel="` + linkRel + `">` + label + "</a>"
+}
+
+// runLength returns how many times the byte repeats at the start of the text
+func runLength(text string, c byte) int {
+	n := 0
+	for n < len(text) && text[n] == c {
+		n++
+	}
+	return n
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) != -1
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isWordByte(c byte) bool {
+	return c >= utf8.RuneSelf || c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,87 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"paragraphs", "First line\nsecond line\n\nAnother", "<p>First line\nsecond line</p>\n<p>Another</p>\n"},
+		{"hard breaks", "Line one  \nLine two\\\nLine three", "<p>Line one<br>\nLine two<br>\nLine three</p>\n"},
+		{"headings", "# Title #\n### Notes\n#hashtag", "<h1>Title</h1>\n<h3>Notes</h3>\n<p>#hashtag</p>\n"},
+		{"emphasis", "*em* **strong** ***both*** _em_ __strong__ ~~gone~~",
+			"<p><em>em</em> <strong>strong</strong> <strong><em>both</em></strong> <em>em</em> <strong>strong</strong> <del>gone</del></p>\n"},
+		{"nested emphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"literal delimiters", "2 * 3 * 4 and snake_case_name", "<p>2 * 3 * 4 and snake_case_name</p>\n"},
+		{"code span", "Run `go test ./...` and `` a`b ``", "<p>Run <code>go test ./...</code> and <code>a`b</code></p>\n"},
+		{"escapes", `\*not em\* and \<b>`, "<p>*not em* and &lt;b&gt;</p>\n"},
+		{"fenced code", "```go\nif a < b {\n\treturn\n}\n```\nAfter",
+			"<pre><code class=\"language-go\">if a &lt; b {\n    return\n}\n</code></pre>\n<p>After</p>\n"},
+		{"unclosed fence", "~~~\ncode", "<pre><code>code\n</code></pre>\n"},
+		{"thematic break", "Above\n\n---\n\n* * *", "<p>Above</p>\n<hr>\n<hr>\n"},
+		{"block quote", "> Quoted\n> **text**\n\n> > nested", "<blockquote>\n<p>Quoted\n<strong>text</strong></p>\n</blockquote>\n" +
+			"<blockquote>\n<blockquote>\n<p>nested</p>\n</blockquote>\n</blockquote>\n"},
+		{"unordered list", "- one\n- *two*\n  continued\n* three",
+			"<ul>\n<li>one</li>\n<li><em>two</em>\ncontinued</li>\n</ul>\n<ul>\n<li>three</li>\n</ul>\n"},
+		{"ordered list", "3. three\n4. four", "<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n"},
+		{"nested list", "1. Steps\n   - first\n   - second\n2. Done",
+			"<ol>\n<li>Steps\n<ul>\n<li>first</li>\n<li>second</li>\n</ul></li>\n<li>Done</li>\n</ol>\n"},
+		{"list interrupts paragraph", "Todo:\n- milk", "<p>Todo:</p>\n<ul>\n<li>milk</li>\n</ul>\n"},
+		{"links", `[docs](https://example.com/a?b=1&c=2 "Title") and [mail](mailto:me@example.com)`,
+			`<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">docs</a> and ` +
+				`<a href="mailto:me@example.com" rel="nofollow noopener noreferrer">mail</a></p>` + "\n"},
+		{"relative link", "[task](/tasks/1#notes)", `<p><a href="/tasks/1#notes" rel="nofollow noopener noreferrer">task</a></p>` + "\n"},
+		{"bare URL", "See https://example.com/x_(y). Or <https://example.com>",
+			`<p>See <a href="https://example.com/x_(y)" rel="nofollow noopener noreferrer">https://example.com/x_(y)</a>. Or ` +
+				`<a href="https://example.com" rel="nofollow noopener noreferrer">https://example.com</a></p>` + "\n"},
+		{"image", "![diagram](https://example.com/d.png)",
+			`<p><a href="https://example.com/d.png" rel="nofollow noopener noreferrer">diagram</a></p>` + "\n"},
+		{"CRLF", "a\r\nb", "<p>a\nb</p>\n"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.source))
+		})
+	}
+}
+
+func TestRender_Sanitizes(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"raw HTML", `<script>alert("x")</script><img src=x onerror=alert(1)>`,
+			"<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;&lt;img src=x onerror=alert(1)&gt;</p>\n"},
+		{"javascript link", "[click](javascript:alert(1))", "<p>click)</p>\n"},
+		{"mixed case scheme", "[click](JaVaScRiPt:alert%281%29)", "<p>click</p>\n"},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>\n"},
+		{"autolink scheme", "<javascript:alert(1)>", "<p>&lt;javascript:alert(1)&gt;</p>\n"},
+		{"encoded scheme", "[x](javascript&#58;alert(1))", `<p><a href="javascript&amp;#58;alert(1" rel="nofollow noopener noreferrer">x</a>)</p>` + "\n"},
+		{"attribute breakout", `[x](https://example.com/"onmouseover=alert(1))`, "<p>x)</p>\n"},
+		{"fence language", "```\"><script>\ncode\n```", "<pre><code>code\n</code></pre>\n"},
+		{"nested links", "[[inner](https://a.example)](https://b.example)",
+			`<p><a href="https://b.example" rel="nofollow noopener noreferrer">[inner](https://a.example)</a></p>` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.source))
+		})
+	}
+}
+
+func TestRender_DeepNesting(t *testing.T) {
+	// Nesting beyond the maximum depth is rendered as text rather than recursing
+	source := strings.Repeat(">", 10000) + " deep"
+	rendered := Render(source)
+	assert.Equal(t, maxDepth, strings.Count(rendered, "<blockquote>"))
+	assert.Contains(t, rendered, "&gt;&gt;")
+}
@@ -0,0 +1,150 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APNs endpoints of the production and development environments
+const (
+	APNsProductionEndpoint = "https://api.push.apple.com"
+	APNsSandboxEndpoint    = "https://api.sandbox.push.apple.com"
+)
+
+// apnsTokenTTL is how long provider tokens are reused. Apple rejects tokens older than an
+// hour and refreshing them more often than every 20 minutes.
+const apnsTokenTTL = 50 * time.Minute
+
+// APNsConfig configures delivery to iOS devices through the Apple Push Notification service,
+// authenticated with a token signing key
+type APNsConfig struct {
+	Key      []byte // .p8 signing key, PEM encoded
+	KeyID    string
+	TeamID   string
+	Topic    string // bundle ID of the app
+	Endpoint string // defaults to APNsProductionEndpoint
+	Timeout  time.Duration
+}
+
+// apnsSender implements a sender through APNs
+type apnsSender struct {
+	cfg    APNsConfig
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu       sync.Mutex // guards the provider token
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender creates a sender through APNs. Requests use HTTP/2, as APNs requires.
+func NewAPNsSender(cfg APNsConfig) (Sender, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = APNsProductionEndpoint
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+
+	return &apnsSender{
+		cfg:    cfg,
+		key:    key,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Send sends the notification to the device token. Data is passed as custom keys of the payload.
+func (s *apnsSender) Send(ctx context.Context, token string, n *Notification) error {
+	providerToken, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": n.Title, "body": n.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range n.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", s.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &RetryableError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+
+	switch {
+	case resp.StatusCode == http.StatusGone, result.Reason == "BadDeviceToken", result.Reason == "Unregistered",
+		result.Reason == "DeviceTokenNotForTopic":
+		return ErrInvalidToken
+	case result.Reason == "ExpiredProviderToken":
+		// The next attempt signs a new provider token
+		s.mu.Lock()
+		s.token = ""
+		s.mu.Unlock()
+		return &RetryableError{Err: fmt.Errorf("apns rejected the provider token: %s", result.Reason)}
+	}
+	return statusError("apns", resp, result.Reason)
+}
+
+// providerToken returns the signed provider token, signing a new one when it is due
+func (s *apnsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Since(s.issuedAt) < apnsTokenTTL {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.cfg.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.cfg.KeyID
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.issuedAt = signed, now
+	return signed, nil
+}
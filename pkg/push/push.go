@@ -0,0 +1,163 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Notification is a push notification shown on a device
+type Notification struct {
+	Title string
+	Body  string
+	Data  map[string]string // passed to the app, e.g. the ID of the task to open
+}
+
+// Sender delivers push notifications to device tokens of a platform
+type Sender interface {
+	Send(ctx context.Context, token string, n *Notification) error
+}
+
+// ErrInvalidToken is returned for device tokens the platform no longer accepts, such as
+// tokens of uninstalled apps. The token should be forgotten rather than retried.
+var ErrInvalidToken = errors.New("push: device token is invalid or unregistered")
+
+// RetryableError is a failure that may succeed when retried, such as a rate limit or a
+// server error
+type RetryableError struct {
+	Err        error
+	RetryAfter time.Duration // delay requested by the platform, if any
+}
+
+// Error returns the error message
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// statusError returns the error of a failed response, retryable for rate limits and server
+// errors
+func statusError(platform string, resp *http.Response, reason string) error {
+	err := fmt.Errorf("%s responded with status %d: %s", platform, resp.StatusCode, reason)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &RetryableError{Err: err, RetryAfter: time.Duration(retryAfter) * time.Second}
+	}
+	return err
+}
+
+// retrySender retries failed sends with exponential backoff
+type retrySender struct {
+	sender      Sender
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewRetrySender creates a sender retrying retryable failures of the sender, up to
+// maxAttempts sends in total. The delay starts at backoff and doubles after each attempt,
+// with jitter, unless the platform requested a longer one.
+func NewRetrySender(sender Sender, maxAttempts int, backoff time.Duration) Sender {
+	return &retrySender{sender: sender, maxAttempts: maxAttempts, backoff: backoff}
+}
+
+// Send sends the notification, retrying retryable failures
+func (s *retrySender) Send(ctx context.Context, token string, n *Notification) error {
+	delay := s.backoff
+	for attempt := 1; ; attempt++ {
+		err := s.sender.Send(ctx, token, n)
+
+		var retryable *RetryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= s.maxAttempts {
+			return err
+		}
+
+		// Up to half of the delay is jitter, so devices failing together are not retried together
+		wait := delay/2 + rand.N(delay/2+1)
+		if retryable.RetryAfter > wait {
+			wait = retryable.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// logSender writes notifications to the log instead of sending them
+type logSender struct {
+	platform string
+}
+
+// NewLogSender creates a sender logging notifications to devices of the platform, for
+// development and platforms without credentials
+func NewLogSender(platform string) Sender {
+	return &logSender{platform: platform}
+}
+
+// Send logs the notification
+func (s *logSender) Send(ctx context.Context, token string, n *Notification) error {
+	log.Printf("Push notification to %s device %s: %s: %s %v", s.platform, truncate(token), n.Title, n.Body, n.Data)
+	return nil
+}
+
+// truncate shortens device tokens in logs
+func truncate(token string) string {
+	if len(token) <= 12 {
+		return token
+	}
+	return token[:12] + "..."
+}
+
+// MemorySender records notifications instead of sending them, for tests
+type MemorySender struct {
+	mu            sync.Mutex
+	sent          map[string][]*Notification
+	invalidTokens map[string]bool
+}
+
+// NewMemorySender creates a sender recording notifications
+func NewMemorySender() *MemorySender {
+	return &MemorySender{
+		sent:          make(map[string][]*Notification),
+		invalidTokens: make(map[string]bool),
+	}
+}
+
+// Send records the notification, failing for tokens marked invalid
+func (s *MemorySender) Send(ctx context.Context, token string, n *Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.invalidTokens[token] {
+		return ErrInvalidToken
+	}
+	s.sent[token] = append(s.sent[token], n)
+	return nil
+}
+
+// Invalidate makes sending to the token fail with ErrInvalidToken
+func (s *MemorySender) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalidTokens[token] = true
+}
+
+// Sent returns the notifications sent to the token
+func (s *MemorySender) Sent(token string) []*Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Notification(nil), s.sent[token]...)
+}
@@ -0,0 +1,185 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySender fails with the errors in order, then succeeds
+type flakySender struct {
+	errs  []error
+	sends int
+}
+
+func (s *flakySender) Send(ctx context.Context, token string, n *Notification) error {
+	s.sends++
+	if s.sends <= len(s.errs) {
+		return s.errs[s.sends-1]
+	}
+	return nil
+}
+
+func TestRetrySender(t *testing.T) {
+	unavailable := &RetryableError{Err: errors.New("unavailable")}
+
+	// Retryable failures are retried
+	sender := &flakySender{errs: []error{unavailable, unavailable}}
+	require.NoError(t, NewRetrySender(sender, 3, time.Millisecond).Send(context.Background(), "token", &Notification{}))
+	assert.Equal(t, 3, sender.sends)
+
+	// Up to the maximum number of attempts
+	sender = &flakySender{errs: []error{unavailable, unavailable, unavailable}}
+	assert.ErrorIs(t, NewRetrySender(sender, 3, time.Millisecond).Send(context.Background(), "token", &Notification{}), unavailable)
+	assert.Equal(t, 3, sender.sends)
+
+	// Invalid tokens are not retried
+	sender = &flakySender{errs: []error{ErrInvalidToken}}
+	assert.ErrorIs(t, NewRetrySender(sender, 3, time.Millisecond).Send(context.Background(), "token", &Notification{}), ErrInvalidToken)
+	assert.Equal(t, 1, sender.sends)
+
+	// Waiting for a retry stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sender = &flakySender{errs: []error{unavailable}}
+	assert.Error(t, NewRetrySender(sender, 3, time.Hour).Send(ctx, "token", &Notification{}))
+	assert.Equal(t, 1, sender.sends)
+}
+
+// fcmCredentials returns a service account key using the token URI
+func fcmCredentials(t *testing.T, tokenURI string) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "todo-app",
+		"client_email": "push@todo-app.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    tokenURI,
+	})
+	require.NoError(t, err)
+	return credentials
+}
+
+func TestFCMSender(t *testing.T) {
+	var tokenRequests atomic.Int32
+	var message map[string]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests.Add(1)
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+			assert.NotEmpty(t, r.FormValue("assertion"))
+			w.Write([]byte(`{"access_token":"access-token","expires_in":3600,"token_type":"Bearer"}`))
+		case "/v1/projects/todo-app/messages:send":
+			assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+			if message["message"]["token"] == "stale" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+				return
+			}
+			w.Write([]byte(`{"name":"projects/todo-app/messages/1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sender, err := NewFCMSender(FCMConfig{Credentials: fcmCredentials(t, server.URL+"/token"), Endpoint: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	n := &Notification{Title: "Due soon", Body: "Pay rent", Data: map[string]string{"task_id": "1"}}
+	require.NoError(t, sender.Send(context.Background(), "device-token", n))
+	assert.Equal(t, "device-token", message["message"]["token"])
+	assert.Equal(t, map[string]interface{}{"title": "Due soon", "body": "Pay rent"}, message["message"]["notification"])
+	assert.Equal(t, map[string]interface{}{"task_id": "1"}, message["message"]["data"])
+
+	assert.ErrorIs(t, sender.Send(context.Background(), "stale", n), ErrInvalidToken)
+
+	// Access tokens are reused until they expire
+	assert.Equal(t, int32(1), tokenRequests.Load())
+
+	_, err = NewFCMSender(FCMConfig{Credentials: []byte(`{"project_id":"todo-app"}`)})
+	assert.Error(t, err)
+}
+
+func TestFCMSenderServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Write([]byte(`{"access_token":"access-token","expires_in":3600}`))
+			return
+		}
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":{"code":503,"status":"UNAVAILABLE","message":"try later"}}`))
+	}))
+	defer server.Close()
+
+	sender, err := NewFCMSender(FCMConfig{Credentials: fcmCredentials(t, server.URL+"/token"), Endpoint: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), "device-token", &Notification{Title: "Due soon"})
+	var retryable *RetryableError
+	require.ErrorAs(t, err, &retryable)
+	assert.Equal(t, 7*time.Second, retryable.RetryAfter)
+}
+
+func TestAPNsSender(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "bearer "))
+		assert.Equal(t, "com.example.todo", r.Header.Get("apns-topic"))
+		assert.Equal(t, "alert", r.Header.Get("apns-push-type"))
+
+		switch r.URL.Path {
+		case "/3/device/abc123":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		case "/3/device/gone":
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"reason":"Unregistered"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"reason":"BadDeviceToken"}`))
+		}
+	}))
+	defer server.Close()
+
+	sender, err := NewAPNsSender(APNsConfig{Key: keyPEM, KeyID: "KEY123", TeamID: "TEAM123", Topic: "com.example.todo",
+		Endpoint: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	n := &Notification{Title: "Assigned", Body: "Review notes", Data: map[string]string{"task_id": "1"}}
+	require.NoError(t, sender.Send(context.Background(), "abc123", n))
+	assert.Equal(t, map[string]interface{}{"title": "Assigned", "body": "Review notes"}, payload["aps"].(map[string]interface{})["alert"])
+	assert.Equal(t, "1", payload["task_id"])
+
+	assert.ErrorIs(t, sender.Send(context.Background(), "gone", n), ErrInvalidToken)
+	assert.ErrorIs(t, sender.Send(context.Background(), "malformed", n), ErrInvalidToken)
+
+	_, err = NewAPNsSender(APNsConfig{Key: []byte("not a key")})
+	assert.Error(t, err)
+}
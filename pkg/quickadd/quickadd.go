@@ -0,0 +1,407 @@
+// Package quickadd parses tasks written in natural language, such as
+// "Pay rent tomorrow 5pm #finance !high", into a title, due date, tags, and priority.
+package quickadd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Priorities set with a ! marker
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// Task is a task parsed from text
+type Task struct {
+	Title    string     // text left once the due date, tags, and priority are removed
+	Due      *time.Time // nil when the text names no date or time
+	Tags     []string   // without the #, in lowercase and in order, without duplicates
+	Priority string     // PriorityHigh, PriorityMedium, PriorityLow, or empty
+}
+
+// ErrUnrecognizedDue is returned for due dates that do not name only a date, a time, or both
+var ErrUnrecognizedDue = errors.New("unrecognized due date")
+
+// Hours of the day used for dates named without a time
+const (
+	endOfDayHour   = 23
+	endOfDayMinute = 59
+	tonightHour    = 20
+)
+
+// priorities maps the markers of priorities, without the !, to their priority
+var priorities = map[string]string{
+	"high": PriorityHigh, "h": PriorityHigh, "1": PriorityHigh,
+	"medium": PriorityMedium, "med": PriorityMedium, "m": PriorityMedium, "2": PriorityMedium,
+	"low": PriorityLow, "l": PriorityLow, "3": PriorityLow,
+}
+
+// connectors are words dropped from the title when they introduce a date or time, as in
+// "due on friday at 5pm"
+var connectors = map[string]bool{"on": true, "at": true, "by": true, "due": true}
+
+// Weekdays are only recognized by their full names, as abbreviations such as "sun" and
+// "sat" are common words
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// Months are only recognized next to a day, as in "may 3" or "3rd may"
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January, "feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March, "apr": time.April, "april": time.April, "may": time.May,
+	"jun": time.June, "june": time.June, "jul": time.July, "july": time.July, "aug": time.August,
+	"august": time.August, "sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October, "nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+var (
+	isoDatePattern = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	dayPattern     = regexp.MustCompile(`^([1-9]|[12]\d|3[01])(st|nd|rd|th)?$`)
+	yearPattern    = regexp.MustCompile(`^\d{4}$`)
+	clock12Pattern = regexp.MustCompile(`^(1[0-2]|0?[1-9])(?::([0-5]\d))?(am|pm)?$`)
+	clock24Pattern = regexp.MustCompile(`^([01]?\d|2[0-3]):([0-5]\d)$`)
+	countPattern   = regexp.MustCompile(`^([1-9]\d{0,2}|a|an)$`)
+)
+
+// Parse parses the text of a task. Dates and times are relative to now and in its location:
+//
+//   - #tag adds a tag, and !high, !medium, or !low (or !1 to !3) sets the priority
+//   - dates are today, tonight, tomorrow, weekdays such as friday (the next one, or today),
+//     next friday (the one after), next week (its Monday), next month (its first day),
+//     in 3 days, weeks, or months, 2026-05-03, and may 3 or 3rd may with an optional year
+//   - times are 5pm, 5:30pm, 17:00, and noon, while in 2 hours or 30 minutes is from now
+//
+// Dates without a time are due at the end of the day, and tonight at 8pm. A time without a
+// date is due today, or tomorrow once the time has passed. Only the first date and time
+// are parsed, later ones are left in the title, as are words escaped with a backslash,
+// such as \friday.
+func Parse(text string, now time.Time) *Task {
+	p := &parser{now: now}
+	result := &Task{}
+
+	tokens := strings.Fields(text)
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = normalize(token)
+	}
+
+	var title []string
+	var titleTokens []int // index of the token of each title word, -1 for escaped words
+	for i := 0; i < len(tokens); {
+		token := tokens[i]
+		if escaped, ok := strings.CutPrefix(token, `\`); ok && escaped != "" {
+			title = append(title, escaped)
+			titleTokens = append(titleTokens, -1)
+			i++
+			continue
+		}
+		if tag, ok := strings.CutPrefix(words[i], "#"); ok && tag != "" {
+			result.addTag(tag)
+			i++
+			continue
+		}
+		if marker, ok := strings.CutPrefix(words[i], "!"); ok && priorities[marker] != "" {
+			result.Priority = priorities[marker]
+			i++
+			continue
+		}
+
+		if n := p.match(words[i:]); n > 0 {
+			// Drop the connectors right before the date or time
+			for j := i - 1; len(title) > 0 && titleTokens[len(title)-1] == j && connectors[words[j]]; j-- {
+				title, titleTokens = title[:len(title)-1], titleTokens[:len(title)-1]
+			}
+			i += n
+			continue
+		}
+
+		title = append(title, token)
+		titleTokens = append(titleTokens, i)
+		i++
+	}
+
+	result.Title = strings.Join(title, " ")
+	result.Due = p.due()
+	return result
+}
+
+// ParseDue parses text naming only a due date, a time, or both, such as "next friday" or
+// "tomorrow at 5pm", in the forms Parse recognizes
+func ParseDue(text string, now time.Time) (time.Time, error) {
+	parsed := Parse(text, now)
+	if parsed.Due == nil || parsed.Title != "" || len(parsed.Tags) > 0 || parsed.Priority != "" {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrUnrecognizedDue, text)
+	}
+	return *parsed.Due, nil
+}
+
+// addTag adds the tag unless the task already has it
+func (t *Task) addTag(tag string) {
+	for _, existing := range t.Tags {
+		if existing == tag {
+			return
+		}
+	}
+	t.Tags = append(t.Tags, tag)
+}
+
+// normalize returns the token in lowercase without trailing punctuation, and with the
+// periods of a.m. and p.m. removed
+func normalize(token string) string {
+	word := strings.ToLower(strings.TrimRight(token, ",;:?"))
+	word = strings.NewReplacer("a.m.", "am", "p.m.", "pm").Replace(word)
+	return strings.TrimSuffix(word, ".")
+}
+
+// parser collects the date and time named by the words of a text
+type parser struct {
+	now time.Time
+
+	date    time.Time // midnight of the date, when hasDate
+	hasDate bool
+	tonight bool // the date was named as tonight
+
+	hour, minute int
+	hasTime      bool
+
+	exact *time.Time // an exact moment relative to now, as in 2 hours
+}
+
+// match parses a date or time at the start of the words, returning the number of words it
+// spans, or 0 when the words do not start with one or the task has one already
+func (p *parser) match(words []string) int {
+	if p.exact != nil {
+		return 0
+	}
+	if !p.hasDate {
+		if n := p.matchDate(words); n > 0 {
+			return n
+		}
+	}
+	if !p.hasTime {
+		if n := p.matchTime(words); n > 0 {
+			return n
+		}
+	}
+	if !p.hasDate && !p.hasTime {
+		return p.matchRelative(words)
+	}
+	return 0
+}
+
+// matchDate parses a date at the start of the words
+func (p *parser) matchDate(words []string) int {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+
+	switch words[0] {
+	case "today":
+		p.setDate(today)
+		return 1
+	case "tonight":
+		p.setDate(today)
+		p.tonight = true
+		return 1
+	case "tomorrow", "tmr", "tmrw":
+		p.setDate(today.AddDate(0, 0, 1))
+		return 1
+	}
+	if weekday, ok := weekdays[words[0]]; ok {
+		p.setDate(nextWeekday(today, weekday))
+		return 1
+	}
+
+	if len(words) >= 2 && (words[0] == "this" || words[0] == "next") {
+		next := words[0] == "next"
+		if weekday, ok := weekdays[words[1]]; ok {
+			date := nextWeekday(today, weekday)
+			if next {
+				date = date.AddDate(0, 0, 7)
+			}
+			p.setDate(date)
+			return 2
+		}
+		if next && words[1] == "week" {
+			daysSinceMonday := (int(today.Weekday()) + 6) % 7
+			p.setDate(today.AddDate(0, 0, 7-daysSinceMonday))
+			return 2
+		}
+		if next && words[1] == "month" {
+			p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()))
+			return 2
+		}
+	}
+
+	if m := isoDatePattern.FindStringSubmatch(words[0]); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if date, ok := calendarDate(year, time.Month(month), day, today.Location()); ok {
+			p.setDate(date)
+			return 1
+		}
+		return 0
+	}
+
+	// may 3 [2027] or 3rd may [2027]
+	if len(words) >= 2 {
+		if month, ok := months[words[0]]; ok && dayPattern.MatchString(words[1]) {
+			return p.matchMonthDay(today, month, words[1], words[2:])
+		}
+		if month, ok := months[words[1]]; ok && dayPattern.MatchString(words[0]) {
+			return p.matchMonthDay(today, month, words[0], words[2:])
+		}
+	}
+	return 0
+}
+
+// matchMonthDay sets the date of the day of the month, spanning the two words naming them
+// and the year following them, if any. Without a year, the date is the next one on or
+// after today.
+func (p *parser) matchMonthDay(today time.Time, month time.Month, dayWord string, rest []string) int {
+	day, _ := strconv.Atoi(dayPattern.FindStringSubmatch(dayWord)[1])
+
+	if len(rest) > 0 && yearPattern.MatchString(rest[0]) {
+		year, _ := strconv.Atoi(rest[0])
+		if date, ok := calendarDate(year, month, day, today.Location()); ok {
+			p.setDate(date)
+			return 3
+		}
+		return 0
+	}
+
+	for year := today.Year(); year <= today.Year()+4; year++ {
+		// February 29 only exists in leap years
+		if date, ok := calendarDate(year, month, day, today.Location()); ok && !date.Before(today) {
+			p.setDate(date)
+			return 2
+		}
+	}
+	return 0
+}
+
+// matchTime parses a time of day at the start of the words
+func (p *parser) matchTime(words []string) int {
+	if words[0] == "noon" {
+		p.setTime(12, 0)
+		return 1
+	}
+
+	if m := clock24Pattern.FindStringSubmatch(words[0]); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		p.setTime(hour, minute)
+		return 1
+	}
+
+	m := clock12Pattern.FindStringSubmatch(words[0])
+	if m == nil {
+		return 0
+	}
+	n, meridiem := 1, m[3]
+	if meridiem == "" {
+		// Bare numbers are only times when followed by am or pm, as in 5 pm
+		if len(words) < 2 || (words[1] != "am" && words[1] != "pm") {
+			return 0
+		}
+		n, meridiem = 2, words[1]
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	hour %= 12
+	if meridiem == "pm" {
+		hour += 12
+	}
+	p.setTime(hour, minute)
+	return n
+}
+
+// matchRelative parses a due date relative to now at the start of the words, as in
+// in 3 days or in 2 hours
+func (p *parser) matchRelative(words []string) int {
+	if len(words) < 3 || words[0] != "in" || !countPattern.MatchString(words[1]) {
+		return 0
+	}
+	count := 1
+	if words[1] != "a" && words[1] != "an" {
+		count, _ = strconv.Atoi(words[1])
+	}
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+
+	switch strings.TrimSuffix(words[2], "s") {
+	case "minute", "min":
+		exact := p.now.Add(time.Duration(count) * time.Minute)
+		p.exact = &exact
+	case "hour", "hr":
+		exact := p.now.Add(time.Duration(count) * time.Hour)
+		p.exact = &exact
+	case "day":
+		p.setDate(today.AddDate(0, 0, count))
+	case "week":
+		p.setDate(today.AddDate(0, 0, 7*count))
+	case "month":
+		p.setDate(today.AddDate(0, count, 0))
+	default:
+		return 0
+	}
+	return 3
+}
+
+func (p *parser) setDate(date time.Time) {
+	p.date, p.hasDate = date, true
+}
+
+func (p *parser) setTime(hour, minute int) {
+	p.hour, p.minute, p.hasTime = hour, minute, true
+}
+
+// due combines the parsed date and time into the due date of the task
+func (p *parser) due() *time.Time {
+	if p.exact != nil {
+		return p.exact
+	}
+	if !p.hasDate && !p.hasTime {
+		return nil
+	}
+
+	hour, minute := endOfDayHour, endOfDayMinute
+	switch {
+	case p.hasTime:
+		hour, minute = p.hour, p.minute
+	case p.tonight:
+		hour, minute = tonightHour, 0
+	}
+
+	date := p.date
+	if !p.hasDate {
+		date = time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+	}
+	due := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+	if !p.hasDate && !due.After(p.now) {
+		due = time.Date(date.Year(), date.Month(), date.Day()+1, hour, minute, 0, 0, date.Location())
+	}
+	return &due
+}
+
+// nextWeekday returns the first date on or after the day that falls on the weekday
+func nextWeekday(day time.Time, weekday time.Weekday) time.Time {
+	return day.AddDate(0, 0, (int(weekday)-int(day.Weekday())+7)%7)
+}
+
+// calendarDate returns midnight of the date, reporting whether the date exists
+func calendarDate(year int, month time.Month, day int, loc *time.Location) (time.Time, bool) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	return date, date.Year() == year && date.Month() == month && date.Day() == day
+}
@@ -0,0 +1,100 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("EDT", -4*60*60)
+	// A Wednesday
+	now := time.Date(2026, time.October, 14, 10, 0, 0, 0, loc)
+	at := func(month time.Month, day, hour, minute int) *time.Time {
+		due := time.Date(2026, month, day, hour, minute, 0, 0, loc)
+		return &due
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want Task
+	}{
+		{"example", "Pay rent tomorrow 5pm #finance !high",
+			Task{Title: "Pay rent", Due: at(time.October, 15, 17, 0), Tags: []string{"finance"}, Priority: PriorityHigh}},
+		{"plain title", "Call the plumber", Task{Title: "Call the plumber"}},
+		{"date without time", "Renew passport today", Task{Title: "Renew passport", Due: at(time.October, 14, 23, 59)}},
+		{"tonight", "Take out the bins tonight", Task{Title: "Take out the bins", Due: at(time.October, 14, 20, 0)}},
+		{"connectors", "Submit report due on friday at 9:30am",
+			Task{Title: "Submit report", Due: at(time.October, 16, 9, 30)}},
+		{"weekday today", "Standup wednesday 17:00", Task{Title: "Standup", Due: at(time.October, 14, 17, 0)}},
+		{"next weekday", "Dentist next wednesday", Task{Title: "Dentist", Due: at(time.October, 21, 23, 59)}},
+		{"next week", "Plan sprint next week", Task{Title: "Plan sprint", Due: at(time.October, 19, 23, 59)}},
+		{"next month", "Pay invoice next month", Task{Title: "Pay invoice", Due: at(time.November, 1, 23, 59)}},
+		{"in days", "Follow up in 3 days", Task{Title: "Follow up", Due: at(time.October, 17, 23, 59)}},
+		{"in hours", "Check the oven in 2 hours", Task{Title: "Check the oven", Due: at(time.October, 14, 12, 0)}},
+		{"in a week", "Water plants in a week", Task{Title: "Water plants", Due: at(time.October, 21, 23, 59)}},
+		{"ISO date", "Tax return 2026-10-31 noon", Task{Title: "Tax return", Due: at(time.October, 31, 12, 0)}},
+		{"month day", "Book flights nov 3rd", Task{Title: "Book flights", Due: at(time.November, 3, 23, 59)}},
+		{"day month", "Party 24 December 7 pm", Task{Title: "Party", Due: at(time.December, 24, 19, 0)}},
+		{"time only, later today", "Call mom at 6pm", Task{Title: "Call mom", Due: at(time.October, 14, 18, 0)}},
+		{"time only, passed", "Stretch 8am", Task{Title: "Stretch", Due: at(time.October, 15, 8, 0)}},
+		{"tags and priority markers", "Review PR #Work #urgent #work !2",
+			Task{Title: "Review PR", Tags: []string{"work", "urgent"}, Priority: PriorityMedium}},
+		{"escaped word", `Read \monday notes`, Task{Title: "Read monday notes"}},
+		{"later dates stay in the title", "Move meeting from today to tomorrow",
+			Task{Title: "Move meeting from to tomorrow", Due: at(time.October, 14, 23, 59)}},
+		{"connector not before a date", "Look at the roof", Task{Title: "Look at the roof"}},
+		{"bare number", "Buy 5 apples", Task{Title: "Buy 5 apples"}},
+		{"unknown marker", "Wow !nice", Task{Title: "Wow !nice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.text, now)
+			assert.Equal(t, tt.want.Title, got.Title)
+			assert.Equal(t, tt.want.Tags, got.Tags)
+			assert.Equal(t, tt.want.Priority, got.Priority)
+			if tt.want.Due == nil {
+				assert.Nil(t, got.Due)
+				return
+			}
+			require.NotNil(t, got.Due)
+			assert.True(t, tt.want.Due.Equal(*got.Due), "due %s, want %s", got.Due, tt.want.Due)
+		})
+	}
+}
+
+func TestParse_MonthDayWithoutYear(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 10, 0, 0, 0, time.UTC)
+
+	got := Parse("Renew lease march 1", now)
+	require.NotNil(t, got.Due)
+	assert.Equal(t, time.Date(2027, time.March, 1, 23, 59, 0, 0, time.UTC), *got.Due)
+
+	got = Parse("Celebrate feb 29", now)
+	require.NotNil(t, got.Due)
+	assert.Equal(t, time.Date(2028, time.February, 29, 23, 59, 0, 0, time.UTC), *got.Due)
+
+	got = Parse("Nothing on feb 30", now)
+	assert.Nil(t, got.Due)
+	assert.Equal(t, "Nothing on feb 30", got.Title)
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 10, 0, 0, 0, time.UTC)
+
+	due, err := ParseDue("next friday", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.October, 23, 23, 59, 0, 0, time.UTC), due)
+
+	due, err = ParseDue("Tomorrow at 5pm", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.October, 15, 17, 0, 0, 0, time.UTC), due)
+
+	for _, text := range []string{"", "soon", "friday lunch", "friday #work", "friday !high"} {
+		_, err := ParseDue(text, now)
+		assert.ErrorIs(t, err, ErrUnrecognizedDue, text)
+	}
+}
@@ -0,0 +1,65 @@
+// Package redact removes credentials and personal data from text written to logs and sent in
+// error reports
+package redact
+
+import (
+	"io"
+	"regexp"
+)
+
+// Placeholder replaces redacted values
+const Placeholder = "[REDACTED]"
+
+// secretKey matches the end of the names of keys holding credentials, such as new_password,
+// refresh_token, or client_secret
+const secretKey = `(?:password|token|secret|api[_-]?key)`
+
+// rules are applied in order, so Authorization headers are redacted whole before the
+// credentials they hold are matched on their own
+var rules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Authorization headers, such as "Authorization: Bearer eyJ..." or {"authorization":"..."}
+	{regexp.MustCompile(`(?i)(\bauthorization["']?\s*[:=]\s*["']?)[^"'\r\n,;}]+`), "${1}" + Placeholder},
+	// Bearer and basic credentials outside of a header
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`), "${1} " + Placeholder},
+	// JSON web tokens, such as access tokens and signed links
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Placeholder},
+	// Passwords, tokens, and secrets in JSON strings, which may hold spaces
+	{regexp.MustCompile(`(?i)(\b[\w-]*` + secretKey + `"\s*:\s*")(?:[^"\\]|\\.)*`), "${1}" + Placeholder},
+	// and in query strings, forms, and key=value or key: value pairs
+	{regexp.MustCompile(`(?i)(\b[\w-]*` + secretKey + `["']?\s*[:=]\s*["']?)[^"'&\s,;}[\]]+`), "${1}" + Placeholder},
+	// Email addresses
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), Placeholder},
+}
+
+// String returns s with its email addresses, tokens, passwords, and Authorization headers
+// replaced by Placeholder
+func String(s string) string {
+	for _, rule := range rules {
+		s = rule.pattern.ReplaceAllString(s, rule.replacement)
+	}
+	return s
+}
+
+// Writer redacts everything written through it with String before passing it on. Each write is
+// redacted on its own, so values split across writes are not matched; the log package and
+// JSON encoders write whole lines.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter creates a Writer passing redacted writes on to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write redacts p and writes it to the underlying writer. It reports the length of p as
+// written, as redacting changes the length of what is written.
+func (r *Writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,228 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// maxBulkLength bounds the bulk strings read from the server
+const maxBulkLength = 64 << 20
+
+// Config configures the connection to a Redis server
+type Config struct {
+	Addr     string // host:port of the server
+	Username string // ACL user, for Redis 6 or later; empty for the default user
+	Password string
+	DB       int
+	TLS      bool
+	Timeout  time.Duration // timeout of connecting and of each command
+	PoolSize int           // idle connections kept for later commands
+}
+
+// Error is an error reply of the server, such as WRONGTYPE
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// Client sends commands to a Redis server over a pool of connections. It is safe for
+// concurrent use.
+type Client struct {
+	cfg  Config
+	idle chan *conn
+}
+
+// conn is a connection to the server with its buffered reader and writer
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// NewClient creates a client of the server. Connections are made as commands need them.
+func NewClient(cfg Config) *Client {
+	if cfg.PoolSize < 1 {
+		cfg.PoolSize = 1
+	}
+	return &Client{cfg: cfg, idle: make(chan *conn, cfg.PoolSize)}
+}
+
+// Do sends a command and returns its reply: a string for status replies, an int64 for
+// integers, a []byte for bulk strings, nil for missing values, and an []interface{} for
+// arrays. Error replies are returned as Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	reply, err := cn.do(ctx, c.cfg.Timeout, args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may hold part of a reply, so it cannot be reused
+		cn.Close()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("redis: %s: %w", args[0], err)
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes the idle connections
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// get takes an idle connection, or connects to the server
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: c.cfg.Timeout}
+	nc, err := dialer.DialContext(ctx, "tcp", c.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.TLS {
+		host, _, splitErr := net.SplitHostPort(c.cfg.Addr)
+		if splitErr != nil {
+			host = c.cfg.Addr
+		}
+		tlsConn := tls.Client(nc, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("TLS handshake: %w", err)
+		}
+		nc = tlsConn
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if c.cfg.Password != "" {
+		args := []string{"AUTH", c.cfg.Password}
+		if c.cfg.Username != "" {
+			args = []string{"AUTH", c.cfg.Username, c.cfg.Password}
+		}
+		if _, err := cn.do(ctx, c.cfg.Timeout, args); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("authenticating: %w", err)
+		}
+	}
+	if c.cfg.DB != 0 {
+		if _, err := cn.do(ctx, c.cfg.Timeout, []string{"SELECT", strconv.Itoa(c.cfg.DB)}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("selecting database %d: %w", c.cfg.DB, err)
+		}
+	}
+	return cn, nil
+}
+
+// put returns a connection to the pool, closing it when the pool is full
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// do writes a command as an array of bulk strings and reads its reply
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []string) (interface{}, error) {
+	d := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(d) {
+		d = ctxDeadline
+	}
+	cn.SetDeadline(d)
+	stop := context.AfterFunc(ctx, func() {
+		cn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	fmt.Fprintf(cn.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(cn.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+// readReply reads a RESP2 reply
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, Error(body)
+	case ':':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed integer %q", body)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n > maxBulkLength {
+			return nil, fmt.Errorf("malformed bulk length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed array length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			// Errors inside arrays, such as those of EXEC, are kept as items
+			item, err := readReply(r)
+			var replyErr Error
+			if errors.As(err, &replyErr) {
+				item, err = replyErr, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
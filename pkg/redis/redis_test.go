@@ -0,0 +1,178 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer answers commands with a handler, recording them
+type fakeServer struct {
+	mu       sync.Mutex
+	commands [][]string
+	conns    int
+}
+
+// startServer starts a server answering each command with the reply of the handler, written
+// as is
+func startServer(t *testing.T, reply func(args []string) string) (*fakeServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	srv := &fakeServer{}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			srv.mu.Lock()
+			srv.conns++
+			srv.mu.Unlock()
+			go srv.serve(conn, reply)
+		}
+	}()
+	return srv, lis.Addr().String()
+}
+
+func (s *fakeServer) serve(conn net.Conn, reply func(args []string) string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		s.mu.Unlock()
+		if _, err := io.WriteString(conn, reply(args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func TestClient_Do(t *testing.T) {
+	srv, addr := startServer(t, func(args []string) string {
+		switch args[0] {
+		case "AUTH", "SELECT":
+			return "+OK\r\n"
+		case "GET":
+			if args[1] == "missing" {
+				return "$-1\r\n"
+			}
+			return "$5\r\nhello\r\n"
+		case "DEL":
+			return fmt.Sprintf(":%d\r\n", len(args)-1)
+		case "HGETALL":
+			return "*2\r\n$1\r\na\r\n$1\r\n1\r\n"
+		default:
+			return "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+	})
+
+	client := NewClient(Config{Addr: addr, Username: "app", Password: "secret", DB: 2, Timeout: time.Second})
+	defer client.Close()
+	ctx := context.Background()
+
+	reply, err := client.Do(ctx, "GET", "greeting")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), reply)
+
+	reply, err = client.Do(ctx, "GET", "missing")
+	require.NoError(t, err)
+	assert.Nil(t, reply)
+
+	reply, err = client.Do(ctx, "DEL", "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), reply)
+
+	reply, err = client.Do(ctx, "HGETALL", "h")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{[]byte("a"), []byte("1")}, reply)
+
+	// Error replies leave the connection usable
+	_, err = client.Do(ctx, "FLUSHALL")
+	var replyErr Error
+	require.ErrorAs(t, err, &replyErr)
+	assert.Equal(t, "redis: ERR unknown command 'FLUSHALL'", err.Error())
+	_, err = client.Do(ctx, "GET", "greeting")
+	require.NoError(t, err)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Equal(t, 1, srv.conns)
+	assert.Equal(t, []string{"AUTH", "app", "secret"}, srv.commands[0])
+	assert.Equal(t, []string{"SELECT", "2"}, srv.commands[1])
+}
+
+func TestClient_Do_AuthFailure(t *testing.T) {
+	_, addr := startServer(t, func(args []string) string {
+		return "-WRONGPASS invalid username-password pair\r\n"
+	})
+
+	client := NewClient(Config{Addr: addr, Password: "wrong", Timeout: time.Second})
+	_, err := client.Do(context.Background(), "PING")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authenticating: redis: WRONGPASS")
+}
+
+func TestClient_Do_Timeout(t *testing.T) {
+	srv, addr := startServer(t, func(args []string) string {
+		if args[0] == "BLPOP" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return "+PONG\r\n"
+	})
+
+	client := NewClient(Config{Addr: addr, Timeout: 20 * time.Millisecond})
+	_, err := client.Do(context.Background(), "BLPOP", "queue", "0")
+	require.Error(t, err)
+
+	// The connection is dropped rather than reused with the late reply pending
+	reply, err := client.Do(context.Background(), "PING")
+	require.NoError(t, err)
+	assert.Equal(t, "PONG", reply)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Equal(t, 2, srv.conns)
+}
+
+func TestReadReply_Malformed(t *testing.T) {
+	for _, input := range []string{"?\r\n", "+OK\n", ":x\r\n", "$abc\r\n", "$5\r\nhi\r\n"} {
+		_, err := readReply(bufio.NewReader(strings.NewReader(input)))
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,123 @@
+package resilience
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is the state of a breaker
+type State int
+
+// States of a breaker. Closed breakers let calls through. Open breakers reject them until the
+// open timeout passes, and are then half-open: a single call is let through, closing the
+// breaker if it succeeds and opening it again otherwise.
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Breaker stops calling an integration after consecutive failures, so a failing integration
+// is given time to recover and callers fail fast instead of waiting for timeouts
+type Breaker struct {
+	integration  string
+	policy       Policy
+	stateChanged func(integration string, state State)
+	now          func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int       // consecutive failures while closed
+	openedAt time.Time // when the breaker last opened
+	probing  bool      // whether the call let through while half-open is in flight
+}
+
+// State returns the state of the breaker
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.policy.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a call may be made, returning ErrCircuitOpen otherwise
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.policy.OpenTimeout {
+			return fmt.Errorf("%s: %w", b.integration, ErrCircuitOpen)
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s: %w", b.integration, ErrCircuitOpen)
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record records the result of a call let through by allow
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case !failed:
+		b.failures = 0
+		b.probing = false
+		b.setState(StateClosed)
+	case b.state == StateHalfOpen:
+		b.probing = false
+		b.open()
+	default:
+		b.failures++
+		if b.failures >= b.policy.FailureThreshold {
+			b.open()
+		}
+	}
+}
+
+// open opens the breaker. The caller must hold the lock.
+func (b *Breaker) open() {
+	b.failures = 0
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+// setState changes the state of the breaker, calling the hook if it changed. The caller must
+// hold the lock.
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if b.stateChanged != nil {
+		b.stateChanged(b.integration, state)
+	}
+}
@@ -0,0 +1,181 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of calling an integration whose breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Policy configures how calls to integrations are retried and when their breakers open
+type Policy struct {
+	MaxAttempts      int           // attempts of each call, the first included
+	RetryBackoff     time.Duration // delay before the first retry, doubled for each later one
+	MaxBackoff       time.Duration // longest delay between attempts, Retry-After included
+	AttemptTimeout   time.Duration // timeout of each attempt, none when zero
+	FailureThreshold int           // consecutive failures opening the breaker
+	OpenTimeout      time.Duration // how long an open breaker rejects calls before letting one through
+}
+
+// backoff returns the delay before the retry following the attempt. Up to half of the delay
+// is jitter, so callers failing together are not retried together.
+func (p Policy) backoff(attempt int) time.Duration {
+	delay := p.RetryBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxBackoff)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Hooks are called as calls complete and breakers change state, to expose them as metrics.
+// Either may be nil.
+type Hooks struct {
+	// StateChanged is called with the state of each breaker when it is created and whenever
+	// it changes
+	StateChanged func(integration string, state State)
+	// Called is called after each attempt with its result: success, failure, or rejected
+	// when the breaker was open
+	Called func(integration, result string)
+}
+
+// Results of attempts reported to Hooks.Called
+const (
+	ResultSuccess  = "success"
+	ResultFailure  = "failure"
+	ResultRejected = "rejected"
+)
+
+// Group holds the breakers of the integrations, which share a policy
+type Group struct {
+	policy Policy
+	hooks  Hooks
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup creates a group of breakers with the policy
+func NewGroup(policy Policy) *Group {
+	return NewGroupWithHooks(policy, Hooks{})
+}
+
+// NewGroupWithHooks creates a group of breakers with the policy, calling the hooks as calls
+// complete and breakers change state
+func NewGroupWithHooks(policy Policy, hooks Hooks) *Group {
+	return &Group{policy: policy, hooks: hooks, breakers: make(map[string]*Breaker)}
+}
+
+// Breaker returns the breaker of the integration, creating it on first use
+func (g *Group) Breaker(integration string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.breakers[integration]
+	if !ok {
+		b = &Breaker{integration: integration, policy: g.policy, stateChanged: g.hooks.StateChanged, now: time.Now}
+		g.breakers[integration] = b
+		if b.stateChanged != nil {
+			b.stateChanged(integration, StateClosed)
+		}
+	}
+	return b
+}
+
+// Do calls fn through the breaker of the integration, retrying the failures fn marks with
+// Retryable and failures to connect, as nothing was sent. Only the last error is returned.
+func (g *Group) Do(ctx context.Context, integration string, fn func(ctx context.Context) error) error {
+	b := g.Breaker(integration)
+	for attempt := 1; ; attempt++ {
+		if err := b.allow(); err != nil {
+			g.called(integration, ResultRejected)
+			return err
+		}
+
+		attemptCtx, cancel := g.attemptContext(ctx)
+		err := fn(attemptCtx)
+		cancel()
+		b.record(err != nil)
+
+		if err == nil {
+			g.called(integration, ResultSuccess)
+			return nil
+		}
+		g.called(integration, ResultFailure)
+
+		var retryable *retryableError
+		if !(errors.As(err, &retryable) || dialFailed(err)) || attempt >= g.policy.MaxAttempts {
+			return err
+		}
+		if !sleep(ctx, g.policy.backoff(attempt)) {
+			return err
+		}
+	}
+}
+
+// Guard returns a function calling fn through the breaker of the integration like Do, for
+// clients decorated without depending on this package
+func (g *Group) Guard(integration string) func(ctx context.Context, fn func(ctx context.Context) error) error {
+	return func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return g.Do(ctx, integration, fn)
+	}
+}
+
+// attemptContext returns the context of an attempt, bounded by the attempt timeout if any
+func (g *Group) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if g.policy.AttemptTimeout > 0 {
+		return context.WithTimeout(ctx, g.policy.AttemptTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// called reports the result of an attempt to the hooks
+func (g *Group) called(integration, result string) {
+	if g.hooks.Called != nil {
+		g.hooks.Called(integration, result)
+	}
+}
+
+// sleep waits for the delay, reporting false if the context is done first
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// dialFailed reports whether the error is a failure to connect
+func dialFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryableError is a failure that may succeed when retried
+type retryableError struct {
+	err error
+}
+
+// Retryable marks the error as a failure that may succeed when retried by Do
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
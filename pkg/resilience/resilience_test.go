@@ -0,0 +1,167 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPolicy retries quickly and opens breakers after three failures
+var testPolicy = Policy{
+	MaxAttempts:      3,
+	RetryBackoff:     time.Millisecond,
+	MaxBackoff:       time.Millisecond,
+	FailureThreshold: 3,
+	OpenTimeout:      time.Minute,
+}
+
+func TestBreaker(t *testing.T) {
+	var states []State
+	g := NewGroupWithHooks(testPolicy, Hooks{StateChanged: func(integration string, state State) {
+		assert.Equal(t, "slack", integration)
+		states = append(states, state)
+	}})
+	b := g.Breaker("slack")
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	// Consecutive failures open the breaker, a success in between resets them
+	b.record(true)
+	b.record(true)
+	b.record(false)
+	b.record(true)
+	b.record(true)
+	assert.Equal(t, StateClosed, b.State())
+	b.record(true)
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+	// Once the open timeout passes, a single call is let through
+	now = now.Add(testPolicy.OpenTimeout)
+	assert.Equal(t, StateHalfOpen, b.State())
+	require.NoError(t, b.allow())
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+	// Its failure opens the breaker again, its success closes it
+	b.record(true)
+	assert.Equal(t, StateOpen, b.State())
+	now = now.Add(testPolicy.OpenTimeout)
+	require.NoError(t, b.allow())
+	b.record(false)
+	assert.Equal(t, StateClosed, b.State())
+	require.NoError(t, b.allow())
+
+	assert.Equal(t, []State{StateClosed, StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}, states)
+}
+
+func TestGroup_Do(t *testing.T) {
+	unavailable := errors.New("unavailable")
+	results := map[string]int{}
+	g := NewGroupWithHooks(testPolicy, Hooks{Called: func(integration, result string) { results[result]++ }})
+
+	// Retryable failures are retried up to the maximum number of attempts
+	calls := 0
+	err := g.Do(context.Background(), "mail", func(ctx context.Context) error {
+		calls++
+		return Retryable(unavailable)
+	})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 3, calls)
+
+	// The breaker is now open, so calls are rejected without being made
+	calls = 0
+	err = g.Do(context.Background(), "mail", func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, map[string]int{ResultFailure: 3, ResultRejected: 1}, results)
+
+	// Other failures are not retried
+	calls = 0
+	err = g.Do(context.Background(), "github", func(ctx context.Context) error {
+		calls++
+		return unavailable
+	})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 1, calls)
+}
+
+func TestTransport(t *testing.T) {
+	var requests atomic.Int32
+	var bodies []string
+	statuses := []int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	send := func(client *http.Client, method string) (*http.Response, error) {
+		requests.Store(0)
+		bodies = nil
+		req, err := http.NewRequest(method, server.URL, strings.NewReader("payload"))
+		require.NoError(t, err)
+		return client.Do(req)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		status   int
+		requests int
+	}{
+		{"rate limits are retried", http.MethodPost, []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}, http.StatusOK, 3},
+		{"up to the maximum number of attempts", http.MethodGet, []int{503, 503, 503}, http.StatusServiceUnavailable, 3},
+		{"gateway errors of idempotent requests are retried", http.MethodPut, []int{http.StatusBadGateway}, http.StatusOK, 2},
+		{"gateway errors of other requests are not", http.MethodPost, []int{http.StatusBadGateway}, http.StatusBadGateway, 1},
+		{"client errors are not", http.MethodGet, []int{http.StatusNotFound}, http.StatusNotFound, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: NewGroup(testPolicy).Transport("slack", nil)}
+			statuses = tt.statuses
+			resp, err := send(client, tt.method)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.requests, int(requests.Load()))
+			for _, body := range bodies {
+				assert.Equal(t, "payload", body)
+			}
+		})
+	}
+
+	t.Run("open breakers reject requests", func(t *testing.T) {
+		g := NewGroup(testPolicy)
+		client := &http.Client{Transport: g.Transport("slack", nil)}
+		statuses = []int{500, 500, 500}
+		for i := 0; i < 3; i++ {
+			resp, err := send(client, http.MethodPost)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+		assert.Equal(t, StateOpen, g.Breaker("slack").State())
+
+		_, err := send(client, http.MethodPost)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, 0, int(requests.Load()))
+	})
+}
@@ -0,0 +1,147 @@
+package resilience
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// transport calls an integration over HTTP through its breaker, retrying failed requests
+type transport struct {
+	group       *Group
+	integration string
+	base        http.RoundTripper
+}
+
+// Transport returns an HTTP transport calling the integration through its breaker with base,
+// http.DefaultTransport when nil. Requests are retried on rate limits and unavailability, and
+// idempotent requests also on connection failures and gateway errors. Retried requests must
+// have no body or one that can be read again, as http.NewRequest sets up for common bodies.
+func (g *Group) Transport(integration string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{group: g, integration: integration, base: base}
+}
+
+// RoundTrip sends the request, retrying it as the policy allows
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	g := t.group
+	b := g.Breaker(t.integration)
+	for attempt := 1; ; attempt++ {
+		attemptReq, cancel, err := t.attemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.allow(); err != nil {
+			cancel()
+			g.called(t.integration, ResultRejected)
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+
+		// Rate limits and server errors count against the breaker, client errors do not
+		failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		b.record(failed)
+		if !failed {
+			g.called(t.integration, ResultSuccess)
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		g.called(t.integration, ResultFailure)
+
+		if attempt >= g.policy.MaxAttempts || !retryable(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := g.policy.backoff(attempt)
+		if resp != nil {
+			if retryAfter := retryAfter(resp); retryAfter > delay {
+				delay = min(retryAfter, g.policy.MaxBackoff)
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		cancel()
+		if !sleep(req.Context(), delay) {
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// attemptRequest returns the request of the attempt, with a fresh body for retries and a
+// context bounded by the attempt timeout, if any
+func (t *transport) attemptRequest(req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := t.group.attemptContext(req.Context())
+	attemptReq := req.Clone(ctx)
+	if attempt > 1 && req.Body != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+	return attemptReq, cancel, nil
+}
+
+// retryable reports whether the failed request may be retried. Rate limited and unavailable
+// requests were not processed, so any request may be retried. Requests that failed in flight
+// or behind a gateway may have been processed, so only idempotent ones are, unless the
+// connection could not be made at all.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		return dialFailed(err) || idempotent(req)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(req)
+	default:
+		return false
+	}
+}
+
+// idempotent reports whether sending the request twice has the effect of sending it once.
+// Requests with an idempotency key are, as the integration deduplicates them.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+// retryAfter returns the delay requested by the Retry-After header of the response, in
+// seconds, if any
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// cancelBody releases the context of the attempt once the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,94 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ClamAVConfig configures the connection to a ClamAV daemon
+type ClamAVConfig struct {
+	Address string        // host:port of clamd, or the path of its Unix socket
+	Timeout time.Duration // timeout of connecting and of each scan
+}
+
+// clamAV scans files with the INSTREAM command of clamd
+type clamAV struct {
+	cfg ClamAVConfig
+}
+
+// NewClamAV creates a scanner sending files to clamd. Files larger than the StreamMaxLength
+// of clamd cannot be scanned.
+func NewClamAV(cfg ClamAVConfig) Scanner {
+	return &clamAV{cfg: cfg}
+}
+
+// Scan streams the file to clamd in chunks, each prefixed with its length, and reads the
+// verdict
+func (c *clamAV) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	conn, stop, err := dial(ctx, c.cfg.Address, c.cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("clamav: %w", err)
+	}
+	defer conn.Close()
+	defer stop()
+
+	writeErr := writeStream(bufio.NewWriter(conn), r)
+	// clamd replies before closing the connection when it rejects the stream, such as when
+	// it is too large
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		if writeErr != nil {
+			err = writeErr
+		}
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("clamav: %w", err)
+	}
+	return parseClamAVReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// writeStream writes the INSTREAM command and the file, ended by an empty chunk
+func writeStream(w *bufio.Writer, r io.Reader) error {
+	w.WriteString("zINSTREAM\x00")
+	buf := make([]byte, chunkSize)
+	var size [4]byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			w.Write(size[:])
+			w.Write(buf[:n])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading file: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	w.Write(size[:])
+	return w.Flush()
+}
+
+// parseClamAVReply parses a reply such as "stream: OK" or "stream: Eicar-Signature FOUND"
+func parseClamAVReply(reply string) (*Result, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Infected: true, Threat: strings.TrimSuffix(verdict, " FOUND")}, nil
+	case strings.HasSuffix(verdict, " ERROR"):
+		return nil, errors.New("clamav: " + strings.TrimSuffix(verdict, " ERROR"))
+	default:
+		return nil, fmt.Errorf("clamav: unexpected reply %q", reply)
+	}
+}
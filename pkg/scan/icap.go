@@ -0,0 +1,123 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icapResponseHeader is the header of the HTTP response the file is sent as
+const icapResponseHeader = "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\n\r\n"
+
+// ICAPConfig configures the connection to an ICAP server (RFC 3507)
+type ICAPConfig struct {
+	Address string        // host:port of the server
+	Service string        // path of the scanning service, e.g. avscan
+	Timeout time.Duration // timeout of connecting and of each scan
+}
+
+// icap scans files with RESPMOD requests of an ICAP server
+type icap struct {
+	cfg ICAPConfig
+}
+
+// NewICAP creates a scanner sending files to an ICAP server as HTTP responses to modify.
+// Servers answer 204 No Content for clean files; any other answer of 200 OK, such as a page
+// replacing the file, flags it.
+func NewICAP(cfg ICAPConfig) Scanner {
+	return &icap{cfg: cfg}
+}
+
+// Scan sends the file as the chunked body of a response and reads the verdict
+func (c *icap) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	conn, stop, err := dial(ctx, c.cfg.Address, c.cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("icap: %w", err)
+	}
+	defer conn.Close()
+	defer stop()
+
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD icap://%s/%s ICAP/1.0\r\n", c.cfg.Address, strings.TrimPrefix(c.cfg.Service, "/"))
+	fmt.Fprintf(w, "Host: %s\r\nAllow: 204\r\nConnection: close\r\n", c.cfg.Address)
+	fmt.Fprintf(w, "Encapsulated: res-hdr=0, res-body=%d\r\n\r\n%s", len(icapResponseHeader), icapResponseHeader)
+	if err := writeChunks(w, r); err != nil {
+		return nil, fmt.Errorf("icap: %w", err)
+	}
+
+	result, err := readICAPResponse(textproto.NewReader(bufio.NewReader(conn)))
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("icap: %w", err)
+	}
+	return result, nil
+}
+
+// writeChunks writes the file with the chunked transfer coding
+func writeChunks(w *bufio.Writer, r io.Reader) error {
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			fmt.Fprintf(w, "%x\r\n", n)
+			w.Write(buf[:n])
+			w.WriteString("\r\n")
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading file: %w", err)
+		}
+	}
+	w.WriteString("0\r\n\r\n")
+	return w.Flush()
+}
+
+// readICAPResponse reads the status and headers of the response of the server
+func readICAPResponse(r *textproto.Reader) (*Result, error) {
+	line, err := r.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	version, rest, _ := strings.Cut(line, " ")
+	codeText, _, _ := strings.Cut(rest, " ")
+	code, err := strconv.Atoi(codeText)
+	if version != "ICAP/1.0" || err != nil {
+		return nil, fmt.Errorf("malformed status line %q", line)
+	}
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	switch code {
+	case 204:
+		return &Result{}, nil
+	case 200:
+		return &Result{Infected: true, Threat: icapThreat(header)}, nil
+	default:
+		return nil, fmt.Errorf("server responded with status %d", code)
+	}
+}
+
+// icapThreat returns the threat named by the headers servers describe infections with
+func icapThreat(header textproto.MIMEHeader) string {
+	// e.g. X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;
+	for _, field := range strings.Split(header.Get("X-Infection-Found"), ";") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(field), "="); ok && strings.EqualFold(name, "Threat") && value != "" {
+			return value
+		}
+	}
+	if threat := strings.TrimSpace(header.Get("X-Virus-ID")); threat != "" {
+		return threat
+	}
+	return "blocked by the ICAP server"
+}
@@ -0,0 +1,51 @@
+// Package scan scans files for malware with a ClamAV daemon or an ICAP server, such as those
+// of enterprise antivirus gateways.
+package scan
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// chunkSize is the size of the chunks files are streamed to scanners in
+const chunkSize = 32 << 10
+
+// Result is the verdict of a scan
+type Result struct {
+	Infected bool
+	Threat   string // name of the threat found, if infected
+}
+
+// Scanner scans files for malware
+type Scanner interface {
+	// Scan reads the file to its end and reports whether it is infected. Errors mean the
+	// file could not be scanned, not that it is clean.
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// dial connects to the scanner at the address, a Unix socket when it is a path, and bounds
+// the connection by the timeout and the context
+func dial(ctx context.Context, address string, timeout time.Duration) (net.Conn, func() bool, error) {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	return conn, stop, nil
+}
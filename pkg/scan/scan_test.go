@@ -0,0 +1,178 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eicar is the standard antivirus test file
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// serve accepts connections on a local port, handling each with the handler
+func serve(t *testing.T, handle func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// fakeClamd reads an INSTREAM command and flags files containing the EICAR test string
+func fakeClamd(t *testing.T, conn net.Conn) {
+	r := bufio.NewReader(conn)
+	command, err := r.ReadString(0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "zINSTREAM\x00", command)
+
+	var file bytes.Buffer
+	for {
+		var size uint32
+		if !assert.NoError(t, binary.Read(r, binary.BigEndian, &size)) {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		_, err := io.CopyN(&file, r, int64(size))
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	switch {
+	case strings.Contains(file.String(), "EICAR"):
+		conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+	case file.Len() > 1<<20:
+		conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+	default:
+		conn.Write([]byte("stream: OK\x00"))
+	}
+}
+
+func TestClamAV(t *testing.T) {
+	address := serve(t, func(conn net.Conn) { fakeClamd(t, conn) })
+	scanner := NewClamAV(ClamAVConfig{Address: address, Timeout: time.Second})
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader("quarterly report"))
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader(eicar))
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Infected: true, Threat: "Eicar-Signature"}, result)
+
+	// Files streamed in several chunks
+	_, err = scanner.Scan(context.Background(), bytes.NewReader(make([]byte, 2<<20)))
+	assert.EqualError(t, err, "clamav: INSTREAM size limit exceeded.")
+
+	unreachable := NewClamAV(ClamAVConfig{Address: "127.0.0.1:1", Timeout: time.Second})
+	_, err = unreachable.Scan(context.Background(), strings.NewReader("report"))
+	assert.Error(t, err)
+}
+
+func TestClamAV_Timeout(t *testing.T) {
+	address := serve(t, func(conn net.Conn) {
+		io.Copy(io.Discard, conn)
+	})
+	scanner := NewClamAV(ClamAVConfig{Address: address, Timeout: 50 * time.Millisecond})
+
+	_, err := scanner.Scan(context.Background(), strings.NewReader("report"))
+	assert.Error(t, err)
+}
+
+// fakeICAP reads a RESPMOD request and flags files containing the EICAR test string
+func fakeICAP(t *testing.T, conn net.Conn, flagged string) {
+	r := bufio.NewReader(conn)
+	tp := textproto.NewReader(r)
+	line, err := tp.ReadLine()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(line, "RESPMOD icap://"), line)
+	assert.True(t, strings.HasSuffix(line, "/avscan ICAP/1.0"), line)
+	header, err := tp.ReadMIMEHeader()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "204", header.Get("Allow"))
+
+	// The encapsulated response header, then its chunked body
+	_, err = http.ReadResponse(r, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	file, err := io.ReadAll(httputil.NewChunkedReader(r))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if strings.Contains(string(file), "EICAR") {
+		conn.Write([]byte("ICAP/1.0 200 OK\r\n" + flagged + "Encapsulated: null-body=0\r\n\r\n"))
+		return
+	}
+	conn.Write([]byte("ICAP/1.0 204 No Content\r\nEncapsulated: null-body=0\r\n\r\n"))
+}
+
+func TestICAP(t *testing.T) {
+	tests := []struct {
+		name    string
+		flagged string
+		threat  string
+	}{
+		{"infection header", "X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\n", "Eicar-Test-Signature"},
+		{"virus ID header", "X-Virus-ID: EICAR\r\n", "EICAR"},
+		{"blocked", "", "blocked by the ICAP server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := serve(t, func(conn net.Conn) { fakeICAP(t, conn, tt.flagged) })
+			scanner := NewICAP(ICAPConfig{Address: address, Service: "avscan", Timeout: time.Second})
+
+			result, err := scanner.Scan(context.Background(), strings.NewReader("quarterly report"))
+			require.NoError(t, err)
+			assert.False(t, result.Infected)
+
+			result, err = scanner.Scan(context.Background(), strings.NewReader(eicar))
+			require.NoError(t, err)
+			assert.Equal(t, &Result{Infected: true, Threat: tt.threat}, result)
+		})
+	}
+}
+
+func TestICAP_Errors(t *testing.T) {
+	address := serve(t, func(conn net.Conn) {
+		bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("ICAP/1.0 404 Service Not Found\r\n\r\n"))
+	})
+	scanner := NewICAP(ICAPConfig{Address: address, Service: "missing", Timeout: time.Second})
+
+	_, err := scanner.Scan(context.Background(), strings.NewReader("report"))
+	assert.EqualError(t, err, "icap: server responded with status 404")
+}
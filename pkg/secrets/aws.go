@@ -0,0 +1,161 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSConfig configures an AWS Secrets Manager secret
+type AWSConfig struct {
+	Region          string
+	SecretID        string // name or ARN of the secret
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // optional, for temporary credentials
+	Endpoint        string // optional, e.g. for LocalStack; defaults to the regional endpoint
+	Timeout         time.Duration
+}
+
+// awsService is the service name used to sign Secrets Manager requests
+const awsService = "secretsmanager"
+
+// awsProvider implements a provider reading a secret of AWS Secrets Manager
+type awsProvider struct {
+	cfg      AWSConfig
+	client   *http.Client
+	endpoint string
+	now      func() time.Time
+}
+
+// NewAWSProvider creates a provider reading a secret of AWS Secrets Manager. The secret
+// string must be a JSON object of string values.
+func NewAWSProvider(cfg AWSConfig) Provider {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", awsService, cfg.Region)
+	}
+
+	return &awsProvider{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		endpoint: strings.TrimSuffix(endpoint, "/") + "/",
+		now:      time.Now,
+	}
+}
+
+// Name returns the name of the provider
+func (p *awsProvider) Name() string {
+	return "aws"
+}
+
+// Fetch reads the key/value pairs of the current version of the secret
+func (p *awsProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": p.cfg.SecretID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, payload)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("secrets manager responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+	if result.SecretString == nil {
+		return nil, fmt.Errorf("secret %q has no secret string", p.cfg.SecretID)
+	}
+	return decodeSecret([]byte(*result.SecretString))
+}
+
+// sign adds an AWS Signature Version 4 authorization to the request
+func (p *awsProvider) sign(req *http.Request, payload []byte) {
+	now := p.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if p.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.cfg.SessionToken)
+	}
+
+	// Every header set above is signed, along with the host
+	headers := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if p.cfg.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	sort.Strings(headers)
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(payload),
+	}, "\n")
+
+	scope := strings.Join([]string{date, p.cfg.Region, awsService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+p.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, p.cfg.Region)
+	key = hmacSHA256(key, awsService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Provider defines a secret manager holding configuration secrets.
+// Backends such as GCP Secret Manager can be plugged in by implementing this interface.
+type Provider interface {
+	// Name returns the name of the provider, e.g. vault
+	Name() string
+	// Fetch returns the secrets keyed by setting name, e.g. JWT_SECRET_KEY
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Watch fetches the secrets every interval until the context is done, passing them to
+// update. Failed fetches are logged and retried at the next interval.
+func Watch(ctx context.Context, provider Provider, interval time.Duration, update func(map[string]string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			values, err := provider.Fetch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Failed to refresh secrets from %s: %v", provider.Name(), err)
+				}
+				continue
+			}
+			update(values)
+		}
+	}
+}
+
+// decodeSecret decodes a secret holding a JSON object of string values
+func decodeSecret(data []byte) (map[string]string, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, errors.New("secret is not a JSON object")
+	}
+	return stringValues(values)
+}
+
+// stringValues checks that every value of the secret is a string
+func stringValues(values map[string]interface{}) (map[string]string, error) {
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("value of %q is not a string", key)
+		}
+		result[key] = s
+	}
+	return result, nil
+}
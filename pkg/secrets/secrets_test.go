@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/todo-api":
+			_, _ = w.Write([]byte(`{"data":{"data":{"JWT_SECRET_KEY":"from-vault"},"metadata":{"version":3}}}`))
+		case "/v1/kv/todo-api":
+			_, _ = w.Write([]byte(`{"data":{"JWT_SECRET_KEY":"from-kv-v1"}}`))
+		default:
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// KV v2 secrets are nested with their metadata
+	values, err := NewVaultProvider(VaultConfig{Address: server.URL + "/", Token: "root", Path: "/secret/data/todo-api"}).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"JWT_SECRET_KEY": "from-vault"}, values)
+
+	values, err = NewVaultProvider(VaultConfig{Address: server.URL, Token: "root", Path: "kv/todo-api"}).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"JWT_SECRET_KEY": "from-kv-v1"}, values)
+
+	_, err = NewVaultProvider(VaultConfig{Address: server.URL, Token: "wrong", Path: "kv/todo-api"}).Fetch(context.Background())
+	assert.ErrorContains(t, err, "status 403")
+}
+
+func TestAWSProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "20261016T120000Z", r.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20261016/eu-west-1/secretsmanager/aws4_request, "))
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, ")
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["SecretId"] != "todo-api" {
+			http.Error(w, `{"__type":"ResourceNotFoundException"}`, http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"Name":"todo-api","SecretString":"{\"JWT_SECRET_KEY\":\"from-aws\"}"}`))
+	}))
+	defer server.Close()
+
+	cfg := AWSConfig{
+		Region:          "eu-west-1",
+		SecretID:        "todo-api",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        server.URL,
+	}
+	provider := NewAWSProvider(cfg).(*awsProvider)
+	provider.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	values, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"JWT_SECRET_KEY": "from-aws"}, values)
+
+	provider.cfg.SecretID = "missing"
+	_, err = provider.Fetch(context.Background())
+	assert.ErrorContains(t, err, "ResourceNotFoundException")
+
+	// Without an endpoint, the regional endpoint is used
+	assert.Equal(t, "https://secretsmanager.eu-west-1.amazonaws.com/", NewAWSProvider(AWSConfig{Region: "eu-west-1"}).(*awsProvider).endpoint)
+}
+
+func TestDecodeSecretRejectsNonStringValues(t *testing.T) {
+	_, err := decodeSecret([]byte(`{"JWT_SECRET_KEY":42}`))
+	assert.ErrorContains(t, err, `value of "JWT_SECRET_KEY" is not a string`)
+
+	_, err = decodeSecret([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+// countingProvider returns the number of fetches as the JWT secret
+type countingProvider struct {
+	fetches atomic.Int32
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	n := p.fetches.Add(1)
+	return map[string]string{"JWT_SECRET_KEY": strings.Repeat("x", int(n))}, nil
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan map[string]string)
+	done := make(chan struct{})
+	go func() {
+		Watch(ctx, &countingProvider{}, time.Millisecond, func(values map[string]string) {
+			select {
+			case updates <- values:
+			case <-ctx.Done():
+			}
+		})
+		close(done)
+	}()
+
+	assert.Equal(t, "x", (<-updates)["JWT_SECRET_KEY"])
+	assert.Equal(t, "xx", (<-updates)["JWT_SECRET_KEY"])
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not return after the context was cancelled")
+	}
+}
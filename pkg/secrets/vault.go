@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultConfig configures a HashiCorp Vault secret
+type VaultConfig struct {
+	Address string // base URL of the server, e.g. https://vault.example.com:8200
+	Token   string
+	Path    string // API path of the secret, e.g. secret/data/todo-api for a KV v2 engine
+	Timeout time.Duration
+}
+
+// vaultProvider implements a provider reading a secret of a Vault KV engine
+type vaultProvider struct {
+	cfg    VaultConfig
+	client *http.Client
+	url    string
+}
+
+// NewVaultProvider creates a provider reading a secret of a Vault KV engine, version 1 or 2
+func NewVaultProvider(cfg VaultConfig) Provider {
+	return &vaultProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		url:    strings.TrimSuffix(cfg.Address, "/") + "/v1/" + strings.Trim(cfg.Path, "/"),
+	}
+}
+
+// Name returns the name of the provider
+func (p *vaultProvider) Name() string {
+	return "vault"
+}
+
+// Fetch reads the key/value pairs of the secret
+func (p *vaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vault responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	// KV v2 engines nest the secret with its metadata
+	data := result.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	return stringValues(data)
+}
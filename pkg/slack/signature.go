@@ -0,0 +1,52 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Headers of requests signed by Slack
+const (
+	SignatureHeader = "X-Slack-Signature"
+	TimestampHeader = "X-Slack-Request-Timestamp"
+)
+
+// MaxRequestAge is how old a signed request may be before it is rejected as a possible replay
+const MaxRequestAge = 5 * time.Minute
+
+// signatureVersion is the version prefix of signatures
+const signatureVersion = "v0"
+
+// ErrInvalidSignature is returned for requests that were not signed by Slack
+var ErrInvalidSignature = errors.New("invalid slack signature")
+
+// VerifySignature checks the request was signed with the signing secret of the Slack app
+// and is recent. The signature is the HMAC-SHA256 of "v0:<timestamp>:<body>".
+func VerifySignature(signingSecret, timestamp, signature string, body []byte, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > MaxRequestAge || age < -MaxRequestAge {
+		return errors.New("slack request timestamp is too old")
+	}
+
+	expected := Sign(signingSecret, timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the signature of the request body sent at the timestamp
+func Sign(signingSecret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(signatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
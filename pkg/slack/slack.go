@@ -0,0 +1,158 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the Slack Web API
+const DefaultAPIURL = "https://slack.com/api"
+
+// Message is a message posted to a Slack channel
+type Message struct {
+	Channel string `json:"channel,omitempty"` // set from the destination for bot tokens
+	Text    string `json:"text"`
+}
+
+// Client posts messages to Slack, through an incoming webhook or with a bot token
+type Client interface {
+	// PostWebhook posts the message to the incoming webhook URL
+	PostWebhook(ctx context.Context, webhookURL string, msg *Message) error
+	// PostMessage posts the message to the channel with the bot token through chat.postMessage
+	PostMessage(ctx context.Context, token, channel string, msg *Message) error
+}
+
+// client implements a Slack client over HTTP
+type client struct {
+	apiURL string
+	http   *http.Client
+}
+
+// NewClient creates a Slack client calling the Web API at the URL, DefaultAPIURL when empty
+func NewClient(apiURL string, timeout time.Duration) Client {
+	return NewClientWithTransport(apiURL, timeout, nil)
+}
+
+// NewClientWithTransport creates a Slack client like NewClient, sending requests through the
+// transport, http.DefaultTransport when nil
+func NewClientWithTransport(apiURL string, timeout time.Duration, transport http.RoundTripper) Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &client{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		http:   &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// PostWebhook posts the message to the incoming webhook URL. Webhooks respond with a plain
+// text body, "ok" on success and an error code such as no_service otherwise.
+func (c *client) PostWebhook(ctx context.Context, webhookURL string, msg *Message) error {
+	resp, err := c.post(ctx, webhookURL, "", msg)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if code := strings.TrimSpace(string(body)); code != "" {
+			return &APIError{Code: code}
+		}
+		return fmt.Errorf("slack webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PostMessage posts the message to the channel with the bot token. The Web API responds with
+// status 200 and reports failures in the body.
+func (c *client) PostMessage(ctx context.Context, token, channel string, msg *Message) error {
+	payload := *msg
+	payload.Channel = channel
+
+	resp, err := c.post(ctx, c.apiURL+"/chat.postMessage", token, &payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack responded with status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid slack response: %w", err)
+	}
+	if !result.OK {
+		return &APIError{Code: result.Error}
+	}
+	return nil
+}
+
+// post sends the message as JSON to the URL, authenticated with the token when set
+func (c *client) post(ctx context.Context, url, token string, msg *Message) (*http.Response, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.http.Do(req)
+}
+
+// APIError is an error reported by the Slack Web API, such as channel_not_found
+type APIError struct {
+	Code string
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	return "slack API error: " + e.Code
+}
+
+// IsRevoked reports whether the error means the bot token or webhook is no longer valid, so
+// the connection should be removed rather than retried
+func IsRevoked(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case "invalid_auth", "account_inactive", "token_revoked", "token_expired", "not_authed",
+		"invalid_token", "no_service":
+		return true
+	}
+	return false
+}
+
+// escaper escapes the characters Slack treats as control characters in message text
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape escapes text shown in a message, so it is not interpreted as a link or mention
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// Link formats a link to the URL labelled with the text
+func Link(url, text string) string {
+	return "<" + url + "|" + Escape(text) + ">"
+}
@@ -0,0 +1,88 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostWebhook(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	c := NewClient("", time.Second)
+	require.NoError(t, c.PostWebhook(context.Background(), server.URL+"/services/T/B/X", &Message{Text: "hello"}))
+	assert.Equal(t, "hello", received.Text)
+}
+
+func TestPostWebhookFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service"))
+	}))
+	defer server.Close()
+
+	err := NewClient("", time.Second).PostWebhook(context.Background(), server.URL, &Message{Text: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no_service")
+	assert.True(t, IsRevoked(err))
+}
+
+func TestPostMessage(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+		assert.Equal(t, "Bearer xoxb-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL+"/", time.Second)
+	require.NoError(t, c.PostMessage(context.Background(), "xoxb-token", "C123", &Message{Text: "hello"}))
+	assert.Equal(t, "C123", received.Channel)
+	assert.Equal(t, "hello", received.Text)
+}
+
+func TestPostMessageAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"token_revoked"}`))
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL, time.Second).PostMessage(context.Background(), "xoxb-token", "C123", &Message{Text: "hello"})
+	require.Error(t, err)
+	assert.True(t, IsRevoked(err))
+	assert.False(t, IsRevoked(&APIError{Code: "channel_not_found"}))
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte("command=%2Ftodo&text=add+Buy+milk")
+	signature := Sign("secret", timestamp, body)
+
+	assert.NoError(t, VerifySignature("secret", timestamp, signature, body, now.Add(time.Minute)))
+	assert.ErrorIs(t, VerifySignature("other", timestamp, signature, body, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("secret", timestamp, signature, []byte("tampered"), now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("secret", "not-a-number", signature, body, now), ErrInvalidSignature)
+	assert.Error(t, VerifySignature("secret", timestamp, signature, body, now.Add(MaxRequestAge+time.Second)))
+}
+
+func TestLink(t *testing.T) {
+	assert.Equal(t, "Ship &lt;release&gt; &amp; notes", Escape("Ship <release> & notes"))
+	assert.Equal(t, "<https://todo.example.com/tasks/1|Ship &lt;it&gt;>", Link("https://todo.example.com/tasks/1", "Ship <it>"))
+}
@@ -0,0 +1,516 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Kafka API keys and versions of the requests sent by the producer
+const (
+	kafkaProduce          int16 = 0  // v3, the first version with record batches
+	kafkaMetadata         int16 = 3  // v1
+	kafkaSaslHandshake    int16 = 17 // v1
+	kafkaSaslAuthenticate int16 = 36 // v0
+)
+
+// kafkaMaxResponseSize bounds the responses read from brokers
+const kafkaMaxResponseSize = 16 << 20
+
+// kafkaErrors names the error codes brokers commonly return to producers
+var kafkaErrors = map[int16]string{
+	2:  "CORRUPT_MESSAGE",
+	3:  "UNKNOWN_TOPIC_OR_PARTITION",
+	5:  "LEADER_NOT_AVAILABLE",
+	6:  "NOT_LEADER_OR_FOLLOWER",
+	7:  "REQUEST_TIMED_OUT",
+	10: "MESSAGE_TOO_LARGE",
+	19: "NOT_ENOUGH_REPLICAS",
+	20: "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+	29: "TOPIC_AUTHORIZATION_FAILED",
+	33: "UNSUPPORTED_SASL_MECHANISM",
+	35: "UNSUPPORTED_VERSION",
+	58: "SASL_AUTHENTICATION_FAILED",
+}
+
+// crc32c is the checksum table of record batches
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// kafkaProducer produces messages to the partitions of a Kafka topic, choosing partitions
+// by key like the Java client's default partitioner, so each key keeps to one partition
+type kafkaProducer struct {
+	cfg Config
+
+	mu          sync.Mutex
+	brokers     map[int32]string // addresses of brokers by node ID
+	leaders     []int32          // leader of each partition of the topic, by partition
+	conns       map[int32]*kafkaConn
+	correlation int32
+}
+
+// kafkaConn is a connection to a broker
+type kafkaConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewKafkaProducer creates a publisher producing messages to the topic, waiting for every
+// in-sync replica to store them. Messages are keyed by their key and carry their ID and
+// type in the event-id and event-type headers.
+func NewKafkaProducer(cfg Config) Publisher {
+	return &kafkaProducer{cfg: cfg, conns: make(map[int32]*kafkaConn)}
+}
+
+// Publish produces the messages, keeping the order of those with the same key. Partition
+// leaders are looked up first if needed; on failure, connections and leaders are dropped
+// and looked up again on the next call.
+func (p *kafkaProducer) Publish(ctx context.Context, msgs []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.produce(ctx, msgs); err != nil {
+		p.reset()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return fmt.Errorf("kafka: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connections to the brokers
+func (p *kafkaProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reset()
+	return nil
+}
+
+// reset closes the connections and forgets the partition leaders
+func (p *kafkaProducer) reset() {
+	for id, c := range p.conns {
+		c.conn.Close()
+		delete(p.conns, id)
+	}
+	p.leaders = nil
+}
+
+// produce sends one produce request to the leader of each partition receiving messages
+func (p *kafkaProducer) produce(ctx context.Context, msgs []Message) error {
+	if p.leaders == nil {
+		if err := p.refreshMetadata(ctx); err != nil {
+			return err
+		}
+	}
+
+	partitions := make(map[int32][]Message)
+	for _, msg := range msgs {
+		partition := Partition(msg, len(p.leaders))
+		partitions[partition] = append(partitions[partition], msg)
+	}
+	byLeader := make(map[int32][]int32)
+	for partition := range partitions {
+		leader := p.leaders[partition]
+		byLeader[leader] = append(byLeader[leader], partition)
+	}
+
+	now := time.Now()
+	for leader, leaderPartitions := range byLeader {
+		var e kafkaEncoder
+		e.int16(-1) // no transactional ID
+		e.int16(-1) // acks from all in-sync replicas
+		e.int32(int32(p.cfg.Timeout / time.Millisecond))
+		e.int32(1)
+		e.string(p.cfg.Topic)
+		e.int32(int32(len(leaderPartitions)))
+		for _, partition := range leaderPartitions {
+			e.int32(partition)
+			e.bytes(encodeRecordBatch(partitions[partition], now))
+		}
+
+		d, err := p.request(ctx, leader, kafkaProduce, 3, e.buf)
+		if err != nil {
+			return err
+		}
+		for topics := d.int32(); topics > 0 && d.err == nil; topics-- {
+			d.string()
+			for n := d.int32(); n > 0 && d.err == nil; n-- {
+				partition, code := d.int32(), d.int16()
+				d.int64() // base offset
+				d.int64() // log append time
+				if code != 0 && d.err == nil {
+					return fmt.Errorf("producing to partition %d: %s", partition, kafkaError(code))
+				}
+			}
+		}
+		if d.err != nil {
+			return fmt.Errorf("invalid produce response: %w", d.err)
+		}
+	}
+	return nil
+}
+
+// refreshMetadata looks up the brokers and the partition leaders of the topic from the
+// first reachable bootstrap server
+func (p *kafkaProducer) refreshMetadata(ctx context.Context) error {
+	c, err := p.connect(ctx, p.cfg.Servers)
+	if err != nil {
+		return err
+	}
+	defer c.conn.Close()
+
+	var e kafkaEncoder
+	e.int32(1)
+	e.string(p.cfg.Topic)
+	d, err := p.roundTrip(ctx, c, kafkaMetadata, 1, e.buf)
+	if err != nil {
+		return err
+	}
+
+	brokers := make(map[int32]string)
+	for n := d.int32(); n > 0 && d.err == nil; n-- {
+		id, host, port := d.int32(), d.string(), d.int32()
+		d.nullableString() // rack
+		brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.int32() // controller ID
+
+	var leaders []int32
+	var topicErr error
+	for topics := d.int32(); topics > 0 && d.err == nil; topics-- {
+		code, name := d.int16(), d.string()
+		d.bool() // internal
+		if code != 0 && name == p.cfg.Topic {
+			topicErr = fmt.Errorf("topic %s: %s", name, kafkaError(code))
+		}
+		for n := d.int32(); n > 0 && d.err == nil; n-- {
+			d.int16() // partition error, e.g. a leader being elected
+			partition, leader := d.int32(), d.int32()
+			d.int32Array() // replicas
+			d.int32Array() // in-sync replicas
+			if name != p.cfg.Topic || partition < 0 || partition >= 1<<16 {
+				continue
+			}
+			for int(partition) >= len(leaders) {
+				leaders = append(leaders, -1)
+			}
+			leaders[partition] = leader
+		}
+	}
+	if d.err != nil {
+		return fmt.Errorf("invalid metadata response: %w", d.err)
+	}
+	if topicErr != nil {
+		return topicErr
+	}
+	if len(leaders) == 0 {
+		return fmt.Errorf("topic %s has no partitions", p.cfg.Topic)
+	}
+	for partition, leader := range leaders {
+		if _, ok := brokers[leader]; !ok {
+			return fmt.Errorf("partition %d of topic %s has no leader", partition, p.cfg.Topic)
+		}
+	}
+
+	p.brokers, p.leaders = brokers, leaders
+	return nil
+}
+
+// request sends a request to the broker, connecting first if needed
+func (p *kafkaProducer) request(ctx context.Context, broker int32, apiKey, version int16, body []byte) (*kafkaDecoder, error) {
+	c, ok := p.conns[broker]
+	if !ok {
+		var err error
+		if c, err = p.connect(ctx, []string{p.brokers[broker]}); err != nil {
+			return nil, err
+		}
+		p.conns[broker] = c
+	}
+	return p.roundTrip(ctx, c, apiKey, version, body)
+}
+
+// connect connects to the first reachable server, over TLS and authenticating with SASL
+// PLAIN when configured
+func (p *kafkaProducer) connect(ctx context.Context, servers []string) (*kafkaConn, error) {
+	conn, host, err := dial(ctx, servers, p.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if p.cfg.TLS {
+		conn.SetDeadline(deadline(ctx, p.cfg.Timeout))
+		if conn, err = upgradeTLS(ctx, conn, host); err != nil {
+			return nil, err
+		}
+	}
+	c := &kafkaConn{conn: conn, r: bufio.NewReader(conn)}
+
+	if p.cfg.Username != "" {
+		if err := p.authenticate(ctx, c); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// authenticate authenticates the connection with SASL PLAIN
+func (p *kafkaProducer) authenticate(ctx context.Context, c *kafkaConn) error {
+	var e kafkaEncoder
+	e.string("PLAIN")
+	d, err := p.roundTrip(ctx, c, kafkaSaslHandshake, 1, e.buf)
+	if err != nil {
+		return err
+	}
+	if code := d.int16(); code != 0 {
+		return fmt.Errorf("SASL handshake: %s", kafkaError(code))
+	}
+
+	e = kafkaEncoder{}
+	e.bytes([]byte("\x00" + p.cfg.Username + "\x00" + p.cfg.Password))
+	if d, err = p.roundTrip(ctx, c, kafkaSaslAuthenticate, 0, e.buf); err != nil {
+		return err
+	}
+	if code, message := d.int16(), d.nullableString(); code != 0 {
+		return fmt.Errorf("SASL authentication: %s: %s", kafkaError(code), message)
+	}
+	return d.err
+}
+
+// roundTrip sends a request over the connection and reads its response
+func (p *kafkaProducer) roundTrip(ctx context.Context, c *kafkaConn, apiKey, version int16, body []byte) (*kafkaDecoder, error) {
+	stop := interruptOnCancel(ctx, c.conn)
+	defer stop()
+	c.conn.SetDeadline(deadline(ctx, p.cfg.Timeout))
+
+	p.correlation++
+	var e kafkaEncoder
+	e.int32(0) // size, set below
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(p.correlation)
+	e.string("todo-api")
+	e.buf = append(e.buf, body...)
+	binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+	if _, err := c.conn.Write(e.buf); err != nil {
+		return nil, err
+	}
+
+	var header [8]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.BigEndian.Uint32(header[:4]))
+	if size < 4 || size > kafkaMaxResponseSize {
+		return nil, fmt.Errorf("invalid response size %d", size)
+	}
+	if correlation := int32(binary.BigEndian.Uint32(header[4:])); correlation != p.correlation {
+		return nil, fmt.Errorf("response to request %d received for request %d", correlation, p.correlation)
+	}
+	response := make([]byte, size-4)
+	if _, err := io.ReadFull(c.r, response); err != nil {
+		return nil, err
+	}
+	return &kafkaDecoder{buf: response}, nil
+}
+
+// Partition returns the partition of the message among n partitions: the murmur2 hash of
+// its key, as the Java client's default partitioner computes, or of its ID when unkeyed
+func Partition(msg Message, n int) int32 {
+	key := msg.Key
+	if key == "" {
+		key = msg.ID
+	}
+	return (murmur2([]byte(key)) & 0x7fffffff) % int32(n)
+}
+
+// murmur2 returns the murmur2 hash of the data, as computed by Kafka clients
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := length &^ 3
+	switch length & 3 {
+	case 3:
+		h ^= uint32(data[tail+2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[tail+1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[tail])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// encodeRecordBatch encodes the messages as an uncompressed record batch (magic 2)
+func encodeRecordBatch(msgs []Message, now time.Time) []byte {
+	var records []byte
+	for i, msg := range msgs {
+		var record []byte
+		record = append(record, 0)                       // attributes
+		record = binary.AppendVarint(record, 0)          // timestamp delta
+		record = binary.AppendVarint(record, int64(i))   // offset delta
+		record = appendVarBytes(record, []byte(msg.Key)) // key
+		record = appendVarBytes(record, msg.Value)       // value
+		record = binary.AppendVarint(record, 2)          // headers
+		record = appendVarBytes(record, []byte("event-id"))
+		record = appendVarBytes(record, []byte(msg.ID))
+		record = appendVarBytes(record, []byte("event-type"))
+		record = appendVarBytes(record, []byte(msg.Type))
+
+		records = binary.AppendVarint(records, int64(len(record)))
+		records = append(records, record...)
+	}
+
+	// Fields covered by the checksum
+	timestamp := uint64(now.UnixMilli())
+	var body []byte
+	body = binary.BigEndian.AppendUint16(body, 0)                   // attributes: no compression
+	body = binary.BigEndian.AppendUint32(body, uint32(len(msgs)-1)) // last offset delta
+	body = binary.BigEndian.AppendUint64(body, timestamp)           // first timestamp
+	body = binary.BigEndian.AppendUint64(body, timestamp)           // max timestamp
+	body = binary.BigEndian.AppendUint64(body, ^uint64(0))          // no producer ID
+	body = binary.BigEndian.AppendUint16(body, ^uint16(0))          // no producer epoch
+	body = binary.BigEndian.AppendUint32(body, ^uint32(0))          // no base sequence
+	body = binary.BigEndian.AppendUint32(body, uint32(len(msgs)))
+	body = append(body, records...)
+
+	var batch []byte
+	batch = binary.BigEndian.AppendUint64(batch, 0)                       // base offset, assigned by the broker
+	batch = binary.BigEndian.AppendUint32(batch, uint32(4+1+4+len(body))) // length of the rest of the batch
+	batch = binary.BigEndian.AppendUint32(batch, ^uint32(0))              // partition leader epoch
+	batch = append(batch, 2)                                              // magic
+	batch = binary.BigEndian.AppendUint32(batch, crc32.Checksum(body, crc32c))
+	return append(batch, body...)
+}
+
+// appendVarBytes appends bytes prefixed with their varint length, or -1 when empty
+func appendVarBytes(b, data []byte) []byte {
+	if len(data) == 0 {
+		return binary.AppendVarint(b, -1)
+	}
+	b = binary.AppendVarint(b, int64(len(data)))
+	return append(b, data...)
+}
+
+// kafkaError describes an error code returned by a broker
+func kafkaError(code int16) string {
+	if name, ok := kafkaErrors[code]; ok {
+		return name
+	}
+	return "error code " + strconv.Itoa(int(code))
+}
+
+// kafkaEncoder encodes the fields of requests
+type kafkaEncoder struct {
+	buf []byte
+}
+
+func (e *kafkaEncoder) int16(v int16) {
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+}
+
+func (e *kafkaEncoder) int32(v int32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+}
+
+func (e *kafkaEncoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *kafkaEncoder) bytes(b []byte) {
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// kafkaDecoder decodes the fields of responses, keeping the first error
+type kafkaDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *kafkaDecoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errors.New("truncated response")
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *kafkaDecoder) bool() bool {
+	b := d.take(1)
+	return b != nil && b[0] != 0
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) string() string {
+	return string(d.take(int(d.int16())))
+}
+
+func (d *kafkaDecoder) nullableString() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func (d *kafkaDecoder) int32Array() {
+	if n := d.int32(); n > 0 {
+		d.take(4 * int(n))
+	}
+}
@@ -0,0 +1,295 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// natsMaxControlLine bounds the protocol lines read from the server
+const natsMaxControlLine = 4096
+
+// natsPublisher publishes messages to NATS JetStream, waiting for the stream to acknowledge
+// each one before sending the next, so messages are stored in publish order
+type natsPublisher struct {
+	cfg Config
+
+	mu    sync.Mutex
+	conn  net.Conn
+	r     *bufio.Reader
+	inbox string // subject prefix of acknowledgements
+	next  int    // sequence of the last acknowledgement subject
+}
+
+// natsInfo is the INFO message the server greets clients with
+type natsInfo struct {
+	Headers     bool `json:"headers"`
+	TLSRequired bool `json:"tls_required"`
+}
+
+// natsAck is the acknowledgement of a message stored by JetStream
+type natsAck struct {
+	Stream string `json:"stream"`
+	Seq    uint64 `json:"seq"`
+	Error  *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+// NewNATSPublisher creates a publisher sending messages to NATS JetStream under the subject
+// <topic>.<type>, e.g. todo.events.task.updated. A stream must capture these subjects; its
+// duplicate window discards messages published again with the same ID.
+func NewNATSPublisher(cfg Config) Publisher {
+	return &natsPublisher{cfg: cfg}
+}
+
+// Publish publishes the messages in order, connecting first if needed. The connection is
+// dropped on failure and made again on the next call.
+func (p *natsPublisher) Publish(ctx context.Context, msgs []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return fmt.Errorf("nats: %w", err)
+		}
+	}
+
+	stop := interruptOnCancel(ctx, p.conn)
+	defer stop()
+
+	for _, msg := range msgs {
+		if err := p.publish(ctx, msg); err != nil {
+			p.disconnect()
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return fmt.Errorf("nats: publishing %s: %w", msg.ID, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the server
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.disconnect()
+	return nil
+}
+
+// connect connects to the server and subscribes to acknowledgements
+func (p *natsPublisher) connect(ctx context.Context) error {
+	conn, host, err := dial(ctx, p.cfg.Servers, p.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(deadline(ctx, p.cfg.Timeout))
+	r := bufio.NewReaderSize(conn, natsMaxControlLine)
+
+	line, err := readLine(r)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("reading server info: %w", err)
+	}
+	var info natsInfo
+	if !strings.HasPrefix(line, "INFO ") || json.Unmarshal([]byte(line[len("INFO "):]), &info) != nil {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	if info.TLSRequired && !p.cfg.TLS {
+		conn.Close()
+		return errors.New("server requires TLS")
+	}
+	if !info.Headers {
+		conn.Close()
+		return errors.New("server does not support headers")
+	}
+	if p.cfg.TLS {
+		if conn, err = upgradeTLS(ctx, conn, host); err != nil {
+			return err
+		}
+		conn.SetDeadline(deadline(ctx, p.cfg.Timeout))
+		r = bufio.NewReaderSize(conn, natsMaxControlLine)
+	}
+
+	connect := map[string]interface{}{
+		"verbose":       false,
+		"pedantic":      false,
+		"tls_required":  p.cfg.TLS,
+		"name":          "todo-api",
+		"lang":          "go",
+		"version":       "1.0.0",
+		"protocol":      1,
+		"headers":       true,
+		"no_responders": true,
+	}
+	if p.cfg.Username != "" {
+		connect["user"], connect["pass"] = p.cfg.Username, p.cfg.Password
+	}
+	options, err := json.Marshal(connect)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	inbox := "_INBOX." + randomToken()
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\nSUB %s.* 1\r\n", options, inbox); err != nil {
+		conn.Close()
+		return err
+	}
+
+	// The server answers the ping once it accepted the connection
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("connecting: %w", err)
+		}
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return fmt.Errorf("connecting: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+
+	p.conn, p.r, p.inbox = conn, r, inbox
+	return nil
+}
+
+// disconnect closes the connection, if any
+func (p *natsPublisher) disconnect() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.r = nil, nil
+	}
+}
+
+// publish sends a message and waits for JetStream to acknowledge it. The message ID is sent
+// in the Nats-Msg-Id header for the stream to discard duplicates.
+func (p *natsPublisher) publish(ctx context.Context, msg Message) error {
+	p.next++
+	reply := p.inbox + "." + strconv.Itoa(p.next)
+	subject := p.cfg.Topic + "." + msg.Type
+	header := "NATS/1.0\r\nNats-Msg-Id: " + msg.ID + "\r\n\r\n"
+
+	p.conn.SetDeadline(deadline(ctx, p.cfg.Timeout))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HPUB %s %s %d %d\r\n", subject, reply, len(header), len(header)+len(msg.Value))
+	buf.WriteString(header)
+	buf.Write(msg.Value)
+	buf.WriteString("\r\n")
+	if _, err := p.conn.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	for {
+		line, err := readLine(p.r)
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "PING":
+			if _, err := io.WriteString(p.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case "-ERR":
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case "MSG", "HMSG":
+			subject, headers, payload, err := p.readMessage(fields)
+			if err != nil {
+				return err
+			}
+			// Acknowledgements of earlier attempts are skipped
+			if subject != reply {
+				continue
+			}
+			return parseAck(headers, payload)
+		}
+	}
+}
+
+// readMessage reads the headers and payload of a MSG or HMSG delivered to the subscription
+func (p *natsPublisher) readMessage(fields []string) (subject, headers string, payload []byte, err error) {
+	// MSG <subject> <sid> [reply] <size>, HMSG <subject> <sid> [reply] <header size> <size>
+	sizes := 1
+	if fields[0] == "HMSG" {
+		sizes = 2
+	}
+	if len(fields) < 3+sizes {
+		return "", "", nil, fmt.Errorf("malformed %s", fields[0])
+	}
+	total, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || total < 0 {
+		return "", "", nil, fmt.Errorf("malformed %s", fields[0])
+	}
+	headerSize := 0
+	if sizes == 2 {
+		if headerSize, err = strconv.Atoi(fields[len(fields)-2]); err != nil || headerSize < 0 || headerSize > total {
+			return "", "", nil, fmt.Errorf("malformed %s", fields[0])
+		}
+	}
+
+	data := make([]byte, total+2)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return "", "", nil, err
+	}
+	return fields[1], string(data[:headerSize]), data[headerSize:total], nil
+}
+
+// parseAck returns the error reported by the acknowledgement of a message, if any
+func parseAck(headers string, payload []byte) error {
+	// Status headers, e.g. NATS/1.0 503, report that no stream captures the subject
+	if status, _, _ := strings.Cut(headers, "\r\n"); len(status) > len("NATS/1.0") {
+		return fmt.Errorf("no stream acknowledged the message (status %s)", strings.TrimSpace(status[len("NATS/1.0"):]))
+	}
+
+	var ack natsAck
+	if err := json.Unmarshal(payload, &ack); err != nil {
+		return fmt.Errorf("invalid acknowledgement: %w", err)
+	}
+	if ack.Error != nil {
+		return fmt.Errorf("stream rejected the message: %s (%d)", ack.Error.Description, ack.Error.Code)
+	}
+	if ack.Stream == "" {
+		return errors.New("invalid acknowledgement: no stream")
+	}
+	return nil
+}
+
+// readLine reads a protocol line without its line ending
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errors.New("protocol line too long")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// randomToken returns a random token for subjects unique to the connection
+func randomToken() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Message is an event streamed to the broker
+type Message struct {
+	ID    string // unique per event, for brokers and consumers to discard redelivered copies
+	Type  string // event type, e.g. task.updated
+	Key   string // messages with the same key are stored and delivered in publish order
+	Value []byte
+}
+
+// Publisher delivers messages to a broker. Publish returns once the broker has stored every
+// message, in order; on failure, some of them may have been stored and are published again
+// when retried, so consumers receive each message at least once.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []Message) error
+	Close() error
+}
+
+// Config configures the connection to a broker
+type Config struct {
+	Servers  []string // host:port addresses, tried in order
+	Topic    string   // Kafka topic, or prefix of NATS subjects
+	Username string   // optional
+	Password string
+	TLS      bool
+	Timeout  time.Duration // of connecting and of each request
+}
+
+// dial connects to the first reachable server, returning the connection and the host name
+// of the server for verifying its TLS certificate
+func dial(ctx context.Context, servers []string, timeout time.Duration) (net.Conn, string, error) {
+	err := errors.New("no servers configured")
+	for _, server := range servers {
+		dialer := &net.Dialer{Timeout: timeout}
+		var conn net.Conn
+		if conn, err = dialer.DialContext(ctx, "tcp", server); err == nil {
+			host, _, splitErr := net.SplitHostPort(server)
+			if splitErr != nil {
+				host = server
+			}
+			return conn, host, nil
+		}
+	}
+	return nil, "", err
+}
+
+// upgradeTLS performs the TLS handshake with the server over the connection
+func upgradeTLS(ctx context.Context, conn net.Conn, host string) (net.Conn, error) {
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// deadline returns when a request started now must finish: after the timeout, or when the
+// context ends if that is sooner
+func deadline(ctx context.Context, timeout time.Duration) time.Time {
+	d := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(d) {
+		return ctxDeadline
+	}
+	return d
+}
+
+// interruptOnCancel unblocks reads and writes on the connection once the context is
+// canceled. The returned function stops watching the context.
+func interruptOnCancel(ctx context.Context, conn net.Conn) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+}
@@ -0,0 +1,361 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMurmur2(t *testing.T) {
+	// Hashes computed by the Java client
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for data, want := range cases {
+		assert.Equal(t, want, murmur2([]byte(data)), data)
+	}
+}
+
+// fakeKafka is a single broker serving one topic, recording the records produced to it
+type fakeKafka struct {
+	t          *testing.T
+	ln         net.Listener
+	partitions int
+
+	mu      sync.Mutex
+	records map[int32][]Message
+	fail    int16 // error code returned to the next produce request
+}
+
+func newFakeKafka(t *testing.T, partitions int) *fakeKafka {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	k := &fakeKafka{t: t, ln: ln, partitions: partitions, records: make(map[int32][]Message)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go k.serve(conn)
+		}
+	}()
+	return k
+}
+
+func (k *fakeKafka) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		request := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		d := &kafkaDecoder{buf: request}
+		apiKey, _, correlation := d.int16(), d.int16(), d.int32()
+		d.nullableString() // client ID
+
+		var e kafkaEncoder
+		e.int32(0)
+		e.int32(correlation)
+		switch apiKey {
+		case kafkaMetadata:
+			k.metadata(d, &e)
+		case kafkaProduce:
+			k.produce(d, &e)
+		default:
+			return
+		}
+		binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+		if _, err := conn.Write(e.buf); err != nil {
+			return
+		}
+	}
+}
+
+func (k *fakeKafka) metadata(d *kafkaDecoder, e *kafkaEncoder) {
+	d.int32()
+	topic := d.string()
+	host, port, _ := net.SplitHostPort(k.ln.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	e.int32(1)
+	e.int32(7)
+	e.string(host)
+	e.int32(int32(portNumber))
+	e.int16(-1) // no rack
+	e.int32(7)
+	e.int32(1)
+	e.int16(0)
+	e.string(topic)
+	e.buf = append(e.buf, 0)
+	e.int32(int32(k.partitions))
+	for i := 0; i < k.partitions; i++ {
+		e.int16(0)
+		e.int32(int32(i))
+		e.int32(7)
+		e.int32(0)
+		e.int32(0)
+	}
+}
+
+func (k *fakeKafka) produce(d *kafkaDecoder, e *kafkaEncoder) {
+	d.nullableString()
+	assert.Equal(k.t, int16(-1), d.int16(), "acks")
+	d.int32()
+	d.int32()
+	topic := d.string()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	code := k.fail
+	k.fail = 0
+
+	var partitions []int32
+	for n := d.int32(); n > 0; n-- {
+		partition := d.int32()
+		batch := d.take(int(d.int32()))
+		partitions = append(partitions, partition)
+		if code == 0 {
+			k.records[partition] = append(k.records[partition], decodeRecordBatch(k.t, batch)...)
+		}
+	}
+	assert.NoError(k.t, d.err)
+
+	e.int32(1)
+	e.string(topic)
+	e.int32(int32(len(partitions)))
+	for _, partition := range partitions {
+		e.int32(partition)
+		e.int16(code)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, 0)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, 0)
+	}
+	e.int32(0) // throttle time
+}
+
+// decodeRecordBatch decodes the records of a batch, checking its checksum
+func decodeRecordBatch(t *testing.T, batch []byte) []Message {
+	require.Greater(t, len(batch), 61)
+	assert.Equal(t, uint32(len(batch)-12), binary.BigEndian.Uint32(batch[8:]))
+	assert.Equal(t, byte(2), batch[16])
+	assert.Equal(t, crc32.Checksum(batch[21:], crc32.MakeTable(crc32.Castagnoli)), binary.BigEndian.Uint32(batch[17:]))
+
+	count := int(binary.BigEndian.Uint32(batch[57:]))
+	r := batch[61:]
+	varBytes := func() string {
+		n, size := binary.Varint(r)
+		r = r[size:]
+		if n < 0 {
+			return ""
+		}
+		s := string(r[:n])
+		r = r[n:]
+		return s
+	}
+	varint := func() int64 {
+		n, size := binary.Varint(r)
+		r = r[size:]
+		return n
+	}
+
+	var msgs []Message
+	for i := 0; i < count; i++ {
+		varint()  // length
+		r = r[1:] // attributes
+		varint()  // timestamp delta
+		assert.Equal(t, int64(i), varint(), "offset delta")
+		msg := Message{Key: varBytes(), Value: []byte(varBytes())}
+		for headers := varint(); headers > 0; headers-- {
+			switch name, value := varBytes(), varBytes(); name {
+			case "event-id":
+				msg.ID = value
+			case "event-type":
+				msg.Type = value
+			}
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestKafkaProducer(t *testing.T) {
+	broker := newFakeKafka(t, 3)
+	producer := NewKafkaProducer(Config{Servers: []string{broker.ln.Addr().String()}, Topic: "todo.events", Timeout: time.Second})
+	t.Cleanup(func() { producer.Close() })
+
+	var msgs []Message
+	for i := 0; i < 6; i++ {
+		msgs = append(msgs, Message{
+			ID:    fmt.Sprintf("event-%d", i),
+			Type:  "task.updated",
+			Key:   fmt.Sprintf("task-%d", i%2),
+			Value: []byte(fmt.Sprintf(`{"n":%d}`, i)),
+		})
+	}
+	require.NoError(t, producer.Publish(context.Background(), msgs))
+
+	// Messages with the same key are stored in order in the same partition
+	broker.mu.Lock()
+	for _, key := range []string{"task-0", "task-1"} {
+		partition := Partition(Message{Key: key}, 3)
+		var ids []string
+		for _, msg := range broker.records[partition] {
+			if msg.Key == key {
+				assert.Equal(t, "task.updated", msg.Type)
+				ids = append(ids, msg.ID)
+			}
+		}
+		if key == "task-0" {
+			assert.Equal(t, []string{"event-0", "event-2", "event-4"}, ids)
+		} else {
+			assert.Equal(t, []string{"event-1", "event-3", "event-5"}, ids)
+		}
+	}
+	broker.fail = 6
+	broker.mu.Unlock()
+
+	err := producer.Publish(context.Background(), msgs[:1])
+	assert.ErrorContains(t, err, "NOT_LEADER_OR_FOLLOWER")
+
+	// Leaders are looked up again after failures
+	require.NoError(t, producer.Publish(context.Background(), msgs[:1]))
+}
+
+func TestKafkaProducer_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	producer := NewKafkaProducer(Config{Servers: []string{addr}, Topic: "todo.events", Timeout: time.Second})
+	assert.Error(t, producer.Publish(context.Background(), []Message{{ID: "1", Type: "task.created", Value: []byte("{}")}}))
+}
+
+// fakeNATS is a NATS server with a JetStream stream capturing every subject, recording the
+// messages published to it
+type fakeNATS struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []Message
+	seen     map[string]bool // message IDs, for discarding duplicates
+	reject   bool            // reject the next message
+}
+
+func newFakeNATS(t *testing.T) *fakeNATS {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeNATS{ln: ln, seen: make(map[string]bool)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"headers\":true,\"max_payload\":1048576}\r\n")
+
+	var inbox string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			// Servers ping clients too
+			fmt.Fprintf(conn, "PONG\r\nPING\r\n")
+		case "SUB":
+			inbox = strings.TrimSuffix(fields[1], "*")
+		case "HPUB":
+			headerSize, _ := strconv.Atoi(fields[3])
+			total, _ := strconv.Atoi(fields[4])
+			data := make([]byte, total+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			id := strings.TrimSpace(strings.SplitN(string(data[:headerSize]), "Nats-Msg-Id:", 2)[1])
+
+			s.mu.Lock()
+			ack := `{"stream":"EVENTS","seq":` + strconv.Itoa(len(s.messages)+1) + `}`
+			switch {
+			case s.reject:
+				s.reject = false
+				ack = `{"error":{"code":503,"description":"stream is offline"}}`
+			case s.seen[id]:
+				ack = `{"stream":"EVENTS","seq":1,"duplicate":true}`
+			default:
+				s.seen[id] = true
+				s.messages = append(s.messages, Message{ID: id, Type: fields[1], Value: data[headerSize:total]})
+			}
+			s.mu.Unlock()
+
+			if strings.HasPrefix(fields[2], inbox) {
+				fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+			}
+		}
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	server := newFakeNATS(t)
+	publisher := NewNATSPublisher(Config{Servers: []string{server.ln.Addr().String()}, Topic: "todo.events", Timeout: time.Second})
+	t.Cleanup(func() { publisher.Close() })
+
+	msgs := []Message{
+		{ID: "event-1", Type: "task.created", Key: "task-1", Value: []byte(`{"n":1}`)},
+		{ID: "event-2", Type: "auth.login_succeeded", Key: "user-1", Value: []byte(`{"n":2}`)},
+	}
+	require.NoError(t, publisher.Publish(context.Background(), msgs))
+
+	server.mu.Lock()
+	require.Len(t, server.messages, 2)
+	assert.Equal(t, Message{ID: "event-1", Type: "todo.events.task.created", Value: []byte(`{"n":1}`)}, server.messages[0])
+	assert.Equal(t, "todo.events.auth.login_succeeded", server.messages[1].Type)
+	server.reject = true
+	server.mu.Unlock()
+
+	err := publisher.Publish(context.Background(), msgs[:1])
+	assert.ErrorContains(t, err, "stream is offline")
+
+	// Messages published again are discarded by the stream
+	require.NoError(t, publisher.Publish(context.Background(), msgs))
+	server.mu.Lock()
+	assert.Len(t, server.messages, 2)
+	server.mu.Unlock()
+}
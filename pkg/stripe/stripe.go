@@ -0,0 +1,151 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the Stripe API
+const DefaultAPIURL = "https://api.stripe.com"
+
+// Subscription statuses in which the subscription is paid for. Other statuses, such as
+// past_due, unpaid, and canceled, mean the customer lost access to the plan.
+const (
+	SubscriptionActive   = "active"
+	SubscriptionTrialing = "trialing"
+)
+
+// CheckoutRequest is a request to create a Checkout Session subscribing a customer to a price
+type CheckoutRequest struct {
+	PriceID string
+	// ClientReferenceID identifies the subscriber, reported back with the completed session
+	ClientReferenceID string
+	// CustomerID reuses an existing customer; otherwise Stripe creates one
+	CustomerID    string
+	CustomerEmail string
+	SuccessURL    string
+	CancelURL     string
+}
+
+// CheckoutSession is a Checkout Session, the hosted page customers pay on
+type CheckoutSession struct {
+	ID                string `json:"id"`
+	URL               string `json:"url"`
+	ClientReferenceID string `json:"client_reference_id"`
+	Customer          string `json:"customer"`
+	Subscription      string `json:"subscription"`
+	Mode              string `json:"mode"`
+	Status            string `json:"status"`
+}
+
+// Subscription is a subscription of a customer
+type Subscription struct {
+	ID               string `json:"id"`
+	Customer         string `json:"customer"`
+	Status           string `json:"status"`
+	CurrentPeriodEnd int64  `json:"current_period_end"` // Unix time
+}
+
+// IsPaid reports whether the customer has access to the subscribed plan
+func (s *Subscription) IsPaid() bool {
+	return s.Status == SubscriptionActive || s.Status == SubscriptionTrialing
+}
+
+// Config configures a Stripe client
+type Config struct {
+	SecretKey string
+	APIURL    string // defaults to DefaultAPIURL
+	Timeout   time.Duration
+	Transport http.RoundTripper // defaults to http.DefaultTransport
+}
+
+// Client creates Checkout Sessions through the Stripe API
+type Client interface {
+	CreateCheckoutSession(ctx context.Context, req *CheckoutRequest) (*CheckoutSession, error)
+}
+
+// client implements a Stripe client over HTTP
+type client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewClient creates a Stripe client authenticating with the secret key
+func NewClient(cfg Config) Client {
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	cfg.APIURL = strings.TrimSuffix(cfg.APIURL, "/")
+	return &client{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport}}
+}
+
+// CreateCheckoutSession creates a Checkout Session in subscription mode. Customers are
+// redirected to its URL to pay, and the completed session is reported to the webhook.
+func (c *client) CreateCheckoutSession(ctx context.Context, checkout *CheckoutRequest) (*CheckoutSession, error) {
+	form := url.Values{
+		"mode":                    {"subscription"},
+		"line_items[0][price]":    {checkout.PriceID},
+		"line_items[0][quantity]": {"1"},
+		"client_reference_id":     {checkout.ClientReferenceID},
+		"success_url":             {checkout.SuccessURL},
+		"cancel_url":              {checkout.CancelURL},
+	}
+	if checkout.CustomerID != "" {
+		form.Set("customer", checkout.CustomerID)
+	} else if checkout.CustomerEmail != "" {
+		form.Set("customer_email", checkout.CustomerEmail)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.APIURL+"/v1/checkout/sessions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body struct {
+			Error APIError `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body)
+		body.Error.StatusCode = resp.StatusCode
+		return nil, &body.Error
+	}
+
+	var session CheckoutSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("invalid stripe response: %w", err)
+	}
+	if session.URL == "" {
+		return nil, errors.New("invalid stripe response: no checkout URL")
+	}
+	return &session, nil
+}
+
+// APIError is an error response of the Stripe API
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("stripe responded with status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("stripe responded with status %d", e.StatusCode)
+}
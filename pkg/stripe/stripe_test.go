@@ -0,0 +1,86 @@
+package stripe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"checkout.session.completed"}`)
+	now := time.Unix(1700000000, 0)
+	header := SignatureHeaderValue(payload, "whsec_test", now)
+
+	assert.NoError(t, VerifySignature(payload, header, "whsec_test", DefaultTolerance, now))
+	assert.NoError(t, VerifySignature(payload, "v0=ignored,"+header, "whsec_test", DefaultTolerance, now.Add(time.Minute)))
+	assert.ErrorIs(t, VerifySignature(payload, header, "whsec_other", DefaultTolerance, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature([]byte(`{}`), header, "whsec_test", DefaultTolerance, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(payload, header, "whsec_test", DefaultTolerance, now.Add(10*time.Minute)), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(payload, "t=1700000000", "whsec_test", DefaultTolerance, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(payload, "", "whsec_test", DefaultTolerance, now), ErrInvalidSignature)
+}
+
+func TestParseEvent(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"customer.subscription.updated","data":{"object":{"id":"sub_1","customer":"cus_1","status":"past_due","current_period_end":1700000000}}}`)
+	now := time.Now()
+
+	event, err := ParseEvent(payload, SignatureHeaderValue(payload, "whsec_test", now), "whsec_test", DefaultTolerance, now)
+	require.NoError(t, err)
+	assert.Equal(t, EventSubscriptionUpdated, event.Type)
+
+	subscription, err := event.Subscription()
+	require.NoError(t, err)
+	assert.Equal(t, "cus_1", subscription.Customer)
+	assert.False(t, subscription.IsPaid())
+
+	_, err = ParseEvent([]byte(`{}`), SignatureHeaderValue([]byte(`{}`), "whsec_test", now), "whsec_test", DefaultTolerance, now)
+	assert.Error(t, err)
+}
+
+func TestCreateCheckoutSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/checkout/sessions", r.URL.Path)
+		assert.Equal(t, "Bearer sk_test", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "subscription", r.PostForm.Get("mode"))
+		assert.Equal(t, "price_pro", r.PostForm.Get("line_items[0][price]"))
+		assert.Equal(t, "user-1", r.PostForm.Get("client_reference_id"))
+		assert.Equal(t, "jane@example.com", r.PostForm.Get("customer_email"))
+		assert.Empty(t, r.PostForm.Get("customer"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"cs_1","url":"https://checkout.stripe.com/c/pay/cs_1","mode":"subscription","status":"open"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{SecretKey: "sk_test", APIURL: server.URL + "/", Timeout: time.Second})
+	session, err := c.CreateCheckoutSession(context.Background(), &CheckoutRequest{
+		PriceID:           "price_pro",
+		ClientReferenceID: "user-1",
+		CustomerEmail:     "jane@example.com",
+		SuccessURL:        "https://todo.example.com/billing/success",
+		CancelURL:         "https://todo.example.com/billing",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cs_1", session.ID)
+	assert.Equal(t, "https://checkout.stripe.com/c/pay/cs_1", session.URL)
+}
+
+func TestCreateCheckoutSessionAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"type":"invalid_request_error","message":"No such price: 'price_missing'"}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{SecretKey: "sk_test", APIURL: server.URL, Timeout: time.Second})
+	_, err := c.CreateCheckoutSession(context.Background(), &CheckoutRequest{PriceID: "price_missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No such price")
+}
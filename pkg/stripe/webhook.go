@@ -0,0 +1,120 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header of webhook requests signed by Stripe
+const SignatureHeader = "Stripe-Signature"
+
+// DefaultTolerance is how old signed webhook requests may be, limiting replays
+const DefaultTolerance = 5 * time.Minute
+
+// Event types the webhook handles
+const (
+	EventCheckoutSessionCompleted = "checkout.session.completed"
+	EventSubscriptionUpdated      = "customer.subscription.updated"
+	EventSubscriptionDeleted      = "customer.subscription.deleted"
+)
+
+// ErrInvalidSignature is returned for webhook requests that were not signed by Stripe, or
+// were signed too long ago
+var ErrInvalidSignature = errors.New("invalid stripe signature")
+
+// Event is an event sent to the webhook. The object depends on the type: a Checkout Session
+// for checkout events and a subscription for subscription events.
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// CheckoutSession decodes the Checkout Session of a checkout event
+func (e *Event) CheckoutSession() (*CheckoutSession, error) {
+	var session CheckoutSession
+	if err := json.Unmarshal(e.Data.Object, &session); err != nil {
+		return nil, fmt.Errorf("invalid checkout session: %w", err)
+	}
+	return &session, nil
+}
+
+// Subscription decodes the subscription of a subscription event
+func (e *Event) Subscription() (*Subscription, error) {
+	var subscription Subscription
+	if err := json.Unmarshal(e.Data.Object, &subscription); err != nil {
+		return nil, fmt.Errorf("invalid subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// ParseEvent checks the payload was signed with the webhook secret within the tolerance of
+// now, then decodes its event
+func ParseEvent(payload []byte, header, secret string, tolerance time.Duration, now time.Time) (*Event, error) {
+	if err := VerifySignature(payload, header, secret, tolerance, now); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid stripe event: %w", err)
+	}
+	if event.Type == "" {
+		return nil, errors.New("invalid stripe event: no type")
+	}
+	return &event, nil
+}
+
+// VerifySignature checks the signature header, such as t=1492774577,v1=5257a869..., holds
+// a v1 signature of the payload made with the secret within the tolerance of now
+func VerifySignature(payload []byte, header, secret string, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 || secret == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := Sign(payload, secret, timestamp)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Sign returns the v1 signature of a payload signed at the Unix timestamp: the hex
+// HMAC-SHA256 of the timestamp and payload joined by a dot
+func Sign(payload []byte, secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaderValue returns the signature header of a payload signed at the time, as
+// Stripe sends it
+func SignatureHeaderValue(payload []byte, secret string, at time.Time) string {
+	return fmt.Sprintf("t=%d,v1=%s", at.Unix(), Sign(payload, secret, at.Unix()))
+}
@@ -0,0 +1,55 @@
+package telegram
+
+import "crypto/subtle"
+
+// SecretTokenHeader is the header carrying the secret token set with setWebhook on every
+// webhook request
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// ChatTypePrivate is the type of one-on-one chats between a user and the bot
+const ChatTypePrivate = "private"
+
+// Update is an incoming update delivered to the webhook. Only messages are handled; other
+// kinds of updates leave Message nil.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message is a message sent to the bot
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text,omitempty"`
+}
+
+// User is a Telegram user or bot
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+// Chat is the chat a message was sent in
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // private, group, supergroup, or channel
+}
+
+// Reply answers an update by calling sendMessage in the webhook response, so replies need
+// no separate request to the Bot API
+type Reply struct {
+	Method string `json:"method"`
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// NewReply creates a reply sending the text to the chat
+func NewReply(chatID int64, text string) *Reply {
+	return &Reply{Method: "sendMessage", ChatID: chatID, Text: text}
+}
+
+// VerifySecretToken reports whether the webhook request carries the secret token
+func VerifySecretToken(secret, token string) bool {
+	return secret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
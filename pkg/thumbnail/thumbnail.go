@@ -0,0 +1,156 @@
+// Package thumbnail scales JPEG, PNG, and GIF images down to thumbnails with the standard
+// library alone.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// MaxPixels bounds the images thumbnails are generated from, as decoding keeps the whole
+// image in memory
+const MaxPixels = 25_000_000
+
+// jpegQuality is the quality of JPEG thumbnails
+const jpegQuality = 80
+
+var (
+	// ErrUnsupported is returned for images of formats other than JPEG, PNG, and GIF
+	ErrUnsupported = errors.New("unsupported image format")
+	// ErrTooLarge is returned for images of more than MaxPixels pixels
+	ErrTooLarge = errors.New("image is too large")
+)
+
+// Image is an encoded thumbnail
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Supported reports whether thumbnails are generated for files of the content type
+func Supported(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
+
+// Generate decodes an image and scales it down to fit each size, the longest side of its
+// thumbnail in pixels. Images are never scaled up. Thumbnails of JPEG images are JPEG, and
+// those of other images PNG, which keeps their transparency; animated GIFs keep their first
+// frame.
+func Generate(r io.Reader, sizes []int) ([]*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The dimensions are checked before decoding, so large images are never allocated
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("invalid image: empty")
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels, at most %d", ErrTooLarge, cfg.Width, cfg.Height, MaxPixels)
+	}
+
+	var src image.Image
+	switch format {
+	case "jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		src, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		src, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	thumbnails := make([]*Image, 0, len(sizes))
+	for _, size := range sizes {
+		scaled := scale(src, size)
+		var encoded bytes.Buffer
+		contentType := "image/png"
+		if format == "jpeg" {
+			contentType = "image/jpeg"
+			err = jpeg.Encode(&encoded, scaled, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			err = png.Encode(&encoded, scaled)
+		}
+		if err != nil {
+			return nil, err
+		}
+		bounds := scaled.Bounds()
+		thumbnails = append(thumbnails, &Image{
+			Data:        encoded.Bytes(),
+			ContentType: contentType,
+			Width:       bounds.Dx(),
+			Height:      bounds.Dy(),
+		})
+	}
+	return thumbnails, nil
+}
+
+// Fit returns the dimensions of an image of the width and height scaled down to fit the
+// size, keeping its aspect ratio
+func Fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, max(1, (height*size+width/2)/width)
+	}
+	return max(1, (width*size+height/2)/height), size
+}
+
+// scale scales the image down to fit the size, averaging the pixels each thumbnail pixel
+// covers
+func scale(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := Fit(width, height, size)
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := y*height/dstHeight, (y+1)*height/dstHeight
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := x*width/dstWidth, (x+1)*width/dstWidth
+
+			// Premultiplied colors average without darkening transparent edges
+			var r, g, b, a uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+				}
+			}
+			n := uint64((y1 - y0) * (x1 - x0))
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
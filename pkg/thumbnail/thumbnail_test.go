@@ -0,0 +1,95 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestFit(t *testing.T) {
+	w, h := Fit(4000, 3000, 128)
+	assert.Equal(t, []int{128, 96}, []int{w, h})
+	w, h = Fit(3000, 4000, 128)
+	assert.Equal(t, []int{96, 128}, []int{w, h})
+	w, h = Fit(10000, 10, 128)
+	assert.Equal(t, []int{128, 1}, []int{w, h})
+
+	// Small images are kept at their size
+	w, h = Fit(64, 48, 128)
+	assert.Equal(t, []int{64, 48}, []int{w, h})
+}
+
+func TestGenerate(t *testing.T) {
+	// Left half red, right half transparent
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	thumbnails, err := Generate(bytes.NewReader(encodePNG(t, src)), []int{100, 1000})
+	require.NoError(t, err)
+	require.Len(t, thumbnails, 2)
+
+	small := thumbnails[0]
+	assert.Equal(t, "image/png", small.ContentType)
+	assert.Equal(t, []int{100, 50}, []int{small.Width, small.Height})
+	decoded, err := png.Decode(bytes.NewReader(small.Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), decoded.Bounds())
+	_, _, _, a := decoded.At(75, 25).RGBA()
+	assert.Zero(t, a)
+	r, _, _, a := decoded.At(25, 25).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	assert.Equal(t, uint32(0xffff), a)
+
+	// Images are never scaled up
+	assert.Equal(t, []int{400, 200}, []int{thumbnails[1].Width, thumbnails[1].Height})
+}
+
+func TestGenerate_JPEG(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 300, 600))
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, src, nil))
+
+	thumbnails, err := Generate(&buf, []int{60})
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", thumbnails[0].ContentType)
+	assert.Equal(t, []int{30, 60}, []int{thumbnails[0].Width, thumbnails[0].Height})
+	_, err = jpeg.Decode(bytes.NewReader(thumbnails[0].Data))
+	assert.NoError(t, err)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := Generate(strings.NewReader("%PDF-1.7"), []int{128})
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	// Large images are rejected before they are decoded
+	var header bytes.Buffer
+	require.NoError(t, png.Encode(&header, image.NewGray(image.Rect(0, 0, 1, 1))))
+	data := header.Bytes()
+	// A 10000x10000 header, with the checksum of the IHDR chunk updated
+	copy(data[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	_, err = Generate(bytes.NewReader(data), []int{128})
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Generate(bytes.NewReader(data[:30]), []int{128})
+	assert.Error(t, err)
+}
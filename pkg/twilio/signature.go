@@ -0,0 +1,46 @@
+package twilio
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"sort"
+)
+
+// SignatureHeader is the header of requests signed by Twilio, such as status callbacks
+const SignatureHeader = "X-Twilio-Signature"
+
+// ErrInvalidSignature is returned for requests that were not signed by Twilio
+var ErrInvalidSignature = errors.New("invalid twilio signature")
+
+// VerifySignature checks the request to the URL, with the form parameters, was signed with
+// the auth token of the account. The URL must be the one Twilio requested, including its
+// scheme and query.
+func VerifySignature(authToken, requestURL string, params url.Values, signature string) error {
+	expected := Sign(authToken, requestURL, params)
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the signature of a request to the URL with the form parameters: the base64
+// HMAC-SHA1 of the URL followed by each parameter name and value, sorted by name
+func Sign(authToken, requestURL string, params url.Values) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(requestURL))
+	for _, name := range names {
+		for _, value := range params[name] {
+			mac.Write([]byte(name + value))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,167 @@
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the Twilio REST API
+const DefaultAPIURL = "https://api.twilio.com"
+
+// Message statuses, reported when a message is sent and to its status callback. Delivered,
+// undelivered, and failed are final.
+const (
+	StatusAccepted    = "accepted"
+	StatusQueued      = "queued"
+	StatusSending     = "sending"
+	StatusSent        = "sent"
+	StatusDelivered   = "delivered"
+	StatusUndelivered = "undelivered"
+	StatusFailed      = "failed"
+)
+
+// Error codes of messages that cannot be sent to the number
+const (
+	ErrorInvalidNumber = 21211 // not a valid phone number
+	ErrorUnsubscribed  = 21610 // the recipient replied STOP
+	ErrorNotMobile     = 21614 // not a mobile number
+)
+
+// Message is an SMS sent through Twilio
+type Message struct {
+	To   string
+	From string
+	Body string
+	// StatusCallback is the URL Twilio posts the status of the message to as it changes
+	StatusCallback string
+}
+
+// SentMessage is a message accepted by Twilio
+type SentMessage struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+// Config configures a Twilio client
+type Config struct {
+	AccountSID string
+	AuthToken  string
+	APIURL     string // defaults to DefaultAPIURL
+	Timeout    time.Duration
+	Transport  http.RoundTripper // defaults to http.DefaultTransport
+}
+
+// Client sends SMS through the Twilio Programmable Messaging API
+type Client interface {
+	SendSMS(ctx context.Context, msg *Message) (*SentMessage, error)
+}
+
+// client implements a Twilio client over HTTP
+type client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewClient creates a Twilio client authenticating with the account SID and auth token
+func NewClient(cfg Config) Client {
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	cfg.APIURL = strings.TrimSuffix(cfg.APIURL, "/")
+	return &client{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport}}
+}
+
+// SendSMS queues the message for delivery. Twilio responds once the message is queued; its
+// delivery is reported to the status callback.
+func (c *client) SendSMS(ctx context.Context, msg *Message) (*SentMessage, error) {
+	form := url.Values{
+		"To":   {msg.To},
+		"From": {msg.From},
+		"Body": {msg.Body},
+	}
+	if msg.StatusCallback != "" {
+		form.Set("StatusCallback", msg.StatusCallback)
+	}
+
+	endpoint := c.cfg.APIURL + "/2010-04-01/Accounts/" + url.PathEscape(c.cfg.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.cfg.AccountSID, c.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(apiErr)
+		return nil, apiErr
+	}
+
+	var sent SentMessage
+	if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil {
+		return nil, fmt.Errorf("invalid twilio response: %w", err)
+	}
+	if sent.SID == "" {
+		return nil, errors.New("invalid twilio response: no message SID")
+	}
+	return &sent, nil
+}
+
+// APIError is an error response of the Twilio REST API
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("twilio responded with status %d: %s (%d)", e.StatusCode, e.Message, e.Code)
+	}
+	return fmt.Sprintf("twilio responded with status %d", e.StatusCode)
+}
+
+// IsUnreachable reports whether the error means messages cannot be sent to the recipient's
+// number, because it is invalid or not mobile or the recipient unsubscribed, so sending
+// should not be retried
+func IsUnreachable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return IsUnreachableCode(apiErr.Code)
+}
+
+// IsUnreachableCode reports whether the error code of a message means messages cannot be
+// sent to the recipient's number
+func IsUnreachableCode(code int) bool {
+	switch code {
+	case ErrorInvalidNumber, ErrorUnsubscribed, ErrorNotMobile:
+		return true
+	}
+	return false
+}
+
+// IsFinalStatus reports whether the message status no longer changes
+func IsFinalStatus(status string) bool {
+	switch status {
+	case StatusDelivered, StatusUndelivered, StatusFailed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package twilio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// Example of the Twilio security documentation
+	params := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	requestURL := "https://mycompany.com/myapp.php?foo=1&bar=2"
+	assert.Equal(t, "0/KCTR6DLpKmkAf8muzZqo1nDgQ=", Sign("12345", requestURL, params))
+
+	assert.NoError(t, VerifySignature("12345", requestURL, params, "0/KCTR6DLpKmkAf8muzZqo1nDgQ="))
+	assert.ErrorIs(t, VerifySignature("12345", "https://mycompany.com/myapp.php", params, "0/KCTR6DLpKmkAf8muzZqo1nDgQ="), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("54321", requestURL, params, "0/KCTR6DLpKmkAf8muzZqo1nDgQ="), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("12345", requestURL, params, ""), ErrInvalidSignature)
+}
+
+func TestSendSMS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "token", pass)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "+14155550100", r.PostForm.Get("To"))
+		assert.Equal(t, "+14155550199", r.PostForm.Get("From"))
+		assert.Equal(t, "hello", r.PostForm.Get("Body"))
+		assert.Equal(t, "https://todo.example.com/integrations/twilio/status", r.PostForm.Get("StatusCallback"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{AccountSID: "AC123", AuthToken: "token", APIURL: server.URL + "/", Timeout: time.Second})
+	sent, err := c.SendSMS(context.Background(), &Message{
+		To:             "+14155550100",
+		From:           "+14155550199",
+		Body:           "hello",
+		StatusCallback: "https://todo.example.com/integrations/twilio/status",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &SentMessage{SID: "SM123", Status: StatusQueued}, sent)
+}
+
+func TestSendSMSAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":21610,"message":"Attempt to send to unsubscribed recipient","status":400}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(Config{AccountSID: "AC123", AuthToken: "token", APIURL: server.URL, Timeout: time.Second})
+	_, err := c.SendSMS(context.Background(), &Message{To: "+14155550100", From: "+14155550199", Body: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsubscribed recipient")
+	assert.True(t, IsUnreachable(err))
+}
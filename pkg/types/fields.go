@@ -0,0 +1,100 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ParseFields parses a comma-separated fields parameter, e.g. "id,title,status"
+func ParseFields(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SelectFields projects a struct, or a slice of structs, onto the given JSON fields.
+// Field names are validated against the struct's JSON tags. With no fields the value is returned unchanged.
+func SelectFields(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return v, nil
+	}
+
+	allowed := jsonFieldNames(reflect.TypeOf(v))
+	for _, field := range fields {
+		if !allowed[field] {
+			return nil, errors.New("invalid field: " + field)
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	switch value := decoded.(type) {
+	case map[string]interface{}:
+		return pickKeys(value, fields), nil
+	case []interface{}:
+		projected := make([]map[string]interface{}, 0, len(value))
+		for _, item := range value {
+			if object, ok := item.(map[string]interface{}); ok {
+				projected = append(projected, pickKeys(object, fields))
+			}
+		}
+		return projected, nil
+	default:
+		return nil, errors.New("fields can only be selected from objects")
+	}
+}
+
+// jsonFieldNames returns the JSON field names of a struct type, looking through pointers and slices
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+
+	names := make(map[string]bool)
+	if t == nil || t.Kind() != reflect.Struct {
+		return names
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = true
+	}
+
+	return names
+}
+
+// pickKeys returns a copy of the object containing only the given keys
+func pickKeys(object map[string]interface{}, keys []string) map[string]interface{} {
+	picked := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, ok := object[key]; ok {
+			picked[key] = value
+		}
+	}
+	return picked
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fieldsItem struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Done     bool   `json:"done,omitempty"`
+	Secret   string `json:"-"`
+	Untagged string
+	internal string
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"single", "id", []string{"id"}},
+		{"spaces and empty entries", " id, ,title ,", []string{"id", "title"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseFields(tt.value))
+		})
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	item := fieldsItem{ID: "1", Title: "Write tests", Done: true, Secret: "s", Untagged: "u", internal: "i"}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		fields  []string
+		want    interface{}
+		wantErr string
+	}{
+		{"no fields", item, nil, item, ""},
+		{"struct", item, []string{"id", "title"},
+			map[string]interface{}{"id": "1", "title": "Write tests"}, ""},
+		{"pointer", &item, []string{"done"}, map[string]interface{}{"done": true}, ""},
+		{"untagged field", item, []string{"Untagged"}, map[string]interface{}{"Untagged": "u"}, ""},
+		{"omitted empty field", fieldsItem{ID: "2"}, []string{"id", "done"}, map[string]interface{}{"id": "2"}, ""},
+		{"slice", []fieldsItem{item, {ID: "2", Title: "Ship"}}, []string{"title"},
+			[]map[string]interface{}{{"title": "Write tests"}, {"title": "Ship"}}, ""},
+		{"empty slice", []*fieldsItem{}, []string{"id"}, []map[string]interface{}{}, ""},
+		{"unknown field", item, []string{"id", "owner"}, nil, "invalid field: owner"},
+		{"skipped field", item, []string{"Secret"}, nil, "invalid field: Secret"},
+		{"unexported field", item, []string{"internal"}, nil, "invalid field: internal"},
+		{"not a struct", "text", []string{"id"}, nil, "invalid field: id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectFields(tt.value, tt.fields)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
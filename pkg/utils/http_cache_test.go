@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	etag := ETag([]byte(`{"id":"1"}`))
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, etag, ETag([]byte(`{"id":"1"}`)))
+	assert.NotEqual(t, etag, ETag([]byte(`{"id":"2"}`)))
+}
+
+func TestIsNotModified(t *testing.T) {
+	etag := ETag([]byte("body"))
+	modified := time.Date(2026, time.October, 14, 10, 0, 0, 500_000_000, time.UTC)
+	at := func(t time.Time) string { return t.Format(http.TimeFormat) }
+
+	tests := []struct {
+		name            string
+		ifNoneMatch     string
+		ifModifiedSince string
+		lastModified    time.Time
+		want            bool
+	}{
+		{"no conditions", "", "", modified, false},
+		{"matching etag", etag, "", modified, true},
+		{"weak matching etag", "W/" + etag, "", modified, true},
+		{"etag in a list", `"other", ` + etag, "", modified, true},
+		{"wildcard", "*", "", modified, true},
+		{"different etag", `"other"`, "", modified, false},
+		{"etag takes precedence", `"other"`, at(modified.Add(time.Hour)), modified, false},
+		{"not modified since", "", at(modified), modified, true},
+		{"modified since", "", at(modified.Add(-time.Second)), modified, false},
+		{"unknown modification time", "", at(modified), time.Time{}, false},
+		{"invalid date", "", "yesterday", modified, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsNotModified(tt.ifNoneMatch, tt.ifModifiedSince, etag, tt.lastModified))
+		})
+	}
+}
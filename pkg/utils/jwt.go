@@ -9,18 +9,42 @@ import (
 	"github.com/google/uuid"
 )
 
+// Token types, so that refresh tokens are not accepted as access tokens and access tokens
+// cannot be refreshed
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // JWTClaims represents the JWT claims structure
 type JWTClaims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
+	UserID   uuid.UUID `json:"user_id"`
+	TenantID uuid.UUID `json:"tenant_id,omitempty"`
+	Email    string    `json:"email"`
+	Scopes   []string  `json:"scopes,omitempty"`
+	Type     string    `json:"typ"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token with the given claims
-func GenerateToken(secretKey string, userID uuid.UUID, email string, ttl time.Duration) (string, error) {
+// GenerateToken generates a JWT access token with the given claims
+func GenerateToken(secretKey string, userID uuid.UUID, email string, ttl time.Duration, scopes ...string) (string, error) {
+	return GenerateTenantToken(secretKey, userID, uuid.Nil, email, ttl, scopes...)
+}
+
+// GenerateTenantToken generates a JWT access token bound to the given tenant
+func GenerateTenantToken(secretKey string, userID, tenantID uuid.UUID, email string, ttl time.Duration, scopes ...string) (string, error) {
+	return GenerateSessionToken(secretKey, TokenTypeAccess, userID, tenantID, uuid.Nil, email, ttl, scopes...)
+}
+
+// GenerateSessionToken generates a JWT token of the type bound to the given tenant and login
+// session, whose ID is the token ID. A nil session ID leaves the token ID out.
+func GenerateSessionToken(secretKey, tokenType string, userID, tenantID, sessionID uuid.UUID, email string, ttl time.Duration, scopes ...string) (string, error) {
 	claims := &JWTClaims{
-		UserID: userID,
-		Email:  email,
+		Type:     tokenType,
+		UserID:   userID,
+		TenantID: tenantID,
+		Email:    email,
+		Scopes:   scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -29,6 +53,9 @@ func GenerateToken(secretKey string, userID uuid.UUID, email string, ttl time.Du
 			Subject:   userID.String(),
 		},
 	}
+	if sessionID != uuid.Nil {
+		claims.ID = sessionID.String()
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secretKey))
@@ -54,6 +81,36 @@ func ValidateToken(tokenString, secretKey string) (*JWTClaims, error) {
 	return nil, errors.New("invalid token")
 }
 
+// IsAccessToken reports whether the token was issued to access the API, rather than to be
+// refreshed
+func (c *JWTClaims) IsAccessToken() bool {
+	return c.Type == TokenTypeAccess
+}
+
+// SessionID returns the login session the token was issued for, or a nil ID for tokens
+// issued outside of a session
+func (c *JWTClaims) SessionID() uuid.UUID {
+	id, err := uuid.Parse(c.ID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// HasScope reports whether the claims grant the given scope.
+// Tokens issued without any scopes are treated as having full access.
+func (c *JWTClaims) HasScope(scope string) bool {
+	if len(c.Scopes) == 0 {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // ExtractTokenFromHeader extracts the token from the Authorization header
 func ExtractTokenFromHeader(authHeader string) (string, error) {
 	if authHeader == "" {
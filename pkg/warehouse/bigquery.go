@@ -0,0 +1,247 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultBigQueryEndpoint is the base URL of the BigQuery REST API
+const DefaultBigQueryEndpoint = "https://bigquery.googleapis.com"
+
+// bigQueryScope is the OAuth scope of access tokens streaming rows into tables
+const bigQueryScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+
+// BigQueryConfig configures a sink streaming rows into the tables of a BigQuery dataset
+type BigQueryConfig struct {
+	// Credentials is the JSON key of a Google service account allowed to insert rows
+	Credentials []byte
+	ProjectID   string // of the dataset; defaults to the project of the service account
+	Dataset     string
+	Endpoint    string // defaults to DefaultBigQueryEndpoint
+	Timeout     time.Duration
+}
+
+// serviceAccount is the part of a service account key used to obtain access tokens
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// bigQuerySink implements a sink through the tabledata.insertAll streaming API
+type bigQuerySink struct {
+	account  serviceAccount
+	key      *rsa.PrivateKey
+	project  string
+	dataset  string
+	endpoint string
+	client   *http.Client
+
+	mu          sync.Mutex // guards the access token
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewBigQuerySink creates a sink streaming rows into the dataset, authenticated as the
+// service account. Tables must exist with a column of each value; rows are deduplicated by
+// their ID on a best-effort basis, for about a minute.
+func NewBigQuerySink(cfg BigQueryConfig) (Sink, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(cfg.Credentials, &account); err != nil {
+		return nil, fmt.Errorf("invalid BigQuery credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" || account.TokenURI == "" {
+		return nil, errors.New("invalid BigQuery credentials: client_email, private_key, and token_uri are required")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BigQuery credentials: %w", err)
+	}
+
+	project := cfg.ProjectID
+	if project == "" {
+		project = account.ProjectID
+	}
+	if project == "" {
+		return nil, errors.New("the BigQuery project is required when the credentials have no project_id")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultBigQueryEndpoint
+	}
+	return &bigQuerySink{
+		account:  account,
+		key:      key,
+		project:  project,
+		dataset:  cfg.Dataset,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Insert streams the rows into the table in one request. A row BigQuery rejects, such as
+// one with an unknown column, fails the request without storing any of its rows.
+func (s *bigQuerySink) Insert(ctx context.Context, table string, rows []Row) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if !ValidIdentifier(table) {
+		return fmt.Errorf("bigquery: invalid table name %q", table)
+	}
+
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	type insertRow struct {
+		InsertID string                 `json:"insertId"`
+		JSON     map[string]interface{} `json:"json"`
+	}
+	payload := struct {
+		Rows []insertRow `json:"rows"`
+	}{Rows: make([]insertRow, len(rows))}
+	for i, row := range rows {
+		payload.Rows[i] = insertRow{InsertID: row.ID, JSON: bigQueryValues(row.Values)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	insertURL := fmt.Sprintf("%s/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+		s.endpoint, url.PathEscape(s.project), url.PathEscape(s.dataset), table)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, insertURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bigquery: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The access token was revoked early; the next attempt fetches a new one
+		s.mu.Lock()
+		s.accessToken = ""
+		s.mu.Unlock()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bigquery responded with status %d: %s %s", resp.StatusCode, result.Error.Status, result.Error.Message)
+	}
+
+	// Rows of a request failing for another row are reported as stopped rather than invalid
+	for _, insertErr := range result.InsertErrors {
+		for _, e := range insertErr.Errors {
+			if e.Reason != "stopped" {
+				return fmt.Errorf("bigquery rejected row %d of %s: %s: %s", insertErr.Index, table, e.Reason, e.Message)
+			}
+		}
+	}
+	if len(result.InsertErrors) > 0 {
+		return fmt.Errorf("bigquery rejected %d rows of %s", len(result.InsertErrors), table)
+	}
+	return nil
+}
+
+// bigQueryValues returns the values with times in UTC, to the microsecond, as BigQuery
+// rejects finer timestamps
+func bigQueryValues(values map[string]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(values))
+	for column, value := range values {
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format("2006-01-02T15:04:05.999999Z07:00")
+		}
+		converted[column] = value
+	}
+	return converted
+}
+
+// token returns an access token of the service account, exchanging a signed assertion for a
+// new one shortly before the current one expires
+func (s *bigQuerySink) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.account.ClientEmail,
+		"scope": bigQueryScope,
+		"aud":   s.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("google oauth: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("google oauth responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.AccessToken == "" {
+		return "", errors.New("invalid google oauth response")
+	}
+
+	s.accessToken = result.AccessToken
+	s.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
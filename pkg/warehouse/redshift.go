@@ -0,0 +1,304 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redshiftService is the service name used to sign Redshift Data API requests
+const redshiftService = "redshift-data"
+
+// defaultRedshiftPollInterval is how often the status of a statement is checked until it
+// finishes
+const defaultRedshiftPollInterval = 250 * time.Millisecond
+
+// RedshiftConfig configures a sink inserting rows into the tables of an Amazon Redshift
+// database through the Data API. Either ClusterID or Workgroup is set.
+type RedshiftConfig struct {
+	Region    string
+	ClusterID string // provisioned cluster
+	Workgroup string // Redshift Serverless workgroup
+	Database  string
+	// DBUser connects with temporary credentials of the database user, and SecretARN with
+	// the credentials of a Secrets Manager secret; Serverless workgroups may use neither
+	DBUser    string
+	SecretARN string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // optional, for temporary credentials
+	Endpoint        string // optional, e.g. for LocalStack; defaults to the regional endpoint
+	Timeout         time.Duration
+	PollInterval    time.Duration // defaults to 250ms
+}
+
+// redshiftSink implements a sink through the Redshift Data API
+type redshiftSink struct {
+	cfg      RedshiftConfig
+	client   *http.Client
+	endpoint string
+	now      func() time.Time
+}
+
+// NewRedshiftSink creates a sink inserting rows into the database through the Data API.
+// Tables must exist with a column of each value. Redshift does not deduplicate rows, so
+// queries should discard rows inserted again with the same ID.
+func NewRedshiftSink(cfg RedshiftConfig) Sink {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", redshiftService, cfg.Region)
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultRedshiftPollInterval
+	}
+
+	return &redshiftSink{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		endpoint: strings.TrimSuffix(endpoint, "/") + "/",
+		now:      time.Now,
+	}
+}
+
+// Insert inserts the rows in one INSERT statement, with the values as parameters, and waits
+// for the statement to finish. Every row must have the columns of the first.
+func (s *redshiftSink) Insert(ctx context.Context, table string, rows []Row) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if !ValidIdentifier(table) {
+		return fmt.Errorf("redshift: invalid table name %q", table)
+	}
+
+	columns := make([]string, 0, len(rows[0].Values))
+	for column := range rows[0].Values {
+		if !ValidIdentifier(column) {
+			return fmt.Errorf("redshift: invalid column name %q", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	type parameter struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	var parameters []parameter
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES ")
+	for i, row := range rows {
+		if len(row.Values) != len(columns) {
+			return fmt.Errorf("redshift: row %d of %s does not have the columns of the first", i, table)
+		}
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString("(")
+		for j, column := range columns {
+			if j > 0 {
+				sql.WriteString(", ")
+			}
+			value, ok := row.Values[column]
+			if !ok {
+				return fmt.Errorf("redshift: row %d of %s does not have the columns of the first", i, table)
+			}
+			// The Data API has no null or empty parameters
+			if value == nil {
+				sql.WriteString("NULL")
+				continue
+			}
+			formatted, err := redshiftValue(value)
+			if err != nil {
+				return fmt.Errorf("redshift: column %s of row %d: %w", column, i, err)
+			}
+			if formatted == "" {
+				sql.WriteString("''")
+				continue
+			}
+			name := "p" + strconv.Itoa(len(parameters))
+			parameters = append(parameters, parameter{Name: name, Value: formatted})
+			sql.WriteString(":" + name)
+		}
+		sql.WriteString(")")
+	}
+
+	statement := map[string]interface{}{
+		"Database": s.cfg.Database,
+		"Sql":      sql.String(),
+	}
+	if len(parameters) > 0 {
+		statement["Parameters"] = parameters
+	}
+	if s.cfg.ClusterID != "" {
+		statement["ClusterIdentifier"] = s.cfg.ClusterID
+	} else {
+		statement["WorkgroupName"] = s.cfg.Workgroup
+	}
+	if s.cfg.DBUser != "" {
+		statement["DbUser"] = s.cfg.DBUser
+	}
+	if s.cfg.SecretARN != "" {
+		statement["SecretArn"] = s.cfg.SecretARN
+	}
+
+	var executed struct {
+		ID string `json:"Id"`
+	}
+	if err := s.call(ctx, "ExecuteStatement", statement, &executed); err != nil {
+		return err
+	}
+	return s.wait(ctx, executed.ID)
+}
+
+// wait polls the status of the statement until it finishes
+func (s *redshiftSink) wait(ctx context.Context, id string) error {
+	for {
+		var described struct {
+			Status string `json:"Status"`
+			Error  string `json:"Error"`
+		}
+		if err := s.call(ctx, "DescribeStatement", map[string]string{"Id": id}, &described); err != nil {
+			return err
+		}
+
+		switch described.Status {
+		case "FINISHED":
+			return nil
+		case "FAILED", "ABORTED":
+			return fmt.Errorf("redshift statement %s %s: %s", id, strings.ToLower(described.Status), described.Error)
+		}
+
+		select {
+		case <-time.After(s.cfg.PollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("redshift statement %s: %w", id, ctx.Err())
+		}
+	}
+}
+
+// call calls the Data API action with the input, decoding its output into out
+func (s *redshiftSink) call(ctx context.Context, action string, input, out interface{}) error {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "RedshiftData."+action)
+	s.sign(req, payload)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("redshift: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("redshift %s responded with status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid redshift %s response: %w", action, err)
+	}
+	return nil
+}
+
+// redshiftValue formats a value as a statement parameter, which Redshift casts to the type
+// of its column
+func redshiftValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.UTC().Format("2006-01-02 15:04:05.999999"), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+// sign adds an AWS Signature Version 4 authorization to the request
+func (s *redshiftSink) sign(req *http.Request, payload []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+
+	// Every header set above is signed, along with the host
+	headers := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if s.cfg.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	sort.Strings(headers)
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(payload),
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.cfg.Region, redshiftService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, redshiftService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,86 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"todo-api/pkg/stream"
+)
+
+// EventsTable is the table domain events are inserted into
+const EventsTable = "events"
+
+// identifierPattern matches the table and column names sinks accept, so they never need
+// quoting or escaping
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// Row is a row inserted into a warehouse table
+type Row struct {
+	ID string // unique per row, for the sink or its queries to discard rows inserted again
+	// Values of the columns by name: strings, numbers, booleans, times, or nil for NULL
+	Values map[string]interface{}
+}
+
+// Sink inserts rows into the tables of a data warehouse. Insert returns once the warehouse
+// has stored every row; on failure, some of them may have been stored and are inserted
+// again when retried, so tables receive each row at least once.
+type Sink interface {
+	Insert(ctx context.Context, table string, rows []Row) error
+}
+
+// ValidIdentifier reports whether the name can be used as a table or column name
+func ValidIdentifier(name string) bool {
+	return identifierPattern.MatchString(name)
+}
+
+// publisher inserts streamed messages into the events table of a sink
+type publisher struct {
+	sink Sink
+}
+
+// NewPublisher creates a publisher inserting messages into the events table of the sink,
+// for the event outbox to relay domain events to the warehouse. Each message is a row of
+// its id, type, key (NULL when unset), recorded_at, and payload, the JSON document of the
+// event.
+func NewPublisher(sink Sink) stream.Publisher {
+	return &publisher{sink: sink}
+}
+
+// Publish inserts the messages in one request
+func (p *publisher) Publish(ctx context.Context, msgs []stream.Message) error {
+	rows := make([]Row, len(msgs))
+	for i, msg := range msgs {
+		// The outbox records when each event happened in its document
+		var envelope struct {
+			RecordedAt time.Time `json:"recorded_at"`
+		}
+		if err := json.Unmarshal(msg.Value, &envelope); err != nil || envelope.RecordedAt.IsZero() {
+			envelope.RecordedAt = time.Now().UTC()
+		}
+
+		var key interface{}
+		if msg.Key != "" {
+			key = msg.Key
+		}
+		rows[i] = Row{ID: msg.ID, Values: map[string]interface{}{
+			"id":          msg.ID,
+			"type":        msg.Type,
+			"key":         key,
+			"recorded_at": envelope.RecordedAt,
+			"payload":     string(msg.Value),
+		}}
+	}
+
+	if err := p.sink.Insert(ctx, EventsTable, rows); err != nil {
+		return fmt.Errorf("inserting events: %w", err)
+	}
+	return nil
+}
+
+// Close releases nothing, as sinks send each insert in its own request
+func (p *publisher) Close() error {
+	return nil
+}
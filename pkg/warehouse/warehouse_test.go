@@ -0,0 +1,212 @@
+package warehouse
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"todo-api/pkg/stream"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records the rows inserted into each table
+type recordingSink struct {
+	tables map[string][]Row
+}
+
+func (s *recordingSink) Insert(_ context.Context, table string, rows []Row) error {
+	if s.tables == nil {
+		s.tables = make(map[string][]Row)
+	}
+	s.tables[table] = append(s.tables[table], rows...)
+	return nil
+}
+
+func TestPublisher(t *testing.T) {
+	sink := &recordingSink{}
+	publisher := NewPublisher(sink)
+	defer publisher.Close()
+
+	value := []byte(`{"id":"1","type":"task.created","recorded_at":"2024-01-15T09:30:00Z","data":{}}`)
+	require.NoError(t, publisher.Publish(context.Background(), []stream.Message{
+		{ID: "1", Type: "task.created", Key: "task-1", Value: value},
+		{ID: "2", Type: "auth.login", Value: []byte(`{"id":"2"}`)},
+	}))
+
+	rows := sink.tables[EventsTable]
+	require.Len(t, rows, 2)
+	assert.Equal(t, Row{ID: "1", Values: map[string]interface{}{
+		"id":          "1",
+		"type":        "task.created",
+		"key":         "task-1",
+		"recorded_at": time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC),
+		"payload":     string(value),
+	}}, rows[0])
+
+	// Events without a key have a NULL key, and those without a time the time of insertion
+	assert.Nil(t, rows[1].Values["key"])
+	assert.WithinDuration(t, time.Now(), rows[1].Values["recorded_at"].(time.Time), time.Minute)
+}
+
+// bigQueryCredentials returns a service account key using the token URI
+func bigQueryCredentials(t *testing.T, tokenURI string) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "todo-app",
+		"client_email": "warehouse@todo-app.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    tokenURI,
+	})
+	require.NoError(t, err)
+	return credentials
+}
+
+func TestBigQuerySink(t *testing.T) {
+	var tokenRequests atomic.Int32
+	var inserted struct {
+		Rows []struct {
+			InsertID string                 `json:"insertId"`
+			JSON     map[string]interface{} `json:"json"`
+		} `json:"rows"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests.Add(1)
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+			w.Write([]byte(`{"access_token":"access-token","expires_in":3600,"token_type":"Bearer"}`))
+		case "/bigquery/v2/projects/todo-app/datasets/analytics/tables/events/insertAll":
+			assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&inserted))
+			w.Write([]byte(`{"kind":"bigquery#tableDataInsertAllResponse"}`))
+		case "/bigquery/v2/projects/todo-app/datasets/analytics/tables/task_snapshots/insertAll":
+			w.Write([]byte(`{"insertErrors":[` +
+				`{"index":0,"errors":[{"reason":"invalid","message":"no such field: title."}]},` +
+				`{"index":1,"errors":[{"reason":"stopped","message":""}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","message":"Not found: Table"}}`))
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewBigQuerySink(BigQueryConfig{
+		Credentials: bigQueryCredentials(t, server.URL+"/token"),
+		Dataset:     "analytics",
+		Endpoint:    server.URL,
+		Timeout:     time.Second,
+	})
+	require.NoError(t, err)
+
+	// Times are sent in UTC to the microsecond
+	recordedAt := time.Date(2024, 1, 15, 16, 30, 0, 123456789, time.FixedZone("WIB", 7*3600))
+	require.NoError(t, sink.Insert(context.Background(), "events", []Row{
+		{ID: "1", Values: map[string]interface{}{"id": "1", "key": nil, "recorded_at": recordedAt}},
+	}))
+	require.Len(t, inserted.Rows, 1)
+	assert.Equal(t, "1", inserted.Rows[0].InsertID)
+	assert.Equal(t, map[string]interface{}{"id": "1", "key": nil, "recorded_at": "2024-01-15T09:30:00.123456Z"}, inserted.Rows[0].JSON)
+
+	rows := []Row{{ID: "1", Values: map[string]interface{}{"title": "Pay rent"}}, {ID: "2", Values: map[string]interface{}{}}}
+	err = sink.Insert(context.Background(), "task_snapshots", rows)
+	assert.EqualError(t, err, "bigquery rejected row 0 of task_snapshots: invalid: no such field: title.")
+	err = sink.Insert(context.Background(), "missing", rows)
+	assert.EqualError(t, err, "bigquery responded with status 404: NOT_FOUND Not found: Table")
+	assert.ErrorContains(t, sink.Insert(context.Background(), "events; DROP", rows), "invalid table name")
+
+	// Access tokens are reused until they expire
+	assert.Equal(t, int32(1), tokenRequests.Load())
+
+	_, err = NewBigQuerySink(BigQueryConfig{Credentials: []byte(`{"project_id":"todo-app"}`)})
+	assert.Error(t, err)
+}
+
+func TestRedshiftSink(t *testing.T) {
+	var statement map[string]interface{}
+	var describes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20261016/us-east-1/redshift-data/aws4_request, "))
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-target, ")
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch r.Header.Get("X-Amz-Target") {
+		case "RedshiftData.ExecuteStatement":
+			statement = body
+			id := "statement-1"
+			if strings.Contains(body["Sql"].(string), "broken") {
+				id = "statement-2"
+			}
+			w.Write([]byte(`{"Id":"` + id + `"}`))
+		case "RedshiftData.DescribeStatement":
+			// Statements are polled until they finish
+			if body["Id"] == "statement-2" {
+				w.Write([]byte(`{"Status":"FAILED","Error":"relation \"broken\" does not exist"}`))
+			} else if describes.Add(1) == 1 {
+				w.Write([]byte(`{"Status":"STARTED"}`))
+			} else {
+				w.Write([]byte(`{"Status":"FINISHED"}`))
+			}
+		default:
+			http.Error(w, `{"__type":"ValidationException"}`, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewRedshiftSink(RedshiftConfig{
+		Region:          "us-east-1",
+		Workgroup:       "analytics",
+		Database:        "todo",
+		SecretARN:       "arn:aws:secretsmanager:us-east-1:123456789012:secret:warehouse",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+		Timeout:         time.Second,
+		PollInterval:    time.Millisecond,
+	}).(*redshiftSink)
+	sink.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	recordedAt := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, sink.Insert(context.Background(), "events", []Row{
+		{ID: "1", Values: map[string]interface{}{"id": "1", "key": nil, "recorded_at": recordedAt, "payload": ""}},
+		{ID: "2", Values: map[string]interface{}{"id": "2", "key": "task-1", "recorded_at": recordedAt, "payload": "{}"}},
+	}))
+	assert.Equal(t, int32(2), describes.Load())
+
+	// Nulls and empty strings are written in the statement, and other values as parameters
+	assert.Equal(t, "INSERT INTO events (id, key, payload, recorded_at) VALUES "+
+		"(:p0, NULL, '', :p1), (:p2, :p3, :p4, :p5)", statement["Sql"])
+	assert.Equal(t, "analytics", statement["WorkgroupName"])
+	assert.Equal(t, "todo", statement["Database"])
+	assert.Equal(t, map[string]interface{}{"name": "p1", "value": "2024-01-15 09:30:00"}, statement["Parameters"].([]interface{})[1])
+	assert.Len(t, statement["Parameters"], 6)
+
+	err := sink.Insert(context.Background(), "broken", []Row{{ID: "1", Values: map[string]interface{}{"id": "1"}}})
+	assert.EqualError(t, err, `redshift statement statement-2 failed: relation "broken" does not exist`)
+
+	err = sink.Insert(context.Background(), "events", []Row{
+		{ID: "1", Values: map[string]interface{}{"id": "1"}},
+		{ID: "2", Values: map[string]interface{}{"key": "task-1"}},
+	})
+	assert.ErrorContains(t, err, "does not have the columns of the first")
+	err = sink.Insert(context.Background(), "events", []Row{{ID: "1", Values: map[string]interface{}{"id; DROP": "1"}}})
+	assert.ErrorContains(t, err, "invalid column name")
+	err = sink.Insert(context.Background(), "events", []Row{{ID: "1", Values: map[string]interface{}{"tags": []string{"bug"}}}})
+	assert.ErrorContains(t, err, "unsupported value of type []string")
+}
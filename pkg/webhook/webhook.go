@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers of webhook requests
+const (
+	SignatureHeader = "X-Todo-Signature"
+	TimestampHeader = "X-Todo-Timestamp"
+	DeliveryHeader  = "X-Todo-Delivery" // ID of the delivery, the same for its retries
+)
+
+// MaxRequestAge is how old a signed request may be before receivers should reject it as a
+// possible replay
+const MaxRequestAge = 5 * time.Minute
+
+// signatureVersion is the version prefix of signatures
+const signatureVersion = "v1"
+
+// ErrInvalidSignature is returned for requests that were not signed with the secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrInternalAddress is returned for endpoints on the local machine or a private network
+var ErrInternalAddress = errors.New("webhook endpoint resolves to a local or private address")
+
+// Client posts signed JSON payloads to webhook endpoints
+type Client interface {
+	// Post posts the payload as JSON to the URL, signed with each of the secrets, so that
+	// receivers can verify it with either while a secret is rotated
+	Post(ctx context.Context, url string, secrets []string, deliveryID string, payload interface{}) error
+}
+
+// client implements a webhook client over HTTP
+type client struct {
+	http *http.Client
+	now  func() time.Time
+}
+
+// NewClient creates a webhook client refusing to connect to loopback, private, link-local,
+// and unspecified addresses. Addresses are checked when dialed, after names are resolved, so
+// endpoints whose names resolve to internal addresses cannot reach internal services either.
+func NewClient(timeout time.Duration) Client {
+	return NewClientWithTransport(timeout, guardedTransport())
+}
+
+// NewClientWithTransport creates a webhook client like NewClient, sending requests through
+// the transport, http.DefaultTransport when nil. Unlike NewClient, the transport may connect
+// to any address. Redirects are not followed, so a delivery only reaches the endpoint that
+// was configured.
+func NewClientWithTransport(timeout time.Duration, transport http.RoundTripper) Client {
+	return &client{
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// guardedTransport returns a transport whose connections to internal addresses are refused.
+// Proxies are not used, as they would connect to the endpoint on the client's behalf.
+func guardedTransport() http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refuseInternal,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// refuseInternal is the dialer control refusing connections to internal addresses
+func refuseInternal(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+		return ErrInternalAddress
+	}
+	return nil
+}
+
+// isInternalIP reports whether the address is a loopback, private, link-local, multicast, or
+// unspecified address, which webhooks must not reach
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// Post posts the payload to the URL. Endpoints acknowledge a delivery with a 2xx status.
+func (c *client) Post(ctx context.Context, url string, secrets []string, deliveryID string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(c.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set(TimestampHeader, timestamp)
+	signatures := make([]string, len(secrets))
+	for i, secret := range secrets {
+		signatures[i] = Sign(secret, timestamp, body)
+	}
+	req.Header.Set(SignatureHeader, strings.Join(signatures, ","))
+	req.Header.Set(DeliveryHeader, deliveryID)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// StatusError is returned when an endpoint responds with a status other than 2xx
+type StatusError struct {
+	StatusCode int
+}
+
+// Error returns the error message
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook endpoint responded with status %d", e.StatusCode)
+}
+
+// IsRejected reports whether the endpoint rejected the delivery in a way retries would not
+// fix: a 4xx status other than 408 Request Timeout and 429 Too Many Requests, or an internal
+// address
+func IsRejected(err error) bool {
+	if errors.Is(err, ErrInternalAddress) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	code := statusErr.StatusCode
+	return code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+// VerifySignature checks the request was signed with the secret and is recent, for
+// receivers of webhooks. The signature is the HMAC-SHA256 of "v1:<timestamp>:<body>"; while
+// the secret is rotated, the signature header lists one signature per secret, separated by
+// commas, and any of them may match.
+func VerifySignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > MaxRequestAge || age < -MaxRequestAge {
+		return errors.New("webhook request timestamp is too old")
+	}
+
+	expected := Sign(secret, timestamp, body)
+	for _, candidate := range strings.Split(signature, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(candidate)), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Sign returns the signature of the request body sent at the timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
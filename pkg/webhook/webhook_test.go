@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPost(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "delivery-1", r.Header.Get(DeliveryHeader))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		// Either secret verifies the request while the secret is rotated
+		for _, secret := range []string{"secret", "previous"} {
+			assert.NoError(t, VerifySignature(secret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, time.Now()))
+		}
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	// The test server listens on a loopback address, which NewClient refuses
+	c := NewClientWithTransport(time.Second, nil)
+	require.NoError(t, c.Post(context.Background(), server.URL, []string{"secret", "previous"}, "delivery-1", map[string]string{"type": "stats.daily"}))
+	assert.Equal(t, "stats.daily", received["type"])
+}
+
+func TestPostInternalAddress(t *testing.T) {
+	posted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = true
+	}))
+	defer server.Close()
+
+	// Addresses are checked once resolved, so names of internal hosts are refused too
+	c := NewClient(time.Second)
+	for _, url := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		err := c.Post(context.Background(), url, []string{"secret"}, "delivery-1", nil)
+		assert.ErrorIs(t, err, ErrInternalAddress, url)
+		assert.True(t, IsRejected(err))
+	}
+	assert.False(t, posted)
+}
+
+func TestPostFailure(t *testing.T) {
+	status := http.StatusGone
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	c := NewClientWithTransport(time.Second, nil)
+	err := c.Post(context.Background(), server.URL, []string{"secret"}, "delivery-1", nil)
+	assert.EqualError(t, err, "webhook endpoint responded with status 410")
+	assert.True(t, IsRejected(err))
+
+	// Endpoints asking to slow down or failing on their side are retried
+	for _, status = range []int{http.StatusTooManyRequests, http.StatusBadGateway} {
+		err = c.Post(context.Background(), server.URL, []string{"secret"}, "delivery-1", nil)
+		require.Error(t, err)
+		assert.False(t, IsRejected(err))
+	}
+
+	// Redirects are not followed
+	status = http.StatusFound
+	err = c.Post(context.Background(), server.URL, []string{"secret"}, "delivery-1", nil)
+	assert.EqualError(t, err, "webhook endpoint responded with status 302")
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"type":"stats.daily"}`)
+	signature := Sign("secret", timestamp, body)
+
+	assert.NoError(t, VerifySignature("secret", timestamp, signature, body, now))
+	assert.ErrorIs(t, VerifySignature("other", timestamp, signature, body, now), ErrInvalidSignature)
+	assert.NoError(t, VerifySignature("secret", timestamp, Sign("new", timestamp, body)+","+signature, body, now))
+	assert.ErrorIs(t, VerifySignature("other", timestamp, Sign("new", timestamp, body)+","+signature, body, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("secret", "soon", signature, body, now), ErrInvalidSignature)
+	assert.EqualError(t, VerifySignature("secret", timestamp, signature, body, now.Add(time.Hour)),
+		"webhook request timestamp is too old")
+}